@@ -87,7 +87,7 @@ func main() {
 	stateMonitorCh := make(chan g.StateMonitorEntry[gameState], 100)
 	memory := b.NewMemMemory[gameState]()
 
-	graph, _ := b.CreateRuntime(startEdge, stateMonitorCh, g.WithMemory(memory))
+	graph, _ := b.CreateRuntime(startEdge, stateMonitorCh, g.WithMemory(memory), g.WithAllowCycles[gameState](100))
 	defer graph.Shutdown()
 
 	graph.AddEdge(
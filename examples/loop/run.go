@@ -78,7 +78,7 @@ func main() {
 	// Build graph
 	startEdge := b.CreateStartEdge(initNode)
 	stateMonitorCh := make(chan g.StateMonitorEntry[gameState], 10)
-	g, _ := b.CreateRuntime(startEdge, stateMonitorCh)
+	g, _ := b.CreateRuntime(startEdge, stateMonitorCh, g.WithAllowCycles[gameState](100))
 	defer g.Shutdown()
 
 	g.AddEdge(
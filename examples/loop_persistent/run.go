@@ -156,7 +156,7 @@ func main() {
 	// Build graph
 	startEdge := b.CreateStartEdge(initNode)
 	stateMonitorCh := make(chan g.StateMonitorEntry[gameState], 10)
-	runtime, _ := b.CreateRuntime(startEdge, stateMonitorCh, g.WithMemory(memory))
+	runtime, _ := b.CreateRuntime(startEdge, stateMonitorCh, g.WithMemory(memory), g.WithAllowCycles[gameState](100))
 	defer runtime.Shutdown()
 
 	runtime.AddEdge(
@@ -136,7 +136,7 @@ func main() {
 	endEdge := b.CreateEndEdge(llmWithTools)
 
 	stateMonitorCh := make(chan g.StateMonitorEntry[a.Conversation], 10)
-	graph, err := b.CreateRuntime(startEdge, stateMonitorCh)
+	graph, err := b.CreateRuntime(startEdge, stateMonitorCh, g.WithAllowCycles[a.Conversation](10))
 	if err != nil {
 		log.Fatalf("Runtime creation failed: %v", err)
 	}
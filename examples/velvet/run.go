@@ -2,10 +2,8 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
-	"sync"
 	"time"
 
 	"github.com/openai/openai-go/v3"
@@ -15,6 +13,7 @@ import (
 	o "github.com/morphy76/ggraph/pkg/agent/openai"
 	b "github.com/morphy76/ggraph/pkg/builders"
 	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/tui"
 )
 
 // Evaluation represents a parsed evaluation result
@@ -33,6 +32,19 @@ type Evaluation struct {
 	} `json:"contenuto"`
 }
 
+// evaluationFormat decodes and validates the evaluations produced by the evaluator node
+var evaluationFormat = a.CreateResponseFormat(validateEvaluation)
+
+// validateEvaluation checks that the scores of an evaluation range from 0 to 10
+func validateEvaluation(eval Evaluation) error {
+	for _, score := range []int{eval.Grammatica.Punteggio, eval.Lessico.Punteggio, eval.Contenuto.Punteggio} {
+		if score < 0 || score > 10 {
+			return fmt.Errorf("punteggio %d fuori dalla scala da 0 a 10", score)
+		}
+	}
+	return nil
+}
+
 // ThreadResult holds the results of a single thread execution
 type ThreadResult struct {
 	ThreadID   string
@@ -132,9 +144,7 @@ var EvaluatorNodeFn o.ConversationNodeFn = func(chatService openai.ChatService,
 		systemMsg := a.CreateMessage(a.System, "Sei un esperto linguista e valutatore di contenuti. "+
 			"Valuta la risposta dello studente in termini di grammatica, correttezza lessicale e correttezza del contenuto rispetto alla domanda posta. "+
 			"Dai un punteggio da 0 a 10 per ogni categoria (10 è il massimo) e un breve commento. "+
-			"Rispondi SOLO con un oggetto JSON nel seguente formato: "+
-			`{"grammatica": {"punteggio": <numero>, "commento": "<testo>"}, "lessico": {"punteggio": <numero>, "commento": "<testo>"}, "contenuto": {"punteggio": <numero>, "commento": "<testo>"}}. `+
-			"Non aggiungere altro testo prima o dopo il JSON. Parla solo in italiano nei commenti.")
+			"Rispondi SOLO con un oggetto JSON, senza altro testo prima o dopo. Parla solo in italiano nei commenti.")
 
 		prompt := fmt.Sprintf("Domanda: %s\n\nRisposta: %s\n\nValuta la risposta.", question, answer)
 
@@ -147,147 +157,48 @@ var EvaluatorNodeFn o.ConversationNodeFn = func(chatService openai.ChatService,
 			return currentState, fmt.Errorf("failed to create conversation options: %w", err)
 		}
 
-		openAIOpts := o.ConvertConversationOptions(useOpts)
-
-		resp, err := chatService.Completions.New(context.Background(), openAIOpts)
+		// The response format of the options has the evaluation validated, and repaired if needed
+		evaluation, err := o.ChatCompletion(context.Background(), chatService, useOpts)
 		if err != nil {
 			return currentState, fmt.Errorf("failed to generate evaluation: %w", err)
 		}
 
 		// Add the evaluator's assessment to the conversation
-		evaluation := resp.Choices[0].Message.Content
-		currentState.Messages = append(currentState.Messages,
-			a.CreateMessage(a.Assistant, evaluation))
+		currentState.Messages = append(currentState.Messages, evaluation)
 
 		return currentState, nil
 	}
 }
 
-// ThreadMonitor tracks state for a single thread
-type ThreadMonitor struct {
-	threadNum       int
-	threadID        string
-	finalEvaluation *Evaluation
-	lastError       error
-	messages        []string
-	completed       bool
-	lastNode        string
-	seenEvaluator   bool
-}
-
-// runThread executes a single thread and monitors its progress
-func runThread(
-	graph g.Runtime[a.Conversation],
-	threadNum int,
-	resultChan chan<- ThreadResult,
-	progressMutex *sync.Mutex,
-	threadProgress map[int]string,
-	monitors map[string]*ThreadMonitor,
-	verbose bool,
-) {
-	startTime := time.Now()
+// collectResult builds the result of a thread from its final snapshot in the monitor
+func collectResult(thread tui.Thread[a.Conversation]) ThreadResult {
 	result := ThreadResult{
-		ThreadID:  fmt.Sprintf("Thread-%d", threadNum),
-		StartTime: startTime,
-	}
-
-	// Update progress
-	updateProgress := func(status string) {
-		progressMutex.Lock()
-		threadProgress[threadNum] = status
-		progressMutex.Unlock()
-	}
-
-	updateProgress("Avvio")
-
-	// Pre-assign a threadID to avoid race condition
-	threadID := fmt.Sprintf("thread-%d", threadNum)
-
-	// Create and register monitor BEFORE invoking the graph
-	monitor := &ThreadMonitor{
-		threadNum: threadNum,
-		threadID:  threadID,
-		messages:  make([]string, 0),
+		ThreadID:  thread.ID,
+		StartTime: thread.StartedAt,
+		EndTime:   thread.UpdatedAt,
+		Error:     thread.Err,
 	}
 
-	progressMutex.Lock()
-	monitors[threadID] = monitor
-	progressMutex.Unlock()
-
-	if verbose {
-		fmt.Printf("\n[Thread %d] Registrato con ID: %s\n", threadNum, threadID)
-	}
-
-	// Run the graph with the pre-assigned threadID
-	userInput := a.CreateConversation()
-	actualThreadID := graph.Invoke(userInput, g.InvokeConfigThreadID(threadID))
-
-	fmt.Printf("\n✅ [Thread %d] Invoke completato: actualThreadID=%s, expected=%s\n",
-		threadNum, actualThreadID, threadID)
-
-	if actualThreadID != threadID {
-		fmt.Printf("\n⚠️  [Thread %d] ThreadID DIVERSO da atteso!\n", threadNum)
-	}
-
-	// Wait for this specific thread to complete
-	timeout := time.After(120 * time.Second)
-	checkInterval := time.NewTicker(100 * time.Millisecond)
-	defer checkInterval.Stop()
-
-	checkCount := 0
-	for {
-		select {
-		case <-timeout:
-			updateProgress("Timeout")
-
-			// Final debug before timeout
-			progressMutex.Lock()
-			fmt.Printf("\n❌ [Thread %d] TIMEOUT! Status finale:\n", threadNum)
-			fmt.Printf("   - completed: %v\n", monitor.completed)
-			fmt.Printf("   - finalEvaluation: %v\n", monitor.finalEvaluation != nil)
-			fmt.Printf("   - lastError: %v\n", monitor.lastError)
-			fmt.Printf("   - messages count: %d\n", len(monitor.messages))
-			progressMutex.Unlock()
-
-			result.EndTime = time.Now()
-			result.Success = false
-			result.Error = fmt.Errorf("timeout dopo 120 secondi")
-			resultChan <- result
-			return
-
-		case <-checkInterval.C:
-			checkCount++
-			progressMutex.Lock()
-			completed := monitor.completed
-			finalEval := monitor.finalEvaluation
-			lastErr := monitor.lastError
-
-			// Debug every 10 seconds
-			if verbose && checkCount%100 == 0 {
-				fmt.Printf("\n🔍 [Thread %d] Check #%d: completed=%v, hasEval=%v, hasError=%v\n",
-					threadNum, checkCount, completed, finalEval != nil, lastErr != nil)
-			}
-			progressMutex.Unlock()
-
-			if completed {
-				result.EndTime = time.Now()
-				result.Success = lastErr == nil && finalEval != nil
-				result.Evaluation = finalEval
-				result.Error = lastErr
-
-				if verbose && result.Success {
-					updateProgress("✓ Completato")
-				} else if verbose && !result.Success {
-					updateProgress(fmt.Sprintf("✗ Errore: %v", lastErr))
-				} else {
-					updateProgress("Completato")
-				}
-
-				resultChan <- result
-				return
-			}
+	switch thread.Status {
+	case tui.ThreadCompleted:
+		// Extract final evaluation from messages (should have 3: question, answer, evaluation)
+		if len(thread.State.Messages) < 3 {
+			result.Error = fmt.Errorf("messaggi insufficienti: %d (attesi 3)", len(thread.State.Messages))
+			return result
 		}
+		eval, err := a.DecodeResponse[Evaluation](evaluationFormat, thread.State.Messages[2].Content)
+		if err != nil {
+			result.Error = fmt.Errorf("errore parsing valutazione finale: %w", err)
+			return result
+		}
+		result.Evaluation = &eval
+		result.Success = true
+	case tui.ThreadFailed:
+		// The error of the failed invocation is already in the result
+	default:
+		result.Error = fmt.Errorf("thread non completato (ultimo nodo: %s)", thread.Node)
 	}
+	return result
 }
 
 // calculateAverages computes the average scores from all successful evaluations
@@ -312,129 +223,6 @@ func calculateAverages(results []ThreadResult) (avgGrammatica, avgLessico, avgCo
 	return
 }
 
-// monitorStateChannel processes all state updates from the graph
-func monitorStateChannel(
-	stateMonitorCh <-chan g.StateMonitorEntry[a.Conversation],
-	monitors map[string]*ThreadMonitor,
-	progressMutex *sync.Mutex,
-	threadProgress map[int]string,
-	verbose bool,
-	done chan bool,
-) {
-	eventCount := 0
-	for entry := range stateMonitorCh {
-		eventCount++
-
-		if verbose {
-			fmt.Printf("\n🔔 Evento #%d ricevuto: ThreadID=%s, Node=%s, Running=%v, Error=%v\n",
-				eventCount, entry.ThreadID, entry.Node, entry.Running, entry.Error)
-		}
-
-		progressMutex.Lock()
-
-		monitor, exists := monitors[entry.ThreadID]
-		if !exists {
-			fmt.Printf("\n⚠️  Evento #%d: ThreadID sconosciuto: %s (Node: %s, Running: %v)\n",
-				eventCount, entry.ThreadID, entry.Node, entry.Running)
-			fmt.Printf("   Monitor registrati: %d\n", len(monitors))
-			if len(monitors) > 0 {
-				fmt.Printf("   ThreadID registrati: ")
-				for tid := range monitors {
-					fmt.Printf("%s ", tid)
-				}
-				fmt.Println()
-			}
-			progressMutex.Unlock()
-			continue
-		}
-
-		// Track which node we're on
-		monitor.lastNode = entry.Node
-		if entry.Node == "EvaluatorNode" {
-			monitor.seenEvaluator = true
-		}
-
-		if entry.Running {
-			// Thread is still running - update progress
-			threadProgress[monitor.threadNum] = fmt.Sprintf("Esecuzione: %s", entry.Node)
-
-			if verbose {
-				fmt.Printf("\n[Thread %d] ▶ %s (thread attivo)\n", monitor.threadNum, entry.Node)
-			}
-		} else {
-			// Thread has completed (Running=false)
-			if entry.Error != nil {
-				monitor.lastError = entry.Error
-				threadProgress[monitor.threadNum] = fmt.Sprintf("Errore: %v", entry.Error)
-
-				if verbose {
-					fmt.Printf("\n[Thread %d] ✗ Thread completato con errore: %v\n", monitor.threadNum, entry.Error)
-				}
-			} else {
-				threadProgress[monitor.threadNum] = "Thread completato"
-
-				if verbose {
-					fmt.Printf("\n[Thread %d] ✅ Thread completato con successo\n", monitor.threadNum)
-				}
-
-				// Extract final evaluation from messages (should have 3: question, answer, evaluation)
-				if len(entry.NewState.Messages) >= 3 {
-					evalMsg := entry.NewState.Messages[2] // Third message should be evaluation
-					var eval Evaluation
-					if err := json.Unmarshal([]byte(evalMsg.Content), &eval); err == nil {
-						monitor.finalEvaluation = &eval
-						if verbose {
-							fmt.Printf("\n[Thread %d] 📊 Valutazione finale estratta:\n", monitor.threadNum)
-							fmt.Printf("   Grammatica: %d/10 - %s\n", eval.Grammatica.Punteggio, eval.Grammatica.Commento)
-							fmt.Printf("   Lessico: %d/10 - %s\n", eval.Lessico.Punteggio, eval.Lessico.Commento)
-							fmt.Printf("   Contenuto: %d/10 - %s\n", eval.Contenuto.Punteggio, eval.Contenuto.Commento)
-						}
-					} else if verbose {
-						fmt.Printf("\n[Thread %d] ⚠️  Errore parsing valutazione finale: %v\n", monitor.threadNum, err)
-						fmt.Printf("   Contenuto messaggio #3: %.200s\n", evalMsg.Content)
-					}
-
-					// Display all messages
-					if verbose {
-						fmt.Printf("\n[Thread %d] 📚 DOMANDA:\n%s\n", monitor.threadNum, entry.NewState.Messages[0].Content)
-						fmt.Printf("\n[Thread %d] 🎓 RISPOSTA:\n%s\n", monitor.threadNum, entry.NewState.Messages[1].Content)
-						fmt.Printf("\n[Thread %d] 📊 VALUTAZIONE:\n%s\n", monitor.threadNum, entry.NewState.Messages[2].Content)
-					}
-				} else if verbose {
-					fmt.Printf("\n[Thread %d] ⚠️  Messaggi insufficienti: %d (attesi 3)\n",
-						monitor.threadNum, len(entry.NewState.Messages))
-				}
-			}
-
-			// Mark thread as completed
-			monitor.completed = true
-		}
-
-		progressMutex.Unlock()
-	}
-
-	done <- true
-}
-
-// printProgress displays the current progress of all threads
-func printProgress(threadProgress map[int]string, totalThreads int) {
-	fmt.Print("\033[2J\033[H") // Clear screen and move to top
-	fmt.Println("=== Velvet Educational Example - Esecuzione Concorrente ===")
-	fmt.Println()
-	fmt.Printf("Thread attivi: %d\n", totalThreads)
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-
-	for i := 1; i <= totalThreads; i++ {
-		status := threadProgress[i]
-		if status == "" {
-			status = "In attesa"
-		}
-		fmt.Printf("Thread %2d: %s\n", i, status)
-	}
-
-	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
-}
-
 func main() {
 	// Get user input for number of threads and time period
 	var numThreads int
@@ -488,6 +276,7 @@ func main() {
 		aiwClient,
 		EvaluatorNodeFn,
 		a.WithTemperature(0.0),
+		a.WithResponseFormat(validateEvaluation),
 	)
 	if err != nil {
 		log.Fatalf("Failed to create evaluator node: %v", err)
@@ -519,100 +308,50 @@ func main() {
 		log.Fatalf("Graph validation failed: %v", err)
 	}
 
-	// Setup concurrent execution
-	resultChan := make(chan ThreadResult, numThreads)
-	var progressMutex sync.Mutex
-	threadProgress := make(map[int]string)
-	monitors := make(map[string]*ThreadMonitor)
-	var wg sync.WaitGroup
-
-	// Ask for verbose mode
-	var verboseInput string
-	fmt.Print("Modalità verbose (mostra domande/risposte/valutazioni)? (s/n): ")
-	fmt.Scanf("%s", &verboseInput)
-	verbose := verboseInput == "s" || verboseInput == "S"
-
 	// Calculate delay between thread starts
 	delayBetweenThreads := time.Duration(timePeriod) * time.Second / time.Duration(numThreads)
 
 	fmt.Println()
 	fmt.Printf("Avvio di %d thread distribuiti su %d secondi (intervallo: %.2f secondi)\n",
 		numThreads, timePeriod, delayBetweenThreads.Seconds())
-	if !verbose {
-		fmt.Println("Premi Invio per iniziare...")
-		fmt.Scanln()
-	} else {
-		var dummy string
-		fmt.Println("Premi Invio per iniziare...")
-		fmt.Scanln(&dummy)
-		fmt.Println()
-		fmt.Println("=== INIZIO ESECUZIONE ===")
-		fmt.Println()
-	}
+	fmt.Println("Premi Invio per iniziare...")
+	fmt.Scanln()
 
 	startTime := time.Now()
 
-	// Start global state monitor
-	monitorDone := make(chan bool)
-	go monitorStateChannel(stateMonitorCh, monitors, &progressMutex, threadProgress, verbose, monitorDone)
-
-	fmt.Println()
-	fmt.Printf("✅ Monitor globale avviato (canale buffer: %d)\n", cap(stateMonitorCh))
-	fmt.Println()
+	// Launch threads with time distribution, with pre-assigned thread IDs
+	go func() {
+		for i := 1; i <= numThreads; i++ {
+			graph.Invoke(a.CreateConversation(), g.InvokeConfigThreadID(fmt.Sprintf("thread-%d", i)))
 
-	// Start progress monitoring (only in non-verbose mode)
-	var progressTicker *time.Ticker
-	if !verbose {
-		progressTicker = time.NewTicker(500 * time.Millisecond)
-		defer progressTicker.Stop()
-
-		go func() {
-			for range progressTicker.C {
-				progressMutex.Lock()
-				printProgress(threadProgress, numThreads)
-				progressMutex.Unlock()
+			// Wait before starting the next thread (except for the last one)
+			if i < numThreads {
+				time.Sleep(delayBetweenThreads)
 			}
-		}()
-	}
-
-	// Launch threads with time distribution
-	for i := 1; i <= numThreads; i++ {
-		wg.Add(1)
-		go func(threadNum int) {
-			defer wg.Done()
-			runThread(graph, threadNum, resultChan, &progressMutex, threadProgress, monitors, verbose)
-		}(i)
-
-		// Wait before starting the next thread (except for the last one)
-		if i < numThreads {
-			time.Sleep(delayBetweenThreads)
 		}
-	}
-
-	// Wait for all threads to complete
-	go func() {
-		wg.Wait()
-		close(resultChan)
 	}()
 
-	// Collect results
-	var results []ThreadResult
-	for result := range resultChan {
-		results = append(results, result)
+	// Monitor the threads until all of them terminate, the last one has 120 seconds to complete
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(timePeriod+120)*time.Second)
+	defer cancel()
+	threads, err := tui.Run(ctx, stateMonitorCh,
+		tui.WithTitle[a.Conversation]("Velvet Educational Example - Esecuzione Concorrente"),
+		tui.WithPreview(tui.ConversationPreview),
+		tui.WithExitAfter[a.Conversation](numThreads),
+	)
+	if err != nil {
+		log.Printf("Monitor interrotto: %v", err)
 	}
 
-	// Stop progress ticker if it was started
-	if progressTicker != nil {
-		progressTicker.Stop()
+	// Collect results
+	var results []ThreadResult
+	for _, thread := range threads {
+		results = append(results, collectResult(thread))
 	}
 
 	totalDuration := time.Since(startTime)
 
-	// Wait a bit for final state updates
-	time.Sleep(500 * time.Millisecond)
-
 	// Display final results
-	fmt.Print("\033[2J\033[H") // Clear screen
 	fmt.Println("=== Velvet Educational Example - Risultati Finali ===")
 	fmt.Println()
 	fmt.Printf("Tempo totale di esecuzione: %.2f secondi\n", totalDuration.Seconds())
@@ -643,8 +382,8 @@ func main() {
 	fmt.Println("📝 DETTAGLIO PER THREAD")
 	fmt.Println("━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━━")
 
-	for i, result := range results {
-		fmt.Printf("\nThread %d:\n", i+1)
+	for _, result := range results {
+		fmt.Printf("\n%s:\n", result.ThreadID)
 		fmt.Printf("  Durata: %.2f secondi\n", result.EndTime.Sub(result.StartTime).Seconds())
 
 		if result.Success && result.Evaluation != nil {
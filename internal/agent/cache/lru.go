@@ -0,0 +1,84 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// LRU is an in-memory cache evicting the least recently used entries.
+type LRU struct {
+	mu       sync.Mutex
+	capacity int
+	entries  map[string]*list.Element
+	order    *list.List
+	now      func() time.Time
+}
+
+// NewLRU creates an LRU cache holding up to capacity entries.
+func NewLRU(capacity int) *LRU {
+	return &LRU{
+		capacity: capacity,
+		entries:  make(map[string]*list.Element, capacity),
+		order:    list.New(),
+		now:      time.Now,
+	}
+}
+
+// Get returns the entry, if present and not expired.
+func (c *LRU) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false, nil
+	}
+	entry := element.Value.(*lruEntry)
+	if !entry.expiresAt.IsZero() && !c.now().Before(entry.expiresAt) {
+		c.order.Remove(element)
+		delete(c.entries, key)
+		return nil, false, nil
+	}
+	c.order.MoveToFront(element)
+	return entry.value, true, nil
+}
+
+// Set stores the entry, evicting the least recently used one when the cache is full.
+func (c *LRU) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry := &lruEntry{key: key, value: value}
+	if ttl > 0 {
+		entry.expiresAt = c.now().Add(ttl)
+	}
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.order.MoveToFront(element)
+		return nil
+	}
+
+	c.entries[key] = c.order.PushFront(entry)
+	for c.order.Len() > c.capacity {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*lruEntry).key)
+	}
+	return nil
+}
+
+// Len returns the number of entries, expired ones included.
+func (c *LRU) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
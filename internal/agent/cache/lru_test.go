@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLRU_Eviction(t *testing.T) {
+	ctx := context.Background()
+	lru := NewLRU(2)
+
+	lru.Set(ctx, "a", []byte("1"), 0)
+	lru.Set(ctx, "b", []byte("2"), 0)
+	if _, ok, _ := lru.Get(ctx, "a"); !ok {
+		t.Fatal("Expected a hit")
+	}
+	lru.Set(ctx, "c", []byte("3"), 0)
+
+	if _, ok, _ := lru.Get(ctx, "b"); ok {
+		t.Error("Expected the least recently used entry to be evicted")
+	}
+	if _, ok, _ := lru.Get(ctx, "a"); !ok {
+		t.Error("Expected the recently used entry to survive")
+	}
+	if lru.Len() != 2 {
+		t.Errorf("Expected 2 entries, got %d", lru.Len())
+	}
+
+	lru.Set(ctx, "a", []byte("updated"), 0)
+	if got, _, _ := lru.Get(ctx, "a"); string(got) != "updated" {
+		t.Errorf("Expected the updated value, got %q", got)
+	}
+}
+
+func TestLRU_TTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	lru := NewLRU(2)
+	lru.now = func() time.Time { return now }
+
+	lru.Set(ctx, "a", []byte("1"), time.Minute)
+	lru.Set(ctx, "b", []byte("2"), 0)
+
+	now = now.Add(2 * time.Minute)
+	if _, ok, _ := lru.Get(ctx, "a"); ok {
+		t.Error("Expected the entry to expire")
+	}
+	if _, ok, _ := lru.Get(ctx, "b"); !ok {
+		t.Error("Expected the entry without ttl to survive")
+	}
+	if lru.Len() != 1 {
+		t.Errorf("Expected the expired entry to be removed, got %d entries", lru.Len())
+	}
+}
@@ -0,0 +1,69 @@
+// Package embedding provides the batching and retry logic shared by the embedding providers.
+package embedding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+)
+
+// BatchFn embeds a single batch of texts.
+type BatchFn func(ctx context.Context, batch []string) ([][]float32, error)
+
+// Batched splits the texts into batches, embeds them in order and retries the rate-limited batches.
+func Batched(ctx context.Context, texts []string, opts *a.EmbedderOptions, fn BatchFn) ([][]float32, error) {
+	rv := make([][]float32, 0, len(texts))
+	for start := 0; start < len(texts); start += opts.BatchSize {
+		batch := texts[start:min(start+opts.BatchSize, len(texts))]
+
+		embeddings, err := withRetries(ctx, batch, opts, fn)
+		if err != nil {
+			return nil, fmt.Errorf("cannot embed texts %d-%d: %w", start, start+len(batch)-1, err)
+		}
+		if len(embeddings) != len(batch) {
+			return nil, fmt.Errorf("%w: %d texts, %d embeddings", a.ErrEmbeddingCountMismatch, len(batch), len(embeddings))
+		}
+		rv = append(rv, embeddings...)
+	}
+	return rv, nil
+}
+
+func withRetries(ctx context.Context, batch []string, opts *a.EmbedderOptions, fn BatchFn) ([][]float32, error) {
+	backoff := opts.RetryBackoff
+	for attempt := 0; ; attempt++ {
+		embeddings, err := fn(ctx, batch)
+		if err == nil {
+			return embeddings, nil
+		}
+
+		var rateLimitErr *a.RateLimitError
+		if !errors.As(err, &rateLimitErr) || attempt >= opts.MaxRetries {
+			return nil, err
+		}
+
+		wait := backoff
+		if rateLimitErr.RetryAfter > 0 {
+			wait = rateLimitErr.RetryAfter
+		}
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// ToFloat32 converts a float64 vector.
+func ToFloat32(vector []float64) []float32 {
+	rv := make([]float32, len(vector))
+	for i, v := range vector {
+		rv[i] = float32(v)
+	}
+	return rv
+}
@@ -0,0 +1,257 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	// TagDescription is the struct tag holding the description of a field.
+	TagDescription = "desc"
+)
+
+var (
+	// ErrSchemaMismatch indicates that a value does not match its JSON schema.
+	ErrSchemaMismatch = errors.New("value does not match the schema")
+
+	timeType = reflect.TypeOf(time.Time{})
+)
+
+// Field describes a single property derived from a struct field.
+type Field struct {
+	// Name is the JSON name of the field.
+	Name string
+	// Index is the field index within the struct.
+	Index int
+	// Required is true when the field has no omitempty option.
+	Required bool
+	// Schema is the JSON schema of the field.
+	Schema map[string]any
+}
+
+// FromType derives a JSON schema from a Go type.
+//
+// Struct fields are named after their json tag, described by their desc tag and
+// required unless marked as omitempty; unexported and "-" fields are skipped.
+func FromType(t reflect.Type) map[string]any {
+	return fromType(t, map[reflect.Type]bool{})
+}
+
+// Fields returns the properties derived from the fields of a struct type.
+func Fields(t reflect.Type) []Field {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return []Field{}
+	}
+	return fields(t, map[reflect.Type]bool{t: true})
+}
+
+// Validate checks that a decoded JSON value matches the given schema.
+//
+// Only the subset of JSON schema produced by FromType is verified: type, properties,
+// required, items, additionalProperties and enum.
+func Validate(schema map[string]any, value any) error {
+	return validate(schema, value, "$")
+}
+
+func fromType(t reflect.Type, visiting map[reflect.Type]bool) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string"}
+		}
+		return map[string]any{"type": "array", "items": fromType(t.Elem(), visiting)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": fromType(t.Elem(), visiting)}
+	case reflect.Struct:
+		if visiting[t] {
+			return map[string]any{"type": "object"}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		props := make(map[string]any)
+		required := make([]string, 0)
+		for _, f := range fields(t, visiting) {
+			props[f.Name] = f.Schema
+			if f.Required {
+				required = append(required, f.Name)
+			}
+		}
+		return map[string]any{
+			"type":                 "object",
+			"properties":           props,
+			"required":             required,
+			"additionalProperties": false,
+		}
+	default:
+		return map[string]any{}
+	}
+}
+
+func fields(t reflect.Type, visiting map[reflect.Type]bool) []Field {
+	rv := make([]Field, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := sf.Name
+		required := true
+		if tag, ok := sf.Tag.Lookup("json"); ok {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			for _, opt := range parts[1:] {
+				if opt == "omitempty" || opt == "omitzero" {
+					required = false
+				}
+			}
+		}
+
+		fieldSchema := fromType(sf.Type, visiting)
+		if desc := sf.Tag.Get(TagDescription); desc != "" {
+			fieldSchema["description"] = desc
+		}
+
+		rv = append(rv, Field{Name: name, Index: i, Required: required, Schema: fieldSchema})
+	}
+	return rv
+}
+
+func validate(schema map[string]any, value any, path string) error {
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, e := range enum {
+			if reflect.DeepEqual(e, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("%w: %s is not one of the allowed values", ErrSchemaMismatch, path)
+		}
+	}
+
+	switch schema["type"] {
+	case "string":
+		if _, ok := value.(string); !ok {
+			return typeMismatch(path, "string", value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return typeMismatch(path, "boolean", value)
+		}
+	case "number":
+		if _, ok := asNumber(value); !ok {
+			return typeMismatch(path, "number", value)
+		}
+	case "integer":
+		n, ok := asNumber(value)
+		if !ok || n != float64(int64(n)) {
+			return typeMismatch(path, "integer", value)
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return typeMismatch(path, "array", value)
+		}
+		if itemSchema, ok := schema["items"].(map[string]any); ok {
+			for i, item := range items {
+				if err := validate(itemSchema, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return typeMismatch(path, "object", value)
+		}
+		for _, req := range requiredOf(schema) {
+			if _, ok := obj[req]; !ok {
+				return fmt.Errorf("%w: %s.%s is required", ErrSchemaMismatch, path, req)
+			}
+		}
+		props, _ := schema["properties"].(map[string]any)
+		for key, v := range obj {
+			if propSchema, ok := props[key].(map[string]any); ok {
+				if err := validate(propSchema, v, path+"."+key); err != nil {
+					return err
+				}
+				continue
+			}
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional && props != nil {
+					return fmt.Errorf("%w: %s.%s is not allowed", ErrSchemaMismatch, path, key)
+				}
+			case map[string]any:
+				if err := validate(additional, v, path+"."+key); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func requiredOf(schema map[string]any) []string {
+	switch req := schema["required"].(type) {
+	case []string:
+		return req
+	case []any:
+		rv := make([]string, 0, len(req))
+		for _, r := range req {
+			if s, ok := r.(string); ok {
+				rv = append(rv, s)
+			}
+		}
+		return rv
+	}
+	return nil
+}
+
+func asNumber(value any) (float64, bool) {
+	switch n := value.(type) {
+	case float64:
+		return n, true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+func typeMismatch(path, expected string, value any) error {
+	return fmt.Errorf("%w: %s must be %s, got %T", ErrSchemaMismatch, path, expected, value)
+}
@@ -0,0 +1,89 @@
+package schema_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/morphy76/ggraph/internal/agent/schema"
+)
+
+type address struct {
+	City string `json:"city" desc:"The city name"`
+	Zip  string `json:"zip,omitempty"`
+}
+
+type person struct {
+	Name     string         `json:"name"`
+	Age      int            `json:"age"`
+	Tags     []string       `json:"tags,omitempty"`
+	Address  *address       `json:"address"`
+	Extra    map[string]int `json:"extra,omitempty"`
+	Ignored  string         `json:"-"`
+	internal string
+}
+
+func TestFromType(t *testing.T) {
+	s := schema.FromType(reflect.TypeOf(person{}))
+
+	if s["type"] != "object" {
+		t.Fatalf("Expected object schema, got %v", s["type"])
+	}
+	props := s["properties"].(map[string]any)
+	if _, ok := props["Ignored"]; ok {
+		t.Error("Expected '-' field to be skipped")
+	}
+	if _, ok := props["internal"]; ok {
+		t.Error("Expected unexported field to be skipped")
+	}
+	if len(props) != 5 {
+		t.Errorf("Expected 5 properties, got %d", len(props))
+	}
+
+	required := s["required"].([]string)
+	if !reflect.DeepEqual(required, []string{"name", "age", "address"}) {
+		t.Errorf("Unexpected required fields %v", required)
+	}
+
+	city := props["address"].(map[string]any)["properties"].(map[string]any)["city"].(map[string]any)
+	if city["description"] != "The city name" {
+		t.Errorf("Expected description from desc tag, got %v", city["description"])
+	}
+}
+
+func TestValidate(t *testing.T) {
+	s := schema.FromType(reflect.TypeOf(person{}))
+
+	tests := []struct {
+		name    string
+		doc     string
+		wantErr bool
+	}{
+		{"valid", `{"name":"a","age":1,"address":{"city":"x"}}`, false},
+		{"valid with optionals", `{"name":"a","age":1,"tags":["t"],"address":{"city":"x","zip":"1"},"extra":{"k":2}}`, false},
+		{"missing required", `{"name":"a","address":{"city":"x"}}`, true},
+		{"wrong type", `{"name":"a","age":"1","address":{"city":"x"}}`, true},
+		{"not an integer", `{"name":"a","age":1.5,"address":{"city":"x"}}`, true},
+		{"unknown property", `{"name":"a","age":1,"address":{"city":"x"},"other":true}`, true},
+		{"nested missing required", `{"name":"a","age":1,"address":{}}`, true},
+		{"wrong item type", `{"name":"a","age":1,"tags":[1],"address":{"city":"x"}}`, true},
+		{"wrong map value type", `{"name":"a","age":1,"address":{"city":"x"},"extra":{"k":"v"}}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var value any
+			if err := json.Unmarshal([]byte(tt.doc), &value); err != nil {
+				t.Fatalf("invalid test document: %v", err)
+			}
+			err := schema.Validate(s, value)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, schema.ErrSchemaMismatch) {
+				t.Errorf("Expected ErrSchemaMismatch, got %v", err)
+			}
+		})
+	}
+}
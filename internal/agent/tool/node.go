@@ -1,6 +1,7 @@
 package tool
 
 import (
+	"context"
 	"fmt"
 	"sync"
 
@@ -12,7 +13,28 @@ import (
 
 // NodeToolFactory creates a new instance of a Node capable of processing tool calls within an agent conversation.
 func NodeToolFactory(name string, tools ...*t.Tool) (g.Node[a.Conversation], error) {
-	rv, err := b.NewNode(name, runToolsFunc(tools...),
+	mappedTools := MapTools(tools...)
+	return newToolNode(name, func() (map[string]*t.Tool, error) {
+		return mappedTools, nil
+	})
+}
+
+// NodeRegistryToolFactory creates a tool node executing the tools of a registry matching the patterns, selected at each call.
+func NodeRegistryToolFactory(name string, registry *t.Registry, patterns ...string) (g.Node[a.Conversation], error) {
+	if registry == nil {
+		return nil, fmt.Errorf("failed to create the tool executor node: %w", t.ErrRegistryNil)
+	}
+	return newToolNode(name, func() (map[string]*t.Tool, error) {
+		tools, err := registry.Select(patterns...)
+		if err != nil {
+			return nil, err
+		}
+		return MapTools(tools...), nil
+	})
+}
+
+func newToolNode(name string, toolsFn func() (map[string]*t.Tool, error)) (g.Node[a.Conversation], error) {
+	rv, err := b.NewContextNode(name, runToolsFunc(toolsFn),
 		g.WithReducer(toolExecutionReducer))
 	if err != nil {
 		return nil, fmt.Errorf("failed to create the tool executor node: %w", err)
@@ -20,61 +42,68 @@ func NodeToolFactory(name string, tools ...*t.Tool) (g.Node[a.Conversation], err
 	return rv, nil
 }
 
-// ------------------------------------------------------------------------------
-// Node Implementation
-// ------------------------------------------------------------------------------
+// MapTools indexes the given tools by name.
+func MapTools(tools ...*t.Tool) map[string]*t.Tool {
+	rv := make(map[string]*t.Tool, len(tools))
+	for _, tool := range tools {
+		rv[tool.Name] = tool
+	}
+	return rv
+}
 
-func runToolsFunc(tools ...*t.Tool) g.NodeFn[a.Conversation] {
+// ExecuteToolCalls runs the tool calls in parallel and returns one tool message per call, in the order of the calls.
+//
+// The context is propagated to context-aware tools and aborts the pending calls when done; the
+// tool policies are enforced against the thread metadata it carries, see t.ContextWithMetadata.
+func ExecuteToolCalls(ctx context.Context, mappedTools map[string]*t.Tool, toolCalls []t.FnCall) []a.Message {
+	// TODO assuming so far that there are no dependencies among tool calls, then I run all tool calls in parallel
+	wg := sync.WaitGroup{}
 
-	mappedTools := make(map[string]*t.Tool)
-	for _, tool := range tools {
-		mappedTools[tool.Name] = tool
+	rv := make([]a.Message, len(toolCalls))
+	for i, call := range toolCalls {
+		wg.Add(1)
+		go func(idx int, tc t.FnCall) {
+			defer wg.Done()
+
+			useTool, found := mappedTools[tc.ToolName]
+			if !found {
+				rv[idx] = a.CreateMessage(a.Tool, fmt.Sprintf("%s:%s", tc.ID, t.ErrToolNotFound))
+				return
+			}
+			result, err := useTool.Execute(ctx, tc)
+			if err != nil {
+				rv[idx] = a.CreateMessage(a.Tool, fmt.Sprintf("%s:%s", tc.ID, err))
+				return
+			}
+
+			rv[idx] = a.CreateMessage(a.Tool, fmt.Sprintf("%s:%v", tc.ID, result))
+		}(i, call)
 	}
 
-	return func(userInput, currentState a.Conversation, notifyPartial g.NotifyPartialFn[a.Conversation]) (a.Conversation, error) {
+	wg.Wait()
+
+	return rv
+}
+
+// ------------------------------------------------------------------------------
+// Node Implementation
+// ------------------------------------------------------------------------------
+
+func runToolsFunc(toolsFn func() (map[string]*t.Tool, error)) g.ContextNodeFn[a.Conversation] {
+	return func(ctx context.Context, userInput, currentState a.Conversation, notifyPartial g.NotifyPartialFn[a.Conversation]) (a.Conversation, error) {
 		toolCalls := currentState.CurrentToolCalls
-		if len(toolCalls) == 0 || len(tools) == 0 {
+		if len(toolCalls) == 0 {
 			return a.CreateConversation(), nil
 		}
-
-		// TODO assuming so far that there are no dependencies among tool calls, then I run all tool calls in parallel
-		wg := sync.WaitGroup{}
-		callStateMutex := sync.Mutex{}
-
-		callState := a.CreateConversation()
-		for _, call := range toolCalls {
-			wg.Add(1)
-			go func(tc t.FnCall) {
-				defer wg.Done()
-
-				useTool, found := mappedTools[tc.ToolName]
-				if !found {
-					errorToolMessage := fmt.Sprintf("%s:%s", call.ID, t.ErrToolNotFound)
-					callStateMutex.Lock()
-					callState.Messages = append(callState.Messages, a.CreateMessage(a.Tool, errorToolMessage))
-					callStateMutex.Unlock()
-					return
-				}
-				useArgs := call.ArgsAsSortedSlice(useTool)
-				rv, err := useTool.Call(useArgs...)
-				if err != nil {
-					errorToolMessage := fmt.Sprintf("%s:%s", call.ID, err)
-					callStateMutex.Lock()
-					callState.Messages = append(callState.Messages, a.CreateMessage(a.Tool, errorToolMessage))
-					callStateMutex.Unlock()
-					return
-				}
-
-				resultToolMessage := fmt.Sprintf("%s:%v", call.ID, rv)
-				callStateMutex.Lock()
-				callState.Messages = append(callState.Messages, a.CreateMessage(a.Tool, resultToolMessage))
-				callStateMutex.Unlock()
-			}(call)
+		mappedTools, err := toolsFn()
+		if err != nil {
+			return currentState, fmt.Errorf("cannot select the tools: %w", err)
+		}
+		if len(mappedTools) == 0 {
+			return a.CreateConversation(), nil
 		}
 
-		wg.Wait()
-
-		return callState, nil
+		return a.CreateConversation(ExecuteToolCalls(t.ContextWithMetadata(ctx, currentState.Metadata), mappedTools, toolCalls)...), nil
 	}
 }
 
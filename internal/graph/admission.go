@@ -0,0 +1,236 @@
+package graph
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+type admissionTicket struct {
+	threadID   string
+	priority   int
+	seq        uint64
+	enqueuedAt time.Time
+	wait       time.Duration
+	start      func(wait time.Duration)
+	index      int
+	// resumed marks the ticket of a suspended invocation, not accounted as a new admission.
+	resumed bool
+}
+
+// admissionHeap orders the tickets by priority, then by arrival.
+type admissionHeap []*admissionTicket
+
+func (h admissionHeap) Len() int { return len(h) }
+
+func (h admissionHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h admissionHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *admissionHeap) Push(x any) {
+	ticket := x.(*admissionTicket)
+	ticket.index = len(*h)
+	*h = append(*h, ticket)
+}
+
+func (h *admissionHeap) Pop() any {
+	old := *h
+	n := len(old)
+	ticket := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	ticket.index = -1
+	return ticket
+}
+
+// admissionController bounds the concurrent invocations, the exceeding ones wait in a priority queue.
+type admissionController struct {
+	mu sync.Mutex
+
+	maxRunning int
+	maxQueued  int
+
+	running int
+	queue   admissionHeap
+	queued  map[string]*admissionTicket
+	seq     uint64
+
+	admitted  uint64
+	rejected  uint64
+	totalWait time.Duration
+	maxWait   time.Duration
+}
+
+func newAdmissionController(maxRunning, maxQueued int) *admissionController {
+	return &admissionController{
+		maxRunning: maxRunning,
+		maxQueued:  maxQueued,
+		queued:     make(map[string]*admissionTicket),
+	}
+}
+
+// enter admits the invocation or enqueues it, start is called when a queued invocation is admitted.
+// It returns true when the invocation is admitted straight away.
+func (a *admissionController) enter(threadID string, priority int, start func(wait time.Duration)) (bool, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.maxRunning <= 0 || (a.running < a.maxRunning && len(a.queue) == 0) {
+		a.running++
+		a.admitted++
+		return true, nil
+	}
+	if a.maxQueued > 0 && len(a.queue) >= a.maxQueued {
+		a.rejected++
+		return false, g.ErrAdmissionQueueFull
+	}
+
+	a.enqueue(threadID, priority, false, start)
+	return false, nil
+}
+
+// reenter admits a suspended invocation like enter, the queue bound does not reject it though.
+func (a *admissionController) reenter(threadID string, priority int, start func(wait time.Duration)) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.maxRunning <= 0 || (a.running < a.maxRunning && len(a.queue) == 0) {
+		a.running++
+		return true
+	}
+	a.enqueue(threadID, priority, true, start)
+	return false
+}
+
+// enqueue queues the invocation of the thread, the caller holds mu.
+func (a *admissionController) enqueue(threadID string, priority int, resumed bool, start func(wait time.Duration)) {
+	a.seq++
+	ticket := &admissionTicket{
+		threadID:   threadID,
+		priority:   priority,
+		seq:        a.seq,
+		enqueuedAt: time.Now(),
+		start:      start,
+		resumed:    resumed,
+	}
+	heap.Push(&a.queue, ticket)
+	a.queued[threadID] = ticket
+}
+
+// leave releases the slot of a terminated invocation and returns the next admitted ticket, if any.
+func (a *admissionController) leave() *admissionTicket {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.running--
+	if len(a.queue) == 0 || (a.maxRunning > 0 && a.running >= a.maxRunning) {
+		return nil
+	}
+
+	ticket := heap.Pop(&a.queue).(*admissionTicket)
+	delete(a.queued, ticket.threadID)
+	ticket.wait = time.Since(ticket.enqueuedAt)
+
+	a.running++
+	if !ticket.resumed {
+		a.admitted++
+		a.totalWait += ticket.wait
+		a.maxWait = max(a.maxWait, ticket.wait)
+	}
+	return ticket
+}
+
+// remove withdraws the queued invocation of the thread, it returns false if the thread is not queued.
+func (a *admissionController) remove(threadID string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	ticket, ok := a.queued[threadID]
+	if !ok {
+		return false
+	}
+	heap.Remove(&a.queue, ticket.index)
+	delete(a.queued, threadID)
+	return true
+}
+
+func (a *admissionController) stats() g.AdmissionStats {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	return g.AdmissionStats{
+		Running:        a.running,
+		Queued:         len(a.queue),
+		Admitted:       a.admitted,
+		Rejected:       a.rejected,
+		TotalQueueWait: a.totalWait,
+		MaxQueueWait:   a.maxWait,
+	}
+}
+
+func (r *runtimeImpl[T]) AdmissionStats() g.AdmissionStats {
+	return r.admission.stats()
+}
+
+// admit starts the invocation when admitted, otherwise it waits in the admission queue.
+func (r *runtimeImpl[T]) admit(userInput T, config g.InvokeConfig) {
+	admitted, err := r.admission.enter(config.ThreadID, config.Priority, func(wait time.Duration) {
+		r.startInvocation(userInput, config, wait)
+	})
+	if err != nil {
+		r.failInvocation("Runtime", config, fmt.Errorf("cannot invoke graph for thread %s: %w", config.ThreadID, err))
+		return
+	}
+	if admitted {
+		r.startInvocation(userInput, config, 0)
+	}
+}
+
+func (r *runtimeImpl[T]) startInvocation(userInput T, config g.InvokeConfig, queueWait time.Duration) {
+	if inv, ok := r.invocations.Load(config.ThreadID); ok && inv.(*invocation).ctx == config.Context {
+		inv.(*invocation).admitted.Store(true)
+	}
+
+	startNode := r.startEdge.From()
+	if err := context.Cause(config.Context); err != nil {
+		r.failInvocation(startNode.Name(), config, fmt.Errorf("invocation context done: %w", limitError(startNode.Name(), config.ThreadID, err)))
+		return
+	}
+
+	if point := r.resumePointOf(config); point != nil {
+		r.logger.Info("invocation resumed", logAttrThreadID, config.ThreadID, logAttrNode, point.node.Name(), "queue_wait", queueWait)
+		if !point.wakeAt.IsZero() {
+			r.sleepUntil(point.node, point.edge, userInput, config, point.wakeAt)
+			return
+		}
+		r.enterNode(point.node, point.edge, userInput, config)
+		return
+	}
+	entryEdge := r.graphOf(config).entryEdge(config.EntryPoint)
+	if entryEdge == nil {
+		r.failInvocation(startNode.Name(), config, fmt.Errorf("cannot enter the graph through %s: %w", config.EntryPoint, g.ErrUnknownEntryPoint))
+		return
+	}
+	r.logger.Info("invocation started", logAttrThreadID, config.ThreadID, logAttrNode, entryEdge.From().Name(), "queue_wait", queueWait)
+	r.enterNode(entryEdge.From(), entryEdge, userInput, config)
+}
+
+// releaseAdmission frees the slot of a terminated invocation, admitting the next queued one.
+func (r *runtimeImpl[T]) releaseAdmission() {
+	if next := r.admission.leave(); next != nil {
+		next.start(next.wait)
+	}
+}
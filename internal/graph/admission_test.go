@@ -0,0 +1,148 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// TestRuntime_Admission_Priority tests that the queued invocations are admitted by priority, then by arrival
+func TestRuntime_Admission_Priority(t *testing.T) {
+	runtime, stateMonitorCh, started, release := newBlockingTestRuntime(t, &g.RuntimeOptions[RuntimeTestState]{Settings: g.RuntimeSettings{MaxConcurrentInvocations: 1}})
+
+	runtime.Invoke(RuntimeTestState{Value: "first"}, g.InvokeConfigThreadID("first"))
+	if value := waitStarted(t, started); value != "first" {
+		t.Fatalf("Expected the first invocation to start, got %s", value)
+	}
+
+	runtime.Invoke(RuntimeTestState{Value: "low"}, g.InvokeConfigThreadID("low"))
+	runtime.Invoke(RuntimeTestState{Value: "normal"}, g.InvokeConfigThreadID("normal"))
+	runtime.Invoke(RuntimeTestState{Value: "high"}, g.InvokeConfigThreadID("high"), g.InvokeConfigPriority(5))
+	runtime.Invoke(RuntimeTestState{Value: "lowest"}, g.InvokeConfigThreadID("lowest"), g.InvokeConfigPriority(-1))
+
+	stats := runtime.AdmissionStats()
+	if stats.Running != 1 || stats.Queued != 4 {
+		t.Errorf("Expected 1 running and 4 queued invocations, got %+v", stats)
+	}
+
+	select {
+	case value := <-started:
+		t.Fatalf("Expected the queued invocations to wait, %s started", value)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	for _, expected := range []string{"high", "low", "normal", "lowest"} {
+		release <- struct{}{}
+		if entry := waitTerminalEntry(t, stateMonitorCh); entry.Error != nil {
+			t.Fatalf("Unexpected error %v", entry.Error)
+		}
+		if value := waitStarted(t, started); value != expected {
+			t.Errorf("Expected %s to be admitted, got %s", expected, value)
+		}
+	}
+	release <- struct{}{}
+	waitTerminalEntry(t, stateMonitorCh)
+
+	stats = runtime.AdmissionStats()
+	if stats.Admitted != 5 || stats.Queued != 0 || stats.Running != 0 {
+		t.Errorf("Unexpected final stats %+v", stats)
+	}
+	if stats.MaxQueueWait < 50*time.Millisecond || stats.AverageQueueWait() <= 0 {
+		t.Errorf("Expected the queue wait to be measured, got %+v", stats)
+	}
+}
+
+// TestRuntime_Admission_QueueFull tests that the invocations exceeding the queue are rejected
+func TestRuntime_Admission_QueueFull(t *testing.T) {
+	runtime, stateMonitorCh, started, release := newBlockingTestRuntime(t, &g.RuntimeOptions[RuntimeTestState]{Settings: g.RuntimeSettings{MaxConcurrentInvocations: 1, MaxQueuedInvocations: 1}})
+	defer close(release)
+
+	runtime.Invoke(RuntimeTestState{Value: "running"})
+	waitStarted(t, started)
+	runtime.Invoke(RuntimeTestState{Value: "queued"})
+	rejected := runtime.Invoke(RuntimeTestState{Value: "rejected"})
+
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if entry.ThreadID != rejected || !errors.Is(entry.Error, g.ErrAdmissionQueueFull) {
+		t.Errorf("Expected the last invocation to be rejected, got %+v", entry)
+	}
+	if stats := runtime.AdmissionStats(); stats.Rejected != 1 || stats.Queued != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+// TestRuntime_Admission_CancelQueued tests that a queued invocation is withdrawn by Cancel
+func TestRuntime_Admission_CancelQueued(t *testing.T) {
+	runtime, stateMonitorCh, started, release := newBlockingTestRuntime(t, &g.RuntimeOptions[RuntimeTestState]{Settings: g.RuntimeSettings{MaxConcurrentInvocations: 1}})
+
+	runtime.Invoke(RuntimeTestState{Value: "running"})
+	waitStarted(t, started)
+	queued := runtime.Invoke(RuntimeTestState{Value: "queued"})
+
+	if err := runtime.Cancel(queued); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if entry.ThreadID != queued || !errors.Is(entry.Error, g.ErrThreadCancelled) {
+		t.Errorf("Expected the queued invocation to be cancelled, got %+v", entry)
+	}
+
+	release <- struct{}{}
+	waitTerminalEntry(t, stateMonitorCh)
+	if stats := runtime.AdmissionStats(); stats.Running != 0 || stats.Queued != 0 || stats.Admitted != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+// TestRuntime_Admission_CancelWhileFailing tests that an invocation terminated both by Cancel and by its failing node
+// releases its admission once
+func TestRuntime_Admission_CancelWhileFailing(t *testing.T) {
+	started := make(chan string, 1)
+	release := make(chan struct{})
+	runtime, stateMonitorCh := newNodeTestRuntime(t, func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		started <- userInput.Value
+		<-release
+		return currentState, errors.New("node failure")
+	}, &g.RuntimeOptions[RuntimeTestState]{Settings: g.RuntimeSettings{MaxConcurrentInvocations: 1}})
+	go func() {
+		for range stateMonitorCh {
+		}
+	}()
+
+	const invocations = 1000
+	for range invocations {
+		threadID := runtime.Invoke(RuntimeTestState{Value: "racing"})
+		waitStarted(t, started)
+		inv, _ := runtime.invocations.Load(threadID)
+		useInvocation := inv.(*invocation)
+
+		// The forced release of Cancel races with the termination of the failed node
+		const terminations = 8
+		barrier := make(chan struct{})
+		var wg sync.WaitGroup
+		for idx := range terminations {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				<-barrier
+				if idx%2 == 0 {
+					runtime.releaseInvocation(threadID, useInvocation, g.ErrThreadCancelled)
+					return
+				}
+				runtime.endInvocation(threadID, useInvocation.ctx, errors.New("node failure"))
+			}()
+		}
+		close(barrier)
+		wg.Wait()
+		release <- struct{}{}
+	}
+
+	if stats := runtime.AdmissionStats(); stats.Running != 0 || stats.Admitted != invocations {
+		t.Fatalf("Expected each invocation to release its admission once, got %+v", stats)
+	}
+	close(release)
+}
@@ -0,0 +1,103 @@
+package graph
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// threadAliases maps the human-readable aliases to the threads, in both directions.
+type threadAliases struct {
+	mu       sync.RWMutex
+	byAlias  map[string]string
+	byThread map[string][]string
+}
+
+func newThreadAliases() *threadAliases {
+	return &threadAliases{
+		byAlias:  make(map[string]string),
+		byThread: make(map[string][]string),
+	}
+}
+
+func (a *threadAliases) add(threadID, alias string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if current, ok := a.byAlias[alias]; ok {
+		if current == threadID {
+			return nil
+		}
+		return fmt.Errorf("cannot alias thread %s as %s: %w", threadID, alias, g.ErrThreadAliasInUse)
+	}
+	a.byAlias[alias] = threadID
+	a.byThread[threadID] = append(a.byThread[threadID], alias)
+	sort.Strings(a.byThread[threadID])
+	return nil
+}
+
+func (a *threadAliases) remove(alias string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	threadID, ok := a.byAlias[alias]
+	if !ok {
+		return fmt.Errorf("cannot remove alias %s: %w", alias, g.ErrUnknownThreadAlias)
+	}
+	delete(a.byAlias, alias)
+	remaining := make([]string, 0, len(a.byThread[threadID]))
+	for _, other := range a.byThread[threadID] {
+		if other != alias {
+			remaining = append(remaining, other)
+		}
+	}
+	if len(remaining) == 0 {
+		delete(a.byThread, threadID)
+	} else {
+		a.byThread[threadID] = remaining
+	}
+	return nil
+}
+
+func (a *threadAliases) resolve(alias string) (string, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	threadID, ok := a.byAlias[alias]
+	return threadID, ok
+}
+
+func (a *threadAliases) of(threadID string) []string {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	if aliases, ok := a.byThread[threadID]; ok {
+		return append([]string(nil), aliases...)
+	}
+	return nil
+}
+
+func (r *runtimeImpl[T]) NewThreadID() string {
+	return r.idGenerator()
+}
+
+func (r *runtimeImpl[T]) AliasThread(threadID, alias string) error {
+	if alias == "" {
+		return fmt.Errorf("cannot alias thread %s: %w", threadID, g.ErrThreadAliasEmpty)
+	}
+	return r.aliases.add(threadID, alias)
+}
+
+func (r *runtimeImpl[T]) UnaliasThread(alias string) error {
+	return r.aliases.remove(alias)
+}
+
+func (r *runtimeImpl[T]) ResolveThread(aliasOrID string) (string, error) {
+	if threadID, ok := r.aliases.resolve(aliasOrID); ok {
+		return threadID, nil
+	}
+	if _, ok := r.state.Load(aliasOrID); ok {
+		return aliasOrID, nil
+	}
+	return "", fmt.Errorf("cannot resolve thread %s: %w", aliasOrID, g.ErrUnknownThreadID)
+}
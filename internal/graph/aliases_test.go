@@ -0,0 +1,89 @@
+package graph
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// TestRuntime_IDGenerator tests that the generated thread identifiers come from the configured generator
+func TestRuntime_IDGenerator(t *testing.T) {
+	var sequence atomic.Int64
+	generator := g.PrefixedIDs("acme-", func() string {
+		return strconv.FormatInt(sequence.Add(1), 10)
+	})
+
+	policy, _ := RouterPolicyImplFactory(AnyRoute[RuntimeTestState])
+	startNode := newMockRuntimeNode("StartNode", g.StartNode, nil, policy)
+	node1 := newMockRuntimeNode("Node1", g.IntermediateNode, func(userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		return currentState, nil
+	}, policy)
+	endNode := newMockRuntimeNode("EndNode", g.EndNode, nil, nil)
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime, err := RuntimeFactory(&mockRuntimeEdge{from: startNode, to: node1, role: g.StartEdge}, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{IDGenerator: generator})
+	if err != nil {
+		t.Fatalf("RuntimeFactory failed: %v", err)
+	}
+	defer runtime.Shutdown()
+	runtime.AddEdge(&mockRuntimeEdge{from: node1, to: endNode, role: g.EndEdge})
+
+	if threadID := runtime.Invoke(RuntimeTestState{}); threadID != "acme-1" {
+		t.Errorf("Expected the invocation to use the generator, got %q", threadID)
+	}
+	waitTerminalEntry(t, stateMonitorCh)
+	if threadID := runtime.NewThreadID(); threadID != "acme-2" {
+		t.Errorf("Expected NewThreadID to use the generator, got %q", threadID)
+	}
+	if forkID, err := runtime.Fork("acme-1"); err != nil || forkID != "acme-3" {
+		t.Errorf("Expected the fork to use the generator, got %q: %v", forkID, err)
+	}
+}
+
+// TestRuntime_ThreadAliases tests the naming of the threads
+func TestRuntime_ThreadAliases(t *testing.T) {
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, testNode("Node1", countInput))
+
+	threadID := runtime.Invoke(RuntimeTestState{})
+	waitCompletion(t, stateMonitorCh, 1)
+
+	for _, alias := range []string{"support/ticket-2", "support/ticket-1", "support/ticket-1"} {
+		if err := runtime.AliasThread(threadID, alias); err != nil {
+			t.Fatalf("AliasThread failed: %v", err)
+		}
+	}
+	if err := runtime.AliasThread("other", "support/ticket-1"); !errors.Is(err, g.ErrThreadAliasInUse) {
+		t.Errorf("Expected ErrThreadAliasInUse, got %v", err)
+	}
+	if err := runtime.AliasThread(threadID, ""); !errors.Is(err, g.ErrThreadAliasEmpty) {
+		t.Errorf("Expected ErrThreadAliasEmpty, got %v", err)
+	}
+
+	for _, name := range []string{"support/ticket-1", threadID} {
+		if resolved, err := runtime.ResolveThread(name); err != nil || resolved != threadID {
+			t.Errorf("Expected %s to resolve to %s, got %q: %v", name, threadID, resolved, err)
+		}
+	}
+	info, _ := runtime.DescribeThread(threadID)
+	if !reflect.DeepEqual(info.Aliases, []string{"support/ticket-1", "support/ticket-2"}) {
+		t.Errorf("Expected the sorted aliases of the thread, got %v", info.Aliases)
+	}
+
+	if err := runtime.UnaliasThread("support/ticket-1"); err != nil {
+		t.Fatalf("UnaliasThread failed: %v", err)
+	}
+	if err := runtime.UnaliasThread("support/ticket-1"); !errors.Is(err, g.ErrUnknownThreadAlias) {
+		t.Errorf("Expected ErrUnknownThreadAlias, got %v", err)
+	}
+	if _, err := runtime.ResolveThread("support/ticket-1"); !errors.Is(err, g.ErrUnknownThreadID) {
+		t.Errorf("Expected ErrUnknownThreadID, got %v", err)
+	}
+	if info, _ := runtime.DescribeThread(threadID); strings.Join(info.Aliases, ",") != "support/ticket-2" {
+		t.Errorf("Expected the remaining alias, got %v", info.Aliases)
+	}
+}
@@ -0,0 +1,86 @@
+package graph
+
+import (
+	"fmt"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func (r *runtimeImpl[T]) Analyze() g.Analysis {
+	return g.Analysis{Cycles: r.graph.Load().cycles()}
+}
+
+// cycles returns the cycles closed by the back edges of a depth-first traversal of the nodes.
+func (v *graphVersion[T]) cycles() []g.Cycle {
+	const (
+		unvisited = iota
+		onPath
+		done
+	)
+
+	var rv []g.Cycle
+	status := make(map[g.Node[T]]int)
+	var path []g.Node[T]
+
+	var visit func(node g.Node[T])
+	visit = func(node g.Node[T]) {
+		status[node] = onPath
+		path = append(path, node)
+		for _, edge := range v.edgesFrom(node) {
+			next := edge.To()
+			if next == nil {
+				continue
+			}
+			switch status[next] {
+			case unvisited:
+				visit(next)
+			case onPath:
+				rv = append(rv, cycleFrom(path, next))
+			}
+		}
+		path = path[:len(path)-1]
+		status[node] = done
+	}
+
+	for _, node := range v.nodes() {
+		if status[node] == unvisited {
+			visit(node)
+		}
+	}
+	return rv
+}
+
+func cycleFrom[T g.SharedState](path []g.Node[T], start g.Node[T]) g.Cycle {
+	var names []string
+	for idx := len(path) - 1; idx >= 0; idx-- {
+		names = append([]string{path[idx].Name()}, names...)
+		if path[idx] == start {
+			break
+		}
+	}
+	return g.Cycle{Nodes: names}
+}
+
+// validateCycles reports the cycles of the graph.
+func (v *graphVersion[T]) validateCycles() []error {
+	var findings []error
+	for _, cycle := range v.cycles() {
+		findings = append(findings, fmt.Errorf("%w: %s", g.ErrCycleDetected, cycle))
+	}
+	return findings
+}
+
+// checkIterations counts the execution of the node within the invocation, failing when it exceeds the maximum.
+func (r *runtimeImpl[T]) checkIterations(node g.Node[T], config g.InvokeConfig) error {
+	if r.maxIterations <= 0 {
+		return nil
+	}
+	inv, ok := r.invocations.Load(config.ThreadID)
+	if !ok || inv.(*invocation).ctx != config.Context {
+		return nil
+	}
+	if visits := inv.(*invocation).visit(node.Name()); visits > r.maxIterations {
+		return &g.RoutingError{Node: node.Name(), ThreadID: config.ThreadID, Code: g.CodeMaxIterations, Err: fmt.Errorf("executed %d times: %w", visits, g.ErrMaxIterationsExceeded)}
+	}
+	return nil
+}
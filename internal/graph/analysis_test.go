@@ -0,0 +1,87 @@
+package graph
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// TestRuntime_Analyze tests that every cycle is reported once, self loops included
+func TestRuntime_Analyze(t *testing.T) {
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+
+	startNode := newMockRuntimeNode("StartNode", g.StartNode, nil, nil)
+	node1 := newMockRuntimeNode("Node1", g.IntermediateNode, nil, nil)
+	node2 := newMockRuntimeNode("Node2", g.IntermediateNode, nil, nil)
+	node3 := newMockRuntimeNode("Node3", g.IntermediateNode, nil, nil)
+	endNode := newMockRuntimeNode("EndNode", g.EndNode, nil, nil)
+
+	runtime, _ := RuntimeFactory(&mockRuntimeEdge{from: startNode, to: node1, role: g.StartEdge}, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{})
+	defer runtime.Shutdown()
+
+	if analysis := runtime.Analyze(); analysis.HasCycles() {
+		t.Errorf("Expected no cycle, got %v", analysis.Cycles)
+	}
+
+	runtime.AddEdge(
+		&mockRuntimeEdge{from: node1, to: node2, role: g.IntermediateEdge},
+		&mockRuntimeEdge{from: node2, to: node3, role: g.IntermediateEdge},
+		&mockRuntimeEdge{from: node3, to: node1, role: g.IntermediateEdge},
+		&mockRuntimeEdge{from: node3, to: node3, role: g.IntermediateEdge},
+		&mockRuntimeEdge{from: node3, to: endNode, role: g.EndEdge},
+	)
+
+	analysis := runtime.Analyze()
+	if len(analysis.Cycles) != 2 {
+		t.Fatalf("Expected 2 cycles, got %v", analysis.Cycles)
+	}
+	if analysis.Cycles[0].String() != "Node1 -> Node2 -> Node3 -> Node1" {
+		t.Errorf("Unexpected first cycle %s", analysis.Cycles[0])
+	}
+	if analysis.Cycles[1].String() != "Node3 -> Node3" {
+		t.Errorf("Unexpected second cycle %s", analysis.Cycles[1])
+	}
+}
+
+// TestRuntime_MaxIterations tests that an invocation looping more than allowed fails
+func TestRuntime_MaxIterations(t *testing.T) {
+	policy, _ := RouterPolicyImplFactory(AnyRoute[RuntimeTestState])
+	opts := &g.NodeOptions[RuntimeTestState]{RoutingPolicy: policy, Reducer: Replacer[RuntimeTestState]}
+
+	startNode, _ := NodeImplFactory(g.StartNode, "StartNode", nil, opts)
+	var executions atomic.Int32
+	loopNode, _ := NodeImplFactory(g.IntermediateNode, "Loop", func(userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		executions.Add(1)
+		return currentState, nil
+	}, opts)
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 100)
+	runtime, err := RuntimeFactory(
+		&mockRuntimeEdge{from: startNode, to: loopNode, role: g.StartEdge},
+		stateMonitorCh,
+		&g.RuntimeOptions[RuntimeTestState]{AllowCycles: true, MaxIterations: 3},
+	)
+	if err != nil {
+		t.Fatalf("RuntimeFactory failed: %v", err)
+	}
+	defer runtime.Shutdown()
+	runtime.AddEdge(&mockRuntimeEdge{from: loopNode, to: loopNode, role: g.IntermediateEdge})
+
+	runtime.Invoke(RuntimeTestState{})
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if !errors.Is(entry.Error, g.ErrMaxIterationsExceeded) {
+		t.Fatalf("Expected ErrMaxIterationsExceeded, got %v", entry.Error)
+	}
+	if entry.Node != "Loop" {
+		t.Errorf("Expected the failure on node Loop, got %s", entry.Node)
+	}
+	if entry.ErrorCode != g.CodeMaxIterations {
+		t.Errorf("Expected code %s, got %s", g.CodeMaxIterations, entry.ErrorCode)
+	}
+
+	if executions.Load() != 3 {
+		t.Errorf("Expected 3 iterations, got %d", executions.Load())
+	}
+}
@@ -0,0 +1,218 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// BatchNodeImplFactory creates a node collecting the inputs of the invocations of the thread until it holds size inputs
+// or the window elapsed, then processing them with batchFn.
+func BatchNodeImplFactory[T g.SharedState](name string, batchFn g.BatchFn[T], size int, window time.Duration, opt *g.NodeOptions[T]) (g.Node[T], error) {
+	if batchFn == nil {
+		return nil, fmt.Errorf("batch node creation error for name %s: %w", name, g.ErrBatchFnNil)
+	}
+	if size <= 0 && window <= 0 {
+		return nil, fmt.Errorf("batch node creation error for name %s: %w", name, g.ErrBatchUnbounded)
+	}
+	node, err := ContextNodeImplFactory(g.IntermediateNode, name, nil, opt)
+	if err != nil {
+		return nil, err
+	}
+	node.(*nodeImpl[T]).batch = batchFn
+	node.(*nodeImpl[T]).batchSize = max(size, 0)
+	node.(*nodeImpl[T]).batchWindow = max(window, 0)
+	return node, nil
+}
+
+func (n *nodeImpl[T]) Batch(ctx context.Context, inputs []T, currentState T) (T, error) {
+	if n.batch == nil {
+		return currentState, nil
+	}
+	return n.batch(ctx, inputs, currentState)
+}
+
+func (n *nodeImpl[T]) BatchLimits() (int, time.Duration) {
+	return n.batchSize, n.batchWindow
+}
+
+// collectingBatch is a batch node collecting the inputs of the thread.
+type collectingBatch[T g.SharedState] struct {
+	node      g.Node[T]
+	batcher   g.Batcher[T]
+	userInput T
+	config    g.InvokeConfig
+	size      int
+
+	mu     sync.Mutex
+	inputs []T
+	closed bool
+	timer  *time.Timer
+}
+
+// add appends the input, it returns the number of inputs collected so far and false if the batch is already closed.
+func (b *collectingBatch[T]) add(input T) (int, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return 0, false
+	}
+	b.inputs = append(b.inputs, input)
+	return len(b.inputs), true
+}
+
+// close stops collecting, returning the collected inputs.
+func (b *collectingBatch[T]) close() []T {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	if b.timer != nil {
+		b.timer.Stop()
+	}
+	return b.inputs
+}
+
+func (b *collectingBatch[T]) full(collected int) bool {
+	return b.size > 0 && collected >= b.size
+}
+
+// startBatch holds the thread on the node collecting its input if the node is a batch one, it returns false otherwise.
+func (r *runtimeImpl[T]) startBatch(node g.Node[T], userInput T, config g.InvokeConfig) bool {
+	batcher, ok := node.(g.Batcher[T])
+	if !ok {
+		return false
+	}
+	size, window := batcher.BatchLimits()
+	if size <= 0 && window <= 0 {
+		return false
+	}
+
+	batch := &collectingBatch[T]{node: node, batcher: batcher, userInput: userInput, config: config, size: size, inputs: []T{r.clone(userInput)}}
+	if batch.full(1) {
+		r.processBatch(batch, batch.close())
+		return true
+	}
+
+	// The collecting thread waits without its slots, the other invocations run meanwhile.
+	r.suspendInvocation(config)
+	// The inputs of the next invocations wait for the batch to be fully started.
+	batch.mu.Lock()
+	defer batch.mu.Unlock()
+	r.batches.Store(config.ThreadID, batch)
+	r.logger.Info("invocation collecting batch", logAttrThreadID, config.ThreadID, logAttrNode, node.Name(), "size", size, "window", window)
+	r.sendBatchedEntry(batch, 1)
+	if window > 0 {
+		batch.timer = time.AfterFunc(window, func() {
+			r.flushBatch(config.ThreadID, batch)
+		})
+	}
+	return true
+}
+
+// addToBatch adds the input to the batch collected by the thread, it returns false if the thread holds no batch node.
+func (r *runtimeImpl[T]) addToBatch(userInput T, config g.InvokeConfig) bool {
+	value, ok := r.batches.Load(config.ThreadID)
+	if !ok {
+		return false
+	}
+	batch := value.(*collectingBatch[T])
+	collected, ok := batch.add(r.clone(userInput))
+	if !ok {
+		return false
+	}
+	r.logger.Debug("input added to batch", logAttrThreadID, config.ThreadID, logAttrNode, batch.node.Name(), "batched", collected)
+	r.sendBatchedEntry(batch, collected)
+	if batch.full(collected) {
+		r.flushBatch(config.ThreadID, batch)
+	}
+	return true
+}
+
+func (r *runtimeImpl[T]) sendBatchedEntry(batch *collectingBatch[T], collected int) {
+	entry := monitorRunning(batch.node.Name(), batch.config.ThreadID, r.committedState(batch.config.ThreadID))
+	entry.Batched = collected
+	r.sendMonitorEntry(entry)
+}
+
+// flushBatch processes the collected inputs, unless the batch was already flushed or aborted.
+func (r *runtimeImpl[T]) flushBatch(threadID string, batch *collectingBatch[T]) {
+	if !r.batches.CompareAndDelete(threadID, batch) {
+		return
+	}
+	r.processBatch(batch, batch.close())
+}
+
+// processBatch executes the batch function on the worker pool once the invocation is re-admitted, then the invocation
+// goes on with its update.
+func (r *runtimeImpl[T]) processBatch(batch *collectingBatch[T], inputs []T) {
+	r.resumeInvocation(batch.config, func() { r.executeBatch(batch, inputs) })
+}
+
+// executeBatch executes the batch function on the worker pool.
+func (r *runtimeImpl[T]) executeBatch(batch *collectingBatch[T], inputs []T) {
+	config := batch.config
+	node := batch.node
+	r.Submit(func() {
+		if err := context.Cause(config.Context); err != nil {
+			r.failInvocation(node.Name(), config, fmt.Errorf("invocation context done: %w", limitError(node.Name(), config.ThreadID, err)))
+			return
+		}
+		r.logger.Info("batch flushed", logAttrThreadID, config.ThreadID, logAttrNode, node.Name(), "batched", len(inputs))
+		var reducer g.ReducerFn[T]
+		if reducing, ok := node.(reducing[T]); ok {
+			reducer = reducing.reducerFn()
+		}
+		r.recordNodeStart(config.ThreadID, node.Name())
+		update, err := r.batchUpdate(batch.batcher, inputs, config)
+		if err != nil {
+			r.NotifyStateChange(node, config, batch.userInput, update, reducer, &g.NodeError{Node: node.Name(), ThreadID: config.ThreadID, Code: g.CodeNodeFailed, Err: limitError(node.Name(), config.ThreadID, err)}, false)
+			return
+		}
+		r.NotifyStateChange(node, config, batch.userInput, update, reducer, nil, false)
+	})
+}
+
+func (r *runtimeImpl[T]) batchUpdate(batcher g.Batcher[T], inputs []T, config g.InvokeConfig) (update T, err error) {
+	currentState := r.committedState(config.ThreadID)
+	defer func() {
+		if rec := recover(); rec != nil {
+			update, err = currentState, fmt.Errorf("batch function panicked: %v", rec)
+		}
+	}()
+	return batcher.Batch(invocationContext(config), inputs, currentState)
+}
+
+func (r *runtimeImpl[T]) Batched(threadID string) (int, bool) {
+	value, ok := r.batches.Load(threadID)
+	if !ok {
+		return 0, false
+	}
+	batch := value.(*collectingBatch[T])
+	batch.mu.Lock()
+	defer batch.mu.Unlock()
+	return len(batch.inputs), true
+}
+
+func (r *runtimeImpl[T]) Flush(threadID string) error {
+	value, ok := r.batches.Load(threadID)
+	if !ok {
+		return fmt.Errorf("cannot flush thread %s: %w", threadID, g.ErrThreadNotBatching)
+	}
+	r.flushBatch(threadID, value.(*collectingBatch[T]))
+	return nil
+}
+
+// abortBatching terminates the invocation of the thread if it collects a batch, it returns false otherwise.
+func (r *runtimeImpl[T]) abortBatching(threadID string, cause error) bool {
+	value, ok := r.batches.LoadAndDelete(threadID)
+	if !ok {
+		return false
+	}
+	batch := value.(*collectingBatch[T])
+	batch.close()
+	r.failInvocation(batch.node.Name(), batch.config, fmt.Errorf("invocation of thread %s collecting a batch aborted: %w", threadID, cause))
+	return true
+}
@@ -0,0 +1,119 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// joinInputs is a batch function joining the values of the inputs and counting them, it panics on the "panic" input.
+func joinInputs(ctx context.Context, inputs []RuntimeTestState, currentState RuntimeTestState) (RuntimeTestState, error) {
+	values := make([]string, 0, len(inputs))
+	for _, input := range inputs {
+		if input.Value == "panic" {
+			panic("unexpected input")
+		}
+		values = append(values, input.Value)
+	}
+	currentState.Value = strings.Join(values, ",")
+	currentState.Counter = len(inputs)
+	return currentState, nil
+}
+
+// respondTo is a node function prefixing the value of the state.
+func respondTo(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+	currentState.Value = "responded to " + currentState.Value
+	return currentState, nil
+}
+
+// TestRuntime_BatchSize tests that the batch node goes on once it collected the inputs of as many invocations as its size
+func TestRuntime_BatchSize(t *testing.T) {
+	collectNode, _ := BatchNodeImplFactory("Collect", joinInputs, 3, 0, testNodeOptions())
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 20)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, collectNode, testNode("Respond", respondTo))
+
+	if err := runtime.Flush("unknown"); !errors.Is(err, g.ErrThreadNotBatching) {
+		t.Errorf("Expected ErrThreadNotBatching, got %v", err)
+	}
+
+	threadID := runtime.Invoke(RuntimeTestState{Value: "a"})
+	waitBatchedEntry(t, stateMonitorCh, 1)
+	runtime.Invoke(RuntimeTestState{Value: "b"}, g.InvokeConfig{ThreadID: threadID})
+	waitBatchedEntry(t, stateMonitorCh, 2)
+	if batched, ok := runtime.Batched(threadID); !ok || batched != 2 {
+		t.Errorf("Expected 2 batched inputs, got %d, %v", batched, ok)
+	}
+	runtime.Invoke(RuntimeTestState{Value: "c"}, g.InvokeConfig{ThreadID: threadID})
+
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if entry.Error != nil || entry.NewState.Value != "responded to a,b,c" || entry.NewState.Counter != 3 {
+		t.Errorf("Expected the three inputs to be processed at once, got %+v", entry)
+	}
+	if _, ok := runtime.Batched(threadID); ok {
+		t.Error("Expected the thread not to batch anymore")
+	}
+}
+
+// TestRuntime_BatchWindowAndFlush tests the batches processed when the window elapses or when flushed
+func TestRuntime_BatchWindowAndFlush(t *testing.T) {
+	collectNode, _ := BatchNodeImplFactory("Collect", joinInputs, 0, 50*time.Millisecond, testNodeOptions())
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 20)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, collectNode, testNode("Respond", respondTo))
+	threadID := runtime.Invoke(RuntimeTestState{Value: "a"})
+	waitBatchedEntry(t, stateMonitorCh, 1)
+	runtime.Invoke(RuntimeTestState{Value: "b"}, g.InvokeConfig{ThreadID: threadID})
+	if entry := waitTerminalEntry(t, stateMonitorCh); entry.Error != nil || entry.NewState.Value != "responded to a,b" {
+		t.Errorf("Expected the inputs collected within the window to be processed, got %+v", entry)
+	}
+
+	collectNode, _ = BatchNodeImplFactory("Collect", joinInputs, 10, 0, testNodeOptions())
+	stateMonitorCh = make(chan g.StateMonitorEntry[RuntimeTestState], 20)
+	runtime = newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, collectNode, testNode("Respond", respondTo))
+	threadID = runtime.Invoke(RuntimeTestState{Value: "a"})
+	waitBatchedEntry(t, stateMonitorCh, 1)
+	if err := runtime.Flush(threadID); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if entry := waitTerminalEntry(t, stateMonitorCh); entry.Error != nil || entry.NewState.Value != "responded to a" {
+		t.Errorf("Expected the flushed input to be processed, got %+v", entry)
+	}
+}
+
+// TestRuntime_BatchFailures tests the batch nodes failing, cancelled or misconfigured
+func TestRuntime_BatchFailures(t *testing.T) {
+	collectNode, _ := BatchNodeImplFactory("Collect", joinInputs, 2, 0, testNodeOptions())
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 20)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, collectNode, testNode("Respond", respondTo))
+	threadID := runtime.Invoke(RuntimeTestState{Value: "a"})
+	waitBatchedEntry(t, stateMonitorCh, 1)
+	runtime.Invoke(RuntimeTestState{Value: "panic"}, g.InvokeConfig{ThreadID: threadID})
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	var nodeErr *g.NodeError
+	if !errors.As(entry.Error, &nodeErr) || nodeErr.Code != g.CodeNodeFailed || entry.Node != "Collect" {
+		t.Errorf("Expected the panicking batch function to fail the node, got %+v", entry)
+	}
+
+	threadID = runtime.Invoke(RuntimeTestState{Value: "a"})
+	waitBatchedEntry(t, stateMonitorCh, 1)
+	if err := runtime.Cancel(threadID); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if entry := waitTerminalEntry(t, stateMonitorCh); !errors.Is(entry.Error, g.ErrThreadCancelled) {
+		t.Errorf("Expected the batching invocation to be cancelled, got %+v", entry)
+	}
+
+	opts := &g.NodeOptions[RuntimeTestState]{}
+	batchFn := func(ctx context.Context, inputs []RuntimeTestState, currentState RuntimeTestState) (RuntimeTestState, error) {
+		return currentState, nil
+	}
+	if _, err := BatchNodeImplFactory[RuntimeTestState]("Collect", nil, 2, 0, opts); !errors.Is(err, g.ErrBatchFnNil) {
+		t.Errorf("Expected ErrBatchFnNil, got %v", err)
+	}
+	if _, err := BatchNodeImplFactory("Collect", batchFn, 0, 0, opts); !errors.Is(err, g.ErrBatchUnbounded) {
+		t.Errorf("Expected ErrBatchUnbounded, got %v", err)
+	}
+}
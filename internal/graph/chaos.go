@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// chaosMonkey draws the faults injected by the runtime, a nil chaosMonkey injects nothing.
+type chaosMonkey struct {
+	mu     sync.Mutex
+	config g.Chaos
+	random *rand.Rand
+}
+
+func newChaosMonkey(config *g.Chaos) *chaosMonkey {
+	if config == nil {
+		return nil
+	}
+	return &chaosMonkey{config: *config, random: rand.New(rand.NewSource(config.Seed))}
+}
+
+func (c *chaosMonkey) roll(rate float64) bool {
+	if c == nil || rate <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.random.Float64() < rate
+}
+
+// nodeFault returns the error replacing the execution of the node, nil to execute it.
+func (c *chaosMonkey) nodeFault(node, threadID string) error {
+	if c == nil || (len(c.config.Nodes) > 0 && !slices.Contains(c.config.Nodes, node)) {
+		return nil
+	}
+	if !c.roll(c.config.NodeErrorRate) {
+		return nil
+	}
+	return &g.NodeError{Node: node, ThreadID: threadID, Code: g.CodeInjectedFault, Err: g.ErrInjectedFault}
+}
+
+func (c *chaosMonkey) dropMonitorEntry() bool {
+	return c != nil && c.roll(c.config.MonitorDropRate)
+}
+
+func (c *chaosMonkey) evictThread() bool {
+	return c != nil && c.roll(c.config.EvictionRate)
+}
+
+// delayPersistence wraps the persistence function, delaying every write unless ctx is done first.
+func delayPersistence[T g.SharedState](c *chaosMonkey, persistFn g.PersistFn[T]) g.PersistFn[T] {
+	if c == nil || c.config.PersistenceDelay <= 0 || persistFn == nil {
+		return persistFn
+	}
+	delay := c.config.PersistenceDelay
+	return func(ctx context.Context, threadID string, state T) error {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			return fmt.Errorf("delayed persistence of thread %s: %w", threadID, ctx.Err())
+		}
+		return persistFn(ctx, threadID, state)
+	}
+}
@@ -0,0 +1,88 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// TestRuntime_Chaos_NodeError tests that an injected node error fails the invocation at the targeted node
+func TestRuntime_Chaos_NodeError(t *testing.T) {
+	runtime, stateMonitorCh := newNodeTestRuntime(t, countInput, &g.RuntimeOptions[RuntimeTestState]{Chaos: &g.Chaos{NodeErrorRate: 1, Nodes: []string{"Node1"}}})
+
+	runtime.Invoke(RuntimeTestState{Value: "input"})
+
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if entry.Node != "Node1" || !errors.Is(entry.Error, g.ErrInjectedFault) {
+		t.Errorf("Expected Node1 to fail with ErrInjectedFault, got %s: %v", entry.Node, entry.Error)
+	}
+	if entry.ErrorCode != g.CodeInjectedFault {
+		t.Errorf("Expected code %s, got %s", g.CodeInjectedFault, entry.ErrorCode)
+	}
+}
+
+// TestRuntime_Chaos_MonitorDrop tests that the dropped monitor entries are counted and never delivered
+func TestRuntime_Chaos_MonitorDrop(t *testing.T) {
+	runtime, stateMonitorCh := newNodeTestRuntime(t, countInput, &g.RuntimeOptions[RuntimeTestState]{Chaos: &g.Chaos{MonitorDropRate: 1}})
+
+	runtime.Invoke(RuntimeTestState{Value: "input"})
+
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.MonitorStats().Dropped < 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("Expected the 3 entries of the invocation to be dropped, got %+v", runtime.MonitorStats())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(stateMonitorCh) != 0 || runtime.MonitorStats().Delivered != 0 {
+		t.Errorf("Expected no entry to be delivered, got %+v", runtime.MonitorStats())
+	}
+}
+
+// TestRuntime_Chaos_Eviction tests that a thread is evicted as soon as its invocation completes
+func TestRuntime_Chaos_Eviction(t *testing.T) {
+	runtime, stateMonitorCh := newNodeTestRuntime(t, countInput, &g.RuntimeOptions[RuntimeTestState]{Chaos: &g.Chaos{EvictionRate: 1}})
+
+	threadID := runtime.Invoke(RuntimeTestState{Value: "input"})
+
+	if entry := waitTerminalEntry(t, stateMonitorCh); entry.Error != nil || entry.NewState.Counter != 1 {
+		t.Fatalf("Expected the invocation to complete, got %+v", entry)
+	}
+	select {
+	case entry := <-stateMonitorCh:
+		if !errors.Is(entry.Error, g.ErrEvictionByInactivity) || entry.ThreadID != threadID {
+			t.Errorf("Expected the eviction of the thread, got %+v", entry)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Test timed out waiting for the eviction")
+	}
+	if state := runtime.CurrentState(threadID); state.Counter != 0 {
+		t.Errorf("Expected the state of the evicted thread to be forgotten, got %+v", state)
+	}
+}
+
+// TestDelayPersistence tests that the writes are delayed and interrupted by their context
+func TestDelayPersistence(t *testing.T) {
+	writes := 0
+	persistFn := delayPersistence(newChaosMonkey(&g.Chaos{PersistenceDelay: 50 * time.Millisecond}), func(context.Context, string, RuntimeTestState) error {
+		writes++
+		return nil
+	})
+
+	start := time.Now()
+	if err := persistFn(context.Background(), "thread", RuntimeTestState{}); err != nil || writes != 1 {
+		t.Fatalf("Expected the state to be written, got %d writes: %v", writes, err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected the write to be delayed, took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := persistFn(ctx, "thread", RuntimeTestState{}); !errors.Is(err, context.Canceled) || writes != 1 {
+		t.Errorf("Expected the write to be interrupted, got %d writes: %v", writes, err)
+	}
+}
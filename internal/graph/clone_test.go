@@ -0,0 +1,51 @@
+package graph
+
+import (
+	"context"
+	"maps"
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// TestRuntime_CloneFn tests that the states handed out by the runtime are snapshots
+func TestRuntime_CloneFn(t *testing.T) {
+	cloneFn := func(state RuntimeTestState) RuntimeTestState {
+		state.Data = maps.Clone(state.Data)
+		return state
+	}
+
+	runtime, stateMonitorCh := newNodeTestRuntime(t, func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		if currentState.Data == nil {
+			currentState.Data = map[string]any{}
+		}
+		currentState.Data["step"] = "node"
+		return currentState, nil
+	}, &g.RuntimeOptions[RuntimeTestState]{
+		CloneFn: cloneFn,
+		Hooks: g.Hooks[RuntimeTestState]{
+			OnComplete: func(ctx context.Context, threadID, node string, state RuntimeTestState) {
+				state.Data["step"] = "hook"
+			},
+		},
+	})
+
+	threadID := runtime.Invoke(RuntimeTestState{})
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if entry.Error != nil {
+		t.Fatalf("Unexpected error %v", entry.Error)
+	}
+	if entry.NewState.Data["step"] != "node" {
+		t.Fatalf("Expected the state of the node, got %v", entry.NewState.Data)
+	}
+	entry.NewState.Data["step"] = "observer"
+
+	snapshot := runtime.CurrentState(threadID)
+	if snapshot.Data["step"] != "node" {
+		t.Errorf("Expected the observers not to alter the runtime state, got %v", snapshot.Data)
+	}
+	snapshot.Data["step"] = "caller"
+	if runtime.CurrentState(threadID).Data["step"] != "node" {
+		t.Error("Expected CurrentState to return a snapshot")
+	}
+}
@@ -0,0 +1,130 @@
+package graph
+
+import (
+	"fmt"
+	"reflect"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// stateBase is the state a node was dispatched with.
+type stateBase struct {
+	version uint64
+	state   any
+}
+
+// dispatched records the state the node is dispatched with, when the conflict detection is enabled.
+func (r *runtimeImpl[T]) dispatched(node g.Node[T], config g.InvokeConfig) {
+	if r.conflictResolver == nil {
+		return
+	}
+	inv, ok := r.invocations.Load(config.ThreadID)
+	if !ok || inv.(*invocation).ctx != config.Context {
+		return
+	}
+	inv.(*invocation).recordBase(node.Name(), stateBase{
+		version: r.stateVersion(config.ThreadID),
+		state:   r.committedState(config.ThreadID),
+	})
+}
+
+// reduce reduces the change of the node into the thread state, mediating the conflicting writes.
+func (r *runtimeImpl[T]) reduce(result nodeFnReturnStruct[T]) (T, error) {
+	useThreadID := result.config.ThreadID
+	if r.conflictResolver == nil {
+		return r.replace(useThreadID, result.stateChange, result.reducer), nil
+	}
+
+	conflict, ok := r.detectConflict(result)
+	if !ok {
+		return r.replace(useThreadID, result.stateChange, result.reducer), nil
+	}
+
+	r.reportNonFatal(result.node.Name(), useThreadID, conflict.Err())
+	resolved, err := r.conflictResolver(conflict)
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("conflict resolution failed for node %s: %w", result.node.Name(), err)
+	}
+
+	r.state.Store(useThreadID, resolved)
+	r.bumpStateVersion(useThreadID)
+	return resolved, nil
+}
+
+// detectConflict compares the state the node was dispatched with to the current one.
+func (r *runtimeImpl[T]) detectConflict(result nodeFnReturnStruct[T]) (g.Conflict[T], bool) {
+	useThreadID := result.config.ThreadID
+
+	inv, ok := r.invocations.Load(useThreadID)
+	if !ok {
+		return g.Conflict[T]{}, false
+	}
+	base, ok := inv.(*invocation).takeBase(result.node.Name())
+	if !ok {
+		return g.Conflict[T]{}, false
+	}
+
+	version := r.stateVersion(useThreadID)
+	if version == base.version {
+		return g.Conflict[T]{}, false
+	}
+
+	baseState := base.state.(T)
+	currentState := r.committedState(useThreadID)
+	fields, conflicting := conflictingFields(baseState, currentState, result.stateChange)
+	if !conflicting {
+		return g.Conflict[T]{}, false
+	}
+
+	return g.Conflict[T]{
+		ThreadID:       useThreadID,
+		Node:           result.node.Name(),
+		BaseVersion:    base.version,
+		CurrentVersion: version,
+		Base:           baseState,
+		Current:        currentState,
+		Change:         result.stateChange,
+		Fields:         fields,
+		Reducer:        result.reducer,
+	}, true
+}
+
+// conflictingFields returns the exported top level fields changed both by the concurrent writes and by the change.
+//
+// States which are not structs conflict as a whole.
+func conflictingFields[T g.SharedState](base, current, change T) ([]string, bool) {
+	baseValue := reflect.Indirect(reflect.ValueOf(base))
+	currentValue := reflect.Indirect(reflect.ValueOf(current))
+	changeValue := reflect.Indirect(reflect.ValueOf(change))
+	if baseValue.Kind() != reflect.Struct || currentValue.Kind() != reflect.Struct || changeValue.Kind() != reflect.Struct {
+		return nil, !reflect.DeepEqual(base, current) && !reflect.DeepEqual(base, change)
+	}
+
+	var fields []string
+	for idx := range baseValue.NumField() {
+		field := baseValue.Type().Field(idx)
+		if !field.IsExported() {
+			continue
+		}
+		baseField := baseValue.Field(idx).Interface()
+		if !reflect.DeepEqual(baseField, currentValue.Field(idx).Interface()) && !reflect.DeepEqual(baseField, changeValue.Field(idx).Interface()) {
+			fields = append(fields, field.Name)
+		}
+	}
+	return fields, len(fields) > 0
+}
+
+// stateVersion returns the number of writes to the thread state.
+func (r *runtimeImpl[T]) stateVersion(threadID string) uint64 {
+	if meta, ok := r.threadMeta.Load(threadID); ok {
+		return meta.(*threadMeta).stateVersion.Load()
+	}
+	return 0
+}
+
+func (r *runtimeImpl[T]) bumpStateVersion(threadID string) {
+	if meta, ok := r.threadMeta.Load(threadID); ok {
+		meta.(*threadMeta).stateVersion.Add(1)
+	}
+}
@@ -0,0 +1,125 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// collectUntilTerminal returns the conflicts reported before the terminal entry, and the terminal entry.
+func collectUntilTerminal(t *testing.T, stateMonitorCh chan g.StateMonitorEntry[RuntimeTestState]) ([]error, g.StateMonitorEntry[RuntimeTestState]) {
+	var conflicts []error
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case entry := <-stateMonitorCh:
+			if !entry.Running {
+				return conflicts, entry
+			}
+			if errors.Is(entry.Error, g.ErrStateConflict) {
+				conflicts = append(conflicts, entry.Error)
+			}
+		case <-timeout:
+			t.Fatal("Test timed out waiting for a terminal entry")
+		}
+	}
+}
+
+// TestRuntime_Conflict_FirstWriterWins tests that a conflicting change is reported and discarded
+func TestRuntime_Conflict_FirstWriterWins(t *testing.T) {
+	var runtime *runtimeImpl[RuntimeTestState]
+	opts := testNodeOptions()
+	node1, _ := ContextNodeImplFactory(g.IntermediateNode, "Node1", func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		threadID, _ := g.ThreadIDFromContext(ctx)
+		// Simulates a parallel branch writing the state while Node1 is running.
+		runtime.replace(threadID, RuntimeTestState{Value: "concurrent"}, Replacer[RuntimeTestState])
+		return RuntimeTestState{Value: "node1"}, nil
+	}, opts)
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime = newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{ConflictResolver: g.ResolveFirstWriterWins[RuntimeTestState]()}, node1)
+
+	threadID := runtime.Invoke(RuntimeTestState{})
+	conflicts, entry := collectUntilTerminal(t, stateMonitorCh)
+	if entry.Error != nil {
+		t.Fatalf("Expected the invocation to complete, got %v", entry.Error)
+	}
+	if len(conflicts) != 1 {
+		t.Fatalf("Expected one conflict to be reported, got %v", conflicts)
+	}
+	if state := runtime.CurrentState(threadID); state.Value != "concurrent" {
+		t.Errorf("Expected the concurrent write to win, got %q", state.Value)
+	}
+}
+
+// TestRuntime_Conflict_Fail tests that the resolver error fails the invocation
+func TestRuntime_Conflict_Fail(t *testing.T) {
+	var runtime *runtimeImpl[RuntimeTestState]
+	opts := testNodeOptions()
+	node1, _ := ContextNodeImplFactory(g.IntermediateNode, "Node1", func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		threadID, _ := g.ThreadIDFromContext(ctx)
+		// Simulates a parallel branch writing the state while Node1 is running.
+		runtime.replace(threadID, RuntimeTestState{Value: "concurrent", Counter: 1}, Replacer[RuntimeTestState])
+		return RuntimeTestState{Value: "node1", Counter: 2}, nil
+	}, opts)
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime = newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{ConflictResolver: g.ResolveFail[RuntimeTestState]()}, node1)
+
+	runtime.Invoke(RuntimeTestState{})
+	_, entry := collectUntilTerminal(t, stateMonitorCh)
+	if !errors.Is(entry.Error, g.ErrStateConflict) {
+		t.Fatalf("Expected ErrStateConflict, got %v", entry.Error)
+	}
+}
+
+// TestRuntime_Conflict_DisjointFields tests that writes to different fields do not conflict
+func TestRuntime_Conflict_DisjointFields(t *testing.T) {
+	merge := func(currentState, change RuntimeTestState) RuntimeTestState {
+		if change.Value != "" {
+			currentState.Value = change.Value
+		}
+		currentState.Counter += change.Counter
+		return currentState
+	}
+	var runtime *runtimeImpl[RuntimeTestState]
+	opts := testNodeOptions()
+	opts.Reducer = merge
+	node1, _ := ContextNodeImplFactory(g.IntermediateNode, "Node1", func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		threadID, _ := g.ThreadIDFromContext(ctx)
+		// Simulates a parallel branch writing the state while Node1 is running.
+		runtime.replace(threadID, RuntimeTestState{Counter: 5}, merge)
+		return RuntimeTestState{Value: "node1"}, nil
+	}, opts)
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime = newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{ConflictResolver: g.ResolveFail[RuntimeTestState]()}, node1)
+
+	threadID := runtime.Invoke(RuntimeTestState{})
+	conflicts, entry := collectUntilTerminal(t, stateMonitorCh)
+	if entry.Error != nil || len(conflicts) != 0 {
+		t.Fatalf("Expected no conflict, got %v and %v", entry.Error, conflicts)
+	}
+	if state := runtime.CurrentState(threadID); state.Value != "node1" || state.Counter != 5 {
+		t.Errorf("Expected both writes to be merged, got %+v", state)
+	}
+}
+
+// TestConflictingFields tests the detection of the fields changed on both sides
+func TestConflictingFields(t *testing.T) {
+	base := RuntimeTestState{Value: "base", Counter: 1}
+
+	fields, ok := conflictingFields(base, RuntimeTestState{Value: "a", Counter: 2}, RuntimeTestState{Value: "b", Counter: 1})
+	if !ok || !slices.Equal(fields, []string{"Value"}) {
+		t.Errorf("Expected Value to conflict, got %v", fields)
+	}
+
+	if _, ok := conflictingFields(base, RuntimeTestState{Value: "base", Counter: 2}, RuntimeTestState{Value: "b", Counter: 1}); ok {
+		t.Error("Expected disjoint changes not to conflict")
+	}
+
+	if _, ok := conflictingFields("base", "a", "b"); !ok {
+		t.Error("Expected non struct states to conflict as a whole")
+	}
+}
@@ -0,0 +1,475 @@
+package graph
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// ChannelDeadLetterQueueFactory creates a bounded in-process DeadLetterQueue.
+func ChannelDeadLetterQueueFactory[T g.SharedState](size int) (g.DeadLetterQueue[T], error) {
+	if size < 1 {
+		return nil, fmt.Errorf("dead-letter queue creation failed: %w", g.ErrInvalidDeadLetterQueueSize)
+	}
+	return &chanDeadLetterQueue[T]{
+		entries: make(chan g.DeadLetter[T], size),
+	}, nil
+}
+
+// MemoryDeadLetterQueueFactory creates a DeadLetterQueue storing the failed states into the given Memory.
+//
+// The retry metadata of the entries is indexed in the scratchpad of the memory, when it is a ScratchStore,
+// and loaded back so that the entries survive a restart; the errors of the loaded entries carry the message
+// of the original one only.
+func MemoryDeadLetterQueueFactory[T g.SharedState](memory g.Memory[T], size int) (g.DeadLetterQueue[T], error) {
+	if memory == nil {
+		return nil, fmt.Errorf("dead-letter queue creation failed: %w", g.ErrDeadLetterMemoryNil)
+	}
+	if size < 1 {
+		return nil, fmt.Errorf("dead-letter queue creation failed: %w", g.ErrInvalidDeadLetterQueueSize)
+	}
+	index := scratchStoreOf(memory)
+	if index == nil {
+		index = MemScratchStoreFactory()
+	}
+	deleter, _ := memory.(g.StateDeleter)
+	rv := &memoryDeadLetterQueue[T]{
+		persistFn: memory.PersistFn(),
+		restoreFn: memory.RestoreFn(),
+		deleter:   deleter,
+		index:     index,
+		size:      size,
+
+		persistedSeqs: make(map[string]uint64),
+		mu:            &sync.Mutex{},
+	}
+	if err := rv.load(context.Background()); err != nil {
+		return nil, fmt.Errorf("dead-letter queue creation failed: %w", err)
+	}
+	return rv, nil
+}
+
+// ------------------------------------------------------------------------------
+// Channel Dead-Letter Queue Implementation
+// ------------------------------------------------------------------------------
+
+var _ g.DeadLetterQueue[g.SharedState] = (*chanDeadLetterQueue[g.SharedState])(nil)
+
+type chanDeadLetterQueue[T g.SharedState] struct {
+	entries chan g.DeadLetter[T]
+}
+
+func (q *chanDeadLetterQueue[T]) Push(ctx context.Context, entry g.DeadLetter[T]) error {
+	select {
+	case q.entries <- entry:
+		return nil
+	default:
+		return g.ErrDeadLetterQueueFull
+	}
+}
+
+func (q *chanDeadLetterQueue[T]) Pop(ctx context.Context) (g.DeadLetter[T], bool, error) {
+	select {
+	case entry := <-q.entries:
+		return entry, true, nil
+	default:
+		return g.DeadLetter[T]{}, false, nil
+	}
+}
+
+func (q *chanDeadLetterQueue[T]) Len() int {
+	return len(q.entries)
+}
+
+// ------------------------------------------------------------------------------
+// Memory Dead-Letter Queue Implementation
+// ------------------------------------------------------------------------------
+
+var _ g.DeadLetterQueue[g.SharedState] = (*memoryDeadLetterQueue[g.SharedState])(nil)
+
+var _ deadLetterTrackerStore = (*memoryDeadLetterQueue[g.SharedState])(nil)
+
+const (
+	// deadLetterIndexThread is the thread whose scratchpad indexes the entries of the memory-backed dead-letter queues.
+	deadLetterIndexThread = "ggraph:dead-letters"
+	// deadLetterPersistedPrefix prefixes the index keys of the last successful persistence of the threads with entries.
+	deadLetterPersistedPrefix = "persisted:"
+)
+
+type memoryDeadLetterQueue[T g.SharedState] struct {
+	persistFn g.PersistFn[T]
+	restoreFn g.RestoreFn[T]
+	// deleter removes the states of the popped entries, they are overwritten with the zero value when nil.
+	deleter g.StateDeleter
+	index   g.ScratchStore
+	size    int
+
+	// entries keeps the retry metadata in order, the states live in the memory.
+	entries []g.DeadLetter[T]
+	// persistedSeqs keeps the sequence of the last successful persistence of the threads with entries.
+	persistedSeqs map[string]uint64
+	mu            *sync.Mutex
+}
+
+// deadLetterRecord is the JSON representation of the retry metadata of an entry, indexed by the key of its state.
+type deadLetterRecord struct {
+	ThreadID      string    `json:"thread_id"`
+	Seq           uint64    `json:"seq"`
+	Err           string    `json:"error,omitempty"`
+	Attempts      int       `json:"attempts"`
+	FirstFailedAt time.Time `json:"first_failed_at"`
+	LastFailedAt  time.Time `json:"last_failed_at"`
+}
+
+func (q *memoryDeadLetterQueue[T]) Push(ctx context.Context, entry g.DeadLetter[T]) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.entries) >= q.size {
+		return g.ErrDeadLetterQueueFull
+	}
+	key := deadLetterKey(entry)
+	if err := q.persistFn(ctx, key, entry.State); err != nil {
+		return fmt.Errorf("cannot store dead letter: %w", err)
+	}
+	record := deadLetterRecord{
+		ThreadID:      entry.ThreadID,
+		Seq:           entry.Seq,
+		Attempts:      entry.Attempts,
+		FirstFailedAt: entry.FirstFailedAt,
+		LastFailedAt:  entry.LastFailedAt,
+	}
+	if entry.Err != nil {
+		record.Err = entry.Err.Error()
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("cannot store dead letter: %w", err)
+	}
+	if err := q.index.SaveScratch(ctx, deadLetterIndexThread, key, data); err != nil {
+		return fmt.Errorf("cannot index dead letter: %w", err)
+	}
+
+	var zero T
+	entry.State = zero
+	q.entries = append(q.entries, entry)
+	return nil
+}
+
+func (q *memoryDeadLetterQueue[T]) Pop(ctx context.Context) (g.DeadLetter[T], bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.entries) == 0 {
+		return g.DeadLetter[T]{}, false, nil
+	}
+	entry := q.entries[0]
+	key := deadLetterKey(entry)
+	state, err := q.restoreFn(ctx, key)
+	if err != nil {
+		return g.DeadLetter[T]{}, false, fmt.Errorf("cannot load dead letter: %w", err)
+	}
+
+	if err := q.remove(ctx, key); err != nil {
+		return g.DeadLetter[T]{}, false, fmt.Errorf("cannot remove dead letter: %w", err)
+	}
+	if err := q.index.DeleteScratch(ctx, deadLetterIndexThread, key); err != nil {
+		// The entry stays in the queue, its state is stored back.
+		return g.DeadLetter[T]{}, false, fmt.Errorf("cannot remove dead letter: %w", errors.Join(err, q.persistFn(ctx, key, state)))
+	}
+
+	q.entries = q.entries[1:]
+	entry.State = state
+	return entry, true, nil
+}
+
+func (q *memoryDeadLetterQueue[T]) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.entries)
+}
+
+// remove deletes the state stored under the key, or overwrites it with the zero value when the memory cannot delete.
+func (q *memoryDeadLetterQueue[T]) remove(ctx context.Context, key string) error {
+	if q.deleter != nil {
+		return q.deleter.Delete(ctx, key)
+	}
+	var zero T
+	return q.persistFn(ctx, key, zero)
+}
+
+// load reads the entries indexed by a previous queue on the same memory, oldest persistence job first.
+func (q *memoryDeadLetterQueue[T]) load(ctx context.Context) error {
+	records, err := q.index.LoadScratch(ctx, deadLetterIndexThread)
+	if err != nil {
+		return fmt.Errorf("cannot load the dead-letter index: %w", err)
+	}
+	for key, data := range records {
+		if threadID, ok := strings.CutPrefix(key, deadLetterPersistedPrefix); ok {
+			var seq uint64
+			if err := json.Unmarshal(data, &seq); err != nil {
+				return fmt.Errorf("cannot decode the persistence of thread %s: %w", threadID, err)
+			}
+			q.persistedSeqs[threadID] = seq
+			continue
+		}
+		var record deadLetterRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return fmt.Errorf("cannot decode dead letter %s: %w", key, err)
+		}
+		entry := g.DeadLetter[T]{
+			ThreadID:      record.ThreadID,
+			Seq:           record.Seq,
+			Attempts:      record.Attempts,
+			FirstFailedAt: record.FirstFailedAt,
+			LastFailedAt:  record.LastFailedAt,
+		}
+		if record.Err != "" {
+			entry.Err = errors.New(record.Err)
+		}
+		q.entries = append(q.entries, entry)
+	}
+	slices.SortFunc(q.entries, func(a, b g.DeadLetter[T]) int {
+		return cmp.Compare(a.Seq, b.Seq)
+	})
+	return nil
+}
+
+func (q *memoryDeadLetterQueue[T]) trackers() map[string]deadLetterTracker {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	rv := make(map[string]deadLetterTracker)
+	for _, entry := range q.entries {
+		tracker := rv[entry.ThreadID]
+		tracker.pending++
+		tracker.persistedSeq = q.persistedSeqs[entry.ThreadID]
+		rv[entry.ThreadID] = tracker
+	}
+	return rv
+}
+
+func (q *memoryDeadLetterQueue[T]) storePersisted(ctx context.Context, threadID string, seq uint64) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if seq <= q.persistedSeqs[threadID] {
+		return nil
+	}
+	data, err := json.Marshal(seq)
+	if err != nil {
+		return err
+	}
+	if err := q.index.SaveScratch(ctx, deadLetterIndexThread, deadLetterPersistedPrefix+threadID, data); err != nil {
+		return fmt.Errorf("cannot index the persistence of thread %s: %w", threadID, err)
+	}
+	q.persistedSeqs[threadID] = seq
+	return nil
+}
+
+func (q *memoryDeadLetterQueue[T]) forgetPersisted(ctx context.Context, threadID string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, ok := q.persistedSeqs[threadID]; !ok {
+		return nil
+	}
+	if err := q.index.DeleteScratch(ctx, deadLetterIndexThread, deadLetterPersistedPrefix+threadID); err != nil {
+		return fmt.Errorf("cannot remove the persistence of thread %s: %w", threadID, err)
+	}
+	delete(q.persistedSeqs, threadID)
+	return nil
+}
+
+func deadLetterKey[T g.SharedState](entry g.DeadLetter[T]) string {
+	return fmt.Sprintf("dead-letter:%s:%d", entry.ThreadID, entry.Seq)
+}
+
+// ------------------------------------------------------------------------------
+// Runtime Dead-Letter Handling
+// ------------------------------------------------------------------------------
+
+// deadLetterTracker keeps, for a thread with pending dead letters, the sequence of its last successful persistence.
+type deadLetterTracker struct {
+	pending      int
+	persistedSeq uint64
+}
+
+// deadLetterTrackerStore is implemented by the dead-letter queues whose entries survive a restart: the runtime
+// rebuilds the trackers of the loaded entries and stores the last successful persistence of their threads, so that
+// a loaded entry never overwrites a newer state.
+type deadLetterTrackerStore interface {
+	// trackers returns the trackers of the threads with entries in the queue.
+	trackers() map[string]deadLetterTracker
+	// storePersisted records the sequence of the last successful persistence of a thread with entries, lower sequences are ignored.
+	storePersisted(ctx context.Context, threadID string, seq uint64) error
+	// forgetPersisted removes the sequence recorded for a thread without entries.
+	forgetPersisted(ctx context.Context, threadID string) error
+}
+
+// loadDeadLetterTrackers rebuilds the trackers of the entries loaded by the dead-letter queue.
+func (r *runtimeImpl[T]) loadDeadLetterTrackers() {
+	store, ok := r.deadLetters.(deadLetterTrackerStore)
+	if !ok {
+		return
+	}
+	for threadID, tracker := range store.trackers() {
+		r.deadLettered[threadID] = &tracker
+	}
+}
+
+func (r *runtimeImpl[T]) ReprocessDeadLetters(ctx context.Context) (int, error) {
+	if r.deadLetters == nil {
+		return 0, g.ErrDeadLetterQueueNotSet
+	}
+	if r.persistFn == nil {
+		return 0, fmt.Errorf("cannot reprocess dead letters: %w", g.ErrPersistNotSet)
+	}
+
+	var errs []error
+	persisted := 0
+	for remaining := r.deadLetters.Len(); remaining > 0; remaining-- {
+		entry, ok, err := r.deadLetters.Pop(ctx)
+		if err != nil {
+			errs = append(errs, err)
+			break
+		}
+		if !ok {
+			break
+		}
+
+		if r.deadLetterSuperseded(entry) {
+			r.releaseDeadLetter(entry.ThreadID, 0)
+			continue
+		}
+
+		if err := r.persistFn(ctx, entry.ThreadID, entry.State); err != nil {
+			entry.Attempts++
+			entry.Err = err
+			entry.LastFailedAt = time.Now()
+			if pushErr := r.deadLetters.Push(ctx, entry); pushErr != nil {
+				r.releaseDeadLetter(entry.ThreadID, 0)
+				r.reportNonFatal("Persistence", entry.ThreadID, fmt.Errorf("dead letter dropped: %w", pushErr))
+			}
+			errs = append(errs, fmt.Errorf("thread %s: %w", entry.ThreadID, err))
+			continue
+		}
+
+		r.releaseDeadLetter(entry.ThreadID, entry.Seq)
+		persisted++
+	}
+
+	if len(errs) > 0 {
+		return persisted, fmt.Errorf("cannot reprocess dead letters: %w", errors.Join(errs...))
+	}
+	return persisted, nil
+}
+
+func (r *runtimeImpl[T]) nextPersistEntry(threadID string, state T) pendingPersistEntry[T] {
+	return pendingPersistEntry[T]{threadID: threadID, state: r.clone(state), seq: r.persistSeq.Add(1)}
+}
+
+func (r *runtimeImpl[T]) deadLetter(entry pendingPersistEntry[T], cause error) {
+	if r.deadLetters == nil {
+		return
+	}
+
+	now := time.Now()
+	letter := g.DeadLetter[T]{
+		ThreadID:      entry.threadID,
+		State:         entry.state,
+		Seq:           entry.seq,
+		Err:           cause,
+		Attempts:      1,
+		FirstFailedAt: now,
+		LastFailedAt:  now,
+	}
+
+	r.deadLetterMu.Lock()
+	tracker, ok := r.deadLettered[entry.threadID]
+	if !ok {
+		tracker = &deadLetterTracker{}
+		r.deadLettered[entry.threadID] = tracker
+	}
+	tracker.pending++
+	r.deadLetterMu.Unlock()
+
+	// The runtime context may already be cancelled while flushing on shutdown.
+	if err := r.deadLetters.Push(context.Background(), letter); err != nil {
+		r.releaseDeadLetter(entry.threadID, 0)
+		r.reportNonFatal("Persistence", entry.threadID, fmt.Errorf("dead letter dropped: %w", err))
+	}
+}
+
+func (r *runtimeImpl[T]) persisted(entry pendingPersistEntry[T]) {
+	if r.deadLetters == nil {
+		return
+	}
+
+	r.deadLetterMu.Lock()
+	var err error
+	if tracker, ok := r.deadLettered[entry.threadID]; ok && entry.seq > tracker.persistedSeq {
+		tracker.persistedSeq = entry.seq
+		err = r.storeDeadLetterTracker(entry.threadID, tracker)
+	}
+	r.deadLetterMu.Unlock()
+
+	if err != nil {
+		r.reportNonFatal("Persistence", entry.threadID, err)
+	}
+}
+
+func (r *runtimeImpl[T]) deadLetterSuperseded(entry g.DeadLetter[T]) bool {
+	r.deadLetterMu.Lock()
+	defer r.deadLetterMu.Unlock()
+	tracker, ok := r.deadLettered[entry.ThreadID]
+	return ok && tracker.persistedSeq > entry.Seq
+}
+
+func (r *runtimeImpl[T]) releaseDeadLetter(threadID string, persistedSeq uint64) {
+	r.deadLetterMu.Lock()
+	tracker, ok := r.deadLettered[threadID]
+	if !ok {
+		r.deadLetterMu.Unlock()
+		return
+	}
+	if persistedSeq > tracker.persistedSeq {
+		tracker.persistedSeq = persistedSeq
+	}
+	tracker.pending--
+	if tracker.pending <= 0 {
+		delete(r.deadLettered, threadID)
+		tracker = nil
+	}
+	err := r.storeDeadLetterTracker(threadID, tracker)
+	r.deadLetterMu.Unlock()
+
+	if err != nil {
+		r.reportNonFatal("Persistence", threadID, err)
+	}
+}
+
+// storeDeadLetterTracker stores the last successful persistence of the thread into the dead-letter queue, when it
+// survives a restart, or forgets it when tracker is nil; deadLetterMu must be held.
+func (r *runtimeImpl[T]) storeDeadLetterTracker(threadID string, tracker *deadLetterTracker) error {
+	store, ok := r.deadLetters.(deadLetterTrackerStore)
+	if !ok {
+		return nil
+	}
+	// The runtime context may already be cancelled while flushing on shutdown.
+	if tracker == nil {
+		return store.forgetPersisted(context.Background(), threadID)
+	}
+	if tracker.persistedSeq == 0 {
+		return nil
+	}
+	return store.storePersisted(context.Background(), threadID, tracker.persistedSeq)
+}
@@ -0,0 +1,296 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+var errFlakyMemory = errors.New("flaky memory")
+
+type flakyMemory struct {
+	failing atomic.Bool
+	mu      sync.Mutex
+	store   map[string]RuntimeTestState
+}
+
+func (m *flakyMemory) PersistFn() g.PersistFn[RuntimeTestState] {
+	return func(ctx context.Context, key string, state RuntimeTestState) error {
+		if m.failing.Load() {
+			return errFlakyMemory
+		}
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.store[key] = state
+		return nil
+	}
+}
+
+func (m *flakyMemory) RestoreFn() g.RestoreFn[RuntimeTestState] {
+	return func(ctx context.Context, key string) (RuntimeTestState, error) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		return m.store[key], nil
+	}
+}
+
+func (m *flakyMemory) stored(key string) (RuntimeTestState, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	state, ok := m.store[key]
+	return state, ok
+}
+
+func waitDeadLetters(t *testing.T, dlq g.DeadLetterQueue[RuntimeTestState], expected int) {
+	t.Helper()
+	waitFor(t, fmt.Sprintf("%d dead letters", expected), func() bool {
+		return dlq.Len() == expected
+	})
+}
+
+// TestRuntime_DeadLetters tests that failed persistence jobs are dead-lettered and can be reprocessed
+func TestRuntime_DeadLetters(t *testing.T) {
+	memory := &flakyMemory{store: map[string]RuntimeTestState{}}
+	memory.failing.Store(true)
+	dlq, err := ChannelDeadLetterQueueFactory[RuntimeTestState](10)
+	if err != nil {
+		t.Fatalf("ChannelDeadLetterQueueFactory failed: %v", err)
+	}
+
+	runtime, stateMonitorCh := newNodeTestRuntime(t, countInput, &g.RuntimeOptions[RuntimeTestState]{Memory: memory, DeadLetterQueue: dlq})
+
+	// The state is persisted after each node: Node1 and EndNode.
+	threadID := runtime.Invoke(RuntimeTestState{Value: "lost"}, g.InvokeConfigThreadID("dead-letter"))
+	waitTerminalEntry(t, stateMonitorCh)
+	waitDeadLetters(t, dlq, 2)
+
+	persisted, err := runtime.ReprocessDeadLetters(context.Background())
+	if !errors.Is(err, errFlakyMemory) {
+		t.Errorf("Expected the memory error, got %v", err)
+	}
+	if persisted != 0 {
+		t.Errorf("Expected no persisted entries, got %d", persisted)
+	}
+
+	entry, ok, _ := dlq.Pop(context.Background())
+	if !ok {
+		t.Fatal("Expected the failed entry to be pushed back")
+	}
+	if entry.ThreadID != threadID || entry.State.Value != "lost" {
+		t.Errorf("Unexpected dead letter: %+v", entry)
+	}
+	if entry.Attempts != 2 {
+		t.Errorf("Expected 2 attempts, got %d", entry.Attempts)
+	}
+	if !errors.Is(entry.Err, errFlakyMemory) {
+		t.Errorf("Expected the memory error, got %v", entry.Err)
+	}
+	if entry.LastFailedAt.Before(entry.FirstFailedAt) {
+		t.Error("Expected LastFailedAt not to precede FirstFailedAt")
+	}
+	// Requeued behind the newer entry, the older one is superseded once the newer is persisted.
+	if err := dlq.Push(context.Background(), entry); err != nil {
+		t.Fatalf("Push failed: %v", err)
+	}
+
+	memory.failing.Store(false)
+	persisted, err = runtime.ReprocessDeadLetters(context.Background())
+	if err != nil {
+		t.Fatalf("ReprocessDeadLetters failed: %v", err)
+	}
+	if persisted != 1 {
+		t.Errorf("Expected 1 persisted entry, got %d", persisted)
+	}
+	if dlq.Len() != 0 {
+		t.Errorf("Expected an empty queue, got %d entries", dlq.Len())
+	}
+	if state, ok := memory.stored(threadID); !ok || state.Value != "lost" {
+		t.Errorf("Expected the dead-lettered state to be persisted, got %+v", state)
+	}
+}
+
+// TestRuntime_DeadLetters_Superseded tests that dead letters never overwrite a newer persisted state
+func TestRuntime_DeadLetters_Superseded(t *testing.T) {
+	memory := &flakyMemory{store: map[string]RuntimeTestState{}}
+	memory.failing.Store(true)
+	dlq, _ := ChannelDeadLetterQueueFactory[RuntimeTestState](10)
+
+	runtime, stateMonitorCh := newNodeTestRuntime(t, countInput, &g.RuntimeOptions[RuntimeTestState]{Memory: memory, DeadLetterQueue: dlq})
+
+	threadID := runtime.Invoke(RuntimeTestState{Value: "old"}, g.InvokeConfigThreadID("superseded"))
+	waitTerminalEntry(t, stateMonitorCh)
+	waitDeadLetters(t, dlq, 2)
+
+	memory.failing.Store(false)
+	runtime.Invoke(RuntimeTestState{Value: "new"}, g.InvokeConfigThreadID(threadID))
+	waitTerminalEntry(t, stateMonitorCh)
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if state, ok := memory.stored(threadID); ok && state.Value == "new" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Expected the new state to be persisted")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	persisted, err := runtime.ReprocessDeadLetters(context.Background())
+	if err != nil {
+		t.Fatalf("ReprocessDeadLetters failed: %v", err)
+	}
+	if persisted != 0 {
+		t.Errorf("Expected the superseded entry to be discarded, got %d persisted", persisted)
+	}
+	if state, _ := memory.stored(threadID); state.Value != "new" {
+		t.Errorf("Expected the new state to be kept, got %q", state.Value)
+	}
+}
+
+// TestRuntime_DeadLetters_Restart tests that the dead letters loaded after a restart are kept apart from the new ones
+// and never overwrite a state persisted before the restart
+func TestRuntime_DeadLetters_Restart(t *testing.T) {
+	memory := &flakyMemory{store: map[string]RuntimeTestState{}}
+	memory.failing.Store(true)
+	dlqMemory := MemMemoryFactory[RuntimeTestState](nil)
+	dlq, _ := MemoryDeadLetterQueueFactory(dlqMemory, 10)
+
+	runtime, stateMonitorCh := newNodeTestRuntime(t, countInput, &g.RuntimeOptions[RuntimeTestState]{Memory: memory, DeadLetterQueue: dlq})
+
+	threadID := runtime.Invoke(RuntimeTestState{Value: "old"}, g.InvokeConfigThreadID("restart"))
+	waitTerminalEntry(t, stateMonitorCh)
+	waitDeadLetters(t, dlq, 2)
+
+	memory.failing.Store(false)
+	runtime.Invoke(RuntimeTestState{Value: "new"}, g.InvokeConfigThreadID(threadID))
+	waitTerminalEntry(t, stateMonitorCh)
+	runtime.Shutdown()
+	if state, _ := memory.stored(threadID); state.Value != "new" {
+		t.Fatalf("Expected the new state to be persisted, got %q", state.Value)
+	}
+
+	restartedDLQ, err := MemoryDeadLetterQueueFactory(dlqMemory, 10)
+	if err != nil {
+		t.Fatalf("MemoryDeadLetterQueueFactory failed: %v", err)
+	}
+	memory.failing.Store(true)
+	restarted, stateMonitorCh := newNodeTestRuntime(t, countInput, &g.RuntimeOptions[RuntimeTestState]{Memory: memory, DeadLetterQueue: restartedDLQ})
+	restarted.Invoke(RuntimeTestState{Value: "after"}, g.InvokeConfigThreadID(threadID))
+	waitTerminalEntry(t, stateMonitorCh)
+	waitDeadLetters(t, restartedDLQ, 4)
+
+	memory.failing.Store(false)
+	persisted, err := restarted.ReprocessDeadLetters(context.Background())
+	if err != nil {
+		t.Fatalf("ReprocessDeadLetters failed: %v", err)
+	}
+	if persisted != 2 {
+		t.Errorf("Expected the 2 entries of the restarted runtime to be persisted and the superseded ones discarded, got %d persisted", persisted)
+	}
+	if state, _ := memory.stored(threadID); state.Value != "after" {
+		t.Errorf("Expected the state of the restarted runtime to be kept, got %q", state.Value)
+	}
+	if _, ok := dlqMemory.(*memMemory[RuntimeTestState]).store[deadLetterPersistedPrefix+threadID]; ok {
+		t.Error("Expected the persistence of the thread to be forgotten")
+	}
+}
+
+// TestRuntime_ReprocessDeadLetters_NotSet tests reprocessing without a dead-letter queue
+func TestRuntime_ReprocessDeadLetters_NotSet(t *testing.T) {
+	runtime, _ := newNodeTestRuntime(t, countInput, &g.RuntimeOptions[RuntimeTestState]{})
+
+	if _, err := runtime.ReprocessDeadLetters(context.Background()); !errors.Is(err, g.ErrDeadLetterQueueNotSet) {
+		t.Errorf("Expected ErrDeadLetterQueueNotSet, got %v", err)
+	}
+}
+
+// TestDeadLetterQueues tests the bounded dead-letter queue implementations
+func TestDeadLetterQueues(t *testing.T) {
+	if _, err := ChannelDeadLetterQueueFactory[RuntimeTestState](0); !errors.Is(err, g.ErrInvalidDeadLetterQueueSize) {
+		t.Errorf("Expected ErrInvalidDeadLetterQueueSize, got %v", err)
+	}
+	if _, err := MemoryDeadLetterQueueFactory[RuntimeTestState](nil, 1); !errors.Is(err, g.ErrDeadLetterMemoryNil) {
+		t.Errorf("Expected ErrDeadLetterMemoryNil, got %v", err)
+	}
+
+	chanQueue, _ := ChannelDeadLetterQueueFactory[RuntimeTestState](1)
+	fallback := &flakyMemory{store: map[string]RuntimeTestState{}}
+	memoryQueue, _ := MemoryDeadLetterQueueFactory[RuntimeTestState](fallback, 1)
+
+	for name, queue := range map[string]g.DeadLetterQueue[RuntimeTestState]{"channel": chanQueue, "memory": memoryQueue} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			if err := queue.Push(ctx, g.DeadLetter[RuntimeTestState]{ThreadID: "t1", Seq: 1, State: RuntimeTestState{Value: "a"}}); err != nil {
+				t.Fatalf("Push failed: %v", err)
+			}
+			if err := queue.Push(ctx, g.DeadLetter[RuntimeTestState]{ThreadID: "t2", Seq: 2}); !errors.Is(err, g.ErrDeadLetterQueueFull) {
+				t.Errorf("Expected ErrDeadLetterQueueFull, got %v", err)
+			}
+
+			entry, ok, err := queue.Pop(ctx)
+			if err != nil || !ok {
+				t.Fatalf("Pop failed: %v", err)
+			}
+			if entry.ThreadID != "t1" || entry.State.Value != "a" {
+				t.Errorf("Unexpected entry: %+v", entry)
+			}
+			if _, ok, _ := queue.Pop(ctx); ok {
+				t.Error("Expected an empty queue")
+			}
+		})
+	}
+
+	if state, ok := fallback.stored("dead-letter:t1:1"); !ok || state.Value != "" {
+		t.Errorf("Expected the popped state to be overwritten in the fallback memory, got %+v", state)
+	}
+}
+
+// TestMemoryDeadLetterQueue_Restart tests that a memory-backed queue resumes the entries indexed in its memory
+func TestMemoryDeadLetterQueue_Restart(t *testing.T) {
+	ctx := context.Background()
+	memory := MemMemoryFactory[RuntimeTestState](nil)
+	queue, _ := MemoryDeadLetterQueueFactory(memory, 10)
+
+	failedAt := time.Now().UTC().Truncate(time.Millisecond)
+	for _, entry := range []g.DeadLetter[RuntimeTestState]{
+		{ThreadID: "t2", Seq: 7, State: RuntimeTestState{Value: "b"}, Err: errFlakyMemory, Attempts: 1, FirstFailedAt: failedAt, LastFailedAt: failedAt},
+		{ThreadID: "t1", Seq: 3, State: RuntimeTestState{Value: "a"}, Err: fmt.Errorf("flush: %w", g.ErrPersistenceQueueFull), Attempts: 2, FirstFailedAt: failedAt, LastFailedAt: failedAt},
+	} {
+		if err := queue.Push(ctx, entry); err != nil {
+			t.Fatalf("Push failed: %v", err)
+		}
+	}
+
+	restarted, err := MemoryDeadLetterQueueFactory(memory, 10)
+	if err != nil {
+		t.Fatalf("MemoryDeadLetterQueueFactory failed: %v", err)
+	}
+	if restarted.Len() != 2 {
+		t.Fatalf("Expected the 2 indexed entries, got %d", restarted.Len())
+	}
+
+	entry, ok, err := restarted.Pop(ctx)
+	if err != nil || !ok {
+		t.Fatalf("Pop failed: %v", err)
+	}
+	if entry.ThreadID != "t1" || entry.Seq != 3 || entry.State.Value != "a" || entry.Attempts != 2 || !entry.LastFailedAt.Equal(failedAt) {
+		t.Errorf("Expected the oldest entry with its retry metadata, got %+v", entry)
+	}
+	if entry.Err == nil || entry.Err.Error() != "flush: "+g.ErrPersistenceQueueFull.Error() {
+		t.Errorf("Expected the message of the error of the entry to be restored, got %v", entry.Err)
+	}
+	if _, ok := memory.(*memMemory[RuntimeTestState]).store["dead-letter:t1:3"]; ok {
+		t.Error("Expected the state of the popped entry to be deleted")
+	}
+
+	if resumed, _ := MemoryDeadLetterQueueFactory(memory, 10); resumed.Len() != 1 {
+		t.Errorf("Expected the popped entry to be removed from the index, got %d entries", resumed.Len())
+	}
+}
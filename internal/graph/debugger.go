@@ -0,0 +1,117 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// pausedNode is a node waiting for Step or Continue before being executed.
+type pausedNode[T g.SharedState] struct {
+	node       g.Node[T]
+	config     g.InvokeConfig
+	breakpoint g.Breakpoint[T]
+}
+
+// enterNode executes the node reached through the edge, unless the invocation has to pause before it.
+func (r *runtimeImpl[T]) enterNode(node g.Node[T], edge g.Edge[T], userInput T, config g.InvokeConfig) {
+	if r.shouldPause(node, config) {
+		paused := &pausedNode[T]{node: node, config: config, breakpoint: g.Breakpoint[T]{Input: r.clone(userInput), Edge: edge}}
+		r.paused.Store(config.ThreadID, paused)
+		r.logger.Info("invocation paused", logAttrThreadID, config.ThreadID, logAttrNode, node.Name())
+
+		breakpoint := paused.breakpoint
+		entry := monitorRunning(node.Name(), config.ThreadID, r.committedState(config.ThreadID))
+		entry.Breakpoint = &breakpoint
+		r.sendMonitorEntry(entry)
+		return
+	}
+	r.executeNode(node, userInput, config)
+}
+
+// executeNode runs the before-node hook and dispatches the node.
+func (r *runtimeImpl[T]) executeNode(node g.Node[T], userInput T, config g.InvokeConfig) {
+	if err := r.beforeNode(node, config); err != nil {
+		r.failInvocation(node.Name(), config, err)
+		return
+	}
+
+	if err := r.chaos.nodeFault(node.Name(), config.ThreadID); err != nil {
+		r.logger.Warn("fault injected: node error", logAttrThreadID, config.ThreadID, logAttrNode, node.Name())
+		r.failInvocation(node.Name(), config, err)
+		return
+	}
+
+	r.dispatched(node, config)
+	r.timeline.nodeStarted(config.ThreadID, node.Name())
+	if r.awaitSignal(node, userInput, config) || r.startBatch(node, userInput, config) {
+		return
+	}
+	if r.isRemote(node) {
+		// The workers report no start, the remote executions are timed from their dispatch.
+		r.recordNodeStart(config.ThreadID, node.Name())
+		r.dispatchRemote(node, userInput, config)
+		return
+	}
+	node.Accept(userInput, r, r, config)
+}
+
+// shouldPause reports whether the invocation pauses before the node: when stepping, or at the breakpoint nodes.
+func (r *runtimeImpl[T]) shouldPause(node g.Node[T], config g.InvokeConfig) bool {
+	if !r.debug {
+		return false
+	}
+	inv, ok := r.invocations.Load(config.ThreadID)
+	if !ok || inv.(*invocation).ctx != config.Context {
+		return false
+	}
+	return inv.(*invocation).stepping.Load() || slices.Contains(r.breakpoints, node.Name())
+}
+
+func (r *runtimeImpl[T]) Step(threadID string) error {
+	return r.resume(threadID, true)
+}
+
+func (r *runtimeImpl[T]) Continue(threadID string) error {
+	return r.resume(threadID, false)
+}
+
+func (r *runtimeImpl[T]) Paused(threadID string) (string, g.Breakpoint[T], bool) {
+	paused, ok := r.paused.Load(threadID)
+	if !ok {
+		return "", g.Breakpoint[T]{}, false
+	}
+	return paused.(*pausedNode[T]).node.Name(), paused.(*pausedNode[T]).breakpoint, true
+}
+
+// resume executes the pending node of the paused thread, stepping tells whether to pause before the next node.
+func (r *runtimeImpl[T]) resume(threadID string, stepping bool) error {
+	paused, ok := r.paused.LoadAndDelete(threadID)
+	if !ok {
+		return fmt.Errorf("cannot resume thread %s: %w", threadID, g.ErrThreadNotPaused)
+	}
+	useNode := paused.(*pausedNode[T])
+	if inv, ok := r.invocations.Load(threadID); ok {
+		inv.(*invocation).stepping.Store(stepping)
+	}
+
+	if err := context.Cause(useNode.config.Context); err != nil {
+		r.failInvocation(useNode.node.Name(), useNode.config, fmt.Errorf("invocation context done: %w", limitError(useNode.node.Name(), useNode.config.ThreadID, err)))
+		return nil
+	}
+	r.executeNode(useNode.node, useNode.breakpoint.Input, useNode.config)
+	return nil
+}
+
+// abortPaused terminates the invocation of the thread if it is paused, it returns false otherwise.
+func (r *runtimeImpl[T]) abortPaused(threadID string, cause error) bool {
+	paused, ok := r.paused.LoadAndDelete(threadID)
+	if !ok {
+		return false
+	}
+	useNode := paused.(*pausedNode[T])
+	r.failInvocation(useNode.node.Name(), useNode.config, fmt.Errorf("paused invocation of thread %s aborted: %w", threadID, cause))
+	return true
+}
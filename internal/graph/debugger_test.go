@@ -0,0 +1,80 @@
+package graph
+
+import (
+	"errors"
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// TestRuntime_Debugger_Step tests that a debugged invocation pauses before every node until continued
+func TestRuntime_Debugger_Step(t *testing.T) {
+	runtime, stateMonitorCh := newNodeTestRuntime(t, countInput, &g.RuntimeOptions[RuntimeTestState]{Debug: true})
+
+	threadID := runtime.Invoke(RuntimeTestState{Value: "input"})
+
+	entry := waitForBreakpoint(t, stateMonitorCh)
+	if entry.Node != "StartNode" || entry.Breakpoint.Edge != runtime.StartEdge() || entry.Breakpoint.Input.Value != "input" {
+		t.Errorf("Expected to pause before StartNode with the start edge, got %s: %+v", entry.Node, entry.Breakpoint)
+	}
+	if node, _, ok := runtime.Paused(threadID); !ok || node != "StartNode" {
+		t.Errorf("Expected the thread to be paused before StartNode, got %s, %v", node, ok)
+	}
+	if err := runtime.Step(threadID); err != nil {
+		t.Fatalf("Step failed: %v", err)
+	}
+
+	entry = waitForBreakpoint(t, stateMonitorCh)
+	if entry.Node != "Node1" || entry.Breakpoint.Edge.From().Name() != "StartNode" || entry.NewState.Counter != 0 {
+		t.Errorf("Expected to pause before Node1, got %s: %+v", entry.Node, entry)
+	}
+	if err := runtime.Continue(threadID); err != nil {
+		t.Fatalf("Continue failed: %v", err)
+	}
+
+	if entry := waitTerminalEntry(t, stateMonitorCh); entry.Error != nil || entry.NewState.Counter != 1 {
+		t.Errorf("Expected the invocation to complete, got %+v", entry)
+	}
+	if err := runtime.Step(threadID); !errors.Is(err, g.ErrThreadNotPaused) {
+		t.Errorf("Expected ErrThreadNotPaused, got %v", err)
+	}
+}
+
+// TestRuntime_Debugger_Breakpoints tests that the invocation pauses only at the breakpoint nodes
+func TestRuntime_Debugger_Breakpoints(t *testing.T) {
+	runtime, stateMonitorCh := newNodeTestRuntime(t, countInput, &g.RuntimeOptions[RuntimeTestState]{Debug: true, Breakpoints: []string{"EndNode"}})
+
+	threadID := runtime.Invoke(RuntimeTestState{Value: "input"})
+
+	entry := waitForBreakpoint(t, stateMonitorCh)
+	if entry.Node != "EndNode" || entry.NewState.Counter != 1 {
+		t.Errorf("Expected to pause before EndNode only, got %s: %+v", entry.Node, entry)
+	}
+	if current := runtime.CurrentState(threadID); current.Counter != 1 {
+		t.Errorf("Expected the state of the paused thread, got %+v", current)
+	}
+	if err := runtime.Continue(threadID); err != nil {
+		t.Fatalf("Continue failed: %v", err)
+	}
+	if entry := waitTerminalEntry(t, stateMonitorCh); entry.Error != nil {
+		t.Errorf("Expected the invocation to complete, got %v", entry.Error)
+	}
+}
+
+// TestRuntime_Debugger_Cancel tests that cancelling a paused thread terminates it immediately
+func TestRuntime_Debugger_Cancel(t *testing.T) {
+	runtime, stateMonitorCh := newNodeTestRuntime(t, countInput, &g.RuntimeOptions[RuntimeTestState]{Debug: true})
+
+	threadID := runtime.Invoke(RuntimeTestState{})
+	waitForBreakpoint(t, stateMonitorCh)
+
+	if err := runtime.Cancel(threadID); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if entry := waitTerminalEntry(t, stateMonitorCh); !errors.Is(entry.Error, g.ErrThreadCancelled) {
+		t.Errorf("Expected ErrThreadCancelled, got %v", entry.Error)
+	}
+	if _, _, ok := runtime.Paused(threadID); ok {
+		t.Error("Expected the thread not to be paused anymore")
+	}
+}
@@ -0,0 +1,133 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// DelayNodeImplFactory creates a node suspending the thread for the duration computed by delayFn, before going on
+// with the state unchanged.
+func DelayNodeImplFactory[T g.SharedState](name string, delayFn g.DelayFn[T], opt *g.NodeOptions[T]) (g.Node[T], error) {
+	if delayFn == nil {
+		return nil, fmt.Errorf("delay node creation error for name %s: %w", name, g.ErrDelayFnNil)
+	}
+	node, err := ContextNodeImplFactory(g.IntermediateNode, name, nil, opt)
+	if err != nil {
+		return nil, err
+	}
+	node.(*nodeImpl[T]).delay = delayFn
+	return node, nil
+}
+
+// sleepingNode is a delay node waiting for its wake-up time before being entered.
+type sleepingNode[T g.SharedState] struct {
+	node      g.Node[T]
+	edge      g.Edge[T]
+	userInput T
+	config    g.InvokeConfig
+	wakeAt    time.Time
+
+	mu    sync.Mutex
+	timer *time.Timer
+}
+
+func (s *sleepingNode[T]) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+}
+
+// wakeAtOf returns the time the thread wakes up before entering the node, zero if the node is not delayed.
+func (r *runtimeImpl[T]) wakeAtOf(node g.Node[T], userInput T, config g.InvokeConfig) (wakeAt time.Time, err error) {
+	delayer, ok := node.(g.Delayer[T])
+	if !ok {
+		return time.Time{}, nil
+	}
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = &g.NodeError{Node: node.Name(), ThreadID: config.ThreadID, Code: g.CodeNodeFailed, Err: fmt.Errorf("delay function panicked: %v", rec)}
+		}
+	}()
+	delay, err := delayer.Delay(invocationContext(config), r.clone(userInput), r.committedState(config.ThreadID))
+	if err != nil {
+		return time.Time{}, &g.NodeError{Node: node.Name(), ThreadID: config.ThreadID, Code: g.CodeNodeFailed, Err: fmt.Errorf("cannot compute the delay: %w", err)}
+	}
+	if delay <= 0 {
+		return time.Time{}, nil
+	}
+	return time.Now().Add(delay), nil
+}
+
+// sleepUntil suspends the thread until the wake-up time, then enters the node; neither a goroutine nor the slots of the
+// invocation are held meanwhile.
+func (r *runtimeImpl[T]) sleepUntil(node g.Node[T], edge g.Edge[T], userInput T, config g.InvokeConfig, wakeAt time.Time) {
+	r.suspendInvocation(config)
+	sleeping := &sleepingNode[T]{node: node, edge: edge, userInput: userInput, config: config, wakeAt: wakeAt}
+	r.sleeping.Store(config.ThreadID, sleeping)
+	r.logger.Info("invocation sleeping", logAttrThreadID, config.ThreadID, logAttrNode, node.Name(), "wake_at", wakeAt)
+
+	entry := monitorRunning(node.Name(), config.ThreadID, r.committedState(config.ThreadID))
+	entry.WakeAt = wakeAt
+	r.sendMonitorEntry(entry)
+
+	sleeping.mu.Lock()
+	defer sleeping.mu.Unlock()
+	sleeping.timer = time.AfterFunc(time.Until(wakeAt), func() {
+		if r.sleeping.CompareAndDelete(config.ThreadID, sleeping) {
+			r.awake(sleeping)
+		}
+	})
+}
+
+// awake enters the node the thread was sleeping before, once re-admitted.
+func (r *runtimeImpl[T]) awake(sleeping *sleepingNode[T]) {
+	if r.ctx.Err() != nil {
+		// The runtime is shut down: the journaled wake-up is honoured by Resume.
+		return
+	}
+	config := sleeping.config
+	if err := context.Cause(config.Context); err != nil {
+		r.failInvocation(sleeping.node.Name(), config, fmt.Errorf("invocation context done: %w", limitError(sleeping.node.Name(), config.ThreadID, err)))
+		return
+	}
+	r.logger.Info("invocation woke up", logAttrThreadID, config.ThreadID, logAttrNode, sleeping.node.Name())
+	r.resumeInvocation(config, func() {
+		r.enterNode(sleeping.node, sleeping.edge, sleeping.userInput, config)
+	})
+}
+
+func (r *runtimeImpl[T]) WakeAt(threadID string) (time.Time, bool) {
+	sleeping, ok := r.sleeping.Load(threadID)
+	if !ok {
+		return time.Time{}, false
+	}
+	return sleeping.(*sleepingNode[T]).wakeAt, true
+}
+
+func (r *runtimeImpl[T]) WakeUp(threadID string) error {
+	sleeping, ok := r.sleeping.LoadAndDelete(threadID)
+	if !ok {
+		return fmt.Errorf("cannot wake up thread %s: %w", threadID, g.ErrThreadNotSleeping)
+	}
+	sleeping.(*sleepingNode[T]).stop()
+	r.awake(sleeping.(*sleepingNode[T]))
+	return nil
+}
+
+// abortSleeping terminates the invocation of the thread if it is sleeping, it returns false otherwise.
+func (r *runtimeImpl[T]) abortSleeping(threadID string, cause error) bool {
+	sleeping, ok := r.sleeping.LoadAndDelete(threadID)
+	if !ok {
+		return false
+	}
+	useNode := sleeping.(*sleepingNode[T])
+	useNode.stop()
+	r.failInvocation(useNode.node.Name(), useNode.config, fmt.Errorf("sleeping invocation of thread %s aborted: %w", threadID, cause))
+	return true
+}
@@ -0,0 +1,165 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func fixedDelay(delay time.Duration) g.DelayFn[RuntimeTestState] {
+	return func(ctx context.Context, userInput, currentState RuntimeTestState) (time.Duration, error) {
+		return delay, nil
+	}
+}
+
+// TestRuntime_Delay tests that the thread sleeps for the computed duration before executing the next node
+func TestRuntime_Delay(t *testing.T) {
+	waitNode, _ := DelayNodeImplFactory("Wait", fixedDelay(100*time.Millisecond), testNodeOptions())
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, waitNode, testNode("Work", countInput))
+
+	started := time.Now()
+	threadID := runtime.Invoke(RuntimeTestState{})
+	entry := waitSleepingEntry(t, stateMonitorCh)
+	if entry.Node != "Wait" {
+		t.Errorf("Expected the Wait node to sleep, got %s", entry.Node)
+	}
+	if wakeAt, ok := runtime.WakeAt(threadID); !ok || !wakeAt.Equal(entry.WakeAt) {
+		t.Errorf("Expected the thread to sleep until %v, got %v, %v", entry.WakeAt, wakeAt, ok)
+	}
+
+	entry = waitTerminalEntry(t, stateMonitorCh)
+	if entry.Error != nil || entry.NewState.Counter != 1 {
+		t.Fatalf("Expected the invocation to complete, got %+v", entry)
+	}
+	if elapsed := time.Since(started); elapsed < 100*time.Millisecond {
+		t.Errorf("Expected the thread to sleep for 100ms, completed in %v", elapsed)
+	}
+	if _, ok := runtime.WakeAt(threadID); ok {
+		t.Error("Expected the thread not to sleep anymore")
+	}
+}
+
+// TestRuntime_DelayWakeUpAndCancel tests the sleeping threads woken up early or cancelled
+func TestRuntime_DelayWakeUpAndCancel(t *testing.T) {
+	waitNode, _ := DelayNodeImplFactory("Wait", fixedDelay(time.Hour), testNodeOptions())
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, waitNode, testNode("Work", countInput))
+
+	if err := runtime.WakeUp("unknown"); !errors.Is(err, g.ErrThreadNotSleeping) {
+		t.Errorf("Expected ErrThreadNotSleeping, got %v", err)
+	}
+
+	threadID := runtime.Invoke(RuntimeTestState{})
+	waitSleepingEntry(t, stateMonitorCh)
+	if err := runtime.WakeUp(threadID); err != nil {
+		t.Fatalf("WakeUp failed: %v", err)
+	}
+	if entry := waitTerminalEntry(t, stateMonitorCh); entry.Error != nil || entry.NewState.Counter != 1 {
+		t.Fatalf("Expected the woken up invocation to complete, got %+v", entry)
+	}
+
+	threadID = runtime.Invoke(RuntimeTestState{})
+	waitSleepingEntry(t, stateMonitorCh)
+	if err := runtime.Cancel(threadID); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if entry := waitTerminalEntry(t, stateMonitorCh); !errors.Is(entry.Error, g.ErrThreadCancelled) || entry.Node != "Wait" {
+		t.Errorf("Expected the sleeping invocation to be cancelled, got %+v", entry)
+	}
+	if _, ok := runtime.WakeAt(threadID); ok {
+		t.Error("Expected the cancelled thread not to sleep anymore")
+	}
+}
+
+// TestRuntime_DelayResume tests that a resumed thread sleeps until its journaled wake-up time
+func TestRuntime_DelayResume(t *testing.T) {
+	runtimeOpts := &g.RuntimeOptions[RuntimeTestState]{Journal: MemJournalFactory[RuntimeTestState](), Settings: g.RuntimeSettings{GracefulShutdownTimeout: 100 * time.Millisecond}}
+	waitNode, _ := DelayNodeImplFactory("Wait", fixedDelay(time.Hour), testNodeOptions())
+	crashedCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	crashed := newTestRuntime(t, crashedCh, runtimeOpts, waitNode, testNode("Work", countInput))
+	threadID := crashed.Invoke(RuntimeTestState{})
+	wakeAt := waitSleepingEntry(t, crashedCh).WakeAt
+	crashed.Shutdown()
+
+	waitNode, _ = DelayNodeImplFactory("Wait", fixedDelay(time.Minute), testNodeOptions())
+	restartedCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	restarted := newTestRuntime(t, restartedCh, runtimeOpts, waitNode, testNode("Work", countInput))
+	if err := restarted.Resume(threadID); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	if entry := waitSleepingEntry(t, restartedCh); !entry.WakeAt.Equal(wakeAt) {
+		t.Errorf("Expected the journaled wake-up time %v, got %v", wakeAt, entry.WakeAt)
+	}
+	_ = restarted.WakeUp(threadID)
+	if entry := waitTerminalEntry(t, restartedCh); entry.Error != nil || entry.NewState.Counter != 1 {
+		t.Errorf("Expected the resumed invocation to complete, got %+v", entry)
+	}
+}
+
+// TestRuntime_DelayErrors tests the delay nodes failing to compute their delay
+func TestRuntime_DelayErrors(t *testing.T) {
+	if _, err := DelayNodeImplFactory[RuntimeTestState]("Wait", nil, &g.NodeOptions[RuntimeTestState]{}); !errors.Is(err, g.ErrDelayFnNil) {
+		t.Errorf("Expected ErrDelayFnNil, got %v", err)
+	}
+
+	waitNode, _ := DelayNodeImplFactory("Wait", func(ctx context.Context, userInput, currentState RuntimeTestState) (time.Duration, error) {
+		return 0, errors.New("rate limit unknown")
+	}, testNodeOptions())
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, waitNode, testNode("Work", countInput))
+	runtime.Invoke(RuntimeTestState{})
+	if entry := waitTerminalEntry(t, stateMonitorCh); entry.Error == nil || entry.Node != "Wait" || entry.ErrorCode != g.CodeNodeFailed {
+		t.Errorf("Expected the Wait node to fail, got %+v", entry)
+	}
+
+	waitNode, _ = DelayNodeImplFactory("Wait", fixedDelay(-time.Second), testNodeOptions())
+	stateMonitorCh = make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime = newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, waitNode, testNode("Work", countInput))
+	runtime.Invoke(RuntimeTestState{})
+	if entry := waitTerminalEntry(t, stateMonitorCh); entry.Error != nil || !entry.WakeAt.IsZero() {
+		t.Errorf("Expected a negative delay to go on at once, got %+v", entry)
+	}
+}
+
+// TestRuntime_DelayReleasesSlots tests that a sleeping thread releases its admission and tenant slots to the other
+// invocations, taking them back on wake-up
+func TestRuntime_DelayReleasesSlots(t *testing.T) {
+	waitNode, err := DelayNodeImplFactory("Wait", func(ctx context.Context, userInput, currentState RuntimeTestState) (time.Duration, error) {
+		if userInput.Value == "sleep" {
+			return time.Hour, nil
+		}
+		return 0, nil
+	}, testNodeOptions())
+	if err != nil {
+		t.Fatalf("DelayNodeImplFactory failed: %v", err)
+	}
+	runtimeOpts := &g.RuntimeOptions[RuntimeTestState]{Settings: g.RuntimeSettings{MaxConcurrentInvocations: 1}}
+	_ = g.WithTenantQuotas[RuntimeTestState](g.TenantQuota{MaxConcurrentInvocations: 1}, nil).Apply(runtimeOpts)
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime := newTestRuntime(t, stateMonitorCh, runtimeOpts, waitNode, testNode("Work", countInput))
+
+	sleeping := runtime.Invoke(RuntimeTestState{Value: "sleep"}, g.InvokeConfigTenant("acme"))
+	waitSleepingEntry(t, stateMonitorCh)
+	if stats := runtime.AdmissionStats(); stats.Running != 0 {
+		t.Errorf("Expected the sleeping thread not to hold its admission slot, got %+v", stats)
+	}
+
+	awake := runtime.Invoke(RuntimeTestState{Value: "work"}, g.InvokeConfigTenant("acme"))
+	if final := waitTerminalEntry(t, stateMonitorCh); final.Error != nil || final.ThreadID != awake {
+		t.Errorf("Expected the second thread to run while the first sleeps, got %+v", final)
+	}
+
+	if err := runtime.WakeUp(sleeping); err != nil {
+		t.Fatalf("WakeUp failed: %v", err)
+	}
+	if final := waitTerminalEntry(t, stateMonitorCh); final.Error != nil || final.ThreadID != sleeping {
+		t.Errorf("Expected the woken thread to complete, got %+v", final)
+	}
+	waitFor(t, "the slots to be released", func() bool {
+		return runtime.AdmissionStats().Running == 0 && runtime.TenantStats("acme").RunningInvocations == 0
+	})
+}
@@ -0,0 +1,25 @@
+package graph
+
+import (
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// stateBefore returns a copy of the state of the thread before the reduction of a node update, when the diffing is enabled.
+func (r *runtimeImpl[T]) stateBefore(threadID string) T {
+	if r.diffFn == nil {
+		var zero T
+		return zero
+	}
+	if current, ok := r.state.Load(threadID); ok {
+		return r.clone(current.(T))
+	}
+	return r.initialState
+}
+
+// stateChanges returns the changes made by the reduction of a node update, nil when the diffing is disabled.
+func (r *runtimeImpl[T]) stateChanges(before, after T) []g.StateChange {
+	if r.diffFn == nil {
+		return nil
+	}
+	return r.diffFn(before, after)
+}
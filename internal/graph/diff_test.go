@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// TestRuntime_StateDiff tests that the entries carry the changes made by the reduced node updates
+func TestRuntime_StateDiff(t *testing.T) {
+	// The reducer accumulates the counter, so the change reflects the reduction rather than the raw update
+	accumulate := func(currentState, change RuntimeTestState) RuntimeTestState {
+		return RuntimeTestState{Value: change.Value, Counter: currentState.Counter + change.Counter}
+	}
+	policy, _ := RouterPolicyImplFactory(AnyRoute[RuntimeTestState])
+	opts := &g.NodeOptions[RuntimeTestState]{RoutingPolicy: policy, Reducer: accumulate}
+	fn := func(userInput, _ RuntimeTestState, _ g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		return RuntimeTestState{Value: userInput.Value, Counter: 2}, nil
+	}
+
+	startNode, _ := NodeImplFactory(g.StartNode, "StartNode", nil, opts)
+	node1, _ := NodeImplFactory(g.IntermediateNode, "Node1", fn, opts)
+	endNode, _ := NodeImplFactory(g.EndNode, "EndNode", nil, opts)
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtimeOpts := &g.RuntimeOptions[RuntimeTestState]{InitialState: RuntimeTestState{Counter: 1}}
+	if err := g.WithStateDiff[RuntimeTestState](nil).Apply(runtimeOpts); err != nil {
+		t.Fatalf("WithStateDiff failed: %v", err)
+	}
+	runtime, err := RuntimeFactory(&mockRuntimeEdge{from: startNode, to: node1, role: g.StartEdge}, stateMonitorCh, runtimeOpts)
+	if err != nil {
+		t.Fatalf("RuntimeFactory failed: %v", err)
+	}
+	defer runtime.Shutdown()
+	runtime.AddEdge(&mockRuntimeEdge{from: node1, to: endNode, role: g.EndEdge})
+
+	runtime.Invoke(RuntimeTestState{Value: "input"})
+
+	changes := map[string][]g.StateChange{}
+	timeout := time.After(2 * time.Second)
+	for done := false; !done; {
+		select {
+		case entry := <-stateMonitorCh:
+			changes[entry.Node] = entry.Changes
+			done = !entry.Running
+		case <-timeout:
+			t.Fatal("Test timed out waiting for the entries")
+		}
+	}
+
+	// The start node folds the initial state into itself, doubling the counter to 2
+	expected := []g.StateChange{
+		{Path: "Value", Before: "", After: "input"},
+		{Path: "Counter", Before: 2, After: 4},
+	}
+	if !reflect.DeepEqual(changes["Node1"], expected) {
+		t.Errorf("Expected Node1 to change the value and the reduced counter, got %+v", changes["Node1"])
+	}
+}
@@ -0,0 +1,99 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// MemEventStoreFactory creates an in-memory EventStore.
+func MemEventStoreFactory[T g.SharedState]() g.EventStore[T] {
+	return &memEventStore[T]{events: make(map[string][]g.Event[T])}
+}
+
+// ------------------------------------------------------------------------------
+// In-Memory EventStore Implementation
+// ------------------------------------------------------------------------------
+
+var _ g.EventStore[g.SharedState] = (*memEventStore[g.SharedState])(nil)
+
+type memEventStore[T g.SharedState] struct {
+	mu     sync.RWMutex
+	seq    uint64
+	events map[string][]g.Event[T]
+}
+
+func (s *memEventStore[T]) Append(_ context.Context, event g.Event[T]) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seq++
+	event.Seq = s.seq
+	s.events[event.ThreadID] = append(s.events[event.ThreadID], event)
+	return nil
+}
+
+func (s *memEventStore[T]) Events(_ context.Context, threadID string, since time.Time) ([]g.Event[T], error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	rv := make([]g.Event[T], 0)
+	for _, event := range s.events[threadID] {
+		if !event.Timestamp.Before(since) {
+			rv = append(rv, event)
+		}
+	}
+	return rv, nil
+}
+
+// ------------------------------------------------------------------------------
+// Runtime Event Log
+// ------------------------------------------------------------------------------
+
+func (r *runtimeImpl[T]) Events(threadID string, since time.Time) ([]g.Event[T], error) {
+	if r.eventStore == nil {
+		return nil, fmt.Errorf("cannot read events of thread %s: %w", threadID, g.ErrEventStoreNotSet)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.settings.PersistenceJobTimeout)
+	defer cancel()
+
+	events, err := r.eventStore.Events(ctx, threadID, since)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read events of thread %s: %w", threadID, err)
+	}
+	return events, nil
+}
+
+// recordEvent appends the monitor entry to the event store, if any.
+//
+// Failures are only logged: reporting them as monitor entries would record them again.
+func (r *runtimeImpl[T]) recordEvent(entry g.StateMonitorEntry[T]) {
+	if r.eventStore == nil {
+		return
+	}
+
+	event := g.Event[T]{
+		ThreadID:     entry.ThreadID,
+		Node:         entry.Node,
+		Timestamp:    time.Now(),
+		State:        r.clone(entry.NewState),
+		Running:      entry.Running,
+		Partial:      entry.Partial,
+		Compensation: entry.Compensation,
+		Outcome:      entry.Outcome,
+		Usage:        entry.Usage,
+	}
+	if entry.Error != nil {
+		event.Error = entry.Error.Error()
+		event.ErrorCode = entry.ErrorCode
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.settings.PersistenceJobTimeout)
+	defer cancel()
+
+	if err := r.eventStore.Append(ctx, event); err != nil {
+		r.logger.Warn("event not recorded", logAttrThreadID, entry.ThreadID, logAttrNode, entry.Node, "error", err)
+	}
+}
@@ -0,0 +1,74 @@
+package graph
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// TestRuntime_Events tests that every monitor entry of a thread is recorded in the event store
+func TestRuntime_Events(t *testing.T) {
+	policy, _ := RouterPolicyImplFactory(AnyRoute[RuntimeTestState])
+	opts := func() *g.NodeOptions[RuntimeTestState] {
+		return &g.NodeOptions[RuntimeTestState]{RoutingPolicy: policy, Reducer: Replacer[RuntimeTestState]}
+	}
+
+	startNode, _ := NodeImplFactory(g.StartNode, "StartNode", nil, opts())
+	node1, _ := NodeImplFactory(g.IntermediateNode, "Node1", func(userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		notify(RuntimeTestState{Value: "partial"})
+		return RuntimeTestState{}, errors.New("tool failed")
+	}, opts())
+
+	store := MemEventStoreFactory[RuntimeTestState]()
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime, err := RuntimeFactory(&mockRuntimeEdge{from: startNode, to: node1, role: g.StartEdge}, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{EventStore: store})
+	if err != nil {
+		t.Fatalf("RuntimeFactory failed: %v", err)
+	}
+	defer runtime.Shutdown()
+
+	before := time.Now()
+	threadID := runtime.Invoke(RuntimeTestState{})
+	waitTerminalEntry(t, stateMonitorCh)
+
+	events, err := runtime.Events(threadID, time.Time{})
+	if err != nil {
+		t.Fatalf("Events failed: %v", err)
+	}
+	if len(events) != 3 {
+		t.Fatalf("Expected 3 events, got %+v", events)
+	}
+
+	start, partial, failure := events[0], events[1], events[2]
+	if start.Node != "StartNode" || !start.Running || start.Seq != 1 {
+		t.Errorf("Unexpected start event %+v", start)
+	}
+	if !partial.Partial || partial.State.Value != "partial" {
+		t.Errorf("Unexpected partial event %+v", partial)
+	}
+	if failure.Running || failure.Node != "Node1" || failure.Error == "" {
+		t.Errorf("Unexpected failure event %+v", failure)
+	}
+	if start.Timestamp.Before(before) || failure.Timestamp.Before(start.Timestamp) {
+		t.Errorf("Unexpected timestamps %s, %s", start.Timestamp, failure.Timestamp)
+	}
+
+	recent, _ := runtime.Events(threadID, failure.Timestamp)
+	if len(recent) != 1 || recent[0].Seq != failure.Seq {
+		t.Errorf("Expected only the failure since its timestamp, got %+v", recent)
+	}
+	if other, _ := runtime.Events("other", time.Time{}); len(other) != 0 {
+		t.Errorf("Expected no events for another thread, got %+v", other)
+	}
+}
+
+// TestRuntime_Events_NotSet tests that reading the events without a store fails
+func TestRuntime_Events_NotSet(t *testing.T) {
+	runtime, _ := newNodeTestRuntime(t, nil, &g.RuntimeOptions[RuntimeTestState]{})
+
+	if _, err := runtime.Events("thread", time.Time{}); !errors.Is(err, g.ErrEventStoreNotSet) {
+		t.Errorf("Expected ErrEventStoreNotSet, got %v", err)
+	}
+}
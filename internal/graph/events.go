@@ -0,0 +1,111 @@
+package graph
+
+import (
+	"sync"
+	"sync/atomic"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+type subscription[T g.SharedState] struct {
+	ch     chan g.StateMonitorEntry[T]
+	filter g.EventFilter[T]
+}
+
+// eventBus fans the monitor entries out to the subscriptions without blocking.
+type eventBus[T g.SharedState] struct {
+	mu            sync.RWMutex
+	subscriptions map[uint64]*subscription[T]
+	nextID        uint64
+	closed        bool
+
+	subscribers atomic.Int64
+	delivered   atomic.Uint64
+	dropped     atomic.Uint64
+}
+
+func newEventBus[T g.SharedState]() *eventBus[T] {
+	return &eventBus[T]{subscriptions: make(map[uint64]*subscription[T])}
+}
+
+func (r *runtimeImpl[T]) Subscribe(filter g.EventFilter[T]) (<-chan g.StateMonitorEntry[T], func()) {
+	return r.events.subscribe(filter)
+}
+
+func (r *runtimeImpl[T]) EventStats() g.EventStats {
+	return r.events.stats()
+}
+
+func (b *eventBus[T]) subscribe(filter g.EventFilter[T]) (<-chan g.StateMonitorEntry[T], func()) {
+	bufferSize := filter.BufferSize
+	if bufferSize <= 0 {
+		bufferSize = g.DefaultSubscriptionBufferSize
+	}
+	sub := &subscription[T]{ch: make(chan g.StateMonitorEntry[T], bufferSize), filter: filter}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(sub.ch)
+		return sub.ch, func() {}
+	}
+	id := b.nextID
+	b.nextID++
+	b.subscriptions[id] = sub
+	b.subscribers.Add(1)
+
+	return sub.ch, func() { b.unsubscribe(id) }
+}
+
+func (b *eventBus[T]) unsubscribe(id uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if sub, ok := b.subscriptions[id]; ok {
+		delete(b.subscriptions, id)
+		b.subscribers.Add(-1)
+		close(sub.ch)
+	}
+}
+
+func (b *eventBus[T]) hasSubscribers() bool {
+	return b.subscribers.Load() > 0
+}
+
+// publish delivers a copy of the entry to every accepting subscription, dropping it for the full ones.
+func (b *eventBus[T]) publish(entry g.StateMonitorEntry[T], clone func(T) T) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, sub := range b.subscriptions {
+		if !sub.filter.Accepts(entry) {
+			continue
+		}
+		useEntry := entry
+		useEntry.NewState = clone(entry.NewState)
+		select {
+		case sub.ch <- useEntry:
+			b.delivered.Add(1)
+		default:
+			b.dropped.Add(1)
+		}
+	}
+}
+
+// close ends the subscriptions, the later ones are created closed.
+func (b *eventBus[T]) close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.closed = true
+	for id, sub := range b.subscriptions {
+		delete(b.subscriptions, id)
+		close(sub.ch)
+	}
+	b.subscribers.Store(0)
+}
+
+func (b *eventBus[T]) stats() g.EventStats {
+	return g.EventStats{
+		Subscribers: int(b.subscribers.Load()),
+		Delivered:   b.delivered.Load(),
+		Dropped:     b.dropped.Load(),
+	}
+}
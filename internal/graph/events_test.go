@@ -0,0 +1,100 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// notifyPartial is a node function notifying a partial update before storing the user input.
+func notifyPartial(_ context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+	notify(RuntimeTestState{Value: "partial"})
+	currentState.Value = userInput.Value
+	return currentState, nil
+}
+
+// TestRuntime_Subscribe tests that independent subscriptions receive the entries accepted by their filters
+func TestRuntime_Subscribe(t *testing.T) {
+	// No state monitor channel: the subscriptions are the only consumers.
+	runtime := newTestRuntime(t, nil, &g.RuntimeOptions[RuntimeTestState]{}, testNode("Node1", notifyPartial))
+
+	all, cancelAll := runtime.Subscribe(g.EventFilter[RuntimeTestState]{})
+	defer cancelAll()
+	thread2, cancelThread2 := runtime.Subscribe(g.EventFilter[RuntimeTestState]{ThreadIDs: []string{"thread-2"}, SkipPartial: true})
+	defer cancelThread2()
+
+	runtime.Invoke(RuntimeTestState{Value: "one"}, g.InvokeConfig{ThreadID: "thread-1"})
+	allEntries := waitCompleted(t, all)
+	runtime.Invoke(RuntimeTestState{Value: "two"}, g.InvokeConfig{ThreadID: "thread-2"})
+	allEntries = append(allEntries, waitCompleted(t, all)...)
+	thread2Entries := waitCompleted(t, thread2)
+
+	partials := 0
+	for _, entry := range allEntries {
+		if entry.Partial {
+			partials++
+		}
+	}
+	if partials != 2 {
+		t.Errorf("Expected 2 partial entries, got %d", partials)
+	}
+
+	for _, entry := range thread2Entries {
+		if entry.ThreadID != "thread-2" || entry.Partial {
+			t.Errorf("Unexpected entry for the thread-2 subscription: %+v", entry)
+		}
+	}
+	if last := thread2Entries[len(thread2Entries)-1]; last.NewState.Value != "two" {
+		t.Errorf("Expected final state 'two', got %q", last.NewState.Value)
+	}
+
+	stats := runtime.EventStats()
+	if stats.Subscribers != 2 || stats.Dropped != 0 || stats.Delivered != uint64(len(allEntries)+len(thread2Entries)) {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+// TestRuntime_Subscribe_Drops tests that a slow subscription drops entries without blocking the runtime
+func TestRuntime_Subscribe_Drops(t *testing.T) {
+	runtime := newTestRuntime(t, nil, &g.RuntimeOptions[RuntimeTestState]{}, testNode("Node1", notifyPartial))
+
+	_, cancelSlow := runtime.Subscribe(g.EventFilter[RuntimeTestState]{BufferSize: 1})
+	defer cancelSlow()
+	fast, cancelFast := runtime.Subscribe(g.EventFilter[RuntimeTestState]{})
+	defer cancelFast()
+
+	runtime.Invoke(RuntimeTestState{Value: "one"})
+	entries := waitCompleted(t, fast)
+
+	stats := runtime.EventStats()
+	if stats.Dropped != uint64(len(entries)-1) {
+		t.Errorf("Expected %d dropped entries, got %+v", len(entries)-1, stats)
+	}
+}
+
+// TestRuntime_Subscribe_Cancel tests that cancelling or shutting down closes the subscriptions
+func TestRuntime_Subscribe_Cancel(t *testing.T) {
+	runtime := newTestRuntime(t, nil, &g.RuntimeOptions[RuntimeTestState]{}, testNode("Node1", notifyPartial))
+
+	cancelled, cancel := runtime.Subscribe(g.EventFilter[RuntimeTestState]{})
+	open, _ := runtime.Subscribe(g.EventFilter[RuntimeTestState]{})
+
+	cancel()
+	cancel()
+	if _, ok := <-cancelled; ok {
+		t.Error("Expected the cancelled subscription to be closed")
+	}
+	if stats := runtime.EventStats(); stats.Subscribers != 1 {
+		t.Errorf("Expected 1 subscriber, got %d", stats.Subscribers)
+	}
+
+	runtime.Shutdown()
+	if _, ok := <-open; ok {
+		t.Error("Expected the subscription to be closed by the shutdown")
+	}
+	late, _ := runtime.Subscribe(g.EventFilter[RuntimeTestState]{})
+	if _, ok := <-late; ok {
+		t.Error("Expected a subscription after the shutdown to be closed")
+	}
+}
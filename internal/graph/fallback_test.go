@@ -0,0 +1,76 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func nilRoute(userInput, currentState RuntimeTestState, edges []g.Edge[RuntimeTestState]) g.Edge[RuntimeTestState] {
+	return nil
+}
+
+// recoverState is a node function marking the state as recovered.
+func recoverState(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+	currentState.Value = "recovered"
+	return currentState, nil
+}
+
+// TestRuntime_FallbackEdge tests that the fallback edge is followed when the routing policy selects no edge
+func TestRuntime_FallbackEdge(t *testing.T) {
+	nonePolicy, _ := RouterPolicyImplFactory(nilRoute)
+	router, _ := NodeImplFactory(g.IntermediateNode, "Router", nil, &g.NodeOptions[RuntimeTestState]{RoutingPolicy: nonePolicy, Reducer: Replacer[RuntimeTestState]})
+	recovery := testNode("Recovery", recoverState)
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, router, recovery)
+	runtime.AddEdge(&mockRuntimeEdge{from: router, to: recovery, role: g.IntermediateEdge, labels: map[string]string{g.FallbackEdgeLabel: "true"}})
+
+	if err := runtime.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	runtime.Invoke(RuntimeTestState{})
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if entry.Error != nil {
+		t.Fatalf("Unexpected error %v", entry.Error)
+	}
+	if entry.NewState.Value != "recovered" {
+		t.Errorf("Expected the fallback node to run, got %+v", entry.NewState)
+	}
+}
+
+// TestRuntime_FallbackEdge_Missing tests that a policy selecting no edge without fallback fails the invocation
+func TestRuntime_FallbackEdge_Missing(t *testing.T) {
+	nonePolicy, _ := RouterPolicyImplFactory(nilRoute)
+	router, _ := NodeImplFactory(g.IntermediateNode, "Router", nil, &g.NodeOptions[RuntimeTestState]{RoutingPolicy: nonePolicy, Reducer: Replacer[RuntimeTestState]})
+	recovery := testNode("Recovery", recoverState)
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, router, recovery)
+	runtime.AddEdge(&mockRuntimeEdge{from: router, to: recovery, role: g.IntermediateEdge, labels: map[string]string{g.FallbackEdgeLabel: "false"}})
+
+	runtime.Invoke(RuntimeTestState{})
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if !errors.Is(entry.Error, g.ErrNilEdge) {
+		t.Errorf("Expected ErrNilEdge, got %v", entry.Error)
+	}
+}
+
+// TestRuntime_FallbackEdge_Validate tests that misconfigured fallback edges fail the validation
+func TestRuntime_FallbackEdge_Validate(t *testing.T) {
+	nonePolicy, _ := RouterPolicyImplFactory(nilRoute)
+	router, _ := NodeImplFactory(g.IntermediateNode, "Router", nil, &g.NodeOptions[RuntimeTestState]{RoutingPolicy: nonePolicy, Reducer: Replacer[RuntimeTestState]})
+	recovery := testNode("Recovery", recoverState)
+	runtime := newTestRuntime(t, nil, &g.RuntimeOptions[RuntimeTestState]{}, router, recovery)
+	runtime.AddEdge(&mockRuntimeEdge{from: router, to: recovery, role: g.IntermediateEdge, labels: map[string]string{g.FallbackEdgeLabel: "true"}}, &mockRuntimeEdge{from: router, to: recovery, role: g.IntermediateEdge, labels: map[string]string{g.FallbackEdgeLabel: "1"}})
+	if err := runtime.Validate(); !errors.Is(err, g.ErrMultipleFallbackEdges) {
+		t.Errorf("Expected ErrMultipleFallbackEdges, got %v", err)
+	}
+
+	invalid := newTestRuntime(t, nil, &g.RuntimeOptions[RuntimeTestState]{}, router, recovery)
+	invalid.AddEdge(&mockRuntimeEdge{from: router, to: recovery, role: g.IntermediateEdge, labels: map[string]string{g.FallbackEdgeLabel: "maybe"}})
+	if err := invalid.Validate(); !errors.Is(err, g.ErrInvalidFallbackLabel) {
+		t.Errorf("Expected ErrInvalidFallbackLabel, got %v", err)
+	}
+}
@@ -0,0 +1,80 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func (r *runtimeImpl[T]) Fork(threadID string) (string, error) {
+	source, err := r.threadSource(threadID)
+	if err != nil {
+		return "", fmt.Errorf("cannot fork thread %s: %w", threadID, err)
+	}
+
+	tenant := r.ownerTenant(threadID)
+	forkID := g.TenantThreadID(tenant, r.idGenerator())
+	if err := r.reserveTenantThread(forkID, tenant); err != nil {
+		return "", fmt.Errorf("cannot fork thread %s: %w", threadID, err)
+	}
+	if err := r.adoptThread(forkID, tenant, r.clone(source)); err != nil {
+		r.threadMeta.Delete(forkID)
+		return "", fmt.Errorf("cannot fork thread %s: %w", threadID, err)
+	}
+
+	r.logger.Info("thread forked", logAttrThreadID, threadID, "fork", forkID)
+	return forkID, nil
+}
+
+// adoptThread makes the state the committed state of the thread, persisting it first when the runtime has a memory.
+func (r *runtimeImpl[T]) adoptThread(threadID string, tenant string, state T) error {
+	if r.persistFn != nil {
+		ctx, cancel := context.WithTimeout(r.ctx, r.settings.PersistenceJobTimeout)
+		defer cancel()
+		if err := r.persistFn(ctx, threadID, state); err != nil {
+			return &g.PersistenceError{Node: "Runtime", ThreadID: threadID, Code: g.CodePersistenceFailed, Err: err}
+		}
+		r.lastPersisted.Store(threadID, r.clone(state))
+	}
+
+	r.state.Store(threadID, state)
+	r.threadTTL.Store(threadID, time.Now().Add(r.settings.ThreadTTL))
+	r.touchThread(threadID, tenant)
+	r.bumpStateVersion(threadID)
+	if r.persistFn != nil {
+		if meta, ok := r.threadMeta.Load(threadID); ok {
+			meta.(*threadMeta).persistedAt.Store(time.Now().UnixNano())
+		}
+	}
+	return nil
+}
+
+// threadSource returns the committed state of an active thread, the state queued for persistence of a released one, or
+// restores it from the memory.
+func (r *runtimeImpl[T]) threadSource(threadID string) (T, error) {
+	if state, ok := r.state.Load(threadID); ok {
+		return state.(T), nil
+	}
+	if pending, ok := r.unpersisted.Load(threadID); ok {
+		return pending.(*pendingPersistEntry[T]).state, nil
+	}
+
+	var zero T
+	if r.restoreFn == nil {
+		return zero, g.ErrUnknownThreadID
+	}
+	ctx, cancel := context.WithTimeout(r.ctx, r.settings.PersistenceJobTimeout)
+	defer cancel()
+	restored, err := r.restoreFn(ctx, threadID)
+	if err != nil {
+		return zero, &g.PersistenceError{Node: "Runtime", ThreadID: threadID, Code: g.CodeRestoreFailed, Err: err}
+	}
+	// The memories restore the zero state for the threads they do not know.
+	if reflect.ValueOf(&restored).Elem().IsZero() {
+		return zero, g.ErrUnknownThreadID
+	}
+	return restored, nil
+}
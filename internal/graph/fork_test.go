@@ -0,0 +1,164 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"slices"
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// appendInput is a node function appending the user input to the state and counting the executions.
+func appendInput(_ context.Context, userInput, currentState RuntimeTestState, _ g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+	return RuntimeTestState{Value: currentState.Value + userInput.Value, Counter: currentState.Counter + 1}, nil
+}
+
+// TestRuntime_Fork tests that a fork continues from the state of its thread without affecting it
+func TestRuntime_Fork(t *testing.T) {
+	runtime, stateMonitorCh := newNodeTestRuntime(t, appendInput, &g.RuntimeOptions[RuntimeTestState]{StateDiff: g.Diff[RuntimeTestState]})
+
+	runtime.Invoke(RuntimeTestState{Value: "a"}, g.InvokeConfigThreadID("origin"))
+	waitTerminalEntry(t, stateMonitorCh)
+
+	forkID, err := runtime.Fork("origin")
+	if err != nil {
+		t.Fatalf("Fork failed: %v", err)
+	}
+	if forkID == "" || forkID == "origin" {
+		t.Fatalf("Expected a new thread identifier, got %q", forkID)
+	}
+	if fork := runtime.CurrentState(forkID); fork.Value != "a" || fork.Counter != 1 {
+		t.Errorf("Expected the fork to start from the state of the thread, got %+v", fork)
+	}
+
+	runtime.Invoke(RuntimeTestState{Value: "b"}, g.InvokeConfigThreadID(forkID))
+	waitTerminalEntry(t, stateMonitorCh)
+
+	if fork := runtime.CurrentState(forkID); fork.Value != "ab" || fork.Counter != 2 {
+		t.Errorf("Expected the fork to continue from the state of the thread, got %+v", fork)
+	}
+	if origin := runtime.CurrentState("origin"); origin.Value != "a" || origin.Counter != 1 {
+		t.Errorf("Expected the thread to be unaffected by its fork, got %+v", origin)
+	}
+
+	if _, err := runtime.Fork("missing"); !errors.Is(err, g.ErrUnknownThreadID) {
+		t.Errorf("Expected ErrUnknownThreadID, got %v", err)
+	}
+}
+
+// TestRuntime_ForkFromMemory tests that inactive threads are forked from the memory and the forks are persisted
+func TestRuntime_ForkFromMemory(t *testing.T) {
+	memory := MemMemoryFactory[RuntimeTestState](nil)
+	if err := memory.PersistFn()(context.Background(), "stored", RuntimeTestState{Value: "a", Counter: 1}); err != nil {
+		t.Fatalf("PersistFn failed: %v", err)
+	}
+	runtime, stateMonitorCh := newNodeTestRuntime(t, appendInput, &g.RuntimeOptions[RuntimeTestState]{Memory: memory, StateDiff: g.Diff[RuntimeTestState]})
+
+	forkID, err := runtime.Fork("stored")
+	if err != nil {
+		t.Fatalf("Fork failed: %v", err)
+	}
+	persisted, err := memory.RestoreFn()(context.Background(), forkID)
+	if err != nil || persisted.Value != "a" || persisted.Counter != 1 {
+		t.Errorf("Expected the fork to be persisted, got %+v: %v", persisted, err)
+	}
+
+	runtime.Invoke(RuntimeTestState{Value: "b"}, g.InvokeConfigThreadID(forkID))
+	if final := waitTerminalEntry(t, stateMonitorCh); final.Error != nil || final.NewState.Value != "ab" {
+		t.Errorf("Expected the fork to continue from the stored state, got %+v", final)
+	}
+
+	if _, err := runtime.Fork("missing"); !errors.Is(err, g.ErrUnknownThreadID) {
+		t.Errorf("Expected ErrUnknownThreadID, got %v", err)
+	}
+}
+
+// gatedMemory holds the writes of a thread until its gate is closed.
+type gatedMemory struct {
+	g.Memory[RuntimeTestState]
+	threadID string
+	gate     chan struct{}
+}
+
+func (m *gatedMemory) PersistFn() g.PersistFn[RuntimeTestState] {
+	persist := m.Memory.PersistFn()
+	return func(ctx context.Context, key string, state RuntimeTestState) error {
+		if key == m.threadID {
+			<-m.gate
+		}
+		return persist(ctx, key, state)
+	}
+}
+
+// TestRuntime_ForkCompleted tests that a thread is forked right after its invocation completes, while its state is
+// still queued for persistence
+func TestRuntime_ForkCompleted(t *testing.T) {
+	memory := &gatedMemory{Memory: MemMemoryFactory[RuntimeTestState](nil), threadID: "origin", gate: make(chan struct{})}
+	runtime, stateMonitorCh := newNodeTestRuntime(t, appendInput, &g.RuntimeOptions[RuntimeTestState]{Memory: memory})
+	t.Cleanup(func() { close(memory.gate) })
+
+	runtime.Invoke(RuntimeTestState{Value: "a"}, g.InvokeConfigThreadID("origin"))
+	waitTerminalEntry(t, stateMonitorCh)
+	waitFor(t, "the thread to be released", func() bool { return !slices.Contains(runtime.ListThreads(), "origin") })
+
+	forkID, err := runtime.Fork("origin")
+	if err != nil {
+		t.Fatalf("Fork failed: %v", err)
+	}
+	if fork := runtime.CurrentState(forkID); fork.Value != "a" || fork.Counter != 1 {
+		t.Errorf("Expected the fork to start from the queued state of the thread, got %+v", fork)
+	}
+}
+
+// TestRuntime_MergeThreads tests that the state of a fork is merged back into its thread and reported
+func TestRuntime_MergeThreads(t *testing.T) {
+	runtime, stateMonitorCh := newNodeTestRuntime(t, appendInput, &g.RuntimeOptions[RuntimeTestState]{StateDiff: g.Diff[RuntimeTestState]})
+
+	runtime.Invoke(RuntimeTestState{Value: "a"}, g.InvokeConfigThreadID("origin"))
+	waitTerminalEntry(t, stateMonitorCh)
+	forkID, err := runtime.Fork("origin")
+	if err != nil {
+		t.Fatalf("Fork failed: %v", err)
+	}
+	runtime.Invoke(RuntimeTestState{Value: "b"}, g.InvokeConfigThreadID(forkID))
+	waitTerminalEntry(t, stateMonitorCh)
+
+	keepLongest := func(currentState, change RuntimeTestState) RuntimeTestState {
+		if len(change.Value) > len(currentState.Value) {
+			return change
+		}
+		return currentState
+	}
+	if err := runtime.MergeThreads("origin", forkID, keepLongest); err != nil {
+		t.Fatalf("MergeThreads failed: %v", err)
+	}
+
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	expected := []g.StateChange{
+		{Path: "Value", Before: "a", After: "ab"},
+		{Path: "Counter", Before: 1, After: 2},
+	}
+	if entry.Node != g.MergeNodeName || entry.ThreadID != "origin" || entry.NewState.Value != "ab" || !reflect.DeepEqual(entry.Changes, expected) {
+		t.Errorf("Expected the merge to be reported with its changes, got %+v", entry)
+	}
+	if origin := runtime.CurrentState("origin"); origin.Value != "ab" || origin.Counter != 2 {
+		t.Errorf("Expected the merged state in the destination, got %+v", origin)
+	}
+	if fork := runtime.CurrentState(forkID); fork.Value != "ab" || fork.Counter != 2 {
+		t.Errorf("Expected the source to be untouched, got %+v", fork)
+	}
+
+	runtime.Invoke(RuntimeTestState{Value: "c"}, g.InvokeConfigThreadID("origin"))
+	if final := waitTerminalEntry(t, stateMonitorCh); final.Error != nil || final.NewState.Value != "abc" {
+		t.Errorf("Expected the destination to continue from the merged state, got %+v", final)
+	}
+
+	if err := runtime.MergeThreads("origin", forkID, nil); !errors.Is(err, g.ErrMergeFnNil) {
+		t.Errorf("Expected ErrMergeFnNil, got %v", err)
+	}
+	if err := runtime.MergeThreads("origin", "missing", keepLongest); !errors.Is(err, g.ErrUnknownThreadID) {
+		t.Errorf("Expected ErrUnknownThreadID, got %v", err)
+	}
+}
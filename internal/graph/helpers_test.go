@@ -0,0 +1,206 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// testTimeout bounds the waits of the tests on the runtime.
+const testTimeout = 2 * time.Second
+
+// testNodeOptions returns the options of the test nodes, routing to any edge and replacing the state.
+func testNodeOptions() *g.NodeOptions[RuntimeTestState] {
+	policy, _ := RouterPolicyImplFactory(AnyRoute[RuntimeTestState])
+	return &g.NodeOptions[RuntimeTestState]{RoutingPolicy: policy, Reducer: Replacer[RuntimeTestState]}
+}
+
+// testNode creates an intermediate node executing fn with the test node options.
+func testNode(name string, fn g.ContextNodeFn[RuntimeTestState]) g.Node[RuntimeTestState] {
+	node, _ := ContextNodeImplFactory(g.IntermediateNode, name, fn, testNodeOptions())
+	return node
+}
+
+// countInput is a node function storing the user input and counting the executions.
+func countInput(_ context.Context, userInput, currentState RuntimeTestState, _ g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+	return RuntimeTestState{Value: userInput.Value, Counter: currentState.Counter + 1}, nil
+}
+
+// newTestRuntime creates a runtime executing the nodes in sequence from StartNode to EndNode, sending its entries to
+// stateMonitorCh; the runtime is shut down at the end of the test.
+func newTestRuntime(t *testing.T, stateMonitorCh chan g.StateMonitorEntry[RuntimeTestState], runtimeOpts *g.RuntimeOptions[RuntimeTestState], nodes ...g.Node[RuntimeTestState]) *runtimeImpl[RuntimeTestState] {
+	t.Helper()
+	startNode, _ := NodeImplFactory(g.StartNode, "StartNode", nil, testNodeOptions())
+	endNode, _ := NodeImplFactory(g.EndNode, "EndNode", nil, testNodeOptions())
+	path := append(append([]g.Node[RuntimeTestState]{startNode}, nodes...), endNode)
+
+	runtime, err := RuntimeFactory(&mockRuntimeEdge{from: path[0], to: path[1], role: g.StartEdge}, stateMonitorCh, runtimeOpts)
+	if err != nil {
+		t.Fatalf("RuntimeFactory failed: %v", err)
+	}
+	t.Cleanup(runtime.Shutdown)
+	for i := 1; i < len(path)-1; i++ {
+		edge := &mockRuntimeEdge{from: path[i], to: path[i+1], role: g.IntermediateEdge}
+		if i == len(path)-2 {
+			edge.role = g.EndEdge
+		}
+		runtime.AddEdge(edge)
+	}
+	return runtime.(*runtimeImpl[RuntimeTestState])
+}
+
+// newNodeTestRuntime creates a runtime executing fn as Node1 between StartNode and EndNode, see newTestRuntime.
+func newNodeTestRuntime(t *testing.T, fn g.ContextNodeFn[RuntimeTestState], runtimeOpts *g.RuntimeOptions[RuntimeTestState]) (*runtimeImpl[RuntimeTestState], chan g.StateMonitorEntry[RuntimeTestState]) {
+	t.Helper()
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 100)
+	return newTestRuntime(t, stateMonitorCh, runtimeOpts, testNode("Node1", fn)), stateMonitorCh
+}
+
+// newBlockingTestRuntime creates a runtime whose Node1 reports the user input on started, then blocks until released
+// ignoring its context, see newNodeTestRuntime.
+func newBlockingTestRuntime(t *testing.T, runtimeOpts *g.RuntimeOptions[RuntimeTestState]) (*runtimeImpl[RuntimeTestState], chan g.StateMonitorEntry[RuntimeTestState], chan string, chan struct{}) {
+	t.Helper()
+	started := make(chan string, 10)
+	release := make(chan struct{})
+	runtime, stateMonitorCh := newNodeTestRuntime(t, func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		started <- userInput.Value
+		<-release
+		currentState.Value = "done"
+		return currentState, nil
+	}, runtimeOpts)
+	return runtime, stateMonitorCh, started, release
+}
+
+// waitStarted returns the user input of the next invocation reaching the node.
+func waitStarted(t *testing.T, started <-chan string) string {
+	t.Helper()
+	select {
+	case value := <-started:
+		return value
+	case <-time.After(testTimeout):
+		t.Fatal("Test timed out waiting for an invocation to start")
+		return ""
+	}
+}
+
+// waitEntry returns the first monitor entry matching, the test fails if none is received in time.
+func waitEntry(t *testing.T, entries <-chan g.StateMonitorEntry[RuntimeTestState], what string, match func(g.StateMonitorEntry[RuntimeTestState]) bool) g.StateMonitorEntry[RuntimeTestState] {
+	t.Helper()
+	timeout := time.After(testTimeout)
+	for {
+		select {
+		case entry, ok := <-entries:
+			if !ok {
+				t.Fatalf("Monitor closed waiting for %s", what)
+			}
+			if match(entry) {
+				return entry
+			}
+		case <-timeout:
+			t.Fatalf("Test timed out waiting for %s", what)
+		}
+	}
+}
+
+// waitRunningEntry is waitEntry failing the test if the invocation terminates before the entry matches.
+func waitRunningEntry(t *testing.T, entries <-chan g.StateMonitorEntry[RuntimeTestState], what string, match func(g.StateMonitorEntry[RuntimeTestState]) bool) g.StateMonitorEntry[RuntimeTestState] {
+	t.Helper()
+	return waitEntry(t, entries, what, func(entry g.StateMonitorEntry[RuntimeTestState]) bool {
+		if match(entry) {
+			return true
+		}
+		if !entry.Running {
+			t.Fatalf("Expected %s, the invocation terminated at %s: %v", what, entry.Node, entry.Error)
+		}
+		return false
+	})
+}
+
+// waitTerminalEntry returns the entry terminating an invocation.
+func waitTerminalEntry(t *testing.T, entries <-chan g.StateMonitorEntry[RuntimeTestState]) g.StateMonitorEntry[RuntimeTestState] {
+	t.Helper()
+	return waitEntry(t, entries, "a terminal entry", func(entry g.StateMonitorEntry[RuntimeTestState]) bool {
+		return !entry.Running
+	})
+}
+
+// waitCompleted returns the entries received up to the termination of an invocation.
+func waitCompleted(t *testing.T, entries <-chan g.StateMonitorEntry[RuntimeTestState]) []g.StateMonitorEntry[RuntimeTestState] {
+	t.Helper()
+	var rv []g.StateMonitorEntry[RuntimeTestState]
+	waitEntry(t, entries, "the completion", func(entry g.StateMonitorEntry[RuntimeTestState]) bool {
+		rv = append(rv, entry)
+		return !entry.Running
+	})
+	return rv
+}
+
+// waitCompletion waits for the successful termination of count invocations.
+func waitCompletion(t *testing.T, entries <-chan g.StateMonitorEntry[RuntimeTestState], count int) {
+	t.Helper()
+	for ; count > 0; count-- {
+		waitEntry(t, entries, "the completion", func(entry g.StateMonitorEntry[RuntimeTestState]) bool {
+			if entry.Error != nil {
+				t.Fatalf("Unexpected error: %v", entry.Error)
+			}
+			return !entry.Running
+		})
+	}
+}
+
+// waitSleepingEntry returns the entry of the thread put to sleep by a delay node.
+func waitSleepingEntry(t *testing.T, entries <-chan g.StateMonitorEntry[RuntimeTestState]) g.StateMonitorEntry[RuntimeTestState] {
+	t.Helper()
+	return waitRunningEntry(t, entries, "the thread to sleep", func(entry g.StateMonitorEntry[RuntimeTestState]) bool {
+		return !entry.WakeAt.IsZero()
+	})
+}
+
+// waitParkedEntry returns the entry of the thread parked by a signal node.
+func waitParkedEntry(t *testing.T, entries <-chan g.StateMonitorEntry[RuntimeTestState]) g.StateMonitorEntry[RuntimeTestState] {
+	t.Helper()
+	return waitRunningEntry(t, entries, "the thread to wait for a signal", func(entry g.StateMonitorEntry[RuntimeTestState]) bool {
+		return entry.Signal != ""
+	})
+}
+
+// waitBatchedEntry returns the entry of the thread whose input is collected by a batch node with batched inputs.
+func waitBatchedEntry(t *testing.T, entries <-chan g.StateMonitorEntry[RuntimeTestState], batched int) g.StateMonitorEntry[RuntimeTestState] {
+	t.Helper()
+	return waitRunningEntry(t, entries, fmt.Sprintf("%d batched inputs", batched), func(entry g.StateMonitorEntry[RuntimeTestState]) bool {
+		return entry.Batched == batched
+	})
+}
+
+// waitForBreakpoint returns the entry of the invocation paused by the debugger.
+func waitForBreakpoint(t *testing.T, entries <-chan g.StateMonitorEntry[RuntimeTestState]) g.StateMonitorEntry[RuntimeTestState] {
+	t.Helper()
+	return waitRunningEntry(t, entries, "a breakpoint", func(entry g.StateMonitorEntry[RuntimeTestState]) bool {
+		return entry.Breakpoint != nil
+	})
+}
+
+// waitForPartials waits for count partial updates.
+func waitForPartials(t *testing.T, entries <-chan g.StateMonitorEntry[RuntimeTestState], count int) {
+	t.Helper()
+	for ; count > 0; count-- {
+		waitEntry(t, entries, "the partial updates", func(entry g.StateMonitorEntry[RuntimeTestState]) bool {
+			return entry.Partial
+		})
+	}
+}
+
+// waitFor polls the condition until it holds, the test fails if it does not in time.
+func waitFor(t *testing.T, what string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(testTimeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Test timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
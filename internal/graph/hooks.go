@@ -0,0 +1,100 @@
+package graph
+
+import (
+	"fmt"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// failInvocation reports the failure of the invocation and releases the thread.
+func (r *runtimeImpl[T]) failInvocation(node string, config g.InvokeConfig, err error) {
+	if !r.ownsInvocation(config) {
+		// The invocation was already terminated, e.g. released by a cancellation.
+		return
+	}
+	if r.compensate(node, config, err) {
+		return
+	}
+	r.terminateFailed(node, config, err)
+}
+
+// terminateFailed notifies the failure of the invocation and releases its thread, persisting the
+// progress of the invocation first; nothing happens if the invocation was already terminated.
+func (r *runtimeImpl[T]) terminateFailed(node string, config g.InvokeConfig, err error) {
+	inv, ok := r.claimInvocation(config.ThreadID, config.Context)
+	if !ok {
+		// A concurrent termination, e.g. a cancellation, already notified it and released the thread.
+		return
+	}
+	r.flushState(node, config.ThreadID)
+	r.onError(node, config, err)
+	r.logInvocationFailed(node, config.ThreadID, err, time.Since(inv.startedAt))
+	entry := monitorError[T](node, config.ThreadID, err)
+	entry.Usage = inv.currentUsage()
+	r.sendMonitorEntry(entry)
+	r.executingByThreadID(config).Store(false)
+	r.finishInvocation(config.ThreadID, inv, err)
+	r.clearThread(config.ThreadID)
+}
+
+func (r *runtimeImpl[T]) threadState(threadID string) T {
+	if state, ok := r.state.Load(threadID); ok {
+		return r.clone(state.(T))
+	}
+	var zero T
+	return zero
+}
+
+func (r *runtimeImpl[T]) beforeNode(node g.Node[T], config g.InvokeConfig) error {
+	return r.callNodeHook("BeforeNode", r.hooks.BeforeNode, node, config)
+}
+
+func (r *runtimeImpl[T]) afterNode(node g.Node[T], config g.InvokeConfig) error {
+	return r.callNodeHook("AfterNode", r.hooks.AfterNode, node, config)
+}
+
+func (r *runtimeImpl[T]) callNodeHook(name string, hook g.NodeHookFn[T], node g.Node[T], config g.InvokeConfig) (err error) {
+	if hook == nil {
+		return nil
+	}
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("%s hook for node %s: %w: %v", name, node.Name(), g.ErrHookPanic, rec)
+		}
+	}()
+	if hookErr := hook(config.Context, config.ThreadID, node.Name(), r.threadState(config.ThreadID)); hookErr != nil {
+		return fmt.Errorf("%s hook for node %s: %w", name, node.Name(), hookErr)
+	}
+	return nil
+}
+
+func (r *runtimeImpl[T]) onRoute(from, to g.Node[T], config g.InvokeConfig) {
+	if r.hooks.OnRoute == nil {
+		return
+	}
+	defer r.recoverHook("OnRoute", config.ThreadID)
+	r.hooks.OnRoute(config.Context, config.ThreadID, from.Name(), to.Name(), r.threadState(config.ThreadID))
+}
+
+func (r *runtimeImpl[T]) onError(node string, config g.InvokeConfig, err error) {
+	if r.hooks.OnError == nil {
+		return
+	}
+	defer r.recoverHook("OnError", config.ThreadID)
+	r.hooks.OnError(config.Context, config.ThreadID, node, r.threadState(config.ThreadID), err)
+}
+
+func (r *runtimeImpl[T]) onComplete(node g.Node[T], config g.InvokeConfig, state T) {
+	if r.hooks.OnComplete == nil {
+		return
+	}
+	defer r.recoverHook("OnComplete", config.ThreadID)
+	r.hooks.OnComplete(config.Context, config.ThreadID, node.Name(), r.clone(state))
+}
+
+func (r *runtimeImpl[T]) recoverHook(name string, threadID string) {
+	if rec := recover(); rec != nil {
+		r.reportNonFatal("Hooks", threadID, fmt.Errorf("%s hook: %w: %v", name, g.ErrHookPanic, rec))
+	}
+}
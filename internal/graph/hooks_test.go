@@ -0,0 +1,151 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+type hookRecorder struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (h *hookRecorder) record(format string, args ...any) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.events = append(h.events, fmt.Sprintf(format, args...))
+}
+
+func (h *hookRecorder) snapshot() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.events...)
+}
+
+func recordingHooks(recorder *hookRecorder) g.Hooks[RuntimeTestState] {
+	return g.Hooks[RuntimeTestState]{
+		BeforeNode: func(ctx context.Context, threadID, node string, state RuntimeTestState) error {
+			recorder.record("before %s %q", node, state.Value)
+			return nil
+		},
+		AfterNode: func(ctx context.Context, threadID, node string, state RuntimeTestState) error {
+			recorder.record("after %s %q", node, state.Value)
+			return nil
+		},
+		OnRoute: func(ctx context.Context, threadID, from, to string, state RuntimeTestState) {
+			recorder.record("route %s->%s", from, to)
+		},
+		OnError: func(ctx context.Context, threadID, node string, state RuntimeTestState, err error) {
+			recorder.record("error %s", node)
+		},
+		OnComplete: func(ctx context.Context, threadID, node string, state RuntimeTestState) {
+			recorder.record("complete %s %q", node, state.Value)
+		},
+	}
+}
+
+// TestRuntime_Hooks tests that the hooks are called along the execution of the thread
+func TestRuntime_Hooks(t *testing.T) {
+	recorder := &hookRecorder{}
+	runtime, stateMonitorCh := newNodeTestRuntime(t, func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		return RuntimeTestState{Value: "done"}, nil
+	}, &g.RuntimeOptions[RuntimeTestState]{Hooks: recordingHooks(recorder)})
+
+	runtime.Invoke(RuntimeTestState{})
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if entry.Error != nil {
+		t.Fatalf("Unexpected error %v", entry.Error)
+	}
+
+	expected := []string{
+		`before StartNode ""`,
+		`after StartNode ""`,
+		`route StartNode->Node1`,
+		`before Node1 ""`,
+		`after Node1 "done"`,
+		`route Node1->EndNode`,
+		`before EndNode "done"`,
+		`after EndNode "done"`,
+		`complete EndNode "done"`,
+	}
+	events := recorder.snapshot()
+	if fmt.Sprint(events) != fmt.Sprint(expected) {
+		t.Errorf("Expected events %v, got %v", expected, events)
+	}
+}
+
+// TestRuntime_Hooks_NodeError tests that OnError is called when a node fails
+func TestRuntime_Hooks_NodeError(t *testing.T) {
+	errBoom := errors.New("boom")
+	recorder := &hookRecorder{}
+	runtime, stateMonitorCh := newNodeTestRuntime(t, func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		return currentState, errBoom
+	}, &g.RuntimeOptions[RuntimeTestState]{Hooks: recordingHooks(recorder)})
+
+	runtime.Invoke(RuntimeTestState{})
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if !errors.Is(entry.Error, errBoom) {
+		t.Fatalf("Expected the node error, got %v", entry.Error)
+	}
+
+	events := recorder.snapshot()
+	if len(events) == 0 || events[len(events)-1] != "error Node1" {
+		t.Errorf("Expected the last event to be the error of Node1, got %v", events)
+	}
+}
+
+// TestRuntime_Hooks_Validation tests that an error returned by a hook fails the invocation
+func TestRuntime_Hooks_Validation(t *testing.T) {
+	errInvalid := errors.New("invalid state")
+	var executed atomic.Bool
+	var failedNode string
+	runtime, stateMonitorCh := newNodeTestRuntime(t, func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		executed.Store(true)
+		return currentState, nil
+	}, &g.RuntimeOptions[RuntimeTestState]{Hooks: g.Hooks[RuntimeTestState]{
+		BeforeNode: func(ctx context.Context, threadID, node string, state RuntimeTestState) error {
+			if node == "Node1" {
+				return errInvalid
+			}
+			return nil
+		},
+		OnError: func(ctx context.Context, threadID, node string, state RuntimeTestState, err error) {
+			failedNode = node
+		},
+	}})
+
+	runtime.Invoke(RuntimeTestState{})
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if !errors.Is(entry.Error, errInvalid) {
+		t.Fatalf("Expected the validation error, got %v", entry.Error)
+	}
+	if executed.Load() {
+		t.Error("Expected Node1 not to be executed")
+	}
+	if failedNode != "Node1" {
+		t.Errorf("Expected OnError for Node1, got %q", failedNode)
+	}
+}
+
+// TestRuntime_Hooks_Panic tests that a panicking hook fails the invocation instead of the runtime
+func TestRuntime_Hooks_Panic(t *testing.T) {
+	runtime, stateMonitorCh := newNodeTestRuntime(t, func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		return currentState, nil
+	}, &g.RuntimeOptions[RuntimeTestState]{Hooks: g.Hooks[RuntimeTestState]{
+		AfterNode: func(ctx context.Context, threadID, node string, state RuntimeTestState) error {
+			panic("unexpected")
+		},
+	}})
+
+	runtime.Invoke(RuntimeTestState{})
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if !errors.Is(entry.Error, g.ErrHookPanic) {
+		t.Fatalf("Expected ErrHookPanic, got %v", entry.Error)
+	}
+}
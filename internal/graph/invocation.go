@@ -0,0 +1,348 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+type invocation struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	stop   func()
+	done   chan struct{}
+	once   sync.Once
+
+	startedAt time.Time
+	admitted  atomic.Bool
+	// suspended marks the invocation waiting without its admission and tenant slots, ended the terminated one.
+	slotsMu   sync.Mutex
+	suspended bool
+	ended     bool
+	// stepping pauses the invocation before every node, see WithDebugger.
+	stepping atomic.Bool
+
+	// tenant is the tenant the invocation belongs to, steps its node executions counted against the quota.
+	tenant string
+	steps  atomic.Int64
+
+	// graph is the *graphVersion[T] the invocation started with.
+	graph        any
+	graphVersion uint64
+
+	usageMu sync.Mutex
+	usage   g.Usage
+	budget  g.Budget
+
+	visitsMu sync.Mutex
+	visits   map[string]int
+
+	basesMu sync.Mutex
+	bases   map[string]stateBase
+
+	// resume is the *resumePoint[T] of a resumed invocation, journalSteps the steps journaled so far.
+	resume       any
+	journalSteps atomic.Int64
+
+	// saga is the *saga[T] of the compensable nodes completed so far, see WithCompensation.
+	sagaMu sync.Mutex
+	saga   any
+
+	// inbound is the payload sent to the current node, outbound the one it sends to the next, see SendPayload.
+	payloadMu sync.Mutex
+	inbound   edgePayload
+	outbound  edgePayload
+}
+
+// addUsage accounts the usage of a model call, cancelling the invocation once its budget is exceeded.
+func (i *invocation) addUsage(usage g.Usage) {
+	i.usageMu.Lock()
+	i.usage = i.usage.Add(usage)
+	total := i.usage
+	i.usageMu.Unlock()
+
+	if err := i.budget.Check(total); err != nil {
+		i.cancel(err)
+	}
+}
+
+func (i *invocation) currentUsage() g.Usage {
+	i.usageMu.Lock()
+	defer i.usageMu.Unlock()
+	return i.usage
+}
+
+// visit counts an execution of the node, returning the number of executions so far.
+func (i *invocation) visit(node string) int {
+	i.visitsMu.Lock()
+	defer i.visitsMu.Unlock()
+	if i.visits == nil {
+		i.visits = make(map[string]int)
+	}
+	i.visits[node]++
+	return i.visits[node]
+}
+
+// recordBase records the state the node is dispatched with, replacing the one of a previous execution.
+func (i *invocation) recordBase(node string, base stateBase) {
+	i.basesMu.Lock()
+	defer i.basesMu.Unlock()
+	if i.bases == nil {
+		i.bases = make(map[string]stateBase)
+	}
+	i.bases[node] = base
+}
+
+// takeBase returns and forgets the state the node was dispatched with.
+func (i *invocation) takeBase(node string) (stateBase, bool) {
+	i.basesMu.Lock()
+	defer i.basesMu.Unlock()
+	base, ok := i.bases[node]
+	delete(i.bases, node)
+	return base, ok
+}
+
+func (i *invocation) end() {
+	i.once.Do(func() {
+		i.cancel(nil)
+		i.stop()
+		close(i.done)
+	})
+}
+
+func (r *runtimeImpl[T]) Cancel(threadID string) error {
+	inv, ok := r.invocations.Load(threadID)
+	if !ok {
+		return fmt.Errorf("cannot cancel thread %s: %w", threadID, g.ErrThreadNotExecuting)
+	}
+	useInvocation := inv.(*invocation)
+	useInvocation.cancel(g.ErrThreadCancelled)
+	r.abortInvocation(threadID, useInvocation, g.ErrThreadCancelled)
+	return nil
+}
+
+// abortInvocation terminates the cancelled invocation: a queued one is withdrawn, otherwise the current node
+// is given the graceful shutdown timeout to abort before the thread is released.
+func (r *runtimeImpl[T]) abortInvocation(threadID string, useInvocation *invocation, cause error) {
+	cause = limitError("Runtime", threadID, cause)
+	if r.abortPaused(threadID, cause) || r.abortSleeping(threadID, cause) || r.abortParked(threadID, cause) || r.abortBatching(threadID, cause) || r.withdrawQueued(threadID, useInvocation, cause) {
+		return
+	}
+
+	timer := time.NewTimer(r.settings.GracefulShutdownTimeout)
+	defer timer.Stop()
+
+	select {
+	case <-useInvocation.done:
+		return
+	case <-r.ctx.Done():
+		return
+	case <-timer.C:
+	}
+
+	// The current node did not abort in time: release the thread, its late outcome is discarded.
+	r.releaseInvocation(threadID, useInvocation, fmt.Errorf("thread %s forcibly released: %w", threadID, cause))
+}
+
+// withdrawQueued terminates the invocation of the thread if it waits for admission, it returns false otherwise.
+func (r *runtimeImpl[T]) withdrawQueued(threadID string, useInvocation *invocation, cause error) bool {
+	if !r.admission.remove(threadID) {
+		return false
+	}
+	r.failInvocation("Runtime", g.InvokeConfig{ThreadID: threadID, Context: useInvocation.ctx}, fmt.Errorf("queued invocation of thread %s withdrawn: %w", threadID, cause))
+	return true
+}
+
+// releaseInvocation terminates the invocation without waiting for its current node.
+func (r *runtimeImpl[T]) releaseInvocation(threadID string, useInvocation *invocation, err error) {
+	if r.endInvocation(threadID, useInvocation.ctx, err) {
+		r.flushState("Runtime", threadID)
+		r.onError("Runtime", g.InvokeConfig{ThreadID: threadID, Context: useInvocation.ctx}, err)
+		r.logger.Error("invocation failed", logAttrThreadID, threadID, logAttrNode, "Runtime", "error", err, "duration", time.Since(useInvocation.startedAt))
+		entry := monitorError[T]("Runtime", threadID, err)
+		entry.Usage = useInvocation.currentUsage()
+		r.sendMonitorEntry(entry)
+		r.executingByThreadID(g.InvokeConfig{ThreadID: threadID}).Store(false)
+		r.clearThread(threadID)
+	}
+}
+
+// limitError types the cause of a stopped invocation when it is the budget or the timeout, see BudgetError.
+func limitError(node, threadID string, cause error) error {
+	var budgetErr *g.BudgetError
+	switch {
+	case errors.As(cause, &budgetErr):
+		return cause
+	case errors.Is(cause, g.ErrBudgetExceeded):
+		return &g.BudgetError{Node: node, ThreadID: threadID, Code: g.CodeBudgetExceeded, Err: cause}
+	case errors.Is(cause, g.ErrInvocationTimeout):
+		return &g.BudgetError{Node: node, ThreadID: threadID, Code: g.CodeInvocationTimeout, Err: cause}
+	default:
+		return cause
+	}
+}
+
+func (r *runtimeImpl[T]) beginInvocation(config g.InvokeConfig) g.InvokeConfig {
+	graph := r.graphFor(config)
+	r.recordVersionStart(graph.version)
+	inv := &invocation{done: make(chan struct{}), startedAt: time.Now(), graph: graph, graphVersion: graph.version, budget: config.Budget, tenant: config.Tenant}
+	r.timeline.begin(config.ThreadID, graph.version, inv.startedAt)
+	ctx := g.ContextWithUsageReporter(g.ContextWithThreadID(config.Context, config.ThreadID), inv.addUsage)
+	ctx = g.ContextWithOnceStore(ctx, r.onceStore)
+	ctx = g.ContextWithPayloadCarrier(ctx, inv)
+	ctx = g.ContextWithValues(ctx, config.Values)
+	ctx = g.ContextWithScratchpad(ctx, r.Scratch(config.ThreadID))
+	if config.Tenant != "" {
+		ctx = g.ContextWithTenant(ctx, config.Tenant)
+	}
+	stopTimeout := func() {}
+	if config.Timeout > 0 {
+		ctx, stopTimeout = context.WithTimeoutCause(ctx, config.Timeout, fmt.Errorf("%w after %s", g.ErrInvocationTimeout, config.Timeout))
+	}
+	inv.ctx, inv.cancel = context.WithCancelCause(ctx)
+	inv.stepping.Store(r.debug && len(r.breakpoints) == 0)
+	r.invocations.Store(config.ThreadID, inv)
+
+	// The invocations exceeding their limits are aborted like the cancelled ones.
+	stopAbort := context.AfterFunc(inv.ctx, func() {
+		cause := context.Cause(inv.ctx)
+		if errors.Is(cause, g.ErrInvocationTimeout) || errors.Is(cause, g.ErrBudgetExceeded) {
+			r.abortInvocation(config.ThreadID, inv, cause)
+		}
+	})
+	inv.stop = func() {
+		stopAbort()
+		stopTimeout()
+	}
+
+	config.Context = inv.ctx
+	return config
+}
+
+// endInvocation terminates the invocation of the thread owning the given context with its error, if any; it returns
+// false if the invocation was already terminated, concurrently too.
+func (r *runtimeImpl[T]) endInvocation(threadID string, ctx context.Context, err error) bool {
+	inv, ok := r.claimInvocation(threadID, ctx)
+	if ok {
+		r.finishInvocation(threadID, inv, err)
+	}
+	return ok
+}
+
+// claimInvocation removes the invocation of the thread owning the given context, so that only one termination goes on;
+// it returns false if the invocation was already terminated, concurrently too. The caller finishes the invocation.
+func (r *runtimeImpl[T]) claimInvocation(threadID string, ctx context.Context) (*invocation, bool) {
+	inv, ok := r.invocations.Load(threadID)
+	if !ok || inv.(*invocation).ctx != ctx {
+		return nil, false
+	}
+	if !r.invocations.CompareAndDelete(threadID, inv) {
+		return nil, false
+	}
+	return inv.(*invocation), true
+}
+
+// finishInvocation ends the claimed invocation and releases its resources.
+func (r *runtimeImpl[T]) finishInvocation(threadID string, inv *invocation, err error) {
+	inv.end()
+	inv.slotsMu.Lock()
+	inv.ended = true
+	holding := !inv.suspended
+	inv.slotsMu.Unlock()
+	if holding {
+		r.releaseTenant(inv.tenant)
+	}
+	r.recordVersionEnd(inv, err != nil)
+	r.timeline.end(threadID, err)
+	r.nodeStats.forget(threadID)
+	r.forgetRemoteCalls(threadID, inv.ctx)
+	r.scheduleLeaseRelease(threadID)
+	if holding && inv.admitted.Load() {
+		r.releaseAdmission()
+	}
+}
+
+// suspendInvocation releases the admission and tenant slots of the invocation while its thread waits, e.g. for a
+// signal, so that the other invocations run meanwhile; resumeInvocation takes them back.
+func (r *runtimeImpl[T]) suspendInvocation(config g.InvokeConfig) {
+	inv, ok := r.invocations.Load(config.ThreadID)
+	if !ok || inv.(*invocation).ctx != config.Context {
+		return
+	}
+	useInvocation := inv.(*invocation)
+	useInvocation.slotsMu.Lock()
+	if useInvocation.ended || useInvocation.suspended {
+		useInvocation.slotsMu.Unlock()
+		return
+	}
+	useInvocation.suspended = true
+	admitted := useInvocation.admitted.Swap(false)
+	useInvocation.slotsMu.Unlock()
+
+	r.releaseTenant(useInvocation.tenant)
+	if admitted {
+		r.releaseAdmission()
+	}
+}
+
+// resumeInvocation re-admits the suspended invocation, then calls resume; the invocation waits in the admission queue
+// when the runtime runs as many invocations as it admits, its tenant counts it again whatever its quota.
+func (r *runtimeImpl[T]) resumeInvocation(config g.InvokeConfig, resume func()) {
+	inv, ok := r.invocations.Load(config.ThreadID)
+	if !ok || inv.(*invocation).ctx != config.Context {
+		resume()
+		return
+	}
+	useInvocation := inv.(*invocation)
+	useInvocation.slotsMu.Lock()
+	suspended := useInvocation.suspended
+	useInvocation.slotsMu.Unlock()
+	if !suspended {
+		resume()
+		return
+	}
+
+	if r.admission.reenter(config.ThreadID, config.Priority, func(time.Duration) { r.readmit(useInvocation, resume) }) {
+		r.readmit(useInvocation, resume)
+	}
+}
+
+// readmit gives back its slots to the suspended invocation and resumes it, unless it was terminated meanwhile.
+func (r *runtimeImpl[T]) readmit(inv *invocation, resume func()) {
+	inv.slotsMu.Lock()
+	if inv.ended {
+		inv.slotsMu.Unlock()
+		r.releaseAdmission()
+		return
+	}
+	inv.suspended = false
+	inv.admitted.Store(true)
+	if inv.tenant != "" {
+		r.tenantUsage(inv.tenant).running.Add(1)
+	}
+	inv.slotsMu.Unlock()
+	resume()
+}
+
+// invocationUsage returns the usage reported so far by the current invocation of the thread.
+func (r *runtimeImpl[T]) invocationUsage(threadID string) g.Usage {
+	if inv, ok := r.invocations.Load(threadID); ok {
+		return inv.(*invocation).currentUsage()
+	}
+	return g.Usage{}
+}
+
+// isStaleOutcome reports whether the outcome belongs to an invocation which is already terminated.
+func (r *runtimeImpl[T]) isStaleOutcome(config g.InvokeConfig) bool {
+	inv, ok := r.invocations.Load(config.ThreadID)
+	if ok {
+		return inv.(*invocation).ctx != config.Context
+	}
+	cause := context.Cause(config.Context)
+	return errors.Is(cause, g.ErrThreadCancelled) || errors.Is(cause, g.ErrInvocationTimeout) || errors.Is(cause, g.ErrBudgetExceeded)
+}
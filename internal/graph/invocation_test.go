@@ -0,0 +1,240 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// TestRuntime_Cancel tests that cancelling a thread aborts the running node and releases the thread
+func TestRuntime_Cancel(t *testing.T) {
+	started := make(chan struct{}, 1)
+	runtime, stateMonitorCh := newNodeTestRuntime(t, func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		if userInput.Value == "fast" {
+			return currentState, nil
+		}
+		started <- struct{}{}
+		<-ctx.Done()
+		return currentState, context.Cause(ctx)
+	}, &g.RuntimeOptions[RuntimeTestState]{})
+
+	if err := runtime.Cancel("missing"); !errors.Is(err, g.ErrThreadNotExecuting) {
+		t.Errorf("Expected ErrThreadNotExecuting, got %v", err)
+	}
+
+	threadID := runtime.Invoke(RuntimeTestState{}, g.InvokeConfigThreadID("cancel-me"))
+	<-started
+
+	if err := runtime.Cancel(threadID); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if !errors.Is(entry.Error, g.ErrThreadCancelled) {
+		t.Errorf("Expected ErrThreadCancelled, got %v", entry.Error)
+	}
+
+	if err := runtime.Cancel(threadID); !errors.Is(err, g.ErrThreadNotExecuting) {
+		t.Errorf("Expected ErrThreadNotExecuting after cancellation, got %v", err)
+	}
+
+	runtime.Invoke(RuntimeTestState{Value: "fast"}, g.InvokeConfigThreadID(threadID))
+	entry = waitTerminalEntry(t, stateMonitorCh)
+	if entry.Error != nil {
+		t.Errorf("Expected the thread to be invocable again, got %v", entry.Error)
+	}
+}
+
+// TestRuntime_Cancel_ForcedRelease tests that a node ignoring the cancellation does not hold the thread forever
+func TestRuntime_Cancel_ForcedRelease(t *testing.T) {
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	runtime, stateMonitorCh := newNodeTestRuntime(t, func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		started <- struct{}{}
+		<-release
+		return currentState, nil
+	}, &g.RuntimeOptions[RuntimeTestState]{Settings: g.RuntimeSettings{GracefulShutdownTimeout: 50 * time.Millisecond}})
+
+	threadID := runtime.Invoke(RuntimeTestState{}, g.InvokeConfigThreadID("stubborn"))
+	<-started
+
+	if err := runtime.Cancel(threadID); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if !errors.Is(entry.Error, g.ErrThreadCancelled) {
+		t.Errorf("Expected ErrThreadCancelled, got %v", entry.Error)
+	}
+	if _, err := runtime.DescribeThread(threadID); !errors.Is(err, g.ErrUnknownThreadID) {
+		t.Errorf("Expected the thread to be released, got %v", err)
+	}
+
+	close(release)
+
+	select {
+	case entry := <-stateMonitorCh:
+		t.Errorf("Expected the late outcome to be discarded, got %+v", entry)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+// TestRuntime_TerminateFailed_Once tests that a failure racing with another termination is neither notified twice
+// nor releases the thread owned by a later invocation
+func TestRuntime_TerminateFailed_Once(t *testing.T) {
+	runtime, stateMonitorCh, started, release := newBlockingTestRuntime(t, &g.RuntimeOptions[RuntimeTestState]{})
+	invocationConfig := func(threadID string) g.InvokeConfig {
+		inv, ok := runtime.invocations.Load(threadID)
+		if !ok {
+			t.Fatalf("Expected thread %s to be executing", threadID)
+		}
+		return g.InvokeConfig{ThreadID: threadID, Context: inv.(*invocation).ctx}
+	}
+	noTerminalEntry := func(what string) {
+		t.Helper()
+		timeout := time.After(100 * time.Millisecond)
+		for {
+			select {
+			case entry := <-stateMonitorCh:
+				if !entry.Running {
+					t.Fatalf("Expected no terminal entry %s, got %+v", what, entry)
+				}
+			case <-timeout:
+				return
+			}
+		}
+	}
+
+	threadID := runtime.Invoke(RuntimeTestState{}, g.InvokeConfigThreadID("racing"))
+	waitStarted(t, started)
+	stale := invocationConfig(threadID)
+
+	runtime.terminateFailed("Node1", stale, errors.New("node failure"))
+	if entry := waitTerminalEntry(t, stateMonitorCh); entry.Error == nil {
+		t.Fatalf("Expected the failure to be notified, got %+v", entry)
+	}
+	runtime.terminateFailed("Runtime", stale, g.ErrThreadCancelled)
+	noTerminalEntry("for the terminated invocation")
+
+	runtime.Invoke(RuntimeTestState{}, g.InvokeConfigThreadID(threadID))
+	waitStarted(t, started)
+	runtime.failInvocation("Node1", stale, errors.New("late failure"))
+	noTerminalEntry("for the stale invocation")
+	if _, ok := runtime.invocations.Load(threadID); !ok {
+		t.Fatal("Expected the later invocation to keep the thread")
+	}
+
+	close(release)
+	if entry := waitTerminalEntry(t, stateMonitorCh); entry.Error != nil {
+		t.Errorf("Expected the later invocation to complete, got %v", entry.Error)
+	}
+}
+
+// TestRuntime_Usage tests that the usage reported by the nodes accumulates into the monitor entries of the invocation
+func TestRuntime_Usage(t *testing.T) {
+	runtime, stateMonitorCh := newNodeTestRuntime(t, func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		g.ReportUsage(ctx, g.Usage{PromptTokens: 10, CompletionTokens: 2, TotalTokens: 12})
+		g.ReportUsage(ctx, g.Usage{PromptTokens: 5, CompletionTokens: 1, TotalTokens: 6})
+		return currentState, nil
+	}, &g.RuntimeOptions[RuntimeTestState]{})
+
+	threadID := runtime.Invoke(RuntimeTestState{})
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if entry.Usage != (g.Usage{PromptTokens: 15, CompletionTokens: 3, TotalTokens: 18}) {
+		t.Errorf("Unexpected usage %+v", entry.Usage)
+	}
+
+	runtime.Invoke(RuntimeTestState{}, g.InvokeConfigThreadID(threadID))
+	entry = waitTerminalEntry(t, stateMonitorCh)
+	if entry.Usage.TotalTokens != 18 {
+		t.Errorf("Expected the usage to restart with the invocation, got %+v", entry.Usage)
+	}
+}
+
+// TestRuntime_InvokeValues tests that the nodes read the values of their invocation from their context
+func TestRuntime_InvokeValues(t *testing.T) {
+	locale := g.NewValueKey[string]("locale")
+	runtime, stateMonitorCh := newNodeTestRuntime(t, func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		currentState.Value, _ = g.ValueFrom(ctx, locale)
+		return currentState, nil
+	}, &g.RuntimeOptions[RuntimeTestState]{})
+
+	threadID := runtime.Invoke(RuntimeTestState{}, g.InvokeConfigValue(locale, "it"))
+	if entry := waitTerminalEntry(t, stateMonitorCh); entry.NewState.Value != "it" {
+		t.Errorf("Expected the locale of the invocation, got %q", entry.NewState.Value)
+	}
+
+	runtime.Invoke(RuntimeTestState{}, g.InvokeConfigThreadID(threadID))
+	if entry := waitTerminalEntry(t, stateMonitorCh); entry.NewState.Value != "" {
+		t.Errorf("Expected the values not to outlive their invocation, got %q", entry.NewState.Value)
+	}
+}
+
+// TestRuntime_InvokeTimeout tests that an invocation exceeding its timeout terminates with ErrInvocationTimeout
+func TestRuntime_InvokeTimeout(t *testing.T) {
+	runtime, stateMonitorCh := newNodeTestRuntime(t, func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		<-ctx.Done()
+		return currentState, context.Cause(ctx)
+	}, &g.RuntimeOptions[RuntimeTestState]{})
+
+	runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTimeout(50*time.Millisecond))
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if !errors.Is(entry.Error, g.ErrInvocationTimeout) {
+		t.Errorf("Expected ErrInvocationTimeout, got %v", entry.Error)
+	}
+	if entry.ErrorCode != g.CodeInvocationTimeout {
+		t.Errorf("Expected code %s, got %s", g.CodeInvocationTimeout, entry.ErrorCode)
+	}
+}
+
+// TestRuntime_InvokeTimeout_StuckNode tests that a node ignoring its context is abandoned once the timeout expired
+func TestRuntime_InvokeTimeout_StuckNode(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	runtime, stateMonitorCh := newNodeTestRuntime(t, func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		<-release
+		return currentState, nil
+	}, &g.RuntimeOptions[RuntimeTestState]{Settings: g.RuntimeSettings{GracefulShutdownTimeout: 50 * time.Millisecond}})
+
+	threadID := runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTimeout(50*time.Millisecond))
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if !errors.Is(entry.Error, g.ErrInvocationTimeout) || entry.Node != "Runtime" {
+		t.Errorf("Expected the thread to be released with ErrInvocationTimeout, got %s: %v", entry.Node, entry.Error)
+	}
+	var budgetErr *g.BudgetError
+	if !errors.As(entry.Error, &budgetErr) || budgetErr.ThreadID != threadID || budgetErr.Code != g.CodeInvocationTimeout {
+		t.Errorf("Expected the BudgetError of thread %s, got %#v", threadID, budgetErr)
+	}
+	if err := runtime.Cancel(threadID); !errors.Is(err, g.ErrThreadNotExecuting) {
+		t.Errorf("Expected the thread to be released, got %v", err)
+	}
+}
+
+// TestRuntime_InvokeBudget tests that an agent loop exceeding its budget is stopped by its next model call
+func TestRuntime_InvokeBudget(t *testing.T) {
+	calls := 0
+	runtime, stateMonitorCh := newNodeTestRuntime(t, func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		for {
+			if err := context.Cause(ctx); err != nil {
+				return currentState, err
+			}
+			calls++
+			g.ReportUsage(ctx, g.Usage{PromptTokens: 80, CompletionTokens: 20, TotalTokens: 100})
+		}
+	}, &g.RuntimeOptions[RuntimeTestState]{})
+
+	runtime.Invoke(RuntimeTestState{}, g.InvokeConfigBudget(g.Budget{MaxTokens: 250}))
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if !errors.Is(entry.Error, g.ErrBudgetExceeded) {
+		t.Errorf("Expected ErrBudgetExceeded, got %v", entry.Error)
+	}
+	if entry.ErrorCode != g.CodeBudgetExceeded {
+		t.Errorf("Expected code %s, got %s", g.CodeBudgetExceeded, entry.ErrorCode)
+	}
+	if calls != 3 || entry.Usage.TotalTokens != 300 {
+		t.Errorf("Expected the loop to stop after 3 calls, got %d calls and %+v", calls, entry.Usage)
+	}
+}
@@ -0,0 +1,196 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// MemJournalFactory creates an in-memory Journal.
+func MemJournalFactory[T g.SharedState]() g.Journal[T] {
+	return &memJournal[T]{entries: make(map[string][]g.JournalEntry[T])}
+}
+
+// ------------------------------------------------------------------------------
+// In-Memory Journal Implementation
+// ------------------------------------------------------------------------------
+
+var _ g.Journal[g.SharedState] = (*memJournal[g.SharedState])(nil)
+
+type memJournal[T g.SharedState] struct {
+	mu      sync.RWMutex
+	entries map[string][]g.JournalEntry[T]
+}
+
+func (j *memJournal[T]) Append(_ context.Context, entry g.JournalEntry[T]) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries[entry.ThreadID] = append(j.entries[entry.ThreadID], entry)
+	return nil
+}
+
+func (j *memJournal[T]) Entries(_ context.Context, threadID string) ([]g.JournalEntry[T], error) {
+	j.mu.RLock()
+	defer j.mu.RUnlock()
+	return slices.Clone(j.entries[threadID]), nil
+}
+
+func (j *memJournal[T]) Clear(_ context.Context, threadID string) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	delete(j.entries, threadID)
+	return nil
+}
+
+// ------------------------------------------------------------------------------
+// Durable Execution
+// ------------------------------------------------------------------------------
+
+// resumePoint is the node a resumed invocation enters, with the state journaled by the last step.
+type resumePoint[T g.SharedState] struct {
+	node   g.Node[T]
+	edge   g.Edge[T]
+	state  T
+	steps  int
+	visits map[string]int
+	// saga are the compensable steps completed before the interruption.
+	saga []sagaStep[T]
+	// wakeAt is the journaled wake-up time when node is a delay node.
+	wakeAt time.Time
+	// payload is the journaled payload sent to node.
+	payload edgePayload
+}
+
+func (r *runtimeImpl[T]) Resume(threadID string, configs ...g.InvokeConfig) error {
+	requestedConfig := g.MergeInvokeConfig(configs...)
+	if requestedConfig.Tenant != "" {
+		if err := g.ValidateTenant(requestedConfig.Tenant); err != nil {
+			return fmt.Errorf("cannot resume thread %s: %w", threadID, err)
+		}
+	}
+	threadID = g.TenantThreadID(requestedConfig.Tenant, threadID)
+	if r.journal == nil {
+		return fmt.Errorf("cannot resume thread %s: %w", threadID, g.ErrJournalNotSet)
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, r.settings.PersistenceJobTimeout)
+	defer cancel()
+	entries, err := r.journal.Entries(ctx, threadID)
+	if err != nil {
+		return fmt.Errorf("cannot resume thread %s: %w", threadID, err)
+	}
+	if len(entries) == 0 {
+		return fmt.Errorf("cannot resume thread %s: %w", threadID, g.ErrNothingToResume)
+	}
+
+	last := entries[len(entries)-1]
+	node, edge := r.graph.Load().route(last.Node, last.Next)
+	if node == nil {
+		return fmt.Errorf("cannot resume thread %s from %s to %s: %w", threadID, last.Node, last.Next, g.ErrJournalDivergence)
+	}
+	point := &resumePoint[T]{node: node, edge: edge, state: last.State, steps: len(entries), visits: make(map[string]int), wakeAt: last.WakeAt}
+	point.payload = edgePayload{value: last.Payload, set: last.PayloadSent}
+	for _, entry := range entries {
+		point.visits[entry.Next]++
+		if _, journaled := r.graph.Load().route(entry.Node, entry.Next); journaled != nil {
+			if step, ok := compensableStep(journaled.From(), entry.UserInput); ok {
+				point.saga = append(point.saga, step)
+			}
+		}
+	}
+
+	requestedConfig.ThreadID = threadID
+	if _, err := r.invoke(last.UserInput, requestedConfig, point); err != nil {
+		return fmt.Errorf("cannot resume thread %s: %w", threadID, err)
+	}
+	return nil
+}
+
+// route returns the node named to and the edge leading to it from the node named from.
+func (v *graphVersion[T]) route(from, to string) (g.Node[T], g.Edge[T]) {
+	for _, edge := range append([]g.Edge[T]{v.startEdge}, v.edges...) {
+		if edge.From() != nil && edge.To() != nil && edge.From().Name() == from && edge.To().Name() == to {
+			return edge.To(), edge
+		}
+	}
+	return nil, nil
+}
+
+// startFrom prepares the invocation to enter the node of the resume point, the thread taking its state.
+func (r *runtimeImpl[T]) startFrom(config g.InvokeConfig, point *resumePoint[T]) {
+	r.state.Store(config.ThreadID, r.clone(point.state))
+	r.bumpStateVersion(config.ThreadID)
+	if inv, ok := r.invocations.Load(config.ThreadID); ok && inv.(*invocation).ctx == config.Context {
+		useInvocation := inv.(*invocation)
+		useInvocation.resume = point
+		useInvocation.journalSteps.Store(int64(point.steps))
+		useInvocation.visitsMu.Lock()
+		useInvocation.visits = point.visits
+		useInvocation.visitsMu.Unlock()
+		useInvocation.sagaMu.Lock()
+		useInvocation.saga = &saga[T]{steps: point.saga}
+		useInvocation.sagaMu.Unlock()
+		useInvocation.payloadMu.Lock()
+		useInvocation.inbound = point.payload
+		useInvocation.payloadMu.Unlock()
+	}
+}
+
+// resumePointOf returns the resume point of the invocation owning the configuration, if it is resumed.
+func (r *runtimeImpl[T]) resumePointOf(config g.InvokeConfig) *resumePoint[T] {
+	if inv, ok := r.invocations.Load(config.ThreadID); ok && inv.(*invocation).ctx == config.Context {
+		if point, ok := inv.(*invocation).resume.(*resumePoint[T]); ok {
+			return point
+		}
+	}
+	return nil
+}
+
+// journalStep records the completion of the node and its routing decision, before the next node is entered.
+func (r *runtimeImpl[T]) journalStep(result nodeFnReturnStruct[T], next g.Node[T], wakeAt time.Time) error {
+	if r.journal == nil {
+		return nil
+	}
+	threadID := result.config.ThreadID
+	step := 1
+	var payload edgePayload
+	if inv, ok := r.invocations.Load(threadID); ok && inv.(*invocation).ctx == result.config.Context {
+		step = int(inv.(*invocation).journalSteps.Add(1))
+		payload = inv.(*invocation).sentPayload()
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, r.settings.PersistenceJobTimeout)
+	defer cancel()
+	entry := g.JournalEntry[T]{
+		ThreadID:    threadID,
+		Step:        step,
+		Node:        result.node.Name(),
+		Next:        next.Name(),
+		State:       r.committedState(threadID),
+		UserInput:   r.clone(result.userInput),
+		RecordedAt:  time.Now(),
+		WakeAt:      wakeAt,
+		Payload:     payload.value,
+		PayloadSent: payload.set,
+	}
+	if err := r.journal.Append(ctx, entry); err != nil {
+		return &g.PersistenceError{Node: result.node.Name(), ThreadID: threadID, Code: g.CodePersistenceFailed, Err: fmt.Errorf("cannot journal step %d: %w", step, err)}
+	}
+	return nil
+}
+
+// clearJournal forgets the steps of the thread, once its invocation completed or a new one starts.
+func (r *runtimeImpl[T]) clearJournal(threadID string) {
+	if r.journal == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.ctx, r.settings.PersistenceJobTimeout)
+	defer cancel()
+	if err := r.journal.Clear(ctx, threadID); err != nil {
+		r.reportNonFatal("Journal", threadID, &g.PersistenceError{Node: "Journal", ThreadID: threadID, Code: g.CodePersistenceFailed, Err: fmt.Errorf("cannot clear the journal: %w", err)})
+	}
+}
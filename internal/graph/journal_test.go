@@ -0,0 +1,182 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// failingJournal refuses to record the steps.
+type failingJournal struct {
+	g.Journal[RuntimeTestState]
+}
+
+func (j *failingJournal) Append(ctx context.Context, entry g.JournalEntry[RuntimeTestState]) error {
+	return errors.New("journal down")
+}
+
+// TestMemJournal tests the append, read and clear of the steps
+func TestMemJournal(t *testing.T) {
+	journal := MemJournalFactory[RuntimeTestState]()
+	ctx := context.Background()
+
+	_ = journal.Append(ctx, g.JournalEntry[RuntimeTestState]{ThreadID: "thread", Step: 1, Node: "A", Next: "B"})
+	_ = journal.Append(ctx, g.JournalEntry[RuntimeTestState]{ThreadID: "thread", Step: 2, Node: "B", Next: "C"})
+	_ = journal.Append(ctx, g.JournalEntry[RuntimeTestState]{ThreadID: "other", Step: 1, Node: "A", Next: "B"})
+
+	entries, _ := journal.Entries(ctx, "thread")
+	if len(entries) != 2 || entries[1].Next != "C" {
+		t.Fatalf("Expected the steps of the thread in order, got %+v", entries)
+	}
+
+	_ = journal.Clear(ctx, "thread")
+	if entries, _ := journal.Entries(ctx, "thread"); len(entries) != 0 {
+		t.Errorf("Expected the steps to be cleared, got %+v", entries)
+	}
+	if entries, _ := journal.Entries(ctx, "other"); len(entries) != 1 {
+		t.Errorf("Expected the steps of the other thread to be kept, got %+v", entries)
+	}
+}
+
+// TestRuntime_Resume tests that the invocation is resumed after its last completed node, which is not executed again
+func TestRuntime_Resume(t *testing.T) {
+	journal := MemJournalFactory[RuntimeTestState]()
+	var charges atomic.Int32
+	charge := func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		charges.Add(1)
+		currentState.Value = "charged " + userInput.Value
+		return currentState, nil
+	}
+
+	crashedCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	crashed := newTestRuntime(t, crashedCh, &g.RuntimeOptions[RuntimeTestState]{Journal: journal}, testNode("Charge", charge), testNode("Ship", func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		return currentState, errors.New("process crashed")
+	}))
+	threadID := crashed.Invoke(RuntimeTestState{Value: "order-1"})
+	if entry := waitTerminalEntry(t, crashedCh); entry.Error == nil {
+		t.Fatal("Expected the invocation to be interrupted")
+	}
+
+	entries, _ := journal.Entries(context.Background(), threadID)
+	if len(entries) != 2 || entries[1].Node != "Charge" || entries[1].Next != "Ship" || entries[1].State.Value != "charged order-1" {
+		t.Fatalf("Expected the routing decisions to be journaled, got %+v", entries)
+	}
+
+	restartedCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	restarted := newTestRuntime(t, restartedCh, &g.RuntimeOptions[RuntimeTestState]{Journal: journal}, testNode("Charge", charge), testNode("Ship", func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		currentState.Counter++
+		return currentState, nil
+	}))
+	if err := restarted.Resume(threadID); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	entry := waitTerminalEntry(t, restartedCh)
+	if entry.Error != nil || entry.NewState.Value != "charged order-1" || entry.NewState.Counter != 1 {
+		t.Fatalf("Expected the invocation to complete from the journaled state, got %+v", entry)
+	}
+	if charges.Load() != 1 {
+		t.Errorf("Expected the completed node not to be executed again, executed %d times", charges.Load())
+	}
+	if entries, _ := journal.Entries(context.Background(), threadID); len(entries) != 0 {
+		t.Errorf("Expected the journal to be cleared on completion, got %+v", entries)
+	}
+	if err := restarted.Resume(threadID); !errors.Is(err, g.ErrNothingToResume) {
+		t.Errorf("Expected ErrNothingToResume, got %v", err)
+	}
+}
+
+// TestRuntime_ResumePayload tests that the resumed node receives the payload sent to it before the interruption
+func TestRuntime_ResumePayload(t *testing.T) {
+	journal := MemJournalFactory[RuntimeTestState]()
+	charge := func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		return currentState, g.SendPayload(ctx, "receipt-1")
+	}
+
+	crashedCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	crashed := newTestRuntime(t, crashedCh, &g.RuntimeOptions[RuntimeTestState]{Journal: journal}, testNode("Charge", charge), testNode("Ship", func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		return currentState, errors.New("process crashed")
+	}))
+	threadID := crashed.Invoke(RuntimeTestState{})
+	waitTerminalEntry(t, crashedCh)
+
+	entries, _ := journal.Entries(context.Background(), threadID)
+	if len(entries) != 2 || !entries[1].PayloadSent || entries[1].Payload != "receipt-1" || entries[0].PayloadSent {
+		t.Fatalf("Expected the payload to be journaled with its routing decision, got %+v", entries)
+	}
+
+	restartedCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	restarted := newTestRuntime(t, restartedCh, &g.RuntimeOptions[RuntimeTestState]{Journal: journal}, testNode("Charge", charge), testNode("Ship", func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		receipt, ok, err := g.Payload[string](ctx)
+		if err != nil || !ok {
+			return currentState, errors.New("payload not received")
+		}
+		currentState.Value = receipt
+		return currentState, nil
+	}))
+	if err := restarted.Resume(threadID); err != nil {
+		t.Fatalf("Resume failed: %v", err)
+	}
+	if entry := waitTerminalEntry(t, restartedCh); entry.Error != nil || entry.NewState.Value != "receipt-1" {
+		t.Errorf("Expected the resumed node to receive the journaled payload, got %+v", entry)
+	}
+}
+
+// TestRuntime_ResumeErrors tests the invocations which cannot be resumed
+func TestRuntime_ResumeErrors(t *testing.T) {
+	withoutJournal := newTestRuntime(t, nil, &g.RuntimeOptions[RuntimeTestState]{}, testNode("Charge", countInput), testNode("Ship", countInput))
+	if err := withoutJournal.Resume("thread"); !errors.Is(err, g.ErrJournalNotSet) {
+		t.Errorf("Expected ErrJournalNotSet, got %v", err)
+	}
+
+	journal := MemJournalFactory[RuntimeTestState]()
+	_ = journal.Append(context.Background(), g.JournalEntry[RuntimeTestState]{ThreadID: "thread", Step: 1, Node: "Charge", Next: "Refund"})
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{Journal: journal}, testNode("Charge", countInput), testNode("Ship", countInput))
+	if err := runtime.Resume("thread"); !errors.Is(err, g.ErrJournalDivergence) {
+		t.Errorf("Expected ErrJournalDivergence, got %v", err)
+	}
+
+	// A new invocation discards the journaled steps.
+	runtime.Invoke(RuntimeTestState{}, g.InvokeConfig{ThreadID: "thread"})
+	waitTerminalEntry(t, stateMonitorCh)
+	if err := runtime.Resume("thread"); !errors.Is(err, g.ErrNothingToResume) {
+		t.Errorf("Expected ErrNothingToResume, got %v", err)
+	}
+
+	// The rejections of the resumed invocation are returned.
+	_ = journal.Append(context.Background(), g.JournalEntry[RuntimeTestState]{ThreadID: "busy", Step: 1, Node: "Charge", Next: "Ship"})
+	runtime.executingByThreadID(g.InvokeConfig{ThreadID: "busy"}).Store(true)
+	if err := runtime.Resume("busy"); !errors.Is(err, g.ErrRuntimeExecuting) {
+		t.Errorf("Expected ErrRuntimeExecuting, got %v", err)
+	}
+	runtime.executingByThreadID(g.InvokeConfig{ThreadID: "busy"}).Store(false)
+	runtime.Stop(context.Background(), g.ShutdownDrain)
+	if err := runtime.Resume("busy"); !errors.Is(err, g.ErrRuntimeShuttingDown) {
+		t.Errorf("Expected ErrRuntimeShuttingDown, got %v", err)
+	}
+}
+
+// TestRuntime_JournalFailure tests that an invocation whose step cannot be journaled fails before entering the next node
+func TestRuntime_JournalFailure(t *testing.T) {
+	var executed atomic.Bool
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{Journal: &failingJournal{Journal: MemJournalFactory[RuntimeTestState]()}},
+		testNode("Charge", func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+			executed.Store(true)
+			return currentState, nil
+		}),
+		testNode("Ship", countInput),
+	)
+
+	runtime.Invoke(RuntimeTestState{})
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if entry.ErrorCode != g.CodePersistenceFailed {
+		t.Errorf("Expected %s, got %s: %v", g.CodePersistenceFailed, entry.ErrorCode, entry.Error)
+	}
+	if executed.Load() {
+		t.Error("Expected the next node not to be entered")
+	}
+}
@@ -0,0 +1,214 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// MemLeaseStoreFactory creates a LeaseStore shared by the runtimes of the same process.
+func MemLeaseStoreFactory() g.LeaseStore {
+	return &memLeaseStore{leases: make(map[string]memLease)}
+}
+
+// ------------------------------------------------------------------------------
+// In-Memory LeaseStore Implementation
+// ------------------------------------------------------------------------------
+
+var _ g.LeaseStore = (*memLeaseStore)(nil)
+
+type memLease struct {
+	owner     string
+	expiresAt time.Time
+}
+
+type memLeaseStore struct {
+	mu     sync.Mutex
+	leases map[string]memLease
+}
+
+func (s *memLeaseStore) Acquire(ctx context.Context, threadID, owner string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	if lease, ok := s.leases[threadID]; ok && lease.owner != owner && now.Before(lease.expiresAt) {
+		return false, nil
+	}
+	s.leases[threadID] = memLease{owner: owner, expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+func (s *memLeaseStore) Release(ctx context.Context, threadID, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if lease, ok := s.leases[threadID]; ok && lease.owner == owner {
+		delete(s.leases, threadID)
+	}
+	return nil
+}
+
+// ------------------------------------------------------------------------------
+// Thread ownership
+// ------------------------------------------------------------------------------
+
+// heldLease is a lease of a thread held by the runtime instance.
+type heldLease struct {
+	renewedAt atomic.Int64
+}
+
+// acquireLease acquires the lease of the thread being invoked; when the lease was not held, the
+// thread is taken over and its state restored from the memory, another instance may have executed it.
+func (r *runtimeImpl[T]) acquireLease(threadID string) error {
+	if r.leaseStore == nil {
+		return nil
+	}
+	r.leaseMu.Lock()
+	defer r.leaseMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(r.ctx, r.settings.PersistenceJobTimeout)
+	defer cancel()
+	ok, err := r.leaseStore.Acquire(ctx, threadID, r.instanceID, r.leaseTTL)
+	if err != nil {
+		return fmt.Errorf("cannot acquire the lease of thread %s: %w", threadID, err)
+	}
+	if !ok {
+		r.heldLeases.Delete(threadID)
+		return fmt.Errorf("cannot invoke graph for thread %s: %w", threadID, g.ErrThreadOwned)
+	}
+
+	lease := &heldLease{}
+	lease.renewedAt.Store(time.Now().UnixNano())
+	if _, held := r.heldLeases.Swap(threadID, lease); !held {
+		r.state.Store(threadID, r.clone(r.initialState))
+		_ = r.Restore(threadID)
+	}
+	return nil
+}
+
+// scheduleLeaseRelease releases the lease of the thread once the pending writes of the terminated invocation are persisted.
+func (r *runtimeImpl[T]) scheduleLeaseRelease(threadID string) {
+	if r.leaseStore == nil {
+		return
+	}
+	select {
+	case r.pendingPersist <- pendingPersistEntry[T]{threadID: threadID, releaseLease: true}:
+	default:
+		r.releaseLease(threadID)
+	}
+}
+
+// releaseLease releases the lease of the thread, unless a new invocation executes it meanwhile.
+func (r *runtimeImpl[T]) releaseLease(threadID string) {
+	r.leaseMu.Lock()
+	defer r.leaseMu.Unlock()
+	if r.executingByThreadID(g.InvokeConfig{ThreadID: threadID}).Load() {
+		return
+	}
+	if _, held := r.heldLeases.LoadAndDelete(threadID); !held {
+		return
+	}
+	r.giveUpLease(threadID)
+}
+
+// releaseLeases releases the leases still held on shutdown, the other instances taking the threads over without waiting for their expiry.
+func (r *runtimeImpl[T]) releaseLeases() {
+	if r.leaseStore == nil {
+		return
+	}
+	r.leaseMu.Lock()
+	defer r.leaseMu.Unlock()
+	r.heldLeases.Range(func(threadID, _ any) bool {
+		r.heldLeases.Delete(threadID)
+		r.giveUpLease(threadID.(string))
+		return true
+	})
+}
+
+func (r *runtimeImpl[T]) giveUpLease(threadID string) {
+	ctx, cancel := context.WithTimeout(context.Background(), r.settings.PersistenceJobTimeout)
+	defer cancel()
+	if err := r.leaseStore.Release(ctx, threadID, r.instanceID); err != nil {
+		r.reportNonFatal("LeaseRenewer", threadID, fmt.Errorf("cannot release the lease of thread %s: %w", threadID, err))
+	}
+}
+
+// fencedWrite handles the lease release markers of the persistence queue and discards the writes
+// of the threads whose lease was lost, it reports whether the entry was handled.
+func (r *runtimeImpl[T]) fencedWrite(entry pendingPersistEntry[T]) bool {
+	if r.leaseStore == nil {
+		return false
+	}
+	if entry.releaseLease {
+		r.releaseLease(entry.threadID)
+		return true
+	}
+	if _, held := r.heldLeases.Load(entry.threadID); held {
+		return false
+	}
+	err := fmt.Errorf("write of thread %s discarded: %w", entry.threadID, g.ErrLeaseLost)
+	r.reportNonFatal("Persistence", entry.threadID, &g.PersistenceError{Node: "Persistence", ThreadID: entry.threadID, Code: g.CodePersistenceFailed, Err: err})
+	return true
+}
+
+func (r *runtimeImpl[T]) startLeaseRenewer() {
+	r.backgroundWorkers.Add(1)
+	go r.leaseRenewer()
+}
+
+// leaseRenewer renews the held leases every third of their TTL.
+func (r *runtimeImpl[T]) leaseRenewer() {
+	defer r.backgroundWorkers.Done()
+
+	ticker := time.NewTicker(r.leaseTTL / 3)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.C:
+			var threads []string
+			r.heldLeases.Range(func(threadID, _ any) bool {
+				threads = append(threads, threadID.(string))
+				return true
+			})
+			for _, threadID := range threads {
+				r.renewLease(threadID)
+			}
+		}
+	}
+}
+
+func (r *runtimeImpl[T]) renewLease(threadID string) {
+	r.leaseMu.Lock()
+	defer r.leaseMu.Unlock()
+	lease, held := r.heldLeases.Load(threadID)
+	if !held {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, r.settings.PersistenceJobTimeout)
+	defer cancel()
+	ok, err := r.leaseStore.Acquire(ctx, threadID, r.instanceID, r.leaseTTL)
+	switch {
+	case err == nil && ok:
+		lease.(*heldLease).renewedAt.Store(time.Now().UnixNano())
+		return
+	case err != nil && time.Since(time.Unix(0, lease.(*heldLease).renewedAt.Load())) < r.leaseTTL:
+		// The lease is not expired yet, the renewal is retried on the next tick.
+		r.reportNonFatal("LeaseRenewer", threadID, fmt.Errorf("cannot renew the lease of thread %s: %w", threadID, err))
+		return
+	}
+
+	r.heldLeases.Delete(threadID)
+	cause := fmt.Errorf("thread %s: %w", threadID, g.ErrLeaseLost)
+	r.logger.Warn("thread lease lost", logAttrThreadID, threadID, "error", err)
+	if inv, ok := r.invocations.Load(threadID); ok {
+		useInvocation := inv.(*invocation)
+		useInvocation.cancel(cause)
+		go r.abortInvocation(threadID, useInvocation, cause)
+	}
+}
@@ -0,0 +1,141 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// refusingLeaseStore refuses the renewals once refuse is set, as if another instance took the threads over.
+type refusingLeaseStore struct {
+	g.LeaseStore
+	refuse atomic.Bool
+}
+
+func (s *refusingLeaseStore) Acquire(ctx context.Context, threadID, owner string, ttl time.Duration) (bool, error) {
+	if s.refuse.Load() {
+		return false, nil
+	}
+	return s.LeaseStore.Acquire(ctx, threadID, owner, ttl)
+}
+
+// leaseTestOptions returns the options of a runtime sharing the memory and the leases of the threads with the others.
+func leaseTestOptions(memory g.Memory[RuntimeTestState], store g.LeaseStore, ttl time.Duration) *g.RuntimeOptions[RuntimeTestState] {
+	return &g.RuntimeOptions[RuntimeTestState]{
+		Memory:     memory,
+		LeaseStore: store,
+		LeaseTTL:   ttl,
+		Settings:   g.RuntimeSettings{GracefulShutdownTimeout: 100 * time.Millisecond},
+	}
+}
+
+// TestMemLeaseStore tests the grant, renewal, expiry and release of the leases
+func TestMemLeaseStore(t *testing.T) {
+	store := MemLeaseStoreFactory()
+	ctx := context.Background()
+
+	if ok, _ := store.Acquire(ctx, "thread", "a", 20*time.Millisecond); !ok {
+		t.Fatal("Expected the lease to be granted")
+	}
+	if ok, _ := store.Acquire(ctx, "thread", "a", 20*time.Millisecond); !ok {
+		t.Error("Expected the lease to be renewed by its owner")
+	}
+	if ok, _ := store.Acquire(ctx, "thread", "b", time.Minute); ok {
+		t.Error("Expected the lease to be refused to another owner")
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	if ok, _ := store.Acquire(ctx, "thread", "b", time.Minute); !ok {
+		t.Fatal("Expected the expired lease to be taken over")
+	}
+
+	_ = store.Release(ctx, "thread", "a")
+	if ok, _ := store.Acquire(ctx, "thread", "a", time.Minute); ok {
+		t.Error("Expected the release by a former owner to be ignored")
+	}
+	_ = store.Release(ctx, "thread", "b")
+	if ok, _ := store.Acquire(ctx, "thread", "a", time.Minute); !ok {
+		t.Error("Expected the released lease to be granted")
+	}
+}
+
+// TestRuntime_ThreadLeases tests that a thread is executed by a single instance, the next one taking it over on the shared state
+func TestRuntime_ThreadLeases(t *testing.T) {
+	memory := MemMemoryFactory[RuntimeTestState](nil)
+	store := MemLeaseStoreFactory()
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	first, firstCh := newNodeTestRuntime(t, func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		started <- struct{}{}
+		<-release
+		currentState.Counter++
+		return currentState, nil
+	}, leaseTestOptions(memory, store, time.Minute))
+	second, secondCh := newNodeTestRuntime(t, func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		currentState.Counter++
+		return currentState, nil
+	}, leaseTestOptions(memory, store, time.Minute))
+
+	threadID := first.Invoke(RuntimeTestState{})
+	<-started
+
+	second.Invoke(RuntimeTestState{}, g.InvokeConfig{ThreadID: threadID})
+	if entry := waitTerminalEntry(t, secondCh); !errors.Is(entry.Error, g.ErrThreadOwned) {
+		t.Fatalf("Expected ErrThreadOwned, got %v", entry.Error)
+	}
+
+	close(release)
+	if entry := waitTerminalEntry(t, firstCh); entry.Error != nil || entry.NewState.Counter != 1 {
+		t.Fatalf("Expected the first instance to complete, got %+v", entry)
+	}
+	waitFor(t, "the release of the lease", func() bool {
+		_, held := first.heldLeases.Load(threadID)
+		return !held
+	})
+
+	second.Invoke(RuntimeTestState{}, g.InvokeConfig{ThreadID: threadID})
+	if entry := waitTerminalEntry(t, secondCh); entry.Error != nil || entry.NewState.Counter != 2 {
+		t.Errorf("Expected the second instance to take over the persisted state, got %+v", entry)
+	}
+}
+
+// TestRuntime_ThreadLeaseLost tests that the invocation of an instance losing the lease of its thread is aborted
+func TestRuntime_ThreadLeaseLost(t *testing.T) {
+	store := &refusingLeaseStore{LeaseStore: MemLeaseStoreFactory()}
+	started := make(chan struct{}, 1)
+	runtime, stateMonitorCh := newNodeTestRuntime(t, func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		started <- struct{}{}
+		<-ctx.Done()
+		return currentState, context.Cause(ctx)
+	}, leaseTestOptions(MemMemoryFactory[RuntimeTestState](nil), store, 30*time.Millisecond))
+
+	threadID := runtime.Invoke(RuntimeTestState{})
+	<-started
+	store.refuse.Store(true)
+
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if entry.ThreadID != threadID || !errors.Is(entry.Error, g.ErrLeaseLost) {
+		t.Errorf("Expected ErrLeaseLost, got %v", entry.Error)
+	}
+	if _, held := runtime.heldLeases.Load(threadID); held {
+		t.Error("Expected the lost lease to be forgotten")
+	}
+}
+
+// TestRuntime_ThreadLeasesRequireMemory tests that the leases cannot be set without a shared memory
+func TestRuntime_ThreadLeasesRequireMemory(t *testing.T) {
+	startNode, _ := NodeImplFactory[RuntimeTestState](g.StartNode, "StartNode", nil, &g.NodeOptions[RuntimeTestState]{})
+	node1, _ := NodeImplFactory[RuntimeTestState](g.IntermediateNode, "Node1", nil, &g.NodeOptions[RuntimeTestState]{})
+	_, err := RuntimeFactory(
+		&mockRuntimeEdge{from: startNode, to: node1, role: g.StartEdge},
+		make(chan g.StateMonitorEntry[RuntimeTestState], 1),
+		&g.RuntimeOptions[RuntimeTestState]{LeaseStore: MemLeaseStoreFactory(), LeaseTTL: time.Minute},
+	)
+	if !errors.Is(err, g.ErrLeasesWithoutMemory) {
+		t.Errorf("Expected ErrLeasesWithoutMemory, got %v", err)
+	}
+}
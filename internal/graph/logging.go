@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"log/slog"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+const (
+	logAttrThreadID = "thread_id"
+	logAttrNode     = "node"
+	logAttrTenant   = "tenant"
+)
+
+func newRuntimeLogger(logger *slog.Logger) *slog.Logger {
+	if logger == nil {
+		return slog.New(slog.DiscardHandler)
+	}
+	return logger
+}
+
+// reportNonFatal logs and notifies an error which does not terminate the invocation.
+func (r *runtimeImpl[T]) reportNonFatal(node string, threadID string, err error) {
+	r.logger.Warn("runtime non-fatal error", logAttrThreadID, threadID, logAttrNode, node, "error", err)
+	r.sendMonitorEntry(monitorNonFatalError[T](node, threadID, err))
+}
+
+func (r *runtimeImpl[T]) logInvocationFailed(node string, threadID string, err error, elapsed time.Duration) {
+	r.logger.Error("invocation failed",
+		logAttrThreadID, threadID,
+		logAttrNode, node,
+		"error", err,
+		"duration", elapsed,
+	)
+}
+
+func (r *runtimeImpl[T]) logInvocationCompleted(node string, threadID string) {
+	if !r.logger.Enabled(r.ctx, slog.LevelInfo) {
+		return
+	}
+	usage := r.invocationUsage(threadID)
+	r.logger.Info("invocation completed",
+		logAttrThreadID, threadID,
+		logAttrNode, node,
+		"duration", r.invocationElapsed(threadID),
+		"total_tokens", usage.TotalTokens,
+	)
+}
+
+func (r *runtimeImpl[T]) logRoute(from, to g.Node[T], threadID string) {
+	r.logger.Debug("node routed", logAttrThreadID, threadID, logAttrNode, from.Name(), "next", to.Name())
+}
+
+// invocationElapsed returns the time elapsed since the current invocation of the thread started.
+func (r *runtimeImpl[T]) invocationElapsed(threadID string) time.Duration {
+	if inv, ok := r.invocations.Load(threadID); ok {
+		return time.Since(inv.(*invocation).startedAt)
+	}
+	return 0
+}
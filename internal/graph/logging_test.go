@@ -0,0 +1,88 @@
+package graph
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) records(t *testing.T) []map[string]any {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var rv []map[string]any
+	for _, line := range bytes.Split(bytes.TrimSpace(b.buf.Bytes()), []byte("\n")) {
+		record := map[string]any{}
+		if err := json.Unmarshal(line, &record); err != nil {
+			t.Fatalf("Invalid log line %q: %v", line, err)
+		}
+		rv = append(rv, record)
+	}
+	return rv
+}
+
+func findRecord(records []map[string]any, msg string) map[string]any {
+	for _, record := range records {
+		if record["msg"] == msg {
+			return record
+		}
+	}
+	return nil
+}
+
+// TestRuntime_Logger tests that the runtime emits structured logs for the invocation lifecycle
+func TestRuntime_Logger(t *testing.T) {
+	buf := &syncBuffer{}
+	logger := slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+
+	runtime, stateMonitorCh := newNodeTestRuntime(t, func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		if userInput.Value == "fail" {
+			return currentState, errors.New("boom")
+		}
+		return currentState, nil
+	}, &g.RuntimeOptions[RuntimeTestState]{Logger: logger})
+
+	threadID := runtime.Invoke(RuntimeTestState{}, g.InvokeConfigThreadID("logged"))
+	waitTerminalEntry(t, stateMonitorCh)
+	runtime.Invoke(RuntimeTestState{Value: "fail"}, g.InvokeConfigThreadID(threadID))
+	waitTerminalEntry(t, stateMonitorCh)
+
+	records := buf.records(t)
+
+	started := findRecord(records, "invocation started")
+	if started == nil || started["thread_id"] != threadID || started["node"] != "StartNode" || started["level"] != "INFO" {
+		t.Errorf("Unexpected start record %v", started)
+	}
+
+	routed := findRecord(records, "node routed")
+	if routed == nil || routed["node"] != "StartNode" || routed["next"] != "Node1" || routed["level"] != "DEBUG" {
+		t.Errorf("Unexpected routing record %v", routed)
+	}
+
+	completed := findRecord(records, "invocation completed")
+	if completed == nil || completed["node"] != "EndNode" {
+		t.Errorf("Unexpected completion record %v", completed)
+	}
+
+	failed := findRecord(records, "invocation failed")
+	if failed == nil || failed["node"] != "Node1" || failed["error"] != "error executing node Node1: boom" || failed["level"] != "ERROR" {
+		t.Errorf("Unexpected failure record %v", failed)
+	}
+}
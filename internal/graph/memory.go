@@ -10,8 +10,10 @@ import (
 // MemMemoryFactory creates an in-memory Memory implementation.
 func MemMemoryFactory[T g.SharedState](opts *g.MemoryOptions) g.Memory[T] {
 	return &memMemory[T]{
-		store: make(map[string]T),
-		mu:    &sync.RWMutex{},
+		store:        make(map[string]T),
+		mu:           &sync.RWMutex{},
+		OnceStore:    MemOnceStoreFactory(),
+		ScratchStore: MemScratchStoreFactory(),
 	}
 }
 
@@ -20,10 +22,17 @@ func MemMemoryFactory[T g.SharedState](opts *g.MemoryOptions) g.Memory[T] {
 // ------------------------------------------------------------------------------
 
 var _ g.Memory[g.SharedState] = (*memMemory[g.SharedState])(nil)
+var _ g.OnceStore = (*memMemory[g.SharedState])(nil)
+var _ g.ScratchStore = (*memMemory[g.SharedState])(nil)
+var _ g.StateDeleter = (*memMemory[g.SharedState])(nil)
 
 type memMemory[T g.SharedState] struct {
 	store map[string]T
 	mu    *sync.RWMutex
+	// OnceStore records the side effects of the threads along with their states.
+	g.OnceStore
+	// ScratchStore persists the scratchpads of the threads along with their states.
+	g.ScratchStore
 }
 
 func (m *memMemory[T]) PersistFn() g.PersistFn[T] {
@@ -47,3 +56,10 @@ func (m *memMemory[T]) RestoreFn() g.RestoreFn[T] {
 		return state, nil
 	}
 }
+
+func (m *memMemory[T]) Delete(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.store, key)
+	return nil
+}
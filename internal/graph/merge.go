@@ -0,0 +1,40 @@
+package graph
+
+import (
+	"fmt"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func (r *runtimeImpl[T]) MergeThreads(dst, src string, mergeFn g.ReducerFn[T]) error {
+	if mergeFn == nil {
+		return fmt.Errorf("cannot merge thread %s into %s: %w", src, dst, g.ErrMergeFnNil)
+	}
+
+	// Holding the executing flag keeps the invocations of the destination out while it is merged.
+	useExecuting := r.executingByThreadID(g.InvokeConfig{ThreadID: dst})
+	if !useExecuting.CompareAndSwap(false, true) {
+		return fmt.Errorf("cannot merge thread %s into %s: %w", src, dst, g.ErrRuntimeExecuting)
+	}
+	defer useExecuting.Store(false)
+
+	current, err := r.threadSource(dst)
+	if err != nil {
+		return fmt.Errorf("cannot merge thread %s into %s: %w", src, dst, err)
+	}
+	change, err := r.threadSource(src)
+	if err != nil {
+		return fmt.Errorf("cannot merge thread %s into %s: %w", src, dst, err)
+	}
+
+	merged := mergeFn(r.clone(current), r.clone(change))
+	if err := r.adoptThread(dst, r.ownerTenant(dst), merged); err != nil {
+		return fmt.Errorf("cannot merge thread %s into %s: %w", src, dst, err)
+	}
+
+	r.logger.Info("threads merged", logAttrThreadID, dst, "source", src)
+	entry := monitorCompleted(g.MergeNodeName, dst, merged)
+	entry.Changes = r.stateChanges(current, merged)
+	r.sendMonitorEntry(entry)
+	return nil
+}
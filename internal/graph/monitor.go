@@ -0,0 +1,374 @@
+package graph
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// monitorDelivery sends the monitor entries to the state monitor channel according to the delivery policy.
+type monitorDelivery[T g.SharedState] struct {
+	ch          chan g.StateMonitorEntry[T]
+	policy      g.MonitorDeliveryPolicy
+	maxInterval time.Duration
+	logger      *slog.Logger
+
+	delivered atomic.Uint64
+	dropped   atomic.Uint64
+
+	buffer *monitorBuffer[T]
+}
+
+func newMonitorDelivery[T g.SharedState](ch chan g.StateMonitorEntry[T], settings g.RuntimeSettings, logger *slog.Logger) (*monitorDelivery[T], error) {
+	rv := &monitorDelivery[T]{
+		ch:          ch,
+		policy:      settings.MonitorDeliveryPolicy,
+		maxInterval: settings.OutcomeNotificationMaxInterval,
+		logger:      logger,
+	}
+	if ch != nil && rv.policy == g.MonitorBufferToDisk {
+		buffer, err := newMonitorBuffer[T](settings.MonitorBufferDir)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create the monitor buffer: %w", err)
+		}
+		rv.buffer = buffer
+	}
+	return rv, nil
+}
+
+// deliver sends the entry to the channel, ctx being the lifetime of the runtime.
+func (d *monitorDelivery[T]) deliver(ctx context.Context, entry g.StateMonitorEntry[T]) {
+	switch d.policy {
+	case g.MonitorBlock:
+		select {
+		case d.ch <- entry:
+			d.delivered.Add(1)
+		case <-ctx.Done():
+		}
+	case g.MonitorDropOldest:
+		for {
+			select {
+			case d.ch <- entry:
+				d.delivered.Add(1)
+				return
+			default:
+			}
+			select {
+			case oldest := <-d.ch:
+				d.drop(oldest, "monitor entry dropped, replaced by a newer one")
+			default:
+			}
+		}
+	case g.MonitorBufferToDisk:
+		direct, err := d.buffer.push(d.ch, entry)
+		if err != nil {
+			d.drop(entry, "monitor entry dropped, cannot buffer it: "+err.Error())
+			return
+		}
+		if direct {
+			d.delivered.Add(1)
+		}
+	default:
+		select {
+		case d.ch <- entry:
+			d.delivered.Add(1)
+		case <-time.After(d.maxInterval):
+			d.drop(entry, "monitor entry dropped, channel full")
+		case <-ctx.Done():
+		}
+	}
+}
+
+func (d *monitorDelivery[T]) drop(entry g.StateMonitorEntry[T], msg string) {
+	dropped := d.dropped.Add(1)
+	d.logger.Warn(msg, logAttrThreadID, entry.ThreadID, logAttrNode, entry.Node, "dropped", dropped)
+}
+
+// drain delivers the buffered entries as the consumer catches up, until ctx is done.
+func (d *monitorDelivery[T]) drain(ctx context.Context) {
+	if d.buffer == nil {
+		return
+	}
+	defer func() {
+		// Protect against panic if the channel is closed during send
+		_ = recover()
+		if lost := d.buffer.close(); lost > 0 {
+			d.dropped.Add(uint64(lost))
+		}
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-d.buffer.signal:
+		}
+		for {
+			entry, ok, err := d.buffer.next()
+			if err != nil {
+				d.logger.Warn("monitor buffer unreadable, buffered entries dropped", "error", err)
+				d.dropped.Add(uint64(d.buffer.reset()))
+				break
+			}
+			if !ok {
+				break
+			}
+			select {
+			case d.ch <- entry:
+				d.delivered.Add(1)
+				d.buffer.delivered()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+func (d *monitorDelivery[T]) stats() g.MonitorStats {
+	rv := g.MonitorStats{
+		Policy:    d.policy,
+		Delivered: d.delivered.Load(),
+		Dropped:   d.dropped.Load(),
+	}
+	if d.buffer != nil {
+		rv.Buffered, rv.Spilled = d.buffer.stats()
+	}
+	return rv
+}
+
+// bufferedEntry is the JSON representation of a monitor entry written to the buffer file.
+type bufferedEntry[T g.SharedState] struct {
+	Node         string      `json:"node"`
+	ThreadID     string      `json:"thread_id"`
+	Tenant       string      `json:"tenant,omitempty"`
+	NewState     T           `json:"state"`
+	ErrorCode    g.ErrorCode `json:"error_code,omitempty"`
+	Running      bool        `json:"running"`
+	Partial      bool        `json:"partial"`
+	Usage        g.Usage     `json:"usage"`
+	Compensation bool        `json:"compensation,omitempty"`
+	WakeAt       time.Time   `json:"wake_at,omitzero"`
+	Signal       string      `json:"signal,omitempty"`
+	Batched      int         `json:"batched,omitempty"`
+	Outcome      string      `json:"outcome,omitempty"`
+	// BreakpointInput is the pending input of a breakpoint, whose edge is not buffered.
+	BreakpointInput *T `json:"breakpoint_input,omitempty"`
+	// Changes are buffered with their values, decoded back as generic JSON values.
+	Changes []g.StateChange `json:"changes,omitempty"`
+}
+
+// monitorBuffer spills the monitor entries to a file while the channel is full, preserving their order.
+type monitorBuffer[T g.SharedState] struct {
+	mu sync.Mutex
+
+	file       *os.File
+	reader     *bufio.Reader
+	readOffset int64
+
+	// pending counts the entries written and not yet delivered, including the one being delivered.
+	pending int
+	spilled uint64
+
+	// errs keeps the errors of the buffered entries in memory, keyed by their sequence in the file, so that they
+	// are delivered unchanged; written and read are the sequences of the last entry written and read.
+	errs    map[uint64]error
+	written uint64
+	read    uint64
+
+	signal chan struct{}
+}
+
+func newMonitorBuffer[T g.SharedState](dir string) (*monitorBuffer[T], error) {
+	file, err := os.CreateTemp(dir, "ggraph-monitor-*.jsonl")
+	if err != nil {
+		return nil, err
+	}
+	rv := &monitorBuffer[T]{
+		file:   file,
+		errs:   make(map[uint64]error),
+		signal: make(chan struct{}, 1),
+	}
+	rv.reader = bufio.NewReader(rv)
+	return rv, nil
+}
+
+// Read implements io.Reader for the reader of the buffer, which has its own offset in the file.
+func (b *monitorBuffer[T]) Read(p []byte) (int, error) {
+	n, err := b.file.ReadAt(p, b.readOffset)
+	b.readOffset += int64(n)
+	if n > 0 && err == io.EOF {
+		err = nil
+	}
+	return n, err
+}
+
+// push sends the entry straight to the channel when nothing is buffered and there is room, otherwise it appends it to the file.
+//
+// It returns true if the entry was sent to the channel.
+func (b *monitorBuffer[T]) push(ch chan g.StateMonitorEntry[T], entry g.StateMonitorEntry[T]) (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.pending == 0 {
+		select {
+		case ch <- entry:
+			return true, nil
+		default:
+		}
+	}
+
+	record := bufferedEntry[T]{
+		Node:         entry.Node,
+		ThreadID:     entry.ThreadID,
+		Tenant:       entry.Tenant,
+		NewState:     entry.NewState,
+		ErrorCode:    entry.ErrorCode,
+		Running:      entry.Running,
+		Partial:      entry.Partial,
+		Usage:        entry.Usage,
+		Compensation: entry.Compensation,
+		WakeAt:       entry.WakeAt,
+		Signal:       entry.Signal,
+		Batched:      entry.Batched,
+		Outcome:      entry.Outcome,
+		Changes:      entry.Changes,
+	}
+	if entry.Breakpoint != nil {
+		record.BreakpointInput = &entry.Breakpoint.Input
+	}
+	line, err := json.Marshal(record)
+	if err != nil {
+		return false, err
+	}
+	if _, err := b.file.Write(append(line, '\n')); err != nil {
+		return false, err
+	}
+	b.written++
+	if entry.Error != nil {
+		b.errs[b.written] = entry.Error
+	}
+	b.pending++
+	b.spilled++
+
+	select {
+	case b.signal <- struct{}{}:
+	default:
+	}
+	return false, nil
+}
+
+// next reads the oldest buffered entry, ok is false when nothing is buffered.
+func (b *monitorBuffer[T]) next() (g.StateMonitorEntry[T], bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.pending == 0 {
+		return g.StateMonitorEntry[T]{}, false, nil
+	}
+	line, err := b.reader.ReadBytes('\n')
+	if err != nil {
+		return g.StateMonitorEntry[T]{}, false, err
+	}
+	b.read++
+	var record bufferedEntry[T]
+	if err := json.Unmarshal(line, &record); err != nil {
+		return g.StateMonitorEntry[T]{}, false, err
+	}
+	entryErr := b.errs[b.read]
+	delete(b.errs, b.read)
+	entry := g.StateMonitorEntry[T]{
+		Node:         record.Node,
+		ThreadID:     record.ThreadID,
+		Tenant:       record.Tenant,
+		NewState:     record.NewState,
+		Error:        entryErr,
+		ErrorCode:    record.ErrorCode,
+		Running:      record.Running,
+		Partial:      record.Partial,
+		Usage:        record.Usage,
+		Compensation: record.Compensation,
+		WakeAt:       record.WakeAt,
+		Signal:       record.Signal,
+		Batched:      record.Batched,
+		Outcome:      record.Outcome,
+		Changes:      record.Changes,
+	}
+	if record.BreakpointInput != nil {
+		entry.Breakpoint = &g.Breakpoint[T]{Input: *record.BreakpointInput}
+	}
+	return entry, true, nil
+}
+
+// delivered marks the oldest buffered entry as delivered, truncating the file once all of them are.
+func (b *monitorBuffer[T]) delivered() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pending--
+	if b.pending == 0 {
+		b.truncate()
+	}
+}
+
+// reset discards the buffered entries, returning their number.
+func (b *monitorBuffer[T]) reset() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	lost := b.pending
+	b.pending = 0
+	b.read = b.written
+	clear(b.errs)
+	b.truncate()
+	return lost
+}
+
+func (b *monitorBuffer[T]) truncate() {
+	_ = b.file.Truncate(0)
+	_, _ = b.file.Seek(0, io.SeekStart)
+	b.readOffset = 0
+	b.reader.Reset(b)
+}
+
+func (b *monitorBuffer[T]) stats() (int, uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.pending, b.spilled
+}
+
+// close removes the buffer file, returning the number of entries which were not delivered.
+func (b *monitorBuffer[T]) close() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	lost := b.pending
+	b.pending = 0
+	clear(b.errs)
+	_ = b.file.Close()
+	_ = os.Remove(b.file.Name())
+	return lost
+}
+
+// MonitorStats returns a snapshot of the delivery metrics of the monitor entries.
+func (r *runtimeImpl[T]) MonitorStats() g.MonitorStats {
+	return r.monitor.stats()
+}
+
+func (r *runtimeImpl[T]) startMonitorDrainer() {
+	if r.monitor.buffer == nil {
+		return
+	}
+	r.backgroundWorkers.Add(1)
+	go func() {
+		defer r.backgroundWorkers.Done()
+		r.monitor.drain(r.ctx)
+	}()
+}
@@ -0,0 +1,213 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// waitForStats polls the monitor stats until the condition holds.
+func waitForStats(t *testing.T, runtime g.Runtime[RuntimeTestState], condition func(g.MonitorStats) bool) g.MonitorStats {
+	t.Helper()
+	var stats g.MonitorStats
+	waitFor(t, "the monitor stats", func() bool {
+		stats = runtime.MonitorStats()
+		return condition(stats)
+	})
+	return stats
+}
+
+// TestRuntime_Monitor_DropNewest tests that the entries exceeding the channel are dropped and counted by default
+func TestRuntime_Monitor_DropNewest(t *testing.T) {
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 1)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{Settings: g.RuntimeSettings{OutcomeNotificationMaxInterval: 5 * time.Millisecond}}, testNode("Node1", countInput))
+
+	runtime.Invoke(RuntimeTestState{Value: "node1"})
+	stats := waitForStats(t, runtime, func(s g.MonitorStats) bool { return s.Delivered+s.Dropped == 3 })
+	if stats.Policy != g.MonitorDropNewest || stats.Delivered != 1 || stats.Dropped != 2 {
+		t.Errorf("Expected 1 delivered and 2 dropped entries, got %+v", stats)
+	}
+	if entry := <-stateMonitorCh; entry.Node != "StartNode" {
+		t.Errorf("Expected the first entry to be kept, got %s", entry.Node)
+	}
+}
+
+// TestRuntime_Monitor_DropOldest tests that the oldest entries make room for the newest ones
+func TestRuntime_Monitor_DropOldest(t *testing.T) {
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 1)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{Settings: g.RuntimeSettings{MonitorDeliveryPolicy: g.MonitorDropOldest}}, testNode("Node1", countInput))
+
+	runtime.Invoke(RuntimeTestState{Value: "node1"})
+	stats := waitForStats(t, runtime, func(s g.MonitorStats) bool { return s.Delivered == 3 })
+	if stats.Dropped != 2 {
+		t.Errorf("Expected 2 dropped entries, got %+v", stats)
+	}
+	if entry := <-stateMonitorCh; entry.Running || entry.NewState.Value != "node1" {
+		t.Errorf("Expected the completion entry to be kept, got %+v", entry)
+	}
+}
+
+// TestRuntime_Monitor_Block tests that no entry is lost when the delivery blocks on a slow consumer
+func TestRuntime_Monitor_Block(t *testing.T) {
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState])
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{Settings: g.RuntimeSettings{MonitorDeliveryPolicy: g.MonitorBlock, OutcomeNotificationMaxInterval: time.Millisecond}}, testNode("Node1", countInput))
+
+	runtime.Invoke(RuntimeTestState{Value: "node1"})
+	var nodes []string
+	for entry := range stateMonitorCh {
+		time.Sleep(10 * time.Millisecond)
+		nodes = append(nodes, entry.Node)
+		if !entry.Running {
+			break
+		}
+	}
+	if len(nodes) != 3 || nodes[2] != "EndNode" {
+		t.Errorf("Expected all the entries, got %v", nodes)
+	}
+	if stats := runtime.MonitorStats(); stats.Dropped != 0 || stats.Delivered != 3 {
+		t.Errorf("Expected no dropped entry, got %+v", stats)
+	}
+}
+
+// TestRuntime_Monitor_BufferToDisk tests that the entries exceeding the channel are buffered and delivered in order
+func TestRuntime_Monitor_BufferToDisk(t *testing.T) {
+	dir := t.TempDir()
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 1)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{Settings: g.RuntimeSettings{MonitorDeliveryPolicy: g.MonitorBufferToDisk, MonitorBufferDir: dir}}, testNode("Node1", countInput))
+
+	runtime.Invoke(RuntimeTestState{Value: "node1"})
+	stats := waitForStats(t, runtime, func(s g.MonitorStats) bool { return s.Spilled == 2 })
+	if stats.Delivered != 1 || stats.Buffered != 2 || stats.Dropped != 0 {
+		t.Errorf("Expected 1 delivered and 2 buffered entries, got %+v", stats)
+	}
+
+	var entries []g.StateMonitorEntry[RuntimeTestState]
+	for len(entries) < 3 {
+		entries = append(entries, <-stateMonitorCh)
+	}
+	if entries[0].Node != "StartNode" || entries[1].Node != "Node1" || entries[2].Node != "EndNode" {
+		t.Errorf("Expected the entries in order, got %s, %s, %s", entries[0].Node, entries[1].Node, entries[2].Node)
+	}
+	if entries[2].Running || entries[2].NewState.Value != "node1" || entries[2].NewState.Counter != 1 {
+		t.Errorf("Expected the buffered completion entry, got %+v", entries[2])
+	}
+	waitForStats(t, runtime, func(s g.MonitorStats) bool { return s.Delivered == 3 && s.Buffered == 0 })
+
+	runtime.Shutdown()
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 0 {
+		t.Errorf("Expected the buffer file to be removed, got %v", files)
+	}
+}
+
+// TestMonitorBuffer_RoundTrip tests that every field of a monitor entry survives the spill
+func TestMonitorBuffer_RoundTrip(t *testing.T) {
+	buffer, err := newMonitorBuffer[RuntimeTestState](t.TempDir())
+	if err != nil {
+		t.Fatalf("newMonitorBuffer failed: %v", err)
+	}
+	defer buffer.close()
+
+	entry := g.StateMonitorEntry[RuntimeTestState]{
+		Node:         "Node1",
+		ThreadID:     "thread",
+		Tenant:       "tenant",
+		NewState:     RuntimeTestState{Value: "state", Counter: 2},
+		Error:        &g.NodeError{Node: "Node1", ThreadID: "thread", Code: g.CodeNodeFailed, Err: errors.New("node failure")},
+		ErrorCode:    g.CodeNodeFailed,
+		Running:      true,
+		Partial:      true,
+		Usage:        g.Usage{PromptTokens: 1, CompletionTokens: 2, TotalTokens: 3},
+		Breakpoint:   &g.Breakpoint[RuntimeTestState]{Input: RuntimeTestState{Value: "input"}},
+		Compensation: true,
+		WakeAt:       time.Date(2026, time.January, 2, 3, 4, 5, 0, time.UTC),
+		Signal:       "approved",
+		Batched:      4,
+		Outcome:      "resolved",
+		Changes:      []g.StateChange{{Path: "Value", Before: "before", After: "state"}},
+	}
+	// Every field must be set, so that a field added to the entries but not to the spill record fails the test;
+	// the reducer is not serializable and is not buffered.
+	fields := reflect.ValueOf(entry)
+	for i := range fields.NumField() {
+		if name := fields.Type().Field(i).Name; name != "ReducerFn" && fields.Field(i).IsZero() {
+			t.Fatalf("Expected the field %s to be set", name)
+		}
+	}
+	if sent, err := buffer.push(make(chan g.StateMonitorEntry[RuntimeTestState]), entry); sent || err != nil {
+		t.Fatalf("Expected the entry to be buffered, got %t and %v", sent, err)
+	}
+	spilled, ok, err := buffer.next()
+	if !ok || err != nil {
+		t.Fatalf("Expected a buffered entry, got %t and %v", ok, err)
+	}
+
+	if !reflect.DeepEqual(spilled.Error, entry.Error) {
+		t.Errorf("Expected the error %#v, got %#v", entry.Error, spilled.Error)
+	}
+	if !spilled.WakeAt.Equal(entry.WakeAt) {
+		t.Errorf("Expected the wake time %s, got %s", entry.WakeAt, spilled.WakeAt)
+	}
+	spilled.Error, entry.Error = nil, nil
+	spilled.WakeAt, entry.WakeAt = time.Time{}, time.Time{}
+	if !reflect.DeepEqual(spilled, entry) {
+		t.Errorf("Expected the entry to survive the spill\nwant %+v\ngot  %+v", entry, spilled)
+	}
+}
+
+// TestMonitorBuffer_Errors tests that the errors of the buffered entries are delivered unchanged, in order
+func TestMonitorBuffer_Errors(t *testing.T) {
+	buffer, err := newMonitorBuffer[RuntimeTestState](t.TempDir())
+	if err != nil {
+		t.Fatalf("newMonitorBuffer failed: %v", err)
+	}
+	defer buffer.close()
+
+	budgetErr := &g.BudgetError{Node: "Node1", ThreadID: "thread", Code: g.CodeBudgetExceeded, Err: fmt.Errorf("prompt tokens over 100: %w", g.ErrBudgetExceeded)}
+	errs := []error{
+		fmt.Errorf("thread thread forcibly released: %w", budgetErr),
+		nil,
+		fmt.Errorf("queued invocation withdrawn: %w", g.ErrThreadCancelled),
+		errors.New("custom failure"),
+	}
+	ch := make(chan g.StateMonitorEntry[RuntimeTestState])
+	for _, err := range errs {
+		if sent, pushErr := buffer.push(ch, g.StateMonitorEntry[RuntimeTestState]{Error: err}); sent || pushErr != nil {
+			t.Fatalf("Expected the entry to be buffered, got %t and %v", sent, pushErr)
+		}
+	}
+
+	for idx := range errs {
+		entry, ok, err := buffer.next()
+		if !ok || err != nil {
+			t.Fatalf("Expected a buffered entry, got %t and %v", ok, err)
+		}
+		buffer.delivered()
+		if entry.Error != errs[idx] {
+			t.Errorf("Expected the error %#v, got %#v", errs[idx], entry.Error)
+		}
+	}
+	if len(buffer.errs) != 0 {
+		t.Errorf("Expected the delivered errors to be released, got %d", len(buffer.errs))
+	}
+}
+
+// TestRuntime_Monitor_InvalidBufferDir tests that the runtime creation fails when the buffer file cannot be created
+func TestRuntime_Monitor_InvalidBufferDir(t *testing.T) {
+	policy, _ := RouterPolicyImplFactory(AnyRoute[RuntimeTestState])
+	startNode, _ := NodeImplFactory(g.StartNode, "StartNode", nil, &g.NodeOptions[RuntimeTestState]{RoutingPolicy: policy})
+
+	_, err := RuntimeFactory(
+		&mockRuntimeEdge{from: startNode, to: startNode, role: g.StartEdge},
+		make(chan g.StateMonitorEntry[RuntimeTestState], 1),
+		&g.RuntimeOptions[RuntimeTestState]{Settings: g.RuntimeSettings{MonitorDeliveryPolicy: g.MonitorBufferToDisk, MonitorBufferDir: filepath.Join(t.TempDir(), "missing")}},
+	)
+	if !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected the missing directory to fail the creation, got %v", err)
+	}
+}
@@ -3,12 +3,24 @@ package graph
 import (
 	"context"
 	"fmt"
+	"time"
 
 	g "github.com/morphy76/ggraph/pkg/graph"
 )
 
 // NodeImplFactory creates a new instance of Node with the specified SharedState type.
 func NodeImplFactory[T g.SharedState](role g.NodeRole, name string, fn g.NodeFn[T], opt *g.NodeOptions[T]) (g.Node[T], error) {
+	var useFn g.ContextNodeFn[T]
+	if fn != nil {
+		useFn = func(_ context.Context, userInput, currentState T, notify g.NotifyPartialFn[T]) (T, error) {
+			return fn(userInput, currentState, notify)
+		}
+	}
+	return ContextNodeImplFactory(role, name, useFn, opt)
+}
+
+// ContextNodeImplFactory creates a new instance of Node whose function receives the invocation context.
+func ContextNodeImplFactory[T g.SharedState](role g.NodeRole, name string, fn g.ContextNodeFn[T], opt *g.NodeOptions[T]) (g.Node[T], error) {
 	if name == "" {
 		return nil, fmt.Errorf("node creation failed: %w", g.ErrNodeNameEmpty)
 	}
@@ -28,7 +40,7 @@ func NodeImplFactory[T g.SharedState](role g.NodeRole, name string, fn g.NodeFn[
 
 	useFn := fn
 	if useFn == nil {
-		useFn = func(userInput T, currentState T, notifyPartial g.NotifyPartialFn[T]) (T, error) {
+		useFn = func(_ context.Context, userInput T, currentState T, notifyPartial g.NotifyPartialFn[T]) (T, error) {
 			return currentState, nil
 		}
 	}
@@ -37,13 +49,15 @@ func NodeImplFactory[T g.SharedState](role g.NodeRole, name string, fn g.NodeFn[
 		usePolicy, _ = RouterPolicyImplFactory[T](AnyRoute)
 	}
 	return &nodeImpl[T]{
-		mailbox:     make(chan T, opt.NodeSettings.MailboxSize),
-		name:        name,
-		fn:          useFn,
-		routePolicy: usePolicy,
-		role:        role,
-		reducer:     opt.Reducer,
-		settings:    opt.NodeSettings,
+		mailbox:      make(chan T, opt.NodeSettings.MailboxSize),
+		name:         name,
+		fn:           useFn,
+		routePolicy:  usePolicy,
+		role:         role,
+		reducer:      opt.Reducer,
+		settings:     opt.NodeSettings,
+		compensation: opt.Compensation,
+		cache:        newNodeCache(name, opt.Cache),
 	}, nil
 }
 
@@ -52,12 +66,16 @@ func NodeImplFactory[T g.SharedState](role g.NodeRole, name string, fn g.NodeFn[
 // ------------------------------------------------------------------------------
 
 var _ g.Node[g.SharedState] = (*nodeImpl[g.SharedState])(nil)
+var _ g.Compensable[g.SharedState] = (*nodeImpl[g.SharedState])(nil)
+var _ g.Delayer[g.SharedState] = (*nodeImpl[g.SharedState])(nil)
+var _ g.SignalAwaiter[g.SharedState] = (*nodeImpl[g.SharedState])(nil)
+var _ g.Batcher[g.SharedState] = (*nodeImpl[g.SharedState])(nil)
 
 type nodeImpl[T g.SharedState] struct {
 	mailbox chan T
 
 	name        string
-	fn          g.NodeFn[T]
+	fn          g.ContextNodeFn[T]
 	routePolicy g.RoutePolicy[T]
 
 	role g.NodeRole
@@ -65,6 +83,19 @@ type nodeImpl[T g.SharedState] struct {
 	reducer g.ReducerFn[T]
 
 	settings g.NodeSettings
+
+	compensation g.CompensationFn[T]
+
+	cache *nodeCache[T]
+
+	delay g.DelayFn[T]
+
+	signal        string
+	signalTimeout time.Duration
+
+	batch       g.BatchFn[T]
+	batchSize   int
+	batchWindow time.Duration
 }
 
 func (n *nodeImpl[T]) Name() string {
@@ -87,16 +118,19 @@ func (n *nodeImpl[T]) Accept(
 			stateObserver.NotifyStateChange(n, config, userInput, state, n.reducer, nil, true)
 		}
 
+		// The mailbox bounds the inputs waiting for the node: the task dequeues any of them but
+		// executes its own, the concurrent threads dequeuing in any order.
 		select {
-		case asyncDeltaState := <-n.mailbox:
-			stateChange, err := n.fn(asyncDeltaState, stateObserver.CurrentState(useThreadID), partialStateChange)
+		case <-n.mailbox:
+			nodeStarting(stateObserver, useThreadID, n.name)
+			stateChange, err := n.execute(nodeContext(invocationContext(config), n.name, stateObserver), userInput, stateForNode(stateObserver, useThreadID), partialStateChange)
 			if err != nil {
-				stateObserver.NotifyStateChange(n, config, userInput, stateChange, n.reducer, fmt.Errorf("error executing node %s: %w", n.name, err), false)
+				stateObserver.NotifyStateChange(n, config, userInput, stateChange, n.reducer, &g.NodeError{Node: n.name, ThreadID: useThreadID, Code: g.CodeNodeFailed, Err: limitError(n.name, useThreadID, err)}, false)
 				return
 			}
 			stateObserver.NotifyStateChange(n, config, userInput, stateChange, n.reducer, nil, false)
 		case <-ctx.Done():
-			stateObserver.NotifyStateChange(n, config, userInput, stateObserver.CurrentState(useThreadID), n.reducer, fmt.Errorf("error executing node %s: %w", n.name, ctx.Err()), false)
+			stateObserver.NotifyStateChange(n, config, userInput, stateForNode(stateObserver, useThreadID), n.reducer, &g.NodeError{Node: n.name, ThreadID: useThreadID, Code: g.CodeNodeTimeout, Err: ctx.Err()}, false)
 			return
 		}
 	}
@@ -113,3 +147,21 @@ func (n *nodeImpl[T]) RoutePolicy() g.RoutePolicy[T] {
 func (n *nodeImpl[T]) Role() g.NodeRole {
 	return n.role
 }
+
+func (n *nodeImpl[T]) Compensation() g.CompensationFn[T] {
+	return n.compensation
+}
+
+func (n *nodeImpl[T]) Delay(ctx context.Context, userInput, currentState T) (time.Duration, error) {
+	if n.delay == nil {
+		return 0, nil
+	}
+	return n.delay(ctx, userInput, currentState)
+}
+
+func invocationContext(config g.InvokeConfig) context.Context {
+	if config.Context == nil {
+		return context.Background()
+	}
+	return config.Context
+}
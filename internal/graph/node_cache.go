@@ -0,0 +1,74 @@
+package graph
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	ic "github.com/morphy76/ggraph/internal/agent/cache"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// nodeCache memoizes the results of a node, see WithCache.
+type nodeCache[T g.SharedState] struct {
+	node  string
+	key   g.NodeCacheKeyFn[T]
+	store g.NodeCacheStore
+	ttl   time.Duration
+}
+
+// newNodeCache returns the cache of the node, nil if the node is not cached.
+func newNodeCache[T g.SharedState](node string, cache *g.NodeCache[T]) *nodeCache[T] {
+	if cache == nil || cache.Key == nil {
+		return nil
+	}
+	store := cache.Store
+	if store == nil {
+		store = ic.NewLRU(g.DefaultNodeCacheCapacity)
+	}
+	return &nodeCache[T]{node: node, key: cache.Key, store: store, ttl: cache.TTL}
+}
+
+// storeKey hashes the node name along with the key, so that the nodes can share a store.
+func (c *nodeCache[T]) storeKey(key string) string {
+	sum := sha256.Sum256([]byte(c.node + "\x00" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// call returns the cached result of the node, or executes fn and caches its result.
+func (c *nodeCache[T]) call(ctx context.Context, fn g.ContextNodeFn[T], userInput, currentState T, notify g.NotifyPartialFn[T]) (T, error) {
+	key, err := c.key(userInput, currentState)
+	if err != nil {
+		return currentState, err
+	}
+	if key == "" {
+		return fn(ctx, userInput, currentState, notify)
+	}
+
+	storeKey := c.storeKey(key)
+	if cached, ok, err := c.store.Get(ctx, storeKey); err == nil && ok {
+		var result T
+		if json.Unmarshal(cached, &result) == nil {
+			return result, nil
+		}
+	}
+
+	result, err := fn(ctx, userInput, currentState, notify)
+	if err != nil {
+		return result, err
+	}
+	if encoded, err := json.Marshal(result); err == nil {
+		_ = c.store.Set(ctx, storeKey, encoded, c.ttl)
+	}
+	return result, nil
+}
+
+// execute executes the function of the node, through its cache if any.
+func (n *nodeImpl[T]) execute(ctx context.Context, userInput, currentState T, notify g.NotifyPartialFn[T]) (T, error) {
+	if n.cache == nil {
+		return n.fn(ctx, userInput, currentState, notify)
+	}
+	return n.cache.call(ctx, n.fn, userInput, currentState, notify)
+}
@@ -0,0 +1,115 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// unreliableCacheStore is a node cache store failing its reads, recording the time to live of its writes.
+type unreliableCacheStore struct {
+	mu   sync.Mutex
+	ttls []time.Duration
+}
+
+func (s *unreliableCacheStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	return nil, false, errors.New("store down")
+}
+
+func (s *unreliableCacheStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ttls = append(s.ttls, ttl)
+	return nil
+}
+
+// TestRuntime_NodeCache tests that the cached results skip the execution of the node, whatever the thread
+func TestRuntime_NodeCache(t *testing.T) {
+	var calls atomic.Int32
+	cachedOpts := testNodeOptions()
+	_ = g.WithCache(func(userInput, currentState RuntimeTestState) (string, error) {
+		if userInput.Value == "invalid" {
+			return "", errors.New("no key")
+		}
+		return userInput.Value, nil
+	}).Apply(cachedOpts)
+	lookupNode, _ := ContextNodeImplFactory(g.IntermediateNode, "Lookup", func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		currentState.Value = userInput.Value + "!"
+		currentState.Counter = int(calls.Add(1))
+		return currentState, nil
+	}, cachedOpts)
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, lookupNode)
+
+	tests := []struct {
+		input     string
+		wantValue string
+		wantCalls int
+	}{
+		{input: "x", wantValue: "x!", wantCalls: 1},
+		{input: "x", wantValue: "x!", wantCalls: 1},
+		{input: "y", wantValue: "y!", wantCalls: 2},
+		{input: "", wantValue: "!", wantCalls: 3},
+		{input: "", wantValue: "!", wantCalls: 4},
+	}
+	for _, tt := range tests {
+		runtime.Invoke(RuntimeTestState{Value: tt.input})
+		entry := waitTerminalEntry(t, stateMonitorCh)
+		if entry.Error != nil || entry.NewState.Value != tt.wantValue || entry.NewState.Counter != tt.wantCalls {
+			t.Errorf("Input %q: expected %q after %d executions, got %+v", tt.input, tt.wantValue, tt.wantCalls, entry)
+		}
+	}
+
+	runtime.Invoke(RuntimeTestState{Value: "invalid"})
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	var nodeErr *g.NodeError
+	if !errors.As(entry.Error, &nodeErr) || nodeErr.Code != g.CodeNodeFailed || entry.Node != "Lookup" {
+		t.Errorf("Expected the key failure to fail the node, got %+v", entry)
+	}
+}
+
+// TestRuntime_NodeCacheStore tests that the failures of the store do not fail the node
+func TestRuntime_NodeCacheStore(t *testing.T) {
+	var calls atomic.Int32
+	store := &unreliableCacheStore{}
+	cachedOpts := testNodeOptions()
+	keyFn := func(userInput, currentState RuntimeTestState) (string, error) { return userInput.Value, nil }
+	_ = g.WithCache(keyFn, g.WithNodeCacheStore(store), g.WithNodeCacheTTL(time.Minute)).Apply(cachedOpts)
+	lookupNode, _ := ContextNodeImplFactory(g.IntermediateNode, "Lookup", func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		currentState.Value = userInput.Value + "!"
+		currentState.Counter = int(calls.Add(1))
+		return currentState, nil
+	}, cachedOpts)
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, lookupNode)
+
+	for range 2 {
+		runtime.Invoke(RuntimeTestState{Value: "x"})
+		if entry := waitTerminalEntry(t, stateMonitorCh); entry.Error != nil || entry.NewState.Value != "x!" {
+			t.Errorf("Expected the node to be executed, got %+v", entry)
+		}
+	}
+	if calls.Load() != 2 {
+		t.Errorf("Expected the unreadable results to be computed again, got %d executions", calls.Load())
+	}
+	store.mu.Lock()
+	defer store.mu.Unlock()
+	if len(store.ttls) != 2 || store.ttls[0] != time.Minute {
+		t.Errorf("Expected the results to be cached for a minute, got %v", store.ttls)
+	}
+
+	if err := g.WithCache[RuntimeTestState](nil).Apply(&g.NodeOptions[RuntimeTestState]{}); !errors.Is(err, g.ErrNodeCacheKeyFnNil) {
+		t.Errorf("Expected ErrNodeCacheKeyFnNil, got %v", err)
+	}
+	if err := g.WithCache(keyFn, g.WithNodeCacheStore(nil)).Apply(&g.NodeOptions[RuntimeTestState]{}); !errors.Is(err, g.ErrNodeCacheStoreNil) {
+		t.Errorf("Expected ErrNodeCacheStoreNil, got %v", err)
+	}
+	if err := g.WithCache(keyFn, g.WithNodeCacheTTL(-time.Second)).Apply(&g.NodeOptions[RuntimeTestState]{}); !errors.Is(err, g.ErrInvalidNodeCacheOption) {
+		t.Errorf("Expected ErrInvalidNodeCacheOption, got %v", err)
+	}
+}
@@ -0,0 +1,196 @@
+package graph
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/morphy76/ggraph/internal/latency"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// nodeStatsRecorder accounts the executions of the nodes by name, see g.Profiled.
+type nodeStatsRecorder struct {
+	mu         sync.Mutex
+	since      time.Time
+	window     int
+	defaultSLO time.Duration
+	slos       map[string]time.Duration
+	nodes      map[string]*nodeMetrics
+	// started keeps the start of the running executions, independently of the retention of the timelines.
+	started map[nodeRun]time.Time
+}
+
+// nodeRun identifies a running execution of a node.
+type nodeRun struct {
+	threadID string
+	node     string
+}
+
+type nodeMetrics struct {
+	stats g.NodeStats
+	// latencies are the durations of the most recent executions, a ring overwritten from next.
+	latencies []time.Duration
+	next      int
+}
+
+func newNodeStatsRecorder(window int, defaultSLO time.Duration, slos map[string]time.Duration) *nodeStatsRecorder {
+	if window <= 0 {
+		window = g.DefaultNodeStatsWindow
+	}
+	return &nodeStatsRecorder{
+		since:      time.Now(),
+		window:     window,
+		defaultSLO: defaultSLO,
+		slos:       slos,
+		nodes:      make(map[string]*nodeMetrics),
+		started:    make(map[nodeRun]time.Time),
+	}
+}
+
+// metrics returns the metrics of the node, the caller holds mu.
+func (s *nodeStatsRecorder) metrics(node string) *nodeMetrics {
+	metrics, ok := s.nodes[node]
+	if !ok {
+		slo, ok := s.slos[node]
+		if !ok {
+			slo = s.defaultSLO
+		}
+		metrics = &nodeMetrics{stats: g.NodeStats{Node: node, LatencySLO: slo}}
+		s.nodes[node] = metrics
+	}
+	return metrics
+}
+
+// start marks the start of the execution of the node function by the thread.
+func (s *nodeStatsRecorder) start(threadID, node string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.started[nodeRun{threadID: threadID, node: node}] = time.Now()
+}
+
+// ended accounts the execution of the node by the thread since its start; the executions which never started, e.g.
+// waiting for a signal, are ignored.
+func (s *nodeStatsRecorder) ended(threadID, node string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	run := nodeRun{threadID: threadID, node: node}
+	startedAt, ok := s.started[run]
+	if !ok {
+		return
+	}
+	delete(s.started, run)
+	s.record(node, time.Since(startedAt), err)
+}
+
+// forget discards the executions of the thread which did not return, e.g. when its invocation is cancelled.
+func (s *nodeStatsRecorder) forget(threadID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for run := range s.started {
+		if run.threadID == threadID {
+			delete(s.started, run)
+		}
+	}
+}
+
+func (s *nodeStatsRecorder) executed(node string, latency time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.record(node, latency, err)
+}
+
+// record accounts an execution of the node, the caller holds mu.
+func (s *nodeStatsRecorder) record(node string, latency time.Duration, err error) {
+	metrics := s.metrics(node)
+	metrics.stats.Executions++
+	if err != nil {
+		metrics.stats.Failures++
+	}
+	metrics.stats.TotalLatency += latency
+	metrics.stats.MaxLatency = max(metrics.stats.MaxLatency, latency)
+	if metrics.stats.LatencySLO > 0 && latency > metrics.stats.LatencySLO {
+		metrics.stats.SLOBreaches++
+	}
+	if len(metrics.latencies) < s.window {
+		metrics.latencies = append(metrics.latencies, latency)
+		return
+	}
+	metrics.latencies[metrics.next] = latency
+	metrics.next = (metrics.next + 1) % s.window
+}
+
+func (s *nodeStatsRecorder) partial(node string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.metrics(node).stats.PartialUpdates++
+}
+
+func (s *nodeStatsRecorder) usage(node string, usage g.Usage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	metrics := s.metrics(node)
+	metrics.stats.Usage = metrics.stats.Usage.Add(usage)
+}
+
+func (s *nodeStatsRecorder) snapshot() g.RuntimeStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rv := g.RuntimeStats{Since: s.since, Nodes: make([]g.NodeStats, 0, len(s.nodes))}
+	for _, metrics := range s.nodes {
+		stats := metrics.stats
+		sorted := slices.Clone(metrics.latencies)
+		slices.Sort(sorted)
+		stats.P50Latency = latency.Percentile(sorted, 0.50)
+		stats.P95Latency = latency.Percentile(sorted, 0.95)
+		stats.P99Latency = latency.Percentile(sorted, 0.99)
+		rv.Nodes = append(rv.Nodes, stats)
+	}
+	slices.SortFunc(rv.Nodes, func(x, y g.NodeStats) int { return strings.Compare(x.Node, y.Node) })
+	return rv
+}
+
+// nodeUsageRecorder accounts the usage reported by the executions of a node, implemented by the runtime.
+type nodeUsageRecorder interface {
+	recordNodeUsage(node string, usage g.Usage)
+}
+
+// nodeStartRecorder marks the start of the executions of the node functions, implemented by the runtime.
+type nodeStartRecorder interface {
+	recordNodeStart(threadID, node string)
+}
+
+// nodeStarting marks the start of the execution of the node function by the thread, the latency of the node is
+// accounted from there.
+func nodeStarting(observer any, threadID, node string) {
+	if recorder, ok := observer.(nodeStartRecorder); ok {
+		recorder.recordNodeStart(threadID, node)
+	}
+}
+
+// nodeContext returns the context of an execution of the node, attributing the usage it reports to
+// the node before forwarding it to the invocation.
+func nodeContext(ctx context.Context, node string, observer any) context.Context {
+	recorder, ok := observer.(nodeUsageRecorder)
+	if !ok {
+		return ctx
+	}
+	return g.ContextWithUsageReporter(ctx, func(usage g.Usage) {
+		recorder.recordNodeUsage(node, usage)
+		g.ReportUsage(ctx, usage)
+	})
+}
+
+func (r *runtimeImpl[T]) recordNodeUsage(node string, usage g.Usage) {
+	r.nodeStats.usage(node, usage)
+}
+
+func (r *runtimeImpl[T]) recordNodeStart(threadID, node string) {
+	r.nodeStats.start(threadID, node)
+}
+
+func (r *runtimeImpl[T]) Stats() g.RuntimeStats {
+	return r.nodeStats.snapshot()
+}
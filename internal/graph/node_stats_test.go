@@ -0,0 +1,139 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// TestRuntime_Stats tests that the latency, the partial updates and the usage of the nodes are accounted by name
+func TestRuntime_Stats(t *testing.T) {
+	runtimeOpts := &g.RuntimeOptions[RuntimeTestState]{}
+	for _, opt := range []g.RuntimeOption[RuntimeTestState]{
+		g.WithLatencySLO[RuntimeTestState](time.Hour),
+		g.WithLatencySLO[RuntimeTestState](time.Millisecond, "Node1"),
+	} {
+		if err := opt.Apply(runtimeOpts); err != nil {
+			t.Fatalf("Option failed: %v", err)
+		}
+	}
+	runtime, stateMonitorCh := newNodeTestRuntime(t, func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		if userInput.Value == "fail" {
+			return currentState, errors.New("node1 failure")
+		}
+		time.Sleep(5 * time.Millisecond)
+		notify(currentState)
+		notify(currentState)
+		g.ReportUsage(ctx, g.Usage{PromptTokens: 10, CompletionTokens: 20, TotalTokens: 30})
+		return currentState, nil
+	}, runtimeOpts)
+
+	if stats := runtime.Stats(); len(stats.Nodes) != 0 || stats.Since.IsZero() {
+		t.Fatalf("Expected no node metrics before the first invocation, got %+v", stats)
+	}
+	for _, value := range []string{"", "", "fail"} {
+		runtime.Invoke(RuntimeTestState{Value: value})
+		waitTerminalEntry(t, stateMonitorCh)
+	}
+
+	stats := runtime.Stats()
+	if len(stats.Nodes) != 3 || stats.Nodes[0].Node != "EndNode" || stats.Nodes[2].Node != "StartNode" {
+		t.Fatalf("Expected the metrics of the 3 nodes sorted by name, got %+v", stats.Nodes)
+	}
+	node, _ := stats.Node("Node1")
+	if node.Executions != 3 || node.Failures != 1 || node.PartialUpdates != 4 {
+		t.Errorf("Expected 3 executions, 1 failure and 4 partial updates, got %+v", node)
+	}
+	if node.Usage != (g.Usage{PromptTokens: 20, CompletionTokens: 40, TotalTokens: 60}) || node.TokensPerSecond() <= 0 {
+		t.Errorf("Expected the usage reported by the node, got %+v", node.Usage)
+	}
+	if node.P50Latency < 5*time.Millisecond || node.P99Latency != node.MaxLatency || node.P50Latency > node.P95Latency {
+		t.Errorf("Unexpected latency percentiles %+v", node)
+	}
+	if node.LatencySLO != time.Millisecond || node.SLOBreaches < 2 || node.SLOCompliance() > 0.34 {
+		t.Errorf("Expected the successful executions to breach the latency objective, got %+v", node)
+	}
+	if start, _ := stats.Node("StartNode"); start.LatencySLO != time.Hour || start.SLOBreaches != 0 || start.SLOCompliance() != 1 || !start.Usage.IsZero() {
+		t.Errorf("Expected the default latency objective, got %+v", start)
+	}
+	if bottleneck, ok := stats.Bottleneck(); !ok || bottleneck.Node != "Node1" {
+		t.Errorf("Expected Node1 to be the bottleneck, got %+v", bottleneck)
+	}
+}
+
+// TestRuntime_Stats_SignalWait tests that the wait for a signal is not accounted as the latency of the node
+func TestRuntime_Stats_SignalWait(t *testing.T) {
+	awaitNode, _ := SignalNodeImplFactory("AwaitPayment", "payment", 0, testNodeOptions())
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, awaitNode, testNode("Ship", countInput))
+
+	threadID := runtime.Invoke(RuntimeTestState{})
+	waitParkedEntry(t, stateMonitorCh)
+	time.Sleep(20 * time.Millisecond)
+	if err := runtime.Signal(threadID, "payment", RuntimeTestState{Counter: 1}); err != nil {
+		t.Fatalf("Signal failed: %v", err)
+	}
+	waitTerminalEntry(t, stateMonitorCh)
+
+	stats := runtime.Stats()
+	if node, ok := stats.Node("AwaitPayment"); ok && node.MaxLatency >= 20*time.Millisecond {
+		t.Errorf("Expected the wait for the signal not to be accounted, got %+v", node)
+	}
+	if node, ok := stats.Node("Ship"); !ok || node.Executions != 1 {
+		t.Errorf("Expected the execution of the node after the signal, got %+v", node)
+	}
+}
+
+// TestRuntime_Stats_TimelineEviction tests that the executions are accounted when the timeline of their thread is evicted
+func TestRuntime_Stats_TimelineEviction(t *testing.T) {
+	runtime, stateMonitorCh, started, release := newBlockingTestRuntime(t, &g.RuntimeOptions[RuntimeTestState]{TimelineThreads: 1})
+
+	for range 3 {
+		runtime.Invoke(RuntimeTestState{})
+		waitStarted(t, started)
+	}
+	close(release)
+	for range 3 {
+		waitTerminalEntry(t, stateMonitorCh)
+	}
+
+	if node, _ := runtime.Stats().Node("Node1"); node.Executions != 3 {
+		t.Errorf("Expected the 3 executions to be accounted, got %d", node.Executions)
+	}
+}
+
+// TestNodeStatsRecorder_Window tests that the percentiles cover the most recent executions only
+func TestNodeStatsRecorder_Window(t *testing.T) {
+	recorder := newNodeStatsRecorder(2, 0, nil)
+	for _, latency := range []time.Duration{30 * time.Millisecond, 10 * time.Millisecond, 20 * time.Millisecond} {
+		recorder.executed("Node", latency, nil)
+	}
+
+	node, _ := recorder.snapshot().Node("Node")
+	if node.Executions != 3 || node.MaxLatency != 30*time.Millisecond || node.TotalLatency != 60*time.Millisecond {
+		t.Errorf("Expected the totals of all the executions, got %+v", node)
+	}
+	if node.P50Latency != 10*time.Millisecond || node.P99Latency != 20*time.Millisecond {
+		t.Errorf("Expected the percentiles of the last 2 executions, got %+v", node)
+	}
+	if node.SLOCompliance() != 1 || node.AverageLatency() != 20*time.Millisecond {
+		t.Errorf("Unexpected derived metrics %+v", node)
+	}
+}
+
+// TestWithNodeStatsWindow tests the validation of the node stats options
+func TestWithNodeStatsWindow(t *testing.T) {
+	opts := &g.RuntimeOptions[RuntimeTestState]{}
+	if err := g.WithNodeStatsWindow[RuntimeTestState](0).Apply(opts); !errors.Is(err, g.ErrInvalidNodeStatsWindow) {
+		t.Errorf("Expected ErrInvalidNodeStatsWindow, got %v", err)
+	}
+	if err := g.WithLatencySLO[RuntimeTestState](0, "Node").Apply(opts); !errors.Is(err, g.ErrInvalidLatencySLO) {
+		t.Errorf("Expected ErrInvalidLatencySLO, got %v", err)
+	}
+	if err := g.WithNodeStatsWindow[RuntimeTestState](10).Apply(opts); err != nil || opts.NodeStatsWindow != 10 {
+		t.Errorf("Expected the window to be set, got %d and %v", opts.NodeStatsWindow, err)
+	}
+}
@@ -562,6 +562,42 @@ func TestNodeImplFactory_MultipleExecutions(t *testing.T) {
 	}
 }
 
+// deferredNodeExecutor holds the submitted tasks until run, to execute them in any order
+type deferredNodeExecutor struct {
+	tasks []func()
+}
+
+func (d *deferredNodeExecutor) Submit(task func()) {
+	d.tasks = append(d.tasks, task)
+}
+
+func TestNodeImplFactory_ConcurrentInputs(t *testing.T) {
+	nodeFn := func(userInput, currentState NodeTestState, notify g.NotifyPartialFn[NodeTestState]) (NodeTestState, error) {
+		currentState.Counter = userInput.Counter
+		return currentState, nil
+	}
+	node, err := graph.NodeImplFactory[NodeTestState](g.IntermediateNode, "concurrent-node", nodeFn, &g.NodeOptions[NodeTestState]{})
+	if err != nil {
+		t.Fatalf("NodeImplFactory failed: %v", err)
+	}
+
+	observer := newMockStateObserver(NodeTestState{})
+	executor := &deferredNodeExecutor{}
+	for i, threadID := range []string{"thread-1", "thread-2", "thread-3"} {
+		node.Accept(NodeTestState{Counter: i + 1}, observer, executor, g.InvokeConfigThreadID(threadID))
+	}
+	// The tasks of the threads run in the reverse order of their inputs
+	for i := len(executor.tasks) - 1; i >= 0; i-- {
+		executor.tasks[i]()
+	}
+
+	for _, notification := range observer.getNotifications() {
+		if notification.stateChange.Counter != notification.userInput.Counter {
+			t.Errorf("Expected each task to execute its own input %d, got %d", notification.userInput.Counter, notification.stateChange.Counter)
+		}
+	}
+}
+
 func TestNodeImplFactory_NodeNamePreservation(t *testing.T) {
 	names := []string{"node-1", "processing-unit", "validator", "transformer"}
 
@@ -0,0 +1,62 @@
+package graph
+
+import (
+	"context"
+	"sync"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// MemOnceStoreFactory creates an in-process OnceStore.
+func MemOnceStoreFactory() g.OnceStore {
+	return &memOnceStore{claimed: make(map[onceKey]struct{})}
+}
+
+// ------------------------------------------------------------------------------
+// In-Memory OnceStore Implementation
+// ------------------------------------------------------------------------------
+
+var _ g.OnceStore = (*memOnceStore)(nil)
+
+type onceKey struct {
+	threadID string
+	key      string
+}
+
+type memOnceStore struct {
+	mu      sync.Mutex
+	claimed map[onceKey]struct{}
+}
+
+func (s *memOnceStore) ClaimOnce(_ context.Context, threadID, key string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.claimed[onceKey{threadID, key}]; ok {
+		return false, nil
+	}
+	s.claimed[onceKey{threadID, key}] = struct{}{}
+	return true, nil
+}
+
+func (s *memOnceStore) ReleaseOnce(_ context.Context, threadID, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.claimed, onceKey{threadID, key})
+	return nil
+}
+
+// ------------------------------------------------------------------------------
+// Runtime Side Effects
+// ------------------------------------------------------------------------------
+
+// onceStoreOf returns the memory when it records the side effects, an in-process store otherwise.
+func onceStoreOf[T g.SharedState](memory g.Memory[T]) g.OnceStore {
+	if store, ok := memory.(g.OnceStore); ok {
+		return store
+	}
+	return MemOnceStoreFactory()
+}
+
+func (r *runtimeImpl[T]) Once(threadID, key string, fn func() error) (bool, error) {
+	return g.Once(g.ContextWithOnceStore(g.ContextWithThreadID(r.ctx, threadID), r.onceStore), key, fn)
+}
@@ -0,0 +1,101 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// TestRuntime_Once tests that a side effect is executed once per thread, again after a failure
+func TestRuntime_Once(t *testing.T) {
+	var charges atomic.Int32
+	var fail atomic.Bool
+	runtime, stateMonitorCh := newNodeTestRuntime(t, func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		_, err := g.Once(ctx, "charge", func() error {
+			if fail.Load() {
+				return errors.New("payment declined")
+			}
+			charges.Add(1)
+			return nil
+		})
+		return currentState, err
+	}, &g.RuntimeOptions[RuntimeTestState]{})
+
+	fail.Store(true)
+	runtime.Invoke(RuntimeTestState{}, g.InvokeConfig{ThreadID: "order-1"})
+	if entry := waitTerminalEntry(t, stateMonitorCh); entry.Error == nil {
+		t.Fatal("Expected the failed side effect to fail the node")
+	}
+
+	fail.Store(false)
+	for range 2 {
+		runtime.Invoke(RuntimeTestState{}, g.InvokeConfig{ThreadID: "order-1"})
+		if entry := waitTerminalEntry(t, stateMonitorCh); entry.Error != nil {
+			t.Fatalf("Unexpected error: %v", entry.Error)
+		}
+	}
+	if charges.Load() != 1 {
+		t.Errorf("Expected the side effect to be executed once after the failure, executed %d times", charges.Load())
+	}
+
+	runtime.Invoke(RuntimeTestState{}, g.InvokeConfig{ThreadID: "order-2"})
+	waitTerminalEntry(t, stateMonitorCh)
+	if charges.Load() != 2 {
+		t.Errorf("Expected the side effect to be executed for another thread, executed %d times", charges.Load())
+	}
+}
+
+// TestRuntime_OnceSharedMemory tests that the side effects are recorded by the memory shared by the runtimes
+func TestRuntime_OnceSharedMemory(t *testing.T) {
+	memory := MemMemoryFactory[RuntimeTestState](nil)
+	newRuntime := func() g.Runtime[RuntimeTestState] {
+		startNode, _ := NodeImplFactory[RuntimeTestState](g.StartNode, "StartNode", nil, &g.NodeOptions[RuntimeTestState]{})
+		node1, _ := NodeImplFactory[RuntimeTestState](g.IntermediateNode, "Node1", nil, &g.NodeOptions[RuntimeTestState]{})
+		runtime, err := RuntimeFactory(
+			&mockRuntimeEdge{from: startNode, to: node1, role: g.StartEdge},
+			nil,
+			&g.RuntimeOptions[RuntimeTestState]{Memory: memory, Settings: g.RuntimeSettings{GracefulShutdownTimeout: 100 * time.Millisecond}},
+		)
+		if err != nil {
+			t.Fatalf("RuntimeFactory failed: %v", err)
+		}
+		t.Cleanup(runtime.Shutdown)
+		return runtime
+	}
+	first, second := newRuntime(), newRuntime()
+
+	var executions int
+	effect := func() error {
+		executions++
+		return nil
+	}
+	if executed, err := first.Once("thread", "notify", effect); !executed || err != nil {
+		t.Fatalf("Expected the side effect to be executed, got %v, %v", executed, err)
+	}
+	if executed, err := second.Once("thread", "notify", effect); executed || err != nil {
+		t.Errorf("Expected the side effect recorded by the other runtime to be skipped, got %v, %v", executed, err)
+	}
+	if executions != 1 {
+		t.Errorf("Expected 1 execution, got %d", executions)
+	}
+}
+
+// TestOnce_Errors tests the invalid side effects
+func TestOnce_Errors(t *testing.T) {
+	ctx := g.ContextWithOnceStore(g.ContextWithThreadID(context.Background(), "thread"), MemOnceStoreFactory())
+	noop := func() error { return nil }
+
+	if _, err := g.Once(ctx, "", noop); !errors.Is(err, g.ErrOnceKeyEmpty) {
+		t.Errorf("Expected ErrOnceKeyEmpty, got %v", err)
+	}
+	if _, err := g.Once(ctx, "key", nil); !errors.Is(err, g.ErrOnceFnNil) {
+		t.Errorf("Expected ErrOnceFnNil, got %v", err)
+	}
+	if _, err := g.Once(context.Background(), "key", noop); !errors.Is(err, g.ErrOnceStoreNotSet) {
+		t.Errorf("Expected ErrOnceStoreNotSet, got %v", err)
+	}
+}
@@ -0,0 +1,137 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+type queuedOutcome[T g.SharedState] struct {
+	result     nodeFnReturnStruct[T]
+	enqueuedAt time.Time
+}
+
+// outcomeQueue holds the node outcomes waiting to be processed, a FIFO per thread served in round-robin.
+//
+// The order of the outcomes of a thread is preserved, while a thread emitting many outcomes, e.g. partial
+// updates, only delays the others by one outcome per round. The producers block while the queue is full.
+type outcomeQueue[T g.SharedState] struct {
+	mu       sync.Mutex
+	capacity int
+	size     int
+	threads  map[string][]queuedOutcome[T]
+	// ring holds the threads with waiting outcomes, in serving order.
+	ring []string
+
+	notEmpty chan struct{}
+	notFull  chan struct{}
+
+	processed uint64
+	totalWait time.Duration
+	maxWait   time.Duration
+}
+
+func newOutcomeQueue[T g.SharedState](capacity int) *outcomeQueue[T] {
+	return &outcomeQueue[T]{
+		capacity: max(capacity, 1),
+		threads:  make(map[string][]queuedOutcome[T]),
+		notEmpty: make(chan struct{}, 1),
+		notFull:  make(chan struct{}, 1),
+	}
+}
+
+// push enqueues the outcome, waiting for room until the context is done; it returns false if the outcome is dropped.
+func (q *outcomeQueue[T]) push(ctx context.Context, result nodeFnReturnStruct[T]) bool {
+	threadID := result.config.ThreadID
+	for {
+		q.mu.Lock()
+		if q.size < q.capacity {
+			pending, waiting := q.threads[threadID]
+			if !waiting {
+				q.ring = append(q.ring, threadID)
+			}
+			q.threads[threadID] = append(pending, queuedOutcome[T]{result: result, enqueuedAt: time.Now()})
+			q.size++
+			hasRoom := q.size < q.capacity
+			q.mu.Unlock()
+
+			signal(q.notEmpty)
+			if hasRoom {
+				// Another producer may wait for the room freed by a previous pop.
+				signal(q.notFull)
+			}
+			return true
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-q.notFull:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// pop dequeues the next outcome of the thread at the head of the ring, waiting until the context is done.
+func (q *outcomeQueue[T]) pop(ctx context.Context) (nodeFnReturnStruct[T], bool) {
+	for {
+		q.mu.Lock()
+		if q.size > 0 {
+			threadID := q.ring[0]
+			q.ring = q.ring[1:]
+			pending := q.threads[threadID]
+			next := pending[0]
+			pending[0] = queuedOutcome[T]{}
+			if len(pending) > 1 {
+				q.threads[threadID] = pending[1:]
+				q.ring = append(q.ring, threadID)
+			} else {
+				delete(q.threads, threadID)
+			}
+			q.size--
+
+			wait := time.Since(next.enqueuedAt)
+			q.processed++
+			q.totalWait += wait
+			q.maxWait = max(q.maxWait, wait)
+			q.mu.Unlock()
+
+			signal(q.notFull)
+			return next.result, true
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-q.notEmpty:
+		case <-ctx.Done():
+			return nodeFnReturnStruct[T]{}, false
+		}
+	}
+}
+
+func (q *outcomeQueue[T]) stats() g.OutcomeStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return g.OutcomeStats{
+		Queued:         q.size,
+		Threads:        len(q.ring),
+		Processed:      q.processed,
+		TotalQueueWait: q.totalWait,
+		MaxQueueWait:   q.maxWait,
+	}
+}
+
+// signal wakes up a waiter of the channel, if it is not already signalled.
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+func (r *runtimeImpl[T]) OutcomeStats() g.OutcomeStats {
+	return r.outcomes.stats()
+}
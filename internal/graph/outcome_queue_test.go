@@ -0,0 +1,89 @@
+package graph
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func queuedOutcomeOf(threadID, value string) nodeFnReturnStruct[RuntimeTestState] {
+	return nodeFnReturnStruct[RuntimeTestState]{config: g.InvokeConfig{ThreadID: threadID}, stateChange: RuntimeTestState{Value: value}}
+}
+
+// TestOutcomeQueue_RoundRobin tests that the threads are served in turn, each one in order
+func TestOutcomeQueue_RoundRobin(t *testing.T) {
+	queue := newOutcomeQueue[RuntimeTestState](10)
+	ctx := context.Background()
+	for _, outcome := range [][2]string{{"busy", "b1"}, {"busy", "b2"}, {"busy", "b3"}, {"quiet", "q1"}, {"other", "o1"}} {
+		queue.push(ctx, queuedOutcomeOf(outcome[0], outcome[1]))
+	}
+
+	if stats := queue.stats(); stats.Queued != 5 || stats.Threads != 3 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+
+	var order []string
+	for range 5 {
+		result, ok := queue.pop(ctx)
+		if !ok {
+			t.Fatal("Expected an outcome")
+		}
+		order = append(order, result.stateChange.Value)
+	}
+	if got := len(order); got != 5 || order[0] != "b1" || order[1] != "q1" || order[2] != "o1" || order[3] != "b2" || order[4] != "b3" {
+		t.Errorf("Expected the threads to be served in round-robin, got %v", order)
+	}
+
+	if stats := queue.stats(); stats.Queued != 0 || stats.Threads != 0 || stats.Processed != 5 || stats.MaxQueueWait < stats.AverageQueueWait() {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+// TestOutcomeQueue_Backpressure tests that the producers wait for room in a full queue
+func TestOutcomeQueue_Backpressure(t *testing.T) {
+	queue := newOutcomeQueue[RuntimeTestState](1)
+	ctx := context.Background()
+	queue.push(ctx, queuedOutcomeOf("thread", "first"))
+
+	pushed := make(chan bool)
+	go func() {
+		pushed <- queue.push(ctx, queuedOutcomeOf("thread", "second"))
+	}()
+	select {
+	case <-pushed:
+		t.Fatal("Expected the producer to wait for room")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	if result, _ := queue.pop(ctx); result.stateChange.Value != "first" {
+		t.Errorf("Expected the first outcome, got %+v", result)
+	}
+	if ok := <-pushed; !ok {
+		t.Error("Expected the producer to push once the room is freed")
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if queue.push(cancelled, queuedOutcomeOf("thread", "dropped")) {
+		t.Error("Expected the outcome to be dropped once the context is done")
+	}
+	queue.pop(ctx)
+	if _, ok := queue.pop(cancelled); ok {
+		t.Error("Expected no outcome from an empty queue once the context is done")
+	}
+}
+
+// TestRuntime_OutcomeStats tests that the runtime reports the processing of the outcomes
+func TestRuntime_OutcomeStats(t *testing.T) {
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, testNode("Node1", countInput))
+
+	runtime.Invoke(RuntimeTestState{})
+	waitCompletion(t, stateMonitorCh, 1)
+
+	if stats := runtime.OutcomeStats(); stats.Processed != 3 || stats.Queued != 0 {
+		t.Errorf("Expected the outcomes of the three nodes to be processed, got %+v", stats)
+	}
+}
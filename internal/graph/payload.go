@@ -0,0 +1,45 @@
+package graph
+
+import (
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+var _ g.PayloadCarrier = (*invocation)(nil)
+
+// edgePayload is a payload sent along an edge, set tells a nil payload from none.
+type edgePayload struct {
+	value any
+	set   bool
+}
+
+func (i *invocation) Inbound() (any, bool) {
+	i.payloadMu.Lock()
+	defer i.payloadMu.Unlock()
+	return i.inbound.value, i.inbound.set
+}
+
+func (i *invocation) Outbound(value any) {
+	i.payloadMu.Lock()
+	defer i.payloadMu.Unlock()
+	i.outbound = edgePayload{value: value, set: true}
+}
+
+// sentPayload returns the payload sent by the current node, not yet delivered to the next one.
+func (i *invocation) sentPayload() edgePayload {
+	i.payloadMu.Lock()
+	defer i.payloadMu.Unlock()
+	return i.outbound
+}
+
+// routePayload delivers the payload sent by the completed node to the next one, discarding the previous one.
+func (r *runtimeImpl[T]) routePayload(config g.InvokeConfig) {
+	inv, ok := r.invocations.Load(config.ThreadID)
+	if !ok || inv.(*invocation).ctx != config.Context {
+		return
+	}
+	useInvocation := inv.(*invocation)
+	useInvocation.payloadMu.Lock()
+	defer useInvocation.payloadMu.Unlock()
+	useInvocation.inbound = useInvocation.outbound
+	useInvocation.outbound = edgePayload{}
+}
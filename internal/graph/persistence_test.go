@@ -0,0 +1,124 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+type countingMemory struct {
+	mu       sync.Mutex
+	persists []RuntimeTestState
+}
+
+func (m *countingMemory) PersistFn() g.PersistFn[RuntimeTestState] {
+	return func(ctx context.Context, key string, state RuntimeTestState) error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.persists = append(m.persists, state)
+		return nil
+	}
+}
+
+func (m *countingMemory) RestoreFn() g.RestoreFn[RuntimeTestState] {
+	return func(ctx context.Context, key string) (RuntimeTestState, error) {
+		return RuntimeTestState{}, nil
+	}
+}
+
+// runCoalescingGraph runs a graph incrementing the counter in 3 nodes, the last one failing when requested,
+// and returns the persisted states.
+func runCoalescingGraph(t *testing.T, settings g.RuntimeSettings, failing bool) []RuntimeTestState {
+	policy, _ := RouterPolicyImplFactory(AnyRoute[RuntimeTestState])
+	opts := func() *g.NodeOptions[RuntimeTestState] {
+		return &g.NodeOptions[RuntimeTestState]{RoutingPolicy: policy, Reducer: Replacer[RuntimeTestState]}
+	}
+	increment := func(userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		return RuntimeTestState{Counter: currentState.Counter + 1}, nil
+	}
+
+	startNode, _ := NodeImplFactory(g.StartNode, "StartNode", nil, opts())
+	node1, _ := NodeImplFactory(g.IntermediateNode, "Node1", increment, opts())
+	node2, _ := NodeImplFactory(g.IntermediateNode, "Node2", increment, opts())
+	node3, _ := NodeImplFactory(g.IntermediateNode, "Node3", func(userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		if failing {
+			return currentState, errors.New("node3 failure")
+		}
+		return increment(userInput, currentState, notify)
+	}, opts())
+	endNode, _ := NodeImplFactory(g.EndNode, "EndNode", nil, opts())
+
+	memory := &countingMemory{}
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 100)
+	runtime, err := RuntimeFactory(
+		&mockRuntimeEdge{from: startNode, to: node1, role: g.StartEdge},
+		stateMonitorCh,
+		&g.RuntimeOptions[RuntimeTestState]{Memory: memory, Settings: settings},
+	)
+	if err != nil {
+		t.Fatalf("RuntimeFactory failed: %v", err)
+	}
+	runtime.AddEdge(
+		&mockRuntimeEdge{from: node1, to: node2},
+		&mockRuntimeEdge{from: node2, to: node3},
+		&mockRuntimeEdge{from: node3, to: endNode, role: g.EndEdge},
+	)
+
+	runtime.Invoke(RuntimeTestState{})
+	waitTerminalEntry(t, stateMonitorCh)
+	// Shutdown flushes the pending persistence jobs.
+	runtime.Shutdown()
+
+	memory.mu.Lock()
+	defer memory.mu.Unlock()
+	expected := 3
+	if failing {
+		expected = 2
+	}
+	if len(memory.persists) == 0 || memory.persists[len(memory.persists)-1].Counter != expected {
+		t.Fatalf("Expected the final state to be persisted, got %+v", memory.persists)
+	}
+	return memory.persists
+}
+
+// TestRuntime_PersistenceCoalescing tests the persistence mode and debounce settings
+func TestRuntime_PersistenceCoalescing(t *testing.T) {
+	t.Run("persists every node by default", func(t *testing.T) {
+		persists := runCoalescingGraph(t, g.RuntimeSettings{}, false)
+		if len(persists) != 4 {
+			t.Errorf("Expected 4 persistence jobs, got %d", len(persists))
+		}
+	})
+
+	t.Run("persists at invocation end only", func(t *testing.T) {
+		persists := runCoalescingGraph(t, g.RuntimeSettings{PersistenceMode: g.PersistAtInvocationEnd}, false)
+		if len(persists) != 1 {
+			t.Errorf("Expected 1 persistence job, got %d", len(persists))
+		}
+	})
+
+	t.Run("debounces intermediate steps", func(t *testing.T) {
+		persists := runCoalescingGraph(t, g.RuntimeSettings{PersistenceDebounce: time.Hour}, false)
+		if len(persists) != 2 {
+			t.Errorf("Expected the first step and the end to be persisted, got %d jobs", len(persists))
+		}
+		if persists[0].Counter != 1 {
+			t.Errorf("Expected the first step to be persisted, got %+v", persists[0])
+		}
+	})
+
+	t.Run("persists the progress of a failed invocation", func(t *testing.T) {
+		persists := runCoalescingGraph(t, g.RuntimeSettings{PersistenceMode: g.PersistAtInvocationEnd}, true)
+		if len(persists) != 1 {
+			t.Errorf("Expected 1 persistence job at the failure, got %d", len(persists))
+		}
+		persists = runCoalescingGraph(t, g.RuntimeSettings{PersistenceDebounce: time.Hour}, true)
+		if len(persists) != 2 || persists[0].Counter != 1 {
+			t.Errorf("Expected the first step and the failure to be persisted, got %+v", persists)
+		}
+	})
+}
@@ -0,0 +1,175 @@
+package graph
+
+import (
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"sync"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// activeRollout retains the versions of the graph split by a rollout.
+type activeRollout[T g.SharedState] struct {
+	config g.Rollout
+	stable *graphVersion[T]
+	// canary is nil when the rollout follows the latest version.
+	canary *graphVersion[T]
+}
+
+func (a *activeRollout[T]) canaryOr(latest *graphVersion[T]) *graphVersion[T] {
+	if a.canary != nil {
+		return a.canary
+	}
+	return latest
+}
+
+// versionStats accounts the invocations of a graph version.
+type versionStats struct {
+	mu    sync.Mutex
+	stats g.GraphVersionStats
+}
+
+func (r *runtimeImpl[T]) SetRollout(rollout g.Rollout) error {
+	if rollout.CanaryPercent < 0 || rollout.CanaryPercent > 100 {
+		return fmt.Errorf("cannot set rollout: %w: %d", g.ErrInvalidRollout, rollout.CanaryPercent)
+	}
+
+	r.graphMu.Lock()
+	defer r.graphMu.Unlock()
+
+	stable, err := r.retainedVersion(rollout.Stable)
+	if err != nil {
+		return fmt.Errorf("cannot set rollout: stable %w", err)
+	}
+	var canary *graphVersion[T]
+	if rollout.Canary != 0 {
+		if canary, err = r.retainedVersion(rollout.Canary); err != nil {
+			return fmt.Errorf("cannot set rollout: canary %w", err)
+		}
+	}
+
+	r.rollout.Store(&activeRollout[T]{config: rollout, stable: stable, canary: canary})
+	r.logger.Info("rollout updated", "stable", rollout.Stable, "canary", rollout.Canary, "canaryPercent", rollout.CanaryPercent)
+	return nil
+}
+
+// retainedVersion returns the given version of the graph if it is the latest one or one of the current rollout, the caller holds graphMu.
+func (r *runtimeImpl[T]) retainedVersion(version uint64) (*graphVersion[T], error) {
+	if latest := r.graph.Load(); latest.version == version {
+		return latest, nil
+	}
+	if active := r.rollout.Load(); active != nil {
+		for _, retained := range []*graphVersion[T]{active.stable, active.canary} {
+			if retained != nil && retained.version == version {
+				return retained, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("version %d: %w", version, g.ErrUnknownGraphVersion)
+}
+
+func (r *runtimeImpl[T]) EndRollout(promote bool) error {
+	r.graphMu.Lock()
+	defer r.graphMu.Unlock()
+
+	active := r.rollout.Load()
+	if active == nil {
+		return fmt.Errorf("cannot end rollout: %w", g.ErrNoActiveRollout)
+	}
+
+	latest := r.graph.Load()
+	kept := active.stable
+	if promote {
+		kept = active.canaryOr(latest)
+	}
+	if kept != latest {
+		r.graph.Store(&graphVersion[T]{
+			version:   latest.version + 1,
+			startEdge: latest.startEdge,
+			edges:     slices.Clone(kept.edges),
+		})
+	}
+	r.rollout.Store(nil)
+	r.logger.Info("rollout ended", "promoted", promote, "kept", kept.version, "version", r.graph.Load().version)
+	return nil
+}
+
+func (r *runtimeImpl[T]) RolloutStats() g.RolloutStats {
+	active := r.rollout.Load()
+	if active == nil {
+		return g.RolloutStats{}
+	}
+	canary := active.canaryOr(r.graph.Load())
+	rollout := active.config
+	rollout.Canary = canary.version
+	return g.RolloutStats{
+		Active:  true,
+		Rollout: rollout,
+		Stable:  r.GraphVersionStats(active.stable.version),
+		Canary:  r.GraphVersionStats(canary.version),
+	}
+}
+
+func (r *runtimeImpl[T]) GraphVersionStats(version uint64) g.GraphVersionStats {
+	if stats, ok := r.versionStats.Load(version); ok {
+		stats.(*versionStats).mu.Lock()
+		defer stats.(*versionStats).mu.Unlock()
+		return stats.(*versionStats).stats
+	}
+	return g.GraphVersionStats{Version: version}
+}
+
+// graphFor returns the version of the graph a new invocation starts with.
+func (r *runtimeImpl[T]) graphFor(config g.InvokeConfig) *graphVersion[T] {
+	latest := r.graph.Load()
+	active := r.rollout.Load()
+	if active == nil {
+		return latest
+	}
+	if active.config.Selector != nil {
+		if canary, ok := active.config.Selector(config); ok {
+			if canary {
+				return active.canaryOr(latest)
+			}
+			return active.stable
+		}
+	}
+	if rolloutBucket(config.ThreadID) < active.config.CanaryPercent {
+		return active.canaryOr(latest)
+	}
+	return active.stable
+}
+
+// rolloutBucket maps the thread to a bucket from 0 to 99, so that the invocations of a thread stick to the same version.
+func rolloutBucket(threadID string) int {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(threadID))
+	return int(hash.Sum32() % 100)
+}
+
+func (r *runtimeImpl[T]) versionStatsOf(version uint64) *versionStats {
+	stats, _ := r.versionStats.LoadOrStore(version, &versionStats{stats: g.GraphVersionStats{Version: version}})
+	return stats.(*versionStats)
+}
+
+func (r *runtimeImpl[T]) recordVersionStart(version uint64) {
+	stats := r.versionStatsOf(version)
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	stats.stats.Invocations++
+}
+
+func (r *runtimeImpl[T]) recordVersionEnd(inv *invocation, failed bool) {
+	stats := r.versionStatsOf(inv.graphVersion)
+	stats.mu.Lock()
+	defer stats.mu.Unlock()
+	if failed {
+		stats.stats.Failed++
+	} else {
+		stats.stats.Completed++
+	}
+	stats.stats.TotalDuration += time.Since(inv.startedAt)
+	stats.stats.Usage = stats.stats.Usage.Add(inv.currentUsage())
+}
@@ -0,0 +1,134 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// TestRuntime_Rollout tests that the new invocations are split between the stable and the canary versions of the graph
+func TestRuntime_Rollout(t *testing.T) {
+	policy, _ := RouterPolicyImplFactory(AnyRoute[RuntimeTestState])
+	opts := &g.NodeOptions[RuntimeTestState]{RoutingPolicy: policy, Reducer: Replacer[RuntimeTestState]}
+
+	startNode, _ := NodeImplFactory(g.StartNode, "StartNode", nil, opts)
+	node1, _ := NodeImplFactory(g.IntermediateNode, "Node1", func(userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		currentState.Value += "1"
+		return currentState, nil
+	}, opts)
+	node2, _ := NodeImplFactory(g.IntermediateNode, "Node2", func(userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		if userInput.Value == "fail" {
+			return currentState, errors.New("canary failure")
+		}
+		currentState.Value += "2"
+		return currentState, nil
+	}, opts)
+	endNode, _ := NodeImplFactory(g.EndNode, "EndNode", nil, opts)
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 100)
+	runtime, err := RuntimeFactory(&mockRuntimeEdge{from: startNode, to: node1, role: g.StartEdge}, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{})
+	if err != nil {
+		t.Fatalf("RuntimeFactory failed: %v", err)
+	}
+	defer runtime.Shutdown()
+
+	directEnd := &mockRuntimeEdge{from: node1, to: endNode, role: g.EndEdge}
+	runtime.AddEdge(directEnd)
+	stable := runtime.GraphVersion()
+
+	run := func(threadID string, userInput RuntimeTestState, configs ...g.InvokeConfig) g.StateMonitorEntry[RuntimeTestState] {
+		runtime.Invoke(userInput, append(configs, g.InvokeConfigThreadID(threadID))...)
+		return waitTerminalEntry(t, stateMonitorCh)
+	}
+
+	if err := runtime.SetRollout(g.Rollout{Stable: stable}); err != nil {
+		t.Fatalf("SetRollout failed: %v", err)
+	}
+	runtime.AddEdge(&mockRuntimeEdge{from: node1, to: node2, role: g.IntermediateEdge}, &mockRuntimeEdge{from: node2, to: endNode, role: g.EndEdge})
+	_ = runtime.RemoveEdge(directEnd)
+	canary := runtime.GraphVersion()
+
+	if final := run("frozen", RuntimeTestState{}); final.NewState.Value != "1" {
+		t.Errorf("Expected the new invocations to stay on the stable version, got %+v", final)
+	}
+
+	t.Run("metadata", func(t *testing.T) {
+		if err := runtime.SetRollout(g.Rollout{Stable: stable, Selector: g.MetadataSelector("track", "canary")}); err != nil {
+			t.Fatalf("SetRollout failed: %v", err)
+		}
+		if final := run("header-canary", RuntimeTestState{}, g.InvokeConfigMetadata(map[string]string{"track": "canary"})); final.NewState.Value != "12" {
+			t.Errorf("Expected the canary version, got %+v", final)
+		}
+		if final := run("header-stable", RuntimeTestState{}, g.InvokeConfigMetadata(map[string]string{"track": "blue"})); final.NewState.Value != "1" {
+			t.Errorf("Expected the stable version, got %+v", final)
+		}
+	})
+
+	t.Run("percentage", func(t *testing.T) {
+		if err := runtime.SetRollout(g.Rollout{Stable: stable, CanaryPercent: 50}); err != nil {
+			t.Fatalf("SetRollout failed: %v", err)
+		}
+		for idx := range 10 {
+			threadID := fmt.Sprintf("split-%d", idx)
+			expected := "1"
+			if rolloutBucket(threadID) < 50 {
+				expected = "12"
+			}
+			if final := run(threadID, RuntimeTestState{}); final.NewState.Value != expected {
+				t.Errorf("Expected thread %s to end with %q, got %+v", threadID, expected, final)
+			}
+		}
+	})
+
+	t.Run("stats", func(t *testing.T) {
+		_ = runtime.SetRollout(g.Rollout{Stable: stable, CanaryPercent: 100})
+		if final := run("canary-failure", RuntimeTestState{Value: "fail"}); final.Error == nil {
+			t.Fatalf("Expected the canary invocation to fail, got %+v", final)
+		}
+
+		deadline := time.Now().Add(2 * time.Second)
+		for runtime.RolloutStats().Canary.Failed == 0 {
+			if time.Now().After(deadline) {
+				t.Fatal("Test timed out waiting for the failure to be accounted")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		stats := runtime.RolloutStats()
+		if !stats.Active || stats.Rollout.Stable != stable || stats.Rollout.Canary != canary {
+			t.Errorf("Unexpected rollout %+v", stats.Rollout)
+		}
+		if stats.Stable.Invocations != stats.Stable.Completed || stats.Stable.Failed != 0 || stats.Stable.Completed == 0 {
+			t.Errorf("Unexpected stable stats %+v", stats.Stable)
+		}
+		if stats.Canary.Failed != 1 || stats.Canary.FailureRate() <= 0 {
+			t.Errorf("Unexpected canary stats %+v", stats.Canary)
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		if err := runtime.SetRollout(g.Rollout{Stable: stable, CanaryPercent: 101}); !errors.Is(err, g.ErrInvalidRollout) {
+			t.Errorf("Expected ErrInvalidRollout, got %v", err)
+		}
+		if err := runtime.SetRollout(g.Rollout{Stable: 42}); !errors.Is(err, g.ErrUnknownGraphVersion) {
+			t.Errorf("Expected ErrUnknownGraphVersion, got %v", err)
+		}
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		if err := runtime.EndRollout(false); err != nil {
+			t.Fatalf("EndRollout failed: %v", err)
+		}
+		if runtime.GraphVersion() != canary+1 || runtime.RolloutStats().Active {
+			t.Errorf("Expected the stable version to become the latest one, got version %d", runtime.GraphVersion())
+		}
+		if final := run("rolled-back", RuntimeTestState{}); final.NewState.Value != "1" {
+			t.Errorf("Expected the stable topology, got %+v", final)
+		}
+		if err := runtime.EndRollout(true); !errors.Is(err, g.ErrNoActiveRollout) {
+			t.Errorf("Expected ErrNoActiveRollout, got %v", err)
+		}
+	})
+}
@@ -3,6 +3,7 @@ package graph
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"reflect"
 	"sync"
 	"sync/atomic"
@@ -14,6 +15,9 @@ import (
 type pendingPersistEntry[T g.SharedState] struct {
 	threadID string
 	state    T
+	seq      uint64
+	// releaseLease marks the release of the lease of the thread, after its pending writes, see WithThreadLeases.
+	releaseLease bool
 }
 
 // RuntimeFactory creates a new instance of Runtime with the specified SharedState type, state merger function, and initial state.
@@ -35,18 +39,31 @@ func RuntimeFactory[T g.SharedState](
 		return nil, fmt.Errorf("runtime creation failed: %w", g.ErrRuntimeOptionsNil)
 	}
 
+	if opts.WorkQueue != nil && opts.Memory == nil {
+		return nil, fmt.Errorf("runtime creation failed: %w", g.ErrWorkQueueWithoutMemory)
+	}
+	if opts.LeaseStore != nil && opts.Memory == nil {
+		return nil, fmt.Errorf("runtime creation failed: %w", g.ErrLeasesWithoutMemory)
+	}
+
 	opts.Settings = g.FillRuntimeSettingsWithDefaults(opts.Settings)
 
+	logger := newRuntimeLogger(opts.Logger)
+	monitor, err := newMonitorDelivery(stateMonitorCh, opts.Settings, logger)
+	if err != nil {
+		return nil, fmt.Errorf("runtime creation failed: %w", err)
+	}
+
 	ctx, cancelFn := context.WithCancel(context.Background())
 	rv := &runtimeImpl[T]{
 		ctx:    ctx,
 		cancel: cancelFn,
 
-		outcomeCh:      make(chan nodeFnReturnStruct[T], opts.Settings.OutcomeNotificationQueueSize),
+		outcomes:       newOutcomeQueue[T](opts.Settings.OutcomeNotificationQueueSize),
 		stateMonitorCh: stateMonitorCh,
+		monitor:        monitor,
 
 		startEdge: startEdge,
-		edges:     []g.Edge[T]{},
 
 		workerPool: newWorkerPool(
 			opts.WorkerCount,
@@ -66,17 +83,84 @@ func RuntimeFactory[T g.SharedState](
 		pendingPersist: make(chan pendingPersistEntry[T], opts.Settings.PersistenceJobsQueueSize),
 
 		threadTTL: sync.Map{}, // map[string]time.Time
+
+		threadMeta: sync.Map{}, // map[string]*threadMeta
+
+		invocations: sync.Map{}, // map[string]*invocation
+
+		deadLetters:  opts.DeadLetterQueue,
+		deadLettered: make(map[string]*deadLetterTracker),
+
+		admission: newAdmissionController(opts.Settings.MaxConcurrentInvocations, opts.Settings.MaxQueuedInvocations),
+
+		hooks:   opts.Hooks,
+		logger:  logger,
+		cloneFn: opts.CloneFn,
+
+		allowCycles:   opts.AllowCycles,
+		maxIterations: opts.MaxIterations,
+
+		conflictResolver: opts.ConflictResolver,
+
+		streamingReducer: opts.StreamingReducer,
+
+		debug:       opts.Debug,
+		breakpoints: opts.Breakpoints,
+
+		events:     newEventBus[T](),
+		eventStore: opts.EventStore,
+
+		chaos: newChaosMonkey(opts.Chaos),
+
+		diffFn: opts.StateDiff,
+
+		idGenerator: opts.IDGenerator,
+		aliases:     newThreadAliases(),
+
+		defaultTenantQuota: opts.DefaultTenantQuota,
+		tenantQuotas:       opts.TenantQuotas,
+
+		timeline:  newTimelineRecorder(opts.TimelineThreads, opts.TimelineInvocations),
+		nodeStats: newNodeStatsRecorder(opts.NodeStatsWindow, opts.LatencySLO, opts.NodeLatencySLOs),
+
+		workQueue:   opts.WorkQueue,
+		remoteNodes: opts.RemoteNodes,
+		instanceID:  g.UUID(),
+
+		leaseStore: opts.LeaseStore,
+		leaseTTL:   opts.LeaseTTL,
+
+		journal: opts.Journal,
+
+		onceStore:    onceStoreOf(opts.Memory),
+		scratchStore: scratchStoreOf(opts.Memory),
+	}
+	if rv.idGenerator == nil {
+		rv.idGenerator = g.UUID
 	}
+	// The sequence of the persistence jobs starts from the current time, so that they stay ordered, and the keys of
+	// their dead letters unique, across restarts.
+	rv.persistSeq.Store(uint64(time.Now().UnixNano()))
+	rv.loadDeadLetterTrackers()
+
+	rv.graph.Store(&graphVersion[T]{version: 1, startEdge: startEdge})
 
 	if opts.Memory != nil {
-		rv.persistFn = opts.Memory.PersistFn()
+		rv.persistFn = delayPersistence(rv.chaos, opts.Memory.PersistFn())
 		rv.restoreFn = opts.Memory.RestoreFn()
 
 		rv.startPersistenceWorker()
 	}
 
 	rv.start()
+	if rv.workQueue != nil {
+		rv.startRemoteResults()
+	}
+	if rv.leaseStore != nil {
+		rv.startLeaseRenewer()
+	}
 	rv.startThreadEvictor()
+	rv.startMonitorDrainer()
 	return rv, nil
 }
 
@@ -89,6 +173,16 @@ var _ g.StateObserver[g.SharedState] = (*runtimeImpl[g.SharedState])(nil)
 var _ g.Persistent[g.SharedState] = (*runtimeImpl[g.SharedState])(nil)
 var _ g.Threaded = (*runtimeImpl[g.SharedState])(nil)
 var _ g.NodeExecutor = (*runtimeImpl[g.SharedState])(nil)
+var _ g.Admitting = (*runtimeImpl[g.SharedState])(nil)
+var _ g.Monitored = (*runtimeImpl[g.SharedState])(nil)
+var _ g.Debugger[g.SharedState] = (*runtimeImpl[g.SharedState])(nil)
+var _ g.Analyzer = (*runtimeImpl[g.SharedState])(nil)
+var _ g.Topology[g.SharedState] = (*runtimeImpl[g.SharedState])(nil)
+var _ g.EventBus[g.SharedState] = (*runtimeImpl[g.SharedState])(nil)
+var _ g.EventLogged[g.SharedState] = (*runtimeImpl[g.SharedState])(nil)
+var _ g.Timelined = (*runtimeImpl[g.SharedState])(nil)
+var _ g.Durable = (*runtimeImpl[g.SharedState])(nil)
+var _ g.Onced = (*runtimeImpl[g.SharedState])(nil)
 
 type nodeFnReturnStruct[T g.SharedState] struct {
 	node        g.Node[T]
@@ -104,11 +198,42 @@ type runtimeImpl[T g.SharedState] struct {
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	outcomeCh      chan nodeFnReturnStruct[T]
+	outcomes       *outcomeQueue[T]
 	stateMonitorCh chan g.StateMonitorEntry[T]
+	monitor        *monitorDelivery[T]
 
 	startEdge g.Edge[T]
-	edges     []g.Edge[T]
+	graph     atomic.Pointer[graphVersion[T]]
+	graphMu   sync.Mutex
+	rollout   atomic.Pointer[activeRollout[T]]
+	// versionStats holds the metrics of the graph versions, map[uint64]*versionStats.
+	versionStats sync.Map
+
+	timeline *timelineRecorder
+	// nodeStats accounts the latency, the partial updates and the usage of the nodes, see g.Profiled.
+	nodeStats *nodeStatsRecorder
+
+	// workQueue publishes the executions of the remoteNodes to the workers, their results are addressed to instanceID.
+	workQueue   g.WorkQueue
+	remoteNodes []string
+	instanceID  string
+	remoteCalls sync.Map // map[string]*remoteCall[T]
+
+	// leaseStore grants the ownership of the threads among the instances, heldLeases are the ones owned by instanceID.
+	leaseStore g.LeaseStore
+	leaseTTL   time.Duration
+	leaseMu    sync.Mutex
+	heldLeases sync.Map // map[string]*heldLease
+
+	// journal records the steps of the invocations, see Resume.
+	journal g.Journal[T]
+
+	// onceStore records the side effects executed with Once.
+	onceStore g.OnceStore
+
+	// scratchStore persists the scratchpads of the threads, nil when they are kept in memory only.
+	scratchStore g.ScratchStore
+	scratch      sync.Map // map[string]*scratchpad
 
 	workerPool *workerPool
 
@@ -124,54 +249,169 @@ type runtimeImpl[T g.SharedState] struct {
 	lastPersisted sync.Map // map[string]T
 
 	pendingPersist chan pendingPersistEntry[T]
+	// unpersisted is the latest state of each thread queued for persistence, until the worker writes it.
+	unpersisted sync.Map // map[string]*pendingPersistEntry[T]
 
 	threadTTL sync.Map // map[string]time.Time
 
+	threadMeta sync.Map // map[string]*threadMeta
+
+	invocations sync.Map // map[string]*invocation
+
+	persistSeq   atomic.Uint64
+	deadLetters  g.DeadLetterQueue[T]
+	deadLettered map[string]*deadLetterTracker
+	deadLetterMu sync.Mutex
+
+	admission *admissionController
+
+	hooks   g.Hooks[T]
+	logger  *slog.Logger
+	cloneFn g.CloneFn[T]
+
+	allowCycles   bool
+	maxIterations int
+
+	conflictResolver g.ConflictResolverFn[T]
+
+	streamingReducer g.ReducerFn[T]
+	streaming        sync.Map // map[string]T
+
+	debug       bool
+	breakpoints []string
+	paused      sync.Map // map[string]*pausedNode[T]
+
+	sleeping sync.Map // map[string]*sleepingNode[T]
+	parked   sync.Map // map[string]*parkedNode[T]
+	batches  sync.Map // map[string]*collectingBatch[T]
+
+	events     *eventBus[T]
+	eventStore g.EventStore[T]
+
+	chaos *chaosMonkey
+
+	diffFn g.DiffFn[T]
+
+	idGenerator g.IDGeneratorFn
+	aliases     *threadAliases
+
+	defaultTenantQuota g.TenantQuota
+	tenantQuotas       map[string]g.TenantQuota
+	tenants            sync.Map // map[string]*tenantUsage
+	tenantMu           sync.Mutex
+
+	// stopping rejects the new invocations once Stop is called, stopRejected counts them.
+	stopping     atomic.Bool
+	stopRejected atomic.Int64
+	shutdownOnce sync.Once
+
 	backgroundWorkers sync.WaitGroup
 }
 
 func (r *runtimeImpl[T]) Invoke(userInput T, configs ...g.InvokeConfig) string {
-	requestedConfig := g.MergeInvokeConfig(configs...)
+	threadID, _ := r.invoke(userInput, g.MergeInvokeConfig(configs...), nil)
+	return threadID
+}
+
+// invoke starts the invocation of the thread, from the start edge or, when resumed, from the resume point; it returns
+// the thread ID and the error rejecting the invocation, if any, which is also sent to the monitor.
+func (r *runtimeImpl[T]) invoke(userInput T, requestedConfig g.InvokeConfig, point *resumePoint[T]) (string, error) {
+	if requestedConfig.ThreadID == "" {
+		requestedConfig.ThreadID = r.idGenerator()
+	}
+	requestedConfig.ThreadID = g.TenantThreadID(requestedConfig.Tenant, requestedConfig.ThreadID)
 	useConfig := g.MergeInvokeConfig(g.DefaultInvokeConfig(), requestedConfig)
 
+	if r.stopping.Load() {
+		r.stopRejected.Add(1)
+		err := fmt.Errorf("cannot invoke graph for thread %s: %w", useConfig.ThreadID, g.ErrRuntimeShuttingDown)
+		r.logger.Warn("invocation rejected", logAttrThreadID, useConfig.ThreadID, "error", err)
+		r.sendMonitorEntry(monitorError[T]("Runtime", useConfig.ThreadID, err))
+		return useConfig.ThreadID, err
+	}
+
+	var newThread bool
+	if useConfig.Tenant != "" {
+		if err := g.ValidateTenant(useConfig.Tenant); err != nil {
+			err = fmt.Errorf("cannot invoke graph for thread %s: %w", useConfig.ThreadID, err)
+			r.logger.Warn("invocation rejected", logAttrThreadID, useConfig.ThreadID, "error", err)
+			r.sendMonitorEntry(monitorError[T]("Runtime", useConfig.ThreadID, err))
+			return useConfig.ThreadID, err
+		}
+		var err error
+		if newThread, err = r.admitTenant(useConfig); err != nil {
+			r.logger.Warn("invocation rejected", logAttrThreadID, useConfig.ThreadID, logAttrTenant, useConfig.Tenant, "error", err)
+			entry := monitorError[T]("Runtime", useConfig.ThreadID, err)
+			entry.Tenant = useConfig.Tenant
+			r.sendMonitorEntry(entry)
+			return useConfig.ThreadID, err
+		}
+	}
+
+	// The inputs merged into a pending batch are admitted like the invocations, they do not start one though.
+	if point == nil && r.addToBatch(userInput, useConfig) {
+		r.releaseTenant(useConfig.Tenant)
+		r.settleTenantThread(useConfig.Tenant, newThread)
+		return useConfig.ThreadID, nil
+	}
+
 	if !r.threadExistsWithinTTL(useConfig.ThreadID) {
-		r.state.Store(useConfig.ThreadID, r.initialState)
+		r.state.Store(useConfig.ThreadID, r.clone(r.initialState))
 		_ = r.Restore(useConfig.ThreadID)
 	}
 
 	r.threadTTL.Store(useConfig.ThreadID, time.Now().Add(r.settings.ThreadTTL))
 
 	if !r.executingByThreadID(useConfig).CompareAndSwap(false, true) {
-		r.sendMonitorEntry(monitorError[T]("Runtime", useConfig.ThreadID, fmt.Errorf("cannot invoke graph for thread %s: %w", useConfig.ThreadID, g.ErrRuntimeExecuting)))
-		return useConfig.ThreadID
+		r.releaseTenant(useConfig.Tenant)
+		r.settleTenantThread(useConfig.Tenant, newThread)
+		err := fmt.Errorf("cannot invoke graph for thread %s: %w", useConfig.ThreadID, g.ErrRuntimeExecuting)
+		r.logger.Warn("invocation rejected", logAttrThreadID, useConfig.ThreadID, "error", err)
+		r.sendMonitorEntry(monitorError[T]("Runtime", useConfig.ThreadID, err))
+		return useConfig.ThreadID, err
 	}
 
-	r.startEdge.From().Accept(userInput, r, r, useConfig)
-	return useConfig.ThreadID
-}
+	if err := r.acquireLease(useConfig.ThreadID); err != nil {
+		r.clearThread(useConfig.ThreadID)
+		r.releaseTenant(useConfig.Tenant)
+		r.settleTenantThread(useConfig.Tenant, newThread)
+		r.logger.Warn("invocation rejected", logAttrThreadID, useConfig.ThreadID, "error", err)
+		r.sendMonitorEntry(monitorError[T]("Runtime", useConfig.ThreadID, err))
+		return useConfig.ThreadID, err
+	}
 
-func (r *runtimeImpl[T]) AddEdge(edge ...g.Edge[T]) {
-	r.edges = append(r.edges, edge...)
-}
+	// The thread is registered once its invocation is accepted, the rejected ones do not count against its tenant.
+	r.touchThread(useConfig.ThreadID, useConfig.Tenant)
+	r.settleTenantThread(useConfig.Tenant, newThread)
 
-func (r *runtimeImpl[T]) Validate() error {
-	if r.startEdge.From() == nil {
-		return fmt.Errorf("graph validation failed: %w", g.ErrSourceNodeNil)
+	if point == nil {
+		r.clearJournal(useConfig.ThreadID)
 	}
-
-	// Check if there's at least one path from start to an end edge
-	visited := make(map[string]bool)
-	// Include the start edge in the traversal by starting from its target node
-	hasPathToEnd := r.hasPathToEndEdge(r.startEdge.To(), visited)
-	if !hasPathToEnd {
-		return fmt.Errorf("graph validation failed: %w", g.ErrNoPathToEnd)
+	config := r.beginInvocation(useConfig)
+	if point != nil {
+		r.startFrom(config, point)
 	}
+	r.admit(userInput, config)
+	return useConfig.ThreadID, nil
+}
 
+// fallbackEdge returns the outbound edge labelled as fallback, if any.
+func fallbackEdge[T g.SharedState](edges []g.Edge[T]) g.Edge[T] {
+	for _, edge := range edges {
+		if isFallback, _ := g.IsFallbackEdge(edge); isFallback {
+			return edge
+		}
+	}
 	return nil
 }
 
 func (r *runtimeImpl[T]) Shutdown() {
+	r.shutdownOnce.Do(r.shutdown)
+}
+
+func (r *runtimeImpl[T]) shutdown() {
 	r.cancel()
+	defer r.events.close()
 
 	ctx, cancel := context.WithTimeout(context.Background(), r.settings.GracefulShutdownTimeout)
 	defer cancel()
@@ -186,9 +426,9 @@ func (r *runtimeImpl[T]) Shutdown() {
 	case <-done:
 	case <-ctx.Done():
 		close(r.pendingPersist)
-		close(r.outcomeCh)
 		r.workerPool.Shutdown()
 	}
+	r.releaseLeases()
 }
 
 func (r *runtimeImpl[T]) NotifyStateChange(
@@ -200,16 +440,24 @@ func (r *runtimeImpl[T]) NotifyStateChange(
 	err error,
 	partial bool,
 ) {
-	r.outcomeCh <- nodeFnReturnStruct[T]{node: node, userInput: userInput, stateChange: stateChange, err: err, partial: partial, reducer: reducer, config: config}
+	if partial {
+		r.nodeStats.partial(node.Name())
+	} else {
+		r.timeline.nodeEnded(config.ThreadID, node.Name(), err)
+		r.nodeStats.ended(config.ThreadID, node.Name(), err)
+	}
+	r.outcomes.push(r.ctx, nodeFnReturnStruct[T]{node: node, userInput: userInput, stateChange: stateChange, err: err, partial: partial, reducer: reducer, config: config})
 }
 
 func (r *runtimeImpl[T]) CurrentState(threadID string) T {
-	useState, _ := r.state.LoadOrStore(threadID, r.initialState)
-	return useState.(T)
+	if view, ok := r.streaming.Load(threadID); ok {
+		return r.clone(view.(T))
+	}
+	return r.committedState(threadID)
 }
 
 func (r *runtimeImpl[T]) InitialState() T {
-	return r.initialState
+	return r.clone(r.initialState)
 }
 
 func (r *runtimeImpl[T]) StartEdge() g.Edge[T] {
@@ -222,7 +470,7 @@ func (r *runtimeImpl[T]) Restore(threadID string) error {
 	}
 	restoredState, err := r.restoreFn(r.ctx, threadID)
 	if err != nil {
-		return fmt.Errorf("state restoration failed: %w", err)
+		return &g.PersistenceError{Node: "Runtime", ThreadID: threadID, Code: g.CodeRestoreFailed, Err: err}
 	}
 
 	r.state.Store(threadID, restoredState)
@@ -249,132 +497,245 @@ func (r *runtimeImpl[T]) persistState(threadID string) error {
 		return nil
 	}
 
-	currentState, _ := r.state.Load(threadID)
-	lastPersisted, _ := r.lastPersisted.Load(threadID)
-
-	if r.statesEqual(currentState.(T), lastPersisted.(T)) {
+	currentState, ok := r.state.Load(threadID)
+	if !ok {
+		return nil
+	}
+	if lastPersisted, ok := r.lastPersisted.Load(threadID); ok && r.statesEqual(currentState.(T), lastPersisted.(T)) {
 		return nil
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), r.settings.PersistenceJobTimeout)
 	defer cancel()
 
+	if meta, ok := r.threadMeta.Load(threadID); ok {
+		meta.(*threadMeta).persistedAt.Store(time.Now().UnixNano())
+	}
+
+	entry := r.nextPersistEntry(threadID, currentState.(T))
+	r.unpersisted.Store(threadID, &entry)
 	select {
-	case r.pendingPersist <- pendingPersistEntry[T]{threadID: threadID, state: currentState.(T)}:
+	case r.pendingPersist <- entry:
 	case <-ctx.Done():
-		r.sendMonitorEntry(monitorNonFatalError[T]("Persistence", threadID, fmt.Errorf("persistence timed out: %w", ctx.Err())))
+		r.settlePersist(entry)
+		err := fmt.Errorf("persistence timed out: %w", ctx.Err())
+		r.reportNonFatal("Persistence", threadID, &g.PersistenceError{Node: "Persistence", ThreadID: threadID, Code: g.CodePersistenceFailed, Err: err})
+		r.deadLetter(entry, err)
 	default:
-		r.sendMonitorEntry(monitorNonFatalError[T]("Persistence", threadID, fmt.Errorf("cannot persist state: %w", g.ErrPersistenceQueueFull)))
+		r.settlePersist(entry)
+		err := fmt.Errorf("cannot persist state: %w", g.ErrPersistenceQueueFull)
+		r.reportNonFatal("Persistence", threadID, &g.PersistenceError{Node: "Persistence", ThreadID: threadID, Code: g.CodePersistenceFailed, Err: err})
+		r.deadLetter(entry, err)
 	}
 
 	return nil
 }
 
+// settlePersist forgets the queued state of the entry, unless a later state of the thread was queued since.
+func (r *runtimeImpl[T]) settlePersist(entry pendingPersistEntry[T]) {
+	if pending, ok := r.unpersisted.Load(entry.threadID); ok && pending.(*pendingPersistEntry[T]).seq == entry.seq {
+		r.unpersisted.CompareAndDelete(entry.threadID, pending)
+	}
+}
+
+// flushState persists the state of the thread before it is released by a failed invocation, so
+// that neither PersistAtInvocationEnd nor the debounce discard the progress of the invocation.
+func (r *runtimeImpl[T]) flushState(node, threadID string) {
+	if err := r.persistState(threadID); err != nil {
+		r.reportNonFatal(node, threadID, &g.PersistenceError{Node: node, ThreadID: threadID, Code: g.CodePersistenceFailed, Err: err})
+	}
+}
+
+// persistStepDue reports whether the state of an intermediate step has to be persisted, according to the persistence mode and debounce settings.
+func (r *runtimeImpl[T]) persistStepDue(threadID string) bool {
+	if r.persistFn == nil || r.settings.PersistenceMode == g.PersistAtInvocationEnd {
+		return false
+	}
+	if r.settings.PersistenceDebounce <= 0 {
+		return true
+	}
+
+	meta, ok := r.threadMeta.Load(threadID)
+	if !ok {
+		return true
+	}
+	last := meta.(*threadMeta).persistedAt.Load()
+	return last == 0 || time.Since(time.Unix(0, last)) >= r.settings.PersistenceDebounce
+}
+
 func (r *runtimeImpl[T]) start() {
 	go r.onNodeOutcome()
 }
 
 func (r *runtimeImpl[T]) onNodeOutcome() {
 	for {
-		select {
-		case <-r.ctx.Done():
+		result, ok := r.outcomes.pop(r.ctx)
+		if !ok {
 			return
-		case result := <-r.outcomeCh:
-			useThreadID := result.config.ThreadID
-			useInvocationContext := result.config.Context
-			useExecuting := r.executingByThreadID(result.config)
+		}
 
-			if result.err != nil {
-				r.sendMonitorEntry(monitorError[T](result.node.Name(), useThreadID, result.err))
-				useExecuting.Store(false)
-				r.clearThread(useThreadID)
+		if r.isStaleOutcome(result.config) {
+			continue
+		}
+
+		useThreadID := result.config.ThreadID
+		useInvocationContext := result.config.Context
+		useExecuting := r.executingByThreadID(result.config)
+
+		if result.err != nil {
+			r.failInvocation(result.node.Name(), result.config, result.err)
+			continue
+		}
+
+		select {
+		case <-useInvocationContext.Done():
+			r.failInvocation(result.node.Name(), result.config, fmt.Errorf("invocation context done: %w", limitError(result.node.Name(), useThreadID, context.Cause(useInvocationContext))))
+			continue
+		default:
+			if result.partial {
+				r.foldPartial(useThreadID, result.stateChange)
+				r.sendMonitorEntry(monitorPartial(result.node.Name(), useThreadID, result.stateChange))
 				continue
 			}
 
-			select {
-			case <-useInvocationContext.Done():
-				err := r.persistState(useThreadID)
-				if err != nil {
-					r.sendMonitorEntry(monitorNonFatalError[T](result.node.Name(), useThreadID, fmt.Errorf("state persistence error: %w", err)))
-				}
-				r.sendMonitorEntry(monitorError[T](result.node.Name(), useThreadID, fmt.Errorf("invocation context done: %w", useInvocationContext.Err())))
-				useExecuting.Store(false)
-				r.clearThread(useThreadID)
+			before := r.stateBefore(useThreadID)
+			newState, err := r.reduce(result)
+			r.dropStreamingView(useThreadID)
+			if err != nil {
+				r.failInvocation(result.node.Name(), result.config, err)
 				continue
-			default:
-				if result.partial {
-					r.sendMonitorEntry(monitorPartial(result.node.Name(), useThreadID, result.stateChange))
-					continue
-				}
+			}
+			r.recordThreadStep(useThreadID)
 
-				newState := r.replace(useThreadID, result.stateChange, result.reducer)
+			if err := r.afterNode(result.node, result.config); err != nil {
+				r.failInvocation(result.node.Name(), result.config, err)
+				continue
+			}
+			r.recordCompletion(result)
 
+			if result.node.Role() == g.EndNode || r.persistStepDue(useThreadID) {
 				err := r.persistState(useThreadID)
 				if err != nil {
-					r.sendMonitorEntry(monitorNonFatalError[T](result.node.Name(), useThreadID, fmt.Errorf("state persistence error: %w", err)))
+					r.reportNonFatal(result.node.Name(), useThreadID, &g.PersistenceError{Node: result.node.Name(), ThreadID: useThreadID, Code: g.CodePersistenceFailed, Err: err})
 				}
+			}
 
-				if result.node.Role() == g.EndNode {
-					if r.stateMonitorCh != nil {
-						r.sendMonitorEntry(monitorCompleted(result.node.Name(), useThreadID, newState))
-					}
-					useExecuting.Store(false)
-					// Don't clear thread state immediately if there's no persistence
-					// This allows CurrentState() to return the final state
-					if r.persistFn != nil {
-						r.clearThread(useThreadID)
-					}
+			if result.node.Role() == g.EndNode {
+				r.onComplete(result.node, result.config, newState)
+				r.logInvocationCompleted(result.node.Name(), useThreadID)
+				entry := monitorCompleted(result.node.Name(), useThreadID, newState)
+				entry.Changes = r.stateChanges(before, newState)
+				entry.Outcome = r.graphOf(result.config).outcomeOf(result.node)
+				r.sendMonitorEntry(entry)
+				r.clearJournal(useThreadID)
+				useExecuting.Store(false)
+				r.endInvocation(useThreadID, useInvocationContext, nil)
+				if r.chaos.evictThread() {
+					r.logger.Warn("fault injected: thread eviction", logAttrThreadID, useThreadID)
+					r.evictThread(useThreadID)
 					continue
-				} else {
-					if r.stateMonitorCh != nil {
-						r.sendMonitorEntry(monitorRunning(result.node.Name(), useThreadID, newState))
-					}
 				}
-
-				outboundEdges := r.edgesFrom(result.node)
-				if len(outboundEdges) == 0 {
-					r.sendMonitorEntry(monitorError[T](result.node.Name(), useThreadID, fmt.Errorf("routing error for node %s: %w", result.node.Name(), g.ErrNoOutboundEdges)))
-					useExecuting.Store(false)
+				// Don't clear thread state immediately if there's no persistence
+				// This allows CurrentState() to return the final state
+				if r.persistFn != nil {
 					r.clearThread(useThreadID)
-					continue
 				}
+				continue
+			} else {
+				entry := monitorRunning(result.node.Name(), useThreadID, newState)
+				entry.Changes = r.stateChanges(before, newState)
+				r.sendMonitorEntry(entry)
+			}
 
-				policy := result.node.RoutePolicy()
-				if policy == nil {
-					r.sendMonitorEntry(monitorError[T](result.node.Name(), useThreadID, fmt.Errorf("routing error for node %s: %w", result.node.Name(), g.ErrNoRoutingPolicy)))
-					useExecuting.Store(false)
-					r.clearThread(useThreadID)
-					continue
-				}
+			outboundEdges := r.graphOf(result.config).edgesFrom(result.node)
+			if len(outboundEdges) == 0 {
+				r.failInvocation(result.node.Name(), result.config, &g.RoutingError{Node: result.node.Name(), ThreadID: useThreadID, Code: g.CodeNoOutboundEdges, Err: g.ErrNoOutboundEdges})
+				continue
+			}
+
+			policy := result.node.RoutePolicy()
+			if policy == nil {
+				r.failInvocation(result.node.Name(), result.config, &g.RoutingError{Node: result.node.Name(), ThreadID: useThreadID, Code: g.CodeNoRoutingPolicy, Err: g.ErrNoRoutingPolicy})
+				continue
+			}
 
-				currentState, _ := r.state.Load(useThreadID)
+			currentState, _ := r.state.Load(useThreadID)
 
-				nextEdge := policy.SelectEdge(result.userInput, currentState.(T), outboundEdges)
-				if nextEdge == nil {
-					r.sendMonitorEntry(monitorError[T](result.node.Name(), useThreadID, fmt.Errorf("routing error for node %s: %w", result.node.Name(), g.ErrNilEdge)))
-					useExecuting.Store(false)
-					r.clearThread(useThreadID)
-					continue
-				}
+			nextEdge := policy.SelectEdge(result.userInput, currentState.(T), outboundEdges)
+			fallback := false
+			if nextEdge == nil {
+				nextEdge = fallbackEdge(outboundEdges)
+				fallback = nextEdge != nil
+			}
+			if nextEdge == nil {
+				r.failInvocation(result.node.Name(), result.config, &g.RoutingError{Node: result.node.Name(), ThreadID: useThreadID, Code: g.CodeNoEdgeSelected, Err: g.ErrNilEdge})
+				continue
+			}
 
-				nextNode := nextEdge.To()
-				if nextNode == nil {
-					r.sendMonitorEntry(monitorError[T](result.node.Name(), useThreadID, fmt.Errorf("routing error for node %s: %w", result.node.Name(), g.ErrNextEdgeNil)))
-					useExecuting.Store(false)
-					r.clearThread(useThreadID)
-					continue
-				}
+			nextNode := nextEdge.To()
+			if nextNode == nil {
+				r.failInvocation(result.node.Name(), result.config, &g.RoutingError{Node: result.node.Name(), ThreadID: useThreadID, Code: g.CodeNextNodeNil, Err: g.ErrNextEdgeNil})
+				continue
+			}
 
-				nextNode.Accept(result.userInput, r, r, result.config)
+			if err := r.checkIterations(nextNode, result.config); err != nil {
+				r.failInvocation(nextNode.Name(), result.config, err)
+				continue
+			}
+			if err := r.checkTenantSteps(result.config); err != nil {
+				r.failInvocation(nextNode.Name(), result.config, err)
+				continue
+			}
+
+			wakeAt, err := r.wakeAtOf(nextNode, result.userInput, result.config)
+			if err != nil {
+				r.failInvocation(nextNode.Name(), result.config, err)
+				continue
+			}
+
+			if err := r.journalStep(result, nextNode, wakeAt); err != nil {
+				r.failInvocation(result.node.Name(), result.config, err)
+				continue
+			}
+
+			r.routePayload(result.config)
+			r.timeline.routed(useThreadID, result.node.Name(), nextNode.Name(), fallback)
+			r.logRoute(result.node, nextNode, useThreadID)
+			r.onRoute(result.node, nextNode, result.config)
+			if !wakeAt.IsZero() {
+				r.sleepUntil(nextNode, nextEdge, result.userInput, result.config, wakeAt)
+				continue
 			}
+			r.enterNode(nextNode, nextEdge, result.userInput, result.config)
 		}
 	}
 }
 
 func (r *runtimeImpl[T]) sendMonitorEntry(entry g.StateMonitorEntry[T]) {
+	if r.stateMonitorCh == nil && !r.events.hasSubscribers() && r.eventStore == nil {
+		return
+	}
+	if entry.Usage.IsZero() {
+		entry.Usage = r.invocationUsage(entry.ThreadID)
+	}
+	if entry.Tenant == "" {
+		entry.Tenant = r.threadTenant(entry.ThreadID)
+	}
+	if entry.Error != nil && entry.ErrorCode == "" {
+		entry.ErrorCode = g.ErrorCodeOf(entry.Error)
+	}
+	r.recordEvent(entry)
+	r.events.publish(entry, r.clone)
+
 	if r.stateMonitorCh == nil {
 		return
 	}
+	entry.NewState = r.clone(entry.NewState)
+
+	if r.chaos.dropMonitorEntry() {
+		r.monitor.drop(entry, "monitor entry dropped by the fault injection")
+		return
+	}
 
 	// Protect against panic if channel is closed during send
 	defer func() {
@@ -383,66 +744,21 @@ func (r *runtimeImpl[T]) sendMonitorEntry(entry g.StateMonitorEntry[T]) {
 		}
 	}()
 
-	select {
-	case r.stateMonitorCh <- entry:
-	case <-time.After(r.settings.OutcomeNotificationMaxInterval):
-	case <-r.ctx.Done():
-	}
+	r.monitor.deliver(r.ctx, entry)
 }
 
 func (r *runtimeImpl[T]) replace(threadID string, stateChange T, reducer g.ReducerFn[T]) T {
-	useState, _ := r.state.LoadOrStore(threadID, r.initialState)
+	useState, _ := r.state.LoadOrStore(threadID, r.clone(r.initialState))
 	newState := reducer(useState.(T), stateChange)
 	r.state.Swap(threadID, newState)
+	r.bumpStateVersion(threadID)
 
 	return newState
 }
 
+// edgesFrom returns the outbound edges of the node in the latest version of the graph.
 func (r *runtimeImpl[T]) edgesFrom(node g.Node[T]) []g.Edge[T] {
-	if r.startEdge.From() == node {
-		return []g.Edge[T]{r.StartEdge()}
-	}
-	var outboundEdges []g.Edge[T]
-	for _, edge := range r.edges {
-		if edge.From() == node {
-			outboundEdges = append(outboundEdges, edge)
-		}
-	}
-	return outboundEdges
-}
-
-func (r *runtimeImpl[T]) hasPathToEndEdge(node g.Node[T], visited map[string]bool) bool {
-	// Check if the node is an EndNode
-	if node.Role() == g.EndNode {
-		return true
-	}
-
-	// Mark the node as visited
-	nodeKey := fmt.Sprintf("%p", node)
-	if visited[nodeKey] {
-		return false
-	}
-	visited[nodeKey] = true
-
-	// Check if any EndEdge starts from this node
-	for _, edge := range r.edges {
-		if edge.Role() == g.EndEdge {
-			if edge.From() == node {
-				return true
-			}
-		}
-	}
-
-	// Explore all edges to find connected nodes
-	for _, edge := range r.edges {
-		if edge.From() == node {
-			if r.hasPathToEndEdge(edge.To(), visited) {
-				return true
-			}
-		}
-	}
-
-	return false
+	return r.graph.Load().edgesFrom(node)
 }
 
 func (r *runtimeImpl[T]) startPersistenceWorker() {
@@ -459,9 +775,17 @@ func (r *runtimeImpl[T]) persistenceWorker() {
 			r.flushPendingStates()
 			return
 		case state := <-r.pendingPersist:
+			if r.fencedWrite(state) {
+				r.settlePersist(state)
+				continue
+			}
 			if err := r.persistFn(r.ctx, state.threadID, state.state); err != nil {
-				r.sendMonitorEntry(monitorNonFatalError[T]("Persistence", state.threadID, fmt.Errorf("state persistence error: %w", err)))
+				r.reportNonFatal("Persistence", state.threadID, &g.PersistenceError{Node: "Persistence", ThreadID: state.threadID, Code: g.CodePersistenceFailed, Err: err})
+				r.deadLetter(state, err)
+			} else {
+				r.persisted(state)
 			}
+			r.settlePersist(state)
 		}
 	}
 }
@@ -493,26 +817,40 @@ func (r *runtimeImpl[T]) threadEvictor() {
 
 			// Process expired threads outside the lock
 			for _, threadID := range expiredThreads {
-				err := r.persistState(threadID)
-				if err != nil {
-					r.sendMonitorEntry(monitorNonFatalError[T]("ThreadEvictor", threadID, fmt.Errorf("state persistence error during eviction: %w", err)))
-				}
-
-				r.clearThread(threadID)
-
-				r.sendMonitorEntry(monitorNonFatalError[T]("ThreadEvictor", threadID, fmt.Errorf("evicted thread %s: %w", threadID, g.ErrEvictionByInactivity)))
+				r.evictThread(threadID)
 			}
 		}
 	}
 }
 
+// evictThread persists the state of the thread and forgets it.
+func (r *runtimeImpl[T]) evictThread(threadID string) {
+	err := r.persistState(threadID)
+	if err != nil {
+		r.reportNonFatal("ThreadEvictor", threadID, &g.PersistenceError{Node: "ThreadEvictor", ThreadID: threadID, Code: g.CodePersistenceFailed, Err: fmt.Errorf("eviction of thread %s: %w", threadID, err)})
+	}
+
+	r.clearThread(threadID)
+
+	r.logger.Info("thread evicted", logAttrThreadID, threadID)
+	r.sendMonitorEntry(monitorNonFatalError[T]("ThreadEvictor", threadID, fmt.Errorf("evicted thread %s: %w", threadID, g.ErrEvictionByInactivity)))
+}
+
 func (r *runtimeImpl[T]) flushPendingStates() {
 	for {
 		select {
 		case state := <-r.pendingPersist:
+			if r.fencedWrite(state) {
+				r.settlePersist(state)
+				continue
+			}
 			if err := r.persistFn(r.ctx, state.threadID, state.state); err != nil {
-				r.sendMonitorEntry(monitorNonFatalError[T]("Persistence", state.threadID, fmt.Errorf("state persistence error during flush: %w", err)))
+				r.reportNonFatal("Persistence", state.threadID, &g.PersistenceError{Node: "Persistence", ThreadID: state.threadID, Code: g.CodePersistenceFailed, Err: fmt.Errorf("flush on shutdown: %w", err)})
+				r.deadLetter(state, err)
+			} else {
+				r.persisted(state)
 			}
+			r.settlePersist(state)
 		default:
 			return
 		}
@@ -542,4 +880,13 @@ func (r *runtimeImpl[T]) clearThread(threadID string) {
 	r.state.Delete(threadID)
 	r.lastPersisted.Delete(threadID)
 	r.executing.Delete(threadID)
+	r.threadMeta.Delete(threadID)
+	r.streaming.Delete(threadID)
+	r.paused.Delete(threadID)
+	r.scratch.Delete(threadID)
+}
+
+// clone returns a snapshot of the state, safe to hand out of the runtime.
+func (r *runtimeImpl[T]) clone(state T) T {
+	return g.CloneState(state, r.cloneFn)
 }
@@ -3,7 +3,6 @@ package graph
 import (
 	"context"
 	"errors"
-	"fmt"
 	"sync"
 	"testing"
 	"time"
@@ -321,9 +320,11 @@ func TestRuntime_Validate_NoPathToEnd(t *testing.T) {
 		t.Fatal("Expected validation error when no path to end exists, got nil")
 	}
 
-	expectedMsg := "graph validation failed: no path from start edge to any end edge"
-	if err.Error() != expectedMsg {
-		t.Errorf("Expected error message '%s', got '%s'", expectedMsg, err.Error())
+	if !errors.Is(err, g.ErrNoPathToEnd) {
+		t.Errorf("Expected ErrNoPathToEnd, got '%s'", err.Error())
+	}
+	if !errors.Is(err, g.ErrDeadEndNode) {
+		t.Errorf("Expected ErrDeadEndNode to be reported as well, got '%s'", err.Error())
 	}
 }
 
@@ -845,9 +846,12 @@ func TestRuntime_NoOutboundEdges(t *testing.T) {
 		case entry := <-stateMonitorCh:
 			if entry.Error != nil {
 				foundError = true
-				expectedMsg := fmt.Sprintf("routing error for node %s: no outbound edges from node", node1.Name())
-				if entry.Error.Error() != expectedMsg {
-					t.Errorf("Expected error '%s', got '%s'", expectedMsg, entry.Error.Error())
+				var routingErr *g.RoutingError
+				if !errors.As(entry.Error, &routingErr) || routingErr.Node != node1.Name() || !errors.Is(routingErr, g.ErrNoOutboundEdges) {
+					t.Errorf("Expected the routing error of node %s, got '%v'", node1.Name(), entry.Error)
+				}
+				if entry.ErrorCode != g.CodeNoOutboundEdges {
+					t.Errorf("Expected code %s, got %s", g.CodeNoOutboundEdges, entry.ErrorCode)
 				}
 			}
 		case <-timeout:
@@ -891,9 +895,12 @@ func TestRuntime_NilRoutingPolicy(t *testing.T) {
 		case entry := <-stateMonitorCh:
 			if entry.Error != nil {
 				foundError = true
-				expectedMsg := fmt.Sprintf("routing error for node %s: no routing policy defined for node", node1.Name())
-				if entry.Error.Error() != expectedMsg {
-					t.Errorf("Expected error '%s', got '%s'", expectedMsg, entry.Error.Error())
+				var routingErr *g.RoutingError
+				if !errors.As(entry.Error, &routingErr) || routingErr.Node != node1.Name() || !errors.Is(routingErr, g.ErrNoRoutingPolicy) {
+					t.Errorf("Expected the routing error of node %s, got '%v'", node1.Name(), entry.Error)
+				}
+				if entry.ErrorCode != g.CodeNoRoutingPolicy {
+					t.Errorf("Expected code %s, got %s", g.CodeNoRoutingPolicy, entry.ErrorCode)
 				}
 			}
 		case <-timeout:
@@ -0,0 +1,143 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// sagaStep is a completed node of the invocation whose side effects can be compensated.
+type sagaStep[T g.SharedState] struct {
+	node         g.Node[T]
+	compensation g.CompensationFn[T]
+	userInput    T
+}
+
+// saga are the compensable steps completed by an invocation, in order.
+type saga[T g.SharedState] struct {
+	mu    sync.Mutex
+	steps []sagaStep[T]
+}
+
+func (s *saga[T]) add(step sagaStep[T]) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.steps = append(s.steps, step)
+}
+
+// take returns the completed steps and forgets them, so that they are compensated once.
+func (s *saga[T]) take() []sagaStep[T] {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	steps := s.steps
+	s.steps = nil
+	return steps
+}
+
+// compensableStep returns the saga step of the node, false if the node has no compensation.
+func compensableStep[T g.SharedState](node g.Node[T], userInput T) (sagaStep[T], bool) {
+	compensable, ok := node.(g.Compensable[T])
+	if !ok || compensable.Compensation() == nil {
+		return sagaStep[T]{}, false
+	}
+	return sagaStep[T]{node: node, compensation: compensable.Compensation(), userInput: userInput}, true
+}
+
+// sagaOf returns the saga of the invocation owning the configuration, nil if it is terminated.
+func (r *runtimeImpl[T]) sagaOf(config g.InvokeConfig) *saga[T] {
+	inv, ok := r.invocations.Load(config.ThreadID)
+	if !ok || inv.(*invocation).ctx != config.Context {
+		return nil
+	}
+	useInvocation := inv.(*invocation)
+	useInvocation.sagaMu.Lock()
+	defer useInvocation.sagaMu.Unlock()
+	if useInvocation.saga == nil {
+		useInvocation.saga = &saga[T]{}
+	}
+	return useInvocation.saga.(*saga[T])
+}
+
+// recordCompletion adds the completed node to the saga of its invocation, if the node has a compensation.
+func (r *runtimeImpl[T]) recordCompletion(result nodeFnReturnStruct[T]) {
+	step, ok := compensableStep(result.node, result.userInput)
+	if !ok {
+		return
+	}
+	if useSaga := r.sagaOf(result.config); useSaga != nil {
+		useSaga.add(step)
+	}
+}
+
+// compensate runs in background the compensations of the failed invocation before failing it, it returns
+// false if there is nothing to compensate.
+func (r *runtimeImpl[T]) compensate(node string, config g.InvokeConfig, err error) bool {
+	if errors.Is(err, g.ErrLeaseLost) {
+		return false
+	}
+	useSaga := r.sagaOf(config)
+	if useSaga == nil {
+		return false
+	}
+	steps := useSaga.take()
+	if len(steps) == 0 {
+		return false
+	}
+
+	go func() {
+		r.runCompensations(steps, config)
+		if !r.ownsInvocation(config) {
+			// The invocation was released meanwhile, e.g. cancelled: it is already terminated.
+			return
+		}
+		// The compensated steps must not be resumed.
+		r.clearJournal(config.ThreadID)
+		if err := r.persistState(config.ThreadID); err != nil {
+			r.reportNonFatal(node, config.ThreadID, &g.PersistenceError{Node: node, ThreadID: config.ThreadID, Code: g.CodePersistenceFailed, Err: err})
+		}
+		r.terminateFailed(node, config, err)
+	}()
+	return true
+}
+
+// runCompensations walks back through the completed steps, notifying the outcome of each compensation.
+func (r *runtimeImpl[T]) runCompensations(steps []sagaStep[T], config g.InvokeConfig) {
+	// The compensations must run even if the failure is the cancellation of the invocation.
+	ctx := context.WithoutCancel(config.Context)
+	for i := len(steps) - 1; i >= 0; i-- {
+		step := steps[i]
+		name := step.node.Name()
+		newState, err := r.runCompensation(ctx, step, config.ThreadID)
+		if err != nil {
+			r.logger.Warn("compensation failed", logAttrThreadID, config.ThreadID, logAttrNode, name, "error", err)
+			entry := monitorNonFatalError[T](name, config.ThreadID, &g.NodeError{Node: name, ThreadID: config.ThreadID, Code: g.CodeNodeFailed, Err: fmt.Errorf("%w: %w", g.ErrCompensationFailed, err)})
+			entry.Compensation = true
+			r.sendMonitorEntry(entry)
+			continue
+		}
+		r.state.Store(config.ThreadID, r.clone(newState))
+		r.bumpStateVersion(config.ThreadID)
+		r.logger.Info("node compensated", logAttrThreadID, config.ThreadID, logAttrNode, name)
+		entry := monitorRunning(name, config.ThreadID, newState)
+		entry.Compensation = true
+		r.sendMonitorEntry(entry)
+	}
+}
+
+func (r *runtimeImpl[T]) runCompensation(ctx context.Context, step sagaStep[T], threadID string) (newState T, err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("compensation of node %s panicked: %v", step.node.Name(), rec)
+		}
+	}()
+	return step.compensation(ctx, r.clone(step.userInput), r.committedState(threadID))
+}
+
+// ownsInvocation reports whether the configuration belongs to the current invocation of its thread.
+func (r *runtimeImpl[T]) ownsInvocation(config g.InvokeConfig) bool {
+	inv, ok := r.invocations.Load(config.ThreadID)
+	return ok && inv.(*invocation).ctx == config.Context
+}
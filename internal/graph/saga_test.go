@@ -0,0 +1,130 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// compensableNode creates a test node appending value to the state, compensated by compensation.
+func compensableNode(name, value string, compensation g.CompensationFn[RuntimeTestState]) g.Node[RuntimeTestState] {
+	opts := testNodeOptions()
+	opts.Compensation = compensation
+	node, _ := ContextNodeImplFactory(g.IntermediateNode, name, func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		currentState.Value += value
+		return currentState, nil
+	}, opts)
+	return node
+}
+
+// TestRuntime_Compensation tests that the completed nodes are compensated in reverse order before the failure is notified
+func TestRuntime_Compensation(t *testing.T) {
+	ship := func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		return currentState, errors.New("carrier down")
+	}
+	reserveComp := func(ctx context.Context, userInput, currentState RuntimeTestState) (RuntimeTestState, error) {
+		if ctx.Err() != nil {
+			t.Errorf("Expected the compensation context to be alive, got %v", ctx.Err())
+		}
+		currentState.Value += "-r"
+		return currentState, nil
+	}
+	chargeComp := func(ctx context.Context, userInput, currentState RuntimeTestState) (RuntimeTestState, error) {
+		currentState.Value += "-c"
+		return currentState, nil
+	}
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 20)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, compensableNode("Reserve", "R", reserveComp), compensableNode("Charge", "C", chargeComp), testNode("Ship", ship))
+
+	runtime.Invoke(RuntimeTestState{}, g.InvokeConfig{ThreadID: "saga"})
+
+	var compensated []string
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case entry := <-stateMonitorCh:
+			if entry.Compensation {
+				if entry.Error != nil {
+					t.Fatalf("Expected the compensation of %s to succeed, got %v", entry.Node, entry.Error)
+				}
+				compensated = append(compensated, entry.Node)
+				continue
+			}
+			if entry.Running {
+				continue
+			}
+			if entry.Error == nil || entry.Node != "Ship" {
+				t.Fatalf("Expected the failure of Ship, got %+v", entry)
+			}
+			if len(compensated) != 2 || compensated[0] != "Charge" || compensated[1] != "Reserve" {
+				t.Fatalf("Expected Charge then Reserve to be compensated before the failure, got %v", compensated)
+			}
+			return
+		case <-timeout:
+			t.Fatal("Timeout waiting for the failure")
+		}
+	}
+}
+
+// TestRuntime_CompensationFailure tests that a failed compensation is notified and the walk goes on
+func TestRuntime_CompensationFailure(t *testing.T) {
+	ship := func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		return currentState, errors.New("carrier down")
+	}
+	reserved := make(chan string, 1)
+	reserveComp := func(ctx context.Context, userInput, currentState RuntimeTestState) (RuntimeTestState, error) {
+		reserved <- currentState.Value
+		return currentState, nil
+	}
+	chargeComp := func(ctx context.Context, userInput, currentState RuntimeTestState) (RuntimeTestState, error) {
+		panic("refund unavailable")
+	}
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 20)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, compensableNode("Reserve", "R", reserveComp), compensableNode("Charge", "C", chargeComp), testNode("Ship", ship))
+
+	runtime.Invoke(RuntimeTestState{}, g.InvokeConfig{ThreadID: "saga"})
+
+	var failed g.StateMonitorEntry[RuntimeTestState]
+	timeout := time.After(2 * time.Second)
+	for failed.Node == "" {
+		select {
+		case entry := <-stateMonitorCh:
+			if entry.Compensation && entry.Error != nil {
+				failed = entry
+			}
+		case <-timeout:
+			t.Fatal("Timeout waiting for the failed compensation")
+		}
+	}
+	if failed.Node != "Charge" || !errors.Is(failed.Error, g.ErrCompensationFailed) || !failed.Running {
+		t.Errorf("Expected a non-fatal compensation failure of Charge, got %+v", failed)
+	}
+	if value := <-reserved; value != "RC" {
+		t.Errorf("Expected Reserve to be compensated with the committed state, got %q", value)
+	}
+	if entry := waitTerminalEntry(t, stateMonitorCh); entry.Error == nil || entry.Compensation {
+		t.Errorf("Expected the terminal failure after the compensations, got %+v", entry)
+	}
+}
+
+// TestRuntime_NoCompensation tests that a failure without completed compensable nodes is notified at once
+func TestRuntime_NoCompensation(t *testing.T) {
+	ship := func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		return currentState, nil
+	}
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 20)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, compensableNode("Reserve", "R", nil), compensableNode("Charge", "C", nil), testNode("Ship", ship))
+
+	runtime.Invoke(RuntimeTestState{}, g.InvokeConfig{ThreadID: "saga"})
+
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if entry.Error != nil || entry.NewState.Value != "RC" {
+		t.Errorf("Expected the invocation to complete without compensations, got %+v", entry)
+	}
+	if useSaga := runtime.sagaOf(g.InvokeConfig{ThreadID: "saga"}); useSaga != nil {
+		t.Errorf("Expected no saga once the invocation is terminated, got %+v", useSaga)
+	}
+}
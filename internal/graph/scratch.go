@@ -0,0 +1,197 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// MemScratchStoreFactory creates an in-process ScratchStore.
+func MemScratchStoreFactory() g.ScratchStore {
+	return &memScratchStore{entries: make(map[string]map[string][]byte)}
+}
+
+// ------------------------------------------------------------------------------
+// In-Memory ScratchStore Implementation
+// ------------------------------------------------------------------------------
+
+var _ g.ScratchStore = (*memScratchStore)(nil)
+
+type memScratchStore struct {
+	mu      sync.Mutex
+	entries map[string]map[string][]byte
+}
+
+func (s *memScratchStore) SaveScratch(_ context.Context, threadID, key string, value []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries[threadID] == nil {
+		s.entries[threadID] = make(map[string][]byte)
+	}
+	s.entries[threadID][key] = slices.Clone(value)
+	return nil
+}
+
+func (s *memScratchStore) LoadScratch(_ context.Context, threadID string) (map[string][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.entries[threadID]), nil
+}
+
+func (s *memScratchStore) DeleteScratch(_ context.Context, threadID, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.entries[threadID], key)
+	return nil
+}
+
+// ------------------------------------------------------------------------------
+// Runtime Scratchpads
+// ------------------------------------------------------------------------------
+
+var _ g.Scratchpad = (*scratchpad)(nil)
+
+// scratchpad is the scratchpad of a thread, writing its persisted entries through the store, if any.
+type scratchpad struct {
+	threadID string
+	store    g.ScratchStore
+	ctx      context.Context
+	timeout  time.Duration
+
+	mu     sync.RWMutex
+	values map[string]any
+	// transient are the keys of the entries set with SetTransient, never persisted nor snapshotted.
+	transient map[string]bool
+}
+
+func (p *scratchpad) Get(key string) (any, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	value, ok := p.values[key]
+	return value, ok
+}
+
+func (p *scratchpad) Set(key string, value any) error {
+	if key == "" {
+		return g.ErrScratchKeyEmpty
+	}
+	if p.store != nil {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("cannot encode scratchpad entry %s of thread %s: %w", key, p.threadID, err)
+		}
+		ctx, cancel := context.WithTimeout(p.ctx, p.timeout)
+		defer cancel()
+		if err := p.store.SaveScratch(ctx, p.threadID, key, encoded); err != nil {
+			return fmt.Errorf("cannot persist scratchpad entry %s of thread %s: %w", key, p.threadID, err)
+		}
+	}
+	p.set(key, value, false)
+	return nil
+}
+
+func (p *scratchpad) SetTransient(key string, value any) error {
+	if key == "" {
+		return g.ErrScratchKeyEmpty
+	}
+	p.set(key, value, true)
+	return nil
+}
+
+func (p *scratchpad) set(key string, value any, transient bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.values[key] = value
+	if transient {
+		p.transient[key] = true
+	} else {
+		delete(p.transient, key)
+	}
+}
+
+func (p *scratchpad) Delete(key string) error {
+	p.mu.Lock()
+	delete(p.values, key)
+	delete(p.transient, key)
+	p.mu.Unlock()
+
+	if p.store == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(p.ctx, p.timeout)
+	defer cancel()
+	if err := p.store.DeleteScratch(ctx, p.threadID, key); err != nil {
+		return fmt.Errorf("cannot delete scratchpad entry %s of thread %s: %w", key, p.threadID, err)
+	}
+	return nil
+}
+
+func (p *scratchpad) Keys() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return slices.Sorted(maps.Keys(p.values))
+}
+
+// encoded returns the JSON encoded entries of the scratchpad, the transient ones excluded.
+func (p *scratchpad) encoded() (map[string]json.RawMessage, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	rv := make(map[string]json.RawMessage, len(p.values))
+	for key, value := range p.values {
+		if p.transient[key] {
+			continue
+		}
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("cannot encode scratchpad entry %s of thread %s: %w", key, p.threadID, err)
+		}
+		rv[key] = encoded
+	}
+	return rv, nil
+}
+
+// scratchStoreOf returns the memory when it persists the scratchpads, nil otherwise.
+func scratchStoreOf[T g.SharedState](memory g.Memory[T]) g.ScratchStore {
+	if store, ok := memory.(g.ScratchStore); ok {
+		return store
+	}
+	return nil
+}
+
+func (r *runtimeImpl[T]) Scratch(threadID string) g.Scratchpad {
+	if pad, ok := r.scratch.Load(threadID); ok {
+		return pad.(*scratchpad)
+	}
+
+	pad := r.newScratchpad(threadID)
+	if r.scratchStore != nil {
+		ctx, cancel := context.WithTimeout(r.ctx, r.settings.PersistenceJobTimeout)
+		defer cancel()
+		restored, err := r.scratchStore.LoadScratch(ctx, threadID)
+		if err != nil {
+			r.reportNonFatal("Scratchpad", threadID, &g.PersistenceError{Node: "Scratchpad", ThreadID: threadID, Code: g.CodeRestoreFailed, Err: err})
+		}
+		for key, value := range restored {
+			pad.values[key] = json.RawMessage(value)
+		}
+	}
+	actual, _ := r.scratch.LoadOrStore(threadID, pad)
+	return actual.(*scratchpad)
+}
+
+func (r *runtimeImpl[T]) newScratchpad(threadID string) *scratchpad {
+	return &scratchpad{
+		threadID:  threadID,
+		store:     r.scratchStore,
+		ctx:       r.ctx,
+		timeout:   r.settings.PersistenceJobTimeout,
+		values:    make(map[string]any),
+		transient: make(map[string]bool),
+	}
+}
@@ -0,0 +1,94 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// fetchDocs is a node function counting the documents it finds in the scratchpad, then adding the user input.
+func fetchDocs(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+	pad, err := g.ScratchFrom(ctx)
+	if err != nil {
+		return currentState, err
+	}
+	docs, _, err := g.ScratchValue[[]string](pad, "docs")
+	if err != nil {
+		return currentState, err
+	}
+	docs = append(docs, userInput.Value)
+	currentState.Counter = len(docs)
+	if err := pad.SetTransient("client", func() {}); err != nil {
+		return currentState, err
+	}
+	return currentState, pad.Set("docs", docs)
+}
+
+// TestRuntime_Scratch tests that the scratchpad is shared by the invocations of the thread, apart from the state
+func TestRuntime_Scratch(t *testing.T) {
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, testNode("Fetch", fetchDocs))
+
+	threadID := runtime.Invoke(RuntimeTestState{Value: "a"})
+	if entry := waitTerminalEntry(t, stateMonitorCh); entry.Error != nil || entry.NewState.Counter != 1 {
+		t.Fatalf("Expected one document, got %+v", entry)
+	}
+	runtime.Invoke(RuntimeTestState{Value: "b"}, g.InvokeConfigThreadID(threadID))
+	if entry := waitTerminalEntry(t, stateMonitorCh); entry.Error != nil || entry.NewState.Counter != 2 {
+		t.Fatalf("Expected the documents of the previous invocation, got %+v", entry)
+	}
+
+	pad := runtime.Scratch(threadID)
+	if keys := pad.Keys(); !slices.Equal(keys, []string{"client", "docs"}) {
+		t.Errorf("Expected the client and the docs entries, got %v", keys)
+	}
+	if docs, ok, err := g.ScratchValue[[]string](pad, "docs"); err != nil || !ok || !slices.Equal(docs, []string{"a", "b"}) {
+		t.Errorf("Expected the docs of both invocations, got %v, %v, %v", docs, ok, err)
+	}
+	if _, _, err := g.ScratchValue[int](pad, "docs"); err == nil {
+		t.Error("Expected an error reading the docs as an int")
+	}
+	if _, ok := runtime.Scratch("other").Get("docs"); ok {
+		t.Error("Expected the scratchpads to be per thread")
+	}
+	if err := pad.Set("", 1); !errors.Is(err, g.ErrScratchKeyEmpty) {
+		t.Errorf("Expected ErrScratchKeyEmpty, got %v", err)
+	}
+	if _, err := g.ScratchFrom(context.Background()); !errors.Is(err, g.ErrScratchpadNotSet) {
+		t.Errorf("Expected ErrScratchpadNotSet, got %v", err)
+	}
+}
+
+// TestRuntime_ScratchStore tests that only the entries set with Set are restored from the memory
+func TestRuntime_ScratchStore(t *testing.T) {
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{Memory: MemMemoryFactory[RuntimeTestState](nil)}, testNode("Fetch", fetchDocs))
+
+	threadID := runtime.Invoke(RuntimeTestState{Value: "a"})
+	waitTerminalEntry(t, stateMonitorCh)
+	runtime.Invoke(RuntimeTestState{Value: "b"}, g.InvokeConfigThreadID(threadID))
+	if entry := waitTerminalEntry(t, stateMonitorCh); entry.Error != nil || entry.NewState.Counter != 2 {
+		t.Fatalf("Expected the documents to be restored, got %+v", entry)
+	}
+
+	runtime.clearThread(threadID)
+	pad := runtime.Scratch(threadID)
+	if keys := pad.Keys(); !slices.Equal(keys, []string{"docs"}) {
+		t.Errorf("Expected only the persisted entry to be restored, got %v", keys)
+	}
+	if raw, _ := pad.Get("docs"); string(raw.(json.RawMessage)) != `["a","b"]` {
+		t.Errorf("Expected the encoded docs, got %v", raw)
+	}
+
+	if err := pad.Delete("docs"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	runtime.clearThread(threadID)
+	if keys := runtime.Scratch(threadID).Keys(); len(keys) != 0 {
+		t.Errorf("Expected the deleted entry not to be restored, got %v", keys)
+	}
+}
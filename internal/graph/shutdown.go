@@ -0,0 +1,89 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func (r *runtimeImpl[T]) Stop(ctx context.Context, mode g.ShutdownMode) g.ShutdownReport {
+	startedAt := time.Now()
+	r.stopping.Store(true)
+	r.logger.Info("runtime stopping", "mode", mode.String())
+
+	report := g.ShutdownReport{Mode: mode}
+	if mode == g.ShutdownDrain {
+		report.Drained = r.drain(ctx)
+	}
+	for threadID, inv := range r.inFlight() {
+		report.Interrupted = append(report.Interrupted, r.interrupt(threadID, inv))
+	}
+	slices.SortFunc(report.Interrupted, func(a, b g.InterruptedInvocation) int {
+		return strings.Compare(a.ThreadID, b.ThreadID)
+	})
+
+	r.Shutdown()
+
+	report.Rejected = int(r.stopRejected.Load())
+	report.DiscardedOutcomes = r.outcomes.stats().Queued
+	report.Duration = time.Since(startedAt)
+	r.logger.Info("runtime stopped", "mode", mode.String(), "drained", len(report.Drained), "interrupted", len(report.Interrupted), "duration", report.Duration)
+	return report
+}
+
+// inFlight returns the invocations not yet terminated, by thread.
+func (r *runtimeImpl[T]) inFlight() map[string]*invocation {
+	invocations := make(map[string]*invocation)
+	r.invocations.Range(func(threadID, inv any) bool {
+		invocations[threadID.(string)] = inv.(*invocation)
+		return true
+	})
+	return invocations
+}
+
+// drain waits for the in-flight invocations to terminate until the context is done, it returns their threads.
+func (r *runtimeImpl[T]) drain(ctx context.Context) []string {
+	var drained []string
+	for {
+		invocations := r.inFlight()
+		if len(invocations) == 0 {
+			break
+		}
+		for threadID, inv := range invocations {
+			select {
+			case <-inv.done:
+				drained = append(drained, threadID)
+			case <-ctx.Done():
+				slices.Sort(drained)
+				return drained
+			}
+		}
+	}
+	slices.Sort(drained)
+	return drained
+}
+
+// interrupt terminates the invocation with ErrRuntimeShuttingDown without waiting for its current node.
+func (r *runtimeImpl[T]) interrupt(threadID string, useInvocation *invocation) g.InterruptedInvocation {
+	interrupted := g.InterruptedInvocation{
+		ThreadID: threadID,
+		Nodes:    r.timeline.runningNodes(threadID),
+		Running:  time.Since(useInvocation.startedAt),
+	}
+	cause := g.ErrRuntimeShuttingDown
+	useInvocation.cancel(cause)
+
+	switch {
+	case r.abortPaused(threadID, cause):
+		interrupted.Paused = true
+	case r.withdrawQueued(threadID, useInvocation, cause):
+		interrupted.Queued = true
+	default:
+		r.releaseInvocation(threadID, useInvocation, fmt.Errorf("thread %s interrupted: %w", threadID, cause))
+	}
+	return interrupted
+}
@@ -0,0 +1,88 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// TestRuntime_Stop tests that the in-flight invocations are drained or interrupted on shutdown
+func TestRuntime_Stop(t *testing.T) {
+	t.Run("drain", func(t *testing.T) {
+		runtime, stateMonitorCh, started, release := newBlockingTestRuntime(t, &g.RuntimeOptions[RuntimeTestState]{})
+
+		threadID := runtime.Invoke(RuntimeTestState{})
+		<-started
+
+		reportCh := make(chan g.ShutdownReport, 1)
+		go func() {
+			reportCh <- runtime.Stop(context.Background(), g.ShutdownDrain)
+		}()
+		for !runtime.stopping.Load() {
+			time.Sleep(time.Millisecond)
+		}
+
+		rejected := runtime.Invoke(RuntimeTestState{})
+		entry := waitTerminalEntry(t, stateMonitorCh)
+		if entry.ThreadID != rejected || !errors.Is(entry.Error, g.ErrRuntimeShuttingDown) {
+			t.Errorf("Expected the new invocation to be rejected, got %s: %v", entry.ThreadID, entry.Error)
+		}
+
+		close(release)
+		if entry := waitTerminalEntry(t, stateMonitorCh); entry.Error != nil || entry.NewState.Value != "done" {
+			t.Errorf("Expected the running invocation to complete, got %+v", entry)
+		}
+
+		report := <-reportCh
+		if report.Mode != g.ShutdownDrain || !slices.Equal(report.Drained, []string{threadID}) || len(report.Interrupted) != 0 || report.Rejected != 1 {
+			t.Errorf("Unexpected report %+v", report)
+		}
+	})
+
+	t.Run("drain timeout", func(t *testing.T) {
+		runtime, stateMonitorCh, started, release := newBlockingTestRuntime(t, &g.RuntimeOptions[RuntimeTestState]{})
+		defer close(release)
+
+		threadID := runtime.Invoke(RuntimeTestState{})
+		<-started
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		report := runtime.Stop(ctx, g.ShutdownDrain)
+		if len(report.Drained) != 0 || len(report.Interrupted) != 1 || report.Interrupted[0].ThreadID != threadID {
+			t.Fatalf("Expected the invocation to be interrupted, got %+v", report)
+		}
+		if entry := waitTerminalEntry(t, stateMonitorCh); !errors.Is(entry.Error, g.ErrRuntimeShuttingDown) {
+			t.Errorf("Expected ErrRuntimeShuttingDown, got %v", entry.Error)
+		}
+	})
+
+	t.Run("abort", func(t *testing.T) {
+		runtime, stateMonitorCh, started, release := newBlockingTestRuntime(t, &g.RuntimeOptions[RuntimeTestState]{})
+		defer close(release)
+
+		threadID := runtime.Invoke(RuntimeTestState{})
+		<-started
+
+		report := runtime.Stop(context.Background(), g.ShutdownAbort)
+		if report.Mode != g.ShutdownAbort || len(report.Interrupted) != 1 {
+			t.Fatalf("Expected the invocation to be interrupted, got %+v", report)
+		}
+		interrupted := report.Interrupted[0]
+		if interrupted.ThreadID != threadID || !slices.Equal(interrupted.Nodes, []string{"Node1"}) || interrupted.Queued || interrupted.Paused {
+			t.Errorf("Unexpected interrupted invocation %+v", interrupted)
+		}
+
+		entry := waitTerminalEntry(t, stateMonitorCh)
+		if entry.ThreadID != threadID || !errors.Is(entry.Error, g.ErrRuntimeShuttingDown) {
+			t.Errorf("Expected the thread to be interrupted, got %s: %v", entry.ThreadID, entry.Error)
+		}
+		if err := runtime.Cancel(threadID); !errors.Is(err, g.ErrThreadNotExecuting) {
+			t.Errorf("Expected the thread to be released, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,143 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// SignalNodeImplFactory creates a node parking the thread until the signal arrives, its payload being the update of the node.
+func SignalNodeImplFactory[T g.SharedState](name, signal string, timeout time.Duration, opt *g.NodeOptions[T]) (g.Node[T], error) {
+	if signal == "" {
+		return nil, fmt.Errorf("signal node creation error for name %s: %w", name, g.ErrSignalNameEmpty)
+	}
+	node, err := ContextNodeImplFactory(g.IntermediateNode, name, nil, opt)
+	if err != nil {
+		return nil, err
+	}
+	node.(*nodeImpl[T]).signal = signal
+	node.(*nodeImpl[T]).signalTimeout = timeout
+	return node, nil
+}
+
+func (n *nodeImpl[T]) AwaitedSignal() (string, time.Duration) {
+	return n.signal, n.signalTimeout
+}
+
+// reducing is implemented by the nodes exposing their reducer, to reduce the updates not computed by their function.
+type reducing[T g.SharedState] interface {
+	reducerFn() g.ReducerFn[T]
+}
+
+func (n *nodeImpl[T]) reducerFn() g.ReducerFn[T] {
+	return n.reducer
+}
+
+// parkedNode is a signal node waiting for its signal.
+type parkedNode[T g.SharedState] struct {
+	node      g.Node[T]
+	signal    string
+	userInput T
+	config    g.InvokeConfig
+
+	mu    sync.Mutex
+	timer *time.Timer
+}
+
+func (p *parkedNode[T]) stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+}
+
+// awaitSignal parks the thread if the node waits for a signal, it returns false otherwise.
+func (r *runtimeImpl[T]) awaitSignal(node g.Node[T], userInput T, config g.InvokeConfig) bool {
+	awaiter, ok := node.(g.SignalAwaiter[T])
+	if !ok {
+		return false
+	}
+	signal, timeout := awaiter.AwaitedSignal()
+	if signal == "" {
+		return false
+	}
+
+	// The parked thread waits without its slots, the other invocations run meanwhile.
+	r.suspendInvocation(config)
+	parked := &parkedNode[T]{node: node, signal: signal, userInput: userInput, config: config}
+	r.parked.Store(config.ThreadID, parked)
+	r.logger.Info("invocation waiting for signal", logAttrThreadID, config.ThreadID, logAttrNode, node.Name(), "signal", signal)
+
+	entry := monitorRunning(node.Name(), config.ThreadID, r.committedState(config.ThreadID))
+	entry.Signal = signal
+	r.sendMonitorEntry(entry)
+
+	if timeout > 0 {
+		parked.mu.Lock()
+		defer parked.mu.Unlock()
+		parked.timer = time.AfterFunc(timeout, func() {
+			if !r.parked.CompareAndDelete(config.ThreadID, parked) {
+				return
+			}
+			err := &g.NodeError{Node: node.Name(), ThreadID: config.ThreadID, Code: g.CodeNodeTimeout, Err: fmt.Errorf("%w: %s not received within %s", g.ErrSignalTimeout, signal, timeout)}
+			r.resumeInvocation(config, func() {
+				r.NotifyStateChange(node, config, userInput, r.committedState(config.ThreadID), nil, err, false)
+			})
+		})
+	}
+	return true
+}
+
+func (r *runtimeImpl[T]) Signal(threadID, signal string, payload T) error {
+	value, ok := r.parked.Load(threadID)
+	if !ok {
+		return fmt.Errorf("cannot signal %s to thread %s: %w", signal, threadID, g.ErrThreadNotWaiting)
+	}
+	parked := value.(*parkedNode[T])
+	if parked.signal != signal {
+		return fmt.Errorf("cannot signal %s to thread %s waiting for %s: %w", signal, threadID, parked.signal, g.ErrUnexpectedSignal)
+	}
+	if !r.parked.CompareAndDelete(threadID, parked) {
+		return fmt.Errorf("cannot signal %s to thread %s: %w", signal, threadID, g.ErrThreadNotWaiting)
+	}
+	parked.stop()
+
+	config := parked.config
+	if err := context.Cause(config.Context); err != nil {
+		r.failInvocation(parked.node.Name(), config, fmt.Errorf("invocation context done: %w", limitError(parked.node.Name(), threadID, err)))
+		return nil
+	}
+	r.logger.Info("signal received", logAttrThreadID, threadID, logAttrNode, parked.node.Name(), "signal", signal)
+	var reducer g.ReducerFn[T]
+	if node, ok := parked.node.(reducing[T]); ok {
+		reducer = node.reducerFn()
+	}
+	r.resumeInvocation(config, func() {
+		r.NotifyStateChange(parked.node, config, parked.userInput, payload, reducer, nil, false)
+	})
+	return nil
+}
+
+func (r *runtimeImpl[T]) AwaitedSignal(threadID string) (string, bool) {
+	parked, ok := r.parked.Load(threadID)
+	if !ok {
+		return "", false
+	}
+	return parked.(*parkedNode[T]).signal, true
+}
+
+// abortParked terminates the invocation of the thread if it waits for a signal, it returns false otherwise.
+func (r *runtimeImpl[T]) abortParked(threadID string, cause error) bool {
+	parked, ok := r.parked.LoadAndDelete(threadID)
+	if !ok {
+		return false
+	}
+	useNode := parked.(*parkedNode[T])
+	useNode.stop()
+	r.failInvocation(useNode.node.Name(), useNode.config, fmt.Errorf("invocation of thread %s waiting for signal %s aborted: %w", threadID, useNode.signal, cause))
+	return true
+}
@@ -0,0 +1,136 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// TestRuntime_Signal tests that the payload of the signal is reduced into the state before the invocation goes on
+func TestRuntime_Signal(t *testing.T) {
+	orderNode := testNode("Order", func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		currentState.Value = "ordered"
+		return currentState, nil
+	})
+	awaitOpts := testNodeOptions()
+	awaitOpts.Reducer = func(currentState, payload RuntimeTestState) RuntimeTestState {
+		currentState.Counter += payload.Counter
+		return currentState
+	}
+	awaitNode, err := SignalNodeImplFactory("AwaitPayment", "payment", 0, awaitOpts)
+	if err != nil {
+		t.Fatalf("SignalNodeImplFactory failed: %v", err)
+	}
+	shipNode := testNode("Ship", func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		currentState.Value += " and shipped"
+		return currentState, nil
+	})
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, orderNode, awaitNode, shipNode)
+
+	if err := runtime.Signal("unknown", "payment", RuntimeTestState{}); !errors.Is(err, g.ErrThreadNotWaiting) {
+		t.Errorf("Expected ErrThreadNotWaiting, got %v", err)
+	}
+
+	threadID := runtime.Invoke(RuntimeTestState{})
+	entry := waitParkedEntry(t, stateMonitorCh)
+	if entry.Node != "AwaitPayment" || entry.Signal != "payment" || entry.NewState.Value != "ordered" {
+		t.Errorf("Expected AwaitPayment to wait for the payment, got %+v", entry)
+	}
+	if signal, ok := runtime.AwaitedSignal(threadID); !ok || signal != "payment" {
+		t.Errorf("Expected the thread to wait for the payment, got %q, %v", signal, ok)
+	}
+
+	if err := runtime.Signal(threadID, "refund", RuntimeTestState{}); !errors.Is(err, g.ErrUnexpectedSignal) {
+		t.Errorf("Expected ErrUnexpectedSignal, got %v", err)
+	}
+	if err := runtime.Signal(threadID, "payment", RuntimeTestState{Counter: 42}); err != nil {
+		t.Fatalf("Signal failed: %v", err)
+	}
+	entry = waitTerminalEntry(t, stateMonitorCh)
+	if entry.Error != nil || entry.NewState.Value != "ordered and shipped" || entry.NewState.Counter != 42 {
+		t.Errorf("Expected the payload to be merged into the state, got %+v", entry)
+	}
+	if _, ok := runtime.AwaitedSignal(threadID); ok {
+		t.Error("Expected the thread not to wait anymore")
+	}
+	if err := runtime.Signal(threadID, "payment", RuntimeTestState{}); !errors.Is(err, g.ErrThreadNotWaiting) {
+		t.Errorf("Expected the signal to be delivered once, got %v", err)
+	}
+}
+
+// TestRuntime_SignalTimeoutAndCancel tests the parked threads whose signal does not arrive
+func TestRuntime_SignalTimeoutAndCancel(t *testing.T) {
+	awaitNode, _ := SignalNodeImplFactory("AwaitPayment", "payment", 50*time.Millisecond, testNodeOptions())
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, awaitNode)
+	runtime.Invoke(RuntimeTestState{})
+	waitParkedEntry(t, stateMonitorCh)
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if !errors.Is(entry.Error, g.ErrSignalTimeout) || entry.ErrorCode != g.CodeNodeTimeout || entry.Node != "AwaitPayment" {
+		t.Errorf("Expected the signal to time out, got %+v", entry)
+	}
+
+	awaitNode, _ = SignalNodeImplFactory("AwaitPayment", "payment", 0, testNodeOptions())
+	stateMonitorCh = make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime = newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, awaitNode)
+	threadID := runtime.Invoke(RuntimeTestState{})
+	waitParkedEntry(t, stateMonitorCh)
+	if err := runtime.Cancel(threadID); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if entry := waitTerminalEntry(t, stateMonitorCh); !errors.Is(entry.Error, g.ErrThreadCancelled) {
+		t.Errorf("Expected the parked invocation to be cancelled, got %+v", entry)
+	}
+	if err := runtime.Signal(threadID, "payment", RuntimeTestState{}); !errors.Is(err, g.ErrThreadNotWaiting) {
+		t.Errorf("Expected ErrThreadNotWaiting, got %v", err)
+	}
+
+	if _, err := SignalNodeImplFactory[RuntimeTestState]("Await", "", 0, &g.NodeOptions[RuntimeTestState]{}); !errors.Is(err, g.ErrSignalNameEmpty) {
+		t.Errorf("Expected ErrSignalNameEmpty, got %v", err)
+	}
+}
+
+// TestRuntime_SignalReleasesSlots tests that a parked thread releases its admission and tenant slots to the other
+// invocations, taking them back once signalled
+func TestRuntime_SignalReleasesSlots(t *testing.T) {
+	awaitNode, err := SignalNodeImplFactory("AwaitPayment", "payment", 24*time.Hour, testNodeOptions())
+	if err != nil {
+		t.Fatalf("SignalNodeImplFactory failed: %v", err)
+	}
+	runtimeOpts := &g.RuntimeOptions[RuntimeTestState]{Settings: g.RuntimeSettings{MaxConcurrentInvocations: 1}}
+	_ = g.WithTenantQuotas[RuntimeTestState](g.TenantQuota{MaxConcurrentInvocations: 1}, nil).Apply(runtimeOpts)
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime := newTestRuntime(t, stateMonitorCh, runtimeOpts, awaitNode)
+
+	first := runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTenant("acme"))
+	waitParkedEntry(t, stateMonitorCh)
+	second := runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTenant("acme"))
+	if entry := waitParkedEntry(t, stateMonitorCh); entry.ThreadID != second {
+		t.Fatalf("Expected the second thread to run while the first is parked, got %+v", entry)
+	}
+	if stats := runtime.AdmissionStats(); stats.Running != 0 || stats.Queued != 0 {
+		t.Errorf("Expected the parked threads not to hold their admission slots, got %+v", stats)
+	}
+	if stats := runtime.TenantStats("acme"); stats.RunningInvocations != 0 || stats.Rejected != 0 {
+		t.Errorf("Expected the parked threads not to hold their tenant slots, got %+v", stats)
+	}
+
+	for _, threadID := range []string{first, second} {
+		if err := runtime.Signal(threadID, "payment", RuntimeTestState{Value: "paid"}); err != nil {
+			t.Fatalf("Signal failed: %v", err)
+		}
+		if final := waitTerminalEntry(t, stateMonitorCh); final.Error != nil || final.ThreadID != threadID {
+			t.Errorf("Expected the signalled thread %s to complete, got %+v", threadID, final)
+		}
+	}
+	waitFor(t, "the slots to be released", func() bool {
+		return runtime.AdmissionStats().Running == 0 && runtime.TenantStats("acme").RunningInvocations == 0
+	})
+	if stats := runtime.AdmissionStats(); stats.Admitted != 2 {
+		t.Errorf("Expected the resumed invocations not to be admitted again, got %+v", stats)
+	}
+}
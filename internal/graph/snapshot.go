@@ -0,0 +1,120 @@
+package graph
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// RuntimeFactoryFromSnapshot creates a new instance of Runtime, warm restarted with the threads of the snapshot.
+func RuntimeFactoryFromSnapshot[T g.SharedState](
+	startEdge g.Edge[T],
+	stateMonitorCh chan g.StateMonitorEntry[T],
+	opts *g.RuntimeOptions[T],
+	snapshot g.RuntimeSnapshot[T],
+) (g.Runtime[T], error) {
+	runtime, err := RuntimeFactory(startEdge, stateMonitorCh, opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := runtime.(*runtimeImpl[T]).restoreSnapshot(snapshot); err != nil {
+		runtime.Shutdown()
+		return nil, fmt.Errorf("runtime creation failed: %w", err)
+	}
+	return runtime, nil
+}
+
+func (r *runtimeImpl[T]) Snapshot() (g.RuntimeSnapshot[T], error) {
+	if inFlight := len(r.inFlight()); inFlight > 0 {
+		return g.RuntimeSnapshot[T]{}, fmt.Errorf("cannot snapshot the runtime with %d invocations in flight: %w", inFlight, g.ErrRuntimeExecuting)
+	}
+
+	rv := g.RuntimeSnapshot[T]{TakenAt: time.Now(), Threads: make([]g.ThreadSnapshot[T], 0)}
+	var err error
+	r.state.Range(func(threadID, state any) bool {
+		info := r.threadInfo(threadID.(string))
+		thread := g.ThreadSnapshot[T]{
+			ID:           info.ID,
+			Tenant:       info.Tenant,
+			Aliases:      info.Aliases,
+			State:        r.clone(state.(T)),
+			CreatedAt:    info.CreatedAt,
+			LastActiveAt: info.LastActiveAt,
+			ExpiresAt:    info.ExpiresAt,
+			Steps:        info.Steps,
+		}
+		if checkpoint, ok := r.lastPersisted.Load(threadID); ok {
+			useCheckpoint := r.clone(checkpoint.(T))
+			thread.Checkpoint = &useCheckpoint
+		}
+		if meta, ok := r.threadMeta.Load(threadID); ok {
+			if persistedAt := meta.(*threadMeta).persistedAt.Load(); persistedAt > 0 {
+				thread.CheckpointedAt = time.Unix(0, persistedAt)
+			}
+		}
+		if pad, ok := r.scratch.Load(threadID); ok {
+			if thread.Scratch, err = pad.(*scratchpad).encoded(); err != nil {
+				return false
+			}
+		}
+		rv.Threads = append(rv.Threads, thread)
+		return true
+	})
+	if err != nil {
+		return g.RuntimeSnapshot[T]{}, fmt.Errorf("cannot snapshot the runtime: %w", err)
+	}
+	slices.SortFunc(rv.Threads, func(a, b g.ThreadSnapshot[T]) int {
+		return strings.Compare(a.ID, b.ID)
+	})
+	return rv, nil
+}
+
+// restoreSnapshot adopts the threads of the snapshot, the runtime has no thread yet.
+func (r *runtimeImpl[T]) restoreSnapshot(snapshot g.RuntimeSnapshot[T]) error {
+	seen := make(map[string]bool, len(snapshot.Threads))
+	for _, thread := range snapshot.Threads {
+		if thread.ID == "" || seen[thread.ID] {
+			return fmt.Errorf("cannot restore thread %q: %w", thread.ID, g.ErrInvalidSnapshot)
+		}
+		seen[thread.ID] = true
+	}
+
+	for _, thread := range snapshot.Threads {
+		meta := &threadMeta{createdAt: thread.CreatedAt, tenant: thread.Tenant}
+		meta.lastActiveAt.Store(thread.LastActiveAt.UnixNano())
+		meta.steps.Store(thread.Steps)
+		if !thread.CheckpointedAt.IsZero() {
+			meta.persistedAt.Store(thread.CheckpointedAt.UnixNano())
+		}
+		r.threadMeta.Store(thread.ID, meta)
+
+		r.state.Store(thread.ID, r.clone(thread.State))
+		if thread.Checkpoint != nil {
+			r.lastPersisted.Store(thread.ID, r.clone(*thread.Checkpoint))
+		} else if r.persistFn != nil {
+			// Without a checkpoint the thread is persisted at its next step.
+			var zero T
+			r.lastPersisted.Store(thread.ID, zero)
+		}
+		r.threadTTL.Store(thread.ID, thread.ExpiresAt)
+		if len(thread.Scratch) > 0 {
+			pad := r.newScratchpad(thread.ID)
+			for key, value := range thread.Scratch {
+				pad.values[key] = value
+			}
+			r.scratch.Store(thread.ID, pad)
+		}
+
+		for _, alias := range thread.Aliases {
+			if err := r.aliases.add(thread.ID, alias); err != nil {
+				return err
+			}
+		}
+	}
+
+	r.logger.Info("runtime restored from snapshot", "threads", len(snapshot.Threads), "taken_at", snapshot.TakenAt)
+	return nil
+}
@@ -0,0 +1,136 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func newSnapshotTestGraph() (*mockRuntimeEdge, *mockRuntimeEdge) {
+	policy, _ := RouterPolicyImplFactory(AnyRoute[RuntimeTestState])
+	opts := &g.NodeOptions[RuntimeTestState]{RoutingPolicy: policy, Reducer: Replacer[RuntimeTestState]}
+
+	startNode, _ := NodeImplFactory(g.StartNode, "StartNode", nil, opts)
+	node1, _ := NodeImplFactory(g.IntermediateNode, "Node1", func(userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		currentState.Value += userInput.Value
+		currentState.Counter++
+		return currentState, nil
+	}, opts)
+	endNode, _ := NodeImplFactory(g.EndNode, "EndNode", nil, opts)
+	return &mockRuntimeEdge{from: startNode, to: node1, role: g.StartEdge}, &mockRuntimeEdge{from: node1, to: endNode, role: g.EndEdge}
+}
+
+// TestRuntime_Snapshot tests that the threads of a runtime are warm restarted in another runtime
+func TestRuntime_Snapshot(t *testing.T) {
+	startEdge, endEdge := newSnapshotTestGraph()
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 100)
+	runtime, err := RuntimeFactory(startEdge, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{})
+	if err != nil {
+		t.Fatalf("RuntimeFactory failed: %v", err)
+	}
+	runtime.AddEdge(endEdge)
+
+	threadID := runtime.Invoke(RuntimeTestState{Value: "a"})
+	waitTerminalEntry(t, stateMonitorCh)
+	runtime.Invoke(RuntimeTestState{Value: "b"}, g.InvokeConfigThreadID(threadID))
+	waitTerminalEntry(t, stateMonitorCh)
+	if err := runtime.AliasThread(threadID, "support/1"); err != nil {
+		t.Fatalf("AliasThread failed: %v", err)
+	}
+	if err := runtime.Scratch(threadID).Set("docs", []string{"a", "b"}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	runtime.Scratch(threadID).SetTransient("client", make(chan struct{}))
+	before, _ := runtime.DescribeThread(threadID)
+
+	runtime.Stop(context.Background(), g.ShutdownDrain)
+	snapshot, err := runtime.Snapshot()
+	if err != nil {
+		t.Fatalf("Snapshot failed: %v", err)
+	}
+	if len(snapshot.Threads) != 1 || snapshot.Threads[0].State.Value != "ab" || snapshot.Threads[0].Checkpoint != nil {
+		t.Fatalf("Unexpected snapshot %+v", snapshot)
+	}
+	if scratch := snapshot.Threads[0].Scratch; len(scratch) != 1 || string(scratch["docs"]) != `["a","b"]` {
+		t.Errorf("Expected the persistent scratchpad entries, got %s", scratch)
+	}
+
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded g.RuntimeSnapshot[RuntimeTestState]
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	restored, err := RuntimeFactoryFromSnapshot(startEdge, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, decoded)
+	if err != nil {
+		t.Fatalf("RuntimeFactoryFromSnapshot failed: %v", err)
+	}
+	defer restored.Shutdown()
+	restored.AddEdge(endEdge)
+
+	after, err := restored.DescribeThread(threadID)
+	if err != nil {
+		t.Fatalf("DescribeThread failed: %v", err)
+	}
+	if after.Steps != before.Steps || !after.ExpiresAt.Equal(before.ExpiresAt) || !after.CreatedAt.Equal(before.CreatedAt) || !slices.Equal(after.Aliases, []string{"support/1"}) {
+		t.Errorf("Expected the thread metadata to be restored, got %+v, was %+v", after, before)
+	}
+	pad := restored.Scratch(threadID)
+	if docs, ok, err := g.ScratchValue[[]string](pad, "docs"); err != nil || !ok || !slices.Equal(docs, []string{"a", "b"}) {
+		t.Errorf("Expected the scratchpad to be restored, got %v, %v, %v", docs, ok, err)
+	}
+	if _, ok := pad.Get("client"); ok {
+		t.Error("Expected the transient entry not to be restored")
+	}
+
+	restored.Invoke(RuntimeTestState{Value: "c"}, g.InvokeConfigThreadID(threadID))
+	if final := waitTerminalEntry(t, stateMonitorCh); final.NewState.Value != "abc" || final.NewState.Counter != 3 {
+		t.Errorf("Expected the thread to resume from its state, got %+v", final.NewState)
+	}
+}
+
+// TestRuntime_Snapshot_Errors tests that the inconsistent snapshots are refused
+func TestRuntime_Snapshot_Errors(t *testing.T) {
+	t.Run("in flight", func(t *testing.T) {
+		runtime, stateMonitorCh, started, release := newBlockingTestRuntime(t, &g.RuntimeOptions[RuntimeTestState]{})
+		runtime.Invoke(RuntimeTestState{})
+		<-started
+		if _, err := runtime.Snapshot(); !errors.Is(err, g.ErrRuntimeExecuting) {
+			t.Errorf("Expected ErrRuntimeExecuting, got %v", err)
+		}
+		close(release)
+		waitTerminalEntry(t, stateMonitorCh)
+	})
+
+	t.Run("scratchpad not encodable", func(t *testing.T) {
+		startEdge, endEdge := newSnapshotTestGraph()
+		stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 100)
+		runtime, _ := RuntimeFactory(startEdge, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{})
+		defer runtime.Shutdown()
+		runtime.AddEdge(endEdge)
+
+		threadID := runtime.Invoke(RuntimeTestState{})
+		waitTerminalEntry(t, stateMonitorCh)
+		if err := runtime.Scratch(threadID).Set("client", make(chan struct{})); err != nil {
+			t.Fatalf("Set failed: %v", err)
+		}
+		if _, err := runtime.Snapshot(); err == nil {
+			t.Error("Expected the entry not encodable to fail the snapshot")
+		}
+	})
+
+	t.Run("duplicate thread", func(t *testing.T) {
+		startEdge, _ := newSnapshotTestGraph()
+		snapshot := g.RuntimeSnapshot[RuntimeTestState]{Threads: []g.ThreadSnapshot[RuntimeTestState]{{ID: "t1"}, {ID: "t1"}}}
+		if _, err := RuntimeFactoryFromSnapshot(startEdge, nil, &g.RuntimeOptions[RuntimeTestState]{}, snapshot); !errors.Is(err, g.ErrInvalidSnapshot) {
+			t.Errorf("Expected ErrInvalidSnapshot, got %v", err)
+		}
+	})
+}
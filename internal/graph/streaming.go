@@ -0,0 +1,41 @@
+package graph
+
+import (
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// committedStateReader is implemented by the state observers distinguishing the committed state from its streaming view.
+type committedStateReader[T g.SharedState] interface {
+	committedState(threadID string) T
+}
+
+// committedState returns the state the nodes are executed with, ignoring the streaming view.
+func (r *runtimeImpl[T]) committedState(threadID string) T {
+	useState, _ := r.state.LoadOrStore(threadID, r.clone(r.initialState))
+	return r.clone(useState.(T))
+}
+
+// foldPartial folds a partial update into the streaming view of the thread, starting from the committed state.
+func (r *runtimeImpl[T]) foldPartial(threadID string, partial T) {
+	if r.streamingReducer == nil {
+		return
+	}
+	view, ok := r.streaming.Load(threadID)
+	if !ok {
+		view = r.committedState(threadID)
+	}
+	r.streaming.Store(threadID, r.streamingReducer(view.(T), partial))
+}
+
+// dropStreamingView discards the streaming view of the thread, CurrentState returning the committed state again.
+func (r *runtimeImpl[T]) dropStreamingView(threadID string) {
+	r.streaming.Delete(threadID)
+}
+
+// stateForNode returns the state a node is executed with.
+func stateForNode[T g.SharedState](stateObserver g.StateObserver[T], threadID string) T {
+	if reader, ok := stateObserver.(committedStateReader[T]); ok {
+		return reader.committedState(threadID)
+	}
+	return stateObserver.CurrentState(threadID)
+}
@@ -0,0 +1,91 @@
+package graph
+
+import (
+	"context"
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// streamTokens is a node function streaming the tokens, waiting for the test to release each of them.
+func streamTokens(tokens []string, release chan struct{}) g.ContextNodeFn[RuntimeTestState] {
+	return func(_ context.Context, _, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		answer := currentState.Value
+		for _, token := range tokens {
+			<-release
+			notify(RuntimeTestState{Value: token})
+			answer += token
+		}
+		<-release
+		return RuntimeTestState{Value: answer, Counter: currentState.Counter + 1}, nil
+	}
+}
+
+func appendTokens(view, partial RuntimeTestState) RuntimeTestState {
+	view.Value += partial.Value
+	return view
+}
+
+// TestRuntime_StreamingView tests that CurrentState reflects the partial updates folded into the streaming view
+func TestRuntime_StreamingView(t *testing.T) {
+	release := make(chan struct{})
+	runtime, stateMonitorCh := newNodeTestRuntime(t, streamTokens([]string{"Hel", "lo"}, release), &g.RuntimeOptions[RuntimeTestState]{StreamingReducer: appendTokens})
+
+	threadID := runtime.Invoke(RuntimeTestState{})
+
+	for i, expected := range []string{"Hel", "Hello"} {
+		release <- struct{}{}
+		waitForPartials(t, stateMonitorCh, 1)
+		if current := runtime.CurrentState(threadID); current.Value != expected || current.Counter != 0 {
+			t.Errorf("Expected the streaming view %q after the partial update %d, got %+v", expected, i, current)
+		}
+	}
+
+	release <- struct{}{}
+	_, entry := collectUntilTerminal(t, stateMonitorCh)
+	if entry.Error != nil {
+		t.Fatalf("Expected the invocation to complete, got %v", entry.Error)
+	}
+	if current := runtime.CurrentState(threadID); current.Value != "Hello" || current.Counter != 1 {
+		t.Errorf("Expected the committed state after the node completed, got %+v", current)
+	}
+}
+
+// TestRuntime_StreamingView_Disabled tests that the partial updates do not affect CurrentState by default
+func TestRuntime_StreamingView_Disabled(t *testing.T) {
+	release := make(chan struct{})
+	runtime, stateMonitorCh := newNodeTestRuntime(t, streamTokens([]string{"Hel"}, release), &g.RuntimeOptions[RuntimeTestState]{})
+
+	threadID := runtime.Invoke(RuntimeTestState{})
+
+	release <- struct{}{}
+	waitForPartials(t, stateMonitorCh, 1)
+	if current := runtime.CurrentState(threadID); current.Value != "" {
+		t.Errorf("Expected the committed state while streaming, got %+v", current)
+	}
+
+	release <- struct{}{}
+	if _, entry := collectUntilTerminal(t, stateMonitorCh); entry.Error != nil {
+		t.Fatalf("Expected the invocation to complete, got %v", entry.Error)
+	}
+}
+
+// TestRuntime_StreamingView_DroppedOnFailure tests that the streaming view is discarded when the invocation fails
+func TestRuntime_StreamingView_DroppedOnFailure(t *testing.T) {
+	release := make(chan struct{})
+	runtime, stateMonitorCh := newNodeTestRuntime(t, streamTokens([]string{"Hel"}, release), &g.RuntimeOptions[RuntimeTestState]{StreamingReducer: appendTokens})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	threadID := runtime.Invoke(RuntimeTestState{}, g.InvokeConfig{Context: ctx})
+
+	release <- struct{}{}
+	waitForPartials(t, stateMonitorCh, 1)
+	cancel()
+	release <- struct{}{}
+	if _, entry := collectUntilTerminal(t, stateMonitorCh); entry.Error == nil {
+		t.Fatal("Expected the cancelled invocation to fail")
+	}
+	if current := runtime.CurrentState(threadID); current.Value == "Hel" {
+		t.Errorf("Expected the streaming view to be dropped, got %+v", current)
+	}
+}
@@ -0,0 +1,158 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// tenantUsage accounts the invocations of a tenant.
+type tenantUsage struct {
+	running  atomic.Int64
+	rejected atomic.Uint64
+	// reserved counts the new threads of the admitted invocations, until they are registered or rejected.
+	reserved atomic.Int64
+}
+
+func (r *runtimeImpl[T]) TenantStats(tenant string) g.TenantStats {
+	usage := r.tenantUsage(tenant)
+	return g.TenantStats{
+		ActiveThreads:      r.tenantThreads(tenant),
+		RunningInvocations: int(usage.running.Load()),
+		Rejected:           usage.rejected.Load(),
+		Quota:              r.tenantQuota(tenant),
+	}
+}
+
+func (r *runtimeImpl[T]) tenantQuota(tenant string) g.TenantQuota {
+	if quota, ok := r.tenantQuotas[tenant]; ok {
+		return quota
+	}
+	return r.defaultTenantQuota
+}
+
+func (r *runtimeImpl[T]) tenantUsage(tenant string) *tenantUsage {
+	usage, _ := r.tenants.LoadOrStore(tenant, &tenantUsage{})
+	return usage.(*tenantUsage)
+}
+
+// tenantThreads returns the number of threads of the tenant held by the runtime.
+func (r *runtimeImpl[T]) tenantThreads(tenant string) int {
+	count := 0
+	r.threadMeta.Range(func(_, meta any) bool {
+		if meta.(*threadMeta).tenant == tenant {
+			count++
+		}
+		return true
+	})
+	return count
+}
+
+// threadTenant returns the tenant of an active thread, empty if it has none.
+func (r *runtimeImpl[T]) threadTenant(threadID string) string {
+	if meta, ok := r.threadMeta.Load(threadID); ok {
+		return meta.(*threadMeta).tenant
+	}
+	return ""
+}
+
+// ownerTenant returns the tenant owning the thread: the one of an active thread, otherwise the namespace of the thread
+// ID, e.g. for a thread released to the memory, see g.TenantThreadID.
+func (r *runtimeImpl[T]) ownerTenant(threadID string) string {
+	if tenant := r.threadTenant(threadID); tenant != "" {
+		return tenant
+	}
+	if tenant, _, ok := strings.Cut(threadID, g.TenantSeparator); ok && g.ValidateTenant(tenant) == nil {
+		return tenant
+	}
+	return ""
+}
+
+// admitTenant applies the quota of the tenant to an invocation, reserving its running slot and, when its thread is
+// new, one of the active threads of the tenant; the slot is released by endInvocation, or by releaseTenant if the
+// invocation does not start, the thread reservation by settleTenantThread. It returns whether the thread is new.
+func (r *runtimeImpl[T]) admitTenant(config g.InvokeConfig) (bool, error) {
+	r.tenantMu.Lock()
+	defer r.tenantMu.Unlock()
+
+	quota := r.tenantQuota(config.Tenant)
+	usage := r.tenantUsage(config.Tenant)
+
+	_, known := r.threadMeta.Load(config.ThreadID)
+	if !known {
+		if err := r.checkTenantThreads(config.Tenant); err != nil {
+			return false, fmt.Errorf("cannot invoke graph for thread %s: %w", config.ThreadID, err)
+		}
+	}
+	if quota.MaxConcurrentInvocations > 0 && usage.running.Load() >= int64(quota.MaxConcurrentInvocations) {
+		usage.rejected.Add(1)
+		return false, fmt.Errorf("cannot invoke graph for thread %s: %d running invocations: %w", config.ThreadID, quota.MaxConcurrentInvocations, g.ErrTenantQuotaExceeded)
+	}
+
+	usage.running.Add(1)
+	if !known {
+		usage.reserved.Add(1)
+	}
+	return !known, nil
+}
+
+// settleTenantThread releases the reservation of the new thread of an admitted invocation, once the invocation is
+// accepted and its thread registered, or rejected.
+func (r *runtimeImpl[T]) settleTenantThread(tenant string, newThread bool) {
+	if tenant != "" && newThread {
+		r.tenantUsage(tenant).reserved.Add(-1)
+	}
+}
+
+// reserveTenantThread registers a new thread of the tenant, within the quota of its active threads.
+func (r *runtimeImpl[T]) reserveTenantThread(threadID, tenant string) error {
+	if tenant == "" {
+		return nil
+	}
+	r.tenantMu.Lock()
+	defer r.tenantMu.Unlock()
+
+	if err := r.checkTenantThreads(tenant); err != nil {
+		return err
+	}
+	r.touchThread(threadID, tenant)
+	return nil
+}
+
+// checkTenantThreads fails when the tenant holds the active threads of its quota, the caller holds tenantMu.
+func (r *runtimeImpl[T]) checkTenantThreads(tenant string) error {
+	quota := r.tenantQuota(tenant)
+	if quota.MaxActiveThreads > 0 && r.tenantThreads(tenant)+int(r.tenantUsage(tenant).reserved.Load()) >= quota.MaxActiveThreads {
+		r.tenantUsage(tenant).rejected.Add(1)
+		return fmt.Errorf("%d active threads: %w", quota.MaxActiveThreads, g.ErrTenantQuotaExceeded)
+	}
+	return nil
+}
+
+func (r *runtimeImpl[T]) releaseTenant(tenant string) {
+	if tenant != "" {
+		r.tenantUsage(tenant).running.Add(-1)
+	}
+}
+
+// checkTenantSteps counts a node execution of the invocation against the quota of its tenant.
+func (r *runtimeImpl[T]) checkTenantSteps(config g.InvokeConfig) error {
+	if config.Tenant == "" {
+		return nil
+	}
+	quota := r.tenantQuota(config.Tenant)
+	if quota.MaxSteps <= 0 {
+		return nil
+	}
+	inv, ok := r.invocations.Load(config.ThreadID)
+	if !ok || inv.(*invocation).ctx != config.Context {
+		return nil
+	}
+	if steps := inv.(*invocation).steps.Add(1); steps > int64(quota.MaxSteps) {
+		r.tenantUsage(config.Tenant).rejected.Add(1)
+		return fmt.Errorf("invocation of thread %s exceeded %d steps: %w", config.ThreadID, quota.MaxSteps, g.ErrTenantQuotaExceeded)
+	}
+	return nil
+}
@@ -0,0 +1,265 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func tenantOf(ctx context.Context, _, currentState RuntimeTestState, _ g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+	currentState.Value, _ = g.TenantFromContext(ctx)
+	return currentState, nil
+}
+
+// TestRuntime_TenantNamespacing tests that the threads of a tenant are namespaced and labelled with it
+func TestRuntime_TenantNamespacing(t *testing.T) {
+	t.Run("threads and entries", func(t *testing.T) {
+		runtime, stateMonitorCh := newNodeTestRuntime(t, tenantOf, &g.RuntimeOptions[RuntimeTestState]{})
+
+		threadID := runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTenant("acme"), g.InvokeConfigThreadID("support-42"))
+		if threadID != "acme/support-42" {
+			t.Fatalf("Expected the thread ID to be namespaced, got %q", threadID)
+		}
+		final := waitTerminalEntry(t, stateMonitorCh)
+		if final.ThreadID != threadID || final.Tenant != "acme" || final.NewState.Value != "acme" {
+			t.Errorf("Expected the entry of the tenant thread, with the tenant in the node context, got %+v", final)
+		}
+
+		if again := runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTenant("acme"), g.InvokeConfigThreadID(threadID)); again != threadID {
+			t.Errorf("Expected the namespaced thread ID to be reused, got %q", again)
+		}
+		waitTerminalEntry(t, stateMonitorCh)
+		runtime.Invoke(RuntimeTestState{}, g.InvokeConfigThreadID("untenanted"))
+		waitTerminalEntry(t, stateMonitorCh)
+
+		if info, _ := runtime.DescribeThread(threadID); info.Tenant != "acme" {
+			t.Errorf("Expected the thread to belong to the tenant, got %+v", info)
+		}
+		if page := runtime.QueryThreads(g.ThreadQuery{Tenant: "acme"}); page.Total != 1 || page.Threads[0].ID != threadID {
+			t.Errorf("Expected the threads of the tenant only, got %+v", page.Threads)
+		}
+	})
+
+	t.Run("invalid tenants", func(t *testing.T) {
+		runtime, stateMonitorCh := newNodeTestRuntime(t, tenantOf, &g.RuntimeOptions[RuntimeTestState]{})
+
+		threadID := runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTenant("acme"), g.InvokeConfigThreadID("evil/x"))
+		waitTerminalEntry(t, stateMonitorCh)
+
+		if evil := runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTenant("acme/evil"), g.InvokeConfigThreadID("x")); evil != threadID {
+			t.Fatalf("Expected the colliding thread ID %q, got %q", threadID, evil)
+		}
+		if rejected := waitTerminalEntry(t, stateMonitorCh); !errors.Is(rejected.Error, g.ErrInvalidTenant) {
+			t.Errorf("Expected the tenant with the separator to be rejected, got %+v", rejected)
+		}
+		if info, _ := runtime.DescribeThread(threadID); info.Tenant != "acme" {
+			t.Errorf("Expected the thread to stay with its tenant, got %+v", info)
+		}
+		if err := runtime.Resume("x", g.InvokeConfigTenant("acme/evil")); !errors.Is(err, g.ErrInvalidTenant) {
+			t.Errorf("Expected the resume of the tenant with the separator to be rejected, got %v", err)
+		}
+	})
+
+	t.Run("released threads", func(t *testing.T) {
+		runtime, stateMonitorCh := newNodeTestRuntime(t, tenantOf, &g.RuntimeOptions[RuntimeTestState]{Memory: MemMemoryFactory[RuntimeTestState](nil)})
+
+		threadID := runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTenant("acme"), g.InvokeConfigThreadID("t1"))
+		waitTerminalEntry(t, stateMonitorCh)
+		waitFor(t, "the thread to be released", func() bool { return !slices.Contains(runtime.ListThreads(), threadID) })
+
+		forkID, err := runtime.Fork(threadID)
+		if err != nil {
+			t.Fatalf("Fork failed: %v", err)
+		}
+		if !strings.HasPrefix(forkID, "acme/") {
+			t.Errorf("Expected the fork to be namespaced with the tenant, got %q", forkID)
+		}
+		if info, _ := runtime.DescribeThread(forkID); info.Tenant != "acme" {
+			t.Errorf("Expected the fork to belong to the tenant, got %+v", info)
+		}
+
+		if err := runtime.MergeThreads(threadID, forkID, Replacer[RuntimeTestState]); err != nil {
+			t.Fatalf("MergeThreads failed: %v", err)
+		}
+		if info, _ := runtime.DescribeThread(threadID); info.Tenant != "acme" {
+			t.Errorf("Expected the merged thread to belong to the tenant, got %+v", info)
+		}
+	})
+
+	t.Run("memory keys", func(t *testing.T) {
+		memory := MemMemoryFactory[RuntimeTestState](nil)
+		runtime, stateMonitorCh := newNodeTestRuntime(t, tenantOf, &g.RuntimeOptions[RuntimeTestState]{Memory: memory})
+
+		runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTenant("acme"), g.InvokeConfigThreadID("support-42"))
+		waitTerminalEntry(t, stateMonitorCh)
+		runtime.Shutdown()
+
+		if stored, err := memory.RestoreFn()(context.Background(), "acme/support-42"); err != nil || stored.Value != "acme" {
+			t.Errorf("Expected the state to be stored under the namespaced key, got %+v: %v", stored, err)
+		}
+	})
+}
+
+// TestRuntime_TenantQuotas tests that the invocations exceeding the quota of their tenant are rejected
+func TestRuntime_TenantQuotas(t *testing.T) {
+	t.Run("active threads", func(t *testing.T) {
+		runtimeOpts := &g.RuntimeOptions[RuntimeTestState]{}
+		if err := g.WithTenantQuotas[RuntimeTestState](g.TenantQuota{MaxActiveThreads: 1}, nil).Apply(runtimeOpts); err != nil {
+			t.Fatalf("WithTenantQuotas failed: %v", err)
+		}
+		runtime, stateMonitorCh := newNodeTestRuntime(t, tenantOf, runtimeOpts)
+
+		runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTenant("acme"), g.InvokeConfigThreadID("first"))
+		waitTerminalEntry(t, stateMonitorCh)
+
+		runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTenant("acme"), g.InvokeConfigThreadID("second"))
+		if rejected := waitTerminalEntry(t, stateMonitorCh); !errors.Is(rejected.Error, g.ErrTenantQuotaExceeded) || rejected.Tenant != "acme" {
+			t.Errorf("Expected the new thread to be rejected, got %+v", rejected)
+		}
+		if _, err := runtime.DescribeThread("acme/second"); !errors.Is(err, g.ErrUnknownThreadID) {
+			t.Errorf("Expected the rejected thread not to be created, got %v", err)
+		}
+
+		for _, config := range []g.InvokeConfig{
+			{Tenant: "acme", ThreadID: "first"},
+			{Tenant: "globex", ThreadID: "first"},
+		} {
+			runtime.Invoke(RuntimeTestState{}, config)
+			if final := waitTerminalEntry(t, stateMonitorCh); final.Error != nil {
+				t.Errorf("Expected the invocation of %+v to be admitted, got %v", config, final.Error)
+			}
+		}
+
+		if stats := runtime.TenantStats("acme"); stats.ActiveThreads != 1 || stats.RunningInvocations != 0 || stats.Rejected != 1 || stats.Quota.MaxActiveThreads != 1 {
+			t.Errorf("Unexpected tenant stats %+v", stats)
+		}
+	})
+
+	t.Run("rejected threads", func(t *testing.T) {
+		store := MemLeaseStoreFactory()
+		if ok, _ := store.Acquire(context.Background(), "acme/owned", "another instance", time.Minute); !ok {
+			t.Fatal("Expected the lease to be granted")
+		}
+		runtimeOpts := leaseTestOptions(MemMemoryFactory[RuntimeTestState](nil), store, time.Minute)
+		_ = g.WithTenantQuotas[RuntimeTestState](g.TenantQuota{MaxActiveThreads: 1}, nil).Apply(runtimeOpts)
+		runtime, stateMonitorCh := newNodeTestRuntime(t, tenantOf, runtimeOpts)
+
+		runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTenant("acme"), g.InvokeConfigThreadID("owned"))
+		if rejected := waitTerminalEntry(t, stateMonitorCh); !errors.Is(rejected.Error, g.ErrThreadOwned) {
+			t.Fatalf("Expected the thread owned by another instance to be rejected, got %+v", rejected)
+		}
+		if stats := runtime.TenantStats("acme"); stats.ActiveThreads != 0 || stats.RunningInvocations != 0 {
+			t.Errorf("Expected the rejected thread not to count against the tenant, got %+v", stats)
+		}
+
+		runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTenant("acme"), g.InvokeConfigThreadID("free"))
+		if final := waitTerminalEntry(t, stateMonitorCh); final.Error != nil {
+			t.Errorf("Expected the new thread to be admitted, got %v", final.Error)
+		}
+	})
+
+	t.Run("concurrent invocations", func(t *testing.T) {
+		release := make(chan struct{})
+		blocking := func(ctx context.Context, _, currentState RuntimeTestState, _ g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+			<-release
+			return currentState, nil
+		}
+		runtimeOpts := &g.RuntimeOptions[RuntimeTestState]{}
+		_ = g.WithTenantQuotas[RuntimeTestState](g.TenantQuota{}, map[string]g.TenantQuota{"acme": {MaxConcurrentInvocations: 1}}).Apply(runtimeOpts)
+		runtime, stateMonitorCh := newNodeTestRuntime(t, blocking, runtimeOpts)
+
+		runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTenant("acme"))
+		runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTenant("acme"))
+		if rejected := waitTerminalEntry(t, stateMonitorCh); !errors.Is(rejected.Error, g.ErrTenantQuotaExceeded) {
+			t.Errorf("Expected the second invocation to be rejected, got %+v", rejected)
+		}
+		if stats := runtime.TenantStats("acme"); stats.RunningInvocations != 1 {
+			t.Errorf("Expected one running invocation, got %+v", stats)
+		}
+
+		close(release)
+		if final := waitTerminalEntry(t, stateMonitorCh); final.Error != nil {
+			t.Errorf("Expected the first invocation to complete, got %v", final.Error)
+		}
+		// The invocation ends right after its terminal entry is sent.
+		deadline := time.Now().Add(2 * time.Second)
+		for runtime.TenantStats("acme").RunningInvocations != 0 {
+			if time.Now().After(deadline) {
+				t.Fatal("Test timed out waiting for the running slot to be released")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	})
+
+	t.Run("steps", func(t *testing.T) {
+		runtimeOpts := &g.RuntimeOptions[RuntimeTestState]{}
+		_ = g.WithTenantQuotas[RuntimeTestState](g.TenantQuota{}, map[string]g.TenantQuota{"acme": {MaxSteps: 1}}).Apply(runtimeOpts)
+		runtime, stateMonitorCh := newNodeTestRuntime(t, tenantOf, runtimeOpts)
+
+		runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTenant("acme"))
+		if final := waitTerminalEntry(t, stateMonitorCh); !errors.Is(final.Error, g.ErrTenantQuotaExceeded) || final.Node != "EndNode" {
+			t.Errorf("Expected the invocation to fail routing to its second node, got %+v", final)
+		}
+		runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTenant("globex"))
+		if final := waitTerminalEntry(t, stateMonitorCh); final.Error != nil {
+			t.Errorf("Expected the other tenants to be unbounded, got %v", final.Error)
+		}
+	})
+
+	t.Run("batched inputs", func(t *testing.T) {
+		collectNode, err := BatchNodeImplFactory("Collect", func(ctx context.Context, inputs []RuntimeTestState, currentState RuntimeTestState) (RuntimeTestState, error) {
+			currentState.Counter = len(inputs)
+			return currentState, nil
+		}, 2, 0, testNodeOptions())
+		if err != nil {
+			t.Fatalf("BatchNodeImplFactory failed: %v", err)
+		}
+		release := make(chan struct{})
+		respondNode := testNode("Respond", func(ctx context.Context, _, currentState RuntimeTestState, _ g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+			<-release
+			return currentState, nil
+		})
+		runtimeOpts := &g.RuntimeOptions[RuntimeTestState]{}
+		_ = g.WithTenantQuotas[RuntimeTestState](g.TenantQuota{}, map[string]g.TenantQuota{"acme": {MaxConcurrentInvocations: 1}}).Apply(runtimeOpts)
+		stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 20)
+		runtime := newTestRuntime(t, stateMonitorCh, runtimeOpts, collectNode, respondNode)
+
+		collecting := runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTenant("acme"))
+		waitBatchedEntry(t, stateMonitorCh, 1)
+		if stats := runtime.TenantStats("acme"); stats.RunningInvocations != 0 {
+			t.Errorf("Expected the collecting thread not to hold its running slot, got %+v", stats)
+		}
+
+		// The batch of the second thread is full at once, its invocation runs and holds the running slot.
+		running := runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTenant("acme"))
+		waitBatchedEntry(t, stateMonitorCh, 1)
+		runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTenant("acme"), g.InvokeConfigThreadID(running))
+		waitFor(t, "the batch to be processed", func() bool { return runtime.TenantStats("acme").RunningInvocations == 1 })
+
+		runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTenant("acme"), g.InvokeConfigThreadID(collecting))
+		if rejected := waitTerminalEntry(t, stateMonitorCh); !errors.Is(rejected.Error, g.ErrTenantQuotaExceeded) || rejected.ThreadID != collecting {
+			t.Errorf("Expected the batched input of the tenant at its quota to be rejected, got %+v", rejected)
+		}
+		if batched, _ := runtime.Batched(collecting); batched != 1 {
+			t.Errorf("Expected the rejected input not to be batched, got %d batched inputs", batched)
+		}
+
+		close(release)
+		if final := waitTerminalEntry(t, stateMonitorCh); final.Error != nil || final.ThreadID != running {
+			t.Errorf("Expected the running invocation to complete, got %+v", final)
+		}
+		waitFor(t, "the running slot to be released", func() bool { return runtime.TenantStats("acme").RunningInvocations == 0 })
+		runtime.Invoke(RuntimeTestState{}, g.InvokeConfigTenant("acme"), g.InvokeConfigThreadID(collecting))
+		if final := waitTerminalEntry(t, stateMonitorCh); final.Error != nil || final.ThreadID != collecting || final.NewState.Counter != 2 {
+			t.Errorf("Expected the batch to be completed by the admitted input, got %+v", final)
+		}
+		if stats := runtime.TenantStats("acme"); stats.Rejected != 1 {
+			t.Errorf("Unexpected tenant stats %+v", stats)
+		}
+	})
+}
@@ -0,0 +1,105 @@
+package graph
+
+import (
+	"sort"
+	"sync/atomic"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+type threadMeta struct {
+	createdAt    time.Time
+	lastActiveAt atomic.Int64 // unix nanoseconds
+	steps        atomic.Int64
+	persistedAt  atomic.Int64 // unix nanoseconds
+	stateVersion atomic.Uint64
+	tenant       string
+}
+
+func (r *runtimeImpl[T]) DescribeThread(threadID string) (g.ThreadInfo, error) {
+	if _, ok := r.state.Load(threadID); !ok {
+		return g.ThreadInfo{}, g.ErrUnknownThreadID
+	}
+	return r.threadInfo(threadID), nil
+}
+
+func (r *runtimeImpl[T]) QueryThreads(query g.ThreadQuery) g.ThreadPage {
+	matching := make([]g.ThreadInfo, 0)
+	r.state.Range(func(threadID, _ any) bool {
+		info := r.threadInfo(threadID.(string))
+		if query.ExecutingOnly && !info.Executing {
+			return true
+		}
+		if query.Tenant != "" && info.Tenant != query.Tenant {
+			return true
+		}
+		if !query.ActiveSince.IsZero() && info.LastActiveAt.Before(query.ActiveSince) {
+			return true
+		}
+		if query.Filter != nil && !query.Filter(info) {
+			return true
+		}
+		matching = append(matching, info)
+		return true
+	})
+
+	sort.Slice(matching, func(i, j int) bool {
+		if matching[i].CreatedAt.Equal(matching[j].CreatedAt) {
+			return matching[i].ID < matching[j].ID
+		}
+		return matching[i].CreatedAt.Before(matching[j].CreatedAt)
+	})
+
+	rv := g.ThreadPage{Total: len(matching)}
+
+	start := min(max(query.Offset, 0), len(matching))
+	end := len(matching)
+	if query.Limit > 0 {
+		end = min(start+query.Limit, len(matching))
+	}
+
+	rv.Threads = matching[start:end]
+	rv.NextOffset = end
+	rv.HasMore = end < len(matching)
+
+	return rv
+}
+
+func (r *runtimeImpl[T]) threadInfo(threadID string) g.ThreadInfo {
+	rv := g.ThreadInfo{ID: threadID, Aliases: r.aliases.of(threadID)}
+
+	if meta, ok := r.threadMeta.Load(threadID); ok {
+		useMeta := meta.(*threadMeta)
+		rv.Tenant = useMeta.tenant
+		rv.CreatedAt = useMeta.createdAt
+		rv.LastActiveAt = time.Unix(0, useMeta.lastActiveAt.Load())
+		rv.Steps = useMeta.steps.Load()
+	}
+	if ttl, ok := r.threadTTL.Load(threadID); ok {
+		rv.ExpiresAt = ttl.(time.Time)
+	}
+	if exec, ok := r.executing.Load(threadID); ok {
+		rv.Executing = exec.(*atomic.Bool).Load()
+	}
+	if inv, ok := r.invocations.Load(threadID); ok {
+		rv.GraphVersion = inv.(*invocation).graphVersion
+	}
+
+	return rv
+}
+
+// touchThread records the activity of the thread, the tenant is recorded when the thread is new.
+func (r *runtimeImpl[T]) touchThread(threadID string, tenant string) {
+	now := time.Now()
+	meta, _ := r.threadMeta.LoadOrStore(threadID, &threadMeta{createdAt: now, tenant: tenant})
+	meta.(*threadMeta).lastActiveAt.Store(now.UnixNano())
+}
+
+func (r *runtimeImpl[T]) recordThreadStep(threadID string) {
+	if meta, ok := r.threadMeta.Load(threadID); ok {
+		useMeta := meta.(*threadMeta)
+		useMeta.steps.Add(1)
+		useMeta.lastActiveAt.Store(time.Now().UnixNano())
+	}
+}
@@ -0,0 +1,89 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// TestRuntime_DescribeThread tests the metadata of a completed thread
+func TestRuntime_DescribeThread(t *testing.T) {
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, testNode("Node1", countInput))
+
+	if _, err := runtime.DescribeThread("missing"); !errors.Is(err, g.ErrUnknownThreadID) {
+		t.Errorf("Expected ErrUnknownThreadID, got %v", err)
+	}
+
+	before := time.Now()
+	threadID := runtime.Invoke(RuntimeTestState{}, g.InvokeConfigThreadID("thread-1"))
+	waitCompletion(t, stateMonitorCh, 1)
+
+	info, err := runtime.DescribeThread(threadID)
+	if err != nil {
+		t.Fatalf("DescribeThread failed: %v", err)
+	}
+	if info.ID != "thread-1" {
+		t.Errorf("Expected ID 'thread-1', got '%s'", info.ID)
+	}
+	if info.CreatedAt.Before(before) || info.LastActiveAt.Before(info.CreatedAt) {
+		t.Errorf("Unexpected timestamps: created %v, last active %v", info.CreatedAt, info.LastActiveAt)
+	}
+	if !info.ExpiresAt.After(info.LastActiveAt) {
+		t.Errorf("Expected expiry after last activity, got %v", info.ExpiresAt)
+	}
+	if info.Executing {
+		t.Error("Expected the thread not to be executing")
+	}
+	// start, node1 and end nodes
+	if info.Steps != 3 {
+		t.Errorf("Expected 3 steps, got %d", info.Steps)
+	}
+}
+
+// TestRuntime_QueryThreads tests filtering and pagination of threads
+func TestRuntime_QueryThreads(t *testing.T) {
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 100)
+	runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, testNode("Node1", countInput))
+
+	for i := 0; i < 5; i++ {
+		runtime.Invoke(RuntimeTestState{}, g.InvokeConfigThreadID(fmt.Sprintf("thread-%d", i)))
+		waitCompletion(t, stateMonitorCh, 1)
+	}
+
+	page := runtime.QueryThreads(g.ThreadQuery{Limit: 2})
+	if page.Total != 5 || len(page.Threads) != 2 || !page.HasMore || page.NextOffset != 2 {
+		t.Fatalf("Unexpected first page: %+v", page)
+	}
+	if page.Threads[0].ID != "thread-0" || page.Threads[1].ID != "thread-1" {
+		t.Errorf("Expected threads ordered by creation, got %s, %s", page.Threads[0].ID, page.Threads[1].ID)
+	}
+
+	page = runtime.QueryThreads(g.ThreadQuery{Offset: 4, Limit: 2})
+	if len(page.Threads) != 1 || page.HasMore {
+		t.Errorf("Unexpected last page: %+v", page)
+	}
+
+	page = runtime.QueryThreads(g.ThreadQuery{Offset: 10})
+	if len(page.Threads) != 0 || page.Total != 5 {
+		t.Errorf("Unexpected out of range page: %+v", page)
+	}
+
+	page = runtime.QueryThreads(g.ThreadQuery{ExecutingOnly: true})
+	if page.Total != 0 {
+		t.Errorf("Expected no executing threads, got %d", page.Total)
+	}
+
+	page = runtime.QueryThreads(g.ThreadQuery{Filter: func(info g.ThreadInfo) bool { return info.ID == "thread-3" }})
+	if page.Total != 1 || page.Threads[0].ID != "thread-3" {
+		t.Errorf("Unexpected filtered page: %+v", page)
+	}
+
+	page = runtime.QueryThreads(g.ThreadQuery{ActiveSince: time.Now().Add(time.Hour)})
+	if page.Total != 0 {
+		t.Errorf("Expected no thread active in the future, got %d", page.Total)
+	}
+}
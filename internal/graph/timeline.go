@@ -0,0 +1,180 @@
+package graph
+
+import (
+	"container/list"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// timelineRecorder retains the timelines of the most recently invoked threads.
+type timelineRecorder struct {
+	mu             sync.Mutex
+	maxThreads     int
+	maxInvocations int
+	threads        map[string]*list.Element
+	// recency orders the *threadTimeline, the most recently invoked first.
+	recency *list.List
+}
+
+type threadTimeline struct {
+	threadID    string
+	invocations []g.TimelineInvocation
+}
+
+func newTimelineRecorder(maxThreads, maxInvocations int) *timelineRecorder {
+	if maxThreads <= 0 {
+		maxThreads = g.DefaultTimelineThreads
+	}
+	if maxInvocations <= 0 {
+		maxInvocations = g.DefaultTimelineInvocations
+	}
+	return &timelineRecorder{
+		maxThreads:     maxThreads,
+		maxInvocations: maxInvocations,
+		threads:        make(map[string]*list.Element),
+		recency:        list.New(),
+	}
+}
+
+func (t *timelineRecorder) begin(threadID string, graphVersion uint64, at time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	elem, ok := t.threads[threadID]
+	if ok {
+		t.recency.MoveToFront(elem)
+	} else {
+		elem = t.recency.PushFront(&threadTimeline{threadID: threadID})
+		t.threads[threadID] = elem
+		if t.recency.Len() > t.maxThreads {
+			oldest := t.recency.Back()
+			t.recency.Remove(oldest)
+			delete(t.threads, oldest.Value.(*threadTimeline).threadID)
+		}
+	}
+
+	thread := elem.Value.(*threadTimeline)
+	thread.invocations = append(thread.invocations, g.TimelineInvocation{
+		GraphVersion: graphVersion,
+		StartedAt:    at,
+		Spans:        []g.TimelineSpan{},
+		Routes:       []g.TimelineRoute{},
+	})
+	if excess := len(thread.invocations) - t.maxInvocations; excess > 0 {
+		thread.invocations = slices.Delete(thread.invocations, 0, excess)
+	}
+}
+
+// running returns the running invocation of the thread, the caller holds mu.
+func (t *timelineRecorder) running(threadID string) *g.TimelineInvocation {
+	elem, ok := t.threads[threadID]
+	if !ok {
+		return nil
+	}
+	thread := elem.Value.(*threadTimeline)
+	if len(thread.invocations) == 0 {
+		return nil
+	}
+	last := &thread.invocations[len(thread.invocations)-1]
+	if !last.EndedAt.IsZero() {
+		return nil
+	}
+	return last
+}
+
+func (t *timelineRecorder) nodeStarted(threadID, node string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if inv := t.running(threadID); inv != nil {
+		inv.Spans = append(inv.Spans, g.TimelineSpan{Node: node, StartedAt: time.Now()})
+	}
+}
+
+func (t *timelineRecorder) nodeEnded(threadID, node string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	inv := t.running(threadID)
+	if inv == nil {
+		return
+	}
+	for idx := len(inv.Spans) - 1; idx >= 0; idx-- {
+		span := &inv.Spans[idx]
+		if span.Node == node && span.EndedAt.IsZero() {
+			span.EndedAt = time.Now()
+			span.Duration = span.EndedAt.Sub(span.StartedAt)
+			if err != nil {
+				span.Error = err.Error()
+			}
+			return
+		}
+	}
+}
+
+// runningNodes returns the nodes executing in the running invocation of the thread.
+func (t *timelineRecorder) runningNodes(threadID string) []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	inv := t.running(threadID)
+	if inv == nil {
+		return nil
+	}
+	var nodes []string
+	for _, span := range inv.Spans {
+		if span.EndedAt.IsZero() {
+			nodes = append(nodes, span.Node)
+		}
+	}
+	return nodes
+}
+
+func (t *timelineRecorder) routed(threadID, from, to string, fallback bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if inv := t.running(threadID); inv != nil {
+		inv.Routes = append(inv.Routes, g.TimelineRoute{At: time.Now(), From: from, To: to, Fallback: fallback})
+	}
+}
+
+func (t *timelineRecorder) end(threadID string, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	inv := t.running(threadID)
+	if inv == nil {
+		return
+	}
+	inv.EndedAt = time.Now()
+	inv.Duration = inv.EndedAt.Sub(inv.StartedAt)
+	if err != nil {
+		inv.Error = err.Error()
+	}
+}
+
+func (t *timelineRecorder) timeline(threadID string) (g.Timeline, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	elem, ok := t.threads[threadID]
+	if !ok {
+		return g.Timeline{}, false
+	}
+	thread := elem.Value.(*threadTimeline)
+	rv := g.Timeline{ThreadID: threadID, Invocations: make([]g.TimelineInvocation, len(thread.invocations))}
+	for idx, inv := range thread.invocations {
+		inv.Spans = slices.Clone(inv.Spans)
+		inv.Routes = slices.Clone(inv.Routes)
+		rv.Invocations[idx] = inv
+	}
+	return rv, true
+}
+
+func (r *runtimeImpl[T]) Timeline(threadID string) (g.Timeline, error) {
+	timeline, ok := r.timeline.timeline(threadID)
+	if !ok {
+		return g.Timeline{}, fmt.Errorf("cannot get the timeline of thread %s: %w", threadID, g.ErrUnknownThreadID)
+	}
+	return timeline, nil
+}
@@ -0,0 +1,115 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// waitTimeline waits for the last invocation of the thread to be terminated in its timeline.
+func waitTimeline(t *testing.T, runtime g.Runtime[RuntimeTestState], threadID string) g.Timeline {
+	t.Helper()
+	var timeline g.Timeline
+	waitFor(t, "the timeline of thread "+threadID, func() bool {
+		var err error
+		timeline, err = runtime.Timeline(threadID)
+		return err == nil && len(timeline.Invocations) > 0 && !timeline.Invocations[len(timeline.Invocations)-1].EndedAt.IsZero()
+	})
+	return timeline
+}
+
+// TestRuntime_Timeline tests that the node executions and routing decisions of the invocations are recorded
+func TestRuntime_Timeline(t *testing.T) {
+	t.Run("completed and failed invocations", func(t *testing.T) {
+		node1 := testNode("Node1", func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+			time.Sleep(5 * time.Millisecond)
+			return currentState, nil
+		})
+		node2 := testNode("Node2", func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+			if userInput.Value == "fail" {
+				return currentState, errors.New("node2 failure")
+			}
+			return currentState, nil
+		})
+		stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 100)
+		runtime := newTestRuntime(t, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{}, node1, node2)
+
+		threadID := runtime.Invoke(RuntimeTestState{})
+		waitTerminalEntry(t, stateMonitorCh)
+		waitTimeline(t, runtime, threadID)
+		runtime.Invoke(RuntimeTestState{Value: "fail"}, g.InvokeConfigThreadID(threadID))
+		waitTerminalEntry(t, stateMonitorCh)
+		timeline := waitTimeline(t, runtime, threadID)
+
+		if timeline.ThreadID != threadID || len(timeline.Invocations) != 2 {
+			t.Fatalf("Expected the two invocations of the thread, got %+v", timeline)
+		}
+
+		completed := timeline.Invocations[0]
+		nodes := make([]string, 0, len(completed.Spans))
+		for idx, span := range completed.Spans {
+			nodes = append(nodes, span.Node)
+			if span.EndedAt.Before(span.StartedAt) || span.Duration != span.EndedAt.Sub(span.StartedAt) {
+				t.Errorf("Unexpected span %+v", span)
+			}
+			if idx > 0 && span.StartedAt.Before(completed.Spans[idx-1].StartedAt) {
+				t.Errorf("Expected the spans in order of start, got %+v", completed.Spans)
+			}
+		}
+		if !slices.Equal(nodes, []string{"StartNode", "Node1", "Node2", "EndNode"}) {
+			t.Errorf("Unexpected spans %v", nodes)
+		}
+		if completed.Spans[1].Duration < 5*time.Millisecond {
+			t.Errorf("Expected the duration of Node1, got %s", completed.Spans[1].Duration)
+		}
+		if len(completed.Routes) != 3 || completed.Routes[1].From != "Node1" || completed.Routes[1].To != "Node2" {
+			t.Errorf("Unexpected routes %+v", completed.Routes)
+		}
+		if completed.Error != "" || completed.GraphVersion != runtime.GraphVersion() || completed.Duration <= 0 {
+			t.Errorf("Unexpected completed invocation %+v", completed)
+		}
+
+		failed := timeline.Invocations[1]
+		if !strings.Contains(failed.Error, "node2 failure") || len(failed.Routes) != 2 {
+			t.Errorf("Expected the failed invocation, got %+v", failed)
+		}
+		if last := failed.Spans[len(failed.Spans)-1]; last.Node != "Node2" || !strings.Contains(last.Error, "node2 failure") {
+			t.Errorf("Expected the failed span of Node2, got %+v", last)
+		}
+
+		data, err := json.Marshal(timeline)
+		if err != nil || !strings.Contains(string(data), `"spans":[{"node":"StartNode"`) {
+			t.Errorf("Unexpected JSON %s: %v", data, err)
+		}
+	})
+
+	t.Run("retention", func(t *testing.T) {
+		runtimeOpts := &g.RuntimeOptions[RuntimeTestState]{}
+		if err := g.WithTimelineRetention[RuntimeTestState](1, 1).Apply(runtimeOpts); err != nil {
+			t.Fatalf("WithTimelineRetention failed: %v", err)
+		}
+		stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 100)
+		runtime := newTestRuntime(t, stateMonitorCh, runtimeOpts, testNode("Node1", countInput))
+
+		first := runtime.Invoke(RuntimeTestState{})
+		waitTerminalEntry(t, stateMonitorCh)
+		runtime.Invoke(RuntimeTestState{}, g.InvokeConfigThreadID(first))
+		waitTerminalEntry(t, stateMonitorCh)
+		if timeline := waitTimeline(t, runtime, first); len(timeline.Invocations) != 1 {
+			t.Errorf("Expected the last invocation only, got %d", len(timeline.Invocations))
+		}
+
+		second := runtime.Invoke(RuntimeTestState{})
+		waitTerminalEntry(t, stateMonitorCh)
+		waitTimeline(t, runtime, second)
+		if _, err := runtime.Timeline(first); !errors.Is(err, g.ErrUnknownThreadID) {
+			t.Errorf("Expected the timeline of the least recent thread to be discarded, got %v", err)
+		}
+	})
+}
@@ -0,0 +1,25 @@
+package graph
+
+import (
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func (r *runtimeImpl[T]) Nodes() []g.Node[T] {
+	return r.graph.Load().nodes()
+}
+
+func (r *runtimeImpl[T]) Edges() []g.Edge[T] {
+	return r.graph.Load().allEdges()
+}
+
+func (r *runtimeImpl[T]) Neighbors(node g.Node[T]) []g.Node[T] {
+	rv := []g.Node[T]{}
+	seen := make(map[g.Node[T]]bool)
+	for _, edge := range r.edgesFrom(node) {
+		if to := edge.To(); to != nil && !seen[to] {
+			seen[to] = true
+			rv = append(rv, to)
+		}
+	}
+	return rv
+}
@@ -0,0 +1,64 @@
+package graph
+
+import (
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// TestRuntime_Topology tests the read-only accessors of the graph structure
+func TestRuntime_Topology(t *testing.T) {
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+
+	startNode := newMockRuntimeNode("StartNode", g.StartNode, nil, nil)
+	router := newMockRuntimeNode("Router", g.IntermediateNode, nil, nil)
+	left := newMockRuntimeNode("Left", g.IntermediateNode, nil, nil)
+	right := newMockRuntimeNode("Right", g.IntermediateNode, nil, nil)
+	endNode := newMockRuntimeNode("EndNode", g.EndNode, nil, nil)
+
+	startEdge := &mockRuntimeEdge{from: startNode, to: router, role: g.StartEdge}
+	runtime, _ := RuntimeFactory(startEdge, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{})
+	defer runtime.Shutdown()
+
+	runtime.AddEdge(
+		&mockRuntimeEdge{from: router, to: left, role: g.IntermediateEdge},
+		&mockRuntimeEdge{from: router, to: right, role: g.IntermediateEdge},
+		&mockRuntimeEdge{from: router, to: left, role: g.IntermediateEdge, labels: map[string]string{"retry": "true"}},
+		&mockRuntimeEdge{from: left, to: endNode, role: g.EndEdge},
+		&mockRuntimeEdge{from: right, to: endNode, role: g.EndEdge},
+	)
+
+	names := func(nodes []g.Node[RuntimeTestState]) []string {
+		rv := make([]string, len(nodes))
+		for idx, node := range nodes {
+			rv[idx] = node.Name()
+		}
+		return rv
+	}
+	assertNames := func(label string, actual, expected []string) {
+		t.Helper()
+		if len(actual) != len(expected) {
+			t.Fatalf("%s: expected %v, got %v", label, expected, actual)
+		}
+		for idx := range expected {
+			if actual[idx] != expected[idx] {
+				t.Errorf("%s: expected %v, got %v", label, expected, actual)
+			}
+		}
+	}
+
+	assertNames("Nodes", names(runtime.Nodes()), []string{"StartNode", "Router", "Left", "Right", "EndNode"})
+	assertNames("Neighbors", names(runtime.Neighbors(router)), []string{"Left", "Right"})
+	assertNames("Neighbors of the end node", names(runtime.Neighbors(endNode)), []string{})
+	assertNames("Neighbors of an unknown node", names(runtime.Neighbors(newMockRuntimeNode("Unknown", g.IntermediateNode, nil, nil))), []string{})
+
+	edges := runtime.Edges()
+	if len(edges) != 6 || edges[0] != startEdge {
+		t.Fatalf("Expected 6 edges starting with the start edge, got %d", len(edges))
+	}
+
+	edges[0] = nil
+	if runtime.Edges()[0] != startEdge {
+		t.Error("Expected Edges to return a copy")
+	}
+}
@@ -0,0 +1,215 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func (r *runtimeImpl[T]) Validate() error {
+	return r.graph.Load().validate(r.allowCycles)
+}
+
+// validate checks the version with the checks of Validate.
+func (v *graphVersion[T]) validate(allowCycles bool) error {
+	if v.startEdge.From() == nil {
+		return fmt.Errorf("graph validation failed: %w", g.ErrSourceNodeNil)
+	}
+
+	var findings []error
+
+	// Check if there's at least one path from start to an end edge
+	visited := make(map[string]bool)
+	// Include the start edge in the traversal by starting from its target node
+	if !v.hasPathToEndEdge(v.startEdge.To(), visited) {
+		findings = append(findings, g.ErrNoPathToEnd)
+	}
+	// Every entry point is validated independently
+	for _, edge := range v.edges {
+		if name, ok := g.EntryPointOf(edge); ok && !v.hasPathToEndEdge(edge.To(), make(map[string]bool)) {
+			findings = append(findings, fmt.Errorf("entry point %s: %w", name, g.ErrNoPathToEnd))
+		}
+	}
+
+	findings = append(findings, v.validateEdges()...)
+	findings = append(findings, v.validateNodes()...)
+	findings = append(findings, v.validateRoutes()...)
+	if !allowCycles {
+		findings = append(findings, v.validateCycles()...)
+	}
+
+	if len(findings) == 0 {
+		return nil
+	}
+	return fmt.Errorf("graph validation failed: %w", errors.Join(findings...))
+}
+
+// validateEdges reports the additional start edges, the duplicate entry points and the edges referencing nil nodes.
+func (v *graphVersion[T]) validateEdges() []error {
+	var findings []error
+	entryPoints := make(map[string]bool)
+	if name, ok := g.EntryPointOf(v.startEdge); ok {
+		entryPoints[name] = true
+	}
+	for idx, edge := range v.edges {
+		if edge.Role() == g.StartEdge {
+			name, ok := g.EntryPointOf(edge)
+			switch {
+			case !ok:
+				findings = append(findings, fmt.Errorf("edge %d: %w", idx, g.ErrMultipleStartEdges))
+			case entryPoints[name]:
+				findings = append(findings, fmt.Errorf("edge %d: %w: %s", idx, g.ErrDuplicateEntryPoint, name))
+			}
+			entryPoints[name] = true
+		}
+		if edge.From() == nil || edge.To() == nil {
+			findings = append(findings, fmt.Errorf("edge %d: %w", idx, g.ErrDanglingEdge))
+		}
+	}
+	return findings
+}
+
+// validateNodes reports the duplicate names, the unreachable nodes and the dead ends among the operational nodes.
+func (v *graphVersion[T]) validateNodes() []error {
+	reachable := v.reachableNodes()
+
+	var findings []error
+	byName := make(map[string]g.Node[T])
+	for _, node := range v.nodes() {
+		if node.Role() != g.IntermediateNode {
+			continue
+		}
+
+		if other, ok := byName[node.Name()]; ok && other != node {
+			findings = append(findings, fmt.Errorf("node %s: %w", node.Name(), g.ErrDuplicateNodeName))
+		}
+		byName[node.Name()] = node
+
+		if !reachable[node] {
+			findings = append(findings, fmt.Errorf("node %s: %w", node.Name(), g.ErrUnreachableNode))
+		}
+		if len(v.edgesFrom(node)) == 0 {
+			findings = append(findings, fmt.Errorf("node %s: %w", node.Name(), g.ErrDeadEndNode))
+		}
+	}
+	return findings
+}
+
+// validateRoutes checks the fallback edges of the nodes and the routing policies implementing RouteValidator.
+func (v *graphVersion[T]) validateRoutes() []error {
+	var findings []error
+	for _, node := range v.nodes() {
+		if err := v.validateNodeRoutes(node); err != nil {
+			findings = append(findings, fmt.Errorf("routes of node %s: %w", node.Name(), err))
+		}
+	}
+	return findings
+}
+
+func (v *graphVersion[T]) validateNodeRoutes(node g.Node[T]) error {
+	outboundEdges := v.edgesFrom(node)
+
+	fallbacks := 0
+	for _, edge := range outboundEdges {
+		isFallback, err := g.IsFallbackEdge(edge)
+		if err != nil {
+			return err
+		}
+		if isFallback {
+			fallbacks++
+		}
+	}
+	if fallbacks > 1 {
+		return g.ErrMultipleFallbackEdges
+	}
+
+	validator, ok := node.RoutePolicy().(g.RouteValidator[T])
+	if !ok {
+		return nil
+	}
+	err := validator.ValidateRoutes(node, outboundEdges)
+	if errors.Is(err, g.ErrRoutesNotExhaustive) && fallbacks == 1 {
+		return nil
+	}
+	return err
+}
+
+// nodes returns the nodes referenced by the edges, in order of appearance.
+func (v *graphVersion[T]) nodes() []g.Node[T] {
+	var rv []g.Node[T]
+	seen := make(map[g.Node[T]]bool)
+	for _, edge := range v.allEdges() {
+		for _, node := range []g.Node[T]{edge.From(), edge.To()} {
+			if node == nil || seen[node] {
+				continue
+			}
+			seen[node] = true
+			rv = append(rv, node)
+		}
+	}
+	return rv
+}
+
+// reachableNodes returns the nodes reachable from the targets of the start edges.
+func (v *graphVersion[T]) reachableNodes() map[g.Node[T]]bool {
+	reachable := map[g.Node[T]]bool{v.startEdge.To(): true}
+	queue := []g.Node[T]{v.startEdge.To()}
+	for _, edge := range v.edges {
+		if _, ok := g.EntryPointOf(edge); ok && edge.To() != nil && !reachable[edge.To()] {
+			reachable[edge.To()] = true
+			queue = append(queue, edge.To())
+		}
+	}
+	for len(queue) > 0 {
+		node := queue[0]
+		queue = queue[1:]
+		for _, edge := range v.edgesFrom(node) {
+			if to := edge.To(); to != nil && !reachable[to] {
+				reachable[to] = true
+				queue = append(queue, to)
+			}
+		}
+	}
+	return reachable
+}
+
+func (v *graphVersion[T]) hasPathToEndEdge(node g.Node[T], visited map[string]bool) bool {
+	if node == nil {
+		return false
+	}
+
+	// Check if the node is an EndNode
+	if node.Role() == g.EndNode {
+		return true
+	}
+
+	// Mark the node as visited
+	nodeKey := fmt.Sprintf("%p", node)
+	if visited[nodeKey] {
+		return false
+	}
+	visited[nodeKey] = true
+
+	edges := v.edges
+
+	// Check if any EndEdge starts from this node
+	for _, edge := range edges {
+		if edge.Role() == g.EndEdge {
+			if edge.From() == node {
+				return true
+			}
+		}
+	}
+
+	// Explore all edges to find connected nodes
+	for _, edge := range edges {
+		if edge.From() == node {
+			if v.hasPathToEndEdge(edge.To(), visited) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,88 @@
+package graph
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// TestRuntime_Validate_AllFindings tests that Validate reports every structural issue at once
+func TestRuntime_Validate_AllFindings(t *testing.T) {
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+
+	startNode := newMockRuntimeNode("StartNode", g.StartNode, nil, nil)
+	node1 := newMockRuntimeNode("Node1", g.IntermediateNode, nil, nil)
+	deadEnd := newMockRuntimeNode("DeadEnd", g.IntermediateNode, nil, nil)
+	orphan := newMockRuntimeNode("Orphan", g.IntermediateNode, nil, nil)
+	duplicate := newMockRuntimeNode("Node1", g.IntermediateNode, nil, nil)
+	endNode := newMockRuntimeNode("EndNode", g.EndNode, nil, nil)
+
+	runtime, _ := RuntimeFactory(&mockRuntimeEdge{from: startNode, to: node1, role: g.StartEdge}, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{})
+	defer runtime.Shutdown()
+
+	runtime.AddEdge(
+		&mockRuntimeEdge{from: node1, to: endNode, role: g.EndEdge},
+		&mockRuntimeEdge{from: node1, to: deadEnd, role: g.IntermediateEdge},
+		&mockRuntimeEdge{from: orphan, to: endNode, role: g.EndEdge},
+		&mockRuntimeEdge{from: startNode, to: duplicate, role: g.StartEdge},
+		&mockRuntimeEdge{from: duplicate, to: nil, role: g.IntermediateEdge},
+	)
+
+	err := runtime.Validate()
+	if err == nil {
+		t.Fatal("Expected validation errors, got nil")
+	}
+
+	for _, expected := range []error{g.ErrDeadEndNode, g.ErrUnreachableNode, g.ErrDuplicateNodeName, g.ErrMultipleStartEdges, g.ErrDanglingEdge} {
+		if !errors.Is(err, expected) {
+			t.Errorf("Expected %v to be reported, got '%s'", expected, err.Error())
+		}
+	}
+	if errors.Is(err, g.ErrNoPathToEnd) {
+		t.Errorf("Expected a path to end to be found, got '%s'", err.Error())
+	}
+
+	for _, expected := range []string{"node DeadEnd: node has no outbound edge", "node Orphan: node is unreachable from the start edge", "edge 3: graph has more than one start edge", "edge 4: edge references a node which is not in the graph"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected '%s' in '%s'", expected, err.Error())
+		}
+	}
+}
+
+// TestRuntime_Validate_Loop tests that loops fail validation unless allowed, their nodes being reachable and with outbound edges
+func TestRuntime_Validate_Loop(t *testing.T) {
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 10)
+
+	startNode := newMockRuntimeNode("StartNode", g.StartNode, nil, nil)
+	node1 := newMockRuntimeNode("Node1", g.IntermediateNode, nil, nil)
+	node2 := newMockRuntimeNode("Node2", g.IntermediateNode, nil, nil)
+	endNode := newMockRuntimeNode("EndNode", g.EndNode, nil, nil)
+
+	edges := []g.Edge[RuntimeTestState]{
+		&mockRuntimeEdge{from: node1, to: node2, role: g.IntermediateEdge},
+		&mockRuntimeEdge{from: node2, to: node1, role: g.IntermediateEdge},
+		&mockRuntimeEdge{from: node2, to: endNode, role: g.EndEdge},
+	}
+
+	strict, _ := RuntimeFactory(&mockRuntimeEdge{from: startNode, to: node1, role: g.StartEdge}, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{})
+	defer strict.Shutdown()
+	strict.AddEdge(edges...)
+
+	err := strict.Validate()
+	if !errors.Is(err, g.ErrCycleDetected) {
+		t.Fatalf("Expected ErrCycleDetected, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "Node1 -> Node2 -> Node1") {
+		t.Errorf("Expected the cycle in the error, got '%s'", err.Error())
+	}
+
+	allowing, _ := RuntimeFactory(&mockRuntimeEdge{from: startNode, to: node1, role: g.StartEdge}, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{AllowCycles: true, MaxIterations: 5})
+	defer allowing.Shutdown()
+	allowing.AddEdge(edges...)
+
+	if err := allowing.Validate(); err != nil {
+		t.Errorf("Validate() failed for an allowed loop: %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package graph
+
+import (
+	"fmt"
+	"slices"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// graphVersion is an immutable snapshot of the edges of the graph.
+type graphVersion[T g.SharedState] struct {
+	version   uint64
+	startEdge g.Edge[T]
+	edges     []g.Edge[T]
+}
+
+func (v *graphVersion[T]) edgesFrom(node g.Node[T]) []g.Edge[T] {
+	if v.startEdge.From() == node {
+		return []g.Edge[T]{v.startEdge}
+	}
+	var outboundEdges []g.Edge[T]
+	for _, edge := range v.edges {
+		if edge.From() == node {
+			outboundEdges = append(outboundEdges, edge)
+		}
+	}
+	return outboundEdges
+}
+
+// allEdges returns the start edge followed by the other edges.
+func (v *graphVersion[T]) allEdges() []g.Edge[T] {
+	return append([]g.Edge[T]{v.startEdge}, v.edges...)
+}
+
+// entryEdge returns the start edge of the named entry point, the one the runtime was created with if the name is empty.
+func (v *graphVersion[T]) entryEdge(name string) g.Edge[T] {
+	if name == "" {
+		return v.startEdge
+	}
+	for _, edge := range v.allEdges() {
+		if entryPoint, ok := g.EntryPointOf(edge); ok && entryPoint == name {
+			return edge
+		}
+	}
+	return nil
+}
+
+// outcomeOf returns the outcome of the end edges leading to the end node, empty if they have none.
+func (v *graphVersion[T]) outcomeOf(endNode g.Node[T]) string {
+	for _, edge := range v.edges {
+		if outcome, ok := g.OutcomeOf(edge); ok && edge.To() == endNode {
+			return outcome
+		}
+	}
+	return ""
+}
+
+// next returns the version following this one, with the given edges.
+func (v *graphVersion[T]) next(edges []g.Edge[T]) *graphVersion[T] {
+	return &graphVersion[T]{
+		version:   v.version + 1,
+		startEdge: v.startEdge,
+		edges:     edges,
+	}
+}
+
+func (r *runtimeImpl[T]) AddEdge(edge ...g.Edge[T]) {
+	r.graphMu.Lock()
+	defer r.graphMu.Unlock()
+
+	current := r.graph.Load()
+	r.graph.Store(current.next(append(slices.Clone(current.edges), edge...)))
+}
+
+func (r *runtimeImpl[T]) AddEdgeValidated(edge ...g.Edge[T]) error {
+	r.graphMu.Lock()
+	defer r.graphMu.Unlock()
+
+	current := r.graph.Load()
+	candidate := current.next(append(slices.Clone(current.edges), edge...))
+	if err := candidate.validate(r.allowCycles); err != nil {
+		return fmt.Errorf("cannot add edge: %w", err)
+	}
+	r.graph.Store(candidate)
+	return nil
+}
+
+func (r *runtimeImpl[T]) RemoveEdge(edge ...g.Edge[T]) error {
+	r.graphMu.Lock()
+	defer r.graphMu.Unlock()
+
+	current := r.graph.Load()
+	edges := slices.Clone(current.edges)
+	for _, removed := range edge {
+		idx := slices.Index(edges, removed)
+		if idx < 0 {
+			return fmt.Errorf("cannot remove edge: %w", g.ErrEdgeNotFound)
+		}
+		edges = slices.Delete(edges, idx, idx+1)
+	}
+
+	candidate := current.next(edges)
+	if err := candidate.validate(r.allowCycles); err != nil {
+		return fmt.Errorf("cannot remove edge: %w", err)
+	}
+	r.graph.Store(candidate)
+	return nil
+}
+
+func (r *runtimeImpl[T]) GraphVersion() uint64 {
+	return r.graph.Load().version
+}
+
+// graphOf returns the version of the graph the invocation owning the configuration started with, the latest one otherwise.
+func (r *runtimeImpl[T]) graphOf(config g.InvokeConfig) *graphVersion[T] {
+	if inv, ok := r.invocations.Load(config.ThreadID); ok && inv.(*invocation).ctx == config.Context {
+		return inv.(*invocation).graph.(*graphVersion[T])
+	}
+	return r.graph.Load()
+}
@@ -0,0 +1,119 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// TestRuntime_GraphVersions tests that running invocations keep routing on the graph version they started with
+func TestRuntime_GraphVersions(t *testing.T) {
+	policy, _ := RouterPolicyImplFactory(AnyRoute[RuntimeTestState])
+	opts := func() *g.NodeOptions[RuntimeTestState] {
+		return &g.NodeOptions[RuntimeTestState]{RoutingPolicy: policy, Reducer: Replacer[RuntimeTestState]}
+	}
+
+	started := make(chan struct{}, 1)
+	release := make(chan struct{})
+	startNode, _ := NodeImplFactory(g.StartNode, "StartNode", nil, opts())
+	node1, _ := ContextNodeImplFactory(g.IntermediateNode, "Node1", func(ctx context.Context, userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		if userInput.Value == "slow" {
+			started <- struct{}{}
+			<-release
+		}
+		currentState.Value += "1"
+		return currentState, nil
+	}, opts())
+	node2, _ := NodeImplFactory(g.IntermediateNode, "Node2", func(userInput, currentState RuntimeTestState, notify g.NotifyPartialFn[RuntimeTestState]) (RuntimeTestState, error) {
+		currentState.Value += "2"
+		return currentState, nil
+	}, opts())
+	endNode, _ := NodeImplFactory(g.EndNode, "EndNode", nil, opts())
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[RuntimeTestState], 100)
+	runtime, err := RuntimeFactory(&mockRuntimeEdge{from: startNode, to: node1, role: g.StartEdge}, stateMonitorCh, &g.RuntimeOptions[RuntimeTestState]{})
+	if err != nil {
+		t.Fatalf("RuntimeFactory failed: %v", err)
+	}
+	defer runtime.Shutdown()
+
+	directEnd := &mockRuntimeEdge{from: node1, to: endNode, role: g.EndEdge}
+	runtime.AddEdge(directEnd)
+	initialVersion := runtime.GraphVersion()
+
+	slowThread := runtime.Invoke(RuntimeTestState{Value: "slow"})
+	<-started
+
+	info, _ := runtime.DescribeThread(slowThread)
+	if info.GraphVersion != initialVersion {
+		t.Errorf("Expected the running thread on version %d, got %d", initialVersion, info.GraphVersion)
+	}
+
+	if err := runtime.RemoveEdge(&mockRuntimeEdge{from: node1, to: endNode, role: g.EndEdge}); !errors.Is(err, g.ErrEdgeNotFound) {
+		t.Errorf("Expected ErrEdgeNotFound, got %v", err)
+	}
+	if runtime.GraphVersion() != initialVersion {
+		t.Errorf("Expected a failed removal to keep the version, got %d", runtime.GraphVersion())
+	}
+
+	runtime.AddEdge(&mockRuntimeEdge{from: node1, to: node2, role: g.IntermediateEdge}, &mockRuntimeEdge{from: node2, to: endNode, role: g.EndEdge})
+	if err := runtime.RemoveEdge(directEnd); err != nil {
+		t.Fatalf("RemoveEdge failed: %v", err)
+	}
+	if runtime.GraphVersion() != initialVersion+2 {
+		t.Errorf("Expected version %d, got %d", initialVersion+2, runtime.GraphVersion())
+	}
+	if err := runtime.Validate(); err != nil {
+		t.Errorf("Validate() failed on the new version: %v", err)
+	}
+
+	close(release)
+	entry := waitTerminalEntry(t, stateMonitorCh)
+	if entry.Error != nil || entry.ThreadID != slowThread || entry.NewState.Value != "1" {
+		t.Errorf("Expected the running thread to complete on its version, got %+v", entry)
+	}
+
+	runtime.Invoke(RuntimeTestState{Value: "fast"})
+	entry = waitTerminalEntry(t, stateMonitorCh)
+	if entry.Error != nil || entry.NewState.Value != "12" {
+		t.Errorf("Expected the new invocation to use the latest version, got %+v", entry)
+	}
+}
+
+// TestRuntime_GraphVersions_Validation tests that the validating reconfigurations publish the valid versions only
+func TestRuntime_GraphVersions_Validation(t *testing.T) {
+	node1 := testNode("Node1", countInput)
+	runtime := newTestRuntime(t, make(chan g.StateMonitorEntry[RuntimeTestState], 100), &g.RuntimeOptions[RuntimeTestState]{}, node1)
+	directEnd := runtime.Edges()[1]
+	endNode := directEnd.To()
+	version := runtime.GraphVersion()
+
+	node2 := testNode("Node2", countInput)
+	toNode2 := &mockRuntimeEdge{from: node1, to: node2, role: g.IntermediateEdge}
+	if err := runtime.AddEdgeValidated(toNode2); !errors.Is(err, g.ErrDeadEndNode) {
+		t.Errorf("Expected ErrDeadEndNode, got %v", err)
+	}
+	if runtime.GraphVersion() != version {
+		t.Errorf("Expected a rejected addition to keep the version, got %d", runtime.GraphVersion())
+	}
+
+	node2End := &mockRuntimeEdge{from: node2, to: endNode, role: g.EndEdge}
+	if err := runtime.AddEdgeValidated(toNode2, node2End); err != nil {
+		t.Fatalf("AddEdgeValidated failed: %v", err)
+	}
+	if runtime.GraphVersion() != version+1 {
+		t.Errorf("Expected version %d, got %d", version+1, runtime.GraphVersion())
+	}
+
+	if err := runtime.RemoveEdge(node2End); !errors.Is(err, g.ErrDeadEndNode) {
+		t.Errorf("Expected ErrDeadEndNode, got %v", err)
+	}
+	if runtime.GraphVersion() != version+1 {
+		t.Errorf("Expected a rejected removal to keep the version, got %d", runtime.GraphVersion())
+	}
+	if err := runtime.RemoveEdge(directEnd); err != nil {
+		t.Errorf("RemoveEdge failed: %v", err)
+	}
+}
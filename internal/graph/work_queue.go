@@ -0,0 +1,144 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// workQueueRetryDelay is the pause before reading the results again after a work queue error.
+const workQueueRetryDelay = time.Second
+
+// remoteCall is a node execution published to the work queue, waiting for its result.
+type remoteCall[T g.SharedState] struct {
+	node      g.Node[T]
+	userInput T
+	config    g.InvokeConfig
+}
+
+// isRemote reports whether the node is executed by the workers of the work queue.
+func (r *runtimeImpl[T]) isRemote(node g.Node[T]) bool {
+	if r.workQueue == nil || node.Role() != g.IntermediateNode {
+		return false
+	}
+	return len(r.remoteNodes) == 0 || slices.Contains(r.remoteNodes, node.Name())
+}
+
+// dispatchRemote persists the state of the thread for the workers and publishes the execution of the node.
+func (r *runtimeImpl[T]) dispatchRemote(node g.Node[T], userInput T, config g.InvokeConfig) {
+	threadID := config.ThreadID
+	fail := func(err error) {
+		r.NotifyStateChange(node, config, userInput, r.committedState(threadID), Replacer[T], err, false)
+	}
+
+	ctx, cancel := context.WithTimeout(r.ctx, r.settings.PersistenceJobTimeout)
+	defer cancel()
+
+	state := r.committedState(threadID)
+	if err := r.persistFn(ctx, threadID, state); err != nil {
+		fail(&g.PersistenceError{Node: node.Name(), ThreadID: threadID, Code: g.CodePersistenceFailed, Err: err})
+		return
+	}
+	r.lastPersisted.Store(threadID, state)
+	if meta, ok := r.threadMeta.Load(threadID); ok {
+		meta.(*threadMeta).persistedAt.Store(time.Now().UnixNano())
+	}
+
+	encodedInput, err := json.Marshal(userInput)
+	if err != nil {
+		fail(&g.NodeError{Node: node.Name(), ThreadID: threadID, Code: g.CodeDispatchFailed, Err: fmt.Errorf("cannot encode the user input: %w", err)})
+		return
+	}
+	task := g.NodeTask{
+		ID:         g.UUID(),
+		Instance:   r.instanceID,
+		ThreadID:   threadID,
+		Tenant:     r.threadTenant(threadID),
+		Node:       node.Name(),
+		UserInput:  encodedInput,
+		EnqueuedAt: time.Now(),
+	}
+	r.remoteCalls.Store(task.ID, &remoteCall[T]{node: node, userInput: userInput, config: config})
+	if err := r.workQueue.Enqueue(ctx, task); err != nil {
+		r.remoteCalls.Delete(task.ID)
+		fail(&g.NodeError{Node: node.Name(), ThreadID: threadID, Code: g.CodeDispatchFailed, Err: err})
+	}
+}
+
+func (r *runtimeImpl[T]) startRemoteResults() {
+	r.backgroundWorkers.Add(1)
+	go r.remoteResults()
+}
+
+// remoteResults notifies the results of the work queue as the outcomes of the nodes.
+func (r *runtimeImpl[T]) remoteResults() {
+	defer r.backgroundWorkers.Done()
+
+	for {
+		result, err := r.workQueue.Results(r.ctx, r.instanceID)
+		if err != nil {
+			if r.ctx.Err() != nil {
+				return
+			}
+			r.reportNonFatal("WorkQueue", "", err)
+			select {
+			case <-r.ctx.Done():
+				return
+			case <-time.After(workQueueRetryDelay):
+			}
+			continue
+		}
+		r.onRemoteResult(result)
+	}
+}
+
+func (r *runtimeImpl[T]) onRemoteResult(result g.NodeResult) {
+	call, ok := r.remoteCalls.LoadAndDelete(result.TaskID)
+	if !ok {
+		// The invocation was terminated meanwhile.
+		return
+	}
+	useCall := call.(*remoteCall[T])
+	threadID := useCall.config.ThreadID
+
+	if !result.Usage.IsZero() {
+		r.nodeStats.usage(result.Node, result.Usage)
+		if inv, ok := r.invocations.Load(threadID); ok && inv.(*invocation).ctx == useCall.config.Context {
+			inv.(*invocation).addUsage(result.Usage)
+		}
+	}
+
+	var newState T
+	var err error
+	if result.Error != "" {
+		code := result.Code
+		if code == "" {
+			code = g.CodeNodeFailed
+		}
+		err = &g.NodeError{Node: result.Node, ThreadID: threadID, Code: code, Err: errors.New(result.Error)}
+	} else if decodeErr := json.Unmarshal(result.State, &newState); decodeErr != nil {
+		err = &g.NodeError{Node: result.Node, ThreadID: threadID, Code: g.CodeNodeFailed, Err: fmt.Errorf("cannot decode the state: %w", decodeErr)}
+	}
+	if err != nil {
+		newState = r.committedState(threadID)
+	}
+	r.NotifyStateChange(useCall.node, useCall.config, useCall.userInput, newState, Replacer[T], err, false)
+}
+
+// forgetRemoteCalls discards the pending remote calls of the terminated invocation, their late results are ignored.
+func (r *runtimeImpl[T]) forgetRemoteCalls(threadID string, ctx context.Context) {
+	if r.workQueue == nil {
+		return
+	}
+	r.remoteCalls.Range(func(taskID, call any) bool {
+		if useCall := call.(*remoteCall[T]); useCall.config.ThreadID == threadID && useCall.config.Context == ctx {
+			r.remoteCalls.Delete(taskID)
+		}
+		return true
+	})
+}
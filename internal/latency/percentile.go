@@ -0,0 +1,16 @@
+// Package latency provides the latency percentiles shared by the runtime and the evaluation harness.
+package latency
+
+import (
+	"math"
+	"time"
+)
+
+// Percentile returns the nearest-rank percentile p, from 0 to 1, of the sorted durations; it returns zero if there are none.
+func Percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(float64(len(sorted))*p)) - 1
+	return sorted[min(max(rank, 0), len(sorted)-1)]
+}
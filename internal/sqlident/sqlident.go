@@ -0,0 +1,18 @@
+// Package sqlident provides the validation of the SQL identifiers shared by the SQL backed stores.
+package sqlident
+
+// Valid reports whether the name is a plain SQL identifier: letters, digits and underscores, not starting with a digit.
+func Valid(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		switch {
+		case c == '_', c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -19,6 +19,9 @@ import (
 // Example usage:
 //
 //	client := NewAIWClient("your-api-key", option.WithTimeout(30*time.Second))
+//
+//	limiter, _ := agent.NewProviderLimiter(agent.WithMaxInFlight(4))
+//	client := NewAIWClient("your-api-key", openai.WithLimiter(limiter))
 func NewAIWClient(
 	PAT string,
 	opts ...option.RequestOption,
@@ -0,0 +1,324 @@
+// Package audio provides the speech nodes of the voice agents.
+//
+// A Transcriber turns the audio clips into text, a Synthesizer turns the text into an audio clip;
+// the openai package backs both with the Whisper-compatible transcription and text-to-speech APIs.
+// NewSpeechToTextNode and NewTextToSpeechNode wrap them into graph nodes, so that a voice agent is
+// a graph: audio in → speech to text → chat → text to speech → audio out.
+//
+// Example:
+//
+//	transcriber, _ := openai.NewTranscriber(client, "gpt-4o-transcribe", audio.WithStreaming())
+//	synthesizer, _ := openai.NewSynthesizer(client, "gpt-4o-mini-tts", audio.WithVoice("coral"))
+//	stt, _ := audio.NewSpeechToTextNode("STT", transcriber)
+//	tts, _ := audio.NewTextToSpeechNode("TTS", synthesizer)
+//	runtime, _ := b.CreateRuntime(b.CreateStartEdge(stt), stateMonitorCh)
+//	runtime.AddEdge(b.CreateEdge(stt, chat), b.CreateEdge(chat, tts), b.CreateEndEdge(tts))
+//	runtime.Invoke(a.CreateConversation(a.CreateMultiModalMessage(a.User, a.Audio(wav, "audio/wav"))))
+package audio
+
+import (
+	"context"
+	"errors"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+)
+
+const (
+	// DefaultVoice is the default voice of the synthesized speech.
+	DefaultVoice = "alloy"
+	// DefaultSpeechFormat is the default encoding of the synthesized speech.
+	DefaultSpeechFormat = "mp3"
+	// MinSpeechSpeed is the minimum speed of the synthesized speech.
+	MinSpeechSpeed = 0.25
+	// MaxSpeechSpeed is the maximum speed of the synthesized speech.
+	MaxSpeechSpeed = 4.0
+)
+
+var (
+	// ErrTranscriberNil indicates that the provided transcriber is nil.
+	ErrTranscriberNil = errors.New("transcriber cannot be nil")
+	// ErrSynthesizerNil indicates that the provided synthesizer is nil.
+	ErrSynthesizerNil = errors.New("synthesizer cannot be nil")
+	// ErrInvalidVoice indicates that the provided voice is empty.
+	ErrInvalidVoice = errors.New("voice cannot be empty")
+	// ErrInvalidSpeechFormat indicates that the provided speech format is empty.
+	ErrInvalidSpeechFormat = errors.New("speech format cannot be empty")
+	// ErrInvalidSpeechSpeed indicates that the provided speech speed is out of range.
+	ErrInvalidSpeechSpeed = errors.New("speech speed must be between 0.25 and 4.0")
+)
+
+// PartialTranscriptFn receives the transcript of an audio clip as it grows.
+//
+// Parameters:
+//   - transcript: The text transcribed so far.
+type PartialTranscriptFn func(transcript string)
+
+// Transcriber turns speech into text.
+type Transcriber interface {
+	// Transcribe returns the transcript of the audio clip.
+	//
+	// Parameters:
+	//   - ctx: The context of the request.
+	//   - clip: The audio content part, see a.Audio.
+	//   - onPartial: Receives the partial transcripts, when streaming; it can be nil.
+	//
+	// Returns:
+	//   - The transcript.
+	//   - An error if the clip could not be transcribed.
+	Transcribe(ctx context.Context, clip a.ContentPart, onPartial PartialTranscriptFn) (string, error)
+}
+
+// Synthesizer turns text into speech.
+type Synthesizer interface {
+	// Synthesize returns the spoken text as an audio clip.
+	//
+	// Parameters:
+	//   - ctx: The context of the request.
+	//   - text: The text to speak.
+	//
+	// Returns:
+	//   - The audio content part, see a.Audio.
+	//   - An error if the speech could not be synthesized.
+	Synthesize(ctx context.Context, text string) (a.ContentPart, error)
+}
+
+// TranscriptionOptions defines the parameters of the transcription requests.
+type TranscriptionOptions struct {
+	// Language is the ISO-639-1 language of the speech, detected when empty.
+	Language string
+	// Prompt guides the style of the transcript or carries the vocabulary of the domain.
+	Prompt string
+	// Stream requests the partial transcripts, for the models supporting it.
+	Stream bool
+}
+
+// TranscriptionOption is a functional option for configuring the transcription requests.
+type TranscriptionOption interface {
+	// Apply applies the option to the TranscriptionOptions.
+	//
+	// Parameters:
+	//   - r: A pointer to TranscriptionOptions to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *TranscriptionOptions) error
+}
+
+// TranscriptionOptionFunc is a function type that implements the TranscriptionOption interface.
+type TranscriptionOptionFunc func(*TranscriptionOptions) error
+
+// Apply applies the TranscriptionOptionFunc to the given TranscriptionOptions.
+//
+// Parameters:
+//   - r: A pointer to TranscriptionOptions to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s TranscriptionOptionFunc) Apply(r *TranscriptionOptions) error { return s(r) }
+
+// CreateTranscriptionOptions creates the transcription options with the given options applied.
+//
+// Parameters:
+//   - opts: The transcription options.
+//
+// Returns:
+//   - The TranscriptionOptions.
+//   - An error if any option is invalid.
+//
+// Example:
+//
+//	options, err := audio.CreateTranscriptionOptions(audio.WithLanguage("it"))
+func CreateTranscriptionOptions(opts ...TranscriptionOption) (*TranscriptionOptions, error) {
+	rv := &TranscriptionOptions{}
+	for _, opt := range opts {
+		if err := opt.Apply(rv); err != nil {
+			return nil, err
+		}
+	}
+	return rv, nil
+}
+
+// WithLanguage sets the language of the speech, improving the accuracy and the latency of the transcription.
+//
+// Parameters:
+//   - language: The ISO-639-1 language, e.g. "en".
+//
+// Returns:
+//   - A TranscriptionOption that sets the language.
+func WithLanguage(language string) TranscriptionOption {
+	return TranscriptionOptionFunc(func(r *TranscriptionOptions) error {
+		r.Language = language
+		return nil
+	})
+}
+
+// WithTranscriptionPrompt sets the text guiding the transcription, e.g. the spelling of the domain terms.
+//
+// Parameters:
+//   - prompt: The prompt, in the language of the speech.
+//
+// Returns:
+//   - A TranscriptionOption that sets the prompt.
+func WithTranscriptionPrompt(prompt string) TranscriptionOption {
+	return TranscriptionOptionFunc(func(r *TranscriptionOptions) error {
+		r.Prompt = prompt
+		return nil
+	})
+}
+
+// WithStreaming requests the partial transcripts as the speech is transcribed.
+//
+// Returns:
+//   - A TranscriptionOption that enables the streaming.
+func WithStreaming() TranscriptionOption {
+	return TranscriptionOptionFunc(func(r *TranscriptionOptions) error {
+		r.Stream = true
+		return nil
+	})
+}
+
+// SpeechOptions defines the parameters of the speech synthesis requests.
+type SpeechOptions struct {
+	// Voice is the voice of the speech.
+	Voice string
+	// Format is the encoding of the speech, e.g. "mp3", "wav" or "opus".
+	Format string
+	// Speed is the speed of the speech, provider default when zero.
+	Speed float64
+	// Instructions control the tone of the speech, for the models supporting it.
+	Instructions string
+}
+
+// SpeechOption is a functional option for configuring the speech synthesis requests.
+type SpeechOption interface {
+	// Apply applies the option to the SpeechOptions.
+	//
+	// Parameters:
+	//   - r: A pointer to SpeechOptions to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *SpeechOptions) error
+}
+
+// SpeechOptionFunc is a function type that implements the SpeechOption interface.
+type SpeechOptionFunc func(*SpeechOptions) error
+
+// Apply applies the SpeechOptionFunc to the given SpeechOptions.
+//
+// Parameters:
+//   - r: A pointer to SpeechOptions to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s SpeechOptionFunc) Apply(r *SpeechOptions) error { return s(r) }
+
+// CreateSpeechOptions creates the speech options with the defaults and the given options applied.
+//
+// Parameters:
+//   - opts: The speech options.
+//
+// Returns:
+//   - The SpeechOptions.
+//   - An error if any option is invalid.
+//
+// Example:
+//
+//	options, err := audio.CreateSpeechOptions(audio.WithVoice("coral"))
+func CreateSpeechOptions(opts ...SpeechOption) (*SpeechOptions, error) {
+	rv := &SpeechOptions{
+		Voice:  DefaultVoice,
+		Format: DefaultSpeechFormat,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(rv); err != nil {
+			return nil, err
+		}
+	}
+	return rv, nil
+}
+
+// WithVoice sets the voice of the speech.
+//
+// Parameters:
+//   - voice: The voice, e.g. "alloy" or "coral".
+//
+// Returns:
+//   - A SpeechOption that sets the voice.
+func WithVoice(voice string) SpeechOption {
+	return SpeechOptionFunc(func(r *SpeechOptions) error {
+		if voice == "" {
+			return ErrInvalidVoice
+		}
+		r.Voice = voice
+		return nil
+	})
+}
+
+// WithSpeechFormat sets the encoding of the speech.
+//
+// Parameters:
+//   - format: The encoding, e.g. "mp3", "wav", "opus", "aac", "flac" or "pcm".
+//
+// Returns:
+//   - A SpeechOption that sets the encoding.
+func WithSpeechFormat(format string) SpeechOption {
+	return SpeechOptionFunc(func(r *SpeechOptions) error {
+		if format == "" {
+			return ErrInvalidSpeechFormat
+		}
+		r.Format = format
+		return nil
+	})
+}
+
+// WithSpeed sets the speed of the speech.
+//
+// Parameters:
+//   - speed: The speed, from 0.25 to 4.0; 1.0 is the natural speed.
+//
+// Returns:
+//   - A SpeechOption that sets the speed.
+func WithSpeed(speed float64) SpeechOption {
+	return SpeechOptionFunc(func(r *SpeechOptions) error {
+		if speed < MinSpeechSpeed || speed > MaxSpeechSpeed {
+			return ErrInvalidSpeechSpeed
+		}
+		r.Speed = speed
+		return nil
+	})
+}
+
+// WithSpeechInstructions sets how the text is spoken, e.g. its tone or its accent.
+//
+// Parameters:
+//   - instructions: The instructions.
+//
+// Returns:
+//   - A SpeechOption that sets the instructions.
+func WithSpeechInstructions(instructions string) SpeechOption {
+	return SpeechOptionFunc(func(r *SpeechOptions) error {
+		r.Instructions = instructions
+		return nil
+	})
+}
+
+// MIMEType returns the media type of a speech encoding.
+//
+// Parameters:
+//   - format: The encoding, e.g. "mp3".
+//
+// Returns:
+//   - The media type, e.g. "audio/mpeg".
+func MIMEType(format string) string {
+	switch format {
+	case "mp3":
+		return "audio/mpeg"
+	case "opus":
+		return "audio/ogg"
+	case "pcm":
+		return "audio/pcm"
+	default:
+		return "audio/" + format
+	}
+}
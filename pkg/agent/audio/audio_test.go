@@ -0,0 +1,55 @@
+package audio_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/morphy76/ggraph/pkg/agent/audio"
+)
+
+func TestCreateSpeechOptions(t *testing.T) {
+	opts, err := audio.CreateSpeechOptions()
+	if err != nil {
+		t.Fatalf("CreateSpeechOptions failed: %v", err)
+	}
+	if opts.Voice != audio.DefaultVoice || opts.Format != audio.DefaultSpeechFormat || opts.Speed != 0 {
+		t.Errorf("Unexpected defaults: %+v", opts)
+	}
+
+	tests := []struct {
+		name string
+		opt  audio.SpeechOption
+		want error
+	}{
+		{"empty voice", audio.WithVoice(""), audio.ErrInvalidVoice},
+		{"empty format", audio.WithSpeechFormat(""), audio.ErrInvalidSpeechFormat},
+		{"too slow", audio.WithSpeed(0.1), audio.ErrInvalidSpeechSpeed},
+		{"too fast", audio.WithSpeed(4.5), audio.ErrInvalidSpeechSpeed},
+		{"valid", audio.WithSpeed(1.5), nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := audio.CreateSpeechOptions(tt.opt); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestCreateTranscriptionOptions(t *testing.T) {
+	opts, err := audio.CreateTranscriptionOptions(audio.WithLanguage("it"), audio.WithTranscriptionPrompt("ggraph"), audio.WithStreaming())
+	if err != nil {
+		t.Fatalf("CreateTranscriptionOptions failed: %v", err)
+	}
+	if opts.Language != "it" || opts.Prompt != "ggraph" || !opts.Stream {
+		t.Errorf("Unexpected options: %+v", opts)
+	}
+}
+
+func TestMIMEType(t *testing.T) {
+	for format, want := range map[string]string{"mp3": "audio/mpeg", "wav": "audio/wav", "opus": "audio/ogg", "flac": "audio/flac"} {
+		if got := audio.MIMEType(format); got != want {
+			t.Errorf("MIMEType(%q) = %q, want %q", format, got, want)
+		}
+	}
+}
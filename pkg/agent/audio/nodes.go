@@ -0,0 +1,185 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	b "github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// TranscribedMetadataKey marks, in the message metadata, the user messages transcribed from speech.
+const TranscribedMetadataKey = "ggraph.audio.transcribed"
+
+// NewSpeechToTextNode creates a node transcribing the audio clips of the latest user message.
+//
+// The audio parts of the latest user message, either from the user input or from the conversation,
+// are replaced by their transcript and the message is merged into the state, keeping its ID: the
+// downstream chat nodes see the transcript in place of the user input, see a.PromptMessages. The
+// partial transcripts are notified as the conversation with the growing user message; when the
+// latest user message carries no audio the conversation is left unchanged.
+//
+// Parameters:
+//   - name: The unique name for the node.
+//   - transcriber: The speech-to-text provider.
+//   - opts: Optional node options.
+//
+// Returns:
+//   - The speech-to-text node.
+//   - An error if the node could not be created.
+//
+// Example:
+//
+//	transcriber, _ := openai.NewTranscriber(client, "whisper-1")
+//	stt, err := audio.NewSpeechToTextNode("STT", transcriber)
+//	runtime.AddEdge(b.CreateEdge(stt, chat))
+func NewSpeechToTextNode(name string, transcriber Transcriber, opts ...g.NodeOption[a.Conversation]) (g.Node[a.Conversation], error) {
+	if transcriber == nil {
+		return nil, fmt.Errorf("speech-to-text node creation error for name %s: %w", name, ErrTranscriberNil)
+	}
+	return b.NewContextNode(name, speechToTextFn(transcriber), opts...)
+}
+
+// NewTextToSpeechNode creates a node speaking the latest assistant message.
+//
+// The synthesized clip is appended to the parts of the latest assistant message, after its text,
+// so that the message keeps its Content for the next turns; the messages already carrying an audio
+// part and the empty ones, e.g. the tool calls, are left unchanged.
+//
+// Parameters:
+//   - name: The unique name for the node.
+//   - synthesizer: The text-to-speech provider.
+//   - opts: Optional node options.
+//
+// Returns:
+//   - The text-to-speech node.
+//   - An error if the node could not be created.
+//
+// Example:
+//
+//	synthesizer, _ := openai.NewSynthesizer(client, "tts-1", audio.WithVoice("nova"))
+//	tts, err := audio.NewTextToSpeechNode("TTS", synthesizer)
+//	runtime.AddEdge(b.CreateEdge(chat, tts), b.CreateEndEdge(tts))
+func NewTextToSpeechNode(name string, synthesizer Synthesizer, opts ...g.NodeOption[a.Conversation]) (g.Node[a.Conversation], error) {
+	if synthesizer == nil {
+		return nil, fmt.Errorf("text-to-speech node creation error for name %s: %w", name, ErrSynthesizerNil)
+	}
+	return b.NewContextNode(name, textToSpeechFn(synthesizer), opts...)
+}
+
+func speechToTextFn(transcriber Transcriber) g.ContextNodeFn[a.Conversation] {
+	return func(ctx context.Context, userInput, currentState a.Conversation, notify g.NotifyPartialFn[a.Conversation]) (a.Conversation, error) {
+		messages := a.PromptMessages(userInput, currentState)
+
+		lastUser := -1
+		for i := len(messages) - 1; i >= 0; i-- {
+			if messages[i].Role == a.User {
+				lastUser = i
+				break
+			}
+		}
+		if lastUser < 0 || !hasAudio(messages[lastUser]) {
+			return currentState, nil
+		}
+
+		message := messages[lastUser]
+		parts := make([]a.ContentPart, 0, len(message.Parts))
+		for _, part := range message.Parts {
+			if part.Kind != a.AudioPart {
+				parts = append(parts, part.Clone())
+				continue
+			}
+
+			transcript, err := transcriber.Transcribe(ctx, part, func(partial string) {
+				partialState := currentState
+				partialState.Messages = withMessage(messages, lastUser, transcribed(message, append(parts, a.Text(partial))))
+				notify(partialState)
+			})
+			if err != nil {
+				return currentState, fmt.Errorf("cannot transcribe the user message: %w", err)
+			}
+			parts = append(parts, a.Text(strings.TrimSpace(transcript)))
+		}
+
+		useState := currentState
+		useState.Messages = withMessage(messages, lastUser, transcribed(message, parts))
+		return useState, nil
+	}
+}
+
+func textToSpeechFn(synthesizer Synthesizer) g.ContextNodeFn[a.Conversation] {
+	return func(ctx context.Context, userInput, currentState a.Conversation, notify g.NotifyPartialFn[a.Conversation]) (a.Conversation, error) {
+		lastAssistant := -1
+		for i := len(currentState.Messages) - 1; i >= 0; i-- {
+			if currentState.Messages[i].Role == a.Assistant {
+				lastAssistant = i
+				break
+			}
+		}
+		if lastAssistant < 0 {
+			return currentState, nil
+		}
+		message := currentState.Messages[lastAssistant].Clone()
+		if message.Content == "" || hasAudio(message) {
+			return currentState, nil
+		}
+
+		clip, err := synthesizer.Synthesize(ctx, message.Content)
+		if err != nil {
+			return currentState, fmt.Errorf("cannot synthesize the assistant message: %w", err)
+		}
+		if len(message.Parts) == 0 {
+			message.Parts = []a.ContentPart{a.Text(message.Content)}
+		}
+		message.Parts = append(message.Parts, clip)
+
+		useState := currentState
+		useState.Messages = withMessage(currentState.Messages, lastAssistant, message)
+		return useState, nil
+	}
+}
+
+// transcribed returns the message made of the given parts, text-only when no other media is left.
+func transcribed(message a.Message, parts []a.ContentPart) a.Message {
+	var texts []string
+	multiModal := false
+	for _, part := range parts {
+		if part.Kind == a.TextPart {
+			texts = append(texts, part.Text)
+		} else {
+			multiModal = true
+		}
+	}
+
+	rv := message
+	rv.Content = strings.Join(texts, "\n")
+	rv.Parts = nil
+	if multiModal {
+		rv.Parts = append([]a.ContentPart{}, parts...)
+	}
+	rv.Metadata = maps.Clone(message.Metadata)
+	if rv.Metadata == nil {
+		rv.Metadata = make(map[string]string, 1)
+	}
+	rv.Metadata[TranscribedMetadataKey] = "true"
+	return rv
+}
+
+// withMessage returns a copy of the messages with the one at the given index replaced.
+func withMessage(messages []a.Message, at int, message a.Message) []a.Message {
+	rv := append([]a.Message{}, messages...)
+	rv[at] = message
+	return rv
+}
+
+func hasAudio(message a.Message) bool {
+	for _, part := range message.Parts {
+		if part.Kind == a.AudioPart {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package audio_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/agent/audio"
+	b "github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+type fakeTranscriber struct {
+	partials []string
+	err      error
+}
+
+func (f *fakeTranscriber) Transcribe(ctx context.Context, clip a.ContentPart, onPartial audio.PartialTranscriptFn) (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	for _, partial := range f.partials {
+		onPartial(partial)
+	}
+	return string(clip.Data), nil
+}
+
+type fakeSynthesizer struct {
+	texts []string
+}
+
+func (f *fakeSynthesizer) Synthesize(ctx context.Context, text string) (a.ContentPart, error) {
+	f.texts = append(f.texts, text)
+	return a.Audio([]byte("speech"), "audio/mpeg"), nil
+}
+
+func echoNode(t *testing.T) g.Node[a.Conversation] {
+	node, err := b.NewNode("Chat", func(userInput, currentState a.Conversation, notify g.NotifyPartialFn[a.Conversation]) (a.Conversation, error) {
+		messages := a.PromptMessages(userInput, currentState)
+		currentState.Messages = append(messages, a.CreateMessage(a.Assistant, "You said: "+messages[len(messages)-1].Content))
+		return currentState, nil
+	})
+	if err != nil {
+		t.Fatalf("NewNode failed: %v", err)
+	}
+	return node
+}
+
+func runVoiceGraph(t *testing.T, transcriber audio.Transcriber, synthesizer audio.Synthesizer, userInput a.Conversation) ([]g.StateMonitorEntry[a.Conversation], error) {
+	stt, err := audio.NewSpeechToTextNode("STT", transcriber)
+	if err != nil {
+		t.Fatalf("NewSpeechToTextNode failed: %v", err)
+	}
+	tts, err := audio.NewTextToSpeechNode("TTS", synthesizer)
+	if err != nil {
+		t.Fatalf("NewTextToSpeechNode failed: %v", err)
+	}
+	chat := echoNode(t)
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[a.Conversation], 20)
+	runtime, err := b.CreateRuntime(b.CreateStartEdge(stt), stateMonitorCh)
+	if err != nil {
+		t.Fatalf("CreateRuntime failed: %v", err)
+	}
+	runtime.AddEdge(b.CreateEdge(stt, chat), b.CreateEdge(chat, tts), b.CreateEndEdge(tts))
+	defer runtime.Shutdown()
+
+	runtime.Invoke(userInput)
+
+	var entries []g.StateMonitorEntry[a.Conversation]
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case entry := <-stateMonitorCh:
+			if entry.Error != nil {
+				return entries, entry.Error
+			}
+			entries = append(entries, entry)
+			if !entry.Running {
+				return entries, nil
+			}
+		case <-timeout:
+			t.Fatal("Timeout waiting for the graph completion")
+		}
+	}
+}
+
+func TestVoiceGraph(t *testing.T) {
+	transcriber := &fakeTranscriber{partials: []string{"Hel", "Hello"}}
+	synthesizer := &fakeSynthesizer{}
+	voice := a.CreateMultiModalMessage(a.User, a.Audio([]byte("Hello there"), "audio/wav"))
+
+	entries, err := runVoiceGraph(t, transcriber, synthesizer, a.CreateConversation(voice))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var partials []string
+	for _, entry := range entries {
+		if entry.Partial && entry.Node == "STT" {
+			partials = append(partials, entry.NewState.Messages[0].Content)
+		}
+	}
+	if len(partials) != 2 || partials[0] != "Hel" || partials[1] != "Hello" {
+		t.Errorf("Expected the partial transcripts, got %v", partials)
+	}
+
+	final := entries[len(entries)-1].NewState
+	if len(final.Messages) != 2 {
+		t.Fatalf("Expected the transcribed user message and the answer, got %+v", final.Messages)
+	}
+	user, answer := final.Messages[0], final.Messages[1]
+	if user.ID != voice.ID || user.Content != "Hello there" || user.Parts != nil {
+		t.Errorf("Expected the audio to be replaced by its transcript, got %+v", user)
+	}
+	if user.Metadata[audio.TranscribedMetadataKey] == "" {
+		t.Error("Expected the user message to be marked as transcribed")
+	}
+	if answer.Content != "You said: Hello there" {
+		t.Errorf("Expected the chat to see the transcript, got %q", answer.Content)
+	}
+	if len(synthesizer.texts) != 1 || synthesizer.texts[0] != "You said: Hello there" {
+		t.Errorf("Expected the answer to be synthesized, got %v", synthesizer.texts)
+	}
+	if len(answer.Parts) != 2 || answer.Parts[0].Kind != a.TextPart || answer.Parts[1].Kind != a.AudioPart {
+		t.Errorf("Expected the text and the speech parts, got %+v", answer.Parts)
+	}
+}
+
+func TestVoiceGraph_TextInput(t *testing.T) {
+	transcriber := &fakeTranscriber{err: errors.New("unexpected")}
+	entries, err := runVoiceGraph(t, transcriber, &fakeSynthesizer{}, a.CreateConversation(a.CreateMessage(a.User, "typed")))
+	if err != nil {
+		t.Fatalf("Expected the text input not to be transcribed, got %v", err)
+	}
+	final := entries[len(entries)-1].NewState
+	if len(final.Messages) != 2 || final.Messages[0].Content != "typed" {
+		t.Errorf("Expected the text input to pass through, got %+v", final.Messages)
+	}
+}
+
+func TestSpeechToTextNode_Errors(t *testing.T) {
+	if _, err := audio.NewSpeechToTextNode("STT", nil); !errors.Is(err, audio.ErrTranscriberNil) {
+		t.Errorf("Expected ErrTranscriberNil, got %v", err)
+	}
+	if _, err := audio.NewTextToSpeechNode("TTS", nil); !errors.Is(err, audio.ErrSynthesizerNil) {
+		t.Errorf("Expected ErrSynthesizerNil, got %v", err)
+	}
+
+	failure := errors.New("transcription down")
+	voice := a.CreateMultiModalMessage(a.User, a.Audio([]byte("Hi"), "audio/wav"))
+	if _, err := runVoiceGraph(t, &fakeTranscriber{err: failure}, &fakeSynthesizer{}, a.CreateConversation(voice)); !errors.Is(err, failure) {
+		t.Errorf("Expected the transcription error, got %v", err)
+	}
+}
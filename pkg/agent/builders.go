@@ -56,16 +56,19 @@ func CreateCompletionOptions(
 //   - content: The content of the message.
 //
 // Returns:
-//   - An instance of Message with the current timestamp.
+//   - An instance of Message with a new identifier and the current timestamp.
 //
 // Example usage:
 //
 //	msg := CreateMessage(User, "Hello, how can I assist you?")
 func CreateMessage(role MessageRole, content string) Message {
+	now := time.Now()
 	return Message{
-		Ts:      time.Now(),
-		Role:    role,
-		Content: content,
+		ID:        NewMessageID(),
+		Ts:        now,
+		CreatedAt: now,
+		Role:      role,
+		Content:   content,
 	}
 }
 
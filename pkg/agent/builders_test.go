@@ -229,6 +229,12 @@ func TestCreateMessage(t *testing.T) {
 			if got.Ts.Before(beforeTime) {
 				t.Errorf("CreateMessage() Ts should be after test start time")
 			}
+			if got.ID == "" {
+				t.Errorf("CreateMessage() ID should be set")
+			}
+			if !got.CreatedAt.Equal(got.Ts) {
+				t.Errorf("CreateMessage() CreatedAt = %v, want %v", got.CreatedAt, got.Ts)
+			}
 		})
 	}
 }
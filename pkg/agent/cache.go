@@ -0,0 +1,252 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	ic "github.com/morphy76/ggraph/internal/agent/cache"
+	t "github.com/morphy76/ggraph/pkg/agent/tool"
+)
+
+var (
+	// ErrResponseCacheNil indicates that no response cache has been provided.
+	ErrResponseCacheNil = errors.New("response cache cannot be nil")
+	// ErrInvalidCacheTTL indicates that the time to live of the cached responses is negative.
+	ErrInvalidCacheTTL = errors.New("cache ttl cannot be negative")
+	// ErrInvalidCacheCapacity indicates that the capacity of an in-memory cache is not positive.
+	ErrInvalidCacheCapacity = errors.New("cache capacity must be at least 1")
+)
+
+// ResponseCache stores the model responses, keyed by a hash of the request.
+//
+// Entries are opaque bytes, so that any key-value store can back the cache; see NewLRUCache
+// and the cache/redis package.
+type ResponseCache interface {
+	// Get returns the cached response.
+	//
+	// Parameters:
+	//   - ctx: The context of the request.
+	//   - key: The request hash.
+	//
+	// Returns:
+	//   - The cached response.
+	//   - false if the response is not cached or expired.
+	//   - An error if the cache could not be read.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores a response.
+	//
+	// Parameters:
+	//   - ctx: The context of the request.
+	//   - key: The request hash.
+	//   - value: The response.
+	//   - ttl: The time to live of the entry, 0 for no expiration.
+	//
+	// Returns:
+	//   - An error if the response could not be stored.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// NewLRUCache creates an in-memory ResponseCache evicting the least recently used entries.
+//
+// Parameters:
+//   - capacity: The maximum number of cached responses.
+//
+// Returns:
+//   - The ResponseCache.
+//   - An error if capacity is not positive.
+//
+// Example:
+//
+//	cache, err := agent.NewLRUCache(1000)
+//	option := agent.WithResponseCache(cache, time.Hour)
+func NewLRUCache(capacity int) (ResponseCache, error) {
+	if capacity < 1 {
+		return nil, ErrInvalidCacheCapacity
+	}
+	return ic.NewLRU(capacity), nil
+}
+
+// WithResponseCache makes the nodes consult the cache before calling the provider.
+//
+// The cache is keyed by the model, the messages or prompt and the generation options: it pays off
+// with deterministic requests, e.g. temperature 0 evaluation graphs.
+//
+// Parameters:
+//   - cache: The response cache.
+//   - ttl: The time to live of the cached responses, 0 for no expiration.
+//
+// Returns:
+//   - A ModelOption that sets the response cache.
+//
+// Example usage:
+//
+//	option := WithResponseCache(cache, 24*time.Hour)
+func WithResponseCache(cache ResponseCache, ttl time.Duration) ModelOption {
+	return ModelOptionFunc(func(r *ModelOptions) error {
+		if cache == nil {
+			return ErrResponseCacheNil
+		}
+		if ttl < 0 {
+			return ErrInvalidCacheTTL
+		}
+		r.Cache = cache
+		r.CacheTTL = ttl
+		return nil
+	})
+}
+
+// WithCacheBypass skips the cache lookup, the fresh response still refreshes the cache.
+//
+// Returns:
+//   - A ModelOption that sets the cache bypass flag.
+//
+// Example usage:
+//
+//	option := WithCacheBypass()
+func WithCacheBypass() ModelOption {
+	return ModelOptionFunc(func(r *ModelOptions) error {
+		r.CacheBypass = true
+		return nil
+	})
+}
+
+type cacheKeyMessage struct {
+	Role      MessageRole
+	Name      string `json:",omitempty"`
+	Content   string
+	ToolCalls []t.FnCall    `json:",omitempty"`
+	Parts     []ContentPart `json:",omitempty"`
+}
+
+type cacheKeyTool struct {
+	Name   string
+	Args   []t.Arg
+	Prompt string
+}
+
+type cacheKeyPayload struct {
+	Kind                string
+	Model               string
+	Prompt              string            `json:",omitempty"`
+	Messages            []cacheKeyMessage `json:",omitempty"`
+	BestOf              *int64            `json:",omitempty"`
+	Echo                *bool             `json:",omitempty"`
+	FrequencyPenalty    *float64          `json:",omitempty"`
+	Logprobs            *int64            `json:",omitempty"`
+	MaxCompletionTokens *int64            `json:",omitempty"`
+	MaxTokens           *int64            `json:",omitempty"`
+	N                   *int64            `json:",omitempty"`
+	PresencePenalty     *float64          `json:",omitempty"`
+	Seed                *int64            `json:",omitempty"`
+	Temperature         *float64          `json:",omitempty"`
+	TopP                *float64          `json:",omitempty"`
+	User                *string           `json:",omitempty"`
+	Tools               []cacheKeyTool    `json:",omitempty"`
+	ResponseFormat      string            `json:",omitempty"`
+	ResponseSchema      map[string]any    `json:",omitempty"`
+}
+
+// CacheKey returns the hash identifying a request in the response cache.
+//
+// Message timestamps are ignored, the tools are identified by their name, arguments and prompt.
+//
+// Parameters:
+//   - kind: The kind of request, e.g. "chat" or "completion", to separate otherwise equal requests.
+//   - opts: The request options, with the messages already compacted.
+//
+// Returns:
+//   - The hex encoded SHA-256 of the request.
+//   - An error if the request cannot be encoded.
+func CacheKey(kind string, opts *ModelOptions) (string, error) {
+	payload := cacheKeyPayload{
+		Kind:                kind,
+		Model:               opts.Model,
+		Prompt:              opts.Prompt,
+		BestOf:              opts.BestOf,
+		Echo:                opts.Echo,
+		FrequencyPenalty:    opts.FrequencyPenalty,
+		Logprobs:            opts.Logprobs,
+		MaxCompletionTokens: opts.MaxCompletionTokens,
+		MaxTokens:           opts.MaxTokens,
+		N:                   opts.N,
+		PresencePenalty:     opts.PresencePenalty,
+		Seed:                opts.Seed,
+		Temperature:         opts.Temperature,
+		TopP:                opts.TopP,
+		User:                opts.User,
+	}
+	for _, message := range opts.Messages {
+		payload.Messages = append(payload.Messages, cacheKeyMessage{Role: message.Role, Name: message.Name, Content: message.Content, ToolCalls: message.ToolCalls, Parts: message.Parts})
+	}
+	for _, tool := range opts.Tools {
+		payload.Tools = append(payload.Tools, cacheKeyTool{Name: tool.Name, Args: tool.Args, Prompt: tool.BuildToolPrompt()})
+	}
+	if opts.ResponseFormat != nil {
+		payload.ResponseFormat = opts.ResponseFormat.Name
+		payload.ResponseSchema = opts.ResponseFormat.Schema
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return "", fmt.Errorf("cannot compute the cache key: %w", err)
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// CachedCall returns the cached response of the request, or calls the provider and caches its response.
+//
+// Without a cache in the options, call is invoked directly. Cache failures never fail the request:
+// an unreadable or undecodable entry is treated as a miss and a failed write is ignored.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - kind: The kind of request, see CacheKey.
+//   - opts: The request options.
+//   - call: The provider request.
+//
+// Returns:
+//   - The response.
+//   - true if the response comes from the cache.
+//   - An error if the provider request fails.
+//
+// Example:
+//
+//	answer, hit, err := agent.CachedCall(ctx, "chat", opts, func(ctx context.Context) (agent.Message, error) {
+//	    return callTheModel(ctx, opts)
+//	})
+func CachedCall[R any](ctx context.Context, kind string, opts *ModelOptions, call func(ctx context.Context) (R, error)) (R, bool, error) {
+	if opts == nil || opts.Cache == nil {
+		rv, err := call(ctx)
+		return rv, false, err
+	}
+
+	key, err := CacheKey(kind, opts)
+	if err != nil {
+		rv, err := call(ctx)
+		return rv, false, err
+	}
+
+	if !opts.CacheBypass {
+		if cached, ok, err := opts.Cache.Get(ctx, key); err == nil && ok {
+			var rv R
+			if json.Unmarshal(cached, &rv) == nil {
+				return rv, true, nil
+			}
+		}
+	}
+
+	rv, err := call(ctx)
+	if err != nil {
+		return rv, false, err
+	}
+	if encoded, err := json.Marshal(rv); err == nil {
+		_ = opts.Cache.Set(ctx, key, encoded, opts.CacheTTL)
+	}
+	return rv, false, nil
+}
@@ -0,0 +1,91 @@
+// Package redis provides an agent.ResponseCache backed by Redis.
+package redis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+)
+
+var _ a.ResponseCache = (*Cache)(nil)
+
+// Cache stores the model responses in Redis, the expiration is delegated to the Redis TTL.
+type Cache struct {
+	client goredis.UniversalClient
+	prefix string
+}
+
+// NewCache creates a ResponseCache on the given Redis client.
+//
+// Parameters:
+//   - client: The Redis client, standalone, sentinel or cluster.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - The cache.
+//   - An error if the client is nil or an option is invalid.
+//
+// Example:
+//
+//	client := goredis.NewClient(&goredis.Options{Addr: "localhost:6379"})
+//	cache, err := redis.NewCache(client, redis.WithKeyPrefix("myapp:llm:"))
+//	option := agent.WithResponseCache(cache, time.Hour)
+func NewCache(client goredis.UniversalClient, opts ...Option) (*Cache, error) {
+	if client == nil {
+		return nil, fmt.Errorf("redis cache creation failed: %w", ErrClientNil)
+	}
+
+	useOpts := &Options{
+		KeyPrefix: DefaultKeyPrefix,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("redis cache creation failed: %w", err)
+		}
+	}
+
+	return &Cache{client: client, prefix: useOpts.KeyPrefix}, nil
+}
+
+// Get returns the cached response.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - key: The request hash.
+//
+// Returns:
+//   - The cached response.
+//   - false if the response is not cached or expired.
+//   - An error if Redis could not be read.
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	rv, err := c.client.Get(ctx, c.prefix+key).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("cannot read the cached response: %w", err)
+	}
+	return rv, true, nil
+}
+
+// Set stores a response.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - key: The request hash.
+//   - value: The response.
+//   - ttl: The time to live of the entry, 0 for no expiration.
+//
+// Returns:
+//   - An error if Redis could not be written.
+func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := c.client.Set(ctx, c.prefix+key, value, ttl).Err(); err != nil {
+		return fmt.Errorf("cannot cache the response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/morphy76/ggraph/pkg/agent/cache/redis"
+)
+
+func TestNewCache_Errors(t *testing.T) {
+	if _, err := redis.NewCache(nil); !errors.Is(err, redis.ErrClientNil) {
+		t.Errorf("Expected ErrClientNil, got %v", err)
+	}
+
+	client := goredis.NewClient(&goredis.Options{Addr: "localhost:0"})
+	defer client.Close()
+	if _, err := redis.NewCache(client, redis.WithKeyPrefix("")); !errors.Is(err, redis.ErrInvalidKeyPrefix) {
+		t.Errorf("Expected ErrInvalidKeyPrefix, got %v", err)
+	}
+}
+
+func TestCache(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: server.Addr(), MaxRetries: -1})
+	defer client.Close()
+
+	cache, err := redis.NewCache(client, redis.WithKeyPrefix("test:"))
+	if err != nil {
+		t.Fatalf("NewCache failed: %v", err)
+	}
+	ctx := context.Background()
+
+	if _, ok, err := cache.Get(ctx, "missing"); ok || err != nil {
+		t.Errorf("Expected a miss, got %v %v", ok, err)
+	}
+
+	if err := cache.Set(ctx, "key", []byte("value"), time.Minute); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if !server.Exists("test:key") {
+		t.Error("Expected the key to be prefixed")
+	}
+	got, ok, err := cache.Get(ctx, "key")
+	if err != nil || !ok || string(got) != "value" {
+		t.Errorf("Expected a hit, got %q %v %v", got, ok, err)
+	}
+
+	server.FastForward(2 * time.Minute)
+	if _, ok, _ := cache.Get(ctx, "key"); ok {
+		t.Error("Expected the entry to expire")
+	}
+
+	server.Close()
+	if _, _, err := cache.Get(ctx, "key"); err == nil {
+		t.Error("Expected an error when Redis is down")
+	}
+}
@@ -0,0 +1,62 @@
+package redis
+
+import "errors"
+
+const (
+	// DefaultKeyPrefix is the default prefix of the keys holding the cached responses.
+	DefaultKeyPrefix = "ggraph:llm:"
+)
+
+var (
+	// ErrClientNil indicates that the provided Redis client is nil.
+	ErrClientNil = errors.New("redis client cannot be nil")
+	// ErrInvalidKeyPrefix indicates that the provided key prefix is empty.
+	ErrInvalidKeyPrefix = errors.New("key prefix cannot be empty")
+)
+
+// Options holds the configuration for the Redis ResponseCache implementation.
+type Options struct {
+	// KeyPrefix is prepended to the request hashes, to share a Redis database with other applications.
+	KeyPrefix string
+}
+
+// Option is a functional option for configuring the Redis ResponseCache implementation.
+type Option interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - r: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *Options) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc func(*Options) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - r: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc) Apply(r *Options) error { return s(r) }
+
+// WithKeyPrefix sets the prefix of the keys holding the cached responses.
+//
+// Parameters:
+//   - prefix: The key prefix.
+//
+// Returns:
+//   - An Option that sets the key prefix.
+func WithKeyPrefix(prefix string) Option {
+	return OptionFunc(func(r *Options) error {
+		if prefix == "" {
+			return ErrInvalidKeyPrefix
+		}
+		r.KeyPrefix = prefix
+		return nil
+	})
+}
@@ -0,0 +1,87 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCacheKey(t *testing.T) {
+	first := CreateMessage(User, "hello")
+	second := first
+	second.Ts = first.Ts.Add(time.Hour)
+
+	opts, _ := CreateConversationOptions("m", []Message{first}, WithTemperature(0))
+	same, _ := CreateConversationOptions("m", []Message{second}, WithTemperature(0))
+	hotter, _ := CreateConversationOptions("m", []Message{first}, WithTemperature(1))
+
+	key, err := CacheKey("chat", opts)
+	if err != nil {
+		t.Fatalf("CacheKey failed: %v", err)
+	}
+	if sameKey, _ := CacheKey("chat", same); sameKey != key {
+		t.Error("Expected the message timestamps to be ignored")
+	}
+	if hotterKey, _ := CacheKey("chat", hotter); hotterKey == key {
+		t.Error("Expected the options to change the key")
+	}
+	if otherKind, _ := CacheKey("completion", opts); otherKind == key {
+		t.Error("Expected the kind to change the key")
+	}
+}
+
+func TestCachedCall(t *testing.T) {
+	cache, err := NewLRUCache(10)
+	if err != nil {
+		t.Fatalf("NewLRUCache failed: %v", err)
+	}
+
+	calls := 0
+	call := func(ctx context.Context) (Message, error) {
+		calls++
+		return CreateMessage(Assistant, "answer"), nil
+	}
+	messages := []Message{CreateMessage(User, "question")}
+	opts, _ := CreateConversationOptions("m", messages, WithResponseCache(cache, time.Minute))
+
+	got, hit, err := CachedCall(context.Background(), "chat", opts, call)
+	if err != nil || hit || got.Content != "answer" {
+		t.Fatalf("Expected a miss, got %+v %v %v", got, hit, err)
+	}
+	got, hit, _ = CachedCall(context.Background(), "chat", opts, call)
+	if !hit || got.Content != "answer" || calls != 1 {
+		t.Errorf("Expected a hit, got %+v %v after %d calls", got, hit, calls)
+	}
+
+	bypass, _ := CreateConversationOptions("m", messages, WithResponseCache(cache, time.Minute), WithCacheBypass())
+	if _, hit, _ := CachedCall(context.Background(), "chat", bypass, call); hit || calls != 2 {
+		t.Errorf("Expected the bypass to call the provider, got hit %v after %d calls", hit, calls)
+	}
+
+	failure := errors.New("provider down")
+	other, _ := CreateConversationOptions("m", []Message{CreateMessage(User, "other")}, WithResponseCache(cache, 0))
+	if _, _, err := CachedCall(context.Background(), "chat", other, func(ctx context.Context) (Message, error) { return Message{}, failure }); !errors.Is(err, failure) {
+		t.Errorf("Expected the provider error, got %v", err)
+	}
+	if _, hit, _ := CachedCall(context.Background(), "chat", other, call); hit {
+		t.Error("Expected failures not to be cached")
+	}
+
+	if _, hit, _ := CachedCall(context.Background(), "chat", &ModelOptions{}, call); hit {
+		t.Error("Expected no caching without a cache")
+	}
+}
+
+func TestCacheOptions(t *testing.T) {
+	if _, err := NewLRUCache(0); !errors.Is(err, ErrInvalidCacheCapacity) {
+		t.Errorf("Expected ErrInvalidCacheCapacity, got %v", err)
+	}
+	if _, err := CreateConversationOptions("m", nil, WithResponseCache(nil, 0)); !errors.Is(err, ErrResponseCacheNil) {
+		t.Errorf("Expected ErrResponseCacheNil, got %v", err)
+	}
+	cache, _ := NewLRUCache(1)
+	if _, err := CreateConversationOptions("m", nil, WithResponseCache(cache, -time.Second)); !errors.Is(err, ErrInvalidCacheTTL) {
+		t.Errorf("Expected ErrInvalidCacheTTL, got %v", err)
+	}
+}
@@ -0,0 +1,407 @@
+// Package cassette records the HTTP interactions of the providers to fixtures and replays them in tests.
+//
+// A cassette is a JSON file holding the recorded interactions. In the record mode the requests
+// reach the provider and the interactions are appended to the file as they complete; in the
+// replay mode the requests are answered from the file and never reach the network, so that the
+// integration tests are reproducible and cheap. The secrets are scrubbed before anything is
+// written: the authentication headers by default, plus the patterns and scrubbers of the options.
+//
+// The cassette plugs into the OpenAI and AIW clients as a request option, see Cassette.Middleware,
+// and into the plain HTTP clients, such as the Ollama embedder one, as a transport, see
+// Cassette.Transport. The streamed responses are recorded whole and replayed at once.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/openai/openai-go/v3/option"
+)
+
+// EnvMode is the environment variable setting the mode of the cassettes, unless WithMode is given.
+//
+// Its values are "auto", "replay" and "record", e.g. GGRAPH_CASSETTE_MODE=record go test ./... refreshes the fixtures.
+const EnvMode = "GGRAPH_CASSETTE_MODE"
+
+var (
+	// ErrCassetteNotFound indicates that a cassette to be replayed does not exist.
+	ErrCassetteNotFound = errors.New("cassette not found")
+	// ErrInteractionNotFound indicates that no recorded interaction answers a replayed request.
+	ErrInteractionNotFound = errors.New("no recorded interaction matches the request")
+)
+
+// Mode tells whether a cassette records or replays the interactions.
+type Mode int
+
+const (
+	// ModeAuto replays the cassette if its file exists, otherwise it records it.
+	ModeAuto Mode = iota
+	// ModeReplay answers the requests from the cassette, failing the unmatched ones with ErrInteractionNotFound.
+	ModeReplay
+	// ModeRecord sends the requests to the provider and records the interactions, overwriting the cassette.
+	ModeRecord
+)
+
+// String returns the name of the mode.
+func (m Mode) String() string {
+	switch m {
+	case ModeAuto:
+		return "auto"
+	case ModeReplay:
+		return "replay"
+	case ModeRecord:
+		return "record"
+	default:
+		return "unknown"
+	}
+}
+
+// Request is a recorded HTTP request.
+type Request struct {
+	// Method is the HTTP method.
+	Method string `json:"method"`
+	// URL is the full URL, query included.
+	URL string `json:"url"`
+	// Header are the scrubbed headers.
+	Header http.Header `json:"header,omitempty"`
+	// Body is the scrubbed body.
+	Body string `json:"body,omitempty"`
+}
+
+// Response is a recorded HTTP response.
+type Response struct {
+	// Status is the HTTP status code.
+	Status int `json:"status"`
+	// Header are the scrubbed headers.
+	Header http.Header `json:"header,omitempty"`
+	// Body is the scrubbed body, the whole event stream for the streamed responses.
+	Body string `json:"body,omitempty"`
+}
+
+// Interaction is a recorded request with its response.
+type Interaction struct {
+	// Request is the request.
+	Request Request `json:"request"`
+	// Response is the response.
+	Response Response `json:"response"`
+}
+
+// file is the JSON representation of a cassette.
+type file struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Cassette records or replays the HTTP interactions of the providers.
+//
+// It is safe for concurrent use; the concurrent requests are recorded in the order they complete.
+type Cassette struct {
+	mu sync.Mutex
+
+	path         string
+	opts         Options
+	mode         Mode
+	interactions []Interaction
+	replayed     []bool
+}
+
+// New opens a cassette.
+//
+// The mode is given by WithMode, otherwise by the EnvMode variable, otherwise it is ModeAuto.
+//
+// Parameters:
+//   - path: The path of the JSON file of the cassette, e.g. "testdata/chat.json".
+//   - opts: The configuration options.
+//
+// Returns:
+//   - *Cassette: The cassette.
+//   - error: ErrCassetteNotFound in replay mode if the file does not exist, or an error if the options or the file are invalid.
+//
+// Example:
+//
+//	rec, err := cassette.New("testdata/weather.json", cassette.WithRedactedPattern(`sk-[A-Za-z0-9_-]+`))
+//	client := o.NewOpenAIClient(o.APIKeyFromEnv(), rec.Middleware())
+func New(path string, opts ...Option) (*Cassette, error) {
+	useOpts := Options{
+		Mode:          ModeAuto,
+		SecretHeaders: slices.Clone(DefaultSecretHeaders),
+		Matcher:       MatchMethodURLBody,
+	}
+	if env := os.Getenv(EnvMode); env != "" {
+		mode, err := parseMode(env)
+		if err != nil {
+			return nil, fmt.Errorf("cannot open the cassette: %w", err)
+		}
+		useOpts.Mode = mode
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(&useOpts); err != nil {
+			return nil, fmt.Errorf("cannot open the cassette: %w", err)
+		}
+	}
+
+	rv := &Cassette{path: path, opts: useOpts, mode: useOpts.Mode}
+	if rv.mode == ModeRecord {
+		return rv, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		if rv.mode == ModeReplay {
+			return nil, fmt.Errorf("cannot open the cassette: %w: %s", ErrCassetteNotFound, path)
+		}
+		rv.mode = ModeRecord
+		return rv, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot open the cassette: %w", err)
+	}
+	var content file
+	if err := json.Unmarshal(data, &content); err != nil {
+		return nil, fmt.Errorf("cannot open the cassette %s: %w", path, err)
+	}
+	rv.mode = ModeReplay
+	rv.interactions = content.Interactions
+	rv.replayed = make([]bool, len(content.Interactions))
+	return rv, nil
+}
+
+func parseMode(value string) (Mode, error) {
+	for _, mode := range []Mode{ModeAuto, ModeReplay, ModeRecord} {
+		if strings.EqualFold(value, mode.String()) {
+			return mode, nil
+		}
+	}
+	return ModeAuto, fmt.Errorf("%w: unknown mode %q in %s", ErrInvalidOption, value, EnvMode)
+}
+
+// Mode returns the effective mode of the cassette, ModeAuto being resolved when the cassette is opened.
+//
+// Returns:
+//   - ModeReplay or ModeRecord.
+func (c *Cassette) Mode() Mode {
+	return c.mode
+}
+
+// Interactions returns the interactions of the cassette, the recorded ones so far in record mode.
+//
+// Returns:
+//   - A copy of the interactions.
+func (c *Cassette) Interactions() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return slices.Clone(c.interactions)
+}
+
+// Middleware returns the request option plugging the cassette into the OpenAI and AIW clients.
+//
+// Returns:
+//   - The request option.
+//
+// Example:
+//
+//	client := aiw.NewAIWClient(aiw.PATFromEnv(), rec.Middleware())
+func (c *Cassette) Middleware() option.RequestOption {
+	return option.WithMiddleware(func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		return c.roundTrip(req, next)
+	})
+}
+
+// Transport returns a transport plugging the cassette into a plain HTTP client.
+//
+// Parameters:
+//   - base: The transport reaching the provider in record mode, http.DefaultTransport when nil.
+//
+// Returns:
+//   - The transport.
+//
+// Example:
+//
+//	embedder, err := ollama.NewEmbedder(baseURL, "nomic-embed-text", &http.Client{Transport: rec.Transport(nil)})
+func (c *Cassette) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return transportFn(func(req *http.Request) (*http.Response, error) {
+		return c.roundTrip(req, base.RoundTrip)
+	})
+}
+
+type transportFn func(req *http.Request) (*http.Response, error)
+
+func (f transportFn) RoundTrip(req *http.Request) (*http.Response, error) { return f(req) }
+
+func (c *Cassette) roundTrip(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the request body: %w", err)
+	}
+	request := c.scrubRequest(Request{Method: req.Method, URL: req.URL.String(), Header: req.Header.Clone(), Body: string(body)})
+
+	if c.mode == ModeReplay {
+		recorded, err := c.replay(c.scrubReplayed(request))
+		if err != nil {
+			return nil, err
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", recorded.Status, http.StatusText(recorded.Status)),
+			StatusCode:    recorded.Status,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        recorded.Header.Clone(),
+			Body:          io.NopCloser(strings.NewReader(recorded.Body)),
+			ContentLength: int64(len(recorded.Body)),
+			Request:       req,
+		}, nil
+	}
+
+	resp, err := next(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	if err := c.record(Interaction{
+		Request:  request,
+		Response: Response{Status: resp.StatusCode, Header: resp.Header.Clone(), Body: string(respBody)},
+	}); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// readBody reads the request body, restoring it for the next handler.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
+
+// replay returns the response of the first matching interaction not replayed yet.
+func (c *Cassette) replay(request Request) (Response, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, recorded := range c.interactions {
+		if !c.replayed[i] && c.opts.Matcher(request, recorded) {
+			c.replayed[i] = true
+			return recorded.Response, nil
+		}
+	}
+	return Response{}, fmt.Errorf("%w: %s %s in %s", ErrInteractionNotFound, request.Method, request.URL, c.path)
+}
+
+// record scrubs the interaction, appends it and rewrites the cassette, so that nothing is lost if the test fails.
+func (c *Cassette) record(interaction Interaction) error {
+	interaction.Response.Header = c.scrubHeader(interaction.Response.Header)
+	interaction.Response.Body = c.redact(interaction.Response.Body)
+	for _, scrubber := range c.opts.Scrubbers {
+		scrubber(&interaction)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.interactions = append(c.interactions, interaction)
+	data, err := json.MarshalIndent(file{Interactions: c.interactions}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("cannot record the interaction: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0o755); err != nil {
+		return fmt.Errorf("cannot record the interaction: %w", err)
+	}
+	temp := c.path + ".tmp"
+	if err := os.WriteFile(temp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("cannot record the interaction: %w", err)
+	}
+	if err := os.Rename(temp, c.path); err != nil {
+		return fmt.Errorf("cannot record the interaction: %w", err)
+	}
+	return nil
+}
+
+func (c *Cassette) scrubRequest(request Request) Request {
+	request.URL = c.redact(request.URL)
+	request.Header = c.scrubHeader(request.Header)
+	request.Body = c.redact(request.Body)
+	return request
+}
+
+// scrubReplayed runs the scrubbers on a replayed request, as they ran on the recorded one, the response being empty.
+func (c *Cassette) scrubReplayed(request Request) Request {
+	interaction := Interaction{Request: request, Response: Response{Header: http.Header{}}}
+	for _, scrubber := range c.opts.Scrubbers {
+		scrubber(&interaction)
+	}
+	return interaction.Request
+}
+
+func (c *Cassette) scrubHeader(header http.Header) http.Header {
+	for name, values := range header {
+		secret := slices.ContainsFunc(c.opts.SecretHeaders, func(secret string) bool { return strings.EqualFold(secret, name) })
+		for i := range values {
+			if secret {
+				values[i] = Redacted
+			} else {
+				values[i] = c.redact(values[i])
+			}
+		}
+	}
+	return header
+}
+
+func (c *Cassette) redact(value string) string {
+	for _, pattern := range c.opts.Patterns {
+		value = pattern.ReplaceAllString(value, Redacted)
+	}
+	return value
+}
+
+// MatchMethodURLBody matches the requests with the same method, URL and body, the JSON bodies being compared by value.
+//
+// Parameters:
+//   - request: The scrubbed request.
+//   - recorded: The recorded interaction.
+//
+// Returns:
+//   - true if the interaction answers the request.
+func MatchMethodURLBody(request Request, recorded Interaction) bool {
+	return request.Method == recorded.Request.Method &&
+		request.URL == recorded.Request.URL &&
+		sameBody(request.Body, recorded.Request.Body)
+}
+
+func sameBody(left, right string) bool {
+	if left == right {
+		return true
+	}
+	var leftValue, rightValue any
+	if json.Unmarshal([]byte(left), &leftValue) != nil || json.Unmarshal([]byte(right), &rightValue) != nil {
+		return false
+	}
+	leftJSON, _ := json.Marshal(leftValue)
+	rightJSON, _ := json.Marshal(rightValue)
+	return bytes.Equal(leftJSON, rightJSON)
+}
@@ -0,0 +1,185 @@
+package cassette_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/agent/cassette"
+	"github.com/morphy76/ggraph/pkg/agent/mock"
+	o "github.com/morphy76/ggraph/pkg/agent/openai"
+)
+
+const apiKey = "sk-live-0123456789"
+
+func ask(client *openai.Client, question string) (a.Message, error) {
+	opts, _ := a.CreateConversationOptions("gpt", []a.Message{a.CreateMessage(a.User, question)})
+	return o.ChatCompletion(context.Background(), client.Chat, opts)
+}
+
+func TestCassette_RecordReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fixtures", "chat.json")
+	provider, _ := mock.New(mock.WithScript(mock.Text("Hello!"), mock.Text("Streamed answer")))
+	server := httptest.NewServer(provider)
+
+	rec, err := cassette.New(path, cassette.WithRedactedPattern(`sk-[A-Za-z0-9_-]+`))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if rec.Mode() != cassette.ModeRecord {
+		t.Fatalf("Expected a missing cassette to be recorded, got %s", rec.Mode())
+	}
+	client := o.NewClient(server.URL, apiKey, rec.Middleware())
+	if answer, err := ask(client, "Hi, my key is "+apiKey); err != nil || answer.Content != "Hello!" {
+		t.Fatalf("Unexpected recorded answer %+v, %v", answer, err)
+	}
+	opts, _ := a.CreateConversationOptions("gpt", []a.Message{a.CreateMessage(a.User, "Stream it")})
+	if answer, err := o.ChatCompletionStream(context.Background(), client.Chat, opts, nil); err != nil || answer.Content != "Streamed answer" {
+		t.Fatalf("Unexpected recorded stream %+v, %v", answer, err)
+	}
+	server.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Expected the cassette to be written: %v", err)
+	}
+	if strings.Contains(string(data), apiKey) {
+		t.Errorf("Expected the secrets to be scrubbed:\n%s", data)
+	}
+	if recorded := rec.Interactions(); len(recorded) != 2 || recorded[0].Request.Header.Get("Authorization") != cassette.Redacted {
+		t.Errorf("Unexpected recorded interactions %+v", recorded)
+	}
+
+	replay, err := cassette.New(path, cassette.WithMode(cassette.ModeReplay), cassette.WithRedactedPattern(`sk-[A-Za-z0-9_-]+`))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	client = o.NewClient(server.URL, "another-key", replay.Middleware(), option.WithMaxRetries(0))
+	if answer, err := ask(client, "Hi, my key is "+apiKey); err != nil || answer.Content != "Hello!" {
+		t.Errorf("Unexpected replayed answer %+v, %v", answer, err)
+	}
+	var partials []string
+	answer, err := o.ChatCompletionStream(context.Background(), client.Chat, opts, func(partial a.Message) { partials = append(partials, partial.Content) })
+	if err != nil || answer.Content != "Streamed answer" || len(partials) < 2 {
+		t.Errorf("Unexpected replayed stream %+v, %q, %v", answer, partials, err)
+	}
+
+	if _, err := ask(client, "Hi, my key is "+apiKey); !errors.Is(err, cassette.ErrInteractionNotFound) {
+		t.Errorf("Expected an interaction to be replayed once, got %v", err)
+	}
+	if _, err := ask(client, "Unknown"); !errors.Is(err, cassette.ErrInteractionNotFound) {
+		t.Errorf("Expected ErrInteractionNotFound, got %v", err)
+	}
+}
+
+func TestCassette_Transport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "embed.json")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=secret")
+		io.WriteString(w, `{"embeddings":[[0.1,0.2]]}`)
+	}))
+
+	post := func(client *http.Client) (string, error) {
+		resp, err := client.Post(server.URL+"/api/embed", "application/json", strings.NewReader(`{"model": "nomic", "input": ["hello"]}`))
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		return string(body), err
+	}
+
+	rec, _ := cassette.New(path, cassette.WithMode(cassette.ModeRecord), cassette.WithScrubber(func(interaction *cassette.Interaction) {
+		interaction.Response.Header.Del("Date")
+	}))
+	if body, err := post(&http.Client{Transport: rec.Transport(nil)}); err != nil || !strings.Contains(body, "0.1") {
+		t.Fatalf("Unexpected recorded body %q, %v", body, err)
+	}
+	server.Close()
+	recorded := rec.Interactions()[0].Response.Header
+	if recorded.Get("Set-Cookie") != cassette.Redacted || recorded.Get("Date") != "" {
+		t.Errorf("Unexpected recorded headers %v", recorded)
+	}
+
+	replay, _ := cassette.New(path)
+	if replay.Mode() != cassette.ModeReplay {
+		t.Fatalf("Expected an existing cassette to be replayed, got %s", replay.Mode())
+	}
+	if body, err := post(&http.Client{Transport: replay.Transport(nil)}); err != nil || body != `{"embeddings":[[0.1,0.2]]}` {
+		t.Errorf("Unexpected replayed body %q, %v", body, err)
+	}
+}
+
+func TestCassette_RequestScrubber(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nonce.json")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"ok":true}`)
+	}))
+	nonce := regexp.MustCompile(`"nonce":"[^"]*"`)
+	scrubNonce := cassette.WithScrubber(func(interaction *cassette.Interaction) {
+		interaction.Request.Body = nonce.ReplaceAllString(interaction.Request.Body, `"nonce":""`)
+	})
+	post := func(client *http.Client, body string) (string, error) {
+		resp, err := client.Post(server.URL+"/api/sign", "application/json", strings.NewReader(body))
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		answer, err := io.ReadAll(resp.Body)
+		return string(answer), err
+	}
+
+	rec, _ := cassette.New(path, cassette.WithMode(cassette.ModeRecord), scrubNonce)
+	if _, err := post(&http.Client{Transport: rec.Transport(nil)}, `{"input":"hello","nonce":"1f2e"}`); err != nil {
+		t.Fatalf("Unexpected recording error %v", err)
+	}
+	server.Close()
+	if recorded := rec.Interactions()[0].Request.Body; recorded != `{"input":"hello","nonce":""}` {
+		t.Errorf("Expected the request to be scrubbed, got %s", recorded)
+	}
+
+	replay, _ := cassette.New(path, cassette.WithMode(cassette.ModeReplay), scrubNonce)
+	if body, err := post(&http.Client{Transport: replay.Transport(nil)}, `{"input":"hello","nonce":"9a8b"}`); err != nil || body != `{"ok":true}` {
+		t.Errorf("Expected the scrubbed request to match the recorded one, got %q, %v", body, err)
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	missing := filepath.Join(t.TempDir(), "missing.json")
+	if _, err := cassette.New(missing, cassette.WithMode(cassette.ModeReplay)); !errors.Is(err, cassette.ErrCassetteNotFound) {
+		t.Errorf("Expected ErrCassetteNotFound, got %v", err)
+	}
+
+	t.Setenv(cassette.EnvMode, "replay")
+	if _, err := cassette.New(missing); !errors.Is(err, cassette.ErrCassetteNotFound) {
+		t.Errorf("Expected the mode to be read from the environment, got %v", err)
+	}
+	t.Setenv(cassette.EnvMode, "rewind")
+	if _, err := cassette.New(missing); !errors.Is(err, cassette.ErrInvalidOption) {
+		t.Errorf("Expected ErrInvalidOption, got %v", err)
+	}
+	t.Setenv(cassette.EnvMode, "")
+
+	for _, opt := range []cassette.Option{
+		cassette.WithMode(cassette.Mode(7)),
+		cassette.WithSecretHeaders(""),
+		cassette.WithRedactedPattern("("),
+		cassette.WithScrubber(nil),
+		cassette.WithMatcher(nil),
+	} {
+		if _, err := cassette.New(missing, opt); !errors.Is(err, cassette.ErrInvalidOption) {
+			t.Errorf("Expected ErrInvalidOption, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package cassette
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+)
+
+// Redacted replaces the scrubbed secrets in the recorded interactions.
+const Redacted = "[REDACTED]"
+
+var (
+	// ErrInvalidOption indicates that an option of the cassette has an invalid value.
+	ErrInvalidOption = errors.New("invalid cassette option")
+)
+
+// DefaultSecretHeaders are the headers scrubbed from every recorded interaction.
+var DefaultSecretHeaders = []string{
+	"Authorization",
+	"Api-Key",
+	"X-Api-Key",
+	"Ocp-Apim-Subscription-Key",
+	"Openai-Organization",
+	"Openai-Project",
+	"Cookie",
+	"Set-Cookie",
+}
+
+// MatcherFn tells whether a recorded interaction answers a request.
+//
+// Both the request and the interaction are scrubbed, so that the matcher compares like with like.
+type MatcherFn func(request Request, recorded Interaction) bool
+
+// ScrubberFn edits an interaction before it is recorded, e.g. to remove the secrets from the bodies.
+//
+// In replay mode it edits the incoming requests too, their response being empty, so that they match
+// the scrubbed recorded ones.
+type ScrubberFn func(interaction *Interaction)
+
+// Options holds the configuration of a cassette.
+type Options struct {
+	// Mode tells whether the interactions are recorded or replayed.
+	Mode Mode
+	// SecretHeaders are the headers whose values are replaced by Redacted.
+	SecretHeaders []string
+	// Patterns are the expressions whose matches are replaced by Redacted in the URLs, headers and bodies.
+	Patterns []*regexp.Regexp
+	// Scrubbers are the functions editing the interactions before they are recorded, and the replayed requests.
+	Scrubbers []ScrubberFn
+	// Matcher tells whether a recorded interaction answers a request, MatchMethodURLBody when nil.
+	Matcher MatcherFn
+}
+
+// Option is a functional option for configuring a cassette.
+type Option interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - o: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(o *Options) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc func(*Options) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - o: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc) Apply(o *Options) error { return s(o) }
+
+// WithMode sets the mode of the cassette, overriding the EnvMode variable.
+//
+// Parameters:
+//   - mode: The mode.
+//
+// Returns:
+//   - An Option that sets the mode.
+func WithMode(mode Mode) Option {
+	return OptionFunc(func(o *Options) error {
+		if mode < ModeAuto || mode > ModeRecord {
+			return fmt.Errorf("%w: unknown mode %d", ErrInvalidOption, mode)
+		}
+		o.Mode = mode
+		return nil
+	})
+}
+
+// WithSecretHeaders adds headers to be scrubbed, besides DefaultSecretHeaders.
+//
+// Parameters:
+//   - names: The names of the headers, case insensitive.
+//
+// Returns:
+//   - An Option that adds the headers.
+func WithSecretHeaders(names ...string) Option {
+	return OptionFunc(func(o *Options) error {
+		for _, name := range names {
+			if name == "" {
+				return fmt.Errorf("%w: empty header name", ErrInvalidOption)
+			}
+			o.SecretHeaders = append(o.SecretHeaders, http.CanonicalHeaderKey(name))
+		}
+		return nil
+	})
+}
+
+// WithRedactedPattern replaces the matches of an expression by Redacted in the URLs, header values and bodies.
+//
+// Parameters:
+//   - pattern: The regular expression of the secret.
+//
+// Returns:
+//   - An Option that adds the pattern.
+//
+// Example:
+//
+//	cassette.WithRedactedPattern(`sk-[A-Za-z0-9_-]+`)
+func WithRedactedPattern(pattern string) Option {
+	return OptionFunc(func(o *Options) error {
+		expr, err := regexp.Compile(pattern)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidOption, err)
+		}
+		o.Patterns = append(o.Patterns, expr)
+		return nil
+	})
+}
+
+// WithScrubber edits the interactions before they are recorded, after the headers and patterns are scrubbed,
+// and the requests before they are matched in replay mode.
+//
+// Parameters:
+//   - scrubber: The function editing the interaction.
+//
+// Returns:
+//   - An Option that adds the scrubber.
+func WithScrubber(scrubber ScrubberFn) Option {
+	return OptionFunc(func(o *Options) error {
+		if scrubber == nil {
+			return fmt.Errorf("%w: nil scrubber", ErrInvalidOption)
+		}
+		o.Scrubbers = append(o.Scrubbers, scrubber)
+		return nil
+	})
+}
+
+// WithMatcher sets how the requests are matched with the recorded interactions.
+//
+// Parameters:
+//   - matcher: The matcher.
+//
+// Returns:
+//   - An Option that sets the matcher.
+func WithMatcher(matcher MatcherFn) Option {
+	return OptionFunc(func(o *Options) error {
+		if matcher == nil {
+			return fmt.Errorf("%w: nil matcher", ErrInvalidOption)
+		}
+		o.Matcher = matcher
+		return nil
+	})
+}
@@ -0,0 +1,161 @@
+package agent
+
+import (
+	"encoding/base64"
+	"slices"
+	"strings"
+	"time"
+)
+
+// PartKind is the kind of a content part.
+type PartKind int
+
+const (
+	// TextPart is a text content part.
+	TextPart PartKind = iota
+	// ImagePart is an image, referenced by URL or carried inline.
+	ImagePart
+	// AudioPart is an audio clip carried inline.
+	AudioPart
+)
+
+// ImageDetail is the fidelity requested for an image part.
+type ImageDetail string
+
+const (
+	// ImageDetailAuto lets the provider choose the fidelity.
+	ImageDetailAuto ImageDetail = "auto"
+	// ImageDetailLow requests a low fidelity, cheaper image.
+	ImageDetailLow ImageDetail = "low"
+	// ImageDetailHigh requests a high fidelity image.
+	ImageDetailHigh ImageDetail = "high"
+)
+
+// ContentPart is a part of a multi-modal message.
+//
+// Build the parts with Text, ImageURL, ImageData and Audio.
+type ContentPart struct {
+	// Kind is the kind of the part.
+	Kind PartKind
+	// Text is the text of a TextPart.
+	Text string
+	// URL references an ImagePart, either an http(s) or a data URL.
+	URL string
+	// Data is the inline content of an ImagePart or an AudioPart, when URL is not set.
+	Data []byte
+	// MIMEType is the media type of Data, e.g. "image/png" or "audio/wav".
+	MIMEType string
+	// Detail is the fidelity of an ImagePart, provider default when empty.
+	Detail ImageDetail
+}
+
+// Text creates a text content part.
+//
+// Parameters:
+//   - text: The text.
+//
+// Returns:
+//   - The content part.
+func Text(text string) ContentPart {
+	return ContentPart{Kind: TextPart, Text: text}
+}
+
+// ImageURL creates an image content part referenced by URL.
+//
+// Parameters:
+//   - url: The http(s) or data URL of the image.
+//   - detail: The requested fidelity, empty for the provider default.
+//
+// Returns:
+//   - The content part.
+func ImageURL(url string, detail ImageDetail) ContentPart {
+	return ContentPart{Kind: ImagePart, URL: url, Detail: detail}
+}
+
+// ImageData creates an image content part carrying the image bytes.
+//
+// Parameters:
+//   - data: The encoded image.
+//   - mimeType: The media type of the image, e.g. "image/jpeg".
+//
+// Returns:
+//   - The content part.
+func ImageData(data []byte, mimeType string) ContentPart {
+	return ContentPart{Kind: ImagePart, Data: data, MIMEType: mimeType}
+}
+
+// Audio creates an audio content part carrying the audio bytes.
+//
+// Parameters:
+//   - data: The encoded audio.
+//   - mimeType: The media type of the audio, e.g. "audio/wav" or "audio/mpeg".
+//
+// Returns:
+//   - The content part.
+func Audio(data []byte, mimeType string) ContentPart {
+	return ContentPart{Kind: AudioPart, Data: data, MIMEType: mimeType}
+}
+
+// DataURL returns the URL of an image part, encoding the inline data when no URL is set.
+//
+// Returns:
+//   - The http(s) URL or a base64 data URL.
+func (p ContentPart) DataURL() string {
+	if p.URL != "" {
+		return p.URL
+	}
+	return "data:" + p.MIMEType + ";base64," + base64.StdEncoding.EncodeToString(p.Data)
+}
+
+// Clone returns a copy of the content part sharing no mutable data with the original.
+//
+// Returns:
+//   - ContentPart: The copy of the content part.
+func (p ContentPart) Clone() ContentPart {
+	p.Data = slices.Clone(p.Data)
+	return p
+}
+
+// CreateMultiModalMessage creates a message made of content parts.
+//
+// The text parts are also joined into Content, so that the text-only consumers, such as the
+// token counters and the summarizers, keep working with the message.
+//
+// Parameters:
+//   - role: The role of the message.
+//   - parts: The content parts, in order.
+//
+// Returns:
+//   - The message.
+//
+// Example usage:
+//
+//	msg := CreateMultiModalMessage(User, Text("What is in this picture?"), ImageURL("https://example.com/cat.png", ImageDetailLow))
+func CreateMultiModalMessage(role MessageRole, parts ...ContentPart) Message {
+	var texts []string
+	for _, part := range parts {
+		if part.Kind == TextPart {
+			texts = append(texts, part.Text)
+		}
+	}
+	now := time.Now()
+	return Message{
+		ID:        NewMessageID(),
+		Ts:        now,
+		CreatedAt: now,
+		Role:      role,
+		Content:   strings.Join(texts, "\n"),
+		Parts:     parts,
+	}
+}
+
+func cloneParts(parts []ContentPart) []ContentPart {
+	if parts == nil {
+		return nil
+	}
+	rv := make([]ContentPart, len(parts))
+	for idx, part := range parts {
+		rv[idx] = part.Clone()
+	}
+	return rv
+}
@@ -0,0 +1,39 @@
+package agent
+
+import "testing"
+
+func TestCreateMultiModalMessage(t *testing.T) {
+	image := []byte{0x89, 'P', 'N', 'G'}
+	msg := CreateMultiModalMessage(User, Text("What is it?"), ImageData(image, "image/png"), Text("Be brief."))
+
+	if msg.Role != User || msg.Content != "What is it?\nBe brief." {
+		t.Errorf("Expected the text parts to be joined into Content, got %q", msg.Content)
+	}
+	if len(msg.Parts) != 3 || msg.Parts[1].Kind != ImagePart {
+		t.Fatalf("Unexpected parts %+v", msg.Parts)
+	}
+	if url := msg.Parts[1].DataURL(); url != "data:image/png;base64,iVBORw==" {
+		t.Errorf("Unexpected data URL %q", url)
+	}
+	if url := ImageURL("https://example.com/cat.png", ImageDetailLow).DataURL(); url != "https://example.com/cat.png" {
+		t.Errorf("Expected the URL to be kept, got %q", url)
+	}
+
+	clone := msg.Clone()
+	clone.Parts[1].Data[0] = 0
+	if msg.Parts[1].Data[0] != 0x89 {
+		t.Error("Expected the clone not to share the part data")
+	}
+}
+
+func TestCountMessageTokens_MediaParts(t *testing.T) {
+	text := CreateMessage(User, "abcd")
+	multiModal := CreateMultiModalMessage(User, Text("abcd"), Audio([]byte{1, 2, 3}, "audio/wav"))
+
+	if got, want := CountMessageTokens(charTokenizer, multiModal), CountMessageTokens(charTokenizer, text)+mediaPartTokens; got != want {
+		t.Errorf("Expected %d tokens, got %d", want, got)
+	}
+	if got, want := ApproximateTokenCount(multiModal), ApproximateTokenCount(text)+mediaPartTokens; got != want {
+		t.Errorf("Expected %d estimated tokens, got %d", want, got)
+	}
+}
@@ -1,9 +1,11 @@
 package agent
 
 import (
+	"maps"
 	"time"
 
 	t "github.com/morphy76/ggraph/pkg/agent/tool"
+	g "github.com/morphy76/ggraph/pkg/graph"
 )
 
 // MessageRole defines the role of a message in a chat conversation.
@@ -21,15 +23,46 @@ const (
 )
 
 // Message represents a single message in a chat conversation.
+//
+// The optional fields are omitted from the JSON encoding when empty, so that the messages without
+// them encode as they always did and the persisted conversations decode unchanged.
 type Message struct {
-	// Timestamp of the message.
+	// ID identifies the message, see NewMessageID; empty for the messages created as literals.
+	ID string `json:",omitempty"`
+	// Timestamp of the message, the time it joined the conversation.
 	Ts time.Time
+	// CreatedAt is the time the message was created, e.g. by the model provider; unlike Ts, it is
+	// kept when a cached answer joins the conversation again.
+	CreatedAt time.Time `json:",omitzero"`
 	// Role of the message (System, User, Assistant, Tool).
 	Role MessageRole
-	// Content of the message.
+	// Name of the participant authoring the message, telling apart the participants of a same role
+	// to the model providers supporting it.
+	Name string `json:",omitempty"`
+	// Content of the message, the text of the message parts if any.
 	Content string
+	// Parts of a multi-modal message, see CreateMultiModalMessage; nil for text-only messages.
+	Parts []ContentPart
 	// Tool calls made in the message.
 	ToolCalls []t.FnCall
+	// Metadata annotates the message, e.g. with the verdict of a guardrail or a parser node; it is
+	// not sent to the model providers.
+	Metadata map[string]string `json:",omitempty"`
+}
+
+// NewMessageID returns a new identifier of a message.
+//
+// The identifiers are ULIDs, so that they sort by creation time; the messages created by
+// CreateMessage, CreateMultiModalMessage and the model providers are given one.
+//
+// Returns:
+//   - The message identifier.
+//
+// Example usage:
+//
+//	msg := Message{ID: NewMessageID(), Ts: time.Now(), Role: User, Content: "Hello"}
+func NewMessageID() string {
+	return g.ULID()
 }
 
 // Conversation represents a chat-based language model for an agent.
@@ -38,4 +71,112 @@ type Conversation struct {
 	Messages []Message
 	// CurrentToolCalls holds the current tool calls to be executed.
 	CurrentToolCalls []t.FnCall
+	// Metadata annotates the conversation, e.g. with the backend which produced the last answer.
+	Metadata map[string]string
+	// SystemPrompt overrides the system prompt of the conversation nodes, see OverrideSystemPrompt.
+	SystemPrompt *SystemPrompt `json:",omitempty"`
+}
+
+// Clone returns a copy of the message sharing no mutable data with the original.
+//
+// Returns:
+//   - Message: The copy of the message.
+func (m Message) Clone() Message {
+	m.ToolCalls = cloneFnCalls(m.ToolCalls)
+	m.Parts = cloneParts(m.Parts)
+	m.Metadata = maps.Clone(m.Metadata)
+	return m
+}
+
+// Clone returns a copy of the conversation sharing no mutable data with the original.
+//
+// Conversation implements graph.Cloneable, so that the runtime hands out snapshots of it.
+//
+// Returns:
+//   - Conversation: The copy of the conversation.
+func (c Conversation) Clone() Conversation {
+	if c.Messages != nil {
+		messages := make([]Message, len(c.Messages))
+		for idx, message := range c.Messages {
+			messages[idx] = message.Clone()
+		}
+		c.Messages = messages
+	}
+	c.CurrentToolCalls = cloneFnCalls(c.CurrentToolCalls)
+	c.Metadata = maps.Clone(c.Metadata)
+	if c.SystemPrompt != nil {
+		prompt := *c.SystemPrompt
+		c.SystemPrompt = &prompt
+	}
+	return c
+}
+
+// PromptMessages returns the messages of the conversation state followed by those of the user input.
+//
+// The input messages already merged into the state by an upstream node, e.g. transcribed by a
+// speech-to-text node, are matched by ID and not repeated: the state version is kept.
+//
+// Parameters:
+//   - userInput: The user input of the invocation.
+//   - currentState: The current state of the conversation.
+//
+// Returns:
+//   - The messages of the prompt, in a new slice.
+//
+// Example:
+//
+//	messages := agent.PromptMessages(userInput, currentState)
+func PromptMessages(userInput, currentState Conversation) []Message {
+	rv := make([]Message, 0, len(currentState.Messages)+len(userInput.Messages))
+	rv = append(rv, currentState.Messages...)
+
+	var merged map[string]bool
+	for _, message := range currentState.Messages {
+		if message.ID != "" {
+			if merged == nil {
+				merged = make(map[string]bool, len(currentState.Messages))
+			}
+			merged[message.ID] = true
+		}
+	}
+	for _, message := range userInput.Messages {
+		if message.ID == "" || !merged[message.ID] {
+			rv = append(rv, message)
+		}
+	}
+	return rv
+}
+
+func cloneFnCalls(calls []t.FnCall) []t.FnCall {
+	if calls == nil {
+		return nil
+	}
+	rv := make([]t.FnCall, len(calls))
+	for idx, call := range calls {
+		rv[idx] = call.Clone()
+	}
+	return rv
+}
+
+// StreamingViewReducer folds the partial conversations notified by the agent nodes into the streaming view of the state.
+//
+// The agent nodes notify the whole conversation followed by the assistant message growing as the
+// tokens arrive, see openai.StreamingConversationNodeFn: the messages of the view are replaced by
+// the notified ones, the tool calls and the metadata of the view are kept.
+//
+// Parameters:
+//   - view: The streaming view of the conversation.
+//   - partial: The partial conversation notified by a node.
+//
+// Returns:
+//   - Conversation: The updated streaming view.
+//
+// Example:
+//
+//	runtime, err := b.CreateRuntime(startEdge, stateMonitorCh, g.WithStreamingView(a.StreamingViewReducer))
+func StreamingViewReducer(view, partial Conversation) Conversation {
+	if len(partial.Messages) > 0 {
+		view.Messages = partial.Messages
+	}
+	return view
 }
@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// ErrConversationHistoryNil indicates that the provided conversation history is nil.
+var ErrConversationHistoryNil = errors.New("conversation history cannot be nil")
+
+// ConversationHistory keeps the messages of the threads apart from the graph state, see the history package.
+type ConversationHistory interface {
+	// Load returns the messages of the thread to send to the model before the conversation.
+	//
+	// Parameters:
+	//   - ctx: The context of the request.
+	//   - threadID: The thread of the conversation.
+	//
+	// Returns:
+	//   - The messages, in chronological order.
+	//   - An error if the messages could not be loaded.
+	Load(ctx context.Context, threadID string) ([]Message, error)
+	// Save appends the messages exchanged by a node to the thread.
+	//
+	// Parameters:
+	//   - ctx: The context of the request.
+	//   - threadID: The thread of the conversation.
+	//   - messages: The new messages, in chronological order.
+	//
+	// Returns:
+	//   - An error if the messages could not be saved.
+	Save(ctx context.Context, threadID string, messages []Message) error
+}
+
+// WithConversationHistory loads the history of the thread before the request, and saves the new messages after it.
+//
+// Only the conversation nodes given the invocation context, like the tool agent and the failover
+// nodes, know the thread: the others send the conversation state alone.
+//
+// Parameters:
+//   - history: The conversation history.
+//
+// Returns:
+//   - A ModelOption that sets the conversation history.
+//
+// Example usage:
+//
+//	option := WithConversationHistory(myHistory)
+//
+//	// or, with a history store
+//	option := history.WithHistory(history.NewMemoryStore(), 50)
+func WithConversationHistory(history ConversationHistory) ModelOption {
+	return ModelOptionFunc(func(r *ModelOptions) error {
+		if history == nil {
+			return ErrConversationHistoryNil
+		}
+		r.History = history
+		return nil
+	})
+}
+
+// LoadHistory returns the history of the thread of the invocation to send before the given messages, if any.
+//
+// The history messages found among the given ones, by ID, are left out, so that a conversation
+// kept in the state too is not sent twice.
+//
+// Parameters:
+//   - ctx: The context of the invocation.
+//   - opts: The model options.
+//   - messages: The messages of the conversation.
+//
+// Returns:
+//   - The history messages, nil without a history or outside of an invocation.
+//   - An error if the history could not be loaded.
+func LoadHistory(ctx context.Context, opts *ModelOptions, messages []Message) ([]Message, error) {
+	threadID, ok := g.ThreadIDFromContext(ctx)
+	if opts == nil || opts.History == nil || !ok {
+		return nil, nil
+	}
+	rv, err := opts.History.Load(ctx, threadID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load the history of thread %s: %w", threadID, err)
+	}
+	known := make(map[string]struct{}, len(messages))
+	for _, message := range messages {
+		if message.ID != "" {
+			known[message.ID] = struct{}{}
+		}
+	}
+	return slices.DeleteFunc(rv, func(message Message) bool {
+		_, ok := known[message.ID]
+		return ok && message.ID != ""
+	}), nil
+}
+
+// SaveHistory appends the new messages to the history of the thread of the invocation, if any.
+//
+// Parameters:
+//   - ctx: The context of the invocation.
+//   - opts: The model options.
+//   - messages: The user input and the messages produced by the node.
+//
+// Returns:
+//   - An error if the history could not be saved.
+func SaveHistory(ctx context.Context, opts *ModelOptions, messages []Message) error {
+	threadID, ok := g.ThreadIDFromContext(ctx)
+	if opts == nil || opts.History == nil || !ok || len(messages) == 0 {
+		return nil
+	}
+	if err := opts.History.Save(ctx, threadID, messages); err != nil {
+		return fmt.Errorf("cannot save the history of thread %s: %w", threadID, err)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+type testHistory struct {
+	loaded []Message
+	saved  map[string][]Message
+	err    error
+}
+
+func (h *testHistory) Load(_ context.Context, _ string) ([]Message, error) {
+	return append([]Message{}, h.loaded...), h.err
+}
+
+func (h *testHistory) Save(_ context.Context, threadID string, messages []Message) error {
+	if h.saved == nil {
+		h.saved = make(map[string][]Message)
+	}
+	h.saved[threadID] = append(h.saved[threadID], messages...)
+	return h.err
+}
+
+func TestWithConversationHistory(t *testing.T) {
+	if _, err := CreateConversationOptions("gpt", nil, WithConversationHistory(nil)); !errors.Is(err, ErrConversationHistoryNil) {
+		t.Errorf("Expected ErrConversationHistoryNil, got %v", err)
+	}
+}
+
+func TestLoadSaveHistory(t *testing.T) {
+	shared := CreateMessage(User, "shared")
+	history := &testHistory{loaded: []Message{CreateMessage(User, "earlier"), shared}}
+	opts, err := CreateConversationOptions("gpt", nil, WithConversationHistory(history))
+	if err != nil {
+		t.Fatalf("CreateConversationOptions failed: %v", err)
+	}
+	ctx := g.ContextWithThreadID(context.Background(), "thread-1")
+
+	loaded, err := LoadHistory(ctx, opts, []Message{shared, CreateMessage(User, "now")})
+	if err != nil {
+		t.Fatalf("LoadHistory failed: %v", err)
+	}
+	if contents(loaded) != "earlier" {
+		t.Errorf("Expected the messages of the state to be left out, got %s", contents(loaded))
+	}
+	if err := SaveHistory(ctx, opts, []Message{CreateMessage(Assistant, "answer")}); err != nil {
+		t.Fatalf("SaveHistory failed: %v", err)
+	}
+	if contents(history.saved["thread-1"]) != "answer" {
+		t.Errorf("Expected the messages to be saved on the thread, got %v", history.saved)
+	}
+
+	if loaded, err := LoadHistory(context.Background(), opts, nil); loaded != nil || err != nil {
+		t.Errorf("Expected no history outside of an invocation, got %v %v", loaded, err)
+	}
+	if err := SaveHistory(context.Background(), opts, []Message{CreateMessage(Assistant, "lost")}); err != nil || len(history.saved) != 1 {
+		t.Errorf("Expected nothing saved outside of an invocation, got %v %v", history.saved, err)
+	}
+
+	history.err = errors.New("boom")
+	if _, err := LoadHistory(ctx, opts, nil); !errors.Is(err, history.err) {
+		t.Errorf("Expected the load error, got %v", err)
+	}
+	if err := SaveHistory(ctx, opts, []Message{CreateMessage(Assistant, "answer")}); !errors.Is(err, history.err) {
+		t.Errorf("Expected the save error, got %v", err)
+	}
+}
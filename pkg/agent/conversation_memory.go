@@ -0,0 +1,294 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// SummaryPrefix marks the system message holding the summary of the older turns of a conversation.
+	SummaryPrefix = "Summary of the earlier conversation:\n"
+)
+
+var (
+	// ErrInvalidWindowTurns indicates that a conversation window does not keep at least one turn.
+	ErrInvalidWindowTurns = errors.New("window turns must be at least 1")
+	// ErrInvalidWindowTokens indicates that a conversation window does not allow at least one token.
+	ErrInvalidWindowTokens = errors.New("window tokens must be at least 1")
+	// ErrSummarizerNil indicates that the summarization memory has no summarizer.
+	ErrSummarizerNil = errors.New("summarizer cannot be nil")
+	// ErrConversationMemoryNil indicates that no conversation memory has been provided.
+	ErrConversationMemoryNil = errors.New("conversation memory cannot be nil")
+)
+
+// ConversationMemory compacts the messages of a conversation before they reach the model.
+//
+// Implementations keep the leading system messages and cut the conversation at turn boundaries,
+// a turn starting with a user message, so that tool calls are never separated from their results.
+type ConversationMemory interface {
+	// Compact returns the messages to keep.
+	//
+	// Parameters:
+	//   - ctx: The context of the request.
+	//   - messages: The messages of the conversation.
+	//
+	// Returns:
+	//   - The compacted messages.
+	//   - An error if the messages could not be compacted.
+	Compact(ctx context.Context, messages []Message) ([]Message, error)
+}
+
+// ConversationMemoryFunc is a function type that implements the ConversationMemory interface.
+type ConversationMemoryFunc func(ctx context.Context, messages []Message) ([]Message, error)
+
+// Compact calls the ConversationMemoryFunc.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - messages: The messages of the conversation.
+//
+// Returns:
+//   - The compacted messages.
+//   - An error if the messages could not be compacted.
+func (f ConversationMemoryFunc) Compact(ctx context.Context, messages []Message) ([]Message, error) {
+	return f(ctx, messages)
+}
+
+// TokenCounter estimates the number of tokens of a message.
+type TokenCounter func(message Message) int
+
+// Summarizer condenses the given messages, including any previous summary, into a text.
+type Summarizer func(ctx context.Context, messages []Message) (string, error)
+
+// ApproximateTokenCount estimates the tokens of a message as one every four characters plus a fixed overhead.
+//
+// Parameters:
+//   - message: The message to count.
+//
+// Returns:
+//   - The estimated number of tokens.
+func ApproximateTokenCount(message Message) int {
+	chars := len(message.Content)
+	for _, call := range message.ToolCalls {
+		chars += len(call.ToolName)
+		for name, value := range call.Arguments {
+			chars += len(name) + len(fmt.Sprint(value))
+		}
+	}
+	return 4 + (chars+3)/4 + mediaTokens(message)
+}
+
+// NewTurnWindow creates a memory keeping the leading system messages and the latest turns.
+//
+// Parameters:
+//   - turns: The number of turns to keep.
+//
+// Returns:
+//   - The conversation memory.
+//   - An error if turns is not positive.
+//
+// Example:
+//
+//	memory, err := agent.NewTurnWindow(10)
+func NewTurnWindow(turns int) (ConversationMemory, error) {
+	if turns < 1 {
+		return nil, ErrInvalidWindowTurns
+	}
+	return ConversationMemoryFunc(func(ctx context.Context, messages []Message) ([]Message, error) {
+		split := splitConversation(messages)
+		return split.join(split.turns[max(len(split.turns)-turns, 0):]), nil
+	}), nil
+}
+
+// NewTokenWindow creates a memory keeping the leading system messages and as many of the latest turns as fit the budget.
+//
+// The latest turn is always kept, even when it exceeds the budget on its own.
+//
+// Parameters:
+//   - maxTokens: The token budget of the conversation.
+//   - counter: The token estimation, ApproximateTokenCount if nil.
+//
+// Returns:
+//   - The conversation memory.
+//   - An error if maxTokens is not positive.
+//
+// Example:
+//
+//	memory, err := agent.NewTokenWindow(8000, nil)
+func NewTokenWindow(maxTokens int, counter TokenCounter) (ConversationMemory, error) {
+	if maxTokens < 1 {
+		return nil, ErrInvalidWindowTokens
+	}
+	if counter == nil {
+		counter = ApproximateTokenCount
+	}
+	return ConversationMemoryFunc(func(ctx context.Context, messages []Message) ([]Message, error) {
+		split := splitConversation(messages)
+
+		used := 0
+		for _, message := range split.head {
+			used += counter(message)
+		}
+		if split.summary != nil {
+			used += counter(*split.summary)
+		}
+
+		from := len(split.turns)
+		for from > 0 {
+			turnTokens := 0
+			for _, message := range split.turns[from-1] {
+				turnTokens += counter(message)
+			}
+			if used+turnTokens > maxTokens && from < len(split.turns) {
+				break
+			}
+			used += turnTokens
+			from--
+		}
+		return split.join(split.turns[from:]), nil
+	}), nil
+}
+
+// NewSummaryMemory creates a memory replacing the turns older than the latest ones with a summary.
+//
+// The summary is stored as a system message starting with SummaryPrefix, right after the leading
+// system messages; it is handed back to the summarizer along with the next turns to fold, so that
+// compacting the conversation state repeatedly does not stack summaries.
+//
+// Parameters:
+//   - keepTurns: The number of latest turns kept verbatim.
+//   - summarizer: The summarization of the older turns, usually backed by a model.
+//
+// Returns:
+//   - The conversation memory.
+//   - An error if keepTurns is not positive or the summarizer is nil.
+//
+// Example:
+//
+//	memory, err := agent.NewSummaryMemory(4, openai.NewSummarizer(client, "gpt-4o-mini"))
+func NewSummaryMemory(keepTurns int, summarizer Summarizer) (ConversationMemory, error) {
+	if keepTurns < 1 {
+		return nil, ErrInvalidWindowTurns
+	}
+	if summarizer == nil {
+		return nil, ErrSummarizerNil
+	}
+	return ConversationMemoryFunc(func(ctx context.Context, messages []Message) ([]Message, error) {
+		split := splitConversation(messages)
+		if len(split.turns) <= keepTurns {
+			return messages, nil
+		}
+
+		older := make([]Message, 0)
+		if split.summary != nil {
+			older = append(older, *split.summary)
+		}
+		for _, turn := range split.turns[:len(split.turns)-keepTurns] {
+			older = append(older, turn...)
+		}
+
+		summary, err := summarizer(ctx, older)
+		if err != nil {
+			return messages, fmt.Errorf("cannot summarize the conversation: %w", err)
+		}
+		summaryMessage := CreateMessage(System, SummaryPrefix+strings.TrimSpace(summary))
+		split.summary = &summaryMessage
+
+		return split.join(split.turns[len(split.turns)-keepTurns:]), nil
+	}), nil
+}
+
+// WithConversationMemory compacts the messages sent to the model, the conversation state is left untouched.
+//
+// Parameters:
+//   - memory: The conversation memory.
+//
+// Returns:
+//   - A ModelOption that sets the conversation memory.
+//
+// Example usage:
+//
+//	window, _ := agent.NewTurnWindow(10)
+//	option := WithConversationMemory(window)
+func WithConversationMemory(memory ConversationMemory) ModelOption {
+	return ModelOptionFunc(func(r *ModelOptions) error {
+		if memory == nil {
+			return ErrConversationMemoryNil
+		}
+		r.Memory = memory
+		return nil
+	})
+}
+
+// CompactMessages applies the system prompt, the conversation memory and then the context budget of the options to the given messages, if any.
+//
+// The system prompt of the invocation, see SystemPromptValue, overrides the one of the options.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - opts: The model options.
+//   - messages: The messages of the conversation.
+//
+// Returns:
+//   - The messages to send to the model.
+//   - An error if the messages could not be compacted or do not fit the context budget.
+func CompactMessages(ctx context.Context, opts *ModelOptions, messages []Message) ([]Message, error) {
+	if opts == nil {
+		return messages, nil
+	}
+
+	rv := ApplySystemPrompt(messages, systemPromptOf(ctx, opts))
+	if opts.Memory != nil {
+		var err error
+		rv, err = opts.Memory.Compact(ctx, rv)
+		if err != nil {
+			return messages, err
+		}
+	}
+	if opts.ContextBudget != nil {
+		return opts.ContextBudget.enforce(ctx, rv)
+	}
+	return rv, nil
+}
+
+type splitMessages struct {
+	head    []Message
+	summary *Message
+	turns   [][]Message
+}
+
+func splitConversation(messages []Message) splitMessages {
+	rv := splitMessages{}
+
+	i := 0
+	for ; i < len(messages) && messages[i].Role == System; i++ {
+		if strings.HasPrefix(messages[i].Content, SummaryPrefix) {
+			summary := messages[i]
+			rv.summary = &summary
+			continue
+		}
+		rv.head = append(rv.head, messages[i])
+	}
+
+	for ; i < len(messages); i++ {
+		if messages[i].Role == User || len(rv.turns) == 0 {
+			rv.turns = append(rv.turns, []Message{})
+		}
+		rv.turns[len(rv.turns)-1] = append(rv.turns[len(rv.turns)-1], messages[i])
+	}
+
+	return rv
+}
+
+func (s splitMessages) join(turns [][]Message) []Message {
+	rv := append([]Message{}, s.head...)
+	if s.summary != nil {
+		rv = append(rv, *s.summary)
+	}
+	for _, turn := range turns {
+		rv = append(rv, turn...)
+	}
+	return rv
+}
@@ -0,0 +1,147 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func memoryTestConversation() []Message {
+	return []Message{
+		CreateMessage(System, "prompt"),
+		CreateMessage(User, "u1"),
+		CreateMessage(Assistant, "a1"),
+		CreateMessage(User, "u2"),
+		CreateMessage(Assistant, "a2"),
+		CreateMessage(Tool, "t2"),
+		CreateMessage(Assistant, "a2 bis"),
+		CreateMessage(User, "u3"),
+	}
+}
+
+func contents(messages []Message) string {
+	rv := make([]string, len(messages))
+	for i, message := range messages {
+		rv[i] = message.Content
+	}
+	return strings.Join(rv, ",")
+}
+
+func TestNewTurnWindow(t *testing.T) {
+	if _, err := NewTurnWindow(0); !errors.Is(err, ErrInvalidWindowTurns) {
+		t.Errorf("Expected ErrInvalidWindowTurns, got %v", err)
+	}
+
+	tests := []struct {
+		turns int
+		want  string
+	}{
+		{1, "prompt,u3"},
+		{2, "prompt,u2,a2,t2,a2 bis,u3"},
+		{5, "prompt,u1,a1,u2,a2,t2,a2 bis,u3"},
+	}
+	for _, tt := range tests {
+		memory, _ := NewTurnWindow(tt.turns)
+		got, err := memory.Compact(context.Background(), memoryTestConversation())
+		if err != nil {
+			t.Fatalf("Compact failed: %v", err)
+		}
+		if contents(got) != tt.want {
+			t.Errorf("turns %d: expected %q, got %q", tt.turns, tt.want, contents(got))
+		}
+	}
+}
+
+func TestNewTokenWindow(t *testing.T) {
+	if _, err := NewTokenWindow(0, nil); !errors.Is(err, ErrInvalidWindowTokens) {
+		t.Errorf("Expected ErrInvalidWindowTokens, got %v", err)
+	}
+
+	oneEach := func(Message) int { return 1 }
+	tests := []struct {
+		maxTokens int
+		want      string
+	}{
+		{1, "prompt,u3"},
+		{5, "prompt,u3"},
+		{6, "prompt,u2,a2,t2,a2 bis,u3"},
+		{100, "prompt,u1,a1,u2,a2,t2,a2 bis,u3"},
+	}
+	for _, tt := range tests {
+		memory, _ := NewTokenWindow(tt.maxTokens, oneEach)
+		got, _ := memory.Compact(context.Background(), memoryTestConversation())
+		if contents(got) != tt.want {
+			t.Errorf("maxTokens %d: expected %q, got %q", tt.maxTokens, tt.want, contents(got))
+		}
+	}
+}
+
+func TestNewSummaryMemory(t *testing.T) {
+	if _, err := NewSummaryMemory(1, nil); !errors.Is(err, ErrSummarizerNil) {
+		t.Errorf("Expected ErrSummarizerNil, got %v", err)
+	}
+
+	var summarized []string
+	summarizer := func(ctx context.Context, messages []Message) (string, error) {
+		summarized = append(summarized, contents(messages))
+		return "S" + string(rune('0'+len(summarized))), nil
+	}
+	memory, err := NewSummaryMemory(1, summarizer)
+	if err != nil {
+		t.Fatalf("NewSummaryMemory failed: %v", err)
+	}
+
+	got, err := memory.Compact(context.Background(), memoryTestConversation())
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	if contents(got) != "prompt,"+SummaryPrefix+"S1,u3" {
+		t.Errorf("Unexpected compaction %q", contents(got))
+	}
+
+	got = append(got, CreateMessage(Assistant, "a3"), CreateMessage(User, "u4"))
+	got, _ = memory.Compact(context.Background(), got)
+	if contents(got) != "prompt,"+SummaryPrefix+"S2,u4" {
+		t.Errorf("Expected the summaries not to stack, got %q", contents(got))
+	}
+	if summarized[1] != SummaryPrefix+"S1,u3,a3" {
+		t.Errorf("Expected the previous summary to be folded, got %q", summarized[1])
+	}
+
+	short := memoryTestConversation()[:3]
+	got, _ = memory.Compact(context.Background(), short)
+	if len(summarized) != 2 || contents(got) != contents(short) {
+		t.Errorf("Expected no summarization within the kept turns, got %q", contents(got))
+	}
+
+	failing, _ := NewSummaryMemory(1, func(ctx context.Context, messages []Message) (string, error) {
+		return "", errors.New("boom")
+	})
+	if _, err := failing.Compact(context.Background(), memoryTestConversation()); err == nil {
+		t.Error("Expected the summarization error")
+	}
+}
+
+func TestCompactMessages(t *testing.T) {
+	messages := memoryTestConversation()
+
+	got, _ := CompactMessages(context.Background(), &ModelOptions{}, messages)
+	if len(got) != len(messages) {
+		t.Errorf("Expected untouched messages without memory, got %d", len(got))
+	}
+
+	window, _ := NewTurnWindow(1)
+	opts, err := CreateConversationOptions("model", messages, WithConversationMemory(window))
+	if err != nil {
+		t.Fatalf("CreateConversationOptions failed: %v", err)
+	}
+	got, _ = CompactMessages(context.Background(), opts, opts.Messages)
+	if contents(got) != "prompt,u3" {
+		t.Errorf("Expected the window to be applied, got %q", contents(got))
+	}
+
+	if _, err := CreateConversationOptions("model", messages, WithConversationMemory(nil)); !errors.Is(err, ErrConversationMemoryNil) {
+		t.Errorf("Expected ErrConversationMemoryNil, got %v", err)
+	}
+}
@@ -1,8 +1,13 @@
 package agent
 
 import (
+	"encoding/json"
+	"reflect"
 	"testing"
 	"time"
+
+	"github.com/morphy76/ggraph/pkg/agent/tool"
+	g "github.com/morphy76/ggraph/pkg/graph"
 )
 
 func TestMessageRole(t *testing.T) {
@@ -240,3 +245,124 @@ func TestConversationAppend(t *testing.T) {
 		t.Errorf("Expected second message to be User, got %v", conv.Messages[1].Role)
 	}
 }
+
+func TestConversation_Clone(t *testing.T) {
+	original := Conversation{
+		Messages: []Message{
+			{Role: Assistant, ToolCalls: []tool.FnCall{{ID: "1", ToolName: "search", Arguments: map[string]any{"filters": map[string]any{"year": 2024.0}, "tags": []any{"a"}}}}, Metadata: map[string]string{"guardrail": "passed"}},
+		},
+		CurrentToolCalls: []tool.FnCall{{ID: "2", Arguments: map[string]any{"q": "x"}}},
+	}
+
+	clone := g.CloneState(original, nil)
+
+	clone.Messages[0].Content = "changed"
+	clone.Messages[0].ToolCalls[0].Arguments["filters"].(map[string]any)["year"] = 2025.0
+	clone.Messages[0].ToolCalls[0].Arguments["tags"].([]any)[0] = "b"
+	clone.CurrentToolCalls[0].Arguments["q"] = "y"
+	clone.Messages[0].Metadata["guardrail"] = "blocked"
+
+	if original.Messages[0].Content != "" {
+		t.Error("Expected the messages to be copied")
+	}
+	if original.Messages[0].ToolCalls[0].Arguments["filters"].(map[string]any)["year"] != 2024.0 {
+		t.Error("Expected the nested arguments to be copied")
+	}
+	if original.Messages[0].ToolCalls[0].Arguments["tags"].([]any)[0] != "a" {
+		t.Error("Expected the argument slices to be copied")
+	}
+	if original.CurrentToolCalls[0].Arguments["q"] != "x" {
+		t.Error("Expected the current tool calls to be copied")
+	}
+	if original.Messages[0].Metadata["guardrail"] != "passed" {
+		t.Error("Expected the message metadata to be copied")
+	}
+	if (Conversation{}).Clone().Messages != nil {
+		t.Error("Expected an empty conversation to stay empty")
+	}
+}
+
+func TestMessage_JSON(t *testing.T) {
+	ts := time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+
+	plain, err := json.Marshal(Message{Ts: ts, Role: User, Content: "hi"})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	if string(plain) != `{"Ts":"2026-10-16T09:30:00Z","Role":1,"Content":"hi","Parts":null,"ToolCalls":null}` {
+		t.Errorf("Expected the message without the optional fields to encode unchanged, got %s", plain)
+	}
+
+	message := Message{
+		ID:        NewMessageID(),
+		Ts:        ts,
+		CreatedAt: ts.Add(-time.Second),
+		Role:      Assistant,
+		Name:      "planner",
+		Content:   "done",
+		ToolCalls: []tool.FnCall{{ID: "call_1", ToolName: "search", Arguments: map[string]any{"q": "x"}}},
+		Metadata:  map[string]string{"guardrail": "passed"},
+	}
+	encoded, err := json.Marshal(message)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	var decoded Message
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, message) {
+		t.Errorf("Expected the message to survive the round trip, got %+v", decoded)
+	}
+}
+
+func TestNewMessageID(t *testing.T) {
+	first, second := NewMessageID(), NewMessageID()
+	if first == "" || first == second {
+		t.Errorf("Expected distinct identifiers, got %q and %q", first, second)
+	}
+}
+
+func TestStreamingViewReducer(t *testing.T) {
+	view := Conversation{
+		Messages: []Message{CreateMessage(User, "Hi")},
+		Metadata: map[string]string{"backend": "primary"},
+	}
+
+	view = StreamingViewReducer(view, Conversation{Messages: []Message{CreateMessage(User, "Hi"), CreateMessage(Assistant, "Hel")}})
+	view = StreamingViewReducer(view, Conversation{Messages: []Message{CreateMessage(User, "Hi"), CreateMessage(Assistant, "Hello")}})
+	view = StreamingViewReducer(view, Conversation{})
+
+	if len(view.Messages) != 2 || view.Messages[1].Content != "Hello" {
+		t.Errorf("Expected the growing assistant message, got %+v", view.Messages)
+	}
+	if view.Metadata["backend"] != "primary" {
+		t.Errorf("Expected the metadata to be kept, got %v", view.Metadata)
+	}
+}
+
+func TestPromptMessages(t *testing.T) {
+	audio := CreateMultiModalMessage(User, Audio([]byte("RIFF"), "audio/wav"))
+	transcribed := audio
+	transcribed.Parts = nil
+	transcribed.Content = "Hello"
+	literal := Message{Role: User, Content: "no id"}
+
+	state := Conversation{Messages: []Message{CreateMessage(System, "Be brief"), transcribed}}
+	messages := PromptMessages(CreateConversation(audio, literal), state)
+
+	if len(messages) != 3 {
+		t.Fatalf("Expected the merged input not to be repeated, got %+v", messages)
+	}
+	if messages[1].Content != "Hello" || messages[1].Parts != nil {
+		t.Errorf("Expected the state version of the merged input, got %+v", messages[1])
+	}
+	if messages[2].Content != "no id" {
+		t.Errorf("Expected the input without identifier to be kept, got %+v", messages[2])
+	}
+
+	messages[0].Content = "changed"
+	if state.Messages[0].Content != "Be brief" {
+		t.Error("Expected a new slice")
+	}
+}
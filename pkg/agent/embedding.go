@@ -0,0 +1,199 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultEmbeddingBatchSize is the default number of texts sent in a single embedding request.
+	DefaultEmbeddingBatchSize = 64
+	// DefaultEmbeddingMaxRetries is the default number of retries of a rate-limited embedding request.
+	DefaultEmbeddingMaxRetries = 3
+	// DefaultEmbeddingRetryBackoff is the default wait before retrying a rate-limited request which carries no retry hint.
+	DefaultEmbeddingRetryBackoff = 500 * time.Millisecond
+)
+
+var (
+	// ErrorInvalidBatchSize is returned when the embedding batch size is not positive.
+	ErrorInvalidBatchSize = errors.New("batch size must be at least 1")
+	// ErrorInvalidMaxRetries is returned when the number of retries is negative.
+	ErrorInvalidMaxRetries = errors.New("max retries must be at least 0")
+	// ErrorInvalidRetryBackoff is returned when the retry backoff is not positive.
+	ErrorInvalidRetryBackoff = errors.New("retry backoff must be positive")
+	// ErrorInvalidDimensions is returned when the requested embedding dimensions are not positive.
+	ErrorInvalidDimensions = errors.New("dimensions must be at least 1")
+	// ErrRateLimited indicates that the provider rejected the request because of rate limits.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrEmbeddingCountMismatch indicates that the provider returned a number of embeddings different from the number of texts.
+	ErrEmbeddingCountMismatch = errors.New("embedding count does not match the input count")
+)
+
+// Embedder computes the vector embeddings of texts.
+type Embedder interface {
+	// Embed returns one embedding per text, in the order of the texts.
+	//
+	// Parameters:
+	//   - ctx: The context of the request.
+	//   - texts: The texts to embed.
+	//
+	// Returns:
+	//   - The embeddings.
+	//   - An error if any batch fails after the configured retries.
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// RateLimitError is returned by embedding providers when a request is rate limited.
+type RateLimitError struct {
+	// RetryAfter is the wait suggested by the provider, zero if unknown.
+	RetryAfter time.Duration
+	// Err is the provider error.
+	Err error
+}
+
+// Error returns the error message.
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrRateLimited, e.Err)
+}
+
+// Unwrap returns the provider error.
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is ErrRateLimited.
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+// EmbedderOptions defines the parameters of the embedding requests.
+type EmbedderOptions struct {
+	// BatchSize is the maximum number of texts sent in a single request.
+	BatchSize int
+	// MaxRetries is the number of retries of a rate-limited request.
+	MaxRetries int
+	// RetryBackoff is the initial wait before retrying a rate-limited request, doubled at each retry.
+	RetryBackoff time.Duration
+	// Dimensions is the number of dimensions of the embeddings, for the models supporting it.
+	Dimensions *int64
+}
+
+// EmbedderOption defines an interface for applying options to embedders.
+type EmbedderOption interface {
+	// ApplyToEmbedder applies the option to the given EmbedderOptions.
+	//
+	// Parameters:
+	//   - r: A pointer to EmbedderOptions to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	ApplyToEmbedder(r *EmbedderOptions) error
+}
+
+// EmbedderOptionFunc is a function type that implements the EmbedderOption interface.
+type EmbedderOptionFunc func(*EmbedderOptions) error
+
+// ApplyToEmbedder applies the EmbedderOptionFunc to the given EmbedderOptions.
+//
+// Parameters:
+//   - r: A pointer to EmbedderOptions to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s EmbedderOptionFunc) ApplyToEmbedder(r *EmbedderOptions) error { return s(r) }
+
+// CreateEmbedderOptions creates the embedder options with the defaults and the given options applied.
+//
+// Parameters:
+//   - opts: The embedder options.
+//
+// Returns:
+//   - The EmbedderOptions.
+//   - An error if any option is invalid.
+//
+// Example usage:
+//
+//	options, err := CreateEmbedderOptions(WithEmbeddingBatchSize(16))
+func CreateEmbedderOptions(opts ...EmbedderOption) (*EmbedderOptions, error) {
+	rv := &EmbedderOptions{
+		BatchSize:    DefaultEmbeddingBatchSize,
+		MaxRetries:   DefaultEmbeddingMaxRetries,
+		RetryBackoff: DefaultEmbeddingRetryBackoff,
+	}
+	for _, opt := range opts {
+		if err := opt.ApplyToEmbedder(rv); err != nil {
+			return nil, err
+		}
+	}
+	return rv, nil
+}
+
+// WithEmbeddingBatchSize sets the maximum number of texts sent in a single request.
+//
+// Parameters:
+//   - size: The batch size.
+//
+// Returns:
+//   - An EmbedderOption that sets the batch size.
+//
+// Example usage:
+//
+//	option := WithEmbeddingBatchSize(16)
+func WithEmbeddingBatchSize(size int) EmbedderOption {
+	return EmbedderOptionFunc(func(r *EmbedderOptions) error {
+		if size < 1 {
+			return ErrorInvalidBatchSize
+		}
+		r.BatchSize = size
+		return nil
+	})
+}
+
+// WithEmbeddingRetries sets the retry policy of rate-limited requests.
+//
+// Parameters:
+//   - maxRetries: The number of retries, 0 disables them.
+//   - backoff: The initial wait, doubled at each retry; a provider retry hint takes precedence.
+//
+// Returns:
+//   - An EmbedderOption that sets the retry policy.
+//
+// Example usage:
+//
+//	option := WithEmbeddingRetries(5, time.Second)
+func WithEmbeddingRetries(maxRetries int, backoff time.Duration) EmbedderOption {
+	return EmbedderOptionFunc(func(r *EmbedderOptions) error {
+		if maxRetries < 0 {
+			return ErrorInvalidMaxRetries
+		}
+		if backoff <= 0 {
+			return ErrorInvalidRetryBackoff
+		}
+		r.MaxRetries = maxRetries
+		r.RetryBackoff = backoff
+		return nil
+	})
+}
+
+// WithEmbeddingDimensions sets the number of dimensions of the embeddings.
+//
+// Parameters:
+//   - dimensions: The number of dimensions.
+//
+// Returns:
+//   - An EmbedderOption that sets the dimensions.
+//
+// Example usage:
+//
+//	option := WithEmbeddingDimensions(256)
+func WithEmbeddingDimensions(dimensions int64) EmbedderOption {
+	return EmbedderOptionFunc(func(r *EmbedderOptions) error {
+		if dimensions < 1 {
+			return ErrorInvalidDimensions
+		}
+		r.Dimensions = &dimensions
+		return nil
+	})
+}
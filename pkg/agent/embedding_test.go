@@ -0,0 +1,57 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCreateEmbedderOptions(t *testing.T) {
+	opts, err := CreateEmbedderOptions()
+	if err != nil {
+		t.Fatalf("CreateEmbedderOptions() failed: %v", err)
+	}
+	if opts.BatchSize != DefaultEmbeddingBatchSize || opts.MaxRetries != DefaultEmbeddingMaxRetries || opts.RetryBackoff != DefaultEmbeddingRetryBackoff {
+		t.Errorf("Unexpected defaults %+v", opts)
+	}
+
+	opts, err = CreateEmbedderOptions(WithEmbeddingBatchSize(8), WithEmbeddingRetries(0, time.Second), WithEmbeddingDimensions(256))
+	if err != nil {
+		t.Fatalf("CreateEmbedderOptions() failed: %v", err)
+	}
+	if opts.BatchSize != 8 || opts.MaxRetries != 0 || opts.RetryBackoff != time.Second || *opts.Dimensions != 256 {
+		t.Errorf("Unexpected options %+v", opts)
+	}
+
+	invalid := []struct {
+		option   EmbedderOption
+		expected error
+	}{
+		{WithEmbeddingBatchSize(0), ErrorInvalidBatchSize},
+		{WithEmbeddingRetries(-1, time.Second), ErrorInvalidMaxRetries},
+		{WithEmbeddingRetries(1, 0), ErrorInvalidRetryBackoff},
+		{WithEmbeddingDimensions(0), ErrorInvalidDimensions},
+	}
+	for _, tc := range invalid {
+		if _, err := CreateEmbedderOptions(tc.option); !errors.Is(err, tc.expected) {
+			t.Errorf("Expected %v, got %v", tc.expected, err)
+		}
+	}
+}
+
+func TestRateLimitError(t *testing.T) {
+	cause := errors.New("429")
+	err := fmt.Errorf("wrapped: %w", &RateLimitError{RetryAfter: time.Second, Err: cause})
+
+	if !errors.Is(err, ErrRateLimited) {
+		t.Error("Expected the error to match ErrRateLimited")
+	}
+	if !errors.Is(err, cause) {
+		t.Error("Expected the error to wrap the provider error")
+	}
+	var rateLimitErr *RateLimitError
+	if !errors.As(err, &rateLimitErr) || rateLimitErr.RetryAfter != time.Second {
+		t.Errorf("Expected the retry hint, got %v", rateLimitErr)
+	}
+}
@@ -23,3 +23,24 @@ import (
 func CreateToolNode(name string, tools ...*pt.Tool) (g.Node[a.Conversation], error) {
 	return t.NodeToolFactory(name, tools...)
 }
+
+// CreateRegistryToolNode creates a new Node processing tool calls with the tools of a registry.
+//
+// The tools matching the patterns are selected at each call, so that the tools registered at
+// runtime are available; pair it with a model node configured with a.WithToolsFrom and the same patterns.
+//
+// Parameters:
+//   - name: The unique name for the tool node.
+//   - registry: The tool registry.
+//   - patterns: The patterns of the tools, all the tools when empty, see pt.Registry.Select.
+//
+// Returns:
+//   - An instance of g.Node[a.Conversation] configured for tool processing.
+//   - An error if the registry is nil.
+//
+// Example usage:
+//
+//	toolNode, err := CreateRegistryToolNode("FileTools", registry, "fs.*")
+func CreateRegistryToolNode(name string, registry *pt.Registry, patterns ...string) (g.Node[a.Conversation], error) {
+	return t.NodeRegistryToolFactory(name, registry, patterns...)
+}
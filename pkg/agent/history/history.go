@@ -0,0 +1,179 @@
+// Package history keeps the conversations of the threads apart from the graph state.
+//
+// A Store appends the messages of a thread and reads them back, the latest ones or the ones
+// matching a query; NewMemoryStore keeps them in memory, the sqlite and redis packages persist
+// them. With WithHistory the conversation nodes load the history of the thread of the invocation
+// before asking the model and save the new messages afterwards, so that the chat history does not
+// depend on the persistence of the graph state.
+//
+// Example:
+//
+//	store := history.NewMemoryStore()
+//	node, err := openai.CreateToolAgentNode("Agent", "gpt-4o-mini", client, openai.DefaultToolAgentMaxIterations,
+//	    history.WithHistory(store, 50))
+//	runtime.Invoke(a.CreateConversation(a.CreateMessage(a.User, "Hi")), g.InvokeConfigThreadID("thread-1"))
+package history
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+)
+
+// ErrStoreNil indicates that the provided history store is nil.
+var ErrStoreNil = errors.New("history store cannot be nil")
+
+// Store keeps the messages of the threads.
+type Store interface {
+	// Append appends the messages to the thread.
+	//
+	// Parameters:
+	//   - ctx: The context of the request.
+	//   - threadID: The thread of the conversation.
+	//   - messages: The messages, in chronological order.
+	//
+	// Returns:
+	//   - An error if the messages could not be stored.
+	Append(ctx context.Context, threadID string, messages ...a.Message) error
+	// Last returns the latest messages of the thread.
+	//
+	// Parameters:
+	//   - ctx: The context of the request.
+	//   - threadID: The thread of the conversation.
+	//   - n: The maximum number of messages, zero or negative for all of them.
+	//
+	// Returns:
+	//   - The messages, in chronological order; none for an unknown thread.
+	//   - An error if the messages could not be read.
+	Last(ctx context.Context, threadID string, n int) ([]a.Message, error)
+	// Search returns the latest messages of the thread whose content matches the query, see Matches.
+	//
+	// Parameters:
+	//   - ctx: The context of the request.
+	//   - threadID: The thread of the conversation.
+	//   - query: The text to look for.
+	//   - limit: The maximum number of messages, zero or negative for all of them.
+	//
+	// Returns:
+	//   - The matching messages, in chronological order.
+	//   - An error if the messages could not be read.
+	Search(ctx context.Context, threadID, query string, limit int) ([]a.Message, error)
+}
+
+// Matches reports whether the content of the message contains the query, ignoring the case.
+//
+// Parameters:
+//   - message: The message.
+//   - query: The text to look for.
+//
+// Returns:
+//   - true if the message matches the query.
+func Matches(message a.Message, query string) bool {
+	return strings.Contains(strings.ToLower(message.Content), strings.ToLower(query))
+}
+
+// WithHistory loads the latest messages of the thread from the store before the request, and appends the new messages after it.
+//
+// Parameters:
+//   - store: The history store.
+//   - lastN: The maximum number of messages loaded, zero or negative for the whole history.
+//
+// Returns:
+//   - A ModelOption that sets the conversation history, see a.WithConversationHistory.
+//
+// Example:
+//
+//	node, err := openai.CreateToolAgentNode("Agent", "gpt-4o-mini", client, 5, history.WithHistory(store, 50))
+func WithHistory(store Store, lastN int) a.ModelOption {
+	return a.ModelOptionFunc(func(r *a.ModelOptions) error {
+		if store == nil {
+			return ErrStoreNil
+		}
+		return a.WithConversationHistory(&storeHistory{store: store, lastN: lastN}).ApplyToConversation(r)
+	})
+}
+
+type storeHistory struct {
+	store Store
+	lastN int
+}
+
+func (h *storeHistory) Load(ctx context.Context, threadID string) ([]a.Message, error) {
+	return h.store.Last(ctx, threadID, h.lastN)
+}
+
+func (h *storeHistory) Save(ctx context.Context, threadID string, messages []a.Message) error {
+	return h.store.Append(ctx, threadID, messages...)
+}
+
+// ------------------------------------------------------------------------------
+// In-memory Store Implementation
+// ------------------------------------------------------------------------------
+
+var _ Store = (*MemoryStore)(nil)
+
+// MemoryStore is a Store keeping the messages in memory, for the tests and the single process deployments.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	messages map[string][]a.Message
+}
+
+// NewMemoryStore creates an in-memory Store.
+//
+// Returns:
+//   - The store.
+//
+// Example:
+//
+//	store := history.NewMemoryStore()
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{messages: make(map[string][]a.Message)}
+}
+
+// Append appends the messages to the thread.
+func (s *MemoryStore) Append(_ context.Context, threadID string, messages ...a.Message) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, message := range messages {
+		s.messages[threadID] = append(s.messages[threadID], message.Clone())
+	}
+	return nil
+}
+
+// Last returns the latest messages of the thread.
+func (s *MemoryStore) Last(_ context.Context, threadID string, n int) ([]a.Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	messages := s.messages[threadID]
+	if n > 0 && len(messages) > n {
+		messages = messages[len(messages)-n:]
+	}
+	return cloneMessages(messages), nil
+}
+
+// Search returns the latest messages of the thread whose content matches the query.
+func (s *MemoryStore) Search(_ context.Context, threadID, query string, limit int) ([]a.Message, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var rv []a.Message
+	messages := s.messages[threadID]
+	for idx := len(messages) - 1; idx >= 0 && (limit <= 0 || len(rv) < limit); idx-- {
+		if Matches(messages[idx], query) {
+			rv = append(rv, messages[idx].Clone())
+		}
+	}
+	slices.Reverse(rv)
+	return rv, nil
+}
+
+func cloneMessages(messages []a.Message) []a.Message {
+	rv := make([]a.Message, len(messages))
+	for idx, message := range messages {
+		rv[idx] = message.Clone()
+	}
+	return rv
+}
@@ -0,0 +1,92 @@
+package history_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/agent/history"
+)
+
+func contents(messages []a.Message) []string {
+	rv := make([]string, len(messages))
+	for idx, message := range messages {
+		rv[idx] = message.Content
+	}
+	return rv
+}
+
+func equal(got []a.Message, want ...string) bool {
+	gotContents := contents(got)
+	if len(gotContents) != len(want) {
+		return false
+	}
+	for idx := range want {
+		if gotContents[idx] != want[idx] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestMemoryStore(t *testing.T) {
+	store := history.NewMemoryStore()
+	ctx := context.Background()
+
+	if messages, err := store.Last(ctx, "unknown", 10); err != nil || len(messages) != 0 {
+		t.Errorf("Expected no messages for an unknown thread, got %v %v", messages, err)
+	}
+
+	err := store.Append(ctx, "thread-1",
+		a.CreateMessage(a.User, "Weather in Rome?"),
+		a.CreateMessage(a.Assistant, "Sunny in Rome."),
+		a.CreateMessage(a.User, "And in Milan?"),
+		a.CreateMessage(a.Assistant, "Foggy in Milan."))
+	if err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	_ = store.Append(ctx, "thread-2", a.CreateMessage(a.User, "Rome again"))
+
+	if got, _ := store.Last(ctx, "thread-1", 2); !equal(got, "And in Milan?", "Foggy in Milan.") {
+		t.Errorf("Last(2) = %v", contents(got))
+	}
+	if got, _ := store.Last(ctx, "thread-1", 0); len(got) != 4 {
+		t.Errorf("Last(0) = %v, want the whole thread", contents(got))
+	}
+	if got, _ := store.Search(ctx, "thread-1", "rome", 0); !equal(got, "Weather in Rome?", "Sunny in Rome.") {
+		t.Errorf("Search(rome) = %v", contents(got))
+	}
+	if got, _ := store.Search(ctx, "thread-1", "in", 1); !equal(got, "Foggy in Milan.") {
+		t.Errorf("Search(in, 1) = %v, want the latest match", contents(got))
+	}
+
+	got, _ := store.Last(ctx, "thread-1", 1)
+	got[0].Content = "changed"
+	if again, _ := store.Last(ctx, "thread-1", 1); again[0].Content != "Foggy in Milan." {
+		t.Error("Expected the stored messages to be copied")
+	}
+}
+
+func TestWithHistory(t *testing.T) {
+	if _, err := a.CreateConversationOptions("gpt", nil, history.WithHistory(nil, 10)); !errors.Is(err, history.ErrStoreNil) {
+		t.Errorf("Expected ErrStoreNil, got %v", err)
+	}
+
+	store := history.NewMemoryStore()
+	_ = store.Append(context.Background(), "thread-1", a.CreateMessage(a.User, "one"), a.CreateMessage(a.User, "two"))
+	opts, err := a.CreateConversationOptions("gpt", nil, history.WithHistory(store, 1))
+	if err != nil {
+		t.Fatalf("CreateConversationOptions failed: %v", err)
+	}
+	got, err := opts.History.Load(context.Background(), "thread-1")
+	if err != nil || !equal(got, "two") {
+		t.Errorf("Expected the last message to be loaded, got %v %v", contents(got), err)
+	}
+	if err := opts.History.Save(context.Background(), "thread-1", []a.Message{a.CreateMessage(a.Assistant, "three")}); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	if got, _ := store.Last(context.Background(), "thread-1", 0); !equal(got, "one", "two", "three") {
+		t.Errorf("Expected the message to be appended, got %v", contents(got))
+	}
+}
@@ -0,0 +1,107 @@
+package redis
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// DefaultKeyPrefix is the default prefix of the keys holding the messages of the threads.
+	DefaultKeyPrefix = "ggraph:history:"
+)
+
+var (
+	// ErrClientNil indicates that the provided Redis client is nil.
+	ErrClientNil = errors.New("redis client cannot be nil")
+	// ErrInvalidKeyPrefix indicates that the provided key prefix is empty.
+	ErrInvalidKeyPrefix = errors.New("key prefix cannot be empty")
+	// ErrInvalidMaxLength indicates that the provided maximum length is not positive.
+	ErrInvalidMaxLength = errors.New("max length must be positive")
+	// ErrInvalidTTL indicates that the provided time to live is not positive.
+	ErrInvalidTTL = errors.New("ttl must be positive")
+)
+
+// Options holds the configuration for the Redis history Store implementation.
+type Options struct {
+	// KeyPrefix is prepended to the thread identifiers, to share a Redis database with other applications.
+	KeyPrefix string
+	// MaxLength is the maximum number of messages kept per thread, the oldest ones are trimmed; 0 for no limit.
+	MaxLength int
+	// TTL is the time to live of a thread since its last message, 0 for no expiration.
+	TTL time.Duration
+}
+
+// Option is a functional option for configuring the Redis history Store implementation.
+type Option interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - r: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *Options) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc func(*Options) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - r: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc) Apply(r *Options) error { return s(r) }
+
+// WithKeyPrefix sets the prefix of the keys holding the messages of the threads.
+//
+// Parameters:
+//   - prefix: The key prefix.
+//
+// Returns:
+//   - An Option that sets the key prefix.
+func WithKeyPrefix(prefix string) Option {
+	return OptionFunc(func(r *Options) error {
+		if prefix == "" {
+			return ErrInvalidKeyPrefix
+		}
+		r.KeyPrefix = prefix
+		return nil
+	})
+}
+
+// WithMaxLength bounds the messages kept per thread, trimming the oldest ones on append.
+//
+// Parameters:
+//   - maxLength: The maximum number of messages per thread.
+//
+// Returns:
+//   - An Option that sets the maximum length.
+func WithMaxLength(maxLength int) Option {
+	return OptionFunc(func(r *Options) error {
+		if maxLength <= 0 {
+			return ErrInvalidMaxLength
+		}
+		r.MaxLength = maxLength
+		return nil
+	})
+}
+
+// WithTTL expires the threads left without new messages, the expiration is delegated to the Redis TTL.
+//
+// Parameters:
+//   - ttl: The time to live of a thread since its last message.
+//
+// Returns:
+//   - An Option that sets the time to live.
+func WithTTL(ttl time.Duration) Option {
+	return OptionFunc(func(r *Options) error {
+		if ttl <= 0 {
+			return ErrInvalidTTL
+		}
+		r.TTL = ttl
+		return nil
+	})
+}
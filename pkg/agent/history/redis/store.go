@@ -0,0 +1,138 @@
+// Package redis provides a history Store backed by Redis.
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/agent/history"
+)
+
+var _ history.Store = (*Store)(nil)
+
+// Store keeps the messages of each thread in a Redis list of JSON serialized messages.
+type Store struct {
+	client goredis.UniversalClient
+	opts   Options
+}
+
+// NewStore creates a history Store on the given Redis client.
+//
+// Parameters:
+//   - client: The Redis client, standalone, sentinel or cluster.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - The store.
+//   - An error if the client is nil or an option is invalid.
+//
+// Example:
+//
+//	client := goredis.NewClient(&goredis.Options{Addr: "localhost:6379"})
+//	store, err := redis.NewStore(client, redis.WithMaxLength(500), redis.WithTTL(30*24*time.Hour))
+//	option := history.WithHistory(store, 50)
+func NewStore(client goredis.UniversalClient, opts ...Option) (*Store, error) {
+	if client == nil {
+		return nil, fmt.Errorf("redis history store creation failed: %w", ErrClientNil)
+	}
+
+	useOpts := &Options{
+		KeyPrefix: DefaultKeyPrefix,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("redis history store creation failed: %w", err)
+		}
+	}
+
+	return &Store{client: client, opts: *useOpts}, nil
+}
+
+// Append appends the messages to the thread, in a single transaction.
+func (s *Store) Append(ctx context.Context, threadID string, messages ...a.Message) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	values := make([]any, len(messages))
+	for idx, message := range messages {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return fmt.Errorf("cannot serialize a message of thread %s: %w", threadID, err)
+		}
+		values[idx] = data
+	}
+
+	key := s.opts.KeyPrefix + threadID
+	_, err := s.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.RPush(ctx, key, values...)
+		if s.opts.MaxLength > 0 {
+			pipe.LTrim(ctx, key, int64(-s.opts.MaxLength), -1)
+		}
+		if s.opts.TTL > 0 {
+			pipe.Expire(ctx, key, s.opts.TTL)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("cannot append the messages of thread %s: %w", threadID, err)
+	}
+	return nil
+}
+
+// Last returns the latest messages of the thread.
+func (s *Store) Last(ctx context.Context, threadID string, n int) ([]a.Message, error) {
+	start := int64(0)
+	if n > 0 {
+		start = int64(-n)
+	}
+	values, err := s.client.LRange(ctx, s.opts.KeyPrefix+threadID, start, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the messages of thread %s: %w", threadID, err)
+	}
+
+	rv := make([]a.Message, 0, len(values))
+	for _, value := range values {
+		message, err := decode(threadID, value)
+		if err != nil {
+			return nil, err
+		}
+		rv = append(rv, message)
+	}
+	return rv, nil
+}
+
+// Search returns the latest messages of the thread whose content matches the query, see history.Matches.
+//
+// Redis does not index the messages: the whole thread is read and filtered.
+func (s *Store) Search(ctx context.Context, threadID, query string, limit int) ([]a.Message, error) {
+	values, err := s.client.LRange(ctx, s.opts.KeyPrefix+threadID, 0, -1).Result()
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the messages of thread %s: %w", threadID, err)
+	}
+
+	var rv []a.Message
+	for idx := len(values) - 1; idx >= 0 && (limit <= 0 || len(rv) < limit); idx-- {
+		message, err := decode(threadID, values[idx])
+		if err != nil {
+			return nil, err
+		}
+		if history.Matches(message, query) {
+			rv = append(rv, message)
+		}
+	}
+	slices.Reverse(rv)
+	return rv, nil
+}
+
+func decode(threadID, value string) (a.Message, error) {
+	var rv a.Message
+	if err := json.Unmarshal([]byte(value), &rv); err != nil {
+		return a.Message{}, fmt.Errorf("cannot deserialize a message of thread %s: %w", threadID, err)
+	}
+	return rv, nil
+}
@@ -0,0 +1,85 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/agent/history/redis"
+)
+
+func TestNewStore_Errors(t *testing.T) {
+	if _, err := redis.NewStore(nil); !errors.Is(err, redis.ErrClientNil) {
+		t.Errorf("Expected ErrClientNil, got %v", err)
+	}
+
+	client := goredis.NewClient(&goredis.Options{Addr: "localhost:0"})
+	defer client.Close()
+	if _, err := redis.NewStore(client, redis.WithKeyPrefix("")); !errors.Is(err, redis.ErrInvalidKeyPrefix) {
+		t.Errorf("Expected ErrInvalidKeyPrefix, got %v", err)
+	}
+	if _, err := redis.NewStore(client, redis.WithMaxLength(0)); !errors.Is(err, redis.ErrInvalidMaxLength) {
+		t.Errorf("Expected ErrInvalidMaxLength, got %v", err)
+	}
+	if _, err := redis.NewStore(client, redis.WithTTL(0)); !errors.Is(err, redis.ErrInvalidTTL) {
+		t.Errorf("Expected ErrInvalidTTL, got %v", err)
+	}
+}
+
+func TestStore(t *testing.T) {
+	server := miniredis.RunT(t)
+	client := goredis.NewClient(&goredis.Options{Addr: server.Addr(), MaxRetries: -1})
+	defer client.Close()
+
+	store, err := redis.NewStore(client, redis.WithKeyPrefix("test:"), redis.WithMaxLength(3), redis.WithTTL(time.Hour))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	ctx := context.Background()
+
+	if got, err := store.Last(ctx, "unknown", 5); err != nil || len(got) != 0 {
+		t.Errorf("Expected no messages for an unknown thread, got %v %v", got, err)
+	}
+
+	first := a.CreateMessage(a.User, "Weather in Rome?")
+	first.Metadata = map[string]string{"tenant": "acme"}
+	err = store.Append(ctx, "thread-1",
+		first,
+		a.CreateMessage(a.Assistant, "Sunny in Rome."),
+		a.CreateMessage(a.User, "And in Milan?"))
+	if err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if !server.Exists("test:thread-1") || server.TTL("test:thread-1") != time.Hour {
+		t.Error("Expected the thread key to be prefixed and to expire")
+	}
+
+	got, err := store.Last(ctx, "thread-1", 0)
+	if err != nil || len(got) != 3 || got[0].ID != first.ID || got[0].Metadata["tenant"] != "acme" || got[2].Content != "And in Milan?" {
+		t.Errorf("Expected the messages to survive the round trip, got %+v %v", got, err)
+	}
+	if got, _ := store.Search(ctx, "thread-1", "ROME", 1); len(got) != 1 || got[0].Content != "Sunny in Rome." {
+		t.Errorf("Expected the latest match, got %+v", got)
+	}
+
+	if err := store.Append(ctx, "thread-1", a.CreateMessage(a.Assistant, "Foggy in Milan.")); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	got, _ = store.Last(ctx, "thread-1", 2)
+	if len(got) != 2 || got[0].Content != "And in Milan?" || got[1].Content != "Foggy in Milan." {
+		t.Errorf("Unexpected latest messages %+v", got)
+	}
+	if got, _ := store.Last(ctx, "thread-1", 0); len(got) != 3 || got[0].Content != "Sunny in Rome." {
+		t.Errorf("Expected the oldest message to be trimmed, got %+v", got)
+	}
+
+	server.Close()
+	if _, err := store.Last(ctx, "thread-1", 1); err == nil {
+		t.Error("Expected an error when Redis is down")
+	}
+}
@@ -0,0 +1,85 @@
+package sqlite
+
+import (
+	"errors"
+
+	"github.com/morphy76/ggraph/internal/sqlident"
+)
+
+const (
+	// DriverName is the name of the CGO-free SQLite driver registered by the package.
+	DriverName = "sqlite"
+	// DefaultTableName is the default name of the table holding the messages.
+	DefaultTableName = "ggraph_messages"
+)
+
+var (
+	// ErrDBNil indicates that the provided database handle is nil.
+	ErrDBNil = errors.New("database handle cannot be nil")
+	// ErrInvalidTableName indicates that the provided table name is not a valid SQL identifier.
+	ErrInvalidTableName = errors.New("invalid table name")
+)
+
+// Options holds the configuration for the SQLite history Store implementation.
+type Options struct {
+	// TableName is the name of the append-only table holding one row per message.
+	TableName string
+	// SkipMigration disables the automatic creation of the table.
+	SkipMigration bool
+}
+
+// Option is a functional option for configuring the SQLite history Store implementation.
+type Option interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - r: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *Options) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc func(*Options) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - r: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc) Apply(r *Options) error { return s(r) }
+
+// WithTableName sets the name of the table holding the messages.
+//
+// Parameters:
+//   - name: The table name, it must be a valid SQL identifier.
+//
+// Returns:
+//   - An Option that sets the table name.
+//
+// Example:
+//
+//	store, err := sqlite.NewStore(db, sqlite.WithTableName("chat_messages"))
+func WithTableName(name string) Option {
+	return OptionFunc(func(r *Options) error {
+		if !sqlident.Valid(name) {
+			return ErrInvalidTableName
+		}
+		r.TableName = name
+		return nil
+	})
+}
+
+// WithoutMigration disables the automatic creation of the table.
+//
+// Returns:
+//   - An Option that disables the schema migration.
+func WithoutMigration() Option {
+	return OptionFunc(func(r *Options) error {
+		r.SkipMigration = true
+		return nil
+	})
+}
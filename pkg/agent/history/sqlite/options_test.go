@@ -0,0 +1,55 @@
+package sqlite_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/morphy76/ggraph/pkg/agent/history/sqlite"
+)
+
+func TestNewStore_NilDB(t *testing.T) {
+	store, err := sqlite.NewStore(nil)
+	if !errors.Is(err, sqlite.ErrDBNil) {
+		t.Errorf("Expected ErrDBNil, got %v", err)
+	}
+	if store != nil {
+		t.Error("Expected nil store when database is nil")
+	}
+}
+
+func TestWithTableName(t *testing.T) {
+	tests := []struct {
+		name    string
+		table   string
+		wantErr bool
+	}{
+		{name: "valid", table: "chat_messages"},
+		{name: "valid with digits", table: "messages_2"},
+		{name: "empty", table: "", wantErr: true},
+		{name: "leading digit", table: "2messages", wantErr: true},
+		{name: "injection", table: "messages; DROP TABLE x", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &sqlite.Options{}
+			err := sqlite.WithTableName(tt.table).Apply(opts)
+			if tt.wantErr {
+				if !errors.Is(err, sqlite.ErrInvalidTableName) {
+					t.Errorf("Expected ErrInvalidTableName, got %v", err)
+				}
+				return
+			}
+			if err != nil || opts.TableName != tt.table {
+				t.Errorf("Expected table %s, got %s %v", tt.table, opts.TableName, err)
+			}
+		})
+	}
+}
+
+func TestWithoutMigration(t *testing.T) {
+	opts := &sqlite.Options{}
+	if err := sqlite.WithoutMigration().Apply(opts); err != nil || !opts.SkipMigration {
+		t.Errorf("Expected the migration to be skipped, got %+v %v", opts, err)
+	}
+}
@@ -0,0 +1,169 @@
+// Package sqlite provides a history Store backed by an embedded SQLite database.
+//
+// The package registers the CGO-free modernc.org/sqlite driver as DriverName: open the
+// database with it and hand the *sql.DB over to NewStore.
+//
+// Example:
+//
+//	db, _ := sql.Open(sqlite.DriverName, "file:chat.db?_pragma=journal_mode(WAL)")
+//	store, err := sqlite.NewStore(db)
+//	node, err := openai.CreateToolAgentNode("Agent", "gpt-4o-mini", client, 5, history.WithHistory(store, 50))
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/agent/history"
+)
+
+var _ history.Store = (*Store)(nil)
+
+// Store is a history Store keeping one JSON serialized row per message.
+type Store struct {
+	db   *sql.DB
+	opts Options
+}
+
+// NewStore creates a history Store on the given database.
+//
+// Parameters:
+//   - db: An open SQLite database handle.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - The SQLite history Store.
+//   - An error if the options are invalid or the schema cannot be created.
+//
+// Example:
+//
+//	store, err := sqlite.NewStore(db)
+func NewStore(db *sql.DB, opts ...Option) (*Store, error) {
+	if db == nil {
+		return nil, fmt.Errorf("sqlite history store creation failed: %w", ErrDBNil)
+	}
+
+	useOpts := &Options{
+		TableName: DefaultTableName,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("sqlite history store creation failed: %w", err)
+		}
+	}
+
+	rv := &Store{db: db, opts: *useOpts}
+	if !useOpts.SkipMigration {
+		if err := rv.migrate(context.Background()); err != nil {
+			return nil, fmt.Errorf("sqlite history store creation failed: %w", err)
+		}
+	}
+	return rv, nil
+}
+
+// Append appends the messages to the thread, in a single transaction.
+func (s *Store) Append(ctx context.Context, threadID string, messages ...a.Message) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("cannot append the messages of thread %s: %w", threadID, err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC().UnixNano()
+	for _, message := range messages {
+		data, err := json.Marshal(message)
+		if err != nil {
+			return fmt.Errorf("cannot serialize a message of thread %s: %w", threadID, err)
+		}
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO "+s.opts.TableName+" (thread_id, message_id, content, message, appended_at) VALUES (?, ?, ?, ?, ?)",
+			threadID, message.ID, message.Content, data, now)
+		if err != nil {
+			return fmt.Errorf("cannot append the messages of thread %s: %w", threadID, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// Last returns the latest messages of the thread.
+func (s *Store) Last(ctx context.Context, threadID string, n int) ([]a.Message, error) {
+	return s.query(ctx, threadID,
+		"SELECT message FROM "+s.opts.TableName+" WHERE thread_id = ? ORDER BY seq DESC LIMIT ?",
+		threadID, limitOf(n))
+}
+
+// Search returns the latest messages of the thread whose content matches the query.
+//
+// The query is matched with LIKE, which ignores the case of the ASCII letters only.
+func (s *Store) Search(ctx context.Context, threadID, query string, limit int) ([]a.Message, error) {
+	return s.query(ctx, threadID,
+		"SELECT message FROM "+s.opts.TableName+" WHERE thread_id = ? AND content LIKE ? ESCAPE '\\' ORDER BY seq DESC LIMIT ?",
+		threadID, "%"+escapeLike(query)+"%", limitOf(limit))
+}
+
+func (s *Store) query(ctx context.Context, threadID, query string, args ...any) ([]a.Message, error) {
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read the messages of thread %s: %w", threadID, err)
+	}
+	defer rows.Close()
+
+	var rv []a.Message
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("cannot read the messages of thread %s: %w", threadID, err)
+		}
+		var message a.Message
+		if err := json.Unmarshal(data, &message); err != nil {
+			return nil, fmt.Errorf("cannot deserialize a message of thread %s: %w", threadID, err)
+		}
+		rv = append(rv, message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read the messages of thread %s: %w", threadID, err)
+	}
+	slices.Reverse(rv)
+	return rv, nil
+}
+
+func (s *Store) migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx,
+		"CREATE TABLE IF NOT EXISTS "+s.opts.TableName+" ("+
+			"seq INTEGER PRIMARY KEY AUTOINCREMENT, "+
+			"thread_id TEXT NOT NULL, "+
+			"message_id TEXT NOT NULL, "+
+			"content TEXT NOT NULL, "+
+			"message BLOB NOT NULL, "+
+			"appended_at INTEGER NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("cannot create table %s: %w", s.opts.TableName, err)
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		"CREATE INDEX IF NOT EXISTS "+s.opts.TableName+"_thread_idx ON "+s.opts.TableName+" (thread_id, seq)")
+	if err != nil {
+		return fmt.Errorf("cannot create index on table %s: %w", s.opts.TableName, err)
+	}
+	return nil
+}
+
+// limitOf maps the unbounded limits to the SQLite LIMIT without bound.
+func limitOf(n int) int {
+	if n <= 0 {
+		return -1
+	}
+	return n
+}
+
+func escapeLike(query string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(query)
+}
@@ -0,0 +1,61 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/agent/history/sqlite"
+)
+
+func TestStore(t *testing.T) {
+	db, err := sql.Open(sqlite.DriverName, ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open failed: %v", err)
+	}
+	// A single connection keeps the in-memory database alive across the statements
+	db.SetMaxOpenConns(1)
+	defer db.Close()
+
+	store, err := sqlite.NewStore(db, sqlite.WithTableName("messages"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	ctx := context.Background()
+
+	if got, err := store.Last(ctx, "unknown", 5); err != nil || len(got) != 0 {
+		t.Errorf("Expected no messages for an unknown thread, got %v %v", got, err)
+	}
+
+	first := a.CreateMessage(a.User, "Weather in Rome?")
+	first.Metadata = map[string]string{"tenant": "acme"}
+	err = store.Append(ctx, "thread-1",
+		first,
+		a.CreateMessage(a.Assistant, "Sunny in Rome, 100% chance of sun."),
+		a.CreateMessage(a.User, "And in Milan?"))
+	if err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	if err := store.Append(ctx, "thread-2", a.CreateMessage(a.User, "Weather in Rome?")); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+
+	got, err := store.Last(ctx, "thread-1", 0)
+	if err != nil || len(got) != 3 || got[0].ID != first.ID || got[0].Metadata["tenant"] != "acme" || got[2].Content != "And in Milan?" {
+		t.Errorf("Expected the messages to survive the round trip, got %+v %v", got, err)
+	}
+	if got, _ := store.Last(ctx, "thread-1", 2); len(got) != 2 || got[0].Role != a.Assistant || got[1].Content != "And in Milan?" {
+		t.Errorf("Expected the latest messages oldest first, got %+v", got)
+	}
+
+	if got, _ := store.Search(ctx, "thread-1", "ROME", 1); len(got) != 1 || got[0].Role != a.Assistant {
+		t.Errorf("Expected the latest case insensitive match, got %+v", got)
+	}
+	if got, _ := store.Search(ctx, "thread-1", "100%", 0); len(got) != 1 {
+		t.Errorf("Expected the wildcards of the query to be matched literally, got %+v", got)
+	}
+	if got, _ := store.Search(ctx, "thread-1", "_", 0); len(got) != 0 {
+		t.Errorf("Expected no match, got %+v", got)
+	}
+}
@@ -0,0 +1,398 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	ir "github.com/morphy76/ggraph/internal/agent/ratelimit"
+)
+
+const (
+	// DefaultLimiterMaxRetries is the default number of retries of a rate-limited request.
+	DefaultLimiterMaxRetries = 3
+	// DefaultLimiterRetryBackoff is the default wait before retrying a rate-limited request without Retry-After.
+	DefaultLimiterRetryBackoff = time.Second
+	// DefaultLimiterMaxRetryWait is the default cap of the wait before retrying a rate-limited request.
+	DefaultLimiterMaxRetryWait = time.Minute
+)
+
+var (
+	// ErrInvalidRequestRate indicates that the requests per second or their burst are not positive.
+	ErrInvalidRequestRate = errors.New("requests per second and burst must be positive")
+	// ErrInvalidTokenRate indicates that the tokens per minute are not positive.
+	ErrInvalidTokenRate = errors.New("tokens per minute must be positive")
+	// ErrInvalidMaxInFlight indicates that the maximum number of in-flight requests is not positive.
+	ErrInvalidMaxInFlight = errors.New("max in-flight requests must be at least 1")
+	// ErrInvalidLimiterRetries indicates that the retry configuration of the limiter is invalid.
+	ErrInvalidLimiterRetries = errors.New("limiter retries and waits cannot be negative")
+)
+
+// LimiterOptions holds the configuration of a ProviderLimiter, zero values disable the related limit.
+type LimiterOptions struct {
+	// RequestsPerSecond is the sustained request rate.
+	RequestsPerSecond float64
+	// RequestBurst is the number of requests allowed at once above the sustained rate.
+	RequestBurst int
+	// TokensPerMinute is the sustained token rate, estimated from the request size.
+	TokensPerMinute int
+	// MaxInFlight is the maximum number of concurrent requests.
+	MaxInFlight int
+	// MaxRetries is the number of retries of a rate-limited request.
+	MaxRetries int
+	// RetryBackoff is the wait before the first retry when the provider gives no Retry-After, doubled at each retry.
+	RetryBackoff time.Duration
+	// MaxRetryWait caps the wait before a retry.
+	MaxRetryWait time.Duration
+}
+
+// LimiterOption is a functional option for configuring a ProviderLimiter.
+type LimiterOption interface {
+	// Apply applies the option to the LimiterOptions.
+	//
+	// Parameters:
+	//   - r: A pointer to LimiterOptions to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *LimiterOptions) error
+}
+
+// LimiterOptionFunc is a function type that implements the LimiterOption interface.
+type LimiterOptionFunc func(*LimiterOptions) error
+
+// Apply applies the LimiterOptionFunc to the given LimiterOptions.
+//
+// Parameters:
+//   - r: A pointer to LimiterOptions to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s LimiterOptionFunc) Apply(r *LimiterOptions) error { return s(r) }
+
+// WithRequestsPerSecond limits the request rate.
+//
+// Parameters:
+//   - rps: The sustained requests per second.
+//   - burst: The requests allowed at once above the sustained rate.
+//
+// Returns:
+//   - A LimiterOption that sets the request rate.
+func WithRequestsPerSecond(rps float64, burst int) LimiterOption {
+	return LimiterOptionFunc(func(r *LimiterOptions) error {
+		if rps <= 0 || burst < 1 {
+			return ErrInvalidRequestRate
+		}
+		r.RequestsPerSecond = rps
+		r.RequestBurst = burst
+		return nil
+	})
+}
+
+// WithTokensPerMinute limits the token rate, estimated from the size of the request body.
+//
+// Parameters:
+//   - tpm: The sustained tokens per minute.
+//
+// Returns:
+//   - A LimiterOption that sets the token rate.
+func WithTokensPerMinute(tpm int) LimiterOption {
+	return LimiterOptionFunc(func(r *LimiterOptions) error {
+		if tpm < 1 {
+			return ErrInvalidTokenRate
+		}
+		r.TokensPerMinute = tpm
+		return nil
+	})
+}
+
+// WithMaxInFlight limits the number of concurrent requests.
+//
+// Parameters:
+//   - n: The maximum number of in-flight requests.
+//
+// Returns:
+//   - A LimiterOption that sets the concurrency cap.
+func WithMaxInFlight(n int) LimiterOption {
+	return LimiterOptionFunc(func(r *LimiterOptions) error {
+		if n < 1 {
+			return ErrInvalidMaxInFlight
+		}
+		r.MaxInFlight = n
+		return nil
+	})
+}
+
+// WithLimiterRetries configures the retries of the rate-limited requests.
+//
+// Parameters:
+//   - maxRetries: The number of retries, 0 to disable them.
+//   - backoff: The wait before the first retry when the provider gives no Retry-After.
+//   - maxWait: The cap of the wait before a retry.
+//
+// Returns:
+//   - A LimiterOption that sets the retry policy.
+func WithLimiterRetries(maxRetries int, backoff, maxWait time.Duration) LimiterOption {
+	return LimiterOptionFunc(func(r *LimiterOptions) error {
+		if maxRetries < 0 || backoff < 0 || maxWait < 0 {
+			return ErrInvalidLimiterRetries
+		}
+		r.MaxRetries = maxRetries
+		r.RetryBackoff = backoff
+		r.MaxRetryWait = maxWait
+		return nil
+	})
+}
+
+// ProviderLimiter throttles the requests to a model provider, shared by all the nodes and threads using it.
+//
+// When the provider answers with a rate limit, every request through the limiter is held for the
+// Retry-After delay, not only the rejected one, and the rejected request is retried.
+type ProviderLimiter struct {
+	opts     LimiterOptions
+	requests *rate.Limiter
+	tokens   *rate.Limiter
+	inFlight chan struct{}
+
+	mu          sync.Mutex
+	pausedUntil time.Time
+}
+
+// NewProviderLimiter creates a limiter with the given limits.
+//
+// Parameters:
+//   - opts: The limits and retry policy, no limit is applied by default.
+//
+// Returns:
+//   - The ProviderLimiter.
+//   - An error if an option is invalid.
+//
+// Example:
+//
+//	limiter, err := agent.NewProviderLimiter(
+//	    agent.WithRequestsPerSecond(5, 10),
+//	    agent.WithTokensPerMinute(90000),
+//	    agent.WithMaxInFlight(8),
+//	)
+//	client := openai.NewOpenAIClient(apiKey, openai.WithLimiter(limiter))
+func NewProviderLimiter(opts ...LimiterOption) (*ProviderLimiter, error) {
+	useOpts := LimiterOptions{
+		MaxRetries:   DefaultLimiterMaxRetries,
+		RetryBackoff: DefaultLimiterRetryBackoff,
+		MaxRetryWait: DefaultLimiterMaxRetryWait,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(&useOpts); err != nil {
+			return nil, err
+		}
+	}
+
+	rv := &ProviderLimiter{opts: useOpts}
+	if useOpts.RequestsPerSecond > 0 {
+		rv.requests = rate.NewLimiter(rate.Limit(useOpts.RequestsPerSecond), useOpts.RequestBurst)
+	}
+	if useOpts.TokensPerMinute > 0 {
+		rv.tokens = rate.NewLimiter(rate.Limit(float64(useOpts.TokensPerMinute)/60), useOpts.TokensPerMinute)
+	}
+	if useOpts.MaxInFlight > 0 {
+		rv.inFlight = make(chan struct{}, useOpts.MaxInFlight)
+	}
+	return rv, nil
+}
+
+// Acquire waits until a request of the given size can be sent.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - tokens: The estimated tokens of the request, capped to the tokens per minute.
+//
+// Returns:
+//   - The function releasing the in-flight slot, to be called when the response is received.
+//   - An error if the context is done while waiting.
+func (l *ProviderLimiter) Acquire(ctx context.Context, tokens int) (func(), error) {
+	if err := l.waitPause(ctx); err != nil {
+		return nil, err
+	}
+
+	release := func() {}
+	if l.inFlight != nil {
+		select {
+		case l.inFlight <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		var once sync.Once
+		release = func() { once.Do(func() { <-l.inFlight }) }
+	}
+
+	if l.requests != nil {
+		if err := l.requests.Wait(ctx); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	if l.tokens != nil && tokens > 0 {
+		if err := l.tokens.WaitN(ctx, min(tokens, l.opts.TokensPerMinute)); err != nil {
+			release()
+			return nil, err
+		}
+	}
+	return release, nil
+}
+
+// Pause holds every request through the limiter for the given delay.
+//
+// Parameters:
+//   - d: The delay, e.g. the Retry-After of a rate-limited response.
+func (l *ProviderLimiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+// Do runs a provider call within the limits, retrying it when it fails with ErrRateLimited.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - tokens: The estimated tokens of the request.
+//   - call: The provider call, a RateLimitError carries the Retry-After delay.
+//
+// Returns:
+//   - The error of the last attempt.
+//
+// Example:
+//
+//	err := limiter.Do(ctx, 500, func(ctx context.Context) error {
+//	    vectors, err = embedder.Embed(ctx, texts)
+//	    return err
+//	})
+func (l *ProviderLimiter) Do(ctx context.Context, tokens int, call func(ctx context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		release, err := l.Acquire(ctx, tokens)
+		if err != nil {
+			return err
+		}
+		err = call(ctx)
+		release()
+
+		if !errors.Is(err, ErrRateLimited) || attempt >= l.opts.MaxRetries {
+			return err
+		}
+		var retryAfter time.Duration
+		var rateLimitErr *RateLimitError
+		if errors.As(err, &rateLimitErr) {
+			retryAfter = rateLimitErr.RetryAfter
+		}
+		l.Pause(l.retryWait(attempt, retryAfter))
+	}
+}
+
+// Send sends an HTTP request within the limits, retrying it when the provider answers 429 Too Many Requests.
+//
+// The tokens are estimated from the length of the request body; the body is rewound with
+// req.GetBody for the retries, requests without it are not retried.
+//
+// Parameters:
+//   - req: The request.
+//   - next: The function actually sending the request, e.g. a client middleware chain.
+//
+// Returns:
+//   - The response of the last attempt.
+//   - An error if the request could not be sent.
+func (l *ProviderLimiter) Send(req *http.Request, next func(*http.Request) (*http.Response, error)) (*http.Response, error) {
+	ctx := req.Context()
+	tokens := int(max(req.ContentLength, 0)+3) / 4
+	canRetry := req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+
+	for attempt := 0; ; attempt++ {
+		useReq := req
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			useReq = req.Clone(ctx)
+			useReq.Body = body
+		}
+
+		release, err := l.Acquire(ctx, tokens)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := next(useReq)
+		release()
+
+		if err != nil || resp.StatusCode != http.StatusTooManyRequests || !canRetry || attempt >= l.opts.MaxRetries {
+			return resp, err
+		}
+		l.Pause(l.retryWait(attempt, ir.RetryAfter(resp.Header)))
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+}
+
+// RoundTripper wraps an HTTP transport with the limits, for providers taking an *http.Client.
+//
+// Parameters:
+//   - base: The wrapped transport, http.DefaultTransport if nil.
+//
+// Returns:
+//   - The limited transport.
+//
+// Example:
+//
+//	httpClient := &http.Client{Transport: limiter.RoundTripper(nil)}
+//	embedder, err := ollama.NewEmbedder(ollama.BaseURLFromEnv(), "nomic-embed-text", httpClient)
+func (l *ProviderLimiter) RoundTripper(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return limitedTransport{limiter: l, base: base}
+}
+
+func (l *ProviderLimiter) retryWait(attempt int, retryAfter time.Duration) time.Duration {
+	wait := retryAfter
+	if wait <= 0 {
+		wait = time.Duration(float64(l.opts.RetryBackoff) * math.Pow(2, float64(attempt)))
+	}
+	if l.opts.MaxRetryWait > 0 {
+		wait = min(wait, l.opts.MaxRetryWait)
+	}
+	return wait
+}
+
+func (l *ProviderLimiter) waitPause(ctx context.Context) error {
+	for {
+		l.mu.Lock()
+		wait := time.Until(l.pausedUntil)
+		l.mu.Unlock()
+		if wait <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+type limitedTransport struct {
+	limiter *ProviderLimiter
+	base    http.RoundTripper
+}
+
+func (t limitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.limiter.Send(req, t.base.RoundTrip)
+}
@@ -0,0 +1,153 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestNewProviderLimiter_InvalidOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		option  LimiterOption
+		wantErr error
+	}{
+		{"zero rps", WithRequestsPerSecond(0, 1), ErrInvalidRequestRate},
+		{"zero burst", WithRequestsPerSecond(1, 0), ErrInvalidRequestRate},
+		{"zero tpm", WithTokensPerMinute(0), ErrInvalidTokenRate},
+		{"zero in-flight", WithMaxInFlight(0), ErrInvalidMaxInFlight},
+		{"negative retries", WithLimiterRetries(-1, 0, 0), ErrInvalidLimiterRetries},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewProviderLimiter(tt.option); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestProviderLimiter_MaxInFlight(t *testing.T) {
+	limiter, _ := NewProviderLimiter(WithMaxInFlight(2))
+
+	var current, peak atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limiter.Do(context.Background(), 0, func(ctx context.Context) error {
+				n := current.Add(1)
+				for {
+					p := peak.Load()
+					if n <= p || peak.CompareAndSwap(p, n) {
+						break
+					}
+				}
+				time.Sleep(5 * time.Millisecond)
+				current.Add(-1)
+				return nil
+			})
+		}()
+	}
+	wg.Wait()
+
+	if peak.Load() > 2 {
+		t.Errorf("Expected at most 2 concurrent calls, got %d", peak.Load())
+	}
+}
+
+func TestProviderLimiter_RequestRate(t *testing.T) {
+	limiter, _ := NewProviderLimiter(WithRequestsPerSecond(100, 1))
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		release, err := limiter.Acquire(context.Background(), 0)
+		if err != nil {
+			t.Fatalf("Acquire failed: %v", err)
+		}
+		release()
+	}
+	if elapsed := time.Since(start); elapsed < 25*time.Millisecond {
+		t.Errorf("Expected the requests to be spaced, took %v", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := limiter.Acquire(ctx, 0); err == nil {
+		t.Error("Expected the cancelled context to abort the wait")
+	}
+}
+
+func TestProviderLimiter_DoRetriesRateLimited(t *testing.T) {
+	limiter, _ := NewProviderLimiter(WithLimiterRetries(2, time.Millisecond, time.Second))
+
+	calls := 0
+	start := time.Now()
+	err := limiter.Do(context.Background(), 0, func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			return &RateLimitError{RetryAfter: 20 * time.Millisecond, Err: errors.New("429")}
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Fatalf("Expected a successful retry, got %v after %d calls", err, calls)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("Expected the Retry-After delay to be honoured, took %v", elapsed)
+	}
+
+	calls = 0
+	err = limiter.Do(context.Background(), 0, func(ctx context.Context) error {
+		calls++
+		return &RateLimitError{Err: errors.New("429")}
+	})
+	if !errors.Is(err, ErrRateLimited) || calls != 3 {
+		t.Errorf("Expected the rate limit error after 3 calls, got %v after %d calls", err, calls)
+	}
+
+	other := errors.New("boom")
+	calls = 0
+	if err := limiter.Do(context.Background(), 0, func(ctx context.Context) error { calls++; return other }); !errors.Is(err, other) || calls != 1 {
+		t.Errorf("Expected other errors not to be retried, got %v after %d calls", err, calls)
+	}
+}
+
+func TestProviderLimiter_RoundTripper(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	limiter, _ := NewProviderLimiter(WithLimiterRetries(1, time.Millisecond, time.Second))
+	client := &http.Client{Transport: limiter.RoundTripper(nil)}
+
+	resp, err := client.Post(server.URL, "text/plain", strings.NewReader("payload"))
+	if err != nil {
+		t.Fatalf("Post failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected the retry to succeed, got %d", resp.StatusCode)
+	}
+	if len(bodies) != 2 || bodies[1] != "payload" {
+		t.Errorf("Expected the body to be replayed, got %q", bodies)
+	}
+}
@@ -0,0 +1,613 @@
+// Package mock provides a scripted LLM provider for deterministic tests.
+//
+// The provider speaks the OpenAI wire protocol: Client returns an OpenAI client, usable by the
+// openai and aiw packages, whose requests are answered in-process without network access nor
+// API keys; the provider is an http.Handler as well, to be mounted on an httptest server for
+// the clients configured with a base URL. The chat completions, streamed or not, the legacy
+// completions and the embeddings are served.
+//
+// The chat and completion requests consume the scripted responses in order, then fall back to
+// the responder, if any; the embeddings are derived from a hash of the texts, so that the same
+// text always gets the same vector. Latency and failures can be injected, the failures being
+// drawn from a seeded generator so that a test fails the same requests at every run.
+package mock
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+
+	o "github.com/morphy76/ggraph/pkg/agent/openai"
+	"github.com/morphy76/ggraph/pkg/agent/tool"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+const (
+	// BaseURL is the fake base URL of the clients returned by Provider.Client.
+	BaseURL = "http://mock.ggraph.invalid/v1"
+	// APIKey is the fake API key of the clients returned by Provider.Client.
+	APIKey = "mock-api-key"
+)
+
+var (
+	// ErrScriptExhausted indicates that a request was received after the last scripted response and no responder is set.
+	ErrScriptExhausted = errors.New("mock script exhausted")
+)
+
+// Message is a message of a recorded chat request.
+type Message struct {
+	// Role is the role of the author of the message.
+	Role string
+	// Content is the text of the message, the text parts joined by a new line for the multi-modal ones.
+	Content string
+	// ToolCallID is the identifier of the tool call answered by a tool message.
+	ToolCallID string
+}
+
+// Request is a request received by the provider.
+type Request struct {
+	// Path is the endpoint of the request, e.g. "/chat/completions".
+	Path string
+	// Model is the requested model.
+	Model string
+	// Messages are the messages of a chat request.
+	Messages []Message
+	// Prompt is the prompt of a legacy completion request.
+	Prompt string
+	// Tools are the names of the tools offered to the model.
+	Tools []string
+	// Stream tells whether the response is streamed.
+	Stream bool
+	// Input are the texts of an embedding request.
+	Input []string
+}
+
+// LastMessage returns the last message of a chat request, the zero Message if it has none.
+//
+// Returns:
+//   - The last message.
+func (r Request) LastMessage() Message {
+	if len(r.Messages) == 0 {
+		return Message{}
+	}
+	return r.Messages[len(r.Messages)-1]
+}
+
+// Response is a scripted response of the provider.
+type Response struct {
+	// Content is the text generated by the model.
+	Content string
+	// ToolCalls are the tool calls requested by the model, their IDs are generated when empty.
+	ToolCalls []tool.FnCall
+	// Usage is the reported usage, estimated from the lengths of the request and of the content when zero.
+	Usage g.Usage
+	// Latency delays the response, overriding the latency of the provider when positive.
+	Latency time.Duration
+	// Status fails the request with the HTTP status, when it is an error status.
+	Status int
+	// Err fails the request with a transport error, as a broken connection would.
+	Err error
+}
+
+// Text creates a response generating a text.
+//
+// Parameters:
+//   - content: The generated text.
+//
+// Returns:
+//   - The response.
+func Text(content string) Response {
+	return Response{Content: content}
+}
+
+// ToolCall creates a response requesting a tool call.
+//
+// Parameters:
+//   - name: The name of the tool.
+//   - args: The arguments of the call.
+//
+// Returns:
+//   - The response.
+//
+// Example:
+//
+//	mock.ToolCall("additionTool", map[string]any{"addend1": 4, "addend2": 5})
+func ToolCall(name string, args map[string]any) Response {
+	return Response{ToolCalls: []tool.FnCall{{ToolName: name, Arguments: args}}}
+}
+
+// Failure creates a response failing with an HTTP status.
+//
+// Parameters:
+//   - status: The HTTP status, e.g. 429 or 503.
+//
+// Returns:
+//   - The response.
+func Failure(status int) Response {
+	return Response{Status: status}
+}
+
+// Provider is a scripted LLM provider speaking the OpenAI wire protocol.
+//
+// It is safe for concurrent use; the concurrent requests consume the script in the order they are received.
+type Provider struct {
+	mu sync.Mutex
+
+	opts     Options
+	script   []Response
+	requests []Request
+	random   *rand.Rand
+	calls    int
+}
+
+// New creates a mock provider.
+//
+// Parameters:
+//   - opts: The configuration options.
+//
+// Returns:
+//   - *Provider: The provider.
+//   - error: An error if the options are invalid.
+//
+// Example:
+//
+//	provider, err := mock.New(mock.WithScript(mock.Text("Hello!")))
+//	client := provider.Client()
+//	node, err := o.CreateConversationNode("chat", "gpt-4o-mini", client, o.ConversationNodeFn(o.ChatCompletion))
+func New(opts ...Option) (*Provider, error) {
+	useOpts := Options{
+		StreamChunkSize:     DefaultStreamChunkSize,
+		EmbeddingDimensions: DefaultEmbeddingDimensions,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(&useOpts); err != nil {
+			return nil, fmt.Errorf("cannot create the mock provider: %w", err)
+		}
+	}
+	return &Provider{
+		opts:   useOpts,
+		script: append([]Response(nil), useOpts.Script...),
+		random: rand.New(rand.NewSource(useOpts.Seed)),
+	}, nil
+}
+
+// Client creates an OpenAI client answered by the provider.
+//
+// The client does not retry the failed requests, so that every injected failure reaches the
+// caller; the given options are applied last and can override it.
+//
+// Parameters:
+//   - opts: Additional request options.
+//
+// Returns:
+//   - The OpenAI client.
+func (p *Provider) Client(opts ...option.RequestOption) *openai.Client {
+	useOpts := append([]option.RequestOption{p.Middleware(), option.WithMaxRetries(0)}, opts...)
+	return o.NewClient(BaseURL, APIKey, useOpts...)
+}
+
+// Middleware returns a client middleware answering the requests with the provider, they never reach the network.
+//
+// Returns:
+//   - The middleware, to be passed to any OpenAI compatible client.
+func (p *Provider) Middleware() option.RequestOption {
+	return option.WithMiddleware(func(req *http.Request, _ option.MiddlewareNext) (*http.Response, error) {
+		recorder := httptest.NewRecorder()
+		if err := p.serve(recorder, req); err != nil {
+			return nil, err
+		}
+		rv := recorder.Result()
+		rv.Request = req
+		return rv, nil
+	})
+}
+
+// ServeHTTP implements http.Handler, a transport error of a response aborts the connection.
+func (p *Provider) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if err := p.serve(w, req); err != nil {
+		panic(http.ErrAbortHandler)
+	}
+}
+
+// Enqueue appends responses to the script.
+//
+// Parameters:
+//   - responses: The scripted responses.
+func (p *Provider) Enqueue(responses ...Response) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.script = append(p.script, responses...)
+}
+
+// Remaining returns the number of scripted responses not yet served.
+//
+// Returns:
+//   - The number of responses.
+func (p *Provider) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.script)
+}
+
+// Requests returns the requests received so far, in order.
+//
+// Returns:
+//   - A copy of the recorded requests.
+func (p *Provider) Requests() []Request {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]Request(nil), p.requests...)
+}
+
+// wireRequest is the union of the request bodies of the served endpoints.
+type wireRequest struct {
+	Model    string `json:"model"`
+	Messages []struct {
+		Role       string          `json:"role"`
+		Content    json.RawMessage `json:"content"`
+		ToolCallID string          `json:"tool_call_id"`
+	} `json:"messages"`
+	Tools []struct {
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	} `json:"tools"`
+	Stream     bool            `json:"stream"`
+	Prompt     json.RawMessage `json:"prompt"`
+	Input      json.RawMessage `json:"input"`
+	Dimensions int             `json:"dimensions"`
+}
+
+func (p *Provider) serve(w http.ResponseWriter, req *http.Request) error {
+	var body wireRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return nil
+	}
+	path := req.URL.Path
+	if idx := strings.LastIndex(path, "/v1/"); idx >= 0 {
+		path = path[idx+len("/v1"):]
+	}
+	request := parseRequest(path, body)
+
+	response, err := p.next(request)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "server_error", err.Error())
+		return nil
+	}
+	if err := wait(req.Context(), response.Latency); err != nil {
+		return err
+	}
+	if response.Err != nil {
+		return response.Err
+	}
+	if response.Status >= http.StatusBadRequest {
+		writeError(w, response.Status, "server_error", fmt.Sprintf("injected failure: %s", http.StatusText(response.Status)))
+		return nil
+	}
+
+	switch path {
+	case "/chat/completions":
+		if request.Stream {
+			writeChatStream(w, request, response, p.opts.StreamChunkSize)
+		} else {
+			writeChat(w, request, response)
+		}
+	case "/completions":
+		writeCompletion(w, request, response)
+	case "/embeddings":
+		dimensions := p.opts.EmbeddingDimensions
+		if body.Dimensions > 0 {
+			dimensions = body.Dimensions
+		}
+		writeEmbeddings(w, request, dimensions)
+	default:
+		writeError(w, http.StatusNotFound, "invalid_request_error", "unknown endpoint "+path)
+	}
+	return nil
+}
+
+// next records the request and picks its response: an injected failure, the next scripted response or the responder one.
+func (p *Provider) next(request Request) (Response, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.requests = append(p.requests, request)
+	var rv Response
+	switch {
+	case p.opts.FailureRate > 0 && p.random.Float64() < p.opts.FailureRate:
+		rv = Failure(p.opts.FailureStatus)
+	case request.Path == "/embeddings":
+	case len(p.script) > 0:
+		rv = p.script[0]
+		p.script = p.script[1:]
+	case p.opts.Responder != nil:
+		rv = p.opts.Responder(request)
+	default:
+		return Response{}, fmt.Errorf("%w: request %d to %s", ErrScriptExhausted, len(p.requests), request.Path)
+	}
+
+	if rv.Latency <= 0 {
+		rv.Latency = p.opts.Latency
+	}
+	if len(rv.ToolCalls) > 0 {
+		calls := make([]tool.FnCall, len(rv.ToolCalls))
+		for i, call := range rv.ToolCalls {
+			p.calls++
+			if call.ID == "" {
+				call.ID = fmt.Sprintf("call_%d", p.calls)
+			}
+			calls[i] = call
+		}
+		rv.ToolCalls = calls
+	}
+	return rv, nil
+}
+
+func parseRequest(path string, body wireRequest) Request {
+	rv := Request{
+		Path:   path,
+		Model:  body.Model,
+		Stream: body.Stream,
+		Prompt: joinText(body.Prompt),
+	}
+	for _, message := range body.Messages {
+		rv.Messages = append(rv.Messages, Message{Role: message.Role, Content: joinText(message.Content), ToolCallID: message.ToolCallID})
+	}
+	for _, t := range body.Tools {
+		rv.Tools = append(rv.Tools, t.Function.Name)
+	}
+	if len(body.Input) > 0 {
+		var single string
+		if err := json.Unmarshal(body.Input, &single); err == nil {
+			rv.Input = []string{single}
+		} else {
+			_ = json.Unmarshal(body.Input, &rv.Input)
+		}
+	}
+	return rv
+}
+
+// joinText reads a string, an array of strings or an array of content parts, joining the texts by a new line.
+func joinText(raw json.RawMessage) string {
+	if len(raw) == 0 {
+		return ""
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text
+	}
+	var texts []string
+	if err := json.Unmarshal(raw, &texts); err == nil {
+		return strings.Join(texts, "\n")
+	}
+	var parts []struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(raw, &parts); err == nil {
+		texts = texts[:0]
+		for _, part := range parts {
+			if part.Text != "" {
+				texts = append(texts, part.Text)
+			}
+		}
+		return strings.Join(texts, "\n")
+	}
+	return ""
+}
+
+func wait(ctx context.Context, latency time.Duration) error {
+	if latency <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(latency)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// usage returns the scripted usage, or estimates it at about four characters per token.
+func usage(request Request, response Response) g.Usage {
+	if response.Usage != (g.Usage{}) {
+		return response.Usage
+	}
+	prompt := len(request.Prompt)
+	for _, message := range request.Messages {
+		prompt += len(message.Content)
+	}
+	for _, text := range request.Input {
+		prompt += len(text)
+	}
+	completion := len(response.Content)
+	for _, call := range response.ToolCalls {
+		completion += len(call.ToolName) + len(arguments(call))
+	}
+	rv := g.Usage{PromptTokens: int64(prompt/4 + 1)}
+	if request.Path != "/embeddings" {
+		rv.CompletionTokens = int64(completion/4 + 1)
+	}
+	rv.TotalTokens = rv.PromptTokens + rv.CompletionTokens
+	return rv
+}
+
+func arguments(call tool.FnCall) string {
+	if call.Arguments == nil {
+		return "{}"
+	}
+	rv, err := json.Marshal(call.Arguments)
+	if err != nil {
+		return "{}"
+	}
+	return string(rv)
+}
+
+func finishReason(response Response) string {
+	if len(response.ToolCalls) > 0 {
+		return "tool_calls"
+	}
+	return "stop"
+}
+
+func wireToolCalls(response Response) []map[string]any {
+	rv := make([]map[string]any, 0, len(response.ToolCalls))
+	for _, call := range response.ToolCalls {
+		rv = append(rv, map[string]any{
+			"id":   call.ID,
+			"type": "function",
+			"function": map[string]any{
+				"name":      call.ToolName,
+				"arguments": arguments(call),
+			},
+		})
+	}
+	return rv
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func writeError(w http.ResponseWriter, status int, kind, message string) {
+	writeJSON(w, status, map[string]any{
+		"error": map[string]any{"message": message, "type": kind},
+	})
+}
+
+func writeChat(w http.ResponseWriter, request Request, response Response) {
+	message := map[string]any{"role": "assistant", "content": response.Content}
+	if len(response.ToolCalls) > 0 {
+		message["tool_calls"] = wireToolCalls(response)
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"id":      "chatcmpl-mock",
+		"object":  "chat.completion",
+		"created": 0,
+		"model":   request.Model,
+		"choices": []map[string]any{{
+			"index":         0,
+			"finish_reason": finishReason(response),
+			"message":       message,
+		}},
+		"usage": usage(request, response),
+	})
+}
+
+// writeChatStream streams the content in chunks, then each tool call in two fragments, then the usage.
+func writeChatStream(w http.ResponseWriter, request Request, response Response, chunkSize int) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+
+	send := func(choices []map[string]any, usage *g.Usage) {
+		chunk := map[string]any{
+			"id":      "chatcmpl-mock",
+			"object":  "chat.completion.chunk",
+			"created": 0,
+			"model":   request.Model,
+			"choices": choices,
+		}
+		if usage != nil {
+			chunk["usage"] = usage
+		}
+		data, _ := json.Marshal(chunk)
+		fmt.Fprintf(w, "data: %s\n\n", data)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	delta := func(delta map[string]any) {
+		send([]map[string]any{{"index": 0, "delta": delta}}, nil)
+	}
+
+	delta(map[string]any{"role": "assistant", "content": ""})
+	content := []rune(response.Content)
+	for start := 0; start < len(content); start += chunkSize {
+		delta(map[string]any{"content": string(content[start:min(start+chunkSize, len(content))])})
+	}
+	for i, call := range wireToolCalls(response) {
+		function := call["function"].(map[string]any)
+		delta(map[string]any{"tool_calls": []map[string]any{{
+			"index": i, "id": call["id"], "type": "function",
+			"function": map[string]any{"name": function["name"], "arguments": ""},
+		}}})
+		delta(map[string]any{"tool_calls": []map[string]any{{
+			"index":    i,
+			"function": map[string]any{"arguments": function["arguments"]},
+		}}})
+	}
+	send([]map[string]any{{"index": 0, "delta": map[string]any{}, "finish_reason": finishReason(response)}}, nil)
+	used := usage(request, response)
+	send([]map[string]any{}, &used)
+	fmt.Fprint(w, "data: [DONE]\n\n")
+}
+
+func writeCompletion(w http.ResponseWriter, request Request, response Response) {
+	writeJSON(w, http.StatusOK, map[string]any{
+		"id":      "cmpl-mock",
+		"object":  "text_completion",
+		"created": 0,
+		"model":   request.Model,
+		"choices": []map[string]any{{
+			"index":         0,
+			"finish_reason": "stop",
+			"text":          response.Content,
+			"logprobs":      nil,
+		}},
+		"usage": usage(request, response),
+	})
+}
+
+func writeEmbeddings(w http.ResponseWriter, request Request, dimensions int) {
+	data := make([]map[string]any, len(request.Input))
+	for i, text := range request.Input {
+		data[i] = map[string]any{"object": "embedding", "index": i, "embedding": Embedding(text, dimensions)}
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"object": "list",
+		"model":  request.Model,
+		"data":   data,
+		"usage":  usage(request, Response{}),
+	})
+}
+
+// Embedding returns the deterministic unit vector served by the provider for a text.
+//
+// Parameters:
+//   - text: The embedded text.
+//   - dimensions: The number of dimensions.
+//
+// Returns:
+//   - The embedding.
+func Embedding(text string, dimensions int) []float64 {
+	rv := make([]float64, dimensions)
+	var norm float64
+	for i := range rv {
+		sum := sha256.Sum256(fmt.Appendf(nil, "%d:%s", i, text))
+		rv[i] = float64(binary.BigEndian.Uint32(sum[:4]))/math.MaxUint32*2 - 1
+		norm += rv[i] * rv[i]
+	}
+	norm = math.Sqrt(norm)
+	for i := range rv {
+		rv[i] /= norm
+	}
+	return rv
+}
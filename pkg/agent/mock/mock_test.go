@@ -0,0 +1,249 @@
+package mock_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/agent/mock"
+	o "github.com/morphy76/ggraph/pkg/agent/openai"
+	"github.com/morphy76/ggraph/pkg/agent/tool"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func additionTool(addend1, addend2 int) (int, error) {
+	return addend1 + addend2, nil
+}
+
+func ask(t *testing.T, client *openai.Client, question string) (a.Message, error) {
+	opts, err := a.CreateConversationOptions("gpt", []a.Message{a.CreateMessage(a.User, question)})
+	if err != nil {
+		t.Fatalf("CreateConversationOptions failed: %v", err)
+	}
+	return o.ChatCompletion(context.Background(), client.Chat, opts)
+}
+
+func TestProvider_Script(t *testing.T) {
+	provider, err := mock.New(mock.WithScript(mock.Text("Hello!")))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	client := provider.Client()
+
+	answer, err := ask(t, client, "Hi")
+	if err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+	if answer.Role != a.Assistant || answer.Content != "Hello!" {
+		t.Errorf("Unexpected answer %+v", answer)
+	}
+	if provider.Remaining() != 0 {
+		t.Errorf("Expected the script to be consumed, %d responses left", provider.Remaining())
+	}
+
+	if _, err := ask(t, client, "Again"); err == nil || !strings.Contains(err.Error(), mock.ErrScriptExhausted.Error()) {
+		t.Errorf("Expected the script to be exhausted, got %v", err)
+	}
+
+	provider.Enqueue(mock.Text("Back"))
+	if answer, err := ask(t, client, "Again"); err != nil || answer.Content != "Back" {
+		t.Errorf("Expected the enqueued response, got %+v, %v", answer, err)
+	}
+
+	requests := provider.Requests()
+	if len(requests) != 3 || requests[0].Path != "/chat/completions" || requests[0].Model != "gpt" {
+		t.Fatalf("Unexpected requests %+v", requests)
+	}
+	if last := requests[2].LastMessage(); last.Role != "user" || last.Content != "Again" {
+		t.Errorf("Unexpected last message %+v", last)
+	}
+}
+
+func TestProvider_Responder(t *testing.T) {
+	provider, _ := mock.New(mock.WithResponder(func(req mock.Request) mock.Response {
+		return mock.Response{Content: "echo: " + req.LastMessage().Content, Usage: g.Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5}}
+	}))
+
+	var used g.Usage
+	ctx := g.ContextWithUsageReporter(context.Background(), func(usage g.Usage) { used = used.Add(usage) })
+	opts, _ := a.CreateConversationOptions("gpt", []a.Message{a.CreateMessage(a.User, "ping")})
+	answer, err := o.ChatCompletion(ctx, provider.Client().Chat, opts)
+	if err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+	if answer.Content != "echo: ping" {
+		t.Errorf("Unexpected answer %q", answer.Content)
+	}
+	if used.TotalTokens != 5 {
+		t.Errorf("Expected the scripted usage to be reported, got %+v", used)
+	}
+}
+
+func TestProvider_ToolAgent(t *testing.T) {
+	provider, _ := mock.New(mock.WithScript(
+		mock.ToolCall("additionTool", map[string]any{"addend1": 4, "addend2": 5}),
+		mock.Text("The result is 9"),
+	))
+	addition, err := tool.CreateTool[int](additionTool, "Prompt: sum two integers", "Input: addend1, addend2")
+	if err != nil {
+		t.Fatalf("Failed to create addition tool: %v", err)
+	}
+
+	fn := o.ToolAgentNodeFn(3)(provider.Client().Chat, "gpt", a.WithTools(addition))
+	got, err := fn(a.CreateConversation(a.CreateMessage(a.User, "4+5?")), a.Conversation{}, func(a.Conversation) {})
+	if err != nil {
+		t.Fatalf("tool agent failed: %v", err)
+	}
+	if len(got.Messages) != 4 || got.Messages[2].Content != "call_1:9" || got.Messages[3].Content != "The result is 9" {
+		t.Errorf("Unexpected conversation %+v", got.Messages)
+	}
+
+	requests := provider.Requests()
+	if !slices.Equal(requests[0].Tools, []string{"additionTool"}) {
+		t.Errorf("Expected the tool to be offered, got %v", requests[0].Tools)
+	}
+	if fed := requests[1].Messages[2]; fed.Role != "tool" || fed.ToolCallID != "call_1" || fed.Content != "9" {
+		t.Errorf("Expected the tool result to be fed back, got %+v", fed)
+	}
+}
+
+func TestProvider_Stream(t *testing.T) {
+	provider, _ := mock.New(mock.WithStreamChunkSize(5), mock.WithScript(
+		mock.Response{Content: "Let me compute.", ToolCalls: []tool.FnCall{{ToolName: "additionTool", Arguments: map[string]any{"addend1": 1, "addend2": 2}}}},
+	))
+	opts, _ := a.CreateConversationOptions("gpt", []a.Message{a.CreateMessage(a.User, "1+2?")})
+
+	var partials []string
+	var used g.Usage
+	ctx := g.ContextWithUsageReporter(context.Background(), func(usage g.Usage) { used = used.Add(usage) })
+	answer, err := o.ChatCompletionStream(ctx, provider.Client().Chat, opts, func(partial a.Message) {
+		partials = append(partials, partial.Content)
+	})
+	if err != nil {
+		t.Fatalf("ChatCompletionStream failed: %v", err)
+	}
+	if strings.Join(partials, "|") != "Let m|Let me com|Let me compute." {
+		t.Errorf("Unexpected partials %q", partials)
+	}
+	if len(answer.ToolCalls) != 1 || answer.ToolCalls[0].ID != "call_1" || answer.ToolCalls[0].Arguments["addend2"] != float64(2) {
+		t.Errorf("Unexpected tool calls %+v", answer.ToolCalls)
+	}
+	if used.TotalTokens == 0 || used.TotalTokens != used.PromptTokens+used.CompletionTokens {
+		t.Errorf("Expected an estimated usage, got %+v", used)
+	}
+	if !provider.Requests()[0].Stream {
+		t.Error("Expected the request to be recorded as streamed")
+	}
+}
+
+func TestProvider_Failures(t *testing.T) {
+	provider, _ := mock.New(mock.WithFailureRate(1, http.StatusServiceUnavailable, 1))
+	_, err := ask(t, provider.Client(), "Hi")
+	var apiErr *openai.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected an injected 503, got %v", err)
+	}
+	if !errors.Is(err, a.ErrTransient) {
+		t.Errorf("Expected the injected failure to be transient, got %v", err)
+	}
+
+	broken := errors.New("connection reset")
+	provider, _ = mock.New(mock.WithScript(mock.Response{Err: broken}, mock.Failure(http.StatusBadRequest)))
+	client := provider.Client()
+	if _, err := ask(t, client, "Hi"); !errors.Is(err, broken) {
+		t.Errorf("Expected the transport error, got %v", err)
+	}
+	if _, err := ask(t, client, "Hi"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected a 400, got %v", err)
+	}
+
+	first, _ := mock.New(mock.WithFailureRate(0.5, http.StatusTooManyRequests, 42), mock.WithResponder(func(mock.Request) mock.Response { return mock.Text("ok") }))
+	second, _ := mock.New(mock.WithFailureRate(0.5, http.StatusTooManyRequests, 42), mock.WithResponder(func(mock.Request) mock.Response { return mock.Text("ok") }))
+	for i := range 10 {
+		_, err1 := ask(t, first.Client(), "Hi")
+		_, err2 := ask(t, second.Client(), "Hi")
+		if (err1 == nil) != (err2 == nil) {
+			t.Fatalf("Expected the same seed to fail the same requests, request %d: %v, %v", i, err1, err2)
+		}
+	}
+}
+
+func TestProvider_Latency(t *testing.T) {
+	provider, _ := mock.New(mock.WithLatency(50*time.Millisecond), mock.WithScript(mock.Text("slow"), mock.Response{Content: "slower", Latency: 5 * time.Second}))
+	client := provider.Client()
+
+	start := time.Now()
+	if _, err := ask(t, client, "Hi"); err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 50*time.Millisecond {
+		t.Errorf("Expected the latency to be applied, took %s", elapsed)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	opts, _ := a.CreateConversationOptions("gpt", []a.Message{a.CreateMessage(a.User, "Hi")})
+	start = time.Now()
+	if _, err := o.ChatCompletion(ctx, client.Chat, opts); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the deadline to interrupt the latency, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Expected the request to be interrupted, took %s", elapsed)
+	}
+}
+
+func TestProvider_Embeddings(t *testing.T) {
+	provider, _ := mock.New(mock.WithEmbeddingDimensions(4))
+	embedder, err := o.NewEmbedder(provider.Client(), "embed")
+	if err != nil {
+		t.Fatalf("NewEmbedder failed: %v", err)
+	}
+
+	vectors, err := embedder.Embed(context.Background(), []string{"hello", "world", "hello"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(vectors) != 3 || len(vectors[0]) != 4 {
+		t.Fatalf("Unexpected vectors %v", vectors)
+	}
+	if !slices.Equal(vectors[0], vectors[2]) || slices.Equal(vectors[0], vectors[1]) {
+		t.Errorf("Expected the vectors to depend on the text only, got %v", vectors)
+	}
+	if vectors[0][0] != float32(mock.Embedding("hello", 4)[0]) {
+		t.Errorf("Expected the served vector to match Embedding")
+	}
+}
+
+func TestProvider_Handler(t *testing.T) {
+	provider, _ := mock.New(mock.WithScript(mock.Text("over the wire")))
+	server := httptest.NewServer(provider)
+	defer server.Close()
+
+	answer, err := ask(t, o.NewClient(server.URL, "key"), "Hi")
+	if err != nil || answer.Content != "over the wire" {
+		t.Errorf("Unexpected answer %+v, %v", answer, err)
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	for _, opt := range []mock.Option{
+		mock.WithResponder(nil),
+		mock.WithLatency(-time.Second),
+		mock.WithFailureRate(2, http.StatusServiceUnavailable, 0),
+		mock.WithFailureRate(0.5, http.StatusOK, 0),
+		mock.WithStreamChunkSize(0),
+		mock.WithEmbeddingDimensions(0),
+	} {
+		if _, err := mock.New(opt); !errors.Is(err, mock.ErrInvalidOption) {
+			t.Errorf("Expected ErrInvalidOption, got %v", err)
+		}
+	}
+}
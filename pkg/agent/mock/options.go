@@ -0,0 +1,183 @@
+package mock
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultStreamChunkSize is the default number of characters of the content carried by a streamed chunk.
+	DefaultStreamChunkSize = 4
+	// DefaultEmbeddingDimensions is the default number of dimensions of the embeddings.
+	DefaultEmbeddingDimensions = 8
+)
+
+var (
+	// ErrInvalidOption indicates that an option of the mock provider has an invalid value.
+	ErrInvalidOption = errors.New("invalid mock provider option")
+)
+
+// Options holds the configuration of the mock provider.
+type Options struct {
+	// Script are the responses served in order.
+	Script []Response
+	// Responder answers the requests once the script is exhausted, nil to fail them with ErrScriptExhausted.
+	Responder func(request Request) Response
+	// Latency delays every response which has no latency of its own.
+	Latency time.Duration
+	// FailureRate is the probability for a request to fail with FailureStatus, from 0 to 1.
+	FailureRate float64
+	// FailureStatus is the HTTP status of the injected failures.
+	FailureStatus int
+	// Seed seeds the failure injection, so that the failing requests are the same across runs.
+	Seed int64
+	// StreamChunkSize is the number of characters of the content carried by a streamed chunk.
+	StreamChunkSize int
+	// EmbeddingDimensions is the number of dimensions of the embeddings, unless requested otherwise.
+	EmbeddingDimensions int
+}
+
+// Option is a functional option for configuring the mock provider.
+type Option interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - o: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(o *Options) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc func(*Options) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - o: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc) Apply(o *Options) error { return s(o) }
+
+// WithScript appends responses to the script, served in order to the chat and completion requests.
+//
+// Parameters:
+//   - responses: The scripted responses.
+//
+// Returns:
+//   - An Option that appends the responses.
+//
+// Example:
+//
+//	mock.WithScript(
+//	    mock.ToolCall("search", map[string]any{"query": "weather"}),
+//	    mock.Text("It is sunny"))
+func WithScript(responses ...Response) Option {
+	return OptionFunc(func(o *Options) error {
+		o.Script = append(o.Script, responses...)
+		return nil
+	})
+}
+
+// WithResponder answers the requests with a function once the script is exhausted.
+//
+// Parameters:
+//   - responder: The function computing the response of a request.
+//
+// Returns:
+//   - An Option that sets the responder.
+//
+// Example:
+//
+//	mock.WithResponder(func(req mock.Request) mock.Response {
+//	    return mock.Text("echo: " + req.LastMessage().Content)
+//	})
+func WithResponder(responder func(request Request) Response) Option {
+	return OptionFunc(func(o *Options) error {
+		if responder == nil {
+			return fmt.Errorf("%w: nil responder", ErrInvalidOption)
+		}
+		o.Responder = responder
+		return nil
+	})
+}
+
+// WithLatency delays the responses which have no latency of their own.
+//
+// Parameters:
+//   - latency: The delay, it must not be negative.
+//
+// Returns:
+//   - An Option that sets the latency.
+func WithLatency(latency time.Duration) Option {
+	return OptionFunc(func(o *Options) error {
+		if latency < 0 {
+			return fmt.Errorf("%w: negative latency", ErrInvalidOption)
+		}
+		o.Latency = latency
+		return nil
+	})
+}
+
+// WithFailureRate makes a share of the requests fail with an HTTP status, before any scripted response is consumed.
+//
+// The failures are drawn from a generator seeded with seed, so that a test fails the same requests at every run.
+//
+// Parameters:
+//   - rate: The probability for a request to fail, from 0 to 1.
+//   - status: The HTTP status of the failures, e.g. 429 or 503.
+//   - seed: The seed of the generator.
+//
+// Returns:
+//   - An Option that enables the failure injection.
+func WithFailureRate(rate float64, status int, seed int64) Option {
+	return OptionFunc(func(o *Options) error {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("%w: failure rate must be between 0 and 1", ErrInvalidOption)
+		}
+		if status < 400 || status > 599 {
+			return fmt.Errorf("%w: failure status must be an HTTP error", ErrInvalidOption)
+		}
+		o.FailureRate = rate
+		o.FailureStatus = status
+		o.Seed = seed
+		return nil
+	})
+}
+
+// WithStreamChunkSize sets the number of characters of the content carried by a streamed chunk.
+//
+// Parameters:
+//   - size: The number of characters, it must be positive.
+//
+// Returns:
+//   - An Option that sets the chunk size.
+func WithStreamChunkSize(size int) Option {
+	return OptionFunc(func(o *Options) error {
+		if size < 1 {
+			return fmt.Errorf("%w: stream chunk size must be positive", ErrInvalidOption)
+		}
+		o.StreamChunkSize = size
+		return nil
+	})
+}
+
+// WithEmbeddingDimensions sets the number of dimensions of the embeddings, unless requested otherwise.
+//
+// Parameters:
+//   - dimensions: The number of dimensions, it must be positive.
+//
+// Returns:
+//   - An Option that sets the dimensions.
+func WithEmbeddingDimensions(dimensions int) Option {
+	return OptionFunc(func(o *Options) error {
+		if dimensions < 1 {
+			return fmt.Errorf("%w: embedding dimensions must be positive", ErrInvalidOption)
+		}
+		o.EmbeddingDimensions = dimensions
+		return nil
+	})
+}
@@ -0,0 +1,22 @@
+// Package ollama integrates a local Ollama server.
+package ollama
+
+import "os"
+
+const (
+	// OllamaBaseURL is the default base URL of a local Ollama server.
+	OllamaBaseURL = "http://localhost:11434"
+	// EnvKeyHost is the environment variable key for the Ollama server URL.
+	EnvKeyHost = "OLLAMA_HOST"
+)
+
+// BaseURLFromEnv retrieves the Ollama server URL from the environment variable "OLLAMA_HOST".
+//
+// Returns:
+//   - The Ollama server URL, OllamaBaseURL if the variable is not set.
+func BaseURLFromEnv() string {
+	if host := os.Getenv(EnvKeyHost); host != "" {
+		return host
+	}
+	return OllamaBaseURL
+}
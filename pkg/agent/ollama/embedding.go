@@ -0,0 +1,107 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	ie "github.com/morphy76/ggraph/internal/agent/embedding"
+	ir "github.com/morphy76/ggraph/internal/agent/ratelimit"
+	a "github.com/morphy76/ggraph/pkg/agent"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+var _ a.Embedder = (*embedder)(nil)
+
+type embedRequest struct {
+	Model      string   `json:"model"`
+	Input      []string `json:"input"`
+	Dimensions *int64   `json:"dimensions,omitempty"`
+}
+
+type embedResponse struct {
+	Embeddings [][]float32 `json:"embeddings"`
+}
+
+type embedder struct {
+	endpoint   string
+	model      string
+	httpClient *http.Client
+	opts       *a.EmbedderOptions
+}
+
+// NewEmbedder creates an Embedder backed by the Ollama /api/embed endpoint.
+//
+// Parameters:
+//   - baseURL: The Ollama server URL, see BaseURLFromEnv.
+//   - model: The embedding model, e.g. "nomic-embed-text".
+//   - httpClient: The HTTP client, http.DefaultClient if nil.
+//   - opts: The embedder options.
+//
+// Returns:
+//   - The Embedder.
+//   - An error if any option is invalid.
+//
+// Example usage:
+//
+//	embedder, err := NewEmbedder(BaseURLFromEnv(), "nomic-embed-text", nil)
+//	vectors, err := embedder.Embed(ctx, []string{"hello", "world"})
+func NewEmbedder(baseURL, model string, httpClient *http.Client, opts ...a.EmbedderOption) (a.Embedder, error) {
+	useOpts, err := a.CreateEmbedderOptions(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create the embedder: %w", err)
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &embedder{
+		endpoint:   strings.TrimSuffix(baseURL, "/") + "/api/embed",
+		model:      model,
+		httpClient: httpClient,
+		opts:       useOpts,
+	}, nil
+}
+
+func (e *embedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return ie.Batched(ctx, texts, e.opts, e.embedBatch)
+}
+
+func (e *embedder) embedBatch(ctx context.Context, batch []string) ([][]float32, error) {
+	body, err := json.Marshal(embedRequest{Model: e.model, Input: batch, Dimensions: e.opts.Dimensions})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for header, value := range g.PropagatedHeaders(ctx) {
+		req.Header.Set(header, value)
+	}
+
+	resp, err := e.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		err := fmt.Errorf("ollama embed request failed: %s %s", resp.Status, strings.TrimSpace(string(message)))
+		if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode == http.StatusServiceUnavailable {
+			return nil, &a.RateLimitError{RetryAfter: ir.RetryAfter(resp.Header), Err: err}
+		}
+		return nil, err
+	}
+
+	var decoded embedResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("cannot decode the ollama embed response: %w", err)
+	}
+	return decoded.Embeddings, nil
+}
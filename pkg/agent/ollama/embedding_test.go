@@ -0,0 +1,69 @@
+package ollama_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/agent/ollama"
+)
+
+func TestEmbedder(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Path != "/api/embed" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var req struct {
+			Model string   `json:"model"`
+			Input []string `json:"input"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model != "nomic-embed-text" {
+			t.Errorf("Unexpected model %s", req.Model)
+		}
+
+		embeddings := make([][]float32, len(req.Input))
+		for i, text := range req.Input {
+			embeddings[i] = []float32{float32(len(text))}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"embeddings": embeddings})
+	}))
+	defer server.Close()
+
+	embedder, err := ollama.NewEmbedder(server.URL+"/", "nomic-embed-text", nil, a.WithEmbeddingRetries(1, time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewEmbedder failed: %v", err)
+	}
+
+	vectors, err := embedder.Embed(context.Background(), []string{"a", "bb"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+	if len(vectors) != 2 || vectors[0][0] != 1 || vectors[1][0] != 2 {
+		t.Errorf("Unexpected vectors %v", vectors)
+	}
+}
+
+func TestEmbedder_CountMismatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"embeddings":[[1]]}`))
+	}))
+	defer server.Close()
+
+	embedder, _ := ollama.NewEmbedder(server.URL, "nomic-embed-text", nil)
+	if _, err := embedder.Embed(context.Background(), []string{"a", "b"}); !errors.Is(err, a.ErrEmbeddingCountMismatch) {
+		t.Errorf("Expected ErrEmbeddingCountMismatch, got %v", err)
+	}
+}
@@ -0,0 +1,152 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/openai/openai-go/v3"
+
+	it "github.com/morphy76/ggraph/internal/agent/tool"
+	a "github.com/morphy76/ggraph/pkg/agent"
+	t "github.com/morphy76/ggraph/pkg/agent/tool"
+	b "github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+const (
+	// DefaultToolAgentMaxIterations is the default number of model calls a tool agent performs before giving up.
+	DefaultToolAgentMaxIterations = 10
+)
+
+var (
+	// ErrInvalidMaxIterations is returned when the maximum number of iterations is not positive.
+	ErrInvalidMaxIterations = errors.New("max iterations must be at least 1")
+	// ErrToolAgentMaxIterations is returned when the tool agent does not reach a final answer within the maximum number of iterations.
+	ErrToolAgentMaxIterations = errors.New("tool agent reached the maximum number of iterations without a final answer")
+	// ErrNoChoices is returned when the model response carries no choice.
+	ErrNoChoices = errors.New("no choices in the response")
+)
+
+// ToolAgentNodeFn creates a ConversationNodeFn running the tool-calling loop within a single node.
+//
+// The model is called with the conversation; requested tool calls are executed with the tools
+// configured through a.WithTools and their results are fed back to the model, until the model
+// answers without tool calls or the maximum number of iterations is reached.
+//
+// The node function has no invocation context: the model calls and the tools run with
+// context.Background(), so cancellations, deadlines and the context values of the invocation are
+// not propagated. Use CreateToolAgentNode to propagate the invocation context.
+//
+// Parameters:
+//   - maxIterations: The maximum number of model calls, see DefaultToolAgentMaxIterations.
+//
+// Returns:
+//   - A ConversationNodeFn implementing the tool-calling loop, its node function fails with
+//     ErrInvalidMaxIterations if maxIterations is not positive.
+//
+// Example usage:
+//
+//	node, err := CreateAzureConversationNode("Agent", config, ToolAgentNodeFn(5), a.WithTools(tool1))
+func ToolAgentNodeFn(maxIterations int) ConversationNodeFn {
+	return func(chatService openai.ChatService, model string, modelOptions ...a.ModelOption) g.NodeFn[a.Conversation] {
+		loop := toolAgentLoop(chatService, model, maxIterations, modelOptions...)
+		return func(userInput, currentState a.Conversation, notify g.NotifyPartialFn[a.Conversation]) (a.Conversation, error) {
+			if maxIterations < 1 {
+				return currentState, fmt.Errorf("tool agent request failed: %w", ErrInvalidMaxIterations)
+			}
+			return loop(context.Background(), userInput, currentState, notify)
+		}
+	}
+}
+
+func toolAgentLoop(
+	chatService openai.ChatService,
+	model string,
+	maxIterations int,
+	modelOptions ...a.ModelOption,
+) g.ContextNodeFn[a.Conversation] {
+	return func(ctx context.Context, userInput, currentState a.Conversation, notify g.NotifyPartialFn[a.Conversation]) (a.Conversation, error) {
+		useOpts, err := a.CreateConversationOptions(model, nil, modelOptions...)
+		if err != nil {
+			return currentState, fmt.Errorf("failed to create conversation options: %w", err)
+		}
+		a.OverrideSystemPrompt(useOpts, userInput, currentState)
+		mappedTools := it.MapTools(useOpts.Tools...)
+
+		useState := a.Conversation{
+			Messages: a.PromptMessages(userInput, currentState),
+		}
+		known := len(useState.Messages)
+		loaded, err := a.LoadHistory(ctx, useOpts, useState.Messages)
+		if err != nil {
+			return currentState, fmt.Errorf("tool agent request failed: %w", err)
+		}
+
+		for i := 0; i < maxIterations; i++ {
+			useOpts.Messages, err = a.CompactMessages(ctx, useOpts, append(slices.Clip(loaded), useState.Messages...))
+			if err != nil {
+				return currentState, fmt.Errorf("tool agent request failed: %w", err)
+			}
+
+			useAnswer, err := ChatCompletion(ctx, chatService, useOpts)
+			if err != nil {
+				return currentState, fmt.Errorf("tool agent request failed: %w", err)
+			}
+			if len(useAnswer.ToolCalls) == 0 {
+				useState.Messages = append(useState.Messages, useAnswer)
+				if err := a.SaveHistory(ctx, useOpts, append(slices.Clone(userInput.Messages), useState.Messages[known:]...)); err != nil {
+					return currentState, fmt.Errorf("tool agent request failed: %w", err)
+				}
+				return useState, nil
+			}
+
+			useState.Messages = append(useState.Messages, useAnswer)
+			useState.Messages = append(useState.Messages, it.ExecuteToolCalls(t.ContextWithMetadata(ctx, currentState.Metadata), mappedTools, useAnswer.ToolCalls)...)
+			notify(useState)
+		}
+
+		return currentState, fmt.Errorf("%w: %d", ErrToolAgentMaxIterations, maxIterations)
+	}
+}
+
+// CreateToolAgentNode creates a graph node running the standard tool-calling agent loop.
+//
+// The node calls the model, executes the requested tools, feeds the results back and repeats
+// until a final answer is produced, so that no router or tool node has to be wired manually.
+// Tools are registered with a.WithTools; the invocation context is propagated to the model
+// calls and to context-aware tools.
+//
+// Parameters:
+//   - name: The unique name for the node.
+//   - model: The OpenAI model to be used for the agent.
+//   - client: The OpenAI client instance.
+//   - maxIterations: The maximum number of model calls, see DefaultToolAgentMaxIterations.
+//   - conversationOptions: Additional conversation options for the OpenAI API calls.
+//
+// Returns:
+//   - An instance of g.Node[a.Conversation] running the tool-calling loop.
+//   - An error if the node creation fails.
+//
+// Example usage:
+//
+//	node, err := CreateToolAgentNode("Agent", "gpt-4o-mini", client, DefaultToolAgentMaxIterations, a.WithTools(tool1, tool2))
+func CreateToolAgentNode(
+	name, model string,
+	client *openai.Client,
+	maxIterations int,
+	conversationOptions ...a.ModelOption,
+) (g.Node[a.Conversation], error) {
+	if maxIterations < 1 {
+		return nil, fmt.Errorf("cannot create a tool agent node: %w", ErrInvalidMaxIterations)
+	}
+
+	routingPolicy, err := b.CreateConditionalRoutePolicy(a.ToolProcessorRoutingFn)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create a tool agent node: %w", err)
+	}
+
+	return b.NewContextNode(name, toolAgentLoop(client.Chat, model, maxIterations, conversationOptions...),
+		g.WithRoutingPolicy(routingPolicy))
+}
@@ -0,0 +1,187 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/agent/history"
+	o "github.com/morphy76/ggraph/pkg/agent/openai"
+	"github.com/morphy76/ggraph/pkg/agent/tool"
+	b "github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+const toolCallResponse = `{"id":"1","object":"chat.completion","created":0,"model":"gpt","choices":[{"index":0,"finish_reason":"tool_calls","message":{"role":"assistant","content":"","tool_calls":[{"id":"call_1","type":"function","function":{"name":"additionTool","arguments":"{\"addend1\":4,\"addend2\":5}"}}]}}]}`
+
+func TestToolAgentNodeFn(t *testing.T) {
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req map[string]any
+		json.Unmarshal(body, &req)
+		requests = append(requests, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		if len(requests) == 1 {
+			w.Write([]byte(toolCallResponse))
+			return
+		}
+		w.Write([]byte(chatResponse("The result is 9")))
+	}))
+	defer server.Close()
+
+	addition, err := tool.CreateTool[int](additionTool, "Prompt: sum two integers", "Input: addend1, addend2")
+	if err != nil {
+		t.Fatalf("Failed to create addition tool: %v", err)
+	}
+
+	client := o.NewClient(server.URL, "key")
+	partials := 0
+	fn := o.ToolAgentNodeFn(3)(client.Chat, "gpt", a.WithTools(addition))
+
+	got, err := fn(a.CreateConversation(a.CreateMessage(a.User, "4+5?")), a.Conversation{}, func(a.Conversation) { partials++ })
+	if err != nil {
+		t.Fatalf("tool agent failed: %v", err)
+	}
+
+	if len(got.Messages) != 4 {
+		t.Fatalf("Expected 4 messages, got %d", len(got.Messages))
+	}
+	if got.Messages[2].Role != a.Tool || got.Messages[2].Content != "call_1:9" {
+		t.Errorf("Unexpected tool message %+v", got.Messages[2])
+	}
+	if got.Messages[3].Content != "The result is 9" {
+		t.Errorf("Unexpected final answer '%s'", got.Messages[3].Content)
+	}
+	if len(got.CurrentToolCalls) != 0 {
+		t.Errorf("Expected no pending tool calls, got %d", len(got.CurrentToolCalls))
+	}
+	if partials != 1 {
+		t.Errorf("Expected 1 partial notification, got %d", partials)
+	}
+
+	secondRequest, _ := json.Marshal(requests[1]["messages"])
+	if !strings.Contains(string(secondRequest), `"tool_call_id":"call_1"`) {
+		t.Errorf("Expected the tool result to be fed back, got %s", secondRequest)
+	}
+}
+
+func TestToolAgentNodeFn_MaxIterations(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(toolCallResponse))
+	}))
+	defer server.Close()
+
+	addition, err := tool.CreateTool[int](additionTool, "Prompt: sum two integers", "Input: addend1, addend2")
+	if err != nil {
+		t.Fatalf("Failed to create addition tool: %v", err)
+	}
+
+	client := o.NewClient(server.URL, "key")
+	fn := o.ToolAgentNodeFn(2)(client.Chat, "gpt", a.WithTools(addition))
+
+	_, err = fn(a.CreateConversation(a.CreateMessage(a.User, "4+5?")), a.Conversation{}, func(a.Conversation) {})
+	if !errors.Is(err, o.ErrToolAgentMaxIterations) {
+		t.Errorf("Expected ErrToolAgentMaxIterations, got %v", err)
+	}
+}
+
+func TestToolAgentNodeFn_InvalidMaxIterations(t *testing.T) {
+	client := o.NewClient("http://localhost", "key")
+	fn := o.ToolAgentNodeFn(0)(client.Chat, "gpt")
+
+	_, err := fn(a.CreateConversation(a.CreateMessage(a.User, "4+5?")), a.Conversation{}, func(a.Conversation) {})
+	if !errors.Is(err, o.ErrInvalidMaxIterations) {
+		t.Errorf("Expected ErrInvalidMaxIterations, got %v", err)
+	}
+}
+
+func TestCreateToolAgentNode_InvalidMaxIterations(t *testing.T) {
+	client := o.NewClient("http://localhost", "key")
+	if _, err := o.CreateToolAgentNode("Agent", "gpt", client, 0); !errors.Is(err, o.ErrInvalidMaxIterations) {
+		t.Errorf("Expected ErrInvalidMaxIterations, got %v", err)
+	}
+}
+
+func TestToolAgentNodeFn_SystemPrompt(t *testing.T) {
+	var sent []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(chatResponse("Arr!")))
+	}))
+	defer server.Close()
+
+	client := o.NewClient(server.URL, "key")
+	fn := o.ToolAgentNodeFn(1)(client.Chat, "gpt", a.WithSystemPrompt(a.SystemPrompt{Content: "You are an assistant."}))
+
+	userInput := a.CreateConversation(a.CreateMessage(a.User, "Hi"))
+	userInput.SystemPrompt = &a.SystemPrompt{Content: "You are a pirate."}
+	got, err := fn(userInput, a.CreateConversation(a.CreateMessage(a.System, "Be polite.")), func(a.Conversation) {})
+	if err != nil {
+		t.Fatalf("tool agent failed: %v", err)
+	}
+
+	if !strings.Contains(string(sent), "You are a pirate.") || strings.Contains(string(sent), "Be polite.") || strings.Contains(string(sent), "You are an assistant.") {
+		t.Errorf("Expected the system prompt of the invocation to be sent, got %s", sent)
+	}
+	if got.Messages[0].Content != "Be polite." {
+		t.Errorf("Expected the conversation to be left untouched, got %+v", got.Messages[0])
+	}
+}
+
+func TestCreateToolAgentNode_History(t *testing.T) {
+	var sent []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sent, _ = io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(chatResponse("Foggy in Milan.")))
+	}))
+	defer server.Close()
+
+	store := history.NewMemoryStore()
+	_ = store.Append(context.Background(), "thread-1", a.CreateMessage(a.User, "Weather in Rome?"), a.CreateMessage(a.Assistant, "Sunny in Rome."))
+
+	node, err := o.CreateToolAgentNode("Agent", "gpt", o.NewClient(server.URL, "key"), 1, history.WithHistory(store, 10))
+	if err != nil {
+		t.Fatalf("CreateToolAgentNode failed: %v", err)
+	}
+	stateMonitorCh := make(chan g.StateMonitorEntry[a.Conversation], 10)
+	runtime, err := b.CreateRuntime(b.CreateStartEdge(node), stateMonitorCh)
+	if err != nil {
+		t.Fatalf("CreateRuntime failed: %v", err)
+	}
+	runtime.AddEdge(b.CreateEndEdge(node))
+	defer runtime.Shutdown()
+
+	runtime.Invoke(a.CreateConversation(a.CreateMessage(a.User, "And in Milan?")), g.InvokeConfigThreadID("thread-1"))
+	timeout := time.After(5 * time.Second)
+	for done := false; !done; {
+		select {
+		case entry := <-stateMonitorCh:
+			if entry.Error != nil {
+				t.Fatalf("tool agent failed: %v", entry.Error)
+			}
+			done = !entry.Running
+		case <-timeout:
+			t.Fatal("Timeout waiting for the graph completion")
+		}
+	}
+
+	if !strings.Contains(string(sent), "Sunny in Rome.") || !strings.Contains(string(sent), "And in Milan?") {
+		t.Errorf("Expected the history to be sent before the conversation, got %s", sent)
+	}
+	saved, _ := store.Last(context.Background(), "thread-1", 0)
+	if len(saved) != 4 || saved[2].Content != "And in Milan?" || saved[3].Content != "Foggy in Milan." {
+		t.Errorf("Expected the new messages to be saved, got %+v", saved)
+	}
+}
@@ -0,0 +1,158 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/openai/openai-go/v3"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/agent/audio"
+)
+
+var (
+	_ audio.Transcriber = (*transcriber)(nil)
+	_ audio.Synthesizer = (*synthesizer)(nil)
+)
+
+type transcriber struct {
+	service openai.AudioTranscriptionService
+	model   string
+	opts    *audio.TranscriptionOptions
+}
+
+// NewTranscriber creates a Transcriber backed by the OpenAI transcription API.
+//
+// Any Whisper-compatible server can be used through the base URL of the client. With
+// audio.WithStreaming the transcript deltas are accumulated and handed to the partial
+// transcript function as they arrive: the models not supporting the streaming, such as
+// whisper-1, must be used without it.
+//
+// Parameters:
+//   - client: The OpenAI client instance.
+//   - model: The transcription model, e.g. "whisper-1" or "gpt-4o-transcribe".
+//   - opts: The transcription options.
+//
+// Returns:
+//   - The Transcriber.
+//   - An error if any option is invalid.
+//
+// Example usage:
+//
+//	transcriber, err := NewTranscriber(client, "gpt-4o-transcribe", audio.WithLanguage("en"), audio.WithStreaming())
+//	text, err := transcriber.Transcribe(ctx, a.Audio(wav, "audio/wav"), nil)
+func NewTranscriber(client *openai.Client, model string, opts ...audio.TranscriptionOption) (audio.Transcriber, error) {
+	useOpts, err := audio.CreateTranscriptionOptions(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create the transcriber: %w", err)
+	}
+	return &transcriber{service: client.Audio.Transcriptions, model: model, opts: useOpts}, nil
+}
+
+func (t *transcriber) Transcribe(ctx context.Context, clip a.ContentPart, onPartial audio.PartialTranscriptFn) (string, error) {
+	if onPartial == nil {
+		onPartial = func(string) {}
+	}
+
+	format := audioFormat(clip.MIMEType)
+	params := openai.AudioTranscriptionNewParams{
+		File:  openai.File(bytes.NewReader(clip.Data), "speech."+format, clip.MIMEType),
+		Model: openai.AudioModel(t.model),
+	}
+	if t.opts.Language != "" {
+		params.Language = openai.String(t.opts.Language)
+	}
+	if t.opts.Prompt != "" {
+		params.Prompt = openai.String(t.opts.Prompt)
+	}
+
+	if !t.opts.Stream {
+		resp, err := t.service.New(ctx, params)
+		if err != nil {
+			return "", classifyError(ctx, err)
+		}
+		return resp.Text, nil
+	}
+
+	stream := t.service.NewStreaming(ctx, params)
+	defer stream.Close()
+
+	var transcript strings.Builder
+	done := ""
+	for stream.Next() {
+		event := stream.Current()
+		switch event.Type {
+		case "transcript.text.delta":
+			transcript.WriteString(event.Delta)
+			onPartial(transcript.String())
+		case "transcript.text.done":
+			done = event.Text
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return "", classifyError(ctx, err)
+	}
+	if done != "" {
+		return done, nil
+	}
+	return transcript.String(), nil
+}
+
+type synthesizer struct {
+	service openai.AudioSpeechService
+	model   string
+	opts    *audio.SpeechOptions
+}
+
+// NewSynthesizer creates a Synthesizer backed by the OpenAI text-to-speech API.
+//
+// Parameters:
+//   - client: The OpenAI client instance.
+//   - model: The speech model, e.g. "tts-1" or "gpt-4o-mini-tts".
+//   - opts: The speech options.
+//
+// Returns:
+//   - The Synthesizer.
+//   - An error if any option is invalid.
+//
+// Example usage:
+//
+//	synthesizer, err := NewSynthesizer(client, "tts-1", audio.WithVoice("nova"), audio.WithSpeechFormat("wav"))
+//	clip, err := synthesizer.Synthesize(ctx, "Hello there")
+func NewSynthesizer(client *openai.Client, model string, opts ...audio.SpeechOption) (audio.Synthesizer, error) {
+	useOpts, err := audio.CreateSpeechOptions(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create the synthesizer: %w", err)
+	}
+	return &synthesizer{service: client.Audio.Speech, model: model, opts: useOpts}, nil
+}
+
+func (s *synthesizer) Synthesize(ctx context.Context, text string) (a.ContentPart, error) {
+	params := openai.AudioSpeechNewParams{
+		Input:          text,
+		Model:          openai.SpeechModel(s.model),
+		Voice:          openai.AudioSpeechNewParamsVoice(s.opts.Voice),
+		ResponseFormat: openai.AudioSpeechNewParamsResponseFormat(s.opts.Format),
+	}
+	if s.opts.Speed != 0 {
+		params.Speed = openai.Float(s.opts.Speed)
+	}
+	if s.opts.Instructions != "" {
+		params.Instructions = openai.String(s.opts.Instructions)
+	}
+
+	resp, err := s.service.New(ctx, params)
+	if err != nil {
+		return a.ContentPart{}, classifyError(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return a.ContentPart{}, fmt.Errorf("cannot read the speech: %w", err)
+	}
+	return a.Audio(data, audio.MIMEType(s.opts.Format)), nil
+}
@@ -0,0 +1,126 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go/v3/option"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/agent/audio"
+	o "github.com/morphy76/ggraph/pkg/agent/openai"
+)
+
+func TestTranscriber(t *testing.T) {
+	var fields map[string][]string
+	var fileName string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/audio/transcriptions" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("Expected a multipart request: %v", err)
+		}
+		fields = r.MultipartForm.Value
+		if files := r.MultipartForm.File["file"]; len(files) == 1 {
+			fileName = files[0].Filename
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"text":"Hello there"}`))
+	}))
+	defer server.Close()
+
+	client := o.NewClient(server.URL, "key", option.WithMaxRetries(0))
+	transcriber, err := o.NewTranscriber(client, "whisper-1", audio.WithLanguage("en"))
+	if err != nil {
+		t.Fatalf("NewTranscriber failed: %v", err)
+	}
+
+	text, err := transcriber.Transcribe(context.Background(), a.Audio([]byte("RIFF"), "audio/wav"), nil)
+	if err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+	if text != "Hello there" {
+		t.Errorf("Unexpected transcript %q", text)
+	}
+	if fields["model"][0] != "whisper-1" || fields["language"][0] != "en" {
+		t.Errorf("Unexpected request fields %v", fields)
+	}
+	if fileName != "speech.wav" {
+		t.Errorf("Expected the file name to carry the format, got %q", fileName)
+	}
+}
+
+func TestTranscriber_Stream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseMultipartForm(1 << 20)
+		if r.MultipartForm.Value["stream"][0] != "true" {
+			t.Errorf("Expected a streaming request, got %v", r.MultipartForm.Value)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range []string{
+			`{"type":"transcript.text.delta","delta":"Hello"}`,
+			`{"type":"transcript.text.delta","delta":" there"}`,
+			`{"type":"transcript.text.done","text":"Hello there."}`,
+		} {
+			w.Write([]byte("data: " + event + "\n\n"))
+		}
+	}))
+	defer server.Close()
+
+	client := o.NewClient(server.URL, "key", option.WithMaxRetries(0))
+	transcriber, _ := o.NewTranscriber(client, "gpt-4o-transcribe", audio.WithStreaming())
+
+	var partials []string
+	text, err := transcriber.Transcribe(context.Background(), a.Audio([]byte("ID3"), "audio/mpeg"), func(partial string) {
+		partials = append(partials, partial)
+	})
+	if err != nil {
+		t.Fatalf("Transcribe failed: %v", err)
+	}
+	if text != "Hello there." {
+		t.Errorf("Expected the final transcript, got %q", text)
+	}
+	if len(partials) != 2 || partials[1] != "Hello there" {
+		t.Errorf("Expected the growing transcript, got %v", partials)
+	}
+}
+
+func TestSynthesizer(t *testing.T) {
+	var req map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/audio/speech" {
+			t.Errorf("Unexpected path %s", r.URL.Path)
+		}
+		body, _ := io.ReadAll(r.Body)
+		json.Unmarshal(body, &req)
+		w.Header().Set("Content-Type", "audio/wav")
+		w.Write([]byte("RIFF"))
+	}))
+	defer server.Close()
+
+	client := o.NewClient(server.URL, "key", option.WithMaxRetries(0))
+	synthesizer, err := o.NewSynthesizer(client, "tts-1", audio.WithVoice("nova"), audio.WithSpeechFormat("wav"), audio.WithSpeed(1.25))
+	if err != nil {
+		t.Fatalf("NewSynthesizer failed: %v", err)
+	}
+
+	clip, err := synthesizer.Synthesize(context.Background(), "Hello")
+	if err != nil {
+		t.Fatalf("Synthesize failed: %v", err)
+	}
+	if clip.Kind != a.AudioPart || string(clip.Data) != "RIFF" || clip.MIMEType != "audio/wav" {
+		t.Errorf("Unexpected clip %+v", clip)
+	}
+	if req["input"] != "Hello" || req["voice"] != "nova" || req["response_format"] != "wav" || req["speed"] != 1.25 {
+		t.Errorf("Unexpected request %v", req)
+	}
+
+	if _, err := o.NewSynthesizer(client, "tts-1", audio.WithSpeed(9)); err == nil {
+		t.Error("Expected the invalid speed to be rejected")
+	}
+}
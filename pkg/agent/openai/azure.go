@@ -0,0 +1,190 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+const (
+	// AzureDefaultAPIVersion is the Azure OpenAI API version used when none is configured.
+	AzureDefaultAPIVersion = "2024-10-21"
+	// EnvKeyAzureAPIKey is the environment variable key for the Azure OpenAI API key.
+	EnvKeyAzureAPIKey = "AZURE_OPENAI_API_KEY"
+	// EnvKeyAzureEndpoint is the environment variable key for the Azure OpenAI endpoint.
+	EnvKeyAzureEndpoint = "AZURE_OPENAI_ENDPOINT"
+	// EnvKeyAzureAPIVersion is the environment variable key for the Azure OpenAI API version.
+	EnvKeyAzureAPIVersion = "AZURE_OPENAI_API_VERSION"
+)
+
+var (
+	// ErrAzureEndpointEmpty indicates that the Azure OpenAI endpoint is not set.
+	ErrAzureEndpointEmpty = errors.New("azure endpoint cannot be empty")
+	// ErrAzureDeploymentEmpty indicates that the Azure OpenAI deployment name is not set.
+	ErrAzureDeploymentEmpty = errors.New("azure deployment cannot be empty")
+	// ErrAzureAuthMissing indicates that neither an API key nor a token provider is set.
+	ErrAzureAuthMissing = errors.New("azure authentication requires either an API key or a token provider")
+	// ErrAzureAuthAmbiguous indicates that both an API key and a token provider are set.
+	ErrAzureAuthAmbiguous = errors.New("azure authentication accepts either an API key or a token provider, not both")
+)
+
+// AzureTokenProvider returns a Microsoft Entra ID (Azure AD) access token for the Azure OpenAI scope.
+//
+// An azcore.TokenCredential can be adapted with:
+//
+//	provider := func(ctx context.Context) (string, error) {
+//	    tk, err := cred.GetToken(ctx, policy.TokenRequestOptions{Scopes: []string{"https://cognitiveservices.azure.com/.default"}})
+//	    return tk.Token, err
+//	}
+type AzureTokenProvider func(ctx context.Context) (string, error)
+
+// AzureConfig holds the configuration to reach an Azure OpenAI deployment.
+type AzureConfig struct {
+	// Endpoint is the Azure OpenAI resource endpoint, e.g. https://my-resource.openai.azure.com.
+	Endpoint string
+	// Deployment is the name of the model deployment; it replaces the model name in requests.
+	Deployment string
+	// APIVersion is the Azure OpenAI API version; AzureDefaultAPIVersion when empty.
+	APIVersion string
+	// APIKey is the Azure OpenAI API key, mutually exclusive with TokenProvider.
+	APIKey string
+	// TokenProvider supplies Azure AD bearer tokens, mutually exclusive with APIKey.
+	TokenProvider AzureTokenProvider
+}
+
+// AzureConfigFromEnv builds an AzureConfig for the given deployment from the
+// AZURE_OPENAI_ENDPOINT, AZURE_OPENAI_API_KEY and AZURE_OPENAI_API_VERSION environment variables.
+//
+// Parameters:
+//   - deployment: The name of the model deployment.
+//
+// Returns:
+//   - The AzureConfig populated from the environment.
+func AzureConfigFromEnv(deployment string) AzureConfig {
+	return AzureConfig{
+		Endpoint:   os.Getenv(EnvKeyAzureEndpoint),
+		Deployment: deployment,
+		APIVersion: os.Getenv(EnvKeyAzureAPIVersion),
+		APIKey:     os.Getenv(EnvKeyAzureAPIKey),
+	}
+}
+
+// Validate checks that the configuration is complete.
+//
+// Returns:
+//   - An error describing the first missing or conflicting setting, otherwise nil.
+func (c AzureConfig) Validate() error {
+	if c.Endpoint == "" {
+		return ErrAzureEndpointEmpty
+	}
+	if c.Deployment == "" {
+		return ErrAzureDeploymentEmpty
+	}
+	if c.APIKey == "" && c.TokenProvider == nil {
+		return ErrAzureAuthMissing
+	}
+	if c.APIKey != "" && c.TokenProvider != nil {
+		return ErrAzureAuthAmbiguous
+	}
+	return nil
+}
+
+// NewAzureClient creates a new OpenAI client targeting an Azure OpenAI deployment.
+//
+// Requests are routed to {endpoint}/openai/deployments/{deployment}/... with the
+// api-version query parameter, authenticated either with the Api-Key header or with
+// a bearer token obtained from the configured token provider. The values of the invocation
+// propagated as HTTP headers are set on every request, see g.ValueKey.PropagatedAs.
+//
+// Parameters:
+//   - config: The Azure OpenAI configuration.
+//   - opts: Additional request options.
+//
+// Returns:
+//   - An instance of openai.Client configured for Azure OpenAI.
+//   - An error if the configuration is invalid.
+//
+// Example usage:
+//
+//	client, err := NewAzureClient(AzureConfigFromEnv("gpt-4o-mini"))
+func NewAzureClient(
+	config AzureConfig,
+	opts ...option.RequestOption,
+) (*openai.Client, error) {
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("cannot create the azure client: %w", err)
+	}
+
+	useAPIVersion := config.APIVersion
+	if useAPIVersion == "" {
+		useAPIVersion = AzureDefaultAPIVersion
+	}
+
+	baseURL := strings.TrimSuffix(config.Endpoint, "/") + "/openai/deployments/" + config.Deployment + "/"
+
+	useOpts := append(opts,
+		option.WithBaseURL(baseURL),
+		option.WithQueryAdd("api-version", useAPIVersion),
+		option.WithHeaderDel("authorization"),
+		option.WithMiddleware(propagateHeaders),
+	)
+	if config.TokenProvider != nil {
+		useOpts = append(useOpts, option.WithMiddleware(azureTokenMiddleware(config.TokenProvider)))
+	} else {
+		useOpts = append(useOpts, option.WithHeader("Api-Key", config.APIKey))
+	}
+
+	rv := openai.NewClient(useOpts...)
+	return &rv, nil
+}
+
+// CreateAzureConversationNode creates a graph node for an Azure OpenAI-based chat agent.
+//
+// The deployment name of the configuration is used as the model of the conversation.
+//
+// Parameters:
+//   - name: The unique name for the node.
+//   - config: The Azure OpenAI configuration.
+//   - conversationNodeFn: A function that creates the node function for the chat agent.
+//   - conversationOptions: Additional conversation options for the API calls.
+//
+// Returns:
+//   - An instance of g.Node[a.Conversation] configured for the Azure OpenAI chat agent.
+//   - An error if the client or the node creation fails.
+//
+// Example usage:
+//
+//	node, err := CreateAzureConversationNode("ChatNode", AzureConfigFromEnv("gpt-4o-mini"), myOpenAINodeFn)
+func CreateAzureConversationNode(
+	name string,
+	config AzureConfig,
+	conversationNodeFn ConversationNodeFn,
+	conversationOptions ...a.ModelOption,
+) (g.Node[a.Conversation], error) {
+	client, err := NewAzureClient(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create a conversation node: %w", err)
+	}
+
+	return CreateConversationNode(name, config.Deployment, client, conversationNodeFn, conversationOptions...)
+}
+
+func azureTokenMiddleware(provider AzureTokenProvider) option.Middleware {
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		token, err := provider(req.Context())
+		if err != nil {
+			return nil, fmt.Errorf("cannot acquire azure token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+		return next(req)
+	}
+}
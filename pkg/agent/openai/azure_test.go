@@ -0,0 +1,131 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go/v3"
+
+	ggraphopenai "github.com/morphy76/ggraph/pkg/agent/openai"
+)
+
+const azureChatResponse = `{"id":"1","object":"chat.completion","created":0,"model":"gpt","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":"hi"}}]}`
+
+func TestAzureConfig_Validate(t *testing.T) {
+	provider := func(ctx context.Context) (string, error) { return "token", nil }
+
+	tests := []struct {
+		name   string
+		config ggraphopenai.AzureConfig
+		want   error
+	}{
+		{"missing endpoint", ggraphopenai.AzureConfig{Deployment: "d", APIKey: "k"}, ggraphopenai.ErrAzureEndpointEmpty},
+		{"missing deployment", ggraphopenai.AzureConfig{Endpoint: "e", APIKey: "k"}, ggraphopenai.ErrAzureDeploymentEmpty},
+		{"missing auth", ggraphopenai.AzureConfig{Endpoint: "e", Deployment: "d"}, ggraphopenai.ErrAzureAuthMissing},
+		{"ambiguous auth", ggraphopenai.AzureConfig{Endpoint: "e", Deployment: "d", APIKey: "k", TokenProvider: provider}, ggraphopenai.ErrAzureAuthAmbiguous},
+		{"api key", ggraphopenai.AzureConfig{Endpoint: "e", Deployment: "d", APIKey: "k"}, nil},
+		{"token provider", ggraphopenai.AzureConfig{Endpoint: "e", Deployment: "d", TokenProvider: provider}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.config.Validate(); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestNewAzureClient_APIKey(t *testing.T) {
+	var gotPath, gotVersion, gotKey, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath = r.URL.Path
+		gotVersion = r.URL.Query().Get("api-version")
+		gotKey = r.Header.Get("Api-Key")
+		gotAuth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(azureChatResponse))
+	}))
+	defer server.Close()
+
+	client, err := ggraphopenai.NewAzureClient(ggraphopenai.AzureConfig{
+		Endpoint:   server.URL + "/",
+		Deployment: "my-deployment",
+		APIVersion: "2024-06-01",
+		APIKey:     "secret",
+	})
+	if err != nil {
+		t.Fatalf("NewAzureClient() failed: %v", err)
+	}
+
+	_, err = client.Chat.Completions.New(context.Background(), openai.ChatCompletionNewParams{
+		Model:    "my-deployment",
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hello")},
+	})
+	if err != nil {
+		t.Fatalf("chat completion failed: %v", err)
+	}
+
+	if gotPath != "/openai/deployments/my-deployment/chat/completions" {
+		t.Errorf("Unexpected path '%s'", gotPath)
+	}
+	if gotVersion != "2024-06-01" {
+		t.Errorf("Expected api-version '2024-06-01', got '%s'", gotVersion)
+	}
+	if gotKey != "secret" {
+		t.Errorf("Expected Api-Key 'secret', got '%s'", gotKey)
+	}
+	if gotAuth != "" {
+		t.Errorf("Expected no Authorization header, got '%s'", gotAuth)
+	}
+}
+
+func TestNewAzureClient_TokenProvider(t *testing.T) {
+	var gotVersion, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotVersion = r.URL.Query().Get("api-version")
+		gotAuth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(azureChatResponse))
+	}))
+	defer server.Close()
+
+	client, err := ggraphopenai.NewAzureClient(ggraphopenai.AzureConfig{
+		Endpoint:   server.URL,
+		Deployment: "my-deployment",
+		TokenProvider: func(ctx context.Context) (string, error) {
+			return "ad-token", nil
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewAzureClient() failed: %v", err)
+	}
+
+	_, err = client.Chat.Completions.New(context.Background(), openai.ChatCompletionNewParams{
+		Model:    "my-deployment",
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hello")},
+	})
+	if err != nil {
+		t.Fatalf("chat completion failed: %v", err)
+	}
+
+	if gotAuth != "Bearer ad-token" {
+		t.Errorf("Expected bearer token, got '%s'", gotAuth)
+	}
+	if gotVersion != ggraphopenai.AzureDefaultAPIVersion {
+		t.Errorf("Expected default api-version, got '%s'", gotVersion)
+	}
+}
+
+func TestCreateAzureConversationNode_InvalidConfig(t *testing.T) {
+	node, err := ggraphopenai.CreateAzureConversationNode("Chat", ggraphopenai.AzureConfig{}, nil)
+	if err == nil {
+		t.Fatal("Expected error for invalid azure configuration, got nil")
+	}
+	if node != nil {
+		t.Error("Expected nil node for invalid azure configuration")
+	}
+}
@@ -2,6 +2,7 @@ package openai
 
 import (
 	"fmt"
+	"net/http"
 
 	"github.com/openai/openai-go/v3"
 	"github.com/openai/openai-go/v3/option"
@@ -13,7 +14,8 @@ import (
 
 // NewClient creates a new OpenAI client with the specified base URL and API key.
 //
-// Additional request options can be provided as needed.
+// Additional request options can be provided as needed. The values of the invocation propagated as
+// HTTP headers are set on every request, see g.ValueKey.PropagatedAs.
 //
 // Parameters:
 //   - baseURL: The base URL for the OpenAI API.
@@ -33,6 +35,7 @@ func NewClient(
 	useOpts := append(opts,
 		option.WithBaseURL(baseURL),
 		option.WithAPIKey(apiKey),
+		option.WithMiddleware(propagateHeaders),
 	)
 	rv := openai.NewClient(useOpts...)
 	return &rv
@@ -87,6 +90,10 @@ func CreateCompletionNode(
 
 // CreateConversationNode creates a graph node for an OpenAI-based chat agent.
 //
+// When the options carry a response format, see a.WithResponseFormat, the final answer of the
+// node is validated: an invalid answer is repaired with the model, as by NewStructuredConversation,
+// and replaced by the valid one; the node fails if no valid answer is produced.
+//
 // Parameters:
 //   - name: The unique name for the node.
 //   - model: The OpenAI model to be used for the chat agent.
@@ -108,6 +115,9 @@ func CreateConversationNode(
 	conversationOptions ...a.ModelOption,
 ) (g.Node[a.Conversation], error) {
 	openAIFn := conversationNodeFn(client.Chat, model, conversationOptions...)
+	if useOpts, err := a.CreateConversationOptions(model, nil, conversationOptions...); err == nil && useOpts.ResponseFormat != nil {
+		openAIFn = structuredNodeFn(client.Chat, useOpts, openAIFn)
+	}
 
 	routingPolicy, err := b.CreateConditionalRoutePolicy(a.ToolProcessorRoutingFn)
 	if err != nil {
@@ -118,3 +128,33 @@ func CreateConversationNode(
 		g.WithRoutingPolicy(routingPolicy))
 	return rv, err
 }
+
+// WithLimiter throttles the requests of the client with a shared provider limiter.
+//
+// Rate-limited requests are retried by the limiter honouring the Retry-After header, every
+// request of the client being held meanwhile; combine it with option.WithMaxRetries(0) to leave
+// the 429 handling to the limiter only.
+//
+// Parameters:
+//   - limiter: The provider limiter, usually shared by all the clients of the same account.
+//
+// Returns:
+//   - A request option to be passed to NewClient, NewOpenAIClient or the AIW client.
+//
+// Example usage:
+//
+//	limiter, _ := a.NewProviderLimiter(a.WithMaxInFlight(4), a.WithTokensPerMinute(90000))
+//	client := NewOpenAIClient("your-api-key", WithLimiter(limiter))
+func WithLimiter(limiter *a.ProviderLimiter) option.RequestOption {
+	return option.WithMiddleware(func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		return limiter.Send(req, next)
+	})
+}
+
+// propagateHeaders sets the headers of the invocation values propagated to the provider, see g.PropagatedHeaders.
+func propagateHeaders(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+	for header, value := range g.PropagatedHeaders(req.Context()) {
+		req.Header.Set(header, value)
+	}
+	return next(req)
+}
@@ -0,0 +1,71 @@
+package openai_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/v3/option"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	o "github.com/morphy76/ggraph/pkg/agent/openai"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func TestWithLimiter(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":{"message":"slow down"}}`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(chatResponse("hello")))
+	}))
+	defer server.Close()
+
+	limiter, err := a.NewProviderLimiter(a.WithMaxInFlight(1), a.WithLimiterRetries(1, time.Millisecond, time.Second))
+	if err != nil {
+		t.Fatalf("NewProviderLimiter failed: %v", err)
+	}
+	client := o.NewClient(server.URL, "key", option.WithMaxRetries(0), o.WithLimiter(limiter))
+
+	opts, _ := a.CreateConversationOptions("gpt", []a.Message{a.CreateMessage(a.User, "hi")})
+	answer, err := o.ChatCompletion(context.Background(), client.Chat, opts)
+	if err != nil {
+		t.Fatalf("Expected the limiter to retry the rate-limited request, got %v", err)
+	}
+	if answer.Content != "hello" || requests != 2 {
+		t.Errorf("Unexpected answer %q after %d requests", answer.Content, requests)
+	}
+}
+
+func TestNewClient_PropagatedHeaders(t *testing.T) {
+	var requestID, userID string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID, userID = r.Header.Get("X-Request-ID"), r.Header.Get("X-User-ID")
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(chatResponse("hello")))
+	}))
+	defer server.Close()
+
+	client := o.NewClient(server.URL, "key", option.WithMaxRetries(0))
+	ctx := g.ContextWithValue(context.Background(), g.NewValueKey[string]("request_id").PropagatedAs("X-Request-ID"), "req-1")
+	ctx = g.ContextWithValue(ctx, g.NewValueKey[string]("user_id"), "u-42")
+
+	opts, _ := a.CreateConversationOptions("gpt", []a.Message{a.CreateMessage(a.User, "hi")})
+	if _, err := o.ChatCompletion(ctx, client.Chat, opts); err != nil {
+		t.Fatalf("ChatCompletion failed: %v", err)
+	}
+	if requestID != "req-1" {
+		t.Errorf("Expected the request ID to be propagated, got %q", requestID)
+	}
+	if userID != "" {
+		t.Errorf("Expected the user ID not to be propagated, got %q", userID)
+	}
+}
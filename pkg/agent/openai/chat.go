@@ -0,0 +1,122 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+
+	ir "github.com/morphy76/ggraph/internal/agent/ratelimit"
+	a "github.com/morphy76/ggraph/pkg/agent"
+)
+
+// CacheKindChat identifies the chat completion requests in the response cache.
+const CacheKindChat = "openai.chat"
+
+// ChatCompletion sends the conversation to the model and returns the assistant message.
+//
+// The response cache of the options is consulted first, see a.WithResponseCache; the usage of
+// the request is reported to the invocation, cache hits report none. The transient failures are
+// retried with the policy of the options, see a.WithRetry, the retries of the client being
+// disabled for the request. When the options carry a response format, see a.WithResponseFormat,
+// the answer is validated and repaired as by NewStructuredConversation; the answers requesting
+// tool calls are returned as they are.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - chatService: The OpenAI ChatService client.
+//   - modelOptions: The conversation options, with the messages to send.
+//
+// Returns:
+//   - The assistant message, with a new identifier and the requested tool calls if any.
+//   - An error if the request fails, the model returns no choice or no valid structured answer.
+//
+// Example usage:
+//
+//	opts, _ := a.CreateConversationOptions(model, messages, a.WithResponseCache(cache, 0))
+//	answer, err := ChatCompletion(ctx, client.Chat, opts)
+func ChatCompletion(ctx context.Context, chatService openai.ChatService, modelOptions *a.ModelOptions) (a.Message, error) {
+	rv, err := chatCompletion(ctx, chatService, modelOptions)
+	if err != nil || modelOptions.ResponseFormat == nil || len(rv.ToolCalls) > 0 {
+		return rv, err
+	}
+	return structuredAnswer(ctx, chatService, modelOptions, modelOptions.Messages, rv)
+}
+
+// chatCompletion sends the conversation to the model, see ChatCompletion, without validating the structured answers.
+func chatCompletion(ctx context.Context, chatService openai.ChatService, modelOptions *a.ModelOptions) (a.Message, error) {
+	rv, _, err := a.CachedCall(ctx, CacheKindChat, modelOptions, func(ctx context.Context) (a.Message, error) {
+		resp, err := a.RetryCall(ctx, modelOptions, func(ctx context.Context) (*openai.ChatCompletion, error) {
+			resp, err := chatService.Completions.New(ctx, ConvertConversationOptions(modelOptions), retryOptions(modelOptions)...)
+			return resp, classifyError(ctx, err)
+		})
+		if err != nil {
+			return a.Message{}, err
+		}
+		reportCompletionUsage(ctx, resp)
+		if len(resp.Choices) == 0 {
+			return a.Message{}, ErrNoChoices
+		}
+
+		answer := resp.Choices[0].Message
+		message := a.Message{Ts: time.Now(), Role: a.Assistant, Content: answer.Content}
+		if resp.Created != 0 {
+			message.CreatedAt = time.Unix(resp.Created, 0)
+		}
+		for _, openAIToolCall := range answer.ToolCalls {
+			toolCall, err := ConvertToolCall(openAIToolCall)
+			if err != nil {
+				return a.Message{}, fmt.Errorf("failed to convert tool call: %w", err)
+			}
+			message.ToolCalls = append(message.ToolCalls, *toolCall)
+		}
+		return message, nil
+	})
+	if err != nil {
+		return a.Message{}, err
+	}
+	rv.ID = a.NewMessageID()
+	rv.Ts = time.Now()
+	return rv, nil
+}
+
+// retryOptions disables the retries of the client when the options carry a retry policy, so that the
+// transient failures are retried by the policy only.
+func retryOptions(modelOptions *a.ModelOptions) []option.RequestOption {
+	if modelOptions.Retry == nil {
+		return nil
+	}
+	return []option.RequestOption{option.WithMaxRetries(0)}
+}
+
+// classifyError marks the failures worth retrying as a.ErrTransient: the 408, 429 and 5xx
+// statuses, honouring their Retry-After header, and the timeouts of the single attempt.
+func classifyError(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) {
+		status := apiErr.StatusCode
+		if status == http.StatusRequestTimeout || status == http.StatusTooManyRequests || status >= http.StatusInternalServerError {
+			var retryAfter time.Duration
+			if apiErr.Response != nil {
+				retryAfter = ir.RetryAfter(apiErr.Response.Header)
+			}
+			return &a.TransientError{RetryAfter: retryAfter, Err: err}
+		}
+		return err
+	}
+
+	var netErr net.Error
+	if ctx.Err() == nil && (errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout())) {
+		return &a.TransientError{Err: err}
+	}
+	return err
+}
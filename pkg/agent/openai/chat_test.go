@@ -0,0 +1,91 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	o "github.com/morphy76/ggraph/pkg/agent/openai"
+)
+
+func TestChatCompletion_Cache(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(toolCallResponse))
+	}))
+	defer server.Close()
+
+	cache, _ := a.NewLRUCache(10)
+	opts, err := a.CreateConversationOptions("gpt", []a.Message{a.CreateMessage(a.User, "4+5?")}, a.WithTemperature(0), a.WithResponseCache(cache, 0))
+	if err != nil {
+		t.Fatalf("CreateConversationOptions failed: %v", err)
+	}
+	client := o.NewClient(server.URL, "key")
+
+	ids := make(map[string]struct{})
+	for i := 0; i < 2; i++ {
+		answer, err := o.ChatCompletion(context.Background(), client.Chat, opts)
+		if err != nil {
+			t.Fatalf("ChatCompletion failed: %v", err)
+		}
+		if len(answer.ToolCalls) != 1 || answer.ToolCalls[0].ID != "call_1" || answer.ToolCalls[0].ToolName != "additionTool" {
+			t.Errorf("Unexpected answer %+v", answer)
+		}
+		ids[answer.ID] = struct{}{}
+	}
+	if _, ok := ids[""]; ok || len(ids) != 2 {
+		t.Errorf("Expected each answer to get its own identifier, got %v", ids)
+	}
+	if requests != 1 {
+		t.Errorf("Expected the second request to be served by the cache, got %d requests", requests)
+	}
+}
+
+func TestChatCompletion_Retry(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(chatResponse("recovered")))
+	}))
+	defer server.Close()
+
+	client := o.NewClient(server.URL, "key")
+	opts, _ := a.CreateConversationOptions("gpt", []a.Message{a.CreateMessage(a.User, "hi")},
+		a.WithRetry(2, 0), a.WithRetryBackoff(time.Millisecond, time.Millisecond))
+
+	answer, err := o.ChatCompletion(context.Background(), client.Chat, opts)
+	if err != nil || answer.Content != "recovered" || requests != 2 {
+		t.Errorf("Expected the 503 to be retried, got %q, %v after %d requests", answer.Content, err, requests)
+	}
+
+	requests = 0
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	if _, err := o.ChatCompletion(context.Background(), client.Chat, opts); errors.Is(err, a.ErrTransient) || requests != 1 {
+		t.Errorf("Expected the 400 not to be retried, got %v after %d requests", err, requests)
+	}
+
+	requests = 0
+	server.Config.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Retry-After", "0")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	if _, err := o.ChatCompletion(context.Background(), client.Chat, opts); !errors.Is(err, a.ErrTransient) || requests != 3 {
+		t.Errorf("Expected the 503 to be retried by the policy only, got %v after %d requests", err, requests)
+	}
+}
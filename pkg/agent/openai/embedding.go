@@ -0,0 +1,85 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/openai/openai-go/v3"
+
+	ie "github.com/morphy76/ggraph/internal/agent/embedding"
+	ir "github.com/morphy76/ggraph/internal/agent/ratelimit"
+	a "github.com/morphy76/ggraph/pkg/agent"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+var _ a.Embedder = (*embedder)(nil)
+
+type embedder struct {
+	service openai.EmbeddingService
+	model   string
+	opts    *a.EmbedderOptions
+}
+
+// NewEmbedder creates an Embedder backed by the OpenAI embeddings API.
+//
+// Texts are sent in batches of the configured size; rate-limited batches are retried
+// honouring the Retry-After header.
+//
+// Parameters:
+//   - client: The OpenAI client instance.
+//   - model: The embedding model, e.g. "text-embedding-3-small".
+//   - opts: The embedder options.
+//
+// Returns:
+//   - The Embedder.
+//   - An error if any option is invalid.
+//
+// Example usage:
+//
+//	embedder, err := NewEmbedder(client, "text-embedding-3-small", a.WithEmbeddingBatchSize(32))
+//	vectors, err := embedder.Embed(ctx, []string{"hello", "world"})
+func NewEmbedder(client *openai.Client, model string, opts ...a.EmbedderOption) (a.Embedder, error) {
+	useOpts, err := a.CreateEmbedderOptions(opts...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create the embedder: %w", err)
+	}
+	return &embedder{service: client.Embeddings, model: model, opts: useOpts}, nil
+}
+
+func (e *embedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return ie.Batched(ctx, texts, e.opts, e.embedBatch)
+}
+
+func (e *embedder) embedBatch(ctx context.Context, batch []string) ([][]float32, error) {
+	params := openai.EmbeddingNewParams{
+		Input: openai.EmbeddingNewParamsInputUnion{OfArrayOfStrings: batch},
+		Model: openai.EmbeddingModel(e.model),
+	}
+	if e.opts.Dimensions != nil {
+		params.Dimensions = openai.Int(*e.opts.Dimensions)
+	}
+
+	resp, err := e.service.New(ctx, params)
+	if err != nil {
+		var apiErr *openai.Error
+		if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusTooManyRequests {
+			return nil, &a.RateLimitError{RetryAfter: ir.RetryAfter(apiErr.Response.Header), Err: err}
+		}
+		return nil, err
+	}
+	g.ReportUsage(ctx, g.Usage{PromptTokens: resp.Usage.PromptTokens, TotalTokens: resp.Usage.TotalTokens})
+
+	rv := make([][]float32, len(batch))
+	for _, data := range resp.Data {
+		if data.Index < 0 || int(data.Index) >= len(rv) {
+			return nil, fmt.Errorf("%w: unexpected index %d", a.ErrEmbeddingCountMismatch, data.Index)
+		}
+		rv[data.Index] = ie.ToFloat32(data.Embedding)
+	}
+	if len(resp.Data) != len(batch) {
+		return nil, fmt.Errorf("%w: %d texts, %d embeddings", a.ErrEmbeddingCountMismatch, len(batch), len(resp.Data))
+	}
+	return rv, nil
+}
@@ -0,0 +1,85 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/v3/option"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	o "github.com/morphy76/ggraph/pkg/agent/openai"
+)
+
+func TestEmbedder(t *testing.T) {
+	var batches [][]string
+	rateLimited := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !rateLimited {
+			rateLimited = true
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"error":{"message":"slow down"}}`))
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		var req struct {
+			Input []string `json:"input"`
+		}
+		json.Unmarshal(body, &req)
+		batches = append(batches, req.Input)
+
+		data := make([]string, len(req.Input))
+		for i := range req.Input {
+			// Reverse the order to check the index mapping.
+			idx := len(req.Input) - 1 - i
+			data[i] = fmt.Sprintf(`{"object":"embedding","index":%d,"embedding":[%d]}`, idx, len(req.Input[idx]))
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"object":"list","model":"m","data":[` + strings.Join(data, ",") + `],"usage":{"prompt_tokens":1,"total_tokens":1}}`))
+	}))
+	defer server.Close()
+
+	client := o.NewClient(server.URL, "key", option.WithMaxRetries(0))
+	embedder, err := o.NewEmbedder(client, "text-embedding-3-small", a.WithEmbeddingBatchSize(2), a.WithEmbeddingRetries(1, time.Millisecond))
+	if err != nil {
+		t.Fatalf("NewEmbedder failed: %v", err)
+	}
+
+	vectors, err := embedder.Embed(context.Background(), []string{"a", "bb", "ccc"})
+	if err != nil {
+		t.Fatalf("Embed failed: %v", err)
+	}
+
+	if len(batches) != 2 || len(batches[0]) != 2 || len(batches[1]) != 1 {
+		t.Errorf("Expected batches of 2 and 1 texts, got %v", batches)
+	}
+	for i, expected := range []float32{1, 2, 3} {
+		if len(vectors[i]) != 1 || vectors[i][0] != expected {
+			t.Errorf("Unexpected vector %d: %v", i, vectors[i])
+		}
+	}
+}
+
+func TestEmbedder_RateLimitExhausted(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":{"message":"slow down"}}`))
+	}))
+	defer server.Close()
+
+	client := o.NewClient(server.URL, "key", option.WithMaxRetries(0))
+	embedder, _ := o.NewEmbedder(client, "text-embedding-3-small", a.WithEmbeddingRetries(2, time.Millisecond))
+
+	if _, err := embedder.Embed(context.Background(), []string{"a"}); !errors.Is(err, a.ErrRateLimited) {
+		t.Errorf("Expected ErrRateLimited, got %v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	b "github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// BackendMetadataKey is the conversation metadata key of the backend which produced the last answer.
+const BackendMetadataKey = "ggraph.backend"
+
+var (
+	// ErrNoBackends indicates that a failover node was configured without backends.
+	ErrNoBackends = errors.New("at least one backend is required")
+	// ErrInvalidBackend indicates that a backend has no name, client or model.
+	ErrInvalidBackend = errors.New("backend requires a name, a client and a model")
+	// ErrAllBackendsFailed indicates that no backend produced an answer.
+	ErrAllBackendsFailed = errors.New("all backends failed")
+)
+
+// Backend is an OpenAI-compatible endpoint and model a failover node can answer with.
+type Backend struct {
+	// Name identifies the backend in the conversation metadata, see BackendMetadataKey.
+	Name string
+	// Client is the client of the endpoint, e.g. built with NewClient or NewAzureClient.
+	Client *openai.Client
+	// Model is the model, or the Azure deployment, to call.
+	Model string
+	// Timeout bounds a request to the backend, 0 for no bound.
+	Timeout time.Duration
+	// Options are the conversation options of this backend only, applied after the shared ones.
+	Options []a.ModelOption
+}
+
+// CreateFailoverConversationNode creates a conversation node answering with the first backend succeeding.
+//
+// The backends are tried in order: when a backend fails or times out, the same conversation is
+// sent to the next one, so that an outage of the primary provider does not fail the thread.
+// The name of the backend which produced the answer is recorded in the conversation metadata
+// under BackendMetadataKey; the tool calls of the answer are routed as by CreateConversationNode.
+//
+// Parameters:
+//   - name: The unique name for the node.
+//   - backends: The backends, in order of preference.
+//   - conversationOptions: The conversation options shared by the backends.
+//
+// Returns:
+//   - An instance of g.Node[a.Conversation] failing over the backends.
+//   - An error if the backends are invalid or the node creation fails.
+//
+// Example usage:
+//
+//	node, err := CreateFailoverConversationNode("Chat", []Backend{
+//	    {Name: "openai", Client: NewOpenAIClient(key), Model: "gpt-4o-mini", Timeout: 20 * time.Second},
+//	    {Name: "azure", Client: azureClient, Model: "gpt-4o-mini"},
+//	}, a.WithTemperature(0))
+func CreateFailoverConversationNode(
+	name string,
+	backends []Backend,
+	conversationOptions ...a.ModelOption,
+) (g.Node[a.Conversation], error) {
+	if len(backends) == 0 {
+		return nil, fmt.Errorf("cannot create a failover node: %w", ErrNoBackends)
+	}
+	for idx, backend := range backends {
+		if backend.Name == "" || backend.Client == nil || backend.Model == "" {
+			return nil, fmt.Errorf("cannot create a failover node: backend %d: %w", idx, ErrInvalidBackend)
+		}
+	}
+
+	routingPolicy, err := b.CreateConditionalRoutePolicy(a.ToolProcessorRoutingFn)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create a failover node: %w", err)
+	}
+
+	return b.NewContextNode(name, failoverFn(append([]Backend{}, backends...), conversationOptions...),
+		g.WithRoutingPolicy(routingPolicy))
+}
+
+func failoverFn(backends []Backend, conversationOptions ...a.ModelOption) g.ContextNodeFn[a.Conversation] {
+	return func(ctx context.Context, userInput, currentState a.Conversation, notify g.NotifyPartialFn[a.Conversation]) (a.Conversation, error) {
+		messages := a.PromptMessages(userInput, currentState)
+
+		var errs []error
+		for _, backend := range backends {
+			answer, useOpts, err := askBackend(ctx, backend, messages, conversationOptions, userInput, currentState)
+			if err == nil {
+				if err := a.SaveHistory(ctx, useOpts, append(slices.Clone(userInput.Messages), answer)); err != nil {
+					return currentState, fmt.Errorf("failover request failed: %w", err)
+				}
+				metadata := maps.Clone(currentState.Metadata)
+				if metadata == nil {
+					metadata = make(map[string]string)
+				}
+				metadata[BackendMetadataKey] = backend.Name
+				return a.Conversation{
+					Messages:         append(messages, answer),
+					CurrentToolCalls: answer.ToolCalls,
+					Metadata:         metadata,
+				}, nil
+			}
+			if ctx.Err() != nil {
+				return currentState, fmt.Errorf("failover request cancelled: %w", ctx.Err())
+			}
+			errs = append(errs, fmt.Errorf("backend %s: %w", backend.Name, err))
+		}
+
+		return currentState, fmt.Errorf("%w: %w", ErrAllBackendsFailed, errors.Join(errs...))
+	}
+}
+
+func askBackend(ctx context.Context, backend Backend, messages []a.Message, conversationOptions []a.ModelOption, conversations ...a.Conversation) (a.Message, *a.ModelOptions, error) {
+	useOpts, err := a.CreateConversationOptions(backend.Model, nil, append(append([]a.ModelOption{}, conversationOptions...), backend.Options...)...)
+	if err != nil {
+		return a.Message{}, nil, fmt.Errorf("failed to create conversation options: %w", err)
+	}
+	a.OverrideSystemPrompt(useOpts, conversations...)
+	loaded, err := a.LoadHistory(ctx, useOpts, messages)
+	if err != nil {
+		return a.Message{}, nil, err
+	}
+	useOpts.Messages, err = a.CompactMessages(ctx, useOpts, append(loaded, messages...))
+	if err != nil {
+		return a.Message{}, nil, err
+	}
+
+	if backend.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, backend.Timeout)
+		defer cancel()
+	}
+	answer, err := ChatCompletion(ctx, backend.Client.Chat, useOpts)
+	return answer, useOpts, err
+}
@@ -0,0 +1,108 @@
+package openai_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go/v3/option"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	o "github.com/morphy76/ggraph/pkg/agent/openai"
+	b "github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func runFailoverNode(t *testing.T, node g.Node[a.Conversation]) g.StateMonitorEntry[a.Conversation] {
+	t.Helper()
+	stateMonitorCh := make(chan g.StateMonitorEntry[a.Conversation], 10)
+	runtime, err := b.CreateRuntime(b.CreateStartEdge(node), stateMonitorCh)
+	if err != nil {
+		t.Fatalf("CreateRuntime failed: %v", err)
+	}
+	runtime.AddEdge(b.CreateEndEdge(node))
+	defer runtime.Shutdown()
+
+	runtime.Invoke(a.CreateConversation(a.CreateMessage(a.User, "hi")))
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case entry := <-stateMonitorCh:
+			if !entry.Running {
+				return entry
+			}
+		case <-timeout:
+			t.Fatal("Timeout waiting for the graph completion")
+		}
+	}
+}
+
+func TestCreateFailoverConversationNode(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(2 * time.Second):
+		}
+	}))
+	defer slow.Close()
+	healthy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(chatResponse("from the fallback")))
+	}))
+	defer healthy.Close()
+
+	noRetries := option.WithMaxRetries(0)
+	node, err := o.CreateFailoverConversationNode("Chat", []o.Backend{
+		{Name: "primary", Client: o.NewClient(failing.URL, "key", noRetries), Model: "gpt"},
+		{Name: "slow", Client: o.NewClient(slow.URL, "key", noRetries), Model: "gpt", Timeout: 50 * time.Millisecond},
+		{Name: "fallback", Client: o.NewClient(healthy.URL, "key", noRetries), Model: "gpt"},
+	})
+	if err != nil {
+		t.Fatalf("CreateFailoverConversationNode failed: %v", err)
+	}
+
+	entry := runFailoverNode(t, node)
+	if entry.Error != nil {
+		t.Fatalf("Expected the fallback to answer, got %v", entry.Error)
+	}
+	messages := entry.NewState.Messages
+	if len(messages) != 2 || messages[1].Content != "from the fallback" {
+		t.Errorf("Unexpected conversation %+v", messages)
+	}
+	if backend := entry.NewState.Metadata[o.BackendMetadataKey]; backend != "fallback" {
+		t.Errorf("Expected the fallback backend to be recorded, got %q", backend)
+	}
+}
+
+func TestCreateFailoverConversationNode_AllFailed(t *testing.T) {
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer failing.Close()
+
+	client := o.NewClient(failing.URL, "key", option.WithMaxRetries(0))
+	node, _ := o.CreateFailoverConversationNode("Chat", []o.Backend{
+		{Name: "first", Client: client, Model: "gpt"},
+		{Name: "second", Client: client, Model: "gpt"},
+	})
+
+	entry := runFailoverNode(t, node)
+	if !errors.Is(entry.Error, o.ErrAllBackendsFailed) {
+		t.Errorf("Expected ErrAllBackendsFailed, got %v", entry.Error)
+	}
+}
+
+func TestCreateFailoverConversationNode_Invalid(t *testing.T) {
+	if _, err := o.CreateFailoverConversationNode("Chat", nil); !errors.Is(err, o.ErrNoBackends) {
+		t.Errorf("Expected ErrNoBackends, got %v", err)
+	}
+	if _, err := o.CreateFailoverConversationNode("Chat", []o.Backend{{Name: "x", Model: "gpt"}}); !errors.Is(err, o.ErrInvalidBackend) {
+		t.Errorf("Expected ErrInvalidBackend, got %v", err)
+	}
+}
@@ -1,6 +1,8 @@
 package openai
 
 import (
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"strings"
 
@@ -8,6 +10,7 @@ import (
 
 	a "github.com/morphy76/ggraph/pkg/agent"
 	t "github.com/morphy76/ggraph/pkg/agent/tool"
+	g "github.com/morphy76/ggraph/pkg/graph"
 )
 
 // ConvertCompletionOptions converts internal CompletionOptions to OpenAI CompletionNewParams.
@@ -83,11 +86,24 @@ func ConvertConversationOptions(modelOptions *a.ModelOptions) openai.ChatComplet
 		switch msg.Role {
 		case a.System:
 			union = openai.SystemMessage(msg.Content)
+			if msg.Name != "" {
+				union.OfSystem.Name = openai.String(msg.Name)
+			}
 		case a.User:
-			union = openai.UserMessage(msg.Content)
+			if len(msg.Parts) > 0 {
+				union = openai.UserMessage(ConvertContentParts(msg.Parts))
+			} else {
+				union = openai.UserMessage(msg.Content)
+			}
+			if msg.Name != "" {
+				union.OfUser.Name = openai.String(msg.Name)
+			}
 		case a.Assistant:
 			union = openai.AssistantMessage(msg.Content)
 			useUnion := union.OfAssistant
+			if msg.Name != "" {
+				useUnion.Name = openai.String(msg.Name)
+			}
 			if len(msg.ToolCalls) > 0 {
 				useUnion.ToolCalls = make([]openai.ChatCompletionMessageToolCallUnionParam, len(msg.ToolCalls))
 				for i, tc := range msg.ToolCalls {
@@ -161,48 +177,97 @@ func ConvertConversationOptions(modelOptions *a.ModelOptions) openai.ChatComplet
 	if modelOptions.Seed != nil {
 		rv.Seed = openai.Int(*modelOptions.Seed)
 	}
+	if modelOptions.ResponseFormat != nil {
+		rv.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
+			OfJSONSchema: &openai.ResponseFormatJSONSchemaParam{
+				JSONSchema: openai.ResponseFormatJSONSchemaJSONSchemaParam{
+					Name:   modelOptions.ResponseFormat.Name,
+					Schema: modelOptions.ResponseFormat.Schema,
+					Strict: openai.Bool(modelOptions.ResponseFormat.Strict),
+				},
+			},
+		}
+	}
 
 	return rv
 }
 
 func tool2Fn(tool *t.Tool) *openai.ChatCompletionFunctionToolParam {
-	toolProps := make(map[string]interface{})
-	for _, arg := range tool.Args {
-		useType := convertToSupportedJSONType(arg.Type)
-		toolProps[arg.Name] = map[string]interface{}{
-			"type": useType,
-		}
-	}
-
 	return &openai.ChatCompletionFunctionToolParam{
 		Function: openai.FunctionDefinitionParam{
 			Name:        tool.Name,
 			Description: openai.String(tool.BuildToolPrompt()),
-			Parameters: openai.FunctionParameters{
-				"type":       "object",
-				"properties": toolProps,
-				"required":   tool.RequiredArgs(),
-			},
+			Parameters:  openai.FunctionParameters(tool.Parameters()),
 		},
 	}
 }
 
-func convertToSupportedJSONType(argType string) string {
-	switch argType {
-	case "string":
-		return "string"
-	case "int", "int8", "int16", "int32", "int64",
-		"uint", "uint8", "uint16", "uint32", "uint64":
-		return "integer"
-	case "float32", "float64":
-		return "number"
-	case "bool":
-		return "boolean"
-	case "[]string", "[]int", "[]float64", "[]bool":
-		return "array"
-	case "map[string]interface{}":
-		return "object"
+// ConvertUsage converts the OpenAI usage of a completion to the graph usage.
+//
+// Parameters:
+//   - usage: The OpenAI usage.
+//
+// Returns:
+//   - The graph usage.
+func ConvertUsage(usage openai.CompletionUsage) g.Usage {
+	return g.Usage{
+		PromptTokens:     usage.PromptTokens,
+		CompletionTokens: usage.CompletionTokens,
+		TotalTokens:      usage.TotalTokens,
+	}
+}
+
+// ConvertContentParts converts the parts of a multi-modal message to OpenAI content parts.
+//
+// The images are sent by URL, the inline ones as base64 data URLs; the audio clips are sent
+// as input audio, in the wav or mp3 format derived from their MIME type. OpenAI accepts the
+// content parts in the user messages only: the other roles are sent with their text Content.
+//
+// Parameters:
+//   - parts: The content parts.
+//
+// Returns:
+//   - The OpenAI content parts.
+//
+// Example usage:
+//
+//	parts := ConvertContentParts([]a.ContentPart{a.Text("Describe it"), a.ImageData(png, "image/png")})
+func ConvertContentParts(parts []a.ContentPart) []openai.ChatCompletionContentPartUnionParam {
+	rv := make([]openai.ChatCompletionContentPartUnionParam, 0, len(parts))
+	for _, part := range parts {
+		switch part.Kind {
+		case a.TextPart:
+			rv = append(rv, openai.TextContentPart(part.Text))
+		case a.ImagePart:
+			rv = append(rv, openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{
+				URL:    part.DataURL(),
+				Detail: string(part.Detail),
+			}))
+		case a.AudioPart:
+			rv = append(rv, openai.InputAudioContentPart(openai.ChatCompletionContentPartInputAudioInputAudioParam{
+				Data:   base64.StdEncoding.EncodeToString(part.Data),
+				Format: audioFormat(part.MIMEType),
+			}))
+		}
+	}
+	return rv
+}
+
+// audioFormat maps the MIME type of an audio clip to the OpenAI input audio format.
+func audioFormat(mimeType string) string {
+	switch strings.ToLower(mimeType) {
+	case "audio/mpeg", "audio/mp3":
+		return "mp3"
+	case "audio/wav", "audio/x-wav", "audio/wave", "audio/vnd.wave":
+		return "wav"
 	default:
-		return "string"
+		_, subtype, _ := strings.Cut(mimeType, "/")
+		return subtype
+	}
+}
+
+func reportCompletionUsage(ctx context.Context, resp *openai.ChatCompletion) {
+	if resp != nil {
+		g.ReportUsage(ctx, ConvertUsage(resp.Usage))
 	}
 }
@@ -549,3 +549,71 @@ func verifyArgType(t *testing.T, props map[string]interface{}, argName, expected
 		t.Errorf("For arg %s: expected type %s, got %s", argName, expectedType, actualType)
 	}
 }
+
+func TestConvertConversationOptions_MultiModal(t *testing.T) {
+	opts := &a.ModelOptions{
+		Model: "gpt-4o",
+		Messages: []a.Message{
+			a.CreateMultiModalMessage(a.System, a.Text("Describe the inputs.")),
+			a.CreateMultiModalMessage(a.User,
+				a.Text("What is this?"),
+				a.ImageURL("https://example.com/cat.png", a.ImageDetailHigh),
+				a.ImageData([]byte("png"), "image/png"),
+				a.Audio([]byte("mp3"), "audio/mpeg"),
+			),
+		},
+	}
+
+	result := ggraphopenai.ConvertConversationOptions(opts)
+
+	if result.Messages[0].OfSystem == nil || result.Messages[0].OfSystem.Content.OfString.Value != "Describe the inputs." {
+		t.Errorf("Expected the system message to be sent as text, got %+v", result.Messages[0])
+	}
+	user := result.Messages[1].OfUser
+	if user == nil {
+		t.Fatalf("Expected a user message, got %+v", result.Messages[1])
+	}
+	parts := user.Content.OfArrayOfContentParts
+	if len(parts) != 4 {
+		t.Fatalf("Expected 4 content parts, got %d", len(parts))
+	}
+	if parts[0].OfText == nil || parts[0].OfText.Text != "What is this?" {
+		t.Errorf("Unexpected text part %+v", parts[0])
+	}
+	if parts[1].OfImageURL == nil || parts[1].OfImageURL.ImageURL.URL != "https://example.com/cat.png" || parts[1].OfImageURL.ImageURL.Detail != "high" {
+		t.Errorf("Unexpected image URL part %+v", parts[1])
+	}
+	if parts[2].OfImageURL == nil || parts[2].OfImageURL.ImageURL.URL != "data:image/png;base64,cG5n" {
+		t.Errorf("Unexpected inline image part %+v", parts[2])
+	}
+	if parts[3].OfInputAudio == nil || parts[3].OfInputAudio.InputAudio.Format != "mp3" || parts[3].OfInputAudio.InputAudio.Data != "bXAz" {
+		t.Errorf("Unexpected audio part %+v", parts[3])
+	}
+}
+
+func TestConvertConversationOptions_Names(t *testing.T) {
+	opts := &a.ModelOptions{
+		Model: "gpt-4o",
+		Messages: []a.Message{
+			{Role: a.System, Name: "policy", Content: "Be brief."},
+			{Role: a.User, Name: "alice", Content: "Hi"},
+			{Role: a.Assistant, Name: "planner", Content: "Hello"},
+			{Role: a.User, Content: "Bye"},
+		},
+	}
+
+	result := ggraphopenai.ConvertConversationOptions(opts)
+
+	if name := result.Messages[0].OfSystem.Name; name.Value != "policy" {
+		t.Errorf("Expected the system message name, got %+v", name)
+	}
+	if name := result.Messages[1].OfUser.Name; name.Value != "alice" {
+		t.Errorf("Expected the user message name, got %+v", name)
+	}
+	if name := result.Messages[2].OfAssistant.Name; name.Value != "planner" {
+		t.Errorf("Expected the assistant message name, got %+v", name)
+	}
+	if result.Messages[3].OfUser.Name.Valid() {
+		t.Errorf("Expected no name for an anonymous message, got %+v", result.Messages[3].OfUser.Name)
+	}
+}
@@ -0,0 +1,207 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/openai/openai-go/v3"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	t "github.com/morphy76/ggraph/pkg/agent/tool"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// StreamDeltaFn receives the assistant message assembled so far while a completion is streamed.
+//
+// The message carries the content received so far; the tool calls are added only once the
+// stream ends, when their arguments are complete.
+type StreamDeltaFn func(partial a.Message)
+
+// ChatCompletionStream streams the conversation to the model and returns the assistant message.
+//
+// The content deltas are accumulated and handed to onDelta as they arrive; the tool-call
+// fragments are assembled by their index and parsed when the stream ends. The response cache of
+// the options is consulted first, a cache hit being handed to onDelta at once; the usage of the
+// request is reported to the invocation. The transient failures are retried with the policy of
+// the options, see a.WithRetry, the retries of the client being disabled for the request: a
+// retried stream notifies its content from the start.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - chatService: The OpenAI ChatService client.
+//   - modelOptions: The conversation options, with the messages to send.
+//   - onDelta: The function receiving the partial message, can be nil.
+//
+// Returns:
+//   - The assistant message, with a new identifier and the requested tool calls if any.
+//   - An error if the request or the stream fails, the model returns no choice or a tool call cannot be parsed.
+//
+// Example usage:
+//
+//	answer, err := ChatCompletionStream(ctx, client.Chat, opts, func(partial a.Message) {
+//	    fmt.Print("\r", partial.Content)
+//	})
+func ChatCompletionStream(ctx context.Context, chatService openai.ChatService, modelOptions *a.ModelOptions, onDelta StreamDeltaFn) (a.Message, error) {
+	if onDelta == nil {
+		onDelta = func(a.Message) {}
+	}
+
+	rv, hit, err := a.CachedCall(ctx, CacheKindChat, modelOptions, func(ctx context.Context) (a.Message, error) {
+		return a.RetryCall(ctx, modelOptions, func(ctx context.Context) (a.Message, error) {
+			return streamCompletion(ctx, chatService, modelOptions, onDelta)
+		})
+	})
+	if err != nil {
+		return a.Message{}, err
+	}
+	rv.ID = a.NewMessageID()
+	rv.Ts = time.Now()
+	if hit {
+		onDelta(rv.Clone())
+	}
+	return rv, nil
+}
+
+// streamCompletion makes one streamed request.
+func streamCompletion(ctx context.Context, chatService openai.ChatService, modelOptions *a.ModelOptions, onDelta StreamDeltaFn) (a.Message, error) {
+	params := ConvertConversationOptions(modelOptions)
+	params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: openai.Bool(true)}
+
+	stream := chatService.Completions.NewStreaming(ctx, params, retryOptions(modelOptions)...)
+	defer stream.Close()
+
+	var content strings.Builder
+	calls := &toolCallAssembler{}
+	choices := false
+	var createdAt time.Time
+	for stream.Next() {
+		chunk := stream.Current()
+		if createdAt.IsZero() && chunk.Created != 0 {
+			createdAt = time.Unix(chunk.Created, 0)
+		}
+		if chunk.JSON.Usage.Valid() {
+			g.ReportUsage(ctx, ConvertUsage(chunk.Usage))
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+		choices = true
+
+		delta := chunk.Choices[0].Delta
+		for _, fragment := range delta.ToolCalls {
+			calls.add(fragment)
+		}
+		if delta.Content != "" {
+			content.WriteString(delta.Content)
+			onDelta(a.Message{Ts: time.Now(), CreatedAt: createdAt, Role: a.Assistant, Content: content.String()})
+		}
+	}
+	if err := stream.Err(); err != nil {
+		return a.Message{}, classifyError(ctx, err)
+	}
+	if !choices {
+		return a.Message{}, ErrNoChoices
+	}
+
+	toolCalls, err := calls.finalize()
+	if err != nil {
+		return a.Message{}, fmt.Errorf("failed to convert tool call: %w", err)
+	}
+	return a.Message{Ts: time.Now(), CreatedAt: createdAt, Role: a.Assistant, Content: content.String(), ToolCalls: toolCalls}, nil
+}
+
+// StreamingConversationNodeFn creates a ConversationNodeFn answering with a streamed completion.
+//
+// The conversation, followed by the user input, is sent to the model; the partial answers are
+// notified as the tokens arrive, so that the monitor receives the growing assistant message;
+// with g.WithStreamingView(a.StreamingViewReducer) the runtime CurrentState reflects it too.
+// The final state carries the assistant message and its tool calls in CurrentToolCalls, to be
+// routed with a.ToolProcessorRoutingFn.
+//
+// Returns:
+//   - A ConversationNodeFn streaming the model answer.
+//
+// Example usage:
+//
+//	node, err := CreateConversationNode("Chat", "gpt-4o-mini", client, StreamingConversationNodeFn(), a.WithTools(tool1))
+func StreamingConversationNodeFn() ConversationNodeFn {
+	return func(chatService openai.ChatService, model string, modelOptions ...a.ModelOption) g.NodeFn[a.Conversation] {
+		return func(userInput, currentState a.Conversation, notify g.NotifyPartialFn[a.Conversation]) (a.Conversation, error) {
+			useOpts, err := a.CreateConversationOptions(model, nil, modelOptions...)
+			if err != nil {
+				return currentState, fmt.Errorf("failed to create conversation options: %w", err)
+			}
+			a.OverrideSystemPrompt(useOpts, userInput, currentState)
+
+			messages := a.PromptMessages(userInput, currentState)
+			useOpts.Messages, err = a.CompactMessages(context.Background(), useOpts, messages)
+			if err != nil {
+				return currentState, fmt.Errorf("streaming request failed: %w", err)
+			}
+
+			answer, err := ChatCompletionStream(context.Background(), chatService, useOpts, func(partial a.Message) {
+				notify(a.Conversation{Messages: append(slices.Clip(messages), partial)})
+			})
+			if err != nil {
+				return currentState, fmt.Errorf("streaming request failed: %w", err)
+			}
+
+			return a.Conversation{
+				Messages:         append(messages, answer),
+				CurrentToolCalls: answer.ToolCalls,
+			}, nil
+		}
+	}
+}
+
+// toolCallAssembler assembles the tool calls streamed as fragments sharing the same index.
+type toolCallAssembler struct {
+	order []int64
+	calls map[int64]*streamedToolCall
+}
+
+type streamedToolCall struct {
+	id        string
+	name      string
+	arguments strings.Builder
+}
+
+// add merges the fragment: the first fragment of a call carries its ID and name, all of them a slice of the arguments.
+func (s *toolCallAssembler) add(fragment openai.ChatCompletionChunkChoiceDeltaToolCall) {
+	if s.calls == nil {
+		s.calls = make(map[int64]*streamedToolCall)
+	}
+	call, ok := s.calls[fragment.Index]
+	if !ok {
+		call = &streamedToolCall{}
+		s.calls[fragment.Index] = call
+		s.order = append(s.order, fragment.Index)
+	}
+	if fragment.ID != "" {
+		call.id = fragment.ID
+	}
+	if fragment.Function.Name != "" {
+		call.name = fragment.Function.Name
+	}
+	call.arguments.WriteString(fragment.Function.Arguments)
+}
+
+// finalize parses the arguments of the assembled calls, in the order they were started.
+func (s *toolCallAssembler) finalize() ([]t.FnCall, error) {
+	var rv []t.FnCall
+	for _, index := range s.order {
+		call := s.calls[index]
+		arguments := make(map[string]any)
+		if raw := call.arguments.String(); strings.TrimSpace(raw) != "" {
+			if err := json.Unmarshal([]byte(raw), &arguments); err != nil {
+				return nil, fmt.Errorf("%w: tool call %s to %s: %w", ErrArgumentParse, call.id, call.name, err)
+			}
+		}
+		rv = append(rv, t.FnCall{ID: call.id, ToolName: call.name, Arguments: arguments})
+	}
+	return rv, nil
+}
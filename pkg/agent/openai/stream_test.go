@@ -0,0 +1,117 @@
+package openai_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	o "github.com/morphy76/ggraph/pkg/agent/openai"
+)
+
+func streamChunk(delta string) string {
+	return fmt.Sprintf(`{"id":"1","object":"chat.completion.chunk","created":0,"model":"gpt","choices":[{"index":0,"delta":%s}]}`, delta)
+}
+
+func streamServer(chunks ...string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, chunk := range chunks {
+			fmt.Fprintf(w, "data: %s\n\n", chunk)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+}
+
+func TestChatCompletionStream_ToolCalls(t *testing.T) {
+	server := streamServer(
+		streamChunk(`{"role":"assistant","content":"Let me "}`),
+		streamChunk(`{"content":"compute."}`),
+		streamChunk(`{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"additionTool","arguments":"{\"addend1\":"}}]}`),
+		streamChunk(`{"tool_calls":[{"index":1,"id":"call_2","type":"function","function":{"name":"additionTool","arguments":""}}]}`),
+		streamChunk(`{"tool_calls":[{"index":0,"function":{"arguments":"4,\"addend2\":5}"}}]}`),
+		streamChunk(`{"tool_calls":[{"index":1,"function":{"arguments":"{\"addend1\":1,\"addend2\":2}"}}]}`),
+		`{"id":"1","object":"chat.completion.chunk","created":0,"model":"gpt","choices":[],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`,
+	)
+	defer server.Close()
+
+	opts, _ := a.CreateConversationOptions("gpt", []a.Message{a.CreateMessage(a.User, "4+5? 1+2?")})
+	client := o.NewClient(server.URL, "key")
+
+	var partials []string
+	answer, err := o.ChatCompletionStream(context.Background(), client.Chat, opts, func(partial a.Message) {
+		if len(partial.ToolCalls) != 0 {
+			t.Errorf("Expected no tool calls in the partial messages, got %+v", partial.ToolCalls)
+		}
+		partials = append(partials, partial.Content)
+	})
+	if err != nil {
+		t.Fatalf("ChatCompletionStream failed: %v", err)
+	}
+
+	if strings.Join(partials, "|") != "Let me |Let me compute." {
+		t.Errorf("Unexpected partial contents %q", partials)
+	}
+	if answer.Role != a.Assistant || answer.Content != "Let me compute." {
+		t.Errorf("Unexpected answer %+v", answer)
+	}
+	if len(answer.ToolCalls) != 2 {
+		t.Fatalf("Expected two tool calls, got %+v", answer.ToolCalls)
+	}
+	first, second := answer.ToolCalls[0], answer.ToolCalls[1]
+	if first.ID != "call_1" || first.ToolName != "additionTool" || first.Arguments["addend1"] != float64(4) || first.Arguments["addend2"] != float64(5) {
+		t.Errorf("Unexpected first tool call %+v", first)
+	}
+	if second.ID != "call_2" || second.Arguments["addend1"] != float64(1) || second.Arguments["addend2"] != float64(2) {
+		t.Errorf("Unexpected second tool call %+v", second)
+	}
+}
+
+func TestChatCompletionStream_InvalidArguments(t *testing.T) {
+	server := streamServer(
+		streamChunk(`{"tool_calls":[{"index":0,"id":"call_1","type":"function","function":{"name":"additionTool","arguments":"{\"addend1\":"}}]}`),
+	)
+	defer server.Close()
+
+	opts, _ := a.CreateConversationOptions("gpt", []a.Message{a.CreateMessage(a.User, "4+5?")})
+	client := o.NewClient(server.URL, "key")
+
+	_, err := o.ChatCompletionStream(context.Background(), client.Chat, opts, nil)
+	if !errors.Is(err, o.ErrArgumentParse) {
+		t.Errorf("Expected ErrArgumentParse for the truncated arguments, got %v", err)
+	}
+}
+
+func TestStreamingConversationNodeFn(t *testing.T) {
+	server := streamServer(
+		streamChunk(`{"role":"assistant","content":"The result"}`),
+		streamChunk(`{"content":" is 9"}`),
+	)
+	defer server.Close()
+
+	client := o.NewClient(server.URL, "key")
+	fn := o.StreamingConversationNodeFn()(client.Chat, "gpt")
+
+	var partials []a.Conversation
+	history := a.CreateConversation(a.CreateMessage(a.System, "Be brief."))
+	got, err := fn(a.CreateConversation(a.CreateMessage(a.User, "4+5?")), history, func(partial a.Conversation) {
+		partials = append(partials, partial)
+	})
+	if err != nil {
+		t.Fatalf("Node function failed: %v", err)
+	}
+
+	if len(partials) != 2 {
+		t.Fatalf("Expected a partial state per content delta, got %d", len(partials))
+	}
+	if last := partials[0].Messages[len(partials[0].Messages)-1]; last.Content != "The result" {
+		t.Errorf("Unexpected first partial message %+v", last)
+	}
+	if len(got.Messages) != 3 || got.Messages[2].Content != "The result is 9" || len(got.CurrentToolCalls) != 0 {
+		t.Errorf("Unexpected final conversation %+v", got)
+	}
+}
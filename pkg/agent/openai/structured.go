@@ -0,0 +1,117 @@
+package openai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/openai/openai-go/v3"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// NewStructuredConversation sends the conversation to the model and decodes the answer into T.
+//
+// The model options must carry a response format, see a.WithResponseFormat. When the answer
+// does not match the format, the invalid answer and a repair prompt are appended to the
+// conversation and the model is asked again, up to the configured number of repair attempts.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - chatService: The OpenAI ChatService client.
+//   - modelOptions: The conversation options, including the response format.
+//
+// Returns:
+//   - The decoded value.
+//   - The last assistant message.
+//   - An error if the request fails or no valid answer is produced within the repair attempts.
+//
+// Example usage:
+//
+//	opts, _ := a.CreateConversationOptions(model, messages, a.WithResponseFormat[Evaluation]())
+//	eval, msg, err := NewStructuredConversation[Evaluation](ctx, chatService, opts)
+func NewStructuredConversation[T any](
+	ctx context.Context,
+	chatService openai.ChatService,
+	modelOptions *a.ModelOptions,
+) (T, a.Message, error) {
+	var zero T
+	if modelOptions.ResponseFormat == nil {
+		return zero, a.Message{}, a.ErrResponseFormatNotSet
+	}
+
+	messages, err := a.CompactMessages(ctx, modelOptions, modelOptions.Messages)
+	if err != nil {
+		return zero, a.Message{}, fmt.Errorf("structured conversation request failed: %w", err)
+	}
+
+	useOptions := *modelOptions
+	useOptions.Messages = messages
+
+	message, err := ChatCompletion(ctx, chatService, &useOptions)
+	switch {
+	case errors.Is(err, ErrNoChoices):
+		return zero, a.Message{}, fmt.Errorf("%w: %w", a.ErrInvalidStructuredResponse, err)
+	case errors.Is(err, a.ErrInvalidStructuredResponse):
+		return zero, a.Message{}, err
+	case err != nil:
+		return zero, a.Message{}, fmt.Errorf("structured conversation request failed: %w", err)
+	}
+
+	rv, err := a.DecodeResponse[T](modelOptions.ResponseFormat, message.Content)
+	if err != nil {
+		return zero, a.Message{}, err
+	}
+	return rv, message, nil
+}
+
+// structuredAnswer validates the answer to the messages with the response format of the options: an invalid answer
+// and a repair prompt are appended to the messages and the model is asked again, up to the repair attempts of the
+// format.
+func structuredAnswer(
+	ctx context.Context,
+	chatService openai.ChatService,
+	modelOptions *a.ModelOptions,
+	messages []a.Message,
+	answer a.Message,
+) (a.Message, error) {
+	format := modelOptions.ResponseFormat
+	useOptions := *modelOptions
+	useOptions.Messages = slices.Clip(messages)
+
+	for attempt := 0; ; attempt++ {
+		_, err := format.Decode(answer.Content)
+		if err == nil {
+			return answer, nil
+		}
+		if attempt == format.MaxRepairAttempts {
+			return a.Message{}, fmt.Errorf("no valid answer after %d repair attempts: %w", format.MaxRepairAttempts, err)
+		}
+
+		useOptions.Messages = append(useOptions.Messages, answer, a.CreateMessage(a.User, format.RepairPrompt(err)))
+		if answer, err = chatCompletion(ctx, chatService, &useOptions); err != nil {
+			return a.Message{}, err
+		}
+	}
+}
+
+// structuredNodeFn validates the final answer of the node function with the response format of the options, an
+// invalid answer being repaired with the conversation of the returned state, see structuredAnswer.
+func structuredNodeFn(chatService openai.ChatService, modelOptions *a.ModelOptions, fn g.NodeFn[a.Conversation]) g.NodeFn[a.Conversation] {
+	return func(userInput, currentState a.Conversation, notify g.NotifyPartialFn[a.Conversation]) (a.Conversation, error) {
+		rv, err := fn(userInput, currentState, notify)
+		last := len(rv.Messages) - 1
+		if err != nil || last < 0 || rv.Messages[last].Role != a.Assistant || len(rv.Messages[last].ToolCalls) > 0 {
+			return rv, err
+		}
+
+		answer, err := structuredAnswer(context.Background(), chatService, modelOptions, rv.Messages[:last], rv.Messages[last])
+		if err != nil {
+			return currentState, fmt.Errorf("structured conversation request failed: %w", err)
+		}
+		rv.Messages = append(slices.Clip(rv.Messages[:last]), answer)
+		return rv, nil
+	}
+}
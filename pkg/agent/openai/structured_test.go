@@ -0,0 +1,146 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/openai/openai-go/v3"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	ggraphopenai "github.com/morphy76/ggraph/pkg/agent/openai"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+type evaluation struct {
+	Score  int    `json:"score"`
+	Reason string `json:"reason"`
+}
+
+func chatResponse(content string) string {
+	escaped, _ := json.Marshal(content)
+	return fmt.Sprintf(`{"id":"1","object":"chat.completion","created":0,"model":"gpt","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":%s}}],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`, escaped)
+}
+
+func TestNewStructuredConversation_Repair(t *testing.T) {
+	answers := []string{`not json`, `{"score":8,"reason":"fine"}`}
+	var requests []map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req map[string]any
+		json.Unmarshal(body, &req)
+		requests = append(requests, req)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(chatResponse(answers[len(requests)-1])))
+	}))
+	defer server.Close()
+
+	client := ggraphopenai.NewClient(server.URL, "key")
+	opts, err := a.CreateConversationOptions("gpt", []a.Message{a.CreateMessage(a.User, "evaluate")}, a.WithResponseFormat[evaluation]())
+	if err != nil {
+		t.Fatalf("CreateConversationOptions() failed: %v", err)
+	}
+
+	got, msg, err := ggraphopenai.NewStructuredConversation[evaluation](context.Background(), client.Chat, opts)
+	if err != nil {
+		t.Fatalf("NewStructuredConversation() failed: %v", err)
+	}
+	if got != (evaluation{Score: 8, Reason: "fine"}) {
+		t.Errorf("Unexpected result %+v", got)
+	}
+	if msg.Role != a.Assistant {
+		t.Errorf("Expected assistant message, got %v", msg.Role)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("Expected 2 requests, got %d", len(requests))
+	}
+
+	format, _ := requests[0]["response_format"].(map[string]any)
+	if format["type"] != "json_schema" {
+		t.Errorf("Expected json_schema response format, got %v", requests[0]["response_format"])
+	}
+	if messages, _ := requests[1]["messages"].([]any); len(messages) != 3 {
+		t.Errorf("Expected the repair request to carry 3 messages, got %d", len(messages))
+	}
+	if len(opts.Messages) != 1 {
+		t.Errorf("Expected the original options to be untouched, got %d messages", len(opts.Messages))
+	}
+}
+
+func TestNewStructuredConversation_Exhausted(t *testing.T) {
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(chatResponse(`{"score":"high"}`)))
+	}))
+	defer server.Close()
+
+	client := ggraphopenai.NewClient(server.URL, "key")
+	opts, err := a.CreateConversationOptions("gpt", []a.Message{a.CreateMessage(a.User, "evaluate")},
+		a.WithResponseFormat[evaluation](), a.WithResponseFormatRepairAttempts(1))
+	if err != nil {
+		t.Fatalf("CreateConversationOptions() failed: %v", err)
+	}
+
+	_, _, err = ggraphopenai.NewStructuredConversation[evaluation](context.Background(), client.Chat, opts)
+	if !errors.Is(err, a.ErrInvalidStructuredResponse) {
+		t.Errorf("Expected ErrInvalidStructuredResponse, got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+}
+
+func TestNewStructuredConversation_NoFormat(t *testing.T) {
+	client := ggraphopenai.NewClient("http://localhost", "key")
+	_, _, err := ggraphopenai.NewStructuredConversation[evaluation](context.Background(), client.Chat, &a.ModelOptions{Model: "gpt"})
+	if !errors.Is(err, a.ErrResponseFormatNotSet) {
+		t.Errorf("Expected ErrResponseFormatNotSet, got %v", err)
+	}
+}
+
+func TestCreateConversationNode_ResponseFormat(t *testing.T) {
+	answers := []string{`{"score":"high"}`, `{"score":8,"reason":"fine"}`}
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(chatResponse(answers[calls-1])))
+	}))
+	defer server.Close()
+
+	// The node function asks the client directly, its answer is validated by the node
+	rawNodeFn := func(chatService openai.ChatService, model string, modelOptions ...a.ModelOption) g.NodeFn[a.Conversation] {
+		return func(userInput, currentState a.Conversation, notify g.NotifyPartialFn[a.Conversation]) (a.Conversation, error) {
+			opts, _ := a.CreateConversationOptions(model, userInput.Messages, modelOptions...)
+			resp, err := chatService.Completions.New(context.Background(), ggraphopenai.ConvertConversationOptions(opts))
+			if err != nil {
+				return currentState, err
+			}
+			return a.Conversation{Messages: append(userInput.Messages, a.CreateMessage(a.Assistant, resp.Choices[0].Message.Content))}, nil
+		}
+	}
+	node, err := ggraphopenai.CreateConversationNode("Evaluator", "gpt", ggraphopenai.NewClient(server.URL, "key"), rawNodeFn, a.WithResponseFormat[evaluation]())
+	if err != nil {
+		t.Fatalf("CreateConversationNode() failed: %v", err)
+	}
+
+	entry := runFailoverNode(t, node)
+	if entry.Error != nil {
+		t.Fatalf("Unexpected error: %v", entry.Error)
+	}
+	messages := entry.NewState.Messages
+	if len(messages) != 2 || messages[1].Content != answers[1] {
+		t.Errorf("Expected the invalid answer to be replaced by the repaired one, got %+v", messages)
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 calls, got %d", calls)
+	}
+}
@@ -0,0 +1,60 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/openai/openai-go/v3"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+)
+
+// DefaultSummaryPrompt is the system prompt instructing the model to summarize the older turns.
+const DefaultSummaryPrompt = "Summarize the following conversation in a few sentences. " +
+	"Keep the facts, decisions and open questions needed to continue it, omit greetings and chit-chat."
+
+var roleNames = map[a.MessageRole]string{
+	a.System:    "system",
+	a.User:      "user",
+	a.Assistant: "assistant",
+	a.Tool:      "tool",
+}
+
+// NewSummarizer creates a Summarizer asking an OpenAI chat model to condense the older turns of a conversation.
+//
+// Parameters:
+//   - client: The OpenAI client instance.
+//   - model: The OpenAI model used to summarize, usually a small and cheap one.
+//   - modelOptions: Additional conversation options for the OpenAI API calls.
+//
+// Returns:
+//   - The Summarizer, to be used with a.NewSummaryMemory.
+//
+// Example usage:
+//
+//	memory, err := a.NewSummaryMemory(4, NewSummarizer(client, "gpt-4o-mini"))
+func NewSummarizer(client *openai.Client, model string, modelOptions ...a.ModelOption) a.Summarizer {
+	chatService := client.Chat
+	return func(ctx context.Context, messages []a.Message) (string, error) {
+		var transcript strings.Builder
+		for _, message := range messages {
+			fmt.Fprintf(&transcript, "%s: %s\n", roleNames[message.Role], message.Content)
+		}
+
+		useOpts, err := a.CreateConversationOptions(model, []a.Message{
+			a.CreateMessage(a.System, DefaultSummaryPrompt),
+			a.CreateMessage(a.User, transcript.String()),
+		}, modelOptions...)
+		if err != nil {
+			return "", fmt.Errorf("failed to create conversation options: %w", err)
+		}
+		useOpts.Memory = nil
+
+		answer, err := ChatCompletion(ctx, chatService, useOpts)
+		if err != nil {
+			return "", fmt.Errorf("summary request failed: %w", err)
+		}
+		return answer.Content, nil
+	}
+}
@@ -0,0 +1,65 @@
+package openai_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	o "github.com/morphy76/ggraph/pkg/agent/openai"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func TestNewSummarizer(t *testing.T) {
+	var transcript string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req struct {
+			Messages []struct {
+				Role    string `json:"role"`
+				Content string `json:"content"`
+			} `json:"messages"`
+		}
+		json.Unmarshal(body, &req)
+		transcript = req.Messages[len(req.Messages)-1].Content
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(chatResponse("The user greeted.")))
+	}))
+	defer server.Close()
+
+	client := o.NewClient(server.URL, "key")
+	memory, err := a.NewSummaryMemory(1, o.NewSummarizer(client, "gpt"))
+	if err != nil {
+		t.Fatalf("NewSummaryMemory failed: %v", err)
+	}
+
+	var usage g.Usage
+	ctx := g.ContextWithUsageReporter(context.Background(), func(reported g.Usage) { usage = usage.Add(reported) })
+
+	got, err := memory.Compact(ctx, []a.Message{
+		a.CreateMessage(a.User, "hello"),
+		a.CreateMessage(a.Assistant, "hi"),
+		a.CreateMessage(a.User, "what now?"),
+	})
+	if err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+
+	if usage.TotalTokens != 15 || usage.PromptTokens != 10 {
+		t.Errorf("Expected the usage to be reported, got %+v", usage)
+	}
+	if transcript != "user: hello\nassistant: hi\n" {
+		t.Errorf("Unexpected transcript %q", transcript)
+	}
+	if len(got) != 2 || got[0].Content != a.SummaryPrefix+"The user greeted." || got[1].Content != "what now?" {
+		t.Errorf("Unexpected compaction %+v", got)
+	}
+	if !strings.HasPrefix(got[0].Content, a.SummaryPrefix) || got[0].Role != a.System {
+		t.Errorf("Expected a system summary, got %+v", got[0])
+	}
+}
@@ -1,6 +1,10 @@
 package agent
 
-import "github.com/morphy76/ggraph/pkg/agent/tool"
+import (
+	"time"
+
+	"github.com/morphy76/ggraph/pkg/agent/tool"
+)
 
 // ModelOptions defines the parameters for generating text completions.
 type ModelOptions struct {
@@ -64,6 +68,24 @@ type ModelOptions struct {
 	User *string
 	// Tools available to the agent during the conversation.
 	Tools []*tool.Tool
+	// SystemPrompt is applied to the messages sent to the model, see WithSystemPrompt.
+	SystemPrompt *SystemPrompt
+	// ResponseFormat is the structured output expected from the model.
+	ResponseFormat *ResponseFormat
+	// Memory compacts the messages sent to the model.
+	Memory ConversationMemory
+	// History keeps the messages of the threads apart from the graph state, see WithConversationHistory.
+	History ConversationHistory
+	// ContextBudget bounds the prompt sent to the model.
+	ContextBudget *ContextBudget
+	// Cache stores the responses of the model.
+	Cache ResponseCache
+	// CacheTTL is the time to live of the cached responses, 0 for no expiration.
+	CacheTTL time.Duration
+	// CacheBypass skips the cache lookup, the fresh response still refreshes the cache.
+	CacheBypass bool
+	// Retry retries the requests failing for a transient reason, see WithRetry.
+	Retry *RetryPolicy
 }
 
 // ModelOption defines an interface for applying options to completion requests.
@@ -368,3 +390,31 @@ func WithTools(tools ...*tool.Tool) ModelOption {
 		return nil
 	})
 }
+
+// WithToolsFrom sets the Tools option with the tools of a registry matching the patterns.
+//
+// The tools are selected at each request, so that the tools registered at runtime are discovered.
+//
+// Parameters:
+//   - registry: The tool registry.
+//   - patterns: The patterns of the tools, all the tools when empty, see tool.Registry.Select.
+//
+// Returns:
+//   - A ModelOption that sets the Tools parameter.
+//
+// Example usage:
+//
+//	option := WithToolsFrom(registry, "fs.*", "web.search")
+func WithToolsFrom(registry *tool.Registry, patterns ...string) ModelOption {
+	return ModelOptionFunc(func(r *ModelOptions) error {
+		if registry == nil {
+			return tool.ErrRegistryNil
+		}
+		tools, err := registry.Select(patterns...)
+		if err != nil {
+			return err
+		}
+		r.Tools = tools
+		return nil
+	})
+}
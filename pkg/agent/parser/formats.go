@@ -0,0 +1,207 @@
+package parser
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"unicode"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+)
+
+// JSON creates a parser decoding a JSON document into T.
+//
+// The document is searched in the first code fence, then from the first brace or bracket of the
+// text, so that the surrounding prose is ignored; it is validated against the JSON schema of T
+// and the optional validators, see a.CreateResponseFormat.
+//
+// Parameters:
+//   - validators: Optional functions checking semantic constraints on the decoded value.
+//
+// Returns:
+//   - The Parser.
+//
+// Example:
+//
+//	evaluation := parser.JSON[Evaluation](func(e Evaluation) error {
+//	    if e.Score < 0 || e.Score > 10 {
+//	        return errors.New("score must be between 0 and 10")
+//	    }
+//	    return nil
+//	})
+func JSON[T any](validators ...func(T) error) Parser[T] {
+	format := a.CreateResponseFormat[T](validators...)
+	useSchema, _ := json.Marshal(format.Schema)
+	instructions := fmt.Sprintf("Answer with ONLY a JSON document matching this JSON schema:\n%s", useSchema)
+
+	return Func(func(text string) (T, error) {
+		var zero T
+		document, err := jsonDocument(text)
+		if err != nil {
+			return zero, err
+		}
+		rv, err := a.DecodeResponse[T](format, document)
+		if err != nil {
+			return zero, fmt.Errorf("%w: %w", ErrParse, err)
+		}
+		return rv, nil
+	}, instructions)
+}
+
+// jsonDocument extracts the first JSON value of the text.
+func jsonDocument(text string) (string, error) {
+	useText := fencedBlock(text)
+	start := strings.IndexAny(useText, "{[")
+	if start == -1 {
+		return "", fmt.Errorf("%w: no JSON document found", ErrParse)
+	}
+
+	var raw json.RawMessage
+	if err := json.NewDecoder(strings.NewReader(useText[start:])).Decode(&raw); err != nil {
+		return "", fmt.Errorf("%w: %w", ErrParse, err)
+	}
+	return string(raw), nil
+}
+
+// XML creates a parser decoding an XML document into T with encoding/xml.
+//
+// The document is searched in the first code fence, then from the first tag of the text.
+//
+// Parameters:
+//   - root: The name of the root element, used in the instructions.
+//
+// Returns:
+//   - The Parser.
+//
+// Example:
+//
+//	type Answer struct {
+//	    Title string   `xml:"title"`
+//	    Tags  []string `xml:"tag"`
+//	}
+//	answer := parser.XML[Answer]("answer")
+func XML[T any](root string) Parser[T] {
+	instructions := fmt.Sprintf("Answer with ONLY an XML document whose root element is <%s>.", root)
+
+	return Func(func(text string) (T, error) {
+		var rv T
+		useText := fencedBlock(text)
+		start := strings.Index(useText, "<")
+		if start == -1 {
+			return rv, fmt.Errorf("%w: no XML document found", ErrParse)
+		}
+		if err := xml.NewDecoder(strings.NewReader(useText[start:])).Decode(&rv); err != nil {
+			return rv, fmt.Errorf("%w: %w", ErrParse, err)
+		}
+		return rv, nil
+	}, instructions)
+}
+
+// MarkdownTable creates a parser reading the first markdown table of the text.
+//
+// Every row is returned as a map from the header cells to the row cells.
+//
+// Returns:
+//   - The Parser.
+//
+// Example:
+//
+//	rows, err := parser.MarkdownTable().Parse("| name | age |\n|---|---|\n| Ada | 36 |")
+//	// rows[0]["name"] == "Ada"
+func MarkdownTable() Parser[[]map[string]string] {
+	return Func(func(text string) ([]map[string]string, error) {
+		var lines []string
+		for _, line := range strings.Split(text, "\n") {
+			line = strings.TrimSpace(line)
+			if strings.HasPrefix(line, "|") {
+				lines = append(lines, line)
+			} else if len(lines) > 0 {
+				break
+			}
+		}
+		if len(lines) < 2 || !isSeparatorRow(lines[1]) {
+			return nil, fmt.Errorf("%w: no markdown table found", ErrParse)
+		}
+
+		header := tableCells(lines[0])
+		rv := make([]map[string]string, 0, len(lines)-2)
+		for idx, line := range lines[2:] {
+			cells := tableCells(line)
+			if len(cells) != len(header) {
+				return nil, fmt.Errorf("%w: row %d has %d cells, expected %d", ErrParse, idx+1, len(cells), len(header))
+			}
+			row := make(map[string]string, len(header))
+			for col, name := range header {
+				row[name] = cells[col]
+			}
+			rv = append(rv, row)
+		}
+		return rv, nil
+	}, "Answer with ONLY a markdown table, the first row being the header.")
+}
+
+func tableCells(line string) []string {
+	line = strings.TrimSuffix(strings.TrimPrefix(line, "|"), "|")
+	cells := strings.Split(line, "|")
+	for idx, cell := range cells {
+		cells[idx] = strings.TrimSpace(cell)
+	}
+	return cells
+}
+
+func isSeparatorRow(line string) bool {
+	for _, cell := range tableCells(line) {
+		if strings.Trim(cell, ":-") != "" || !strings.Contains(cell, "-") {
+			return false
+		}
+	}
+	return true
+}
+
+// Enum creates a parser accepting one of the given values, case-insensitively.
+//
+// The text matches a value when, trimmed of quotes and punctuation, it equals the value; a
+// longer text matches when it mentions exactly one of the values as a word.
+//
+// Parameters:
+//   - values: The accepted values, returned as given.
+//
+// Returns:
+//   - The Parser.
+//
+// Example:
+//
+//	sentiment := parser.Enum("positive", "negative", "neutral")
+//	value, err := sentiment.Parse("The sentiment is Positive.") // "positive"
+func Enum(values ...string) Parser[string] {
+	instructions := fmt.Sprintf("Answer with ONLY one of: %s.", strings.Join(values, ", "))
+
+	return Func(func(text string) (string, error) {
+		useText := strings.TrimFunc(text, func(r rune) bool {
+			return unicode.IsSpace(r) || unicode.IsPunct(r)
+		})
+		for _, value := range values {
+			if strings.EqualFold(useText, value) {
+				return value, nil
+			}
+		}
+
+		words := make(map[string]bool)
+		for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-'
+		}) {
+			words[word] = true
+		}
+		var found []string
+		for _, value := range values {
+			if words[strings.ToLower(value)] {
+				found = append(found, value)
+			}
+		}
+		if len(found) != 1 {
+			return "", fmt.Errorf("%w: expected one of %s", ErrParse, strings.Join(values, ", "))
+		}
+		return found[0], nil
+	}, instructions)
+}
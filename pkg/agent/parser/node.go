@@ -0,0 +1,62 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	b "github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+var (
+	// ErrNoAssistantMessage indicates that the state has no assistant message to parse.
+	ErrNoAssistantMessage = errors.New("no assistant message to parse")
+	// ErrAccessorNil indicates that a nil state accessor was given.
+	ErrAccessorNil = errors.New("state accessors cannot be nil")
+)
+
+// NewNode creates a node parsing the last assistant message of the state into a typed field.
+//
+// Parameters:
+//   - name: The unique name for the node.
+//   - parser: The parser of the message.
+//   - messages: The accessor of the messages of the state.
+//   - set: The function writing the parsed value into the state.
+//   - opts: Optional node options.
+//
+// Returns:
+//   - The parser node, failing the invocation when the message cannot be parsed.
+//   - An error if the node could not be created.
+//
+// Example:
+//
+//	node, err := parser.NewNode("Evaluate", parser.JSON[Evaluation](),
+//	    func(s ReviewState) []a.Message { return s.Conversation.Messages },
+//	    func(s ReviewState, e Evaluation) ReviewState { s.Evaluation = &e; return s })
+func NewNode[S g.SharedState, T any](
+	name string,
+	parser Parser[T],
+	messages func(S) []a.Message,
+	set func(S, T) S,
+	opts ...g.NodeOption[S],
+) (g.Node[S], error) {
+	if parser == nil || messages == nil || set == nil {
+		return nil, fmt.Errorf("parser node creation error for name %s: %w", name, ErrAccessorNil)
+	}
+
+	return b.NewNode(name, func(userInput, currentState S, notify g.NotifyPartialFn[S]) (S, error) {
+		history := messages(currentState)
+		for i := len(history) - 1; i >= 0; i-- {
+			if history[i].Role != a.Assistant {
+				continue
+			}
+			value, err := parser.Parse(history[i].Content)
+			if err != nil {
+				return currentState, fmt.Errorf("node %s: %w", name, err)
+			}
+			return set(currentState, value), nil
+		}
+		return currentState, fmt.Errorf("node %s: %w", name, ErrNoAssistantMessage)
+	}, opts...)
+}
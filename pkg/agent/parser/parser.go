@@ -0,0 +1,151 @@
+// Package parser extracts structured values from the output of the models.
+//
+// A Parser turns the text of an assistant message into a typed value and describes the
+// expected format with Instructions, to be added to the prompt. Parsers compose with Map and
+// FirstOf; ParseWithRetry feeds the parse errors back to the model, and NewNode applies a parser
+// to the last assistant message of the state.
+//
+// Example:
+//
+//	sentiment := parser.Enum("positive", "negative", "neutral")
+//	node, err := parser.NewNode("Classify", sentiment,
+//	    func(s MyState) []a.Message { return s.Conversation.Messages },
+//	    func(s MyState, value string) MyState { s.Sentiment = value; return s })
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrParse indicates that the text does not contain a value in the expected format.
+var ErrParse = errors.New("cannot parse the model output")
+
+// Parser extracts a typed value from the output of a model.
+type Parser[T any] interface {
+	// Parse extracts the value from the text.
+	//
+	// Parameters:
+	//   - text: The output of the model.
+	//
+	// Returns:
+	//   - The value.
+	//   - An error wrapping ErrParse if the text does not contain a valid value.
+	Parse(text string) (T, error)
+	// Instructions describes the expected format, to be added to the prompt.
+	//
+	// Returns:
+	//   - The format instructions.
+	Instructions() string
+}
+
+// ParseFn is the function implementing the parsing of a Parser built with Func.
+type ParseFn[T any] func(text string) (T, error)
+
+type funcParser[T any] struct {
+	parse        ParseFn[T]
+	instructions string
+}
+
+func (p funcParser[T]) Parse(text string) (T, error) { return p.parse(text) }
+
+func (p funcParser[T]) Instructions() string { return p.instructions }
+
+// Func creates a Parser from a function.
+//
+// Parameters:
+//   - parse: The parsing function, its errors should wrap ErrParse.
+//   - instructions: The format instructions.
+//
+// Returns:
+//   - The Parser.
+//
+// Example:
+//
+//	yesNo := parser.Func(func(text string) (bool, error) {
+//	    return strconv.ParseBool(strings.TrimSpace(text))
+//	}, "Answer with true or false.")
+func Func[T any](parse ParseFn[T], instructions string) Parser[T] {
+	return funcParser[T]{parse: parse, instructions: instructions}
+}
+
+// Map converts the values extracted by a parser.
+//
+// Parameters:
+//   - parser: The parser extracting the source value.
+//   - fn: The conversion, an error fails the parsing.
+//
+// Returns:
+//   - The Parser of the converted values.
+//
+// Example:
+//
+//	count := parser.Map(parser.Enum("one", "two"), func(value string) (int, error) {
+//	    return map[string]int{"one": 1, "two": 2}[value], nil
+//	})
+func Map[S, T any](parser Parser[S], fn func(S) (T, error)) Parser[T] {
+	return Func(func(text string) (T, error) {
+		var zero T
+		value, err := parser.Parse(text)
+		if err != nil {
+			return zero, err
+		}
+		rv, err := fn(value)
+		if err != nil {
+			return zero, fmt.Errorf("%w: %w", ErrParse, err)
+		}
+		return rv, nil
+	}, parser.Instructions())
+}
+
+// FirstOf creates a parser returning the value of the first parser succeeding.
+//
+// The instructions are the ones of the first parser, the others acting as fallbacks.
+//
+// Parameters:
+//   - parsers: The parsers, in order of preference.
+//
+// Returns:
+//   - The Parser.
+//
+// Example:
+//
+//	rows := parser.FirstOf(parser.JSON[[]map[string]string](), parser.MarkdownTable())
+func FirstOf[T any](parsers ...Parser[T]) Parser[T] {
+	instructions := ""
+	if len(parsers) > 0 {
+		instructions = parsers[0].Instructions()
+	}
+	return Func(func(text string) (T, error) {
+		var zero T
+		errs := make([]error, 0, len(parsers))
+		for _, parser := range parsers {
+			rv, err := parser.Parse(text)
+			if err == nil {
+				return rv, nil
+			}
+			errs = append(errs, err)
+		}
+		if len(errs) == 0 {
+			return zero, fmt.Errorf("%w: no parser", ErrParse)
+		}
+		return zero, errors.Join(errs...)
+	}, instructions)
+}
+
+// fencedBlock returns the content of the first markdown code fence of the text, or the trimmed text.
+func fencedBlock(text string) string {
+	start := strings.Index(text, "```")
+	if start == -1 {
+		return strings.TrimSpace(text)
+	}
+	rest := text[start+3:]
+	if nl := strings.Index(rest, "\n"); nl != -1 {
+		rest = rest[nl+1:]
+	}
+	if end := strings.Index(rest, "```"); end != -1 {
+		rest = rest[:end]
+	}
+	return strings.TrimSpace(rest)
+}
@@ -0,0 +1,176 @@
+package parser_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/agent/parser"
+	b "github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+type evaluation struct {
+	Score  int    `json:"score" xml:"score"`
+	Reason string `json:"reason" xml:"reason"`
+}
+
+func TestJSON(t *testing.T) {
+	p := parser.JSON[evaluation]()
+
+	got, err := p.Parse("Here it is:\n```json\n{\"score\": 8, \"reason\": \"fine\"}\n```\nHope it helps.")
+	if err != nil || got.Score != 8 || got.Reason != "fine" {
+		t.Errorf("Expected the fenced document to be parsed, got %+v and %v", got, err)
+	}
+	got, err = p.Parse(`Sure! {"score": 3, "reason": "weak"} is my evaluation.`)
+	if err != nil || got.Score != 3 {
+		t.Errorf("Expected the inline document to be parsed, got %+v and %v", got, err)
+	}
+	if _, err := p.Parse(`{"score": "high"}`); !errors.Is(err, parser.ErrParse) {
+		t.Errorf("Expected ErrParse for a document not matching the schema, got %v", err)
+	}
+	if !strings.Contains(p.Instructions(), `"score"`) {
+		t.Errorf("Expected the schema in the instructions, got %q", p.Instructions())
+	}
+}
+
+func TestXML(t *testing.T) {
+	got, err := parser.XML[evaluation]("evaluation").Parse("Result: <evaluation><score>7</score><reason>ok</reason></evaluation>")
+	if err != nil || got.Score != 7 || got.Reason != "ok" {
+		t.Errorf("Expected the XML document to be parsed, got %+v and %v", got, err)
+	}
+	if _, err := parser.XML[evaluation]("evaluation").Parse("no markup"); !errors.Is(err, parser.ErrParse) {
+		t.Errorf("Expected ErrParse, got %v", err)
+	}
+}
+
+func TestMarkdownTable(t *testing.T) {
+	rows, err := parser.MarkdownTable().Parse("The team:\n\n| name | age |\n|:-----|----:|\n| Ada | 36 |\n| Alan | 41 |\n\nThat's all.")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if len(rows) != 2 || rows[0]["name"] != "Ada" || rows[1]["age"] != "41" {
+		t.Errorf("Unexpected rows %v", rows)
+	}
+	if _, err := parser.MarkdownTable().Parse("| a | b |\n| 1 | 2 |"); !errors.Is(err, parser.ErrParse) {
+		t.Errorf("Expected ErrParse without the separator row, got %v", err)
+	}
+}
+
+func TestEnum(t *testing.T) {
+	p := parser.Enum("positive", "negative", "neutral")
+	tests := map[string]string{
+		"Positive":                  "positive",
+		` "negative". `:             "negative",
+		"The sentiment is neutral.": "neutral",
+	}
+	for text, want := range tests {
+		if got, err := p.Parse(text); err != nil || got != want {
+			t.Errorf("Parse(%q) = %q, %v; want %q", text, got, err, want)
+		}
+	}
+	if _, err := p.Parse("Either positive or negative"); !errors.Is(err, parser.ErrParse) {
+		t.Errorf("Expected ErrParse for an ambiguous answer, got %v", err)
+	}
+}
+
+func TestMapAndFirstOf(t *testing.T) {
+	score := parser.Map(parser.JSON[evaluation](), func(e evaluation) (int, error) {
+		if e.Score > 10 {
+			return 0, errors.New("score out of range")
+		}
+		return e.Score, nil
+	})
+	if _, err := score.Parse(`{"score": 11, "reason": "x"}`); !errors.Is(err, parser.ErrParse) {
+		t.Errorf("Expected the conversion error to wrap ErrParse, got %v", err)
+	}
+
+	rows := parser.FirstOf(parser.JSON[[]map[string]string](), parser.MarkdownTable())
+	got, err := rows.Parse("| k |\n|---|\n| v |")
+	if err != nil || len(got) != 1 || got[0]["k"] != "v" {
+		t.Errorf("Expected the fallback parser to succeed, got %v and %v", got, err)
+	}
+	if rows.Instructions() != parser.JSON[[]map[string]string]().Instructions() {
+		t.Error("Expected the instructions of the first parser")
+	}
+}
+
+func TestParseWithRetry(t *testing.T) {
+	answers := []string{"I think it is good", "positive"}
+	var requests [][]a.Message
+	ask := func(ctx context.Context, messages []a.Message) (a.Message, error) {
+		requests = append(requests, messages)
+		return a.CreateMessage(a.Assistant, answers[len(requests)-1]), nil
+	}
+
+	messages := []a.Message{a.CreateMessage(a.User, "Classify: great product")}
+	got, answer, err := parser.ParseWithRetry(context.Background(), parser.Enum("positive", "negative"), messages, ask, 1)
+	if err != nil || got != "positive" || answer.Content != "positive" {
+		t.Fatalf("Expected the retried answer to be parsed, got %q and %v", got, err)
+	}
+	if len(requests) != 2 || len(requests[1]) != 3 || !strings.Contains(requests[1][2].Content, "one of: positive, negative") {
+		t.Errorf("Expected the feedback to be sent with the retry, got %+v", requests)
+	}
+	if len(messages) != 1 {
+		t.Error("Expected the messages not to be modified")
+	}
+
+	requests = nil
+	answers = []string{"maybe"}
+	if _, _, err := parser.ParseWithRetry(context.Background(), parser.Enum("positive", "negative"), messages, ask, 0); !errors.Is(err, parser.ErrParse) {
+		t.Errorf("Expected ErrParse once the retries are exhausted, got %v", err)
+	}
+}
+
+type reviewState struct {
+	Conversation a.Conversation
+	Evaluation   *evaluation
+}
+
+func TestNewNode(t *testing.T) {
+	if _, err := parser.NewNode[reviewState, evaluation]("Parse", parser.JSON[evaluation](), nil, nil); !errors.Is(err, parser.ErrAccessorNil) {
+		t.Errorf("Expected ErrAccessorNil, got %v", err)
+	}
+
+	node, err := parser.NewNode("Parse", parser.JSON[evaluation](),
+		func(s reviewState) []a.Message { return s.Conversation.Messages },
+		func(s reviewState, e evaluation) reviewState { s.Evaluation = &e; return s })
+	if err != nil {
+		t.Fatalf("NewNode failed: %v", err)
+	}
+
+	seed, _ := b.NewNode("Seed", func(userInput, currentState reviewState, notify g.NotifyPartialFn[reviewState]) (reviewState, error) {
+		return userInput, nil
+	})
+	stateMonitorCh := make(chan g.StateMonitorEntry[reviewState], 10)
+	runtime, _ := b.CreateRuntime(b.CreateStartEdge(seed), stateMonitorCh)
+	runtime.AddEdge(b.CreateEdge(seed, node), b.CreateEndEdge(node))
+	defer runtime.Shutdown()
+
+	runtime.Invoke(reviewState{Conversation: a.CreateConversation(
+		a.CreateMessage(a.User, "Evaluate"),
+		a.CreateMessage(a.Assistant, `{"score": 9, "reason": "great"}`),
+	)})
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case entry := <-stateMonitorCh:
+			if entry.Error != nil {
+				t.Fatalf("Unexpected error: %v", entry.Error)
+			}
+			if entry.Running {
+				continue
+			}
+			if entry.NewState.Evaluation == nil || entry.NewState.Evaluation.Score != 9 {
+				t.Errorf("Expected the evaluation to be parsed into the state, got %+v", entry.NewState)
+			}
+			return
+		case <-timeout:
+			t.Fatal("Timeout waiting for the graph completion")
+		}
+	}
+}
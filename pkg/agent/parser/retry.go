@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+)
+
+// ErrInvalidRetries indicates that a negative number of retries was given.
+var ErrInvalidRetries = errors.New("retries must be at least 0")
+
+// AskFn sends the messages to the model and returns its answer.
+//
+// Example:
+//
+//	ask := func(ctx context.Context, messages []a.Message) (a.Message, error) {
+//	    opts.Messages = messages
+//	    return openai.ChatCompletion(ctx, client.Chat, opts)
+//	}
+type AskFn func(ctx context.Context, messages []a.Message) (a.Message, error)
+
+// FeedbackPrompt builds the message sent back to the model when its output cannot be parsed.
+//
+// Parameters:
+//   - instructions: The format instructions of the parser.
+//   - err: The parse error.
+//
+// Returns:
+//   - The feedback prompt.
+func FeedbackPrompt(instructions string, err error) string {
+	return fmt.Sprintf("Your previous answer is not valid: %v.\n%s", err, instructions)
+}
+
+// ParseWithRetry asks the model and parses its answer, feeding the parse errors back to the model.
+//
+// When the answer cannot be parsed, the answer and a FeedbackPrompt are appended to the messages
+// and the model is asked again, up to maxRetries times.
+//
+// Parameters:
+//   - ctx: The context of the requests.
+//   - parser: The parser of the answers.
+//   - messages: The messages to send, not modified.
+//   - ask: The function calling the model.
+//   - maxRetries: The number of retries after the first answer.
+//
+// Returns:
+//   - The parsed value.
+//   - The last answer of the model.
+//   - An error if a request fails or no answer can be parsed within the retries.
+//
+// Example:
+//
+//	value, answer, err := parser.ParseWithRetry(ctx, parser.Enum("yes", "no"), messages, ask, 2)
+func ParseWithRetry[T any](ctx context.Context, parser Parser[T], messages []a.Message, ask AskFn, maxRetries int) (T, a.Message, error) {
+	var zero T
+	if maxRetries < 0 {
+		return zero, a.Message{}, ErrInvalidRetries
+	}
+
+	useMessages := append([]a.Message{}, messages...)
+	var lastErr error
+	for attempt := 0; attempt <= maxRetries; attempt++ {
+		answer, err := ask(ctx, useMessages)
+		if err != nil {
+			return zero, a.Message{}, fmt.Errorf("parse request failed: %w", err)
+		}
+
+		rv, err := parser.Parse(answer.Content)
+		if err == nil {
+			return rv, answer, nil
+		}
+		lastErr = err
+
+		useMessages = append(useMessages,
+			answer,
+			a.CreateMessage(a.User, FeedbackPrompt(parser.Instructions(), err)),
+		)
+	}
+
+	return zero, a.Message{}, fmt.Errorf("no valid answer after %d retries: %w", maxRetries, lastErr)
+}
@@ -0,0 +1,227 @@
+package agent
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	t "github.com/morphy76/ggraph/pkg/agent/tool"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// AppendMessages reduces a conversation appending the new messages of the change to the history.
+//
+// The change may either be the whole conversation, as returned by the agent nodes, or only its new
+// messages: the messages of the change extending the history are the new ones, otherwise all of
+// them are. The tool calls are the ones of the change, the metadata and the system prompt too unless
+// the change has none.
+// Unlike the default replacing reducer, a node returning only its answer does not blow away the
+// history.
+//
+// Parameters:
+//   - currentState: The conversation before the change.
+//   - change: The conversation returned by the node.
+//
+// Returns:
+//   - Conversation: The reduced conversation.
+//
+// Example:
+//
+//	node, err := builders.NewNode("Answer", answerFn, g.WithReducer(agent.AppendMessages))
+func AppendMessages(currentState, change Conversation) Conversation {
+	rv := currentState.Clone()
+	rv.Messages = append(rv.Messages, newMessages(currentState.Messages, change.Messages)...)
+	rv.CurrentToolCalls = change.CurrentToolCalls
+	if change.Metadata != nil {
+		rv.Metadata = change.Metadata
+	}
+	if change.SystemPrompt != nil {
+		rv.SystemPrompt = change.SystemPrompt
+	}
+	return rv
+}
+
+// DedupeMessages reduces a conversation like AppendMessages, dropping the repeated messages.
+//
+// A message is repeated when an earlier one has the same ID or, for the messages with no ID, the
+// same timestamp, role, content and tool calls, e.g. when a retried or a replayed node returns the
+// same messages again; the first occurrence is kept.
+//
+// Parameters:
+//   - currentState: The conversation before the change.
+//   - change: The conversation returned by the node.
+//
+// Returns:
+//   - Conversation: The reduced conversation.
+//
+// Example:
+//
+//	node, err := builders.NewNode("Answer", answerFn, g.WithReducer(agent.DedupeMessages))
+func DedupeMessages(currentState, change Conversation) Conversation {
+	rv := AppendMessages(currentState, change)
+	rv.Messages = dedupeMessages(rv.Messages)
+	return rv
+}
+
+// CapMessages creates a reducer appending the new messages like AppendMessages, then capping the history length.
+//
+// The leading system messages are always kept, the oldest messages after them are dropped first;
+// the tool results left without the message calling the tools are dropped too, since the
+// providers reject them.
+//
+// Parameters:
+//   - maxMessages: The maximum number of messages of the history, zero or negative for no cap.
+//
+// Returns:
+//   - graph.ReducerFn[Conversation]: The reducer function.
+//
+// Example:
+//
+//	node, err := builders.NewNode("Answer", answerFn, g.WithReducer(agent.CapMessages(50)))
+func CapMessages(maxMessages int) g.ReducerFn[Conversation] {
+	return func(currentState, change Conversation) Conversation {
+		rv := AppendMessages(currentState, change)
+		rv.Messages = capMessages(rv.Messages, maxMessages)
+		return rv
+	}
+}
+
+// MergeToolResults reduces a conversation appending the results of the tool calls, as returned by the tool nodes.
+//
+// A result of a call already answered replaces the previous result in place, instead of being
+// appended again; the other messages are appended like AppendMessages. Unless the change requests
+// new tool calls, the pending tool calls are the ones left without a result.
+//
+// Parameters:
+//   - currentState: The conversation before the change.
+//   - change: The conversation returned by the node.
+//
+// Returns:
+//   - Conversation: The reduced conversation.
+//
+// Example:
+//
+//	tools, err := builders.NewContextNode("Tools", runToolsFn, g.WithReducer(agent.MergeToolResults))
+func MergeToolResults(currentState, change Conversation) Conversation {
+	rv := currentState.Clone()
+	answered := make(map[string]struct{})
+	for _, message := range newMessages(currentState.Messages, change.Messages) {
+		callID, ok := toolResultCallID(message)
+		if !ok {
+			rv.Messages = append(rv.Messages, message)
+			continue
+		}
+		answered[callID] = struct{}{}
+		idx := slices.IndexFunc(rv.Messages, func(previous Message) bool {
+			previousID, ok := toolResultCallID(previous)
+			return ok && previousID == callID
+		})
+		if idx < 0 {
+			rv.Messages = append(rv.Messages, message)
+			continue
+		}
+		rv.Messages[idx] = message
+	}
+
+	rv.CurrentToolCalls = change.CurrentToolCalls
+	if len(rv.CurrentToolCalls) == 0 {
+		rv.CurrentToolCalls = slices.DeleteFunc(cloneFnCalls(currentState.CurrentToolCalls), func(call t.FnCall) bool {
+			_, ok := answered[call.ID]
+			return ok
+		})
+	}
+	if change.Metadata != nil {
+		rv.Metadata = change.Metadata
+	}
+	if change.SystemPrompt != nil {
+		rv.SystemPrompt = change.SystemPrompt
+	}
+	return rv
+}
+
+// ConversationReducer creates the reducer to use by default for the conversation nodes.
+//
+// It merges the tool results like MergeToolResults, drops the repeated messages like
+// DedupeMessages and caps the history like CapMessages: it suits both the agent nodes, returning
+// the whole conversation, and the nodes returning only their new messages.
+//
+// Parameters:
+//   - maxMessages: The maximum number of messages of the history, zero or negative for no cap.
+//
+// Returns:
+//   - graph.ReducerFn[Conversation]: The reducer function.
+//
+// Example:
+//
+//	node, err := builders.NewNode("Answer", answerFn, g.WithReducer(agent.ConversationReducer(100)))
+func ConversationReducer(maxMessages int) g.ReducerFn[Conversation] {
+	return func(currentState, change Conversation) Conversation {
+		rv := MergeToolResults(currentState, change)
+		rv.Messages = capMessages(dedupeMessages(rv.Messages), maxMessages)
+		return rv
+	}
+}
+
+// messageKey identifies a message by its ID, by its timestamp, role, content and tool calls if it has none.
+func messageKey(message Message) string {
+	if message.ID != "" {
+		return "id|" + message.ID
+	}
+	var key strings.Builder
+	fmt.Fprintf(&key, "%d|%d|%s", message.Ts.UnixNano(), message.Role, message.Content)
+	for _, call := range message.ToolCalls {
+		fmt.Fprintf(&key, "|%s:%s", call.ID, call.ToolName)
+	}
+	return key.String()
+}
+
+// newMessages returns the messages of the change not already in the history, i.e. the ones following the history
+// when the change extends it, all of them otherwise.
+func newMessages(history, change []Message) []Message {
+	if len(change) < len(history) {
+		return change
+	}
+	for idx, message := range history {
+		if messageKey(message) != messageKey(change[idx]) {
+			return change
+		}
+	}
+	return change[len(history):]
+}
+
+func dedupeMessages(messages []Message) []Message {
+	seen := make(map[string]struct{}, len(messages))
+	return slices.DeleteFunc(messages, func(message Message) bool {
+		key := messageKey(message)
+		if _, ok := seen[key]; ok {
+			return true
+		}
+		seen[key] = struct{}{}
+		return false
+	})
+}
+
+func capMessages(messages []Message, maxMessages int) []Message {
+	if maxMessages <= 0 || len(messages) <= maxMessages {
+		return messages
+	}
+	system := 0
+	for system < len(messages) && messages[system].Role == System {
+		system++
+	}
+	keep := max(maxMessages-system, 0)
+	tail := messages[len(messages)-min(keep, len(messages)-system):]
+	for len(tail) > 0 && tail[0].Role == Tool {
+		tail = tail[1:]
+	}
+	return append(slices.Clone(messages[:system]), tail...)
+}
+
+// toolResultCallID returns the identifier of the tool call a tool message is the result of, see ExecuteToolCalls.
+func toolResultCallID(message Message) (string, bool) {
+	if message.Role != Tool {
+		return "", false
+	}
+	callID, _, ok := strings.Cut(message.Content, ":")
+	return callID, ok && callID != ""
+}
@@ -0,0 +1,161 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morphy76/ggraph/pkg/agent/tool"
+)
+
+func testMessage(role MessageRole, content string, offset int) Message {
+	return Message{Ts: time.Unix(1700000000, 0).Add(time.Duration(offset) * time.Second), Role: role, Content: content}
+}
+
+func assertContents(t *testing.T, got []Message, want ...string) {
+	t.Helper()
+	if contents(got) != strings.Join(want, ",") {
+		t.Fatalf("messages = %s, want %s", contents(got), strings.Join(want, ","))
+	}
+}
+
+func TestAppendMessages(t *testing.T) {
+	history := Conversation{
+		Messages: []Message{testMessage(System, "sys", 0), testMessage(User, "hi", 1)},
+		Metadata: map[string]string{"backend": "a"},
+	}
+	answer := testMessage(Assistant, "hello", 2)
+
+	t.Run("whole conversation", func(t *testing.T) {
+		change := history.Clone()
+		change.Messages = append(change.Messages, answer)
+		got := AppendMessages(history, change)
+		assertContents(t, got.Messages, "sys", "hi", "hello")
+		if got.Metadata["backend"] != "a" {
+			t.Errorf("Metadata = %v, want the current one", got.Metadata)
+		}
+	})
+
+	t.Run("new messages only", func(t *testing.T) {
+		calls := []tool.FnCall{{ID: "c1", ToolName: "lookup"}}
+		got := AppendMessages(history, Conversation{Messages: []Message{answer}, CurrentToolCalls: calls, Metadata: map[string]string{"backend": "b"}})
+		assertContents(t, got.Messages, "sys", "hi", "hello")
+		if len(got.CurrentToolCalls) != 1 || got.CurrentToolCalls[0].ID != "c1" {
+			t.Errorf("CurrentToolCalls = %v, want the change's", got.CurrentToolCalls)
+		}
+		if got.Metadata["backend"] != "b" {
+			t.Errorf("Metadata = %v, want the change's", got.Metadata)
+		}
+		assertContents(t, history.Messages, "sys", "hi")
+	})
+
+	t.Run("empty change", func(t *testing.T) {
+		got := AppendMessages(history, Conversation{})
+		assertContents(t, got.Messages, "sys", "hi")
+	})
+}
+
+func TestDedupeMessages(t *testing.T) {
+	history := Conversation{Messages: []Message{testMessage(User, "hi", 1), testMessage(Assistant, "hello", 2)}}
+	change := Conversation{Messages: []Message{testMessage(Assistant, "hello", 2), testMessage(User, "hi", 3)}}
+
+	got := DedupeMessages(history, change)
+	assertContents(t, got.Messages, "hi", "hello", "hi")
+	if !got.Messages[2].Ts.Equal(change.Messages[1].Ts) {
+		t.Errorf("kept message at %v, want the later one", got.Messages[2].Ts)
+	}
+}
+
+func TestDedupeMessages_ByID(t *testing.T) {
+	answer := testMessage(Assistant, "hello", 2)
+	answer.ID = "m-1"
+	replayed := answer
+	replayed.Ts = answer.Ts.Add(time.Minute)
+	again := testMessage(Assistant, "hello", 2)
+	again.ID = "m-2"
+
+	got := DedupeMessages(Conversation{Messages: []Message{answer}}, Conversation{Messages: []Message{replayed, again}})
+	if len(got.Messages) != 2 || got.Messages[0].ID != "m-1" || !got.Messages[0].Ts.Equal(answer.Ts) || got.Messages[1].ID != "m-2" {
+		t.Errorf("Messages = %+v, want m-1 once followed by m-2", got.Messages)
+	}
+}
+
+func TestCapMessages(t *testing.T) {
+	history := Conversation{Messages: []Message{
+		testMessage(System, "sys", 0),
+		testMessage(User, "q1", 1),
+		{Ts: time.Unix(1700000002, 0), Role: Assistant, ToolCalls: []tool.FnCall{{ID: "c1", ToolName: "lookup"}}},
+		testMessage(Tool, "c1:42", 3),
+		testMessage(Assistant, "a1", 4),
+	}}
+
+	tests := []struct {
+		name        string
+		maxMessages int
+		want        []string
+	}{
+		{name: "no cap", maxMessages: 0, want: []string{"sys", "q1", "", "c1:42", "a1", "q2"}},
+		{name: "under cap", maxMessages: 10, want: []string{"sys", "q1", "", "c1:42", "a1", "q2"}},
+		{name: "keeps system", maxMessages: 3, want: []string{"sys", "a1", "q2"}},
+		{name: "drops orphaned tool results", maxMessages: 4, want: []string{"sys", "a1", "q2"}},
+		{name: "keeps tool calls", maxMessages: 5, want: []string{"sys", "", "c1:42", "a1", "q2"}},
+		{name: "system only", maxMessages: 1, want: []string{"sys"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CapMessages(tt.maxMessages)(history, Conversation{Messages: []Message{testMessage(User, "q2", 5)}})
+			assertContents(t, got.Messages, tt.want...)
+		})
+	}
+}
+
+func TestMergeToolResults(t *testing.T) {
+	calls := []tool.FnCall{{ID: "c1", ToolName: "lookup"}, {ID: "c2", ToolName: "search"}}
+	history := Conversation{
+		Messages: []Message{
+			testMessage(User, "q", 1),
+			{Ts: time.Unix(1700000002, 0), Role: Assistant, ToolCalls: calls},
+			testMessage(Tool, "c1:stale", 3),
+		},
+		CurrentToolCalls: calls,
+	}
+
+	got := MergeToolResults(history, Conversation{Messages: []Message{
+		testMessage(Tool, "c1:fresh", 4),
+		testMessage(Tool, "c2:found", 4),
+	}})
+	assertContents(t, got.Messages, "q", "", "c1:fresh", "c2:found")
+	if len(got.CurrentToolCalls) != 0 {
+		t.Errorf("CurrentToolCalls = %v, want none pending", got.CurrentToolCalls)
+	}
+
+	partial := MergeToolResults(history, Conversation{Messages: []Message{testMessage(Tool, "c2:found", 4)}})
+	assertContents(t, partial.Messages, "q", "", "c1:stale", "c2:found")
+	if len(partial.CurrentToolCalls) != 1 || partial.CurrentToolCalls[0].ID != "c1" {
+		t.Errorf("CurrentToolCalls = %v, want c1 still pending", partial.CurrentToolCalls)
+	}
+	if len(history.CurrentToolCalls) != 2 {
+		t.Errorf("current state tool calls modified: %v", history.CurrentToolCalls)
+	}
+
+	next := []tool.FnCall{{ID: "c3", ToolName: "lookup"}}
+	requested := MergeToolResults(history, Conversation{Messages: []Message{testMessage(Assistant, "", 5)}, CurrentToolCalls: next})
+	if len(requested.CurrentToolCalls) != 1 || requested.CurrentToolCalls[0].ID != "c3" {
+		t.Errorf("CurrentToolCalls = %v, want the requested ones", requested.CurrentToolCalls)
+	}
+}
+
+func TestConversationReducer(t *testing.T) {
+	reducer := ConversationReducer(3)
+	state := Conversation{Messages: []Message{testMessage(System, "sys", 0)}}
+
+	state = reducer(state, Conversation{Messages: []Message{testMessage(User, "q1", 1)}})
+	whole := state.Clone()
+	whole.Messages = append(whole.Messages, testMessage(Assistant, "a1", 2))
+	state = reducer(state, whole)
+	assertContents(t, state.Messages, "sys", "q1", "a1")
+
+	state = reducer(state, Conversation{Messages: []Message{testMessage(Assistant, "a1", 2), testMessage(User, "q2", 3)}})
+	assertContents(t, state.Messages, "sys", "a1", "q2")
+}
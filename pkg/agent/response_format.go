@@ -0,0 +1,234 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/morphy76/ggraph/internal/agent/schema"
+)
+
+const (
+	// DefaultResponseFormatRepairAttempts is the default number of repair prompts sent when the model output is invalid.
+	DefaultResponseFormatRepairAttempts = 2
+)
+
+var (
+	// ErrResponseFormatNotSet is returned when a structured response is requested without a response format.
+	ErrResponseFormatNotSet = errors.New("response format is not set")
+	// ErrInvalidStructuredResponse is returned when the model output cannot be decoded into the response format.
+	ErrInvalidStructuredResponse = errors.New("invalid structured response")
+	// ErrorInvalidRepairAttempts is returned when the number of repair attempts is negative.
+	ErrorInvalidRepairAttempts = errors.New("repair attempts must be at least 0")
+
+	nonSchemaNameChars = regexp.MustCompile(`[^a-zA-Z0-9_-]`)
+)
+
+// ResponseFormat describes the structured output expected from the model.
+type ResponseFormat struct {
+	// Name is the name of the format, derived from the Go type.
+	Name string
+	// Schema is the JSON schema the output must satisfy.
+	Schema map[string]any
+	// Strict requests strict schema adherence to providers supporting it.
+	Strict bool
+	// MaxRepairAttempts is the number of repair prompts sent before giving up.
+	MaxRepairAttempts int
+
+	decode func(content string) (any, error)
+}
+
+// Decode parses, validates and unmarshals the model output.
+//
+// Markdown code fences around the JSON document are tolerated.
+//
+// Parameters:
+//   - content: The content of the assistant message.
+//
+// Returns:
+//   - The decoded value, of the type used to build the format.
+//   - An error wrapping ErrInvalidStructuredResponse if the content is not acceptable.
+func (f *ResponseFormat) Decode(content string) (any, error) {
+	return f.decode(content)
+}
+
+// RepairPrompt builds the message sent back to the model when its output is invalid.
+//
+// Parameters:
+//   - err: The error returned by Decode.
+//
+// Returns:
+//   - The repair prompt.
+func (f *ResponseFormat) RepairPrompt(err error) string {
+	useSchema, _ := json.Marshal(f.Schema)
+	return fmt.Sprintf("Your previous answer is not valid: %v.\n"+
+		"Answer again with ONLY a JSON document matching this JSON schema, without any other text:\n%s", err, useSchema)
+}
+
+// CreateResponseFormat builds a ResponseFormat for the type T.
+//
+// Parameters:
+//   - validators: Optional functions checking semantic constraints on the decoded value.
+//
+// Returns:
+//   - The ResponseFormat for T.
+//
+// Example usage:
+//
+//	format := CreateResponseFormat[Evaluation]()
+func CreateResponseFormat[T any](validators ...func(T) error) *ResponseFormat {
+	var zero T
+	useType := reflect.TypeOf(&zero).Elem()
+	useSchema := schema.FromType(useType)
+
+	name := useType.Name()
+	if name == "" {
+		name = "response"
+	}
+
+	return &ResponseFormat{
+		Name:              nonSchemaNameChars.ReplaceAllString(name, "_"),
+		Schema:            useSchema,
+		MaxRepairAttempts: DefaultResponseFormatRepairAttempts,
+		decode: func(content string) (any, error) {
+			useContent := stripCodeFence(content)
+
+			var raw any
+			if err := json.Unmarshal([]byte(useContent), &raw); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidStructuredResponse, err)
+			}
+			if err := schema.Validate(useSchema, raw); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidStructuredResponse, err)
+			}
+
+			var rv T
+			if err := json.Unmarshal([]byte(useContent), &rv); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidStructuredResponse, err)
+			}
+			for _, validator := range validators {
+				if err := validator(rv); err != nil {
+					return nil, fmt.Errorf("%w: %v", ErrInvalidStructuredResponse, err)
+				}
+			}
+			return rv, nil
+		},
+	}
+}
+
+// DecodeResponse decodes the model output with the given format into T.
+//
+// Parameters:
+//   - format: The ResponseFormat built for T.
+//   - content: The content of the assistant message.
+//
+// Returns:
+//   - The decoded value.
+//   - An error if the format is not set, the content is invalid or the format was built for another type.
+//
+// Example usage:
+//
+//	eval, err := DecodeResponse[Evaluation](opts.ResponseFormat, message.Content)
+func DecodeResponse[T any](format *ResponseFormat, content string) (T, error) {
+	var zero T
+	if format == nil {
+		return zero, ErrResponseFormatNotSet
+	}
+	decoded, err := format.Decode(content)
+	if err != nil {
+		return zero, err
+	}
+	rv, ok := decoded.(T)
+	if !ok {
+		return zero, fmt.Errorf("%w: expected %T, got %T", ErrInvalidStructuredResponse, zero, decoded)
+	}
+	return rv, nil
+}
+
+// WithResponseFormat requests a structured output matching the JSON schema of T.
+//
+// Providers supporting schema-constrained output receive the schema with the request;
+// the decoded value is validated against the schema and the optional validators, and
+// invalid answers are repaired by re-prompting the model.
+//
+// Parameters:
+//   - validators: Optional functions checking semantic constraints on the decoded value.
+//
+// Returns:
+//   - A ModelOption that sets the ResponseFormat parameter.
+//
+// Example usage:
+//
+//	option := WithResponseFormat[Evaluation](func(e Evaluation) error {
+//	    if e.Score < 0 || e.Score > 10 {
+//	        return errors.New("score must be between 0 and 10")
+//	    }
+//	    return nil
+//	})
+func WithResponseFormat[T any](validators ...func(T) error) ModelOption {
+	return ModelOptionFunc(func(r *ModelOptions) error {
+		r.ResponseFormat = CreateResponseFormat(validators...)
+		return nil
+	})
+}
+
+// WithStrictResponseFormat enables strict schema adherence for the configured response format.
+//
+// It must be applied after WithResponseFormat.
+//
+// Returns:
+//   - A ModelOption that enables the strict mode.
+//
+// Example usage:
+//
+//	options, err := CreateConversationOptions(model, messages, WithResponseFormat[Evaluation](), WithStrictResponseFormat())
+func WithStrictResponseFormat() ModelOption {
+	return ModelOptionFunc(func(r *ModelOptions) error {
+		if r.ResponseFormat == nil {
+			return ErrResponseFormatNotSet
+		}
+		r.ResponseFormat.Strict = true
+		return nil
+	})
+}
+
+// WithResponseFormatRepairAttempts sets how many repair prompts are sent for invalid structured outputs.
+//
+// It must be applied after WithResponseFormat.
+//
+// Parameters:
+//   - attempts: The number of repair attempts, 0 disables the repair.
+//
+// Returns:
+//   - A ModelOption that sets the number of repair attempts.
+//
+// Example usage:
+//
+//	option := WithResponseFormatRepairAttempts(3)
+func WithResponseFormatRepairAttempts(attempts int) ModelOption {
+	return ModelOptionFunc(func(r *ModelOptions) error {
+		if attempts < 0 {
+			return ErrorInvalidRepairAttempts
+		}
+		if r.ResponseFormat == nil {
+			return ErrResponseFormatNotSet
+		}
+		r.ResponseFormat.MaxRepairAttempts = attempts
+		return nil
+	})
+}
+
+func stripCodeFence(content string) string {
+	rv := strings.TrimSpace(content)
+	if !strings.HasPrefix(rv, "```") {
+		return rv
+	}
+	rv = strings.TrimPrefix(rv, "```")
+	if nl := strings.Index(rv, "\n"); nl != -1 {
+		rv = rv[nl+1:]
+	}
+	rv = strings.TrimSuffix(strings.TrimSpace(rv), "```")
+	return strings.TrimSpace(rv)
+}
@@ -0,0 +1,104 @@
+package agent
+
+import (
+	"errors"
+	"testing"
+)
+
+type evaluation struct {
+	Score  int    `json:"score" desc:"The score between 0 and 10"`
+	Reason string `json:"reason"`
+}
+
+func TestWithResponseFormat(t *testing.T) {
+	opts := &ModelOptions{}
+	if err := WithResponseFormat[evaluation]().ApplyToConversation(opts); err != nil {
+		t.Fatalf("WithResponseFormat() failed: %v", err)
+	}
+	if opts.ResponseFormat == nil {
+		t.Fatal("Expected ResponseFormat to be set")
+	}
+	if opts.ResponseFormat.Name != "evaluation" {
+		t.Errorf("Expected name 'evaluation', got '%s'", opts.ResponseFormat.Name)
+	}
+	if opts.ResponseFormat.MaxRepairAttempts != DefaultResponseFormatRepairAttempts {
+		t.Errorf("Expected default repair attempts, got %d", opts.ResponseFormat.MaxRepairAttempts)
+	}
+}
+
+func TestWithResponseFormatRepairAttempts(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []ModelOption
+		wantErr error
+	}{
+		{"without format", []ModelOption{WithResponseFormatRepairAttempts(1)}, ErrResponseFormatNotSet},
+		{"negative", []ModelOption{WithResponseFormat[evaluation](), WithResponseFormatRepairAttempts(-1)}, ErrorInvalidRepairAttempts},
+		{"valid", []ModelOption{WithResponseFormat[evaluation](), WithResponseFormatRepairAttempts(0)}, nil},
+		{"strict without format", []ModelOption{WithStrictResponseFormat()}, ErrResponseFormatNotSet},
+		{"strict", []ModelOption{WithResponseFormat[evaluation](), WithStrictResponseFormat()}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &ModelOptions{}
+			var err error
+			for _, opt := range tt.opts {
+				if err = opt.ApplyToConversation(opts); err != nil {
+					break
+				}
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestDecodeResponse(t *testing.T) {
+	format := CreateResponseFormat(func(e evaluation) error {
+		if e.Score < 0 || e.Score > 10 {
+			return errors.New("score must be between 0 and 10")
+		}
+		return nil
+	})
+
+	tests := []struct {
+		name    string
+		content string
+		want    evaluation
+		wantErr bool
+	}{
+		{"valid", `{"score":7,"reason":"good"}`, evaluation{Score: 7, Reason: "good"}, false},
+		{"fenced", "```json\n{\"score\":3,\"reason\":\"meh\"}\n```", evaluation{Score: 3, Reason: "meh"}, false},
+		{"not json", `the score is 7`, evaluation{}, true},
+		{"schema mismatch", `{"score":"7","reason":"good"}`, evaluation{}, true},
+		{"missing field", `{"score":7}`, evaluation{}, true},
+		{"validator failure", `{"score":11,"reason":"too good"}`, evaluation{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := DecodeResponse[evaluation](format, tt.content)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidStructuredResponse) {
+					t.Errorf("Expected ErrInvalidStructuredResponse, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DecodeResponse() failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expected %+v, got %+v", tt.want, got)
+			}
+		})
+	}
+
+	if _, err := DecodeResponse[evaluation](nil, "{}"); !errors.Is(err, ErrResponseFormatNotSet) {
+		t.Errorf("Expected ErrResponseFormatNotSet, got %v", err)
+	}
+	if _, err := DecodeResponse[string](format, `{"score":1,"reason":"r"}`); !errors.Is(err, ErrInvalidStructuredResponse) {
+		t.Errorf("Expected ErrInvalidStructuredResponse for mismatching type, got %v", err)
+	}
+}
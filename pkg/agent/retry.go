@@ -0,0 +1,200 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+const (
+	// DefaultRetryInitialBackoff is the default wait before the first retry of a transient failure.
+	DefaultRetryInitialBackoff = 500 * time.Millisecond
+	// DefaultRetryMaxBackoff is the default cap of the wait between the retries of a transient failure.
+	DefaultRetryMaxBackoff = 30 * time.Second
+)
+
+var (
+	// ErrTransient indicates a provider failure worth retrying, such as a 429, a 5xx or a timeout.
+	ErrTransient = errors.New("transient provider error")
+	// ErrInvalidRetryPolicy indicates that the retry configuration of the model calls is invalid.
+	ErrInvalidRetryPolicy = errors.New("retries and budget cannot be negative and backoffs must be positive")
+	// ErrRetryPolicyNotSet indicates that the retry backoff is configured without WithRetry.
+	ErrRetryPolicyNotSet = errors.New("retry policy is not set")
+)
+
+// TransientError is returned by the providers when a request fails for a transient reason.
+type TransientError struct {
+	// RetryAfter is the wait suggested by the provider, zero if unknown.
+	RetryAfter time.Duration
+	// Err is the provider error.
+	Err error
+}
+
+// Error returns the error message.
+func (e *TransientError) Error() string {
+	return fmt.Sprintf("%s: %v", ErrTransient, e.Err)
+}
+
+// Unwrap returns the provider error.
+func (e *TransientError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the target is ErrTransient.
+func (e *TransientError) Is(target error) bool {
+	return target == ErrTransient
+}
+
+// RetryPolicy configures the retries of the model calls failing for a transient reason.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt.
+	MaxRetries int
+	// Budget bounds the total time spent by the attempts and the waits, 0 for no bound; the attempts
+	// run with its deadline.
+	Budget time.Duration
+	// InitialBackoff is the wait before the first retry, doubled at each retry.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the wait between the retries.
+	MaxBackoff time.Duration
+}
+
+// wait returns the wait before the retry following the given attempt.
+//
+// A Retry-After hint is honoured as is; otherwise the exponential backoff is jittered in its
+// upper half, so that the clients failing together do not retry together.
+func (p *RetryPolicy) wait(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+	backoff := min(time.Duration(float64(p.InitialBackoff)*math.Pow(2, float64(attempt))), p.MaxBackoff)
+	half := backoff / 2
+	return half + rand.N(half+1)
+}
+
+// WithRetry retries the model calls failing for a transient reason.
+//
+// The failures reported as ErrTransient or ErrRateLimited by the providers, such as the 429 and
+// 5xx statuses or the timeouts, are retried with a jittered exponential backoff honouring the
+// Retry-After hint of the provider; the other failures are returned at once.
+//
+// The retries of the provider clients multiply the ones of the policy. When a policy is set, the
+// OpenAI adapter disables the retries of its client; the callers of the other providers must
+// disable the retries of their clients themselves. A ProviderLimiter also retries the rate-limited
+// requests before the policy sees them: combined with the policy, disable its retries with
+// WithLimiterRetries(0, ...).
+//
+// The budget bounds the attempts too: each attempt runs with the deadline of the budget.
+//
+// Parameters:
+//   - maxRetries: The number of retries after the first attempt.
+//   - budget: The bound of the total time spent by the attempts and the waits, 0 for no bound.
+//
+// Returns:
+//   - A ModelOption that sets the retry policy.
+//
+// Example usage:
+//
+//	option := WithRetry(4, 2*time.Minute)
+func WithRetry(maxRetries int, budget time.Duration) ModelOption {
+	return ModelOptionFunc(func(r *ModelOptions) error {
+		if maxRetries < 0 || budget < 0 {
+			return ErrInvalidRetryPolicy
+		}
+		r.Retry = &RetryPolicy{
+			MaxRetries:     maxRetries,
+			Budget:         budget,
+			InitialBackoff: DefaultRetryInitialBackoff,
+			MaxBackoff:     DefaultRetryMaxBackoff,
+		}
+		return nil
+	})
+}
+
+// WithRetryBackoff sets the backoff of the retry policy configured with WithRetry.
+//
+// Parameters:
+//   - initial: The wait before the first retry, doubled at each retry.
+//   - maxBackoff: The cap of the wait between the retries.
+//
+// Returns:
+//   - A ModelOption that sets the backoff.
+//
+// Example usage:
+//
+//	opts, _ := CreateConversationOptions(model, messages, WithRetry(3, 0), WithRetryBackoff(time.Second, 10*time.Second))
+func WithRetryBackoff(initial, maxBackoff time.Duration) ModelOption {
+	return ModelOptionFunc(func(r *ModelOptions) error {
+		if initial <= 0 || maxBackoff < initial {
+			return ErrInvalidRetryPolicy
+		}
+		if r.Retry == nil {
+			return ErrRetryPolicyNotSet
+		}
+		r.Retry.InitialBackoff = initial
+		r.Retry.MaxBackoff = maxBackoff
+		return nil
+	})
+}
+
+// RetryCall calls the provider, retrying the transient failures with the policy of the options.
+//
+// Without a retry policy in the options, call is invoked once.
+//
+// Parameters:
+//   - ctx: The context of the request, cancelling the attempts and the waits.
+//   - opts: The request options.
+//   - call: The provider request, reporting the transient failures as ErrTransient or ErrRateLimited.
+//
+// Returns:
+//   - The response.
+//   - The error of the last attempt, when the retries or the budget are exhausted.
+//
+// Example:
+//
+//	answer, err := agent.RetryCall(ctx, opts, func(ctx context.Context) (agent.Message, error) {
+//	    return callTheModel(ctx, opts)
+//	})
+func RetryCall[R any](ctx context.Context, opts *ModelOptions, call func(ctx context.Context) (R, error)) (R, error) {
+	if opts == nil || opts.Retry == nil {
+		return call(ctx)
+	}
+	policy := opts.Retry
+
+	started := time.Now()
+	if policy.Budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, started.Add(policy.Budget))
+		defer cancel()
+	}
+	for attempt := 0; ; attempt++ {
+		rv, err := call(ctx)
+		if err == nil || (!errors.Is(err, ErrTransient) && !errors.Is(err, ErrRateLimited)) {
+			return rv, err
+		}
+		if attempt >= policy.MaxRetries {
+			return rv, fmt.Errorf("giving up after %d attempts: %w", attempt+1, err)
+		}
+
+		var retryAfter time.Duration
+		var transientErr *TransientError
+		var rateLimitErr *RateLimitError
+		if errors.As(err, &transientErr) {
+			retryAfter = transientErr.RetryAfter
+		} else if errors.As(err, &rateLimitErr) {
+			retryAfter = rateLimitErr.RetryAfter
+		}
+		wait := policy.wait(attempt, retryAfter)
+		if policy.Budget > 0 && time.Since(started)+wait > policy.Budget {
+			return rv, fmt.Errorf("retry budget of %s exhausted after %d attempts: %w", policy.Budget, attempt+1, err)
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return rv, fmt.Errorf("%w: %w", ctx.Err(), err)
+		}
+	}
+}
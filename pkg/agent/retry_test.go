@@ -0,0 +1,109 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func retryOptions(t *testing.T, opts ...ModelOption) *ModelOptions {
+	t.Helper()
+	rv, err := CreateConversationOptions("model", nil, opts...)
+	if err != nil {
+		t.Fatalf("CreateConversationOptions failed: %v", err)
+	}
+	return rv
+}
+
+func TestRetryCall_Transient(t *testing.T) {
+	opts := retryOptions(t, WithRetry(3, 0), WithRetryBackoff(time.Millisecond, 4*time.Millisecond))
+
+	calls := 0
+	got, err := RetryCall(context.Background(), opts, func(ctx context.Context) (string, error) {
+		calls++
+		if calls < 3 {
+			return "", &TransientError{Err: errors.New("503 Service Unavailable")}
+		}
+		return "answer", nil
+	})
+	if err != nil || got != "answer" || calls != 3 {
+		t.Errorf("Expected the transient failures to be retried, got %q, %v after %d calls", got, err, calls)
+	}
+
+	calls = 0
+	permanent := errors.New("400 Bad Request")
+	_, err = RetryCall(context.Background(), opts, func(ctx context.Context) (string, error) {
+		calls++
+		return "", permanent
+	})
+	if !errors.Is(err, permanent) || calls != 1 {
+		t.Errorf("Expected the permanent failure not to be retried, got %v after %d calls", err, calls)
+	}
+
+	calls = 0
+	_, err = RetryCall(context.Background(), opts, func(ctx context.Context) (string, error) {
+		calls++
+		return "", &RateLimitError{Err: errors.New("429")}
+	})
+	if !errors.Is(err, ErrRateLimited) || calls != 4 {
+		t.Errorf("Expected the rate limit to be retried until exhausted, got %v after %d calls", err, calls)
+	}
+}
+
+func TestRetryCall_RetryAfterAndBudget(t *testing.T) {
+	opts := retryOptions(t, WithRetry(5, 50*time.Millisecond), WithRetryBackoff(time.Millisecond, time.Millisecond))
+
+	calls := 0
+	started := time.Now()
+	_, err := RetryCall(context.Background(), opts, func(ctx context.Context) (string, error) {
+		calls++
+		return "", &TransientError{RetryAfter: 30 * time.Millisecond, Err: errors.New("429")}
+	})
+	if !errors.Is(err, ErrTransient) || calls != 2 {
+		t.Errorf("Expected the budget to stop the retries after 2 calls, got %v after %d calls", err, calls)
+	}
+	if elapsed := time.Since(started); elapsed < 30*time.Millisecond {
+		t.Errorf("Expected the Retry-After hint to be honoured, retried after %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = RetryCall(ctx, opts, func(ctx context.Context) (string, error) {
+		return "", &TransientError{Err: errors.New("timeout")}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancellation to stop the retries, got %v", err)
+	}
+
+	started = time.Now()
+	_, err = RetryCall(context.Background(), opts, func(ctx context.Context) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the budget to stop the hanging attempt, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("Expected the attempt to be bounded by the budget, returned after %s", elapsed)
+	}
+}
+
+func TestRetryPolicy_Jitter(t *testing.T) {
+	policy := &RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt := 0; attempt < 6; attempt++ {
+		backoff := min(100*time.Millisecond<<attempt, time.Second)
+		if wait := policy.wait(attempt, 0); wait < backoff/2 || wait > backoff {
+			t.Errorf("Expected the wait of attempt %d within [%s, %s], got %s", attempt, backoff/2, backoff, wait)
+		}
+	}
+}
+
+func TestWithRetry_Invalid(t *testing.T) {
+	if _, err := CreateConversationOptions("model", nil, WithRetry(-1, 0)); !errors.Is(err, ErrInvalidRetryPolicy) {
+		t.Errorf("Expected ErrInvalidRetryPolicy, got %v", err)
+	}
+	if _, err := CreateConversationOptions("model", nil, WithRetryBackoff(time.Second, time.Minute)); !errors.Is(err, ErrRetryPolicyNotSet) {
+		t.Errorf("Expected ErrRetryPolicyNotSet, got %v", err)
+	}
+}
@@ -0,0 +1,140 @@
+package supervisor
+
+import (
+	"errors"
+	"fmt"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/agent/parser"
+)
+
+const (
+	// DefaultMaxHandoffs is the default number of handoffs to the workers within an invocation.
+	DefaultMaxHandoffs = 10
+	// DefaultParseRetries is the default number of times the model is asked again when its choice is not a worker.
+	DefaultParseRetries = 2
+	// DefaultSystemPrompt is the default prompt of the supervisor, the %s verb is replaced by the list of the workers.
+	DefaultSystemPrompt = "You are a supervisor managing a conversation between the following workers:\n%s\n" +
+		"Given the conversation, choose the worker to act next. Each worker performs a task and reports back with its result. " +
+		"When the request of the user is fulfilled, answer " + Finish + "."
+)
+
+var (
+	// ErrInvalidMaxHandoffs indicates that the maximum number of handoffs is not positive.
+	ErrInvalidMaxHandoffs = errors.New("max handoffs must be at least 1")
+)
+
+// Options holds the configuration of a supervisor.
+type Options struct {
+	// SystemPrompt is the prompt of the supervisor, the %s verb is replaced by the list of the workers.
+	SystemPrompt string
+	// MaxHandoffs bounds the handoffs to the workers within an invocation.
+	MaxHandoffs int
+	// ParseRetries is the number of times the model is asked again when its choice is not a worker.
+	ParseRetries int
+	// HandoffMessage creates the message appended to the conversation when handing off to a worker, nil for none.
+	HandoffMessage func(worker string) a.Message
+}
+
+// Option is a functional option for configuring a supervisor.
+type Option interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - o: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(o *Options) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc func(*Options) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - o: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc) Apply(o *Options) error { return s(o) }
+
+// WithSystemPrompt sets the prompt of the supervisor.
+//
+// Parameters:
+//   - prompt: The prompt, the %s verb is replaced by the list of the workers.
+//
+// Returns:
+//   - An Option that sets the prompt.
+//
+// Example:
+//
+//	sup, err := supervisor.New("Supervisor", ask, workers,
+//	    supervisor.WithSystemPrompt("You lead a research team:\n%s\nPick who works next, or FINISH."))
+func WithSystemPrompt(prompt string) Option {
+	return OptionFunc(func(o *Options) error {
+		o.SystemPrompt = prompt
+		return nil
+	})
+}
+
+// WithMaxHandoffs bounds the handoffs to the workers within an invocation.
+//
+// The invocation fails with ErrMaxHandoffs when the supervisor chooses a worker once more.
+//
+// Parameters:
+//   - maxHandoffs: The maximum number of handoffs, it must be positive.
+//
+// Returns:
+//   - An Option that sets the bound.
+func WithMaxHandoffs(maxHandoffs int) Option {
+	return OptionFunc(func(o *Options) error {
+		if maxHandoffs < 1 {
+			return fmt.Errorf("%w: %d", ErrInvalidMaxHandoffs, maxHandoffs)
+		}
+		o.MaxHandoffs = maxHandoffs
+		return nil
+	})
+}
+
+// WithParseRetries sets the number of times the model is asked again when its choice is not a worker.
+//
+// Parameters:
+//   - retries: The number of retries, it must not be negative.
+//
+// Returns:
+//   - An Option that sets the retries.
+func WithParseRetries(retries int) Option {
+	return OptionFunc(func(o *Options) error {
+		if retries < 0 {
+			return fmt.Errorf("%w: %d", parser.ErrInvalidRetries, retries)
+		}
+		o.ParseRetries = retries
+		return nil
+	})
+}
+
+// WithHandoffMessage sets the message appended to the conversation when handing off to a worker.
+//
+// Parameters:
+//   - handoff: The function creating the message, nil to append no message.
+//
+// Returns:
+//   - An Option that sets the handoff message.
+//
+// Example:
+//
+//	sup, err := supervisor.New("Supervisor", ask, workers, supervisor.WithHandoffMessage(func(worker string) a.Message {
+//	    return a.CreateMessage(a.System, "The "+worker+" takes over.")
+//	}))
+func WithHandoffMessage(handoff func(worker string) a.Message) Option {
+	return OptionFunc(func(o *Options) error {
+		o.HandoffMessage = handoff
+		return nil
+	})
+}
+
+func defaultHandoffMessage(worker string) a.Message {
+	return a.CreateMessage(a.Assistant, fmt.Sprintf("Handing off to %s.", worker))
+}
@@ -0,0 +1,228 @@
+// Package supervisor provides the supervisor multi-agent topology.
+//
+// A supervisor is a routing agent: at each turn it asks the model which of the registered workers
+// acts next, hands the conversation off to it and receives the conversation back when the worker
+// is done, until the model decides that the request is fulfilled.
+//
+//	Start -> Supervisor -> Worker 1 -> Supervisor -> ... -> End
+//	                    \-> Worker 2 -/
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/agent/parser"
+	b "github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+const (
+	// Finish is the choice of the supervisor ending the invocation.
+	Finish = "FINISH"
+	// RouteMetadataKey is the conversation metadata key of the last choice of the supervisor.
+	RouteMetadataKey = "ggraph.supervisor.route"
+	// HandoffsMetadataKey is the conversation metadata key of the number of handoffs of the invocation.
+	HandoffsMetadataKey = "ggraph.supervisor.handoffs"
+	// RouteTagKey is the label key of the edges from the supervisor to its workers, the value is the worker name.
+	RouteTagKey = "supervisor_route"
+)
+
+var (
+	// ErrAskFnNil indicates that the supervisor has no function to call the model.
+	ErrAskFnNil = errors.New("ask function cannot be nil")
+	// ErrNoWorkers indicates that the supervisor has no worker.
+	ErrNoWorkers = errors.New("at least one worker is required")
+	// ErrInvalidWorker indicates that a worker has no node or a name which is not a single word.
+	ErrInvalidWorker = errors.New("worker requires a node and a single word name")
+	// ErrDuplicateWorker indicates that two workers have the same name.
+	ErrDuplicateWorker = errors.New("duplicate worker name")
+	// ErrMaxHandoffs indicates that the supervisor did not finish within the maximum number of handoffs.
+	ErrMaxHandoffs = errors.New("supervisor reached the maximum number of handoffs")
+)
+
+// Worker is an agent the supervisor can hand the conversation off to.
+type Worker struct {
+	// Name identifies the worker to the model, a single word like "Researcher".
+	Name string
+	// Description tells the model what the worker does.
+	Description string
+	// Node is the entry node of the worker; its outbound edge back to the supervisor is created
+	// by Edges, additional nodes of the worker, e.g. a tool node, are wired by the caller.
+	Node g.Node[a.Conversation]
+}
+
+// Supervisor is a routing agent delegating the conversation to its workers.
+type Supervisor struct {
+	node    g.Node[a.Conversation]
+	workers []Worker
+}
+
+// New creates a supervisor.
+//
+// The supervisor node asks the model, with the system prompt listing the workers followed by the
+// conversation, which worker acts next; the choice is parsed with parser.Enum, the invalid ones
+// being fed back to the model. The choice is recorded in the conversation metadata under
+// RouteMetadataKey and, unless disabled with WithHandoffMessage(nil), a handoff message is
+// appended to the conversation before routing to the worker. The user input is merged into the
+// conversation on the first turn of the invocation only, the handoff count being tracked in the
+// metadata under HandoffsMetadataKey: workers are expected to answer from the current
+// conversation and to preserve its metadata.
+//
+// Parameters:
+//   - name: The unique name for the supervisor node.
+//   - ask: The function calling the model.
+//   - workers: The workers.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - The Supervisor, whose Node and Edges are added to the runtime.
+//   - An error if the workers or the options are invalid.
+//
+// Example:
+//
+//	ask := func(ctx context.Context, messages []a.Message) (a.Message, error) {
+//	    opts, _ := a.CreateConversationOptions("gpt-4o-mini", messages)
+//	    return openai.ChatCompletion(ctx, client.Chat, opts)
+//	}
+//	sup, _ := supervisor.New("Supervisor", ask, []supervisor.Worker{
+//	    {Name: "Researcher", Description: "Searches the web", Node: researcher},
+//	    {Name: "Writer", Description: "Writes the final report", Node: writer},
+//	})
+//	runtime, _ := builders.CreateRuntime(builders.CreateStartEdge(sup.Node()), stateMonitorCh,
+//	    graph.WithAllowCycles[a.Conversation](2*supervisor.DefaultMaxHandoffs+1))
+//	runtime.AddEdge(sup.Edges()...)
+func New(name string, ask parser.AskFn, workers []Worker, opts ...Option) (*Supervisor, error) {
+	if ask == nil {
+		return nil, fmt.Errorf("supervisor creation error for name %s: %w", name, ErrAskFnNil)
+	}
+	if len(workers) == 0 {
+		return nil, fmt.Errorf("supervisor creation error for name %s: %w", name, ErrNoWorkers)
+	}
+	names := make([]string, 0, len(workers)+1)
+	for idx, worker := range workers {
+		if worker.Node == nil || worker.Name == "" || strings.ContainsFunc(worker.Name, isSeparator) || strings.EqualFold(worker.Name, Finish) {
+			return nil, fmt.Errorf("supervisor creation error for name %s: worker %d: %w", name, idx, ErrInvalidWorker)
+		}
+		if slices.ContainsFunc(names, func(n string) bool { return strings.EqualFold(n, worker.Name) }) {
+			return nil, fmt.Errorf("supervisor creation error for name %s: %w: %s", name, ErrDuplicateWorker, worker.Name)
+		}
+		names = append(names, worker.Name)
+	}
+
+	useOpts := &Options{
+		SystemPrompt:   DefaultSystemPrompt,
+		MaxHandoffs:    DefaultMaxHandoffs,
+		ParseRetries:   DefaultParseRetries,
+		HandoffMessage: defaultHandoffMessage,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("supervisor creation error for name %s: %w", name, err)
+		}
+	}
+
+	routingPolicy, err := b.CreateConditionalRoutePolicy(routeFn)
+	if err != nil {
+		return nil, fmt.Errorf("supervisor creation error for name %s: %w", name, err)
+	}
+
+	var roster strings.Builder
+	for _, worker := range workers {
+		fmt.Fprintf(&roster, "- %s: %s\n", worker.Name, worker.Description)
+	}
+	systemPrompt := fmt.Sprintf(useOpts.SystemPrompt, strings.TrimSuffix(roster.String(), "\n"))
+
+	node, err := b.NewContextNode(name,
+		superviseFn(ask, parser.Enum(append(names, Finish)...), systemPrompt, useOpts),
+		g.WithRoutingPolicy(routingPolicy))
+	if err != nil {
+		return nil, err
+	}
+	return &Supervisor{node: node, workers: append([]Worker{}, workers...)}, nil
+}
+
+// Node returns the supervisor node, the start node of the topology.
+//
+// Returns:
+//   - The supervisor node.
+func (s *Supervisor) Node() g.Node[a.Conversation] {
+	return s.node
+}
+
+// Edges returns the edges of the topology.
+//
+// The edges are the labelled edges from the supervisor to each worker, the edges from each
+// worker back to the supervisor and the end edge of the supervisor: the runtime must be
+// created with graph.WithAllowCycles, see the example of New.
+//
+// Returns:
+//   - The edges, to be added to the runtime.
+func (s *Supervisor) Edges() []g.Edge[a.Conversation] {
+	rv := make([]g.Edge[a.Conversation], 0, 2*len(s.workers)+1)
+	for _, worker := range s.workers {
+		rv = append(rv,
+			b.CreateEdge(s.node, worker.Node, map[string]string{RouteTagKey: worker.Name}),
+			b.CreateEdge(worker.Node, s.node),
+		)
+	}
+	return append(rv, b.CreateEndEdge(s.node))
+}
+
+func superviseFn(ask parser.AskFn, choices parser.Parser[string], systemPrompt string, opts *Options) g.ContextNodeFn[a.Conversation] {
+	return func(ctx context.Context, userInput, currentState a.Conversation, notify g.NotifyPartialFn[a.Conversation]) (a.Conversation, error) {
+		rv := currentState.Clone()
+		if rv.Metadata == nil {
+			rv.Metadata = make(map[string]string)
+		}
+		if _, handingOff := rv.Metadata[HandoffsMetadataKey]; !handingOff {
+			rv.Messages = append(rv.Messages, userInput.Messages...)
+		}
+		rv.CurrentToolCalls = nil
+
+		messages := append([]a.Message{a.CreateMessage(a.System, systemPrompt)}, rv.Messages...)
+		messages = append(messages, a.CreateMessage(a.User, choices.Instructions()))
+		choice, _, err := parser.ParseWithRetry(ctx, choices, messages, ask, opts.ParseRetries)
+		if err != nil {
+			return currentState, fmt.Errorf("supervisor cannot choose a worker: %w", err)
+		}
+
+		rv.Metadata[RouteMetadataKey] = choice
+		if choice == Finish {
+			delete(rv.Metadata, HandoffsMetadataKey)
+			return rv, nil
+		}
+
+		handoffs, _ := strconv.Atoi(rv.Metadata[HandoffsMetadataKey])
+		if handoffs >= opts.MaxHandoffs {
+			return currentState, fmt.Errorf("%w: %d", ErrMaxHandoffs, opts.MaxHandoffs)
+		}
+		rv.Metadata[HandoffsMetadataKey] = strconv.Itoa(handoffs + 1)
+		if opts.HandoffMessage != nil {
+			rv.Messages = append(rv.Messages, opts.HandoffMessage(choice))
+		}
+		return rv, nil
+	}
+}
+
+// routeFn follows the choice of the supervisor: the edge labelled with the chosen worker, or the unlabelled edge on Finish.
+func routeFn(userInput, currentState a.Conversation, edges []g.Edge[a.Conversation]) g.Edge[a.Conversation] {
+	choice := currentState.Metadata[RouteMetadataKey]
+	for _, edge := range edges {
+		worker, ok := edge.LabelByKey(RouteTagKey)
+		if (ok && worker == choice) || (!ok && choice == Finish) {
+			return edge
+		}
+	}
+	return nil
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-'
+}
@@ -0,0 +1,177 @@
+package supervisor_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/agent/parser"
+	"github.com/morphy76/ggraph/pkg/agent/supervisor"
+	"github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// scriptedAsk answers with the given choices in order, recording the requests.
+type scriptedAsk struct {
+	mu       sync.Mutex
+	choices  []string
+	requests [][]a.Message
+}
+
+func (s *scriptedAsk) ask(_ context.Context, messages []a.Message) (a.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.requests = append(s.requests, messages)
+	if len(s.choices) == 0 {
+		return a.Message{}, errors.New("no more choices")
+	}
+	choice := s.choices[0]
+	s.choices = s.choices[1:]
+	return a.CreateMessage(a.Assistant, choice), nil
+}
+
+func createWorker(t *testing.T, name string) supervisor.Worker {
+	node, err := builders.NewNode(name, func(userInput, currentState a.Conversation, notify g.NotifyPartialFn[a.Conversation]) (a.Conversation, error) {
+		rv := currentState.Clone()
+		rv.Messages = append(rv.Messages, a.CreateMessage(a.Assistant, name+" done"))
+		return rv, nil
+	})
+	if err != nil {
+		t.Fatalf("NewNode failed: %v", err)
+	}
+	return supervisor.Worker{Name: name, Description: "The " + name, Node: node}
+}
+
+func run(t *testing.T, sup *supervisor.Supervisor, question string) g.StateMonitorEntry[a.Conversation] {
+	stateMonitorCh := make(chan g.StateMonitorEntry[a.Conversation], 100)
+	runtime, err := builders.CreateRuntime(builders.CreateStartEdge(sup.Node()), stateMonitorCh,
+		g.WithAllowCycles[a.Conversation](2*supervisor.DefaultMaxHandoffs+1))
+	if err != nil {
+		t.Fatalf("CreateRuntime failed: %v", err)
+	}
+	defer runtime.Shutdown()
+	runtime.AddEdge(sup.Edges()...)
+	if err := runtime.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+
+	runtime.Invoke(a.CreateConversation(a.CreateMessage(a.User, question)))
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case entry := <-stateMonitorCh:
+			if !entry.Running {
+				return entry
+			}
+		case <-timeout:
+			t.Fatal("timeout waiting for the supervisor")
+		}
+	}
+}
+
+func TestSupervisor_HandsOffUntilFinish(t *testing.T) {
+	script := &scriptedAsk{choices: []string{"Researcher", "I pick writer.", "FINISH"}}
+	sup, err := supervisor.New("Supervisor", script.ask, []supervisor.Worker{
+		createWorker(t, "Researcher"),
+		createWorker(t, "Writer"),
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	final := run(t, sup, "Write a report")
+	if final.Error != nil {
+		t.Fatalf("unexpected error: %v", final.Error)
+	}
+
+	var contents []string
+	for _, message := range final.NewState.Messages {
+		contents = append(contents, message.Content)
+	}
+	expected := []string{"Write a report", "Handing off to Researcher.", "Researcher done", "Handing off to Writer.", "Writer done"}
+	if strings.Join(contents, "|") != strings.Join(expected, "|") {
+		t.Errorf("expected %v, got %v", expected, contents)
+	}
+	if route := final.NewState.Metadata[supervisor.RouteMetadataKey]; route != supervisor.Finish {
+		t.Errorf("expected the last route to be %s, got %s", supervisor.Finish, route)
+	}
+
+	system := script.requests[0][0]
+	if system.Role != a.System || !strings.Contains(system.Content, "- Researcher: The Researcher") || !strings.Contains(system.Content, "- Writer: The Writer") {
+		t.Errorf("expected the workers in the system prompt, got %q", system.Content)
+	}
+	if len(script.requests[2]) != len(final.NewState.Messages)+2 {
+		t.Errorf("expected the supervisor to see the whole conversation, got %d messages", len(script.requests[2]))
+	}
+}
+
+func TestSupervisor_RetriesInvalidChoice(t *testing.T) {
+	script := &scriptedAsk{choices: []string{"the coder", "FINISH"}}
+	sup, err := supervisor.New("Supervisor", script.ask, []supervisor.Worker{createWorker(t, "Researcher")},
+		supervisor.WithHandoffMessage(nil))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	final := run(t, sup, "hello")
+	if final.Error != nil {
+		t.Fatalf("unexpected error: %v", final.Error)
+	}
+	if len(script.requests) != 2 {
+		t.Fatalf("expected a retry, got %d requests", len(script.requests))
+	}
+	feedback := script.requests[1][len(script.requests[1])-1]
+	if !strings.Contains(feedback.Content, "not valid") {
+		t.Errorf("expected the parse error to be fed back, got %q", feedback.Content)
+	}
+	if len(final.NewState.Messages) != 1 {
+		t.Errorf("expected only the user message, got %+v", final.NewState.Messages)
+	}
+}
+
+func TestSupervisor_MaxHandoffs(t *testing.T) {
+	script := &scriptedAsk{choices: []string{"Researcher", "Researcher", "Researcher"}}
+	sup, err := supervisor.New("Supervisor", script.ask, []supervisor.Worker{createWorker(t, "Researcher")},
+		supervisor.WithMaxHandoffs(2))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	final := run(t, sup, "loop")
+	if !errors.Is(final.Error, supervisor.ErrMaxHandoffs) {
+		t.Errorf("expected ErrMaxHandoffs, got %v", final.Error)
+	}
+}
+
+func TestNew_Validation(t *testing.T) {
+	ask := (&scriptedAsk{}).ask
+	worker := createWorker(t, "Researcher")
+
+	tests := []struct {
+		name     string
+		ask      parser.AskFn
+		workers  []supervisor.Worker
+		opts     []supervisor.Option
+		expected error
+	}{
+		{"nil ask", nil, []supervisor.Worker{worker}, nil, supervisor.ErrAskFnNil},
+		{"no workers", ask, nil, nil, supervisor.ErrNoWorkers},
+		{"no node", ask, []supervisor.Worker{{Name: "Writer"}}, nil, supervisor.ErrInvalidWorker},
+		{"multi word name", ask, []supervisor.Worker{{Name: "The Writer", Node: worker.Node}}, nil, supervisor.ErrInvalidWorker},
+		{"finish name", ask, []supervisor.Worker{{Name: "finish", Node: worker.Node}}, nil, supervisor.ErrInvalidWorker},
+		{"duplicate", ask, []supervisor.Worker{worker, worker}, nil, supervisor.ErrDuplicateWorker},
+		{"max handoffs", ask, []supervisor.Worker{worker}, []supervisor.Option{supervisor.WithMaxHandoffs(0)}, supervisor.ErrInvalidMaxHandoffs},
+		{"parse retries", ask, []supervisor.Worker{worker}, []supervisor.Option{supervisor.WithParseRetries(-1)}, parser.ErrInvalidRetries},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := supervisor.New("Supervisor", tt.ask, tt.workers, tt.opts...); !errors.Is(err, tt.expected) {
+				t.Errorf("expected %v, got %v", tt.expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,145 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// ErrSystemPromptEmpty indicates that a system prompt has no content.
+var ErrSystemPromptEmpty = errors.New("system prompt cannot be empty")
+
+// SystemPromptMode defines how a system prompt changes the system messages of the conversation.
+type SystemPromptMode int
+
+const (
+	// ReplaceSystemPrompt sends the system prompt instead of the system messages leading the conversation.
+	ReplaceSystemPrompt SystemPromptMode = iota
+	// AugmentSystemPrompt sends the system prompt after the system messages leading the conversation.
+	AugmentSystemPrompt
+)
+
+// SystemPrompt is a system prompt applied to the messages sent to the model, the conversation state is left untouched.
+//
+// The summaries of the conversation memory, see SummaryPrefix, are never replaced.
+type SystemPrompt struct {
+	// Content is the text of the system prompt.
+	Content string
+	// Mode is how the system prompt changes the system messages of the conversation.
+	Mode SystemPromptMode
+}
+
+// SystemPromptValue is the invocation value overriding the system prompt of the conversation nodes.
+//
+// The invocation value takes precedence over the system prompt of the conversation and over the
+// one of the node, see CompactMessages; only the nodes given the invocation context, like the
+// tool agent and the failover nodes, see it.
+//
+// Example usage:
+//
+//	runtime.Invoke(userInput, g.InvokeConfigValue(a.SystemPromptValue, a.SystemPrompt{Content: tenant.Persona}))
+var SystemPromptValue = g.NewValueKey[SystemPrompt]("agent.system_prompt")
+
+// WithSystemPrompt sets the system prompt the node applies to the messages sent to the model.
+//
+// The prompt of the node is overridden by the one of the conversation, see Conversation.SystemPrompt,
+// and by the one of the invocation, see SystemPromptValue.
+//
+// Parameters:
+//   - prompt: The system prompt.
+//
+// Returns:
+//   - A ModelOption that sets the system prompt.
+//
+// Example usage:
+//
+//	option := WithSystemPrompt(SystemPrompt{Content: "Answer in one sentence.", Mode: AugmentSystemPrompt})
+func WithSystemPrompt(prompt SystemPrompt) ModelOption {
+	return ModelOptionFunc(func(r *ModelOptions) error {
+		if strings.TrimSpace(prompt.Content) == "" {
+			return ErrSystemPromptEmpty
+		}
+		r.SystemPrompt = &prompt
+		return nil
+	})
+}
+
+// OverrideSystemPrompt overrides the system prompt of the options with the one of the first conversation having one.
+//
+// The conversation nodes call it with their user input and their current state, so that the
+// system prompt of an invocation, or the one set by a node preceding them, wins over the prompt
+// of the node.
+//
+// Parameters:
+//   - opts: The model options.
+//   - conversations: The conversations, by precedence.
+//
+// Example usage:
+//
+//	useOpts, err := a.CreateConversationOptions(model, nil, modelOptions...)
+//	a.OverrideSystemPrompt(useOpts, userInput, currentState)
+func OverrideSystemPrompt(opts *ModelOptions, conversations ...Conversation) {
+	for _, conversation := range conversations {
+		if conversation.SystemPrompt != nil {
+			prompt := *conversation.SystemPrompt
+			opts.SystemPrompt = &prompt
+			return
+		}
+	}
+}
+
+// ApplySystemPrompt returns the messages with the system prompt applied, the given messages are left untouched.
+//
+// Parameters:
+//   - messages: The messages of the conversation.
+//   - prompt: The system prompt, nil or empty to send the messages as they are.
+//
+// Returns:
+//   - The messages to send to the model.
+//
+// Example usage:
+//
+//	messages := ApplySystemPrompt(conversation.Messages, &SystemPrompt{Content: "You are a pirate."})
+func ApplySystemPrompt(messages []Message, prompt *SystemPrompt) []Message {
+	if prompt == nil || strings.TrimSpace(prompt.Content) == "" {
+		return messages
+	}
+
+	head := 0
+	for head < len(messages) && messages[head].Role == System {
+		head++
+	}
+	rv := make([]Message, 0, len(messages)+1)
+	promptMessage := CreateMessage(System, prompt.Content)
+	switch prompt.Mode {
+	case AugmentSystemPrompt:
+		at := 0
+		for idx, message := range messages[:head] {
+			if !strings.HasPrefix(message.Content, SummaryPrefix) {
+				at = idx + 1
+			}
+		}
+		rv = append(rv, messages[:at]...)
+		rv = append(rv, promptMessage)
+		rv = append(rv, messages[at:]...)
+	default:
+		rv = append(rv, promptMessage)
+		for _, message := range messages[:head] {
+			if strings.HasPrefix(message.Content, SummaryPrefix) {
+				rv = append(rv, message)
+			}
+		}
+		rv = append(rv, messages[head:]...)
+	}
+	return slices.Clip(rv)
+}
+
+func systemPromptOf(ctx context.Context, opts *ModelOptions) *SystemPrompt {
+	if prompt, ok := g.ValueFrom(ctx, SystemPromptValue); ok {
+		return &prompt
+	}
+	return opts.SystemPrompt
+}
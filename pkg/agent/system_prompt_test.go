@@ -0,0 +1,83 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func TestApplySystemPrompt(t *testing.T) {
+	messages := []Message{
+		CreateMessage(System, "You are an assistant."),
+		CreateMessage(System, "Be polite."),
+		CreateMessage(System, SummaryPrefix+"the user asked about Rome"),
+		CreateMessage(User, "And Milan?"),
+	}
+
+	tests := []struct {
+		name   string
+		prompt *SystemPrompt
+		want   string
+	}{
+		{name: "no prompt", prompt: nil, want: "You are an assistant.,Be polite.," + SummaryPrefix + "the user asked about Rome,And Milan?"},
+		{name: "empty prompt", prompt: &SystemPrompt{Content: " "}, want: "You are an assistant.,Be polite.," + SummaryPrefix + "the user asked about Rome,And Milan?"},
+		{name: "replace", prompt: &SystemPrompt{Content: "You are a pirate."}, want: "You are a pirate.," + SummaryPrefix + "the user asked about Rome,And Milan?"},
+		{name: "augment", prompt: &SystemPrompt{Content: "Answer in Italian.", Mode: AugmentSystemPrompt}, want: "You are an assistant.,Be polite.,Answer in Italian.," + SummaryPrefix + "the user asked about Rome,And Milan?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ApplySystemPrompt(messages, tt.prompt)
+			if contents(got) != tt.want {
+				t.Errorf("ApplySystemPrompt() = %s, want %s", contents(got), tt.want)
+			}
+			if len(messages) != 4 || messages[0].Content != "You are an assistant." {
+				t.Errorf("Expected the messages to be left untouched, got %s", contents(messages))
+			}
+		})
+	}
+
+	got := ApplySystemPrompt([]Message{CreateMessage(User, "Hi")}, &SystemPrompt{Content: "Be brief.", Mode: AugmentSystemPrompt})
+	if contents(got) != "Be brief.,Hi" || got[0].Role != System {
+		t.Errorf("Expected the prompt to lead a conversation without system messages, got %s", contents(got))
+	}
+}
+
+func TestWithSystemPrompt(t *testing.T) {
+	if _, err := CreateConversationOptions("gpt", nil, WithSystemPrompt(SystemPrompt{})); !errors.Is(err, ErrSystemPromptEmpty) {
+		t.Errorf("Expected ErrSystemPromptEmpty, got %v", err)
+	}
+
+	opts, err := CreateConversationOptions("gpt", nil, WithSystemPrompt(SystemPrompt{Content: "node"}))
+	if err != nil {
+		t.Fatalf("CreateConversationOptions failed: %v", err)
+	}
+	OverrideSystemPrompt(opts, Conversation{}, Conversation{SystemPrompt: &SystemPrompt{Content: "state"}})
+	if opts.SystemPrompt.Content != "state" {
+		t.Errorf("Expected the conversation prompt to override the node one, got %+v", opts.SystemPrompt)
+	}
+	OverrideSystemPrompt(opts, Conversation{SystemPrompt: &SystemPrompt{Content: "input"}}, Conversation{SystemPrompt: &SystemPrompt{Content: "state"}})
+	if opts.SystemPrompt.Content != "input" {
+		t.Errorf("Expected the first conversation prompt to win, got %+v", opts.SystemPrompt)
+	}
+
+	messages := []Message{CreateMessage(System, "original"), CreateMessage(User, "Hi")}
+	got, err := CompactMessages(context.Background(), opts, messages)
+	if err != nil {
+		t.Fatalf("CompactMessages failed: %v", err)
+	}
+	if contents(got) != "input,Hi" {
+		t.Errorf("Expected the options prompt to be applied, got %s", contents(got))
+	}
+
+	ctx := g.ContextWithValue(context.Background(), SystemPromptValue, SystemPrompt{Content: "tenant", Mode: AugmentSystemPrompt})
+	got, err = CompactMessages(ctx, opts, messages)
+	if err != nil {
+		t.Fatalf("CompactMessages failed: %v", err)
+	}
+	if contents(got) != "original,tenant,Hi" {
+		t.Errorf("Expected the invocation prompt to override the options one, got %s", contents(got))
+	}
+}
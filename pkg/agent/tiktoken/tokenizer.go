@@ -0,0 +1,90 @@
+// Package tiktoken provides agent.Tokenizer implementations backed by the OpenAI tiktoken encodings.
+//
+// The encodings are loaded by github.com/pkoukk/tiktoken-go, which downloads and caches the BPE
+// ranks on first use; set TIKTOKEN_CACHE_DIR or a custom loader with tiktoken.SetBpeLoader to run offline.
+package tiktoken
+
+import (
+	"errors"
+	"fmt"
+
+	tk "github.com/pkoukk/tiktoken-go"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+)
+
+const (
+	// CL100KBase is the encoding of the GPT-4 and GPT-3.5 models.
+	CL100KBase = "cl100k_base"
+	// O200KBase is the encoding of the GPT-4o models.
+	O200KBase = "o200k_base"
+)
+
+// ErrEncodingNotAvailable indicates that the requested encoding could not be loaded.
+var ErrEncodingNotAvailable = errors.New("tiktoken encoding not available")
+
+var _ a.Tokenizer = (*Tokenizer)(nil)
+
+// Tokenizer counts tokens with a tiktoken encoding.
+type Tokenizer struct {
+	encoding *tk.Tiktoken
+}
+
+// NewTokenizer creates a Tokenizer for the given encoding.
+//
+// Parameters:
+//   - encoding: The encoding name, e.g. CL100KBase.
+//
+// Returns:
+//   - The Tokenizer.
+//   - An error if the encoding is unknown or cannot be loaded.
+//
+// Example:
+//
+//	tokenizer, err := tiktoken.NewTokenizer(tiktoken.CL100KBase)
+//	tokens := agent.CountPromptTokens(tokenizer, conversation.Messages)
+func NewTokenizer(encoding string) (*Tokenizer, error) {
+	useEncoding, err := tk.GetEncoding(encoding)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s: %v", ErrEncodingNotAvailable, encoding, err)
+	}
+	return &Tokenizer{encoding: useEncoding}, nil
+}
+
+// NewTokenizerForModel creates a Tokenizer with the encoding of the given OpenAI model.
+//
+// Parameters:
+//   - model: The model name, e.g. "gpt-4o-mini".
+//
+// Returns:
+//   - The Tokenizer.
+//   - An error if the model is unknown or its encoding cannot be loaded.
+func NewTokenizerForModel(model string) (*Tokenizer, error) {
+	useEncoding, err := tk.EncodingForModel(model)
+	if err != nil {
+		return nil, fmt.Errorf("%w: model %s: %v", ErrEncodingNotAvailable, model, err)
+	}
+	return &Tokenizer{encoding: useEncoding}, nil
+}
+
+// Encode returns the tokens of the text, special tokens are encoded as plain text.
+//
+// Parameters:
+//   - text: The text to encode.
+//
+// Returns:
+//   - The token identifiers.
+func (t *Tokenizer) Encode(text string) []int {
+	return t.encoding.EncodeOrdinary(text)
+}
+
+// CountTokens returns the number of tokens of the text.
+//
+// Parameters:
+//   - text: The text to encode.
+//
+// Returns:
+//   - The number of tokens.
+func (t *Tokenizer) CountTokens(text string) int {
+	return len(t.Encode(text))
+}
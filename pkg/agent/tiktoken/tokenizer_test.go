@@ -0,0 +1,58 @@
+package tiktoken_test
+
+import (
+	"errors"
+	"testing"
+
+	tk "github.com/pkoukk/tiktoken-go"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/agent/tiktoken"
+)
+
+// byteLoader serves byte-level ranks with a single merge, so that the tests run offline.
+type byteLoader struct{}
+
+func (byteLoader) LoadTiktokenBpe(string) (map[string]int, error) {
+	ranks := make(map[string]int, 257)
+	for i := 0; i < 256; i++ {
+		ranks[string([]byte{byte(i)})] = i
+	}
+	ranks["he"] = 256
+	return ranks, nil
+}
+
+func init() {
+	tk.SetBpeLoader(byteLoader{})
+}
+
+func TestTokenizer(t *testing.T) {
+	tokenizer, err := tiktoken.NewTokenizer(tiktoken.CL100KBase)
+	if err != nil {
+		t.Fatalf("NewTokenizer failed: %v", err)
+	}
+
+	if got := tokenizer.CountTokens("hello"); got != 4 {
+		t.Errorf("Expected 4 tokens, got %d", got)
+	}
+	if got := tokenizer.Encode("he"); len(got) != 1 || got[0] != 256 {
+		t.Errorf("Expected the merged token, got %v", got)
+	}
+
+	messages := []a.Message{a.CreateMessage(a.User, "hello")}
+	if got := a.CountPromptTokens(tokenizer, messages); got != 3+4+4 {
+		t.Errorf("Expected 11 prompt tokens, got %d", got)
+	}
+}
+
+func TestNewTokenizer_Unknown(t *testing.T) {
+	if _, err := tiktoken.NewTokenizer("unknown"); !errors.Is(err, tiktoken.ErrEncodingNotAvailable) {
+		t.Errorf("Expected ErrEncodingNotAvailable, got %v", err)
+	}
+	if _, err := tiktoken.NewTokenizerForModel("unknown-model"); !errors.Is(err, tiktoken.ErrEncodingNotAvailable) {
+		t.Errorf("Expected ErrEncodingNotAvailable, got %v", err)
+	}
+	if _, err := tiktoken.NewTokenizerForModel("gpt-4o"); err != nil {
+		t.Errorf("Expected the gpt-4o encoding, got %v", err)
+	}
+}
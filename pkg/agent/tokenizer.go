@@ -0,0 +1,182 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	// messageOverheadTokens is the number of tokens framing each message of a chat prompt.
+	messageOverheadTokens = 4
+	// replyPrimingTokens is the number of tokens priming the reply of the model.
+	replyPrimingTokens = 3
+	// mediaPartTokens is the estimated number of tokens of an image or audio part.
+	mediaPartTokens = 85
+)
+
+var (
+	// ErrInvalidContextBudget indicates that a context budget does not allow at least one token.
+	ErrInvalidContextBudget = errors.New("context budget must be at least 1 token")
+	// ErrContextBudgetExceeded indicates that the conversation does not fit the context budget even after compaction.
+	ErrContextBudgetExceeded = errors.New("conversation exceeds the context budget")
+)
+
+// Tokenizer counts the tokens of a text, as the model would encode it.
+//
+// The tiktoken subpackage provides the OpenAI encodings; ApproximateTokenizer is a dependency-free estimation.
+type Tokenizer interface {
+	// CountTokens returns the number of tokens of the text.
+	//
+	// Parameters:
+	//   - text: The text to encode.
+	//
+	// Returns:
+	//   - The number of tokens.
+	CountTokens(text string) int
+}
+
+// TokenizerFunc is a function type that implements the Tokenizer interface.
+type TokenizerFunc func(text string) int
+
+// CountTokens calls the TokenizerFunc.
+//
+// Parameters:
+//   - text: The text to encode.
+//
+// Returns:
+//   - The number of tokens.
+func (f TokenizerFunc) CountTokens(text string) int { return f(text) }
+
+// ApproximateTokenizer estimates one token every four characters.
+var ApproximateTokenizer Tokenizer = TokenizerFunc(func(text string) int {
+	return (len(text) + 3) / 4
+})
+
+// CountMessageTokens returns the tokens of a message in a chat prompt, framing included.
+//
+// Parameters:
+//   - tokenizer: The tokenizer of the model.
+//   - message: The message to count.
+//
+// Returns:
+//   - The number of tokens.
+func CountMessageTokens(tokenizer Tokenizer, message Message) int {
+	rv := messageOverheadTokens + tokenizer.CountTokens(message.Content) + mediaTokens(message)
+	for _, call := range message.ToolCalls {
+		rv += tokenizer.CountTokens(call.ToolName)
+		for name, value := range call.Arguments {
+			rv += tokenizer.CountTokens(name) + tokenizer.CountTokens(fmt.Sprint(value))
+		}
+	}
+	return rv
+}
+
+// CountPromptTokens returns the tokens of the prompt made of the given messages.
+//
+// Parameters:
+//   - tokenizer: The tokenizer of the model.
+//   - messages: The messages of the conversation.
+//
+// Returns:
+//   - The number of tokens.
+//
+// Example:
+//
+//	tokens := agent.CountPromptTokens(tokenizer, conversation.Messages)
+func CountPromptTokens(tokenizer Tokenizer, messages []Message) int {
+	rv := replyPrimingTokens
+	for _, message := range messages {
+		rv += CountMessageTokens(tokenizer, message)
+	}
+	return rv
+}
+
+// TokenCounterFor adapts a tokenizer to a TokenCounter, e.g. to size a NewTokenWindow with the model encoding.
+//
+// Parameters:
+//   - tokenizer: The tokenizer of the model.
+//
+// Returns:
+//   - The TokenCounter.
+func TokenCounterFor(tokenizer Tokenizer) TokenCounter {
+	return func(message Message) int {
+		return CountMessageTokens(tokenizer, message)
+	}
+}
+
+// ContextBudget bounds the prompt sent to the model.
+type ContextBudget struct {
+	// MaxTokens is the maximum number of prompt tokens.
+	MaxTokens int
+	// Tokenizer counts the tokens, ApproximateTokenizer if nil.
+	Tokenizer Tokenizer
+	// Overflow compacts the conversation when it exceeds the budget, e.g. a summary memory;
+	// the oldest turns are trimmed afterwards if it still does not fit.
+	Overflow ConversationMemory
+}
+
+// WithContextBudget enforces a maximum prompt size, compacting the messages before the request.
+//
+// Parameters:
+//   - budget: The context budget.
+//
+// Returns:
+//   - A ModelOption that sets the context budget.
+//
+// Example usage:
+//
+//	summary, _ := agent.NewSummaryMemory(4, summarizer)
+//	option := WithContextBudget(ContextBudget{MaxTokens: 8000, Tokenizer: tokenizer, Overflow: summary})
+func WithContextBudget(budget ContextBudget) ModelOption {
+	return ModelOptionFunc(func(r *ModelOptions) error {
+		if budget.MaxTokens < 1 {
+			return ErrInvalidContextBudget
+		}
+		if budget.Tokenizer == nil {
+			budget.Tokenizer = ApproximateTokenizer
+		}
+		r.ContextBudget = &budget
+		return nil
+	})
+}
+
+func (b *ContextBudget) enforce(ctx context.Context, messages []Message) ([]Message, error) {
+	if CountPromptTokens(b.Tokenizer, messages) <= b.MaxTokens {
+		return messages, nil
+	}
+
+	rv := messages
+	if b.Overflow != nil {
+		var err error
+		rv, err = b.Overflow.Compact(ctx, rv)
+		if err != nil {
+			return messages, err
+		}
+		if CountPromptTokens(b.Tokenizer, rv) <= b.MaxTokens {
+			return rv, nil
+		}
+	}
+
+	window, err := NewTokenWindow(max(b.MaxTokens-replyPrimingTokens, 1), TokenCounterFor(b.Tokenizer))
+	if err != nil {
+		return messages, err
+	}
+	rv, _ = window.Compact(ctx, rv)
+
+	if tokens := CountPromptTokens(b.Tokenizer, rv); tokens > b.MaxTokens {
+		return messages, fmt.Errorf("%w: %d tokens over %d", ErrContextBudgetExceeded, tokens, b.MaxTokens)
+	}
+	return rv, nil
+}
+
+// mediaTokens estimates the tokens of the non-text parts of a message.
+func mediaTokens(message Message) int {
+	rv := 0
+	for _, part := range message.Parts {
+		if part.Kind != TextPart {
+			rv += mediaPartTokens
+		}
+	}
+	return rv
+}
@@ -0,0 +1,88 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// charTokenizer counts one token per character, to keep the arithmetic readable.
+var charTokenizer = TokenizerFunc(func(text string) int { return len(text) })
+
+func TestCountPromptTokens(t *testing.T) {
+	messages := []Message{CreateMessage(System, "abc"), CreateMessage(User, "de")}
+	if got := CountPromptTokens(charTokenizer, messages); got != replyPrimingTokens+2*messageOverheadTokens+5 {
+		t.Errorf("Unexpected prompt tokens %d", got)
+	}
+	if got := ApproximateTokenizer.CountTokens("12345678"); got != 2 {
+		t.Errorf("Expected 2 approximate tokens, got %d", got)
+	}
+}
+
+func TestWithContextBudget(t *testing.T) {
+	if _, err := CreateConversationOptions("m", nil, WithContextBudget(ContextBudget{})); !errors.Is(err, ErrInvalidContextBudget) {
+		t.Errorf("Expected ErrInvalidContextBudget, got %v", err)
+	}
+
+	opts, _ := CreateConversationOptions("m", nil, WithContextBudget(ContextBudget{MaxTokens: 10}))
+	if opts.ContextBudget.Tokenizer == nil {
+		t.Error("Expected the approximate tokenizer by default")
+	}
+}
+
+func TestContextBudget_Enforce(t *testing.T) {
+	// Each message costs 4 + 2 tokens, the prompt 3 more.
+	messages := []Message{
+		CreateMessage(System, "sp"),
+		CreateMessage(User, "u1"),
+		CreateMessage(Assistant, "a1"),
+		CreateMessage(User, "u2"),
+	}
+
+	tests := []struct {
+		name      string
+		maxTokens int
+		overflow  ConversationMemory
+		want      string
+		wantErr   error
+	}{
+		{name: "fits", maxTokens: 27, want: "sp,u1,a1,u2"},
+		{name: "trims the oldest turns", maxTokens: 26, want: "sp,u2"},
+		{name: "does not fit", maxTokens: 10, wantErr: ErrContextBudgetExceeded},
+		{
+			name:      "overflow memory first",
+			maxTokens: 26,
+			overflow: ConversationMemoryFunc(func(ctx context.Context, messages []Message) ([]Message, error) {
+				return append([]Message{CreateMessage(System, "s")}, messages[3:]...), nil
+			}),
+			want: "s,u2",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts, err := CreateConversationOptions("m", messages, WithContextBudget(ContextBudget{
+				MaxTokens: tt.maxTokens,
+				Tokenizer: charTokenizer,
+				Overflow:  tt.overflow,
+			}))
+			if err != nil {
+				t.Fatalf("CreateConversationOptions failed: %v", err)
+			}
+
+			got, err := CompactMessages(context.Background(), opts, opts.Messages)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("CompactMessages failed: %v", err)
+			}
+			if contents(got) != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, contents(got))
+			}
+		})
+	}
+}
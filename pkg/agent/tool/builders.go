@@ -1,9 +1,13 @@
 package tool
 
 import (
+	"context"
 	"reflect"
 	"runtime"
+	"slices"
 	"strings"
+
+	"github.com/morphy76/ggraph/internal/agent/schema"
 )
 
 // ExecFn represents a generic function type that can be used as a tool.
@@ -24,7 +28,9 @@ type ExecFn any
 // CreateTool creates a new Tool instance from the provided function and descriptors.
 //
 // T is the return type of the tool function along with an error.
-// The tool function must have the signature: func(args...) (T, error)
+// The tool function must have the signature: func(args...) (T, error); when its first
+// argument is a context.Context, the context of the call is passed to it and it is not
+// exposed as a tool argument.
 //
 // Common descriptors roles could include:
 //   - "Prompt": A brief description of the tool's purpose.
@@ -47,35 +53,23 @@ type ExecFn any
 func CreateTool[T any](fn ExecFn, descriptors ...string) (*Tool, error) {
 	// TODO fn is a builder which accepts builder args (pooled http clients, dbconnections,...) and returns a ExecFn
 
-	toolDesc := make(map[string]string, len(descriptors))
-	for _, desc := range descriptors {
-		parts := strings.Split(desc, ":")
-
-		if len(parts) < 2 {
-			return nil, ErrInvalidDescriptorFormat
-		}
-
-		role := strings.ToLower(strings.TrimSpace(parts[0]))
-		value := strings.TrimSpace(strings.Join(parts[1:], ":"))
-
-		toolDesc[role] = value
+	toolDesc, err := parseDescriptors(descriptors...)
+	if err != nil {
+		return nil, err
 	}
 
-	fnType := reflect.TypeOf(fn)
-	fnValue := reflect.ValueOf(fn)
-
-	if fnType.Kind() != reflect.Func {
-		return nil, ErrToolFnNotFunction
+	fnType, fnValue, err := checkToolFn(fn)
+	if err != nil {
+		return nil, err
 	}
 
-	if fnType.NumOut() != 2 {
-		return nil, ErrToolFnInvalidReturnCount
-	}
-	if !fnType.Out(1).Implements(reflect.TypeOf((*error)(nil)).Elem()) {
-		return nil, ErrToolFnInvalidReturnCount
+	withContext := acceptsContext(fnType)
+	offset := 0
+	if withContext {
+		offset = 1
 	}
 
-	toolFn := callable{fn: fnValue, in: fnType.NumIn()}
+	toolFn := callable{fn: fnValue, in: fnType.NumIn() - offset, withContext: withContext}
 
 	var t T
 	toolName := extractToolName(fn, reflect.TypeOf(t))
@@ -86,9 +80,9 @@ func CreateTool[T any](fn ExecFn, descriptors ...string) (*Tool, error) {
 		callable:     toolFn,
 	}
 
-	args := make([]Arg, fnType.NumIn())
-	for i := 0; i < fnType.NumIn(); i++ {
-		argType := fnType.In(i)
+	args := make([]Arg, toolFn.in)
+	for i := 0; i < toolFn.in; i++ {
+		argType := fnType.In(i + offset)
 		args[i] = Arg{
 			Name: rv.InputNameByIdx(i),
 			Type: argType.String(),
@@ -100,6 +94,241 @@ func CreateTool[T any](fn ExecFn, descriptors ...string) (*Tool, error) {
 	return rv, nil
 }
 
+// CreateToolFromStruct creates a new Tool whose parameters are described by a tagged struct.
+//
+// The tool function must have the signature: func(P) (T, error) or func(*P) (T, error), where P
+// is the type of params, optionally preceded by a context.Context argument. Each exported field of P becomes a tool argument: its name comes from the
+// json tag, its description from the desc tag, and it is required unless tagged omitempty.
+// The resulting JSON schema is available through Tool.Schema.
+//
+// Parameters:
+//   - fn: The function to be wrapped as a tool.
+//   - params: A value (or pointer) of the parameters struct, used only for its type.
+//   - descriptors: A variable number of strings describing the tool's purpose and usage in the format "role:description".
+//
+// Returns:
+//   - *Tool: A pointer to the created Tool instance.
+//   - error: An error if the function or the parameters are not valid or descriptors are incorrectly formatted.
+//
+// Example:
+//
+//	type WeatherParams struct {
+//	    City  string `json:"city" desc:"The city to get the weather for"`
+//	    Units string `json:"units,omitempty" desc:"celsius or fahrenheit"`
+//	}
+//
+//	weatherTool, err := tool.CreateToolFromStruct(getWeather, WeatherParams{}, "Prompt: Get the current weather")
+func CreateToolFromStruct(fn ExecFn, params any, descriptors ...string) (*Tool, error) {
+	toolDesc, err := parseDescriptors(descriptors...)
+	if err != nil {
+		return nil, err
+	}
+
+	paramsType := reflect.TypeOf(params)
+	if paramsType == nil {
+		return nil, ErrToolParamsNotStruct
+	}
+	for paramsType.Kind() == reflect.Pointer {
+		paramsType = paramsType.Elem()
+	}
+	if paramsType.Kind() != reflect.Struct {
+		return nil, ErrToolParamsNotStruct
+	}
+
+	fnType, fnValue, err := checkToolFn(fn)
+	if err != nil {
+		return nil, err
+	}
+	withContext := acceptsContext(fnType)
+	offset := 0
+	if withContext {
+		offset = 1
+	}
+	if fnType.NumIn()-offset != 1 {
+		return nil, ErrToolFnParamsMismatch
+	}
+	paramsPtr := false
+	switch fnType.In(offset) {
+	case paramsType:
+	case reflect.PointerTo(paramsType):
+		paramsPtr = true
+	default:
+		return nil, ErrToolFnParamsMismatch
+	}
+
+	fields := schema.Fields(paramsType)
+	args := make([]Arg, len(fields))
+	argNames := make(map[int]string, len(fields))
+	required := make([]string, 0, len(fields))
+	for i, f := range fields {
+		desc, _ := f.Schema["description"].(string)
+		args[i] = Arg{
+			Name:        f.Name,
+			Type:        paramsType.Field(f.Index).Type.String(),
+			Description: desc,
+		}
+		argNames[i] = f.Name
+		if f.Required {
+			required = append(required, f.Name)
+		}
+	}
+
+	return &Tool{
+		Name:         extractToolName(fn, nil),
+		Args:         args,
+		descriptions: toolDesc,
+		callable: callable{
+			fn:          fnValue,
+			in:          len(fields),
+			withContext: withContext,
+			params:      paramsType,
+			paramsPtr:   paramsPtr,
+		},
+		requiredArgs: required,
+		argNames:     argNames,
+		schema:       schema.FromType(paramsType),
+	}, nil
+}
+
+// SchemaFn is the function of a tool created with CreateToolFromSchema.
+//
+// Parameters:
+//   - ctx: The context of the call.
+//   - args: The arguments of the call, by name; the arguments not given by the model are absent.
+//
+// Returns:
+//   - The result of the call.
+//   - An error if the call fails.
+type SchemaFn func(ctx context.Context, args map[string]any) (any, error)
+
+// CreateToolFromSchema creates a new Tool whose parameters are described by a JSON schema.
+//
+// It is meant for the tools whose parameters are only known at runtime, e.g. generated from
+// an API description: the arguments are the properties of the schema, ordered by name, and
+// are handed to the function as a map.
+//
+// Parameters:
+//   - name: The name of the tool.
+//   - params: The JSON schema of the parameters, an object schema; nil for no parameter.
+//   - fn: The function of the tool.
+//   - descriptors: A variable number of strings describing the tool's purpose and usage in the format "role:description".
+//
+// Returns:
+//   - *Tool: A pointer to the created Tool instance.
+//   - error: An error if the name, the function or the schema are not valid or descriptors are incorrectly formatted.
+//
+// Example:
+//
+//	lookup, err := tool.CreateToolFromSchema("lookup_order", map[string]any{
+//	    "type":       "object",
+//	    "properties": map[string]any{"id": map[string]any{"type": "string", "description": "The order ID"}},
+//	    "required":   []string{"id"},
+//	}, func(ctx context.Context, args map[string]any) (any, error) {
+//	    return orders.Get(ctx, args["id"].(string))
+//	}, "description:Looks an order up")
+func CreateToolFromSchema(name string, params map[string]any, fn SchemaFn, descriptors ...string) (*Tool, error) {
+	if name == "" {
+		return nil, ErrToolNameEmpty
+	}
+	if fn == nil {
+		return nil, ErrToolFnNotFunction
+	}
+	toolDesc, err := parseDescriptors(descriptors...)
+	if err != nil {
+		return nil, err
+	}
+
+	if params == nil {
+		params = map[string]any{"type": "object", "properties": map[string]any{}}
+	}
+	if kind, _ := params["type"].(string); kind != "object" {
+		return nil, ErrToolParamsNotStruct
+	}
+	properties, _ := params["properties"].(map[string]any)
+
+	names := make([]string, 0, len(properties))
+	for propName := range properties {
+		names = append(names, propName)
+	}
+	slices.Sort(names)
+
+	args := make([]Arg, len(names))
+	argNames := make(map[int]string, len(names))
+	for i, propName := range names {
+		propSchema, _ := properties[propName].(map[string]any)
+		propType, _ := propSchema["type"].(string)
+		desc, _ := propSchema["description"].(string)
+		args[i] = Arg{Name: propName, Type: propType, Description: desc}
+		argNames[i] = propName
+	}
+
+	required := make([]string, 0)
+	switch req := params["required"].(type) {
+	case []string:
+		required = append(required, req...)
+	case []any:
+		for _, item := range req {
+			if s, ok := item.(string); ok {
+				required = append(required, s)
+			}
+		}
+	}
+
+	return &Tool{
+		Name:         name,
+		Args:         args,
+		descriptions: toolDesc,
+		callable: callable{
+			fn:          reflect.ValueOf(fn),
+			in:          len(args),
+			withContext: true,
+			dynamic:     true,
+		},
+		requiredArgs: required,
+		argNames:     argNames,
+		schema:       params,
+	}, nil
+}
+
+func parseDescriptors(descriptors ...string) (map[string]string, error) {
+	rv := make(map[string]string, len(descriptors))
+	for _, desc := range descriptors {
+		parts := strings.Split(desc, ":")
+
+		if len(parts) < 2 {
+			return nil, ErrInvalidDescriptorFormat
+		}
+
+		role := strings.ToLower(strings.TrimSpace(parts[0]))
+		value := strings.TrimSpace(strings.Join(parts[1:], ":"))
+
+		rv[role] = value
+	}
+	return rv, nil
+}
+
+func checkToolFn(fn ExecFn) (reflect.Type, reflect.Value, error) {
+	fnType := reflect.TypeOf(fn)
+	fnValue := reflect.ValueOf(fn)
+
+	if fnType == nil || fnType.Kind() != reflect.Func {
+		return nil, reflect.Value{}, ErrToolFnNotFunction
+	}
+
+	if fnType.NumOut() != 2 {
+		return nil, reflect.Value{}, ErrToolFnInvalidReturnCount
+	}
+	if !fnType.Out(1).Implements(reflect.TypeOf((*error)(nil)).Elem()) {
+		return nil, reflect.Value{}, ErrToolFnInvalidReturnCount
+	}
+
+	return fnType, fnValue, nil
+}
+
+func acceptsContext(fnType reflect.Type) bool {
+	return fnType.NumIn() > 0 && fnType.In(0) == contextType
+}
+
 func extractToolName(fn any, genericType reflect.Type) string {
 	fnValue := reflect.ValueOf(fn)
 
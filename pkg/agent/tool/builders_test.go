@@ -1,8 +1,11 @@
 package tool_test
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/morphy76/ggraph/pkg/agent/tool"
 )
@@ -313,3 +316,182 @@ func TestCreateToolErrors(t *testing.T) {
 		}
 	})
 }
+
+type weatherParams struct {
+	City  string `json:"city" desc:"The city to get the weather for"`
+	Days  int    `json:"days,omitempty" desc:"The number of forecast days"`
+	Units string `json:"units,omitempty"`
+}
+
+func weather(p weatherParams) (string, error) {
+	return fmt.Sprintf("%s:%d:%s", p.City, p.Days, p.Units), nil
+}
+
+func weatherPtr(p *weatherParams) (string, error) {
+	return p.City, nil
+}
+
+func TestCreateToolFromStruct(t *testing.T) {
+	weatherTool, err := tool.CreateToolFromStruct(weather, weatherParams{}, "Prompt: Get the weather")
+	if err != nil {
+		t.Fatalf("Failed to create weather tool: %v", err)
+	}
+
+	if weatherTool.Name != "weather" {
+		t.Errorf("Expected tool name 'weather', got '%s'", weatherTool.Name)
+	}
+	if len(weatherTool.Args) != 3 {
+		t.Fatalf("Expected 3 args, got %d", len(weatherTool.Args))
+	}
+	if weatherTool.Args[0].Name != "city" || weatherTool.Args[0].Description != "The city to get the weather for" {
+		t.Errorf("Unexpected first arg %+v", weatherTool.Args[0])
+	}
+	if required := weatherTool.RequiredArgs(); len(required) != 1 || required[0] != "city" {
+		t.Errorf("Expected only 'city' to be required, got %v", required)
+	}
+	if weatherTool.Schema()["type"] != "object" {
+		t.Errorf("Expected an object schema, got %v", weatherTool.Schema())
+	}
+
+	call := tool.FnCall{ID: "1", ToolName: "weather", Arguments: map[string]any{"city": "Rome", "days": float64(3)}}
+	result, err := weatherTool.Call(call.ArgsAsSortedSlice(weatherTool)...)
+	if err != nil {
+		t.Fatalf("Failed to call weather tool: %v", err)
+	}
+	if result != "Rome:3:" {
+		t.Errorf("Expected 'Rome:3:', got '%v'", result)
+	}
+
+	ptrTool, err := tool.CreateToolFromStruct(weatherPtr, &weatherParams{})
+	if err != nil {
+		t.Fatalf("Failed to create pointer weather tool: %v", err)
+	}
+	result, err = ptrTool.Call("Milan", nil, nil)
+	if err != nil || result != "Milan" {
+		t.Errorf("Expected 'Milan', got '%v' (%v)", result, err)
+	}
+}
+
+func TestCreateToolFromStruct_Invalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		fn      any
+		params  any
+		wantErr error
+	}{
+		{"params not struct", weather, "params", tool.ErrToolParamsNotStruct},
+		{"nil params", weather, nil, tool.ErrToolParamsNotStruct},
+		{"not a function", "weather", weatherParams{}, tool.ErrToolFnNotFunction},
+		{"mismatching params", concat, weatherParams{}, tool.ErrToolFnParamsMismatch},
+		{"wrong returns", func(p weatherParams) string { return "" }, weatherParams{}, tool.ErrToolFnInvalidReturnCount},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tool.CreateToolFromStruct(tt.fn, tt.params)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func slowTool(ctx context.Context, delay int) (string, error) {
+	select {
+	case <-time.After(time.Duration(delay) * time.Millisecond):
+		return "done", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func TestContextAwareTool(t *testing.T) {
+	ctxTool, err := tool.CreateTool[string](slowTool, "Prompt: wait", "Input: delay")
+	if err != nil {
+		t.Fatalf("Failed to create slow tool: %v", err)
+	}
+
+	if len(ctxTool.Args) != 1 || ctxTool.Args[0].Name != "delay" {
+		t.Fatalf("Expected the context not to be exposed as argument, got %+v", ctxTool.Args)
+	}
+
+	result, err := ctxTool.Call(float64(1))
+	if err != nil || result != "done" {
+		t.Errorf("Expected 'done', got '%v' (%v)", result, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := ctxTool.CallContext(ctx, 1000); !errors.Is(err, tool.ErrToolCallAborted) || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected aborted call, got %v", err)
+	}
+
+	if _, err := ctxTool.WithTimeout(-time.Second); !errors.Is(err, tool.ErrInvalidToolTimeout) {
+		t.Errorf("Expected ErrInvalidToolTimeout, got %v", err)
+	}
+	if _, err := ctxTool.WithTimeout(20 * time.Millisecond); err != nil {
+		t.Fatalf("WithTimeout() failed: %v", err)
+	}
+	if _, err := ctxTool.Call(1000); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+}
+
+func TestTimeoutOnContextUnawareTool(t *testing.T) {
+	blocking := make(chan struct{})
+	defer close(blocking)
+
+	blockingTool, err := tool.CreateTool[string](func() (string, error) {
+		<-blocking
+		return "unblocked", nil
+	})
+	if err != nil {
+		t.Fatalf("Failed to create blocking tool: %v", err)
+	}
+	blockingTool.WithTimeout(10 * time.Millisecond)
+
+	if _, err := blockingTool.Call(); !errors.Is(err, tool.ErrToolCallAborted) {
+		t.Errorf("Expected ErrToolCallAborted, got %v", err)
+	}
+}
+
+func TestCreateToolFromSchema(t *testing.T) {
+	lookup, err := tool.CreateToolFromSchema("lookup_order", map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id":      map[string]any{"type": "string", "description": "The order ID"},
+			"details": map[string]any{"type": "boolean"},
+		},
+		"required": []any{"id"},
+	}, func(ctx context.Context, args map[string]any) (any, error) {
+		if _, ok := args["details"]; ok {
+			return nil, errors.New("details not available")
+		}
+		return fmt.Sprintf("order %s", args["id"]), nil
+	}, "description:Looks an order up")
+	if err != nil {
+		t.Fatalf("CreateToolFromSchema failed: %v", err)
+	}
+
+	if lookup.Name != "lookup_order" || len(lookup.Args) != 2 || lookup.Args[0].Name != "details" || lookup.Args[1].Description != "The order ID" {
+		t.Errorf("unexpected tool: %+v", lookup)
+	}
+	if required := lookup.RequiredArgs(); len(required) != 1 || required[0] != "id" {
+		t.Errorf("expected id to be required, got %v", required)
+	}
+
+	result, err := lookup.Execute(context.Background(), tool.FnCall{ToolName: "lookup_order", Arguments: map[string]any{"id": "42"}})
+	if err != nil || result != "order 42" {
+		t.Errorf("expected order 42, got %v, %v", result, err)
+	}
+	if _, err := lookup.Execute(context.Background(), tool.FnCall{ToolName: "lookup_order", Arguments: map[string]any{"id": "42", "details": true}}); err == nil {
+		t.Error("expected the error of the function")
+	}
+
+	if _, err := tool.CreateToolFromSchema("", nil, func(context.Context, map[string]any) (any, error) { return nil, nil }); !errors.Is(err, tool.ErrToolNameEmpty) {
+		t.Errorf("expected ErrToolNameEmpty, got %v", err)
+	}
+	if _, err := tool.CreateToolFromSchema("noop", map[string]any{"type": "string"}, func(context.Context, map[string]any) (any, error) { return nil, nil }); !errors.Is(err, tool.ErrToolParamsNotStruct) {
+		t.Errorf("expected ErrToolParamsNotStruct, got %v", err)
+	}
+}
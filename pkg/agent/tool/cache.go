@@ -0,0 +1,238 @@
+package tool
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	ic "github.com/morphy76/ggraph/internal/agent/cache"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+const (
+	// DefaultMaxCachedResultBytes is the default size above which a tool result is not cached.
+	DefaultMaxCachedResultBytes = 64 * 1024
+)
+
+var (
+	// ErrResultCacheNil indicates that no result cache has been provided.
+	ErrResultCacheNil = errors.New("result cache cannot be nil")
+	// ErrInvalidCacheCapacity indicates that the capacity of an in-memory cache is not positive.
+	ErrInvalidCacheCapacity = errors.New("cache capacity must be at least 1")
+	// ErrInvalidCacheOption indicates that an option of a result cache has an invalid value.
+	ErrInvalidCacheOption = errors.New("invalid result cache option")
+)
+
+// ResultCache stores the tool results, keyed by a hash of the call.
+//
+// Entries are opaque bytes, so that any key-value store can back the cache: the response caches
+// of the agent package, like agent.NewLRUCache and the cache/redis package, are result caches too.
+type ResultCache interface {
+	// Get returns the cached result.
+	//
+	// Parameters:
+	//   - ctx: The context of the call.
+	//   - key: The call hash.
+	//
+	// Returns:
+	//   - The cached result.
+	//   - false if the result is not cached or expired.
+	//   - An error if the cache could not be read.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores a result.
+	//
+	// Parameters:
+	//   - ctx: The context of the call.
+	//   - key: The call hash.
+	//   - value: The result.
+	//   - ttl: The time to live of the entry, 0 for no expiration.
+	//
+	// Returns:
+	//   - An error if the result could not be stored.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// NewLRUCache creates an in-memory ResultCache evicting the least recently used entries.
+//
+// Parameters:
+//   - capacity: The maximum number of cached results.
+//
+// Returns:
+//   - The ResultCache.
+//   - An error if capacity is not positive.
+//
+// Example:
+//
+//	cache, err := tool.NewLRUCache(500)
+//	searchTool, err = searchTool.WithCache(cache, tool.WithResultTTL(10*time.Minute))
+func NewLRUCache(capacity int) (ResultCache, error) {
+	if capacity < 1 {
+		return nil, ErrInvalidCacheCapacity
+	}
+	return ic.NewLRU(capacity), nil
+}
+
+// CacheOptions holds the configuration of the result cache of a tool.
+type CacheOptions struct {
+	// TTL is the time to live of the cached results, 0 for no expiration.
+	TTL time.Duration
+	// Shared shares the cached results among the threads, otherwise they are scoped to the thread of the call.
+	Shared bool
+	// MaxResultBytes is the size above which a result is not cached.
+	MaxResultBytes int
+}
+
+// CacheOption is a functional option for configuring the result cache of a tool.
+type CacheOption interface {
+	// Apply applies the option to the CacheOptions.
+	//
+	// Parameters:
+	//   - o: A pointer to CacheOptions to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(o *CacheOptions) error
+}
+
+// CacheOptionFunc is a function type that implements the CacheOption interface.
+type CacheOptionFunc func(*CacheOptions) error
+
+// Apply applies the CacheOptionFunc to the given CacheOptions.
+//
+// Parameters:
+//   - o: A pointer to CacheOptions to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s CacheOptionFunc) Apply(o *CacheOptions) error { return s(o) }
+
+// WithResultTTL sets the time to live of the cached results.
+//
+// Parameters:
+//   - ttl: The time to live, 0 for no expiration.
+//
+// Returns:
+//   - A CacheOption that sets the time to live.
+func WithResultTTL(ttl time.Duration) CacheOption {
+	return CacheOptionFunc(func(o *CacheOptions) error {
+		if ttl < 0 {
+			return fmt.Errorf("%w: ttl cannot be negative", ErrInvalidCacheOption)
+		}
+		o.TTL = ttl
+		return nil
+	})
+}
+
+// WithSharedResults shares the cached results among all the threads, e.g. for a tool reading public data.
+//
+// Returns:
+//   - A CacheOption that shares the results.
+func WithSharedResults() CacheOption {
+	return CacheOptionFunc(func(o *CacheOptions) error {
+		o.Shared = true
+		return nil
+	})
+}
+
+// WithMaxResultBytes sets the size above which a result is not cached.
+//
+// Parameters:
+//   - maxBytes: The maximum size of a cached result, it must be positive.
+//
+// Returns:
+//   - A CacheOption that sets the size limit.
+func WithMaxResultBytes(maxBytes int) CacheOption {
+	return CacheOptionFunc(func(o *CacheOptions) error {
+		if maxBytes < 1 {
+			return fmt.Errorf("%w: max result bytes must be positive", ErrInvalidCacheOption)
+		}
+		o.MaxResultBytes = maxBytes
+		return nil
+	})
+}
+
+type resultCache struct {
+	cache ResultCache
+	opts  CacheOptions
+}
+
+// WithCache memoizes the results of a pure tool, keyed by the tool name and the call arguments.
+//
+// The repeated identical calls of a thread, common in agent loops, are answered from the cache
+// by Execute, after the policy of the tool is enforced; a cached result is the text the model
+// receives, i.e. the result formatted with fmt.Sprint. The failed calls are not cached and
+// neither are, unless the results are shared, the calls without a thread ID in their context.
+//
+// Parameters:
+//   - cache: The result cache.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - *Tool: The tool itself, to allow chaining.
+//   - error: An error if the cache is nil or the options are invalid.
+//
+// Example:
+//
+//	cache, _ := tool.NewLRUCache(500)
+//	weatherTool, err = weatherTool.WithCache(cache, tool.WithResultTTL(5*time.Minute), tool.WithSharedResults())
+func (t *Tool) WithCache(cache ResultCache, opts ...CacheOption) (*Tool, error) {
+	if cache == nil {
+		return nil, ErrResultCacheNil
+	}
+	useOpts := CacheOptions{MaxResultBytes: DefaultMaxCachedResultBytes}
+	for _, opt := range opts {
+		if err := opt.Apply(&useOpts); err != nil {
+			return nil, fmt.Errorf("invalid cache for tool %s: %w", t.Name, err)
+		}
+	}
+	t.cache = &resultCache{cache: cache, opts: useOpts}
+	return t, nil
+}
+
+// cachedCall calls the tool through its result cache, if any; cache failures never fail the call.
+func (t *Tool) cachedCall(ctx context.Context, call FnCall) (any, error) {
+	if t.cache == nil {
+		return t.CallContext(ctx, call.ArgsAsSortedSlice(t)...)
+	}
+	key, ok := t.cache.key(ctx, t.Name, call.Arguments)
+	if !ok {
+		return t.CallContext(ctx, call.ArgsAsSortedSlice(t)...)
+	}
+
+	if cached, found, err := t.cache.cache.Get(ctx, key); err == nil && found {
+		return string(cached), nil
+	}
+	rv, err := t.CallContext(ctx, call.ArgsAsSortedSlice(t)...)
+	if err != nil {
+		return rv, err
+	}
+	if text := fmt.Sprint(rv); len(text) <= t.cache.opts.MaxResultBytes {
+		_ = t.cache.cache.Set(ctx, key, []byte(text), t.cache.opts.TTL)
+	}
+	return rv, nil
+}
+
+// key hashes the scope, the tool name and the arguments of a call; false when the call cannot be cached.
+func (c *resultCache) key(ctx context.Context, toolName string, args map[string]any) (string, bool) {
+	var threadID string
+	if !c.opts.Shared {
+		var ok bool
+		if threadID, ok = g.ThreadIDFromContext(ctx); !ok {
+			return "", false
+		}
+	}
+	encoded, err := json.Marshal(struct {
+		Thread string `json:",omitempty"`
+		Tool   string
+		Args   map[string]any `json:",omitempty"`
+	}{Thread: threadID, Tool: toolName, Args: args})
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:]), true
+}
@@ -0,0 +1,96 @@
+package tool_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/morphy76/ggraph/pkg/agent/tool"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func createCountingTool(t *testing.T, calls *int) *tool.Tool {
+	search := func(query string) (string, error) {
+		*calls++
+		if query == "fail" {
+			return "", errors.New("search failed")
+		}
+		return "results for " + query, nil
+	}
+	rv, err := tool.CreateTool[string](search, "description:Searches the web", "input:query")
+	if err != nil {
+		t.Fatalf("CreateTool failed: %v", err)
+	}
+	return rv
+}
+
+func TestWithCache(t *testing.T) {
+	var calls int
+	cache, err := tool.NewLRUCache(10)
+	if err != nil {
+		t.Fatalf("NewLRUCache failed: %v", err)
+	}
+	search, err := createCountingTool(t, &calls).WithCache(cache)
+	if err != nil {
+		t.Fatalf("WithCache failed: %v", err)
+	}
+	call := func(ctx context.Context, query string) (any, error) {
+		return search.Execute(ctx, tool.FnCall{ToolName: search.Name, Arguments: map[string]any{"query": query}})
+	}
+
+	thread1 := g.ContextWithThreadID(context.Background(), "thread-1")
+	for range 3 {
+		if result, err := call(thread1, "go"); err != nil || result != "results for go" {
+			t.Errorf("unexpected result: %v, %v", result, err)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("expected the repeated calls to hit the cache, got %d calls", calls)
+	}
+
+	if _, err := call(g.ContextWithThreadID(context.Background(), "thread-2"), "go"); err != nil || calls != 2 {
+		t.Errorf("expected the results to be scoped to the thread, got %d calls, %v", calls, err)
+	}
+	if _, err := call(context.Background(), "go"); err != nil || calls != 3 {
+		t.Errorf("expected no caching without a thread, got %d calls, %v", calls, err)
+	}
+	for range 2 {
+		if _, err := call(thread1, "fail"); err == nil {
+			t.Errorf("expected the call to fail")
+		}
+	}
+	if calls != 5 {
+		t.Errorf("expected the failures not to be cached, got %d calls", calls)
+	}
+}
+
+func TestWithCache_Options(t *testing.T) {
+	var calls int
+	cache, _ := tool.NewLRUCache(10)
+	search, err := createCountingTool(t, &calls).WithCache(cache, tool.WithSharedResults(), tool.WithMaxResultBytes(len("results for go")))
+	if err != nil {
+		t.Fatalf("WithCache failed: %v", err)
+	}
+	for _, threadID := range []string{"thread-1", "thread-2"} {
+		ctx := g.ContextWithThreadID(context.Background(), threadID)
+		for _, query := range []string{"go", "golang"} {
+			if _, err := search.Execute(ctx, tool.FnCall{ToolName: search.Name, Arguments: map[string]any{"query": query}}); err != nil {
+				t.Fatalf("Execute failed: %v", err)
+			}
+		}
+	}
+	if calls != 3 {
+		t.Errorf("expected the small result to be shared and the large one not cached, got %d calls", calls)
+	}
+
+	if _, err := search.WithCache(nil); !errors.Is(err, tool.ErrResultCacheNil) {
+		t.Errorf("expected ErrResultCacheNil, got %v", err)
+	}
+	if _, err := search.WithCache(cache, tool.WithResultTTL(-1)); !errors.Is(err, tool.ErrInvalidCacheOption) || !strings.Contains(err.Error(), search.Name) {
+		t.Errorf("expected ErrInvalidCacheOption, got %v", err)
+	}
+	if _, err := tool.NewLRUCache(0); !errors.Is(err, tool.ErrInvalidCacheCapacity) {
+		t.Errorf("expected ErrInvalidCacheCapacity, got %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+// Package code provides a tool running code snippets in a constrained subprocess.
+//
+// The tool is opt-in twice: it has no language until one is enabled, and it is meant to be
+// combined with the tool policies, e.g. tool.RequireApproval. A run is a subprocess of the
+// interpreter reading the snippet from stdin, in a temporary working directory, with a minimal
+// environment, a wall-clock timeout killing its whole process group and, on unix systems, CPU
+// time and memory limits; its stdout and stderr are captured and bounded.
+//
+// The subprocess is not isolated from the network nor from the file system of the host user:
+// run the agent in a container or under a dedicated user when the snippets are not trusted.
+package code
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/morphy76/ggraph/pkg/agent/tool"
+)
+
+const (
+	// ToolName is the name of the code execution tool.
+	ToolName = "run_code"
+	// Shell is the name of the shell language, see WithShell.
+	Shell = "shell"
+	// Python is the name of the Python language, see WithPython.
+	Python = "python"
+)
+
+var (
+	// ErrNoLanguage indicates that the code execution tool was created without any enabled language.
+	ErrNoLanguage = errors.New("no language enabled")
+	// ErrUnknownLanguage indicates that a snippet is written in a language which is not enabled.
+	ErrUnknownLanguage = errors.New("language not enabled")
+	// ErrLimitsUnsupported indicates that the CPU time and memory limits are not supported on the platform.
+	ErrLimitsUnsupported = errors.New("resource limits are not supported on this platform")
+)
+
+// New creates the tool running code snippets.
+//
+// The tool takes the language and the code of the snippet; its result reports the exit status,
+// stdout and stderr of the run. A failing snippet is not an error of the tool, so that the model
+// can read the failure and fix the snippet.
+//
+// Parameters:
+//   - opts: The configuration options, at least one language must be enabled.
+//
+// Returns:
+//   - *tool.Tool: The code execution tool.
+//   - error: ErrNoLanguage, ErrLimitsUnsupported or an error if the options are invalid.
+//
+// Example:
+//
+//	runCode, err := code.New(
+//	    code.WithPython(""),
+//	    code.WithTimeout(30*time.Second),
+//	    code.WithCPUTime(10*time.Second),
+//	    code.WithMemory(512<<20))
+//	runCode, err = runCode.WithPolicy(tool.RequireApproval(approve))
+func New(opts ...Option) (*tool.Tool, error) {
+	useOpts := &Options{
+		Languages:      make(map[string][]string),
+		Timeout:        DefaultTimeout,
+		MaxOutputBytes: DefaultMaxOutputBytes,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("cannot create the code execution tool: %w", err)
+		}
+	}
+	if len(useOpts.Languages) == 0 {
+		return nil, fmt.Errorf("cannot create the code execution tool: %w", ErrNoLanguage)
+	}
+
+	commands := make(map[string][]string, len(useOpts.Languages))
+	for language, command := range useOpts.Languages {
+		limited, err := limitCommand(command, useOpts)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create the code execution tool: %w", err)
+		}
+		commands[language] = limited
+	}
+
+	languages := slices.Sorted(maps.Keys(commands))
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"language": map[string]any{
+				"type":        "string",
+				"enum":        languages,
+				"description": "The language of the snippet",
+			},
+			"code": map[string]any{
+				"type":        "string",
+				"description": "The snippet, printing its results on stdout",
+			},
+		},
+		"required":             []string{"code", "language"},
+		"additionalProperties": false,
+	}
+	description := fmt.Sprintf("Runs a %s snippet in a sandbox limited to %s and returns its exit status, stdout and stderr",
+		strings.Join(languages, " or "), useOpts.Timeout)
+	return tool.CreateToolFromSchema(ToolName, schema, runFn(commands, useOpts), "description:"+description)
+}
+
+func runFn(commands map[string][]string, opts *Options) tool.SchemaFn {
+	return func(ctx context.Context, args map[string]any) (any, error) {
+		language, _ := args["language"].(string)
+		command, ok := commands[language]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownLanguage, language)
+		}
+		snippet, _ := args["code"].(string)
+
+		workDir := opts.WorkDir
+		if workDir == "" {
+			tempDir, err := os.MkdirTemp("", "ggraph-code-*")
+			if err != nil {
+				return nil, fmt.Errorf("cannot create the working directory: %w", err)
+			}
+			defer os.RemoveAll(tempDir)
+			workDir = tempDir
+		}
+
+		runCtx, cancel := context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+		cmd := exec.CommandContext(runCtx, command[0], command[1:]...)
+		configureProcess(cmd)
+		cmd.WaitDelay = time.Second
+		cmd.Dir = workDir
+		cmd.Env = append([]string{"PATH=" + os.Getenv("PATH"), "HOME=" + workDir}, opts.Env...)
+		cmd.Stdin = strings.NewReader(snippet)
+		stdout := &boundedBuffer{max: opts.MaxOutputBytes}
+		stderr := &boundedBuffer{max: opts.MaxOutputBytes}
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+
+		start := time.Now()
+		err := cmd.Run()
+		if cmd.ProcessState == nil {
+			return nil, fmt.Errorf("cannot run the %s snippet: %w", language, err)
+		}
+		if ctx.Err() != nil {
+			return nil, context.Cause(ctx)
+		}
+
+		var rv strings.Builder
+		if runCtx.Err() != nil {
+			fmt.Fprintf(&rv, "timed out after %s, the process was killed\n", opts.Timeout)
+		} else {
+			fmt.Fprintf(&rv, "%s in %s\n", cmd.ProcessState, time.Since(start).Round(time.Millisecond))
+		}
+		fmt.Fprintf(&rv, "stdout:\n%s\nstderr:\n%s", stdout, stderr)
+		return rv.String(), nil
+	}
+}
+
+// boundedBuffer keeps the first max bytes written to it and counts the dropped ones.
+type boundedBuffer struct {
+	buf     bytes.Buffer
+	max     int
+	dropped int
+}
+
+// Write implements io.Writer, it never fails so that the process is not blocked by a full pipe.
+func (b *boundedBuffer) Write(p []byte) (int, error) {
+	if room := b.max - b.buf.Len(); room < len(p) {
+		b.buf.Write(p[:max(room, 0)])
+		b.dropped += len(p) - max(room, 0)
+		return len(p), nil
+	}
+	b.buf.Write(p)
+	return len(p), nil
+}
+
+// String returns the kept bytes, followed by the number of dropped ones.
+func (b *boundedBuffer) String() string {
+	if b.dropped > 0 {
+		return fmt.Sprintf("%s\n[truncated: %d more bytes]", b.buf.String(), b.dropped)
+	}
+	return b.buf.String()
+}
@@ -0,0 +1,18 @@
+//go:build !unix
+
+package code
+
+import (
+	"os/exec"
+)
+
+// limitCommand rejects the resource limits, which are enforced on unix systems only.
+func limitCommand(command []string, opts *Options) ([]string, error) {
+	if opts.CPUTime > 0 || opts.MemoryBytes > 0 {
+		return nil, ErrLimitsUnsupported
+	}
+	return command, nil
+}
+
+// configureProcess keeps the default process handling, the child processes are not killed on cancellation.
+func configureProcess(cmd *exec.Cmd) {}
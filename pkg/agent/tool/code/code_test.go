@@ -0,0 +1,97 @@
+package code_test
+
+import (
+	"context"
+	"errors"
+	"os/exec"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morphy76/ggraph/pkg/agent/tool"
+	"github.com/morphy76/ggraph/pkg/agent/tool/code"
+)
+
+func run(t *testing.T, runCode *tool.Tool, language, snippet string) string {
+	result, err := runCode.Execute(context.Background(), tool.FnCall{ToolName: runCode.Name, Arguments: map[string]any{"language": language, "code": snippet}})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	return result.(string)
+}
+
+func requireShell(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the shell snippets require a unix shell")
+	}
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh not available")
+	}
+}
+
+func TestNew_Shell(t *testing.T) {
+	requireShell(t)
+	t.Setenv("GGRAPH_SECRET", "leaked")
+	runCode, err := code.New(code.WithShell(), code.WithEnv("GREETING=hello"), code.WithMaxOutputBytes(32))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if runCode.Name != code.ToolName {
+		t.Errorf("unexpected tool name %s", runCode.Name)
+	}
+
+	result := run(t, runCode, code.Shell, `echo "$GREETING $GGRAPH_SECRET from $(pwd)"; echo oops >&2; exit 3`)
+	if !strings.HasPrefix(result, "exit status 3 in ") || !strings.Contains(result, "stdout:\nhello  from ") || !strings.Contains(result, "stderr:\noops\n") {
+		t.Errorf("unexpected result:\n%s", result)
+	}
+	if strings.Contains(result, "leaked") {
+		t.Errorf("expected the environment of the host not to be inherited:\n%s", result)
+	}
+
+	result = run(t, runCode, code.Shell, `printf '%0100d' 0`)
+	if !strings.Contains(result, "[truncated: 68 more bytes]") {
+		t.Errorf("expected the output to be truncated:\n%s", result)
+	}
+
+	if _, err := runCode.Execute(context.Background(), tool.FnCall{ToolName: runCode.Name, Arguments: map[string]any{"language": "ruby", "code": "puts 1"}}); !errors.Is(err, code.ErrUnknownLanguage) {
+		t.Errorf("expected ErrUnknownLanguage, got %v", err)
+	}
+}
+
+func TestNew_Limits(t *testing.T) {
+	requireShell(t)
+	runCode, err := code.New(code.WithShell(), code.WithTimeout(300*time.Millisecond))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	start := time.Now()
+	result := run(t, runCode, code.Shell, `echo started; sleep 5 & wait`)
+	if !strings.HasPrefix(result, "timed out after 300ms") || !strings.Contains(result, "started") {
+		t.Errorf("expected a timeout with the partial output:\n%s", result)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("expected the process group to be killed, took %s", elapsed)
+	}
+
+	cpuBound, err := code.New(code.WithShell(), code.WithCPUTime(time.Second), code.WithTimeout(20*time.Second))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	result = run(t, cpuBound, code.Shell, `while :; do :; done`)
+	if !strings.HasPrefix(result, "signal: ") {
+		t.Errorf("expected the CPU time limit to kill the process:\n%s", result)
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	if _, err := code.New(); !errors.Is(err, code.ErrNoLanguage) {
+		t.Errorf("expected ErrNoLanguage, got %v", err)
+	}
+	if _, err := code.New(code.WithLanguage("node")); !errors.Is(err, code.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+	if _, err := code.New(code.WithShell(), code.WithEnv("NOVALUE")); !errors.Is(err, code.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
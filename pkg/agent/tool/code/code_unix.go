@@ -0,0 +1,35 @@
+//go:build unix
+
+package code
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// limitCommand wraps the command in a shell setting the resource limits before executing it.
+func limitCommand(command []string, opts *Options) ([]string, error) {
+	var limits []string
+	if opts.CPUTime > 0 {
+		limits = append(limits, fmt.Sprintf("ulimit -t %d", (opts.CPUTime+time.Second-1)/time.Second))
+	}
+	if opts.MemoryBytes > 0 {
+		limits = append(limits, fmt.Sprintf("ulimit -v %d", (opts.MemoryBytes+1023)/1024))
+	}
+	if len(limits) == 0 {
+		return command, nil
+	}
+	script := strings.Join(append(limits, `exec "$@"`), " && ")
+	return append([]string{"sh", "-c", script, "sandbox"}, command...), nil
+}
+
+// configureProcess runs the command in its own process group, killed as a whole on cancellation.
+func configureProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
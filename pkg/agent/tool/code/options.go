@@ -0,0 +1,210 @@
+package code
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultTimeout is the default wall-clock limit of a run.
+	DefaultTimeout = 10 * time.Second
+	// DefaultMaxOutputBytes is the default number of bytes of stdout and of stderr handed to the model.
+	DefaultMaxOutputBytes = 16 * 1024
+)
+
+var (
+	// ErrInvalidOption indicates that an option of the code execution tool has an invalid value.
+	ErrInvalidOption = errors.New("invalid code execution option")
+)
+
+// Options holds the configuration of the code execution tool.
+type Options struct {
+	// Languages are the enabled languages, by name, with the interpreter command reading the snippet from stdin.
+	Languages map[string][]string
+	// Timeout is the wall-clock limit of a run, the process group is killed when it expires.
+	Timeout time.Duration
+	// CPUTime is the CPU time limit of a run, 0 for none.
+	CPUTime time.Duration
+	// MemoryBytes is the virtual memory limit of a run, 0 for none.
+	MemoryBytes int64
+	// MaxOutputBytes bounds stdout and stderr handed to the model, the rest is dropped.
+	MaxOutputBytes int
+	// WorkDir is the working directory of the runs, a new temporary directory for each run when empty.
+	WorkDir string
+	// Env are the additional variables of the environment of the runs, in the key=value form.
+	Env []string
+}
+
+// Option is a functional option for configuring the code execution tool.
+type Option interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - o: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(o *Options) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc func(*Options) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - o: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc) Apply(o *Options) error { return s(o) }
+
+// WithShell enables the shell snippets, run by sh.
+//
+// Returns:
+//   - An Option that enables the shell language.
+func WithShell() Option {
+	return WithLanguage(Shell, "sh", "-s")
+}
+
+// WithPython enables the Python snippets.
+//
+// Parameters:
+//   - interpreter: The Python interpreter, "python3" when empty.
+//
+// Returns:
+//   - An Option that enables the python language.
+func WithPython(interpreter string) Option {
+	if interpreter == "" {
+		interpreter = "python3"
+	}
+	return WithLanguage(Python, interpreter, "-")
+}
+
+// WithLanguage enables a language run by an interpreter reading the snippet from stdin.
+//
+// Parameters:
+//   - name: The name of the language given by the model.
+//   - command: The interpreter and its arguments.
+//
+// Returns:
+//   - An Option that enables the language.
+//
+// Example:
+//
+//	code.WithLanguage("node", "node", "-")
+func WithLanguage(name string, command ...string) Option {
+	return OptionFunc(func(o *Options) error {
+		if strings.TrimSpace(name) == "" || len(command) == 0 || command[0] == "" {
+			return fmt.Errorf("%w: a language requires a name and an interpreter", ErrInvalidOption)
+		}
+		o.Languages[name] = command
+		return nil
+	})
+}
+
+// WithTimeout sets the wall-clock limit of a run.
+//
+// Parameters:
+//   - timeout: The limit, it must be positive.
+//
+// Returns:
+//   - An Option that sets the timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return OptionFunc(func(o *Options) error {
+		if timeout <= 0 {
+			return fmt.Errorf("%w: timeout must be positive", ErrInvalidOption)
+		}
+		o.Timeout = timeout
+		return nil
+	})
+}
+
+// WithCPUTime sets the CPU time limit of a run, enforced with the resource limits of the process.
+//
+// Parameters:
+//   - cpuTime: The limit, rounded up to the second; it must be positive.
+//
+// Returns:
+//   - An Option that sets the CPU time limit.
+func WithCPUTime(cpuTime time.Duration) Option {
+	return OptionFunc(func(o *Options) error {
+		if cpuTime <= 0 {
+			return fmt.Errorf("%w: cpu time must be positive", ErrInvalidOption)
+		}
+		o.CPUTime = cpuTime
+		return nil
+	})
+}
+
+// WithMemory sets the virtual memory limit of a run, enforced with the resource limits of the process.
+//
+// Parameters:
+//   - memoryBytes: The limit in bytes, rounded up to the KiB; it must be positive.
+//
+// Returns:
+//   - An Option that sets the memory limit.
+func WithMemory(memoryBytes int64) Option {
+	return OptionFunc(func(o *Options) error {
+		if memoryBytes <= 0 {
+			return fmt.Errorf("%w: memory must be positive", ErrInvalidOption)
+		}
+		o.MemoryBytes = memoryBytes
+		return nil
+	})
+}
+
+// WithMaxOutputBytes bounds stdout and stderr handed to the model.
+//
+// Parameters:
+//   - maxBytes: The maximum number of bytes of each stream, it must be positive.
+//
+// Returns:
+//   - An Option that sets the bound.
+func WithMaxOutputBytes(maxBytes int) Option {
+	return OptionFunc(func(o *Options) error {
+		if maxBytes < 1 {
+			return fmt.Errorf("%w: max output bytes must be positive", ErrInvalidOption)
+		}
+		o.MaxOutputBytes = maxBytes
+		return nil
+	})
+}
+
+// WithWorkDir runs the snippets in a fixed directory, shared by the runs, instead of a new temporary one.
+//
+// Parameters:
+//   - dir: The working directory.
+//
+// Returns:
+//   - An Option that sets the working directory.
+func WithWorkDir(dir string) Option {
+	return OptionFunc(func(o *Options) error {
+		if dir == "" {
+			return fmt.Errorf("%w: empty working directory", ErrInvalidOption)
+		}
+		o.WorkDir = dir
+		return nil
+	})
+}
+
+// WithEnv adds variables to the environment of the runs, which is otherwise empty but for PATH and HOME.
+//
+// Parameters:
+//   - env: The variables, in the key=value form.
+//
+// Returns:
+//   - An Option that adds the variables.
+func WithEnv(env ...string) Option {
+	return OptionFunc(func(o *Options) error {
+		for _, variable := range env {
+			if key, _, ok := strings.Cut(variable, "="); !ok || key == "" {
+				return fmt.Errorf("%w: variable %q is not in the key=value form", ErrInvalidOption, variable)
+			}
+		}
+		o.Env = append(o.Env, env...)
+		return nil
+	})
+}
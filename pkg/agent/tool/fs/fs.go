@@ -0,0 +1,349 @@
+// Package fs provides the file-system tools of the coding agents, confined to a root directory.
+//
+// The tools read, write, list and glob the files below the root: the paths are relative to the
+// root and are resolved with an os.Root, so that neither "..", absolute paths nor symbolic links
+// can escape it. The hidden files, the file extensions and the sizes are restricted by options.
+package fs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	iofs "io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/morphy76/ggraph/pkg/agent/tool"
+)
+
+const (
+	// ReadFileTool is the name of the tool reading a file.
+	ReadFileTool = "read_file"
+	// WriteFileTool is the name of the tool writing a file.
+	WriteFileTool = "write_file"
+	// ListDirTool is the name of the tool listing a directory.
+	ListDirTool = "list_dir"
+	// GlobTool is the name of the tool finding the files matching a pattern.
+	GlobTool = "glob"
+)
+
+var (
+	// ErrInvalidRoot indicates that the root directory cannot be opened.
+	ErrInvalidRoot = errors.New("invalid root directory")
+	// ErrPathOutsideRoot indicates that a path is absolute or escapes the root directory.
+	ErrPathOutsideRoot = errors.New("path outside the root directory")
+	// ErrHiddenPath indicates that a path refers to a hidden file or directory.
+	ErrHiddenPath = errors.New("hidden files are not allowed")
+	// ErrExtensionNotAllowed indicates that the extension of a file is not allowed.
+	ErrExtensionNotAllowed = errors.New("file extension not allowed")
+	// ErrFileTooLarge indicates that the content to write exceeds the maximum size.
+	ErrFileTooLarge = errors.New("file too large")
+	// ErrNotRegularFile indicates that a path to read is not a regular file.
+	ErrNotRegularFile = errors.New("not a regular file")
+	// ErrBinaryFile indicates that a file to read is not a text file.
+	ErrBinaryFile = errors.New("binary files are not supported")
+)
+
+// Jail gives the file-system tools access to the files below a root directory.
+type Jail struct {
+	root  *os.Root
+	opts  Options
+	tools []*tool.Tool
+}
+
+type readFileParams struct {
+	Path string `json:"path" desc:"The path of the file, relative to the root directory"`
+}
+
+type writeFileParams struct {
+	Path    string `json:"path" desc:"The path of the file, relative to the root directory; the missing directories are created"`
+	Content string `json:"content" desc:"The whole content of the file"`
+}
+
+type listDirParams struct {
+	Path string `json:"path,omitempty" desc:"The path of the directory, relative to the root directory; the root directory when empty"`
+}
+
+type globParams struct {
+	Pattern string `json:"pattern" desc:"The pattern of the paths, relative to the root directory, like src/*.go; ** matches any number of directories"`
+}
+
+// New opens a root directory for the file-system tools.
+//
+// Parameters:
+//   - root: The root directory.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - *Jail: The jail, to be closed when the tools are not used anymore.
+//   - error: ErrInvalidRoot if the directory cannot be opened, or an error if the options are invalid.
+//
+// Example:
+//
+//	jail, err := fs.New("./workspace", fs.WithExtensions(".go", ".md"), fs.WithMaxWriteBytes(64*1024))
+//	defer jail.Close()
+//	_ = registry.RegisterNamespace("fs", jail.Tools()...)
+func New(root string, opts ...Option) (*Jail, error) {
+	useOpts := Options{
+		MaxReadBytes:  DefaultMaxReadBytes,
+		MaxWriteBytes: DefaultMaxWriteBytes,
+		MaxEntries:    DefaultMaxEntries,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(&useOpts); err != nil {
+			return nil, fmt.Errorf("cannot create the file-system tools: %w", err)
+		}
+	}
+	openRoot, err := os.OpenRoot(root)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidRoot, err)
+	}
+	rv := &Jail{root: openRoot, opts: useOpts}
+	if err := rv.createTools(); err != nil {
+		_ = openRoot.Close()
+		return nil, err
+	}
+	return rv, nil
+}
+
+// Close releases the root directory.
+//
+// Returns:
+//   - An error if the root directory cannot be closed.
+func (j *Jail) Close() error {
+	return j.root.Close()
+}
+
+// Tools returns the file-system tools: read_file, write_file unless read-only, list_dir and glob.
+//
+// Returns:
+//   - []*tool.Tool: The tools.
+func (j *Jail) Tools() []*tool.Tool {
+	return slices.Clone(j.tools)
+}
+
+func (j *Jail) createTools() error {
+	type toolSpec struct {
+		name        string
+		fn          any
+		params      any
+		description string
+	}
+	specs := []toolSpec{
+		{ReadFileTool, j.readFile, readFileParams{}, "Reads a text file"},
+		{WriteFileTool, j.writeFile, writeFileParams{}, "Creates or overwrites a text file"},
+		{ListDirTool, j.listDir, listDirParams{}, "Lists a directory, the directories end with a slash"},
+		{GlobTool, j.glob, globParams{}, "Finds the files whose path matches a pattern"},
+	}
+
+	for _, spec := range specs {
+		if j.opts.ReadOnly && spec.name == WriteFileTool {
+			continue
+		}
+		fsTool, err := tool.CreateToolFromStruct(spec.fn, spec.params, "description:"+spec.description)
+		if err != nil {
+			return fmt.Errorf("cannot create the %s tool: %w", spec.name, err)
+		}
+		fsTool.Name = spec.name
+		j.tools = append(j.tools, fsTool)
+	}
+	return nil
+}
+
+// readFile reads a text file, truncated to the maximum read size.
+func (j *Jail) readFile(params readFileParams) (string, error) {
+	name, err := j.checkFile(params.Path)
+	if err != nil {
+		return "", err
+	}
+	file, err := j.root.Open(name)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return "", err
+	}
+	if !info.Mode().IsRegular() {
+		return "", fmt.Errorf("%w: %s", ErrNotRegularFile, params.Path)
+	}
+
+	content, err := io.ReadAll(io.LimitReader(file, int64(j.opts.MaxReadBytes)))
+	if err != nil {
+		return "", err
+	}
+	if bytes.IndexByte(content, 0) >= 0 {
+		return "", fmt.Errorf("%w: %s", ErrBinaryFile, params.Path)
+	}
+	if remaining := info.Size() - int64(len(content)); remaining > 0 {
+		return fmt.Sprintf("%s\n[truncated: %d more bytes]", content, remaining), nil
+	}
+	return string(content), nil
+}
+
+// writeFile creates or overwrites a text file, creating the missing directories.
+func (j *Jail) writeFile(params writeFileParams) (string, error) {
+	name, err := j.checkFile(params.Path)
+	if err != nil {
+		return "", err
+	}
+	if len(params.Content) > j.opts.MaxWriteBytes {
+		return "", fmt.Errorf("%w: %d bytes, the maximum is %d", ErrFileTooLarge, len(params.Content), j.opts.MaxWriteBytes)
+	}
+	if dir := filepath.Dir(name); dir != "." {
+		if err := j.root.MkdirAll(dir, 0o755); err != nil {
+			return "", err
+		}
+	}
+	if err := j.root.WriteFile(name, []byte(params.Content), 0o644); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("wrote %d bytes to %s", len(params.Content), filepath.ToSlash(name)), nil
+}
+
+// listDir lists a directory, one entry per line, the directories ending with a slash.
+func (j *Jail) listDir(params listDirParams) (string, error) {
+	name := params.Path
+	if name == "" {
+		name = "."
+	}
+	name, err := j.checkPath(name)
+	if err != nil {
+		return "", err
+	}
+	entries, err := iofs.ReadDir(j.root.FS(), filepath.ToSlash(name))
+	if err != nil {
+		return "", err
+	}
+
+	var lines []string
+	for _, entry := range entries {
+		if !j.visible(entry.Name(), entry.IsDir()) {
+			continue
+		}
+		if entry.IsDir() {
+			lines = append(lines, entry.Name()+"/")
+		} else {
+			lines = append(lines, entry.Name())
+		}
+	}
+	return j.format(lines), nil
+}
+
+// glob finds the files whose path matches a pattern, ** matching any number of directories.
+func (j *Jail) glob(params globParams) (string, error) {
+	pattern := strings.TrimPrefix(path.Clean(filepath.ToSlash(params.Pattern)), "./")
+	if !iofs.ValidPath(pattern) {
+		return "", fmt.Errorf("%w: %s", ErrPathOutsideRoot, params.Pattern)
+	}
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return "", fmt.Errorf("invalid pattern %s: %w", params.Pattern, err)
+		}
+	}
+
+	var lines []string
+	err := iofs.WalkDir(j.root.FS(), ".", func(name string, entry iofs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == "." {
+			return nil
+		}
+		if !j.visible(entry.Name(), entry.IsDir()) {
+			if entry.IsDir() {
+				return iofs.SkipDir
+			}
+			return nil
+		}
+		if !entry.IsDir() && matchGlob(strings.Split(pattern, "/"), strings.Split(name, "/")) {
+			lines = append(lines, name)
+			if len(lines) > j.opts.MaxEntries {
+				return iofs.SkipAll
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return j.format(lines), nil
+}
+
+// checkPath cleans a path relative to the root, rejecting the absolute, escaping and hidden ones.
+func (j *Jail) checkPath(name string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(name))
+	if !filepath.IsLocal(cleaned) {
+		return "", fmt.Errorf("%w: %s", ErrPathOutsideRoot, name)
+	}
+	if !j.opts.Hidden && cleaned != "." {
+		for _, segment := range strings.Split(filepath.ToSlash(cleaned), "/") {
+			if strings.HasPrefix(segment, ".") {
+				return "", fmt.Errorf("%w: %s", ErrHiddenPath, name)
+			}
+		}
+	}
+	return cleaned, nil
+}
+
+// checkFile checks a path and the extension of the file.
+func (j *Jail) checkFile(name string) (string, error) {
+	cleaned, err := j.checkPath(name)
+	if err != nil {
+		return "", err
+	}
+	if !j.allowedExtension(cleaned) {
+		return "", fmt.Errorf("%w: %s", ErrExtensionNotAllowed, name)
+	}
+	return cleaned, nil
+}
+
+func (j *Jail) allowedExtension(name string) bool {
+	return len(j.opts.Extensions) == 0 || slices.Contains(j.opts.Extensions, strings.ToLower(filepath.Ext(name)))
+}
+
+// visible tells whether an entry is shown by the listings.
+func (j *Jail) visible(name string, isDir bool) bool {
+	if !j.opts.Hidden && strings.HasPrefix(name, ".") {
+		return false
+	}
+	return isDir || j.allowedExtension(name)
+}
+
+// format joins the entries, cut to the maximum number of entries.
+func (j *Jail) format(lines []string) string {
+	if len(lines) == 0 {
+		return "no entries"
+	}
+	if len(lines) > j.opts.MaxEntries {
+		return strings.Join(lines[:j.opts.MaxEntries], "\n") + "\n[more entries omitted]"
+	}
+	return strings.Join(lines, "\n")
+}
+
+// matchGlob matches the segments of a path against the segments of a pattern, ** matching any number of segments.
+func matchGlob(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(name); i++ {
+				if matchGlob(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
@@ -0,0 +1,168 @@
+package fs_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/morphy76/ggraph/pkg/agent/tool"
+	"github.com/morphy76/ggraph/pkg/agent/tool/fs"
+)
+
+func createWorkspace(t *testing.T) string {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.go":          "package main\n",
+		"README.md":        "# Workspace\n",
+		"pkg/util/util.go": "package util\n",
+		"pkg/data.bin":     "\x00\x01",
+		".env":             "SECRET=1\n",
+		".git/config":      "[core]\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func createTools(t *testing.T, root string, opts ...fs.Option) map[string]*tool.Tool {
+	jail, err := fs.New(root, opts...)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	t.Cleanup(func() { _ = jail.Close() })
+	rv := make(map[string]*tool.Tool)
+	for _, fsTool := range jail.Tools() {
+		rv[fsTool.Name] = fsTool
+	}
+	return rv
+}
+
+func run(tools map[string]*tool.Tool, name string, args map[string]any) (string, error) {
+	result, err := tools[name].Execute(context.Background(), tool.FnCall{ToolName: name, Arguments: args})
+	if err != nil {
+		return "", err
+	}
+	return result.(string), nil
+}
+
+func TestReadFile(t *testing.T) {
+	root := createWorkspace(t)
+	tools := createTools(t, root, fs.WithMaxReadBytes(5))
+
+	if content, err := run(tools, fs.ReadFileTool, map[string]any{"path": "pkg/util/util.go"}); err != nil || content != "packa\n[truncated: 8 more bytes]" {
+		t.Errorf("unexpected content %q, %v", content, err)
+	}
+
+	for path, expected := range map[string]error{
+		"../outside.go":  fs.ErrPathOutsideRoot,
+		"/etc/passwd":    fs.ErrPathOutsideRoot,
+		".env":           fs.ErrHiddenPath,
+		".git/config":    fs.ErrHiddenPath,
+		"pkg/data.bin":   fs.ErrBinaryFile,
+		"pkg":            fs.ErrNotRegularFile,
+		"missing.go":     os.ErrNotExist,
+		"pkg/../main.go": nil,
+	} {
+		_, err := run(tools, fs.ReadFileTool, map[string]any{"path": path})
+		if !errors.Is(err, expected) {
+			t.Errorf("reading %s: expected %v, got %v", path, expected, err)
+		}
+	}
+}
+
+func TestReadFile_Symlink(t *testing.T) {
+	outside := t.TempDir()
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	root := createWorkspace(t)
+	if err := os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(root, "link.txt")); err != nil {
+		t.Skipf("symbolic links not supported: %v", err)
+	}
+	tools := createTools(t, root)
+
+	if content, err := run(tools, fs.ReadFileTool, map[string]any{"path": "link.txt"}); err == nil {
+		t.Errorf("expected the symbolic link escaping the root to fail, got %q", content)
+	}
+}
+
+func TestWriteFile(t *testing.T) {
+	root := createWorkspace(t)
+	tools := createTools(t, root, fs.WithExtensions(".go", ".MD"), fs.WithMaxWriteBytes(20))
+
+	result, err := run(tools, fs.WriteFileTool, map[string]any{"path": "cmd/app/main.go", "content": "package main\n"})
+	if err != nil || result != "wrote 13 bytes to cmd/app/main.go" {
+		t.Fatalf("unexpected result %q, %v", result, err)
+	}
+	if written, err := os.ReadFile(filepath.Join(root, "cmd", "app", "main.go")); err != nil || string(written) != "package main\n" {
+		t.Errorf("unexpected file %q, %v", written, err)
+	}
+	if _, err := run(tools, fs.WriteFileTool, map[string]any{"path": "NOTES.md", "content": "ok"}); err != nil {
+		t.Errorf("expected the extensions to ignore the case, got %v", err)
+	}
+
+	if _, err := run(tools, fs.WriteFileTool, map[string]any{"path": "run.sh", "content": "rm -rf /"}); !errors.Is(err, fs.ErrExtensionNotAllowed) {
+		t.Errorf("expected ErrExtensionNotAllowed, got %v", err)
+	}
+	if _, err := run(tools, fs.WriteFileTool, map[string]any{"path": "big.go", "content": strings.Repeat("x", 21)}); !errors.Is(err, fs.ErrFileTooLarge) {
+		t.Errorf("expected ErrFileTooLarge, got %v", err)
+	}
+	if _, err := run(tools, fs.WriteFileTool, map[string]any{"path": "../escape.go", "content": "x"}); !errors.Is(err, fs.ErrPathOutsideRoot) {
+		t.Errorf("expected ErrPathOutsideRoot, got %v", err)
+	}
+
+	readOnly := createTools(t, root, fs.WithReadOnly())
+	if _, ok := readOnly[fs.WriteFileTool]; ok || len(readOnly) != 3 {
+		t.Errorf("expected no write tool in read-only mode, got %v", readOnly)
+	}
+}
+
+func TestListDirAndGlob(t *testing.T) {
+	root := createWorkspace(t)
+	tools := createTools(t, root, fs.WithExtensions(".go", ".md"))
+
+	if listing, err := run(tools, fs.ListDirTool, map[string]any{}); err != nil || listing != "README.md\nmain.go\npkg/" {
+		t.Errorf("unexpected listing %q, %v", listing, err)
+	}
+	if listing, err := run(tools, fs.ListDirTool, map[string]any{"path": "pkg"}); err != nil || listing != "util/" {
+		t.Errorf("unexpected listing %q, %v", listing, err)
+	}
+
+	for pattern, expected := range map[string]string{
+		"**/*.go":   "main.go\npkg/util/util.go",
+		"pkg/*/*":   "pkg/util/util.go",
+		"*.md":      "README.md",
+		"**/config": "no entries",
+	} {
+		if matches, err := run(tools, fs.GlobTool, map[string]any{"pattern": pattern}); err != nil || matches != expected {
+			t.Errorf("glob %s: unexpected matches %q, %v", pattern, matches, err)
+		}
+	}
+	if _, err := run(tools, fs.GlobTool, map[string]any{"pattern": "../*"}); !errors.Is(err, fs.ErrPathOutsideRoot) {
+		t.Errorf("expected ErrPathOutsideRoot, got %v", err)
+	}
+
+	limited := createTools(t, root, fs.WithMaxEntries(1), fs.WithHiddenFiles())
+	if matches, err := run(limited, fs.GlobTool, map[string]any{"pattern": "**"}); err != nil || matches != ".env\n[more entries omitted]" {
+		t.Errorf("unexpected matches %q, %v", matches, err)
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	if _, err := fs.New(filepath.Join(t.TempDir(), "missing")); !errors.Is(err, fs.ErrInvalidRoot) {
+		t.Errorf("expected ErrInvalidRoot, got %v", err)
+	}
+	if _, err := fs.New(t.TempDir(), fs.WithExtensions("go")); !errors.Is(err, fs.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package fs
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	// DefaultMaxReadBytes is the default number of bytes of a file handed to the model.
+	DefaultMaxReadBytes = 256 * 1024
+	// DefaultMaxWriteBytes is the default maximum size of a written file.
+	DefaultMaxWriteBytes = 1024 * 1024
+	// DefaultMaxEntries is the default number of entries returned by a listing or a glob.
+	DefaultMaxEntries = 500
+)
+
+var (
+	// ErrInvalidOption indicates that an option of the file-system tools has an invalid value.
+	ErrInvalidOption = errors.New("invalid file-system tool option")
+)
+
+// Options holds the configuration of the file-system tools.
+type Options struct {
+	// MaxReadBytes bounds the content of a file handed to the model, the rest is truncated.
+	MaxReadBytes int
+	// MaxWriteBytes bounds the size of a written file.
+	MaxWriteBytes int
+	// MaxEntries bounds the entries returned by a listing or a glob.
+	MaxEntries int
+	// Extensions are the allowed file extensions, like ".go", all when empty.
+	Extensions []string
+	// ReadOnly omits the write tool.
+	ReadOnly bool
+	// Hidden allows the hidden files and directories, like .env or .git.
+	Hidden bool
+}
+
+// Option is a functional option for configuring the file-system tools.
+type Option interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - o: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(o *Options) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc func(*Options) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - o: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc) Apply(o *Options) error { return s(o) }
+
+// WithMaxReadBytes bounds the content of a file handed to the model.
+//
+// Parameters:
+//   - maxBytes: The maximum number of bytes, it must be positive.
+//
+// Returns:
+//   - An Option that sets the bound.
+func WithMaxReadBytes(maxBytes int) Option {
+	return OptionFunc(func(o *Options) error {
+		if maxBytes < 1 {
+			return fmt.Errorf("%w: max read bytes must be positive", ErrInvalidOption)
+		}
+		o.MaxReadBytes = maxBytes
+		return nil
+	})
+}
+
+// WithMaxWriteBytes bounds the size of a written file.
+//
+// Parameters:
+//   - maxBytes: The maximum number of bytes, it must be positive.
+//
+// Returns:
+//   - An Option that sets the bound.
+func WithMaxWriteBytes(maxBytes int) Option {
+	return OptionFunc(func(o *Options) error {
+		if maxBytes < 1 {
+			return fmt.Errorf("%w: max write bytes must be positive", ErrInvalidOption)
+		}
+		o.MaxWriteBytes = maxBytes
+		return nil
+	})
+}
+
+// WithMaxEntries bounds the entries returned by a listing or a glob.
+//
+// Parameters:
+//   - maxEntries: The maximum number of entries, it must be positive.
+//
+// Returns:
+//   - An Option that sets the bound.
+func WithMaxEntries(maxEntries int) Option {
+	return OptionFunc(func(o *Options) error {
+		if maxEntries < 1 {
+			return fmt.Errorf("%w: max entries must be positive", ErrInvalidOption)
+		}
+		o.MaxEntries = maxEntries
+		return nil
+	})
+}
+
+// WithExtensions restricts the files to the given extensions.
+//
+// Parameters:
+//   - extensions: The extensions, with the leading dot, like ".go"; the comparison ignores the case.
+//
+// Returns:
+//   - An Option that sets the allowed extensions.
+//
+// Example:
+//
+//	fs.WithExtensions(".go", ".md", ".yaml")
+func WithExtensions(extensions ...string) Option {
+	return OptionFunc(func(o *Options) error {
+		for _, extension := range extensions {
+			if !strings.HasPrefix(extension, ".") {
+				return fmt.Errorf("%w: extension %q must start with a dot", ErrInvalidOption, extension)
+			}
+			o.Extensions = append(o.Extensions, strings.ToLower(extension))
+		}
+		return nil
+	})
+}
+
+// WithReadOnly omits the write tool.
+//
+// Returns:
+//   - An Option that makes the tools read-only.
+func WithReadOnly() Option {
+	return OptionFunc(func(o *Options) error {
+		o.ReadOnly = true
+		return nil
+	})
+}
+
+// WithHiddenFiles allows the hidden files and directories, denied by default since they usually
+// hold secrets or repository metadata, like .env or .git.
+//
+// Returns:
+//   - An Option that allows the hidden files.
+func WithHiddenFiles() Option {
+	return OptionFunc(func(o *Options) error {
+		o.Hidden = true
+		return nil
+	})
+}
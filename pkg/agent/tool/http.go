@@ -0,0 +1,562 @@
+package tool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+)
+
+const (
+	// DefaultHTTPMaxResponseBytes is the default number of bytes of the response body handed to the model.
+	DefaultHTTPMaxResponseBytes = 16 * 1024
+)
+
+var (
+	// ErrInvalidHTTPMethod indicates that the method of an HTTP tool is not a standard HTTP method.
+	ErrInvalidHTTPMethod = errors.New("invalid HTTP method")
+	// ErrInvalidURLTemplate indicates that the URL template of an HTTP tool is not a valid absolute URL.
+	ErrInvalidURLTemplate = errors.New("invalid URL template")
+	// ErrHTTPBodyConflict indicates that an HTTP tool was given both a JSON body and a body template.
+	ErrHTTPBodyConflict = errors.New("JSON body and body template are mutually exclusive")
+	// ErrHTTPMissingArg indicates that a placeholder of the URL template has no argument.
+	ErrHTTPMissingArg = errors.New("missing argument for URL placeholder")
+	// ErrHTTPStatus indicates that the HTTP call answered with an error status.
+	ErrHTTPStatus = errors.New("HTTP error status")
+	// ErrInvalidHTTPOption indicates that an option of an HTTP tool has an invalid value.
+	ErrInvalidHTTPOption = errors.New("invalid HTTP tool option")
+
+	placeholderPattern = regexp.MustCompile(`\{([A-Za-z_][A-Za-z0-9_.-]*)\}`)
+	httpMethods        = []string{http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodOptions}
+)
+
+// HTTPParam describes an argument of an HTTP tool.
+type HTTPParam struct {
+	// Type is the JSON schema type of the argument, "string" when empty.
+	Type string
+	// Schema is the complete JSON schema of the argument, e.g. with the items of an array; it overrides Type.
+	Schema map[string]any
+	// Description describes the argument to the model.
+	Description string
+	// Required is true when the model must give the argument.
+	Required bool
+}
+
+// HTTPResponseFn shapes the response of a successful HTTP call into the result of the tool.
+//
+// Parameters:
+//   - status: The status code of the response.
+//   - header: The headers of the response.
+//   - body: The body of the response, truncated to the maximum response size.
+//
+// Returns:
+//   - The result of the tool.
+//   - An error if the response cannot be shaped.
+type HTTPResponseFn func(status int, header http.Header, body []byte) (any, error)
+
+// HTTPOptions holds the configuration of an HTTP tool.
+type HTTPOptions struct {
+	// Description describes the tool to the model.
+	Description string
+	// Params declares the arguments, the placeholders of the URL template are declared as required strings.
+	Params map[string]HTTPParam
+	// Query are the arguments sent as query parameters.
+	Query []string
+	// JSONBody are the arguments sent as the fields of a JSON body.
+	JSONBody []string
+	// BodyTemplate renders the body from the arguments.
+	BodyTemplate *template.Template
+	// Headers are the headers of every request.
+	Headers http.Header
+	// HeaderFn adds the headers of a request, e.g. the credentials of the caller.
+	HeaderFn func(ctx context.Context, header http.Header) error
+	// Client is the HTTP client, http.DefaultClient if nil.
+	Client *http.Client
+	// Timeout bounds a call, 0 for no bound.
+	Timeout time.Duration
+	// MaxResponseBytes bounds the response body handed to the model.
+	MaxResponseBytes int
+	// Response shapes the response body, the body as text when nil.
+	Response HTTPResponseFn
+}
+
+// HTTPOption is a functional option for configuring an HTTP tool.
+type HTTPOption interface {
+	// Apply applies the option to the HTTPOptions.
+	//
+	// Parameters:
+	//   - o: A pointer to HTTPOptions to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(o *HTTPOptions) error
+}
+
+// HTTPOptionFunc is a function type that implements the HTTPOption interface.
+type HTTPOptionFunc func(*HTTPOptions) error
+
+// Apply applies the HTTPOptionFunc to the given HTTPOptions.
+//
+// Parameters:
+//   - o: A pointer to HTTPOptions to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s HTTPOptionFunc) Apply(o *HTTPOptions) error { return s(o) }
+
+// WithHTTPDescription sets the description of the tool.
+//
+// Parameters:
+//   - description: The description given to the model.
+//
+// Returns:
+//   - An HTTPOption that sets the description.
+func WithHTTPDescription(description string) HTTPOption {
+	return HTTPOptionFunc(func(o *HTTPOptions) error {
+		o.Description = description
+		return nil
+	})
+}
+
+// WithHTTPParam declares an argument of the tool.
+//
+// Parameters:
+//   - name: The name of the argument.
+//   - param: The description of the argument.
+//
+// Returns:
+//   - An HTTPOption that declares the argument.
+//
+// Example:
+//
+//	tool.WithHTTPParam("units", tool.HTTPParam{Description: "metric or imperial"})
+func WithHTTPParam(name string, param HTTPParam) HTTPOption {
+	return HTTPOptionFunc(func(o *HTTPOptions) error {
+		if name == "" {
+			return fmt.Errorf("%w: empty parameter name", ErrInvalidHTTPOption)
+		}
+		o.Params[name] = param
+		return nil
+	})
+}
+
+// WithHTTPQuery sends the arguments as query parameters, the absent ones are omitted.
+//
+// Parameters:
+//   - names: The names of the arguments, declared as optional strings unless declared otherwise.
+//
+// Returns:
+//   - An HTTPOption that sets the query parameters.
+func WithHTTPQuery(names ...string) HTTPOption {
+	return HTTPOptionFunc(func(o *HTTPOptions) error {
+		o.Query = append(o.Query, names...)
+		return nil
+	})
+}
+
+// WithHTTPJSONBody sends the arguments as the fields of a JSON body, the absent ones are omitted.
+//
+// Parameters:
+//   - names: The names of the arguments, declared as optional strings unless declared otherwise.
+//
+// Returns:
+//   - An HTTPOption that sets the JSON body.
+func WithHTTPJSONBody(names ...string) HTTPOption {
+	return HTTPOptionFunc(func(o *HTTPOptions) error {
+		o.JSONBody = append(o.JSONBody, names...)
+		return nil
+	})
+}
+
+// WithHTTPBodyTemplate renders the body with a text/template executed on the arguments.
+//
+// The template receives the map of the arguments and can use the json function to encode a value;
+// the arguments it uses are declared with WithHTTPParam.
+//
+// Parameters:
+//   - body: The template of the body.
+//
+// Returns:
+//   - An HTTPOption that sets the body template.
+//
+// Example:
+//
+//	tool.WithHTTPBodyTemplate(`{"query": {{json .query}}, "limit": 10}`)
+func WithHTTPBodyTemplate(body string) HTTPOption {
+	return HTTPOptionFunc(func(o *HTTPOptions) error {
+		tmpl, err := template.New("body").Option("missingkey=zero").Funcs(template.FuncMap{
+			"json": func(value any) (string, error) {
+				raw, err := json.Marshal(value)
+				return string(raw), err
+			},
+		}).Parse(body)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidHTTPOption, err)
+		}
+		o.BodyTemplate = tmpl
+		return nil
+	})
+}
+
+// WithHTTPHeader sets a header of every request.
+//
+// Parameters:
+//   - key: The header name.
+//   - value: The header value.
+//
+// Returns:
+//   - An HTTPOption that sets the header.
+func WithHTTPHeader(key, value string) HTTPOption {
+	return HTTPOptionFunc(func(o *HTTPOptions) error {
+		o.Headers.Set(key, value)
+		return nil
+	})
+}
+
+// WithHTTPHeaderFn adds the headers of each request from its context, e.g. the credentials of the caller.
+//
+// Parameters:
+//   - fn: The function adding the headers.
+//
+// Returns:
+//   - An HTTPOption that sets the header function.
+//
+// Example:
+//
+//	tool.WithHTTPHeaderFn(func(ctx context.Context, header http.Header) error {
+//	    header.Set("Authorization", "Bearer "+tokenFor(tool.MetadataFromContext(ctx)["tenant"]))
+//	    return nil
+//	})
+func WithHTTPHeaderFn(fn func(ctx context.Context, header http.Header) error) HTTPOption {
+	return HTTPOptionFunc(func(o *HTTPOptions) error {
+		if fn == nil {
+			return fmt.Errorf("%w: nil header function", ErrInvalidHTTPOption)
+		}
+		o.HeaderFn = fn
+		return nil
+	})
+}
+
+// WithHTTPClient sets the HTTP client.
+//
+// Parameters:
+//   - client: The HTTP client.
+//
+// Returns:
+//   - An HTTPOption that sets the client.
+func WithHTTPClient(client *http.Client) HTTPOption {
+	return HTTPOptionFunc(func(o *HTTPOptions) error {
+		o.Client = client
+		return nil
+	})
+}
+
+// WithHTTPTimeout bounds a call of the tool, see Tool.WithTimeout.
+//
+// Parameters:
+//   - timeout: The maximum duration of a call.
+//
+// Returns:
+//   - An HTTPOption that sets the timeout.
+func WithHTTPTimeout(timeout time.Duration) HTTPOption {
+	return HTTPOptionFunc(func(o *HTTPOptions) error {
+		if timeout < 0 {
+			return ErrInvalidToolTimeout
+		}
+		o.Timeout = timeout
+		return nil
+	})
+}
+
+// WithHTTPMaxResponseBytes bounds the response body handed to the model.
+//
+// Parameters:
+//   - maxBytes: The maximum number of bytes, it must be positive.
+//
+// Returns:
+//   - An HTTPOption that sets the bound.
+func WithHTTPMaxResponseBytes(maxBytes int) HTTPOption {
+	return HTTPOptionFunc(func(o *HTTPOptions) error {
+		if maxBytes < 1 {
+			return fmt.Errorf("%w: max response bytes must be positive", ErrInvalidHTTPOption)
+		}
+		o.MaxResponseBytes = maxBytes
+		return nil
+	})
+}
+
+// WithHTTPResponse shapes the response of the successful calls.
+//
+// Parameters:
+//   - fn: The function shaping the response.
+//
+// Returns:
+//   - An HTTPOption that sets the response function.
+func WithHTTPResponse(fn HTTPResponseFn) HTTPOption {
+	return HTTPOptionFunc(func(o *HTTPOptions) error {
+		o.Response = fn
+		return nil
+	})
+}
+
+// WithHTTPResponseFields reduces a JSON object response to the given top-level fields.
+//
+// Parameters:
+//   - fields: The fields to keep.
+//
+// Returns:
+//   - An HTTPOption shaping the response as the JSON object of the fields.
+//
+// Example:
+//
+//	tool.WithHTTPResponseFields("temperature", "conditions")
+func WithHTTPResponseFields(fields ...string) HTTPOption {
+	return WithHTTPResponse(func(status int, header http.Header, body []byte) (any, error) {
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(body, &object); err != nil {
+			return nil, fmt.Errorf("cannot decode the response: %w", err)
+		}
+		maps.DeleteFunc(object, func(key string, _ json.RawMessage) bool {
+			return !slices.Contains(fields, key)
+		})
+		raw, err := json.Marshal(object)
+		if err != nil {
+			return nil, err
+		}
+		return string(raw), nil
+	})
+}
+
+// NewHTTPTool creates a tool performing an HTTP call.
+//
+// The placeholders of the URL template, like {city}, are replaced by the escaped arguments of
+// the same name; the query parameters and the body are built from the arguments as configured.
+// The response body is handed to the model as text, shaped by WithHTTPResponse when given;
+// an error status fails the call with ErrHTTPStatus and the beginning of the body.
+//
+// Parameters:
+//   - name: The name of the tool.
+//   - method: The HTTP method.
+//   - urlTemplate: The absolute URL, with the placeholders of the arguments.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - *Tool: The HTTP tool.
+//   - error: An error if the method, the URL template or the options are invalid.
+//
+// Example:
+//
+//	weather, err := tool.NewHTTPTool("get_weather", http.MethodGet, "https://api.example.com/weather/{city}",
+//	    tool.WithHTTPDescription("Returns the current weather of a city"),
+//	    tool.WithHTTPQuery("units"),
+//	    tool.WithHTTPHeader("X-Api-Key", apiKey),
+//	    tool.WithHTTPTimeout(10*time.Second),
+//	    tool.WithHTTPResponseFields("temperature", "conditions"))
+func NewHTTPTool(name, method, urlTemplate string, opts ...HTTPOption) (*Tool, error) {
+	method = strings.ToUpper(method)
+	if !slices.Contains(httpMethods, method) {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidHTTPMethod, method)
+	}
+	parsed, err := url.Parse(placeholderPattern.ReplaceAllString(urlTemplate, "x"))
+	if err != nil || !parsed.IsAbs() || parsed.Host == "" {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidURLTemplate, urlTemplate)
+	}
+
+	useOpts := &HTTPOptions{
+		Params:           make(map[string]HTTPParam),
+		Headers:          make(http.Header),
+		MaxResponseBytes: DefaultHTTPMaxResponseBytes,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("cannot create the HTTP tool %s: %w", name, err)
+		}
+	}
+	if len(useOpts.JSONBody) > 0 && useOpts.BodyTemplate != nil {
+		return nil, fmt.Errorf("cannot create the HTTP tool %s: %w", name, ErrHTTPBodyConflict)
+	}
+
+	params := make(map[string]HTTPParam)
+	for _, match := range placeholderPattern.FindAllStringSubmatch(urlTemplate, -1) {
+		params[match[1]] = HTTPParam{Type: "string", Required: true}
+	}
+	for _, argName := range slices.Concat(useOpts.Query, useOpts.JSONBody) {
+		if _, ok := params[argName]; !ok {
+			params[argName] = HTTPParam{Type: "string"}
+		}
+	}
+	maps.Copy(params, useOpts.Params)
+
+	properties := make(map[string]any, len(params))
+	required := make([]string, 0)
+	for argName, param := range params {
+		property := maps.Clone(param.Schema)
+		if property == nil {
+			paramType := param.Type
+			if paramType == "" {
+				paramType = "string"
+			}
+			property = map[string]any{"type": paramType}
+		}
+		if param.Description != "" {
+			property["description"] = param.Description
+		}
+		properties[argName] = property
+		if param.Required {
+			required = append(required, argName)
+		}
+	}
+	slices.Sort(required)
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+
+	var descriptors []string
+	if useOpts.Description != "" {
+		descriptors = append(descriptors, "description:"+useOpts.Description)
+	}
+	rv, err := CreateToolFromSchema(name, schema, httpCallFn(method, urlTemplate, useOpts), descriptors...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create the HTTP tool %s: %w", name, err)
+	}
+	return rv.WithTimeout(useOpts.Timeout)
+}
+
+func httpCallFn(method, urlTemplate string, opts *HTTPOptions) SchemaFn {
+	client := opts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	return func(ctx context.Context, args map[string]any) (any, error) {
+		target, err := expandURL(urlTemplate, args)
+		if err != nil {
+			return nil, err
+		}
+		if len(opts.Query) > 0 {
+			parsed, err := url.Parse(target)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidURLTemplate, err)
+			}
+			query := parsed.Query()
+			for _, argName := range opts.Query {
+				switch value := args[argName].(type) {
+				case nil:
+				case []any:
+					for _, item := range value {
+						query.Add(argName, fmt.Sprint(item))
+					}
+				default:
+					query.Set(argName, fmt.Sprint(value))
+				}
+			}
+			parsed.RawQuery = query.Encode()
+			target = parsed.String()
+		}
+
+		var body io.Reader
+		header := opts.Headers.Clone()
+		switch {
+		case len(opts.JSONBody) > 0:
+			fields := make(map[string]any, len(opts.JSONBody))
+			for _, argName := range opts.JSONBody {
+				if value, ok := args[argName]; ok {
+					fields[argName] = value
+				}
+			}
+			raw, err := json.Marshal(fields)
+			if err != nil {
+				return nil, fmt.Errorf("cannot encode the request body: %w", err)
+			}
+			body = bytes.NewReader(raw)
+			if header.Get("Content-Type") == "" {
+				header.Set("Content-Type", "application/json")
+			}
+		case opts.BodyTemplate != nil:
+			var buf bytes.Buffer
+			if err := opts.BodyTemplate.Execute(&buf, args); err != nil {
+				return nil, fmt.Errorf("cannot render the request body: %w", err)
+			}
+			body = &buf
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, target, body)
+		if err != nil {
+			return nil, fmt.Errorf("cannot create the request: %w", err)
+		}
+		req.Header = header
+		if opts.HeaderFn != nil {
+			if err := opts.HeaderFn(ctx, req.Header); err != nil {
+				return nil, fmt.Errorf("cannot set the request headers: %w", err)
+			}
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("HTTP call failed: %w", err)
+		}
+		defer resp.Body.Close()
+
+		raw, err := io.ReadAll(io.LimitReader(resp.Body, int64(opts.MaxResponseBytes)+1))
+		if err != nil {
+			return nil, fmt.Errorf("cannot read the response: %w", err)
+		}
+		truncated := len(raw) > opts.MaxResponseBytes
+		if truncated {
+			raw = raw[:opts.MaxResponseBytes]
+		}
+
+		if resp.StatusCode >= http.StatusBadRequest {
+			return nil, fmt.Errorf("%w: %s: %s", ErrHTTPStatus, resp.Status, strings.TrimSpace(string(raw)))
+		}
+		if opts.Response != nil {
+			return opts.Response(resp.StatusCode, resp.Header, raw)
+		}
+		if truncated {
+			return string(raw) + "\n[truncated]", nil
+		}
+		return string(raw), nil
+	}
+}
+
+// expandURL replaces the placeholders, path-escaped before the query and query-escaped after it.
+func expandURL(urlTemplate string, args map[string]any) (string, error) {
+	queryStart := strings.IndexByte(urlTemplate, '?')
+	var rv strings.Builder
+	var missing []string
+	last := 0
+	for _, loc := range placeholderPattern.FindAllStringSubmatchIndex(urlTemplate, -1) {
+		rv.WriteString(urlTemplate[last:loc[0]])
+		last = loc[1]
+
+		argName := urlTemplate[loc[2]:loc[3]]
+		value, ok := args[argName]
+		switch {
+		case !ok || value == nil:
+			missing = append(missing, argName)
+		case queryStart >= 0 && loc[0] > queryStart:
+			rv.WriteString(url.QueryEscape(fmt.Sprint(value)))
+		default:
+			rv.WriteString(url.PathEscape(fmt.Sprint(value)))
+		}
+	}
+	rv.WriteString(urlTemplate[last:])
+
+	if len(missing) > 0 {
+		return "", fmt.Errorf("%w: %s", ErrHTTPMissingArg, strings.Join(missing, ", "))
+	}
+	return rv.String(), nil
+}
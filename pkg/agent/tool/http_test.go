@@ -0,0 +1,168 @@
+package tool_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morphy76/ggraph/pkg/agent/tool"
+)
+
+// echoServer answers with the JSON description of the request.
+func echoServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/fail") {
+			http.Error(w, "upstream exploded", http.StatusBadGateway)
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"method":      r.Method,
+			"path":        r.URL.EscapedPath(),
+			"query":       r.URL.RawQuery,
+			"apiKey":      r.Header.Get("X-Api-Key"),
+			"tenant":      r.Header.Get("X-Tenant"),
+			"contentType": r.Header.Get("Content-Type"),
+			"body":        string(body),
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func execute(t *testing.T, httpTool *tool.Tool, ctx context.Context, args map[string]any) map[string]any {
+	result, err := httpTool.Execute(ctx, tool.FnCall{ToolName: httpTool.Name, Arguments: args})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	var rv map[string]any
+	if err := json.Unmarshal([]byte(result.(string)), &rv); err != nil {
+		t.Fatalf("unexpected result %v: %v", result, err)
+	}
+	return rv
+}
+
+func TestNewHTTPTool_Get(t *testing.T) {
+	srv := echoServer(t)
+	weather, err := tool.NewHTTPTool("get_weather", "get", srv.URL+"/weather/{city}?lang={lang}",
+		tool.WithHTTPDescription("Returns the weather"),
+		tool.WithHTTPQuery("units"),
+		tool.WithHTTPHeader("X-Api-Key", "secret"),
+		tool.WithHTTPHeaderFn(func(ctx context.Context, header http.Header) error {
+			header.Set("X-Tenant", tool.MetadataFromContext(ctx)["tenant"])
+			return nil
+		}),
+		tool.WithHTTPTimeout(5*time.Second),
+		tool.WithHTTPResponseFields("method", "path", "query", "apiKey", "tenant"))
+	if err != nil {
+		t.Fatalf("NewHTTPTool failed: %v", err)
+	}
+
+	if weather.Description() != "Returns the weather" || weather.Timeout() != 5*time.Second {
+		t.Errorf("unexpected tool: %+v", weather)
+	}
+	if required := weather.RequiredArgs(); strings.Join(required, ",") != "city,lang" {
+		t.Errorf("expected the placeholders to be required, got %v", required)
+	}
+	properties := weather.Schema()["properties"].(map[string]any)
+	if _, ok := properties["units"]; !ok || len(properties) != 3 {
+		t.Errorf("unexpected properties: %v", properties)
+	}
+
+	ctx := tool.ContextWithMetadata(context.Background(), map[string]string{"tenant": "acme"})
+	rv := execute(t, weather, ctx, map[string]any{"city": "New York", "lang": "en&fr", "units": "metric"})
+	expected := map[string]any{
+		"method": "GET",
+		"path":   "/weather/New%20York",
+		"query":  "lang=en%26fr&units=metric",
+		"apiKey": "secret",
+		"tenant": "acme",
+	}
+	if len(rv) != len(expected) {
+		t.Errorf("expected the response to be reduced to %d fields, got %v", len(expected), rv)
+	}
+	for key, value := range expected {
+		if rv[key] != value {
+			t.Errorf("expected %s=%v, got %v", key, value, rv[key])
+		}
+	}
+
+	if _, err := weather.Execute(ctx, tool.FnCall{ToolName: weather.Name, Arguments: map[string]any{"lang": "en"}}); !errors.Is(err, tool.ErrHTTPMissingArg) {
+		t.Errorf("expected ErrHTTPMissingArg, got %v", err)
+	}
+}
+
+func TestNewHTTPTool_Body(t *testing.T) {
+	srv := echoServer(t)
+
+	create, err := tool.NewHTTPTool("create_ticket", http.MethodPost, srv.URL+"/tickets",
+		tool.WithHTTPJSONBody("title", "priority"),
+		tool.WithHTTPParam("priority", tool.HTTPParam{Type: "integer", Description: "1 to 5"}))
+	if err != nil {
+		t.Fatalf("NewHTTPTool failed: %v", err)
+	}
+	properties := create.Schema()["properties"].(map[string]any)
+	if properties["priority"].(map[string]any)["type"] != "integer" {
+		t.Errorf("expected the declared type, got %v", properties["priority"])
+	}
+	rv := execute(t, create, context.Background(), map[string]any{"title": "Broken", "priority": 2})
+	if rv["body"] != `{"priority":2,"title":"Broken"}` || rv["contentType"] != "application/json" {
+		t.Errorf("unexpected request: %v", rv)
+	}
+
+	search, err := tool.NewHTTPTool("search", http.MethodPost, srv.URL+"/search",
+		tool.WithHTTPBodyTemplate(`{"q": {{json .query}}, "limit": 10}`),
+		tool.WithHTTPParam("query", tool.HTTPParam{Required: true}))
+	if err != nil {
+		t.Fatalf("NewHTTPTool failed: %v", err)
+	}
+	rv = execute(t, search, context.Background(), map[string]any{"query": `say "hi"`})
+	if rv["body"] != `{"q": "say \"hi\"", "limit": 10}` {
+		t.Errorf("unexpected body: %v", rv["body"])
+	}
+}
+
+func TestNewHTTPTool_Response(t *testing.T) {
+	srv := echoServer(t)
+
+	failing, err := tool.NewHTTPTool("fail", http.MethodGet, srv.URL+"/fail")
+	if err != nil {
+		t.Fatalf("NewHTTPTool failed: %v", err)
+	}
+	_, err = failing.Execute(context.Background(), tool.FnCall{ToolName: "fail"})
+	if !errors.Is(err, tool.ErrHTTPStatus) || !strings.Contains(err.Error(), "upstream exploded") {
+		t.Errorf("expected ErrHTTPStatus with the body, got %v", err)
+	}
+
+	truncated, err := tool.NewHTTPTool("echo", http.MethodGet, srv.URL+"/echo", tool.WithHTTPMaxResponseBytes(10))
+	if err != nil {
+		t.Fatalf("NewHTTPTool failed: %v", err)
+	}
+	result, err := truncated.Execute(context.Background(), tool.FnCall{ToolName: "echo"})
+	if err != nil || result != `{"apiKey":`+"\n[truncated]" {
+		t.Errorf("expected a truncated response, got %q, %v", result, err)
+	}
+}
+
+func TestNewHTTPTool_Invalid(t *testing.T) {
+	if _, err := tool.NewHTTPTool("x", "FETCH", "https://example.com"); !errors.Is(err, tool.ErrInvalidHTTPMethod) {
+		t.Errorf("expected ErrInvalidHTTPMethod, got %v", err)
+	}
+	if _, err := tool.NewHTTPTool("x", http.MethodGet, "/relative/{id}"); !errors.Is(err, tool.ErrInvalidURLTemplate) {
+		t.Errorf("expected ErrInvalidURLTemplate, got %v", err)
+	}
+	if _, err := tool.NewHTTPTool("x", http.MethodPost, "https://example.com",
+		tool.WithHTTPJSONBody("a"), tool.WithHTTPBodyTemplate("{{.a}}")); !errors.Is(err, tool.ErrHTTPBodyConflict) {
+		t.Errorf("expected ErrHTTPBodyConflict, got %v", err)
+	}
+	if _, err := tool.NewHTTPTool("x", http.MethodPost, "https://example.com", tool.WithHTTPBodyTemplate("{{")); !errors.Is(err, tool.ErrInvalidHTTPOption) {
+		t.Errorf("expected ErrInvalidHTTPOption, got %v", err)
+	}
+}
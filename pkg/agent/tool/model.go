@@ -1,11 +1,14 @@
 package tool
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var (
@@ -19,6 +22,18 @@ var (
 	ErrInvalidDescriptorFormat = errors.New("invalid descriptor format (role:description expected)")
 	// ErrCallingToolInvalidArgsCount indicates that the number of arguments provided to the tool function is incorrect.
 	ErrCallingToolInvalidArgsCount = errors.New("invalid number of arguments provided to tool function")
+	// ErrToolParamsNotStruct indicates that the parameters of a struct-based tool are not a struct.
+	ErrToolParamsNotStruct = errors.New("tool parameters must be a struct or a pointer to a struct")
+	// ErrToolFnParamsMismatch indicates that the tool function does not accept the parameters struct as its only argument.
+	ErrToolFnParamsMismatch = errors.New("tool function must accept the parameters struct as its only argument")
+	// ErrToolCallAborted indicates that the tool call was cancelled or timed out before completion.
+	ErrToolCallAborted = errors.New("tool call aborted")
+	// ErrInvalidToolTimeout indicates that the tool timeout is negative.
+	ErrInvalidToolTimeout = errors.New("tool timeout cannot be negative")
+	// ErrToolNameEmpty indicates that a tool was created without a name.
+	ErrToolNameEmpty = errors.New("tool name cannot be empty")
+
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
 
 	descriptions = []string{"prompt", "description", "usage"}
 	requiredArgs = []string{"required", "required_args", "mandatory_args"}
@@ -26,8 +41,14 @@ var (
 )
 
 type callable struct {
-	fn reflect.Value
-	in int
+	fn          reflect.Value
+	in          int
+	withContext bool
+
+	params    reflect.Type
+	paramsPtr bool
+
+	dynamic bool
 }
 
 // Arg represents a single argument for a Tool.
@@ -36,6 +57,8 @@ type Arg struct {
 	Name string
 	// Type is the type of the argument.
 	Type string
+	// Description is the description of the argument, if any.
+	Description string
 }
 
 // FnCall represents a single tool call in a conversation.
@@ -63,9 +86,41 @@ func (t FnCall) ArgsAsSortedSlice(tool *Tool) []any {
 	return args
 }
 
+// Clone returns a copy of the tool call, the arguments are copied deeply.
+//
+// Returns:
+//   - FnCall: The copy of the tool call.
+func (t FnCall) Clone() FnCall {
+	if t.Arguments != nil {
+		t.Arguments = cloneValue(t.Arguments).(map[string]any)
+	}
+	return t
+}
+
+// cloneValue copies the maps and slices decoded from JSON arguments, other values are immutable or shared.
+func cloneValue(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		rv := make(map[string]any, len(v))
+		for key, item := range v {
+			rv[key] = cloneValue(item)
+		}
+		return rv
+	case []any:
+		rv := make([]any, len(v))
+		for idx, item := range v {
+			rv[idx] = cloneValue(item)
+		}
+		return rv
+	default:
+		return value
+	}
+}
+
 // Tool represents a callable tool with metadata.
 //
-// The tool function must have the signature: func(args...) (T, error)
+// The tool function must have the signature: func(args...) (T, error), optionally
+// accepting a context.Context as first argument: func(ctx, args...) (T, error).
 type Tool struct {
 	// Name is the name of the tool.
 	Name string
@@ -77,6 +132,10 @@ type Tool struct {
 	toolPrompt   string
 	requiredArgs []string
 	argNames     map[int]string
+	schema       map[string]any
+	timeout      time.Duration
+	policy       *Policy
+	cache        *resultCache
 }
 
 // Call invokes the tool function with the provided arguments.
@@ -95,33 +154,142 @@ type Tool struct {
 //
 //	result, err := myTool.Call(arg1, arg2)
 func (t Tool) Call(args ...any) (any, error) {
+	return t.CallContext(context.Background(), args...)
+}
+
+// CallContext invokes the tool function with the provided context and arguments.
+//
+// The context is passed to tools accepting a context.Context as first argument; the tool
+// timeout, if any, is applied on top of it. When the context is done before the tool
+// returns, an error wrapping ErrToolCallAborted and the context error is returned.
+//
+// Parameters:
+//   - ctx: The context of the call.
+//   - args: The arguments to pass to the tool function.
+//
+// Returns:
+//   - T: The result of the tool function.
+//   - error: An error if the call failed, was aborted or if the argument count is incorrect.
+//
+// Example:
+//
+//	result, err := myTool.CallContext(ctx, arg1, arg2)
+func (t Tool) CallContext(ctx context.Context, args ...any) (any, error) {
 	if len(args) != t.callable.in {
 		return nil, ErrCallingToolInvalidArgsCount
 	}
 
-	in := make([]reflect.Value, len(args))
-	for i, arg := range args {
-		expectedType := t.callable.fn.Type().In(i)
-		argValue := reflect.ValueOf(arg)
+	useCtx := ctx
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		useCtx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
 
-		if argValue.Type() != expectedType {
-			convertedValue, err := convertToType(arg, expectedType)
-			if err != nil {
-				return nil, fmt.Errorf("failed to convert argument %d: %w", i, err)
-			}
-			in[i] = convertedValue
-		} else {
-			in[i] = argValue
-		}
+	if err := useCtx.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrToolCallAborted, err)
 	}
 
-	rvs := t.callable.fn.Call(in)
+	var in []reflect.Value
+	var err error
+	switch {
+	case t.callable.dynamic:
+		in = t.argsAsMap(args...)
+	case t.callable.params != nil:
+		in, err = t.paramsAsValues(args...)
+	default:
+		in, err = t.argsAsValues(args...)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if t.callable.withContext {
+		in = append([]reflect.Value{reflect.ValueOf(useCtx)}, in...)
+	}
 
-	if rvs[1].IsNil() {
-		return rvs[0].Interface(), nil
+	if useCtx.Done() == nil {
+		return t.invoke(in)
 	}
 
-	return nil, rvs[1].Interface().(error)
+	type outcome struct {
+		rv  any
+		err error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		rv, err := t.invoke(in)
+		done <- outcome{rv: rv, err: err}
+	}()
+
+	select {
+	case out := <-done:
+		return out.rv, out.err
+	case <-useCtx.Done():
+		return nil, fmt.Errorf("%w: %w", ErrToolCallAborted, useCtx.Err())
+	}
+}
+
+// Timeout returns the maximum duration of a single call, zero when unbounded.
+//
+// Returns:
+//   - time.Duration: The tool timeout.
+func (t Tool) Timeout() time.Duration {
+	return t.timeout
+}
+
+// WithTimeout sets the maximum duration of a single call of the tool.
+//
+// Parameters:
+//   - timeout: The maximum duration, zero disables the timeout.
+//
+// Returns:
+//   - *Tool: The tool itself, to allow chaining.
+//   - error: An error if the timeout is negative.
+//
+// Example:
+//
+//	myTool, err = myTool.WithTimeout(5 * time.Second)
+func (t *Tool) WithTimeout(timeout time.Duration) (*Tool, error) {
+	if timeout < 0 {
+		return nil, ErrInvalidToolTimeout
+	}
+	t.timeout = timeout
+	return t, nil
+}
+
+// Schema returns the JSON schema of the tool parameters.
+//
+// Only tools created with CreateToolFromStruct carry a schema.
+//
+// Returns:
+//   - map[string]any: The JSON schema of the parameters, or nil if the tool has none.
+func (t Tool) Schema() map[string]any {
+	return t.schema
+}
+
+// Parameters returns the JSON schema of the tool parameters sent to the model.
+//
+// It is the schema of the tool when it carries one, otherwise it is derived from the arguments
+// and the required arguments of the tool.
+//
+// Returns:
+//   - map[string]any: The JSON schema of the parameters.
+func (t *Tool) Parameters() map[string]any {
+	if t.schema != nil {
+		return t.schema
+	}
+
+	properties := make(map[string]any, len(t.Args))
+	for _, arg := range t.Args {
+		properties[arg.Name] = map[string]any{
+			"type": jsonType(arg.Type),
+		}
+	}
+	return map[string]any{
+		"type":       "object",
+		"properties": properties,
+		"required":   t.RequiredArgs(),
+	}
 }
 
 // Description returns the tool's description.
@@ -238,6 +406,73 @@ func (t *Tool) InputNameByIdx(idx int) string {
 	return "arg" + strconv.Itoa(idx)
 }
 
+func (t Tool) argsAsValues(args ...any) ([]reflect.Value, error) {
+	offset := 0
+	if t.callable.withContext {
+		offset = 1
+	}
+
+	in := make([]reflect.Value, len(args))
+	for i, arg := range args {
+		expectedType := t.callable.fn.Type().In(i + offset)
+		argValue := reflect.ValueOf(arg)
+
+		if argValue.Type() != expectedType {
+			convertedValue, err := convertToType(arg, expectedType)
+			if err != nil {
+				return nil, fmt.Errorf("failed to convert argument %d: %w", i, err)
+			}
+			in[i] = convertedValue
+		} else {
+			in[i] = argValue
+		}
+	}
+	return in, nil
+}
+
+func (t Tool) argsAsMap(args ...any) []reflect.Value {
+	fields := make(map[string]any, len(args))
+	for i, arg := range args {
+		if arg != nil {
+			fields[t.Args[i].Name] = arg
+		}
+	}
+	return []reflect.Value{reflect.ValueOf(fields)}
+}
+
+func (t Tool) paramsAsValues(args ...any) ([]reflect.Value, error) {
+	fields := make(map[string]any, len(args))
+	for i, arg := range args {
+		if arg != nil {
+			fields[t.Args[i].Name] = arg
+		}
+	}
+
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode the tool parameters: %w", err)
+	}
+	params := reflect.New(t.callable.params)
+	if err := json.Unmarshal(raw, params.Interface()); err != nil {
+		return nil, fmt.Errorf("failed to decode the tool parameters: %w", err)
+	}
+
+	if t.callable.paramsPtr {
+		return []reflect.Value{params}, nil
+	}
+	return []reflect.Value{params.Elem()}, nil
+}
+
+func (t Tool) invoke(in []reflect.Value) (any, error) {
+	rvs := t.callable.fn.Call(in)
+
+	if rvs[1].IsNil() {
+		return rvs[0].Interface(), nil
+	}
+
+	return nil, rvs[1].Interface().(error)
+}
+
 func (t Tool) descriptionForRoles(role ...string) string {
 	for _, r := range role {
 		if desc, ok := t.descriptions[r]; ok {
@@ -293,3 +528,23 @@ func convertToType(value any, targetType reflect.Type) (reflect.Value, error) {
 
 	return reflect.Value{}, fmt.Errorf("cannot convert %v to %v", sourceType, targetType)
 }
+
+func jsonType(argType string) string {
+	switch argType {
+	case "string":
+		return "string"
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64":
+		return "integer"
+	case "float32", "float64":
+		return "number"
+	case "bool":
+		return "boolean"
+	case "[]string", "[]int", "[]float64", "[]bool":
+		return "array"
+	case "map[string]interface{}":
+		return "object"
+	default:
+		return "string"
+	}
+}
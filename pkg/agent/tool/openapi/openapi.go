@@ -0,0 +1,591 @@
+// Package openapi generates tools from OpenAPI 3 documents.
+//
+// Each operation of the document becomes an HTTP tool (see tool.NewHTTPTool): the path and query
+// parameters and the fields of a JSON request body are the arguments of the tool, with their
+// schemas; the security schemes of the operation are applied with the configured credentials and
+// the responses are summarized before being handed to the model.
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/morphy76/ggraph/pkg/agent/tool"
+)
+
+const (
+	maxToolNameLength = 64
+	bodyArg           = "body"
+)
+
+var (
+	// ErrInvalidDocument indicates that the document is not a valid OpenAPI 3 document.
+	ErrInvalidDocument = errors.New("invalid OpenAPI 3 document")
+	// ErrNoServer indicates that the document has no absolute server URL and no base URL was given.
+	ErrNoServer = errors.New("no absolute server URL")
+	// ErrUnresolvedRef indicates that a reference of the document cannot be resolved.
+	ErrUnresolvedRef = errors.New("unresolved OpenAPI reference")
+
+	operationMethods = []string{"get", "put", "post", "delete", "options", "head", "patch"}
+	// openAPIKeywords are the keywords of the OpenAPI schemas which are not JSON schema keywords.
+	openAPIKeywords = []string{"nullable", "readOnly", "writeOnly", "xml", "example", "externalDocs", "discriminator", "deprecated"}
+)
+
+// Document is a parsed OpenAPI 3 document.
+type Document struct {
+	// Title is the title of the API.
+	Title string
+	// Version is the version of the API.
+	Version string
+
+	spec map[string]any
+}
+
+// Operation describes an operation of the document.
+type Operation struct {
+	// ID is the operationId, empty when the document does not declare it.
+	ID string
+	// Method is the upper case HTTP method.
+	Method string
+	// Path is the path template, like /pets/{petId}.
+	Path string
+	// Summary is the short description of the operation.
+	Summary string
+	// Description is the long description of the operation.
+	Description string
+	// Tags are the tags of the operation.
+	Tags []string
+
+	pathItem  map[string]any
+	operation map[string]any
+}
+
+// Parse parses an OpenAPI 3 document.
+//
+// Parameters:
+//   - data: The document, either JSON or YAML.
+//
+// Returns:
+//   - *Document: The parsed document.
+//   - error: ErrInvalidDocument if the document cannot be decoded or is not an OpenAPI 3 document.
+//
+// Example:
+//
+//	raw, _ := os.ReadFile("petstore.yaml")
+//	doc, err := openapi.Parse(raw)
+func Parse(data []byte) (*Document, error) {
+	var raw any
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidDocument, err)
+	}
+	spec, ok := normalize(raw).(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: the document is not an object", ErrInvalidDocument)
+	}
+	if version := fmt.Sprint(spec["openapi"]); !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("%w: unsupported version %q", ErrInvalidDocument, version)
+	}
+	if _, ok := spec["paths"].(map[string]any); !ok {
+		return nil, fmt.Errorf("%w: missing paths", ErrInvalidDocument)
+	}
+
+	info, _ := spec["info"].(map[string]any)
+	return &Document{
+		Title:   stringOf(info["title"]),
+		Version: stringOf(info["version"]),
+		spec:    spec,
+	}, nil
+}
+
+// Operations lists the operations of the document, by path and method.
+//
+// Returns:
+//   - The operations.
+func (d *Document) Operations() []Operation {
+	paths := d.spec["paths"].(map[string]any)
+	rv := make([]Operation, 0, len(paths))
+	for _, path := range slices.Sorted(maps.Keys(paths)) {
+		pathItem, err := d.resolve(paths[path])
+		if err != nil {
+			continue
+		}
+		for _, method := range operationMethods {
+			operation, ok := pathItem[method].(map[string]any)
+			if !ok {
+				continue
+			}
+			var tags []string
+			for _, tag := range sliceOf(operation["tags"]) {
+				tags = append(tags, stringOf(tag))
+			}
+			rv = append(rv, Operation{
+				ID:          stringOf(operation["operationId"]),
+				Method:      strings.ToUpper(method),
+				Path:        path,
+				Summary:     stringOf(operation["summary"]),
+				Description: stringOf(operation["description"]),
+				Tags:        tags,
+				pathItem:    pathItem,
+				operation:   operation,
+			})
+		}
+	}
+	return rv
+}
+
+// Tools generates a tool for each operation of the document.
+//
+// The tool is named after the operationId, or the method and the path when the operation has no
+// identifier. The header and cookie parameters are not arguments of the tools, set them with
+// WithHTTPOptions or with the credentials of the security schemes.
+//
+// Parameters:
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - []*tool.Tool: The tools, in the order of Operations.
+//   - error: An error if the options are invalid or an operation cannot be converted.
+//
+// Example:
+//
+//	tools, err := doc.Tools(
+//	    openapi.WithTags("pets"),
+//	    openapi.WithCredential("apiKey", os.Getenv("PETSTORE_API_KEY")),
+//	    openapi.WithTimeout(10*time.Second))
+//	node, err := openai.CreateConversationNode("Chat", "gpt-4o-mini", client, openai.ToolAgentNodeFn(5), a.WithTools(tools...))
+func (d *Document) Tools(opts ...Option) ([]*tool.Tool, error) {
+	useOpts := &Options{
+		Credentials:      make(map[string]CredentialFn),
+		MaxResponseBytes: DefaultMaxResponseBytes,
+		MaxItems:         DefaultMaxItems,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("cannot generate the OpenAPI tools: %w", err)
+		}
+	}
+
+	rv := make([]*tool.Tool, 0)
+	names := make(map[string]string)
+	for _, operation := range d.Operations() {
+		if len(useOpts.Operations) > 0 && !slices.Contains(useOpts.Operations, operation.ID) {
+			continue
+		}
+		if len(useOpts.Tags) > 0 && !slices.ContainsFunc(operation.Tags, func(tag string) bool {
+			return slices.Contains(useOpts.Tags, tag)
+		}) {
+			continue
+		}
+
+		name := toolName(operation)
+		if other, ok := names[name]; ok {
+			return nil, fmt.Errorf("%w: operations %s and %s %s have the same tool name %s", ErrInvalidDocument, other, operation.Method, operation.Path, name)
+		}
+		names[name] = operation.Method + " " + operation.Path
+
+		operationTool, err := d.operationTool(name, operation, useOpts)
+		if err != nil {
+			return nil, fmt.Errorf("cannot generate the tool of %s %s: %w", operation.Method, operation.Path, err)
+		}
+		rv = append(rv, operationTool)
+	}
+	return rv, nil
+}
+
+func (d *Document) operationTool(name string, operation Operation, opts *Options) (*tool.Tool, error) {
+	baseURL, err := d.baseURL(operation, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	description := strings.TrimSpace(strings.Join([]string{operation.Summary, operation.Description}, "\n\n"))
+	if description == "" {
+		description = operation.Method + " " + operation.Path
+	}
+	summarizer := opts.Summarizer
+	if summarizer == nil {
+		summarizer = summarize(opts.MaxItems)
+	}
+	httpOpts := []tool.HTTPOption{
+		tool.WithHTTPDescription(description),
+		tool.WithHTTPTimeout(opts.Timeout),
+		tool.WithHTTPMaxResponseBytes(opts.MaxResponseBytes),
+		tool.WithHTTPResponse(summarizer),
+	}
+
+	paramOpts, argNames, err := d.parameterOptions(operation)
+	if err != nil {
+		return nil, err
+	}
+	httpOpts = append(httpOpts, paramOpts...)
+	bodyOpts, err := d.requestBodyOptions(operation, argNames)
+	if err != nil {
+		return nil, err
+	}
+	httpOpts = append(httpOpts, bodyOpts...)
+
+	client, err := d.securedClient(operation, opts)
+	if err != nil {
+		return nil, err
+	}
+	httpOpts = append(httpOpts, tool.WithHTTPClient(client))
+	httpOpts = append(httpOpts, opts.HTTPOptions...)
+
+	return tool.NewHTTPTool(name, operation.Method, strings.TrimSuffix(baseURL, "/")+operation.Path, httpOpts...)
+}
+
+// baseURL returns the first server of the operation, of its path or of the document, with the default values of its variables.
+func (d *Document) baseURL(operation Operation, opts *Options) (string, error) {
+	if opts.BaseURL != "" {
+		return opts.BaseURL, nil
+	}
+	servers := sliceOf(operation.operation["servers"])
+	if len(servers) == 0 {
+		servers = sliceOf(operation.pathItem["servers"])
+	}
+	if len(servers) == 0 {
+		servers = sliceOf(d.spec["servers"])
+	}
+	if len(servers) == 0 {
+		return "", ErrNoServer
+	}
+
+	server, _ := servers[0].(map[string]any)
+	rv := stringOf(server["url"])
+	variables, _ := server["variables"].(map[string]any)
+	for variable, definition := range variables {
+		definition, _ := definition.(map[string]any)
+		rv = strings.ReplaceAll(rv, "{"+variable+"}", stringOf(definition["default"]))
+	}
+	if parsed, err := url.Parse(rv); err != nil || !parsed.IsAbs() {
+		return "", fmt.Errorf("%w: %q, use WithBaseURL", ErrNoServer, rv)
+	}
+	return rv, nil
+}
+
+// parameterOptions declares the path and query parameters, the ones of the operation override the ones of the path.
+func (d *Document) parameterOptions(operation Operation) ([]tool.HTTPOption, []string, error) {
+	type paramKey struct{ name, in string }
+	params := make(map[paramKey]map[string]any)
+	var order []paramKey
+	for _, raw := range slices.Concat(sliceOf(operation.pathItem["parameters"]), sliceOf(operation.operation["parameters"])) {
+		param, err := d.resolve(raw)
+		if err != nil {
+			return nil, nil, err
+		}
+		key := paramKey{name: stringOf(param["name"]), in: stringOf(param["in"])}
+		if _, ok := params[key]; !ok {
+			order = append(order, key)
+		}
+		params[key] = param
+	}
+
+	var rv []tool.HTTPOption
+	var argNames []string
+	for _, key := range order {
+		if key.in != "path" && key.in != "query" {
+			continue
+		}
+		param := params[key]
+		schema, err := d.schemaOf(param["schema"])
+		if err != nil {
+			return nil, nil, err
+		}
+		required, _ := param["required"].(bool)
+		rv = append(rv, tool.WithHTTPParam(key.name, tool.HTTPParam{
+			Schema:      schema,
+			Description: stringOf(param["description"]),
+			Required:    required || key.in == "path",
+		}))
+		if key.in == "query" {
+			rv = append(rv, tool.WithHTTPQuery(key.name))
+		}
+		argNames = append(argNames, key.name)
+	}
+	return rv, argNames, nil
+}
+
+// requestBodyOptions sends the properties of a JSON object body as arguments, any other body as the body argument.
+func (d *Document) requestBodyOptions(operation Operation, argNames []string) ([]tool.HTTPOption, error) {
+	raw, ok := operation.operation["requestBody"]
+	if !ok {
+		return nil, nil
+	}
+	requestBody, err := d.resolve(raw)
+	if err != nil {
+		return nil, err
+	}
+	content, _ := requestBody["content"].(map[string]any)
+	if len(content) == 0 {
+		return nil, nil
+	}
+	required, _ := requestBody["required"].(bool)
+	description := stringOf(requestBody["description"])
+
+	mediaTypes := slices.Sorted(maps.Keys(content))
+	mediaType := mediaTypes[0]
+	if index := slices.IndexFunc(mediaTypes, isJSON); index >= 0 {
+		mediaType = mediaTypes[index]
+	}
+	media, _ := content[mediaType].(map[string]any)
+	schema, err := d.schemaOf(media["schema"])
+	if err != nil {
+		return nil, err
+	}
+
+	if !isJSON(mediaType) {
+		return []tool.HTTPOption{
+			tool.WithHTTPParam(bodyArg, tool.HTTPParam{Description: description, Required: required}),
+			tool.WithHTTPBodyTemplate("{{." + bodyArg + "}}"),
+			tool.WithHTTPHeader("Content-Type", mediaType),
+		}, nil
+	}
+
+	properties, _ := schema["properties"].(map[string]any)
+	if schema["type"] == "object" && len(properties) > 0 && !slices.ContainsFunc(argNames, func(argName string) bool {
+		_, ok := properties[argName]
+		return ok
+	}) {
+		var requiredFields []string
+		for _, field := range sliceOf(schema["required"]) {
+			requiredFields = append(requiredFields, stringOf(field))
+		}
+		fields := slices.Sorted(maps.Keys(properties))
+		rv := []tool.HTTPOption{tool.WithHTTPJSONBody(fields...), tool.WithHTTPHeader("Content-Type", mediaType)}
+		for _, field := range fields {
+			property, _ := properties[field].(map[string]any)
+			rv = append(rv, tool.WithHTTPParam(field, tool.HTTPParam{
+				Schema:   property,
+				Required: required && slices.Contains(requiredFields, field),
+			}))
+		}
+		return rv, nil
+	}
+
+	return []tool.HTTPOption{
+		tool.WithHTTPParam(bodyArg, tool.HTTPParam{Schema: schema, Description: description, Required: required}),
+		tool.WithHTTPBodyTemplate("{{json ." + bodyArg + "}}"),
+		tool.WithHTTPHeader("Content-Type", mediaType),
+	}, nil
+}
+
+// schemaOf inlines the references of a schema and removes the OpenAPI keywords, nil for a missing schema.
+func (d *Document) schemaOf(raw any) (map[string]any, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	schema, err := d.inline(raw, true, make(map[string]bool))
+	if err != nil {
+		return nil, err
+	}
+	rv, _ := schema.(map[string]any)
+	return rv, nil
+}
+
+// inline replaces the references by their targets; a recursive reference becomes an unconstrained schema.
+func (d *Document) inline(node any, isSchema bool, visiting map[string]bool) (any, error) {
+	switch value := node.(type) {
+	case map[string]any:
+		if ref, ok := value["$ref"].(string); ok && isSchema {
+			if visiting[ref] {
+				return map[string]any{}, nil
+			}
+			target, err := d.lookup(ref)
+			if err != nil {
+				return nil, err
+			}
+			visiting[ref] = true
+			defer delete(visiting, ref)
+			return d.inline(target, true, visiting)
+		}
+		rv := make(map[string]any, len(value))
+		for key, item := range value {
+			if isSchema && (slices.Contains(openAPIKeywords, key) || strings.HasPrefix(key, "x-")) {
+				continue
+			}
+			// the keys of the properties are names, their values are schemas
+			childIsSchema := !isSchema || (key != "properties" && key != "patternProperties")
+			inlined, err := d.inline(item, childIsSchema, visiting)
+			if err != nil {
+				return nil, err
+			}
+			rv[key] = inlined
+		}
+		return rv, nil
+	case []any:
+		rv := make([]any, len(value))
+		for index, item := range value {
+			inlined, err := d.inline(item, isSchema, visiting)
+			if err != nil {
+				return nil, err
+			}
+			rv[index] = inlined
+		}
+		return rv, nil
+	}
+	return node, nil
+}
+
+// resolve follows the references of an object of the document.
+func (d *Document) resolve(node any) (map[string]any, error) {
+	for range 10 {
+		object, ok := node.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("%w: expected an object", ErrInvalidDocument)
+		}
+		ref, ok := object["$ref"].(string)
+		if !ok {
+			return object, nil
+		}
+		target, err := d.lookup(ref)
+		if err != nil {
+			return nil, err
+		}
+		node = target
+	}
+	return nil, fmt.Errorf("%w: too many nested references", ErrUnresolvedRef)
+}
+
+// lookup returns the target of a local JSON pointer reference.
+func (d *Document) lookup(ref string) (any, error) {
+	pointer, ok := strings.CutPrefix(ref, "#/")
+	if !ok {
+		return nil, fmt.Errorf("%w: only local references are supported, got %s", ErrUnresolvedRef, ref)
+	}
+	var node any = d.spec
+	for _, token := range strings.Split(pointer, "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch value := node.(type) {
+		case map[string]any:
+			if node, ok = value[token]; !ok {
+				return nil, fmt.Errorf("%w: %s", ErrUnresolvedRef, ref)
+			}
+		case []any:
+			index, err := strconv.Atoi(token)
+			if err != nil || index < 0 || index >= len(value) {
+				return nil, fmt.Errorf("%w: %s", ErrUnresolvedRef, ref)
+			}
+			node = value[index]
+		default:
+			return nil, fmt.Errorf("%w: %s", ErrUnresolvedRef, ref)
+		}
+	}
+	return node, nil
+}
+
+// toolName sanitizes the operationId, or the method and the path, to the characters accepted by the models.
+func toolName(operation Operation) string {
+	name := operation.ID
+	if name == "" {
+		name = strings.ToLower(operation.Method) + "_" + operation.Path
+	}
+	var rv strings.Builder
+	lastUnderscore := false
+	for _, r := range name {
+		valid := r == '-' || r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+		if !valid {
+			r = '_'
+		}
+		if r == '_' && lastUnderscore {
+			continue
+		}
+		lastUnderscore = r == '_'
+		rv.WriteRune(r)
+	}
+	sanitized := strings.Trim(rv.String(), "_")
+	if len(sanitized) > maxToolNameLength {
+		sanitized = sanitized[:maxToolNameLength]
+	}
+	return sanitized
+}
+
+// summarize compacts the JSON responses and cuts their arrays to maxItems elements.
+func summarize(maxItems int) tool.HTTPResponseFn {
+	return func(status int, header http.Header, body []byte) (any, error) {
+		if len(bytes.TrimSpace(body)) == 0 {
+			return fmt.Sprintf("%d %s", status, http.StatusText(status)), nil
+		}
+		decoder := json.NewDecoder(bytes.NewReader(body))
+		decoder.UseNumber()
+		var value any
+		if err := decoder.Decode(&value); err != nil {
+			return string(body), nil
+		}
+		raw, err := json.Marshal(cutArrays(value, maxItems))
+		if err != nil {
+			return nil, fmt.Errorf("cannot summarize the response: %w", err)
+		}
+		if len(raw) > tool.DefaultHTTPMaxResponseBytes {
+			return string(raw[:tool.DefaultHTTPMaxResponseBytes]) + "\n[truncated]", nil
+		}
+		return string(raw), nil
+	}
+}
+
+func cutArrays(value any, maxItems int) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, item := range typed {
+			typed[key] = cutArrays(item, maxItems)
+		}
+	case []any:
+		if len(typed) > maxItems {
+			omitted := len(typed) - maxItems
+			typed = append(typed[:maxItems:maxItems], fmt.Sprintf("... %d more items", omitted))
+		}
+		for index, item := range typed {
+			typed[index] = cutArrays(item, maxItems)
+		}
+		return typed
+	}
+	return value
+}
+
+// normalize converts the mappings with non-string keys decoded from YAML, like the response codes.
+func normalize(node any) any {
+	switch value := node.(type) {
+	case map[string]any:
+		for key, item := range value {
+			value[key] = normalize(item)
+		}
+		return value
+	case map[any]any:
+		rv := make(map[string]any, len(value))
+		for key, item := range value {
+			rv[fmt.Sprint(key)] = normalize(item)
+		}
+		return rv
+	case []any:
+		for index, item := range value {
+			value[index] = normalize(item)
+		}
+	}
+	return node
+}
+
+func isJSON(mediaType string) bool {
+	base, _, _ := strings.Cut(mediaType, ";")
+	return base == "application/json" || strings.HasSuffix(base, "+json")
+}
+
+func stringOf(value any) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func sliceOf(value any) []any {
+	rv, _ := value.([]any)
+	return rv
+}
@@ -0,0 +1,247 @@
+package openapi_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/morphy76/ggraph/pkg/agent/tool"
+	"github.com/morphy76/ggraph/pkg/agent/tool/openapi"
+)
+
+const petstore = `
+openapi: 3.0.3
+info:
+  title: Petstore
+  version: "1.0"
+servers:
+  - url: "{scheme}://petstore.example.com/v1"
+    variables:
+      scheme:
+        default: https
+security:
+  - apiKey: []
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      summary: Lists the pets
+      tags: [pets]
+      parameters:
+        - $ref: "#/components/parameters/Limit"
+      responses:
+        200:
+          description: The pets
+    post:
+      operationId: createPet
+      summary: Creates a pet
+      tags: [pets]
+      security:
+        - bearerAuth: []
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: "#/components/schemas/NewPet"
+      responses:
+        201:
+          description: Created
+  /pets/{petId}:
+    parameters:
+      - name: petId
+        in: path
+        description: The pet identifier
+        schema:
+          type: integer
+    get:
+      summary: Returns a pet
+      tags: [pets]
+      responses:
+        200:
+          description: The pet
+  /status:
+    get:
+      operationId: status
+      tags: [admin]
+      security: []
+      responses:
+        200:
+          description: OK
+components:
+  parameters:
+    Limit:
+      name: limit
+      in: query
+      schema:
+        type: integer
+        example: 10
+  schemas:
+    NewPet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+        tag:
+          type: string
+          nullable: true
+        parent:
+          $ref: "#/components/schemas/NewPet"
+  securitySchemes:
+    apiKey:
+      type: apiKey
+      in: query
+      name: api_key
+    bearerAuth:
+      type: http
+      scheme: bearer
+`
+
+func petstoreServer(t *testing.T) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/v1/pets" && r.Method == http.MethodGet {
+			_, _ = w.Write([]byte(`[{"id": 1}, {"id": 2}, {"id": 3}]`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"method": r.Method,
+			"path":   r.URL.Path,
+			"query":  r.URL.RawQuery,
+			"auth":   r.Header.Get("Authorization"),
+			"body":   string(body),
+		})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestParse(t *testing.T) {
+	doc, err := openapi.Parse([]byte(petstore))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if doc.Title != "Petstore" || doc.Version != "1.0" {
+		t.Errorf("unexpected info: %s %s", doc.Title, doc.Version)
+	}
+	var operations []string
+	for _, operation := range doc.Operations() {
+		operations = append(operations, operation.Method+" "+operation.Path)
+	}
+	if strings.Join(operations, ",") != "GET /pets,POST /pets,GET /pets/{petId},GET /status" {
+		t.Errorf("unexpected operations: %v", operations)
+	}
+
+	if _, err := openapi.Parse([]byte(`swagger: "2.0"`)); !errors.Is(err, openapi.ErrInvalidDocument) {
+		t.Errorf("expected ErrInvalidDocument, got %v", err)
+	}
+	if _, err := openapi.Parse([]byte(`{"openapi": "3.1.0"}`)); !errors.Is(err, openapi.ErrInvalidDocument) {
+		t.Errorf("expected ErrInvalidDocument without paths, got %v", err)
+	}
+}
+
+func TestTools(t *testing.T) {
+	srv := petstoreServer(t)
+	doc, err := openapi.Parse([]byte(petstore))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	tools, err := doc.Tools(
+		openapi.WithBaseURL(srv.URL+"/v1"),
+		openapi.WithTags("pets"),
+		openapi.WithCredential("apiKey", "secret"),
+		openapi.WithCredentialFn("bearerAuth", func(ctx context.Context) (string, error) {
+			return "token-" + tool.MetadataFromContext(ctx)["tenant"], nil
+		}),
+		openapi.WithMaxItems(2))
+	if err != nil {
+		t.Fatalf("Tools failed: %v", err)
+	}
+	byName := make(map[string]*tool.Tool)
+	for _, generated := range tools {
+		byName[generated.Name] = generated
+	}
+	if len(tools) != 3 || byName["listPets"] == nil || byName["createPet"] == nil || byName["get_pets_petId"] == nil {
+		t.Fatalf("unexpected tools: %v", byName)
+	}
+
+	getPet := byName["get_pets_petId"]
+	if getPet.Description() != "Returns a pet" || strings.Join(getPet.RequiredArgs(), ",") != "petId" {
+		t.Errorf("unexpected tool: %s %v", getPet.Description(), getPet.RequiredArgs())
+	}
+	petID := getPet.Schema()["properties"].(map[string]any)["petId"].(map[string]any)
+	if petID["type"] != "integer" || petID["description"] != "The pet identifier" {
+		t.Errorf("unexpected parameter schema: %v", petID)
+	}
+	rv := execute(t, getPet, context.Background(), map[string]any{"petId": 7})
+	if rv["path"] != "/v1/pets/7" || rv["query"] != "api_key=secret" {
+		t.Errorf("unexpected request: %v", rv)
+	}
+
+	limit := byName["listPets"].Schema()["properties"].(map[string]any)["limit"].(map[string]any)
+	if _, ok := limit["example"]; ok || limit["type"] != "integer" {
+		t.Errorf("expected a JSON schema without the OpenAPI keywords, got %v", limit)
+	}
+	result, err := byName["listPets"].Execute(context.Background(), tool.FnCall{ToolName: "listPets", Arguments: map[string]any{"limit": 3}})
+	if err != nil || result != `[{"id":1},{"id":2},"... 1 more items"]` {
+		t.Errorf("expected a summarized response, got %v, %v", result, err)
+	}
+
+	createPet := byName["createPet"]
+	if strings.Join(createPet.RequiredArgs(), ",") != "name" {
+		t.Errorf("expected the required body fields, got %v", createPet.RequiredArgs())
+	}
+	parent := createPet.Schema()["properties"].(map[string]any)["parent"].(map[string]any)
+	if len(parent) != 0 {
+		t.Errorf("expected the recursive reference to be unconstrained, got %v", parent)
+	}
+	ctx := tool.ContextWithMetadata(context.Background(), map[string]string{"tenant": "acme"})
+	rv = execute(t, createPet, ctx, map[string]any{"name": "Rex", "tag": "dog"})
+	if rv["method"] != http.MethodPost || rv["body"] != `{"name":"Rex","tag":"dog"}` || rv["auth"] != "Bearer token-acme" || rv["query"] != "" {
+		t.Errorf("unexpected request: %v", rv)
+	}
+}
+
+func TestTools_Errors(t *testing.T) {
+	doc, err := openapi.Parse([]byte(strings.Replace(petstore, "{scheme}://petstore.example.com/v1", "/v1", 1)))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if _, err := doc.Tools(); !errors.Is(err, openapi.ErrNoServer) {
+		t.Errorf("expected ErrNoServer, got %v", err)
+	}
+	if tools, err := doc.Tools(openapi.WithOperations("status"), openapi.WithBaseURL("https://example.com")); err != nil || len(tools) != 1 {
+		t.Errorf("expected the status tool only, got %v, %v", tools, err)
+	}
+
+	broken, err := openapi.Parse([]byte(strings.Replace(petstore, "#/components/parameters/Limit", "#/components/parameters/Missing", 1)))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if _, err := broken.Tools(); !errors.Is(err, openapi.ErrUnresolvedRef) {
+		t.Errorf("expected ErrUnresolvedRef, got %v", err)
+	}
+	if _, err := doc.Tools(openapi.WithMaxItems(0)); !errors.Is(err, openapi.ErrInvalidOption) {
+		t.Errorf("expected ErrInvalidOption, got %v", err)
+	}
+}
+
+func execute(t *testing.T, generated *tool.Tool, ctx context.Context, args map[string]any) map[string]any {
+	result, err := generated.Execute(ctx, tool.FnCall{ToolName: generated.Name, Arguments: args})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	var rv map[string]any
+	if err := json.Unmarshal([]byte(result.(string)), &rv); err != nil {
+		t.Fatalf("unexpected result %v: %v", result, err)
+	}
+	return rv
+}
@@ -0,0 +1,276 @@
+package openapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/morphy76/ggraph/pkg/agent/tool"
+)
+
+const (
+	// DefaultMaxResponseBytes is the default number of bytes of the response body read from the API before its summarization.
+	DefaultMaxResponseBytes = 1024 * 1024
+	// DefaultMaxItems is the default number of elements of a JSON array kept by the response summarization.
+	DefaultMaxItems = 20
+)
+
+var (
+	// ErrInvalidOption indicates that an option of the generator has an invalid value.
+	ErrInvalidOption = errors.New("invalid OpenAPI option")
+)
+
+// CredentialFn returns the credential of a security scheme for a call, e.g. from the metadata of the thread.
+//
+// The credential is the API key of an apiKey scheme, the token of a bearer, oauth2 or openIdConnect
+// scheme and the "user:password" pair of a basic scheme.
+//
+// Parameters:
+//   - ctx: The context of the tool call.
+//
+// Returns:
+//   - The credential.
+//   - An error if the credential is not available.
+type CredentialFn func(ctx context.Context) (string, error)
+
+// Options holds the configuration of the tool generation.
+type Options struct {
+	// BaseURL overrides the first server of the document.
+	BaseURL string
+	// Operations restricts the generation to the operations with the given identifiers, all when empty.
+	Operations []string
+	// Tags restricts the generation to the operations with at least one of the given tags, all when empty.
+	Tags []string
+	// Credentials are the credentials of the security schemes, by scheme name.
+	Credentials map[string]CredentialFn
+	// Client is the HTTP client, http.DefaultClient if nil.
+	Client *http.Client
+	// Timeout bounds a call, 0 for no bound.
+	Timeout time.Duration
+	// MaxResponseBytes bounds the response body read from the API.
+	MaxResponseBytes int
+	// MaxItems bounds the elements of the JSON arrays handed to the model by the default summarization.
+	MaxItems int
+	// Summarizer shapes the responses, replacing the default summarization.
+	Summarizer tool.HTTPResponseFn
+	// HTTPOptions are added to the options of every generated tool.
+	HTTPOptions []tool.HTTPOption
+}
+
+// Option is a functional option for configuring the tool generation.
+type Option interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - o: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(o *Options) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc func(*Options) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - o: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc) Apply(o *Options) error { return s(o) }
+
+// WithBaseURL sets the base URL of the API, overriding the servers of the document.
+//
+// Parameters:
+//   - baseURL: The absolute base URL, the paths of the operations are appended to it.
+//
+// Returns:
+//   - An Option that sets the base URL.
+func WithBaseURL(baseURL string) Option {
+	return OptionFunc(func(o *Options) error {
+		if baseURL == "" {
+			return fmt.Errorf("%w: empty base URL", ErrInvalidOption)
+		}
+		o.BaseURL = baseURL
+		return nil
+	})
+}
+
+// WithOperations restricts the generation to the given operations.
+//
+// Parameters:
+//   - operationIDs: The identifiers of the operations.
+//
+// Returns:
+//   - An Option that sets the operations.
+//
+// Example:
+//
+//	tools, err := doc.Tools(openapi.WithOperations("listPets", "getPet"))
+func WithOperations(operationIDs ...string) Option {
+	return OptionFunc(func(o *Options) error {
+		o.Operations = append(o.Operations, operationIDs...)
+		return nil
+	})
+}
+
+// WithTags restricts the generation to the operations with at least one of the given tags.
+//
+// Parameters:
+//   - tags: The tags of the operations.
+//
+// Returns:
+//   - An Option that sets the tags.
+func WithTags(tags ...string) Option {
+	return OptionFunc(func(o *Options) error {
+		o.Tags = append(o.Tags, tags...)
+		return nil
+	})
+}
+
+// WithCredential sets the static credential of a security scheme of the document.
+//
+// Parameters:
+//   - scheme: The name of the scheme in the securitySchemes of the components.
+//   - credential: The credential, see CredentialFn.
+//
+// Returns:
+//   - An Option that sets the credential.
+func WithCredential(scheme, credential string) Option {
+	return WithCredentialFn(scheme, func(context.Context) (string, error) {
+		return credential, nil
+	})
+}
+
+// WithCredentialFn sets the credential of a security scheme of the document, resolved at each call.
+//
+// An operation is authenticated with the first of its security requirements whose schemes all
+// have a credential; the operations without a satisfiable requirement are called anonymously.
+//
+// Parameters:
+//   - scheme: The name of the scheme in the securitySchemes of the components.
+//   - fn: The function returning the credential.
+//
+// Returns:
+//   - An Option that sets the credential function.
+//
+// Example:
+//
+//	openapi.WithCredentialFn("bearerAuth", func(ctx context.Context) (string, error) {
+//	    return tokenFor(tool.MetadataFromContext(ctx)["tenant"])
+//	})
+func WithCredentialFn(scheme string, fn CredentialFn) Option {
+	return OptionFunc(func(o *Options) error {
+		if scheme == "" || fn == nil {
+			return fmt.Errorf("%w: credential requires a scheme and a function", ErrInvalidOption)
+		}
+		o.Credentials[scheme] = fn
+		return nil
+	})
+}
+
+// WithHTTPClient sets the HTTP client of the generated tools.
+//
+// Parameters:
+//   - client: The HTTP client.
+//
+// Returns:
+//   - An Option that sets the client.
+func WithHTTPClient(client *http.Client) Option {
+	return OptionFunc(func(o *Options) error {
+		o.Client = client
+		return nil
+	})
+}
+
+// WithTimeout bounds a call of the generated tools.
+//
+// Parameters:
+//   - timeout: The maximum duration of a call.
+//
+// Returns:
+//   - An Option that sets the timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return OptionFunc(func(o *Options) error {
+		if timeout < 0 {
+			return tool.ErrInvalidToolTimeout
+		}
+		o.Timeout = timeout
+		return nil
+	})
+}
+
+// WithMaxResponseBytes bounds the response body read from the API.
+//
+// Parameters:
+//   - maxBytes: The maximum number of bytes, it must be positive.
+//
+// Returns:
+//   - An Option that sets the bound.
+func WithMaxResponseBytes(maxBytes int) Option {
+	return OptionFunc(func(o *Options) error {
+		if maxBytes < 1 {
+			return fmt.Errorf("%w: max response bytes must be positive", ErrInvalidOption)
+		}
+		o.MaxResponseBytes = maxBytes
+		return nil
+	})
+}
+
+// WithMaxItems bounds the elements of the JSON arrays kept by the default summarization.
+//
+// Parameters:
+//   - maxItems: The maximum number of elements, it must be positive.
+//
+// Returns:
+//   - An Option that sets the bound.
+func WithMaxItems(maxItems int) Option {
+	return OptionFunc(func(o *Options) error {
+		if maxItems < 1 {
+			return fmt.Errorf("%w: max items must be positive", ErrInvalidOption)
+		}
+		o.MaxItems = maxItems
+		return nil
+	})
+}
+
+// WithSummarizer replaces the default summarization of the responses.
+//
+// Parameters:
+//   - fn: The function shaping the responses.
+//
+// Returns:
+//   - An Option that sets the summarizer.
+//
+// Example:
+//
+//	openapi.WithSummarizer(func(status int, header http.Header, body []byte) (any, error) {
+//	    return fmt.Sprintf("status %d, %d bytes", status, len(body)), nil
+//	})
+func WithSummarizer(fn tool.HTTPResponseFn) Option {
+	return OptionFunc(func(o *Options) error {
+		if fn == nil {
+			return fmt.Errorf("%w: nil summarizer", ErrInvalidOption)
+		}
+		o.Summarizer = fn
+		return nil
+	})
+}
+
+// WithHTTPOptions adds options to every generated tool, e.g. a header.
+//
+// Parameters:
+//   - opts: The options of the HTTP tools.
+//
+// Returns:
+//   - An Option that adds the HTTP options.
+func WithHTTPOptions(opts ...tool.HTTPOption) Option {
+	return OptionFunc(func(o *Options) error {
+		o.HTTPOptions = append(o.HTTPOptions, opts...)
+		return nil
+	})
+}
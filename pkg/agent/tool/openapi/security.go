@@ -0,0 +1,124 @@
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+var securitySchemeTypes = []string{"apiKey", "http", "oauth2", "openIdConnect"}
+
+// securityScheme is a security scheme of the document with its credential.
+type securityScheme struct {
+	name       string
+	kind       string
+	in         string
+	paramName  string
+	httpScheme string
+	credential CredentialFn
+}
+
+// securedClient wraps the client to authenticate the calls of the operation, the client itself when anonymous.
+func (d *Document) securedClient(operation Operation, opts *Options) (*http.Client, error) {
+	base := opts.Client
+	if base == nil {
+		base = http.DefaultClient
+	}
+
+	requirements, ok := operation.operation["security"]
+	if !ok {
+		requirements = d.spec["security"]
+	}
+	components, _ := d.spec["components"].(map[string]any)
+	definitions, _ := components["securitySchemes"].(map[string]any)
+
+	for _, raw := range sliceOf(requirements) {
+		requirement, _ := raw.(map[string]any)
+		schemes := make([]securityScheme, 0, len(requirement))
+		satisfied := true
+		for name := range requirement {
+			credential, ok := opts.Credentials[name]
+			if !ok {
+				satisfied = false
+				break
+			}
+			definition, err := d.resolve(definitions[name])
+			if err != nil {
+				return nil, fmt.Errorf("security scheme %s: %w", name, err)
+			}
+			if kind := stringOf(definition["type"]); !slices.Contains(securitySchemeTypes, kind) {
+				return nil, fmt.Errorf("%w: unsupported type %q of the security scheme %s", ErrInvalidDocument, kind, name)
+			}
+			schemes = append(schemes, securityScheme{
+				name:       name,
+				kind:       stringOf(definition["type"]),
+				in:         stringOf(definition["in"]),
+				paramName:  stringOf(definition["name"]),
+				httpScheme: strings.ToLower(stringOf(definition["scheme"])),
+				credential: credential,
+			})
+		}
+		if !satisfied {
+			continue
+		}
+		if len(schemes) == 0 {
+			return base, nil
+		}
+
+		transport := base.Transport
+		if transport == nil {
+			transport = http.DefaultTransport
+		}
+		return &http.Client{
+			Transport:     &authTransport{base: transport, schemes: schemes},
+			CheckRedirect: base.CheckRedirect,
+			Jar:           base.Jar,
+			Timeout:       base.Timeout,
+		}, nil
+	}
+	return base, nil
+}
+
+// authTransport adds the credentials of the security schemes to the requests.
+type authTransport struct {
+	base    http.RoundTripper
+	schemes []securityScheme
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for _, scheme := range t.schemes {
+		credential, err := scheme.credential(req.Context())
+		if err != nil {
+			return nil, fmt.Errorf("cannot get the credential of %s: %w", scheme.name, err)
+		}
+		switch scheme.kind {
+		case "apiKey":
+			switch scheme.in {
+			case "query":
+				query := req.URL.Query()
+				query.Set(scheme.paramName, credential)
+				req.URL.RawQuery = query.Encode()
+			case "cookie":
+				req.AddCookie(&http.Cookie{Name: scheme.paramName, Value: credential})
+			default:
+				req.Header.Set(scheme.paramName, credential)
+			}
+		case "http":
+			switch scheme.httpScheme {
+			case "basic":
+				user, password, _ := strings.Cut(credential, ":")
+				req.SetBasicAuth(user, password)
+			case "bearer":
+				req.Header.Set("Authorization", "Bearer "+credential)
+			default:
+				req.Header.Set("Authorization", scheme.httpScheme+" "+credential)
+			}
+		case "oauth2", "openIdConnect":
+			req.Header.Set("Authorization", "Bearer "+credential)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
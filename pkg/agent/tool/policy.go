@@ -0,0 +1,301 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+var (
+	// ErrToolDenied indicates that the policy of the tool does not allow the call in the thread.
+	ErrToolDenied = errors.New("tool call denied by policy")
+	// ErrToolNotApproved indicates that the approval of a tool call was refused.
+	ErrToolNotApproved = errors.New("tool call not approved")
+	// ErrInvalidToolArgs indicates that an argument validator rejected the tool call.
+	ErrInvalidToolArgs = errors.New("invalid tool arguments")
+	// ErrPolicyFnNil indicates that a nil validator or approval function was given to a policy.
+	ErrPolicyFnNil = errors.New("policy function cannot be nil")
+)
+
+// ArgsValidator checks the arguments of a tool call before its execution.
+type ArgsValidator func(args map[string]any) error
+
+// Approval is the decision on a tool call requiring approval.
+type Approval struct {
+	// Approved is true when the call may run.
+	Approved bool `json:"approved"`
+	// Reason explains the decision, reported to the model when the call is refused.
+	Reason string `json:"reason,omitempty"`
+}
+
+// ApproveFn decides whether a tool call may run, blocking until the decision is taken.
+//
+// The context is the invocation context, it carries the thread ID and the thread metadata.
+type ApproveFn func(ctx context.Context, call FnCall) (Approval, error)
+
+// Policy guards the execution of a tool, see Tool.WithPolicy.
+type Policy struct {
+	// AllowedMetadata allows the calls only in the threads whose metadata, see ContextWithMetadata,
+	// holds for each key one of the values; empty allows every thread.
+	AllowedMetadata map[string][]string
+	// Validators check the arguments of the calls.
+	Validators []ArgsValidator
+	// DryRun reports the calls instead of executing them.
+	DryRun bool
+	// Approve decides whether a call may run, nil when no approval is required.
+	Approve ApproveFn
+}
+
+// PolicyOption is a functional option for configuring a Policy.
+type PolicyOption interface {
+	// Apply applies the option to the Policy.
+	//
+	// Parameters:
+	//   - p: A pointer to Policy to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(p *Policy) error
+}
+
+// PolicyOptionFunc is a function type that implements the PolicyOption interface.
+type PolicyOptionFunc func(*Policy) error
+
+// Apply applies the PolicyOptionFunc to the given Policy.
+//
+// Parameters:
+//   - p: A pointer to Policy to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s PolicyOptionFunc) Apply(p *Policy) error { return s(p) }
+
+// AllowWhenMetadata allows the calls only in the threads whose metadata holds one of the values for the key.
+//
+// Combined options for different keys must all match.
+//
+// Parameters:
+//   - key: The metadata key.
+//   - values: The allowed values.
+//
+// Returns:
+//   - A PolicyOption restricting the tool.
+//
+// Example:
+//
+//	deleteTool, err = deleteTool.WithPolicy(tool.AllowWhenMetadata("role", "admin", "owner"))
+func AllowWhenMetadata(key string, values ...string) PolicyOption {
+	return PolicyOptionFunc(func(p *Policy) error {
+		if p.AllowedMetadata == nil {
+			p.AllowedMetadata = make(map[string][]string)
+		}
+		p.AllowedMetadata[key] = append(p.AllowedMetadata[key], values...)
+		return nil
+	})
+}
+
+// ValidateArgs checks the arguments of the calls with the validator.
+//
+// Parameters:
+//   - validator: The validator, its error is reported to the model.
+//
+// Returns:
+//   - A PolicyOption adding the validator.
+//
+// Example:
+//
+//	transfer, err = transfer.WithPolicy(tool.ValidateArgs(func(args map[string]any) error {
+//	    if amount, _ := args["amount"].(float64); amount > 1000 {
+//	        return errors.New("amount above 1000")
+//	    }
+//	    return nil
+//	}))
+func ValidateArgs(validator ArgsValidator) PolicyOption {
+	return PolicyOptionFunc(func(p *Policy) error {
+		if validator == nil {
+			return ErrPolicyFnNil
+		}
+		p.Validators = append(p.Validators, validator)
+		return nil
+	})
+}
+
+// DryRun reports the calls of the tool instead of executing them, see also ContextWithDryRun.
+//
+// Returns:
+//   - A PolicyOption enabling the dry-run mode.
+func DryRun() PolicyOption {
+	return PolicyOptionFunc(func(p *Policy) error {
+		p.DryRun = true
+		return nil
+	})
+}
+
+// RequireApproval asks for the approval of each call before executing it.
+//
+// The approval function can decide synchronously or wait for a human, e.g. through the mailbox
+// of the interrupt nodes with server.ToolApprover.
+//
+// Parameters:
+//   - approve: The approval function.
+//
+// Returns:
+//   - A PolicyOption requiring the approval.
+//
+// Example:
+//
+//	sendMail, err = sendMail.WithPolicy(tool.RequireApproval(server.ToolApprover(mailbox)))
+func RequireApproval(approve ApproveFn) PolicyOption {
+	return PolicyOptionFunc(func(p *Policy) error {
+		if approve == nil {
+			return ErrPolicyFnNil
+		}
+		p.Approve = approve
+		return nil
+	})
+}
+
+// WithPolicy guards the execution of the tool with a policy, enforced by Execute.
+//
+// Parameters:
+//   - opts: The policy options, added to the current policy of the tool.
+//
+// Returns:
+//   - *Tool: The tool itself, to allow chaining.
+//   - error: An error if an option is invalid.
+//
+// Example:
+//
+//	myTool, err = myTool.WithPolicy(tool.AllowWhenMetadata("tenant", "acme"), tool.RequireApproval(approve))
+func (t *Tool) WithPolicy(opts ...PolicyOption) (*Tool, error) {
+	usePolicy := &Policy{}
+	if t.policy != nil {
+		usePolicy.AllowedMetadata = make(map[string][]string, len(t.policy.AllowedMetadata))
+		for key, values := range t.policy.AllowedMetadata {
+			usePolicy.AllowedMetadata[key] = slices.Clone(values)
+		}
+		usePolicy.Validators = slices.Clone(t.policy.Validators)
+		usePolicy.DryRun = t.policy.DryRun
+		usePolicy.Approve = t.policy.Approve
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(usePolicy); err != nil {
+			return nil, fmt.Errorf("invalid policy for tool %s: %w", t.Name, err)
+		}
+	}
+	t.policy = usePolicy
+	return t, nil
+}
+
+// Execute runs a tool call enforcing the policy of the tool.
+//
+// The call is denied when the thread metadata does not match the allowed metadata, rejected
+// when an argument validator fails and, in dry-run mode, reported without being executed;
+// otherwise the approval, if required, is asked before calling the tool with CallContext, or
+// answering from its result cache, see WithCache.
+//
+// Parameters:
+//   - ctx: The context of the call, carrying the thread metadata and the dry-run mode.
+//   - call: The tool call.
+//
+// Returns:
+//   - any: The result of the tool, or the dry-run report.
+//   - error: An error wrapping ErrToolDenied, ErrInvalidToolArgs or ErrToolNotApproved, or the error of the call.
+func (t *Tool) Execute(ctx context.Context, call FnCall) (any, error) {
+	if t.policy != nil {
+		if err := t.policy.allows(MetadataFromContext(ctx)); err != nil {
+			return nil, fmt.Errorf("tool %s: %w", t.Name, err)
+		}
+		for _, validator := range t.policy.Validators {
+			if err := validator(call.Clone().Arguments); err != nil {
+				return nil, fmt.Errorf("tool %s: %w: %w", t.Name, ErrInvalidToolArgs, err)
+			}
+		}
+	}
+
+	if (t.policy != nil && t.policy.DryRun) || DryRunFromContext(ctx) {
+		args, _ := json.Marshal(call.Arguments)
+		return fmt.Sprintf("dry run: tool %s not executed, arguments %s", t.Name, args), nil
+	}
+
+	if t.policy != nil && t.policy.Approve != nil {
+		approval, err := t.policy.Approve(ctx, call.Clone())
+		if err != nil {
+			return nil, fmt.Errorf("tool %s approval failed: %w", t.Name, err)
+		}
+		if !approval.Approved {
+			return nil, fmt.Errorf("tool %s: %w: %s", t.Name, ErrToolNotApproved, approval.Reason)
+		}
+	}
+
+	return t.cachedCall(ctx, call)
+}
+
+func (p *Policy) allows(metadata map[string]string) error {
+	for key, values := range p.AllowedMetadata {
+		value, ok := metadata[key]
+		if !ok || !slices.Contains(values, value) {
+			return fmt.Errorf("%w: metadata %s=%q", ErrToolDenied, key, value)
+		}
+	}
+	return nil
+}
+
+type metadataContextKey struct{}
+
+type dryRunContextKey struct{}
+
+// ContextWithMetadata returns a copy of the context carrying the thread metadata checked by the tool policies.
+//
+// The tool nodes set the conversation metadata on the context of the tool calls.
+//
+// Parameters:
+//   - ctx: The parent context.
+//   - metadata: The thread metadata.
+//
+// Returns:
+//   - The derived context.
+func ContextWithMetadata(ctx context.Context, metadata map[string]string) context.Context {
+	return context.WithValue(ctx, metadataContextKey{}, metadata)
+}
+
+// MetadataFromContext returns the thread metadata carried by the context.
+//
+// Parameters:
+//   - ctx: The context of the tool call.
+//
+// Returns:
+//   - The thread metadata, nil if the context carries none.
+func MetadataFromContext(ctx context.Context) map[string]string {
+	metadata, _ := ctx.Value(metadataContextKey{}).(map[string]string)
+	return metadata
+}
+
+// ContextWithDryRun returns a copy of the context reporting the tool calls instead of executing them.
+//
+// Parameters:
+//   - ctx: The parent context, e.g. the invocation context given with graph.InvokeConfigContext.
+//
+// Returns:
+//   - The derived context.
+//
+// Example:
+//
+//	runtime.Invoke(input, graph.InvokeConfigContext(tool.ContextWithDryRun(context.Background())))
+func ContextWithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunContextKey{}, true)
+}
+
+// DryRunFromContext reports whether the context requests the dry-run mode.
+//
+// Parameters:
+//   - ctx: The context of the tool call.
+//
+// Returns:
+//   - true in dry-run mode.
+func DryRunFromContext(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunContextKey{}).(bool)
+	return dryRun
+}
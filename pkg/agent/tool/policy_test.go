@@ -0,0 +1,116 @@
+package tool_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/morphy76/ggraph/pkg/agent/tool"
+)
+
+func createDeleteTool(t *testing.T, calls *int) *tool.Tool {
+	deleteFile := func(path string) (string, error) {
+		*calls++
+		return "deleted " + path, nil
+	}
+	rv, err := tool.CreateTool[string](deleteFile, "description:Deletes a file", "input:path")
+	if err != nil {
+		t.Fatalf("CreateTool failed: %v", err)
+	}
+	return rv
+}
+
+func TestExecute_AllowWhenMetadata(t *testing.T) {
+	var calls int
+	deleteTool, err := createDeleteTool(t, &calls).WithPolicy(tool.AllowWhenMetadata("role", "admin", "owner"))
+	if err != nil {
+		t.Fatalf("WithPolicy failed: %v", err)
+	}
+	call := tool.FnCall{ID: "1", ToolName: deleteTool.Name, Arguments: map[string]any{"path": "/tmp/a"}}
+
+	if _, err := deleteTool.Execute(context.Background(), call); !errors.Is(err, tool.ErrToolDenied) {
+		t.Errorf("expected ErrToolDenied without metadata, got %v", err)
+	}
+	guest := tool.ContextWithMetadata(context.Background(), map[string]string{"role": "guest"})
+	if _, err := deleteTool.Execute(guest, call); !errors.Is(err, tool.ErrToolDenied) {
+		t.Errorf("expected ErrToolDenied for a guest, got %v", err)
+	}
+	owner := tool.ContextWithMetadata(context.Background(), map[string]string{"role": "owner"})
+	result, err := deleteTool.Execute(owner, call)
+	if err != nil || result != "deleted /tmp/a" {
+		t.Errorf("expected the call to run for an owner, got %v, %v", result, err)
+	}
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestExecute_ValidateArgs(t *testing.T) {
+	var calls int
+	deleteTool, err := createDeleteTool(t, &calls).WithPolicy(tool.ValidateArgs(func(args map[string]any) error {
+		if path, _ := args["path"].(string); !strings.HasPrefix(path, "/tmp/") {
+			return errors.New("only /tmp can be deleted")
+		}
+		return nil
+	}))
+	if err != nil {
+		t.Fatalf("WithPolicy failed: %v", err)
+	}
+
+	_, err = deleteTool.Execute(context.Background(), tool.FnCall{ToolName: deleteTool.Name, Arguments: map[string]any{"path": "/etc/passwd"}})
+	if !errors.Is(err, tool.ErrInvalidToolArgs) || !strings.Contains(err.Error(), "only /tmp") {
+		t.Errorf("expected ErrInvalidToolArgs, got %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("expected no call, got %d", calls)
+	}
+}
+
+func TestExecute_DryRun(t *testing.T) {
+	var calls int
+	deleteTool := createDeleteTool(t, &calls)
+	call := tool.FnCall{ToolName: deleteTool.Name, Arguments: map[string]any{"path": "/tmp/a"}}
+
+	result, err := deleteTool.Execute(tool.ContextWithDryRun(context.Background()), call)
+	if err != nil || !strings.Contains(result.(string), "dry run") || !strings.Contains(result.(string), `"/tmp/a"`) {
+		t.Errorf("expected a dry-run report, got %v, %v", result, err)
+	}
+
+	if _, err := deleteTool.WithPolicy(tool.DryRun()); err != nil {
+		t.Fatalf("WithPolicy failed: %v", err)
+	}
+	if _, err := deleteTool.Execute(context.Background(), call); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if calls != 0 {
+		t.Errorf("expected no call in dry-run mode, got %d", calls)
+	}
+}
+
+func TestExecute_RequireApproval(t *testing.T) {
+	var calls int
+	var asked []tool.FnCall
+	deleteTool, err := createDeleteTool(t, &calls).WithPolicy(tool.RequireApproval(func(ctx context.Context, call tool.FnCall) (tool.Approval, error) {
+		asked = append(asked, call)
+		path, _ := call.Arguments["path"].(string)
+		return tool.Approval{Approved: path != "/", Reason: "never delete the root"}, nil
+	}))
+	if err != nil {
+		t.Fatalf("WithPolicy failed: %v", err)
+	}
+
+	if _, err := deleteTool.Execute(context.Background(), tool.FnCall{ToolName: deleteTool.Name, Arguments: map[string]any{"path": "/"}}); !errors.Is(err, tool.ErrToolNotApproved) || !strings.Contains(err.Error(), "never delete the root") {
+		t.Errorf("expected ErrToolNotApproved, got %v", err)
+	}
+	if _, err := deleteTool.Execute(context.Background(), tool.FnCall{ToolName: deleteTool.Name, Arguments: map[string]any{"path": "/tmp/a"}}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if len(asked) != 2 || calls != 1 {
+		t.Errorf("expected 2 approvals and 1 call, got %d and %d", len(asked), calls)
+	}
+
+	if _, err := deleteTool.WithPolicy(tool.RequireApproval(nil)); !errors.Is(err, tool.ErrPolicyFnNil) {
+		t.Errorf("expected ErrPolicyFnNil, got %v", err)
+	}
+}
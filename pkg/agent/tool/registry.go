@@ -0,0 +1,311 @@
+package tool
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	// DefaultNamespaceSeparator separates the namespace from the name of a tool, like in "fs.read".
+	DefaultNamespaceSeparator = "."
+	// Wildcard selects every tool of a registry, or every tool of a namespace when it follows the separator, like in "fs.*".
+	Wildcard = "*"
+)
+
+var (
+	// ErrRegistryNil indicates that a nil registry was given.
+	ErrRegistryNil = errors.New("tool registry cannot be nil")
+	// ErrDuplicateTool indicates that a tool with the same name is already registered.
+	ErrDuplicateTool = errors.New("tool already registered")
+	// ErrInvalidRegistryOption indicates that an option of a registry has an invalid value.
+	ErrInvalidRegistryOption = errors.New("invalid tool registry option")
+)
+
+// Definition is the exported description of a tool, as sent to the model.
+type Definition struct {
+	// Name is the name of the tool, qualified by its namespace.
+	Name string `json:"name"`
+	// Description is the prompt describing the tool.
+	Description string `json:"description,omitempty"`
+	// Parameters is the JSON schema of the parameters.
+	Parameters map[string]any `json:"parameters"`
+}
+
+// RegistryOptions holds the configuration of a Registry.
+type RegistryOptions struct {
+	// Separator separates the namespace from the name of a tool.
+	Separator string
+}
+
+// RegistryOption is a functional option for configuring a Registry.
+type RegistryOption interface {
+	// Apply applies the option to the RegistryOptions.
+	//
+	// Parameters:
+	//   - o: A pointer to RegistryOptions to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(o *RegistryOptions) error
+}
+
+// RegistryOptionFunc is a function type that implements the RegistryOption interface.
+type RegistryOptionFunc func(*RegistryOptions) error
+
+// Apply applies the RegistryOptionFunc to the given RegistryOptions.
+//
+// Parameters:
+//   - o: A pointer to RegistryOptions to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s RegistryOptionFunc) Apply(o *RegistryOptions) error { return s(o) }
+
+// WithNamespaceSeparator sets the separator of the namespaces.
+//
+// OpenAI accepts only letters, digits, underscores and dashes in the tool names: use a separator
+// like "__" with it.
+//
+// Parameters:
+//   - separator: The separator, it cannot be empty or contain the wildcard.
+//
+// Returns:
+//   - A RegistryOption that sets the separator.
+//
+// Example:
+//
+//	registry, err := tool.NewRegistry(tool.WithNamespaceSeparator("__"))
+func WithNamespaceSeparator(separator string) RegistryOption {
+	return RegistryOptionFunc(func(o *RegistryOptions) error {
+		if separator == "" || strings.Contains(separator, Wildcard) {
+			return fmt.Errorf("%w: invalid separator %q", ErrInvalidRegistryOption, separator)
+		}
+		o.Separator = separator
+		return nil
+	})
+}
+
+// Registry indexes the tools by name, grouped by namespaces, to share them among the nodes.
+//
+// The nodes select the subset of the tools they expose with name patterns, see Select; since the
+// selection happens at each request, the tools registered at runtime are discovered by the nodes.
+// A Registry is safe for concurrent use.
+type Registry struct {
+	separator string
+
+	mu    sync.RWMutex
+	tools map[string]*Tool
+}
+
+// NewRegistry creates an empty tool registry.
+//
+// Parameters:
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - *Registry: The registry.
+//   - error: An error if the options are invalid.
+//
+// Example:
+//
+//	registry, _ := tool.NewRegistry()
+//	_ = registry.RegisterNamespace("fs", readTool, writeTool)
+//	_ = registry.Register(searchTool)
+//	node, err := openai.CreateToolAgentNode("Agent", "gpt-4o-mini", client, 5, a.WithToolsFrom(registry, "fs.*"))
+func NewRegistry(opts ...RegistryOption) (*Registry, error) {
+	useOpts := &RegistryOptions{Separator: DefaultNamespaceSeparator}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("cannot create the tool registry: %w", err)
+		}
+	}
+	return &Registry{
+		separator: useOpts.Separator,
+		tools:     make(map[string]*Tool),
+	}, nil
+}
+
+// Register adds tools to the registry under their own names.
+//
+// The registration is atomic: no tool is added when one of them is invalid or already registered.
+//
+// Parameters:
+//   - tools: The tools.
+//
+// Returns:
+//   - An error if a tool has no name or its name is already registered.
+func (r *Registry) Register(tools ...*Tool) error {
+	return r.RegisterNamespace("", tools...)
+}
+
+// RegisterNamespace adds tools to the registry under a namespace.
+//
+// The registry holds a copy of each tool named after the namespace, the separator and the tool
+// name, like "fs.read": the models call it by its qualified name.
+//
+// Parameters:
+//   - namespace: The namespace, empty for none.
+//   - tools: The tools.
+//
+// Returns:
+//   - An error if a tool has no name or its qualified name is already registered.
+//
+// Example:
+//
+//	err := registry.RegisterNamespace("fs", readTool, writeTool) // fs.read, fs.write
+func (r *Registry) RegisterNamespace(namespace string, tools ...*Tool) error {
+	qualified := make(map[string]*Tool, len(tools))
+	for _, useTool := range tools {
+		if useTool == nil || useTool.Name == "" {
+			return ErrToolNameEmpty
+		}
+		registered := useTool
+		if namespace != "" {
+			copied := *useTool
+			copied.Name = namespace + r.separator + useTool.Name
+			registered = &copied
+		}
+		// the prompt is cached on the first use, build it now that the tool is not shared yet
+		registered.BuildToolPrompt()
+		registered.RequiredArgs()
+		if _, ok := qualified[registered.Name]; ok {
+			return fmt.Errorf("%w: %s", ErrDuplicateTool, registered.Name)
+		}
+		qualified[registered.Name] = registered
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name := range qualified {
+		if _, ok := r.tools[name]; ok {
+			return fmt.Errorf("%w: %s", ErrDuplicateTool, name)
+		}
+	}
+	maps.Copy(r.tools, qualified)
+	return nil
+}
+
+// Unregister removes tools from the registry, the unknown names are ignored.
+//
+// Parameters:
+//   - names: The qualified names of the tools.
+func (r *Registry) Unregister(names ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, name := range names {
+		delete(r.tools, name)
+	}
+}
+
+// Lookup returns a tool by its qualified name.
+//
+// Parameters:
+//   - name: The qualified name of the tool, like "fs.read".
+//
+// Returns:
+//   - *Tool: The tool.
+//   - bool: false if no tool has the name.
+func (r *Registry) Lookup(name string) (*Tool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	rv, ok := r.tools[name]
+	return rv, ok
+}
+
+// Names returns the sorted qualified names of the registered tools.
+//
+// Returns:
+//   - []string: The names.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return slices.Sorted(maps.Keys(r.tools))
+}
+
+// List returns the registered tools, sorted by name.
+//
+// Returns:
+//   - []*Tool: The tools.
+func (r *Registry) List() []*Tool {
+	rv, _ := r.Select()
+	return rv
+}
+
+// Select returns the subset of the tools matching the patterns, sorted by name.
+//
+// A pattern is either a qualified name, like "fs.read", a namespace followed by the separator
+// and the wildcard, like "fs.*", or the wildcard alone; no pattern selects every tool.
+//
+// Parameters:
+//   - patterns: The patterns of the tools.
+//
+// Returns:
+//   - []*Tool: The matching tools.
+//   - error: ErrToolNotFound if a qualified name is not registered.
+//
+// Example:
+//
+//	tools, err := registry.Select("fs.*", "web.search")
+//	toolNode, err := graph.CreateToolNode("Tools", tools...)
+func (r *Registry) Select(patterns ...string) ([]*Tool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	selected := make(map[string]*Tool)
+	if len(patterns) == 0 {
+		maps.Copy(selected, r.tools)
+	}
+	for _, pattern := range patterns {
+		prefix, isNamespace := strings.CutSuffix(pattern, Wildcard)
+		switch {
+		case pattern == Wildcard:
+			maps.Copy(selected, r.tools)
+		case isNamespace && strings.HasSuffix(prefix, r.separator):
+			for name, useTool := range r.tools {
+				if strings.HasPrefix(name, prefix) {
+					selected[name] = useTool
+				}
+			}
+		default:
+			useTool, ok := r.tools[pattern]
+			if !ok {
+				return nil, fmt.Errorf("%w: %s", ErrToolNotFound, pattern)
+			}
+			selected[pattern] = useTool
+		}
+	}
+
+	rv := make([]*Tool, 0, len(selected))
+	for _, name := range slices.Sorted(maps.Keys(selected)) {
+		rv = append(rv, selected[name])
+	}
+	return rv, nil
+}
+
+// Definitions exports the definitions of the tools matching the patterns, e.g. to publish them as JSON.
+//
+// Parameters:
+//   - patterns: The patterns of the tools, see Select.
+//
+// Returns:
+//   - []Definition: The definitions, sorted by name.
+//   - error: ErrToolNotFound if a qualified name is not registered.
+func (r *Registry) Definitions(patterns ...string) ([]Definition, error) {
+	tools, err := r.Select(patterns...)
+	if err != nil {
+		return nil, err
+	}
+	rv := make([]Definition, len(tools))
+	for i, useTool := range tools {
+		rv[i] = Definition{
+			Name:        useTool.Name,
+			Description: useTool.BuildToolPrompt(),
+			Parameters:  useTool.Parameters(),
+		}
+	}
+	return rv, nil
+}
@@ -0,0 +1,110 @@
+package tool_test
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/morphy76/ggraph/pkg/agent/tool"
+)
+
+func createNamedTool(t *testing.T, name string) *tool.Tool {
+	rv, err := tool.CreateTool[string](func(path string) (string, error) {
+		return name + " " + path, nil
+	}, "description:Runs "+name, "input:path", "required:path")
+	if err != nil {
+		t.Fatalf("CreateTool failed: %v", err)
+	}
+	rv.Name = name
+	return rv
+}
+
+func names(tools []*tool.Tool) string {
+	rv := make([]string, len(tools))
+	for i, useTool := range tools {
+		rv[i] = useTool.Name
+	}
+	return strings.Join(rv, ",")
+}
+
+func TestRegistry(t *testing.T) {
+	registry, err := tool.NewRegistry()
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+	read := createNamedTool(t, "read")
+	if err := registry.RegisterNamespace("fs", read, createNamedTool(t, "write")); err != nil {
+		t.Fatalf("RegisterNamespace failed: %v", err)
+	}
+	if err := registry.Register(createNamedTool(t, "search")); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	if strings.Join(registry.Names(), ",") != "fs.read,fs.write,search" || names(registry.List()) != "fs.read,fs.write,search" {
+		t.Errorf("unexpected tools: %v", registry.Names())
+	}
+	if read.Name != "read" {
+		t.Errorf("expected the registered tool to be a copy, got %s", read.Name)
+	}
+	fsRead, ok := registry.Lookup("fs.read")
+	if !ok {
+		t.Fatal("expected fs.read to be registered")
+	}
+	if result, err := fsRead.Call("/tmp/a"); err != nil || result != "read /tmp/a" {
+		t.Errorf("unexpected call result: %v, %v", result, err)
+	}
+
+	if selected, err := registry.Select("fs.*"); err != nil || names(selected) != "fs.read,fs.write" {
+		t.Errorf("unexpected namespace selection: %v, %v", names(selected), err)
+	}
+	if selected, err := registry.Select("search", "fs.write", "search"); err != nil || names(selected) != "fs.write,search" {
+		t.Errorf("unexpected selection: %v, %v", names(selected), err)
+	}
+	if _, err := registry.Select("fs.delete"); !errors.Is(err, tool.ErrToolNotFound) {
+		t.Errorf("expected ErrToolNotFound, got %v", err)
+	}
+
+	if err := registry.RegisterNamespace("fs", createNamedTool(t, "list"), createNamedTool(t, "read")); !errors.Is(err, tool.ErrDuplicateTool) {
+		t.Errorf("expected ErrDuplicateTool, got %v", err)
+	}
+	if _, ok := registry.Lookup("fs.list"); ok {
+		t.Errorf("expected the failed registration to add no tool")
+	}
+	if err := registry.Register(&tool.Tool{}); !errors.Is(err, tool.ErrToolNameEmpty) {
+		t.Errorf("expected ErrToolNameEmpty, got %v", err)
+	}
+
+	registry.Unregister("fs.write", "unknown")
+	if selected, _ := registry.Select(tool.Wildcard); names(selected) != "fs.read,search" {
+		t.Errorf("unexpected tools after Unregister: %v", names(selected))
+	}
+}
+
+func TestRegistry_Definitions(t *testing.T) {
+	registry, err := tool.NewRegistry(tool.WithNamespaceSeparator("__"))
+	if err != nil {
+		t.Fatalf("NewRegistry failed: %v", err)
+	}
+	if err := registry.RegisterNamespace("fs", createNamedTool(t, "read")); err != nil {
+		t.Fatalf("RegisterNamespace failed: %v", err)
+	}
+
+	definitions, err := registry.Definitions("fs__*")
+	if err != nil {
+		t.Fatalf("Definitions failed: %v", err)
+	}
+	raw, err := json.Marshal(definitions)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	expected := `[{"name":"fs__read","description":"Runs read\nInput hint:[ path] of types [string]\n",` +
+		`"parameters":{"properties":{"path":{"type":"string"}},"required":["path"],"type":"object"}}]`
+	if string(raw) != expected {
+		t.Errorf("unexpected definitions:\n%s\nexpected:\n%s", raw, expected)
+	}
+
+	if _, err := tool.NewRegistry(tool.WithNamespaceSeparator("")); !errors.Is(err, tool.ErrInvalidRegistryOption) {
+		t.Errorf("expected ErrInvalidRegistryOption, got %v", err)
+	}
+}
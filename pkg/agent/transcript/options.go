@@ -0,0 +1,91 @@
+package transcript
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrInvalidOption indicates that an option of the transcript has an invalid value.
+	ErrInvalidOption = errors.New("invalid transcript option")
+)
+
+// Options holds the configuration of an export.
+type Options struct {
+	// ThreadID is the thread the conversation belongs to, recorded in the header.
+	ThreadID string
+	// ExportedAt is the export time recorded in the header, the current time when zero.
+	ExportedAt time.Time
+	// OmitData leaves the inline data of the image and audio parts out of the transcript.
+	OmitData bool
+}
+
+// Option is a functional option for configuring an export.
+type Option interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - o: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(o *Options) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc func(*Options) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - o: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc) Apply(o *Options) error { return s(o) }
+
+// WithThreadID records the thread the conversation belongs to.
+//
+// Parameters:
+//   - threadID: The thread identifier.
+//
+// Returns:
+//   - An Option that sets the thread identifier.
+func WithThreadID(threadID string) Option {
+	return OptionFunc(func(o *Options) error {
+		o.ThreadID = threadID
+		return nil
+	})
+}
+
+// WithExportedAt records a given export time, e.g. for reproducible transcripts.
+//
+// Parameters:
+//   - exportedAt: The export time.
+//
+// Returns:
+//   - An Option that sets the export time.
+func WithExportedAt(exportedAt time.Time) Option {
+	return OptionFunc(func(o *Options) error {
+		if exportedAt.IsZero() {
+			return fmt.Errorf("%w: zero export time", ErrInvalidOption)
+		}
+		o.ExportedAt = exportedAt
+		return nil
+	})
+}
+
+// WithoutData leaves the inline data of the image and audio parts out of the transcript.
+//
+// The parts keep their kind, MIME type and URL, so that the transcript stays small and free of
+// personal media; the imported conversations cannot be sent back to the models as they are.
+//
+// Returns:
+//   - An Option that omits the inline data.
+func WithoutData() Option {
+	return OptionFunc(func(o *Options) error {
+		o.OmitData = true
+		return nil
+	})
+}
@@ -0,0 +1,389 @@
+// Package transcript converts the conversations to and from a portable, provider-agnostic format.
+//
+// A transcript is a JSON Lines document: the first line is the Header, each following line is
+// a Record holding a message with its role, content, parts and tool calls. Transcripts move the
+// threads between deployments, whatever the memory they use, and feed the offline analysis.
+//
+// Example:
+//
+//	// export the conversation of a thread
+//	var buf bytes.Buffer
+//	err := transcript.ExportThread(&buf, runtime, threadID)
+//
+//	// seed a thread of another deployment, then resume it
+//	conversation, err := transcript.Seed(ctx, memory, "", &buf)
+//	runtime.Invoke(a.CreateConversation(a.CreateMessage(a.User, "and then?")), g.InvokeConfigThreadID(threadID))
+package transcript
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"strings"
+	"time"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/agent/tool"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// Version is the version of the transcript format written by Export.
+const Version = 1
+
+// maxLineSize bounds the size of a transcript line, inline media included.
+const maxLineSize = 64 << 20
+
+var (
+	// ErrInvalidTranscript indicates that a transcript cannot be parsed.
+	ErrInvalidTranscript = errors.New("invalid transcript")
+	// ErrUnsupportedVersion indicates that a transcript was written by a newer version of the format.
+	ErrUnsupportedVersion = errors.New("unsupported transcript version")
+	// ErrUnknownRole indicates that a record has a role which is not a conversation role.
+	ErrUnknownRole = errors.New("unknown message role")
+	// ErrUnknownPartKind indicates that a part has a kind which is not a content part kind.
+	ErrUnknownPartKind = errors.New("unknown content part kind")
+	// ErrNoThreadID indicates that a transcript is seeded without a thread identifier.
+	ErrNoThreadID = errors.New("no thread identifier to seed")
+	// ErrMemoryNil indicates that a transcript is seeded without a memory.
+	ErrMemoryNil = errors.New("memory cannot be nil")
+)
+
+var roleNames = map[a.MessageRole]string{
+	a.System:    "system",
+	a.User:      "user",
+	a.Assistant: "assistant",
+	a.Tool:      "tool",
+}
+
+var partKindNames = map[a.PartKind]string{
+	a.TextPart:  "text",
+	a.ImagePart: "image",
+	a.AudioPart: "audio",
+}
+
+// Header is the first line of a transcript.
+type Header struct {
+	// Version is the version of the format.
+	Version int `json:"version"`
+	// ThreadID is the thread the conversation was exported from, empty when unknown.
+	ThreadID string `json:"thread_id,omitempty"`
+	// ExportedAt is the export time.
+	ExportedAt time.Time `json:"exported_at"`
+	// Metadata is the metadata of the conversation.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// PendingToolCalls are the tool calls of the conversation still to be executed.
+	PendingToolCalls []ToolCall `json:"pending_tool_calls,omitempty"`
+}
+
+// Record is a message of a transcript.
+type Record struct {
+	// ID identifies the message.
+	ID string `json:"id,omitempty"`
+	// Timestamp is the time of the message.
+	Timestamp time.Time `json:"timestamp"`
+	// CreatedAt is the time the message was created.
+	CreatedAt time.Time `json:"created_at,omitzero"`
+	// Role is one of "system", "user", "assistant" and "tool".
+	Role string `json:"role"`
+	// Name is the participant authoring the message.
+	Name string `json:"name,omitempty"`
+	// Content is the text of the message, the answer of the tool for the tool messages.
+	Content string `json:"content"`
+	// ToolCallID is the tool call answered by a tool message.
+	ToolCallID string `json:"tool_call_id,omitempty"`
+	// Parts are the parts of a multi-modal message.
+	Parts []Part `json:"parts,omitempty"`
+	// ToolCalls are the tool calls requested by an assistant message.
+	ToolCalls []ToolCall `json:"tool_calls,omitempty"`
+	// Metadata annotates the message.
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+// Part is a part of a multi-modal message.
+type Part struct {
+	// Type is one of "text", "image" and "audio".
+	Type string `json:"type"`
+	// Text is the text of a text part.
+	Text string `json:"text,omitempty"`
+	// URL references an image part.
+	URL string `json:"url,omitempty"`
+	// Data is the inline content of an image or audio part, base64 encoded.
+	Data []byte `json:"data,omitempty"`
+	// MIMEType is the media type of the data.
+	MIMEType string `json:"mime_type,omitempty"`
+	// Detail is the fidelity of an image part.
+	Detail string `json:"detail,omitempty"`
+}
+
+// ToolCall is a tool call requested by the assistant.
+type ToolCall struct {
+	// ID identifies the call, the tool messages answer it.
+	ID string `json:"id"`
+	// Name is the name of the tool.
+	Name string `json:"name"`
+	// Arguments are the arguments of the call.
+	Arguments map[string]any `json:"arguments,omitempty"`
+}
+
+// Export writes the conversation as a transcript.
+//
+// The tool messages, whose content is "<call id>:<answer>" in the conversations, are split into
+// the ToolCallID and the Content of their records.
+//
+// Parameters:
+//   - w: The writer of the transcript.
+//   - conversation: The conversation to export.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - An error if an option is invalid or the transcript cannot be written.
+//
+// Example:
+//
+//	file, _ := os.Create("thread.jsonl")
+//	defer file.Close()
+//	err := transcript.Export(file, conversation, transcript.WithThreadID(threadID))
+func Export(w io.Writer, conversation a.Conversation, opts ...Option) error {
+	useOpts := &Options{}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return fmt.Errorf("transcript export failed: %w", err)
+		}
+	}
+	if useOpts.ExportedAt.IsZero() {
+		useOpts.ExportedAt = time.Now()
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+
+	header := Header{
+		Version:          Version,
+		ThreadID:         useOpts.ThreadID,
+		ExportedAt:       useOpts.ExportedAt,
+		Metadata:         maps.Clone(conversation.Metadata),
+		PendingToolCalls: exportToolCalls(conversation.CurrentToolCalls),
+	}
+	if err := encoder.Encode(header); err != nil {
+		return fmt.Errorf("transcript export failed: %w", err)
+	}
+	for idx, message := range conversation.Messages {
+		record, err := exportMessage(message, useOpts.OmitData)
+		if err != nil {
+			return fmt.Errorf("transcript export failed at message %d: %w", idx, err)
+		}
+		if err := encoder.Encode(record); err != nil {
+			return fmt.Errorf("transcript export failed at message %d: %w", idx, err)
+		}
+	}
+	return nil
+}
+
+// ExportThread writes the current conversation of a thread as a transcript.
+//
+// Parameters:
+//   - w: The writer of the transcript.
+//   - runtime: The runtime, or any observer of the thread states.
+//   - threadID: The thread to export.
+//   - opts: Optional configuration options, the thread identifier is recorded unless WithThreadID overrides it.
+//
+// Returns:
+//   - An error if the transcript cannot be written.
+func ExportThread(w io.Writer, runtime g.StateObserver[a.Conversation], threadID string, opts ...Option) error {
+	return Export(w, runtime.CurrentState(threadID), append([]Option{WithThreadID(threadID)}, opts...)...)
+}
+
+// Import reads a transcript back into a conversation.
+//
+// The header is optional, so that the transcripts produced by other tools, one record per line,
+// are accepted; the empty lines are skipped.
+//
+// Parameters:
+//   - r: The reader of the transcript.
+//
+// Returns:
+//   - The conversation.
+//   - The header, the zero value when the transcript has none.
+//   - An error wrapping ErrInvalidTranscript, ErrUnsupportedVersion, ErrUnknownRole or ErrUnknownPartKind.
+func Import(r io.Reader) (a.Conversation, Header, error) {
+	var header Header
+	conversation := a.Conversation{}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		if lineNumber == 1 && isHeader(line) {
+			if err := json.Unmarshal(line, &header); err != nil {
+				return a.Conversation{}, Header{}, fmt.Errorf("%w: line %d: %w", ErrInvalidTranscript, lineNumber, err)
+			}
+			if header.Version > Version {
+				return a.Conversation{}, Header{}, fmt.Errorf("%w: %d", ErrUnsupportedVersion, header.Version)
+			}
+			conversation.Metadata = header.Metadata
+			conversation.CurrentToolCalls = importToolCalls(header.PendingToolCalls)
+			continue
+		}
+
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			return a.Conversation{}, Header{}, fmt.Errorf("%w: line %d: %w", ErrInvalidTranscript, lineNumber, err)
+		}
+		message, err := importRecord(record)
+		if err != nil {
+			return a.Conversation{}, Header{}, fmt.Errorf("%w: line %d: %w", ErrInvalidTranscript, lineNumber, err)
+		}
+		conversation.Messages = append(conversation.Messages, message)
+	}
+	if err := scanner.Err(); err != nil {
+		return a.Conversation{}, Header{}, fmt.Errorf("%w: %w", ErrInvalidTranscript, err)
+	}
+	return conversation, header, nil
+}
+
+// Seed imports a transcript and persists its conversation as the state of a thread.
+//
+// The runtimes using the memory restore the conversation on the next invocation of the thread.
+//
+// Parameters:
+//   - ctx: The context of the persistence.
+//   - memory: The memory of the target runtime.
+//   - threadID: The thread to seed, the one of the header when empty.
+//   - r: The reader of the transcript.
+//
+// Returns:
+//   - The seeded conversation.
+//   - An error if the transcript is invalid, no thread identifier is known or the persistence fails.
+//
+// Example:
+//
+//	file, _ := os.Open("thread.jsonl")
+//	defer file.Close()
+//	if _, err := transcript.Seed(ctx, memory, "migrated-thread", file); err != nil {
+//	    log.Fatal(err)
+//	}
+func Seed(ctx context.Context, memory g.Memory[a.Conversation], threadID string, r io.Reader) (a.Conversation, error) {
+	if memory == nil {
+		return a.Conversation{}, fmt.Errorf("transcript seeding failed: %w", ErrMemoryNil)
+	}
+	conversation, header, err := Import(r)
+	if err != nil {
+		return a.Conversation{}, fmt.Errorf("transcript seeding failed: %w", err)
+	}
+	if threadID == "" {
+		threadID = header.ThreadID
+	}
+	if threadID == "" {
+		return a.Conversation{}, fmt.Errorf("transcript seeding failed: %w", ErrNoThreadID)
+	}
+	if err := memory.PersistFn()(ctx, threadID, conversation); err != nil {
+		return a.Conversation{}, fmt.Errorf("transcript seeding failed for thread %s: %w", threadID, err)
+	}
+	return conversation, nil
+}
+
+func isHeader(line []byte) bool {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(line, &probe); err != nil {
+		return false
+	}
+	_, ok := probe["version"]
+	return ok
+}
+
+func exportMessage(message a.Message, omitData bool) (Record, error) {
+	role, ok := roleNames[message.Role]
+	if !ok {
+		return Record{}, fmt.Errorf("%w: %d", ErrUnknownRole, message.Role)
+	}
+	rv := Record{
+		ID:        message.ID,
+		Timestamp: message.Ts,
+		CreatedAt: message.CreatedAt,
+		Role:      role,
+		Name:      message.Name,
+		Content:   message.Content,
+		ToolCalls: exportToolCalls(message.ToolCalls),
+		Metadata:  maps.Clone(message.Metadata),
+	}
+	if message.Role == a.Tool {
+		if callID, content, found := strings.Cut(message.Content, ":"); found {
+			rv.ToolCallID, rv.Content = callID, content
+		}
+	}
+	for _, part := range message.Parts {
+		kind, ok := partKindNames[part.Kind]
+		if !ok {
+			return Record{}, fmt.Errorf("%w: %d", ErrUnknownPartKind, part.Kind)
+		}
+		exported := Part{Type: kind, Text: part.Text, URL: part.URL, Data: part.Data, MIMEType: part.MIMEType, Detail: string(part.Detail)}
+		if omitData {
+			exported.Data = nil
+		}
+		rv.Parts = append(rv.Parts, exported)
+	}
+	return rv, nil
+}
+
+func importRecord(record Record) (a.Message, error) {
+	role, ok := lookup(roleNames, record.Role)
+	if !ok {
+		return a.Message{}, fmt.Errorf("%w: %q", ErrUnknownRole, record.Role)
+	}
+	rv := a.Message{
+		ID:        record.ID,
+		Ts:        record.Timestamp,
+		CreatedAt: record.CreatedAt,
+		Role:      role,
+		Name:      record.Name,
+		Content:   record.Content,
+		ToolCalls: importToolCalls(record.ToolCalls),
+		Metadata:  maps.Clone(record.Metadata),
+	}
+	if role == a.Tool && record.ToolCallID != "" {
+		rv.Content = record.ToolCallID + ":" + record.Content
+	}
+	for _, part := range record.Parts {
+		kind, ok := lookup(partKindNames, part.Type)
+		if !ok {
+			return a.Message{}, fmt.Errorf("%w: %q", ErrUnknownPartKind, part.Type)
+		}
+		rv.Parts = append(rv.Parts, a.ContentPart{Kind: kind, Text: part.Text, URL: part.URL, Data: part.Data, MIMEType: part.MIMEType, Detail: a.ImageDetail(part.Detail)})
+	}
+	return rv, nil
+}
+
+func exportToolCalls(calls []tool.FnCall) []ToolCall {
+	var rv []ToolCall
+	for _, call := range calls {
+		rv = append(rv, ToolCall{ID: call.ID, Name: call.ToolName, Arguments: maps.Clone(call.Arguments)})
+	}
+	return rv
+}
+
+func importToolCalls(calls []ToolCall) []tool.FnCall {
+	var rv []tool.FnCall
+	for _, call := range calls {
+		rv = append(rv, tool.FnCall{ID: call.ID, ToolName: call.Name, Arguments: call.Arguments})
+	}
+	return rv
+}
+
+func lookup[K comparable](names map[K]string, name string) (K, bool) {
+	for key, value := range names {
+		if value == name {
+			return key, true
+		}
+	}
+	var zero K
+	return zero, false
+}
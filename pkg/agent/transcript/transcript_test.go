@@ -0,0 +1,151 @@
+package transcript_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/agent/tool"
+	"github.com/morphy76/ggraph/pkg/agent/transcript"
+	"github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+var ts = time.Date(2026, 10, 16, 9, 30, 0, 0, time.UTC)
+
+func conversation() a.Conversation {
+	return a.Conversation{
+		Messages: []a.Message{
+			{Ts: ts, Role: a.System, Content: "You are a weather assistant."},
+			{Ts: ts.Add(time.Second), Role: a.User, Content: "Weather in Rome?", Parts: []a.ContentPart{
+				a.Text("Weather in Rome?"),
+				a.ImageData([]byte{0x89, 0x50, 0x4e, 0x47}, "image/png"),
+			}},
+			{Ts: ts.Add(2 * time.Second), Role: a.Assistant, ToolCalls: []tool.FnCall{
+				{ID: "call_1", ToolName: "weather", Arguments: map[string]any{"city": "Rome"}},
+			}},
+			{Ts: ts.Add(3 * time.Second), Role: a.Tool, Content: "call_1:sunny, 24°C"},
+			{ID: "01JAB7Q9X4", Ts: ts.Add(4 * time.Second), CreatedAt: ts.Add(3 * time.Second), Role: a.Assistant, Name: "forecaster", Content: "It is sunny in <Rome>.", Metadata: map[string]string{"guardrail": "passed"}},
+		},
+		Metadata: map[string]string{"backend": "primary"},
+	}
+}
+
+func TestExportImport(t *testing.T) {
+	var buf bytes.Buffer
+	if err := transcript.Export(&buf, conversation(), transcript.WithThreadID("thread-1"), transcript.WithExportedAt(ts)); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 6 {
+		t.Fatalf("Expected a header and 5 records, got:\n%s", buf.String())
+	}
+	if lines[0] != `{"version":1,"thread_id":"thread-1","exported_at":"2026-10-16T09:30:00Z","metadata":{"backend":"primary"}}` {
+		t.Errorf("Unexpected header %s", lines[0])
+	}
+	if lines[4] != `{"timestamp":"2026-10-16T09:30:03Z","role":"tool","content":"sunny, 24°C","tool_call_id":"call_1"}` {
+		t.Errorf("Unexpected tool record %s", lines[4])
+	}
+
+	imported, header, err := transcript.Import(&buf)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if header.Version != transcript.Version || header.ThreadID != "thread-1" || !header.ExportedAt.Equal(ts) {
+		t.Errorf("Unexpected header %+v", header)
+	}
+	if !reflect.DeepEqual(imported, conversation()) {
+		t.Errorf("Expected the conversation to survive the round trip, got %+v", imported)
+	}
+}
+
+func TestExport_WithoutData(t *testing.T) {
+	var buf bytes.Buffer
+	if err := transcript.Export(&buf, conversation(), transcript.WithoutData()); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	imported, _, err := transcript.Import(&buf)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if image := imported.Messages[1].Parts[1]; image.Kind != a.ImagePart || image.MIMEType != "image/png" || image.Data != nil {
+		t.Errorf("Expected the image part without its data, got %+v", image)
+	}
+}
+
+func TestImport_Headerless(t *testing.T) {
+	imported, header, err := transcript.Import(strings.NewReader(`{"role":"user","content":"hi"}` + "\n\n" + `{"role":"assistant","content":"hello"}`))
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if header.Version != 0 || len(imported.Messages) != 2 || imported.Messages[1].Role != a.Assistant || imported.Messages[1].Content != "hello" {
+		t.Errorf("Unexpected import %+v, %+v", imported, header)
+	}
+}
+
+func TestImport_Invalid(t *testing.T) {
+	for input, expected := range map[string]error{
+		`{"version":2}`:                              transcript.ErrUnsupportedVersion,
+		`{"role":"narrator","content":""}`:           transcript.ErrUnknownRole,
+		`{"role":"user","parts":[{"type":"video"}]}`: transcript.ErrUnknownPartKind,
+		`not json`: transcript.ErrInvalidTranscript,
+	} {
+		if _, _, err := transcript.Import(strings.NewReader(input)); !errors.Is(err, expected) {
+			t.Errorf("Expected %v for %s, got %v", expected, input, err)
+		}
+	}
+}
+
+func TestSeed(t *testing.T) {
+	var buf bytes.Buffer
+	if err := transcript.Export(&buf, conversation(), transcript.WithThreadID("origin")); err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	exported := buf.String()
+
+	memory := builders.NewMemMemory[a.Conversation]()
+	start, _ := builders.NewNode[a.Conversation]("Start", nil)
+	runtime, err := builders.CreateRuntime(builders.CreateStartEdge(start), nil, g.WithMemory(memory))
+	if err != nil {
+		t.Fatalf("CreateRuntime failed: %v", err)
+	}
+	defer runtime.Shutdown()
+
+	if _, err := transcript.Seed(context.Background(), memory, "", strings.NewReader(exported)); err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+	if _, err := transcript.Seed(context.Background(), memory, "migrated", strings.NewReader(exported)); err != nil {
+		t.Fatalf("Seed failed: %v", err)
+	}
+	if err := runtime.Restore("migrated"); err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+
+	buf.Reset()
+	if err := transcript.ExportThread(&buf, runtime, "migrated"); err != nil {
+		t.Fatalf("ExportThread failed: %v", err)
+	}
+	reimported, header, err := transcript.Import(&buf)
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if header.ThreadID != "migrated" || !reflect.DeepEqual(reimported, conversation()) {
+		t.Errorf("Expected the seeded conversation, got %+v, %+v", header, reimported)
+	}
+	if restored, err := memory.RestoreFn()(context.Background(), "origin"); err != nil || len(restored.Messages) != 5 {
+		t.Errorf("Expected the thread of the header to be seeded, got %+v: %v", restored, err)
+	}
+
+	if _, err := transcript.Seed(context.Background(), memory, "", strings.NewReader(`{"role":"user"}`)); !errors.Is(err, transcript.ErrNoThreadID) {
+		t.Errorf("Expected ErrNoThreadID, got %v", err)
+	}
+	if _, err := transcript.Seed(context.Background(), nil, "thread", strings.NewReader(exported)); !errors.Is(err, transcript.ErrMemoryNil) {
+		t.Errorf("Expected ErrMemoryNil, got %v", err)
+	}
+}
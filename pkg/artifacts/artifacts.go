@@ -0,0 +1,105 @@
+// Package artifacts defines the storage of the large binary outputs of the nodes.
+//
+// The nodes producing files, images or large documents put them into a Store and keep only the
+// returned Artifact in the graph state, so that the state stays small and its persistence fast;
+// the consumers read the content back by key, or hand a signed URL over to a client or a model.
+// NewFSStore keeps the artifacts on the local filesystem, the s3 package on an S3-compatible
+// object storage.
+//
+// Example:
+//
+//	store, _ := artifacts.NewFSStore("/var/lib/ggraph/artifacts")
+//	artifact, err := store.Put(ctx, threadID+"/chart.png", bytes.NewReader(png), "image/png")
+//	currentState.Chart = artifact
+package artifacts
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrNotFound indicates that no artifact is stored under the requested key.
+	ErrNotFound = errors.New("artifact not found")
+	// ErrInvalidKey indicates that an artifact key is empty, absolute or escapes the store.
+	ErrInvalidKey = errors.New("invalid artifact key")
+	// ErrInvalidTTL indicates that the validity of a signed URL is not positive or too long for the store.
+	ErrInvalidTTL = errors.New("invalid signed url ttl")
+	// ErrSigningUnsupported indicates that the store is not configured to sign URLs.
+	ErrSigningUnsupported = errors.New("url signing is not configured")
+)
+
+// Artifact references a stored artifact; it is the value kept in the graph state in place of the content.
+type Artifact struct {
+	// Key identifies the artifact within the store.
+	Key string
+	// MIMEType is the media type of the content, e.g. "image/png".
+	MIMEType string
+	// Size is the length of the content in bytes.
+	Size int64
+	// Digest is the hex encoded SHA-256 of the content.
+	Digest string
+	// CreatedAt is the time the artifact was stored.
+	CreatedAt time.Time
+}
+
+// Store keeps the artifacts.
+//
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Put stores the content under the key, replacing any artifact with the same key.
+	//
+	// Parameters:
+	//   - ctx: The context of the operation.
+	//   - key: The key of the artifact, a slash separated relative path, see ValidateKey.
+	//   - content: The content of the artifact.
+	//   - mimeType: The media type of the content.
+	//
+	// Returns:
+	//   - The reference to the stored artifact.
+	//   - An error if the key is invalid or the storage fails.
+	Put(ctx context.Context, key string, content io.Reader, mimeType string) (Artifact, error)
+	// Get opens the content of the artifact.
+	//
+	// Parameters:
+	//   - ctx: The context of the operation.
+	//   - key: The key of the artifact.
+	//
+	// Returns:
+	//   - The content, to be closed by the caller.
+	//   - ErrNotFound if no artifact is stored under the key, or another error if the storage fails.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// SignURL returns a URL granting read access to the artifact for a limited time.
+	//
+	// Parameters:
+	//   - ctx: The context of the operation.
+	//   - key: The key of the artifact.
+	//   - ttl: The validity of the URL.
+	//
+	// Returns:
+	//   - The signed URL.
+	//   - ErrSigningUnsupported if the store cannot sign URLs, or another error if the key or the ttl are invalid.
+	SignURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// ValidateKey checks that a key is a relative path staying within the store.
+//
+// Parameters:
+//   - key: The key to check.
+//
+// Returns:
+//   - ErrInvalidKey or nil.
+func ValidateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.ContainsRune(key, '\\') || strings.ContainsRune(key, 0) {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
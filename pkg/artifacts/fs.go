@@ -0,0 +1,276 @@
+package artifacts
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidRoot indicates that the root directory of a filesystem store is empty.
+	ErrInvalidRoot = errors.New("root directory cannot be empty")
+	// ErrInvalidBaseURL indicates that the base URL of the signed URLs is not an absolute http(s) URL.
+	ErrInvalidBaseURL = errors.New("base url must be an absolute http(s) url")
+	// ErrInvalidSigningKey indicates that the key signing the URLs is empty.
+	ErrInvalidSigningKey = errors.New("signing key cannot be empty")
+)
+
+var _ Store = (*FSStore)(nil)
+
+// FSOptions holds the configuration for the filesystem Store implementation.
+type FSOptions struct {
+	// BaseURL is the URL the Handler of the store is served at, required to sign URLs.
+	BaseURL *url.URL
+	// SigningKey is the HMAC key signing the URLs, required to sign URLs.
+	SigningKey []byte
+}
+
+// FSOption is a functional option for configuring the filesystem Store implementation.
+type FSOption interface {
+	// Apply applies the option to the FSOptions.
+	//
+	// Parameters:
+	//   - r: A pointer to FSOptions to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *FSOptions) error
+}
+
+// FSOptionFunc is a function type that implements the FSOption interface.
+type FSOptionFunc func(*FSOptions) error
+
+// Apply applies the FSOptionFunc to the given FSOptions.
+//
+// Parameters:
+//   - r: A pointer to FSOptions to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s FSOptionFunc) Apply(r *FSOptions) error { return s(r) }
+
+// WithSignedURLs enables the signed URLs of a filesystem store, verified and served by its Handler.
+//
+// Parameters:
+//   - baseURL: The absolute URL the Handler is served at, e.g. "https://example.com/artifacts".
+//   - signingKey: The HMAC key signing the URLs, shared by the replicas serving the Handler.
+//
+// Returns:
+//   - An FSOption that enables the signed URLs.
+//
+// Example:
+//
+//	store, err := artifacts.NewFSStore(dir, artifacts.WithSignedURLs("https://example.com/artifacts", key))
+//	http.Handle("/artifacts/", store.Handler())
+func WithSignedURLs(baseURL string, signingKey []byte) FSOption {
+	return FSOptionFunc(func(r *FSOptions) error {
+		parsed, err := url.Parse(baseURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return ErrInvalidBaseURL
+		}
+		if len(signingKey) == 0 {
+			return ErrInvalidSigningKey
+		}
+		parsed.Path = strings.TrimSuffix(parsed.Path, "/")
+		r.BaseURL = parsed
+		r.SigningKey = append([]byte{}, signingKey...)
+		return nil
+	})
+}
+
+// FSStore is a Store keeping one file per artifact under a root directory.
+type FSStore struct {
+	root string
+	opts FSOptions
+	now  func() time.Time
+}
+
+// NewFSStore creates a filesystem Store, creating the root directory if needed.
+//
+// Parameters:
+//   - root: The directory holding the artifacts.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - The filesystem Store.
+//   - An error if the options are invalid or the root directory cannot be created.
+//
+// Example:
+//
+//	store, err := artifacts.NewFSStore("/var/lib/ggraph/artifacts")
+func NewFSStore(root string, opts ...FSOption) (*FSStore, error) {
+	if root == "" {
+		return nil, fmt.Errorf("filesystem artifact store creation failed: %w", ErrInvalidRoot)
+	}
+
+	useOpts := &FSOptions{}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("filesystem artifact store creation failed: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(root, 0o750); err != nil {
+		return nil, fmt.Errorf("filesystem artifact store creation failed: %w", err)
+	}
+	return &FSStore{root: root, opts: *useOpts, now: time.Now}, nil
+}
+
+// Put stores the content under the key, writing a temporary file renamed once complete.
+func (s *FSStore) Put(ctx context.Context, key string, content io.Reader, mimeType string) (Artifact, error) {
+	if err := ValidateKey(key); err != nil {
+		return Artifact{}, fmt.Errorf("cannot put artifact %q: %w", key, err)
+	}
+	path := s.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return Artifact{}, fmt.Errorf("cannot put artifact %q: %w", key, err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".artifact-*")
+	if err != nil {
+		return Artifact{}, fmt.Errorf("cannot put artifact %q: %w", key, err)
+	}
+	defer os.Remove(tmp.Name())
+
+	digest := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, digest), contextReader{ctx: ctx, r: content})
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return Artifact{}, fmt.Errorf("cannot put artifact %q: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return Artifact{}, fmt.Errorf("cannot put artifact %q: %w", key, err)
+	}
+
+	return Artifact{
+		Key:       key,
+		MIMEType:  mimeType,
+		Size:      size,
+		Digest:    hex.EncodeToString(digest.Sum(nil)),
+		CreatedAt: s.now().UTC(),
+	}, nil
+}
+
+// Get opens the file of the artifact.
+func (s *FSStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := ValidateKey(key); err != nil {
+		return nil, fmt.Errorf("cannot get artifact %q: %w", key, err)
+	}
+	file, err := os.Open(s.path(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("cannot get artifact %q: %w", key, ErrNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot get artifact %q: %w", key, err)
+	}
+	return file, nil
+}
+
+// SignURL returns a URL of the Handler of the store, carrying its expiration and its HMAC signature.
+func (s *FSStore) SignURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if err := ValidateKey(key); err != nil {
+		return "", fmt.Errorf("cannot sign the url of artifact %q: %w", key, err)
+	}
+	if s.opts.BaseURL == nil {
+		return "", fmt.Errorf("cannot sign the url of artifact %q: %w", key, ErrSigningUnsupported)
+	}
+	if ttl <= 0 {
+		return "", fmt.Errorf("cannot sign the url of artifact %q: %w", key, ErrInvalidTTL)
+	}
+
+	expires := strconv.FormatInt(s.now().Add(ttl).Unix(), 10)
+	rv := *s.opts.BaseURL
+	rv.Path += "/" + key
+	rv.RawQuery = url.Values{"expires": {expires}, "signature": {s.sign(key, expires)}}.Encode()
+	return rv.String(), nil
+}
+
+// Handler returns the HTTP handler serving the artifacts by signed URL, see WithSignedURLs.
+//
+// The handler answers 403 to the URLs with an invalid signature or expired, 404 to the missing
+// artifacts and supports the range and conditional requests.
+//
+// Returns:
+//   - The HTTP handler, to be mounted at the path of the base URL.
+func (s *FSStore) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		if s.opts.BaseURL == nil {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+
+		key, ok := strings.CutPrefix(r.URL.Path, s.opts.BaseURL.Path+"/")
+		if !ok || ValidateKey(key) != nil || !s.verify(key, r.URL.Query()) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		file, err := os.Open(s.path(key))
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		defer file.Close()
+		info, err := file.Stat()
+		if err != nil || info.IsDir() {
+			http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+			return
+		}
+		http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+	})
+}
+
+func (s *FSStore) path(key string) string {
+	return filepath.Join(s.root, filepath.FromSlash(key))
+}
+
+func (s *FSStore) sign(key, expires string) string {
+	mac := hmac.New(sha256.New, s.opts.SigningKey)
+	mac.Write([]byte(key + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *FSStore) verify(key string, query url.Values) bool {
+	expires, err := strconv.ParseInt(query.Get("expires"), 10, 64)
+	if err != nil || s.now().Unix() > expires {
+		return false
+	}
+	signature, err := hex.DecodeString(query.Get("signature"))
+	if err != nil {
+		return false
+	}
+	expected, _ := hex.DecodeString(s.sign(key, query.Get("expires")))
+	return hmac.Equal(signature, expected)
+}
+
+// contextReader stops reading once the context is done.
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c contextReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
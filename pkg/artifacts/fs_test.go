@@ -0,0 +1,133 @@
+package artifacts_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morphy76/ggraph/pkg/artifacts"
+)
+
+func TestValidateKey(t *testing.T) {
+	for _, key := range []string{"a.png", "thread-1/chart.png", "a/b/c.txt"} {
+		if err := artifacts.ValidateKey(key); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", key, err)
+		}
+	}
+	for _, key := range []string{"", "/etc/passwd", "../a", "a/../../b", "a//b", "a/./b", `a\b`, "a/"} {
+		if err := artifacts.ValidateKey(key); !errors.Is(err, artifacts.ErrInvalidKey) {
+			t.Errorf("Expected %q to be invalid, got %v", key, err)
+		}
+	}
+}
+
+func TestFSStore_PutGet(t *testing.T) {
+	store, err := artifacts.NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewFSStore failed: %v", err)
+	}
+	ctx := context.Background()
+
+	artifact, err := store.Put(ctx, "thread-1/notes.txt", strings.NewReader("hello"), "text/plain")
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if artifact.Size != 5 || artifact.MIMEType != "text/plain" || artifact.CreatedAt.IsZero() {
+		t.Errorf("Unexpected artifact %+v", artifact)
+	}
+	if artifact.Digest != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Errorf("Unexpected digest %s", artifact.Digest)
+	}
+
+	if _, err := store.Put(ctx, "thread-1/notes.txt", strings.NewReader("hello again"), "text/plain"); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	content, err := store.Get(ctx, "thread-1/notes.txt")
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	defer content.Close()
+	if data, _ := io.ReadAll(content); string(data) != "hello again" {
+		t.Errorf("Expected the artifact to be replaced, got %q", data)
+	}
+
+	if _, err := store.Get(ctx, "thread-1/missing.txt"); !errors.Is(err, artifacts.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if _, err := store.Put(ctx, "../escape.txt", strings.NewReader(""), ""); !errors.Is(err, artifacts.ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey, got %v", err)
+	}
+	if _, err := store.SignURL(ctx, "thread-1/notes.txt", time.Minute); !errors.Is(err, artifacts.ErrSigningUnsupported) {
+		t.Errorf("Expected ErrSigningUnsupported, got %v", err)
+	}
+}
+
+func TestFSStore_SignedURL(t *testing.T) {
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	store, err := artifacts.NewFSStore(t.TempDir(), artifacts.WithSignedURLs(server.URL+"/artifacts/", []byte("secret")))
+	if err != nil {
+		t.Fatalf("NewFSStore failed: %v", err)
+	}
+	mux.Handle("/artifacts/", store.Handler())
+
+	ctx := context.Background()
+	if _, err := store.Put(ctx, "charts/q1 sales.png", strings.NewReader("PNG"), "image/png"); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	signed, err := store.SignURL(ctx, "charts/q1 sales.png", time.Minute)
+	if err != nil {
+		t.Fatalf("SignURL failed: %v", err)
+	}
+
+	resp, err := http.Get(signed)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(data) != "PNG" || resp.Header.Get("Content-Type") != "image/png" {
+		t.Errorf("Unexpected response %d %q %s", resp.StatusCode, data, resp.Header.Get("Content-Type"))
+	}
+
+	tampered, _ := url.Parse(signed)
+	tampered.Path = "/artifacts/charts/other.png"
+	expired, _ := url.Parse(signed)
+	query := expired.Query()
+	query.Set("expires", "1")
+	expired.RawQuery = query.Encode()
+	for _, target := range []string{tampered.String(), expired.String(), server.URL + "/artifacts/charts/q1%20sales.png"} {
+		resp, err := http.Get(target)
+		if err != nil {
+			t.Fatalf("GET failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("Expected %s to be forbidden, got %d", target, resp.StatusCode)
+		}
+	}
+
+	if _, err := store.SignURL(ctx, "charts/q1 sales.png", 0); !errors.Is(err, artifacts.ErrInvalidTTL) {
+		t.Errorf("Expected ErrInvalidTTL, got %v", err)
+	}
+}
+
+func TestNewFSStore_Errors(t *testing.T) {
+	if _, err := artifacts.NewFSStore(""); !errors.Is(err, artifacts.ErrInvalidRoot) {
+		t.Errorf("Expected ErrInvalidRoot, got %v", err)
+	}
+	if _, err := artifacts.NewFSStore(t.TempDir(), artifacts.WithSignedURLs("/relative", []byte("k"))); !errors.Is(err, artifacts.ErrInvalidBaseURL) {
+		t.Errorf("Expected ErrInvalidBaseURL, got %v", err)
+	}
+	if _, err := artifacts.NewFSStore(t.TempDir(), artifacts.WithSignedURLs("https://example.com", nil)); !errors.Is(err, artifacts.ErrInvalidSigningKey) {
+		t.Errorf("Expected ErrInvalidSigningKey, got %v", err)
+	}
+}
@@ -0,0 +1,146 @@
+package s3
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+const (
+	// DefaultRegion is the default region of the bucket.
+	DefaultRegion = "us-east-1"
+)
+
+var (
+	// ErrInvalidBucket indicates that the provided bucket name is empty.
+	ErrInvalidBucket = errors.New("bucket cannot be empty")
+	// ErrInvalidCredentials indicates that the access key or the secret key are empty.
+	ErrInvalidCredentials = errors.New("access key and secret key cannot be empty")
+	// ErrInvalidRegion indicates that the provided region is empty.
+	ErrInvalidRegion = errors.New("region cannot be empty")
+	// ErrInvalidEndpoint indicates that the provided endpoint is not an absolute http(s) URL.
+	ErrInvalidEndpoint = errors.New("endpoint must be an absolute http(s) url")
+	// ErrHTTPClientNil indicates that the provided HTTP client is nil.
+	ErrHTTPClientNil = errors.New("http client cannot be nil")
+)
+
+// Credentials are the AWS credentials signing the requests.
+type Credentials struct {
+	// AccessKeyID is the access key.
+	AccessKeyID string
+	// SecretAccessKey is the secret key.
+	SecretAccessKey string
+	// SessionToken is the token of the temporary credentials, empty for the long-term ones.
+	SessionToken string
+}
+
+// Options holds the configuration for the S3 artifact Store implementation.
+type Options struct {
+	// Region is the region of the bucket, part of the request signature.
+	Region string
+	// Endpoint is the URL of the S3-compatible service, the AWS endpoint of the region when nil.
+	Endpoint *url.URL
+	// PathStyle addresses the bucket in the path rather than in the host name, as most S3-compatible services require.
+	PathStyle bool
+	// KeyPrefix is prepended to the keys of the artifacts, to share a bucket with other applications.
+	KeyPrefix string
+	// HTTPClient sends the requests, http.DefaultClient when nil.
+	HTTPClient *http.Client
+}
+
+// Option is a functional option for configuring the S3 artifact Store implementation.
+type Option interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - r: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *Options) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc func(*Options) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - r: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc) Apply(r *Options) error { return s(r) }
+
+// WithRegion sets the region of the bucket.
+//
+// Parameters:
+//   - region: The region, e.g. "eu-west-1".
+//
+// Returns:
+//   - An Option that sets the region.
+func WithRegion(region string) Option {
+	return OptionFunc(func(r *Options) error {
+		if region == "" {
+			return ErrInvalidRegion
+		}
+		r.Region = region
+		return nil
+	})
+}
+
+// WithEndpoint sets the URL of an S3-compatible service, e.g. MinIO, and addresses the bucket in the path.
+//
+// Parameters:
+//   - endpoint: The absolute URL of the service, e.g. "http://localhost:9000".
+//
+// Returns:
+//   - An Option that sets the endpoint.
+//
+// Example:
+//
+//	store, err := s3.NewStore("artifacts", credentials, s3.WithEndpoint("http://localhost:9000"))
+func WithEndpoint(endpoint string) Option {
+	return OptionFunc(func(r *Options) error {
+		parsed, err := url.Parse(endpoint)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return ErrInvalidEndpoint
+		}
+		parsed.Path = strings.TrimSuffix(parsed.Path, "/")
+		r.Endpoint = parsed
+		r.PathStyle = true
+		return nil
+	})
+}
+
+// WithKeyPrefix sets the prefix of the object keys of the artifacts.
+//
+// Parameters:
+//   - prefix: The key prefix, e.g. "ggraph/".
+//
+// Returns:
+//   - An Option that sets the key prefix.
+func WithKeyPrefix(prefix string) Option {
+	return OptionFunc(func(r *Options) error {
+		r.KeyPrefix = prefix
+		return nil
+	})
+}
+
+// WithHTTPClient sets the HTTP client sending the requests, e.g. to set its timeouts.
+//
+// Parameters:
+//   - client: The HTTP client.
+//
+// Returns:
+//   - An Option that sets the HTTP client.
+func WithHTTPClient(client *http.Client) Option {
+	return OptionFunc(func(r *Options) error {
+		if client == nil {
+			return ErrHTTPClientNil
+		}
+		r.HTTPClient = client
+		return nil
+	})
+}
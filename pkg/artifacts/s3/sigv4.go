@@ -0,0 +1,150 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	signingAlgorithm = "AWS4-HMAC-SHA256"
+	signingService   = "s3"
+	amzDateFormat    = "20060102T150405Z"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	// emptyPayloadHash is the SHA-256 of the empty payload.
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// signer signs the requests with the AWS Signature Version 4.
+type signer struct {
+	credentials Credentials
+	region      string
+}
+
+// sign adds the signature headers to the request.
+func (s signer) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.UTC().Format(amzDateFormat)
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if s.credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.credentials.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		lower := strings.ToLower(name)
+		if strings.HasPrefix(lower, "x-amz-") || lower == "content-type" {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := s.scope(amzDate)
+	req.Header.Set("Authorization", signingAlgorithm+
+		" Credential="+s.credentials.AccessKeyID+"/"+scope+
+		", SignedHeaders="+signedHeaders+
+		", Signature="+s.signature(amzDate, scope, canonicalRequest))
+}
+
+// presign returns the URL carrying the signature in its query, valid for the given time.
+func (s signer) presign(method string, target *url.URL, ttl time.Duration, now time.Time) string {
+	amzDate := now.UTC().Format(amzDateFormat)
+	scope := s.scope(amzDate)
+
+	query := target.Query()
+	query.Set("X-Amz-Algorithm", signingAlgorithm)
+	query.Set("X-Amz-Credential", s.credentials.AccessKeyID+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", strconv.FormatInt(int64(ttl/time.Second), 10))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if s.credentials.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", s.credentials.SessionToken)
+	}
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		target.EscapedPath(),
+		canonicalQuery(query),
+		"host:" + target.Host + "\n",
+		"host",
+		unsignedPayload,
+	}, "\n")
+
+	rv := *target
+	rv.RawQuery = canonicalQuery(query) + "&X-Amz-Signature=" + s.signature(amzDate, scope, canonicalRequest)
+	return rv.String()
+}
+
+func (s signer) scope(amzDate string) string {
+	return amzDate[:8] + "/" + s.region + "/" + signingService + "/aws4_request"
+}
+
+func (s signer) signature(amzDate, scope, canonicalRequest string) string {
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := signingAlgorithm + "\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(hashed[:])
+
+	key := hmacSHA256([]byte("AWS4"+s.credentials.SecretAccessKey), amzDate[:8])
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, signingService)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalQuery encodes the query sorted by name and value, as the signature requires.
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode percent-encodes all but the unreserved characters, and the slashes when not encodeSlash.
+func uriEncode(value string, encodeSlash bool) string {
+	var sb strings.Builder
+	for _, c := range []byte(value) {
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9', c == '-', c == '.', c == '_', c == '~':
+			sb.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			sb.WriteByte(c)
+		default:
+			sb.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{c})))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,190 @@
+// Package s3 provides an artifact Store backed by Amazon S3 or an S3-compatible object storage.
+//
+// The requests are signed with the AWS Signature Version 4 by the package itself, without
+// depending on the AWS SDK; the content of an artifact is buffered in memory while it is put.
+//
+// Example:
+//
+//	store, err := s3.NewStore("my-bucket", s3.Credentials{AccessKeyID: id, SecretAccessKey: secret},
+//	    s3.WithRegion("eu-west-1"), s3.WithKeyPrefix("ggraph/"))
+//	artifact, err := store.Put(ctx, threadID+"/report.pdf", report, "application/pdf")
+//	link, err := store.SignURL(ctx, artifact.Key, 15*time.Minute)
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/morphy76/ggraph/pkg/artifacts"
+)
+
+// MaxSignedURLTTL is the longest validity of a signed URL accepted by S3.
+const MaxSignedURLTTL = 7 * 24 * time.Hour
+
+var _ artifacts.Store = (*Store)(nil)
+
+// Store keeps the artifacts as the objects of a bucket.
+type Store struct {
+	bucket string
+	signer signer
+	opts   Options
+	now    func() time.Time
+}
+
+// NewStore creates an artifact Store on the given bucket.
+//
+// Parameters:
+//   - bucket: The name of the bucket, it must exist.
+//   - credentials: The credentials signing the requests.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - The S3 artifact Store.
+//   - An error if the bucket, the credentials or an option are invalid.
+//
+// Example:
+//
+//	store, err := s3.NewStore("artifacts", credentials, s3.WithEndpoint("http://localhost:9000"))
+func NewStore(bucket string, credentials Credentials, opts ...Option) (*Store, error) {
+	if bucket == "" {
+		return nil, fmt.Errorf("s3 artifact store creation failed: %w", ErrInvalidBucket)
+	}
+	if credentials.AccessKeyID == "" || credentials.SecretAccessKey == "" {
+		return nil, fmt.Errorf("s3 artifact store creation failed: %w", ErrInvalidCredentials)
+	}
+
+	useOpts := &Options{
+		Region:     DefaultRegion,
+		HTTPClient: http.DefaultClient,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("s3 artifact store creation failed: %w", err)
+		}
+	}
+
+	return &Store{
+		bucket: bucket,
+		signer: signer{credentials: credentials, region: useOpts.Region},
+		opts:   *useOpts,
+		now:    time.Now,
+	}, nil
+}
+
+// Put uploads the content as the object of the key.
+func (s *Store) Put(ctx context.Context, key string, content io.Reader, mimeType string) (artifacts.Artifact, error) {
+	if err := artifacts.ValidateKey(key); err != nil {
+		return artifacts.Artifact{}, fmt.Errorf("cannot put artifact %q: %w", key, err)
+	}
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return artifacts.Artifact{}, fmt.Errorf("cannot put artifact %q: %w", key, err)
+	}
+	digest := sha256.Sum256(data)
+	payloadHash := hex.EncodeToString(digest[:])
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key).String(), bytes.NewReader(data))
+	if err != nil {
+		return artifacts.Artifact{}, fmt.Errorf("cannot put artifact %q: %w", key, err)
+	}
+	if mimeType != "" {
+		req.Header.Set("Content-Type", mimeType)
+	}
+	now := s.now()
+	s.signer.sign(req, payloadHash, now)
+
+	resp, err := s.opts.HTTPClient.Do(req)
+	if err != nil {
+		return artifacts.Artifact{}, fmt.Errorf("cannot put artifact %q: %w", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return artifacts.Artifact{}, fmt.Errorf("cannot put artifact %q: %w", key, responseError(resp))
+	}
+
+	return artifacts.Artifact{
+		Key:       key,
+		MIMEType:  mimeType,
+		Size:      int64(len(data)),
+		Digest:    payloadHash,
+		CreatedAt: now.UTC(),
+	}, nil
+}
+
+// Get downloads the object of the key.
+func (s *Store) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	if err := artifacts.ValidateKey(key); err != nil {
+		return nil, fmt.Errorf("cannot get artifact %q: %w", key, err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.objectURL(key).String(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get artifact %q: %w", key, err)
+	}
+	s.signer.sign(req, emptyPayloadHash, s.now())
+
+	resp, err := s.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot get artifact %q: %w", key, err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, fmt.Errorf("cannot get artifact %q: %w", key, artifacts.ErrNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, fmt.Errorf("cannot get artifact %q: %w", key, responseError(resp))
+	}
+	return resp.Body, nil
+}
+
+// SignURL returns a presigned GET URL of the object, valid for at most MaxSignedURLTTL.
+func (s *Store) SignURL(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	if err := artifacts.ValidateKey(key); err != nil {
+		return "", fmt.Errorf("cannot sign the url of artifact %q: %w", key, err)
+	}
+	if ttl < time.Second || ttl > MaxSignedURLTTL {
+		return "", fmt.Errorf("cannot sign the url of artifact %q: %w", key, artifacts.ErrInvalidTTL)
+	}
+	return s.signer.presign(http.MethodGet, s.objectURL(key), ttl, s.now()), nil
+}
+
+// objectURL returns the URL of the object of the key, addressing the bucket by host or by path.
+func (s *Store) objectURL(key string) *url.URL {
+	objectPath := "/" + s.opts.KeyPrefix + key
+
+	var rv url.URL
+	switch {
+	case s.opts.Endpoint != nil && s.opts.PathStyle:
+		rv = *s.opts.Endpoint
+		rv.Path += "/" + s.bucket + objectPath
+	case s.opts.Endpoint != nil:
+		rv = *s.opts.Endpoint
+		rv.Host = s.bucket + "." + rv.Host
+		rv.Path += objectPath
+	default:
+		rv = url.URL{Scheme: "https", Host: s.bucket + ".s3." + s.opts.Region + ".amazonaws.com", Path: objectPath}
+	}
+	rv.RawPath = uriEncode(rv.Path, false)
+	return &rv
+}
+
+// responseError describes an unexpected response, with the S3 error code when available.
+func responseError(resp *http.Response) error {
+	var body struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if xml.Unmarshal(data, &body) == nil && body.Code != "" {
+		return fmt.Errorf("unexpected status %s: %s: %s", resp.Status, body.Code, body.Message)
+	}
+	return fmt.Errorf("unexpected status %s", resp.Status)
+}
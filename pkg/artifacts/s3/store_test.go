@@ -0,0 +1,159 @@
+package s3_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/morphy76/ggraph/pkg/artifacts"
+	"github.com/morphy76/ggraph/pkg/artifacts/s3"
+)
+
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]string
+	types   map[string]string
+	paths   []string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.paths = append(f.paths, r.URL.EscapedPath())
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 Credential=AK/") || r.Header.Get("X-Amz-Content-Sha256") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`<Error><Code>AccessDenied</Code><Message>unsigned</Message></Error>`))
+		return
+	}
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = string(body)
+		f.types[r.URL.Path] = r.Header.Get("Content-Type")
+	case http.MethodGet:
+		object, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`<Error><Code>NoSuchKey</Code></Error>`))
+			return
+		}
+		w.Write([]byte(object))
+	}
+}
+
+func newTestStore(t *testing.T) (*s3.Store, *fakeS3) {
+	fake := &fakeS3{objects: map[string]string{}, types: map[string]string{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	store, err := s3.NewStore("bucket", s3.Credentials{AccessKeyID: "AK", SecretAccessKey: "SK"},
+		s3.WithEndpoint(server.URL), s3.WithKeyPrefix("ggraph/"))
+	if err != nil {
+		t.Fatalf("NewStore failed: %v", err)
+	}
+	return store, fake
+}
+
+func TestStore_PutGet(t *testing.T) {
+	store, fake := newTestStore(t)
+	ctx := context.Background()
+
+	artifact, err := store.Put(ctx, "thread-1/q1 report.txt", strings.NewReader("hello"), "text/plain")
+	if err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	if artifact.Key != "thread-1/q1 report.txt" || artifact.Size != 5 || artifact.MIMEType != "text/plain" {
+		t.Errorf("Unexpected artifact %+v", artifact)
+	}
+	if artifact.Digest != "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824" {
+		t.Errorf("Unexpected digest %s", artifact.Digest)
+	}
+	if fake.types["/bucket/ggraph/thread-1/q1 report.txt"] != "text/plain" {
+		t.Errorf("Expected the object to be stored with its type under the prefix, got %v", fake.types)
+	}
+	if fake.paths[0] != "/bucket/ggraph/thread-1/q1%20report.txt" {
+		t.Errorf("Expected the key to be encoded, got %s", fake.paths[0])
+	}
+
+	content, err := store.Get(ctx, artifact.Key)
+	if err != nil {
+		t.Fatalf("Get failed: %v", err)
+	}
+	defer content.Close()
+	if data, _ := io.ReadAll(content); string(data) != "hello" {
+		t.Errorf("Unexpected content %q", data)
+	}
+
+	if _, err := store.Get(ctx, "missing"); !errors.Is(err, artifacts.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound, got %v", err)
+	}
+	if _, err := store.Put(ctx, "../escape", strings.NewReader(""), ""); !errors.Is(err, artifacts.ErrInvalidKey) {
+		t.Errorf("Expected ErrInvalidKey, got %v", err)
+	}
+}
+
+func TestStore_SignURL(t *testing.T) {
+	store, _ := newTestStore(t)
+
+	signed, err := store.SignURL(context.Background(), "report.pdf", 15*time.Minute)
+	if err != nil {
+		t.Fatalf("SignURL failed: %v", err)
+	}
+	parsed, _ := url.Parse(signed)
+	query := parsed.Query()
+	if parsed.Path != "/bucket/ggraph/report.pdf" || query.Get("X-Amz-Expires") != "900" || query.Get("X-Amz-Signature") == "" {
+		t.Errorf("Unexpected signed url %s", signed)
+	}
+
+	for _, ttl := range []time.Duration{0, 8 * 24 * time.Hour} {
+		if _, err := store.SignURL(context.Background(), "report.pdf", ttl); !errors.Is(err, artifacts.ErrInvalidTTL) {
+			t.Errorf("Expected ErrInvalidTTL for %v, got %v", ttl, err)
+		}
+	}
+}
+
+func TestNewStore_Errors(t *testing.T) {
+	credentials := s3.Credentials{AccessKeyID: "AK", SecretAccessKey: "SK"}
+	tests := []struct {
+		name        string
+		bucket      string
+		credentials s3.Credentials
+		opt         s3.Option
+		want        error
+	}{
+		{"empty bucket", "", credentials, s3.WithKeyPrefix(""), s3.ErrInvalidBucket},
+		{"no credentials", "bucket", s3.Credentials{}, s3.WithKeyPrefix(""), s3.ErrInvalidCredentials},
+		{"empty region", "bucket", credentials, s3.WithRegion(""), s3.ErrInvalidRegion},
+		{"relative endpoint", "bucket", credentials, s3.WithEndpoint("localhost:9000"), s3.ErrInvalidEndpoint},
+		{"nil client", "bucket", credentials, s3.WithHTTPClient(nil), s3.ErrHTTPClientNil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s3.NewStore(tt.bucket, tt.credentials, tt.opt); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestStore_Failure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		w.Write([]byte(`<Error><Code>SignatureDoesNotMatch</Code><Message>bad signature</Message></Error>`))
+	}))
+	defer server.Close()
+
+	store, _ := s3.NewStore("bucket", s3.Credentials{AccessKeyID: "AK", SecretAccessKey: "SK"}, s3.WithEndpoint(server.URL))
+	_, err := store.Put(context.Background(), "a.txt", strings.NewReader("a"), "text/plain")
+	if err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("Expected the S3 error code, got %v", err)
+	}
+}
@@ -0,0 +1,234 @@
+package builders
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+var (
+	// ErrRouteLabelFnNil indicates that a label route policy has no label function.
+	ErrRouteLabelFnNil = errors.New("route label function cannot be nil")
+	// ErrRouteTargetNil indicates that a route rule has no target node.
+	ErrRouteTargetNil = errors.New("route target node cannot be nil")
+	// ErrNoRouteRules indicates that a label route policy has no rules.
+	ErrNoRouteRules = errors.New("label route policy needs at least one rule")
+	// ErrMultipleElseRoutes indicates that a label route policy has more than one Else rule.
+	ErrMultipleElseRoutes = errors.New("label route policy can have a single Else rule")
+	// ErrRouteTargetNotConnected indicates that the target of a route rule is not connected to the node owning the policy.
+	ErrRouteTargetNotConnected = errors.New("route target is not connected to the node")
+	// ErrRoutesNotExhaustive indicates that some states match no route rule.
+	ErrRoutesNotExhaustive = g.ErrRoutesNotExhaustive
+)
+
+// RouteLabelFn returns the value of a routing label computed from the state, false if the label is not set.
+type RouteLabelFn[T g.SharedState] func(userInput, currentState T, key string) (string, bool)
+
+// RouteCondition is a condition on a routing label, created by When.
+type RouteCondition[T g.SharedState] struct {
+	key   string
+	match func(value string, ok bool) bool
+}
+
+// RouteRule routes to a target node when its condition matches, created by RouteCondition.RouteTo or Else.
+type RouteRule[T g.SharedState] struct {
+	condition RouteCondition[T]
+	target    g.Node[T]
+	end       bool
+	otherwise bool
+}
+
+func (r RouteRule[T]) edge(edges []g.Edge[T]) g.Edge[T] {
+	for _, edge := range edges {
+		if (r.end && edge.Role() == g.EndEdge) || (!r.end && edge.To() == r.target) {
+			return edge
+		}
+	}
+	return nil
+}
+
+func (r RouteRule[T]) targetName() string {
+	if r.end {
+		return ReservedNodeNameEnd
+	}
+	return r.target.Name()
+}
+
+// When starts a condition on the routing label with the given key.
+//
+// Parameters:
+//   - key: The key of the routing label.
+//
+// Returns:
+//   - A RouteCondition matching when the label is set, refine it with Equals, NotEquals, In or Matching.
+//
+// Example:
+//
+//	policy, err := builders.CreateLabelRoutePolicy(labels,
+//	    builders.When[MyState]("intent").Equals("refund").RouteTo(refundNode),
+//	    builders.When[MyState]("intent").In("invoice", "billing").RouteTo(billingNode),
+//	    builders.Else(fallbackNode),
+//	)
+func When[T g.SharedState](key string) RouteCondition[T] {
+	return RouteCondition[T]{
+		key:   key,
+		match: func(_ string, ok bool) bool { return ok },
+	}
+}
+
+// Exists matches when the label is set, whatever its value.
+func (c RouteCondition[T]) Exists() RouteCondition[T] {
+	c.match = func(_ string, ok bool) bool { return ok }
+	return c
+}
+
+// Equals matches when the label is set to the given value.
+func (c RouteCondition[T]) Equals(value string) RouteCondition[T] {
+	return c.In(value)
+}
+
+// NotEquals matches when the label is not set or set to a different value.
+func (c RouteCondition[T]) NotEquals(value string) RouteCondition[T] {
+	c.match = func(actual string, ok bool) bool { return !ok || actual != value }
+	return c
+}
+
+// In matches when the label is set to one of the given values.
+func (c RouteCondition[T]) In(values ...string) RouteCondition[T] {
+	useValues := slices.Clone(values)
+	c.match = func(actual string, ok bool) bool { return ok && slices.Contains(useValues, actual) }
+	return c
+}
+
+// Matching matches when the label is set and the predicate accepts its value.
+func (c RouteCondition[T]) Matching(predicate func(value string) bool) RouteCondition[T] {
+	c.match = func(actual string, ok bool) bool { return ok && predicate(actual) }
+	return c
+}
+
+// RouteTo completes the condition into a rule routing to the given node.
+//
+// Parameters:
+//   - target: The node to route to when the condition matches.
+//
+// Returns:
+//   - The RouteRule.
+func (c RouteCondition[T]) RouteTo(target g.Node[T]) RouteRule[T] {
+	return RouteRule[T]{condition: c, target: target}
+}
+
+// RouteToEnd completes the condition into a rule terminating the graph through an end edge of the node.
+//
+// Returns:
+//   - The RouteRule.
+func (c RouteCondition[T]) RouteToEnd() RouteRule[T] {
+	return RouteRule[T]{condition: c, end: true}
+}
+
+// Else creates the rule routing to the given node when no other rule matches.
+//
+// Parameters:
+//   - target: The default node.
+//
+// Returns:
+//   - The RouteRule.
+func Else[T g.SharedState](target g.Node[T]) RouteRule[T] {
+	return RouteRule[T]{target: target, otherwise: true}
+}
+
+// ElseEnd creates the rule terminating the graph through an end edge of the node when no other rule matches.
+//
+// Returns:
+//   - The RouteRule.
+func ElseEnd[T g.SharedState]() RouteRule[T] {
+	return RouteRule[T]{end: true, otherwise: true}
+}
+
+// CreateLabelRoutePolicy compiles route rules into a routing policy.
+//
+// The rules are evaluated in order against the routing labels computed by labelFn, the first
+// matching rule selects the outbound edge leading to its target; the Else rule, wherever it is
+// placed, applies when no rule matches. The policy implements graph.RouteValidator: Validate fails
+// when a target is not connected to the node or when the policy has no Else rule, as some states
+// would match no rule, unless the node has a fallback edge or the policy is chained with
+// graph.FallbackRoute.
+//
+// Parameters:
+//   - labelFn: The function computing the routing labels from the state.
+//   - rules: The route rules.
+//
+// Returns:
+//   - The routing policy.
+//   - An error if the rules are invalid.
+//
+// Example:
+//
+//	labels := func(userInput, state MyState, key string) (string, bool) {
+//	    value, ok := state.Labels[key]
+//	    return value, ok
+//	}
+//	policy, err := builders.CreateLabelRoutePolicy(labels,
+//	    builders.When[MyState]("intent").Equals("refund").RouteTo(refundNode),
+//	    builders.Else(fallbackNode),
+//	)
+//	router, _ := builders.CreateRouter("IntentRouter", policy)
+func CreateLabelRoutePolicy[T g.SharedState](labelFn RouteLabelFn[T], rules ...RouteRule[T]) (g.RoutePolicy[T], error) {
+	if labelFn == nil {
+		return nil, fmt.Errorf("label route policy creation failed: %w", ErrRouteLabelFnNil)
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("label route policy creation failed: %w", ErrNoRouteRules)
+	}
+
+	rv := &labelRoutePolicy[T]{labelFn: labelFn}
+	for idx, rule := range rules {
+		if rule.target == nil && !rule.end {
+			return nil, fmt.Errorf("label route policy creation failed: rule %d: %w", idx, ErrRouteTargetNil)
+		}
+		if rule.otherwise {
+			if rv.otherwise != nil {
+				return nil, fmt.Errorf("label route policy creation failed: %w", ErrMultipleElseRoutes)
+			}
+			rv.otherwise = &rule
+			continue
+		}
+		rv.rules = append(rv.rules, rule)
+	}
+	return rv, nil
+}
+
+var _ g.RoutePolicy[g.SharedState] = (*labelRoutePolicy[g.SharedState])(nil)
+var _ g.RouteValidator[g.SharedState] = (*labelRoutePolicy[g.SharedState])(nil)
+
+type labelRoutePolicy[T g.SharedState] struct {
+	labelFn   RouteLabelFn[T]
+	rules     []RouteRule[T]
+	otherwise *RouteRule[T]
+}
+
+func (p *labelRoutePolicy[T]) SelectEdge(userInput T, currentState T, edges []g.Edge[T]) g.Edge[T] {
+	for _, rule := range p.rules {
+		value, ok := p.labelFn(userInput, currentState, rule.condition.key)
+		if rule.condition.match(value, ok) {
+			return rule.edge(edges)
+		}
+	}
+	if p.otherwise != nil {
+		return p.otherwise.edge(edges)
+	}
+	return nil
+}
+
+func (p *labelRoutePolicy[T]) ValidateRoutes(node g.Node[T], edges []g.Edge[T]) error {
+	if p.otherwise == nil {
+		return fmt.Errorf("%w: add an Else rule", ErrRoutesNotExhaustive)
+	}
+	for _, rule := range append(slices.Clone(p.rules), *p.otherwise) {
+		if rule.edge(edges) == nil {
+			return fmt.Errorf("%w: %s", ErrRouteTargetNotConnected, rule.targetName())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package builders_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func labelFromInput(userInput, currentState TestState, key string) (string, bool) {
+	if key != "value" || userInput.Value == "" {
+		return "", false
+	}
+	return userInput.Value, true
+}
+
+func markNode(t *testing.T, name string) g.Node[TestState] {
+	node, err := builders.NewNode(name, func(userInput, currentState TestState, notify g.NotifyPartialFn[TestState]) (TestState, error) {
+		currentState.Value = name
+		return currentState, nil
+	})
+	if err != nil {
+		t.Fatalf("NewNode() failed: %v", err)
+	}
+	return node
+}
+
+// TestCreateLabelRoutePolicy_Routes tests that the rules route by label, in order, with the Else fallback
+func TestCreateLabelRoutePolicy_Routes(t *testing.T) {
+	nodeA, nodeB, fallback := markNode(t, "A"), markNode(t, "B"), markNode(t, "Fallback")
+
+	policy, err := builders.CreateLabelRoutePolicy(labelFromInput,
+		builders.When[TestState]("value").Equals("a").RouteTo(nodeA),
+		builders.Else(fallback),
+		builders.When[TestState]("value").In("b", "c").RouteTo(nodeB),
+		builders.When[TestState]("value").Matching(func(value string) bool { return value == "stop" }).RouteToEnd(),
+	)
+	if err != nil {
+		t.Fatalf("CreateLabelRoutePolicy() failed: %v", err)
+	}
+	router, _ := builders.CreateRouter("Router", policy)
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[TestState], 20)
+	runtime, _ := builders.CreateRuntime(builders.CreateStartEdge(router), stateMonitorCh)
+	defer runtime.Shutdown()
+	runtime.AddEdge(
+		builders.CreateEdge(router, nodeA),
+		builders.CreateEdge(router, nodeB),
+		builders.CreateEdge(router, fallback),
+		builders.CreateEndEdge(router),
+		builders.CreateEndEdge(nodeA),
+		builders.CreateEndEdge(nodeB),
+		builders.CreateEndEdge(fallback),
+	)
+	if err := runtime.Validate(); err != nil {
+		t.Fatalf("Validate() failed: %v", err)
+	}
+
+	for input, expected := range map[string]string{"a": "A", "c": "B", "z": "Fallback", "": "Fallback", "stop": ""} {
+		runtime.Invoke(TestState{Value: input})
+		timeout := time.After(2 * time.Second)
+		for done := false; !done; {
+			select {
+			case entry := <-stateMonitorCh:
+				if entry.Running {
+					continue
+				}
+				if entry.Error != nil {
+					t.Fatalf("Unexpected error for %q: %v", input, entry.Error)
+				}
+				if entry.NewState.Value != expected {
+					t.Errorf("Expected %q to route to %q, got %q", input, expected, entry.NewState.Value)
+				}
+				done = true
+			case <-timeout:
+				t.Fatalf("Timeout waiting for %q", input)
+			}
+		}
+	}
+}
+
+// TestCreateLabelRoutePolicy_Validate tests that misconfigured rules fail the graph validation
+func TestCreateLabelRoutePolicy_Validate(t *testing.T) {
+	nodeA, nodeB := markNode(t, "A"), markNode(t, "B")
+
+	validate := func(rules ...builders.RouteRule[TestState]) error {
+		policy, err := builders.CreateLabelRoutePolicy(labelFromInput, rules...)
+		if err != nil {
+			t.Fatalf("CreateLabelRoutePolicy() failed: %v", err)
+		}
+		router, _ := builders.CreateRouter("Router", policy)
+		runtime, _ := builders.CreateRuntime(builders.CreateStartEdge(router), nil)
+		defer runtime.Shutdown()
+		runtime.AddEdge(builders.CreateEdge(router, nodeA), builders.CreateEndEdge(nodeA))
+		return runtime.Validate()
+	}
+
+	if err := validate(builders.When[TestState]("value").Equals("a").RouteTo(nodeA)); !errors.Is(err, builders.ErrRoutesNotExhaustive) {
+		t.Errorf("Expected ErrRoutesNotExhaustive, got %v", err)
+	}
+	if err := validate(builders.When[TestState]("value").Equals("b").RouteTo(nodeB), builders.Else(nodeA)); !errors.Is(err, builders.ErrRouteTargetNotConnected) {
+		t.Errorf("Expected ErrRouteTargetNotConnected, got %v", err)
+	}
+	if err := validate(builders.When[TestState]("value").Equals("a").RouteTo(nodeA), builders.ElseEnd[TestState]()); !errors.Is(err, builders.ErrRouteTargetNotConnected) {
+		t.Errorf("Expected the missing end edge to be reported, got %v", err)
+	}
+	if err := validate(builders.When[TestState]("value").NotEquals("x").RouteTo(nodeA), builders.Else(nodeA)); err != nil {
+		t.Errorf("Expected a valid policy, got %v", err)
+	}
+}
+
+// TestCreateLabelRoutePolicy_Errors tests the creation errors of a label route policy
+func TestCreateLabelRoutePolicy_Errors(t *testing.T) {
+	nodeA := markNode(t, "A")
+
+	if _, err := builders.CreateLabelRoutePolicy[TestState](nil, builders.Else(nodeA)); !errors.Is(err, builders.ErrRouteLabelFnNil) {
+		t.Errorf("Expected ErrRouteLabelFnNil, got %v", err)
+	}
+	if _, err := builders.CreateLabelRoutePolicy[TestState](labelFromInput); !errors.Is(err, builders.ErrNoRouteRules) {
+		t.Errorf("Expected ErrNoRouteRules, got %v", err)
+	}
+	if _, err := builders.CreateLabelRoutePolicy(labelFromInput, builders.When[TestState]("value").RouteTo(nil)); !errors.Is(err, builders.ErrRouteTargetNil) {
+		t.Errorf("Expected ErrRouteTargetNil, got %v", err)
+	}
+	if _, err := builders.CreateLabelRoutePolicy(labelFromInput, builders.Else(nodeA), builders.ElseEnd[TestState]()); !errors.Is(err, builders.ErrMultipleElseRoutes) {
+		t.Errorf("Expected ErrMultipleElseRoutes, got %v", err)
+	}
+}
@@ -0,0 +1,44 @@
+package builders
+
+import (
+	"context"
+	"fmt"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// NewConversationMemoryNode creates a node compacting the conversation state with the given memory.
+//
+// Unlike a.WithConversationMemory, which only trims the messages sent to the model, the node
+// rewrites the state, so that long threads do not grow unbounded in the persistence either.
+// The user input is not merged into the state, the downstream chat node still sees it as the new turn.
+//
+// Parameters:
+//   - name: The unique name for the node.
+//   - memory: The conversation memory, e.g. a.NewTurnWindow or a.NewSummaryMemory.
+//   - opts: Optional node options.
+//
+// Returns:
+//   - The memory node.
+//   - An error if the node could not be created.
+//
+// Example:
+//
+//	window, _ := agent.NewTurnWindow(20)
+//	memoryNode, err := builders.NewConversationMemoryNode("Memory", window)
+//	runtime.AddEdge(builders.CreateEdge(memoryNode, chatNode))
+func NewConversationMemoryNode(name string, memory a.ConversationMemory, opts ...g.NodeOption[a.Conversation]) (g.Node[a.Conversation], error) {
+	if memory == nil {
+		return nil, fmt.Errorf("conversation memory node creation error for name %s: %w", name, a.ErrConversationMemoryNil)
+	}
+
+	return NewContextNode(name, func(ctx context.Context, userInput, currentState a.Conversation, notify g.NotifyPartialFn[a.Conversation]) (a.Conversation, error) {
+		messages, err := memory.Compact(ctx, currentState.Messages)
+		if err != nil {
+			return currentState, fmt.Errorf("cannot compact the conversation: %w", err)
+		}
+		currentState.Messages = messages
+		return currentState, nil
+	}, opts...)
+}
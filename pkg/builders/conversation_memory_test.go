@@ -0,0 +1,61 @@
+package builders_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// TestNewConversationMemoryNode tests that the memory node compacts the conversation state
+func TestNewConversationMemoryNode(t *testing.T) {
+	if _, err := builders.NewConversationMemoryNode("Memory", nil); !errors.Is(err, a.ErrConversationMemoryNil) {
+		t.Errorf("Expected ErrConversationMemoryNil, got %v", err)
+	}
+
+	window, _ := a.NewTurnWindow(1)
+	node, err := builders.NewConversationMemoryNode("Memory", window)
+	if err != nil {
+		t.Fatalf("NewConversationMemoryNode() failed: %v", err)
+	}
+
+	seed, _ := builders.NewNode("Seed", func(userInput, currentState a.Conversation, notify g.NotifyPartialFn[a.Conversation]) (a.Conversation, error) {
+		currentState.Messages = append(currentState.Messages, userInput.Messages...)
+		return currentState, nil
+	})
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[a.Conversation], 10)
+	runtime, _ := builders.CreateRuntime(builders.CreateStartEdge(seed), stateMonitorCh)
+	runtime.AddEdge(builders.CreateEdge(seed, node), builders.CreateEndEdge(node))
+	defer runtime.Shutdown()
+
+	runtime.Invoke(a.CreateConversation(
+		a.CreateMessage(a.System, "prompt"),
+		a.CreateMessage(a.User, "first"),
+		a.CreateMessage(a.Assistant, "answer"),
+		a.CreateMessage(a.User, "second"),
+	))
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case entry := <-stateMonitorCh:
+			if entry.Error != nil {
+				t.Fatalf("Unexpected error: %v", entry.Error)
+			}
+			if entry.Running {
+				continue
+			}
+			messages := entry.NewState.Messages
+			if len(messages) != 2 || messages[0].Content != "prompt" || messages[1].Content != "second" {
+				t.Errorf("Expected the compacted conversation, got %+v", messages)
+			}
+			return
+		case <-timeout:
+			t.Fatal("Timeout waiting for the graph completion")
+		}
+	}
+}
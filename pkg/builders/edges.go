@@ -51,10 +51,87 @@ func CreateEdge[T g.SharedState](from, to g.Node[T], labels ...map[string]string
 //	firstNode, _ := CreateNode[MyState]("first", myFunction)
 //	startEdge, _ := CreateStartEdge(firstNode)
 func CreateStartEdge[T g.SharedState](to g.Node[T]) g.Edge[T] {
-	startNode, _ := createStartNode[T]()
+	return CreateStartEdgeWithFn(to, nil)
+}
+
+// CreateStartEdgeWithFn creates a new edge from the implicit start node running an initializer.
+//
+// The start node runs startFn at the beginning of every invocation, before the first
+// operational node, to normalize and validate the user input into the state.
+//
+// Type Parameters:
+//   - T: The SharedState type that will be passed through the graph execution.
+//
+// Parameters:
+//   - to: The first operational node in the graph that will receive the initial state.
+//   - startFn: The initializer run by the start node; nil keeps the state unchanged.
+//
+// Returns:
+//   - A new StartEdge instance connecting the implicit start node to the specified node.
+//
+// Example:
+//
+//	startEdge := CreateStartEdgeWithFn(firstNode, func(ctx context.Context, userInput, currentState MyState) (MyState, error) {
+//	    if userInput.Question == "" {
+//	        return currentState, errors.New("empty question")
+//	    }
+//	    currentState.Question = userInput.Question
+//	    return currentState, nil
+//	})
+func CreateStartEdgeWithFn[T g.SharedState](to g.Node[T], startFn g.StartFn[T]) g.Edge[T] {
+	startNode, _ := createStartNode(startFn)
 	return i.EdgeImplFactory(startNode, to, g.StartEdge)
 }
 
+// CreateEntryEdge creates a new start edge for the named entry point of the graph.
+//
+// A runtime serving several related workflows over the same nodes adds a start edge per entry
+// point, selected by the invocations with g.InvokeConfigEntryPoint; every entry point is
+// validated independently.
+//
+// Type Parameters:
+//   - T: The SharedState type that will be passed through the graph execution.
+//
+// Parameters:
+//   - name: The name of the entry point.
+//   - to: The first operational node of the entry point.
+//
+// Returns:
+//   - A new StartEdge instance labelled with the entry point.
+//
+// Example:
+//
+//	runtime, _ := builders.CreateRuntime(builders.CreateStartEdge(chatNode), stateMonitorCh)
+//	runtime.AddEdge(builders.CreateEntryEdge("webhook", parseNode))
+//	runtime.Invoke(event, g.InvokeConfigEntryPoint("webhook"))
+func CreateEntryEdge[T g.SharedState](name string, to g.Node[T]) g.Edge[T] {
+	return CreateEntryEdgeWithFn(name, to, nil)
+}
+
+// CreateEntryEdgeWithFn creates a new start edge for the named entry point, its start node running an initializer.
+//
+// Type Parameters:
+//   - T: The SharedState type that will be passed through the graph execution.
+//
+// Parameters:
+//   - name: The name of the entry point.
+//   - to: The first operational node of the entry point.
+//   - startFn: The initializer run by the start node of the entry point; nil keeps the state unchanged.
+//
+// Returns:
+//   - A new StartEdge instance labelled with the entry point.
+//
+// Example:
+//
+//	cronEdge := builders.CreateEntryEdgeWithFn("cron", reportNode, func(ctx context.Context, userInput, currentState MyState) (MyState, error) {
+//	    currentState.Since = time.Now().Add(-24 * time.Hour)
+//	    return currentState, nil
+//	})
+func CreateEntryEdgeWithFn[T g.SharedState](name string, to g.Node[T], startFn g.StartFn[T]) g.Edge[T] {
+	startNode, _ := createStartNode(startFn)
+	return i.EdgeImplFactory(startNode, to, g.StartEdge, map[string]string{g.EntryPointEdgeLabel: name})
+}
+
 // CreateEndEdge creates a new edge from a specified node to the implicit end node.
 //
 // This function is used to define an exit point of a graph workflow by connecting
@@ -76,6 +153,58 @@ func CreateStartEdge[T g.SharedState](to g.Node[T]) g.Edge[T] {
 //	lastNode, _ := CreateNode[MyState]("last", myFunction)
 //	endEdge, _ := CreateEndEdge(lastNode)
 func CreateEndEdge[T g.SharedState](from g.Node[T], labels ...map[string]string) g.Edge[T] {
-	endNode, _ := createEndNode[T]()
+	return CreateEndEdgeWithFn(from, nil, labels...)
+}
+
+// CreateOutcomeEdge creates a new edge to an implicit end node carrying the outcome of the invocation.
+//
+// The invocations completing through the edge notify the outcome with their final entry, see
+// g.StateMonitorEntry.Outcome, so that the callers need not infer it from the state.
+//
+// Type Parameters:
+//   - T: The SharedState type that will be passed through the graph execution.
+//
+// Parameters:
+//   - from: The operational node from which the graph workflow will terminate.
+//   - outcome: The outcome of the invocations terminating through the edge.
+//   - labels: Optional maps of string key-value pairs for edge metadata/annotations.
+//
+// Returns:
+//   - A new EndEdge instance labelled with the outcome.
+//
+// Example:
+//
+//	runtime.AddEdge(
+//	    builders.CreateOutcomeEdge(review, "approved", map[string]string{"approved": "true"}),
+//	    builders.CreateOutcomeEdge(review, "rejected", map[string]string{"approved": "false"}),
+//	)
+func CreateOutcomeEdge[T g.SharedState](from g.Node[T], outcome string, labels ...map[string]string) g.Edge[T] {
+	return CreateEndEdge(from, append(labels, map[string]string{g.OutcomeEdgeLabel: outcome})...)
+}
+
+// CreateEndEdgeWithFn creates a new edge to the implicit end node running a finalizer.
+//
+// The end node runs endFn when the graph workflow terminates through this edge, letting
+// each exit post-process the final state or emit its artifacts.
+//
+// Type Parameters:
+//   - T: The SharedState type that will be passed through the graph execution.
+//
+// Parameters:
+//   - from: The operational node from which the graph workflow will terminate.
+//   - endFn: The finalizer run by the end node; nil keeps the final state unchanged.
+//   - labels: Optional maps of string key-value pairs for edge metadata/annotations.
+//
+// Returns:
+//   - A new EndEdge instance connecting the specified node to the implicit end node.
+//
+// Example:
+//
+//	endEdge := CreateEndEdgeWithFn(lastNode, func(ctx context.Context, userInput, finalState MyState) (MyState, error) {
+//	    finalState.Report = render(finalState)
+//	    return finalState, nil
+//	})
+func CreateEndEdgeWithFn[T g.SharedState](from g.Node[T], endFn g.EndFn[T], labels ...map[string]string) g.Edge[T] {
+	endNode, _ := createEndNode(endFn)
 	return i.EdgeImplFactory(from, endNode, g.EndEdge, labels...)
 }
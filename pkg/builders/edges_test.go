@@ -0,0 +1,226 @@
+package builders_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func runBoundaryGraph(t *testing.T, startFn g.StartFn[TestState], endFn g.EndFn[TestState], userInput TestState) g.StateMonitorEntry[TestState] {
+	node, _ := builders.NewNode("Upper", func(userInput, currentState TestState, notify g.NotifyPartialFn[TestState]) (TestState, error) {
+		currentState.Value = strings.ToUpper(currentState.Value)
+		currentState.Counter++
+		return currentState, nil
+	})
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[TestState], 10)
+	runtime, err := builders.CreateRuntime(builders.CreateStartEdgeWithFn(node, startFn), stateMonitorCh)
+	if err != nil {
+		t.Fatalf("CreateRuntime() failed: %v", err)
+	}
+	defer runtime.Shutdown()
+	runtime.AddEdge(builders.CreateEndEdgeWithFn(node, endFn))
+
+	runtime.Invoke(userInput)
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case entry := <-stateMonitorCh:
+			if !entry.Running {
+				return entry
+			}
+		case <-timeout:
+			t.Fatal("Timeout waiting for the graph completion")
+		}
+	}
+}
+
+// TestCreateEdgesWithFn tests that the start and end functions run at the boundaries of the invocation
+func TestCreateEdgesWithFn(t *testing.T) {
+	startFn := func(ctx context.Context, userInput, currentState TestState) (TestState, error) {
+		if strings.TrimSpace(userInput.Value) == "" {
+			return currentState, errors.New("empty input")
+		}
+		currentState.Value = strings.TrimSpace(userInput.Value)
+		return currentState, nil
+	}
+	endFn := func(ctx context.Context, userInput, finalState TestState) (TestState, error) {
+		finalState.Value = "<" + finalState.Value + ">"
+		finalState.Counter *= 10
+		return finalState, nil
+	}
+
+	entry := runBoundaryGraph(t, startFn, endFn, TestState{Value: "  hello "})
+	if entry.Error != nil {
+		t.Fatalf("Unexpected error: %v", entry.Error)
+	}
+	if entry.NewState.Value != "<HELLO>" || entry.NewState.Counter != 10 {
+		t.Errorf("Unexpected final state %+v", entry.NewState)
+	}
+
+	entry = runBoundaryGraph(t, startFn, endFn, TestState{Value: " "})
+	if entry.Error == nil || !strings.Contains(entry.Error.Error(), "empty input") {
+		t.Errorf("Expected the start function to reject the input, got %v", entry.Error)
+	}
+	if entry.Node != builders.ReservedNodeNameStart {
+		t.Errorf("Expected the failure on the start node, got %s", entry.Node)
+	}
+}
+
+// TestCreateEdgesWithFn_Nil tests that nil boundary functions keep the state unchanged
+func TestCreateEdgesWithFn_Nil(t *testing.T) {
+	entry := runBoundaryGraph(t, nil, nil, TestState{Value: "ignored"})
+	if entry.Error != nil {
+		t.Fatalf("Unexpected error: %v", entry.Error)
+	}
+	if entry.NewState.Value != "" || entry.NewState.Counter != 1 {
+		t.Errorf("Unexpected final state %+v", entry.NewState)
+	}
+}
+
+// TestCreateEntryEdge tests that the invocations enter the graph through the requested entry point
+func TestCreateEntryEdge(t *testing.T) {
+	mark := func(value string) g.NodeFn[TestState] {
+		return func(userInput, currentState TestState, notify g.NotifyPartialFn[TestState]) (TestState, error) {
+			currentState.Value += value
+			return currentState, nil
+		}
+	}
+	chat, _ := builders.NewNode("Chat", mark("chat"))
+	parse, _ := builders.NewNode("Parse", mark("parse:"))
+	reply, _ := builders.NewNode("Reply", mark("reply"))
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[TestState], 10)
+	runtime, err := builders.CreateRuntime(builders.CreateStartEdge(chat), stateMonitorCh)
+	if err != nil {
+		t.Fatalf("CreateRuntime() failed: %v", err)
+	}
+	defer runtime.Shutdown()
+	runtime.AddEdge(
+		builders.CreateEdge(chat, reply),
+		builders.CreateEntryEdge("webhook", parse),
+		builders.CreateEntryEdgeWithFn("cron", reply, func(ctx context.Context, userInput, currentState TestState) (TestState, error) {
+			currentState.Value = "cron:"
+			return currentState, nil
+		}),
+		builders.CreateEdge(parse, reply),
+		builders.CreateEndEdge(reply),
+	)
+	if err := runtime.Validate(); err != nil {
+		t.Fatalf("Validate() failed: %v", err)
+	}
+
+	wait := func() g.StateMonitorEntry[TestState] {
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case entry := <-stateMonitorCh:
+				if !entry.Running {
+					return entry
+				}
+			case <-timeout:
+				t.Fatal("Timeout waiting for the graph completion")
+			}
+		}
+	}
+
+	for entryPoint, expected := range map[string]string{"": "chatreply", "webhook": "parse:reply", "cron": "cron:reply"} {
+		runtime.Invoke(TestState{}, g.InvokeConfigEntryPoint(entryPoint))
+		if entry := wait(); entry.Error != nil || entry.NewState.Value != expected {
+			t.Errorf("Expected %q through entry point %q, got %+v", expected, entryPoint, entry)
+		}
+	}
+
+	runtime.Invoke(TestState{}, g.InvokeConfigEntryPoint("email"))
+	if entry := wait(); !errors.Is(entry.Error, g.ErrUnknownEntryPoint) {
+		t.Errorf("Expected ErrUnknownEntryPoint, got %+v", entry)
+	}
+}
+
+// TestCreateEntryEdge_Validate tests that every entry point is validated independently
+func TestCreateEntryEdge_Validate(t *testing.T) {
+	chat, _ := builders.NewNode[TestState]("Chat", nil)
+	loop, _ := builders.NewNode[TestState]("Loop", nil)
+	other, _ := builders.NewNode[TestState]("Other", nil)
+
+	runtime, _ := builders.CreateRuntime(builders.CreateStartEdge(chat), make(chan g.StateMonitorEntry[TestState], 10), g.WithAllowCycles[TestState](10))
+	defer runtime.Shutdown()
+	runtime.AddEdge(
+		builders.CreateEndEdge(chat),
+		builders.CreateEntryEdge("cron", loop),
+		builders.CreateEdge(loop, loop),
+		builders.CreateEntryEdge("cron", other),
+		builders.CreateEndEdge(other),
+	)
+
+	err := runtime.Validate()
+	if !errors.Is(err, g.ErrNoPathToEnd) || !strings.Contains(err.Error(), "entry point cron") {
+		t.Errorf("Expected the entry point without path to end to be reported, got %v", err)
+	}
+	if !errors.Is(err, g.ErrDuplicateEntryPoint) {
+		t.Errorf("Expected ErrDuplicateEntryPoint, got %v", err)
+	}
+	if errors.Is(err, g.ErrUnreachableNode) || errors.Is(err, g.ErrMultipleStartEdges) {
+		t.Errorf("Expected the nodes of the entry points to be reachable, got %v", err)
+	}
+}
+
+// TestCreateOutcomeEdge tests that the final entry carries the outcome of the end edge the invocation completed through
+func TestCreateOutcomeEdge(t *testing.T) {
+	policy, _ := builders.CreateConditionalRoutePolicy(func(userInput, currentState TestState, edges []g.Edge[TestState]) g.Edge[TestState] {
+		for _, edge := range edges {
+			if outcome, _ := g.OutcomeOf(edge); (outcome == "approved") == (userInput.Counter > 10) {
+				return edge
+			}
+		}
+		return nil
+	})
+	review, _ := builders.NewNode[TestState]("Review", nil, g.WithRoutingPolicy(policy))
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[TestState], 10)
+	runtime, _ := builders.CreateRuntime(builders.CreateStartEdge(review), stateMonitorCh)
+	defer runtime.Shutdown()
+	runtime.AddEdge(
+		builders.CreateOutcomeEdge(review, "approved"),
+		builders.CreateOutcomeEdge(review, "rejected", map[string]string{"reason": "too low"}),
+	)
+	if err := runtime.Validate(); err != nil {
+		t.Fatalf("Validate() failed: %v", err)
+	}
+
+	for counter, expected := range map[int]string{42: "approved", 1: "rejected"} {
+		runtime.Invoke(TestState{Counter: counter})
+
+		timeout := time.After(2 * time.Second)
+		var final g.StateMonitorEntry[TestState]
+		for final.Node == "" {
+			select {
+			case entry := <-stateMonitorCh:
+				if !entry.Running {
+					final = entry
+				} else if entry.Outcome != "" {
+					t.Errorf("Expected no outcome on the running entries, got %+v", entry)
+				}
+			case <-timeout:
+				t.Fatal("Timeout waiting for the graph completion")
+			}
+		}
+		if final.Error != nil || final.Outcome != expected {
+			t.Errorf("Expected the outcome %s, got %+v", expected, final)
+		}
+	}
+
+	edge := builders.CreateOutcomeEdge(review, "rejected", map[string]string{"reason": "too low"})
+	if reason, _ := edge.LabelByKey("reason"); reason != "too low" {
+		t.Errorf("Expected the labels to be kept, got %q", reason)
+	}
+	if _, ok := g.OutcomeOf(builders.CreateEndEdge(review)); ok {
+		t.Error("Expected no outcome on a plain end edge")
+	}
+}
@@ -0,0 +1,371 @@
+package builders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+const (
+	// DefaultMapConcurrency is the default number of work items processed concurrently by a map node.
+	DefaultMapConcurrency = 4
+)
+
+var (
+	// ErrSplitFnNil indicates that the map node has no split function.
+	ErrSplitFnNil = errors.New("split function cannot be nil")
+	// ErrMapWorkerNil indicates that the map node has no worker.
+	ErrMapWorkerNil = errors.New("map worker cannot be nil")
+	// ErrMergeFnNil indicates that the map node has no merge function.
+	ErrMergeFnNil = errors.New("merge function cannot be nil")
+	// ErrInvalidMapConcurrency indicates that the concurrency of a map node is not positive.
+	ErrInvalidMapConcurrency = errors.New("map concurrency must be positive")
+	// ErrMapWorkerRuntimeNil indicates that a graph map worker has no runtime.
+	ErrMapWorkerRuntimeNil = errors.New("map worker runtime cannot be nil")
+	// ErrMapWorkerMonitorNil indicates that a graph map worker has no state monitor channel.
+	ErrMapWorkerMonitorNil = errors.New("map worker state monitor channel cannot be nil")
+	// ErrMapWorkerStopped indicates that a graph map worker is stopped.
+	ErrMapWorkerStopped = errors.New("map worker is stopped")
+)
+
+// SplitFn splits the state into the work items of a map node.
+type SplitFn[T g.SharedState, I any] func(userInput, currentState T) ([]I, error)
+
+// MergeFn merges the results of a map node, in the order of the work items, into a state change.
+//
+// The state change is reduced into the state by the reducer of the node.
+type MergeFn[T g.SharedState, R any] func(currentState T, results []R) (T, error)
+
+// MapWorker processes a single work item of a map node.
+type MapWorker[I any, R any] interface {
+	// Process processes the work item.
+	//
+	// Parameters:
+	//   - ctx: The context of the invocation, cancelled when another work item fails.
+	//   - item: The work item.
+	//
+	// Returns:
+	//   - The result of the work item.
+	//   - An error if the work item could not be processed.
+	Process(ctx context.Context, item I) (R, error)
+}
+
+// MapWorkerFunc is a function type that implements the MapWorker interface.
+type MapWorkerFunc[I any, R any] func(ctx context.Context, item I) (R, error)
+
+// Process calls the function.
+func (f MapWorkerFunc[I, R]) Process(ctx context.Context, item I) (R, error) { return f(ctx, item) }
+
+// MapOptions holds the configuration of a map node.
+type MapOptions[T g.SharedState] struct {
+	// Concurrency is the maximum number of work items processed at the same time.
+	Concurrency int
+	// NodeOptions are the options of the underlying node.
+	NodeOptions []g.NodeOption[T]
+}
+
+// MapOption is a functional option for configuring a map node.
+type MapOption[T g.SharedState] interface {
+	// Apply applies the option to the MapOptions.
+	//
+	// Parameters:
+	//   - m: A pointer to MapOptions to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(m *MapOptions[T]) error
+}
+
+// MapOptionFunc is a function type that implements the MapOption interface.
+type MapOptionFunc[T g.SharedState] func(*MapOptions[T]) error
+
+// Apply applies the MapOptionFunc to the given MapOptions.
+//
+// Parameters:
+//   - m: A pointer to MapOptions to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s MapOptionFunc[T]) Apply(m *MapOptions[T]) error { return s(m) }
+
+// WithMapConcurrency sets the maximum number of work items processed at the same time.
+//
+// Parameters:
+//   - concurrency: The size of the worker pool, it must be positive.
+//
+// Returns:
+//   - A MapOption that sets the concurrency.
+//
+// Example:
+//
+//	node, err := builders.NewMapNode("Summarize", split, worker, merge, builders.WithMapConcurrency[MyState](8))
+func WithMapConcurrency[T g.SharedState](concurrency int) MapOption[T] {
+	return MapOptionFunc[T](func(m *MapOptions[T]) error {
+		if concurrency < 1 {
+			return ErrInvalidMapConcurrency
+		}
+		m.Concurrency = concurrency
+		return nil
+	})
+}
+
+// WithMapNodeOptions sets the options of the underlying node, like its routing policy or reducer.
+//
+// Parameters:
+//   - opts: The node options.
+//
+// Returns:
+//   - A MapOption that sets the node options.
+//
+// Example:
+//
+//	node, err := builders.NewMapNode("Summarize", split, worker, merge,
+//	    builders.WithMapNodeOptions(graph.WithReducer(myReducer)))
+func WithMapNodeOptions[T g.SharedState](opts ...g.NodeOption[T]) MapOption[T] {
+	return MapOptionFunc[T](func(m *MapOptions[T]) error {
+		m.NodeOptions = append(m.NodeOptions, opts...)
+		return nil
+	})
+}
+
+// NewMapNode creates a node splitting the state into work items, processing them concurrently and merging the results back into the state.
+//
+// The work items are processed by a bounded worker pool; the first failing item cancels the others
+// and fails the node. The results are given to the merge function in the order of the work items.
+//
+// Parameters:
+//   - name: The unique name for the node.
+//   - split: The function splitting the state into work items.
+//   - worker: The worker processing each item, a MapWorkerFunc or a graph through NewGraphMapWorker.
+//   - merge: The function merging the results into a state change.
+//   - opts: Optional configuration options for the map node.
+//
+// Returns:
+//   - The map node.
+//   - An error if the node could not be created.
+//
+// Example:
+//
+//	split := func(userInput, currentState MyState) ([]string, error) { return currentState.Documents, nil }
+//	worker := builders.MapWorkerFunc[string, string](func(ctx context.Context, doc string) (string, error) {
+//	    return summarize(ctx, doc)
+//	})
+//	merge := func(currentState MyState, summaries []string) (MyState, error) {
+//	    currentState.Summaries = summaries
+//	    return currentState, nil
+//	}
+//	node, err := builders.NewMapNode("Summarize", split, worker, merge)
+func NewMapNode[T g.SharedState, I any, R any](name string, split SplitFn[T, I], worker MapWorker[I, R], merge MergeFn[T, R], opts ...MapOption[T]) (g.Node[T], error) {
+	if split == nil {
+		return nil, fmt.Errorf("map node creation error for name %s: %w", name, ErrSplitFnNil)
+	}
+	if worker == nil {
+		return nil, fmt.Errorf("map node creation error for name %s: %w", name, ErrMapWorkerNil)
+	}
+	if merge == nil {
+		return nil, fmt.Errorf("map node creation error for name %s: %w", name, ErrMergeFnNil)
+	}
+
+	useOpts := &MapOptions[T]{Concurrency: DefaultMapConcurrency}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("map node creation error for name %s: %w", name, err)
+		}
+	}
+
+	return NewContextNode(name, mapFn(split, worker, merge, useOpts.Concurrency), useOpts.NodeOptions...)
+}
+
+func mapFn[T g.SharedState, I any, R any](split SplitFn[T, I], worker MapWorker[I, R], merge MergeFn[T, R], concurrency int) g.ContextNodeFn[T] {
+	return func(ctx context.Context, userInput, currentState T, notify g.NotifyPartialFn[T]) (T, error) {
+		items, err := split(userInput, currentState)
+		if err != nil {
+			return currentState, fmt.Errorf("cannot split the state: %w", err)
+		}
+
+		results, err := processItems(ctx, items, worker, concurrency)
+		if err != nil {
+			return currentState, err
+		}
+
+		rv, err := merge(currentState, results)
+		if err != nil {
+			return currentState, fmt.Errorf("cannot merge the results: %w", err)
+		}
+		return rv, nil
+	}
+}
+
+func processItems[I any, R any](ctx context.Context, items []I, worker MapWorker[I, R], concurrency int) ([]R, error) {
+	results := make([]R, len(items))
+	if len(items) == 0 {
+		return results, nil
+	}
+
+	useCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	slots := make(chan struct{}, concurrency)
+
+	for idx, item := range items {
+		select {
+		case slots <- struct{}{}:
+		case <-useCtx.Done():
+		}
+		if useCtx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			result, err := worker.Process(useCtx, item)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("cannot process work item %d: %w", idx, err)
+					cancel(firstErr)
+				})
+				return
+			}
+			results[idx] = result
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := context.Cause(useCtx); err != nil {
+		return nil, fmt.Errorf("map interrupted: %w", err)
+	}
+	return results, nil
+}
+
+// GraphMapWorker is a MapWorker processing each work item with an invocation of a graph runtime.
+//
+// Each work item is the user input of a new thread, the result is the final state of the thread.
+// The worker consumes the state monitor channel of the runtime, which must not be shared.
+type GraphMapWorker[S g.SharedState] struct {
+	runtime g.Runtime[S]
+
+	mu      sync.Mutex
+	waiting map[string]chan g.StateMonitorEntry[S]
+	stopped chan struct{}
+}
+
+// NewGraphMapWorker creates a MapWorker invoking a graph runtime for each work item.
+//
+// Parameters:
+//   - runtime: The runtime of the worker graph.
+//   - stateMonitorCh: The state monitor channel given to the runtime.
+//
+// Returns:
+//   - The running GraphMapWorker, to be stopped when no longer used.
+//   - An error if the runtime or the channel is nil.
+//
+// Example:
+//
+//	stateMonitorCh := make(chan graph.StateMonitorEntry[DocState], 100)
+//	workerRuntime, _ := builders.CreateRuntime(workerStartEdge, stateMonitorCh)
+//	worker, _ := builders.NewGraphMapWorker(workerRuntime, stateMonitorCh)
+//	defer worker.Stop()
+//	node, err := builders.NewMapNode("Summarize", split, worker, merge)
+func NewGraphMapWorker[S g.SharedState](runtime g.Runtime[S], stateMonitorCh <-chan g.StateMonitorEntry[S]) (*GraphMapWorker[S], error) {
+	if runtime == nil {
+		return nil, fmt.Errorf("graph map worker creation error: %w", ErrMapWorkerRuntimeNil)
+	}
+	if stateMonitorCh == nil {
+		return nil, fmt.Errorf("graph map worker creation error: %w", ErrMapWorkerMonitorNil)
+	}
+
+	rv := &GraphMapWorker[S]{
+		runtime: runtime,
+		waiting: make(map[string]chan g.StateMonitorEntry[S]),
+		stopped: make(chan struct{}),
+	}
+	go rv.dispatch(stateMonitorCh)
+	return rv, nil
+}
+
+// Process invokes the runtime with the work item and waits for the final state of the thread.
+func (w *GraphMapWorker[S]) Process(ctx context.Context, item S) (S, error) {
+	threadID := w.runtime.NewThreadID()
+	done := make(chan g.StateMonitorEntry[S], 1)
+
+	w.mu.Lock()
+	select {
+	case <-w.stopped:
+		w.mu.Unlock()
+		return item, ErrMapWorkerStopped
+	default:
+	}
+	w.waiting[threadID] = done
+	w.mu.Unlock()
+
+	defer func() {
+		w.mu.Lock()
+		delete(w.waiting, threadID)
+		w.mu.Unlock()
+	}()
+
+	w.runtime.Invoke(item, g.InvokeConfigThreadID(threadID), g.InvokeConfigContext(ctx))
+
+	select {
+	case entry := <-done:
+		if entry.Error != nil {
+			return item, entry.Error
+		}
+		return entry.NewState, nil
+	case <-w.stopped:
+		return item, ErrMapWorkerStopped
+	case <-ctx.Done():
+		_ = w.runtime.Cancel(threadID)
+		return item, context.Cause(ctx)
+	}
+}
+
+// Stop stops the consumption of the state monitor channel, pending work items fail.
+func (w *GraphMapWorker[S]) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	select {
+	case <-w.stopped:
+	default:
+		close(w.stopped)
+	}
+}
+
+func (w *GraphMapWorker[S]) dispatch(stateMonitorCh <-chan g.StateMonitorEntry[S]) {
+	for {
+		select {
+		case <-w.stopped:
+			return
+		case entry, ok := <-stateMonitorCh:
+			if !ok {
+				w.Stop()
+				return
+			}
+			if entry.Running {
+				continue
+			}
+			w.mu.Lock()
+			if done, ok := w.waiting[entry.ThreadID]; ok {
+				select {
+				case done <- entry:
+				default:
+				}
+			}
+			w.mu.Unlock()
+		}
+	}
+}
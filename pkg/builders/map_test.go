@@ -0,0 +1,181 @@
+package builders_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func runNode[T g.SharedState](t *testing.T, node g.Node[T], userInput T) g.StateMonitorEntry[T] {
+	stateMonitorCh := make(chan g.StateMonitorEntry[T], 10)
+	runtime, err := builders.CreateRuntime(builders.CreateStartEdge(node), stateMonitorCh)
+	if err != nil {
+		t.Fatalf("CreateRuntime() failed: %v", err)
+	}
+	runtime.AddEdge(builders.CreateEndEdge(node))
+	defer runtime.Shutdown()
+
+	runtime.Invoke(userInput)
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case entry := <-stateMonitorCh:
+			if !entry.Running {
+				return entry
+			}
+		case <-timeout:
+			t.Fatal("Timeout waiting for the graph completion")
+		}
+	}
+}
+
+type mapTestState struct {
+	Items   []string
+	Results []string
+}
+
+func splitItems(userInput, currentState mapTestState) ([]string, error) {
+	return userInput.Items, nil
+}
+
+func mergeResults(currentState mapTestState, results []string) (mapTestState, error) {
+	currentState.Results = results
+	return currentState, nil
+}
+
+// TestNewMapNode_ProcessesItemsConcurrently tests that the items are processed by a bounded pool and merged in order
+func TestNewMapNode_ProcessesItemsConcurrently(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	worker := builders.MapWorkerFunc[string, string](func(ctx context.Context, item string) (string, error) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for {
+			seen := maxInFlight.Load()
+			if current <= seen || maxInFlight.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		return strings.ToUpper(item), nil
+	})
+
+	node, err := builders.NewMapNode("Map", splitItems, worker, mergeResults, builders.WithMapConcurrency[mapTestState](2))
+	if err != nil {
+		t.Fatalf("NewMapNode() failed: %v", err)
+	}
+
+	items := []string{"a", "b", "c", "d", "e", "f"}
+	entry := runNode(t, node, mapTestState{Items: items})
+	if entry.Error != nil {
+		t.Fatalf("Unexpected error: %v", entry.Error)
+	}
+	if fmt.Sprint(entry.NewState.Results) != "[A B C D E F]" {
+		t.Errorf("Expected the results in the order of the items, got %v", entry.NewState.Results)
+	}
+	if maxInFlight.Load() > 2 {
+		t.Errorf("Expected at most 2 items in flight, got %d", maxInFlight.Load())
+	}
+}
+
+// TestNewMapNode_FailFast tests that a failing item cancels the others and fails the node
+func TestNewMapNode_FailFast(t *testing.T) {
+	errBoom := errors.New("boom")
+	var cancelled atomic.Int32
+	worker := builders.MapWorkerFunc[string, string](func(ctx context.Context, item string) (string, error) {
+		if item == "bad" {
+			return "", errBoom
+		}
+		select {
+		case <-ctx.Done():
+			cancelled.Add(1)
+			return "", ctx.Err()
+		case <-time.After(time.Second):
+			return item, nil
+		}
+	})
+
+	node, err := builders.NewMapNode("Map", splitItems, worker, mergeResults)
+	if err != nil {
+		t.Fatalf("NewMapNode() failed: %v", err)
+	}
+
+	entry := runNode(t, node, mapTestState{Items: []string{"slow", "bad", "slow"}})
+	if !errors.Is(entry.Error, errBoom) {
+		t.Fatalf("Expected the item error, got %v", entry.Error)
+	}
+	if cancelled.Load() != 2 {
+		t.Errorf("Expected the other items to be cancelled, got %d", cancelled.Load())
+	}
+}
+
+// TestNewMapNode_GraphWorker tests that the items can be processed by a worker graph
+func TestNewMapNode_GraphWorker(t *testing.T) {
+	double, _ := builders.NewNode("Double", func(userInput, currentState TestState, notify g.NotifyPartialFn[TestState]) (TestState, error) {
+		return TestState{Value: userInput.Value, Counter: userInput.Counter * 2}, nil
+	})
+	workerMonitorCh := make(chan g.StateMonitorEntry[TestState], 10)
+	workerRuntime, err := builders.CreateRuntime(builders.CreateStartEdge(double), workerMonitorCh)
+	if err != nil {
+		t.Fatalf("CreateRuntime() failed: %v", err)
+	}
+	workerRuntime.AddEdge(builders.CreateEndEdge(double))
+	defer workerRuntime.Shutdown()
+
+	worker, err := builders.NewGraphMapWorker(workerRuntime, workerMonitorCh)
+	if err != nil {
+		t.Fatalf("NewGraphMapWorker() failed: %v", err)
+	}
+	defer worker.Stop()
+
+	split := func(userInput, currentState TestState) ([]TestState, error) {
+		return []TestState{{Counter: 1}, {Counter: 2}, {Counter: 3}}, nil
+	}
+	merge := func(currentState TestState, results []TestState) (TestState, error) {
+		for _, result := range results {
+			currentState.Counter += result.Counter
+		}
+		return currentState, nil
+	}
+
+	node, err := builders.NewMapNode("Map", split, builders.MapWorker[TestState, TestState](worker), merge)
+	if err != nil {
+		t.Fatalf("NewMapNode() failed: %v", err)
+	}
+
+	entry := runNode(t, node, TestState{})
+	if entry.Error != nil {
+		t.Fatalf("Unexpected error: %v", entry.Error)
+	}
+	if entry.NewState.Counter != 12 {
+		t.Errorf("Expected the doubled items to be summed, got %d", entry.NewState.Counter)
+	}
+}
+
+// TestNewMapNode_Validation tests the creation errors of a map node
+func TestNewMapNode_Validation(t *testing.T) {
+	worker := builders.MapWorkerFunc[string, string](func(ctx context.Context, item string) (string, error) { return item, nil })
+
+	if _, err := builders.NewMapNode[mapTestState, string, string]("Map", nil, worker, mergeResults); !errors.Is(err, builders.ErrSplitFnNil) {
+		t.Errorf("Expected ErrSplitFnNil, got %v", err)
+	}
+	if _, err := builders.NewMapNode[mapTestState, string, string]("Map", splitItems, nil, mergeResults); !errors.Is(err, builders.ErrMapWorkerNil) {
+		t.Errorf("Expected ErrMapWorkerNil, got %v", err)
+	}
+	if _, err := builders.NewMapNode[mapTestState, string, string]("Map", splitItems, worker, nil); !errors.Is(err, builders.ErrMergeFnNil) {
+		t.Errorf("Expected ErrMergeFnNil, got %v", err)
+	}
+	if _, err := builders.NewMapNode("Map", splitItems, worker, mergeResults, builders.WithMapConcurrency[mapTestState](0)); !errors.Is(err, builders.ErrInvalidMapConcurrency) {
+		t.Errorf("Expected ErrInvalidMapConcurrency, got %v", err)
+	}
+	if _, err := builders.NewGraphMapWorker[TestState](nil, make(chan g.StateMonitorEntry[TestState])); !errors.Is(err, builders.ErrMapWorkerRuntimeNil) {
+		t.Errorf("Expected ErrMapWorkerRuntimeNil, got %v", err)
+	}
+}
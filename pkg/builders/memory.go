@@ -21,3 +21,76 @@ func NewMemMemory[T g.SharedState](opts ...g.MemoryOption) g.Memory[T] {
 	}
 	return i.MemMemoryFactory[T](useOpts)
 }
+
+// NewChannelDeadLetterQueue creates a bounded in-process dead-letter queue.
+//
+// Parameters:
+//   - size: The maximum number of entries held by the queue.
+//
+// Returns:
+//   - g.DeadLetterQueue[T]: The dead-letter queue.
+//   - error: g.ErrInvalidDeadLetterQueueSize if size is not positive.
+//
+// Example:
+//
+//	dlq, err := builders.NewChannelDeadLetterQueue[MyState](100)
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, g.WithMemory(memory), g.WithDeadLetterQueue(dlq))
+func NewChannelDeadLetterQueue[T g.SharedState](size int) (g.DeadLetterQueue[T], error) {
+	return i.ChannelDeadLetterQueueFactory[T](size)
+}
+
+// NewMemoryDeadLetterQueue creates a dead-letter queue storing the failed states into a fallback Memory.
+//
+// The states are persisted with keys derived from the thread ID and the persistence sequence,
+// and deleted once popped for reprocessing, or overwritten with the zero value when the memory
+// is not a g.StateDeleter. The retry metadata is indexed in the scratchpad of the memory when it
+// is a g.ScratchStore, so that the queue created on the same memory after a restart resumes the
+// entries; it is kept in process otherwise.
+//
+// Parameters:
+//   - memory: The fallback memory, usually distinct from the one failing.
+//   - size: The maximum number of entries held by the queue.
+//
+// Returns:
+//   - g.DeadLetterQueue[T]: The dead-letter queue.
+//   - error: g.ErrDeadLetterMemoryNil or g.ErrInvalidDeadLetterQueueSize on invalid arguments.
+func NewMemoryDeadLetterQueue[T g.SharedState](memory g.Memory[T], size int) (g.DeadLetterQueue[T], error) {
+	return i.MemoryDeadLetterQueueFactory(memory, size)
+}
+
+// NewMemEventStore creates an in-memory event store.
+//
+// Returns:
+//   - g.EventStore[T]: The in-memory event store.
+//
+// Example:
+//
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, g.WithEventStore(builders.NewMemEventStore[MyState]()))
+func NewMemEventStore[T g.SharedState]() g.EventStore[T] {
+	return i.MemEventStoreFactory[T]()
+}
+
+// NewMemLeaseStore creates a lease store shared by the runtimes of the same process.
+//
+// Returns:
+//   - g.LeaseStore: In-memory LeaseStore implementation.
+//
+// Example:
+//
+//	leases := builders.NewMemLeaseStore()
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, g.WithMemory(memory), g.WithThreadLeases[MyState](leases, 30*time.Second))
+func NewMemLeaseStore() g.LeaseStore {
+	return i.MemLeaseStoreFactory()
+}
+
+// NewMemJournal creates an in-memory journal, lost with the process: use it for tests.
+//
+// Returns:
+//   - g.Journal[T]: The in-memory journal.
+//
+// Example:
+//
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, g.WithJournal(builders.NewMemJournal[MyState]()))
+func NewMemJournal[T g.SharedState]() g.Journal[T] {
+	return i.MemJournalFactory[T]()
+}
@@ -1,7 +1,9 @@
 package builders
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	i "github.com/morphy76/ggraph/internal/graph"
@@ -33,9 +35,47 @@ const (
 //	    builders.WithRoutingPolicy(myRoutingPolicy),
 //	    builders.WithReducer(myReducerFunction))
 func NewNode[T g.SharedState](name string, fn g.NodeFn[T], opts ...g.NodeOption[T]) (g.Node[T], error) {
+	var useFn g.ContextNodeFn[T]
+	if fn != nil {
+		useFn = func(_ context.Context, userInput, currentState T, notify g.NotifyPartialFn[T]) (T, error) {
+			return fn(userInput, currentState, notify)
+		}
+	}
+	return NewContextNode(name, useFn, opts...)
+}
+
+// NewContextNode creates a new node whose processing function receives the invocation context.
+//
+// The context is the one provided with g.InvokeConfigContext, so that long-running
+// operations can be cancelled together with the invocation.
+//
+// Parameters:
+//   - name: The unique name for the node.
+//   - fn: The context-aware processing function (ContextNodeFn) for the node.
+//   - opts: Optional configuration options for the node.
+//
+// Returns:
+//   - The constructed Node[T] instance.
+//   - An error if the node could not be created.
+//
+// Example:
+//
+//	node, err := builders.NewContextNode("Fetch", func(ctx context.Context, userInput, currentState MyState, notify g.NotifyPartialFn[MyState]) (MyState, error) {
+//	    return fetch(ctx, currentState)
+//	})
+func NewContextNode[T g.SharedState](name string, fn g.ContextNodeFn[T], opts ...g.NodeOption[T]) (g.Node[T], error) {
+	name, useOpts, err := nodeOptions(name, opts)
+	if err != nil {
+		return nil, err
+	}
+	return i.ContextNodeImplFactory(g.IntermediateNode, name, fn, useOpts)
+}
+
+// nodeOptions checks the name of an intermediate node, generating one if empty, and applies its options over the defaults.
+func nodeOptions[T g.SharedState](name string, opts []g.NodeOption[T]) (string, *g.NodeOptions[T], error) {
 	// Check for reserved names first
 	if name == ReservedNodeNameStart || name == ReservedNodeNameEnd {
-		return nil, fmt.Errorf("node creation error for name %s: %w", name, g.ErrReservedNodeName)
+		return "", nil, fmt.Errorf("node creation error for name %s: %w", name, g.ErrReservedNodeName)
 	}
 
 	// Generate UUID for empty names
@@ -54,25 +94,171 @@ func NewNode[T g.SharedState](name string, fn g.NodeFn[T], opts ...g.NodeOption[
 		var err error
 		useOpts.RoutingPolicy, err = CreateAnyRoutePolicy[T]()
 		if err != nil {
-			return nil, err
+			return "", nil, err
 		}
 	}
+	return name, useOpts, nil
+}
+
+// NewPayloadNode creates a new node receiving the payload of the previous node as an argument and
+// sending its own to the next node, see g.SendPayload.
+//
+// Parameters:
+//   - name: The unique name for the node.
+//   - fn: The processing function, receiving the payload In and returning the payload Out.
+//   - opts: Optional configuration options for the node.
+//
+// Returns:
+//   - The constructed Node[T] instance.
+//   - An error if the node could not be created.
+//
+// Example:
+//
+//	rank, err := builders.NewPayloadNode("Rank", func(ctx context.Context, userInput, state MyState, hits []Hit, notify g.NotifyPartialFn[MyState]) (MyState, Hit, error) {
+//	    best := rankHits(hits)[0]
+//	    state.Answer = best.Title
+//	    return state, best, nil
+//	})
+func NewPayloadNode[T g.SharedState, In, Out any](name string, fn g.PayloadNodeFn[T, In, Out], opts ...g.NodeOption[T]) (g.Node[T], error) {
+	if fn == nil {
+		return nil, fmt.Errorf("node creation error for name %s: %w", name, g.ErrPayloadNodeFnNil)
+	}
+	return NewContextNode(name, func(ctx context.Context, userInput, currentState T, notify g.NotifyPartialFn[T]) (T, error) {
+		in, _, err := g.Payload[In](ctx)
+		if err != nil {
+			return currentState, err
+		}
+		newState, out, err := fn(ctx, userInput, currentState, in, notify)
+		if err != nil {
+			return newState, err
+		}
+		if err := g.SendPayload(ctx, out); err != nil {
+			return newState, err
+		}
+		return newState, nil
+	}, opts...)
+}
+
+// NewDelayNode creates a new node suspending the thread for a computed duration, then going on with the state unchanged.
+//
+// The suspended thread holds no goroutine: the runtime schedules its wake-up and, with a Journal,
+// journals the wake-up time so that a thread resumed after a crash wakes up on time, see g.Delaying.
+// Delay nodes enable polling loops and rate-limited workflows.
+//
+// Parameters:
+//   - name: The unique name for the node.
+//   - delayFn: The function computing the duration of the wait; zero or negative goes on at once.
+//   - opts: Optional configuration options for the node.
+//
+// Returns:
+//   - The constructed Node[T] instance.
+//   - An error if the node could not be created.
+//
+// Example:
+//
+//	wait, err := builders.NewDelayNode("Wait", func(ctx context.Context, userInput, state MyState) (time.Duration, error) {
+//	    return state.RetryAfter, nil
+//	})
+//	runtime.AddEdge(builders.CreateEdge(poll, wait), builders.CreateEdge(wait, poll))
+func NewDelayNode[T g.SharedState](name string, delayFn g.DelayFn[T], opts ...g.NodeOption[T]) (g.Node[T], error) {
+	name, useOpts, err := nodeOptions(name, opts)
+	if err != nil {
+		return nil, err
+	}
+	return i.DelayNodeImplFactory(name, delayFn, useOpts)
+}
 
-	return i.NodeImplFactory(g.IntermediateNode, name, fn, useOpts)
+// NewSignalNode creates a new node parking the thread until the signal is delivered with Runtime.Signal.
+//
+// The parked thread holds no goroutine. The payload of the signal is the update of the node,
+// reduced into the state with the reducer of the node, see g.WithReducer: the default reducer
+// replaces the state with the payload. A signal not arriving before the timeout fails the
+// invocation with an error wrapping g.ErrSignalTimeout.
+//
+// Parameters:
+//   - name: The unique name for the node.
+//   - signal: The name of the signal the node waits for.
+//   - timeout: How long the node waits for the signal, zero meaning forever.
+//   - opts: Optional configuration options for the node.
+//
+// Returns:
+//   - The constructed Node[T] instance.
+//   - An error if the node could not be created.
+//
+// Example:
+//
+//	awaitPayment, err := builders.NewSignalNode("AwaitPayment", "payment", 24*time.Hour,
+//	    g.WithReducer(func(currentState, payload MyState) MyState {
+//	        currentState.Paid = payload.Paid
+//	        return currentState
+//	    }))
+//	// Later, in the webhook handler:
+//	err = runtime.Signal(threadID, "payment", MyState{Paid: true})
+func NewSignalNode[T g.SharedState](name, signal string, timeout time.Duration, opts ...g.NodeOption[T]) (g.Node[T], error) {
+	name, useOpts, err := nodeOptions(name, opts)
+	if err != nil {
+		return nil, err
+	}
+	return i.SignalNodeImplFactory(name, signal, timeout, useOpts)
 }
 
-func createStartNode[T g.SharedState]() (g.Node[T], error) {
+// NewBatchNode creates a new node collecting the inputs of several invocations of the thread before processing them.
+//
+// The thread reaching the node holds it: the next Runtime.Invoke calls on the thread add their user input
+// to the batch. Once the node holds size inputs, or the window elapsed since the thread reached it,
+// batchFn processes them and the invocation goes on with its update, reduced into the state with the
+// reducer of the node. At least one of size and window must be positive.
+//
+// Parameters:
+//   - name: The unique name for the node.
+//   - batchFn: The function processing the collected inputs.
+//   - size: How many inputs the node collects, zero meaning unbounded.
+//   - window: How long the node collects inputs, zero meaning unbounded.
+//   - opts: Optional configuration options for the node.
+//
+// Returns:
+//   - The constructed Node[T] instance.
+//   - An error if the node could not be created.
+//
+// Example:
+//
+//	// Collect the messages the user sends within 5 seconds, then respond once.
+//	collect, err := builders.NewBatchNode("Collect", func(ctx context.Context, inputs []ChatState, state ChatState) (ChatState, error) {
+//	    for _, input := range inputs {
+//	        state.Messages = append(state.Messages, input.Messages...)
+//	    }
+//	    return state, nil
+//	}, 0, 5*time.Second)
+func NewBatchNode[T g.SharedState](name string, batchFn g.BatchFn[T], size int, window time.Duration, opts ...g.NodeOption[T]) (g.Node[T], error) {
+	name, useOpts, err := nodeOptions(name, opts)
+	if err != nil {
+		return nil, err
+	}
+	return i.BatchNodeImplFactory(name, batchFn, size, window, useOpts)
+}
+
+func createStartNode[T g.SharedState](startFn g.StartFn[T]) (g.Node[T], error) {
 	policy, _ := CreateAnyRoutePolicy[T]()
 	useOpts := &g.NodeOptions[T]{
 		RoutingPolicy: policy,
 		Reducer:       i.Replacer[T],
 	}
-	return i.NodeImplFactory(g.StartNode, ReservedNodeNameStart, nil, useOpts)
+	return i.ContextNodeImplFactory(g.StartNode, ReservedNodeNameStart, boundaryNodeFn(startFn), useOpts)
 }
 
-func createEndNode[T g.SharedState]() (g.Node[T], error) {
+func createEndNode[T g.SharedState](endFn g.EndFn[T]) (g.Node[T], error) {
 	useOpts := &g.NodeOptions[T]{
 		Reducer: i.Replacer[T],
 	}
-	return i.NodeImplFactory(g.EndNode, ReservedNodeNameEnd, nil, useOpts)
+	return i.ContextNodeImplFactory(g.EndNode, ReservedNodeNameEnd, boundaryNodeFn(endFn), useOpts)
+}
+
+// boundaryNodeFn adapts a StartFn or an EndFn to the function of the implicit node, nil keeps the state unchanged.
+func boundaryNodeFn[T g.SharedState, F ~func(ctx context.Context, userInput, currentState T) (T, error)](fn F) g.ContextNodeFn[T] {
+	if fn == nil {
+		return nil
+	}
+	return func(ctx context.Context, userInput, currentState T, _ g.NotifyPartialFn[T]) (T, error) {
+		return fn(ctx, userInput, currentState)
+	}
 }
@@ -1,8 +1,11 @@
 package builders_test
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/morphy76/ggraph/pkg/builders"
 	g "github.com/morphy76/ggraph/pkg/graph"
@@ -475,3 +478,291 @@ func TestNode_RouterPattern(t *testing.T) {
 		t.Error("RoutePolicy() did not return the expected policy")
 	}
 }
+
+type ctxKey struct{}
+
+// TestNewContextNode_ReceivesInvocationContext tests that the invocation context reaches the node function
+func TestNewContextNode_ReceivesInvocationContext(t *testing.T) {
+	node, err := builders.NewContextNode("CtxNode", func(ctx context.Context, userInput, currentState TestState, notify g.NotifyPartialFn[TestState]) (TestState, error) {
+		currentState.Value, _ = ctx.Value(ctxKey{}).(string)
+		return currentState, nil
+	})
+	if err != nil {
+		t.Fatalf("NewContextNode() failed: %v", err)
+	}
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[TestState], 10)
+	runtime, err := builders.CreateRuntime(builders.CreateStartEdge(node), stateMonitorCh)
+	if err != nil {
+		t.Fatalf("CreateRuntime() failed: %v", err)
+	}
+	runtime.AddEdge(builders.CreateEndEdge(node))
+	defer runtime.Shutdown()
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "from-context")
+	runtime.Invoke(TestState{}, g.InvokeConfigContext(ctx))
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case entry := <-stateMonitorCh:
+			if entry.Error != nil {
+				t.Fatalf("Unexpected error: %v", entry.Error)
+			}
+			if !entry.Running {
+				if entry.NewState.Value != "from-context" {
+					t.Errorf("Expected 'from-context', got '%s'", entry.NewState.Value)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("Timeout waiting for the graph completion")
+		}
+	}
+}
+
+func TestNewPayloadNode_DeliversAlongEdges(t *testing.T) {
+	produce, _ := builders.NewPayloadNode("Produce", func(ctx context.Context, userInput, currentState TestState, in struct{}, notify g.NotifyPartialFn[TestState]) (TestState, int, error) {
+		return currentState, 41, nil
+	})
+	increment, _ := builders.NewPayloadNode("Increment", func(ctx context.Context, userInput, currentState TestState, in int, notify g.NotifyPartialFn[TestState]) (TestState, int, error) {
+		currentState.Counter = in
+		return currentState, in + 1, nil
+	})
+	plain, _ := builders.NewContextNode("Plain", func(ctx context.Context, userInput, currentState TestState, notify g.NotifyPartialFn[TestState]) (TestState, error) {
+		in, ok, err := g.Payload[int](ctx)
+		if err != nil || !ok || in != 42 {
+			return currentState, fmt.Errorf("expected the payload 42, got %d, %v, %v", in, ok, err)
+		}
+		return currentState, nil
+	})
+	last, _ := builders.NewPayloadNode("Last", func(ctx context.Context, userInput, currentState TestState, in int, notify g.NotifyPartialFn[TestState]) (TestState, struct{}, error) {
+		currentState.Value = fmt.Sprintf("received %d", in)
+		return currentState, struct{}{}, nil
+	})
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[TestState], 10)
+	runtime, err := builders.CreateRuntime(builders.CreateStartEdge(produce), stateMonitorCh)
+	if err != nil {
+		t.Fatalf("CreateRuntime() failed: %v", err)
+	}
+	runtime.AddEdge(
+		builders.CreateEdge(produce, increment),
+		builders.CreateEdge(increment, plain),
+		builders.CreateEdge(plain, last),
+		builders.CreateEndEdge(last),
+	)
+	defer runtime.Shutdown()
+
+	runtime.Invoke(TestState{})
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case entry := <-stateMonitorCh:
+			if entry.Error != nil {
+				t.Fatalf("Unexpected error: %v", entry.Error)
+			}
+			if !entry.Running {
+				if entry.NewState.Counter != 41 || entry.NewState.Value != "received 0" {
+					t.Errorf("Expected the payloads to reach the next node only, got %+v", entry.NewState)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("Timeout waiting for the graph completion")
+		}
+	}
+}
+
+func TestNewPayloadNode_Errors(t *testing.T) {
+	if _, err := builders.NewPayloadNode[TestState, int, int]("Nil", nil); !errors.Is(err, g.ErrPayloadNodeFnNil) {
+		t.Errorf("Expected ErrPayloadNodeFnNil, got %v", err)
+	}
+	if err := g.SendPayload(context.Background(), 1); !errors.Is(err, g.ErrPayloadCarrierNotSet) {
+		t.Errorf("Expected ErrPayloadCarrierNotSet, got %v", err)
+	}
+
+	produce, _ := builders.NewPayloadNode("Produce", func(ctx context.Context, userInput, currentState TestState, in struct{}, notify g.NotifyPartialFn[TestState]) (TestState, string, error) {
+		return currentState, "not a number", nil
+	})
+	consume, _ := builders.NewPayloadNode("Consume", func(ctx context.Context, userInput, currentState TestState, in int, notify g.NotifyPartialFn[TestState]) (TestState, int, error) {
+		return currentState, in, nil
+	})
+	stateMonitorCh := make(chan g.StateMonitorEntry[TestState], 10)
+	runtime, _ := builders.CreateRuntime(builders.CreateStartEdge(produce), stateMonitorCh)
+	runtime.AddEdge(builders.CreateEdge(produce, consume), builders.CreateEndEdge(consume))
+	defer runtime.Shutdown()
+
+	runtime.Invoke(TestState{})
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case entry := <-stateMonitorCh:
+			if entry.Running {
+				continue
+			}
+			if !errors.Is(entry.Error, g.ErrPayloadType) || entry.Node != "Consume" {
+				t.Errorf("Expected Consume to fail with ErrPayloadType, got %+v", entry)
+			}
+			return
+		case <-timeout:
+			t.Fatal("Timeout waiting for the graph completion")
+		}
+	}
+}
+
+func TestNewDelayNode(t *testing.T) {
+	delayFn := func(ctx context.Context, userInput, currentState TestState) (time.Duration, error) {
+		return 10 * time.Millisecond, nil
+	}
+	if _, err := builders.NewDelayNode[TestState]("Wait", nil); !errors.Is(err, g.ErrDelayFnNil) {
+		t.Errorf("Expected ErrDelayFnNil, got %v", err)
+	}
+	if _, err := builders.NewDelayNode(builders.ReservedNodeNameStart, delayFn); !errors.Is(err, g.ErrReservedNodeName) {
+		t.Errorf("Expected ErrReservedNodeName, got %v", err)
+	}
+
+	wait, err := builders.NewDelayNode("Wait", delayFn)
+	if err != nil {
+		t.Fatalf("NewDelayNode() failed: %v", err)
+	}
+	if _, ok := wait.(g.Delayer[TestState]); !ok {
+		t.Fatal("Expected the delay node to implement Delayer")
+	}
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[TestState], 10)
+	runtime, _ := builders.CreateRuntime(builders.CreateStartEdge(wait), stateMonitorCh)
+	runtime.AddEdge(builders.CreateEndEdge(wait))
+	defer runtime.Shutdown()
+
+	runtime.Invoke(TestState{})
+
+	timeout := time.After(2 * time.Second)
+	slept := false
+	for {
+		select {
+		case entry := <-stateMonitorCh:
+			if entry.Error != nil {
+				t.Fatalf("Unexpected error: %v", entry.Error)
+			}
+			slept = slept || !entry.WakeAt.IsZero()
+			if !entry.Running {
+				if !slept {
+					t.Errorf("Expected the thread to sleep before completing, got %+v", entry)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("Timeout waiting for the graph completion")
+		}
+	}
+}
+
+func TestNewSignalNode(t *testing.T) {
+	if _, err := builders.NewSignalNode[TestState]("Await", "", 0); !errors.Is(err, g.ErrSignalNameEmpty) {
+		t.Errorf("Expected ErrSignalNameEmpty, got %v", err)
+	}
+	if _, err := builders.NewSignalNode[TestState](builders.ReservedNodeNameStart, "approved", 0); !errors.Is(err, g.ErrReservedNodeName) {
+		t.Errorf("Expected ErrReservedNodeName, got %v", err)
+	}
+
+	await, err := builders.NewSignalNode[TestState]("Await", "approved", time.Second)
+	if err != nil {
+		t.Fatalf("NewSignalNode() failed: %v", err)
+	}
+	awaiter, ok := await.(g.SignalAwaiter[TestState])
+	if !ok {
+		t.Fatal("Expected the signal node to implement SignalAwaiter")
+	}
+	if signal, timeout := awaiter.AwaitedSignal(); signal != "approved" || timeout != time.Second {
+		t.Errorf("Expected the approved signal with a 1s timeout, got %q, %v", signal, timeout)
+	}
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[TestState], 10)
+	runtime, _ := builders.CreateRuntime(builders.CreateStartEdge(await), stateMonitorCh)
+	runtime.AddEdge(builders.CreateEndEdge(await))
+	defer runtime.Shutdown()
+
+	threadID := runtime.Invoke(TestState{})
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case entry := <-stateMonitorCh:
+			if entry.Error != nil {
+				t.Fatalf("Unexpected error: %v", entry.Error)
+			}
+			if entry.Signal != "" {
+				if err := runtime.Signal(threadID, entry.Signal, TestState{Value: "approved"}); err != nil {
+					t.Fatalf("Signal() failed: %v", err)
+				}
+			}
+			if !entry.Running {
+				if entry.NewState.Value != "approved" {
+					t.Errorf("Expected the signal payload as state, got %+v", entry.NewState)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("Timeout waiting for the graph completion")
+		}
+	}
+}
+
+func TestNewBatchNode(t *testing.T) {
+	batchFn := func(ctx context.Context, inputs []TestState, currentState TestState) (TestState, error) {
+		currentState.Counter = len(inputs)
+		return currentState, nil
+	}
+	if _, err := builders.NewBatchNode[TestState]("Collect", nil, 2, 0); !errors.Is(err, g.ErrBatchFnNil) {
+		t.Errorf("Expected ErrBatchFnNil, got %v", err)
+	}
+	if _, err := builders.NewBatchNode("Collect", batchFn, 0, 0); !errors.Is(err, g.ErrBatchUnbounded) {
+		t.Errorf("Expected ErrBatchUnbounded, got %v", err)
+	}
+	if _, err := builders.NewBatchNode(builders.ReservedNodeNameStart, batchFn, 2, 0); !errors.Is(err, g.ErrReservedNodeName) {
+		t.Errorf("Expected ErrReservedNodeName, got %v", err)
+	}
+
+	collect, err := builders.NewBatchNode("Collect", batchFn, 2, time.Second)
+	if err != nil {
+		t.Fatalf("NewBatchNode() failed: %v", err)
+	}
+	batcher, ok := collect.(g.Batcher[TestState])
+	if !ok {
+		t.Fatal("Expected the batch node to implement Batcher")
+	}
+	if size, window := batcher.BatchLimits(); size != 2 || window != time.Second {
+		t.Errorf("Expected a batch of 2 inputs within 1s, got %d, %v", size, window)
+	}
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[TestState], 10)
+	runtime, _ := builders.CreateRuntime(builders.CreateStartEdge(collect), stateMonitorCh)
+	runtime.AddEdge(builders.CreateEndEdge(collect))
+	defer runtime.Shutdown()
+
+	threadID := runtime.Invoke(TestState{})
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case entry := <-stateMonitorCh:
+			if entry.Error != nil {
+				t.Fatalf("Unexpected error: %v", entry.Error)
+			}
+			if entry.Batched == 1 {
+				runtime.Invoke(TestState{}, g.InvokeConfig{ThreadID: threadID})
+			}
+			if !entry.Running {
+				if entry.NewState.Counter != 2 {
+					t.Errorf("Expected the 2 inputs to be processed at once, got %+v", entry.NewState)
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatal("Timeout waiting for the graph completion")
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package builders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/vectorstore"
+)
+
+const (
+	// DefaultRetrieverTopK is the default number of documents injected by a retriever node.
+	DefaultRetrieverTopK = 4
+)
+
+var (
+	// ErrEmbedderNil indicates that the retriever node has no embedder.
+	ErrEmbedderNil = errors.New("embedder cannot be nil")
+	// ErrVectorStoreNil indicates that the retriever node has no vector store.
+	ErrVectorStoreNil = errors.New("vector store cannot be nil")
+)
+
+// RetrievedDocumentsFormatter renders the retrieved documents into the content of the injected message.
+type RetrievedDocumentsFormatter func(matches []vectorstore.Match) string
+
+// RetrieverOptions holds the configuration of a retriever node.
+type RetrieverOptions struct {
+	// TopK is the maximum number of documents injected.
+	TopK int
+	// Filter restricts the documents to those whose metadata contains all the given pairs.
+	Filter map[string]string
+	// MinScore discards the documents scoring below it.
+	MinScore float32
+	// Formatter renders the documents, DefaultRetrievedDocumentsFormatter if nil.
+	Formatter RetrievedDocumentsFormatter
+	// NodeOptions are the options of the underlying node.
+	NodeOptions []g.NodeOption[a.Conversation]
+}
+
+// RetrieverOption is a functional option for configuring a retriever node.
+type RetrieverOption interface {
+	// Apply applies the option to the RetrieverOptions.
+	//
+	// Parameters:
+	//   - r: A pointer to RetrieverOptions to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *RetrieverOptions) error
+}
+
+// RetrieverOptionFunc is a function type that implements the RetrieverOption interface.
+type RetrieverOptionFunc func(*RetrieverOptions) error
+
+// Apply applies the RetrieverOptionFunc to the given RetrieverOptions.
+//
+// Parameters:
+//   - r: A pointer to RetrieverOptions to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s RetrieverOptionFunc) Apply(r *RetrieverOptions) error { return s(r) }
+
+// WithRetrieverTopK sets the maximum number of documents injected.
+//
+// Parameters:
+//   - topK: The number of documents.
+//
+// Returns:
+//   - A RetrieverOption that sets the number of documents.
+func WithRetrieverTopK(topK int) RetrieverOption {
+	return RetrieverOptionFunc(func(r *RetrieverOptions) error {
+		if topK < 1 {
+			return vectorstore.ErrInvalidTopK
+		}
+		r.TopK = topK
+		return nil
+	})
+}
+
+// WithRetrieverFilter restricts the documents to those whose metadata contains all the given pairs.
+//
+// Parameters:
+//   - filter: The metadata pairs.
+//
+// Returns:
+//   - A RetrieverOption that sets the filter.
+func WithRetrieverFilter(filter map[string]string) RetrieverOption {
+	return RetrieverOptionFunc(func(r *RetrieverOptions) error {
+		r.Filter = filter
+		return nil
+	})
+}
+
+// WithRetrieverMinScore discards the documents scoring below the given similarity.
+//
+// Parameters:
+//   - minScore: The minimum cosine similarity.
+//
+// Returns:
+//   - A RetrieverOption that sets the minimum score.
+func WithRetrieverMinScore(minScore float32) RetrieverOption {
+	return RetrieverOptionFunc(func(r *RetrieverOptions) error {
+		r.MinScore = minScore
+		return nil
+	})
+}
+
+// WithRetrieverFormatter sets how the documents are rendered into the injected message.
+//
+// Parameters:
+//   - formatter: The formatter.
+//
+// Returns:
+//   - A RetrieverOption that sets the formatter.
+func WithRetrieverFormatter(formatter RetrievedDocumentsFormatter) RetrieverOption {
+	return RetrieverOptionFunc(func(r *RetrieverOptions) error {
+		r.Formatter = formatter
+		return nil
+	})
+}
+
+// WithRetrieverNodeOptions sets the options of the underlying node, e.g. its routing policy.
+//
+// Parameters:
+//   - opts: The node options.
+//
+// Returns:
+//   - A RetrieverOption that sets the node options.
+func WithRetrieverNodeOptions(opts ...g.NodeOption[a.Conversation]) RetrieverOption {
+	return RetrieverOptionFunc(func(r *RetrieverOptions) error {
+		r.NodeOptions = append(r.NodeOptions, opts...)
+		return nil
+	})
+}
+
+// DefaultRetrievedDocumentsFormatter renders the documents as a numbered list.
+//
+// Parameters:
+//   - matches: The retrieved documents.
+//
+// Returns:
+//   - The content of the injected message.
+func DefaultRetrievedDocumentsFormatter(matches []vectorstore.Match) string {
+	var sb strings.Builder
+	sb.WriteString("Use the following documents to answer the user:\n")
+	for i, match := range matches {
+		fmt.Fprintf(&sb, "\n[%d] %s\n", i+1, match.Content)
+	}
+	return sb.String()
+}
+
+// NewRetrieverNode creates a node injecting the documents relevant to the latest user message into the conversation.
+//
+// The node embeds the latest user message, either from the user input or from the conversation,
+// queries the store and injects a system message with the retrieved documents right before that
+// user message: the user input is not merged into the state, so that the downstream chat node
+// still sees it as the new turn. When nothing is retrieved the conversation is left unchanged.
+//
+// Parameters:
+//   - name: The unique name for the node.
+//   - embedder: The embedder of the user message, the same used to index the documents.
+//   - store: The vector store holding the documents.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - The retriever node.
+//   - An error if the node could not be created.
+//
+// Example:
+//
+//	retrieve, err := builders.NewRetrieverNode("Retrieve", embedder, store, builders.WithRetrieverTopK(3))
+//	startEdge := builders.CreateStartEdge(retrieve)
+//	runtime.AddEdge(builders.CreateEdge(retrieve, chatNode))
+func NewRetrieverNode(name string, embedder a.Embedder, store vectorstore.Store, opts ...RetrieverOption) (g.Node[a.Conversation], error) {
+	if embedder == nil {
+		return nil, fmt.Errorf("retriever node creation error for name %s: %w", name, ErrEmbedderNil)
+	}
+	if store == nil {
+		return nil, fmt.Errorf("retriever node creation error for name %s: %w", name, ErrVectorStoreNil)
+	}
+
+	useOpts := &RetrieverOptions{
+		TopK:      DefaultRetrieverTopK,
+		Formatter: DefaultRetrievedDocumentsFormatter,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("retriever node creation error for name %s: %w", name, err)
+		}
+	}
+	if useOpts.Formatter == nil {
+		useOpts.Formatter = DefaultRetrievedDocumentsFormatter
+	}
+
+	return NewContextNode(name, retrieveFn(embedder, store, useOpts), useOpts.NodeOptions...)
+}
+
+func retrieveFn(embedder a.Embedder, store vectorstore.Store, opts *RetrieverOptions) g.ContextNodeFn[a.Conversation] {
+	return func(ctx context.Context, userInput, currentState a.Conversation, notify g.NotifyPartialFn[a.Conversation]) (a.Conversation, error) {
+		messages := a.PromptMessages(userInput, currentState)
+
+		lastUser := -1
+		for i := len(messages) - 1; i >= 0; i-- {
+			if messages[i].Role == a.User {
+				lastUser = i
+				break
+			}
+		}
+		if lastUser < 0 {
+			return currentState, nil
+		}
+
+		embeddings, err := embedder.Embed(ctx, []string{messages[lastUser].Content})
+		if err != nil {
+			return currentState, fmt.Errorf("cannot embed the user message: %w", err)
+		}
+		if len(embeddings) != 1 {
+			return currentState, fmt.Errorf("cannot embed the user message: %w", a.ErrEmbeddingCountMismatch)
+		}
+
+		matches, err := store.Query(ctx, vectorstore.Query{
+			Embedding: embeddings[0],
+			TopK:      opts.TopK,
+			Filter:    opts.Filter,
+			MinScore:  opts.MinScore,
+		})
+		if err != nil {
+			return currentState, fmt.Errorf("cannot retrieve documents: %w", err)
+		}
+		if len(matches) == 0 {
+			return currentState, nil
+		}
+
+		documents := a.CreateMessage(a.System, opts.Formatter(matches))
+		at := min(lastUser, len(currentState.Messages))
+		useState := currentState
+		useState.Messages = append(append(append([]a.Message{}, currentState.Messages[:at]...), documents), currentState.Messages[at:]...)
+		return useState, nil
+	}
+}
@@ -0,0 +1,161 @@
+package builders_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/vectorstore"
+)
+
+type keywordEmbedder struct {
+	err   error
+	texts []string
+}
+
+func (e *keywordEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	e.texts = append(e.texts, texts...)
+	rv := make([][]float32, len(texts))
+	for i, text := range texts {
+		rv[i] = []float32{0.01, 0.01}
+		if strings.Contains(text, "cat") {
+			rv[i][0] = 1
+		}
+		if strings.Contains(text, "dog") {
+			rv[i][1] = 1
+		}
+	}
+	return rv, nil
+}
+
+func newRetrieverTestStore(t *testing.T) vectorstore.Store {
+	store := vectorstore.NewMemStore(2)
+	err := store.Upsert(context.Background(),
+		vectorstore.Document{ID: "cats", Content: "Cats purr.", Embedding: []float32{1, 0}},
+		vectorstore.Document{ID: "dogs", Content: "Dogs bark.", Embedding: []float32{0, 1}},
+	)
+	if err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	return store
+}
+
+func runConversationNode(t *testing.T, node g.Node[a.Conversation], userInput a.Conversation) g.StateMonitorEntry[a.Conversation] {
+	return runNode(t, node, userInput)
+}
+
+// TestNewRetrieverNode_InjectsDocuments tests that the documents relevant to the user message are injected
+func TestNewRetrieverNode_InjectsDocuments(t *testing.T) {
+	embedder := &keywordEmbedder{}
+	node, err := builders.NewRetrieverNode("Retrieve", embedder, newRetrieverTestStore(t), builders.WithRetrieverTopK(1))
+	if err != nil {
+		t.Fatalf("NewRetrieverNode() failed: %v", err)
+	}
+
+	entry := runConversationNode(t, node, a.CreateConversation(a.CreateMessage(a.User, "tell me about my dog")))
+	if entry.Error != nil {
+		t.Fatalf("Unexpected error: %v", entry.Error)
+	}
+	if len(embedder.texts) != 1 || embedder.texts[0] != "tell me about my dog" {
+		t.Errorf("Expected the user message to be embedded, got %v", embedder.texts)
+	}
+
+	var documents []a.Message
+	for _, message := range entry.NewState.Messages {
+		if message.Role == a.System {
+			documents = append(documents, message)
+		}
+	}
+	if len(documents) != 1 {
+		t.Fatalf("Expected one injected message, got %+v", entry.NewState.Messages)
+	}
+	if !strings.Contains(documents[0].Content, "Dogs bark.") || strings.Contains(documents[0].Content, "Cats purr.") {
+		t.Errorf("Expected only the dog document, got %q", documents[0].Content)
+	}
+}
+
+// TestNewRetrieverNode_BeforeUserMessage tests that the documents precede the latest user message in the conversation
+func TestNewRetrieverNode_BeforeUserMessage(t *testing.T) {
+	node, err := builders.NewRetrieverNode("Retrieve", &keywordEmbedder{}, newRetrieverTestStore(t),
+		builders.WithRetrieverFormatter(func(matches []vectorstore.Match) string { return matches[0].ID }),
+	)
+	if err != nil {
+		t.Fatalf("NewRetrieverNode() failed: %v", err)
+	}
+
+	var seen a.Conversation
+	capture, _ := builders.NewNode("Capture", func(userInput, currentState a.Conversation, notify g.NotifyPartialFn[a.Conversation]) (a.Conversation, error) {
+		seen = currentState
+		return currentState, nil
+	})
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[a.Conversation], 10)
+	runtime, _ := builders.CreateRuntime(builders.CreateStartEdge(node), stateMonitorCh)
+	runtime.AddEdge(builders.CreateEdge(node, capture), builders.CreateEndEdge(capture))
+	defer runtime.Shutdown()
+
+	runtime.Invoke(a.CreateConversation(
+		a.CreateMessage(a.Assistant, "hello"),
+		a.CreateMessage(a.User, "a cat question"),
+	))
+
+	timeout := time.After(2 * time.Second)
+	for done := false; !done; {
+		select {
+		case entry := <-stateMonitorCh:
+			if entry.Error != nil {
+				t.Fatalf("Unexpected error: %v", entry.Error)
+			}
+			done = !entry.Running
+		case <-timeout:
+			t.Fatal("Timeout waiting for the graph completion")
+		}
+	}
+
+	messages := append(append([]a.Message{}, seen.Messages...), a.CreateMessage(a.User, "a cat question"))
+	for i, message := range messages {
+		if message.Role == a.System {
+			if message.Content != "cats" {
+				t.Errorf("Expected the cats document, got %q", message.Content)
+			}
+			if messages[i+1].Role != a.User {
+				t.Errorf("Expected the documents right before the user message, got %+v", messages)
+			}
+			return
+		}
+	}
+	t.Errorf("Expected an injected message, got %+v", seen.Messages)
+}
+
+// TestNewRetrieverNode_Errors tests the creation and embedding errors
+func TestNewRetrieverNode_Errors(t *testing.T) {
+	store := newRetrieverTestStore(t)
+
+	if _, err := builders.NewRetrieverNode("Retrieve", nil, store); !errors.Is(err, builders.ErrEmbedderNil) {
+		t.Errorf("Expected ErrEmbedderNil, got %v", err)
+	}
+	if _, err := builders.NewRetrieverNode("Retrieve", &keywordEmbedder{}, nil); !errors.Is(err, builders.ErrVectorStoreNil) {
+		t.Errorf("Expected ErrVectorStoreNil, got %v", err)
+	}
+	if _, err := builders.NewRetrieverNode("Retrieve", &keywordEmbedder{}, store, builders.WithRetrieverTopK(0)); !errors.Is(err, vectorstore.ErrInvalidTopK) {
+		t.Errorf("Expected ErrInvalidTopK, got %v", err)
+	}
+
+	embedErr := errors.New("embedding down")
+	node, err := builders.NewRetrieverNode("Retrieve", &keywordEmbedder{err: embedErr}, store)
+	if err != nil {
+		t.Fatalf("NewRetrieverNode() failed: %v", err)
+	}
+	entry := runConversationNode(t, node, a.CreateConversation(a.CreateMessage(a.User, "cat")))
+	if !errors.Is(entry.Error, embedErr) {
+		t.Errorf("Expected the embedding error, got %v", entry.Error)
+	}
+}
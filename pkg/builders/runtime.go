@@ -60,3 +60,50 @@ func CreateRuntime[T g.SharedState](
 
 	return i.RuntimeFactory(startEdge, stateMonitorCh, useOpts)
 }
+
+// CreateRuntimeFromSnapshot creates a new graph runtime warm restarted with the threads of a snapshot.
+//
+// The threads resume with their states, TTLs, checkpoints and aliases, so that a long-running
+// service is redeployed without losing its conversations: the old runtime is drained with
+// Stop, snapshotted, and the new one is created from the snapshot with the same graph.
+//
+// Type Parameters:
+//   - T: The SharedState type that will be passed through the graph execution.
+//
+// Parameters:
+//   - startEdge: The edge that connects to the first operational node.
+//   - stateMonitorCh: A buffered channel that receives state monitoring entries during execution.
+//   - snapshot: The snapshot taken with Runtime.Snapshot.
+//   - opts: Optional configuration options for the runtime.
+//
+// Returns:
+//   - A new Runtime instance holding the threads of the snapshot.
+//   - An error if the runtime cannot be created, wrapping ErrInvalidSnapshot if the snapshot is inconsistent.
+//
+// Example:
+//
+//	old.Stop(ctx, g.ShutdownDrain)
+//	snapshot, _ := old.Snapshot()
+//	runtime, err := CreateRuntimeFromSnapshot(startEdge, stateMonitorCh, snapshot)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer runtime.Shutdown()
+func CreateRuntimeFromSnapshot[T g.SharedState](
+	startEdge g.Edge[T],
+	stateMonitorCh chan g.StateMonitorEntry[T],
+	snapshot g.RuntimeSnapshot[T],
+	opts ...g.RuntimeOption[T],
+) (g.Runtime[T], error) {
+
+	var zeroState T
+	useOpts := &g.RuntimeOptions[T]{
+		InitialState: zeroState,
+		Settings:     g.RuntimeSettings{},
+	}
+	for _, opt := range opts {
+		opt.Apply(useOpts)
+	}
+
+	return i.RuntimeFactoryFromSnapshot(startEdge, stateMonitorCh, useOpts, snapshot)
+}
@@ -0,0 +1,374 @@
+package builders
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/vectorstore"
+)
+
+const (
+	// DefaultSemanticMemoryTopK is the default number of past messages recalled by a semantic memory node.
+	DefaultSemanticMemoryTopK = 4
+	// SemanticMemoryThreadKey is the metadata key of the thread of the messages indexed by a semantic memory node.
+	SemanticMemoryThreadKey = "thread_id"
+	// SemanticMemoryRoleKey is the metadata key of the role of the messages indexed by a semantic memory node.
+	SemanticMemoryRoleKey = "role"
+	// IndexedMessageKey marks, in the message metadata, the messages already stored in the vector store.
+	IndexedMessageKey = "ggraph.semantic_memory.indexed"
+	// RecalledMessagesKey marks, in the message metadata, the system message holding the recalled messages.
+	RecalledMessagesKey = "ggraph.semantic_memory.recalled"
+)
+
+var recalledRoles = map[a.MessageRole]string{
+	a.System:    "system",
+	a.User:      "user",
+	a.Assistant: "assistant",
+	a.Tool:      "tool",
+}
+
+// RecalledMessagesFormatter renders the recalled messages into the content of the injected message.
+type RecalledMessagesFormatter func(matches []vectorstore.Match) string
+
+// SemanticMemoryOptions holds the configuration of a semantic memory node.
+type SemanticMemoryOptions struct {
+	// TopK is the maximum number of past messages recalled.
+	TopK int
+	// MinScore discards the past messages scoring below it.
+	MinScore float32
+	// Tokenizer counts the tokens of the conversation, a.ApproximateTokenizer if nil.
+	Tokenizer a.Tokenizer
+	// Formatter renders the recalled messages, DefaultRecalledMessagesFormatter if nil.
+	Formatter RecalledMessagesFormatter
+	// NodeOptions are the options of the underlying node.
+	NodeOptions []g.NodeOption[a.Conversation]
+}
+
+// SemanticMemoryOption is a functional option for configuring a semantic memory node.
+type SemanticMemoryOption interface {
+	// Apply applies the option to the SemanticMemoryOptions.
+	//
+	// Parameters:
+	//   - r: A pointer to SemanticMemoryOptions to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *SemanticMemoryOptions) error
+}
+
+// SemanticMemoryOptionFunc is a function type that implements the SemanticMemoryOption interface.
+type SemanticMemoryOptionFunc func(*SemanticMemoryOptions) error
+
+// Apply applies the SemanticMemoryOptionFunc to the given SemanticMemoryOptions.
+//
+// Parameters:
+//   - r: A pointer to SemanticMemoryOptions to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s SemanticMemoryOptionFunc) Apply(r *SemanticMemoryOptions) error { return s(r) }
+
+// WithSemanticMemoryTopK sets the maximum number of past messages recalled.
+//
+// Parameters:
+//   - topK: The number of messages.
+//
+// Returns:
+//   - A SemanticMemoryOption that sets the number of messages.
+func WithSemanticMemoryTopK(topK int) SemanticMemoryOption {
+	return SemanticMemoryOptionFunc(func(r *SemanticMemoryOptions) error {
+		if topK < 1 {
+			return vectorstore.ErrInvalidTopK
+		}
+		r.TopK = topK
+		return nil
+	})
+}
+
+// WithSemanticMemoryMinScore discards the past messages scoring below the given similarity.
+//
+// Parameters:
+//   - minScore: The minimum cosine similarity.
+//
+// Returns:
+//   - A SemanticMemoryOption that sets the minimum score.
+func WithSemanticMemoryMinScore(minScore float32) SemanticMemoryOption {
+	return SemanticMemoryOptionFunc(func(r *SemanticMemoryOptions) error {
+		r.MinScore = minScore
+		return nil
+	})
+}
+
+// WithSemanticMemoryTokenizer sets how the tokens of the conversation are counted against the budget.
+//
+// Parameters:
+//   - tokenizer: The tokenizer of the model.
+//
+// Returns:
+//   - A SemanticMemoryOption that sets the tokenizer.
+func WithSemanticMemoryTokenizer(tokenizer a.Tokenizer) SemanticMemoryOption {
+	return SemanticMemoryOptionFunc(func(r *SemanticMemoryOptions) error {
+		r.Tokenizer = tokenizer
+		return nil
+	})
+}
+
+// WithSemanticMemoryFormatter sets how the recalled messages are rendered into the injected message.
+//
+// Parameters:
+//   - formatter: The formatter.
+//
+// Returns:
+//   - A SemanticMemoryOption that sets the formatter.
+func WithSemanticMemoryFormatter(formatter RecalledMessagesFormatter) SemanticMemoryOption {
+	return SemanticMemoryOptionFunc(func(r *SemanticMemoryOptions) error {
+		r.Formatter = formatter
+		return nil
+	})
+}
+
+// WithSemanticMemoryNodeOptions sets the options of the underlying node, e.g. its routing policy.
+//
+// Parameters:
+//   - opts: The node options.
+//
+// Returns:
+//   - A SemanticMemoryOption that sets the node options.
+func WithSemanticMemoryNodeOptions(opts ...g.NodeOption[a.Conversation]) SemanticMemoryOption {
+	return SemanticMemoryOptionFunc(func(r *SemanticMemoryOptions) error {
+		r.NodeOptions = append(r.NodeOptions, opts...)
+		return nil
+	})
+}
+
+// DefaultRecalledMessagesFormatter renders the recalled messages as a list prefixed by their role.
+//
+// Parameters:
+//   - matches: The recalled messages.
+//
+// Returns:
+//   - The content of the injected message.
+func DefaultRecalledMessagesFormatter(matches []vectorstore.Match) string {
+	var sb strings.Builder
+	sb.WriteString("Relevant messages from earlier in this conversation:\n")
+	for _, match := range matches {
+		fmt.Fprintf(&sb, "\n[%s] %s\n", match.Metadata[SemanticMemoryRoleKey], match.Content)
+	}
+	return sb.String()
+}
+
+// NewSemanticMemoryNode creates a node giving the conversation a long-term memory of its past messages.
+//
+// The node embeds the user and assistant messages of the conversation not indexed yet and stores
+// them into the vector store, tagged with the thread of the invocation; the indexed messages are
+// marked with IndexedMessageKey so that they are embedded once. When the conversation exceeds the
+// token budget, the state keeps the leading system messages and the latest turns fitting the
+// budget, see a.NewTokenWindow, while the older turns live on in the vector store: the past messages
+// of the thread most relevant to the latest user message are recalled into a system message, marked
+// with RecalledMessagesKey, right before that user message. The recalled message is replaced on
+// every invocation; the user input is not merged into the state, as with NewRetrieverNode.
+//
+// Parameters:
+//   - name: The unique name for the node.
+//   - embedder: The embedder of the messages.
+//   - store: The vector store holding the messages, it can be shared with other threads.
+//   - maxTokens: The token budget of the conversation, the user input included.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - The semantic memory node.
+//   - An error if the node could not be created.
+//
+// Example:
+//
+//	memory, err := builders.NewSemanticMemoryNode("Memory", embedder, vectorstore.NewMemStore(1536), 8000,
+//	    builders.WithSemanticMemoryTopK(5))
+//	startEdge := builders.CreateStartEdge(memory)
+//	runtime.AddEdge(builders.CreateEdge(memory, chatNode))
+//	runtime.Invoke(a.CreateConversation(a.CreateMessage(a.User, "Where did we leave?")), g.InvokeConfigThreadID("user-42"))
+func NewSemanticMemoryNode(name string, embedder a.Embedder, store vectorstore.Store, maxTokens int, opts ...SemanticMemoryOption) (g.Node[a.Conversation], error) {
+	if embedder == nil {
+		return nil, fmt.Errorf("semantic memory node creation error for name %s: %w", name, ErrEmbedderNil)
+	}
+	if store == nil {
+		return nil, fmt.Errorf("semantic memory node creation error for name %s: %w", name, ErrVectorStoreNil)
+	}
+	if maxTokens < 1 {
+		return nil, fmt.Errorf("semantic memory node creation error for name %s: %w", name, a.ErrInvalidContextBudget)
+	}
+
+	useOpts := &SemanticMemoryOptions{
+		TopK:      DefaultSemanticMemoryTopK,
+		Tokenizer: a.ApproximateTokenizer,
+		Formatter: DefaultRecalledMessagesFormatter,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("semantic memory node creation error for name %s: %w", name, err)
+		}
+	}
+	if useOpts.Tokenizer == nil {
+		useOpts.Tokenizer = a.ApproximateTokenizer
+	}
+	if useOpts.Formatter == nil {
+		useOpts.Formatter = DefaultRecalledMessagesFormatter
+	}
+
+	return NewContextNode(name, semanticMemoryFn(embedder, store, maxTokens, useOpts), useOpts.NodeOptions...)
+}
+
+func semanticMemoryFn(embedder a.Embedder, store vectorstore.Store, maxTokens int, opts *SemanticMemoryOptions) g.ContextNodeFn[a.Conversation] {
+	return func(ctx context.Context, userInput, currentState a.Conversation, notify g.NotifyPartialFn[a.Conversation]) (a.Conversation, error) {
+		threadID, _ := g.ThreadIDFromContext(ctx)
+
+		useState := currentState
+		useState.Messages = make([]a.Message, 0, len(currentState.Messages))
+		for _, message := range currentState.Messages {
+			if message.Metadata[RecalledMessagesKey] == "" {
+				useState.Messages = append(useState.Messages, message)
+			}
+		}
+
+		if err := indexMessages(ctx, embedder, store, threadID, useState.Messages); err != nil {
+			return currentState, err
+		}
+
+		inputTokens := a.CountPromptTokens(opts.Tokenizer, userInput.Messages)
+		if a.CountPromptTokens(opts.Tokenizer, useState.Messages)+inputTokens <= maxTokens {
+			return useState, nil
+		}
+
+		window, err := a.NewTokenWindow(max(maxTokens-inputTokens, 1), a.TokenCounterFor(opts.Tokenizer))
+		if err != nil {
+			return currentState, err
+		}
+		kept, err := window.Compact(ctx, useState.Messages)
+		if err != nil {
+			return currentState, fmt.Errorf("cannot trim the conversation: %w", err)
+		}
+		useState.Messages = kept
+
+		messages := append(append([]a.Message{}, kept...), userInput.Messages...)
+		lastUser := -1
+		for i := len(messages) - 1; i >= 0; i-- {
+			if messages[i].Role == a.User {
+				lastUser = i
+				break
+			}
+		}
+		if lastUser < 0 {
+			return useState, nil
+		}
+
+		matches, err := recallMessages(ctx, embedder, store, threadID, messages[lastUser].Content, kept, opts)
+		if err != nil {
+			return currentState, err
+		}
+		if len(matches) == 0 {
+			return useState, nil
+		}
+
+		recalled := a.CreateMessage(a.System, opts.Formatter(matches))
+		recalled.Metadata = map[string]string{RecalledMessagesKey: "true"}
+		at := min(lastUser, len(kept))
+		useState.Messages = append(append(append([]a.Message{}, kept[:at]...), recalled), kept[at:]...)
+		return useState, nil
+	}
+}
+
+// indexMessages stores the user and assistant messages not indexed yet, marking them in place.
+func indexMessages(ctx context.Context, embedder a.Embedder, store vectorstore.Store, threadID string, messages []a.Message) error {
+	var pending []int
+	var texts []string
+	for idx, message := range messages {
+		if message.Metadata[IndexedMessageKey] != "" || message.Content == "" {
+			continue
+		}
+		if message.Role != a.User && message.Role != a.Assistant {
+			continue
+		}
+		pending = append(pending, idx)
+		texts = append(texts, message.Content)
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	embeddings, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		return fmt.Errorf("cannot embed the conversation messages: %w", err)
+	}
+	if len(embeddings) != len(texts) {
+		return fmt.Errorf("cannot embed the conversation messages: %w", a.ErrEmbeddingCountMismatch)
+	}
+
+	documents := make([]vectorstore.Document, len(pending))
+	for i, idx := range pending {
+		message := &messages[idx]
+		if message.ID == "" {
+			message.ID = a.NewMessageID()
+		}
+		documents[i] = vectorstore.Document{
+			ID:      threadID + ":" + message.ID,
+			Content: message.Content,
+			Metadata: map[string]string{
+				SemanticMemoryThreadKey: threadID,
+				SemanticMemoryRoleKey:   recalledRoles[message.Role],
+			},
+			Embedding: embeddings[i],
+		}
+	}
+	if err := store.Upsert(ctx, documents...); err != nil {
+		return fmt.Errorf("cannot store the conversation messages: %w", err)
+	}
+
+	for _, idx := range pending {
+		metadata := maps.Clone(messages[idx].Metadata)
+		if metadata == nil {
+			metadata = make(map[string]string, 1)
+		}
+		metadata[IndexedMessageKey] = "true"
+		messages[idx].Metadata = metadata
+	}
+	return nil
+}
+
+// recallMessages returns the past messages of the thread most relevant to the query, but the kept ones.
+func recallMessages(ctx context.Context, embedder a.Embedder, store vectorstore.Store, threadID, query string, kept []a.Message, opts *SemanticMemoryOptions) ([]vectorstore.Match, error) {
+	embeddings, err := embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("cannot embed the user message: %w", err)
+	}
+	if len(embeddings) != 1 {
+		return nil, fmt.Errorf("cannot embed the user message: %w", a.ErrEmbeddingCountMismatch)
+	}
+
+	keptIDs := make(map[string]bool, len(kept))
+	for _, message := range kept {
+		if message.ID != "" {
+			keptIDs[threadID+":"+message.ID] = true
+		}
+	}
+
+	matches, err := store.Query(ctx, vectorstore.Query{
+		Embedding: embeddings[0],
+		TopK:      opts.TopK + len(keptIDs),
+		Filter:    map[string]string{SemanticMemoryThreadKey: threadID},
+		MinScore:  opts.MinScore,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot recall the conversation messages: %w", err)
+	}
+
+	rv := make([]vectorstore.Match, 0, opts.TopK)
+	for _, match := range matches {
+		if !keptIDs[match.ID] && len(rv) < opts.TopK {
+			rv = append(rv, match)
+		}
+	}
+	return rv, nil
+}
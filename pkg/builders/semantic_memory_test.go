@@ -0,0 +1,184 @@
+package builders_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/vectorstore"
+)
+
+func runSemanticMemory(t *testing.T, node g.Node[a.Conversation], initialState, userInput a.Conversation) (a.Conversation, error) {
+	var seen a.Conversation
+	capture, _ := builders.NewNode("Capture", func(userInput, currentState a.Conversation, notify g.NotifyPartialFn[a.Conversation]) (a.Conversation, error) {
+		seen = currentState
+		return currentState, nil
+	})
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[a.Conversation], 10)
+	runtime, err := builders.CreateRuntime(builders.CreateStartEdge(node), stateMonitorCh, g.WithInitialState(initialState))
+	if err != nil {
+		t.Fatalf("CreateRuntime() failed: %v", err)
+	}
+	runtime.AddEdge(builders.CreateEdge(node, capture), builders.CreateEndEdge(capture))
+	defer runtime.Shutdown()
+
+	runtime.Invoke(userInput, g.InvokeConfigThreadID("thread-1"))
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case entry := <-stateMonitorCh:
+			if entry.Error != nil {
+				return seen, entry.Error
+			}
+			if !entry.Running {
+				return seen, nil
+			}
+		case <-timeout:
+			t.Fatal("Timeout waiting for the graph completion")
+		}
+	}
+}
+
+func longConversation() a.Conversation {
+	return a.CreateConversation(
+		a.CreateMessage(a.System, "You are a helpful assistant."),
+		a.CreateMessage(a.User, "my cat is called Tom"+strings.Repeat(" and he purrs", 20)),
+		a.CreateMessage(a.Assistant, "Nice name"+strings.Repeat(" indeed", 20)),
+		a.CreateMessage(a.User, "my dog is called Rex"),
+		a.CreateMessage(a.Assistant, "Nice name for a dog"),
+	)
+}
+
+// TestNewSemanticMemoryNode_UnderBudget tests that the messages are indexed and the conversation is left whole
+func TestNewSemanticMemoryNode_UnderBudget(t *testing.T) {
+	embedder := &keywordEmbedder{}
+	store := vectorstore.NewMemStore(2)
+	node, err := builders.NewSemanticMemoryNode("Memory", embedder, store, 10000)
+	if err != nil {
+		t.Fatalf("NewSemanticMemoryNode() failed: %v", err)
+	}
+
+	seen, err := runSemanticMemory(t, node, longConversation(), a.CreateConversation(a.CreateMessage(a.User, "and my cat?")))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(seen.Messages) != 5 {
+		t.Fatalf("Expected the whole conversation, got %+v", seen.Messages)
+	}
+	if len(embedder.texts) != 4 {
+		t.Errorf("Expected the user and assistant messages to be embedded, got %v", embedder.texts)
+	}
+	for _, message := range seen.Messages[1:] {
+		if message.Metadata[builders.IndexedMessageKey] == "" {
+			t.Errorf("Expected the message %q to be marked as indexed", message.Content)
+		}
+	}
+	if seen.Messages[0].Metadata[builders.IndexedMessageKey] != "" {
+		t.Error("Expected the system message not to be indexed")
+	}
+
+	matches, err := store.Query(context.Background(), vectorstore.Query{
+		Embedding: []float32{1, 1},
+		TopK:      10,
+		Filter:    map[string]string{builders.SemanticMemoryThreadKey: "thread-1"},
+	})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(matches) != 4 {
+		t.Errorf("Expected 4 messages stored for the thread, got %d", len(matches))
+	}
+}
+
+// TestNewSemanticMemoryNode_RecallsOverBudget tests that the older turns are dropped and the relevant ones recalled
+func TestNewSemanticMemoryNode_RecallsOverBudget(t *testing.T) {
+	store := vectorstore.NewMemStore(2)
+	err := store.Upsert(context.Background(), vectorstore.Document{
+		ID:        "other",
+		Content:   "another thread cat",
+		Metadata:  map[string]string{builders.SemanticMemoryThreadKey: "thread-2"},
+		Embedding: []float32{1, 0},
+	})
+	if err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+
+	node, err := builders.NewSemanticMemoryNode("Memory", &keywordEmbedder{}, store, 60, builders.WithSemanticMemoryTopK(1))
+	if err != nil {
+		t.Fatalf("NewSemanticMemoryNode() failed: %v", err)
+	}
+
+	seen, err := runSemanticMemory(t, node, longConversation(), a.CreateConversation(a.CreateMessage(a.User, "what is the name of my cat?")))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var contents []string
+	for _, message := range seen.Messages {
+		contents = append(contents, message.Content)
+	}
+	if len(seen.Messages) != 4 {
+		t.Fatalf("Expected the system message, the latest turn and the recall, got %q", contents)
+	}
+	if seen.Messages[0].Content != "You are a helpful assistant." || seen.Messages[1].Content != "my dog is called Rex" {
+		t.Errorf("Expected the older turn to be dropped, got %q", contents)
+	}
+
+	recalled := seen.Messages[3]
+	if recalled.Role != a.System || recalled.Metadata[builders.RecalledMessagesKey] == "" {
+		t.Fatalf("Expected the recalled messages last, before the user input, got %+v", recalled)
+	}
+	if !strings.Contains(recalled.Content, "[user] my cat is called Tom") {
+		t.Errorf("Expected the cat message to be recalled, got %q", recalled.Content)
+	}
+	if strings.Contains(recalled.Content, "another thread") || strings.Contains(recalled.Content, "Rex") {
+		t.Errorf("Expected neither the other threads nor the kept messages to be recalled, got %q", recalled.Content)
+	}
+
+	again, err := runSemanticMemory(t, node, seen, a.CreateConversation(a.CreateMessage(a.User, "and my cat?"+strings.Repeat(" really", 30))))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	recalls := 0
+	for _, message := range again.Messages {
+		if message.Metadata[builders.RecalledMessagesKey] != "" {
+			recalls++
+		}
+	}
+	if recalls != 1 {
+		t.Errorf("Expected the recalled messages to be replaced, got %d of them", recalls)
+	}
+}
+
+// TestNewSemanticMemoryNode_Errors tests the creation and embedding errors
+func TestNewSemanticMemoryNode_Errors(t *testing.T) {
+	store := vectorstore.NewMemStore(2)
+	if _, err := builders.NewSemanticMemoryNode("Memory", nil, store, 100); !errors.Is(err, builders.ErrEmbedderNil) {
+		t.Errorf("Expected ErrEmbedderNil, got %v", err)
+	}
+	if _, err := builders.NewSemanticMemoryNode("Memory", &keywordEmbedder{}, nil, 100); !errors.Is(err, builders.ErrVectorStoreNil) {
+		t.Errorf("Expected ErrVectorStoreNil, got %v", err)
+	}
+	if _, err := builders.NewSemanticMemoryNode("Memory", &keywordEmbedder{}, store, 0); !errors.Is(err, a.ErrInvalidContextBudget) {
+		t.Errorf("Expected ErrInvalidContextBudget, got %v", err)
+	}
+	if _, err := builders.NewSemanticMemoryNode("Memory", &keywordEmbedder{}, store, 100, builders.WithSemanticMemoryTopK(0)); !errors.Is(err, vectorstore.ErrInvalidTopK) {
+		t.Errorf("Expected ErrInvalidTopK, got %v", err)
+	}
+
+	embedErr := errors.New("embedding down")
+	node, err := builders.NewSemanticMemoryNode("Memory", &keywordEmbedder{err: embedErr}, store, 100)
+	if err != nil {
+		t.Fatalf("NewSemanticMemoryNode() failed: %v", err)
+	}
+	if _, err := runSemanticMemory(t, node, longConversation(), a.CreateConversation(a.CreateMessage(a.User, "hi"))); !errors.Is(err, embedErr) {
+		t.Errorf("Expected the embedding error, got %v", err)
+	}
+}
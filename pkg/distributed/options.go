@@ -0,0 +1,90 @@
+package distributed
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+)
+
+const (
+	// DefaultConcurrency is the default number of tasks executed at once by a worker.
+	DefaultConcurrency = 1
+	// DefaultRetryDelay is the default pause of a worker after a failed read of the queue.
+	DefaultRetryDelay = time.Second
+)
+
+var (
+	// ErrMemoryNil indicates that the memory shared with the runtimes is nil.
+	ErrMemoryNil = errors.New("memory cannot be nil")
+	// ErrNoNodes indicates that a worker has no node to execute.
+	ErrNoNodes = errors.New("worker requires at least one node")
+	// ErrDuplicateNode indicates that two nodes of a worker have the same name.
+	ErrDuplicateNode = errors.New("duplicate node name")
+	// ErrInvalidConcurrency indicates that the concurrency of a worker is not positive.
+	ErrInvalidConcurrency = errors.New("worker concurrency must be positive")
+)
+
+// Options holds the configuration of a Worker.
+type Options struct {
+	// Concurrency is the number of tasks executed at once.
+	Concurrency int
+	// RetryDelay is the pause after a failed read of the queue.
+	RetryDelay time.Duration
+	// Logger receives the structured logs of the worker, nil disables logging.
+	Logger *slog.Logger
+}
+
+// Option is a functional option for configuring a Worker.
+type Option interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - r: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *Options) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc func(*Options) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - r: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc) Apply(r *Options) error { return s(r) }
+
+// WithConcurrency sets the number of tasks executed at once by the worker.
+//
+// Parameters:
+//   - concurrency: The number of tasks, DefaultConcurrency without this option.
+//
+// Returns:
+//   - An Option that sets the concurrency.
+func WithConcurrency(concurrency int) Option {
+	return OptionFunc(func(r *Options) error {
+		if concurrency <= 0 {
+			return ErrInvalidConcurrency
+		}
+		r.Concurrency = concurrency
+		return nil
+	})
+}
+
+// WithLogger sets the logger of the worker.
+//
+// Parameters:
+//   - logger: The logger receiving the structured logs.
+//
+// Returns:
+//   - An Option that sets the logger.
+func WithLogger(logger *slog.Logger) Option {
+	return OptionFunc(func(r *Options) error {
+		r.Logger = logger
+		return nil
+	})
+}
@@ -0,0 +1,111 @@
+// Package distributed executes the nodes of a graph in worker processes.
+//
+// A runtime created with graph.WithWorkQueue publishes the node executions to a graph.WorkQueue
+// instead of running them in its own worker pool; the Workers, started by any number of
+// processes holding the same nodes, execute them on the states shared through a graph.Memory
+// and reply with the reduced states. The runtime keeps routing the invocations, so that the
+// node executions, typically the model and tool calls, scale horizontally.
+//
+// MemoryQueue connects runtimes and workers of the same process, the redis subpackage
+// connects processes through Redis streams.
+package distributed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// ErrInvalidCapacity indicates that the capacity of a queue is not positive.
+var ErrInvalidCapacity = errors.New("queue capacity must be positive")
+
+var _ g.WorkQueue = (*MemoryQueue)(nil)
+
+// MemoryQueue is a WorkQueue within the process, to test the distributed execution or to
+// isolate the node executions in a dedicated pool of workers.
+type MemoryQueue struct {
+	tasks chan g.NodeTask
+
+	mu       sync.Mutex
+	capacity int
+	results  map[string]chan g.NodeResult
+}
+
+// NewMemoryQueue creates a WorkQueue within the process.
+//
+// Parameters:
+//   - capacity: The number of tasks, and of results per runtime instance, buffered by the queue.
+//
+// Returns:
+//   - The queue.
+//   - An error wrapping ErrInvalidCapacity if the capacity is not positive.
+//
+// Example:
+//
+//	queue, _ := distributed.NewMemoryQueue(100)
+//	worker, _ := distributed.NewWorker(queue, memory, []graph.Node[MyState]{research})
+//	go worker.Run(ctx)
+func NewMemoryQueue(capacity int) (*MemoryQueue, error) {
+	if capacity <= 0 {
+		return nil, fmt.Errorf("memory queue creation failed: %w", ErrInvalidCapacity)
+	}
+	return &MemoryQueue{
+		tasks:    make(chan g.NodeTask, capacity),
+		capacity: capacity,
+		results:  make(map[string]chan g.NodeResult),
+	}, nil
+}
+
+// Enqueue publishes a task for the workers, waiting for room until the context is done.
+func (q *MemoryQueue) Enqueue(ctx context.Context, task g.NodeTask) error {
+	select {
+	case q.tasks <- task:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("cannot enqueue task %s: %w", task.ID, ctx.Err())
+	}
+}
+
+// Dequeue waits for the next task.
+func (q *MemoryQueue) Dequeue(ctx context.Context) (g.NodeTask, error) {
+	select {
+	case task := <-q.tasks:
+		return task, nil
+	case <-ctx.Done():
+		return g.NodeTask{}, ctx.Err()
+	}
+}
+
+// Reply publishes the result of a task, waiting for room until the context is done.
+func (q *MemoryQueue) Reply(ctx context.Context, result g.NodeResult) error {
+	select {
+	case q.resultsOf(result.Instance) <- result:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("cannot reply to task %s: %w", result.TaskID, ctx.Err())
+	}
+}
+
+// Results waits for the next result addressed to the runtime instance.
+func (q *MemoryQueue) Results(ctx context.Context, instance string) (g.NodeResult, error) {
+	select {
+	case result := <-q.resultsOf(instance):
+		return result, nil
+	case <-ctx.Done():
+		return g.NodeResult{}, ctx.Err()
+	}
+}
+
+func (q *MemoryQueue) resultsOf(instance string) chan g.NodeResult {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	results, ok := q.results[instance]
+	if !ok {
+		results = make(chan g.NodeResult, q.capacity)
+		q.results[instance] = results
+	}
+	return results
+}
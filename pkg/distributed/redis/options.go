@@ -0,0 +1,138 @@
+package redis
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// DefaultKeyPrefix is the default prefix of the streams of the queue.
+	DefaultKeyPrefix = "ggraph:work:"
+	// DefaultGroup is the default consumer group of the workers.
+	DefaultGroup = "workers"
+	// DefaultBlock is the default time a read waits for a message before checking its context again.
+	DefaultBlock = time.Second
+	// DefaultClaimIdle is the default time after which the task of an unresponsive worker is delivered again.
+	DefaultClaimIdle = time.Minute
+)
+
+var (
+	// ErrClientNil indicates that the provided Redis client is nil.
+	ErrClientNil = errors.New("redis client cannot be nil")
+	// ErrInvalidKeyPrefix indicates that the provided key prefix is empty.
+	ErrInvalidKeyPrefix = errors.New("key prefix cannot be empty")
+	// ErrInvalidConsumer indicates that the provided group or consumer name is empty.
+	ErrInvalidConsumer = errors.New("consumer group and name cannot be empty")
+	// ErrInvalidDuration indicates that the provided duration is not positive.
+	ErrInvalidDuration = errors.New("duration must be positive")
+	// ErrInvalidMessage indicates that a message of a stream does not hold a task or a result.
+	ErrInvalidMessage = errors.New("invalid stream message")
+)
+
+// Options holds the configuration for the Redis WorkQueue implementation.
+type Options struct {
+	// KeyPrefix is prepended to the names of the streams, to share a Redis database with other applications.
+	KeyPrefix string
+	// Group is the consumer group the workers read the tasks with.
+	Group string
+	// Consumer is the name of the worker within the group, a random one by default.
+	Consumer string
+	// Block is the time a read waits for a message before checking its context again.
+	Block time.Duration
+	// ClaimIdle is the time after which a task not acknowledged by its worker is delivered again.
+	ClaimIdle time.Duration
+}
+
+// Option is a functional option for configuring the Redis WorkQueue implementation.
+type Option interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - r: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *Options) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc func(*Options) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - r: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc) Apply(r *Options) error { return s(r) }
+
+// WithKeyPrefix sets the prefix of the streams of the queue.
+//
+// Parameters:
+//   - prefix: The key prefix.
+//
+// Returns:
+//   - An Option that sets the key prefix.
+func WithKeyPrefix(prefix string) Option {
+	return OptionFunc(func(r *Options) error {
+		if prefix == "" {
+			return ErrInvalidKeyPrefix
+		}
+		r.KeyPrefix = prefix
+		return nil
+	})
+}
+
+// WithConsumer sets the consumer group and the name the worker reads the tasks with.
+//
+// Parameters:
+//   - group: The consumer group, shared by the workers executing the same nodes.
+//   - consumer: The name of the worker, unique within the group.
+//
+// Returns:
+//   - An Option that sets the consumer.
+func WithConsumer(group, consumer string) Option {
+	return OptionFunc(func(r *Options) error {
+		if group == "" || consumer == "" {
+			return ErrInvalidConsumer
+		}
+		r.Group = group
+		r.Consumer = consumer
+		return nil
+	})
+}
+
+// WithBlock sets the time a read waits for a message before checking its context again.
+//
+// Parameters:
+//   - block: The blocking time of the reads.
+//
+// Returns:
+//   - An Option that sets the blocking time.
+func WithBlock(block time.Duration) Option {
+	return OptionFunc(func(r *Options) error {
+		if block <= 0 {
+			return ErrInvalidDuration
+		}
+		r.Block = block
+		return nil
+	})
+}
+
+// WithClaimIdle sets the time after which a task not acknowledged by its worker is delivered again.
+//
+// Parameters:
+//   - idle: The idle time of the pending tasks, longer than the slowest node.
+//
+// Returns:
+//   - An Option that sets the claim idle time.
+func WithClaimIdle(idle time.Duration) Option {
+	return OptionFunc(func(r *Options) error {
+		if idle <= 0 {
+			return ErrInvalidDuration
+		}
+		r.ClaimIdle = idle
+		return nil
+	})
+}
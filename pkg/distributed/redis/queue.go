@@ -0,0 +1,255 @@
+// Package redis provides a graph.WorkQueue backed by Redis streams.
+//
+// The tasks are appended to a stream read by the workers through a consumer group, a task is
+// acknowledged once its result is published, and the tasks left pending by an unresponsive
+// worker are claimed again by the others after WithClaimIdle; the results are appended to a
+// stream per runtime instance.
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+var _ g.WorkQueue = (*WorkQueue)(nil)
+
+// WorkQueue carries the node tasks and their results through Redis streams.
+type WorkQueue struct {
+	client goredis.UniversalClient
+	opts   Options
+
+	mu          sync.Mutex
+	groupReady  bool
+	pending     map[string]string // task ID to message ID
+	lastResults map[string]string // instance to last read message ID
+}
+
+// NewWorkQueue creates a WorkQueue on the given Redis client.
+//
+// The same queue serves the runtimes and the workers; WithConsumer names the workers, a random
+// name is used otherwise.
+//
+// Parameters:
+//   - client: The Redis client, standalone, sentinel or cluster.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - The queue.
+//   - An error if the client is nil or an option is invalid.
+//
+// Example:
+//
+//	client := goredis.NewClient(&goredis.Options{Addr: "localhost:6379"})
+//	queue, err := redis.NewWorkQueue(client, redis.WithConsumer("workers", hostname))
+//	runtime, _ := builders.CreateRuntime(startEdge, stateMonitorCh, graph.WithMemory[MyState](memory), graph.WithWorkQueue[MyState](queue))
+func NewWorkQueue(client goredis.UniversalClient, opts ...Option) (*WorkQueue, error) {
+	if client == nil {
+		return nil, fmt.Errorf("redis work queue creation failed: %w", ErrClientNil)
+	}
+
+	useOpts := Options{
+		KeyPrefix: DefaultKeyPrefix,
+		Group:     DefaultGroup,
+		Consumer:  "worker-" + g.UUID(),
+		Block:     DefaultBlock,
+		ClaimIdle: DefaultClaimIdle,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(&useOpts); err != nil {
+			return nil, fmt.Errorf("redis work queue creation failed: %w", err)
+		}
+	}
+
+	return &WorkQueue{
+		client:      client,
+		opts:        useOpts,
+		pending:     make(map[string]string),
+		lastResults: make(map[string]string),
+	}, nil
+}
+
+func (q *WorkQueue) tasksKey() string {
+	return q.opts.KeyPrefix + "tasks"
+}
+
+func (q *WorkQueue) resultsKey(instance string) string {
+	return q.opts.KeyPrefix + "results:" + instance
+}
+
+// Enqueue appends a task to the stream of the tasks.
+func (q *WorkQueue) Enqueue(ctx context.Context, task g.NodeTask) error {
+	data, err := json.Marshal(task)
+	if err != nil {
+		return fmt.Errorf("cannot encode task %s: %w", task.ID, err)
+	}
+	if err := q.client.XAdd(ctx, &goredis.XAddArgs{Stream: q.tasksKey(), Values: map[string]any{"task": data}}).Err(); err != nil {
+		return fmt.Errorf("cannot enqueue task %s: %w", task.ID, err)
+	}
+	return nil
+}
+
+// Dequeue reads the next task within the consumer group, the pending tasks of the unresponsive workers first.
+func (q *WorkQueue) Dequeue(ctx context.Context) (g.NodeTask, error) {
+	if err := q.ensureGroup(ctx); err != nil {
+		return g.NodeTask{}, err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return g.NodeTask{}, err
+		}
+
+		claimed, _, err := q.client.XAutoClaim(ctx, &goredis.XAutoClaimArgs{
+			Stream:   q.tasksKey(),
+			Group:    q.opts.Group,
+			Consumer: q.opts.Consumer,
+			MinIdle:  q.opts.ClaimIdle,
+			Start:    "0-0",
+			Count:    1,
+		}).Result()
+		if err != nil {
+			return g.NodeTask{}, readError(ctx, "cannot claim the pending tasks", err)
+		}
+		if len(claimed) > 0 {
+			return q.task(ctx, claimed[0])
+		}
+
+		streams, err := q.client.XReadGroup(ctx, &goredis.XReadGroupArgs{
+			Group:    q.opts.Group,
+			Consumer: q.opts.Consumer,
+			Streams:  []string{q.tasksKey(), ">"},
+			Count:    1,
+			Block:    q.opts.Block,
+		}).Result()
+		if errors.Is(err, goredis.Nil) {
+			continue
+		}
+		if err != nil {
+			return g.NodeTask{}, readError(ctx, "cannot read the tasks", err)
+		}
+		for _, stream := range streams {
+			for _, message := range stream.Messages {
+				return q.task(ctx, message)
+			}
+		}
+	}
+}
+
+// Reply appends the result to the stream of its runtime instance, then acknowledges the task.
+func (q *WorkQueue) Reply(ctx context.Context, result g.NodeResult) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("cannot encode the result of task %s: %w", result.TaskID, err)
+	}
+	if err := q.client.XAdd(ctx, &goredis.XAddArgs{Stream: q.resultsKey(result.Instance), Values: map[string]any{"result": data}}).Err(); err != nil {
+		return fmt.Errorf("cannot reply to task %s: %w", result.TaskID, err)
+	}
+
+	q.mu.Lock()
+	messageID, ok := q.pending[result.TaskID]
+	delete(q.pending, result.TaskID)
+	q.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	if err := q.client.XAck(ctx, q.tasksKey(), q.opts.Group, messageID).Err(); err != nil {
+		return fmt.Errorf("cannot acknowledge task %s: %w", result.TaskID, err)
+	}
+	return nil
+}
+
+// Results reads the next result of the runtime instance, removing it from its stream.
+func (q *WorkQueue) Results(ctx context.Context, instance string) (g.NodeResult, error) {
+	key := q.resultsKey(instance)
+	for {
+		if err := ctx.Err(); err != nil {
+			return g.NodeResult{}, err
+		}
+
+		q.mu.Lock()
+		lastID, ok := q.lastResults[instance]
+		q.mu.Unlock()
+		if !ok {
+			lastID = "0"
+		}
+
+		streams, err := q.client.XRead(ctx, &goredis.XReadArgs{Streams: []string{key, lastID}, Count: 1, Block: q.opts.Block}).Result()
+		if errors.Is(err, goredis.Nil) {
+			continue
+		}
+		if err != nil {
+			return g.NodeResult{}, readError(ctx, "cannot read the results", err)
+		}
+		for _, stream := range streams {
+			for _, message := range stream.Messages {
+				q.mu.Lock()
+				q.lastResults[instance] = message.ID
+				q.mu.Unlock()
+				if err := q.client.XDel(ctx, key, message.ID).Err(); err != nil {
+					return g.NodeResult{}, fmt.Errorf("cannot remove result %s: %w", message.ID, err)
+				}
+
+				var result g.NodeResult
+				if err := decode(message, "result", &result); err != nil {
+					return g.NodeResult{}, err
+				}
+				return result, nil
+			}
+		}
+	}
+}
+
+// task decodes the task of the message and tracks it until its result is published.
+func (q *WorkQueue) task(ctx context.Context, message goredis.XMessage) (g.NodeTask, error) {
+	var task g.NodeTask
+	if err := decode(message, "task", &task); err != nil {
+		// The message would be claimed again forever.
+		_ = q.client.XAck(ctx, q.tasksKey(), q.opts.Group, message.ID).Err()
+		return g.NodeTask{}, err
+	}
+	q.mu.Lock()
+	q.pending[task.ID] = message.ID
+	q.mu.Unlock()
+	return task, nil
+}
+
+func (q *WorkQueue) ensureGroup(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.groupReady {
+		return nil
+	}
+	err := q.client.XGroupCreateMkStream(ctx, q.tasksKey(), q.opts.Group, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return fmt.Errorf("cannot create consumer group %s: %w", q.opts.Group, err)
+	}
+	q.groupReady = true
+	return nil
+}
+
+// readError returns the error of the context once done, the read error otherwise.
+func readError(ctx context.Context, message string, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return ctxErr
+	}
+	return fmt.Errorf("%s: %w", message, err)
+}
+
+func decode(message goredis.XMessage, field string, target any) error {
+	data, ok := message.Values[field].(string)
+	if !ok {
+		return fmt.Errorf("%w: message %s has no %s", ErrInvalidMessage, message.ID, field)
+	}
+	if err := json.Unmarshal([]byte(data), target); err != nil {
+		return fmt.Errorf("%w: message %s: %v", ErrInvalidMessage, message.ID, err)
+	}
+	return nil
+}
@@ -0,0 +1,133 @@
+package redis_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/morphy76/ggraph/pkg/distributed/redis"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func TestNewWorkQueue_Errors(t *testing.T) {
+	if _, err := redis.NewWorkQueue(nil); !errors.Is(err, redis.ErrClientNil) {
+		t.Errorf("Expected ErrClientNil, got %v", err)
+	}
+
+	client := goredis.NewClient(&goredis.Options{Addr: "localhost:0"})
+	defer client.Close()
+	tests := []struct {
+		name string
+		opt  redis.Option
+		want error
+	}{
+		{"empty prefix", redis.WithKeyPrefix(""), redis.ErrInvalidKeyPrefix},
+		{"empty consumer", redis.WithConsumer("workers", ""), redis.ErrInvalidConsumer},
+		{"invalid block", redis.WithBlock(0), redis.ErrInvalidDuration},
+		{"invalid claim idle", redis.WithClaimIdle(-time.Second), redis.ErrInvalidDuration},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := redis.NewWorkQueue(client, tt.opt); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func newTestQueue(t *testing.T, server *miniredis.Miniredis, consumer string, opts ...redis.Option) *redis.WorkQueue {
+	t.Helper()
+	client := goredis.NewClient(&goredis.Options{Addr: server.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { client.Close() })
+
+	useOpts := append([]redis.Option{redis.WithKeyPrefix("test:"), redis.WithConsumer("workers", consumer), redis.WithBlock(10 * time.Millisecond)}, opts...)
+	queue, err := redis.NewWorkQueue(client, useOpts...)
+	if err != nil {
+		t.Fatalf("NewWorkQueue failed: %v", err)
+	}
+	return queue
+}
+
+func TestWorkQueue(t *testing.T) {
+	server := miniredis.RunT(t)
+	queue := newTestQueue(t, server, "worker-1")
+	ctx := context.Background()
+
+	task := g.NodeTask{ID: "task-1", Instance: "runtime-1", ThreadID: "thread", Node: "Research", UserInput: []byte(`{"q":"a"}`)}
+	if err := queue.Enqueue(ctx, task); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+
+	got, err := queue.Dequeue(ctx)
+	if err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+	if got.ID != task.ID || got.Node != task.Node || string(got.UserInput) != string(task.UserInput) {
+		t.Errorf("Expected %+v, got %+v", task, got)
+	}
+
+	result := g.NodeResult{TaskID: got.ID, Instance: got.Instance, ThreadID: got.ThreadID, Node: got.Node, State: []byte(`{"a":1}`)}
+	if err := queue.Reply(ctx, result); err != nil {
+		t.Fatalf("Reply failed: %v", err)
+	}
+	if !server.Exists("test:results:runtime-1") {
+		t.Error("Expected the result in the stream of the instance")
+	}
+
+	received, err := queue.Results(ctx, "runtime-1")
+	if err != nil {
+		t.Fatalf("Results failed: %v", err)
+	}
+	if received.TaskID != task.ID || string(received.State) != `{"a":1}` {
+		t.Errorf("Expected the result of the task, got %+v", received)
+	}
+
+	// The task is acknowledged, it is not delivered again.
+	shortCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := queue.Dequeue(shortCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected no task, got %v", err)
+	}
+	if _, err := queue.Results(shortCtx, "runtime-1"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected no result, got %v", err)
+	}
+}
+
+func TestWorkQueue_ClaimsStaleTasks(t *testing.T) {
+	server := miniredis.RunT(t)
+	crashed := newTestQueue(t, server, "worker-1")
+	survivor := newTestQueue(t, server, "worker-2", redis.WithClaimIdle(20*time.Millisecond))
+	ctx := context.Background()
+
+	if err := crashed.Enqueue(ctx, g.NodeTask{ID: "task-1", Instance: "runtime-1", Node: "Research"}); err != nil {
+		t.Fatalf("Enqueue failed: %v", err)
+	}
+	if _, err := crashed.Dequeue(ctx); err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+
+	time.Sleep(30 * time.Millisecond)
+	claimed, err := survivor.Dequeue(ctx)
+	if err != nil {
+		t.Fatalf("Dequeue failed: %v", err)
+	}
+	if claimed.ID != "task-1" {
+		t.Errorf("Expected the stale task to be claimed, got %+v", claimed)
+	}
+}
+
+func TestWorkQueue_InvalidMessage(t *testing.T) {
+	server := miniredis.RunT(t)
+	queue := newTestQueue(t, server, "worker-1")
+
+	if _, err := server.XAdd("test:tasks", "*", []string{"other", "value"}); err != nil {
+		t.Fatalf("XAdd failed: %v", err)
+	}
+	if _, err := queue.Dequeue(context.Background()); !errors.Is(err, redis.ErrInvalidMessage) {
+		t.Errorf("Expected ErrInvalidMessage, got %v", err)
+	}
+}
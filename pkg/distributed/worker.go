@@ -0,0 +1,252 @@
+package distributed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// Worker executes the node tasks published by the runtimes to a work queue.
+type Worker[T g.SharedState] struct {
+	queue     g.WorkQueue
+	restoreFn g.RestoreFn[T]
+	onceStore g.OnceStore
+	nodes     map[string]g.Node[T]
+	opts      Options
+	logger    *slog.Logger
+}
+
+// NewWorker creates a worker executing the given nodes.
+//
+// The nodes are built like the ones of the runtime, a worker receiving a task for a node it
+// does not hold replies with ErrUnknownNode. The side effects executed with graph.Once are
+// recorded by the memory, when it implements graph.OnceStore.
+//
+// Parameters:
+//   - queue: The work queue shared with the runtimes.
+//   - memory: The memory shared with the runtimes, the states of the threads are restored from it.
+//   - nodes: The nodes executed by the worker.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - The worker.
+//   - An error if an argument or an option is invalid.
+//
+// Example:
+//
+//	research, _ := builders.NewNode("Research", researchFn)
+//	worker, err := distributed.NewWorker(queue, memory, []graph.Node[MyState]{research}, distributed.WithConcurrency(8))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	worker.Run(ctx)
+func NewWorker[T g.SharedState](queue g.WorkQueue, memory g.Memory[T], nodes []g.Node[T], opts ...Option) (*Worker[T], error) {
+	if queue == nil {
+		return nil, fmt.Errorf("worker creation failed: %w", g.ErrWorkQueueNil)
+	}
+	if memory == nil {
+		return nil, fmt.Errorf("worker creation failed: %w", ErrMemoryNil)
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("worker creation failed: %w", ErrNoNodes)
+	}
+
+	byName := make(map[string]g.Node[T], len(nodes))
+	for _, node := range nodes {
+		if _, ok := byName[node.Name()]; ok {
+			return nil, fmt.Errorf("worker creation failed: %w: %s", ErrDuplicateNode, node.Name())
+		}
+		byName[node.Name()] = node
+	}
+
+	useOpts := Options{Concurrency: DefaultConcurrency, RetryDelay: DefaultRetryDelay}
+	for _, opt := range opts {
+		if err := opt.Apply(&useOpts); err != nil {
+			return nil, fmt.Errorf("worker creation failed: %w", err)
+		}
+	}
+	logger := useOpts.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	onceStore, _ := memory.(g.OnceStore)
+
+	return &Worker[T]{
+		queue:     queue,
+		restoreFn: memory.RestoreFn(),
+		onceStore: onceStore,
+		nodes:     byName,
+		opts:      useOpts,
+		logger:    logger,
+	}, nil
+}
+
+// Run executes the tasks of the queue until the context is done.
+//
+// Parameters:
+//   - ctx: Context whose cancellation stops the worker, the running tasks are abandoned.
+//
+// Example:
+//
+//	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+//	defer stop()
+//	worker.Run(ctx)
+func (w *Worker[T]) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	for range w.opts.Concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			w.loop(ctx)
+		}()
+	}
+	wg.Wait()
+}
+
+func (w *Worker[T]) loop(ctx context.Context) {
+	for {
+		task, err := w.queue.Dequeue(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			w.logger.Warn("work queue not readable", "error", err)
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(w.opts.RetryDelay):
+			}
+			continue
+		}
+
+		result := w.Execute(ctx, task)
+		if err := w.queue.Reply(ctx, result); err != nil {
+			w.logger.Error("result not published", "thread_id", task.ThreadID, "node", task.Node, "error", err)
+		}
+	}
+}
+
+// Execute executes a task, Run calls it for every task of the queue.
+//
+// Parameters:
+//   - ctx: The context of the execution.
+//   - task: The task to execute.
+//
+// Returns:
+//   - The result of the task, carrying the error of the execution if any.
+func (w *Worker[T]) Execute(ctx context.Context, task g.NodeTask) g.NodeResult {
+	startedAt := time.Now()
+	result := g.NodeResult{TaskID: task.ID, Instance: task.Instance, ThreadID: task.ThreadID, Node: task.Node}
+
+	state, usage, err := w.execute(ctx, task)
+	result.Usage = usage
+	if err == nil {
+		result.State, err = json.Marshal(state)
+	}
+	if err != nil {
+		result.Code = g.ErrorCodeOf(err)
+		var nodeErr *g.NodeError
+		if errors.As(err, &nodeErr) {
+			// The runtime wraps the error in its own NodeError.
+			err = nodeErr.Err
+		}
+		result.Error = err.Error()
+		w.logger.Warn("task failed", "thread_id", task.ThreadID, "node", task.Node, "error", err, "duration", time.Since(startedAt))
+		return result
+	}
+	w.logger.Info("task executed", "thread_id", task.ThreadID, "node", task.Node, "duration", time.Since(startedAt))
+	return result
+}
+
+func (w *Worker[T]) execute(ctx context.Context, task g.NodeTask) (T, g.Usage, error) {
+	var zero T
+	var usage g.Usage
+
+	node, ok := w.nodes[task.Node]
+	if !ok {
+		return zero, usage, fmt.Errorf("cannot execute node %s: %w", task.Node, g.ErrUnknownNode)
+	}
+	var userInput T
+	if err := json.Unmarshal(task.UserInput, &userInput); err != nil {
+		return zero, usage, fmt.Errorf("cannot decode the user input: %w", err)
+	}
+	state, err := w.restoreFn(ctx, task.ThreadID)
+	if err != nil {
+		return zero, usage, &g.PersistenceError{Node: task.Node, ThreadID: task.ThreadID, Code: g.CodeRestoreFailed, Err: err}
+	}
+
+	var usageMu sync.Mutex
+	nodeCtx := g.ContextWithUsageReporter(g.ContextWithThreadID(ctx, task.ThreadID), func(reported g.Usage) {
+		usageMu.Lock()
+		defer usageMu.Unlock()
+		usage = usage.Add(reported)
+	})
+	if task.Tenant != "" {
+		nodeCtx = g.ContextWithTenant(nodeCtx, task.Tenant)
+	}
+	if w.onceStore != nil {
+		nodeCtx = g.ContextWithOnceStore(nodeCtx, w.onceStore)
+	}
+
+	observer := &taskObserver[T]{state: state, outcome: make(chan taskOutcome[T], 1)}
+	node.Accept(userInput, observer, goroutineExecutor{}, g.InvokeConfig{ThreadID: task.ThreadID, Tenant: task.Tenant, Context: nodeCtx})
+
+	select {
+	case outcome := <-observer.outcome:
+		usageMu.Lock()
+		defer usageMu.Unlock()
+		return outcome.state, usage, outcome.err
+	case <-ctx.Done():
+		return zero, usage, ctx.Err()
+	}
+}
+
+type taskOutcome[T g.SharedState] struct {
+	state T
+	err   error
+}
+
+// taskObserver is the StateObserver of a node executed by a worker, it reduces the final update of the node.
+type taskObserver[T g.SharedState] struct {
+	state   T
+	outcome chan taskOutcome[T]
+}
+
+func (o *taskObserver[T]) NotifyStateChange(node g.Node[T], config g.InvokeConfig, userInput, stateChange T, reducer g.ReducerFn[T], err error, partial bool) {
+	if partial {
+		return
+	}
+	if err != nil {
+		o.outcome <- taskOutcome[T]{err: err}
+		return
+	}
+	if reducer == nil {
+		o.outcome <- taskOutcome[T]{state: stateChange}
+		return
+	}
+	o.outcome <- taskOutcome[T]{state: reducer(o.state, stateChange)}
+}
+
+func (o *taskObserver[T]) CurrentState(threadID string) T {
+	return o.state
+}
+
+func (o *taskObserver[T]) InitialState() T {
+	var zero T
+	return zero
+}
+
+// goroutineExecutor executes every submitted task in its own goroutine.
+type goroutineExecutor struct{}
+
+func (goroutineExecutor) Submit(task func()) {
+	go task()
+}
@@ -0,0 +1,194 @@
+package distributed_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morphy76/ggraph/pkg/builders"
+	"github.com/morphy76/ggraph/pkg/distributed"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+type workerTestState struct {
+	Steps []string
+}
+
+func workerTestNode(t *testing.T, fn g.ContextNodeFn[workerTestState]) g.Node[workerTestState] {
+	node, err := builders.NewContextNode("Research", fn)
+	if err != nil {
+		t.Fatalf("NewContextNode() failed: %v", err)
+	}
+	return node
+}
+
+// startWorkerTestRuntime starts a runtime delegating the Research node to a worker holding remoteFn.
+func startWorkerTestRuntime(t *testing.T, remoteFn g.ContextNodeFn[workerTestState]) (g.Runtime[workerTestState], g.Memory[workerTestState], chan g.StateMonitorEntry[workerTestState]) {
+	t.Helper()
+	memory := builders.NewMemMemory[workerTestState]()
+	queue, err := distributed.NewMemoryQueue(10)
+	if err != nil {
+		t.Fatalf("NewMemoryQueue() failed: %v", err)
+	}
+
+	local := workerTestNode(t, func(ctx context.Context, userInput, currentState workerTestState, notify g.NotifyPartialFn[workerTestState]) (workerTestState, error) {
+		return currentState, errors.New("executed by the runtime")
+	})
+	stateMonitorCh := make(chan g.StateMonitorEntry[workerTestState], 10)
+	runtime, err := builders.CreateRuntime(builders.CreateStartEdge(local), stateMonitorCh, g.WithMemory(memory), g.WithWorkQueue[workerTestState](queue))
+	if err != nil {
+		t.Fatalf("CreateRuntime() failed: %v", err)
+	}
+	runtime.AddEdge(builders.CreateEndEdge(local))
+	t.Cleanup(runtime.Shutdown)
+
+	worker, err := distributed.NewWorker(queue, memory, []g.Node[workerTestState]{workerTestNode(t, remoteFn)}, distributed.WithConcurrency(2))
+	if err != nil {
+		t.Fatalf("NewWorker() failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		worker.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	return runtime, memory, stateMonitorCh
+}
+
+func waitWorkerTestEntry(t *testing.T, stateMonitorCh chan g.StateMonitorEntry[workerTestState]) g.StateMonitorEntry[workerTestState] {
+	t.Helper()
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case entry := <-stateMonitorCh:
+			if !entry.Running {
+				return entry
+			}
+		case <-timeout:
+			t.Fatal("Timeout waiting for the graph completion")
+		}
+	}
+}
+
+// TestWorker_ExecutesRemoteNode tests that the node is executed by the worker on the persisted state
+func TestWorker_ExecutesRemoteNode(t *testing.T) {
+	runtime, memory, stateMonitorCh := startWorkerTestRuntime(t, func(ctx context.Context, userInput, currentState workerTestState, notify g.NotifyPartialFn[workerTestState]) (workerTestState, error) {
+		g.ReportUsage(ctx, g.Usage{PromptTokens: 3, CompletionTokens: 2, TotalTokens: 5})
+		threadID, _ := g.ThreadIDFromContext(ctx)
+		currentState.Steps = append(currentState.Steps, strings.Join(userInput.Steps, ",")+"@"+threadID)
+		return currentState, nil
+	})
+
+	threadID := runtime.Invoke(workerTestState{Steps: []string{"a"}})
+	entry := waitWorkerTestEntry(t, stateMonitorCh)
+	if entry.Error != nil {
+		t.Fatalf("Unexpected error: %v", entry.Error)
+	}
+	if len(entry.NewState.Steps) != 1 || entry.NewState.Steps[0] != "a@"+threadID {
+		t.Errorf("Expected the state reduced by the worker, got %v", entry.NewState.Steps)
+	}
+	if entry.Usage.TotalTokens != 5 {
+		t.Errorf("Expected the usage reported by the worker, got %+v", entry.Usage)
+	}
+
+	// The final state is persisted in the background.
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if state, _ := memory.RestoreFn()(context.Background(), threadID); len(state.Steps) == 1 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timeout waiting for the persistence of the final state")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	runtime.Invoke(workerTestState{Steps: []string{"b"}}, g.InvokeConfig{ThreadID: threadID})
+	entry = waitWorkerTestEntry(t, stateMonitorCh)
+	if len(entry.NewState.Steps) != 2 || entry.NewState.Steps[1] != "b@"+threadID {
+		t.Errorf("Expected the worker to restore the state of the thread, got %v", entry.NewState.Steps)
+	}
+}
+
+// TestWorker_PropagatesNodeError tests that the error of the remote node fails the invocation with its code
+func TestWorker_PropagatesNodeError(t *testing.T) {
+	runtime, _, stateMonitorCh := startWorkerTestRuntime(t, func(ctx context.Context, userInput, currentState workerTestState, notify g.NotifyPartialFn[workerTestState]) (workerTestState, error) {
+		return currentState, errors.New("boom")
+	})
+
+	runtime.Invoke(workerTestState{})
+	entry := waitWorkerTestEntry(t, stateMonitorCh)
+	if entry.Error == nil || !strings.Contains(entry.Error.Error(), "boom") {
+		t.Fatalf("Expected the error of the worker, got %v", entry.Error)
+	}
+	if strings.Contains(entry.Error.Error(), "executed by the runtime") {
+		t.Error("Expected the node not to be executed by the runtime")
+	}
+	if entry.ErrorCode != g.CodeNodeFailed {
+		t.Errorf("Expected %s, got %s", g.CodeNodeFailed, entry.ErrorCode)
+	}
+}
+
+// TestWorker_UnknownNode tests that a task for a node not held by the worker fails
+func TestWorker_UnknownNode(t *testing.T) {
+	queue, _ := distributed.NewMemoryQueue(1)
+	worker, err := distributed.NewWorker(queue, builders.NewMemMemory[workerTestState](), []g.Node[workerTestState]{workerTestNode(t, nil)})
+	if err != nil {
+		t.Fatalf("NewWorker() failed: %v", err)
+	}
+
+	result := worker.Execute(context.Background(), g.NodeTask{ID: "task", ThreadID: "thread", Node: "Other", UserInput: []byte("{}")})
+	if result.TaskID != "task" || !strings.Contains(result.Error, g.ErrUnknownNode.Error()) {
+		t.Errorf("Expected ErrUnknownNode, got %+v", result)
+	}
+}
+
+// TestNewWorker_Errors tests the validation of the worker arguments
+func TestNewWorker_Errors(t *testing.T) {
+	queue, _ := distributed.NewMemoryQueue(1)
+	memory := builders.NewMemMemory[workerTestState]()
+	node := workerTestNode(t, nil)
+
+	tests := []struct {
+		name   string
+		queue  g.WorkQueue
+		memory g.Memory[workerTestState]
+		nodes  []g.Node[workerTestState]
+		opts   []distributed.Option
+		want   error
+	}{
+		{"nil queue", nil, memory, []g.Node[workerTestState]{node}, nil, g.ErrWorkQueueNil},
+		{"nil memory", queue, nil, []g.Node[workerTestState]{node}, nil, distributed.ErrMemoryNil},
+		{"no nodes", queue, memory, nil, nil, distributed.ErrNoNodes},
+		{"duplicate nodes", queue, memory, []g.Node[workerTestState]{node, node}, nil, distributed.ErrDuplicateNode},
+		{"invalid concurrency", queue, memory, []g.Node[workerTestState]{node}, []distributed.Option{distributed.WithConcurrency(0)}, distributed.ErrInvalidConcurrency},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := distributed.NewWorker(tt.queue, tt.memory, tt.nodes, tt.opts...); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+
+	if _, err := distributed.NewMemoryQueue(0); !errors.Is(err, distributed.ErrInvalidCapacity) {
+		t.Errorf("Expected ErrInvalidCapacity, got %v", err)
+	}
+}
+
+// TestWithWorkQueue_RequiresMemory tests that a runtime cannot delegate the nodes without a shared memory
+func TestWithWorkQueue_RequiresMemory(t *testing.T) {
+	queue, _ := distributed.NewMemoryQueue(1)
+	node := workerTestNode(t, nil)
+	_, err := builders.CreateRuntime(builders.CreateStartEdge(node), make(chan g.StateMonitorEntry[workerTestState], 1), g.WithWorkQueue[workerTestState](queue))
+	if !errors.Is(err, g.ErrWorkQueueWithoutMemory) {
+		t.Errorf("Expected ErrWorkQueueWithoutMemory, got %v", err)
+	}
+}
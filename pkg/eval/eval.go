@@ -0,0 +1,224 @@
+// Package eval runs a graph over a dataset of cases and scores its outputs.
+//
+// Each Case is invoked on its own thread, its final state is graded by the scorers, e.g.
+// ExactMatch, Contains or the LLM-as-judge Judge, and the Report aggregates the pass rate with
+// the latency, the usage and the cost of every node, so that a change of prompt, model or
+// topology is measured before it ships. Simulate drives multi-turn agents with synthetic users,
+// scripted or played by a model following a persona, and scores their transcripts. CompareGolden records the final states of a deterministic
+// run, e.g. on the mock provider, to golden files and diffs the later runs against them in the CI.
+//
+// Example:
+//
+//	dataset := []eval.Case[a.Conversation]{
+//	    {Name: "capital", Input: question("What is the capital of Italy?"), Expected: "Rome"},
+//	}
+//	report, err := eval.Run(ctx, runtime, dataset,
+//	    eval.WithScorers(eval.Contains("answer", eval.LastReply)),
+//	    eval.WithPricing[a.Conversation](g.Pricing{PromptPerMillion: 0.15, CompletionPerMillion: 0.6}),
+//	)
+//	fmt.Printf("pass rate %.0f%%, cost %.4f\n", report.PassRate()*100, report.Cost)
+package eval
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+var (
+	// ErrRuntimeNil indicates that no runtime was given to run the dataset.
+	ErrRuntimeNil = errors.New("runtime cannot be nil")
+	// ErrEmptyDataset indicates that the dataset has no case.
+	ErrEmptyDataset = errors.New("dataset cannot be empty")
+	// ErrScorerNil indicates that a nil scorer was given.
+	ErrScorerNil = errors.New("scorer cannot be nil")
+	// ErrInvalidConcurrency indicates that the number of concurrent cases is not positive.
+	ErrInvalidConcurrency = errors.New("concurrency must be positive")
+	// ErrInvalidTimeout indicates that the timeout of the cases is negative.
+	ErrInvalidTimeout = errors.New("timeout cannot be negative")
+	// ErrRuntimeShutdown indicates that the runtime shut down before a case terminated.
+	ErrRuntimeShutdown = errors.New("runtime shut down before the case terminated")
+)
+
+// Case is an entry of the dataset.
+type Case[T g.SharedState] struct {
+	// Name identifies the case in the report.
+	Name string
+	// Input is the user input the graph is invoked with.
+	Input T
+	// Expected is the expected output, compared by the scorers; empty when the scorers need none.
+	Expected string
+	// Metadata are passed to the invocation, see graph.InvokeConfigMetadata.
+	Metadata map[string]string
+}
+
+// Score is the grade given by a scorer to the output of a case.
+type Score struct {
+	// Scorer is the name of the scorer.
+	Scorer string
+	// Value is the grade, from 0 to 1.
+	Value float64
+	// Pass reports whether the grade reaches the threshold of the scorer.
+	Pass bool
+	// Reason explains the grade, when the scorer provides one.
+	Reason string
+}
+
+// ScorerFn grades the final state of a case.
+//
+// Parameters:
+//   - ctx: The context of the evaluation.
+//   - c: The case.
+//   - output: The final state of the invocation of the case.
+//
+// Returns:
+//   - The score.
+//   - An error if the output cannot be graded, e.g. a failed judge request.
+type ScorerFn[T g.SharedState] func(ctx context.Context, c Case[T], output T) (Score, error)
+
+// CaseResult is the outcome of a case.
+type CaseResult[T g.SharedState] struct {
+	// Case is the evaluated case.
+	Case Case[T]
+	// ThreadID is the thread the case was invoked on.
+	ThreadID string
+	// Output is the final state of the invocation.
+	Output T
+	// Error is the error the invocation or a scorer failed with, nil otherwise.
+	Error error
+	// Scores are the grades of the scorers, in their order.
+	Scores []Score
+	// Pass reports whether the invocation succeeded and every scorer passed.
+	Pass bool
+	// Duration is the time from the invocation to its termination.
+	Duration time.Duration
+	// Usage is the usage reported by the nodes of the invocation.
+	Usage g.Usage
+	// Cost is the cost of the usage, see WithPricing.
+	Cost float64
+	// Spans are the executions of the nodes, from the timeline of the thread.
+	Spans []g.TimelineSpan
+	// NodeUsage is the usage reported by each node.
+	NodeUsage map[string]g.Usage
+	// Turns are the turns of a simulated session, see Simulate; empty for the cases of Run.
+	Turns []Turn
+}
+
+// NodeStats aggregates the executions of a node over the dataset.
+type NodeStats struct {
+	// Node is the name of the node.
+	Node string
+	// Executions is the number of executions of the node.
+	Executions int
+	// Failures is the number of executions which failed.
+	Failures int
+	// TotalLatency is the sum of the durations of the executions.
+	TotalLatency time.Duration
+	// P95Latency is the 95th percentile of the durations of the executions.
+	P95Latency time.Duration
+	// MaxLatency is the longest execution.
+	MaxLatency time.Duration
+	// Usage is the usage reported by the node.
+	Usage g.Usage
+	// Cost is the cost of the usage of the node.
+	Cost float64
+}
+
+// AverageLatency returns the average duration of the executions of the node.
+func (s NodeStats) AverageLatency() time.Duration {
+	if s.Executions > 0 {
+		return s.TotalLatency / time.Duration(s.Executions)
+	}
+	return 0
+}
+
+// Report is the outcome of the evaluation of a dataset.
+type Report[T g.SharedState] struct {
+	// Cases are the results of the cases, in the order of the dataset.
+	Cases []CaseResult[T]
+	// Passed is the number of cases which passed.
+	Passed int
+	// Errored is the number of cases whose invocation or scoring failed.
+	Errored int
+	// Nodes are the aggregates of the nodes, sorted by name.
+	Nodes []NodeStats
+	// Turns is the aggregate of the turns of the simulated sessions, see Simulate.
+	Turns TurnStats
+	// Usage is the usage of all the cases.
+	Usage g.Usage
+	// Cost is the cost of all the cases.
+	Cost float64
+	// Duration is the time taken by the whole evaluation.
+	Duration time.Duration
+}
+
+// PassRate returns the share of the cases which passed, from 0 to 1.
+func (r Report[T]) PassRate() float64 {
+	if len(r.Cases) > 0 {
+		return float64(r.Passed) / float64(len(r.Cases))
+	}
+	return 0
+}
+
+// AverageScore returns the average grade of a scorer over the cases it graded.
+//
+// Parameters:
+//   - scorer: The name of the scorer.
+//
+// Returns:
+//   - The average grade, from 0 to 1.
+//   - false if the scorer graded no case.
+func (r Report[T]) AverageScore(scorer string) (float64, bool) {
+	var total float64
+	var count int
+	for _, result := range r.Cases {
+		for _, score := range result.Scores {
+			if score.Scorer == scorer {
+				total += score.Value
+				count++
+			}
+		}
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return total / float64(count), true
+}
+
+// Node returns the aggregates of a node.
+//
+// Parameters:
+//   - name: The name of the node.
+//
+// Returns:
+//   - The aggregates of the node.
+//   - false if the node never executed.
+func (r Report[T]) Node(name string) (NodeStats, bool) {
+	idx := slices.IndexFunc(r.Nodes, func(s NodeStats) bool { return s.Node == name })
+	if idx == -1 {
+		return NodeStats{}, false
+	}
+	return r.Nodes[idx], true
+}
+
+// LastReply extracts the content of the last assistant message of a conversation, the usual
+// output graded by the scorers of the agents.
+//
+// Parameters:
+//   - conversation: The final state of the invocation.
+//
+// Returns:
+//   - The content of the last assistant message, empty if there is none.
+func LastReply(conversation a.Conversation) string {
+	for i := len(conversation.Messages) - 1; i >= 0; i-- {
+		if conversation.Messages[i].Role == a.Assistant {
+			return strings.TrimSpace(conversation.Messages[i].Content)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,131 @@
+package eval_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/builders"
+	"github.com/morphy76/ggraph/pkg/eval"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// createRuntime creates a graph answering with the upper case question, failing on "fail".
+func createRuntime(t *testing.T) g.Runtime[a.Conversation] {
+	t.Helper()
+	answer, err := builders.NewContextNode("Answer", func(ctx context.Context, userInput, currentState a.Conversation, notify g.NotifyPartialFn[a.Conversation]) (a.Conversation, error) {
+		question := userInput.Messages[len(userInput.Messages)-1].Content
+		if question == "fail" {
+			return currentState, errors.New("cannot answer")
+		}
+		time.Sleep(5 * time.Millisecond)
+		g.ReportUsage(ctx, g.Usage{PromptTokens: 100, CompletionTokens: 10, TotalTokens: 110})
+		rv := currentState.Clone()
+		rv.Messages = append(rv.Messages, a.CreateMessage(a.Assistant, strings.ToUpper(question)))
+		return rv, nil
+	})
+	if err != nil {
+		t.Fatalf("NewContextNode failed: %v", err)
+	}
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[a.Conversation], 100)
+	go func() {
+		for range stateMonitorCh {
+		}
+	}()
+	runtime, err := builders.CreateRuntime(builders.CreateStartEdge(answer), stateMonitorCh)
+	if err != nil {
+		t.Fatalf("CreateRuntime failed: %v", err)
+	}
+	t.Cleanup(runtime.Shutdown)
+	runtime.AddEdge(builders.CreateEndEdge(answer))
+	if err := runtime.Validate(); err != nil {
+		t.Fatalf("Validate failed: %v", err)
+	}
+	return runtime
+}
+
+func question(text string) a.Conversation {
+	return a.CreateConversation(a.CreateMessage(a.User, text))
+}
+
+func TestRun(t *testing.T) {
+	runtime := createRuntime(t)
+	dataset := []eval.Case[a.Conversation]{
+		{Name: "match", Input: question("hello"), Expected: "HELLO"},
+		{Name: "mismatch", Input: question("bye"), Expected: "HELLO"},
+		{Name: "failure", Input: question("fail"), Expected: "FAIL"},
+	}
+
+	report, err := eval.Run(context.Background(), runtime, dataset,
+		eval.WithScorers(eval.ExactMatch("exact", eval.LastReply)),
+		eval.WithPricing[a.Conversation](g.Pricing{PromptPerMillion: 1_000, CompletionPerMillion: 10_000}),
+		eval.WithConcurrency[a.Conversation](2),
+	)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if len(report.Cases) != 3 || report.Passed != 1 || report.Errored != 1 {
+		t.Fatalf("Expected 1 passed and 1 errored case out of 3, got %d and %d out of %d", report.Passed, report.Errored, len(report.Cases))
+	}
+	if rate := report.PassRate(); rate < 0.33 || rate > 0.34 {
+		t.Errorf("Expected a pass rate of 1/3, got %f", rate)
+	}
+	if result := report.Cases[0]; !result.Pass || eval.LastReply(result.Output) != "HELLO" || !strings.HasPrefix(result.ThreadID, eval.DefaultThreadPrefix) {
+		t.Errorf("Expected the first case to pass on an eval thread, got %+v", result)
+	}
+	if result := report.Cases[1]; result.Pass || result.Error != nil || len(result.Scores) != 1 || result.Scores[0].Reason == "" {
+		t.Errorf("Expected the second case to fail its score with a reason, got %+v", result)
+	}
+	if result := report.Cases[2]; result.Pass || result.Error == nil {
+		t.Errorf("Expected the third case to fail with the node error, got %+v", result)
+	}
+	if average, ok := report.AverageScore("exact"); !ok || average != 0.5 {
+		t.Errorf("Expected an average score of 0.5 over the scored cases, got %f", average)
+	}
+
+	node, ok := report.Node("Answer")
+	if !ok {
+		t.Fatalf("Expected the aggregates of the Answer node, got %+v", report.Nodes)
+	}
+	if node.Executions != 3 || node.Failures != 1 {
+		t.Errorf("Expected 3 executions with 1 failure, got %+v", node)
+	}
+	if node.AverageLatency() <= 0 || node.P95Latency < 5*time.Millisecond || node.MaxLatency < node.P95Latency {
+		t.Errorf("Expected the latencies of the node, got %+v", node)
+	}
+	if node.Usage.TotalTokens != 220 || report.Usage.TotalTokens != 220 {
+		t.Errorf("Expected 220 tokens for the 2 answered cases, got %d and %d", node.Usage.TotalTokens, report.Usage.TotalTokens)
+	}
+	if node.Cost < 0.399 || node.Cost > 0.401 || report.Cost < 0.399 || report.Cost > 0.401 {
+		t.Errorf("Expected a cost of 0.4, got %f and %f", node.Cost, report.Cost)
+	}
+}
+
+func TestRun_Validation(t *testing.T) {
+	runtime := createRuntime(t)
+	dataset := []eval.Case[a.Conversation]{{Input: question("hello")}}
+
+	if _, err := eval.Run(context.Background(), nil, dataset); !errors.Is(err, eval.ErrRuntimeNil) {
+		t.Errorf("Expected ErrRuntimeNil, got %v", err)
+	}
+	if _, err := eval.Run(context.Background(), runtime, nil); !errors.Is(err, eval.ErrEmptyDataset) {
+		t.Errorf("Expected ErrEmptyDataset, got %v", err)
+	}
+	if _, err := eval.Run(context.Background(), runtime, dataset, eval.WithConcurrency[a.Conversation](0)); !errors.Is(err, eval.ErrInvalidConcurrency) {
+		t.Errorf("Expected ErrInvalidConcurrency, got %v", err)
+	}
+	if _, err := eval.Run(context.Background(), runtime, dataset, eval.WithScorers[a.Conversation](nil)); !errors.Is(err, eval.ErrScorerNil) {
+		t.Errorf("Expected ErrScorerNil, got %v", err)
+	}
+
+	// Without scorers a case passes when its invocation succeeds
+	report, err := eval.Run(context.Background(), runtime, dataset)
+	if err != nil || report.Passed != 1 {
+		t.Errorf("Expected the case to pass without scorers, got %+v and %v", report, err)
+	}
+}
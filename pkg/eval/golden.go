@@ -0,0 +1,303 @@
+package eval
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// EnvUpdateGolden is the environment variable rewriting the golden files instead of comparing them,
+// unless WithGoldenUpdate is given, e.g. GGRAPH_UPDATE_GOLDEN=true go test ./... after an intended change.
+const EnvUpdateGolden = "GGRAPH_UPDATE_GOLDEN"
+
+var (
+	// ErrGoldenNotFound indicates that the golden file to compare with does not exist.
+	ErrGoldenNotFound = errors.New("golden file not found")
+	// ErrGoldenMismatch indicates that the outputs differ from the golden file.
+	ErrGoldenMismatch = errors.New("outputs differ from the golden file")
+	// ErrInvalidCaseName indicates that a case of a golden comparison has no name or a duplicate one.
+	ErrInvalidCaseName = errors.New("golden cases require a unique name")
+	// ErrNormalizerNil indicates that a nil normalizer was given.
+	ErrNormalizerNil = errors.New("normalizer cannot be nil")
+)
+
+// NormalizeFn removes the nondeterministic fields of a final state before it is recorded or
+// compared, e.g. the identifiers and the timestamps; it must not modify the given state.
+type NormalizeFn[T g.SharedState] func(output T) T
+
+// GoldenOptions holds the configuration for a golden comparison.
+type GoldenOptions[T g.SharedState] struct {
+	// Update rewrites the golden file with the current outputs instead of comparing them.
+	Update bool
+	// Normalizers are applied in order to the outputs before they are recorded or compared.
+	Normalizers []NormalizeFn[T]
+}
+
+// GoldenOption is a functional option for configuring a golden comparison.
+type GoldenOption[T g.SharedState] interface {
+	// Apply applies the option to the GoldenOptions.
+	//
+	// Parameters:
+	//   - r: A pointer to GoldenOptions to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *GoldenOptions[T]) error
+}
+
+// GoldenOptionFunc is a function type that implements the GoldenOption interface.
+type GoldenOptionFunc[T g.SharedState] func(*GoldenOptions[T]) error
+
+// Apply applies the GoldenOptionFunc to the given GoldenOptions.
+//
+// Parameters:
+//   - r: A pointer to GoldenOptions to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s GoldenOptionFunc[T]) Apply(r *GoldenOptions[T]) error { return s(r) }
+
+// WithNormalizer adds a normalizer of the outputs, e.g. NormalizeConversation.
+//
+// Parameters:
+//   - normalize: The normalizer.
+//
+// Returns:
+//   - A GoldenOption that adds the normalizer.
+func WithNormalizer[T g.SharedState](normalize NormalizeFn[T]) GoldenOption[T] {
+	return GoldenOptionFunc[T](func(r *GoldenOptions[T]) error {
+		if normalize == nil {
+			return ErrNormalizerNil
+		}
+		r.Normalizers = append(r.Normalizers, normalize)
+		return nil
+	})
+}
+
+// WithGoldenUpdate sets whether the golden file is rewritten, overriding the EnvUpdateGolden variable.
+//
+// Parameters:
+//   - update: true to rewrite the golden file, false to compare with it.
+//
+// Returns:
+//   - A GoldenOption that sets the update mode.
+func WithGoldenUpdate[T g.SharedState](update bool) GoldenOption[T] {
+	return GoldenOptionFunc[T](func(r *GoldenOptions[T]) error {
+		r.Update = update
+		return nil
+	})
+}
+
+// GoldenMismatch is a case whose output differs from the golden file.
+type GoldenMismatch struct {
+	// Case is the name of the case.
+	Case string
+	// Changes are the fields of the golden output changed by the current one; a case missing from
+	// either side is reported as a single change of the whole output.
+	Changes []g.StateChange
+}
+
+// goldenCase is the JSON representation of the outcome of a case in a golden file.
+type goldenCase[T g.SharedState] struct {
+	Output T      `json:"output"`
+	Error  string `json:"error,omitempty"`
+}
+
+// goldenFile is the JSON representation of a golden file, the cases by name.
+type goldenFile[T g.SharedState] struct {
+	Cases map[string]goldenCase[T] `json:"cases"`
+}
+
+// CompareGolden compares the outputs of a report with a golden file, to catch the regressions of
+// the prompts or the topology of a graph in the CI.
+//
+// The final states of the cases, normalized, and their errors are recorded by case name. The
+// graph must run deterministically: replace the providers with the mock package or a cassette
+// replay, or fix their seed and temperature, and remove the remaining nondeterministic fields with
+// the normalizers. When the update mode is set, by WithGoldenUpdate or the EnvUpdateGolden
+// variable, the file is rewritten instead.
+//
+// Parameters:
+//   - path: The path of the golden file, e.g. "testdata/support_agent.golden.json".
+//   - report: The report of the evaluation, its cases named uniquely.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - The cases whose output differs, in the order of the report then of the golden file.
+//   - ErrGoldenMismatch describing the differences, ErrGoldenNotFound if the file does not exist,
+//     or another error if the options, the case names or the file are invalid.
+//
+// Example:
+//
+//	report, _ := eval.Run(ctx, runtime, dataset)
+//	if _, err := eval.CompareGolden("testdata/agent.golden.json", report,
+//	    eval.WithNormalizer(eval.NormalizeConversation)); err != nil {
+//	    t.Fatal(err)
+//	}
+func CompareGolden[T g.SharedState](path string, report Report[T], opts ...GoldenOption[T]) ([]GoldenMismatch, error) {
+	useOpts := &GoldenOptions[T]{}
+	if env := os.Getenv(EnvUpdateGolden); env != "" {
+		update, err := strconv.ParseBool(env)
+		if err != nil {
+			return nil, fmt.Errorf("golden comparison failed: invalid %s value %q: %w", EnvUpdateGolden, env, err)
+		}
+		useOpts.Update = update
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("golden comparison failed: %w", err)
+		}
+	}
+
+	current, names, err := goldenCases(report, useOpts.Normalizers)
+	if err != nil {
+		return nil, fmt.Errorf("golden comparison failed: %w", err)
+	}
+	if useOpts.Update {
+		if err := writeGolden(path, current); err != nil {
+			return nil, fmt.Errorf("golden update failed: %w", err)
+		}
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("golden comparison failed: %w: %s, set %s=true to record it", ErrGoldenNotFound, path, EnvUpdateGolden)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("golden comparison failed: %w", err)
+	}
+	var golden goldenFile[T]
+	if err := json.Unmarshal(data, &golden); err != nil {
+		return nil, fmt.Errorf("golden comparison failed: cannot decode %s: %w", path, err)
+	}
+
+	var rv []GoldenMismatch
+	for _, name := range names {
+		expected, ok := golden.Cases[name]
+		if !ok {
+			rv = append(rv, GoldenMismatch{Case: name, Changes: []g.StateChange{{After: current.Cases[name]}}})
+			continue
+		}
+		changes := g.Diff(expected.Output, current.Cases[name].Output)
+		if expected.Error != current.Cases[name].Error {
+			changes = append(changes, g.StateChange{Path: "error", Before: expected.Error, After: current.Cases[name].Error})
+		}
+		if len(changes) > 0 {
+			rv = append(rv, GoldenMismatch{Case: name, Changes: changes})
+		}
+	}
+	var removed []string
+	for name := range golden.Cases {
+		if _, ok := current.Cases[name]; !ok {
+			removed = append(removed, name)
+		}
+	}
+	slices.Sort(removed)
+	for _, name := range removed {
+		rv = append(rv, GoldenMismatch{Case: name, Changes: []g.StateChange{{Before: golden.Cases[name]}}})
+	}
+
+	if len(rv) > 0 {
+		return rv, fmt.Errorf("%w %s:\n%s", ErrGoldenMismatch, path, describeMismatches(rv))
+	}
+	return nil, nil
+}
+
+// goldenCases normalizes the outcomes of the cases, round-tripping them through JSON so that they
+// compare like with like with the decoded golden file.
+func goldenCases[T g.SharedState](report Report[T], normalizers []NormalizeFn[T]) (goldenFile[T], []string, error) {
+	rv := goldenFile[T]{Cases: make(map[string]goldenCase[T], len(report.Cases))}
+	names := make([]string, 0, len(report.Cases))
+	for _, result := range report.Cases {
+		name := result.Case.Name
+		if _, ok := rv.Cases[name]; ok || name == "" {
+			return goldenFile[T]{}, nil, fmt.Errorf("%w: %q", ErrInvalidCaseName, name)
+		}
+
+		output := result.Output
+		for _, normalize := range normalizers {
+			output = normalize(output)
+		}
+		outcome := goldenCase[T]{Output: output}
+		if result.Error != nil {
+			outcome.Error = result.Error.Error()
+		}
+
+		data, err := json.Marshal(outcome)
+		if err != nil {
+			return goldenFile[T]{}, nil, fmt.Errorf("cannot encode the output of case %q: %w", name, err)
+		}
+		var decoded goldenCase[T]
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			return goldenFile[T]{}, nil, fmt.Errorf("cannot decode the output of case %q: %w", name, err)
+		}
+		rv.Cases[name] = decoded
+		names = append(names, name)
+	}
+	return rv, names, nil
+}
+
+// writeGolden writes the golden file, creating its directory if needed.
+func writeGolden[T g.SharedState](path string, golden goldenFile[T]) error {
+	data, err := json.MarshalIndent(golden, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// describeMismatches lists the changes of the mismatches, one per line.
+func describeMismatches(mismatches []GoldenMismatch) string {
+	var sb strings.Builder
+	for _, mismatch := range mismatches {
+		for _, change := range mismatch.Changes {
+			switch {
+			case change.Path == "" && change.Before == nil:
+				fmt.Fprintf(&sb, "  %s: added\n", mismatch.Case)
+			case change.Path == "" && change.After == nil:
+				fmt.Fprintf(&sb, "  %s: removed\n", mismatch.Case)
+			default:
+				fmt.Fprintf(&sb, "  %s: %s: %v -> %v\n", mismatch.Case, change.Path, change.Before, change.After)
+			}
+		}
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// NormalizeConversation clears the nondeterministic fields of a conversation: the identifiers and
+// the timestamps of the messages and the identifiers of the tool calls.
+//
+// Parameters:
+//   - conversation: The final state of the invocation.
+//
+// Returns:
+//   - The normalized copy of the conversation.
+func NormalizeConversation(conversation a.Conversation) a.Conversation {
+	rv := conversation.Clone()
+	for idx := range rv.Messages {
+		rv.Messages[idx].ID = ""
+		rv.Messages[idx].Ts = time.Time{}
+		rv.Messages[idx].CreatedAt = time.Time{}
+		for callIdx := range rv.Messages[idx].ToolCalls {
+			rv.Messages[idx].ToolCalls[callIdx].ID = ""
+		}
+	}
+	for idx := range rv.CurrentToolCalls {
+		rv.CurrentToolCalls[idx].ID = ""
+	}
+	return rv
+}
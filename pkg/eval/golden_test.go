@@ -0,0 +1,122 @@
+package eval_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/eval"
+)
+
+func TestCompareGolden(t *testing.T) {
+	t.Setenv(eval.EnvUpdateGolden, "")
+	runtime := createRuntime(t)
+	path := filepath.Join(t.TempDir(), "testdata", "answer.golden.json")
+	run := func(questions map[string]string) eval.Report[a.Conversation] {
+		t.Helper()
+		var dataset []eval.Case[a.Conversation]
+		for _, name := range []string{"greeting", "failure", "farewell"} {
+			if text, ok := questions[name]; ok {
+				dataset = append(dataset, eval.Case[a.Conversation]{Name: name, Input: question(text)})
+			}
+		}
+		report, err := eval.Run(context.Background(), runtime, dataset)
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		return report
+	}
+	normalize := eval.WithNormalizer(eval.NormalizeConversation)
+
+	report := run(map[string]string{"greeting": "hello", "failure": "fail"})
+	if _, err := eval.CompareGolden(path, report, normalize); !errors.Is(err, eval.ErrGoldenNotFound) {
+		t.Fatalf("Expected ErrGoldenNotFound before the recording, got %v", err)
+	}
+	if _, err := eval.CompareGolden(path, report, normalize, eval.WithGoldenUpdate[a.Conversation](true)); err != nil {
+		t.Fatalf("Recording failed: %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || !strings.Contains(string(data), `"HELLO"`) || !strings.Contains(string(data), "cannot answer") {
+		t.Fatalf("Expected the outputs and the errors in the golden file, got %s and %v", data, err)
+	}
+
+	// The identifiers and timestamps of a new run are normalized away
+	if mismatches, err := eval.CompareGolden(path, run(map[string]string{"greeting": "hello", "failure": "fail"}), normalize); err != nil || len(mismatches) != 0 {
+		t.Fatalf("Expected no mismatch on the same outputs, got %+v and %v", mismatches, err)
+	}
+	if mismatches, _ := eval.CompareGolden(path, run(map[string]string{"greeting": "hello", "failure": "fail"})); len(mismatches) != 1 {
+		t.Errorf("Expected the nondeterministic fields to differ without normalizer, got %+v", mismatches)
+	}
+
+	mismatches, err := eval.CompareGolden(path, run(map[string]string{"greeting": "hi", "farewell": "bye"}), normalize)
+	if !errors.Is(err, eval.ErrGoldenMismatch) {
+		t.Fatalf("Expected ErrGoldenMismatch, got %v", err)
+	}
+	if len(mismatches) != 3 || mismatches[0].Case != "greeting" || mismatches[1].Case != "farewell" || mismatches[2].Case != "failure" {
+		t.Fatalf("Expected the changed, added and removed cases, got %+v", mismatches)
+	}
+	if change := mismatches[0].Changes[0]; change.Path != "Messages[0].Content" || change.Before != "HELLO" || change.After != "HI" {
+		t.Errorf("Expected the changed answer, got %+v", change)
+	}
+	for _, expected := range []string{"greeting: Messages[0].Content: HELLO -> HI", "farewell: added", "failure: removed"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("Expected %q in the error, got %v", expected, err)
+		}
+	}
+
+	t.Setenv(eval.EnvUpdateGolden, "true")
+	if _, err := eval.CompareGolden(path, run(map[string]string{"greeting": "hi"}), normalize); err != nil {
+		t.Fatalf("Recording by environment failed: %v", err)
+	}
+	t.Setenv(eval.EnvUpdateGolden, "")
+	if _, err := eval.CompareGolden(path, run(map[string]string{"greeting": "hi"}), normalize); err != nil {
+		t.Errorf("Expected the rewritten golden file to match, got %v", err)
+	}
+}
+
+// TestCompareGolden_Testdata diffs the graph with its recorded golden file, as in the CI;
+// GGRAPH_UPDATE_GOLDEN=true go test ./pkg/eval/ records it again.
+func TestCompareGolden_Testdata(t *testing.T) {
+	runtime := createRuntime(t)
+	dataset := []eval.Case[a.Conversation]{
+		{Name: "greeting", Input: question("hello")},
+		{Name: "question", Input: question("what is the capital of Italy?")},
+		{Name: "failure", Input: question("fail")},
+	}
+	report, err := eval.Run(context.Background(), runtime, dataset)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if _, err := eval.CompareGolden("testdata/answer.golden.json", report, eval.WithNormalizer(eval.NormalizeConversation)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCompareGolden_Validation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "golden.json")
+	report := eval.Report[a.Conversation]{Cases: []eval.CaseResult[a.Conversation]{{Case: eval.Case[a.Conversation]{Name: "same"}}, {Case: eval.Case[a.Conversation]{Name: "same"}}}}
+
+	if _, err := eval.CompareGolden(path, report, eval.WithGoldenUpdate[a.Conversation](true)); !errors.Is(err, eval.ErrInvalidCaseName) {
+		t.Errorf("Expected ErrInvalidCaseName, got %v", err)
+	}
+	if _, err := eval.CompareGolden(path, report, eval.WithNormalizer[a.Conversation](nil)); !errors.Is(err, eval.ErrNormalizerNil) {
+		t.Errorf("Expected ErrNormalizerNil, got %v", err)
+	}
+}
+
+func TestNormalizeConversation(t *testing.T) {
+	conversation := reply("Rome")
+	normalized := eval.NormalizeConversation(conversation)
+
+	for idx, message := range normalized.Messages {
+		if message.ID != "" || !message.Ts.IsZero() || message.Content != conversation.Messages[idx].Content {
+			t.Errorf("Expected the identifier and the timestamp to be cleared, got %+v", message)
+		}
+	}
+	if conversation.Messages[0].ID == "" || conversation.Messages[0].Ts.IsZero() {
+		t.Error("Expected the original conversation not to be modified")
+	}
+}
@@ -0,0 +1,198 @@
+package eval
+
+import (
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+const (
+	// DefaultConcurrency is the default number of cases running at the same time.
+	DefaultConcurrency = 4
+	// DefaultThreadPrefix is the default prefix of the threads of the cases.
+	DefaultThreadPrefix = "eval-"
+	// DefaultMaxTurns is the default number of turns of a simulated session.
+	DefaultMaxTurns = 10
+)
+
+// Options holds the configuration for an evaluation.
+type Options[T g.SharedState] struct {
+	// Scorers grade the final state of each case; a case without scorers passes when its invocation succeeds.
+	Scorers []ScorerFn[T]
+	// Concurrency is the number of cases, or simulated sessions, running at the same time.
+	Concurrency int
+	// CaseTimeout bounds the invocation of each case, or of each turn of a simulated session, see
+	// graph.InvokeConfigTimeout; zero means unlimited.
+	CaseTimeout time.Duration
+	// MaxTurns is the number of turns after which a simulated session ends, see Simulate.
+	MaxTurns int
+	// Pricing converts the usage of the nodes without a NodePricing into a cost.
+	Pricing g.Pricing
+	// NodePricing converts the usage of specific nodes into a cost, e.g. the nodes using another model.
+	NodePricing map[string]g.Pricing
+	// ThreadPrefix is prepended to the generated thread identifiers of the cases.
+	ThreadPrefix string
+}
+
+// Option is a functional option for configuring an evaluation.
+type Option[T g.SharedState] interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - r: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *Options[T]) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc[T g.SharedState] func(*Options[T]) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - r: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc[T]) Apply(r *Options[T]) error { return s(r) }
+
+// WithScorers adds scorers grading the final state of each case.
+//
+// Parameters:
+//   - scorers: The scorers.
+//
+// Returns:
+//   - An Option that adds the scorers.
+//
+// Example:
+//
+//	report, err := eval.Run(ctx, runtime, dataset, eval.WithScorers(eval.ExactMatch("label", eval.LastReply)))
+func WithScorers[T g.SharedState](scorers ...ScorerFn[T]) Option[T] {
+	return OptionFunc[T](func(r *Options[T]) error {
+		for _, scorer := range scorers {
+			if scorer == nil {
+				return ErrScorerNil
+			}
+		}
+		r.Scorers = append(r.Scorers, scorers...)
+		return nil
+	})
+}
+
+// WithConcurrency sets the number of cases running at the same time.
+//
+// Parameters:
+//   - concurrency: The number of concurrent cases, 1 to run the dataset sequentially.
+//
+// Returns:
+//   - An Option that sets the concurrency.
+func WithConcurrency[T g.SharedState](concurrency int) Option[T] {
+	return OptionFunc[T](func(r *Options[T]) error {
+		if concurrency < 1 {
+			return ErrInvalidConcurrency
+		}
+		r.Concurrency = concurrency
+		return nil
+	})
+}
+
+// WithCaseTimeout bounds the invocation of each case; a case timing out fails with graph.ErrInvocationTimeout.
+//
+// Parameters:
+//   - timeout: The timeout of the invocations, zero for none.
+//
+// Returns:
+//   - An Option that sets the timeout.
+func WithCaseTimeout[T g.SharedState](timeout time.Duration) Option[T] {
+	return OptionFunc[T](func(r *Options[T]) error {
+		if timeout < 0 {
+			return ErrInvalidTimeout
+		}
+		r.CaseTimeout = timeout
+		return nil
+	})
+}
+
+// WithMaxTurns sets the number of turns after which a simulated session ends, unless its user ends it before.
+//
+// Parameters:
+//   - turns: The maximum number of turns.
+//
+// Returns:
+//   - An Option that sets the maximum number of turns.
+func WithMaxTurns[T g.SharedState](turns int) Option[T] {
+	return OptionFunc[T](func(r *Options[T]) error {
+		if turns < 1 {
+			return ErrInvalidMaxTurns
+		}
+		r.MaxTurns = turns
+		return nil
+	})
+}
+
+// WithPricing sets the pricing of the usage reported by the nodes.
+//
+// Parameters:
+//   - pricing: The prices of the tokens.
+//   - nodes: The nodes the pricing applies to, all the nodes without a specific pricing when empty.
+//
+// Returns:
+//   - An Option that sets the pricing.
+//
+// Example:
+//
+//	eval.WithPricing[a.Conversation](g.Pricing{PromptPerMillion: 0.15, CompletionPerMillion: 0.6})
+//	eval.WithPricing[a.Conversation](g.Pricing{PromptPerMillion: 2.5, CompletionPerMillion: 10}, "Judge")
+func WithPricing[T g.SharedState](pricing g.Pricing, nodes ...string) Option[T] {
+	return OptionFunc[T](func(r *Options[T]) error {
+		if len(nodes) == 0 {
+			r.Pricing = pricing
+			return nil
+		}
+		if r.NodePricing == nil {
+			r.NodePricing = make(map[string]g.Pricing, len(nodes))
+		}
+		for _, node := range nodes {
+			r.NodePricing[node] = pricing
+		}
+		return nil
+	})
+}
+
+// WithThreadPrefix sets the prefix of the thread identifiers of the cases, e.g. to tell them apart
+// from the production threads in the shared memory or the traces.
+//
+// Parameters:
+//   - prefix: The thread prefix.
+//
+// Returns:
+//   - An Option that sets the thread prefix.
+func WithThreadPrefix[T g.SharedState](prefix string) Option[T] {
+	return OptionFunc[T](func(r *Options[T]) error {
+		r.ThreadPrefix = prefix
+		return nil
+	})
+}
+
+func createOptions[T g.SharedState](opts []Option[T]) (*Options[T], error) {
+	rv := &Options[T]{
+		Concurrency:  DefaultConcurrency,
+		ThreadPrefix: DefaultThreadPrefix,
+		MaxTurns:     DefaultMaxTurns,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(rv); err != nil {
+			return nil, err
+		}
+	}
+	return rv, nil
+}
+
+func (o Options[T]) pricing(node string) g.Pricing {
+	if pricing, ok := o.NodePricing[node]; ok {
+		return pricing
+	}
+	return o.Pricing
+}
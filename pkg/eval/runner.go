@@ -0,0 +1,271 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/morphy76/ggraph/internal/latency"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// subscriptionBufferSize is the capacity of the subscription following a case, large enough for
+// the terminal entry not to be dropped behind the entries of the nodes.
+const subscriptionBufferSize = 256
+
+// Run invokes the graph once per case of the dataset and scores the final states.
+//
+// Each case runs on a new thread, followed through a subscription to the runtime, see
+// graph.EventBus; the caller still owns the state monitor channel of the runtime and must drain
+// it. The latencies of the nodes come from the timelines of the threads, see graph.Timelined,
+// their usage from the usage reported along the invocations, see graph.ReportUsage.
+//
+// Parameters:
+//   - ctx: The context of the evaluation, cancelling it cancels the running cases.
+//   - runtime: The runtime of the graph under evaluation, with its edges added.
+//   - dataset: The cases.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - The report of the evaluation; the failures of the cases are reported, not returned.
+//   - An error if the runtime is nil, the dataset is empty or an option is invalid.
+//
+// Example:
+//
+//	judge, _ := eval.Judge("helpfulness", ask, "The answer solves the request", eval.LastReply)
+//	report, err := eval.Run(ctx, runtime, dataset,
+//	    eval.WithScorers(judge),
+//	    eval.WithConcurrency[a.Conversation](8),
+//	)
+//	for _, node := range report.Nodes {
+//	    fmt.Printf("%s: p95 %s, %d tokens\n", node.Node, node.P95Latency, node.Usage.TotalTokens)
+//	}
+func Run[T g.SharedState](ctx context.Context, runtime g.Runtime[T], dataset []Case[T], opts ...Option[T]) (Report[T], error) {
+	if runtime == nil {
+		return Report[T]{}, fmt.Errorf("evaluation failed: %w", ErrRuntimeNil)
+	}
+	if len(dataset) == 0 {
+		return Report[T]{}, fmt.Errorf("evaluation failed: %w", ErrEmptyDataset)
+	}
+	useOpts, err := createOptions(opts)
+	if err != nil {
+		return Report[T]{}, fmt.Errorf("evaluation failed: %w", err)
+	}
+
+	startedAt := time.Now()
+	results := make([]CaseResult[T], len(dataset))
+	forEach(len(dataset), useOpts.Concurrency, func(idx int) {
+		results[idx] = runCase(ctx, runtime, dataset[idx], useOpts)
+	})
+
+	report := aggregate(results, useOpts)
+	report.Duration = time.Since(startedAt)
+	return report, nil
+}
+
+// forEach calls fn with the indexes from 0 to count, from at most concurrency goroutines at a time.
+func forEach(count, concurrency int, fn func(idx int)) {
+	indexes := make(chan int)
+	var wg sync.WaitGroup
+	for range min(concurrency, count) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range indexes {
+				fn(idx)
+			}
+		}()
+	}
+	for idx := range count {
+		indexes <- idx
+	}
+	close(indexes)
+	wg.Wait()
+}
+
+// runCase invokes a case on a new thread, waits for its termination and scores it.
+func runCase[T g.SharedState](ctx context.Context, runtime g.Runtime[T], c Case[T], opts *Options[T]) CaseResult[T] {
+	threadID := opts.ThreadPrefix + uuid.NewString()
+	rv := CaseResult[T]{Case: c, ThreadID: threadID}
+	if err := ctx.Err(); err != nil {
+		rv.Error = err
+		return rv
+	}
+
+	entries, cancel := subscribe(runtime, threadID)
+	defer cancel()
+	outcome := invoke(ctx, runtime, entries, threadID, c.Input, c.Metadata, opts)
+	rv.Output, rv.Error = outcome.output, outcome.err
+	rv.Duration, rv.Usage, rv.NodeUsage, rv.Spans = outcome.duration, outcome.usage, outcome.nodeUsage, outcome.spans
+	rv.Cost = outcome.cost(opts)
+	if rv.Error != nil {
+		return rv
+	}
+	grade(ctx, &rv, opts.Scorers)
+	return rv
+}
+
+// grade scores the output of a case with the scorers.
+func grade[T g.SharedState](ctx context.Context, rv *CaseResult[T], scorers []ScorerFn[T]) {
+	rv.Pass = true
+	for _, scorer := range scorers {
+		score, err := scorer(ctx, rv.Case, rv.Output)
+		if err != nil {
+			rv.Error = fmt.Errorf("scoring failed: %w", err)
+			rv.Pass = false
+			return
+		}
+		rv.Scores = append(rv.Scores, score)
+		rv.Pass = rv.Pass && score.Pass
+	}
+}
+
+// subscribe follows the complete entries of a thread.
+func subscribe[T g.SharedState](runtime g.Runtime[T], threadID string) (<-chan g.StateMonitorEntry[T], func()) {
+	return runtime.Subscribe(g.EventFilter[T]{
+		ThreadIDs:   []string{threadID},
+		SkipPartial: true,
+		BufferSize:  subscriptionBufferSize,
+	})
+}
+
+// outcome is the outcome of an invocation of a thread.
+type outcome[T g.SharedState] struct {
+	output    T
+	err       error
+	duration  time.Duration
+	usage     g.Usage
+	nodeUsage map[string]g.Usage
+	spans     []g.TimelineSpan
+}
+
+// cost returns the cost of the usage of the nodes.
+func (o outcome[T]) cost(opts *Options[T]) float64 {
+	var rv float64
+	for node, usage := range o.nodeUsage {
+		rv += opts.pricing(node).Cost(usage)
+	}
+	return rv
+}
+
+// invoke invokes the thread and waits for the termination of the invocation, following its entries.
+func invoke[T g.SharedState](ctx context.Context, runtime g.Runtime[T], entries <-chan g.StateMonitorEntry[T], threadID string, input T, metadata map[string]string, opts *Options[T]) outcome[T] {
+	configs := []g.InvokeConfig{g.InvokeConfigThreadID(threadID), g.InvokeConfigContext(ctx)}
+	if len(metadata) > 0 {
+		configs = append(configs, g.InvokeConfigMetadata(metadata))
+	}
+	if opts.CaseTimeout > 0 {
+		configs = append(configs, g.InvokeConfigTimeout(opts.CaseTimeout))
+	}
+	rv := outcome[T]{nodeUsage: make(map[string]g.Usage)}
+	startedAt := time.Now()
+	runtime.Invoke(input, configs...)
+
+	rv.output, rv.err = await(ctx, runtime, threadID, entries, &rv)
+	rv.duration = time.Since(startedAt)
+	if timeline, err := runtime.Timeline(threadID); err == nil && len(timeline.Invocations) > 0 {
+		for _, span := range timeline.Invocations[len(timeline.Invocations)-1].Spans {
+			if !span.EndedAt.IsZero() {
+				rv.spans = append(rv.spans, span)
+			}
+		}
+	}
+	return rv
+}
+
+// await follows the entries of the thread until its terminal one, accounting the usage of the nodes.
+func await[T g.SharedState](ctx context.Context, runtime g.Runtime[T], threadID string, entries <-chan g.StateMonitorEntry[T], rv *outcome[T]) (T, error) {
+	var zero T
+	for {
+		select {
+		case <-ctx.Done():
+			_ = runtime.Cancel(threadID)
+			return zero, ctx.Err()
+		case entry, ok := <-entries:
+			if !ok {
+				return zero, ErrRuntimeShutdown
+			}
+			if delta := usageDelta(entry.Usage, rv.usage); !delta.IsZero() {
+				rv.nodeUsage[entry.Node] = rv.nodeUsage[entry.Node].Add(delta)
+				rv.usage = entry.Usage
+			}
+			if !entry.Running {
+				return entry.NewState, entry.Error
+			}
+		}
+	}
+}
+
+// usageDelta returns the usage reported since the previous cumulative usage.
+func usageDelta(current, previous g.Usage) g.Usage {
+	return g.Usage{
+		PromptTokens:     current.PromptTokens - previous.PromptTokens,
+		CompletionTokens: current.CompletionTokens - previous.CompletionTokens,
+		TotalTokens:      current.TotalTokens - previous.TotalTokens,
+	}
+}
+
+// aggregate builds the report of the results.
+func aggregate[T g.SharedState](results []CaseResult[T], opts *Options[T]) Report[T] {
+	rv := Report[T]{Cases: results}
+	nodes := make(map[string]*NodeStats)
+	latencies := make(map[string][]time.Duration)
+	var turnLatencies []time.Duration
+	stats := func(node string) *NodeStats {
+		if _, ok := nodes[node]; !ok {
+			nodes[node] = &NodeStats{Node: node}
+		}
+		return nodes[node]
+	}
+
+	for _, result := range results {
+		switch {
+		case result.Pass:
+			rv.Passed++
+		case result.Error != nil:
+			rv.Errored++
+		}
+		rv.Usage = rv.Usage.Add(result.Usage)
+		rv.Cost += result.Cost
+
+		for _, span := range result.Spans {
+			node := stats(span.Node)
+			node.Executions++
+			if span.Error != "" {
+				node.Failures++
+			}
+			node.TotalLatency += span.Duration
+			node.MaxLatency = max(node.MaxLatency, span.Duration)
+			latencies[span.Node] = append(latencies[span.Node], span.Duration)
+		}
+		for _, turn := range result.Turns {
+			rv.Turns.Turns++
+			if turn.Error != nil {
+				rv.Turns.Failures++
+			}
+			rv.Turns.TotalLatency += turn.Duration
+			rv.Turns.MaxLatency = max(rv.Turns.MaxLatency, turn.Duration)
+			turnLatencies = append(turnLatencies, turn.Duration)
+		}
+		for name, usage := range result.NodeUsage {
+			node := stats(name)
+			node.Usage = node.Usage.Add(usage)
+			node.Cost += opts.pricing(name).Cost(usage)
+		}
+	}
+
+	for name, node := range nodes {
+		slices.Sort(latencies[name])
+		node.P95Latency = latency.Percentile(latencies[name], 0.95)
+		rv.Nodes = append(rv.Nodes, *node)
+	}
+	slices.Sort(turnLatencies)
+	rv.Turns.P95Latency = latency.Percentile(turnLatencies, 0.95)
+	slices.SortFunc(rv.Nodes, func(x, y NodeStats) int { return strings.Compare(x.Node, y.Node) })
+	return rv
+}
@@ -0,0 +1,244 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/agent/parser"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+const (
+	// DefaultJudgeThreshold is the default grade, from 0 to 1, a judged output must reach to pass.
+	DefaultJudgeThreshold = 0.7
+	// DefaultJudgeRetries is the default number of retries of a judge answer which cannot be parsed.
+	DefaultJudgeRetries = 2
+	// DefaultJudgePrompt is the default system prompt of the judge.
+	DefaultJudgePrompt = "You are an impartial evaluator. Grade the answer against the criteria with a score " +
+		"from 0 (completely fails) to 10 (fully satisfies) and explain the score in one sentence. " +
+		"When an expected answer is given, use it as the reference; do not reward the length nor the style of the answer."
+)
+
+var (
+	// ErrAskFnNil indicates that the judge has no function to call the model.
+	ErrAskFnNil = errors.New("ask function cannot be nil")
+	// ErrExtractFnNil indicates that the scorer has no function extracting the output.
+	ErrExtractFnNil = errors.New("extract function cannot be nil")
+	// ErrInvalidThreshold indicates that the threshold of the judge is not between 0 and 1.
+	ErrInvalidThreshold = errors.New("threshold must be between 0 and 1")
+	// ErrInvalidRetries indicates that the number of retries of the judge is negative.
+	ErrInvalidRetries = errors.New("retries cannot be negative")
+)
+
+// ExtractFn extracts the text graded by a scorer from the final state of a case, see LastReply.
+type ExtractFn[T g.SharedState] func(output T) string
+
+// ExactMatch creates a scorer passing the outputs equal to the expected output of the case,
+// ignoring the surrounding spaces and the case of the letters.
+//
+// Parameters:
+//   - name: The name of the scorer in the report.
+//   - extract: The function extracting the text of the output.
+//
+// Returns:
+//   - The scorer, grading 1 on a match and 0 otherwise.
+//
+// Example:
+//
+//	eval.WithScorers(eval.ExactMatch("label", eval.LastReply))
+func ExactMatch[T g.SharedState](name string, extract ExtractFn[T]) ScorerFn[T] {
+	return func(ctx context.Context, c Case[T], output T) (Score, error) {
+		if extract == nil {
+			return Score{}, ErrExtractFnNil
+		}
+		got := strings.TrimSpace(extract(output))
+		if strings.EqualFold(got, strings.TrimSpace(c.Expected)) {
+			return Score{Scorer: name, Value: 1, Pass: true}, nil
+		}
+		return Score{Scorer: name, Reason: fmt.Sprintf("expected %q, got %q", c.Expected, got)}, nil
+	}
+}
+
+// Contains creates a scorer passing the outputs containing the expected output of the case,
+// ignoring the case of the letters.
+//
+// Parameters:
+//   - name: The name of the scorer in the report.
+//   - extract: The function extracting the text of the output.
+//
+// Returns:
+//   - The scorer, grading 1 when the expected output is contained and 0 otherwise.
+//
+// Example:
+//
+//	eval.WithScorers(eval.Contains("answer", eval.LastReply))
+func Contains[T g.SharedState](name string, extract ExtractFn[T]) ScorerFn[T] {
+	return func(ctx context.Context, c Case[T], output T) (Score, error) {
+		if extract == nil {
+			return Score{}, ErrExtractFnNil
+		}
+		got := extract(output)
+		if strings.Contains(strings.ToLower(got), strings.ToLower(strings.TrimSpace(c.Expected))) {
+			return Score{Scorer: name, Value: 1, Pass: true}, nil
+		}
+		return Score{Scorer: name, Reason: fmt.Sprintf("%q not found in the output", c.Expected)}, nil
+	}
+}
+
+// JudgeOptions holds the configuration for an LLM-as-judge scorer.
+type JudgeOptions struct {
+	// Threshold is the grade, from 0 to 1, an output must reach to pass.
+	Threshold float64
+	// Retries is the number of times the judge is asked again when its answer cannot be parsed.
+	Retries int
+	// SystemPrompt instructs the judge; the format instructions are appended to it.
+	SystemPrompt string
+}
+
+// JudgeOption is a functional option for configuring an LLM-as-judge scorer.
+type JudgeOption interface {
+	// Apply applies the option to the JudgeOptions.
+	//
+	// Parameters:
+	//   - r: A pointer to JudgeOptions to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *JudgeOptions) error
+}
+
+// JudgeOptionFunc is a function type that implements the JudgeOption interface.
+type JudgeOptionFunc func(*JudgeOptions) error
+
+// Apply applies the JudgeOptionFunc to the given JudgeOptions.
+//
+// Parameters:
+//   - r: A pointer to JudgeOptions to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s JudgeOptionFunc) Apply(r *JudgeOptions) error { return s(r) }
+
+// WithThreshold sets the grade a judged output must reach to pass.
+//
+// Parameters:
+//   - threshold: The grade, from 0 to 1.
+//
+// Returns:
+//   - A JudgeOption that sets the threshold.
+func WithThreshold(threshold float64) JudgeOption {
+	return JudgeOptionFunc(func(r *JudgeOptions) error {
+		if threshold < 0 || threshold > 1 {
+			return ErrInvalidThreshold
+		}
+		r.Threshold = threshold
+		return nil
+	})
+}
+
+// WithJudgeRetries sets the number of times the judge is asked again when its answer cannot be parsed.
+//
+// Parameters:
+//   - retries: The number of retries, 0 to fail on the first invalid answer.
+//
+// Returns:
+//   - A JudgeOption that sets the retries.
+func WithJudgeRetries(retries int) JudgeOption {
+	return JudgeOptionFunc(func(r *JudgeOptions) error {
+		if retries < 0 {
+			return ErrInvalidRetries
+		}
+		r.Retries = retries
+		return nil
+	})
+}
+
+// WithJudgePrompt replaces the system prompt of the judge, e.g. to grade in another language.
+//
+// Parameters:
+//   - prompt: The system prompt.
+//
+// Returns:
+//   - A JudgeOption that sets the system prompt.
+func WithJudgePrompt(prompt string) JudgeOption {
+	return JudgeOptionFunc(func(r *JudgeOptions) error {
+		r.SystemPrompt = prompt
+		return nil
+	})
+}
+
+// judgement is the answer of the judge.
+type judgement struct {
+	Score  float64 `json:"score"`
+	Reason string  `json:"reason"`
+}
+
+// Judge creates an LLM-as-judge scorer, asking a model to grade the output against criteria.
+//
+// The judge receives the criteria, the expected output of the case when not empty and the
+// extracted output, and answers a JSON document with a score from 0 to 10 and its reason; the
+// score is normalized from 0 to 1.
+//
+// Parameters:
+//   - name: The name of the scorer in the report.
+//   - ask: The function calling the judge model, see parser.AskFn.
+//   - criteria: What a good output is, e.g. "The answer is grammatically correct Italian".
+//   - extract: The function extracting the text of the output.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - The scorer.
+//   - An error if a function is nil or an option is invalid.
+//
+// Example:
+//
+//	judge, err := eval.Judge("grammar", ask, "The answer is grammatically correct", eval.LastReply, eval.WithThreshold(0.8))
+func Judge[T g.SharedState](name string, ask parser.AskFn, criteria string, extract ExtractFn[T], opts ...JudgeOption) (ScorerFn[T], error) {
+	if ask == nil {
+		return nil, fmt.Errorf("judge creation failed: %w", ErrAskFnNil)
+	}
+	if extract == nil {
+		return nil, fmt.Errorf("judge creation failed: %w", ErrExtractFnNil)
+	}
+	useOpts := &JudgeOptions{
+		Threshold:    DefaultJudgeThreshold,
+		Retries:      DefaultJudgeRetries,
+		SystemPrompt: DefaultJudgePrompt,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("judge creation failed: %w", err)
+		}
+	}
+
+	grades := parser.JSON(func(j judgement) error {
+		if j.Score < 0 || j.Score > 10 {
+			return errors.New("score must be between 0 and 10")
+		}
+		return nil
+	})
+	systemPrompt := useOpts.SystemPrompt + "\n\n" + grades.Instructions()
+
+	return func(ctx context.Context, c Case[T], output T) (Score, error) {
+		var request strings.Builder
+		fmt.Fprintf(&request, "Criteria:\n%s\n\n", criteria)
+		if c.Expected != "" {
+			fmt.Fprintf(&request, "Expected answer:\n%s\n\n", c.Expected)
+		}
+		fmt.Fprintf(&request, "Answer:\n%s", extract(output))
+
+		messages := []a.Message{
+			a.CreateMessage(a.System, systemPrompt),
+			a.CreateMessage(a.User, request.String()),
+		}
+		grade, _, err := parser.ParseWithRetry(ctx, grades, messages, ask, useOpts.Retries)
+		if err != nil {
+			return Score{}, fmt.Errorf("judge %q failed: %w", name, err)
+		}
+		value := grade.Score / 10
+		return Score{Scorer: name, Value: value, Pass: value >= useOpts.Threshold, Reason: grade.Reason}, nil
+	}, nil
+}
@@ -0,0 +1,87 @@
+package eval_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/eval"
+)
+
+func reply(text string) a.Conversation {
+	return a.CreateConversation(a.CreateMessage(a.User, "question"), a.CreateMessage(a.Assistant, text))
+}
+
+func TestExactMatchAndContains(t *testing.T) {
+	c := eval.Case[a.Conversation]{Expected: "Rome"}
+	exact := eval.ExactMatch("exact", eval.LastReply)
+	contains := eval.Contains("contains", eval.LastReply)
+
+	if score, err := exact(context.Background(), c, reply("  rome ")); err != nil || !score.Pass || score.Value != 1 {
+		t.Errorf("Expected an exact match ignoring case and spaces, got %+v and %v", score, err)
+	}
+	if score, _ := exact(context.Background(), c, reply("It is Rome")); score.Pass || score.Value != 0 {
+		t.Errorf("Expected no exact match, got %+v", score)
+	}
+	if score, _ := contains(context.Background(), c, reply("The capital is rome.")); !score.Pass || score.Scorer != "contains" {
+		t.Errorf("Expected the expected output to be contained, got %+v", score)
+	}
+	if score, _ := contains(context.Background(), c, reply("Milan")); score.Pass {
+		t.Errorf("Expected the expected output not to be contained, got %+v", score)
+	}
+}
+
+func TestJudge(t *testing.T) {
+	var requests [][]a.Message
+	answers := []string{"I think it is good", `{"score": 8, "reason": "correct and concise"}`}
+	ask := func(_ context.Context, messages []a.Message) (a.Message, error) {
+		requests = append(requests, messages)
+		answer := answers[0]
+		answers = answers[1:]
+		return a.CreateMessage(a.Assistant, answer), nil
+	}
+
+	judge, err := eval.Judge("quality", ask, "The answer is correct", eval.LastReply, eval.WithThreshold(0.8))
+	if err != nil {
+		t.Fatalf("Judge failed: %v", err)
+	}
+	score, err := judge(context.Background(), eval.Case[a.Conversation]{Expected: "Rome"}, reply("Rome"))
+	if err != nil {
+		t.Fatalf("Scoring failed: %v", err)
+	}
+	if !score.Pass || score.Value != 0.8 || score.Reason != "correct and concise" || score.Scorer != "quality" {
+		t.Errorf("Expected a passing normalized score, got %+v", score)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("Expected the unparsable answer to be retried, got %d requests", len(requests))
+	}
+	request := requests[0][1].Content
+	if !strings.Contains(request, "The answer is correct") || !strings.Contains(request, "Expected answer:\nRome") || !strings.HasSuffix(request, "Answer:\nRome") {
+		t.Errorf("Expected the criteria, the expected and the actual answer in the request, got %q", request)
+	}
+
+	failing := func(context.Context, []a.Message) (a.Message, error) { return a.Message{}, errors.New("unavailable") }
+	judge, _ = eval.Judge("quality", failing, "The answer is correct", eval.LastReply)
+	if _, err := judge(context.Background(), eval.Case[a.Conversation]{}, reply("Rome")); err == nil {
+		t.Error("Expected the failed request to be returned")
+	}
+}
+
+func TestJudge_Validation(t *testing.T) {
+	ask := func(context.Context, []a.Message) (a.Message, error) { return a.Message{}, nil }
+
+	if _, err := eval.Judge("quality", nil, "criteria", eval.LastReply); !errors.Is(err, eval.ErrAskFnNil) {
+		t.Errorf("Expected ErrAskFnNil, got %v", err)
+	}
+	if _, err := eval.Judge[a.Conversation]("quality", ask, "criteria", nil); !errors.Is(err, eval.ErrExtractFnNil) {
+		t.Errorf("Expected ErrExtractFnNil, got %v", err)
+	}
+	if _, err := eval.Judge("quality", ask, "criteria", eval.LastReply, eval.WithThreshold(1.5)); !errors.Is(err, eval.ErrInvalidThreshold) {
+		t.Errorf("Expected ErrInvalidThreshold, got %v", err)
+	}
+	if _, err := eval.Judge("quality", ask, "criteria", eval.LastReply, eval.WithJudgeRetries(-1)); !errors.Is(err, eval.ErrInvalidRetries) {
+		t.Errorf("Expected ErrInvalidRetries, got %v", err)
+	}
+}
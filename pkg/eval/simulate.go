@@ -0,0 +1,308 @@
+package eval
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/agent/parser"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+const (
+	// DoneToken ends a session when a persona user answers it, see PersonaUser.
+	DoneToken = "[DONE]"
+	// DefaultPersonaPrompt instructs the model playing a persona user; the persona is appended to it.
+	DefaultPersonaPrompt = "You are role-playing a user talking with an AI assistant, to test it. " +
+		"Stay in character, write only your next message to the assistant, as the user would type it. " +
+		"When your goal is reached, or the conversation cannot make progress anymore, answer only " + DoneToken + "."
+	// personaKickoff starts the session of a persona user, whose first message has nothing to answer.
+	personaKickoff = "Start the conversation."
+)
+
+var (
+	// ErrNoUsers indicates that the simulation has no user.
+	ErrNoUsers = errors.New("simulation requires at least one user")
+	// ErrUserFnNil indicates that a simulated user has no function writing its messages.
+	ErrUserFnNil = errors.New("user function cannot be nil")
+	// ErrInvalidMaxTurns indicates that the number of turns of the simulated sessions is not positive.
+	ErrInvalidMaxTurns = errors.New("max turns must be positive")
+)
+
+// UserFn writes the next message of a simulated user.
+//
+// Parameters:
+//   - ctx: The context of the simulation.
+//   - transcript: The messages of the session so far, of the user and of the graph.
+//
+// Returns:
+//   - The next message of the user.
+//   - true to end the session, the message is then not sent.
+//   - An error if the message cannot be written, e.g. a failed model request.
+type UserFn func(ctx context.Context, transcript []a.Message) (message string, done bool, err error)
+
+// SimulatedUser is a synthetic user driving a multi-turn session with the graph.
+type SimulatedUser struct {
+	// Name identifies the session in the report.
+	Name string
+	// Next writes the messages of the user, see ScriptedUser and PersonaUser.
+	Next UserFn
+	// Expected is the expected outcome of the session, compared by the scorers; empty when the scorers need none.
+	Expected string
+	// Metadata are passed to the invocations of the session, see graph.InvokeConfigMetadata.
+	Metadata map[string]string
+}
+
+// Turn is a message of a simulated user and the answer of the graph.
+type Turn struct {
+	// Input is the message of the user.
+	Input a.Message
+	// Replies are the messages the graph added to the conversation, e.g. the tool calls and the answer.
+	Replies []a.Message
+	// Duration is the time from the invocation to its termination.
+	Duration time.Duration
+	// Usage is the usage reported by the nodes of the invocation.
+	Usage g.Usage
+	// Error is the error the invocation failed with, nil otherwise.
+	Error error
+}
+
+// TurnStats aggregates the turns of the simulated sessions.
+type TurnStats struct {
+	// Turns is the number of turns.
+	Turns int
+	// Failures is the number of turns whose invocation failed.
+	Failures int
+	// TotalLatency is the sum of the durations of the turns.
+	TotalLatency time.Duration
+	// P95Latency is the 95th percentile of the durations of the turns.
+	P95Latency time.Duration
+	// MaxLatency is the longest turn.
+	MaxLatency time.Duration
+}
+
+// AverageLatency returns the average duration of the turns.
+func (s TurnStats) AverageLatency() time.Duration {
+	if s.Turns > 0 {
+		return s.TotalLatency / time.Duration(s.Turns)
+	}
+	return 0
+}
+
+// ScriptedUser creates a user sending fixed messages, in order, then ending the session.
+//
+// Parameters:
+//   - name: The name of the session in the report.
+//   - messages: The messages of the user, one per turn.
+//
+// Returns:
+//   - The simulated user.
+//
+// Example:
+//
+//	user := eval.ScriptedUser("refund", "I want a refund", "Order 1234", "Thanks")
+func ScriptedUser(name string, messages ...string) SimulatedUser {
+	return SimulatedUser{
+		Name: name,
+		Next: func(_ context.Context, transcript []a.Message) (string, bool, error) {
+			sent := 0
+			for _, message := range transcript {
+				if message.Role == a.User {
+					sent++
+				}
+			}
+			if sent >= len(messages) {
+				return "", true, nil
+			}
+			return messages[sent], false, nil
+		},
+	}
+}
+
+// PersonaUser creates a user played by a model, following a persona until its goal is reached.
+//
+// The model receives the transcript with the roles swapped, the answers of the graph as the user
+// messages and its own messages as the assistant ones, and ends the session answering DoneToken.
+//
+// Parameters:
+//   - name: The name of the session in the report.
+//   - ask: The function calling the model playing the user, see parser.AskFn.
+//   - persona: Who the user is and what they want, e.g. "An impatient customer asking a refund for order 1234".
+//
+// Returns:
+//   - The simulated user.
+//   - An error if the ask function is nil.
+//
+// Example:
+//
+//	user, err := eval.PersonaUser("refund", ask, "An impatient customer asking a refund for order 1234")
+//	report, err := eval.Simulate(ctx, runtime, []eval.SimulatedUser{user}, eval.WithMaxTurns[a.Conversation](6))
+func PersonaUser(name string, ask parser.AskFn, persona string) (SimulatedUser, error) {
+	if ask == nil {
+		return SimulatedUser{}, fmt.Errorf("persona user creation failed: %w", ErrAskFnNil)
+	}
+	systemPrompt := DefaultPersonaPrompt + "\n\nPersona:\n" + persona
+
+	return SimulatedUser{
+		Name: name,
+		Next: func(ctx context.Context, transcript []a.Message) (string, bool, error) {
+			messages := []a.Message{
+				a.CreateMessage(a.System, systemPrompt),
+				a.CreateMessage(a.User, personaKickoff),
+			}
+			for _, message := range transcript {
+				switch {
+				case strings.TrimSpace(message.Content) == "":
+				case message.Role == a.User:
+					messages = append(messages, a.CreateMessage(a.Assistant, message.Content))
+				case message.Role == a.Assistant:
+					messages = append(messages, a.CreateMessage(a.User, message.Content))
+				}
+			}
+
+			answer, err := ask(ctx, messages)
+			if err != nil {
+				return "", false, err
+			}
+			text := strings.TrimSpace(answer.Content)
+			if text == "" || strings.Contains(text, DoneToken) {
+				return "", true, nil
+			}
+			return text, false, nil
+		},
+	}, nil
+}
+
+// Simulate drives the graph with synthetic users, one multi-turn session each, and scores the
+// transcripts.
+//
+// Each session runs on a new thread: the user writes a message, the graph is invoked with it and
+// its answer is appended to the transcript, until the user ends the session, a turn fails or
+// MaxTurns is reached. The output graded by the scorers is the transcript; the report aggregates
+// the latency of the turns with the latency, the usage and the cost of the nodes, so that
+// running many concurrent sessions, see WithConcurrency, also load-tests the graph. As for Run,
+// the caller must drain the state monitor channel of the runtime.
+//
+// Parameters:
+//   - ctx: The context of the simulation, cancelling it cancels the running sessions.
+//   - runtime: The runtime of the agent under simulation, with its edges added; it must keep the
+//     conversation of a thread across the invocations.
+//   - users: The simulated users.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - The report of the simulation, a case per user; the failures of the sessions are reported, not returned.
+//   - An error if the runtime is nil, there is no user, a user has no function or an option is invalid.
+//
+// Example:
+//
+//	judge, _ := eval.Judge("resolution", ask, "The assistant solved the request of the user", eval.LastReply)
+//	report, err := eval.Simulate(ctx, runtime, users,
+//	    eval.WithScorers(judge),
+//	    eval.WithMaxTurns[a.Conversation](8),
+//	)
+//	fmt.Printf("pass rate %.0f%%, p95 turn %s\n", report.PassRate()*100, report.Turns.P95Latency)
+func Simulate(ctx context.Context, runtime g.Runtime[a.Conversation], users []SimulatedUser, opts ...Option[a.Conversation]) (Report[a.Conversation], error) {
+	if runtime == nil {
+		return Report[a.Conversation]{}, fmt.Errorf("simulation failed: %w", ErrRuntimeNil)
+	}
+	if len(users) == 0 {
+		return Report[a.Conversation]{}, fmt.Errorf("simulation failed: %w", ErrNoUsers)
+	}
+	for _, user := range users {
+		if user.Next == nil {
+			return Report[a.Conversation]{}, fmt.Errorf("simulation failed: user %q: %w", user.Name, ErrUserFnNil)
+		}
+	}
+	useOpts, err := createOptions(opts)
+	if err != nil {
+		return Report[a.Conversation]{}, fmt.Errorf("simulation failed: %w", err)
+	}
+
+	startedAt := time.Now()
+	results := make([]CaseResult[a.Conversation], len(users))
+	forEach(len(users), useOpts.Concurrency, func(idx int) {
+		results[idx] = runSession(ctx, runtime, users[idx], useOpts)
+	})
+
+	report := aggregate(results, useOpts)
+	report.Duration = time.Since(startedAt)
+	return report, nil
+}
+
+// runSession drives a session of a user on a new thread and scores its transcript.
+func runSession(ctx context.Context, runtime g.Runtime[a.Conversation], user SimulatedUser, opts *Options[a.Conversation]) CaseResult[a.Conversation] {
+	threadID := opts.ThreadPrefix + uuid.NewString()
+	rv := CaseResult[a.Conversation]{
+		Case:      Case[a.Conversation]{Name: user.Name, Expected: user.Expected, Metadata: user.Metadata},
+		ThreadID:  threadID,
+		NodeUsage: make(map[string]g.Usage),
+	}
+	entries, cancel := subscribe(runtime, threadID)
+	defer cancel()
+
+	var transcript []a.Message
+	seen := make(map[string]bool)
+	stateSize := 0
+	startedAt := time.Now()
+	for len(rv.Turns) < opts.MaxTurns {
+		if err := ctx.Err(); err != nil {
+			rv.Error = err
+			break
+		}
+		text, done, err := user.Next(ctx, slices.Clone(transcript))
+		if err != nil {
+			rv.Error = fmt.Errorf("simulated user %q failed: %w", user.Name, err)
+			break
+		}
+		if done {
+			break
+		}
+
+		message := a.CreateMessage(a.User, text)
+		seen[message.ID] = true
+		transcript = append(transcript, message)
+		result := invoke(ctx, runtime, entries, threadID, a.CreateConversation(message), user.Metadata, opts)
+
+		turn := Turn{Input: message, Duration: result.duration, Usage: result.usage, Error: result.err}
+		// The state of the thread holds the previous turns, and possibly the message of the user
+		for idx, reply := range result.output.Messages {
+			if reply.ID != "" {
+				if seen[reply.ID] {
+					continue
+				}
+				seen[reply.ID] = true
+			} else if idx < stateSize {
+				continue
+			}
+			turn.Replies = append(turn.Replies, reply)
+		}
+		stateSize = len(result.output.Messages)
+		transcript = append(transcript, turn.Replies...)
+		rv.Turns = append(rv.Turns, turn)
+
+		rv.Usage = rv.Usage.Add(result.usage)
+		for node, usage := range result.nodeUsage {
+			rv.NodeUsage[node] = rv.NodeUsage[node].Add(usage)
+		}
+		rv.Spans = append(rv.Spans, result.spans...)
+		if result.err != nil {
+			rv.Error = fmt.Errorf("turn %d failed: %w", len(rv.Turns), result.err)
+			break
+		}
+	}
+	rv.Duration = time.Since(startedAt)
+	rv.Output = a.Conversation{Messages: transcript}
+	rv.Cost = outcome[a.Conversation]{nodeUsage: rv.NodeUsage}.cost(opts)
+	if rv.Error != nil {
+		return rv
+	}
+	grade(ctx, &rv, opts.Scorers)
+	return rv
+}
@@ -0,0 +1,112 @@
+package eval_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/eval"
+)
+
+func TestSimulate(t *testing.T) {
+	runtime := createRuntime(t)
+	users := []eval.SimulatedUser{
+		eval.ScriptedUser("polite", "hello", "bye"),
+		eval.ScriptedUser("failing", "hello", "fail", "never sent"),
+		eval.ScriptedUser("chatty", "one", "two", "three", "four"),
+	}
+	users[0].Expected = "BYE"
+
+	report, err := eval.Simulate(context.Background(), runtime, users,
+		eval.WithScorers(eval.Contains("last", eval.LastReply)),
+		eval.WithMaxTurns[a.Conversation](3),
+	)
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+
+	polite := report.Cases[0]
+	if !polite.Pass || len(polite.Turns) != 2 || len(polite.Output.Messages) != 4 {
+		t.Fatalf("Expected a passing session of 2 turns, got %+v", polite)
+	}
+	roles := []a.MessageRole{a.User, a.Assistant, a.User, a.Assistant}
+	for idx, message := range polite.Output.Messages {
+		if message.Role != roles[idx] {
+			t.Errorf("Expected the transcript to alternate the user and the graph, got %+v", polite.Output.Messages)
+		}
+	}
+	if turn := polite.Turns[1]; turn.Input.Content != "bye" || len(turn.Replies) != 1 || turn.Replies[0].Content != "BYE" || turn.Usage.TotalTokens != 110 {
+		t.Errorf("Expected the second turn to answer only its own message, got %+v", turn)
+	}
+
+	if failing := report.Cases[1]; failing.Error == nil || len(failing.Turns) != 2 || failing.Turns[1].Error == nil {
+		t.Errorf("Expected the session to stop on the failed turn, got %+v", failing)
+	}
+	if chatty := report.Cases[2]; len(chatty.Turns) != 3 || chatty.Usage.TotalTokens != 330 {
+		t.Errorf("Expected the session to stop after the max turns, got %+v", chatty)
+	}
+
+	if report.Passed != 2 || report.Errored != 1 {
+		t.Errorf("Expected 2 passed and 1 errored session, got %d and %d", report.Passed, report.Errored)
+	}
+	if stats := report.Turns; stats.Turns != 7 || stats.Failures != 1 || stats.P95Latency == 0 || stats.AverageLatency() > stats.MaxLatency {
+		t.Errorf("Expected the aggregate of the 7 turns, got %+v", stats)
+	}
+	if node, ok := report.Node("Answer"); !ok || node.Executions != 7 || node.Usage.TotalTokens != 660 {
+		t.Errorf("Expected the executions of the node over the turns, got %+v", node)
+	}
+}
+
+func TestPersonaUser(t *testing.T) {
+	runtime := createRuntime(t)
+	var requests [][]a.Message
+	answers := []string{"hello", "what time is it?", "Thanks! " + eval.DoneToken}
+	ask := func(_ context.Context, messages []a.Message) (a.Message, error) {
+		requests = append(requests, messages)
+		answer := answers[0]
+		answers = answers[1:]
+		return a.CreateMessage(a.Assistant, answer), nil
+	}
+	user, err := eval.PersonaUser("curious", ask, "A curious user asking the time")
+	if err != nil {
+		t.Fatalf("PersonaUser failed: %v", err)
+	}
+
+	report, err := eval.Simulate(context.Background(), runtime, []eval.SimulatedUser{user})
+	if err != nil {
+		t.Fatalf("Simulate failed: %v", err)
+	}
+	if result := report.Cases[0]; !result.Pass || len(result.Turns) != 2 || eval.LastReply(result.Output) != "WHAT TIME IS IT?" {
+		t.Fatalf("Expected the persona to end the session after 2 turns, got %+v", result)
+	}
+
+	last := requests[2]
+	if last[0].Role != a.System || last[1].Role != a.User || len(last) != 6 {
+		t.Fatalf("Expected the system prompt, the kickoff and the transcript, got %+v", last)
+	}
+	if last[2].Role != a.Assistant || last[2].Content != "hello" || last[3].Role != a.User || last[3].Content != "HELLO" {
+		t.Errorf("Expected the roles of the transcript to be swapped, got %+v", last[2:])
+	}
+}
+
+func TestSimulate_Validation(t *testing.T) {
+	runtime := createRuntime(t)
+	user := eval.ScriptedUser("user", "hello")
+
+	if _, err := eval.Simulate(context.Background(), nil, []eval.SimulatedUser{user}); !errors.Is(err, eval.ErrRuntimeNil) {
+		t.Errorf("Expected ErrRuntimeNil, got %v", err)
+	}
+	if _, err := eval.Simulate(context.Background(), runtime, nil); !errors.Is(err, eval.ErrNoUsers) {
+		t.Errorf("Expected ErrNoUsers, got %v", err)
+	}
+	if _, err := eval.Simulate(context.Background(), runtime, []eval.SimulatedUser{{Name: "user"}}); !errors.Is(err, eval.ErrUserFnNil) {
+		t.Errorf("Expected ErrUserFnNil, got %v", err)
+	}
+	if _, err := eval.Simulate(context.Background(), runtime, []eval.SimulatedUser{user}, eval.WithMaxTurns[a.Conversation](0)); !errors.Is(err, eval.ErrInvalidMaxTurns) {
+		t.Errorf("Expected ErrInvalidMaxTurns, got %v", err)
+	}
+	if _, err := eval.PersonaUser("user", nil, "persona"); !errors.Is(err, eval.ErrAskFnNil) {
+		t.Errorf("Expected ErrAskFnNil, got %v", err)
+	}
+}
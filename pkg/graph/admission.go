@@ -0,0 +1,50 @@
+package graph
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrAdmissionQueueFull indicates that an invocation was rejected because the admission queue is full.
+var ErrAdmissionQueueFull = errors.New("admission queue is full")
+
+// AdmissionStats describes the admission of the invocations of a runtime.
+type AdmissionStats struct {
+	// Running is the number of invocations currently admitted.
+	Running int
+	// Queued is the number of invocations waiting for admission.
+	Queued int
+	// Admitted is the number of invocations admitted since the runtime creation.
+	Admitted uint64
+	// Rejected is the number of invocations rejected because the queue was full.
+	Rejected uint64
+	// TotalQueueWait is the time spent in the queue by the admitted invocations.
+	TotalQueueWait time.Duration
+	// MaxQueueWait is the longest time spent in the queue by an admitted invocation.
+	MaxQueueWait time.Duration
+}
+
+// AverageQueueWait returns the average time spent in the queue by the admitted invocations.
+func (s AdmissionStats) AverageQueueWait() time.Duration {
+	if s.Admitted == 0 {
+		return 0
+	}
+	return s.TotalQueueWait / time.Duration(s.Admitted)
+}
+
+// Admitting provides the admission metrics of a runtime.
+//
+// When RuntimeSettings.MaxConcurrentInvocations is set, the invocations exceeding it wait
+// in a queue ordered by priority, see InvokeConfigPriority, then by arrival.
+type Admitting interface {
+	// AdmissionStats returns a snapshot of the admission metrics.
+	//
+	// Returns:
+	//   - The admission metrics.
+	//
+	// Example:
+	//
+	//	stats := runtime.AdmissionStats()
+	//	log.Printf("queued %d, average wait %s", stats.Queued, stats.AverageQueueWait())
+	AdmissionStats() AdmissionStats
+}
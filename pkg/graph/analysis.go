@@ -0,0 +1,58 @@
+package graph
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrCycleDetected indicates that the graph has a cycle while cycles are not allowed, see WithAllowCycles.
+	ErrCycleDetected = errors.New("cycle detected")
+	// ErrMaxIterationsExceeded indicates that a node was executed more times than allowed within an invocation.
+	ErrMaxIterationsExceeded = errors.New("maximum number of iterations exceeded")
+	// ErrInvalidMaxIterations indicates that the maximum number of iterations is not positive.
+	ErrInvalidMaxIterations = errors.New("maximum number of iterations must be at least 1")
+)
+
+// Cycle is a closed path of the graph.
+type Cycle struct {
+	// Nodes are the names of the nodes along the cycle, the first node is not repeated at the end.
+	Nodes []string
+}
+
+// String returns the cycle as "A -> B -> A".
+func (c Cycle) String() string {
+	if len(c.Nodes) == 0 {
+		return ""
+	}
+	return strings.Join(append(append([]string{}, c.Nodes...), c.Nodes[0]), " -> ")
+}
+
+// Analysis is the result of the static analysis of a graph.
+type Analysis struct {
+	// Cycles are the cycles of the graph, one per back edge found by a depth-first traversal.
+	Cycles []Cycle
+}
+
+// HasCycles reports whether the graph has at least one cycle.
+func (a Analysis) HasCycles() bool {
+	return len(a.Cycles) > 0
+}
+
+// Analyzer provides the static analysis of a graph.
+type Analyzer interface {
+	// Analyze inspects the topology of the graph.
+	//
+	// Every cycle is reported once, through the edge closing it in a depth-first traversal
+	// of the nodes; a graph with cycles fails Validate unless created WithAllowCycles.
+	//
+	// Returns:
+	//   - The analysis of the graph.
+	//
+	// Example:
+	//
+	//	for _, cycle := range runtime.Analyze().Cycles {
+	//	    log.Printf("loop: %s", cycle)
+	//	}
+	Analyze() Analysis
+}
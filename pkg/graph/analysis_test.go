@@ -0,0 +1,28 @@
+package graph
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestCycle_String(t *testing.T) {
+	if s := (Cycle{Nodes: []string{"A", "B"}}).String(); s != "A -> B -> A" {
+		t.Errorf("Unexpected cycle string %q", s)
+	}
+	if s := (Cycle{}).String(); s != "" {
+		t.Errorf("Expected an empty string, got %q", s)
+	}
+}
+
+func TestWithAllowCycles(t *testing.T) {
+	opts := &RuntimeOptions[struct{}]{}
+	if err := WithAllowCycles[struct{}](0).Apply(opts); !errors.Is(err, ErrInvalidMaxIterations) {
+		t.Errorf("Expected ErrInvalidMaxIterations, got %v", err)
+	}
+	if err := WithAllowCycles[struct{}](7).Apply(opts); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !opts.AllowCycles || opts.MaxIterations != 7 {
+		t.Errorf("Expected cycles allowed with 7 iterations, got %+v", opts)
+	}
+}
@@ -0,0 +1,78 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrBatchFnNil indicates that the function processing the inputs collected by a batch node is nil.
+	ErrBatchFnNil = errors.New("batch function cannot be nil")
+	// ErrBatchUnbounded indicates that a batch node has neither a size nor a window.
+	ErrBatchUnbounded = errors.New("batch node requires a size or a window")
+	// ErrThreadNotBatching indicates that the thread is not collecting inputs in a batch node.
+	ErrThreadNotBatching = errors.New("thread is not batching")
+)
+
+// BatchFn processes the inputs collected by a batch node.
+//
+// Parameters:
+//   - ctx: The context of the invocation.
+//   - inputs: The collected inputs, in arrival order: the input of the invocation reaching the node first.
+//   - currentState: The current state of the thread.
+//
+// Returns:
+//   - The update of the node, reduced into the state.
+//   - An error if the batch cannot be processed, which will halt graph execution.
+//
+// Example:
+//
+//	func respond(ctx context.Context, inputs []ChatState, state ChatState) (ChatState, error) {
+//	    for _, input := range inputs {
+//	        state.Messages = append(state.Messages, input.Messages...)
+//	    }
+//	    return state, nil
+//	}
+type BatchFn[T SharedState] func(ctx context.Context, inputs []T, currentState T) (T, error)
+
+// Batcher is implemented by the nodes collecting the inputs of several invocations, see builders.NewBatchNode.
+type Batcher[T SharedState] interface {
+	// Batch processes the collected inputs.
+	Batch(ctx context.Context, inputs []T, currentState T) (T, error)
+	// BatchLimits returns how many inputs the node collects and for how long at most, zero meaning unbounded.
+	BatchLimits() (int, time.Duration)
+}
+
+// Batching provides the threads collecting inputs in the batch nodes.
+//
+// A thread reaching a batch node holds the node, with no goroutine, and is notified by a running
+// entry with Batched set. While it holds, Runtime.Invoke on the thread adds the user input to the
+// batch instead of being rejected with ErrRuntimeExecuting; the invoke configuration is ignored.
+// The node processes the batch once it holds the size inputs, or the window elapsed since the
+// thread reached it, then the invocation goes on. The collected inputs are kept in memory: a
+// resumed invocation starts a new batch.
+type Batching interface {
+	// Batched returns how many inputs the thread collected so far.
+	//
+	// Parameters:
+	//   - threadID: The thread to check.
+	//
+	// Returns:
+	//   - The number of collected inputs.
+	//   - true if the thread holds a batch node.
+	Batched(threadID string) (int, bool)
+	// Flush processes at once the inputs collected by the thread.
+	//
+	// Parameters:
+	//   - threadID: The thread to flush.
+	//
+	// Returns:
+	//   - An error wrapping ErrThreadNotBatching if the thread does not hold a batch node.
+	//
+	// Example:
+	//
+	//	// The user stopped typing, respond to the messages collected so far.
+	//	_ = runtime.Flush(threadID)
+	Flush(threadID string) error
+}
@@ -0,0 +1,78 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInvocationTimeout indicates that an invocation did not complete within its timeout, see InvokeConfigTimeout.
+	ErrInvocationTimeout = errors.New("invocation timed out")
+	// ErrBudgetExceeded indicates that the usage reported by an invocation exceeded its budget, see InvokeConfigBudget.
+	ErrBudgetExceeded = errors.New("invocation budget exceeded")
+)
+
+// Pricing converts the usage of the model calls into a cost.
+type Pricing struct {
+	// PromptPerMillion is the price of a million prompt tokens.
+	PromptPerMillion float64
+	// CompletionPerMillion is the price of a million completion tokens.
+	CompletionPerMillion float64
+}
+
+// Cost returns the price of the usage.
+//
+// Parameters:
+//   - usage: The consumed tokens.
+//
+// Returns:
+//   - The cost of the tokens, in the currency of the prices.
+func (p Pricing) Cost(usage Usage) float64 {
+	return (float64(usage.PromptTokens)*p.PromptPerMillion + float64(usage.CompletionTokens)*p.CompletionPerMillion) / 1_000_000
+}
+
+// Budget bounds the usage reported by the model calls of an invocation, see ReportUsage.
+//
+// The zero value is unlimited; every limit is checked as soon as the usage is reported, so
+// that a runaway agent loop is stopped by its next model call.
+type Budget struct {
+	// MaxTokens is the maximum number of tokens of the invocation, zero means unlimited.
+	MaxTokens int64
+	// MaxCost is the maximum cost of the invocation according to Pricing, zero means unlimited.
+	MaxCost float64
+	// Pricing prices the tokens for MaxCost.
+	Pricing Pricing
+}
+
+// IsZero reports whether the budget is unlimited.
+//
+// Returns:
+//   - true if no limit is set.
+func (b Budget) IsZero() bool {
+	return b.MaxTokens == 0 && b.MaxCost == 0
+}
+
+// Check verifies the usage against the budget.
+//
+// Parameters:
+//   - usage: The cumulative usage of the invocation.
+//
+// Returns:
+//   - An error wrapping ErrBudgetExceeded if a limit is exceeded, otherwise nil.
+//
+// Example:
+//
+//	budget := Budget{MaxCost: 0.50, Pricing: Pricing{PromptPerMillion: 0.15, CompletionPerMillion: 0.60}}
+//	if err := budget.Check(usage); err != nil {
+//	    log.Printf("too expensive: %v", err)
+//	}
+func (b Budget) Check(usage Usage) error {
+	tokens := max(usage.TotalTokens, usage.PromptTokens+usage.CompletionTokens)
+	if b.MaxTokens > 0 && tokens > b.MaxTokens {
+		return fmt.Errorf("%w: %d tokens, the maximum is %d", ErrBudgetExceeded, tokens, b.MaxTokens)
+	}
+	if cost := b.Pricing.Cost(usage); b.MaxCost > 0 && cost > b.MaxCost {
+		return fmt.Errorf("%w: cost %.4f, the maximum is %.4f", ErrBudgetExceeded, cost, b.MaxCost)
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package graph
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBudget_Check(t *testing.T) {
+	pricing := Pricing{PromptPerMillion: 1, CompletionPerMillion: 4}
+	if cost := pricing.Cost(Usage{PromptTokens: 500_000, CompletionTokens: 250_000}); cost != 1.5 {
+		t.Errorf("Expected a cost of 1.5, got %f", cost)
+	}
+
+	tests := []struct {
+		name     string
+		budget   Budget
+		usage    Usage
+		exceeded bool
+	}{
+		{"unlimited", Budget{}, Usage{TotalTokens: 1_000_000}, false},
+		{"tokens within", Budget{MaxTokens: 100}, Usage{TotalTokens: 100}, false},
+		{"tokens exceeded", Budget{MaxTokens: 100}, Usage{TotalTokens: 101}, true},
+		{"tokens without total", Budget{MaxTokens: 100}, Usage{PromptTokens: 80, CompletionTokens: 30}, true},
+		{"cost within", Budget{MaxCost: 1.5, Pricing: pricing}, Usage{PromptTokens: 500_000, CompletionTokens: 250_000}, false},
+		{"cost exceeded", Budget{MaxCost: 1, Pricing: pricing}, Usage{PromptTokens: 500_000, CompletionTokens: 250_000}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.budget.Check(tt.usage)
+			if errors.Is(err, ErrBudgetExceeded) != tt.exceeded {
+				t.Errorf("Expected exceeded %v, got %v", tt.exceeded, err)
+			}
+		})
+	}
+}
+
+func TestMergeInvokeConfig_Limits(t *testing.T) {
+	merged := MergeInvokeConfig(
+		InvokeConfigTimeout(time.Minute),
+		InvokeConfigBudget(Budget{MaxTokens: 10}),
+		InvokeConfigThreadID("thread-1"),
+	)
+	if merged.Timeout != time.Minute || merged.Budget.MaxTokens != 10 || merged.ThreadID != "thread-1" {
+		t.Errorf("Unexpected merged config %+v", merged)
+	}
+}
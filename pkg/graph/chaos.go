@@ -0,0 +1,49 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrInjectedFault indicates a failure injected by the runtime, see WithChaos.
+	ErrInjectedFault = errors.New("injected fault")
+	// ErrInvalidChaos indicates that the fault injection configuration is invalid.
+	ErrInvalidChaos = errors.New("invalid fault injection configuration")
+)
+
+// Chaos configures the faults injected by the runtime, for the resilience testing of the graphs and of their consumers.
+//
+// The faults are drawn from a generator seeded with Seed: a single-threaded test gets the same
+// faults at every run. The zero value injects nothing.
+type Chaos struct {
+	// NodeErrorRate is the probability for a node to fail with ErrInjectedFault instead of being executed, from 0 to 1.
+	NodeErrorRate float64
+	// Nodes restricts the node errors to the named nodes, all of them when empty.
+	Nodes []string
+	// PersistenceDelay delays every write of the states to the memory.
+	PersistenceDelay time.Duration
+	// MonitorDropRate is the probability for a monitor entry to be dropped, counted in MonitorStats.Dropped, from 0 to 1.
+	MonitorDropRate float64
+	// EvictionRate is the probability for a thread to be evicted as soon as an invocation completes, from 0 to 1.
+	EvictionRate float64
+	// Seed seeds the generator of the faults.
+	Seed int64
+}
+
+// Validate checks that the rates are probabilities and that the delay is not negative.
+//
+// Returns:
+//   - An error wrapping ErrInvalidChaos if the configuration is invalid, otherwise nil.
+func (c Chaos) Validate() error {
+	for name, rate := range map[string]float64{"node error": c.NodeErrorRate, "monitor drop": c.MonitorDropRate, "eviction": c.EvictionRate} {
+		if rate < 0 || rate > 1 {
+			return fmt.Errorf("%w: %s rate %v is not between 0 and 1", ErrInvalidChaos, name, rate)
+		}
+	}
+	if c.PersistenceDelay < 0 {
+		return fmt.Errorf("%w: negative persistence delay", ErrInvalidChaos)
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+package graph_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func TestChaos_Validate(t *testing.T) {
+	if err := (g.Chaos{NodeErrorRate: 0.5, MonitorDropRate: 1, PersistenceDelay: time.Second}).Validate(); err != nil {
+		t.Errorf("Expected a valid configuration, got %v", err)
+	}
+	for _, chaos := range []g.Chaos{
+		{NodeErrorRate: 1.5},
+		{MonitorDropRate: -0.1},
+		{EvictionRate: 2},
+		{PersistenceDelay: -time.Second},
+	} {
+		if err := chaos.Validate(); !errors.Is(err, g.ErrInvalidChaos) {
+			t.Errorf("Expected ErrInvalidChaos for %+v, got %v", chaos, err)
+		}
+	}
+
+	opts := &g.RuntimeOptions[g.SharedState]{}
+	if err := g.WithChaos[g.SharedState](g.Chaos{EvictionRate: 2}).Apply(opts); !errors.Is(err, g.ErrInvalidChaos) || opts.Chaos != nil {
+		t.Errorf("Expected WithChaos to reject the configuration, got %v", err)
+	}
+	if err := g.WithChaos[g.SharedState](g.Chaos{Seed: 7}).Apply(opts); err != nil || opts.Chaos == nil || opts.Chaos.Seed != 7 {
+		t.Errorf("Expected WithChaos to set the configuration, got %v", err)
+	}
+}
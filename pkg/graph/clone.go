@@ -0,0 +1,36 @@
+package graph
+
+// CloneFn returns a copy of the state which shares no mutable data, like maps and slices, with the original.
+type CloneFn[T SharedState] func(state T) T
+
+// Cloneable is implemented by states able to copy themselves.
+//
+// The runtime uses it when no CloneFn is configured.
+type Cloneable[T SharedState] interface {
+	// Clone returns a copy of the state which shares no mutable data with the original.
+	Clone() T
+}
+
+// CloneState copies the state with the given function, or with its Clone method when the function is nil.
+//
+// States which are neither cloned by the function nor Cloneable are returned as they are.
+//
+// Parameters:
+//   - state: The state to copy.
+//   - cloneFn: The optional clone function.
+//
+// Returns:
+//   - The copy of the state.
+//
+// Example:
+//
+//	snapshot := graph.CloneState(conversation, nil) // uses Conversation.Clone
+func CloneState[T SharedState](state T, cloneFn CloneFn[T]) T {
+	if cloneFn != nil {
+		return cloneFn(state)
+	}
+	if cloneable, ok := any(state).(Cloneable[T]); ok {
+		return cloneable.Clone()
+	}
+	return state
+}
@@ -0,0 +1,36 @@
+package graph
+
+import "testing"
+
+type cloneableState struct {
+	Items []string
+}
+
+func (s cloneableState) Clone() cloneableState {
+	s.Items = append([]string(nil), s.Items...)
+	return s
+}
+
+func TestCloneState(t *testing.T) {
+	original := cloneableState{Items: []string{"a"}}
+
+	clone := CloneState(original, nil)
+	clone.Items[0] = "b"
+	if original.Items[0] != "a" {
+		t.Error("Expected the Clone method to be used")
+	}
+
+	calls := 0
+	clone = CloneState(original, func(state cloneableState) cloneableState {
+		calls++
+		return cloneableState{Items: []string{"fn"}}
+	})
+	if calls != 1 || clone.Items[0] != "fn" {
+		t.Error("Expected the clone function to take precedence")
+	}
+
+	shared := map[string]int{"a": 1}
+	if got := CloneState(shared, nil); got["a"] != 1 {
+		t.Error("Expected a non cloneable state to be returned as it is")
+	}
+}
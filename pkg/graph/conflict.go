@@ -0,0 +1,97 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrStateConflict indicates that a node changed the state after a concurrent write to the same fields.
+	ErrStateConflict = errors.New("conflicting state write")
+	// ErrConflictResolverNil indicates that conflict detection was enabled without a resolver.
+	ErrConflictResolverNil = errors.New("conflict resolver cannot be nil")
+)
+
+// Conflict describes a state change based on a version of the state which was concurrently written.
+//
+// The runtime records the version of the thread state when a node is dispatched, that is the
+// base version of the node. When the change of the node is reduced, other changes may have been
+// written in the meantime, e.g. by a parallel branch: when they touched some of the fields the
+// node changes, the writes conflict.
+type Conflict[T SharedState] struct {
+	// ThreadID is the thread whose state is conflicting.
+	ThreadID string
+	// Node is the name of the node whose change conflicts.
+	Node string
+	// BaseVersion is the version of the state the node was dispatched with.
+	BaseVersion uint64
+	// CurrentVersion is the version of the state when the change is reduced.
+	CurrentVersion uint64
+	// Base is the state the node was dispatched with.
+	Base T
+	// Current is the state including the concurrent writes.
+	Current T
+	// Change is the change returned by the node.
+	Change T
+	// Fields are the names of the conflicting top level fields, empty when the state is not a struct.
+	Fields []string
+	// Reducer is the reducer of the node.
+	Reducer ReducerFn[T]
+}
+
+// Err returns the conflict as an error wrapping ErrStateConflict.
+//
+// Returns:
+//   - The error describing the conflict.
+func (c Conflict[T]) Err() error {
+	return fmt.Errorf("node %s based on version %d, current version %d, fields %v: %w", c.Node, c.BaseVersion, c.CurrentVersion, c.Fields, ErrStateConflict)
+}
+
+// ConflictResolverFn mediates a conflicting write, returning the state to store.
+//
+// Parameters:
+//   - conflict: The conflicting write.
+//
+// Returns:
+//   - The resolved state.
+//   - An error to fail the invocation.
+//
+// Example:
+//
+//	resolver := func(conflict Conflict[MyState]) (MyState, error) {
+//	    resolved := conflict.Reducer(conflict.Current, conflict.Change)
+//	    resolved.Retries = conflict.Current.Retries // keep the concurrent retries
+//	    return resolved, nil
+//	}
+type ConflictResolverFn[T SharedState] func(conflict Conflict[T]) (T, error)
+
+// ResolveLastWriterWins reduces the change on top of the concurrent writes, as without conflict detection.
+//
+// Returns:
+//   - The conflict resolver.
+func ResolveLastWriterWins[T SharedState]() ConflictResolverFn[T] {
+	return func(conflict Conflict[T]) (T, error) {
+		return conflict.Reducer(conflict.Current, conflict.Change), nil
+	}
+}
+
+// ResolveFirstWriterWins discards the conflicting change, keeping the concurrent writes.
+//
+// Returns:
+//   - The conflict resolver.
+func ResolveFirstWriterWins[T SharedState]() ConflictResolverFn[T] {
+	return func(conflict Conflict[T]) (T, error) {
+		return conflict.Current, nil
+	}
+}
+
+// ResolveFail fails the invocation with ErrStateConflict.
+//
+// Returns:
+//   - The conflict resolver.
+func ResolveFail[T SharedState]() ConflictResolverFn[T] {
+	return func(conflict Conflict[T]) (T, error) {
+		var zero T
+		return zero, conflict.Err()
+	}
+}
@@ -0,0 +1,35 @@
+package graph
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestConflictResolvers(t *testing.T) {
+	conflict := Conflict[int]{
+		Node:    "Node1",
+		Current: 2,
+		Change:  3,
+		Reducer: func(currentState, change int) int { return currentState + change },
+	}
+
+	if resolved, err := ResolveLastWriterWins[int]()(conflict); err != nil || resolved != 5 {
+		t.Errorf("Expected the change reduced on the current state, got %d, %v", resolved, err)
+	}
+	if resolved, err := ResolveFirstWriterWins[int]()(conflict); err != nil || resolved != 2 {
+		t.Errorf("Expected the current state, got %d, %v", resolved, err)
+	}
+	if _, err := ResolveFail[int]()(conflict); !errors.Is(err, ErrStateConflict) {
+		t.Errorf("Expected ErrStateConflict, got %v", err)
+	}
+}
+
+func TestWithConflictDetection(t *testing.T) {
+	opts := &RuntimeOptions[int]{}
+	if err := WithConflictDetection[int](nil).Apply(opts); !errors.Is(err, ErrConflictResolverNil) {
+		t.Errorf("Expected ErrConflictResolverNil, got %v", err)
+	}
+	if err := WithConflictDetection(ResolveFail[int]()).Apply(opts); err != nil || opts.ConflictResolver == nil {
+		t.Errorf("Expected the resolver to be set, got %v", err)
+	}
+}
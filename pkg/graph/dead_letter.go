@@ -0,0 +1,87 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrDeadLetterQueueFull indicates that the dead-letter queue cannot accept more entries.
+	ErrDeadLetterQueueFull = errors.New("dead-letter queue is full")
+	// ErrDeadLetterQueueNotSet indicates that the runtime has no dead-letter queue.
+	ErrDeadLetterQueueNotSet = errors.New("dead-letter queue is not set")
+	// ErrDeadLetterMemoryNil indicates that a memory-backed dead-letter queue has no memory.
+	ErrDeadLetterMemoryNil = errors.New("dead-letter queue memory cannot be nil")
+	// ErrInvalidDeadLetterQueueSize indicates that the size of a dead-letter queue is not positive.
+	ErrInvalidDeadLetterQueueSize = errors.New("dead-letter queue size must be at least 1")
+)
+
+// DeadLetter is a persistence job which could not be completed.
+type DeadLetter[T SharedState] struct {
+	// ThreadID is the identifier of the thread whose state was not persisted.
+	ThreadID string
+	// State is the state which was not persisted.
+	State T
+	// Seq orders the persistence jobs of the runtime, later jobs have higher values, also across restarts.
+	Seq uint64
+	// Err is the error of the last failed attempt.
+	Err error
+	// Attempts is the number of failed attempts.
+	Attempts int
+	// FirstFailedAt is the time of the first failed attempt.
+	FirstFailedAt time.Time
+	// LastFailedAt is the time of the last failed attempt.
+	LastFailedAt time.Time
+}
+
+// DeadLetterQueue holds the persistence jobs which failed or could not be enqueued.
+//
+// Implementations must be safe for concurrent use.
+type DeadLetterQueue[T SharedState] interface {
+	// Push adds an entry to the queue.
+	//
+	// Parameters:
+	//   - ctx: The context of the operation.
+	//   - entry: The failed persistence job.
+	//
+	// Returns:
+	//   - ErrDeadLetterQueueFull if the queue cannot accept the entry, or any storage error.
+	Push(ctx context.Context, entry DeadLetter[T]) error
+	// Pop removes and returns the oldest entry of the queue.
+	//
+	// Parameters:
+	//   - ctx: The context of the operation.
+	//
+	// Returns:
+	//   - The oldest entry.
+	//   - false if the queue is empty.
+	//   - An error if the entry cannot be retrieved.
+	Pop(ctx context.Context) (DeadLetter[T], bool, error)
+	// Len returns the number of entries in the queue.
+	Len() int
+}
+
+// DeadLettered provides access to the persistence jobs which could not be completed.
+type DeadLettered interface {
+	// ReprocessDeadLetters retries the persistence of the entries currently held by the dead-letter queue.
+	//
+	// Each entry is attempted once per call; entries failing again are pushed back with updated
+	// retry metadata. Entries superseded by a later successful persistence of the same thread are
+	// discarded, so that an old state never overwrites a newer one.
+	//
+	// Parameters:
+	//   - ctx: The context of the operation.
+	//
+	// Returns:
+	//   - The number of entries persisted.
+	//   - ErrDeadLetterQueueNotSet if no queue is configured, or an error describing the entries which failed again.
+	//
+	// Example:
+	//
+	//	persisted, err := runtime.ReprocessDeadLetters(ctx)
+	//	if err != nil {
+	//	    log.Printf("persisted %d dead letters, some failed again: %v", persisted, err)
+	//	}
+	ReprocessDeadLetters(ctx context.Context) (int, error)
+}
@@ -0,0 +1,61 @@
+package graph
+
+import "errors"
+
+// ErrThreadNotPaused indicates that the thread to resume is not paused at a breakpoint.
+var ErrThreadNotPaused = errors.New("thread is not paused")
+
+// Breakpoint describes a node about to be executed by a paused thread, see WithDebugger.
+type Breakpoint[T SharedState] struct {
+	// Input is the user input the node is going to be executed with.
+	Input T
+	// Edge is the edge selected to reach the node, the start edge for the first node.
+	Edge Edge[T]
+}
+
+// Debugger steps through the invocations of a runtime created with WithDebugger.
+//
+// A paused thread emits a monitor entry carrying the Breakpoint, named after the pending node,
+// and waits for Step or Continue; meanwhile CurrentState returns the state the node will be
+// executed with. Cancelling a paused thread, or exceeding its timeout or budget, terminates it
+// immediately.
+type Debugger[T SharedState] interface {
+	// Step executes the pending node of the paused thread and pauses again before the next one.
+	//
+	// Parameters:
+	//   - threadID: The identifier of the paused thread.
+	//
+	// Returns:
+	//   - An error wrapping ErrThreadNotPaused if the thread is not paused.
+	//
+	// Example:
+	//
+	//	for entry := range stateMonitorCh {
+	//	    if entry.Breakpoint != nil {
+	//	        fmt.Printf("next: %s with %+v\n", entry.Node, entry.NewState)
+	//	        _ = runtime.Step(entry.ThreadID)
+	//	    }
+	//	}
+	Step(threadID string) error
+
+	// Continue executes the pending node of the paused thread and runs until the next breakpoint
+	// node, or the end of the invocation when the debugger has no breakpoint nodes.
+	//
+	// Parameters:
+	//   - threadID: The identifier of the paused thread.
+	//
+	// Returns:
+	//   - An error wrapping ErrThreadNotPaused if the thread is not paused.
+	Continue(threadID string) error
+
+	// Paused returns the breakpoint the thread is paused at.
+	//
+	// Parameters:
+	//   - threadID: The identifier of the thread.
+	//
+	// Returns:
+	//   - The name of the pending node.
+	//   - The breakpoint.
+	//   - false if the thread is not paused.
+	Paused(threadID string) (string, Breakpoint[T], bool)
+}
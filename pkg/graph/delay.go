@@ -0,0 +1,71 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrDelayFnNil indicates that the function computing the delay of a node is nil.
+	ErrDelayFnNil = errors.New("delay function cannot be nil")
+	// ErrThreadNotSleeping indicates that the thread is not waiting for a delay node to wake up.
+	ErrThreadNotSleeping = errors.New("thread is not sleeping")
+)
+
+// DelayFn computes how long the thread waits before executing a delay node.
+//
+// The function runs while routing to the node, it must return quickly: the wait itself is
+// scheduled by the runtime.
+//
+// Parameters:
+//   - ctx: The context of the invocation.
+//   - userInput: The original input provided to Runtime.Invoke().
+//   - currentState: The current state of the thread.
+//
+// Returns:
+//   - The duration of the wait, zero or negative to go on at once.
+//   - An error if the duration cannot be computed, which will halt graph execution.
+//
+// Example:
+//
+//	func backoff(ctx context.Context, userInput, state MyState) (time.Duration, error) {
+//	    return time.Duration(state.Attempts) * 30 * time.Second, nil
+//	}
+type DelayFn[T SharedState] func(ctx context.Context, userInput, currentState T) (time.Duration, error)
+
+// Delayer is implemented by the nodes suspending the thread before being executed, see builders.NewDelayNode.
+type Delayer[T SharedState] interface {
+	// Delay computes how long the thread waits before executing the node.
+	Delay(ctx context.Context, userInput, currentState T) (time.Duration, error)
+}
+
+// Delaying provides the threads suspended by the delay nodes.
+//
+// A suspended thread holds no goroutine: the runtime schedules its wake-up, notified by a running
+// entry with WakeAt set. With a Journal, the wake-up time is journaled along with the step entering
+// the delay node, so that Durable.Resume waits until the original wake-up time after a crash.
+type Delaying interface {
+	// WakeAt returns the time the thread suspended by a delay node wakes up.
+	//
+	// Parameters:
+	//   - threadID: The thread to check.
+	//
+	// Returns:
+	//   - The wake-up time.
+	//   - true if the thread is sleeping.
+	WakeAt(threadID string) (time.Time, bool)
+	// WakeUp executes at once the delay node the thread is waiting for.
+	//
+	// Parameters:
+	//   - threadID: The thread to wake up.
+	//
+	// Returns:
+	//   - An error wrapping ErrThreadNotSleeping if the thread is not sleeping.
+	//
+	// Example:
+	//
+	//	// The webhook the polling loop waits for arrived.
+	//	_ = runtime.WakeUp(threadID)
+	WakeUp(threadID string) error
+}
@@ -0,0 +1,166 @@
+package graph
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// maxDiffDepth bounds the recursion of Diff, the deeper values are compared as a whole.
+const maxDiffDepth = 32
+
+// StateChange is a field of the state changed by a node.
+//
+// The values share the data of the states they come from: treat them as read-only.
+type StateChange struct {
+	// Path locates the field from the root of the state, e.g. `Messages[2].Content` or `Labels["env"]`; empty for the whole state.
+	Path string `json:"path"`
+	// Before is the value before the node, nil when the field was added.
+	Before any `json:"before"`
+	// After is the value after the node, nil when the field was removed.
+	After any `json:"after"`
+}
+
+// DiffFn computes the changes between two states, see Diff.
+type DiffFn[T SharedState] func(before, after T) []StateChange
+
+// Diff returns the fields which differ between two states.
+//
+// The states are walked by reflection: the exported fields of the structs, the keys of the maps,
+// the elements of the slices and arrays, through pointers and interfaces. The added and removed
+// elements are reported as a whole; the structs without exported fields, such as time.Time, and
+// the byte slices are compared as a whole; the functions and channels are ignored.
+//
+// Parameters:
+//   - before: The state before the change.
+//   - after: The state after the change.
+//
+// Returns:
+//   - The changes in the order of the fields, the map keys sorted; nil when the states are equal.
+//
+// Example:
+//
+//	for _, change := range graph.Diff(previous, entry.NewState) {
+//	    fmt.Printf("%s: %v -> %v\n", change.Path, change.Before, change.After)
+//	}
+func Diff[T SharedState](before, after T) []StateChange {
+	var rv []StateChange
+	diffValues(reflect.ValueOf(&before).Elem(), reflect.ValueOf(&after).Elem(), "", 0, &rv)
+	return rv
+}
+
+func diffValues(before, after reflect.Value, path string, depth int, changes *[]StateChange) {
+	changed := func() {
+		*changes = append(*changes, StateChange{Path: path, Before: valueOf(before), After: valueOf(after)})
+	}
+
+	if !before.IsValid() || !after.IsValid() {
+		if before.IsValid() != after.IsValid() {
+			changed()
+		}
+		return
+	}
+	if before.Type() != after.Type() {
+		changed()
+		return
+	}
+	if depth >= maxDiffDepth {
+		if !reflect.DeepEqual(before.Interface(), after.Interface()) {
+			changed()
+		}
+		return
+	}
+
+	switch before.Kind() {
+	case reflect.Struct:
+		if !hasExportedFields(before.Type()) {
+			if !reflect.DeepEqual(before.Interface(), after.Interface()) {
+				changed()
+			}
+			return
+		}
+		for i := range before.NumField() {
+			field := before.Type().Field(i)
+			if field.IsExported() {
+				diffValues(before.Field(i), after.Field(i), joinPath(path, field.Name), depth+1, changes)
+			}
+		}
+	case reflect.Pointer, reflect.Interface:
+		if before.IsNil() || after.IsNil() {
+			if before.IsNil() != after.IsNil() {
+				changed()
+			}
+			return
+		}
+		diffValues(before.Elem(), after.Elem(), path, depth+1, changes)
+	case reflect.Map:
+		keys := append(before.MapKeys(), after.MapKeys()...)
+		slices.SortFunc(keys, func(a, b reflect.Value) int { return strings.Compare(mapKey(a), mapKey(b)) })
+		keys = slices.CompactFunc(keys, func(a, b reflect.Value) bool { return mapKey(a) == mapKey(b) })
+		for _, key := range keys {
+			diffElements(before.MapIndex(key), after.MapIndex(key), path+"["+mapKey(key)+"]", depth, changes)
+		}
+	case reflect.Slice, reflect.Array:
+		if before.Type().Elem().Kind() == reflect.Uint8 {
+			if !reflect.DeepEqual(before.Interface(), after.Interface()) {
+				changed()
+			}
+			return
+		}
+		for i := range max(before.Len(), after.Len()) {
+			var beforeElem, afterElem reflect.Value
+			if i < before.Len() {
+				beforeElem = before.Index(i)
+			}
+			if i < after.Len() {
+				afterElem = after.Index(i)
+			}
+			diffElements(beforeElem, afterElem, fmt.Sprintf("%s[%d]", path, i), depth, changes)
+		}
+	case reflect.Func, reflect.Chan, reflect.UnsafePointer:
+	default:
+		if !reflect.DeepEqual(before.Interface(), after.Interface()) {
+			changed()
+		}
+	}
+}
+
+// diffElements compares the elements of a collection, reporting the added and removed ones as a whole.
+func diffElements(before, after reflect.Value, path string, depth int, changes *[]StateChange) {
+	if !before.IsValid() || !after.IsValid() {
+		*changes = append(*changes, StateChange{Path: path, Before: valueOf(before), After: valueOf(after)})
+		return
+	}
+	diffValues(before, after, path, depth+1, changes)
+}
+
+func valueOf(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
+
+func hasExportedFields(t reflect.Type) bool {
+	for i := range t.NumField() {
+		if t.Field(i).IsExported() {
+			return true
+		}
+	}
+	return false
+}
+
+func mapKey(key reflect.Value) string {
+	if key.Kind() == reflect.String {
+		return fmt.Sprintf("%q", key.String())
+	}
+	return fmt.Sprint(key.Interface())
+}
+
+func joinPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
@@ -0,0 +1,66 @@
+package graph_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+type diffMessage struct {
+	Role    string
+	Content string
+}
+
+type diffState struct {
+	Messages  []diffMessage
+	Labels    map[string]string
+	Parent    *diffMessage
+	UpdatedAt time.Time
+	Payload   []byte
+	Extra     any
+	hidden    int
+}
+
+func TestDiff(t *testing.T) {
+	now := time.Now()
+	before := diffState{
+		Messages: []diffMessage{{Role: "user", Content: "hi"}, {Role: "assistant", Content: "hel"}},
+		Labels:   map[string]string{"env": "dev", "team": "ai"},
+		Payload:  []byte("a"),
+		Extra:    1,
+		hidden:   1,
+	}
+	after := diffState{
+		Messages:  []diffMessage{{Role: "user", Content: "hi"}, {Role: "assistant", Content: "hello"}, {Role: "user", Content: "bye"}},
+		Labels:    map[string]string{"env": "prod", "owner": "me"},
+		Parent:    &diffMessage{Role: "system"},
+		UpdatedAt: now,
+		Payload:   []byte("b"),
+		Extra:     "one",
+		hidden:    2,
+	}
+
+	expected := []g.StateChange{
+		{Path: "Messages[1].Content", Before: "hel", After: "hello"},
+		{Path: "Messages[2]", Before: nil, After: diffMessage{Role: "user", Content: "bye"}},
+		{Path: `Labels["env"]`, Before: "dev", After: "prod"},
+		{Path: `Labels["owner"]`, Before: nil, After: "me"},
+		{Path: `Labels["team"]`, Before: "ai", After: nil},
+		{Path: "Parent", Before: (*diffMessage)(nil), After: after.Parent},
+		{Path: "UpdatedAt", Before: time.Time{}, After: now},
+		{Path: "Payload", Before: []byte("a"), After: []byte("b")},
+		{Path: "Extra", Before: 1, After: "one"},
+	}
+	if changes := g.Diff(before, after); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("Unexpected changes:\n%+v\nexpected:\n%+v", changes, expected)
+	}
+
+	if changes := g.Diff(before, before); changes != nil {
+		t.Errorf("Expected no change between equal states, got %+v", changes)
+	}
+	if changes := g.Diff[g.SharedState](1, 2); len(changes) != 1 || changes[0].Path != "" || changes[0].Before != 1 || changes[0].After != 2 {
+		t.Errorf("Expected the whole state to change, got %+v", changes)
+	}
+}
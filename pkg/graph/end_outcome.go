@@ -0,0 +1,25 @@
+package graph
+
+// OutcomeEdgeLabel is the label key naming the outcome of the end edges, see StateMonitorEntry.Outcome.
+const OutcomeEdgeLabel = "outcome"
+
+// OutcomeOf returns the outcome of the end edge.
+//
+// Parameters:
+//   - edge: The edge to check.
+//
+// Returns:
+//   - The outcome of the invocations completing through the edge.
+//   - true if the edge is an end edge labelled with a non-empty outcome.
+//
+// Example:
+//
+//	edge := builders.CreateOutcomeEdge(reviewNode, "approved")
+//	outcome, _ := graph.OutcomeOf(edge) // "approved"
+func OutcomeOf[T SharedState](edge Edge[T]) (string, bool) {
+	if edge == nil || edge.Role() != EndEdge {
+		return "", false
+	}
+	outcome, ok := edge.LabelByKey(OutcomeEdgeLabel)
+	return outcome, ok && outcome != ""
+}
@@ -0,0 +1,54 @@
+package graph
+
+import "errors"
+
+// EntryPointEdgeLabel is the label key naming the entry point a start edge leads through, see InvokeConfigEntryPoint.
+const EntryPointEdgeLabel = "entry_point"
+
+var (
+	// ErrUnknownEntryPoint indicates that an invocation requested an entry point the graph does not have.
+	ErrUnknownEntryPoint = errors.New("unknown entry point")
+	// ErrDuplicateEntryPoint indicates that several start edges name the same entry point.
+	ErrDuplicateEntryPoint = errors.New("duplicate entry point")
+)
+
+// EntryPointOf returns the name of the entry point of the start edge.
+//
+// Parameters:
+//   - edge: The edge to check.
+//
+// Returns:
+//   - The name of the entry point.
+//   - true if the edge is a start edge labelled with a non-empty entry point name.
+//
+// Example:
+//
+//	edge := builders.CreateEntryEdge("webhook", parseNode)
+//	name, _ := graph.EntryPointOf(edge) // "webhook"
+func EntryPointOf[T SharedState](edge Edge[T]) (string, bool) {
+	if edge == nil || edge.Role() != StartEdge {
+		return "", false
+	}
+	name, ok := edge.LabelByKey(EntryPointEdgeLabel)
+	return name, ok && name != ""
+}
+
+// InvokeConfigEntryPoint creates an InvokeConfig entering the graph through the named entry point.
+//
+// The graphs serving several related workflows over the same nodes add a start edge per entry
+// point, see builders.CreateEntryEdge; the invocations without entry point traverse the start
+// edge the runtime was created with. An unknown entry point fails the invocation with an error
+// wrapping ErrUnknownEntryPoint.
+//
+// Parameters:
+//   - name: The name of the entry point.
+//
+// Returns:
+//   - An InvokeConfig instance with the specified entry point.
+//
+// Example:
+//
+//	runtime.Invoke(event, graph.InvokeConfigEntryPoint("webhook"))
+func InvokeConfigEntryPoint(name string) InvokeConfig {
+	return InvokeConfig{EntryPoint: name}
+}
@@ -0,0 +1,189 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrorCode is the machine-readable kind of a runtime error, see ErrorCodeOf.
+type ErrorCode string
+
+const (
+	// CodeNodeFailed indicates that the function of a node returned an error.
+	CodeNodeFailed ErrorCode = "node_failed"
+	// CodeNodeTimeout indicates that a node did not accept its input within the accept timeout.
+	CodeNodeTimeout ErrorCode = "node_timeout"
+	// CodeInjectedFault indicates that the execution of a node was replaced by a fault, see WithChaos.
+	CodeInjectedFault ErrorCode = "injected_fault"
+	// CodeDispatchFailed indicates that the execution of a node could not be published to the workers, see WithWorkQueue.
+	CodeDispatchFailed ErrorCode = "dispatch_failed"
+
+	// CodeNoOutboundEdges indicates that a node has no outbound edge to route to.
+	CodeNoOutboundEdges ErrorCode = "no_outbound_edges"
+	// CodeNoRoutingPolicy indicates that a node has no routing policy.
+	CodeNoRoutingPolicy ErrorCode = "no_routing_policy"
+	// CodeNoEdgeSelected indicates that the routing policy of a node selected no edge and no fallback is set.
+	CodeNoEdgeSelected ErrorCode = "no_edge_selected"
+	// CodeNextNodeNil indicates that the selected edge leads to no node.
+	CodeNextNodeNil ErrorCode = "next_node_nil"
+	// CodeMaxIterations indicates that a node was executed more times than allowed in an invocation.
+	CodeMaxIterations ErrorCode = "max_iterations"
+
+	// CodePersistenceFailed indicates that the state of a thread could not be persisted.
+	CodePersistenceFailed ErrorCode = "persistence_failed"
+	// CodeRestoreFailed indicates that the state of a thread could not be restored.
+	CodeRestoreFailed ErrorCode = "restore_failed"
+
+	// CodeBudgetExceeded indicates that the usage of an invocation exceeded its budget, see InvokeConfigBudget.
+	CodeBudgetExceeded ErrorCode = "budget_exceeded"
+	// CodeInvocationTimeout indicates that an invocation did not complete within its timeout, see InvokeConfigTimeout.
+	CodeInvocationTimeout ErrorCode = "invocation_timeout"
+)
+
+// CodedError is implemented by the typed errors of the runtime.
+type CodedError interface {
+	error
+	// ErrorCode returns the machine-readable kind of the error.
+	ErrorCode() ErrorCode
+}
+
+// ErrorCodeOf returns the code of the innermost typed error in the chain of err, that is of its root
+// cause: a node failing because the budget of the invocation was exceeded is a CodeBudgetExceeded.
+//
+// Parameters:
+//   - err: The error to inspect, typically StateMonitorEntry.Error.
+//
+// Returns:
+//   - The code of the error, empty if err is nil or carries no typed error.
+//
+// Example:
+//
+//	switch graph.ErrorCodeOf(entry.Error) {
+//	case graph.CodeBudgetExceeded:
+//	    notifyBilling(entry.ThreadID)
+//	case graph.CodeNodeFailed:
+//	    retry(entry.ThreadID)
+//	}
+func ErrorCodeOf(err error) ErrorCode {
+	var code ErrorCode
+	var coded CodedError
+	for errors.As(err, &coded) {
+		code = coded.ErrorCode()
+		err = errors.Unwrap(coded)
+	}
+	return code
+}
+
+// NodeError is the failure of the execution of a node.
+//
+// Example:
+//
+//	var nodeErr *graph.NodeError
+//	if errors.As(entry.Error, &nodeErr) {
+//	    log.Printf("node %s of thread %s failed: %v", nodeErr.Node, nodeErr.ThreadID, nodeErr.Err)
+//	}
+type NodeError struct {
+	// Node is the name of the failed node.
+	Node string
+	// ThreadID is the thread the node was executed for.
+	ThreadID string
+	// Code is the kind of the failure.
+	Code ErrorCode
+	// Err is the error returned by the node, or the reason it was not executed.
+	Err error
+}
+
+func (e *NodeError) Error() string {
+	return fmt.Sprintf("error executing node %s: %v", e.Node, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *NodeError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns the kind of the failure.
+func (e *NodeError) ErrorCode() ErrorCode {
+	return e.Code
+}
+
+// RoutingError is the failure to route an invocation after a node.
+type RoutingError struct {
+	// Node is the name of the node the routing started from.
+	Node string
+	// ThreadID is the thread being routed.
+	ThreadID string
+	// Code is the kind of the failure.
+	Code ErrorCode
+	// Err is the underlying error, such as ErrNoOutboundEdges.
+	Err error
+}
+
+func (e *RoutingError) Error() string {
+	return fmt.Sprintf("routing error for node %s: %v", e.Node, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *RoutingError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns the kind of the failure.
+func (e *RoutingError) ErrorCode() ErrorCode {
+	return e.Code
+}
+
+// PersistenceError is the failure to persist or restore the state of a thread.
+type PersistenceError struct {
+	// Node is the name of the node, or of the runtime component, that triggered the operation.
+	Node string
+	// ThreadID is the thread whose state was persisted or restored.
+	ThreadID string
+	// Code is either CodePersistenceFailed or CodeRestoreFailed.
+	Code ErrorCode
+	// Err is the error returned by the persistence functions, or ErrPersistenceQueueFull.
+	Err error
+}
+
+func (e *PersistenceError) Error() string {
+	if e.Code == CodeRestoreFailed {
+		return fmt.Sprintf("state restoration failed: %v", e.Err)
+	}
+	return fmt.Sprintf("state persistence error: %v", e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *PersistenceError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns the kind of the failure.
+func (e *PersistenceError) ErrorCode() ErrorCode {
+	return e.Code
+}
+
+// BudgetError is the termination of an invocation that exceeded its budget or its timeout.
+type BudgetError struct {
+	// Node is the name of the node the invocation was stopped at.
+	Node string
+	// ThreadID is the thread of the stopped invocation.
+	ThreadID string
+	// Code is either CodeBudgetExceeded or CodeInvocationTimeout.
+	Code ErrorCode
+	// Err wraps ErrBudgetExceeded or ErrInvocationTimeout.
+	Err error
+}
+
+func (e *BudgetError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *BudgetError) Unwrap() error {
+	return e.Err
+}
+
+// ErrorCode returns the kind of the failure.
+func (e *BudgetError) ErrorCode() ErrorCode {
+	return e.Code
+}
@@ -0,0 +1,55 @@
+package graph_test
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func TestErrorCodeOf(t *testing.T) {
+	budgetErr := &g.BudgetError{Node: "Agent", ThreadID: "t1", Code: g.CodeBudgetExceeded, Err: fmt.Errorf("%w: 300 tokens", g.ErrBudgetExceeded)}
+
+	for _, tc := range []struct {
+		name     string
+		err      error
+		expected g.ErrorCode
+	}{
+		{"nil", nil, ""},
+		{"untyped", errors.New("boom"), ""},
+		{"node", &g.NodeError{Node: "Agent", Code: g.CodeNodeFailed, Err: errors.New("boom")}, g.CodeNodeFailed},
+		{"wrapped", fmt.Errorf("invocation context done: %w", budgetErr), g.CodeBudgetExceeded},
+		{"root cause", &g.NodeError{Node: "Agent", Code: g.CodeNodeFailed, Err: budgetErr}, g.CodeBudgetExceeded},
+		{"joined", errors.Join(errors.New("boom"), &g.PersistenceError{Code: g.CodeRestoreFailed, Err: errors.New("gone")}), g.CodeRestoreFailed},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if code := g.ErrorCodeOf(tc.err); code != tc.expected {
+				t.Errorf("Expected code %q, got %q", tc.expected, code)
+			}
+		})
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	routingErr := error(&g.RoutingError{Node: "Router", ThreadID: "t1", Code: g.CodeNoOutboundEdges, Err: g.ErrNoOutboundEdges})
+	if routingErr.Error() != "routing error for node Router: no outbound edges from node" || !errors.Is(routingErr, g.ErrNoOutboundEdges) {
+		t.Errorf("Unexpected routing error %q", routingErr)
+	}
+
+	nodeErr := error(&g.NodeError{Node: "Agent", Code: g.CodeNodeFailed, Err: errors.New("boom")})
+	if nodeErr.Error() != "error executing node Agent: boom" {
+		t.Errorf("Unexpected node error %q", nodeErr)
+	}
+
+	persistErr := &g.PersistenceError{Code: g.CodePersistenceFailed, Err: g.ErrPersistenceQueueFull}
+	restoreErr := &g.PersistenceError{Code: g.CodeRestoreFailed, Err: errors.New("gone")}
+	if persistErr.Error() != "state persistence error: "+g.ErrPersistenceQueueFull.Error() || restoreErr.Error() != "state restoration failed: gone" {
+		t.Errorf("Unexpected persistence errors %q, %q", persistErr, restoreErr)
+	}
+
+	var target *g.RoutingError
+	if !errors.As(fmt.Errorf("context: %w", routingErr), &target) || target.ThreadID != "t1" {
+		t.Errorf("Expected the routing error in the chain, got %v", target)
+	}
+}
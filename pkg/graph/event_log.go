@@ -0,0 +1,92 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrEventStoreNotSet indicates that the runtime has no event store.
+	ErrEventStoreNotSet = errors.New("event store is not set")
+	// ErrEventStoreNil indicates that a nil event store was given.
+	ErrEventStoreNil = errors.New("event store cannot be nil")
+)
+
+// Event is a monitor entry recorded by the event store.
+type Event[T SharedState] struct {
+	// Seq orders the events of the store, assigned when the event is appended.
+	Seq uint64 `json:"seq"`
+	// ThreadID is the thread the event belongs to.
+	ThreadID string `json:"thread_id"`
+	// Node is the name of the node which produced the event.
+	Node string `json:"node"`
+	// Timestamp is the time the event was published.
+	Timestamp time.Time `json:"timestamp"`
+	// State is the state carried by the monitor entry.
+	State T `json:"state"`
+	// Error is the message of the error of the monitor entry, empty when none.
+	Error string `json:"error,omitempty"`
+	// ErrorCode is the machine-readable kind of the error, empty when none.
+	ErrorCode ErrorCode `json:"error_code,omitempty"`
+	// Running is false for the entry terminating the invocation.
+	Running bool `json:"running"`
+	// Partial is true for the partial state updates.
+	Partial bool `json:"partial"`
+	// Compensation is true for the compensations run after a failure.
+	Compensation bool `json:"compensation,omitempty"`
+	// Outcome is the outcome of the completed invocation.
+	Outcome string `json:"outcome,omitempty"`
+	// Usage is the cumulative usage of the invocation.
+	Usage Usage `json:"usage"`
+}
+
+// EventStore is an append-only log of the events of a runtime.
+//
+// Implementations must be safe for concurrent use.
+type EventStore[T SharedState] interface {
+	// Append records the event, assigning its sequence.
+	//
+	// Parameters:
+	//   - ctx: The context of the operation.
+	//   - event: The event to record.
+	//
+	// Returns:
+	//   - An error if the event cannot be recorded.
+	Append(ctx context.Context, event Event[T]) error
+	// Events returns the events of the thread recorded at or after the given time, oldest first.
+	//
+	// Parameters:
+	//   - ctx: The context of the operation.
+	//   - threadID: The thread whose events are requested.
+	//   - since: The lower bound of the event timestamps, the zero time for all the events.
+	//
+	// Returns:
+	//   - The events.
+	//   - An error if the events cannot be read.
+	Events(ctx context.Context, threadID string, since time.Time) ([]Event[T], error)
+}
+
+// EventLogged provides the audit trail of the threads.
+type EventLogged[T SharedState] interface {
+	// Events returns the events of the thread recorded at or after the given time, oldest first.
+	//
+	// Every monitor entry, including the partial updates and the non-fatal errors, is appended
+	// to the event store configured with WithEventStore as it is published.
+	//
+	// Parameters:
+	//   - threadID: The thread whose events are requested.
+	//   - since: The lower bound of the event timestamps, the zero time for all the events.
+	//
+	// Returns:
+	//   - The events.
+	//   - ErrEventStoreNotSet if no event store is configured, or the error of the store.
+	//
+	// Example:
+	//
+	//	events, err := runtime.Events(threadID, time.Now().Add(-time.Hour))
+	//	for _, event := range events {
+	//	    log.Printf("%s %s error=%q", event.Timestamp, event.Node, event.Error)
+	//	}
+	Events(threadID string, since time.Time) ([]Event[T], error)
+}
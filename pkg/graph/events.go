@@ -0,0 +1,79 @@
+package graph
+
+import "slices"
+
+// DefaultSubscriptionBufferSize is the capacity of the subscription channels when EventFilter.BufferSize is not set.
+const DefaultSubscriptionBufferSize = 100
+
+// EventFilter selects the monitor entries delivered to a subscription.
+//
+// The zero value delivers every entry.
+type EventFilter[T SharedState] struct {
+	// ThreadIDs are the threads to follow, all threads when empty.
+	ThreadIDs []string
+	// SkipPartial excludes the partial state updates.
+	SkipPartial bool
+	// Match is an optional predicate the entries must satisfy.
+	Match func(entry StateMonitorEntry[T]) bool
+	// BufferSize is the capacity of the subscription channel, DefaultSubscriptionBufferSize when not positive.
+	BufferSize int
+}
+
+// Accepts reports whether the entry satisfies the filter.
+//
+// Parameters:
+//   - entry: The monitor entry.
+//
+// Returns:
+//   - true if the entry is to be delivered.
+func (f EventFilter[T]) Accepts(entry StateMonitorEntry[T]) bool {
+	if len(f.ThreadIDs) > 0 && !slices.Contains(f.ThreadIDs, entry.ThreadID) {
+		return false
+	}
+	if f.SkipPartial && entry.Partial {
+		return false
+	}
+	return f.Match == nil || f.Match(entry)
+}
+
+// EventStats describes the delivery of the monitor entries to the subscriptions.
+type EventStats struct {
+	// Subscribers is the number of active subscriptions.
+	Subscribers int
+	// Delivered is the number of entries delivered to the subscriptions.
+	Delivered uint64
+	// Dropped is the number of entries dropped because the channel of a subscription was full.
+	Dropped uint64
+}
+
+// EventBus fans the monitor entries out to independent subscriptions.
+//
+// Unlike the state monitor channel given at the runtime creation, a slow subscription never
+// delays the runtime nor the other subscriptions: the entries it cannot receive are dropped
+// and accounted in EventStats.
+type EventBus[T SharedState] interface {
+	// Subscribe creates a subscription receiving the entries accepted by the filter.
+	//
+	// The channel is closed when the subscription is cancelled or the runtime shuts down.
+	//
+	// Parameters:
+	//   - filter: The filter of the entries.
+	//
+	// Returns:
+	//   - The channel of the entries.
+	//   - The function cancelling the subscription, safe to call more than once.
+	//
+	// Example:
+	//
+	//	entries, cancel := runtime.Subscribe(EventFilter[MyState]{ThreadIDs: []string{threadID}, SkipPartial: true})
+	//	defer cancel()
+	//	for entry := range entries {
+	//	    log.Printf("node %s: %+v", entry.Node, entry.NewState)
+	//	}
+	Subscribe(filter EventFilter[T]) (<-chan StateMonitorEntry[T], func())
+	// EventStats returns a snapshot of the delivery metrics.
+	//
+	// Returns:
+	//   - The delivery metrics.
+	EventStats() EventStats
+}
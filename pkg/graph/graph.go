@@ -2,6 +2,7 @@ package graph
 
 import (
 	"context"
+	"time"
 )
 
 // NotifyPartialFn is a callback function for sending partial state updates during node execution.
@@ -52,6 +53,77 @@ type NotifyPartialFn[T SharedState] func(newState T)
 //	}
 type NodeFn[T SharedState] func(userInput, currentState T, notify NotifyPartialFn[T]) (T, error)
 
+// ContextNodeFn is a NodeFn that also receives the context of the invocation.
+//
+// The context is the one provided with InvokeConfigContext, it is cancelled when the
+// invocation is aborted and should be propagated to long-running operations.
+//
+// Parameters:
+//   - ctx: The context of the invocation.
+//   - userInput: The original input provided to Runtime.Invoke(), unchanged throughout execution.
+//   - currentState: The current state at the time this node executes.
+//   - notify: A callback function to send partial state updates during processing.
+//
+// Returns:
+//   - The updated state after processing.
+//   - An error if processing failed, which will halt graph execution.
+//
+// Example:
+//
+//	func fetchNode(ctx context.Context, userInput MyState, currentState MyState, notify NotifyPartialFn[MyState]) (MyState, error) {
+//	    req, _ := http.NewRequestWithContext(ctx, http.MethodGet, currentState.URL, nil)
+//	    // ...
+//	    return currentState, nil
+//	}
+type ContextNodeFn[T SharedState] func(ctx context.Context, userInput, currentState T, notify NotifyPartialFn[T]) (T, error)
+
+// StartFn initializes the state of an invocation, run by the implicit start node.
+//
+// It normalizes and validates the user input into the state before the first
+// operational node executes; an error fails the invocation.
+//
+// Parameters:
+//   - ctx: The context of the invocation.
+//   - userInput: The original input provided to Runtime.Invoke().
+//   - currentState: The state of the thread before the invocation.
+//
+// Returns:
+//   - The initialized state.
+//   - An error if the user input is invalid.
+//
+// Example:
+//
+//	func startFn(ctx context.Context, userInput MyState, currentState MyState) (MyState, error) {
+//	    if strings.TrimSpace(userInput.Question) == "" {
+//	        return currentState, errors.New("empty question")
+//	    }
+//	    currentState.Question = strings.TrimSpace(userInput.Question)
+//	    return currentState, nil
+//	}
+type StartFn[T SharedState] func(ctx context.Context, userInput, currentState T) (T, error)
+
+// EndFn post-processes the final state of an invocation, run by the implicit end node.
+//
+// It runs once the graph reaches the end edge it is attached to, before the state is
+// persisted and the completion is notified; an error fails the invocation.
+//
+// Parameters:
+//   - ctx: The context of the invocation.
+//   - userInput: The original input provided to Runtime.Invoke().
+//   - finalState: The state produced by the last operational node.
+//
+// Returns:
+//   - The post-processed state.
+//   - An error if the post-processing failed.
+//
+// Example:
+//
+//	func endFn(ctx context.Context, userInput MyState, finalState MyState) (MyState, error) {
+//	    finalState.Report = render(finalState)
+//	    return finalState, nil
+//	}
+type EndFn[T SharedState] func(ctx context.Context, userInput, finalState T) (T, error)
+
 // EdgeSelectionFn is a function that determines which edge to follow during graph execution.
 //
 // This function implements the routing logic for conditional branching, loops, and
@@ -173,7 +245,8 @@ type StateObserver[T SharedState] interface {
 	//   - partial: true if this is a partial update, false if final.
 	NotifyStateChange(node Node[T], config InvokeConfig, userInput, stateChange T, reducer ReducerFn[T], err error, partial bool)
 
-	// CurrentState returns the current state for the given thread ID.
+	// CurrentState returns the current state for the given thread ID, or its streaming view while
+	// a node streams partial updates, see WithStreamingView.
 	//
 	// Parameters:
 	//   - threadID: Unique identifier for the thread instance.
@@ -214,6 +287,59 @@ type Persistent[T SharedState] interface {
 	//	}
 	//	runtime.Invoke(userInput)
 	Restore(threadID string) error
+
+	// Fork copies the state of a thread into a new thread, to explore an alternative continuation.
+	//
+	// The fork starts from the last committed state of the thread, the one after its last
+	// completed node, even while the thread is executing; the state is copied with the CloneFn
+	// or the Clone method, so that the two threads share no mutable data. A thread which is not
+	// active is restored from the memory. With a memory, the fork is persisted before Fork
+	// returns, as its first checkpoint. The fork has its own history: the step count, the
+	// events and the pending breakpoints of the thread are not copied.
+	//
+	// Parameters:
+	//   - threadID: The thread to fork.
+	//
+	// Returns:
+	//   - The identifier of the new thread.
+	//   - ErrUnknownThreadID if the thread is neither active nor in the memory, or an error if the fork cannot be persisted.
+	//
+	// Example:
+	//
+	//	branchID, err := runtime.Fork(threadID)
+	//	if err != nil {
+	//	    log.Fatal(err)
+	//	}
+	//	runtime.Invoke(alternativeInput, graph.InvokeConfigThreadID(branchID))
+	Fork(threadID string) (string, error)
+
+	// MergeThreads combines the state of a thread into another one, e.g. to keep the best of two forks.
+	//
+	// The merge function receives the committed state of the destination as the current state and
+	// the one of the source as the change, both copies; its result becomes the committed state of
+	// the destination, persisted before MergeThreads returns when the runtime has a memory. The
+	// source is left untouched. Threads which are not active are restored from the memory. The merge
+	// is reported by a monitor entry of the destination, with MergeNodeName as node and the changes
+	// it made to the state.
+	//
+	// Parameters:
+	//   - dst: The thread receiving the merged state; it cannot be executing.
+	//   - src: The thread to merge into the destination.
+	//   - mergeFn: The function combining the two states.
+	//
+	// Returns:
+	//   - ErrMergeFnNil if mergeFn is nil, ErrUnknownThreadID if a thread is neither active nor in the memory,
+	//     ErrRuntimeExecuting if the destination is executing, or an error if the merged state cannot be persisted.
+	//
+	// Example:
+	//
+	//	err := runtime.MergeThreads(threadID, branchID, func(current, branch MyState) MyState {
+	//	    if branch.Score > current.Score {
+	//	        return branch
+	//	    }
+	//	    return current
+	//	})
+	MergeThreads(dst, src string, mergeFn ReducerFn[T]) error
 }
 
 // Threaded is an interface for retrieving active thread identifiers in a runtime.
@@ -234,4 +360,155 @@ type Threaded interface {
 	//	    fmt.Println("Active thread:", threadID)
 	//	}
 	ListThreads() []string
+
+	// DescribeThread returns the metadata of an active thread.
+	//
+	// Parameters:
+	//   - threadID: The identifier of the thread.
+	//
+	// Returns:
+	//   - The ThreadInfo of the thread.
+	//   - ErrUnknownThreadID if the thread is not active.
+	//
+	// Example:
+	//
+	//	info, err := runtime.DescribeThread("thread-123")
+	//	if err == nil && info.Executing {
+	//	    fmt.Printf("thread busy since %v, %d steps\n", info.LastActiveAt, info.Steps)
+	//	}
+	DescribeThread(threadID string) (ThreadInfo, error)
+
+	// QueryThreads returns a page of active threads matching the query.
+	//
+	// Threads are ordered by creation time, then by identifier, so that pages are stable
+	// while threads are neither created nor evicted.
+	//
+	// Parameters:
+	//   - query: The filters and pagination of the listing.
+	//
+	// Returns:
+	//   - A ThreadPage with the matching threads.
+	//
+	// Example:
+	//
+	//	page := runtime.QueryThreads(ThreadQuery{Limit: 50, ExecutingOnly: true})
+	//	for page.HasMore {
+	//	    page = runtime.QueryThreads(ThreadQuery{Offset: page.NextOffset, Limit: 50, ExecutingOnly: true})
+	//	}
+	QueryThreads(query ThreadQuery) ThreadPage
+
+	// NewThreadID returns a new thread identifier from the ID generator of the runtime.
+	//
+	// It is meant for the callers which need the identifier before invoking, e.g. to subscribe to
+	// the events of the thread; Invoke generates one itself when the configuration has none.
+	//
+	// Returns:
+	//   - A new unique thread identifier.
+	//
+	// Example:
+	//
+	//	threadID := runtime.NewThreadID()
+	//	entries, cancel := runtime.Subscribe(EventFilter[MyState]{ThreadIDs: []string{threadID}})
+	//	defer cancel()
+	//	runtime.Invoke(userInput, InvokeConfigThreadID(threadID))
+	NewThreadID() string
+
+	// AliasThread gives a human-readable name to a thread, resolvable with ResolveThread.
+	//
+	// A thread can have several aliases, an alias names a single thread. The aliases outlive the
+	// eviction of their threads, so that the threads of a memory stay reachable by name, until
+	// they are removed with UnaliasThread.
+	//
+	// Parameters:
+	//   - threadID: The identifier of the thread.
+	//   - alias: The name of the thread.
+	//
+	// Returns:
+	//   - ErrThreadAliasEmpty if the alias is empty, or ErrThreadAliasInUse if it names another thread.
+	//
+	// Example:
+	//
+	//	threadID := runtime.Invoke(userInput)
+	//	_ = runtime.AliasThread(threadID, "support/ticket-4312")
+	AliasThread(threadID, alias string) error
+
+	// UnaliasThread removes an alias of a thread.
+	//
+	// Parameters:
+	//   - alias: The alias to remove.
+	//
+	// Returns:
+	//   - ErrUnknownThreadAlias if the alias names no thread.
+	//
+	// Example:
+	//
+	//	_ = runtime.UnaliasThread("support/ticket-4312")
+	UnaliasThread(alias string) error
+
+	// ResolveThread returns the identifier of the thread named by an alias, or the identifier itself for an active thread.
+	//
+	// Parameters:
+	//   - aliasOrID: An alias or the identifier of an active thread.
+	//
+	// Returns:
+	//   - The identifier of the thread.
+	//   - ErrUnknownThreadID if it is neither an alias nor an active thread.
+	//
+	// Example:
+	//
+	//	threadID, err := runtime.ResolveThread("support/ticket-4312")
+	//	if err == nil {
+	//	    runtime.Invoke(followUp, InvokeConfigThreadID(threadID))
+	//	}
+	ResolveThread(aliasOrID string) (string, error)
+}
+
+// ThreadInfo holds the metadata of an active thread.
+type ThreadInfo struct {
+	// ID is the identifier of the thread.
+	ID string
+	// Tenant is the tenant the thread belongs to, empty for the threads without a tenant.
+	Tenant string
+	// CreatedAt is the time of the first invocation of the thread in the runtime.
+	CreatedAt time.Time
+	// LastActiveAt is the time of the last invocation or node execution of the thread.
+	LastActiveAt time.Time
+	// ExpiresAt is the time after which the thread is evicted if it stays inactive.
+	ExpiresAt time.Time
+	// Executing is true while an invocation of the thread is running.
+	Executing bool
+	// Steps is the number of node executions completed by the thread.
+	Steps int64
+	// GraphVersion is the version of the graph the running invocation started with, 0 when not executing.
+	GraphVersion uint64
+	// Aliases are the human-readable names of the thread, sorted, see AliasThread.
+	Aliases []string
+}
+
+// ThreadQuery defines the filters and pagination of a thread listing.
+type ThreadQuery struct {
+	// Offset is the number of matching threads to skip.
+	Offset int
+	// Limit is the maximum number of threads to return, 0 means no limit.
+	Limit int
+	// ExecutingOnly restricts the listing to threads with a running invocation.
+	ExecutingOnly bool
+	// Tenant restricts the listing to the threads of a tenant, if not empty.
+	Tenant string
+	// ActiveSince restricts the listing to threads active after the given time, if not zero.
+	ActiveSince time.Time
+	// Filter is an optional custom predicate applied to each thread.
+	Filter func(info ThreadInfo) bool
+}
+
+// ThreadPage is a page of a thread listing.
+type ThreadPage struct {
+	// Threads holds the threads of the page.
+	Threads []ThreadInfo
+	// Total is the number of threads matching the query, across all pages.
+	Total int
+	// NextOffset is the offset of the next page.
+	NextOffset int
+	// HasMore is true when further pages are available.
+	HasMore bool
 }
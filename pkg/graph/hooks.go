@@ -0,0 +1,103 @@
+package graph
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrHookPanic indicates that a runtime hook panicked.
+var ErrHookPanic = errors.New("runtime hook panicked")
+
+// NodeHookFn is called around the execution of a node, an error fails the invocation.
+type NodeHookFn[T SharedState] func(ctx context.Context, threadID string, node string, state T) error
+
+// RouteHookFn is called when the runtime routes the thread from a node to the next one.
+type RouteHookFn[T SharedState] func(ctx context.Context, threadID string, from string, to string, state T)
+
+// ErrorHookFn is called when an invocation fails.
+type ErrorHookFn[T SharedState] func(ctx context.Context, threadID string, node string, state T, err error)
+
+// CompleteHookFn is called when an invocation reaches an end node.
+type CompleteHookFn[T SharedState] func(ctx context.Context, threadID string, node string, state T)
+
+// Hooks are callbacks invoked by the runtime along the execution of a thread, for cross-cutting
+// concerns like auditing, validation and metrics; nil callbacks are skipped.
+//
+// The callbacks run synchronously on the runtime outcome loop and receive the invocation context:
+// they must be fast and must not invoke the runtime. A panicking callback is recovered, it fails
+// the invocation from BeforeNode and AfterNode and is reported as a non-fatal error otherwise.
+type Hooks[T SharedState] struct {
+	// BeforeNode is called before a node is scheduled, with the current state of the thread.
+	BeforeNode NodeHookFn[T]
+	// AfterNode is called after the outcome of a node is reduced into the state.
+	AfterNode NodeHookFn[T]
+	// OnRoute is called when the next node has been selected.
+	OnRoute RouteHookFn[T]
+	// OnError is called when the invocation fails, with the node which failed.
+	OnError ErrorHookFn[T]
+	// OnComplete is called when the invocation completes, with the end node and the final state.
+	OnComplete CompleteHookFn[T]
+}
+
+// ChainHooks combines several hooks, the callbacks are called in the given order.
+//
+// BeforeNode and AfterNode stop at the first error.
+//
+// Parameters:
+//   - hooks: The hooks to combine.
+//
+// Returns:
+//   - The combined hooks.
+//
+// Example:
+//
+//	hooks := graph.ChainHooks(auditHooks, metricsHooks)
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, graph.WithHooks(hooks))
+func ChainHooks[T SharedState](hooks ...Hooks[T]) Hooks[T] {
+	rv := Hooks[T]{}
+	for _, h := range hooks {
+		rv.BeforeNode = chainNodeHook(rv.BeforeNode, h.BeforeNode)
+		rv.AfterNode = chainNodeHook(rv.AfterNode, h.AfterNode)
+
+		if prev, next := rv.OnRoute, h.OnRoute; next != nil {
+			rv.OnRoute = func(ctx context.Context, threadID, from, to string, state T) {
+				if prev != nil {
+					prev(ctx, threadID, from, to, state)
+				}
+				next(ctx, threadID, from, to, state)
+			}
+		}
+		if prev, next := rv.OnError, h.OnError; next != nil {
+			rv.OnError = func(ctx context.Context, threadID, node string, state T, err error) {
+				if prev != nil {
+					prev(ctx, threadID, node, state, err)
+				}
+				next(ctx, threadID, node, state, err)
+			}
+		}
+		if prev, next := rv.OnComplete, h.OnComplete; next != nil {
+			rv.OnComplete = func(ctx context.Context, threadID, node string, state T) {
+				if prev != nil {
+					prev(ctx, threadID, node, state)
+				}
+				next(ctx, threadID, node, state)
+			}
+		}
+	}
+	return rv
+}
+
+func chainNodeHook[T SharedState](prev, next NodeHookFn[T]) NodeHookFn[T] {
+	if next == nil {
+		return prev
+	}
+	if prev == nil {
+		return next
+	}
+	return func(ctx context.Context, threadID, node string, state T) error {
+		if err := prev(ctx, threadID, node, state); err != nil {
+			return err
+		}
+		return next(ctx, threadID, node, state)
+	}
+}
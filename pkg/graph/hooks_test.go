@@ -0,0 +1,59 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestChainHooks(t *testing.T) {
+	var calls []string
+	errStop := errors.New("stop")
+
+	hooks := ChainHooks(
+		Hooks[SharedState]{
+			BeforeNode: func(ctx context.Context, threadID, node string, state SharedState) error {
+				calls = append(calls, "first")
+				return nil
+			},
+			OnComplete: func(ctx context.Context, threadID, node string, state SharedState) {
+				calls = append(calls, "first complete")
+			},
+		},
+		Hooks[SharedState]{},
+		Hooks[SharedState]{
+			BeforeNode: func(ctx context.Context, threadID, node string, state SharedState) error {
+				calls = append(calls, "second")
+				return errStop
+			},
+			OnComplete: func(ctx context.Context, threadID, node string, state SharedState) {
+				calls = append(calls, "second complete")
+			},
+		},
+		Hooks[SharedState]{
+			BeforeNode: func(ctx context.Context, threadID, node string, state SharedState) error {
+				calls = append(calls, "third")
+				return nil
+			},
+		},
+	)
+
+	if err := hooks.BeforeNode(context.Background(), "t", "n", nil); !errors.Is(err, errStop) {
+		t.Errorf("Expected the error of the second hook, got %v", err)
+	}
+	hooks.OnComplete(context.Background(), "t", "n", nil)
+	if hooks.AfterNode != nil || hooks.OnRoute != nil || hooks.OnError != nil {
+		t.Error("Expected unset callbacks to stay nil")
+	}
+
+	expected := []string{"first", "second", "first complete", "second complete"}
+	if len(calls) != len(expected) {
+		t.Fatalf("Expected calls %v, got %v", expected, calls)
+	}
+	for i := range expected {
+		if calls[i] != expected[i] {
+			t.Errorf("Expected calls %v, got %v", expected, calls)
+			break
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package graph
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var (
+	// ErrIDGeneratorNil indicates that the runtime is configured with a nil ID generator.
+	ErrIDGeneratorNil = errors.New("ID generator cannot be nil")
+	// ErrThreadAliasEmpty indicates that a thread alias is empty.
+	ErrThreadAliasEmpty = errors.New("thread alias cannot be empty")
+	// ErrThreadAliasInUse indicates that a thread alias already names another thread.
+	ErrThreadAliasInUse = errors.New("thread alias is already in use")
+	// ErrUnknownThreadAlias indicates that a thread alias names no thread.
+	ErrUnknownThreadAlias = errors.New("unknown thread alias")
+)
+
+// crockford is the Crockford's base32 alphabet of the ULIDs.
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// IDGeneratorFn returns a new unique identifier for a thread.
+//
+// The function is called concurrently by the runtime.
+type IDGeneratorFn func() string
+
+// UUID returns a random UUID, the default identifier of the threads.
+//
+// Returns:
+//   - The UUID in its canonical textual form.
+//
+// Example:
+//
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, WithIDGenerator[MyState](UUID))
+func UUID() string {
+	return uuid.NewString()
+}
+
+// ULID returns a universally unique lexicographically sortable identifier.
+//
+// The 26 characters encode the creation time in milliseconds followed by 80 random bits, so that the
+// identifiers sort by creation time, up to the millisecond.
+//
+// Returns:
+//   - The ULID in Crockford's base32.
+//
+// Example:
+//
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, WithIDGenerator[MyState](ULID))
+func ULID() string {
+	var id [16]byte
+	binary.BigEndian.PutUint64(id[:8], uint64(time.Now().UnixMilli())<<16)
+	_, _ = rand.Read(id[6:])
+
+	// The 128 bits are encoded in 26 characters of 5 bits, the first one holding 3 bits only.
+	var encoded [26]byte
+	for i := range encoded {
+		v := 0
+		for b := range 5 {
+			v <<= 1
+			if bit := i*5 + b - 2; bit >= 0 && id[bit/8]&(0x80>>(bit%8)) != 0 {
+				v |= 1
+			}
+		}
+		encoded[i] = crockford[v]
+	}
+	return string(encoded[:])
+}
+
+// PrefixedIDs returns an ID generator prefixing the identifiers of another one, e.g. with a tenant.
+//
+// Parameters:
+//   - prefix: The prefix of the identifiers.
+//   - generator: The generator of the rest of the identifiers, UUID when nil.
+//
+// Returns:
+//   - The IDGeneratorFn of the prefixed identifiers.
+//
+// Example:
+//
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, WithIDGenerator[MyState](PrefixedIDs("acme-", ULID)))
+func PrefixedIDs(prefix string, generator IDGeneratorFn) IDGeneratorFn {
+	if generator == nil {
+		generator = UUID
+	}
+	return func() string {
+		return prefix + generator()
+	}
+}
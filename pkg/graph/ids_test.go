@@ -0,0 +1,44 @@
+package graph_test
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func TestULID(t *testing.T) {
+	format := regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{26}$`)
+	first := g.ULID()
+	time.Sleep(2 * time.Millisecond)
+	second := g.ULID()
+
+	if !format.MatchString(first) || !format.MatchString(second) {
+		t.Fatalf("Expected ULIDs in Crockford's base32, got %s and %s", first, second)
+	}
+	if first >= second {
+		t.Errorf("Expected the ULIDs to sort by creation time, got %s and %s", first, second)
+	}
+}
+
+func TestPrefixedIDs(t *testing.T) {
+	if id := g.PrefixedIDs("acme-", func() string { return "1" })(); id != "acme-1" {
+		t.Errorf("Expected the prefixed identifier, got %s", id)
+	}
+	if id := g.PrefixedIDs("acme-", nil)(); !strings.HasPrefix(id, "acme-") || len(id) != len("acme-")+36 {
+		t.Errorf("Expected a prefixed UUID, got %s", id)
+	}
+}
+
+func TestWithIDGenerator(t *testing.T) {
+	opts := &g.RuntimeOptions[diffState]{}
+	if err := g.WithIDGenerator[diffState](nil).Apply(opts); !errors.Is(err, g.ErrIDGeneratorNil) {
+		t.Errorf("Expected ErrIDGeneratorNil, got %v", err)
+	}
+	if err := g.WithIDGenerator[diffState](g.ULID).Apply(opts); err != nil || opts.IDGenerator == nil {
+		t.Errorf("Expected the generator to be set, got %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrJournalNil indicates that the provided journal is nil.
+	ErrJournalNil = errors.New("journal cannot be nil")
+	// ErrJournalNotSet indicates that the runtime has no journal to resume the threads from.
+	ErrJournalNotSet = errors.New("journal is not set")
+	// ErrNothingToResume indicates that the journal holds no step of the thread to resume.
+	ErrNothingToResume = errors.New("no journaled step to resume")
+	// ErrJournalDivergence indicates that the journaled route is no longer part of the graph.
+	ErrJournalDivergence = errors.New("journaled route not found in the graph")
+)
+
+// JournalEntry is a step of an invocation recorded by the journal: the node completed, its routing
+// decision and the state it left.
+type JournalEntry[T SharedState] struct {
+	// ThreadID is the thread of the invocation.
+	ThreadID string `json:"thread_id"`
+	// Step is the position of the entry in the invocation, starting from 1.
+	Step int `json:"step"`
+	// Node is the name of the completed node.
+	Node string `json:"node"`
+	// Next is the name of the node the routing policy selected.
+	Next string `json:"next"`
+	// State is the state of the thread after the reduction of the change of the node.
+	State T `json:"state"`
+	// UserInput is the user input of the invocation.
+	UserInput T `json:"user_input"`
+	// RecordedAt is the time the step was recorded.
+	RecordedAt time.Time `json:"recorded_at"`
+	// WakeAt is the time the thread wakes up when Next is a delay node, see Delaying.
+	WakeAt time.Time `json:"wake_at"`
+	// Payload is the payload the node sent to Next, see SendPayload. Journals serializing their
+	// entries restore it as decoded by their codec, e.g. a map[string]any for a JSON object.
+	Payload any `json:"payload,omitempty"`
+	// PayloadSent tells whether the node sent a payload, telling a nil payload from none.
+	PayloadSent bool `json:"payload_sent,omitempty"`
+}
+
+// Journal records the steps of the running invocations, so that an invocation interrupted by a
+// crash is resumed after its last completed node instead of being executed again.
+//
+// Implementations must be safe for concurrent use.
+type Journal[T SharedState] interface {
+	// Append records the step, after the previous ones of the thread.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation and timeout control.
+	//   - entry: The step to record.
+	//
+	// Returns:
+	//   - An error if the step cannot be recorded.
+	Append(ctx context.Context, entry JournalEntry[T]) error
+	// Entries returns the recorded steps of the thread, in order.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation and timeout control.
+	//   - threadID: The thread whose steps are requested.
+	//
+	// Returns:
+	//   - The steps, empty when none is recorded.
+	//   - An error if the steps cannot be read.
+	Entries(ctx context.Context, threadID string) ([]JournalEntry[T], error)
+	// Clear forgets the steps of the thread.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation and timeout control.
+	//   - threadID: The thread whose steps are forgotten.
+	//
+	// Returns:
+	//   - An error if the steps cannot be forgotten.
+	Clear(ctx context.Context, threadID string) error
+}
+
+// Durable resumes the invocations recorded by the journal, see WithJournal.
+type Durable interface {
+	// Resume continues the interrupted invocation of the thread from its last journaled step.
+	//
+	// The thread takes the state recorded by the last step and the invocation enters the node
+	// it routed to, with the journaled user input: the completed nodes are not executed again,
+	// which matters for the non-idempotent ones such as the tool calls. The invocations which
+	// completed leave no step to resume, the failed and interrupted ones do; a new Invoke of
+	// the thread discards them.
+	//
+	// The outcome of the resumed invocation is delivered to the monitor as for Invoke.
+	//
+	// Parameters:
+	//   - threadID: The thread to resume.
+	//   - config: Optional configuration settings for the resumed invocation.
+	//
+	// Returns:
+	//   - An error wrapping ErrJournalNotSet, ErrNothingToResume, ErrJournalDivergence, ErrInvalidTenant
+	//     or the error of the journal if the invocation cannot be resumed, or the error rejecting the
+	//     resumed invocation, such as ErrRuntimeExecuting, ErrRuntimeShuttingDown or ErrThreadOwned.
+	//
+	// Example:
+	//
+	//	// after a restart, for the threads the previous process was executing
+	//	for _, threadID := range interrupted {
+	//	    if err := runtime.Resume(threadID); err != nil {
+	//	        log.Printf("thread %s not resumed: %v", threadID, err)
+	//	    }
+	//	}
+	Resume(threadID string, config ...InvokeConfig) error
+}
@@ -0,0 +1,50 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrLeaseStoreNil indicates that the provided lease store is nil.
+	ErrLeaseStoreNil = errors.New("lease store cannot be nil")
+	// ErrInvalidLeaseTTL indicates that the provided lease TTL is not positive.
+	ErrInvalidLeaseTTL = errors.New("lease TTL must be positive")
+	// ErrLeasesWithoutMemory indicates that thread leases are set on a runtime without a memory to share the states.
+	ErrLeasesWithoutMemory = errors.New("thread leases require a memory shared with the other instances")
+	// ErrThreadOwned indicates that the thread is executed by another runtime instance.
+	ErrThreadOwned = errors.New("thread owned by another runtime instance")
+	// ErrLeaseLost indicates that the lease of the thread expired and was taken over by another runtime instance.
+	ErrLeaseLost = errors.New("thread lease lost")
+)
+
+// LeaseStore grants to the runtime instances sharing a Memory the exclusive ownership of the threads, for a limited time.
+//
+// An instance executes a thread only while it holds its lease, renewing it until the invocation
+// ends; the lease of a failed instance expires and the thread is taken over by the next instance
+// invoking it, on the state persisted in the shared memory.
+type LeaseStore interface {
+	// Acquire grants the lease of the thread to the owner, or renews it if the owner already holds it.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation and timeout control.
+	//   - threadID: The thread to own.
+	//   - owner: The identifier of the runtime instance.
+	//   - ttl: The time after which the lease expires unless renewed.
+	//
+	// Returns:
+	//   - true if the owner holds the lease, false if another owner holds an unexpired lease.
+	//   - An error if the store cannot be reached.
+	Acquire(ctx context.Context, threadID, owner string, ttl time.Duration) (bool, error)
+	// Release gives up the lease of the thread, if held by the owner.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation and timeout control.
+	//   - threadID: The owned thread.
+	//   - owner: The identifier of the runtime instance.
+	//
+	// Returns:
+	//   - An error if the store cannot be reached.
+	Release(ctx context.Context, threadID, owner string) error
+}
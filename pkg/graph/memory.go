@@ -1,5 +1,7 @@
 package graph
 
+import "context"
+
 // Memory interface defines methods for persisting and restoring shared state.
 type Memory[T SharedState] interface {
 	// PersistFn returns a function to persist the shared state.
@@ -7,3 +9,19 @@ type Memory[T SharedState] interface {
 	// RestoreFn returns a function to restore the shared state.
 	RestoreFn() RestoreFn[T]
 }
+
+// StateDeleter is implemented by the memories able to delete a persisted state.
+//
+// The memory-backed dead-letter queues delete the states of the reprocessed entries through it,
+// and overwrite them with the zero value of the state otherwise.
+type StateDeleter interface {
+	// Delete removes the state persisted for the thread.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation and timeout control.
+	//   - threadID: The thread whose state is removed.
+	//
+	// Returns:
+	//   - An error if the deletion fails.
+	Delete(ctx context.Context, threadID string) error
+}
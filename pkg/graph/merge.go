@@ -0,0 +1,9 @@
+package graph
+
+import "errors"
+
+// MergeNodeName is the node of the monitor entries reporting the merge of a thread into another one.
+const MergeNodeName = "Merge"
+
+// ErrMergeFnNil indicates that a merge of threads is requested without a merge function.
+var ErrMergeFnNil = errors.New("merge function cannot be nil")
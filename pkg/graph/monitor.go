@@ -0,0 +1,69 @@
+package graph
+
+// MonitorDeliveryPolicy defines how the runtime delivers the monitor entries when the state monitor channel is full.
+type MonitorDeliveryPolicy int
+
+const (
+	// MonitorDropNewest waits up to RuntimeSettings.OutcomeNotificationMaxInterval for room in the
+	// channel, then drops the entry being delivered.
+	MonitorDropNewest MonitorDeliveryPolicy = iota
+	// MonitorBlock waits for room in the channel until the runtime is shut down: no entry is lost,
+	// but a slow consumer slows the whole runtime down.
+	MonitorBlock
+	// MonitorDropOldest discards the oldest entries waiting in the channel to make room for the
+	// entry being delivered, so that the consumer always receives the latest progress.
+	MonitorDropOldest
+	// MonitorBufferToDisk appends the entries to a file in RuntimeSettings.MonitorBufferDir while
+	// the channel is full, and delivers them in order as soon as the consumer catches up.
+	// The states must be JSON serializable, only they are written to the file: the errors of the
+	// buffered entries are kept in memory and delivered unchanged. The breakpoints of the buffered
+	// entries are delivered without the edge and their changes with the values decoded as generic
+	// JSON values.
+	MonitorBufferToDisk
+)
+
+// String returns the string representation of the MonitorDeliveryPolicy.
+func (p MonitorDeliveryPolicy) String() string {
+	switch p {
+	case MonitorDropNewest:
+		return "MonitorDropNewest"
+	case MonitorBlock:
+		return "MonitorBlock"
+	case MonitorDropOldest:
+		return "MonitorDropOldest"
+	case MonitorBufferToDisk:
+		return "MonitorBufferToDisk"
+	default:
+		return "Unknown"
+	}
+}
+
+// MonitorStats describes the delivery of the monitor entries to the state monitor channel.
+type MonitorStats struct {
+	// Policy is the delivery policy of the runtime.
+	Policy MonitorDeliveryPolicy
+	// Delivered is the number of entries sent to the channel since the runtime creation.
+	Delivered uint64
+	// Dropped is the number of entries lost since the runtime creation, either discarded by the
+	// policy or not buffered because of an error.
+	Dropped uint64
+	// Buffered is the number of entries currently waiting on disk, see MonitorBufferToDisk.
+	Buffered int
+	// Spilled is the number of entries written to disk since the runtime creation.
+	Spilled uint64
+}
+
+// Monitored provides the delivery metrics of the state monitor channel of a runtime.
+type Monitored interface {
+	// MonitorStats returns a snapshot of the delivery metrics of the monitor entries.
+	//
+	// Returns:
+	//   - The delivery metrics.
+	//
+	// Example:
+	//
+	//	if stats := runtime.MonitorStats(); stats.Dropped > 0 {
+	//	    log.Printf("%d monitor entries lost, consider MonitorBlock or MonitorBufferToDisk", stats.Dropped)
+	//	}
+	MonitorStats() MonitorStats
+}
@@ -0,0 +1,142 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultNodeCacheCapacity is the number of results held by the in-process cache of a node without a store.
+	DefaultNodeCacheCapacity = 1024
+)
+
+var (
+	// ErrNodeCacheKeyFnNil indicates that the function computing the cache key of a node is nil.
+	ErrNodeCacheKeyFnNil = errors.New("node cache key function cannot be nil")
+	// ErrNodeCacheStoreNil indicates that no store has been provided to the cache of a node.
+	ErrNodeCacheStoreNil = errors.New("node cache store cannot be nil")
+	// ErrInvalidNodeCacheOption indicates that an option of the cache of a node has an invalid value.
+	ErrInvalidNodeCacheOption = errors.New("invalid node cache option")
+)
+
+// NodeCacheStore stores the results of the cached nodes.
+//
+// Entries are opaque bytes, so that any key-value store can back the cache: the response caches
+// of the agent package, like agent.NewLRUCache and the cache/redis package, are node cache
+// stores too.
+type NodeCacheStore interface {
+	// Get returns the cached result.
+	//
+	// Parameters:
+	//   - ctx: The context of the invocation.
+	//   - key: The hash of the node and its cache key.
+	//
+	// Returns:
+	//   - The cached result.
+	//   - false if the result is not cached or expired.
+	//   - An error if the cache could not be read.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	// Set stores a result.
+	//
+	// Parameters:
+	//   - ctx: The context of the invocation.
+	//   - key: The hash of the node and its cache key.
+	//   - value: The result.
+	//   - ttl: The time to live of the entry, 0 for no expiration.
+	//
+	// Returns:
+	//   - An error if the result could not be stored.
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+// NodeCacheKeyFn computes the key the result of a node is cached by.
+//
+// Parameters:
+//   - userInput: The input the node is executed with.
+//   - currentState: The current state of the thread.
+//
+// Returns:
+//   - The cache key, empty to execute the node without the cache.
+//   - An error if the key cannot be computed, which will halt graph execution.
+//
+// Example:
+//
+//	func byQuestion(userInput, state MyState) (string, error) {
+//	    return state.Question, nil
+//	}
+type NodeCacheKeyFn[T SharedState] func(userInput, currentState T) (string, error)
+
+// NodeCacheOptions holds the configuration of the cache of a node.
+type NodeCacheOptions struct {
+	// Store holds the cached results, nil for an in-process cache of DefaultNodeCacheCapacity results.
+	Store NodeCacheStore
+	// TTL is the time to live of the cached results, 0 for no expiration.
+	TTL time.Duration
+}
+
+// NodeCache memoizes the results of a deterministic node, see WithCache.
+type NodeCache[T SharedState] struct {
+	NodeCacheOptions
+	// Key computes the key the results are cached by.
+	Key NodeCacheKeyFn[T]
+}
+
+// NodeCacheOption is a functional option for configuring the cache of a node.
+type NodeCacheOption interface {
+	// Apply applies the option to the NodeCacheOptions.
+	//
+	// Parameters:
+	//   - o: A pointer to NodeCacheOptions to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(o *NodeCacheOptions) error
+}
+
+// NodeCacheOptionFunc is a function type that implements the NodeCacheOption interface.
+type NodeCacheOptionFunc func(*NodeCacheOptions) error
+
+// Apply applies the NodeCacheOptionFunc to the given NodeCacheOptions.
+//
+// Parameters:
+//   - o: A pointer to NodeCacheOptions to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s NodeCacheOptionFunc) Apply(o *NodeCacheOptions) error { return s(o) }
+
+// WithNodeCacheStore sets the store holding the cached results, e.g. to share them among the instances.
+//
+// Parameters:
+//   - store: The node cache store.
+//
+// Returns:
+//   - A NodeCacheOption that sets the store.
+func WithNodeCacheStore(store NodeCacheStore) NodeCacheOption {
+	return NodeCacheOptionFunc(func(o *NodeCacheOptions) error {
+		if store == nil {
+			return ErrNodeCacheStoreNil
+		}
+		o.Store = store
+		return nil
+	})
+}
+
+// WithNodeCacheTTL sets the time to live of the cached results.
+//
+// Parameters:
+//   - ttl: The time to live, 0 for no expiration.
+//
+// Returns:
+//   - A NodeCacheOption that sets the time to live.
+func WithNodeCacheTTL(ttl time.Duration) NodeCacheOption {
+	return NodeCacheOptionFunc(func(o *NodeCacheOptions) error {
+		if ttl < 0 {
+			return fmt.Errorf("%w: ttl cannot be negative", ErrInvalidNodeCacheOption)
+		}
+		o.TTL = ttl
+		return nil
+	})
+}
@@ -5,6 +5,10 @@ type NodeOptions[T SharedState] struct {
 	RoutingPolicy RoutePolicy[T]
 	Reducer       ReducerFn[T]
 	NodeSettings  NodeSettings
+	// Compensation undoes the side effects of the node when the invocation fails further on, see WithCompensation.
+	Compensation CompensationFn[T]
+	// Cache memoizes the results of the node, see WithCache.
+	Cache *NodeCache[T]
 }
 
 // NodeOption is a functional option for configuring a node.
@@ -91,3 +95,70 @@ func WithNodeSettings[T SharedState](settings NodeSettings) NodeOption[T] {
 		return nil
 	})
 }
+
+// WithCompensation sets the function undoing the side effects of the node, making it a step of a saga.
+//
+// When the invocation fails after the node completed, the runtime walks back through the completed
+// nodes and runs their compensations in reverse order, before the terminal error entry: every
+// compensation is notified by a monitor entry with Compensation set, a failed one carries an error
+// wrapping ErrCompensationFailed and the walk goes on. The invocations failing because their
+// lease was lost are not compensated, the thread being executed by another instance.
+//
+// Parameters:
+//   - fn: The compensation of the node.
+//
+// Returns:
+//   - A NodeOption that sets the compensation.
+//
+// Example:
+//
+//	charge, err := builders.NewContextNode("Charge", chargeFn,
+//	    graph.WithCompensation(func(ctx context.Context, userInput, state MyState) (MyState, error) {
+//	        return state, payments.Refund(ctx, state.ChargeID)
+//	    }))
+func WithCompensation[T SharedState](fn CompensationFn[T]) NodeOption[T] {
+	return NodeOptionFunc[T](func(r *NodeOptions[T]) error {
+		if fn == nil {
+			return ErrCompensationNil
+		}
+		r.Compensation = fn
+		return nil
+	})
+}
+
+// WithCache memoizes the results of a deterministic node, keyed by keyFn.
+//
+// Before executing the node, the runtime computes the key of its input and of the current state:
+// a cached result is reduced into the state without executing the node, otherwise the result of
+// a successful execution is cached. Results are encoded as JSON and cached by the hash of the
+// node name and the key, so that the nodes can share a store. Cache failures never fail the
+// node: an unreadable or undecodable entry is treated as a miss and a failed write is ignored.
+//
+// Parameters:
+//   - keyFn: The function computing the cache key.
+//   - opts: Optional configuration options, see WithNodeCacheStore and WithNodeCacheTTL.
+//
+// Returns:
+//   - A NodeOption that sets the cache.
+//
+// Example:
+//
+//	classify, err := builders.NewNode("Classify", classifyFn,
+//	    graph.WithCache(func(userInput, state MyState) (string, error) {
+//	        return state.Ticket, nil
+//	    }, graph.WithNodeCacheTTL(time.Hour)))
+func WithCache[T SharedState](keyFn NodeCacheKeyFn[T], opts ...NodeCacheOption) NodeOption[T] {
+	return NodeOptionFunc[T](func(r *NodeOptions[T]) error {
+		if keyFn == nil {
+			return ErrNodeCacheKeyFnNil
+		}
+		cache := &NodeCache[T]{Key: keyFn}
+		for _, opt := range opts {
+			if err := opt.Apply(&cache.NodeCacheOptions); err != nil {
+				return err
+			}
+		}
+		r.Cache = cache
+		return nil
+	})
+}
@@ -0,0 +1,138 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrOnceKeyEmpty indicates that the key of a side effect is empty.
+	ErrOnceKeyEmpty = errors.New("side effect key cannot be empty")
+	// ErrOnceFnNil indicates that the side effect function is nil.
+	ErrOnceFnNil = errors.New("side effect function cannot be nil")
+	// ErrOnceStoreNotSet indicates that the context does not belong to an invocation recording the side effects.
+	ErrOnceStoreNotSet = errors.New("no side effect store in context")
+)
+
+// OnceStore records the side effects executed by the threads, see Once.
+//
+// A Memory implementing OnceStore records the side effects along with the states, so that they
+// survive the process and are shared by the instances using the memory; the runtimes fall back to
+// an in-process store otherwise.
+//
+// Implementations must be safe for concurrent use.
+type OnceStore interface {
+	// ClaimOnce records the side effect of the thread, unless already recorded.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation and timeout control.
+	//   - threadID: The thread executing the side effect.
+	//   - key: The identifier of the side effect within the thread.
+	//
+	// Returns:
+	//   - true if the side effect was recorded by this call, false if it was already.
+	//   - An error if the store cannot be reached.
+	ClaimOnce(ctx context.Context, threadID, key string) (bool, error)
+	// ReleaseOnce forgets the side effect of the thread, which failed and can be executed again.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation and timeout control.
+	//   - threadID: The thread executing the side effect.
+	//   - key: The identifier of the side effect within the thread.
+	//
+	// Returns:
+	//   - An error if the store cannot be reached.
+	ReleaseOnce(ctx context.Context, threadID, key string) error
+}
+
+// Onced executes the side effects of the threads at most once.
+type Onced interface {
+	// Once executes the side effect at most once per thread, across the retries of the node and
+	// the resumed invocations.
+	//
+	// The side effect is recorded before being executed: a side effect interrupted by a crash is
+	// not executed again, one returning an error is forgotten so that a retry executes it again.
+	// Use the Once function within the nodes, it takes the thread from their context.
+	//
+	// Parameters:
+	//   - threadID: The thread executing the side effect.
+	//   - key: The identifier of the side effect within the thread.
+	//   - fn: The side effect.
+	//
+	// Returns:
+	//   - true if fn was executed by this call, false if the side effect was already executed.
+	//   - The error of fn, or an error wrapping ErrOnceKeyEmpty, ErrOnceFnNil or the error of the store.
+	//
+	// Example:
+	//
+	//	charged, err := runtime.Once(threadID, "charge", func() error {
+	//	    return payments.Charge(ctx, order)
+	//	})
+	Once(threadID, key string, fn func() error) (bool, error)
+}
+
+type onceStoreContextKey struct{}
+
+// ContextWithOnceStore returns a context recording the side effects executed with Once into the store.
+//
+// The runtimes set it on the context of the invocations, it is only needed to execute the nodes
+// outside of a runtime.
+//
+// Parameters:
+//   - ctx: The parent context.
+//   - store: The store recording the side effects.
+//
+// Returns:
+//   - The context carrying the store.
+func ContextWithOnceStore(ctx context.Context, store OnceStore) context.Context {
+	return context.WithValue(ctx, onceStoreContextKey{}, store)
+}
+
+// Once executes the side effect at most once for the thread of the context, see Onced.
+//
+// Parameters:
+//   - ctx: The context of the node, carrying the thread and the store of the invocation.
+//   - key: The identifier of the side effect within the thread.
+//   - fn: The side effect.
+//
+// Returns:
+//   - true if fn was executed by this call, false if the side effect was already executed.
+//   - The error of fn, or an error wrapping ErrOnceStoreNotSet if the context does not belong to an invocation.
+//
+// Example:
+//
+//	func chargeNode(ctx context.Context, userInput, state MyState, notify graph.NotifyPartialFn[MyState]) (MyState, error) {
+//	    _, err := graph.Once(ctx, "charge:"+state.OrderID, func() error {
+//	        return payments.Charge(ctx, state.OrderID, state.Amount)
+//	    })
+//	    return state, err
+//	}
+func Once(ctx context.Context, key string, fn func() error) (bool, error) {
+	if key == "" {
+		return false, ErrOnceKeyEmpty
+	}
+	if fn == nil {
+		return false, ErrOnceFnNil
+	}
+	threadID, hasThread := ThreadIDFromContext(ctx)
+	store, hasStore := ctx.Value(onceStoreContextKey{}).(OnceStore)
+	if !hasThread || !hasStore || store == nil {
+		return false, fmt.Errorf("cannot execute side effect %s: %w", key, ErrOnceStoreNotSet)
+	}
+
+	claimed, err := store.ClaimOnce(ctx, threadID, key)
+	if err != nil {
+		return false, fmt.Errorf("cannot record side effect %s of thread %s: %w", key, threadID, err)
+	}
+	if !claimed {
+		return false, nil
+	}
+	if err := fn(); err != nil {
+		if releaseErr := store.ReleaseOnce(context.WithoutCancel(ctx), threadID, key); releaseErr != nil {
+			return true, errors.Join(err, fmt.Errorf("cannot forget side effect %s of thread %s: %w", key, threadID, releaseErr))
+		}
+		return true, err
+	}
+	return true, nil
+}
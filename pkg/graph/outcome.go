@@ -0,0 +1,42 @@
+package graph
+
+import "time"
+
+// OutcomeStats describes the queue of the node outcomes waiting to be processed by a runtime.
+//
+// The outcomes, the partial updates included, are processed one thread at a time in round-robin,
+// so that a thread emitting many outcomes cannot delay the progress of the others.
+type OutcomeStats struct {
+	// Queued is the number of outcomes waiting to be processed.
+	Queued int
+	// Threads is the number of threads with outcomes waiting to be processed.
+	Threads int
+	// Processed is the number of outcomes processed since the runtime creation.
+	Processed uint64
+	// TotalQueueWait is the time spent in the queue by the processed outcomes.
+	TotalQueueWait time.Duration
+	// MaxQueueWait is the longest time spent in the queue by a processed outcome.
+	MaxQueueWait time.Duration
+}
+
+// AverageQueueWait returns the average time spent in the queue by the processed outcomes.
+func (s OutcomeStats) AverageQueueWait() time.Duration {
+	if s.Processed == 0 {
+		return 0
+	}
+	return s.TotalQueueWait / time.Duration(s.Processed)
+}
+
+// Scheduled provides the metrics of the processing of the node outcomes of a runtime.
+type Scheduled interface {
+	// OutcomeStats returns a snapshot of the metrics of the outcome queue.
+	//
+	// Returns:
+	//   - The outcome queue metrics.
+	//
+	// Example:
+	//
+	//	stats := runtime.OutcomeStats()
+	//	log.Printf("%d outcomes of %d threads queued, average wait %s", stats.Queued, stats.Threads, stats.AverageQueueWait())
+	OutcomeStats() OutcomeStats
+}
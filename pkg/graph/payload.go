@@ -0,0 +1,127 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrPayloadCarrierNotSet indicates that the context does not belong to an invocation carrying edge payloads.
+	ErrPayloadCarrierNotSet = errors.New("no edge payload carrier in context")
+	// ErrPayloadType indicates that the payload received by a node is not of the expected type.
+	ErrPayloadType = errors.New("unexpected edge payload type")
+	// ErrPayloadNodeFnNil indicates that the processing function of a payload node is nil.
+	ErrPayloadNodeFnNil = errors.New("payload node function cannot be nil")
+)
+
+// PayloadCarrier carries the payloads along the edges chosen by an invocation, see SendPayload.
+//
+// The runtimes provide one per invocation; implementations must be safe for concurrent use.
+type PayloadCarrier interface {
+	// Inbound returns the payload sent by the previous node, false if it sent none.
+	Inbound() (any, bool)
+	// Outbound attaches the payload to the edge the current node is about to route along.
+	Outbound(value any)
+}
+
+type payloadCarrierContextKey struct{}
+
+// ContextWithPayloadCarrier returns a context carrying the edge payloads with the carrier.
+//
+// The runtimes set it on the context of the invocations, it is only needed to execute the nodes
+// outside of a runtime.
+//
+// Parameters:
+//   - ctx: The parent context.
+//   - carrier: The carrier of the edge payloads.
+//
+// Returns:
+//   - The context carrying the payloads.
+func ContextWithPayloadCarrier(ctx context.Context, carrier PayloadCarrier) context.Context {
+	return context.WithValue(ctx, payloadCarrierContextKey{}, carrier)
+}
+
+// SendPayload attaches a value to the edge chosen after the current node, delivering it to the next node only.
+//
+// Payloads spare the shared state the scratch fields only meaningful between two nodes: they are
+// neither reduced nor persisted. The journal records them with the routing decision, so that a
+// resumed invocation delivers the payload sent to the node it resumes at, see JournalEntry.
+// The last value sent by a node wins.
+//
+// Parameters:
+//   - ctx: The context of the node, carrying the payloads of the invocation.
+//   - value: The payload for the next node.
+//
+// Returns:
+//   - An error wrapping ErrPayloadCarrierNotSet if the context does not belong to an invocation.
+//
+// Example:
+//
+//	func searchNode(ctx context.Context, userInput, state MyState, notify graph.NotifyPartialFn[MyState]) (MyState, error) {
+//	    hits, err := index.Search(ctx, userInput.Query)
+//	    if err != nil {
+//	        return state, err
+//	    }
+//	    return state, graph.SendPayload(ctx, hits)
+//	}
+func SendPayload(ctx context.Context, value any) error {
+	carrier, ok := ctx.Value(payloadCarrierContextKey{}).(PayloadCarrier)
+	if !ok || carrier == nil {
+		return fmt.Errorf("cannot send edge payload: %w", ErrPayloadCarrierNotSet)
+	}
+	carrier.Outbound(value)
+	return nil
+}
+
+// Payload returns the payload sent to the current node by the previous one, see SendPayload.
+//
+// Parameters:
+//   - ctx: The context of the node, carrying the payloads of the invocation.
+//
+// Returns:
+//   - The payload, the zero value if there is none.
+//   - true if the previous node sent a payload.
+//   - An error wrapping ErrPayloadType if the payload is not a P.
+//
+// Example:
+//
+//	func rankNode(ctx context.Context, userInput, state MyState, notify graph.NotifyPartialFn[MyState]) (MyState, error) {
+//	    hits, ok, err := graph.Payload[[]Hit](ctx)
+//	    if err != nil || !ok {
+//	        return state, err
+//	    }
+//	    state.Best = rank(hits)[0]
+//	    return state, nil
+//	}
+func Payload[P any](ctx context.Context) (P, bool, error) {
+	var zero P
+	carrier, ok := ctx.Value(payloadCarrierContextKey{}).(PayloadCarrier)
+	if !ok || carrier == nil {
+		return zero, false, nil
+	}
+	value, ok := carrier.Inbound()
+	if !ok {
+		return zero, false, nil
+	}
+	typed, ok := value.(P)
+	if !ok && value != nil {
+		return zero, true, fmt.Errorf("%w: got %T, expected %T", ErrPayloadType, value, zero)
+	}
+	return typed, true, nil
+}
+
+// PayloadNodeFn processes a node receiving a typed payload from the previous node and sending one to the next.
+//
+// Parameters:
+//   - ctx: The context of the invocation.
+//   - userInput: The original input provided to Runtime.Invoke().
+//   - currentState: The current state of the thread.
+//   - in: The payload sent by the previous node, the zero value if it sent none.
+//   - notify: Callback to send partial state updates during processing.
+//
+// Returns:
+//   - The updated state after processing.
+//   - The payload for the next node.
+//   - An error if processing failed, which will halt graph execution.
+type PayloadNodeFn[T SharedState, In, Out any] func(ctx context.Context, userInput, currentState T, in In, notify NotifyPartialFn[T]) (T, Out, error)
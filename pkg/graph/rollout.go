@@ -0,0 +1,179 @@
+package graph
+
+import (
+	"errors"
+	"slices"
+	"time"
+)
+
+var (
+	// ErrUnknownGraphVersion indicates that a graph version is neither the latest one nor retained by the rollout.
+	ErrUnknownGraphVersion = errors.New("unknown graph version")
+	// ErrInvalidRollout indicates that a rollout has an out of range canary percentage.
+	ErrInvalidRollout = errors.New("rollout canary percentage must be between 0 and 100")
+	// ErrNoActiveRollout indicates that the runtime has no rollout to end.
+	ErrNoActiveRollout = errors.New("no active rollout")
+)
+
+// RolloutSelectorFn routes a new invocation to a version of the graph explicitly.
+//
+// Parameters:
+//   - config: The configuration of the invocation, including its metadata.
+//
+// Returns:
+//   - true to route the invocation to the canary version, false to the stable one.
+//   - false as second value to leave the choice to the canary percentage.
+type RolloutSelectorFn func(config InvokeConfig) (canary bool, ok bool)
+
+// Rollout splits the new invocations between two versions of the graph, see RolledOut.
+type Rollout struct {
+	// Stable is the version serving the invocations not routed to the canary, the latest or the stable
+	// version of the previous rollout.
+	Stable uint64
+	// Canary is the version under validation, the latest version at the time of each invocation when zero.
+	Canary uint64
+	// CanaryPercent is the share of the threads routed to the canary, from 0 to 100.
+	CanaryPercent int
+	// Selector routes the invocations explicitly, e.g. by their metadata, before the percentage applies.
+	Selector RolloutSelectorFn
+}
+
+// GraphVersionStats describes the invocations which ran on a version of the graph.
+type GraphVersionStats struct {
+	// Version is the graph version.
+	Version uint64
+	// Invocations is the number of invocations started on the version.
+	Invocations uint64
+	// Completed is the number of invocations which reached the end of the graph.
+	Completed uint64
+	// Failed is the number of invocations which terminated with an error.
+	Failed uint64
+	// TotalDuration is the duration of the terminated invocations.
+	TotalDuration time.Duration
+	// Usage is the usage reported by the terminated invocations.
+	Usage Usage
+}
+
+// AverageDuration returns the average duration of the terminated invocations.
+func (s GraphVersionStats) AverageDuration() time.Duration {
+	if terminated := s.Completed + s.Failed; terminated > 0 {
+		return s.TotalDuration / time.Duration(terminated)
+	}
+	return 0
+}
+
+// FailureRate returns the share of the terminated invocations which failed, from 0 to 1.
+func (s GraphVersionStats) FailureRate() float64 {
+	if terminated := s.Completed + s.Failed; terminated > 0 {
+		return float64(s.Failed) / float64(terminated)
+	}
+	return 0
+}
+
+// RolloutStats compares the versions of the graph of the active rollout.
+type RolloutStats struct {
+	// Active reports whether a rollout is active.
+	Active bool
+	// Rollout is the active rollout, with the canary version resolved.
+	Rollout Rollout
+	// Stable are the metrics of the stable version.
+	Stable GraphVersionStats
+	// Canary are the metrics of the canary version.
+	Canary GraphVersionStats
+}
+
+// RolledOut runs two versions of a graph side by side, to validate a change of its topology on part
+// of the traffic before promoting it.
+//
+// Without rollout every new invocation uses the latest version of the graph. A typical blue/green
+// change freezes the current version as stable, reconfigures the graph with AddEdge and RemoveEdge,
+// then routes a share of the threads to the new version and compares the metrics of both:
+//
+//	stable := runtime.GraphVersion()
+//	_ = runtime.SetRollout(graph.Rollout{Stable: stable})
+//	runtime.AddEdge(newRoute)
+//	_ = runtime.RemoveEdge(oldRoute)
+//	_ = runtime.SetRollout(graph.Rollout{Stable: stable, CanaryPercent: 10, Selector: graph.MetadataSelector("x-canary", "true")})
+//	...
+//	stats := runtime.RolloutStats()
+//	_ = runtime.EndRollout(stats.Canary.FailureRate() <= stats.Stable.FailureRate())
+//
+// The running invocations always continue on the version they started with.
+type RolledOut interface {
+	// SetRollout starts or updates the rollout of the graph.
+	//
+	// Parameters:
+	//   - rollout: The versions and the split of the new invocations.
+	//
+	// Returns:
+	//   - ErrInvalidRollout if the percentage is out of range, ErrUnknownGraphVersion if a version
+	//     is neither the latest one nor a version of the current rollout.
+	SetRollout(rollout Rollout) error
+
+	// EndRollout ends the rollout, every new invocation then uses the latest version of the graph.
+	//
+	// Parameters:
+	//   - promote: true to keep the canary version, false to roll back to the stable one; the kept
+	//     version becomes the latest one, as a new version when it is not the latest already.
+	//
+	// Returns:
+	//   - ErrNoActiveRollout if no rollout is active.
+	EndRollout(promote bool) error
+
+	// RolloutStats returns the metrics of the versions of the active rollout.
+	//
+	// Returns:
+	//   - The rollout metrics, Active being false when no rollout is active.
+	RolloutStats() RolloutStats
+
+	// GraphVersionStats returns the metrics of the invocations which ran on a version of the graph.
+	//
+	// Parameters:
+	//   - version: The graph version.
+	//
+	// Returns:
+	//   - The metrics of the version, zero when no invocation ran on it.
+	GraphVersionStats(version uint64) GraphVersionStats
+}
+
+// MetadataSelector routes the invocations to the canary version by one of their metadata, e.g. an HTTP header.
+//
+// Parameters:
+//   - key: The metadata key.
+//   - canaryValues: The values routing to the canary version, any other value routes to the stable one.
+//
+// Returns:
+//   - The RolloutSelectorFn, leaving the choice to the percentage when the invocation has no such metadata.
+//
+// Example:
+//
+//	runtime.SetRollout(graph.Rollout{Stable: stable, Selector: graph.MetadataSelector("x-graph-track", "canary")})
+//	runtime.Invoke(userInput, graph.InvokeConfigMetadata(map[string]string{"x-graph-track": "canary"}))
+func MetadataSelector(key string, canaryValues ...string) RolloutSelectorFn {
+	useValues := slices.Clone(canaryValues)
+	return func(config InvokeConfig) (bool, bool) {
+		value, ok := config.Metadata[key]
+		if !ok {
+			return false, false
+		}
+		return slices.Contains(useValues, value), true
+	}
+}
+
+// InvokeConfigMetadata creates an InvokeConfig with the specified metadata.
+//
+// The metadata describe the invocation, e.g. the headers of the request which triggered it, for
+// the RolloutSelectorFn to route it; merged configurations merge their metadata.
+//
+// Parameters:
+//   - metadata: The metadata of the invocation.
+//
+// Returns:
+//   - An InvokeConfig instance with the specified metadata.
+//
+// Example:
+//
+//	runtime.Invoke(userInput, InvokeConfigMetadata(map[string]string{"x-graph-track": "canary"}))
+func InvokeConfigMetadata(metadata map[string]string) InvokeConfig {
+	return InvokeConfig{Metadata: metadata}
+}
@@ -0,0 +1,45 @@
+package graph_test
+
+import (
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func TestMetadataSelector(t *testing.T) {
+	selector := g.MetadataSelector("x-track", "canary", "green")
+
+	merged := g.MergeInvokeConfig(
+		g.InvokeConfigMetadata(map[string]string{"x-track": "blue", "region": "eu"}),
+		g.InvokeConfigMetadata(map[string]string{"x-track": "green"}),
+	)
+	if merged.Metadata["x-track"] != "green" || merged.Metadata["region"] != "eu" {
+		t.Errorf("Expected the metadata to be merged, got %v", merged.Metadata)
+	}
+
+	for _, tc := range []struct {
+		metadata       map[string]string
+		canary, choice bool
+	}{
+		{map[string]string{"x-track": "green"}, true, true},
+		{map[string]string{"x-track": "blue"}, false, true},
+		{map[string]string{"region": "eu"}, false, false},
+		{nil, false, false},
+	} {
+		canary, ok := selector(g.InvokeConfigMetadata(tc.metadata))
+		if canary != tc.canary || ok != tc.choice {
+			t.Errorf("Expected (%v, %v) for %v, got (%v, %v)", tc.canary, tc.choice, tc.metadata, canary, ok)
+		}
+	}
+}
+
+func TestGraphVersionStats(t *testing.T) {
+	if stats := (g.GraphVersionStats{}); stats.AverageDuration() != 0 || stats.FailureRate() != 0 {
+		t.Errorf("Expected zero metrics without invocations, got %+v", stats)
+	}
+	stats := g.GraphVersionStats{Completed: 3, Failed: 1, TotalDuration: 4 * time.Second}
+	if stats.AverageDuration() != time.Second || stats.FailureRate() != 0.25 {
+		t.Errorf("Unexpected metrics %v, %v", stats.AverageDuration(), stats.FailureRate())
+	}
+}
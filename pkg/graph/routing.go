@@ -1,6 +1,13 @@
 package graph
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// FallbackEdgeLabel is the label key marking the outbound edge taken when the routing policy of a node selects no edge.
+const FallbackEdgeLabel = "fallback"
 
 var (
 	// ErrEdgeSelectionFnNil indicates that the edge selection function is nil.
@@ -13,6 +20,12 @@ var (
 	ErrNilEdge = errors.New("routing policy returned nil edge")
 	// ErrNextEdgeNil indicates that the next edge from a node has a nil target node.
 	ErrNextEdgeNil = errors.New("next edge from node has nil target node")
+	// ErrRoutesNotExhaustive indicates that a routing policy may select no edge for some states.
+	ErrRoutesNotExhaustive = errors.New("route rules are not exhaustive")
+	// ErrMultipleFallbackEdges indicates that a node has more than one fallback edge.
+	ErrMultipleFallbackEdges = errors.New("node has more than one fallback edge")
+	// ErrInvalidFallbackLabel indicates that the fallback label of an edge is not a boolean.
+	ErrInvalidFallbackLabel = errors.New("fallback label must be a boolean")
 )
 
 // RoutePolicy defines the strategy for selecting which edge to follow after node execution.
@@ -60,7 +73,9 @@ type RoutePolicy[T SharedState] interface {
 	//     contains only edges where From() matches the current node.
 	//
 	// Returns:
-	//   - The Edge to traverse next. Must be one of the edges from the provided slice.
+	//   - The Edge to traverse next. Must be one of the edges from the provided slice;
+	//     nil follows the edge labelled with FallbackEdgeLabel, if any, otherwise the
+	//     invocation fails with ErrNilEdge.
 	//
 	// Example implementation:
 	//
@@ -79,3 +94,99 @@ type RoutePolicy[T SharedState] interface {
 	//	}
 	SelectEdge(userInput T, currentState T, edges []Edge[T]) Edge[T]
 }
+
+// RouteValidator is implemented by routing policies able to check their configuration against the graph.
+//
+// Runtime.Validate calls it for every node whose routing policy implements it, so that a
+// misconfigured policy fails when the graph is built rather than when the node is reached.
+type RouteValidator[T SharedState] interface {
+	// ValidateRoutes checks the policy against the outbound edges of its node.
+	//
+	// Parameters:
+	//   - node: The node owning the policy.
+	//   - edges: The outbound edges of the node.
+	//
+	// Returns:
+	//   - An error describing the misconfiguration, otherwise nil.
+	ValidateRoutes(node Node[T], edges []Edge[T]) error
+}
+
+// FallbackRoute chains routing policies: the first edge selected, in order, is followed.
+//
+// The policies after the primary one are tried only when the previous ones select no edge. The
+// chain implements RouteValidator, delegating to the policies implementing it; a policy which is
+// not exhaustive is accepted when a later policy takes over.
+//
+// Parameters:
+//   - primary: The policy tried first.
+//   - fallbacks: The policies tried, in order, when the previous ones select no edge.
+//
+// Returns:
+//   - The chained RoutePolicy.
+//
+// Example:
+//
+//	policy := graph.FallbackRoute(intentPolicy, keywordPolicy)
+//	router, _ := builders.CreateRouter("Router", policy)
+func FallbackRoute[T SharedState](primary RoutePolicy[T], fallbacks ...RoutePolicy[T]) RoutePolicy[T] {
+	policies := make([]RoutePolicy[T], 0, len(fallbacks)+1)
+	for _, policy := range append([]RoutePolicy[T]{primary}, fallbacks...) {
+		if policy != nil {
+			policies = append(policies, policy)
+		}
+	}
+	return &fallbackRoutePolicy[T]{policies: policies}
+}
+
+type fallbackRoutePolicy[T SharedState] struct {
+	policies []RoutePolicy[T]
+}
+
+func (p *fallbackRoutePolicy[T]) SelectEdge(userInput T, currentState T, edges []Edge[T]) Edge[T] {
+	for _, policy := range p.policies {
+		if edge := policy.SelectEdge(userInput, currentState, edges); edge != nil {
+			return edge
+		}
+	}
+	return nil
+}
+
+func (p *fallbackRoutePolicy[T]) ValidateRoutes(node Node[T], edges []Edge[T]) error {
+	for idx, policy := range p.policies {
+		validator, ok := policy.(RouteValidator[T])
+		if !ok {
+			continue
+		}
+		err := validator.ValidateRoutes(node, edges)
+		if err == nil || (errors.Is(err, ErrRoutesNotExhaustive) && idx < len(p.policies)-1) {
+			continue
+		}
+		return err
+	}
+	return nil
+}
+
+// IsFallbackEdge reports whether the edge is labelled as the fallback edge of its source node.
+//
+// Parameters:
+//   - edge: The edge to check.
+//
+// Returns:
+//   - true if the edge has the fallback label set to true.
+//   - An error wrapping ErrInvalidFallbackLabel if the label is not a boolean.
+//
+// Example:
+//
+//	edge := builders.CreateEdge(router, fallbackNode, map[string]string{graph.FallbackEdgeLabel: "true"})
+//	ok, _ := graph.IsFallbackEdge(edge) // true
+func IsFallbackEdge[T SharedState](edge Edge[T]) (bool, error) {
+	value, ok := edge.LabelByKey(FallbackEdgeLabel)
+	if !ok {
+		return false, nil
+	}
+	rv, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, fmt.Errorf("%w: %q", ErrInvalidFallbackLabel, value)
+	}
+	return rv, nil
+}
@@ -0,0 +1,84 @@
+package graph
+
+import (
+	"errors"
+	"testing"
+)
+
+type stubEdge struct {
+	name   string
+	labels map[string]string
+}
+
+func (e *stubEdge) From() Node[SharedState] { return nil }
+func (e *stubEdge) To() Node[SharedState]   { return nil }
+func (e *stubEdge) Role() EdgeRole          { return IntermediateEdge }
+func (e *stubEdge) LabelByKey(key string) (string, bool) {
+	value, ok := e.labels[key]
+	return value, ok
+}
+
+type stubPolicy struct {
+	edge Edge[SharedState]
+	err  error
+}
+
+func (p *stubPolicy) SelectEdge(userInput, currentState SharedState, edges []Edge[SharedState]) Edge[SharedState] {
+	return p.edge
+}
+
+func (p *stubPolicy) ValidateRoutes(node Node[SharedState], edges []Edge[SharedState]) error {
+	return p.err
+}
+
+func TestFallbackRoute_SelectEdge(t *testing.T) {
+	second := &stubEdge{name: "second"}
+
+	policy := FallbackRoute[SharedState](&stubPolicy{}, nil, &stubPolicy{edge: second}, &stubPolicy{edge: &stubEdge{name: "third"}})
+	if edge := policy.SelectEdge(nil, nil, nil); edge != second {
+		t.Errorf("Expected the first selected edge, got %v", edge)
+	}
+
+	if edge := FallbackRoute[SharedState](&stubPolicy{}).SelectEdge(nil, nil, nil); edge != nil {
+		t.Errorf("Expected no edge, got %v", edge)
+	}
+}
+
+func TestFallbackRoute_ValidateRoutes(t *testing.T) {
+	errBroken := errors.New("broken")
+
+	chained := FallbackRoute[SharedState](&stubPolicy{err: ErrRoutesNotExhaustive}, &stubPolicy{})
+	if err := chained.(RouteValidator[SharedState]).ValidateRoutes(nil, nil); err != nil {
+		t.Errorf("Expected a non exhaustive primary to be accepted, got %v", err)
+	}
+
+	last := FallbackRoute[SharedState](&stubPolicy{}, &stubPolicy{err: ErrRoutesNotExhaustive})
+	if err := last.(RouteValidator[SharedState]).ValidateRoutes(nil, nil); !errors.Is(err, ErrRoutesNotExhaustive) {
+		t.Errorf("Expected a non exhaustive last policy to be reported, got %v", err)
+	}
+
+	broken := FallbackRoute[SharedState](&stubPolicy{err: errBroken}, &stubPolicy{})
+	if err := broken.(RouteValidator[SharedState]).ValidateRoutes(nil, nil); !errors.Is(err, errBroken) {
+		t.Errorf("Expected the error of the primary, got %v", err)
+	}
+}
+
+func TestIsFallbackEdge(t *testing.T) {
+	tests := []struct {
+		labels   map[string]string
+		expected bool
+		err      error
+	}{
+		{labels: nil, expected: false},
+		{labels: map[string]string{FallbackEdgeLabel: "true"}, expected: true},
+		{labels: map[string]string{FallbackEdgeLabel: "false"}, expected: false},
+		{labels: map[string]string{FallbackEdgeLabel: "yes"}, err: ErrInvalidFallbackLabel},
+	}
+
+	for _, test := range tests {
+		got, err := IsFallbackEdge[SharedState](&stubEdge{labels: test.labels})
+		if got != test.expected || !errors.Is(err, test.err) {
+			t.Errorf("IsFallbackEdge(%v) = %v, %v", test.labels, got, err)
+		}
+	}
+}
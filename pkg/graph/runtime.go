@@ -3,6 +3,8 @@ package graph
 import (
 	"context"
 	"errors"
+	"maps"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -16,6 +18,8 @@ var (
 	ErrNoPathToEnd = errors.New("no path from start edge to any end edge")
 	// ErrRestoreNotSet indicates that the restore function is not set.
 	ErrRestoreNotSet = errors.New("restore function is not set")
+	// ErrPersistNotSet indicates that the persist function is not set.
+	ErrPersistNotSet = errors.New("persist function is not set")
 	// ErrPersistenceQueueFull indicates that the persistence queue is full.
 	ErrPersistenceQueueFull = errors.New("persistence queue is full")
 	// ErrEvictionByInactivity indicates that a thread was evicted due to inactivity.
@@ -24,6 +28,25 @@ var (
 	ErrUnknownThreadID = errors.New("unknown thread ID")
 	// ErrRuntimeOptionsNil indicates that the provided runtime options are nil.
 	ErrRuntimeOptionsNil = errors.New("runtime options cannot be nil")
+	// ErrThreadNotExecuting indicates that the thread has no running invocation.
+	ErrThreadNotExecuting = errors.New("thread is not executing")
+	// ErrThreadCancelled indicates that the invocation of the thread was cancelled.
+	ErrThreadCancelled = errors.New("thread cancelled")
+	// ErrUnreachableNode indicates that a node cannot be reached from the start edge.
+	ErrUnreachableNode = errors.New("node is unreachable from the start edge")
+	// ErrDeadEndNode indicates that a node which is not an end node has no outbound edge.
+	ErrDeadEndNode = errors.New("node has no outbound edge")
+	// ErrDuplicateNodeName indicates that distinct nodes share the same name.
+	ErrDuplicateNodeName = errors.New("duplicate node name")
+	// ErrMultipleStartEdges indicates that start edges were added besides the one the runtime was created with
+	// and the ones of the named entry points.
+	ErrMultipleStartEdges = errors.New("graph has more than one start edge")
+	// ErrEdgeNotFound indicates that the edge to remove is not part of the graph.
+	ErrEdgeNotFound = errors.New("edge not found")
+	// ErrDanglingEdge indicates that an edge references a nil node.
+	ErrDanglingEdge = errors.New("edge references a node which is not in the graph")
+	// ErrStreamingReducerNil indicates that the streaming view is enabled with a nil reducer.
+	ErrStreamingReducerNil = errors.New("streaming reducer cannot be nil")
 )
 
 // NodeExecutor defines an interface for submitting tasks to be executed.
@@ -51,11 +74,18 @@ type Connected[T SharedState] interface {
 	//
 	// Edges define the connections between nodes and determine how execution flows
 	// through the graph. Multiple edges can be added in a single call for convenience.
-	// Edges should be added after runtime creation but before calling Invoke().
+	// Edges are usually added after runtime creation but before calling Invoke().
+	//
+	// Edges can also be added while threads are running: every call creates a new version
+	// of the graph, the running invocations continue on the version they started with and
+	// the new invocations use the latest one.
 	//
 	// All edges except the StartEdge (which is provided during runtime creation)
 	// must be added using this method before the graph can execute.
 	//
+	// The new version is not validated, so that the graph can be built an edge at a time;
+	// reconfigure a running graph with AddEdgeValidated.
+	//
 	// Parameters:
 	//   - edge: One or more Edge instances to add to the graph.
 	//
@@ -65,22 +95,66 @@ type Connected[T SharedState] interface {
 	//	runtime.AddEdge(edge2, edge3, edge4) // Multiple edges at once
 	AddEdge(edge ...Edge[T])
 
+	// AddEdgeValidated adds one or more edges to the graph structure, like AddEdge, only if
+	// the resulting version passes the checks of Validate.
+	//
+	// Parameters:
+	//   - edge: One or more Edge instances to add to the graph.
+	//
+	// Returns:
+	//   - The error of Validate on the resulting version, which is then not published.
+	//
+	// Example:
+	//
+	//	if err := runtime.AddEdgeValidated(toReviewer, reviewerToEnd); err != nil {
+	//	    log.Printf("reconfiguration rejected: %v", err)
+	//	}
+	AddEdgeValidated(edge ...Edge[T]) error
+
+	// RemoveEdge removes one or more edges from the graph structure.
+	//
+	// Like AddEdge, it creates a new version of the graph: the running invocations
+	// continue on the version they started with. The removal is atomic, no edge is
+	// removed when one of them is not part of the graph or the resulting version does
+	// not pass the checks of Validate.
+	//
+	// Parameters:
+	//   - edge: One or more Edge instances previously added with AddEdge.
+	//
+	// Returns:
+	//   - ErrEdgeNotFound if an edge is not part of the graph; the StartEdge cannot be removed.
+	//   - The error of Validate on the resulting version.
+	//
+	// Example:
+	//
+	//	runtime.AddEdge(newRoute)
+	//	if err := runtime.RemoveEdge(oldRoute); err != nil {
+	//	    log.Printf("reconfiguration failed: %v", err)
+	//	}
+	RemoveEdge(edge ...Edge[T]) error
+
 	// Validate checks the integrity and correctness of the graph structure.
 	//
 	// This method performs structural validation to ensure the graph is properly
 	// formed and executable. It checks for:
-	//   - Exactly one StartEdge exists
-	//   - At least one EndEdge exists
+	//   - A path from the StartEdge, and from every entry point, to an EndEdge exists
+	//   - Exactly one StartEdge exists besides the ones of the named entry points, whose names are unique
 	//   - All nodes (except EndNode) have at least one outgoing edge
-	//   - No unreachable nodes or edges exist
-	//   - Graph topology is valid
+	//   - No unreachable nodes exist
+	//   - Node names are unique
+	//   - No edge references a nil node
+	//   - Routing policies implementing RouteValidator accept the outbound edges of their node
+	//   - No cycle exists, unless the runtime was created WithAllowCycles
+	//
+	// All the findings are reported at once: the returned error joins them and every
+	// finding wraps its sentinel error, e.g. ErrUnreachableNode, to be checked with errors.Is.
 	//
 	// It is recommended to call Validate() after adding all edges and before
 	// invoking the graph to catch configuration errors early.
 	//
 	// Returns:
 	//   - nil if the graph structure is valid and executable.
-	//   - An error describing the validation failure if the graph is invalid.
+	//   - An error joining all the findings if the graph is invalid.
 	//
 	// Example:
 	//
@@ -109,6 +183,20 @@ type InvokeConfig struct {
 	ThreadID string
 	// Context is the context for the invocation.
 	Context context.Context
+	// Priority orders the invocations waiting for admission, higher values are admitted first.
+	Priority int
+	// Timeout bounds the duration of the invocation, including the admission wait; zero means unlimited.
+	Timeout time.Duration
+	// Budget bounds the usage reported by the model calls of the invocation.
+	Budget Budget
+	// Tenant is the tenant the invocation belongs to, see InvokeConfigTenant.
+	Tenant string
+	// Metadata describe the invocation, see InvokeConfigMetadata.
+	Metadata map[string]string
+	// EntryPoint is the entry point the invocation enters the graph through, see InvokeConfigEntryPoint.
+	EntryPoint string
+	// Values are the typed values of the invocation, by name, see InvokeConfigValue.
+	Values map[string]InvocationValue
 }
 
 // MergeInvokeConfig merges multiple InvokeConfig instances into one.
@@ -139,6 +227,33 @@ func MergeInvokeConfig(config ...InvokeConfig) InvokeConfig {
 		if c.Context != nil {
 			merged.Context = c.Context
 		}
+		if c.Priority != 0 {
+			merged.Priority = c.Priority
+		}
+		if c.Timeout != 0 {
+			merged.Timeout = c.Timeout
+		}
+		if !c.Budget.IsZero() {
+			merged.Budget = c.Budget
+		}
+		if c.Tenant != "" {
+			merged.Tenant = c.Tenant
+		}
+		if len(c.Metadata) > 0 {
+			if merged.Metadata == nil {
+				merged.Metadata = make(map[string]string, len(c.Metadata))
+			}
+			maps.Copy(merged.Metadata, c.Metadata)
+		}
+		if c.EntryPoint != "" {
+			merged.EntryPoint = c.EntryPoint
+		}
+		if len(c.Values) > 0 {
+			if merged.Values == nil {
+				merged.Values = make(map[string]InvocationValue, len(c.Values))
+			}
+			maps.Copy(merged.Values, c.Values)
+		}
 	}
 	return merged
 }
@@ -200,6 +315,98 @@ func InvokeConfigContext(ctx context.Context) InvokeConfig {
 	return InvokeConfig{Context: ctx}
 }
 
+// InvokeConfigPriority creates an InvokeConfig with the specified admission priority.
+//
+// The priority matters only when the runtime limits the concurrent invocations, see
+// RuntimeSettings.MaxConcurrentInvocations: queued invocations with higher priority are admitted first.
+//
+// Parameters:
+//   - priority: The admission priority, zero by default.
+//
+// Returns:
+//   - An InvokeConfig instance with the specified priority.
+//
+// Example:
+//
+//	runtime.Invoke(userInput, InvokeConfigThreadID("vip-1"), InvokeConfigPriority(10))
+func InvokeConfigPriority(priority int) InvokeConfig {
+	return InvokeConfig{Priority: priority}
+}
+
+// InvokeConfigTimeout creates an InvokeConfig with the specified timeout.
+//
+// When the timeout expires, the context of the invocation is cancelled with ErrInvocationTimeout
+// and the invocation terminates with an error entry; a node which does not honour its context is
+// abandoned after RuntimeSettings.GracefulShutdownTimeout, its late outcome being discarded.
+//
+// Parameters:
+//   - timeout: The maximum duration of the invocation, including the admission wait.
+//
+// Returns:
+//   - An InvokeConfig instance with the specified timeout.
+//
+// Example:
+//
+//	runtime.Invoke(userInput, InvokeConfigThreadID("thread-1"), InvokeConfigTimeout(2*time.Minute))
+func InvokeConfigTimeout(timeout time.Duration) InvokeConfig {
+	return InvokeConfig{Timeout: timeout}
+}
+
+// InvokeConfigBudget creates an InvokeConfig with the specified usage budget.
+//
+// The usage reported by the model calls of all the nodes, see ReportUsage, is checked against
+// the budget: once exceeded, the context of the invocation is cancelled with ErrBudgetExceeded
+// and the invocation terminates with an error entry, as with InvokeConfigTimeout.
+//
+// Parameters:
+//   - budget: The limits of the invocation.
+//
+// Returns:
+//   - An InvokeConfig instance with the specified budget.
+//
+// Example:
+//
+//	runtime.Invoke(userInput, InvokeConfigBudget(Budget{MaxTokens: 50_000}))
+func InvokeConfigBudget(budget Budget) InvokeConfig {
+	return InvokeConfig{Budget: budget}
+}
+
+type threadIDContextKey struct{}
+
+// ContextWithThreadID returns a copy of the context carrying the thread identifier.
+//
+// The runtime sets it on the invocation context, so that context-aware nodes can identify their thread.
+//
+// Parameters:
+//   - ctx: The parent context.
+//   - threadID: The identifier of the thread.
+//
+// Returns:
+//   - The derived context.
+func ContextWithThreadID(ctx context.Context, threadID string) context.Context {
+	return context.WithValue(ctx, threadIDContextKey{}, threadID)
+}
+
+// ThreadIDFromContext returns the thread identifier carried by the context.
+//
+// Parameters:
+//   - ctx: The context, usually the one given to a ContextNodeFn.
+//
+// Returns:
+//   - The thread identifier.
+//   - false if the context carries no thread identifier.
+//
+// Example:
+//
+//	fn := func(ctx context.Context, userInput, currentState MyState, notify NotifyPartialFn[MyState]) (MyState, error) {
+//	    threadID, _ := ThreadIDFromContext(ctx)
+//	    ...
+//	}
+func ThreadIDFromContext(ctx context.Context) (string, bool) {
+	threadID, ok := ctx.Value(threadIDContextKey{}).(string)
+	return threadID, ok
+}
+
 // Runtime represents the execution engine for graph-based workflows.
 //
 // The Runtime is the central component that manages graph execution. It:
@@ -247,6 +454,69 @@ type Runtime[T SharedState] interface {
 	// Embeds Threaded to provide active thread retrieval capabilities.
 	Threaded
 
+	// Embeds DeadLettered to provide failed persistence reprocessing capabilities.
+	DeadLettered
+
+	// Embeds Admitting to provide admission metrics.
+	Admitting
+
+	// Embeds Tenanted to provide the usage of the tenants.
+	Tenanted
+
+	// Embeds Scheduled to provide the metrics of the outcome processing.
+	Scheduled
+
+	// Embeds Profiled to provide the latency and the throughput of the nodes.
+	Profiled
+
+	// Embeds RolledOut to run two versions of the graph side by side.
+	RolledOut
+
+	// Embeds Monitored to provide the delivery metrics of the monitor entries.
+	Monitored
+
+	// Embeds Debugger to step through the invocations.
+	Debugger[T]
+
+	// Embeds Analyzer to provide the static analysis of the graph.
+	Analyzer
+
+	// Embeds Topology to provide read-only access to the graph structure.
+	Topology[T]
+
+	// Embeds EventBus to provide independent subscriptions to the monitor entries.
+	EventBus[T]
+
+	// Embeds EventLogged to provide the audit trail of the threads.
+	EventLogged[T]
+
+	// Embeds Timelined to provide the execution timelines of the threads.
+	Timelined
+
+	// Embeds Stoppable to drain or abort the in-flight invocations on shutdown.
+	Stoppable
+
+	// Embeds Snapshotted to capture the threads for a warm restart.
+	Snapshotted[T]
+
+	// Embeds Durable to resume the invocations interrupted by a crash.
+	Durable
+
+	// Embeds Onced to execute the side effects of the threads at most once.
+	Onced
+
+	// Embeds Delaying to wake up the threads suspended by the delay nodes.
+	Delaying
+
+	// Embeds Signaled to deliver external events to the threads parked by the signal nodes.
+	Signaled[T]
+
+	// Embeds Batching to flush the inputs collected by the batch nodes.
+	Batching
+
+	// Embeds Scratched to access the scratchpads of the threads.
+	Scratched
+
 	// Invoke starts the graph execution with the provided user input.
 	//
 	// This method initiates the graph workflow by traversing the StartEdge to
@@ -298,7 +568,8 @@ type Runtime[T SharedState] interface {
 	//   - Internal goroutines are terminated
 	//   - Resources are released
 	//
-	// After calling Shutdown(), the runtime cannot be used again.
+	// After calling Shutdown(), the runtime cannot be used again. The in-flight invocations are
+	// abandoned without their terminal entry, see Stop to drain or abort them.
 	//
 	// Example:
 	//
@@ -309,6 +580,30 @@ type Runtime[T SharedState] interface {
 	//	runtime.Invoke(input)
 	Shutdown()
 
+	// Cancel stops the running invocation of a thread.
+	//
+	// The invocation context is cancelled with ErrThreadCancelled as cause, then Cancel
+	// waits for the current node to finish or abort: a terminal monitor entry is emitted
+	// and the thread is released, so that it can be invoked again. When the node does not
+	// return within the graceful shutdown timeout, the thread is released anyway and the
+	// late outcome of the node is discarded. An invocation waiting for admission is withdrawn
+	// from the queue straight away.
+	//
+	// Parameters:
+	//   - threadID: The identifier of the thread to cancel.
+	//
+	// Returns:
+	//   - ErrThreadNotExecuting if the thread has no running invocation, otherwise nil.
+	//
+	// Example:
+	//
+	//	threadID := runtime.Invoke(input)
+	//	// ...
+	//	if err := runtime.Cancel(threadID); err != nil {
+	//	    log.Printf("nothing to cancel: %v", err)
+	//	}
+	Cancel(threadID string) error
+
 	// StartEdge returns the entry edge of the graph.
 	//
 	// This is the edge that was provided during runtime creation and defines
@@ -1,10 +1,83 @@
 package graph
 
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"time"
+)
+
 // RuntimeOptions holds the configuration for a node.
 type RuntimeOptions[T SharedState] struct {
 	InitialState T
 	Memory       Memory[T]
 
+	DeadLetterQueue DeadLetterQueue[T]
+
+	// EventStore records the monitor entries, see WithEventStore.
+	EventStore EventStore[T]
+
+	Hooks Hooks[T]
+
+	// Logger receives the structured logs of the runtime, nil disables logging.
+	Logger *slog.Logger
+
+	// CloneFn copies the states handed out by the runtime, see WithCloneFn.
+	CloneFn CloneFn[T]
+
+	// AllowCycles accepts graphs with cycles, see WithAllowCycles.
+	AllowCycles bool
+	// MaxIterations is the number of times a node can be executed within an invocation, 0 means unlimited.
+	MaxIterations int
+
+	// ConflictResolver enables the conflict detection, see WithConflictDetection.
+	ConflictResolver ConflictResolverFn[T]
+
+	// Debug pauses the invocations before the nodes, see WithDebugger.
+	Debug bool
+	// Breakpoints are the nodes the invocations pause at, all of them when empty.
+	Breakpoints []string
+
+	// StreamingReducer folds the partial updates into the streaming view of the state, see WithStreamingView.
+	StreamingReducer ReducerFn[T]
+
+	// Chaos injects faults for the resilience testing, see WithChaos.
+	Chaos *Chaos
+
+	// StateDiff computes the changes reported by the monitor entries, see WithStateDiff.
+	StateDiff DiffFn[T]
+
+	// IDGenerator generates the identifiers of the threads, see WithIDGenerator.
+	IDGenerator IDGeneratorFn
+
+	// DefaultTenantQuota applies to the tenants without a quota in TenantQuotas, see WithTenantQuotas.
+	DefaultTenantQuota TenantQuota
+	// TenantQuotas are the quotas of specific tenants.
+	TenantQuotas map[string]TenantQuota
+
+	// TimelineThreads is the number of threads whose timeline is retained, see WithTimelineRetention.
+	TimelineThreads int
+	// TimelineInvocations is the number of invocations retained in the timeline of a thread.
+	TimelineInvocations int
+
+	// NodeStatsWindow is the number of most recent executions of a node its latency percentiles cover, see WithNodeStatsWindow.
+	NodeStatsWindow int
+	// LatencySLO is the latency objective of the nodes without one in NodeLatencySLOs, see WithLatencySLO.
+	LatencySLO time.Duration
+	// NodeLatencySLOs are the latency objectives of specific nodes.
+	NodeLatencySLOs map[string]time.Duration
+
+	// WorkQueue publishes the node executions to the worker processes, see WithWorkQueue.
+	WorkQueue WorkQueue
+	// RemoteNodes are the nodes executed by the workers, all the intermediate nodes when empty.
+	RemoteNodes []string
+	// LeaseStore grants the ownership of the threads among the instances sharing the memory, see WithThreadLeases.
+	LeaseStore LeaseStore
+	// LeaseTTL is the time after which the lease of a thread expires unless renewed.
+	LeaseTTL time.Duration
+	// Journal records the steps of the invocations to resume them after a crash, see WithJournal.
+	Journal Journal[T]
+
 	WorkerCount     int
 	WorkerQueueSize int
 
@@ -112,5 +185,536 @@ func WithSettings[T SharedState](settings RuntimeSettings) RuntimeOption[T] {
 	})
 }
 
+// WithDeadLetterQueue sets the queue receiving the persistence jobs which fail or cannot be enqueued.
+//
+// Parameters:
+//   - queue: An instance of DeadLetterQueue[T] to be used by the runtime.
+//
+// Returns:
+//   - A RuntimeOption that sets the dead-letter queue.
+//
+// Example:
+//
+//	dlq, _ := builders.NewChannelDeadLetterQueue[MyState](100)
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, WithMemory(memory), WithDeadLetterQueue(dlq))
+func WithDeadLetterQueue[T SharedState](queue DeadLetterQueue[T]) RuntimeOption[T] {
+	return RuntimeOptionFunc[T](func(r *RuntimeOptions[T]) error {
+		r.DeadLetterQueue = queue
+		return nil
+	})
+}
+
 // TODO pluggable log
 // TODO observability hooks
+
+// WithHooks sets the callbacks invoked by the runtime along the execution of the threads.
+//
+// Parameters:
+//   - hooks: The hooks, see ChainHooks to combine several of them.
+//
+// Returns:
+//   - A RuntimeOption that sets the hooks.
+//
+// Example:
+//
+//	hooks := graph.Hooks[MyState]{
+//	    AfterNode: func(ctx context.Context, threadID, node string, state MyState) error {
+//	        audit.Record(threadID, node, state)
+//	        return nil
+//	    },
+//	}
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, WithHooks(hooks))
+func WithHooks[T SharedState](hooks Hooks[T]) RuntimeOption[T] {
+	return RuntimeOptionFunc[T](func(r *RuntimeOptions[T]) error {
+		r.Hooks = hooks
+		return nil
+	})
+}
+
+// WithLogger sets the structured logger of the graph runtime.
+//
+// The runtime logs the invocations at info level, the routing decisions at debug level, the
+// non-fatal errors like persistence failures, dropped monitor entries and evictions at warn level and
+// the failed invocations at error level; entries carry the thread ID and node attributes.
+//
+// Parameters:
+//   - logger: The logger, nil disables logging.
+//
+// Returns:
+//   - A RuntimeOption that sets the logger.
+//
+// Example:
+//
+//	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, WithLogger[MyState](logger))
+func WithLogger[T SharedState](logger *slog.Logger) RuntimeOption[T] {
+	return RuntimeOptionFunc[T](func(r *RuntimeOptions[T]) error {
+		r.Logger = logger
+		return nil
+	})
+}
+
+// WithCloneFn sets the function copying the states handed out by the runtime.
+//
+// The states given to the nodes, the hooks, the persistence and the state monitor entries, and
+// returned by CurrentState, are copies: callers can read or modify them without racing with the
+// runtime. Without a CloneFn, states implementing Cloneable are copied with their Clone method and
+// the other states are shared as they are.
+//
+// Parameters:
+//   - cloneFn: The clone function.
+//
+// Returns:
+//   - A RuntimeOption that sets the clone function.
+//
+// Example:
+//
+//	cloneFn := func(state MyState) MyState {
+//	    state.Items = slices.Clone(state.Items)
+//	    return state
+//	}
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, WithCloneFn(cloneFn))
+func WithCloneFn[T SharedState](cloneFn CloneFn[T]) RuntimeOption[T] {
+	return RuntimeOptionFunc[T](func(r *RuntimeOptions[T]) error {
+		r.CloneFn = cloneFn
+		return nil
+	})
+}
+
+// WithAllowCycles accepts graphs with cycles, bounding the number of iterations of the loops.
+//
+// Without this option Validate fails when the graph has a cycle, see Analyzer. With it, an
+// invocation executing any node more than maxIterations times fails with ErrMaxIterationsExceeded,
+// preventing a thread from spinning forever in an accidental loop.
+//
+// Parameters:
+//   - maxIterations: The number of times a node can be executed within an invocation.
+//
+// Returns:
+//   - A RuntimeOption that allows the cycles.
+//
+// Example:
+//
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, WithAllowCycles[MyState](10))
+func WithAllowCycles[T SharedState](maxIterations int) RuntimeOption[T] {
+	return RuntimeOptionFunc[T](func(r *RuntimeOptions[T]) error {
+		if maxIterations < 1 {
+			return ErrInvalidMaxIterations
+		}
+		r.AllowCycles = true
+		r.MaxIterations = maxIterations
+		return nil
+	})
+}
+
+// WithConflictDetection detects the conflicting writes to the state, mediating them with the resolver.
+//
+// The runtime versions the state of every thread and records the version each node is dispatched
+// with. When the change of a node is reduced after concurrent writes to the same top level fields,
+// the conflict is reported as a non-fatal ErrStateConflict and the state is the one returned by
+// the resolver; an error of the resolver fails the invocation. Writes to disjoint fields are
+// reduced as usual.
+//
+// Parameters:
+//   - resolver: The conflict resolver, e.g. ResolveLastWriterWins, ResolveFirstWriterWins or ResolveFail.
+//
+// Returns:
+//   - A RuntimeOption that enables the conflict detection.
+//
+// Example:
+//
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, WithConflictDetection(ResolveFail[MyState]()))
+func WithConflictDetection[T SharedState](resolver ConflictResolverFn[T]) RuntimeOption[T] {
+	return RuntimeOptionFunc[T](func(r *RuntimeOptions[T]) error {
+		if resolver == nil {
+			return ErrConflictResolverNil
+		}
+		r.ConflictResolver = resolver
+		return nil
+	})
+}
+
+// WithEventStore records every monitor entry into the event store, see EventLogged.
+//
+// The events are appended synchronously as they are published, so that the log is complete
+// and ordered: a slow store slows the runtime down. The failures to append are logged.
+//
+// Parameters:
+//   - store: The event store.
+//
+// Returns:
+//   - A RuntimeOption that sets the event store.
+//
+// Example:
+//
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, WithEventStore(builders.NewMemEventStore[MyState]()))
+func WithEventStore[T SharedState](store EventStore[T]) RuntimeOption[T] {
+	return RuntimeOptionFunc[T](func(r *RuntimeOptions[T]) error {
+		if store == nil {
+			return ErrEventStoreNil
+		}
+		r.EventStore = store
+		return nil
+	})
+}
+
+// WithStreamingView folds the partial updates of the nodes into a per-thread streaming view of the state.
+//
+// Without this option the partial updates notified through NotifyPartialFn are only delivered to
+// the monitor. With it, the first partial update of a node is reduced by the streaming reducer
+// into a copy of the committed state, and the following ones into the result of the previous
+// fold, so that CurrentState reflects the streamed progress. The view is dropped as soon as the
+// final change of the node is reduced, or the invocation ends: the committed state, which the
+// nodes are executed with and which is persisted, is never affected by the partial updates.
+//
+// Parameters:
+//   - reducer: The function folding a partial update into the streaming view.
+//
+// Returns:
+//   - A RuntimeOption that enables the streaming view.
+//
+// Example:
+//
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, WithStreamingView(func(view, partial MyState) MyState {
+//	    view.Answer += partial.Token
+//	    return view
+//	}))
+func WithStreamingView[T SharedState](reducer ReducerFn[T]) RuntimeOption[T] {
+	return RuntimeOptionFunc[T](func(r *RuntimeOptions[T]) error {
+		if reducer == nil {
+			return ErrStreamingReducerNil
+		}
+		r.StreamingReducer = reducer
+		return nil
+	})
+}
+
+// WithDebugger enables the step-through debugger mode, see Debugger.
+//
+// Every invocation pauses before its first node, or before its first breakpoint node when
+// breakpoints are given, and emits a monitor entry carrying the Breakpoint: Step executes the
+// pending node and pauses before the next one, Continue runs until the next breakpoint node.
+// The runtime is meant for interactive development: a paused thread holds its admission slot.
+//
+// Parameters:
+//   - breakpoints: The names of the nodes to pause at, all the nodes when empty.
+//
+// Returns:
+//   - A RuntimeOption that enables the debugger mode.
+//
+// Example:
+//
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, WithDebugger[MyState]("Planner", "Executor"))
+func WithDebugger[T SharedState](breakpoints ...string) RuntimeOption[T] {
+	return RuntimeOptionFunc[T](func(r *RuntimeOptions[T]) error {
+		r.Debug = true
+		r.Breakpoints = append(r.Breakpoints, breakpoints...)
+		return nil
+	})
+}
+
+// WithChaos injects faults into the runtime, for the resilience testing of the graphs and of their consumers.
+//
+// The nodes fail with ErrInjectedFault instead of being executed, through the usual failure path
+// of the invocations; the writes to the memory are delayed; the monitor entries are dropped as
+// when the channel is full; the threads are evicted right after their invocations complete, as
+// if they had been inactive. The option is meant for tests, never enable it in production.
+//
+// Parameters:
+//   - chaos: The faults to inject.
+//
+// Returns:
+//   - A RuntimeOption that enables the fault injection.
+//
+// Example:
+//
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, WithMemory(memory), WithChaos[MyState](graph.Chaos{
+//	    NodeErrorRate:    0.1,
+//	    PersistenceDelay: 200 * time.Millisecond,
+//	    MonitorDropRate:  0.05,
+//	    Seed:             42,
+//	}))
+func WithChaos[T SharedState](chaos Chaos) RuntimeOption[T] {
+	return RuntimeOptionFunc[T](func(r *RuntimeOptions[T]) error {
+		if err := chaos.Validate(); err != nil {
+			return err
+		}
+		r.Chaos = &chaos
+		return nil
+	})
+}
+
+// WithStateDiff reports in the monitor entries the fields changed by each node.
+//
+// The runtime compares the state of the thread before and after the update of the node is
+// reduced, so the changes reflect the reducer rather than the raw update, and sets them in
+// StateMonitorEntry.Changes of the complete node entries. The state is copied before every
+// reduction, with the CloneFn or the Clone method, to be compared.
+//
+// Parameters:
+//   - diffFn: The function computing the changes, Diff when nil.
+//
+// Returns:
+//   - A RuntimeOption that enables the state diffing.
+//
+// Example:
+//
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, graph.WithStateDiff[MyState](nil))
+//	for entry := range stateMonitorCh {
+//	    for _, change := range entry.Changes {
+//	        log.Printf("%s changed %s: %v -> %v", entry.Node, change.Path, change.Before, change.After)
+//	    }
+//	}
+func WithStateDiff[T SharedState](diffFn DiffFn[T]) RuntimeOption[T] {
+	return RuntimeOptionFunc[T](func(r *RuntimeOptions[T]) error {
+		if diffFn == nil {
+			diffFn = Diff[T]
+		}
+		r.StateDiff = diffFn
+		return nil
+	})
+}
+
+// WithIDGenerator sets the function generating the identifiers of the threads.
+//
+// The runtime calls it for the invocations without a thread ID, for the forks and for NewThreadID;
+// without this option the threads are identified by a UUID.
+//
+// Parameters:
+//   - generator: The ID generator, e.g. ULID or PrefixedIDs.
+//
+// Returns:
+//   - A RuntimeOption that sets the ID generator.
+//
+// Example:
+//
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, graph.WithIDGenerator[MyState](graph.ULID))
+func WithIDGenerator[T SharedState](generator IDGeneratorFn) RuntimeOption[T] {
+	return RuntimeOptionFunc[T](func(r *RuntimeOptions[T]) error {
+		if generator == nil {
+			return ErrIDGeneratorNil
+		}
+		r.IDGenerator = generator
+		return nil
+	})
+}
+
+// WithTenantQuotas bounds the resources each tenant can use in the runtime.
+//
+// The quotas apply to the invocations configured with InvokeConfigTenant. A new thread of a tenant
+// holding MaxActiveThreads threads, or an invocation of a tenant running MaxConcurrentInvocations
+// invocations, is rejected with ErrTenantQuotaExceeded; an invocation routed to more than MaxSteps
+// nodes fails with it. The invocations without a tenant are not bounded. The tenants of the quotas
+// must pass ValidateTenant.
+//
+// Parameters:
+//   - defaultQuota: The quota of the tenants without a specific one.
+//   - quotas: The quotas of specific tenants, by tenant identifier.
+//
+// Returns:
+//   - A RuntimeOption that sets the tenant quotas.
+//
+// Example:
+//
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, graph.WithTenantQuotas[MyState](
+//	    graph.TenantQuota{MaxActiveThreads: 100, MaxConcurrentInvocations: 10},
+//	    map[string]graph.TenantQuota{"acme": {MaxActiveThreads: 1000, MaxConcurrentInvocations: 50}},
+//	))
+func WithTenantQuotas[T SharedState](defaultQuota TenantQuota, quotas map[string]TenantQuota) RuntimeOption[T] {
+	return RuntimeOptionFunc[T](func(r *RuntimeOptions[T]) error {
+		for tenant, quota := range quotas {
+			if err := ValidateTenant(tenant); err != nil {
+				return err
+			}
+			if !quota.valid() {
+				return fmt.Errorf("%w: tenant %s", ErrInvalidTenantQuota, tenant)
+			}
+		}
+		if !defaultQuota.valid() {
+			return ErrInvalidTenantQuota
+		}
+		r.DefaultTenantQuota = defaultQuota
+		r.TenantQuotas = maps.Clone(quotas)
+		return nil
+	})
+}
+
+// WithTimelineRetention bounds the execution timelines retained by the runtime, see Timelined.
+//
+// Without this option the runtime retains the last DefaultTimelineInvocations invocations of the
+// DefaultTimelineThreads most recently invoked threads.
+//
+// Parameters:
+//   - threads: The number of threads whose timeline is retained, the least recently invoked are discarded first.
+//   - invocations: The number of invocations retained in the timeline of a thread, the oldest are discarded first.
+//
+// Returns:
+//   - A RuntimeOption that sets the timeline retention.
+//
+// Example:
+//
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, graph.WithTimelineRetention[MyState](100, 1))
+func WithTimelineRetention[T SharedState](threads, invocations int) RuntimeOption[T] {
+	return RuntimeOptionFunc[T](func(r *RuntimeOptions[T]) error {
+		if threads <= 0 || invocations <= 0 {
+			return fmt.Errorf("%w: %d threads, %d invocations", ErrInvalidTimelineRetention, threads, invocations)
+		}
+		r.TimelineThreads = threads
+		r.TimelineInvocations = invocations
+		return nil
+	})
+}
+
+// WithNodeStatsWindow sets the number of most recent executions of a node its latency percentiles
+// are computed on, see Profiled; without this option DefaultNodeStatsWindow executions are retained.
+//
+// Parameters:
+//   - executions: The number of executions retained per node, a larger window smooths the percentiles.
+//
+// Returns:
+//   - A RuntimeOption that sets the window.
+//
+// Example:
+//
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, graph.WithNodeStatsWindow[MyState](10_000))
+func WithNodeStatsWindow[T SharedState](executions int) RuntimeOption[T] {
+	return RuntimeOptionFunc[T](func(r *RuntimeOptions[T]) error {
+		if executions <= 0 {
+			return fmt.Errorf("%w: %d executions", ErrInvalidNodeStatsWindow, executions)
+		}
+		r.NodeStatsWindow = executions
+		return nil
+	})
+}
+
+// WithLatencySLO sets the latency objective of the nodes, the executions exceeding it are counted
+// as breaches in their NodeStats, see Profiled.
+//
+// Parameters:
+//   - target: The longest acceptable duration of an execution.
+//   - nodes: The nodes the objective applies to, all the nodes without a specific objective when empty.
+//
+// Returns:
+//   - A RuntimeOption that sets the latency objective.
+//
+// Example:
+//
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh,
+//	    graph.WithLatencySLO[MyState](time.Second),
+//	    graph.WithLatencySLO[MyState](5*time.Second, "Planner", "Writer"),
+//	)
+func WithLatencySLO[T SharedState](target time.Duration, nodes ...string) RuntimeOption[T] {
+	return RuntimeOptionFunc[T](func(r *RuntimeOptions[T]) error {
+		if target <= 0 {
+			return fmt.Errorf("%w: %s", ErrInvalidLatencySLO, target)
+		}
+		if len(nodes) == 0 {
+			r.LatencySLO = target
+			return nil
+		}
+		if r.NodeLatencySLOs == nil {
+			r.NodeLatencySLOs = make(map[string]time.Duration, len(nodes))
+		}
+		for _, node := range nodes {
+			r.NodeLatencySLOs[node] = target
+		}
+		return nil
+	})
+}
+
+// WithWorkQueue executes the nodes in worker processes, through a work queue.
+//
+// The runtime keeps routing the invocations, persisting the state of the thread in its memory
+// before publishing each node execution; a worker restores the state from the same memory,
+// executes the node and replies with the reduced state. The partial updates of the remote nodes
+// are not streamed. The runtime requires a memory shared with the workers, see WithMemory.
+//
+// Parameters:
+//   - queue: The work queue shared with the workers.
+//   - nodes: The names of the nodes executed by the workers, all the intermediate nodes when empty.
+//
+// Returns:
+//   - A RuntimeOption that sets the work queue.
+//
+// Example:
+//
+//	queue, _ := redis.NewWorkQueue(client)
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh,
+//	    graph.WithMemory[MyState](sharedMemory),
+//	    graph.WithWorkQueue[MyState](queue, "Research", "Summarize"),
+//	)
+func WithWorkQueue[T SharedState](queue WorkQueue, nodes ...string) RuntimeOption[T] {
+	return RuntimeOptionFunc[T](func(r *RuntimeOptions[T]) error {
+		if queue == nil {
+			return ErrWorkQueueNil
+		}
+		r.WorkQueue = queue
+		r.RemoteNodes = nodes
+		return nil
+	})
+}
+
+// WithThreadLeases lets a single runtime instance at a time execute a thread, among the instances sharing a memory.
+//
+// An invocation first acquires the lease of its thread, it is rejected with ErrThreadOwned
+// while another instance holds it; the lease is renewed every third of its TTL until the final
+// state is persisted, then released. The lease of a failed instance expires after the TTL: the
+// next instance invoking the thread takes it over, restoring the state from the memory, and the
+// failed instance, if only stalled, aborts its invocation with ErrLeaseLost and discards its
+// pending writes. The runtime requires a memory shared with the other instances, see WithMemory.
+//
+// Parameters:
+//   - store: The lease store shared with the other instances.
+//   - ttl: The time after which the lease of a failed instance expires.
+//
+// Returns:
+//   - A RuntimeOption that sets the thread leases.
+//
+// Example:
+//
+//	leases, _ := sqlite.NewLeaseStore(db)
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh,
+//	    graph.WithMemory[MyState](sharedMemory),
+//	    graph.WithThreadLeases[MyState](leases, 30*time.Second),
+//	)
+func WithThreadLeases[T SharedState](store LeaseStore, ttl time.Duration) RuntimeOption[T] {
+	return RuntimeOptionFunc[T](func(r *RuntimeOptions[T]) error {
+		if store == nil {
+			return ErrLeaseStoreNil
+		}
+		if ttl <= 0 {
+			return fmt.Errorf("%w: %s", ErrInvalidLeaseTTL, ttl)
+		}
+		r.LeaseStore = store
+		r.LeaseTTL = ttl
+		return nil
+	})
+}
+
+// WithJournal records the steps of the invocations into the journal, so that they can be resumed after a crash, see Durable.
+//
+// Every node completing is recorded with its routing decision and the resulting state before the
+// next node is entered: the failure to record a step fails the invocation, a step which cannot be
+// recovered is never executed past. The steps of a thread are forgotten when its invocation
+// completes, or when it is invoked again. The journal is written synchronously: a slow journal
+// slows the invocations down.
+//
+// Parameters:
+//   - journal: The journal, durable for the invocations to survive the process.
+//
+// Returns:
+//   - A RuntimeOption that sets the journal.
+//
+// Example:
+//
+//	journal, _ := sqlite.NewJournal[MyState](db)
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, graph.WithJournal[MyState](journal))
+func WithJournal[T SharedState](journal Journal[T]) RuntimeOption[T] {
+	return RuntimeOptionFunc[T](func(r *RuntimeOptions[T]) error {
+		if journal == nil {
+			return ErrJournalNil
+		}
+		r.Journal = journal
+		return nil
+	})
+}
@@ -12,21 +12,55 @@ const (
 	RuntimeSettingDefaultOutcomeNotificationQueueSize = 100
 	// RuntimeSettingDefaultOutcomeNotificationMaxInterval is the default maximum interval between outcome notifications.
 	RuntimeSettingDefaultOutcomeNotificationMaxInterval = 100 * time.Millisecond
+	// RuntimeSettingDefaultMonitorDeliveryPolicy is the default delivery policy of the monitor entries when the channel is full.
+	RuntimeSettingDefaultMonitorDeliveryPolicy = MonitorDropNewest
 
 	// RuntimeSettingDefaultPersistenceQueueSize is the default size of the queue in the runtime worker which flushes pending states.
 	RuntimeSettingDefaultPersistenceQueueSize = 10
 	// RuntimeSettingDefaultPersistenceTimeout is the default timeout between persistence flushes.
 	RuntimeSettingDefaultPersistenceTimeout = 5 * time.Second
 
+	// RuntimeSettingDefaultPersistenceMode is the default persistence mode, the state is persisted after every node.
+	RuntimeSettingDefaultPersistenceMode = PersistEveryNode
+	// RuntimeSettingDefaultPersistenceDebounce is the default minimum interval between two persistence jobs of a thread, zero disables debouncing.
+	RuntimeSettingDefaultPersistenceDebounce = time.Duration(0)
+
 	// RuntimeSettingDefaultThreadTTL is the default time-to-live for inactive threads.
 	RuntimeSettingDefaultThreadTTL = 1 * time.Hour
 	// RuntimeSettingDefaultThreadEvictorInterval is the default interval for evicting inactive threads.
 	RuntimeSettingDefaultThreadEvictorInterval = 5 * time.Minute
 
+	// RuntimeSettingDefaultMaxConcurrentInvocations is the default maximum number of concurrent invocations, zero means unlimited.
+	RuntimeSettingDefaultMaxConcurrentInvocations = 0
+	// RuntimeSettingDefaultMaxQueuedInvocations is the default maximum number of invocations waiting for admission, zero means unlimited.
+	RuntimeSettingDefaultMaxQueuedInvocations = 0
+
 	// RuntimeSettingDefaultGracefulShutdownTimeout is the default timeout for graceful shutdown operations.
 	RuntimeSettingDefaultGracefulShutdownTimeout = 10 * time.Second
 )
 
+// PersistenceMode defines when the runtime persists the state of a thread.
+type PersistenceMode int
+
+const (
+	// PersistEveryNode persists the state after every node completion.
+	PersistEveryNode PersistenceMode = iota
+	// PersistAtInvocationEnd persists the state only when the invocation ends, either completed or aborted.
+	PersistAtInvocationEnd
+)
+
+// String returns the string representation of the PersistenceMode.
+func (m PersistenceMode) String() string {
+	switch m {
+	case PersistEveryNode:
+		return "PersistEveryNode"
+	case PersistAtInvocationEnd:
+		return "PersistAtInvocationEnd"
+	default:
+		return "Unknown"
+	}
+}
+
 // RuntimeSettings holds the configuration settings for the graph runtime.
 type RuntimeSettings struct {
 	// DefaultWorkerCount is the default number of workers in the runtime.
@@ -38,17 +72,33 @@ type RuntimeSettings struct {
 	OutcomeNotificationQueueSize int
 	// OutcomeNotificationMaxInterval is the default maximum interval between outcome notifications.
 	OutcomeNotificationMaxInterval time.Duration
+	// MonitorDeliveryPolicy defines how the monitor entries are delivered when the state monitor channel is full.
+	MonitorDeliveryPolicy MonitorDeliveryPolicy
+	// MonitorBufferDir is the directory of the buffer file of MonitorBufferToDisk, the default temporary directory when empty.
+	MonitorBufferDir string
 
 	// PersistenceJobsQueueSize is the default size of the queue in the runtime worker which flushes pending states.
 	PersistenceJobsQueueSize int
 	// PersistenceJobTimeout is the default timeout between persistence flushes.
 	PersistenceJobTimeout time.Duration
+	// PersistenceMode defines when the state of a thread is persisted.
+	PersistenceMode PersistenceMode
+	// PersistenceDebounce is the minimum interval between two persistence jobs of a thread.
+	// Intermediate states within the interval are skipped; the end of an invocation is always persisted.
+	PersistenceDebounce time.Duration
 
 	// ThreadTTL is the default time-to-live for inactive threads.
 	ThreadTTL time.Duration
 	// ThreadEvictorInterval is the default interval for evicting inactive threads.
 	ThreadEvictorInterval time.Duration
 
+	// MaxConcurrentInvocations is the maximum number of invocations executing at the same time, zero means unlimited.
+	// Further invocations wait in the admission queue, ordered by priority.
+	MaxConcurrentInvocations int
+	// MaxQueuedInvocations is the maximum number of invocations waiting for admission, zero means unlimited.
+	// Invocations exceeding it are rejected with ErrAdmissionQueueFull.
+	MaxQueuedInvocations int
+
 	// GracefulShutdownTimeout is the default timeout for graceful shutdown operations.
 	GracefulShutdownTimeout time.Duration
 }
@@ -59,13 +109,19 @@ var defaultRuntimeSettings = RuntimeSettings{
 
 	OutcomeNotificationQueueSize:   RuntimeSettingDefaultOutcomeNotificationQueueSize,
 	OutcomeNotificationMaxInterval: RuntimeSettingDefaultOutcomeNotificationMaxInterval,
+	MonitorDeliveryPolicy:          RuntimeSettingDefaultMonitorDeliveryPolicy,
 
 	PersistenceJobsQueueSize: RuntimeSettingDefaultPersistenceQueueSize,
 	PersistenceJobTimeout:    RuntimeSettingDefaultPersistenceTimeout,
+	PersistenceMode:          RuntimeSettingDefaultPersistenceMode,
+	PersistenceDebounce:      RuntimeSettingDefaultPersistenceDebounce,
 
 	ThreadTTL:             RuntimeSettingDefaultThreadTTL,
 	ThreadEvictorInterval: RuntimeSettingDefaultThreadEvictorInterval,
 
+	MaxConcurrentInvocations: RuntimeSettingDefaultMaxConcurrentInvocations,
+	MaxQueuedInvocations:     RuntimeSettingDefaultMaxQueuedInvocations,
+
 	GracefulShutdownTimeout: RuntimeSettingDefaultGracefulShutdownTimeout,
 }
 
@@ -86,6 +142,12 @@ func FillRuntimeSettingsWithDefaults(s RuntimeSettings) RuntimeSettings {
 	if s.OutcomeNotificationMaxInterval != 0 {
 		merged.OutcomeNotificationMaxInterval = s.OutcomeNotificationMaxInterval
 	}
+	if s.MonitorDeliveryPolicy != 0 {
+		merged.MonitorDeliveryPolicy = s.MonitorDeliveryPolicy
+	}
+	if s.MonitorBufferDir != "" {
+		merged.MonitorBufferDir = s.MonitorBufferDir
+	}
 
 	if s.PersistenceJobsQueueSize != 0 {
 		merged.PersistenceJobsQueueSize = s.PersistenceJobsQueueSize
@@ -93,6 +155,12 @@ func FillRuntimeSettingsWithDefaults(s RuntimeSettings) RuntimeSettings {
 	if s.PersistenceJobTimeout != 0 {
 		merged.PersistenceJobTimeout = s.PersistenceJobTimeout
 	}
+	if s.PersistenceMode != 0 {
+		merged.PersistenceMode = s.PersistenceMode
+	}
+	if s.PersistenceDebounce != 0 {
+		merged.PersistenceDebounce = s.PersistenceDebounce
+	}
 
 	if s.ThreadTTL != 0 {
 		merged.ThreadTTL = s.ThreadTTL
@@ -101,6 +169,13 @@ func FillRuntimeSettingsWithDefaults(s RuntimeSettings) RuntimeSettings {
 		merged.ThreadEvictorInterval = s.ThreadEvictorInterval
 	}
 
+	if s.MaxConcurrentInvocations != 0 {
+		merged.MaxConcurrentInvocations = s.MaxConcurrentInvocations
+	}
+	if s.MaxQueuedInvocations != 0 {
+		merged.MaxQueuedInvocations = s.MaxQueuedInvocations
+	}
+
 	if s.GracefulShutdownTimeout != 0 {
 		merged.GracefulShutdownTimeout = s.GracefulShutdownTimeout
 	}
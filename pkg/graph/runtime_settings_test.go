@@ -130,6 +130,46 @@ func TestFillRuntimeSettingsWithDefaults(t *testing.T) {
 				GracefulShutdownTimeout:        graph.RuntimeSettingDefaultGracefulShutdownTimeout,
 			},
 		},
+		{
+			name: "custom persistence coalescing should override default",
+			input: graph.RuntimeSettings{
+				PersistenceMode:     graph.PersistAtInvocationEnd,
+				PersistenceDebounce: 500 * time.Millisecond,
+			},
+			expected: graph.RuntimeSettings{
+				DefaultWorkerCount:             graph.RuntimeSettingDefaultWorkerCount,
+				DefaultWorkerQueueSize:         graph.RuntimeSettingDefaultWorkerQueueSize,
+				OutcomeNotificationQueueSize:   graph.RuntimeSettingDefaultOutcomeNotificationQueueSize,
+				OutcomeNotificationMaxInterval: graph.RuntimeSettingDefaultOutcomeNotificationMaxInterval,
+				PersistenceJobsQueueSize:       graph.RuntimeSettingDefaultPersistenceQueueSize,
+				PersistenceJobTimeout:          graph.RuntimeSettingDefaultPersistenceTimeout,
+				PersistenceMode:                graph.PersistAtInvocationEnd,
+				PersistenceDebounce:            500 * time.Millisecond,
+				ThreadTTL:                      graph.RuntimeSettingDefaultThreadTTL,
+				ThreadEvictorInterval:          graph.RuntimeSettingDefaultThreadEvictorInterval,
+				GracefulShutdownTimeout:        graph.RuntimeSettingDefaultGracefulShutdownTimeout,
+			},
+		},
+		{
+			name: "custom monitor delivery should override default",
+			input: graph.RuntimeSettings{
+				MonitorDeliveryPolicy: graph.MonitorBufferToDisk,
+				MonitorBufferDir:      "/var/spool/ggraph",
+			},
+			expected: graph.RuntimeSettings{
+				DefaultWorkerCount:             graph.RuntimeSettingDefaultWorkerCount,
+				DefaultWorkerQueueSize:         graph.RuntimeSettingDefaultWorkerQueueSize,
+				OutcomeNotificationQueueSize:   graph.RuntimeSettingDefaultOutcomeNotificationQueueSize,
+				OutcomeNotificationMaxInterval: graph.RuntimeSettingDefaultOutcomeNotificationMaxInterval,
+				MonitorDeliveryPolicy:          graph.MonitorBufferToDisk,
+				MonitorBufferDir:               "/var/spool/ggraph",
+				PersistenceJobsQueueSize:       graph.RuntimeSettingDefaultPersistenceQueueSize,
+				PersistenceJobTimeout:          graph.RuntimeSettingDefaultPersistenceTimeout,
+				ThreadTTL:                      graph.RuntimeSettingDefaultThreadTTL,
+				ThreadEvictorInterval:          graph.RuntimeSettingDefaultThreadEvictorInterval,
+				GracefulShutdownTimeout:        graph.RuntimeSettingDefaultGracefulShutdownTimeout,
+			},
+		},
 		{
 			name: "custom ThreadTTL should override default",
 			input: graph.RuntimeSettings{
@@ -0,0 +1,41 @@
+package graph
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrCompensationNil indicates that the provided compensation function is nil.
+	ErrCompensationNil = errors.New("compensation function cannot be nil")
+	// ErrCompensationFailed indicates that the compensation of a node returned an error.
+	ErrCompensationFailed = errors.New("compensation failed")
+)
+
+// CompensationFn undoes the side effects of a completed node, once the invocation failed further on.
+//
+// Parameters:
+//   - ctx: The context of the invocation, not cancelled by the failure being compensated.
+//   - userInput: The original input provided to Runtime.Invoke().
+//   - currentState: The state of the thread when the compensation runs.
+//
+// Returns:
+//   - The compensated state.
+//   - An error if the side effects cannot be undone, the other compensations run anyway.
+//
+// Example:
+//
+//	func refund(ctx context.Context, userInput MyState, state MyState) (MyState, error) {
+//	    if err := payments.Refund(ctx, state.ChargeID); err != nil {
+//	        return state, err
+//	    }
+//	    state.ChargeID = ""
+//	    return state, nil
+//	}
+type CompensationFn[T SharedState] func(ctx context.Context, userInput, currentState T) (T, error)
+
+// Compensable is implemented by the nodes whose side effects can be undone, see WithCompensation.
+type Compensable[T SharedState] interface {
+	// Compensation returns the compensation of the node, nil if the node has none.
+	Compensation() CompensationFn[T]
+}
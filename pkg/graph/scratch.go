@@ -0,0 +1,203 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrScratchKeyEmpty indicates that the key of a scratchpad entry is empty.
+	ErrScratchKeyEmpty = errors.New("scratchpad key cannot be empty")
+	// ErrScratchpadNotSet indicates that the context does not belong to an invocation with a scratchpad.
+	ErrScratchpadNotSet = errors.New("no scratchpad in context")
+)
+
+// ScratchStore persists the scratchpads of the threads, see Scratchpad.
+//
+// A Memory implementing ScratchStore persists the entries set with Scratchpad.Set, so that they
+// survive the eviction of the thread and the process; the scratchpads are kept in memory only
+// otherwise.
+//
+// Implementations must be safe for concurrent use.
+type ScratchStore interface {
+	// SaveScratch stores an entry of the scratchpad of the thread, replacing the previous one.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation and timeout control.
+	//   - threadID: The thread owning the scratchpad.
+	//   - key: The key of the entry.
+	//   - value: The JSON encoded value.
+	//
+	// Returns:
+	//   - An error if the entry cannot be stored.
+	SaveScratch(ctx context.Context, threadID, key string, value []byte) error
+	// LoadScratch returns the entries of the scratchpad of the thread.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation and timeout control.
+	//   - threadID: The thread owning the scratchpad.
+	//
+	// Returns:
+	//   - The JSON encoded values, by key.
+	//   - An error if the entries cannot be read.
+	LoadScratch(ctx context.Context, threadID string) (map[string][]byte, error)
+	// DeleteScratch deletes an entry of the scratchpad of the thread.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation and timeout control.
+	//   - threadID: The thread owning the scratchpad.
+	//   - key: The key of the entry.
+	//
+	// Returns:
+	//   - An error if the entry cannot be deleted.
+	DeleteScratch(ctx context.Context, threadID, key string) error
+}
+
+// Scratchpad is an auxiliary key/value store of a thread, apart from the state.
+//
+// The entries are neither reduced nor sent to the monitor: use the scratchpad for the intermediate
+// artifacts of the nodes, like the fetched documents, that would bloat the state. The entries are
+// shared by the invocations of the thread and dropped along with the thread held in memory, i.e.
+// when it is evicted or, with a Memory, once its invocation terminates; with a ScratchStore, the
+// ones set with Set are persisted and restored on the next access.
+//
+// Implementations must be safe for concurrent use.
+type Scratchpad interface {
+	// Get returns the value of the entry.
+	//
+	// A value restored from the ScratchStore or from a RuntimeSnapshot is a json.RawMessage, use
+	// ScratchValue to read typed values.
+	//
+	// Parameters:
+	//   - key: The key of the entry.
+	//
+	// Returns:
+	//   - The value.
+	//   - false if the scratchpad has no such entry.
+	Get(key string) (any, bool)
+	// Set sets the entry, persisting it with the ScratchStore of the runtime, if any.
+	//
+	// Parameters:
+	//   - key: The key of the entry.
+	//   - value: The value, it must be JSON encodable to be persisted.
+	//
+	// Returns:
+	//   - An error wrapping ErrScratchKeyEmpty, or the error of the encoding or of the store.
+	Set(key string, value any) error
+	// SetTransient sets the entry without persisting it, e.g. for the values that cannot be encoded.
+	//
+	// Parameters:
+	//   - key: The key of the entry.
+	//   - value: The value.
+	//
+	// Returns:
+	//   - An error wrapping ErrScratchKeyEmpty.
+	SetTransient(key string, value any) error
+	// Delete deletes the entry, from the ScratchStore of the runtime too.
+	//
+	// Parameters:
+	//   - key: The key of the entry.
+	//
+	// Returns:
+	//   - An error if the entry cannot be deleted from the store.
+	Delete(key string) error
+	// Keys returns the keys of the entries, sorted.
+	Keys() []string
+}
+
+// Scratched provides the scratchpads of the threads.
+type Scratched interface {
+	// Scratch returns the scratchpad of the thread, restored from the ScratchStore of the runtime on first access.
+	//
+	// Parameters:
+	//   - threadID: The thread owning the scratchpad.
+	//
+	// Returns:
+	//   - The scratchpad.
+	//
+	// Example:
+	//
+	//	docs, ok, err := graph.ScratchValue[[]Document](runtime.Scratch(threadID), "docs")
+	Scratch(threadID string) Scratchpad
+}
+
+// ScratchValue returns the typed value of the scratchpad entry, decoding the values restored from the ScratchStore.
+//
+// Parameters:
+//   - pad: The scratchpad.
+//   - key: The key of the entry.
+//
+// Returns:
+//   - The value.
+//   - false if the scratchpad has no such entry.
+//   - An error if the value has another type.
+//
+// Example:
+//
+//	docs, ok, err := graph.ScratchValue[[]Document](pad, "docs")
+func ScratchValue[V any](pad Scratchpad, key string) (V, bool, error) {
+	var rv V
+	value, ok := pad.Get(key)
+	if !ok {
+		return rv, false, nil
+	}
+	if typed, ok := value.(V); ok {
+		return typed, true, nil
+	}
+	if raw, ok := value.(json.RawMessage); ok {
+		if err := json.Unmarshal(raw, &rv); err != nil {
+			return rv, true, fmt.Errorf("cannot decode scratchpad entry %s: %w", key, err)
+		}
+		return rv, true, nil
+	}
+	return rv, true, fmt.Errorf("scratchpad entry %s is a %T, not a %T", key, value, rv)
+}
+
+type scratchpadContextKey struct{}
+
+// ContextWithScratchpad returns a copy of the context carrying the scratchpad.
+//
+// The runtimes set the scratchpad of the thread on the context of the invocations, it is only needed
+// to execute the nodes outside of a runtime.
+//
+// Parameters:
+//   - ctx: The parent context.
+//   - pad: The scratchpad.
+//
+// Returns:
+//   - The derived context.
+func ContextWithScratchpad(ctx context.Context, pad Scratchpad) context.Context {
+	return context.WithValue(ctx, scratchpadContextKey{}, pad)
+}
+
+// ScratchFrom returns the scratchpad of the thread of the context.
+//
+// Parameters:
+//   - ctx: The context of the node.
+//
+// Returns:
+//   - The scratchpad.
+//   - An error wrapping ErrScratchpadNotSet if the context does not belong to an invocation.
+//
+// Example:
+//
+//	func fetchNode(ctx context.Context, userInput, state MyState, notify graph.NotifyPartialFn[MyState]) (MyState, error) {
+//	    pad, err := graph.ScratchFrom(ctx)
+//	    if err != nil {
+//	        return state, err
+//	    }
+//	    docs, err := fetch(ctx, state.Query)
+//	    if err != nil {
+//	        return state, err
+//	    }
+//	    return state, pad.Set("docs", docs)
+//	}
+func ScratchFrom(ctx context.Context) (Scratchpad, error) {
+	pad, ok := ctx.Value(scratchpadContextKey{}).(Scratchpad)
+	if !ok || pad == nil {
+		return nil, ErrScratchpadNotSet
+	}
+	return pad, nil
+}
@@ -0,0 +1,89 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrRuntimeShuttingDown indicates that an invocation was rejected or interrupted because the runtime is stopping.
+var ErrRuntimeShuttingDown = errors.New("runtime is shutting down")
+
+// ShutdownMode selects how Stop treats the in-flight invocations.
+type ShutdownMode int
+
+const (
+	// ShutdownDrain rejects the new invocations and lets the running ones complete, the ones still
+	// running when the context of Stop is done are interrupted.
+	ShutdownDrain ShutdownMode = iota
+	// ShutdownAbort interrupts the in-flight invocations immediately.
+	ShutdownAbort
+)
+
+// String returns the name of the mode.
+func (m ShutdownMode) String() string {
+	switch m {
+	case ShutdownDrain:
+		return "drain"
+	case ShutdownAbort:
+		return "abort"
+	default:
+		return "unknown"
+	}
+}
+
+// InterruptedInvocation is an invocation terminated by Stop before completing.
+type InterruptedInvocation struct {
+	// ThreadID is the thread of the invocation.
+	ThreadID string
+	// Nodes are the nodes executing when the invocation was interrupted, empty if none was.
+	Nodes []string
+	// Queued is true if the invocation was waiting for admission.
+	Queued bool
+	// Paused is true if the invocation was paused by the debugger.
+	Paused bool
+	// Running is the time the invocation had been running.
+	Running time.Duration
+}
+
+// ShutdownReport describes what Stop drained and interrupted.
+type ShutdownReport struct {
+	// Mode is the mode of the shutdown.
+	Mode ShutdownMode
+	// Drained are the threads whose invocation terminated while draining, successfully or not.
+	Drained []string
+	// Interrupted are the invocations terminated with ErrRuntimeShuttingDown.
+	Interrupted []InterruptedInvocation
+	// Rejected is the number of invocations rejected while stopping.
+	Rejected int
+	// DiscardedOutcomes is the number of node outcomes left unprocessed.
+	DiscardedOutcomes int
+	// Duration is the time taken by the shutdown.
+	Duration time.Duration
+}
+
+// Stoppable provides the shutdown of a runtime accounting for its in-flight invocations.
+type Stoppable interface {
+	// Stop stops the runtime according to the mode, then shuts it down like Shutdown.
+	//
+	// The new invocations are rejected with ErrRuntimeShuttingDown as soon as Stop is called;
+	// every interrupted invocation terminates with a monitor entry wrapping ErrRuntimeShuttingDown,
+	// so that no thread is left without its terminal entry.
+	//
+	// Parameters:
+	//   - ctx: Bounds the draining, ignored by ShutdownAbort.
+	//   - mode: ShutdownDrain or ShutdownAbort.
+	//
+	// Returns:
+	//   - The report of the drained and interrupted invocations.
+	//
+	// Example:
+	//
+	//	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	//	defer cancel()
+	//	report := runtime.Stop(ctx, graph.ShutdownDrain)
+	//	for _, interrupted := range report.Interrupted {
+	//	    log.Printf("thread %s interrupted at %v", interrupted.ThreadID, interrupted.Nodes)
+	//	}
+	Stop(ctx context.Context, mode ShutdownMode) ShutdownReport
+}
@@ -0,0 +1,58 @@
+package graph
+
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrSignalNameEmpty indicates that the name of a signal is empty.
+	ErrSignalNameEmpty = errors.New("signal name cannot be empty")
+	// ErrThreadNotWaiting indicates that the thread is not waiting for a signal.
+	ErrThreadNotWaiting = errors.New("thread is not waiting for a signal")
+	// ErrUnexpectedSignal indicates that the thread waits for another signal.
+	ErrUnexpectedSignal = errors.New("unexpected signal")
+	// ErrSignalTimeout indicates that the signal a thread waits for did not arrive in time.
+	ErrSignalTimeout = errors.New("signal timeout")
+)
+
+// SignalAwaiter is implemented by the nodes parking the thread until a signal arrives, see builders.NewSignalNode.
+type SignalAwaiter[T SharedState] interface {
+	// AwaitedSignal returns the name of the signal the node waits for and how long, zero meaning forever.
+	AwaitedSignal() (string, time.Duration)
+}
+
+// Signaled delivers external events to the threads parked by the signal nodes.
+//
+// A parked thread holds no goroutine; it is notified by a running entry with Signal set. The
+// payload of the signal is the update of the node, reduced into the state with the reducer of
+// the node, then the invocation goes on. A signal not arriving before the timeout of the node
+// fails the invocation with an error wrapping ErrSignalTimeout.
+type Signaled[T SharedState] interface {
+	// Signal delivers the signal to the thread parked by a signal node.
+	//
+	// Parameters:
+	//   - threadID: The thread waiting for the signal.
+	//   - signal: The name of the signal.
+	//   - payload: The update of the signal node, reduced into the state.
+	//
+	// Returns:
+	//   - An error wrapping ErrThreadNotWaiting if the thread is not parked, ErrUnexpectedSignal if
+	//     it waits for another signal.
+	//
+	// Example:
+	//
+	//	// In the webhook handler of the payment provider.
+	//	err := runtime.Signal(threadID, "payment", MyState{Paid: true, Receipt: receipt})
+	Signal(threadID, signal string, payload T) error
+
+	// AwaitedSignal returns the signal the thread waits for.
+	//
+	// Parameters:
+	//   - threadID: The thread to check.
+	//
+	// Returns:
+	//   - The name of the signal.
+	//   - true if the thread is parked by a signal node.
+	AwaitedSignal(threadID string) (string, bool)
+}
@@ -0,0 +1,72 @@
+package graph
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// ErrInvalidSnapshot indicates that a snapshot cannot be restored, e.g. a thread appears twice.
+var ErrInvalidSnapshot = errors.New("invalid runtime snapshot")
+
+// RuntimeSnapshot is the state of the threads of a runtime, to warm restart another runtime of the same graph.
+//
+// The snapshot is ready to be encoded as JSON, as long as the state type is.
+type RuntimeSnapshot[T SharedState] struct {
+	// TakenAt is the time the snapshot was taken.
+	TakenAt time.Time `json:"taken_at"`
+	// Threads are the threads of the runtime, sorted by identifier.
+	Threads []ThreadSnapshot[T] `json:"threads"`
+}
+
+// ThreadSnapshot is the state of a thread within a RuntimeSnapshot.
+type ThreadSnapshot[T SharedState] struct {
+	// ID is the identifier of the thread.
+	ID string `json:"id"`
+	// Tenant is the tenant the thread belongs to, empty for the threads without a tenant.
+	Tenant string `json:"tenant,omitempty"`
+	// Aliases are the human-readable names of the thread, sorted.
+	Aliases []string `json:"aliases,omitempty"`
+	// State is the committed state of the thread.
+	State T `json:"state"`
+	// Checkpoint is the state last persisted in the memory of the runtime, nil without a memory.
+	Checkpoint *T `json:"checkpoint,omitempty"`
+	// CheckpointedAt is the time of the last persistence, zero if the thread was never persisted.
+	CheckpointedAt time.Time `json:"checkpointed_at,omitzero"`
+	// CreatedAt is the time of the first invocation of the thread.
+	CreatedAt time.Time `json:"created_at"`
+	// LastActiveAt is the time of the last invocation or node execution of the thread.
+	LastActiveAt time.Time `json:"last_active_at"`
+	// ExpiresAt is the time after which the thread is evicted if it stays inactive.
+	ExpiresAt time.Time `json:"expires_at"`
+	// Steps is the number of node executions completed by the thread.
+	Steps int64 `json:"steps"`
+	// Scratch are the JSON encoded entries of the scratchpad held in memory for the thread, the
+	// transient ones excluded; see Scratchpad.
+	Scratch map[string]json.RawMessage `json:"scratch,omitempty"`
+}
+
+// Snapshotted provides the snapshot of the threads of a runtime, see CreateRuntimeFromSnapshot in the
+// builders package to restore it.
+type Snapshotted[T SharedState] interface {
+	// Snapshot captures the states, TTLs, checkpoints and scratchpads of all the threads of the runtime.
+	//
+	// The invocations are not captured: the snapshot is refused while one is in flight, drain them
+	// first with Stop, whose runtime can still be snapshotted.
+	//
+	// Returns:
+	//   - The snapshot of the threads.
+	//   - An error wrapping ErrRuntimeExecuting if an invocation is in flight, or the error encoding
+	//     a scratchpad entry.
+	//
+	// Example:
+	//
+	//	runtime.Stop(ctx, graph.ShutdownDrain)
+	//	snapshot, err := runtime.Snapshot()
+	//	if err != nil {
+	//	    log.Fatal(err)
+	//	}
+	//	data, _ := json.Marshal(snapshot)
+	//	os.WriteFile("snapshot.json", data, 0o600)
+	Snapshot() (RuntimeSnapshot[T], error)
+}
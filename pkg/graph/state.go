@@ -1,5 +1,7 @@
 package graph
 
+import "time"
+
 // SharedState is the base interface for all state types used in graph processing.
 //
 // Any struct can implement SharedState by simply embedding it or using it as a type
@@ -31,6 +33,9 @@ type SharedState interface {
 //   - Running: true while the graph is still executing, false when execution completes.
 //   - Partial: true if this is a partial state update (from NotifyPartialFn), false
 //     if this is the final state after node completion.
+//   - Usage: The tokens reported by the nodes of the invocation so far, see ReportUsage.
+//   - Breakpoint: The pending input and edge of a thread paused before the node, see WithDebugger.
+//   - Changes: The fields changed by the node once its update is reduced, see WithStateDiff.
 //
 // Example usage:
 //
@@ -53,15 +58,38 @@ type StateMonitorEntry[T SharedState] struct {
 	Node string
 	// ThreadID is the identifier of the thread executing this node.
 	ThreadID string
+	// Tenant is the tenant the thread belongs to, empty for the threads without a tenant.
+	Tenant string
 	// NewState is the state after the node's execution function completed.
 	NewState T
 	// Error is any error that occurred during node execution. nil if successful.
 	Error error
+	// ErrorCode is the machine-readable kind of Error, empty when Error is nil or carries no
+	// typed error; see ErrorCodeOf.
+	ErrorCode ErrorCode
 	// Running is true while the graph is still executing, false when execution completes.
 	Running bool
 	// Partial is true if this is a partial state update (from NotifyPartialFn), false
 	// if this is the final state after node completion.
 	Partial bool
+	// Usage is the cumulative usage reported by the nodes of the invocation so far.
+	Usage Usage
+	// Breakpoint is set when the thread is paused before the node, nil otherwise; see WithDebugger.
+	Breakpoint *Breakpoint[T]
+	// Compensation is true for the entries of the compensations run after a failure, see WithCompensation.
+	Compensation bool
+	// WakeAt is the time the thread suspended by the delay node wakes up, see Delaying.
+	WakeAt time.Time
+	// Signal is the signal the thread parked by the signal node waits for, see Signaled.
+	Signal string
+	// Batched is the number of inputs collected so far by the batch node the thread holds, see Batching.
+	Batched int
+	// Outcome is the outcome of the end node the invocation completed through, empty if its end edge has none,
+	// see builders.CreateOutcomeEdge.
+	Outcome string
+	// Changes are the fields changed by the node once its update is reduced into the state,
+	// set on the complete node entries when the runtime has WithStateDiff, nil otherwise.
+	Changes []StateChange
 	// ReducerFn is the function used to combine state updates.
 	ReducerFn ReducerFn[T]
 }
@@ -0,0 +1,136 @@
+package graph
+
+import (
+	"cmp"
+	"errors"
+	"slices"
+	"time"
+)
+
+// DefaultNodeStatsWindow is the default number of most recent executions of a node the latency percentiles are computed on.
+const DefaultNodeStatsWindow = 1024
+
+var (
+	// ErrInvalidNodeStatsWindow indicates that the window of the node latency percentiles is not positive.
+	ErrInvalidNodeStatsWindow = errors.New("node stats window must be positive")
+	// ErrInvalidLatencySLO indicates that a latency objective is not positive.
+	ErrInvalidLatencySLO = errors.New("latency SLO must be positive")
+)
+
+// NodeStats describes the executions of a node, by name, since the runtime creation.
+type NodeStats struct {
+	// Node is the name of the node.
+	Node string
+	// Executions is the number of executions which returned, failed ones included.
+	Executions uint64
+	// Failures is the number of executions which returned an error.
+	Failures uint64
+	// TotalLatency is the duration of the executions, from the start of the node function to its return;
+	// the waits for a signal or for the inputs of a batch are not accounted.
+	TotalLatency time.Duration
+	// MaxLatency is the longest execution.
+	MaxLatency time.Duration
+	// P50Latency is the median duration of the most recent executions, see WithNodeStatsWindow.
+	P50Latency time.Duration
+	// P95Latency is the 95th percentile of the duration of the most recent executions.
+	P95Latency time.Duration
+	// P99Latency is the 99th percentile of the duration of the most recent executions.
+	P99Latency time.Duration
+	// LatencySLO is the latency objective of the node, zero when none, see WithLatencySLO.
+	LatencySLO time.Duration
+	// SLOBreaches is the number of executions exceeding the latency objective.
+	SLOBreaches uint64
+	// PartialUpdates is the number of partial updates streamed by the node, see NotifyPartialFn.
+	PartialUpdates uint64
+	// Usage is the usage reported by the node, see ReportUsage.
+	Usage Usage
+}
+
+// AverageLatency returns the average duration of the executions.
+func (s NodeStats) AverageLatency() time.Duration {
+	if s.Executions == 0 {
+		return 0
+	}
+	return s.TotalLatency / time.Duration(s.Executions)
+}
+
+// FailureRate returns the share of the executions which failed, from 0 to 1.
+func (s NodeStats) FailureRate() float64 {
+	if s.Executions == 0 {
+		return 0
+	}
+	return float64(s.Failures) / float64(s.Executions)
+}
+
+// SLOCompliance returns the share of the executions within the latency objective, from 0 to 1;
+// it is 1 when the node has no objective or no execution.
+func (s NodeStats) SLOCompliance() float64 {
+	if s.LatencySLO == 0 || s.Executions == 0 {
+		return 1
+	}
+	return 1 - float64(s.SLOBreaches)/float64(s.Executions)
+}
+
+// TokensPerSecond returns the streaming throughput of the node: the completion tokens it reported
+// per second of execution.
+func (s NodeStats) TokensPerSecond() float64 {
+	if s.TotalLatency <= 0 {
+		return 0
+	}
+	return float64(s.Usage.CompletionTokens) / s.TotalLatency.Seconds()
+}
+
+// RuntimeStats describes the node executions of a runtime.
+type RuntimeStats struct {
+	// Since is the time the runtime started collecting the metrics, its creation.
+	Since time.Time
+	// Nodes are the metrics of the executed nodes, sorted by name.
+	Nodes []NodeStats
+}
+
+// Node returns the metrics of a node.
+//
+// Parameters:
+//   - name: The name of the node.
+//
+// Returns:
+//   - The metrics of the node.
+//   - false if the node never executed.
+func (s RuntimeStats) Node(name string) (NodeStats, bool) {
+	idx := slices.IndexFunc(s.Nodes, func(n NodeStats) bool { return n.Node == name })
+	if idx == -1 {
+		return NodeStats{}, false
+	}
+	return s.Nodes[idx], true
+}
+
+// Bottleneck returns the node with the highest 95th percentile latency, the step to optimize first.
+//
+// Returns:
+//   - The metrics of the slowest node.
+//   - false if no node executed.
+func (s RuntimeStats) Bottleneck() (NodeStats, bool) {
+	if len(s.Nodes) == 0 {
+		return NodeStats{}, false
+	}
+	return slices.MaxFunc(s.Nodes, func(x, y NodeStats) int { return cmp.Compare(x.P95Latency, y.P95Latency) }), true
+}
+
+// Profiled provides the latency and the throughput of the nodes of a runtime.
+type Profiled interface {
+	// Stats returns a snapshot of the metrics of the nodes.
+	//
+	// The executions are accounted by node name across the threads and the graph versions; the
+	// percentiles cover the most recent executions of each node, see WithNodeStatsWindow.
+	//
+	// Returns:
+	//   - The node metrics.
+	//
+	// Example:
+	//
+	//	stats := runtime.Stats()
+	//	if node, ok := stats.Bottleneck(); ok {
+	//	    log.Printf("%s: p50 %s, p95 %s, p99 %s, %.1f tokens/s", node.Node, node.P50Latency, node.P95Latency, node.P99Latency, node.TokensPerSecond())
+	//	}
+	Stats() RuntimeStats
+}
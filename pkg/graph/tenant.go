@@ -0,0 +1,158 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrTenantQuotaExceeded indicates that an invocation was rejected or aborted by the quota of its tenant.
+	ErrTenantQuotaExceeded = errors.New("tenant quota exceeded")
+	// ErrInvalidTenantQuota indicates that a tenant quota has a negative limit.
+	ErrInvalidTenantQuota = errors.New("tenant quota limits cannot be negative")
+	// ErrInvalidTenant indicates that a tenant identifier is empty or contains the TenantSeparator.
+	ErrInvalidTenant = errors.New("tenant cannot be empty or contain the tenant separator")
+)
+
+// TenantSeparator separates the tenant from the thread identifier in the namespaced thread IDs.
+const TenantSeparator = "/"
+
+// TenantQuota bounds the resources a tenant can use in a runtime, zero limits mean unlimited.
+type TenantQuota struct {
+	// MaxActiveThreads is the number of threads the tenant can hold in the runtime at once.
+	MaxActiveThreads int
+	// MaxConcurrentInvocations is the number of invocations of the tenant running at once.
+	MaxConcurrentInvocations int
+	// MaxSteps is the number of node executions within an invocation of the tenant.
+	MaxSteps int
+}
+
+func (q TenantQuota) valid() bool {
+	return q.MaxActiveThreads >= 0 && q.MaxConcurrentInvocations >= 0 && q.MaxSteps >= 0
+}
+
+// TenantStats describes the usage of a runtime by a tenant.
+type TenantStats struct {
+	// ActiveThreads is the number of threads of the tenant held by the runtime.
+	ActiveThreads int
+	// RunningInvocations is the number of invocations of the tenant currently running.
+	RunningInvocations int
+	// Rejected is the number of invocations of the tenant rejected or aborted by its quota.
+	Rejected uint64
+	// Quota is the quota applied to the tenant.
+	Quota TenantQuota
+}
+
+// Tenanted provides the usage of a runtime shared by several tenants.
+//
+// The invocations configured with InvokeConfigTenant belong to a tenant: their thread IDs are
+// namespaced with TenantThreadID, and so are the keys of their states in the memory, the monitor
+// entries and the logs carry the tenant, and the quotas set with WithTenantQuotas apply.
+type Tenanted interface {
+	// TenantStats returns a snapshot of the usage of the runtime by a tenant.
+	//
+	// Parameters:
+	//   - tenant: The tenant identifier.
+	//
+	// Returns:
+	//   - The usage of the tenant.
+	//
+	// Example:
+	//
+	//	stats := runtime.TenantStats("acme")
+	//	log.Printf("acme: %d threads, %d running", stats.ActiveThreads, stats.RunningInvocations)
+	TenantStats(tenant string) TenantStats
+}
+
+// ValidateTenant checks a tenant identifier.
+//
+// The tenant is the first segment of the namespaced thread IDs: an identifier containing the
+// TenantSeparator would namespace the threads of another tenant, e.g. the tenant "acme/evil" and
+// the thread "x" would share the thread "acme/evil/x" of the tenant "acme".
+//
+// Parameters:
+//   - tenant: The tenant identifier.
+//
+// Returns:
+//   - ErrInvalidTenant if the tenant is empty or contains the TenantSeparator.
+func ValidateTenant(tenant string) error {
+	if tenant == "" || strings.Contains(tenant, TenantSeparator) {
+		return fmt.Errorf("%w: %q", ErrInvalidTenant, tenant)
+	}
+	return nil
+}
+
+// TenantThreadID namespaces a thread identifier with its tenant.
+//
+// Identifiers which are already namespaced with the tenant are returned as they are, so that the
+// thread IDs returned by Invoke can be given back to the invocations of the same tenant.
+//
+// Parameters:
+//   - tenant: The tenant identifier, the thread identifier is not namespaced when empty.
+//   - threadID: The thread identifier.
+//
+// Returns:
+//   - The namespaced thread identifier.
+//
+// Example:
+//
+//	threadID := TenantThreadID("acme", "support-42") // "acme/support-42"
+func TenantThreadID(tenant, threadID string) string {
+	if tenant == "" || strings.HasPrefix(threadID, tenant+TenantSeparator) {
+		return threadID
+	}
+	return tenant + TenantSeparator + threadID
+}
+
+// InvokeConfigTenant creates an InvokeConfig for an invocation of a tenant.
+//
+// The invocations of a tenant which does not pass ValidateTenant are rejected with ErrInvalidTenant.
+//
+// Parameters:
+//   - tenant: The tenant the invocation belongs to.
+//
+// Returns:
+//   - An InvokeConfig instance with the specified Tenant.
+//
+// Example:
+//
+//	threadID := runtime.Invoke(userInput, InvokeConfigTenant("acme"), InvokeConfigThreadID("support-42"))
+//	// threadID is "acme/support-42"
+func InvokeConfigTenant(tenant string) InvokeConfig {
+	return InvokeConfig{Tenant: tenant}
+}
+
+type tenantContextKey struct{}
+
+// ContextWithTenant returns a copy of the context carrying the tenant of the invocation.
+//
+// The runtime sets it on the context of the invocations of a tenant.
+//
+// Parameters:
+//   - ctx: The parent context.
+//   - tenant: The tenant identifier.
+//
+// Returns:
+//   - The context carrying the tenant.
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant carried by the context.
+//
+// Parameters:
+//   - ctx: The context, usually the one given to a ContextNodeFn.
+//
+// Returns:
+//   - The tenant identifier, and whether the context carries one.
+//
+// Example:
+//
+//	tenant, _ := graph.TenantFromContext(ctx)
+//	client := clients[tenant]
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantContextKey{}).(string)
+	return tenant, ok
+}
@@ -0,0 +1,65 @@
+package graph_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func TestTenantThreadID(t *testing.T) {
+	for _, tc := range []struct{ tenant, threadID, expected string }{
+		{"acme", "support-42", "acme/support-42"},
+		{"acme", "acme/support-42", "acme/support-42"},
+		{"", "support-42", "support-42"},
+		{"acme", "acmesupport", "acme/acmesupport"},
+	} {
+		if got := g.TenantThreadID(tc.tenant, tc.threadID); got != tc.expected {
+			t.Errorf("Expected %q for %+v, got %q", tc.expected, tc, got)
+		}
+	}
+
+	for _, tenant := range []string{"", "acme/evil", "/"} {
+		if err := g.ValidateTenant(tenant); !errors.Is(err, g.ErrInvalidTenant) {
+			t.Errorf("Expected ErrInvalidTenant for %q, got %v", tenant, err)
+		}
+	}
+	if err := g.ValidateTenant("acme"); err != nil {
+		t.Errorf("Expected the tenant to be valid, got %v", err)
+	}
+
+	if tenant, ok := g.TenantFromContext(g.ContextWithTenant(context.Background(), "acme")); !ok || tenant != "acme" {
+		t.Errorf("Expected the tenant from the context, got %q", tenant)
+	}
+	if merged := g.MergeInvokeConfig(g.InvokeConfigTenant("acme"), g.InvokeConfigThreadID("t")); merged.Tenant != "acme" {
+		t.Errorf("Expected the tenant to be merged, got %+v", merged)
+	}
+}
+
+func TestWithTenantQuotas(t *testing.T) {
+	opts := &g.RuntimeOptions[diffState]{}
+	for _, opt := range []g.RuntimeOption[diffState]{
+		g.WithTenantQuotas[diffState](g.TenantQuota{MaxSteps: -1}, nil),
+		g.WithTenantQuotas[diffState](g.TenantQuota{}, map[string]g.TenantQuota{"acme": {MaxActiveThreads: -1}}),
+	} {
+		if err := opt.Apply(opts); !errors.Is(err, g.ErrInvalidTenantQuota) {
+			t.Errorf("Expected ErrInvalidTenantQuota, got %v", err)
+		}
+	}
+
+	for _, tenant := range []string{"", "acme/evil"} {
+		if err := g.WithTenantQuotas[diffState](g.TenantQuota{}, map[string]g.TenantQuota{tenant: {}}).Apply(opts); !errors.Is(err, g.ErrInvalidTenant) {
+			t.Errorf("Expected ErrInvalidTenant for %q, got %v", tenant, err)
+		}
+	}
+
+	quotas := map[string]g.TenantQuota{"acme": {MaxActiveThreads: 10}}
+	if err := g.WithTenantQuotas[diffState](g.TenantQuota{MaxConcurrentInvocations: 2}, quotas).Apply(opts); err != nil {
+		t.Fatalf("WithTenantQuotas failed: %v", err)
+	}
+	quotas["acme"] = g.TenantQuota{}
+	if opts.DefaultTenantQuota.MaxConcurrentInvocations != 2 || opts.TenantQuotas["acme"].MaxActiveThreads != 10 {
+		t.Errorf("Expected a copy of the quotas, got %+v", opts)
+	}
+}
@@ -0,0 +1,90 @@
+package graph
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidTimelineRetention indicates that the retention of the timelines is not positive.
+var ErrInvalidTimelineRetention = errors.New("timeline retention must be positive")
+
+const (
+	// DefaultTimelineThreads is the default number of threads whose timeline is retained.
+	DefaultTimelineThreads = 1000
+	// DefaultTimelineInvocations is the default number of invocations retained in the timeline of a thread.
+	DefaultTimelineInvocations = 10
+)
+
+// Timeline is the execution history of a thread, ready to be encoded as JSON and rendered as a Gantt chart.
+type Timeline struct {
+	// ThreadID is the thread the timeline belongs to.
+	ThreadID string `json:"thread_id"`
+	// Invocations are the retained invocations of the thread, oldest first.
+	Invocations []TimelineInvocation `json:"invocations"`
+}
+
+// TimelineInvocation is the execution history of an invocation.
+type TimelineInvocation struct {
+	// GraphVersion is the version of the graph the invocation ran on.
+	GraphVersion uint64 `json:"graph_version"`
+	// StartedAt is the time the invocation was started.
+	StartedAt time.Time `json:"started_at"`
+	// EndedAt is the time the invocation terminated, zero while it is running.
+	EndedAt time.Time `json:"ended_at,omitzero"`
+	// Duration is the duration of the terminated invocation.
+	Duration time.Duration `json:"duration_ns,omitempty"`
+	// Error is the message of the error terminating the invocation, empty when none.
+	Error string `json:"error,omitempty"`
+	// Spans are the node executions, in order of start.
+	Spans []TimelineSpan `json:"spans"`
+	// Routes are the routing decisions, in order.
+	Routes []TimelineRoute `json:"routes"`
+}
+
+// TimelineSpan is the execution of a node.
+type TimelineSpan struct {
+	// Node is the name of the node.
+	Node string `json:"node"`
+	// StartedAt is the time the node was dispatched.
+	StartedAt time.Time `json:"started_at"`
+	// EndedAt is the time the node returned, zero while it is running.
+	EndedAt time.Time `json:"ended_at,omitzero"`
+	// Duration is the duration of the node execution.
+	Duration time.Duration `json:"duration_ns,omitempty"`
+	// Error is the message of the error returned by the node, empty when none.
+	Error string `json:"error,omitempty"`
+}
+
+// TimelineRoute is a routing decision taken after a node.
+type TimelineRoute struct {
+	// At is the time of the decision.
+	At time.Time `json:"at"`
+	// From is the name of the node the routing policy belongs to.
+	From string `json:"from"`
+	// To is the name of the selected node.
+	To string `json:"to"`
+	// Fallback is true when the policy selected no edge and the fallback edge was followed.
+	Fallback bool `json:"fallback,omitempty"`
+}
+
+// Timelined provides the execution timelines of the threads, without any tracing infrastructure.
+type Timelined interface {
+	// Timeline returns the execution history of a thread.
+	//
+	// The runtime retains the last invocations of the most recently invoked threads, see
+	// WithTimelineRetention, the timeline outlives the thread state until it is discarded.
+	//
+	// Parameters:
+	//   - threadID: The thread identifier.
+	//
+	// Returns:
+	//   - The timeline of the thread.
+	//   - ErrUnknownThreadID if no timeline is retained for the thread.
+	//
+	// Example:
+	//
+	//	timeline, _ := runtime.Timeline(threadID)
+	//	data, _ := json.Marshal(timeline)
+	//	w.Write(data)
+	Timeline(threadID string) (Timeline, error)
+}
@@ -0,0 +1,20 @@
+package graph_test
+
+import (
+	"errors"
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func TestWithTimelineRetention(t *testing.T) {
+	opts := &g.RuntimeOptions[diffState]{}
+	if err := g.WithTimelineRetention[diffState](100, 2).Apply(opts); err != nil || opts.TimelineThreads != 100 || opts.TimelineInvocations != 2 {
+		t.Errorf("Expected the retention to be set, got %+v: %v", opts, err)
+	}
+	for _, retention := range [][2]int{{0, 1}, {1, 0}, {-1, 1}} {
+		if err := g.WithTimelineRetention[diffState](retention[0], retention[1]).Apply(opts); !errors.Is(err, g.ErrInvalidTimelineRetention) {
+			t.Errorf("Expected ErrInvalidTimelineRetention for %v, got %v", retention, err)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package graph
+
+// Topology provides read-only access to the structure of a graph.
+//
+// The returned slices are copies: modifying them does not affect the graph. It is embedded
+// in the Runtime interface so that monitoring UIs, exporters and tests can inspect the
+// constructed graph without keeping their own copies of the edges.
+type Topology[T SharedState] interface {
+	// Nodes returns the nodes of the graph, including the implicit start and end nodes.
+	//
+	// The nodes are listed once, in order of appearance in the edges, starting with
+	// the StartEdge.
+	//
+	// Returns:
+	//   - The nodes of the graph.
+	//
+	// Example:
+	//
+	//	for _, node := range runtime.Nodes() {
+	//	    fmt.Printf("%s (role %d)\n", node.Name(), node.Role())
+	//	}
+	Nodes() []Node[T]
+
+	// Edges returns the edges of the graph, the StartEdge first, then in order of addition.
+	//
+	// Returns:
+	//   - The edges of the graph.
+	//
+	// Example:
+	//
+	//	for _, edge := range runtime.Edges() {
+	//	    fmt.Printf("%s -> %s\n", edge.From().Name(), edge.To().Name())
+	//	}
+	Edges() []Edge[T]
+
+	// Neighbors returns the nodes reachable from the given node through one outbound edge.
+	//
+	// Parameters:
+	//   - node: The node whose neighbors are requested.
+	//
+	// Returns:
+	//   - The distinct targets of the outbound edges of the node, in order of addition; empty
+	//     when the node has no outbound edge or is not part of the graph.
+	//
+	// Example:
+	//
+	//	next := runtime.Neighbors(routerNode)
+	Neighbors(node Node[T]) []Node[T]
+
+	// GraphVersion returns the latest version of the graph, incremented by every AddEdge and RemoveEdge.
+	//
+	// The nodes, edges and neighbors are always those of the latest version, while the running
+	// invocations route on the version they started with, see ThreadInfo.GraphVersion; the new
+	// invocations start with the latest version unless a rollout is active, see RolledOut.
+	//
+	// Returns:
+	//   - The latest version of the graph.
+	GraphVersion() uint64
+}
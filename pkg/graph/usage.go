@@ -0,0 +1,76 @@
+package graph
+
+import "context"
+
+// Usage accounts for the tokens consumed by the model calls of an invocation.
+type Usage struct {
+	// PromptTokens is the number of tokens sent to the models.
+	PromptTokens int64 `json:"prompt_tokens"`
+	// CompletionTokens is the number of tokens generated by the models.
+	CompletionTokens int64 `json:"completion_tokens"`
+	// TotalTokens is the sum of prompt and completion tokens.
+	TotalTokens int64 `json:"total_tokens"`
+}
+
+// Add returns the sum of the two usages.
+//
+// Parameters:
+//   - other: The usage to add.
+//
+// Returns:
+//   - The sum.
+func (u Usage) Add(other Usage) Usage {
+	return Usage{
+		PromptTokens:     u.PromptTokens + other.PromptTokens,
+		CompletionTokens: u.CompletionTokens + other.CompletionTokens,
+		TotalTokens:      u.TotalTokens + other.TotalTokens,
+	}
+}
+
+// IsZero reports whether no token has been accounted.
+//
+// Returns:
+//   - true if all counters are zero.
+func (u Usage) IsZero() bool {
+	return u == Usage{}
+}
+
+// UsageReporterFn collects the usage reported by the nodes of an invocation.
+type UsageReporterFn func(usage Usage)
+
+type usageReporterContextKey struct{}
+
+// ContextWithUsageReporter returns a copy of the context collecting the reported usage with the given function.
+//
+// The runtime sets it on the invocation context and accumulates the usage into the monitor entries.
+//
+// Parameters:
+//   - ctx: The parent context.
+//   - reporter: The collecting function.
+//
+// Returns:
+//   - The derived context.
+func ContextWithUsageReporter(ctx context.Context, reporter UsageReporterFn) context.Context {
+	return context.WithValue(ctx, usageReporterContextKey{}, reporter)
+}
+
+// ReportUsage reports the tokens consumed by a model call to the invocation owning the context.
+//
+// It is a no-op when the context does not belong to an invocation.
+//
+// Parameters:
+//   - ctx: The context, usually the one given to a ContextNodeFn.
+//   - usage: The consumed tokens.
+//
+// Example:
+//
+//	resp, err := chatService.Completions.New(ctx, params)
+//	graph.ReportUsage(ctx, graph.Usage{PromptTokens: resp.Usage.PromptTokens, ...})
+func ReportUsage(ctx context.Context, usage Usage) {
+	if ctx == nil {
+		return
+	}
+	if reporter, ok := ctx.Value(usageReporterContextKey{}).(UsageReporterFn); ok && reporter != nil {
+		reporter(usage)
+	}
+}
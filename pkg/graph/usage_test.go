@@ -0,0 +1,29 @@
+package graph
+
+import (
+	"context"
+	"testing"
+)
+
+func TestUsage_Add(t *testing.T) {
+	got := Usage{PromptTokens: 1, CompletionTokens: 2, TotalTokens: 3}.Add(Usage{PromptTokens: 10, CompletionTokens: 20, TotalTokens: 30})
+	if got != (Usage{PromptTokens: 11, CompletionTokens: 22, TotalTokens: 33}) {
+		t.Errorf("Unexpected sum %+v", got)
+	}
+	if got.IsZero() || !(Usage{}).IsZero() {
+		t.Error("Unexpected IsZero result")
+	}
+}
+
+func TestReportUsage(t *testing.T) {
+	// No reporter: no-op.
+	ReportUsage(context.Background(), Usage{TotalTokens: 1})
+
+	var reported Usage
+	ctx := ContextWithUsageReporter(context.Background(), func(usage Usage) { reported = reported.Add(usage) })
+	ReportUsage(ctx, Usage{TotalTokens: 1})
+	ReportUsage(ctx, Usage{TotalTokens: 2})
+	if reported.TotalTokens != 3 {
+		t.Errorf("Expected 3 total tokens, got %d", reported.TotalTokens)
+	}
+}
@@ -0,0 +1,178 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"maps"
+)
+
+// ValueKey identifies a typed value of the invocations, e.g. the user ID, the locale or an auth token.
+//
+// Values are kept apart from the state: they are not persisted, nor reduced, nor sent to the monitor.
+type ValueKey[V any] struct {
+	name   string
+	header string
+}
+
+// NewValueKey creates the key of a typed invocation value.
+//
+// Parameters:
+//   - name: The name of the value, unique among the values of the invocations.
+//
+// Returns:
+//   - The key, to set the value with InvokeConfigValue and to read it with ValueFrom.
+//
+// Example:
+//
+//	var UserID = graph.NewValueKey[string]("user_id")
+func NewValueKey[V any](name string) ValueKey[V] {
+	return ValueKey[V]{name: name}
+}
+
+// PropagatedAs returns a copy of the key whose values are sent as the HTTP header to the model providers.
+//
+// The value is formatted with fmt.Sprint; the clients of the provider packages, like openai.NewClient,
+// set the headers of the values found in the context of the request, see PropagatedHeaders.
+//
+// Parameters:
+//   - header: The name of the HTTP header.
+//
+// Returns:
+//   - The propagated key.
+//
+// Example:
+//
+//	var RequestID = graph.NewValueKey[string]("request_id").PropagatedAs("X-Request-ID")
+func (k ValueKey[V]) PropagatedAs(header string) ValueKey[V] {
+	k.header = header
+	return k
+}
+
+// Name returns the name of the value.
+func (k ValueKey[V]) Name() string {
+	return k.name
+}
+
+// InvocationValue is a value of an invocation, along with the HTTP header it is propagated as, if any.
+type InvocationValue struct {
+	// Value is the value.
+	Value any
+	// Header is the HTTP header the value is sent as to the model providers, empty if it is not propagated.
+	Header string
+}
+
+// InvokeConfigValue creates an InvokeConfig with the specified value.
+//
+// The values of several configurations are merged by MergeInvokeConfig, the nodes and the tools
+// read them from their context with ValueFrom.
+//
+// Parameters:
+//   - key: The key of the value.
+//   - value: The value.
+//
+// Returns:
+//   - An InvokeConfig instance with the specified value.
+//
+// Example:
+//
+//	runtime.Invoke(userInput,
+//	    graph.InvokeConfigValue(UserID, "u-42"),
+//	    graph.InvokeConfigValue(Locale, language.Italian))
+func InvokeConfigValue[V any](key ValueKey[V], value V) InvokeConfig {
+	return InvokeConfig{Values: map[string]InvocationValue{key.name: {Value: value, Header: key.header}}}
+}
+
+type valuesContextKey struct{}
+
+// ContextWithValues returns a copy of the context carrying the invocation values, on top of the ones it already carries.
+//
+// The runtime sets the values of the InvokeConfig on the invocation context, it is only needed to
+// execute the nodes outside of a runtime.
+//
+// Parameters:
+//   - ctx: The parent context.
+//   - values: The values, by name.
+//
+// Returns:
+//   - The derived context.
+func ContextWithValues(ctx context.Context, values map[string]InvocationValue) context.Context {
+	if len(values) == 0 {
+		return ctx
+	}
+	merged := make(map[string]InvocationValue, len(values))
+	if parent, ok := ctx.Value(valuesContextKey{}).(map[string]InvocationValue); ok {
+		maps.Copy(merged, parent)
+	}
+	maps.Copy(merged, values)
+	return context.WithValue(ctx, valuesContextKey{}, merged)
+}
+
+// ContextWithValue returns a copy of the context carrying the typed value.
+//
+// Parameters:
+//   - ctx: The parent context.
+//   - key: The key of the value.
+//   - value: The value.
+//
+// Returns:
+//   - The derived context.
+func ContextWithValue[V any](ctx context.Context, key ValueKey[V], value V) context.Context {
+	return ContextWithValues(ctx, map[string]InvocationValue{key.name: {Value: value, Header: key.header}})
+}
+
+// ValueFrom returns the typed value carried by the context.
+//
+// Parameters:
+//   - ctx: The context, usually the one given to a ContextNodeFn or to a tool.
+//   - key: The key of the value.
+//
+// Returns:
+//   - The value.
+//   - false if the context carries no value of the key, or a value of another type.
+//
+// Example:
+//
+//	fn := func(ctx context.Context, userInput, currentState MyState, notify graph.NotifyPartialFn[MyState]) (MyState, error) {
+//	    userID, ok := graph.ValueFrom(ctx, UserID)
+//	    ...
+//	}
+func ValueFrom[V any](ctx context.Context, key ValueKey[V]) (V, bool) {
+	var zero V
+	values, ok := ctx.Value(valuesContextKey{}).(map[string]InvocationValue)
+	if !ok {
+		return zero, false
+	}
+	value, ok := values[key.name].Value.(V)
+	if !ok {
+		return zero, false
+	}
+	return value, true
+}
+
+// PropagatedHeaders returns the HTTP headers of the propagated values carried by the context, see ValueKey.PropagatedAs.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//
+// Returns:
+//   - The header values, by header name; nil if the context carries no propagated value.
+//
+// Example:
+//
+//	for header, value := range graph.PropagatedHeaders(ctx) {
+//	    req.Header.Set(header, value)
+//	}
+func PropagatedHeaders(ctx context.Context) map[string]string {
+	values, _ := ctx.Value(valuesContextKey{}).(map[string]InvocationValue)
+	var headers map[string]string
+	for _, value := range values {
+		if value.Header == "" || value.Value == nil {
+			continue
+		}
+		if headers == nil {
+			headers = make(map[string]string)
+		}
+		headers[value.Header] = fmt.Sprint(value.Value)
+	}
+	return headers
+}
@@ -0,0 +1,51 @@
+package graph_test
+
+import (
+	"context"
+	"testing"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+func TestValueFrom(t *testing.T) {
+	userID := g.NewValueKey[string]("user_id")
+	attempts := g.NewValueKey[int]("attempts")
+	requestID := g.NewValueKey[string]("request_id").PropagatedAs("X-Request-ID")
+
+	config := g.MergeInvokeConfig(
+		g.InvokeConfigValue(userID, "u-1"),
+		g.InvokeConfigValue(attempts, 3),
+		g.InvokeConfigValue(userID, "u-42"),
+		g.InvokeConfigValue(requestID, "req-1"),
+	)
+	ctx := g.ContextWithValues(context.Background(), config.Values)
+
+	if value, ok := g.ValueFrom(ctx, userID); !ok || value != "u-42" {
+		t.Errorf("Expected the last user ID, got %q, %v", value, ok)
+	}
+	if value, ok := g.ValueFrom(ctx, attempts); !ok || value != 3 {
+		t.Errorf("Expected 3 attempts, got %d, %v", value, ok)
+	}
+	if _, ok := g.ValueFrom(ctx, g.NewValueKey[int]("user_id")); ok {
+		t.Error("Expected a value of another type not to be found")
+	}
+	if _, ok := g.ValueFrom(context.Background(), userID); ok {
+		t.Error("Expected no value in an empty context")
+	}
+
+	ctx = g.ContextWithValue(ctx, attempts, 4)
+	if value, _ := g.ValueFrom(ctx, attempts); value != 4 {
+		t.Errorf("Expected the overridden attempts, got %d", value)
+	}
+	if value, _ := g.ValueFrom(ctx, userID); value != "u-42" {
+		t.Errorf("Expected the parent values to be kept, got %q", value)
+	}
+
+	headers := g.PropagatedHeaders(ctx)
+	if len(headers) != 1 || headers["X-Request-ID"] != "req-1" {
+		t.Errorf("Expected only the request ID header, got %v", headers)
+	}
+	if headers := g.PropagatedHeaders(context.Background()); headers != nil {
+		t.Errorf("Expected no header, got %v", headers)
+	}
+}
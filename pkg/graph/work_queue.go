@@ -0,0 +1,105 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrWorkQueueNil indicates that the provided work queue is nil.
+	ErrWorkQueueNil = errors.New("work queue cannot be nil")
+	// ErrWorkQueueWithoutMemory indicates that a work queue is set on a runtime without a memory to share the states.
+	ErrWorkQueueWithoutMemory = errors.New("work queue requires a memory shared with the workers")
+	// ErrUnknownNode indicates that a worker received a task for a node it does not execute.
+	ErrUnknownNode = errors.New("unknown node")
+)
+
+// NodeTask is the execution of a node published to a WorkQueue.
+//
+// The state of the thread is not carried by the task: the runtime persists it in its memory
+// before publishing the task, the worker restores it from the same memory.
+type NodeTask struct {
+	// ID is the unique identifier of the task.
+	ID string `json:"id"`
+	// Instance is the identifier of the runtime expecting the result.
+	Instance string `json:"instance"`
+	// ThreadID is the thread the node is executed for.
+	ThreadID string `json:"thread_id"`
+	// Tenant is the tenant of the thread, empty for the threads without a tenant.
+	Tenant string `json:"tenant,omitempty"`
+	// Node is the name of the node to execute.
+	Node string `json:"node"`
+	// UserInput is the JSON encoded input of the invocation.
+	UserInput []byte `json:"user_input"`
+	// EnqueuedAt is the time the task was published.
+	EnqueuedAt time.Time `json:"enqueued_at"`
+}
+
+// NodeResult is the outcome of a NodeTask, published back to the runtime.
+type NodeResult struct {
+	// TaskID is the identifier of the executed task.
+	TaskID string `json:"task_id"`
+	// Instance is the identifier of the runtime expecting the result.
+	Instance string `json:"instance"`
+	// ThreadID is the thread the node was executed for.
+	ThreadID string `json:"thread_id"`
+	// Node is the name of the executed node.
+	Node string `json:"node"`
+	// State is the JSON encoded state of the thread once the node update is reduced, empty on failure.
+	State []byte `json:"state,omitempty"`
+	// Error is the message of the error of the node, empty on success.
+	Error string `json:"error,omitempty"`
+	// Code is the machine-readable kind of the error, see ErrorCodeOf.
+	Code ErrorCode `json:"code,omitempty"`
+	// Usage is the usage reported by the node.
+	Usage Usage `json:"usage"`
+}
+
+// WorkQueue carries the node executions from the runtimes to the worker processes, and their
+// results back, so that the execution of a graph scales beyond one process.
+//
+// The implementations are expected to deliver every task to one worker, and every result to the
+// runtime instance named by the task.
+type WorkQueue interface {
+	// Enqueue publishes a task for the workers.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation and timeout control.
+	//   - task: The task to publish.
+	//
+	// Returns:
+	//   - An error if the task could not be published.
+	Enqueue(ctx context.Context, task NodeTask) error
+
+	// Dequeue waits for the next task, called by the workers.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation, the wait ends when it is done.
+	//
+	// Returns:
+	//   - The next task.
+	//   - The error of the context once done, or an error if the queue could not be read.
+	Dequeue(ctx context.Context) (NodeTask, error)
+
+	// Reply publishes the result of a task for the runtime which enqueued it, called by the workers.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation and timeout control.
+	//   - result: The result of the task.
+	//
+	// Returns:
+	//   - An error if the result could not be published.
+	Reply(ctx context.Context, result NodeResult) error
+
+	// Results waits for the next result addressed to the runtime instance.
+	//
+	// Parameters:
+	//   - ctx: Context for cancellation, the wait ends when it is done.
+	//   - instance: The identifier of the runtime instance.
+	//
+	// Returns:
+	//   - The next result.
+	//   - The error of the context once done, or an error if the queue could not be read.
+	Results(ctx context.Context, instance string) (NodeResult, error)
+}
@@ -0,0 +1,81 @@
+// Package kafka connects graphs to Kafka topics.
+//
+// A Source consumes the messages of a consumer group and invokes the runtime for each of them,
+// committing the offset of a message once its invocation completes; a sink node publishes
+// messages derived from the state as part of a graph.
+//
+// The package does not depend on a Kafka client: Reader and Writer are the few methods used,
+// adapt the client of choice, such as segmentio/kafka-go or franz-go, to them.
+//
+// Example:
+//
+//	type reader struct{ r *kafkago.Reader }
+//
+//	func (a reader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+//	    m, err := a.r.FetchMessage(ctx)
+//	    return kafka.Message{Topic: m.Topic, Partition: m.Partition, Offset: m.Offset, Key: m.Key, Value: m.Value, Time: m.Time}, err
+//	}
+//
+//	func (a reader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+//	    committed := make([]kafkago.Message, len(msgs))
+//	    for i, m := range msgs {
+//	        committed[i] = kafkago.Message{Topic: m.Topic, Partition: m.Partition, Offset: m.Offset}
+//	    }
+//	    return a.r.CommitMessages(ctx, committed...)
+//	}
+package kafka
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrRuntimeNil indicates that no runtime was given to the source.
+	ErrRuntimeNil = errors.New("runtime cannot be nil")
+	// ErrBrokerNil indicates that no broker was given to the source.
+	ErrBrokerNil = errors.New("broker cannot be nil")
+	// ErrReaderNil indicates that no reader was given to the source.
+	ErrReaderNil = errors.New("reader cannot be nil")
+	// ErrWriterNil indicates that no writer was given to the sink node.
+	ErrWriterNil = errors.New("writer cannot be nil")
+	// ErrDecodeFnNil indicates that no decoding function was given to the source.
+	ErrDecodeFnNil = errors.New("decode function cannot be nil")
+	// ErrEncodeFnNil indicates that no encoding function was given to the sink node.
+	ErrEncodeFnNil = errors.New("encode function cannot be nil")
+	// ErrInvocationFailed indicates that the invocation of a consumed message failed.
+	ErrInvocationFailed = errors.New("invocation failed")
+)
+
+// Message is a Kafka record.
+type Message struct {
+	// Topic is the topic of the record.
+	Topic string
+	// Partition is the partition of the record, ignored when publishing.
+	Partition int
+	// Offset is the offset of the record within its partition, ignored when publishing.
+	Offset int64
+	// Key is the key of the record, it selects the partition when publishing.
+	Key []byte
+	// Value is the payload of the record.
+	Value []byte
+	// Headers are the headers of the record.
+	Headers map[string][]byte
+	// Time is the timestamp of the record.
+	Time time.Time
+}
+
+// Reader consumes the records of a consumer group.
+type Reader interface {
+	// FetchMessage waits for the next record, without committing its offset.
+	FetchMessage(ctx context.Context) (Message, error)
+	// CommitMessages commits the offsets of the records.
+	CommitMessages(ctx context.Context, msgs ...Message) error
+}
+
+// Writer publishes records.
+type Writer interface {
+	// WriteMessages publishes the records.
+	WriteMessages(ctx context.Context, msgs ...Message) error
+}
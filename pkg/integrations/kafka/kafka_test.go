@@ -0,0 +1,259 @@
+package kafka_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/integrations/kafka"
+	"github.com/morphy76/ggraph/pkg/server"
+)
+
+type orderState struct {
+	Item  string
+	Items []string
+}
+
+// fakeReader delivers the queued messages, then blocks until the context is done.
+type fakeReader struct {
+	msgs chan kafka.Message
+
+	mu        sync.Mutex
+	committed []int64
+}
+
+func newFakeReader(msgs ...kafka.Message) *fakeReader {
+	r := &fakeReader{msgs: make(chan kafka.Message, len(msgs))}
+	for _, msg := range msgs {
+		r.msgs <- msg
+	}
+	return r
+}
+
+func (r *fakeReader) FetchMessage(ctx context.Context) (kafka.Message, error) {
+	select {
+	case msg := <-r.msgs:
+		return msg, nil
+	case <-ctx.Done():
+		return kafka.Message{}, ctx.Err()
+	}
+}
+
+func (r *fakeReader) CommitMessages(ctx context.Context, msgs ...kafka.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, msg := range msgs {
+		r.committed = append(r.committed, msg.Offset)
+	}
+	return nil
+}
+
+func (r *fakeReader) commits() []int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]int64(nil), r.committed...)
+}
+
+type fakeWriter struct {
+	mu   sync.Mutex
+	msgs []kafka.Message
+	err  error
+}
+
+func (w *fakeWriter) WriteMessages(ctx context.Context, msgs ...kafka.Message) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.err != nil {
+		return w.err
+	}
+	w.msgs = append(w.msgs, msgs...)
+	return nil
+}
+
+func (w *fakeWriter) written() []kafka.Message {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]kafka.Message(nil), w.msgs...)
+}
+
+func orderMessage(offset int64, key, item string) kafka.Message {
+	value, _ := json.Marshal(orderState{Item: item})
+	return kafka.Message{Topic: "orders", Offset: offset, Key: []byte(key), Value: value}
+}
+
+// newOrderRuntime creates a runtime appending the item to the thread, failing on the "fail" item, then publishing the items.
+func newOrderRuntime(t *testing.T, writer kafka.Writer) (g.Runtime[orderState], *server.Broker[orderState]) {
+	t.Helper()
+	collect, err := builders.NewNode("Collect", func(userInput, currentState orderState, notify g.NotifyPartialFn[orderState]) (orderState, error) {
+		if userInput.Item == "fail" {
+			return currentState, errors.New("rejected item")
+		}
+		currentState.Items = append(currentState.Items, userInput.Item)
+		return currentState, nil
+	})
+	if err != nil {
+		t.Fatalf("NewNode() failed: %v", err)
+	}
+	publish, err := kafka.NewSinkNode("Publish", writer, func(ctx context.Context, userInput, currentState orderState) ([]kafka.Message, error) {
+		value, err := json.Marshal(currentState.Items)
+		return []kafka.Message{{Topic: "collected", Value: value}}, err
+	})
+	if err != nil {
+		t.Fatalf("NewSinkNode() failed: %v", err)
+	}
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[orderState], 100)
+	runtime, err := builders.CreateRuntime(builders.CreateStartEdge(collect), stateMonitorCh)
+	if err != nil {
+		t.Fatalf("CreateRuntime() failed: %v", err)
+	}
+	runtime.AddEdge(builders.CreateEdge(collect, publish), builders.CreateEndEdge(publish))
+	broker := server.NewBroker(stateMonitorCh, nil)
+	t.Cleanup(func() {
+		broker.Stop()
+		runtime.Shutdown()
+	})
+	return runtime, broker
+}
+
+func waitCommits(t *testing.T, reader *fakeReader, count int) []int64 {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if commits := reader.commits(); len(commits) >= count {
+			return commits
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Timeout waiting for %d commits, got %v", count, reader.commits())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestSource_ThreadPerKey tests that the messages with the same key build up a thread and are committed once invoked
+func TestSource_ThreadPerKey(t *testing.T) {
+	writer := &fakeWriter{}
+	runtime, broker := newOrderRuntime(t, writer)
+	reader := newFakeReader(orderMessage(1, "customer", "apple"), orderMessage(2, "customer", "pear"))
+
+	source, err := kafka.NewSource(runtime, broker, reader, kafka.DecodeJSON[orderState])
+	if err != nil {
+		t.Fatalf("NewSource() failed: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- source.Run(ctx) }()
+
+	commits := waitCommits(t, reader, 2)
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	if commits[0] != 1 || commits[1] != 2 {
+		t.Errorf("Expected the offsets committed in order, got %v", commits)
+	}
+	written := writer.written()
+	if len(written) != 2 || string(written[1].Value) != `["apple","pear"]` {
+		t.Errorf("Expected the items of the customer thread to be published, got %v", written)
+	}
+	if state := runtime.CurrentState("customer"); len(state.Items) != 2 {
+		t.Errorf("Expected the messages invoked on the key thread, got %+v", state)
+	}
+}
+
+// TestSource_Failures tests that a failed invocation is committed, or stops the source with WithStopOnFailure
+func TestSource_Failures(t *testing.T) {
+	t.Run("commit", func(t *testing.T) {
+		runtime, broker := newOrderRuntime(t, &fakeWriter{})
+		reader := newFakeReader(orderMessage(1, "", "fail"), kafka.Message{Topic: "orders", Offset: 2, Value: []byte("not json")}, orderMessage(3, "", "apple"))
+		source, _ := kafka.NewSource(runtime, broker, reader, kafka.DecodeJSON[orderState], kafka.WithThreadMode(kafka.ThreadPerMessage))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() { _ = source.Run(ctx) }()
+
+		if commits := waitCommits(t, reader, 3); commits[2] != 3 {
+			t.Errorf("Expected every message committed, got %v", commits)
+		}
+		if state := runtime.CurrentState("orders-0-3"); len(state.Items) != 1 {
+			t.Errorf("Expected the message invoked on its own thread, got %+v", state)
+		}
+	})
+
+	t.Run("stop", func(t *testing.T) {
+		runtime, broker := newOrderRuntime(t, &fakeWriter{})
+		reader := newFakeReader(orderMessage(1, "customer", "fail"), orderMessage(2, "customer", "apple"))
+		source, _ := kafka.NewSource(runtime, broker, reader, kafka.DecodeJSON[orderState], kafka.WithStopOnFailure())
+
+		err := source.Run(context.Background())
+		if !errors.Is(err, kafka.ErrInvocationFailed) {
+			t.Errorf("Expected ErrInvocationFailed, got %v", err)
+		}
+		if commits := reader.commits(); len(commits) != 0 {
+			t.Errorf("Expected the failed message left uncommitted, got %v", commits)
+		}
+	})
+}
+
+// TestSinkNode_WriteFailure tests that a failed publication fails the node
+func TestSinkNode_WriteFailure(t *testing.T) {
+	writer := &fakeWriter{err: errors.New("broker down")}
+	runtime, broker := newOrderRuntime(t, writer)
+	sub, unsubscribe := broker.Subscribe("thread", 0)
+	defer unsubscribe()
+
+	runtime.Invoke(orderState{Item: "apple"}, g.InvokeConfigThreadID("thread"))
+	select {
+	case <-sub.Done():
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timeout waiting for the invocation")
+	}
+	if final := sub.Final(); final.Error == nil || final.Node != "Publish" {
+		t.Errorf("Expected the sink node to fail, got %+v", final)
+	}
+}
+
+// TestNew_Errors tests the validation of the arguments
+func TestNew_Errors(t *testing.T) {
+	runtime, broker := newOrderRuntime(t, &fakeWriter{})
+	reader := newFakeReader()
+
+	tests := []struct {
+		name string
+		err  func() error
+		want error
+	}{
+		{"nil runtime", func() error {
+			_, err := kafka.NewSource[orderState](nil, broker, reader, kafka.DecodeJSON[orderState])
+			return err
+		}, kafka.ErrRuntimeNil},
+		{"nil broker", func() error {
+			_, err := kafka.NewSource(runtime, nil, reader, kafka.DecodeJSON[orderState])
+			return err
+		}, kafka.ErrBrokerNil},
+		{"nil reader", func() error {
+			_, err := kafka.NewSource(runtime, broker, nil, kafka.DecodeJSON[orderState])
+			return err
+		}, kafka.ErrReaderNil},
+		{"nil decode", func() error { _, err := kafka.NewSource(runtime, broker, reader, nil); return err }, kafka.ErrDecodeFnNil},
+		{"invalid thread mode", func() error {
+			_, err := kafka.NewSource(runtime, broker, reader, kafka.DecodeJSON[orderState], kafka.WithThreadMode(kafka.ThreadMode(9)))
+			return err
+		}, kafka.ErrInvalidThreadMode},
+		{"nil writer", func() error { _, err := kafka.NewSinkNode[orderState]("Sink", nil, nil); return err }, kafka.ErrWriterNil},
+		{"nil encode", func() error { _, err := kafka.NewSinkNode[orderState]("Sink", &fakeWriter{}, nil); return err }, kafka.ErrEncodeFnNil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.err(); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package kafka
+
+import (
+	"errors"
+	"log/slog"
+)
+
+// ThreadMode selects the thread a consumed message is invoked on.
+type ThreadMode int
+
+const (
+	// ThreadPerKey invokes the messages with the same key on the same thread, as a conversation.
+	ThreadPerKey ThreadMode = iota
+	// ThreadPerMessage invokes every message on its own thread, named after its topic, partition and offset.
+	ThreadPerMessage
+)
+
+// ErrInvalidThreadMode indicates that the provided thread mode is unknown.
+var ErrInvalidThreadMode = errors.New("invalid thread mode")
+
+// Options holds the configuration of a Source.
+type Options struct {
+	// ThreadMode selects the thread of the consumed messages, ThreadPerKey by default.
+	ThreadMode ThreadMode
+	// StopOnFailure stops the source on a failed invocation without committing its message,
+	// which is consumed again once the source restarts; the offset is committed otherwise.
+	StopOnFailure bool
+	// Logger receives the structured logs of the source, nil disables logging.
+	Logger *slog.Logger
+}
+
+// Option is a functional option for configuring a Source.
+type Option interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - r: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *Options) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc func(*Options) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - r: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc) Apply(r *Options) error { return s(r) }
+
+// WithThreadMode selects the thread the consumed messages are invoked on.
+//
+// Parameters:
+//   - mode: ThreadPerKey or ThreadPerMessage.
+//
+// Returns:
+//   - An Option that sets the thread mode.
+func WithThreadMode(mode ThreadMode) Option {
+	return OptionFunc(func(r *Options) error {
+		if mode != ThreadPerKey && mode != ThreadPerMessage {
+			return ErrInvalidThreadMode
+		}
+		r.ThreadMode = mode
+		return nil
+	})
+}
+
+// WithStopOnFailure stops the source on a failed invocation, leaving its message uncommitted.
+//
+// Returns:
+//   - An Option that stops the source on failures.
+func WithStopOnFailure() Option {
+	return OptionFunc(func(r *Options) error {
+		r.StopOnFailure = true
+		return nil
+	})
+}
+
+// WithLogger sets the logger of the source.
+//
+// Parameters:
+//   - logger: The logger receiving the structured logs.
+//
+// Returns:
+//   - An Option that sets the logger.
+func WithLogger(logger *slog.Logger) Option {
+	return OptionFunc(func(r *Options) error {
+		r.Logger = logger
+		return nil
+	})
+}
@@ -0,0 +1,56 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// EncodeFn derives the messages to publish from the state, no message is published when it returns none.
+type EncodeFn[T g.SharedState] func(ctx context.Context, userInput, currentState T) ([]Message, error)
+
+// NewSinkNode creates a node publishing the messages derived from the state, leaving the state unchanged.
+//
+// The node fails when the messages cannot be published, the routing policy, retries and
+// fallbacks of the graph apply as for any other node.
+//
+// Parameters:
+//   - name: The name of the node.
+//   - writer: The publisher of the messages.
+//   - encode: The derivation of the messages from the state.
+//   - opts: Optional node options.
+//
+// Returns:
+//   - The node.
+//   - An error if an argument or an option is invalid.
+//
+// Example:
+//
+//	notify, err := kafka.NewSinkNode("Notify", writer, func(ctx context.Context, userInput, state MyState) ([]kafka.Message, error) {
+//	    value, err := json.Marshal(state.Order)
+//	    return []kafka.Message{{Topic: "orders", Key: []byte(state.Order.ID), Value: value}}, err
+//	})
+func NewSinkNode[T g.SharedState](name string, writer Writer, encode EncodeFn[T], opts ...g.NodeOption[T]) (g.Node[T], error) {
+	if writer == nil {
+		return nil, fmt.Errorf("kafka sink node creation failed: %w", ErrWriterNil)
+	}
+	if encode == nil {
+		return nil, fmt.Errorf("kafka sink node creation failed: %w", ErrEncodeFnNil)
+	}
+
+	return builders.NewContextNode(name, func(ctx context.Context, userInput, currentState T, notify g.NotifyPartialFn[T]) (T, error) {
+		msgs, err := encode(ctx, userInput, currentState)
+		if err != nil {
+			return currentState, fmt.Errorf("cannot encode the messages: %w", err)
+		}
+		if len(msgs) == 0 {
+			return currentState, nil
+		}
+		if err := writer.WriteMessages(ctx, msgs...); err != nil {
+			return currentState, fmt.Errorf("cannot publish the messages: %w", err)
+		}
+		return currentState, nil
+	}, opts...)
+}
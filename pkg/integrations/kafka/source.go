@@ -0,0 +1,164 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/server"
+)
+
+// DecodeFn converts a consumed message into the user input of the invocation.
+type DecodeFn[T g.SharedState] func(msg Message) (T, error)
+
+// DecodeJSON is a DecodeFn unmarshalling the value of the message.
+func DecodeJSON[T g.SharedState](msg Message) (T, error) {
+	var rv T
+	if err := json.Unmarshal(msg.Value, &rv); err != nil {
+		return rv, fmt.Errorf("cannot decode message %s/%d/%d: %w", msg.Topic, msg.Partition, msg.Offset, err)
+	}
+	return rv, nil
+}
+
+// Source invokes a runtime for the messages consumed from Kafka.
+type Source[T g.SharedState] struct {
+	runtime g.Runtime[T]
+	broker  *server.Broker[T]
+	reader  Reader
+	decode  DecodeFn[T]
+	opts    Options
+	logger  *slog.Logger
+}
+
+// NewSource creates a source invoking the runtime for every message of the reader.
+//
+// The messages are processed one at a time, in the order of the reader: the offset of a message
+// is committed once its invocation completes, so that a restarted consumer resumes from the first
+// message whose invocation did not complete. Scale out by adding consumers to the group.
+//
+// Parameters:
+//   - runtime: The runtime to invoke.
+//   - broker: The broker of the state monitor channel of the runtime, to await the invocations.
+//   - reader: The consumer of the messages.
+//   - decode: The conversion of a message into the user input, such as DecodeJSON.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - The source, started by Run.
+//   - An error if an argument or an option is invalid.
+//
+// Example:
+//
+//	broker := server.NewBroker(stateMonitorCh, nil)
+//	source, err := kafka.NewSource(runtime, broker, reader, kafka.DecodeJSON[MyState], kafka.WithThreadMode(kafka.ThreadPerKey))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	err = source.Run(ctx)
+func NewSource[T g.SharedState](runtime g.Runtime[T], broker *server.Broker[T], reader Reader, decode DecodeFn[T], opts ...Option) (*Source[T], error) {
+	if runtime == nil {
+		return nil, fmt.Errorf("kafka source creation failed: %w", ErrRuntimeNil)
+	}
+	if broker == nil {
+		return nil, fmt.Errorf("kafka source creation failed: %w", ErrBrokerNil)
+	}
+	if reader == nil {
+		return nil, fmt.Errorf("kafka source creation failed: %w", ErrReaderNil)
+	}
+	if decode == nil {
+		return nil, fmt.Errorf("kafka source creation failed: %w", ErrDecodeFnNil)
+	}
+
+	useOpts := Options{ThreadMode: ThreadPerKey}
+	for _, opt := range opts {
+		if err := opt.Apply(&useOpts); err != nil {
+			return nil, fmt.Errorf("kafka source creation failed: %w", err)
+		}
+	}
+	logger := useOpts.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	return &Source[T]{
+		runtime: runtime,
+		broker:  broker,
+		reader:  reader,
+		decode:  decode,
+		opts:    useOpts,
+		logger:  logger,
+	}, nil
+}
+
+// Run consumes the messages until the context is done or the reader fails.
+//
+// Parameters:
+//   - ctx: Context whose cancellation stops the source, the message being invoked is left uncommitted.
+//
+// Returns:
+//   - The error of the context once done, the error of the reader, or, with WithStopOnFailure,
+//     an error wrapping ErrInvocationFailed.
+func (s *Source[T]) Run(ctx context.Context) error {
+	for {
+		msg, err := s.reader.FetchMessage(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("cannot fetch message: %w", err)
+		}
+
+		if err := s.process(ctx, msg); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if s.opts.StopOnFailure {
+				return err
+			}
+			s.logger.Warn("message failed", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset, "error", err)
+		}
+
+		if err := s.reader.CommitMessages(ctx, msg); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("cannot commit message %s/%d/%d: %w", msg.Topic, msg.Partition, msg.Offset, err)
+		}
+	}
+}
+
+// process invokes the runtime for the message and waits for the completion of the invocation.
+func (s *Source[T]) process(ctx context.Context, msg Message) error {
+	userInput, err := s.decode(msg)
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvocationFailed, err)
+	}
+
+	threadID := s.threadID(msg)
+	sub, unsubscribe := s.broker.Subscribe(threadID, 1)
+	defer unsubscribe()
+
+	s.runtime.Invoke(userInput, g.InvokeConfigThreadID(threadID), g.InvokeConfigContext(ctx))
+	select {
+	case <-sub.Done():
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	final := sub.Final()
+	if final.Error != nil {
+		return fmt.Errorf("%w: thread %s: %w", ErrInvocationFailed, threadID, final.Error)
+	}
+	s.logger.Info("message processed", "topic", msg.Topic, "partition", msg.Partition, "offset", msg.Offset, "thread_id", threadID)
+	return nil
+}
+
+func (s *Source[T]) threadID(msg Message) string {
+	if s.opts.ThreadMode == ThreadPerKey && len(msg.Key) > 0 {
+		return string(msg.Key)
+	}
+	return fmt.Sprintf("%s-%d-%d", msg.Topic, msg.Partition, msg.Offset)
+}
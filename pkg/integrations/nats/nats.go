@@ -0,0 +1,100 @@
+// Package nats exposes graphs on NATS subjects and calls NATS services from graphs.
+//
+// A Service answers the requests of a subject with the final state of the invocation they start;
+// a request node performs a NATS request as part of a graph, merging the reply into the state.
+//
+// The package does not depend on a NATS client: Conn is the few methods used, adapt the client
+// of choice, such as nats.go, to it.
+//
+// Example:
+//
+//	type conn struct{ nc *natsgo.Conn }
+//
+//	func (c conn) QueueSubscribe(subject, queue string, handler func(nats.Msg)) (nats.Subscription, error) {
+//	    return c.nc.QueueSubscribe(subject, queue, func(m *natsgo.Msg) {
+//	        handler(nats.Msg{Subject: m.Subject, Reply: m.Reply, Data: m.Data, Header: m.Header})
+//	    })
+//	}
+//
+//	func (c conn) Publish(subject string, data []byte) error {
+//	    return c.nc.Publish(subject, data)
+//	}
+//
+//	func (c conn) Request(ctx context.Context, subject string, data []byte) (nats.Msg, error) {
+//	    m, err := c.nc.RequestWithContext(ctx, subject, data)
+//	    if err != nil {
+//	        return nats.Msg{}, err
+//	    }
+//	    return nats.Msg{Subject: m.Subject, Data: m.Data, Header: m.Header}, nil
+//	}
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// HeaderThreadID is the request header selecting the thread of the invocation, a new thread is used without it.
+const HeaderThreadID = "Ggraph-Thread-Id"
+
+var (
+	// ErrRuntimeNil indicates that no runtime was given to the service.
+	ErrRuntimeNil = errors.New("runtime cannot be nil")
+	// ErrBrokerNil indicates that no broker was given to the service.
+	ErrBrokerNil = errors.New("broker cannot be nil")
+	// ErrConnNil indicates that no connection was given.
+	ErrConnNil = errors.New("connection cannot be nil")
+	// ErrSubjectEmpty indicates that the subject is empty.
+	ErrSubjectEmpty = errors.New("subject cannot be empty")
+	// ErrEncodeFnNil indicates that no encoding function was given to the request node.
+	ErrEncodeFnNil = errors.New("encode function cannot be nil")
+	// ErrDecodeFnNil indicates that no decoding function was given to the request node.
+	ErrDecodeFnNil = errors.New("decode function cannot be nil")
+	// ErrInvocationTimeout indicates that the invocation did not complete before the reply timeout.
+	ErrInvocationTimeout = errors.New("invocation did not complete in time")
+)
+
+// Msg is a NATS message.
+type Msg struct {
+	// Subject is the subject the message is published to.
+	Subject string
+	// Reply is the subject the reply is expected on, empty for the messages without reply.
+	Reply string
+	// Data is the payload of the message.
+	Data []byte
+	// Header holds the headers of the message.
+	Header map[string][]string
+}
+
+// Subscription is an active subscription of a Conn.
+type Subscription interface {
+	// Unsubscribe removes the subscription.
+	Unsubscribe() error
+}
+
+// Conn is a connection to a NATS server.
+type Conn interface {
+	// QueueSubscribe delivers the messages of the subject to the handler, once per queue group.
+	QueueSubscribe(subject, queue string, handler func(msg Msg)) (Subscription, error)
+	// Publish publishes the data to the subject.
+	Publish(subject string, data []byte) error
+	// Request publishes the data to the subject and waits for the reply until the context is done.
+	Request(ctx context.Context, subject string, data []byte) (Msg, error)
+}
+
+// Reply is the payload a Service replies with.
+type Reply struct {
+	// ThreadID is the thread of the invocation.
+	ThreadID string `json:"thread_id"`
+	// State is the JSON encoded final state, absent when the invocation failed.
+	State json.RawMessage `json:"state,omitempty"`
+	// Outcome is the outcome of the completed invocation, see graph.StateMonitorEntry.Outcome.
+	Outcome string `json:"outcome,omitempty"`
+	// Error is the error of the invocation or of the request.
+	Error string `json:"error,omitempty"`
+	// ErrorCode is the machine-readable kind of Error, see graph.ErrorCodeOf.
+	ErrorCode g.ErrorCode `json:"error_code,omitempty"`
+}
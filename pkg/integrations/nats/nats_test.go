@@ -0,0 +1,282 @@
+package nats_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/integrations/nats"
+	"github.com/morphy76/ggraph/pkg/server"
+)
+
+type quoteState struct {
+	Item  string
+	Price int
+	Items []string
+}
+
+// fakeConn routes the messages to the handlers subscribed to their exact subject.
+type fakeConn struct {
+	mu       sync.Mutex
+	handlers map[string]func(nats.Msg)
+	inboxes  map[string]chan nats.Msg
+	next     int
+}
+
+func newFakeConn() *fakeConn {
+	return &fakeConn{handlers: make(map[string]func(nats.Msg)), inboxes: make(map[string]chan nats.Msg)}
+}
+
+type fakeSubscription struct {
+	conn    *fakeConn
+	subject string
+}
+
+func (s fakeSubscription) Unsubscribe() error {
+	s.conn.mu.Lock()
+	defer s.conn.mu.Unlock()
+	delete(s.conn.handlers, s.subject)
+	return nil
+}
+
+func (c *fakeConn) QueueSubscribe(subject, queue string, handler func(msg nats.Msg)) (nats.Subscription, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handlers[subject] = handler
+	return fakeSubscription{conn: c, subject: subject}, nil
+}
+
+func (c *fakeConn) Publish(subject string, data []byte) error {
+	return c.publish(nats.Msg{Subject: subject, Data: data})
+}
+
+func (c *fakeConn) publish(msg nats.Msg) error {
+	c.mu.Lock()
+	inbox, isInbox := c.inboxes[msg.Subject]
+	handler, ok := c.handlers[msg.Subject]
+	c.mu.Unlock()
+	switch {
+	case isInbox:
+		inbox <- msg
+	case ok:
+		handler(msg)
+	}
+	return nil
+}
+
+func (c *fakeConn) Request(ctx context.Context, subject string, data []byte) (nats.Msg, error) {
+	return c.request(ctx, nats.Msg{Subject: subject, Data: data})
+}
+
+func (c *fakeConn) request(ctx context.Context, msg nats.Msg) (nats.Msg, error) {
+	c.mu.Lock()
+	c.next++
+	msg.Reply = fmt.Sprintf("_INBOX.%d", c.next)
+	inbox := make(chan nats.Msg, 1)
+	c.inboxes[msg.Reply] = inbox
+	_, ok := c.handlers[msg.Subject]
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.inboxes, msg.Reply)
+		c.mu.Unlock()
+	}()
+	if !ok {
+		return nats.Msg{}, errors.New("no responders")
+	}
+
+	_ = c.publish(msg)
+	select {
+	case reply := <-inbox:
+		return reply, nil
+	case <-ctx.Done():
+		return nats.Msg{}, ctx.Err()
+	}
+}
+
+// newQuoteRuntime creates a runtime pricing the item through the pricing.quote service, then collecting it.
+func newQuoteRuntime(t *testing.T, conn nats.Conn) (g.Runtime[quoteState], *server.Broker[quoteState]) {
+	t.Helper()
+	quote, err := nats.NewRequestNode("Quote", conn, "pricing.quote",
+		func(ctx context.Context, userInput, currentState quoteState) ([]byte, error) {
+			return []byte(userInput.Item), nil
+		},
+		func(reply nats.Msg, currentState quoteState) (quoteState, error) {
+			err := json.Unmarshal(reply.Data, &currentState.Price)
+			return currentState, err
+		},
+	)
+	if err != nil {
+		t.Fatalf("NewRequestNode() failed: %v", err)
+	}
+	collect, err := builders.NewNode("Collect", func(userInput, currentState quoteState, notify g.NotifyPartialFn[quoteState]) (quoteState, error) {
+		currentState.Items = append(currentState.Items, userInput.Item)
+		return currentState, nil
+	})
+	if err != nil {
+		t.Fatalf("NewNode() failed: %v", err)
+	}
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[quoteState], 100)
+	runtime, err := builders.CreateRuntime(builders.CreateStartEdge(quote), stateMonitorCh)
+	if err != nil {
+		t.Fatalf("CreateRuntime() failed: %v", err)
+	}
+	runtime.AddEdge(builders.CreateEdge(quote, collect), builders.CreateEndEdge(collect))
+	broker := server.NewBroker(stateMonitorCh, nil)
+	t.Cleanup(func() {
+		broker.Stop()
+		runtime.Shutdown()
+	})
+	return runtime, broker
+}
+
+// servePricing answers the pricing.quote requests with the length of the item, refusing the empty items.
+func servePricing(t *testing.T, conn *fakeConn) {
+	t.Helper()
+	_, _ = conn.QueueSubscribe("pricing.quote", "pricing", func(msg nats.Msg) {
+		if len(msg.Data) == 0 {
+			_ = conn.Publish(msg.Reply, []byte("not a price"))
+			return
+		}
+		_ = conn.Publish(msg.Reply, []byte(fmt.Sprint(len(msg.Data))))
+	})
+}
+
+func requestGraph(t *testing.T, conn *fakeConn, threadID, item string) nats.Reply {
+	t.Helper()
+	data, _ := json.Marshal(quoteState{Item: item})
+	msg := nats.Msg{Subject: "graphs.quote", Data: data}
+	if threadID != "" {
+		msg.Header = map[string][]string{nats.HeaderThreadID: {threadID}}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	replyMsg, err := conn.request(ctx, msg)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	var reply nats.Reply
+	if err := json.Unmarshal(replyMsg.Data, &reply); err != nil {
+		t.Fatalf("Cannot decode reply %s: %v", replyMsg.Data, err)
+	}
+	return reply
+}
+
+// TestService tests that the requests are invoked on their thread and answered with the final state
+func TestService(t *testing.T) {
+	conn := newFakeConn()
+	servePricing(t, conn)
+	runtime, broker := newQuoteRuntime(t, conn)
+	service, err := nats.NewService(runtime, broker, conn)
+	if err != nil {
+		t.Fatalf("NewService() failed: %v", err)
+	}
+	sub, err := service.Serve("graphs.quote")
+	if err != nil {
+		t.Fatalf("Serve() failed: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	reply := requestGraph(t, conn, "", "apple")
+	if reply.Error != "" || reply.ThreadID == "" {
+		t.Fatalf("Expected a successful reply on a new thread, got %+v", reply)
+	}
+	var state quoteState
+	if err := json.Unmarshal(reply.State, &state); err != nil || state.Price != 5 || len(state.Items) != 1 {
+		t.Errorf("Expected the final state with the quoted price, got %s", reply.State)
+	}
+
+	reply = requestGraph(t, conn, reply.ThreadID, "kiwi")
+	_ = json.Unmarshal(reply.State, &state)
+	if state.Price != 4 || len(state.Items) != 2 {
+		t.Errorf("Expected the request invoked on the given thread, got %s", reply.State)
+	}
+}
+
+// TestService_Failures tests that the failed requests are answered with the error
+func TestService_Failures(t *testing.T) {
+	conn := newFakeConn()
+	servePricing(t, conn)
+	runtime, broker := newQuoteRuntime(t, conn)
+	service, _ := nats.NewService(runtime, broker, conn)
+	_, _ = service.Serve("graphs.quote")
+
+	reply := requestGraph(t, conn, "thread", "")
+	if reply.State != nil || reply.ErrorCode != g.CodeNodeFailed {
+		t.Errorf("Expected the failure of the request node, got %+v", reply)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	replyMsg, err := conn.request(ctx, nats.Msg{Subject: "graphs.quote", Data: []byte("not json")})
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	_ = json.Unmarshal(replyMsg.Data, &reply)
+	if reply.Error == "" {
+		t.Errorf("Expected the decoding error, got %s", replyMsg.Data)
+	}
+}
+
+// TestService_Timeout tests that the request is answered with ErrInvocationTimeout when the invocation does not complete in time
+func TestService_Timeout(t *testing.T) {
+	conn := newFakeConn()
+	release := make(chan struct{})
+	_, _ = conn.QueueSubscribe("pricing.quote", "pricing", func(msg nats.Msg) {
+		go func() {
+			<-release
+			_ = conn.Publish(msg.Reply, []byte("1"))
+		}()
+	})
+	defer close(release)
+	runtime, broker := newQuoteRuntime(t, conn)
+	service, _ := nats.NewService(runtime, broker, conn, nats.WithTimeout(20*time.Millisecond))
+	_, _ = service.Serve("graphs.quote")
+
+	if reply := requestGraph(t, conn, "", "apple"); reply.Error == "" || reply.State != nil {
+		t.Errorf("Expected %v, got %+v", nats.ErrInvocationTimeout, reply)
+	}
+}
+
+// TestNew_Errors tests the validation of the arguments
+func TestNew_Errors(t *testing.T) {
+	conn := newFakeConn()
+	runtime, broker := newQuoteRuntime(t, conn)
+	encode := func(ctx context.Context, userInput, currentState quoteState) ([]byte, error) { return nil, nil }
+	decode := func(reply nats.Msg, currentState quoteState) (quoteState, error) { return currentState, nil }
+
+	tests := []struct {
+		name string
+		err  func() error
+		want error
+	}{
+		{"nil runtime", func() error { _, err := nats.NewService[quoteState](nil, broker, conn); return err }, nats.ErrRuntimeNil},
+		{"nil broker", func() error { _, err := nats.NewService(runtime, nil, conn); return err }, nats.ErrBrokerNil},
+		{"nil conn", func() error { _, err := nats.NewService(runtime, broker, nil); return err }, nats.ErrConnNil},
+		{"empty queue", func() error { _, err := nats.NewService(runtime, broker, conn, nats.WithQueue("")); return err }, nats.ErrInvalidQueue},
+		{"invalid timeout", func() error { _, err := nats.NewService(runtime, broker, conn, nats.WithTimeout(0)); return err }, nats.ErrInvalidTimeout},
+		{"empty served subject", func() error {
+			service, _ := nats.NewService(runtime, broker, conn)
+			_, err := service.Serve("")
+			return err
+		}, nats.ErrSubjectEmpty},
+		{"nil request conn", func() error { _, err := nats.NewRequestNode("Request", nil, "subject", encode, decode); return err }, nats.ErrConnNil},
+		{"empty request subject", func() error { _, err := nats.NewRequestNode("Request", conn, "", encode, decode); return err }, nats.ErrSubjectEmpty},
+		{"nil encode", func() error { _, err := nats.NewRequestNode("Request", conn, "subject", nil, decode); return err }, nats.ErrEncodeFnNil},
+		{"nil decode", func() error { _, err := nats.NewRequestNode("Request", conn, "subject", encode, nil); return err }, nats.ErrDecodeFnNil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.err(); !errors.Is(err, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package nats
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+)
+
+const (
+	// DefaultQueue is the default queue group of the services, balancing the requests among their instances.
+	DefaultQueue = "ggraph"
+	// DefaultTimeout is the default time a service waits for the invocation before replying with an error.
+	DefaultTimeout = 30 * time.Second
+)
+
+var (
+	// ErrInvalidQueue indicates that the provided queue group is empty.
+	ErrInvalidQueue = errors.New("queue group cannot be empty")
+	// ErrInvalidTimeout indicates that the provided timeout is not positive.
+	ErrInvalidTimeout = errors.New("timeout must be positive")
+)
+
+// Options holds the configuration of a Service.
+type Options struct {
+	// Queue is the queue group the service subscribes with.
+	Queue string
+	// Timeout is the time waited for the invocation before replying with ErrInvocationTimeout,
+	// the invocation keeps running.
+	Timeout time.Duration
+	// Logger receives the structured logs of the service, nil disables logging.
+	Logger *slog.Logger
+}
+
+// Option is a functional option for configuring a Service.
+type Option interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - r: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *Options) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc func(*Options) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - r: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc) Apply(r *Options) error { return s(r) }
+
+// WithQueue sets the queue group the service subscribes with.
+//
+// Parameters:
+//   - queue: The queue group, shared by the instances serving the same graph.
+//
+// Returns:
+//   - An Option that sets the queue group.
+func WithQueue(queue string) Option {
+	return OptionFunc(func(r *Options) error {
+		if queue == "" {
+			return ErrInvalidQueue
+		}
+		r.Queue = queue
+		return nil
+	})
+}
+
+// WithTimeout sets the time waited for the invocation before replying with an error.
+//
+// Parameters:
+//   - timeout: The reply timeout, shorter than the one of the requesters.
+//
+// Returns:
+//   - An Option that sets the timeout.
+func WithTimeout(timeout time.Duration) Option {
+	return OptionFunc(func(r *Options) error {
+		if timeout <= 0 {
+			return ErrInvalidTimeout
+		}
+		r.Timeout = timeout
+		return nil
+	})
+}
+
+// WithLogger sets the logger of the service.
+//
+// Parameters:
+//   - logger: The logger receiving the structured logs.
+//
+// Returns:
+//   - An Option that sets the logger.
+func WithLogger(logger *slog.Logger) Option {
+	return OptionFunc(func(r *Options) error {
+		r.Logger = logger
+		return nil
+	})
+}
@@ -0,0 +1,75 @@
+package nats
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// RequestEncodeFn derives the payload of the request from the state.
+type RequestEncodeFn[T g.SharedState] func(ctx context.Context, userInput, currentState T) ([]byte, error)
+
+// ReplyDecodeFn merges the reply into the state, returning the new state of the node.
+type ReplyDecodeFn[T g.SharedState] func(reply Msg, currentState T) (T, error)
+
+// NewRequestNode creates a node performing a NATS request to an external service.
+//
+// The request is bounded by the context of the node, set the timeout of the node to bound the
+// wait for the reply; the node fails when no reply arrives, the routing policy, retries and
+// fallbacks of the graph apply as for any other node.
+//
+// Parameters:
+//   - name: The name of the node.
+//   - conn: The connection to the NATS server.
+//   - subject: The subject of the service.
+//   - encode: The derivation of the request payload from the state.
+//   - decode: The merge of the reply into the state.
+//   - opts: Optional node options.
+//
+// Returns:
+//   - The node.
+//   - An error if an argument or an option is invalid.
+//
+// Example:
+//
+//	quote, err := nats.NewRequestNode("Quote", conn, "pricing.quote",
+//	    func(ctx context.Context, userInput, state MyState) ([]byte, error) {
+//	        return json.Marshal(state.Order)
+//	    },
+//	    func(reply nats.Msg, state MyState) (MyState, error) {
+//	        err := json.Unmarshal(reply.Data, &state.Quote)
+//	        return state, err
+//	    },
+//	)
+func NewRequestNode[T g.SharedState](name string, conn Conn, subject string, encode RequestEncodeFn[T], decode ReplyDecodeFn[T], opts ...g.NodeOption[T]) (g.Node[T], error) {
+	if conn == nil {
+		return nil, fmt.Errorf("nats request node creation failed: %w", ErrConnNil)
+	}
+	if subject == "" {
+		return nil, fmt.Errorf("nats request node creation failed: %w", ErrSubjectEmpty)
+	}
+	if encode == nil {
+		return nil, fmt.Errorf("nats request node creation failed: %w", ErrEncodeFnNil)
+	}
+	if decode == nil {
+		return nil, fmt.Errorf("nats request node creation failed: %w", ErrDecodeFnNil)
+	}
+
+	return builders.NewContextNode(name, func(ctx context.Context, userInput, currentState T, notify g.NotifyPartialFn[T]) (T, error) {
+		data, err := encode(ctx, userInput, currentState)
+		if err != nil {
+			return currentState, fmt.Errorf("cannot encode the request: %w", err)
+		}
+		reply, err := conn.Request(ctx, subject, data)
+		if err != nil {
+			return currentState, fmt.Errorf("request to %s failed: %w", subject, err)
+		}
+		newState, err := decode(reply, currentState)
+		if err != nil {
+			return currentState, fmt.Errorf("cannot decode the reply of %s: %w", subject, err)
+		}
+		return newState, nil
+	}, opts...)
+}
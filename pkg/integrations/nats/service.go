@@ -0,0 +1,163 @@
+package nats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/server"
+)
+
+// Service exposes a runtime on NATS subjects.
+type Service[T g.SharedState] struct {
+	runtime g.Runtime[T]
+	broker  *server.Broker[T]
+	conn    Conn
+	opts    Options
+	logger  *slog.Logger
+}
+
+// NewService creates a service invoking the runtime for the requests of the subjects it serves.
+//
+// The payload of a request is the JSON encoded user input, the thread is selected by the
+// HeaderThreadID header; the reply is a JSON encoded Reply carrying the final state of the invocation.
+// The requests without a reply subject are invoked without waiting for their completion.
+//
+// Parameters:
+//   - runtime: The runtime to invoke.
+//   - broker: The broker of the state monitor channel of the runtime, to await the invocations.
+//   - conn: The connection to the NATS server.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - The service, exposed on a subject by Serve.
+//   - An error if an argument or an option is invalid.
+//
+// Example:
+//
+//	broker := server.NewBroker(stateMonitorCh, nil)
+//	service, err := nats.NewService(runtime, broker, conn, nats.WithTimeout(10*time.Second))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	sub, err := service.Serve("graphs.support")
+//	defer sub.Unsubscribe()
+func NewService[T g.SharedState](runtime g.Runtime[T], broker *server.Broker[T], conn Conn, opts ...Option) (*Service[T], error) {
+	if runtime == nil {
+		return nil, fmt.Errorf("nats service creation failed: %w", ErrRuntimeNil)
+	}
+	if broker == nil {
+		return nil, fmt.Errorf("nats service creation failed: %w", ErrBrokerNil)
+	}
+	if conn == nil {
+		return nil, fmt.Errorf("nats service creation failed: %w", ErrConnNil)
+	}
+
+	useOpts := Options{Queue: DefaultQueue, Timeout: DefaultTimeout}
+	for _, opt := range opts {
+		if err := opt.Apply(&useOpts); err != nil {
+			return nil, fmt.Errorf("nats service creation failed: %w", err)
+		}
+	}
+	logger := useOpts.Logger
+	if logger == nil {
+		logger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	}
+
+	return &Service[T]{
+		runtime: runtime,
+		broker:  broker,
+		conn:    conn,
+		opts:    useOpts,
+		logger:  logger,
+	}, nil
+}
+
+// Serve answers the requests of the subject, balanced among the instances of the same queue group.
+//
+// Parameters:
+//   - subject: The subject of the requests, wildcards included.
+//
+// Returns:
+//   - The subscription, to unsubscribe when the service stops.
+//   - An error if the subject is empty or the subscription fails.
+func (s *Service[T]) Serve(subject string) (Subscription, error) {
+	if subject == "" {
+		return nil, fmt.Errorf("cannot serve subject: %w", ErrSubjectEmpty)
+	}
+	sub, err := s.conn.QueueSubscribe(subject, s.opts.Queue, func(msg Msg) {
+		go s.handle(msg)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("cannot serve subject %s: %w", subject, err)
+	}
+	return sub, nil
+}
+
+// handle invokes the runtime for the request and replies with the outcome of the invocation.
+func (s *Service[T]) handle(msg Msg) {
+	threadID := ""
+	if values := msg.Header[HeaderThreadID]; len(values) > 0 {
+		threadID = values[0]
+	}
+	if threadID == "" {
+		threadID = s.runtime.NewThreadID()
+	}
+
+	var userInput T
+	if err := json.Unmarshal(msg.Data, &userInput); err != nil {
+		s.reply(msg, Reply{ThreadID: threadID, Error: fmt.Sprintf("cannot decode request: %v", err)})
+		return
+	}
+
+	if msg.Reply == "" {
+		s.runtime.Invoke(userInput, g.InvokeConfigThreadID(threadID))
+		s.logger.Info("request invoked", "subject", msg.Subject, "thread_id", threadID)
+		return
+	}
+
+	sub, unsubscribe := s.broker.Subscribe(threadID, 1)
+	defer unsubscribe()
+	s.runtime.Invoke(userInput, g.InvokeConfigThreadID(threadID))
+
+	timer := time.NewTimer(s.opts.Timeout)
+	defer timer.Stop()
+	select {
+	case <-sub.Done():
+	case <-timer.C:
+		s.reply(msg, Reply{ThreadID: threadID, Error: fmt.Sprintf("thread %s: %v", threadID, ErrInvocationTimeout)})
+		return
+	}
+
+	final := sub.Final()
+	if final.Error != nil {
+		s.reply(msg, Reply{ThreadID: threadID, Error: final.Error.Error(), ErrorCode: final.ErrorCode})
+		return
+	}
+	state, err := json.Marshal(final.NewState)
+	if err != nil {
+		s.reply(msg, Reply{ThreadID: threadID, Error: fmt.Sprintf("cannot encode state: %v", err)})
+		return
+	}
+	s.reply(msg, Reply{ThreadID: threadID, State: state, Outcome: final.Outcome})
+}
+
+func (s *Service[T]) reply(msg Msg, reply Reply) {
+	if reply.Error != "" {
+		s.logger.Warn("request failed", "subject", msg.Subject, "thread_id", reply.ThreadID, "error", reply.Error)
+	}
+	if msg.Reply == "" {
+		return
+	}
+	data, err := json.Marshal(reply)
+	if err != nil {
+		s.logger.Error("cannot encode reply", "subject", msg.Subject, "thread_id", reply.ThreadID, "error", err)
+		return
+	}
+	if err := s.conn.Publish(msg.Reply, data); err != nil {
+		s.logger.Error("cannot publish reply", "subject", msg.Subject, "thread_id", reply.ThreadID, "error", err)
+	}
+}
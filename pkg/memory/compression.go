@@ -0,0 +1,357 @@
+// Package memory provides decorators applicable to any Memory backend.
+//
+// Example:
+//
+//	store := builders.NewMemMemory[memory.Compressed]()
+//	compressed, err := memory.WithCompression[MyState](store, memory.WithCodec(memory.GzipCodec(gzip.BestCompression)))
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, g.WithMemory[MyState](compressed))
+package memory
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync/atomic"
+
+	"github.com/klauspost/compress/zstd"
+
+	i "github.com/morphy76/ggraph/internal/graph"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+const (
+	// CodecIdentity is the name of the codec storing the serialized state as is.
+	CodecIdentity = "identity"
+	// CodecGzip is the name of the gzip codec.
+	CodecGzip = "gzip"
+	// CodecZstd is the name of the zstd codec.
+	CodecZstd = "zstd"
+)
+
+var (
+	// ErrCompressedMemoryNil indicates that the decorated memory is nil.
+	ErrCompressedMemoryNil = errors.New("compressed memory cannot be nil")
+	// ErrCodecNil indicates that a provided codec is nil.
+	ErrCodecNil = errors.New("codec cannot be nil")
+	// ErrInvalidMinSize indicates that the minimum size to compress is negative.
+	ErrInvalidMinSize = errors.New("minimum compression size must be non-negative")
+	// ErrUnknownCodec indicates that a persisted state was compressed with a codec which is not registered.
+	ErrUnknownCodec = errors.New("unknown codec")
+)
+
+// Compressed is the envelope persisted by the compression decorator into the decorated memory.
+type Compressed struct {
+	// Codec is the name of the codec used to compress Data.
+	Codec string `json:"codec"`
+	// Data is the compressed JSON serialization of the state.
+	Data []byte `json:"data"`
+}
+
+// Codec compresses and decompresses the serialized states.
+//
+// GzipCodec and ZstdCodec are provided, other algorithms are plugged in by implementing Codec.
+type Codec interface {
+	// Name returns the name stored alongside the compressed data, it must be unique.
+	Name() string
+	// Compress compresses the data.
+	Compress(data []byte) ([]byte, error)
+	// Decompress decompresses the data.
+	Decompress(data []byte) ([]byte, error)
+}
+
+// GzipCodec creates a gzip Codec.
+//
+// Parameters:
+//   - level: The gzip compression level, e.g. gzip.DefaultCompression or gzip.BestSpeed.
+//
+// Returns:
+//   - The gzip Codec.
+func GzipCodec(level int) Codec {
+	return gzipCodec{level: level}
+}
+
+type gzipCodec struct {
+	level int
+}
+
+func (c gzipCodec) Name() string { return CodecGzip }
+
+func (c gzipCodec) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	writer, err := gzip.NewWriterLevel(&buf, c.level)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := writer.Write(data); err != nil {
+		return nil, err
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (c gzipCodec) Decompress(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+	return io.ReadAll(reader)
+}
+
+// ZstdCodec creates a zstd Codec, usually faster than gzip for a similar ratio.
+//
+// Parameters:
+//   - level: The zstd compression level, from 1 (fastest) to 22 (best compression).
+//
+// Returns:
+//   - The zstd Codec.
+//   - An error if the encoder or the decoder cannot be created.
+func ZstdCodec(level int) (Codec, error) {
+	encoder, err := zstd.NewWriter(nil, zstd.WithEncoderLevel(zstd.EncoderLevelFromZstd(level)))
+	if err != nil {
+		return nil, fmt.Errorf("zstd codec creation failed: %w", err)
+	}
+	decoder, err := zstd.NewReader(nil)
+	if err != nil {
+		return nil, fmt.Errorf("zstd codec creation failed: %w", err)
+	}
+	return &zstdCodec{encoder: encoder, decoder: decoder}, nil
+}
+
+type zstdCodec struct {
+	encoder *zstd.Encoder
+	decoder *zstd.Decoder
+}
+
+func (c *zstdCodec) Name() string { return CodecZstd }
+
+func (c *zstdCodec) Compress(data []byte) ([]byte, error) {
+	return c.encoder.EncodeAll(data, nil), nil
+}
+
+func (c *zstdCodec) Decompress(data []byte) ([]byte, error) {
+	return c.decoder.DecodeAll(data, nil)
+}
+
+type identityCodec struct{}
+
+func (identityCodec) Name() string                           { return CodecIdentity }
+func (identityCodec) Compress(data []byte) ([]byte, error)   { return data, nil }
+func (identityCodec) Decompress(data []byte) ([]byte, error) { return data, nil }
+
+// CompressionStats reports the sizes handled by the compression decorator.
+type CompressionStats struct {
+	// Persisted is the number of persisted states.
+	Persisted uint64
+	// Restored is the number of restored states.
+	Restored uint64
+	// RawBytes is the total size of the serialized states before compression.
+	RawBytes uint64
+	// CompressedBytes is the total size of the persisted data after compression.
+	CompressedBytes uint64
+}
+
+// Ratio returns the compressed size over the raw size, 0 when nothing was persisted.
+func (s CompressionStats) Ratio() float64 {
+	if s.RawBytes == 0 {
+		return 0
+	}
+	return float64(s.CompressedBytes) / float64(s.RawBytes)
+}
+
+// SavedBytes returns the number of bytes saved by the compression.
+func (s CompressionStats) SavedBytes() int64 {
+	return int64(s.RawBytes) - int64(s.CompressedBytes)
+}
+
+// WithCompression decorates a Memory backend storing the states JSON serialized and compressed.
+//
+// The decorated memory stores Compressed envelopes. The codec name is persisted with the data
+// so that states written with a previous codec remain readable: gzip is always accepted, other
+// codecs are registered with WithDecoders. Restoring an unknown thread, i.e. an empty envelope,
+// returns the zero value of T. The side effects of the threads, see graph.Once, and their
+// scratchpads, see graph.Scratchpad, are stored uncompressed by the decorated memory when it is
+// a graph.OnceStore and a graph.ScratchStore respectively, in process otherwise.
+//
+// Parameters:
+//   - memory: The decorated Memory backend.
+//   - opts: Optional configuration options; gzip with the default level is used when no codec is set.
+//
+// Returns:
+//   - The compressing Memory implementation.
+//   - An error if the memory is nil or the options are invalid.
+//
+// Example:
+//
+//	store, _ := sqlite.NewMemory[memory.Compressed](db)
+//	compressed, err := memory.WithCompression[MyState](store, memory.WithMinSize(512))
+//	stats := compressed.Stats()
+func WithCompression[T g.SharedState](memory g.Memory[Compressed], opts ...CompressionOption) (*CompressedMemory[T], error) {
+	if memory == nil {
+		return nil, fmt.Errorf("compressed memory creation failed: %w", ErrCompressedMemoryNil)
+	}
+
+	useOpts := &CompressionOptions{
+		Codec: GzipCodec(gzip.DefaultCompression),
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("compressed memory creation failed: %w", err)
+		}
+	}
+
+	codecs := map[string]Codec{
+		CodecIdentity: identityCodec{},
+		CodecGzip:     GzipCodec(gzip.DefaultCompression),
+	}
+	for _, codec := range useOpts.Decoders {
+		codecs[codec.Name()] = codec
+	}
+	codecs[useOpts.Codec.Name()] = useOpts.Codec
+
+	once, ok := memory.(g.OnceStore)
+	if !ok {
+		once = i.MemOnceStoreFactory()
+	}
+	scratch, ok := memory.(g.ScratchStore)
+	if !ok {
+		scratch = i.MemScratchStoreFactory()
+	}
+
+	return &CompressedMemory[T]{
+		memory:  memory,
+		opts:    *useOpts,
+		codecs:  codecs,
+		once:    once,
+		scratch: scratch,
+	}, nil
+}
+
+// ------------------------------------------------------------------------------
+// Compressed Memory Implementation
+// ------------------------------------------------------------------------------
+
+var _ g.Memory[g.SharedState] = (*CompressedMemory[g.SharedState])(nil)
+var _ g.OnceStore = (*CompressedMemory[g.SharedState])(nil)
+var _ g.ScratchStore = (*CompressedMemory[g.SharedState])(nil)
+
+// CompressedMemory is a Memory decorator compressing the persisted states.
+type CompressedMemory[T g.SharedState] struct {
+	memory g.Memory[Compressed]
+	opts   CompressionOptions
+	codecs map[string]Codec
+	// once is the decorated memory when it records the side effects, an in-process store otherwise.
+	once g.OnceStore
+	// scratch is the decorated memory when it stores the scratchpads, an in-process store otherwise.
+	scratch g.ScratchStore
+
+	persisted       atomic.Uint64
+	restored        atomic.Uint64
+	rawBytes        atomic.Uint64
+	compressedBytes atomic.Uint64
+}
+
+// PersistFn returns a function to persist the shared state.
+func (m *CompressedMemory[T]) PersistFn() g.PersistFn[T] {
+	persist := m.memory.PersistFn()
+	return func(ctx context.Context, threadID string, state T) error {
+		data, err := json.Marshal(state)
+		if err != nil {
+			return fmt.Errorf("cannot serialize state of thread %s: %w", threadID, err)
+		}
+
+		codec := m.opts.Codec
+		if len(data) < m.opts.MinSize {
+			codec = identityCodec{}
+		}
+		compressed, err := codec.Compress(data)
+		if err != nil {
+			return fmt.Errorf("cannot compress state of thread %s with %s: %w", threadID, codec.Name(), err)
+		}
+
+		if err := persist(ctx, threadID, Compressed{Codec: codec.Name(), Data: compressed}); err != nil {
+			return err
+		}
+
+		m.persisted.Add(1)
+		m.rawBytes.Add(uint64(len(data)))
+		m.compressedBytes.Add(uint64(len(compressed)))
+		return nil
+	}
+}
+
+// RestoreFn returns a function to restore the shared state.
+func (m *CompressedMemory[T]) RestoreFn() g.RestoreFn[T] {
+	restore := m.memory.RestoreFn()
+	return func(ctx context.Context, threadID string) (T, error) {
+		var zero T
+
+		envelope, err := restore(ctx, threadID)
+		if err != nil {
+			return zero, err
+		}
+		if envelope.Codec == "" && len(envelope.Data) == 0 {
+			return zero, nil
+		}
+
+		codec, ok := m.codecs[envelope.Codec]
+		if !ok {
+			return zero, fmt.Errorf("cannot restore state of thread %s: %w: %s", threadID, ErrUnknownCodec, envelope.Codec)
+		}
+		data, err := codec.Decompress(envelope.Data)
+		if err != nil {
+			return zero, fmt.Errorf("cannot decompress state of thread %s with %s: %w", threadID, codec.Name(), err)
+		}
+
+		var state T
+		if err := json.Unmarshal(data, &state); err != nil {
+			return zero, fmt.Errorf("cannot deserialize state of thread %s: %w", threadID, err)
+		}
+		m.restored.Add(1)
+		return state, nil
+	}
+}
+
+// ClaimOnce records the side effect of the thread, see WithCompression.
+func (m *CompressedMemory[T]) ClaimOnce(ctx context.Context, threadID, key string) (bool, error) {
+	return m.once.ClaimOnce(ctx, threadID, key)
+}
+
+// ReleaseOnce forgets the side effect of the thread, see WithCompression.
+func (m *CompressedMemory[T]) ReleaseOnce(ctx context.Context, threadID, key string) error {
+	return m.once.ReleaseOnce(ctx, threadID, key)
+}
+
+// SaveScratch stores an entry of the scratchpad of the thread, see WithCompression.
+func (m *CompressedMemory[T]) SaveScratch(ctx context.Context, threadID, key string, value []byte) error {
+	return m.scratch.SaveScratch(ctx, threadID, key, value)
+}
+
+// LoadScratch returns the entries of the scratchpad of the thread, see WithCompression.
+func (m *CompressedMemory[T]) LoadScratch(ctx context.Context, threadID string) (map[string][]byte, error) {
+	return m.scratch.LoadScratch(ctx, threadID)
+}
+
+// DeleteScratch deletes an entry of the scratchpad of the thread, see WithCompression.
+func (m *CompressedMemory[T]) DeleteScratch(ctx context.Context, threadID, key string) error {
+	return m.scratch.DeleteScratch(ctx, threadID, key)
+}
+
+// Stats returns the sizes handled so far by the decorator.
+//
+// Returns:
+//   - The compression statistics.
+func (m *CompressedMemory[T]) Stats() CompressionStats {
+	return CompressionStats{
+		Persisted:       m.persisted.Load(),
+		Restored:        m.restored.Load(),
+		RawBytes:        m.rawBytes.Load(),
+		CompressedBytes: m.compressedBytes.Load(),
+	}
+}
@@ -0,0 +1,240 @@
+package memory_test
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/memory"
+)
+
+type testState struct {
+	Value   string
+	History []string
+}
+
+func TestWithCompression_NilMemory(t *testing.T) {
+	compressed, err := memory.WithCompression[testState](nil)
+	if !errors.Is(err, memory.ErrCompressedMemoryNil) {
+		t.Errorf("Expected ErrCompressedMemoryNil, got %v", err)
+	}
+	if compressed != nil {
+		t.Error("Expected nil memory when the decorated memory is nil")
+	}
+}
+
+func TestWithCompression_InvalidOptions(t *testing.T) {
+	store := builders.NewMemMemory[memory.Compressed]()
+	if _, err := memory.WithCompression[testState](store, memory.WithCodec(nil)); !errors.Is(err, memory.ErrCodecNil) {
+		t.Errorf("Expected ErrCodecNil, got %v", err)
+	}
+	if _, err := memory.WithCompression[testState](store, memory.WithDecoders(nil)); !errors.Is(err, memory.ErrCodecNil) {
+		t.Errorf("Expected ErrCodecNil for decoders, got %v", err)
+	}
+	if _, err := memory.WithCompression[testState](store, memory.WithMinSize(-1)); !errors.Is(err, memory.ErrInvalidMinSize) {
+		t.Errorf("Expected ErrInvalidMinSize, got %v", err)
+	}
+}
+
+func TestWithCompression_RoundTrip(t *testing.T) {
+	store := builders.NewMemMemory[memory.Compressed]()
+	compressed, err := memory.WithCompression[testState](store)
+	if err != nil {
+		t.Fatalf("WithCompression failed: %v", err)
+	}
+
+	state := testState{Value: "long", History: []string{strings.Repeat("hello world ", 500)}}
+	if err := compressed.PersistFn()(context.Background(), "thread", state); err != nil {
+		t.Fatalf("Persist failed: %v", err)
+	}
+
+	envelope, _ := store.RestoreFn()(context.Background(), "thread")
+	if envelope.Codec != memory.CodecGzip {
+		t.Errorf("Expected the gzip codec, got %s", envelope.Codec)
+	}
+
+	restored, err := compressed.RestoreFn()(context.Background(), "thread")
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if restored.Value != state.Value || len(restored.History) != 1 || restored.History[0] != state.History[0] {
+		t.Errorf("Unexpected restored state %+v", restored.Value)
+	}
+
+	stats := compressed.Stats()
+	if stats.Persisted != 1 || stats.Restored != 1 {
+		t.Errorf("Unexpected counters %+v", stats)
+	}
+	if stats.CompressedBytes != uint64(len(envelope.Data)) {
+		t.Errorf("Expected %d compressed bytes, got %d", len(envelope.Data), stats.CompressedBytes)
+	}
+	if stats.Ratio() >= 0.1 || stats.SavedBytes() <= 0 {
+		t.Errorf("Expected a repetitive state to compress well, got ratio %f", stats.Ratio())
+	}
+}
+
+func TestWithCompression_UnknownThread(t *testing.T) {
+	compressed, _ := memory.WithCompression[testState](builders.NewMemMemory[memory.Compressed]())
+
+	restored, err := compressed.RestoreFn()(context.Background(), "missing")
+	if err != nil {
+		t.Fatalf("Restore failed: %v", err)
+	}
+	if restored.Value != "" {
+		t.Errorf("Expected the zero state, got %+v", restored)
+	}
+}
+
+func TestWithCompression_MinSize(t *testing.T) {
+	store := builders.NewMemMemory[memory.Compressed]()
+	compressed, _ := memory.WithCompression[testState](store, memory.WithMinSize(1024))
+
+	if err := compressed.PersistFn()(context.Background(), "thread", testState{Value: "short"}); err != nil {
+		t.Fatalf("Persist failed: %v", err)
+	}
+
+	envelope, _ := store.RestoreFn()(context.Background(), "thread")
+	if envelope.Codec != memory.CodecIdentity {
+		t.Errorf("Expected small states to be stored uncompressed, got %s", envelope.Codec)
+	}
+	if stats := compressed.Stats(); stats.RawBytes != stats.CompressedBytes {
+		t.Errorf("Expected equal sizes, got %+v", stats)
+	}
+
+	restored, err := compressed.RestoreFn()(context.Background(), "thread")
+	if err != nil || restored.Value != "short" {
+		t.Errorf("Unexpected restore %+v, %v", restored, err)
+	}
+}
+
+type reverseCodec struct{}
+
+func (reverseCodec) Name() string { return "reverse" }
+
+func (reverseCodec) Compress(data []byte) ([]byte, error) {
+	rv := make([]byte, len(data))
+	for i, b := range data {
+		rv[len(data)-1-i] = b
+	}
+	return rv, nil
+}
+
+func (c reverseCodec) Decompress(data []byte) ([]byte, error) { return c.Compress(data) }
+
+func TestWithCompression_CodecMigration(t *testing.T) {
+	store := builders.NewMemMemory[memory.Compressed]()
+	legacy, _ := memory.WithCompression[testState](store, memory.WithCodec(memory.GzipCodec(gzip.BestSpeed)))
+	if err := legacy.PersistFn()(context.Background(), "thread", testState{Value: "legacy"}); err != nil {
+		t.Fatalf("Persist failed: %v", err)
+	}
+
+	strict, _ := memory.WithCompression[testState](store, memory.WithCodec(reverseCodec{}))
+	if _, err := strict.RestoreFn()(context.Background(), "thread"); err != nil {
+		t.Fatalf("Expected gzip to be always decodable with the default registrations, got %v", err)
+	}
+
+	if err := strict.PersistFn()(context.Background(), "thread", testState{Value: "reversed"}); err != nil {
+		t.Fatalf("Persist failed: %v", err)
+	}
+	plain, _ := memory.WithCompression[testState](store)
+	if _, err := plain.RestoreFn()(context.Background(), "thread"); !errors.Is(err, memory.ErrUnknownCodec) {
+		t.Errorf("Expected ErrUnknownCodec, got %v", err)
+	}
+
+	migrating, _ := memory.WithCompression[testState](store, memory.WithDecoders(reverseCodec{}))
+	restored, err := migrating.RestoreFn()(context.Background(), "thread")
+	if err != nil || restored.Value != "reversed" {
+		t.Errorf("Unexpected restore %+v, %v", restored, err)
+	}
+}
+
+func TestWithCompression_Zstd(t *testing.T) {
+	codec, err := memory.ZstdCodec(3)
+	if err != nil {
+		t.Fatalf("ZstdCodec failed: %v", err)
+	}
+	store := builders.NewMemMemory[memory.Compressed]()
+	compressed, _ := memory.WithCompression[testState](store, memory.WithCodec(codec))
+
+	state := testState{Value: "zstd", History: []string{strings.Repeat("hello world ", 500)}}
+	if err := compressed.PersistFn()(context.Background(), "thread", state); err != nil {
+		t.Fatalf("Persist failed: %v", err)
+	}
+
+	envelope, _ := store.RestoreFn()(context.Background(), "thread")
+	if envelope.Codec != memory.CodecZstd {
+		t.Errorf("Expected the zstd codec, got %s", envelope.Codec)
+	}
+
+	restored, err := compressed.RestoreFn()(context.Background(), "thread")
+	if err != nil || restored.Value != "zstd" || restored.History[0] != state.History[0] {
+		t.Errorf("Unexpected restore %+v, %v", restored.Value, err)
+	}
+	if ratio := compressed.Stats().Ratio(); ratio >= 0.1 {
+		t.Errorf("Expected a repetitive state to compress well, got ratio %f", ratio)
+	}
+}
+
+// plainMemory hides the optional interfaces of the decorated memory.
+type plainMemory struct {
+	g.Memory[memory.Compressed]
+}
+
+func TestWithCompression_Once(t *testing.T) {
+	ctx := context.Background()
+	store := builders.NewMemMemory[memory.Compressed]()
+	compressed, _ := memory.WithCompression[testState](store)
+
+	if claimed, err := compressed.ClaimOnce(ctx, "thread", "email"); err != nil || !claimed {
+		t.Fatalf("Expected the side effect to be claimed, got %t %v", claimed, err)
+	}
+	if claimed, _ := store.(g.OnceStore).ClaimOnce(ctx, "thread", "email"); claimed {
+		t.Error("Expected the side effect to be recorded by the decorated memory")
+	}
+
+	inProcess, _ := memory.WithCompression[testState](plainMemory{builders.NewMemMemory[memory.Compressed]()})
+	if claimed, _ := inProcess.ClaimOnce(ctx, "thread", "email"); !claimed {
+		t.Error("Expected the side effect to be claimed in process")
+	}
+	if claimed, _ := inProcess.ClaimOnce(ctx, "thread", "email"); claimed {
+		t.Error("Expected a recorded side effect not to be claimed twice")
+	}
+	if err := inProcess.ReleaseOnce(ctx, "thread", "email"); err != nil {
+		t.Fatalf("ReleaseOnce failed: %v", err)
+	}
+	if claimed, _ := inProcess.ClaimOnce(ctx, "thread", "email"); !claimed {
+		t.Error("Expected a released side effect to be claimed again")
+	}
+}
+
+func TestWithCompression_Scratch(t *testing.T) {
+	store := builders.NewMemMemory[memory.Compressed]()
+	compressed, _ := memory.WithCompression[testState](store)
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[testState], 10)
+	start, _ := builders.NewNode[testState]("Start", nil)
+	runtime, err := builders.CreateRuntime(builders.CreateStartEdge(start), stateMonitorCh, g.WithMemory[testState](compressed))
+	if err != nil {
+		t.Fatalf("CreateRuntime failed: %v", err)
+	}
+	defer runtime.Shutdown()
+
+	if err := runtime.Scratch("thread").Set("docs", []string{"a"}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	entries, err := store.(g.ScratchStore).LoadScratch(context.Background(), "thread")
+	if err != nil || string(entries["docs"]) != `["a"]` {
+		t.Errorf("Expected the scratchpad to be stored by the decorated memory, got %q %v", entries, err)
+	}
+
+	if err := compressed.DeleteScratch(context.Background(), "thread", "docs"); err != nil {
+		t.Fatalf("DeleteScratch failed: %v", err)
+	}
+	if entries, _ := compressed.LoadScratch(context.Background(), "thread"); len(entries) != 0 {
+		t.Errorf("Expected the entry to be deleted, got %q", entries)
+	}
+}
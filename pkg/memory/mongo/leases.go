@@ -0,0 +1,71 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// NewLeaseStore creates a LeaseStore keeping one document per owned thread.
+//
+// The collection must not be the one of the Memory, both key their documents by the thread ID.
+//
+// Parameters:
+//   - collection: The collection holding the thread leases, shared by the runtime instances.
+//
+// Returns:
+//   - The MongoDB LeaseStore implementation.
+//   - An error if the collection is nil.
+//
+// Example:
+//
+//	leases, err := mongo.NewLeaseStore(client.Database("ggraph").Collection("leases"))
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, g.WithMemory[MyState](memory), g.WithThreadLeases[MyState](leases, 30*time.Second))
+func NewLeaseStore(collection *mongodriver.Collection) (*LeaseStore, error) {
+	if collection == nil {
+		return nil, fmt.Errorf("mongo lease store creation failed: %w", ErrCollectionNil)
+	}
+	return &LeaseStore{collection: collection}, nil
+}
+
+// ------------------------------------------------------------------------------
+// MongoDB LeaseStore Implementation
+// ------------------------------------------------------------------------------
+
+var _ g.LeaseStore = (*LeaseStore)(nil)
+
+// LeaseStore is a LeaseStore implementation backed by MongoDB.
+type LeaseStore struct {
+	collection *mongodriver.Collection
+}
+
+// Acquire upserts the lease of the thread when owned by the owner or expired; the upsert of a
+// lease held by another owner fails on the unique thread ID.
+func (s *LeaseStore) Acquire(ctx context.Context, threadID, owner string, ttl time.Duration) (bool, error) {
+	now := time.Now().UTC()
+	_, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": threadID, "$or": bson.A{bson.M{"owner": owner}, bson.M{"expires_at": bson.M{"$lte": now}}}},
+		bson.M{"$set": bson.M{"owner": owner, "expires_at": now.Add(ttl)}},
+		options.Update().SetUpsert(true))
+	if mongodriver.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("cannot acquire the lease of thread %s: %w", threadID, err)
+	}
+	return true, nil
+}
+
+// Release deletes the lease of the thread, if held by the owner.
+func (s *LeaseStore) Release(ctx context.Context, threadID, owner string) error {
+	if _, err := s.collection.DeleteOne(ctx, bson.M{"_id": threadID, "owner": owner}); err != nil {
+		return fmt.Errorf("cannot release the lease of thread %s: %w", threadID, err)
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package mongo
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestLeaseStore(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+
+	mt.Run("acquire an available lease", func(mt *mtest.T) {
+		leases, _ := NewLeaseStore(mt.Coll)
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+
+		if acquired, err := leases.Acquire(ctx, "thread-1", "instance-a", time.Minute); err != nil || !acquired {
+			mt.Errorf("Expected the lease to be acquired, got %t %v", acquired, err)
+		}
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		if owner := update.Lookup("u", "$set", "owner").StringValue(); owner != "instance-a" || !update.Lookup("upsert").Boolean() {
+			mt.Errorf("Expected the upsert of the lease, got %v", update)
+		}
+	})
+
+	mt.Run("a lease held by another owner", func(mt *mtest.T) {
+		leases, _ := NewLeaseStore(mt.Coll)
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key"}))
+
+		if acquired, err := leases.Acquire(ctx, "thread-1", "instance-b", time.Minute); err != nil || acquired {
+			mt.Errorf("Expected the lease not to be acquired, got %t %v", acquired, err)
+		}
+	})
+
+	mt.Run("release", func(mt *mtest.T) {
+		leases, _ := NewLeaseStore(mt.Coll)
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+
+		if err := leases.Release(ctx, "thread-1", "instance-a"); err != nil {
+			mt.Fatalf("Release failed: %v", err)
+		}
+		deletion := mt.GetStartedEvent().Command.Lookup("deletes").Array().Index(0).Value().Document()
+		if owner := deletion.Lookup("q", "owner").StringValue(); owner != "instance-a" {
+			mt.Errorf("Expected the lease of the owner to be deleted, got %v", deletion)
+		}
+	})
+}
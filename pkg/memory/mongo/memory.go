@@ -0,0 +1,404 @@
+// Package mongo provides a Memory implementation backed by a MongoDB collection.
+//
+// Every thread is stored as one document keyed by the thread ID, the state is kept as a
+// queryable sub-document built from its JSON serialization. The side effects executed with
+// graph.Once and the scratchpads of the threads are kept in sibling collections, see
+// WithOnceCollectionName and WithScratchCollectionName.
+//
+// Example:
+//
+//	client, _ := mongodriver.Connect(options.Client().ApplyURI("mongodb://localhost:27017"))
+//	collection := client.Database("ggraph").Collection("threads")
+//	memory, err := mongo.NewMemory[MyState](collection, mongo.WithTTL(time.Hour))
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, g.WithMemory[MyState](memory))
+package mongo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	mongodriver "go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// StateChange is a change of a thread state observed through a change stream.
+type StateChange[T g.SharedState] struct {
+	// ThreadID is the thread whose state changed.
+	ThreadID string
+	// State is the new state, the zero value when the thread was deleted.
+	State T
+	// Deleted is true when the thread was deleted or expired.
+	Deleted bool
+	// UpdatedAt is the time the state was persisted, zero when the thread was deleted.
+	UpdatedAt time.Time
+	// Err is set on the last change sent before the channel is closed because of a failure.
+	Err error
+}
+
+// NewMemory creates a Memory implementation storing one document per thread.
+//
+// Parameters:
+//   - collection: The collection holding the thread states.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - The MongoDB Memory implementation.
+//   - An error if the options are invalid or the indexes cannot be created.
+//
+// Example:
+//
+//	memory, err := mongo.NewMemory[MyState](collection, mongo.WithTTL(settings.ThreadTTL), mongo.WithChangeStreams(0))
+func NewMemory[T g.SharedState](collection *mongodriver.Collection, opts ...Option) (*Memory[T], error) {
+	if collection == nil {
+		return nil, fmt.Errorf("mongo memory creation failed: %w", ErrCollectionNil)
+	}
+
+	useOpts := &Options{
+		ChangeStreamBuffer:    DefaultChangeStreamBuffer,
+		OnceCollectionName:    DefaultOnceCollectionName,
+		ScratchCollectionName: DefaultScratchCollectionName,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("mongo memory creation failed: %w", err)
+		}
+	}
+
+	rv := &Memory[T]{
+		collection: collection,
+		once:       collection.Database().Collection(useOpts.OnceCollectionName),
+		scratch:    collection.Database().Collection(useOpts.ScratchCollectionName),
+		opts:       *useOpts,
+	}
+
+	if !useOpts.SkipIndexes {
+		if err := rv.createIndexes(context.Background()); err != nil {
+			return nil, fmt.Errorf("mongo memory creation failed: %w", err)
+		}
+	}
+
+	return rv, nil
+}
+
+// ------------------------------------------------------------------------------
+// MongoDB Memory Implementation
+// ------------------------------------------------------------------------------
+
+var _ g.Memory[g.SharedState] = (*Memory[g.SharedState])(nil)
+var _ g.OnceStore = (*Memory[g.SharedState])(nil)
+var _ g.ScratchStore = (*Memory[g.SharedState])(nil)
+var _ g.StateDeleter = (*Memory[g.SharedState])(nil)
+
+// Memory is a Memory implementation backed by MongoDB.
+type Memory[T g.SharedState] struct {
+	collection *mongodriver.Collection
+	once       *mongodriver.Collection
+	scratch    *mongodriver.Collection
+	opts       Options
+}
+
+type threadDocument struct {
+	ThreadID  string    `bson:"_id"`
+	State     bson.Raw  `bson:"state"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+type onceDocument struct {
+	ThreadID  string    `bson:"thread_id"`
+	Key       string    `bson:"key"`
+	ClaimedAt time.Time `bson:"claimed_at"`
+}
+
+type scratchDocument struct {
+	ThreadID  string    `bson:"thread_id"`
+	Key       string    `bson:"key"`
+	Value     []byte    `bson:"value"`
+	UpdatedAt time.Time `bson:"updated_at"`
+}
+
+type changeEvent struct {
+	OperationType string `bson:"operationType"`
+	DocumentKey   struct {
+		ThreadID string `bson:"_id"`
+	} `bson:"documentKey"`
+	FullDocument *threadDocument `bson:"fullDocument"`
+}
+
+// PersistFn returns a function to persist the shared state.
+func (m *Memory[T]) PersistFn() g.PersistFn[T] {
+	return func(ctx context.Context, threadID string, state T) error {
+		doc, err := encodeState(state)
+		if err != nil {
+			return fmt.Errorf("cannot serialize state of thread %s: %w", threadID, err)
+		}
+
+		_, err = m.collection.ReplaceOne(ctx,
+			bson.M{"_id": threadID},
+			threadDocument{ThreadID: threadID, State: doc, UpdatedAt: time.Now().UTC()},
+			options.Replace().SetUpsert(true))
+		if err != nil {
+			return fmt.Errorf("cannot persist state of thread %s: %w", threadID, err)
+		}
+		return nil
+	}
+}
+
+// RestoreFn returns a function to restore the shared state.
+//
+// Restoring an unknown or expired thread returns the zero value of T without error.
+func (m *Memory[T]) RestoreFn() g.RestoreFn[T] {
+	return func(ctx context.Context, threadID string) (T, error) {
+		var zero T
+
+		var doc threadDocument
+		err := m.collection.FindOne(ctx, bson.M{"_id": threadID}).Decode(&doc)
+		if errors.Is(err, mongodriver.ErrNoDocuments) {
+			return zero, nil
+		}
+		if err != nil {
+			return zero, fmt.Errorf("cannot restore state of thread %s: %w", threadID, err)
+		}
+
+		state, err := decodeState[T](doc.State)
+		if err != nil {
+			return zero, fmt.Errorf("cannot deserialize state of thread %s: %w", threadID, err)
+		}
+		return state, nil
+	}
+}
+
+// Delete removes the thread document, the side effects recorded for the thread and its scratchpad.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control.
+//   - threadID: The thread to remove.
+//
+// Returns:
+//   - An error if the deletion fails.
+func (m *Memory[T]) Delete(ctx context.Context, threadID string) error {
+	if _, err := m.collection.DeleteOne(ctx, bson.M{"_id": threadID}); err != nil {
+		return fmt.Errorf("cannot delete thread %s: %w", threadID, err)
+	}
+	if _, err := m.once.DeleteMany(ctx, bson.M{"thread_id": threadID}); err != nil {
+		return fmt.Errorf("cannot delete side effects of thread %s: %w", threadID, err)
+	}
+	if _, err := m.scratch.DeleteMany(ctx, bson.M{"thread_id": threadID}); err != nil {
+		return fmt.Errorf("cannot delete scratchpad of thread %s: %w", threadID, err)
+	}
+	return nil
+}
+
+// ClaimOnce inserts the side effect of the thread, the insertion of a recorded one fails on the
+// unique OnceIndexName index.
+func (m *Memory[T]) ClaimOnce(ctx context.Context, threadID, key string) (bool, error) {
+	_, err := m.once.InsertOne(ctx, onceDocument{ThreadID: threadID, Key: key, ClaimedAt: time.Now().UTC()})
+	if mongodriver.IsDuplicateKeyError(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("cannot record side effect %s of thread %s: %w", key, threadID, err)
+	}
+	return true, nil
+}
+
+// ReleaseOnce deletes the side effect of the thread.
+func (m *Memory[T]) ReleaseOnce(ctx context.Context, threadID, key string) error {
+	if _, err := m.once.DeleteOne(ctx, bson.M{"thread_id": threadID, "key": key}); err != nil {
+		return fmt.Errorf("cannot forget side effect %s of thread %s: %w", key, threadID, err)
+	}
+	return nil
+}
+
+// SaveScratch upserts the scratchpad entry of the thread.
+func (m *Memory[T]) SaveScratch(ctx context.Context, threadID, key string, value []byte) error {
+	_, err := m.scratch.UpdateOne(ctx,
+		bson.M{"thread_id": threadID, "key": key},
+		bson.M{"$set": bson.M{"value": value, "updated_at": time.Now().UTC()}},
+		options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("cannot save scratchpad entry %s of thread %s: %w", key, threadID, err)
+	}
+	return nil
+}
+
+// LoadScratch reads the scratchpad entries of the thread.
+func (m *Memory[T]) LoadScratch(ctx context.Context, threadID string) (map[string][]byte, error) {
+	cursor, err := m.scratch.Find(ctx, bson.M{"thread_id": threadID})
+	if err != nil {
+		return nil, fmt.Errorf("cannot load scratchpad of thread %s: %w", threadID, err)
+	}
+	defer cursor.Close(ctx)
+
+	rv := make(map[string][]byte)
+	for cursor.Next(ctx) {
+		var doc scratchDocument
+		if err := cursor.Decode(&doc); err != nil {
+			return nil, fmt.Errorf("cannot load scratchpad of thread %s: %w", threadID, err)
+		}
+		rv[doc.Key] = doc.Value
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("cannot load scratchpad of thread %s: %w", threadID, err)
+	}
+	return rv, nil
+}
+
+// DeleteScratch deletes the scratchpad entry of the thread.
+func (m *Memory[T]) DeleteScratch(ctx context.Context, threadID, key string) error {
+	if _, err := m.scratch.DeleteOne(ctx, bson.M{"thread_id": threadID, "key": key}); err != nil {
+		return fmt.Errorf("cannot delete scratchpad entry %s of thread %s: %w", key, threadID, err)
+	}
+	return nil
+}
+
+// Watch opens a change stream notifying the changes of the thread states.
+//
+// The channel is closed when the context is done or the change stream fails; in the latter
+// case the last change carries the error. Deletions, including the TTL expirations, are
+// notified with Deleted set.
+//
+// Parameters:
+//   - ctx: Context controlling the lifetime of the change stream.
+//   - threadIDs: The threads to watch; all threads when empty.
+//
+// Returns:
+//   - The channel of the state changes.
+//   - ErrChangeStreamsDisabled if WithChangeStreams was not set, or an error if the change stream cannot be opened.
+//
+// Example:
+//
+//	changes, err := memory.Watch(ctx, threadID)
+//	for change := range changes {
+//	    fmt.Printf("thread %s changed: %+v\n", change.ThreadID, change.State)
+//	}
+func (m *Memory[T]) Watch(ctx context.Context, threadIDs ...string) (<-chan StateChange[T], error) {
+	if !m.opts.ChangeStreams {
+		return nil, fmt.Errorf("cannot watch thread states: %w", ErrChangeStreamsDisabled)
+	}
+
+	match := bson.D{{Key: "operationType", Value: bson.M{"$in": bson.A{"insert", "update", "replace", "delete"}}}}
+	if len(threadIDs) > 0 {
+		match = append(match, bson.E{Key: "documentKey._id", Value: bson.M{"$in": threadIDs}})
+	}
+	pipeline := mongodriver.Pipeline{{{Key: "$match", Value: match}}}
+
+	stream, err := m.collection.Watch(ctx, pipeline, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+	if err != nil {
+		return nil, fmt.Errorf("cannot watch thread states: %w", err)
+	}
+
+	changes := make(chan StateChange[T], m.opts.ChangeStreamBuffer)
+	go func() {
+		defer close(changes)
+		defer stream.Close(context.Background())
+
+		for stream.Next(ctx) {
+			change := m.decodeChange(stream)
+			select {
+			case changes <- change:
+			case <-ctx.Done():
+				return
+			}
+			if change.Err != nil {
+				return
+			}
+		}
+		if err := stream.Err(); err != nil && ctx.Err() == nil {
+			select {
+			case changes <- StateChange[T]{Err: fmt.Errorf("change stream failed: %w", err)}:
+			case <-ctx.Done():
+			}
+		}
+	}()
+	return changes, nil
+}
+
+func (m *Memory[T]) decodeChange(stream *mongodriver.ChangeStream) StateChange[T] {
+	var event changeEvent
+	if err := stream.Decode(&event); err != nil {
+		return StateChange[T]{Err: fmt.Errorf("cannot decode change event: %w", err)}
+	}
+
+	rv := StateChange[T]{ThreadID: event.DocumentKey.ThreadID}
+	// An update looked up after a deletion has no full document: the thread is gone.
+	if event.OperationType == "delete" || event.FullDocument == nil {
+		rv.Deleted = true
+		return rv
+	}
+
+	state, err := decodeState[T](event.FullDocument.State)
+	if err != nil {
+		rv.Err = fmt.Errorf("cannot deserialize state of thread %s: %w", rv.ThreadID, err)
+		return rv
+	}
+	rv.State = state
+	rv.UpdatedAt = event.FullDocument.UpdatedAt
+	return rv
+}
+
+func (m *Memory[T]) createIndexes(ctx context.Context) error {
+	if m.opts.TTL > 0 {
+		_, err := m.collection.Indexes().CreateOne(ctx, mongodriver.IndexModel{
+			Keys: bson.D{{Key: "updated_at", Value: 1}},
+			Options: options.Index().
+				SetName(TTLIndexName).
+				SetExpireAfterSeconds(int32(m.opts.TTL / time.Second)),
+		})
+		if err != nil {
+			return fmt.Errorf("cannot create index %s: %w", TTLIndexName, err)
+		}
+	}
+
+	_, err := m.once.Indexes().CreateOne(ctx, mongodriver.IndexModel{
+		Keys:    bson.D{{Key: "thread_id", Value: 1}, {Key: "key", Value: 1}},
+		Options: options.Index().SetName(OnceIndexName).SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("cannot create index %s: %w", OnceIndexName, err)
+	}
+
+	_, err = m.scratch.Indexes().CreateOne(ctx, mongodriver.IndexModel{
+		Keys:    bson.D{{Key: "thread_id", Value: 1}, {Key: "key", Value: 1}},
+		Options: options.Index().SetName(ScratchIndexName).SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("cannot create index %s: %w", ScratchIndexName, err)
+	}
+	return nil
+}
+
+// encodeState converts the JSON serialization of the state into a BSON document.
+func encodeState[T g.SharedState](state T) (bson.Raw, error) {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 || data[0] != '{' {
+		return nil, ErrStateNotDocument
+	}
+
+	var doc bson.Raw
+	if err := bson.UnmarshalExtJSON(data, false, &doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
+
+// decodeState converts a BSON document back into the JSON serialization of the state.
+func decodeState[T g.SharedState](doc bson.Raw) (T, error) {
+	var state T
+	data, err := bson.MarshalExtJSON(doc, false, false)
+	if err != nil {
+		return state, err
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, err
+	}
+	return state, nil
+}
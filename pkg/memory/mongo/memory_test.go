@@ -0,0 +1,268 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+type codecState struct {
+	Value   string
+	Counter int
+	Ratio   float64
+	Tags    []string
+	Data    map[string]interface{}
+	Missing *string
+}
+
+func TestStateCodec_RoundTrip(t *testing.T) {
+	state := codecState{
+		Value:   "hello",
+		Counter: 42,
+		Ratio:   0.5,
+		Tags:    []string{"a", "b"},
+		Data:    map[string]interface{}{"nested": map[string]interface{}{"n": 1.5}, "flag": true},
+	}
+
+	doc, err := encodeState(state)
+	if err != nil {
+		t.Fatalf("encodeState failed: %v", err)
+	}
+	if value, ok := doc.Lookup("Value").StringValueOK(); !ok || value != "hello" {
+		t.Errorf("Expected a queryable Value field, got %v", doc.Lookup("Value"))
+	}
+
+	restored, err := decodeState[codecState](doc)
+	if err != nil {
+		t.Fatalf("decodeState failed: %v", err)
+	}
+	if restored.Value != state.Value || restored.Counter != state.Counter || restored.Ratio != state.Ratio {
+		t.Errorf("Unexpected restored state %+v", restored)
+	}
+	if len(restored.Tags) != 2 || restored.Data["flag"] != true || restored.Data["nested"].(map[string]interface{})["n"] != 1.5 {
+		t.Errorf("Unexpected restored collections %+v", restored)
+	}
+	if restored.Missing != nil {
+		t.Errorf("Expected nil pointer, got %v", restored.Missing)
+	}
+}
+
+func TestStateCodec_NotDocument(t *testing.T) {
+	if _, err := encodeState("plain string"); !errors.Is(err, ErrStateNotDocument) {
+		t.Errorf("Expected ErrStateNotDocument, got %v", err)
+	}
+}
+
+func TestMemory_PersistRestore(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+
+	mt.Run("persist upserts the thread document", func(mt *mtest.T) {
+		memory, err := NewMemory[codecState](mt.Coll, WithoutIndexes())
+		if err != nil {
+			mt.Fatalf("NewMemory failed: %v", err)
+		}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "upserted", Value: bson.A{bson.D{{Key: "index", Value: 0}, {Key: "_id", Value: "thread-1"}}}}))
+
+		if err := memory.PersistFn()(ctx, "thread-1", codecState{Value: "hello", Counter: 1}); err != nil {
+			mt.Fatalf("PersistFn failed: %v", err)
+		}
+		command := mt.GetStartedEvent().Command
+		update := command.Lookup("updates").Array().Index(0).Value().Document()
+		if id := update.Lookup("q", "_id").StringValue(); id != "thread-1" || !update.Lookup("upsert").Boolean() {
+			mt.Errorf("Expected an upsert of the thread document, got %v", update)
+		}
+		if value := update.Lookup("u", "state", "Value").StringValue(); value != "hello" {
+			mt.Errorf("Expected the state as a sub-document, got %v", update.Lookup("u"))
+		}
+	})
+
+	mt.Run("persist reports the failures", func(mt *mtest.T) {
+		memory, _ := NewMemory[codecState](mt.Coll, WithoutIndexes())
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 11600, Name: "InterruptedAtShutdown", Message: "shutting down"}))
+
+		if err := memory.PersistFn()(ctx, "thread-1", codecState{Value: "hello"}); err == nil {
+			mt.Error("Expected the persistence to fail")
+		}
+	})
+
+	mt.Run("restore decodes the thread document", func(mt *mtest.T) {
+		memory, _ := NewMemory[codecState](mt.Coll, WithoutIndexes())
+		ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: "thread-1"},
+			{Key: "state", Value: bson.D{{Key: "Value", Value: "hello"}, {Key: "Counter", Value: 2}, {Key: "Tags", Value: bson.A{"a"}}}},
+			{Key: "updated_at", Value: time.Now().UTC()},
+		}))
+
+		state, err := memory.RestoreFn()(ctx, "thread-1")
+		if err != nil || state.Value != "hello" || state.Counter != 2 || len(state.Tags) != 1 {
+			mt.Errorf("Expected the persisted state, got %+v %v", state, err)
+		}
+		if filter := mt.GetStartedEvent().Command.Lookup("filter", "_id").StringValue(); filter != "thread-1" {
+			mt.Errorf("Expected the thread document to be looked up, got %s", filter)
+		}
+	})
+
+	mt.Run("restore an unknown thread", func(mt *mtest.T) {
+		memory, _ := NewMemory[codecState](mt.Coll, WithoutIndexes())
+		ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch))
+
+		if state, err := memory.RestoreFn()(ctx, "unknown"); err != nil || state.Value != "" {
+			mt.Errorf("Expected the zero state, got %+v %v", state, err)
+		}
+	})
+
+	mt.Run("delete removes the thread document", func(mt *mtest.T) {
+		memory, _ := NewMemory[codecState](mt.Coll, WithoutIndexes())
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}), mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 2}), mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+
+		if err := memory.Delete(ctx, "thread-1"); err != nil {
+			mt.Fatalf("Delete failed: %v", err)
+		}
+		deletion := mt.GetStartedEvent().Command.Lookup("deletes").Array().Index(0).Value().Document()
+		if id := deletion.Lookup("q", "_id").StringValue(); id != "thread-1" {
+			mt.Errorf("Expected the thread document to be deleted, got %v", deletion)
+		}
+		command := mt.GetStartedEvent().Command
+		deletion = command.Lookup("deletes").Array().Index(0).Value().Document()
+		if command.Lookup("delete").StringValue() != DefaultOnceCollectionName || deletion.Lookup("q", "thread_id").StringValue() != "thread-1" {
+			mt.Errorf("Expected the side effects of the thread to be deleted, got %v", command)
+		}
+		command = mt.GetStartedEvent().Command
+		deletion = command.Lookup("deletes").Array().Index(0).Value().Document()
+		if command.Lookup("delete").StringValue() != DefaultScratchCollectionName || deletion.Lookup("q", "thread_id").StringValue() != "thread-1" {
+			mt.Errorf("Expected the scratchpad of the thread to be deleted, got %v", command)
+		}
+	})
+
+	mt.Run("TTL index", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+		if _, err := NewMemory[codecState](mt.Coll, WithTTL(time.Hour)); err != nil {
+			mt.Fatalf("NewMemory failed: %v", err)
+		}
+		index := mt.GetStartedEvent().Command.Lookup("indexes").Array().Index(0).Value().Document()
+		if index.Lookup("name").StringValue() != TTLIndexName || index.Lookup("expireAfterSeconds").Int32() != 3600 {
+			mt.Errorf("Expected the TTL index, got %v", index)
+		}
+	})
+
+	mt.Run("once and scratch indexes", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateSuccessResponse(), mtest.CreateSuccessResponse())
+		if _, err := NewMemory[codecState](mt.Coll); err != nil {
+			mt.Fatalf("NewMemory failed: %v", err)
+		}
+		for _, want := range []struct{ collection, name string }{{DefaultOnceCollectionName, OnceIndexName}, {DefaultScratchCollectionName, ScratchIndexName}} {
+			command := mt.GetStartedEvent().Command
+			index := command.Lookup("indexes").Array().Index(0).Value().Document()
+			if command.Lookup("createIndexes").StringValue() != want.collection || index.Lookup("name").StringValue() != want.name || !index.Lookup("unique").Boolean() {
+				mt.Errorf("Expected the unique index %s, got %v", want.name, command)
+			}
+		}
+	})
+}
+
+func TestMemory_Once(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+
+	mt.Run("claim a new side effect", func(mt *mtest.T) {
+		memory, _ := NewMemory[codecState](mt.Coll, WithoutIndexes())
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+
+		if claimed, err := memory.ClaimOnce(ctx, "thread-1", "email"); err != nil || !claimed {
+			mt.Errorf("Expected the side effect to be claimed, got %t %v", claimed, err)
+		}
+		document := mt.GetStartedEvent().Command.Lookup("documents").Array().Index(0).Value().Document()
+		if document.Lookup("thread_id").StringValue() != "thread-1" || document.Lookup("key").StringValue() != "email" {
+			mt.Errorf("Expected the insertion of the side effect, got %v", document)
+		}
+	})
+
+	mt.Run("claim a recorded side effect", func(mt *mtest.T) {
+		memory, _ := NewMemory[codecState](mt.Coll, WithoutIndexes())
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key"}))
+
+		if claimed, err := memory.ClaimOnce(ctx, "thread-1", "email"); err != nil || claimed {
+			mt.Errorf("Expected the side effect not to be claimed, got %t %v", claimed, err)
+		}
+	})
+
+	mt.Run("claim reports the failures", func(mt *mtest.T) {
+		memory, _ := NewMemory[codecState](mt.Coll, WithoutIndexes())
+		mt.AddMockResponses(mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 11600, Name: "InterruptedAtShutdown", Message: "shutting down"}))
+
+		if _, err := memory.ClaimOnce(ctx, "thread-1", "email"); err == nil {
+			mt.Error("Expected the claim to fail")
+		}
+	})
+
+	mt.Run("release", func(mt *mtest.T) {
+		memory, _ := NewMemory[codecState](mt.Coll, WithoutIndexes())
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+
+		if err := memory.ReleaseOnce(ctx, "thread-1", "email"); err != nil {
+			mt.Fatalf("ReleaseOnce failed: %v", err)
+		}
+		deletion := mt.GetStartedEvent().Command.Lookup("deletes").Array().Index(0).Value().Document()
+		if deletion.Lookup("q", "thread_id").StringValue() != "thread-1" || deletion.Lookup("q", "key").StringValue() != "email" {
+			mt.Errorf("Expected the side effect to be deleted, got %v", deletion)
+		}
+	})
+}
+
+func TestMemory_Scratch(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	ctx := context.Background()
+
+	mt.Run("save upserts the entry", func(mt *mtest.T) {
+		memory, _ := NewMemory[codecState](mt.Coll, WithoutIndexes())
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+
+		if err := memory.SaveScratch(ctx, "thread-1", "notes", []byte(`"draft"`)); err != nil {
+			mt.Fatalf("SaveScratch failed: %v", err)
+		}
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		if update.Lookup("q", "key").StringValue() != "notes" || !update.Lookup("upsert").Boolean() {
+			mt.Errorf("Expected the upsert of the entry, got %v", update)
+		}
+		if _, value := update.Lookup("u", "$set", "value").Binary(); string(value) != `"draft"` {
+			mt.Errorf("Expected the value of the entry, got %v", update.Lookup("u"))
+		}
+	})
+
+	mt.Run("load reads the entries", func(mt *mtest.T) {
+		memory, _ := NewMemory[codecState](mt.Coll, WithoutIndexes())
+		ns := mt.Coll.Database().Name() + "." + DefaultScratchCollectionName
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch,
+			bson.D{{Key: "thread_id", Value: "thread-1"}, {Key: "key", Value: "notes"}, {Key: "value", Value: []byte(`"final"`)}},
+			bson.D{{Key: "thread_id", Value: "thread-1"}, {Key: "key", Value: "plan"}, {Key: "value", Value: []byte(`1`)}},
+		))
+
+		scratch, err := memory.LoadScratch(ctx, "thread-1")
+		if err != nil || len(scratch) != 2 || string(scratch["notes"]) != `"final"` || string(scratch["plan"]) != "1" {
+			mt.Errorf("Expected the entries of the thread, got %v %v", scratch, err)
+		}
+		if filter := mt.GetStartedEvent().Command.Lookup("filter", "thread_id").StringValue(); filter != "thread-1" {
+			mt.Errorf("Expected the entries of the thread to be looked up, got %s", filter)
+		}
+	})
+
+	mt.Run("delete", func(mt *mtest.T) {
+		memory, _ := NewMemory[codecState](mt.Coll, WithoutIndexes())
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}))
+
+		if err := memory.DeleteScratch(ctx, "thread-1", "notes"); err != nil {
+			mt.Fatalf("DeleteScratch failed: %v", err)
+		}
+		deletion := mt.GetStartedEvent().Command.Lookup("deletes").Array().Index(0).Value().Document()
+		if deletion.Lookup("q", "thread_id").StringValue() != "thread-1" || deletion.Lookup("q", "key").StringValue() != "notes" {
+			mt.Errorf("Expected the entry to be deleted, got %v", deletion)
+		}
+	})
+}
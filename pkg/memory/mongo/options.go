@@ -0,0 +1,188 @@
+package mongo
+
+import (
+	"errors"
+	"time"
+)
+
+const (
+	// DefaultChangeStreamBuffer is the default size of the channels returned by Watch.
+	DefaultChangeStreamBuffer = 16
+	// TTLIndexName is the name of the TTL index created on the update time of the threads.
+	TTLIndexName = "ggraph_thread_ttl"
+	// DefaultOnceCollectionName is the default name of the collection holding the side effects executed by the threads.
+	DefaultOnceCollectionName = "ggraph_threads_once"
+	// OnceIndexName is the name of the unique index created on the thread and the key of the side effects.
+	OnceIndexName = "ggraph_once_thread_key"
+	// DefaultScratchCollectionName is the default name of the collection holding the scratchpads of the threads.
+	DefaultScratchCollectionName = "ggraph_threads_scratch"
+	// ScratchIndexName is the name of the unique index created on the thread and the key of the scratchpad entries.
+	ScratchIndexName = "ggraph_scratch_thread_key"
+)
+
+var (
+	// ErrCollectionNil indicates that the provided collection is nil.
+	ErrCollectionNil = errors.New("collection cannot be nil")
+	// ErrInvalidTTL indicates that the provided TTL is not at least one second.
+	ErrInvalidTTL = errors.New("TTL must be at least one second")
+	// ErrInvalidChangeStreamBuffer indicates that the provided change stream buffer is negative.
+	ErrInvalidChangeStreamBuffer = errors.New("change stream buffer must be non-negative")
+	// ErrChangeStreamsDisabled indicates that Watch was called without enabling the change streams.
+	ErrChangeStreamsDisabled = errors.New("change streams are disabled")
+	// ErrStateNotDocument indicates that the state does not serialize to a JSON object.
+	ErrStateNotDocument = errors.New("state must serialize to a JSON object")
+	// ErrInvalidCollectionName indicates that the provided collection name is empty.
+	ErrInvalidCollectionName = errors.New("collection name cannot be empty")
+)
+
+// Options holds the configuration for the MongoDB Memory implementation.
+type Options struct {
+	// TTL expires the threads not updated for the given duration, 0 keeps them forever.
+	TTL time.Duration
+	// ChangeStreams enables Watch.
+	ChangeStreams bool
+	// ChangeStreamBuffer is the size of the channels returned by Watch.
+	ChangeStreamBuffer int
+	// OnceCollectionName is the name of the collection, in the database of the thread states, recording the
+	// side effects executed with graph.Once.
+	OnceCollectionName string
+	// ScratchCollectionName is the name of the collection, in the database of the thread states, holding the
+	// scratchpad entries set with graph.Scratchpad.Set.
+	ScratchCollectionName string
+	// SkipIndexes disables the automatic creation of the indexes.
+	SkipIndexes bool
+}
+
+// Option is a functional option for configuring the MongoDB Memory implementation.
+type Option interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - r: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *Options) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc func(*Options) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - r: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc) Apply(r *Options) error { return s(r) }
+
+// WithTTL expires the threads not updated for the given duration through a TTL index.
+//
+// Align it to the ThreadTTL of the runtime so that the persisted states live as long as
+// the threads. MongoDB removes the expired documents in background, roughly every minute.
+//
+// Parameters:
+//   - ttl: The time-to-live of the threads, at least one second.
+//
+// Returns:
+//   - An Option that sets the TTL.
+//
+// Example:
+//
+//	memory, err := mongo.NewMemory[MyState](collection, mongo.WithTTL(g.RuntimeSettingDefaultThreadTTL))
+func WithTTL(ttl time.Duration) Option {
+	return OptionFunc(func(r *Options) error {
+		if ttl < time.Second {
+			return ErrInvalidTTL
+		}
+		r.TTL = ttl
+		return nil
+	})
+}
+
+// WithChangeStreams enables Watch, it requires a replica set or a sharded cluster.
+//
+// Parameters:
+//   - bufferSize: The size of the channels returned by Watch; when 0 DefaultChangeStreamBuffer is used.
+//
+// Returns:
+//   - An Option that enables the change streams.
+//
+// Example:
+//
+//	memory, err := mongo.NewMemory[MyState](collection, mongo.WithChangeStreams(0))
+//	changes, err := memory.Watch(ctx)
+func WithChangeStreams(bufferSize int) Option {
+	return OptionFunc(func(r *Options) error {
+		if bufferSize < 0 {
+			return ErrInvalidChangeStreamBuffer
+		}
+		if bufferSize > 0 {
+			r.ChangeStreamBuffer = bufferSize
+		}
+		r.ChangeStreams = true
+		return nil
+	})
+}
+
+// WithOnceCollectionName sets the name of the collection recording the side effects executed by the threads.
+//
+// The collection is taken from the database of the thread states.
+//
+// Parameters:
+//   - name: The collection name, it cannot be empty.
+//
+// Returns:
+//   - An Option that sets the side effect collection name.
+//
+// Example:
+//
+//	memory, err := mongo.NewMemory[MyState](collection, mongo.WithOnceCollectionName("side_effects"))
+func WithOnceCollectionName(name string) Option {
+	return OptionFunc(func(r *Options) error {
+		if name == "" {
+			return ErrInvalidCollectionName
+		}
+		r.OnceCollectionName = name
+		return nil
+	})
+}
+
+// WithScratchCollectionName sets the name of the collection holding the scratchpads of the threads.
+//
+// The collection is taken from the database of the thread states.
+//
+// Parameters:
+//   - name: The collection name, it cannot be empty.
+//
+// Returns:
+//   - An Option that sets the scratchpad collection name.
+//
+// Example:
+//
+//	memory, err := mongo.NewMemory[MyState](collection, mongo.WithScratchCollectionName("scratch"))
+func WithScratchCollectionName(name string) Option {
+	return OptionFunc(func(r *Options) error {
+		if name == "" {
+			return ErrInvalidCollectionName
+		}
+		r.ScratchCollectionName = name
+		return nil
+	})
+}
+
+// WithoutIndexes disables the automatic creation of the indexes.
+//
+// Create them beforehand: without the unique OnceIndexName index the side effects executed
+// with graph.Once are no longer guaranteed to run at most once, without the unique
+// ScratchIndexName index a scratchpad entry saved concurrently may be duplicated.
+//
+// Returns:
+//   - An Option that disables the index creation.
+func WithoutIndexes() Option {
+	return OptionFunc(func(r *Options) error {
+		r.SkipIndexes = true
+		return nil
+	})
+}
@@ -0,0 +1,140 @@
+package mongo_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/morphy76/ggraph/pkg/memory/mongo"
+)
+
+type testState struct {
+	Value string
+}
+
+func TestNewMemory_NilCollection(t *testing.T) {
+	memory, err := mongo.NewMemory[testState](nil)
+	if err == nil {
+		t.Fatal("Expected error when creating memory with nil collection, got nil")
+	}
+	if memory != nil {
+		t.Error("Expected nil memory when collection is nil")
+	}
+	if !errors.Is(err, mongo.ErrCollectionNil) {
+		t.Errorf("Expected ErrCollectionNil, got %v", err)
+	}
+}
+
+func TestNewLeaseStore_NilCollection(t *testing.T) {
+	store, err := mongo.NewLeaseStore(nil)
+	if !errors.Is(err, mongo.ErrCollectionNil) {
+		t.Errorf("Expected ErrCollectionNil, got %v", err)
+	}
+	if store != nil {
+		t.Error("Expected nil store when collection is nil")
+	}
+}
+
+func TestOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		option  mongo.Option
+		wantErr error
+		check   func(t *testing.T, opts mongo.Options)
+	}{
+		{
+			name:   "valid TTL",
+			option: mongo.WithTTL(time.Hour),
+			check: func(t *testing.T, opts mongo.Options) {
+				if opts.TTL != time.Hour {
+					t.Errorf("Expected TTL 1h, got %v", opts.TTL)
+				}
+			},
+		},
+		{
+			name:    "sub-second TTL",
+			option:  mongo.WithTTL(500 * time.Millisecond),
+			wantErr: mongo.ErrInvalidTTL,
+		},
+		{
+			name:   "change streams with default buffer",
+			option: mongo.WithChangeStreams(0),
+			check: func(t *testing.T, opts mongo.Options) {
+				if !opts.ChangeStreams {
+					t.Error("Expected ChangeStreams to be enabled")
+				}
+				if opts.ChangeStreamBuffer != 0 {
+					t.Errorf("Expected untouched ChangeStreamBuffer, got %d", opts.ChangeStreamBuffer)
+				}
+			},
+		},
+		{
+			name:   "change streams with custom buffer",
+			option: mongo.WithChangeStreams(64),
+			check: func(t *testing.T, opts mongo.Options) {
+				if !opts.ChangeStreams || opts.ChangeStreamBuffer != 64 {
+					t.Errorf("Expected change streams with buffer 64, got %+v", opts)
+				}
+			},
+		},
+		{
+			name:    "change streams with negative buffer",
+			option:  mongo.WithChangeStreams(-1),
+			wantErr: mongo.ErrInvalidChangeStreamBuffer,
+		},
+		{
+			name:   "once collection name",
+			option: mongo.WithOnceCollectionName("side_effects"),
+			check: func(t *testing.T, opts mongo.Options) {
+				if opts.OnceCollectionName != "side_effects" {
+					t.Errorf("Expected OnceCollectionName 'side_effects', got '%s'", opts.OnceCollectionName)
+				}
+			},
+		},
+		{
+			name:    "empty once collection name",
+			option:  mongo.WithOnceCollectionName(""),
+			wantErr: mongo.ErrInvalidCollectionName,
+		},
+		{
+			name:   "scratch collection name",
+			option: mongo.WithScratchCollectionName("scratch"),
+			check: func(t *testing.T, opts mongo.Options) {
+				if opts.ScratchCollectionName != "scratch" {
+					t.Errorf("Expected ScratchCollectionName 'scratch', got '%s'", opts.ScratchCollectionName)
+				}
+			},
+		},
+		{
+			name:    "empty scratch collection name",
+			option:  mongo.WithScratchCollectionName(""),
+			wantErr: mongo.ErrInvalidCollectionName,
+		},
+		{
+			name:   "without indexes",
+			option: mongo.WithoutIndexes(),
+			check: func(t *testing.T, opts mongo.Options) {
+				if !opts.SkipIndexes {
+					t.Error("Expected SkipIndexes to be set")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := mongo.Options{}
+			err := tt.option.Apply(&opts)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			tt.check(t, opts)
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package memory
+
+// CompressionOptions holds the configuration of the compression decorator.
+type CompressionOptions struct {
+	// Codec compresses the persisted states.
+	Codec Codec
+	// Decoders are additional codecs accepted when restoring states persisted with a previous codec.
+	Decoders []Codec
+	// MinSize is the serialized size under which states are stored uncompressed.
+	MinSize int
+}
+
+// CompressionOption is a functional option for configuring the compression decorator.
+type CompressionOption interface {
+	// Apply applies the option to the CompressionOptions.
+	//
+	// Parameters:
+	//   - r: A pointer to CompressionOptions to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *CompressionOptions) error
+}
+
+// CompressionOptionFunc is a function type that implements the CompressionOption interface.
+type CompressionOptionFunc func(*CompressionOptions) error
+
+// Apply applies the CompressionOptionFunc to the given CompressionOptions.
+//
+// Parameters:
+//   - r: A pointer to CompressionOptions to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s CompressionOptionFunc) Apply(r *CompressionOptions) error { return s(r) }
+
+// WithCodec sets the codec compressing the persisted states.
+//
+// Parameters:
+//   - codec: The codec, e.g. GzipCodec(gzip.BestSpeed) or ZstdCodec(3).
+//
+// Returns:
+//   - A CompressionOption that sets the codec.
+//
+// Example:
+//
+//	compressed, err := memory.WithCompression[MyState](store, memory.WithCodec(memory.GzipCodec(gzip.BestSpeed)))
+func WithCodec(codec Codec) CompressionOption {
+	return CompressionOptionFunc(func(r *CompressionOptions) error {
+		if codec == nil {
+			return ErrCodecNil
+		}
+		r.Codec = codec
+		return nil
+	})
+}
+
+// WithDecoders registers codecs accepted when restoring, useful when migrating from a codec to another.
+//
+// Parameters:
+//   - codecs: The codecs states may have been persisted with.
+//
+// Returns:
+//   - A CompressionOption that registers the decoders.
+//
+// Example:
+//
+//	zstdCodec, _ := memory.ZstdCodec(3)
+//	compressed, err := memory.WithCompression[MyState](store,
+//	    memory.WithCodec(memory.GzipCodec(gzip.BestCompression)),
+//	    memory.WithDecoders(zstdCodec),
+//	)
+func WithDecoders(codecs ...Codec) CompressionOption {
+	return CompressionOptionFunc(func(r *CompressionOptions) error {
+		for _, codec := range codecs {
+			if codec == nil {
+				return ErrCodecNil
+			}
+		}
+		r.Decoders = append(r.Decoders, codecs...)
+		return nil
+	})
+}
+
+// WithMinSize stores uncompressed the states whose serialization is smaller than size bytes.
+//
+// Parameters:
+//   - size: The minimum serialized size to compress.
+//
+// Returns:
+//   - A CompressionOption that sets the minimum size.
+//
+// Example:
+//
+//	compressed, err := memory.WithCompression[MyState](store, memory.WithMinSize(1024))
+func WithMinSize(size int) CompressionOption {
+	return CompressionOptionFunc(func(r *CompressionOptions) error {
+		if size < 0 {
+			return ErrInvalidMinSize
+		}
+		r.MinSize = size
+		return nil
+	})
+}
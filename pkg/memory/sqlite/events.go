@@ -0,0 +1,134 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// NewEventStore creates an EventStore appending one JSON serialized row per event.
+//
+// Parameters:
+//   - db: An open SQLite database handle.
+//   - opts: Optional configuration options, only WithEventTableName and WithoutMigration apply.
+//
+// Returns:
+//   - The SQLite EventStore implementation.
+//   - An error if the options are invalid or the schema cannot be created.
+//
+// Example:
+//
+//	store, err := sqlite.NewEventStore[MyState](db)
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, g.WithEventStore[MyState](store))
+func NewEventStore[T g.SharedState](db *sql.DB, opts ...Option) (*EventStore[T], error) {
+	if db == nil {
+		return nil, fmt.Errorf("sqlite event store creation failed: %w", ErrDBNil)
+	}
+
+	useOpts := &Options{
+		EventTableName: DefaultEventTableName,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("sqlite event store creation failed: %w", err)
+		}
+	}
+
+	rv := &EventStore[T]{
+		db:   db,
+		opts: *useOpts,
+	}
+
+	if !useOpts.SkipMigration {
+		if err := rv.migrate(context.Background()); err != nil {
+			return nil, fmt.Errorf("sqlite event store creation failed: %w", err)
+		}
+	}
+
+	return rv, nil
+}
+
+// ------------------------------------------------------------------------------
+// SQLite EventStore Implementation
+// ------------------------------------------------------------------------------
+
+var _ g.EventStore[g.SharedState] = (*EventStore[g.SharedState])(nil)
+
+// EventStore is an EventStore implementation backed by SQLite.
+type EventStore[T g.SharedState] struct {
+	db   *sql.DB
+	opts Options
+}
+
+// Append records the event, the sequence is assigned by the table.
+func (s *EventStore[T]) Append(ctx context.Context, event g.Event[T]) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("cannot serialize event of thread %s: %w", event.ThreadID, err)
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		"INSERT INTO "+s.opts.EventTableName+" (thread_id, event, recorded_at) VALUES (?, ?, ?)",
+		event.ThreadID, data, event.Timestamp.UTC().UnixNano())
+	if err != nil {
+		return fmt.Errorf("cannot append event of thread %s: %w", event.ThreadID, err)
+	}
+	return nil
+}
+
+// Events returns the events of the thread recorded at or after the given time, oldest first.
+func (s *EventStore[T]) Events(ctx context.Context, threadID string, since time.Time) ([]g.Event[T], error) {
+	var sinceNanos int64
+	if !since.IsZero() {
+		sinceNanos = since.UTC().UnixNano()
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT seq, event FROM "+s.opts.EventTableName+" WHERE thread_id = ? AND recorded_at >= ? ORDER BY seq", threadID, sinceNanos)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read events of thread %s: %w", threadID, err)
+	}
+	defer rows.Close()
+
+	rv := make([]g.Event[T], 0)
+	for rows.Next() {
+		var seq int64
+		var data []byte
+		if err := rows.Scan(&seq, &data); err != nil {
+			return nil, fmt.Errorf("cannot read events of thread %s: %w", threadID, err)
+		}
+
+		var event g.Event[T]
+		if err := json.Unmarshal(data, &event); err != nil {
+			return nil, fmt.Errorf("cannot deserialize event of thread %s: %w", threadID, err)
+		}
+		event.Seq = uint64(seq)
+		rv = append(rv, event)
+	}
+
+	return rv, rows.Err()
+}
+
+func (s *EventStore[T]) migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx,
+		"CREATE TABLE IF NOT EXISTS "+s.opts.EventTableName+" ("+
+			"seq INTEGER PRIMARY KEY AUTOINCREMENT, "+
+			"thread_id TEXT NOT NULL, "+
+			"event BLOB NOT NULL, "+
+			"recorded_at INTEGER NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("cannot create table %s: %w", s.opts.EventTableName, err)
+	}
+
+	_, err = s.db.ExecContext(ctx,
+		"CREATE INDEX IF NOT EXISTS "+s.opts.EventTableName+"_thread_idx ON "+s.opts.EventTableName+" (thread_id, recorded_at)")
+	if err != nil {
+		return fmt.Errorf("cannot create index on %s: %w", s.opts.EventTableName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// NewJournal creates a Journal storing one JSON serialized row per invocation step.
+//
+// Parameters:
+//   - db: An open SQLite database handle.
+//   - opts: Optional configuration options, only WithJournalTableName and WithoutMigration apply.
+//
+// Returns:
+//   - The SQLite Journal implementation.
+//   - An error if the options are invalid or the schema cannot be created.
+//
+// Example:
+//
+//	journal, err := sqlite.NewJournal[MyState](db)
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, g.WithJournal[MyState](journal))
+func NewJournal[T g.SharedState](db *sql.DB, opts ...Option) (*Journal[T], error) {
+	if db == nil {
+		return nil, fmt.Errorf("sqlite journal creation failed: %w", ErrDBNil)
+	}
+
+	useOpts := &Options{
+		JournalTableName: DefaultJournalTableName,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("sqlite journal creation failed: %w", err)
+		}
+	}
+
+	rv := &Journal[T]{
+		db:   db,
+		opts: *useOpts,
+	}
+
+	if !useOpts.SkipMigration {
+		if err := rv.migrate(context.Background()); err != nil {
+			return nil, fmt.Errorf("sqlite journal creation failed: %w", err)
+		}
+	}
+
+	return rv, nil
+}
+
+// ------------------------------------------------------------------------------
+// SQLite Journal Implementation
+// ------------------------------------------------------------------------------
+
+var _ g.Journal[g.SharedState] = (*Journal[g.SharedState])(nil)
+
+// Journal is a Journal implementation backed by SQLite.
+type Journal[T g.SharedState] struct {
+	db   *sql.DB
+	opts Options
+}
+
+// Append records the step, replacing a step of the thread with the same position.
+func (j *Journal[T]) Append(ctx context.Context, entry g.JournalEntry[T]) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("cannot serialize step %d of thread %s: %w", entry.Step, entry.ThreadID, err)
+	}
+
+	_, err = j.db.ExecContext(ctx,
+		"INSERT OR REPLACE INTO "+j.opts.JournalTableName+" (thread_id, step, entry) VALUES (?, ?, ?)",
+		entry.ThreadID, entry.Step, data)
+	if err != nil {
+		return fmt.Errorf("cannot append step %d of thread %s: %w", entry.Step, entry.ThreadID, err)
+	}
+	return nil
+}
+
+// Entries returns the steps of the thread, in order.
+func (j *Journal[T]) Entries(ctx context.Context, threadID string) ([]g.JournalEntry[T], error) {
+	rows, err := j.db.QueryContext(ctx,
+		"SELECT entry FROM "+j.opts.JournalTableName+" WHERE thread_id = ? ORDER BY step", threadID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read journal of thread %s: %w", threadID, err)
+	}
+	defer rows.Close()
+
+	rv := make([]g.JournalEntry[T], 0)
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("cannot read journal of thread %s: %w", threadID, err)
+		}
+
+		var entry g.JournalEntry[T]
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return nil, fmt.Errorf("cannot deserialize journal of thread %s: %w", threadID, err)
+		}
+		rv = append(rv, entry)
+	}
+
+	return rv, rows.Err()
+}
+
+// Clear deletes the steps of the thread.
+func (j *Journal[T]) Clear(ctx context.Context, threadID string) error {
+	if _, err := j.db.ExecContext(ctx, "DELETE FROM "+j.opts.JournalTableName+" WHERE thread_id = ?", threadID); err != nil {
+		return fmt.Errorf("cannot clear journal of thread %s: %w", threadID, err)
+	}
+	return nil
+}
+
+func (j *Journal[T]) migrate(ctx context.Context) error {
+	_, err := j.db.ExecContext(ctx,
+		"CREATE TABLE IF NOT EXISTS "+j.opts.JournalTableName+" ("+
+			"thread_id TEXT NOT NULL, "+
+			"step INTEGER NOT NULL, "+
+			"entry BLOB NOT NULL, "+
+			"PRIMARY KEY (thread_id, step))")
+	if err != nil {
+		return fmt.Errorf("cannot create table %s: %w", j.opts.JournalTableName, err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// NewLeaseStore creates a LeaseStore keeping one row per owned thread.
+//
+// Parameters:
+//   - db: An open SQLite database handle, shared by the runtime instances.
+//   - opts: Optional configuration options, only WithLeaseTableName and WithoutMigration apply.
+//
+// Returns:
+//   - The SQLite LeaseStore implementation.
+//   - An error if the options are invalid or the schema cannot be created.
+//
+// Example:
+//
+//	leases, err := sqlite.NewLeaseStore(db)
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, g.WithMemory[MyState](memory), g.WithThreadLeases[MyState](leases, 30*time.Second))
+func NewLeaseStore(db *sql.DB, opts ...Option) (*LeaseStore, error) {
+	if db == nil {
+		return nil, fmt.Errorf("sqlite lease store creation failed: %w", ErrDBNil)
+	}
+
+	useOpts := &Options{
+		LeaseTableName: DefaultLeaseTableName,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("sqlite lease store creation failed: %w", err)
+		}
+	}
+
+	rv := &LeaseStore{
+		db:   db,
+		opts: *useOpts,
+	}
+
+	if !useOpts.SkipMigration {
+		if err := rv.migrate(context.Background()); err != nil {
+			return nil, fmt.Errorf("sqlite lease store creation failed: %w", err)
+		}
+	}
+
+	return rv, nil
+}
+
+// ------------------------------------------------------------------------------
+// SQLite LeaseStore Implementation
+// ------------------------------------------------------------------------------
+
+var _ g.LeaseStore = (*LeaseStore)(nil)
+
+// LeaseStore is a LeaseStore implementation backed by SQLite.
+type LeaseStore struct {
+	db   *sql.DB
+	opts Options
+}
+
+// Acquire inserts the lease of the thread, or takes over the row when owned by the owner or expired.
+func (s *LeaseStore) Acquire(ctx context.Context, threadID, owner string, ttl time.Duration) (bool, error) {
+	now := time.Now().UTC()
+	result, err := s.db.ExecContext(ctx,
+		"INSERT INTO "+s.opts.LeaseTableName+" (thread_id, owner, expires_at) VALUES (?, ?, ?) "+
+			"ON CONFLICT(thread_id) DO UPDATE SET owner = excluded.owner, expires_at = excluded.expires_at "+
+			"WHERE "+s.opts.LeaseTableName+".owner = excluded.owner OR "+s.opts.LeaseTableName+".expires_at <= ?",
+		threadID, owner, now.Add(ttl).UnixNano(), now.UnixNano())
+	if err != nil {
+		return false, fmt.Errorf("cannot acquire the lease of thread %s: %w", threadID, err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("cannot acquire the lease of thread %s: %w", threadID, err)
+	}
+	return affected > 0, nil
+}
+
+// Release deletes the lease of the thread, if held by the owner.
+func (s *LeaseStore) Release(ctx context.Context, threadID, owner string) error {
+	_, err := s.db.ExecContext(ctx,
+		"DELETE FROM "+s.opts.LeaseTableName+" WHERE thread_id = ? AND owner = ?", threadID, owner)
+	if err != nil {
+		return fmt.Errorf("cannot release the lease of thread %s: %w", threadID, err)
+	}
+	return nil
+}
+
+func (s *LeaseStore) migrate(ctx context.Context) error {
+	_, err := s.db.ExecContext(ctx,
+		"CREATE TABLE IF NOT EXISTS "+s.opts.LeaseTableName+" ("+
+			"thread_id TEXT PRIMARY KEY, "+
+			"owner TEXT NOT NULL, "+
+			"expires_at INTEGER NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("cannot create table %s: %w", s.opts.LeaseTableName, err)
+	}
+	return nil
+}
@@ -0,0 +1,353 @@
+// Package sqlite provides a Memory and an EventStore implementation backed by an embedded SQLite database.
+//
+// The package registers the CGO-free modernc.org/sqlite driver as DriverName: open the
+// database with it and hand the *sql.DB over to NewMemory.
+//
+// Example:
+//
+//	db, _ := sql.Open(sqlite.DriverName, "file:threads.db?_pragma=journal_mode(WAL)")
+//	memory, err := sqlite.NewMemory[MyState](db, sqlite.WithHistory(""))
+//	runtime, err := builders.CreateRuntime(startEdge, stateMonitorCh, g.WithMemory[MyState](memory))
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// HistoryEntry is a single state recorded in the history table.
+type HistoryEntry[T g.SharedState] struct {
+	// Seq is the monotonically increasing sequence of the entry.
+	Seq int64
+	// ThreadID is the thread the state belongs to.
+	ThreadID string
+	// State is the persisted state.
+	State T
+	// PersistedAt is the time the state was persisted.
+	PersistedAt time.Time
+}
+
+// NewMemory creates a Memory implementation storing one JSON serialized row per thread.
+//
+// Parameters:
+//   - db: An open SQLite database handle.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - The SQLite Memory implementation.
+//   - An error if the options are invalid or the schema cannot be created.
+//
+// Example:
+//
+//	memory, err := sqlite.NewMemory[MyState](db)
+func NewMemory[T g.SharedState](db *sql.DB, opts ...Option) (*Memory[T], error) {
+	if db == nil {
+		return nil, fmt.Errorf("sqlite memory creation failed: %w", ErrDBNil)
+	}
+
+	useOpts := &Options{
+		TableName:        DefaultTableName,
+		HistoryTableName: DefaultHistoryTableName,
+		OnceTableName:    DefaultOnceTableName,
+		ScratchTableName: DefaultScratchTableName,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("sqlite memory creation failed: %w", err)
+		}
+	}
+
+	rv := &Memory[T]{
+		db:   db,
+		opts: *useOpts,
+	}
+
+	if !useOpts.SkipMigration {
+		if err := rv.migrate(context.Background()); err != nil {
+			return nil, fmt.Errorf("sqlite memory creation failed: %w", err)
+		}
+	}
+
+	return rv, nil
+}
+
+// ------------------------------------------------------------------------------
+// SQLite Memory Implementation
+// ------------------------------------------------------------------------------
+
+var _ g.Memory[g.SharedState] = (*Memory[g.SharedState])(nil)
+var _ g.OnceStore = (*Memory[g.SharedState])(nil)
+var _ g.ScratchStore = (*Memory[g.SharedState])(nil)
+var _ g.StateDeleter = (*Memory[g.SharedState])(nil)
+
+// Memory is a Memory implementation backed by SQLite.
+type Memory[T g.SharedState] struct {
+	db   *sql.DB
+	opts Options
+}
+
+// PersistFn returns a function to persist the shared state.
+func (m *Memory[T]) PersistFn() g.PersistFn[T] {
+	return func(ctx context.Context, threadID string, state T) error {
+		data, err := json.Marshal(state)
+		if err != nil {
+			return fmt.Errorf("cannot serialize state of thread %s: %w", threadID, err)
+		}
+
+		tx, err := m.db.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("cannot persist state of thread %s: %w", threadID, err)
+		}
+		defer tx.Rollback()
+
+		now := time.Now().UTC().UnixNano()
+
+		if m.opts.History {
+			_, err = tx.ExecContext(ctx,
+				"INSERT INTO "+m.opts.HistoryTableName+" (thread_id, state, persisted_at) VALUES (?, ?, ?)",
+				threadID, data, now)
+			if err != nil {
+				return fmt.Errorf("cannot append history of thread %s: %w", threadID, err)
+			}
+		}
+
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO "+m.opts.TableName+" (thread_id, state, created_at, updated_at) VALUES (?, ?, ?, ?) "+
+				"ON CONFLICT(thread_id) DO UPDATE SET state = excluded.state, updated_at = excluded.updated_at",
+			threadID, data, now, now)
+		if err != nil {
+			return fmt.Errorf("cannot persist state of thread %s: %w", threadID, err)
+		}
+
+		return tx.Commit()
+	}
+}
+
+// RestoreFn returns a function to restore the shared state.
+//
+// Restoring an unknown thread returns the zero value of T without error.
+func (m *Memory[T]) RestoreFn() g.RestoreFn[T] {
+	return func(ctx context.Context, threadID string) (T, error) {
+		var zero T
+
+		var data []byte
+		err := m.db.QueryRowContext(ctx,
+			"SELECT state FROM "+m.opts.TableName+" WHERE thread_id = ?", threadID).Scan(&data)
+		if errors.Is(err, sql.ErrNoRows) {
+			return zero, nil
+		}
+		if err != nil {
+			return zero, fmt.Errorf("cannot restore state of thread %s: %w", threadID, err)
+		}
+
+		var state T
+		if err := json.Unmarshal(data, &state); err != nil {
+			return zero, fmt.Errorf("cannot deserialize state of thread %s: %w", threadID, err)
+		}
+		return state, nil
+	}
+}
+
+// Delete removes the thread row, its recorded side effects and, when enabled, its history.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control.
+//   - threadID: The thread to remove.
+//
+// Returns:
+//   - An error if the deletion fails.
+func (m *Memory[T]) Delete(ctx context.Context, threadID string) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("cannot delete thread %s: %w", threadID, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+m.opts.TableName+" WHERE thread_id = ?", threadID); err != nil {
+		return fmt.Errorf("cannot delete thread %s: %w", threadID, err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+m.opts.OnceTableName+" WHERE thread_id = ?", threadID); err != nil {
+		return fmt.Errorf("cannot delete side effects of thread %s: %w", threadID, err)
+	}
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+m.opts.ScratchTableName+" WHERE thread_id = ?", threadID); err != nil {
+		return fmt.Errorf("cannot delete scratchpad of thread %s: %w", threadID, err)
+	}
+	if m.opts.History {
+		if _, err := tx.ExecContext(ctx, "DELETE FROM "+m.opts.HistoryTableName+" WHERE thread_id = ?", threadID); err != nil {
+			return fmt.Errorf("cannot delete history of thread %s: %w", threadID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// History returns the persisted states of a thread, oldest first.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control.
+//   - threadID: The thread whose history is requested.
+//
+// Returns:
+//   - The history entries; empty when the history table is disabled.
+//   - An error if the query fails.
+func (m *Memory[T]) History(ctx context.Context, threadID string) ([]HistoryEntry[T], error) {
+	if !m.opts.History {
+		return []HistoryEntry[T]{}, nil
+	}
+
+	rows, err := m.db.QueryContext(ctx,
+		"SELECT seq, state, persisted_at FROM "+m.opts.HistoryTableName+" WHERE thread_id = ? ORDER BY seq", threadID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read history of thread %s: %w", threadID, err)
+	}
+	defer rows.Close()
+
+	rv := make([]HistoryEntry[T], 0)
+	for rows.Next() {
+		var seq, persistedAt int64
+		var data []byte
+		if err := rows.Scan(&seq, &data, &persistedAt); err != nil {
+			return nil, fmt.Errorf("cannot read history of thread %s: %w", threadID, err)
+		}
+
+		var state T
+		if err := json.Unmarshal(data, &state); err != nil {
+			return nil, fmt.Errorf("cannot deserialize history of thread %s: %w", threadID, err)
+		}
+
+		rv = append(rv, HistoryEntry[T]{
+			Seq:         seq,
+			ThreadID:    threadID,
+			State:       state,
+			PersistedAt: time.Unix(0, persistedAt).UTC(),
+		})
+	}
+
+	return rv, rows.Err()
+}
+
+// ClaimOnce records the side effect of the thread, the insertion of a recorded one is ignored.
+func (m *Memory[T]) ClaimOnce(ctx context.Context, threadID, key string) (bool, error) {
+	result, err := m.db.ExecContext(ctx,
+		"INSERT OR IGNORE INTO "+m.opts.OnceTableName+" (thread_id, key, claimed_at) VALUES (?, ?, ?)",
+		threadID, key, time.Now().UTC().UnixNano())
+	if err != nil {
+		return false, fmt.Errorf("cannot record side effect %s of thread %s: %w", key, threadID, err)
+	}
+	inserted, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("cannot record side effect %s of thread %s: %w", key, threadID, err)
+	}
+	return inserted == 1, nil
+}
+
+// ReleaseOnce deletes the side effect of the thread.
+func (m *Memory[T]) ReleaseOnce(ctx context.Context, threadID, key string) error {
+	if _, err := m.db.ExecContext(ctx, "DELETE FROM "+m.opts.OnceTableName+" WHERE thread_id = ? AND key = ?", threadID, key); err != nil {
+		return fmt.Errorf("cannot forget side effect %s of thread %s: %w", key, threadID, err)
+	}
+	return nil
+}
+
+// SaveScratch upserts the scratchpad entry of the thread.
+func (m *Memory[T]) SaveScratch(ctx context.Context, threadID, key string, value []byte) error {
+	_, err := m.db.ExecContext(ctx,
+		"INSERT INTO "+m.opts.ScratchTableName+" (thread_id, key, value, updated_at) VALUES (?, ?, ?, ?) "+
+			"ON CONFLICT(thread_id, key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at",
+		threadID, key, value, time.Now().UTC().UnixNano())
+	if err != nil {
+		return fmt.Errorf("cannot save scratchpad entry %s of thread %s: %w", key, threadID, err)
+	}
+	return nil
+}
+
+// LoadScratch reads the scratchpad entries of the thread.
+func (m *Memory[T]) LoadScratch(ctx context.Context, threadID string) (map[string][]byte, error) {
+	rows, err := m.db.QueryContext(ctx, "SELECT key, value FROM "+m.opts.ScratchTableName+" WHERE thread_id = ?", threadID)
+	if err != nil {
+		return nil, fmt.Errorf("cannot load scratchpad of thread %s: %w", threadID, err)
+	}
+	defer rows.Close()
+
+	rv := make(map[string][]byte)
+	for rows.Next() {
+		var key string
+		var value []byte
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, fmt.Errorf("cannot load scratchpad of thread %s: %w", threadID, err)
+		}
+		rv[key] = value
+	}
+	return rv, rows.Err()
+}
+
+// DeleteScratch deletes the scratchpad entry of the thread.
+func (m *Memory[T]) DeleteScratch(ctx context.Context, threadID, key string) error {
+	if _, err := m.db.ExecContext(ctx, "DELETE FROM "+m.opts.ScratchTableName+" WHERE thread_id = ? AND key = ?", threadID, key); err != nil {
+		return fmt.Errorf("cannot delete scratchpad entry %s of thread %s: %w", key, threadID, err)
+	}
+	return nil
+}
+
+func (m *Memory[T]) migrate(ctx context.Context) error {
+	_, err := m.db.ExecContext(ctx,
+		"CREATE TABLE IF NOT EXISTS "+m.opts.TableName+" ("+
+			"thread_id TEXT PRIMARY KEY, "+
+			"state BLOB NOT NULL, "+
+			"created_at INTEGER NOT NULL, "+
+			"updated_at INTEGER NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("cannot create table %s: %w", m.opts.TableName, err)
+	}
+
+	_, err = m.db.ExecContext(ctx,
+		"CREATE TABLE IF NOT EXISTS "+m.opts.OnceTableName+" ("+
+			"thread_id TEXT NOT NULL, "+
+			"key TEXT NOT NULL, "+
+			"claimed_at INTEGER NOT NULL, "+
+			"PRIMARY KEY (thread_id, key))")
+	if err != nil {
+		return fmt.Errorf("cannot create table %s: %w", m.opts.OnceTableName, err)
+	}
+
+	_, err = m.db.ExecContext(ctx,
+		"CREATE TABLE IF NOT EXISTS "+m.opts.ScratchTableName+" ("+
+			"thread_id TEXT NOT NULL, "+
+			"key TEXT NOT NULL, "+
+			"value BLOB NOT NULL, "+
+			"updated_at INTEGER NOT NULL, "+
+			"PRIMARY KEY (thread_id, key))")
+	if err != nil {
+		return fmt.Errorf("cannot create table %s: %w", m.opts.ScratchTableName, err)
+	}
+
+	if !m.opts.History {
+		return nil
+	}
+
+	_, err = m.db.ExecContext(ctx,
+		"CREATE TABLE IF NOT EXISTS "+m.opts.HistoryTableName+" ("+
+			"seq INTEGER PRIMARY KEY AUTOINCREMENT, "+
+			"thread_id TEXT NOT NULL, "+
+			"state BLOB NOT NULL, "+
+			"persisted_at INTEGER NOT NULL)")
+	if err != nil {
+		return fmt.Errorf("cannot create table %s: %w", m.opts.HistoryTableName, err)
+	}
+
+	_, err = m.db.ExecContext(ctx,
+		"CREATE INDEX IF NOT EXISTS "+m.opts.HistoryTableName+"_thread_idx ON "+m.opts.HistoryTableName+" (thread_id, seq)")
+	if err != nil {
+		return fmt.Errorf("cannot create index on %s: %w", m.opts.HistoryTableName, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,220 @@
+package sqlite_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/memory/sqlite"
+)
+
+// openDB opens an in-memory database, a single connection keeps it alive across the statements.
+func openDB(t *testing.T) *sql.DB {
+	db, err := sql.Open(sqlite.DriverName, ":memory:")
+	if err != nil {
+		t.Fatalf("sql.Open failed: %v", err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestMemory(t *testing.T) {
+	memory, err := sqlite.NewMemory[testState](openDB(t), sqlite.WithHistory(""))
+	if err != nil {
+		t.Fatalf("NewMemory failed: %v", err)
+	}
+	ctx := context.Background()
+
+	if state, err := memory.RestoreFn()(ctx, "unknown"); err != nil || state != (testState{}) {
+		t.Errorf("Expected the zero state for an unknown thread, got %+v %v", state, err)
+	}
+
+	startedAt := time.Now().UTC()
+	for _, value := range []string{"first", "second"} {
+		if err := memory.PersistFn()(ctx, "thread-1", testState{Value: value}); err != nil {
+			t.Fatalf("PersistFn failed: %v", err)
+		}
+	}
+	if err := memory.PersistFn()(ctx, "thread-2", testState{Value: "other"}); err != nil {
+		t.Fatalf("PersistFn failed: %v", err)
+	}
+	if state, err := memory.RestoreFn()(ctx, "thread-1"); err != nil || state.Value != "second" {
+		t.Errorf("Expected the latest state, got %+v %v", state, err)
+	}
+
+	history, err := memory.History(ctx, "thread-1")
+	if err != nil || len(history) != 2 {
+		t.Fatalf("Expected 2 history entries, got %+v %v", history, err)
+	}
+	if history[0].State.Value != "first" || history[1].State.Value != "second" || history[0].Seq >= history[1].Seq {
+		t.Errorf("Expected the history oldest first, got %+v", history)
+	}
+	if history[0].ThreadID != "thread-1" || history[0].PersistedAt.Before(startedAt.Add(-time.Second)) {
+		t.Errorf("Unexpected history entry %+v", history[0])
+	}
+
+	if claimed, err := memory.ClaimOnce(ctx, "thread-1", "email"); err != nil || !claimed {
+		t.Errorf("Expected the side effect to be claimed, got %t %v", claimed, err)
+	}
+	if claimed, _ := memory.ClaimOnce(ctx, "thread-1", "email"); claimed {
+		t.Error("Expected a recorded side effect not to be claimed twice")
+	}
+	if err := memory.ReleaseOnce(ctx, "thread-1", "email"); err != nil {
+		t.Fatalf("ReleaseOnce failed: %v", err)
+	}
+	if claimed, _ := memory.ClaimOnce(ctx, "thread-1", "email"); !claimed {
+		t.Error("Expected a released side effect to be claimed again")
+	}
+
+	for _, value := range []string{"draft", "final"} {
+		if err := memory.SaveScratch(ctx, "thread-1", "notes", []byte(value)); err != nil {
+			t.Fatalf("SaveScratch failed: %v", err)
+		}
+	}
+	if err := memory.SaveScratch(ctx, "thread-1", "plan", []byte("plan")); err != nil {
+		t.Fatalf("SaveScratch failed: %v", err)
+	}
+	if err := memory.DeleteScratch(ctx, "thread-1", "plan"); err != nil {
+		t.Fatalf("DeleteScratch failed: %v", err)
+	}
+	if scratch, err := memory.LoadScratch(ctx, "thread-1"); err != nil || len(scratch) != 1 || string(scratch["notes"]) != "final" {
+		t.Errorf("Expected the latest scratchpad entry, got %v %v", scratch, err)
+	}
+
+	if err := memory.Delete(ctx, "thread-1"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	if state, _ := memory.RestoreFn()(ctx, "thread-1"); state != (testState{}) {
+		t.Errorf("Expected the deleted thread to restore the zero state, got %+v", state)
+	}
+	if history, _ := memory.History(ctx, "thread-1"); len(history) != 0 {
+		t.Errorf("Expected the history to be deleted, got %+v", history)
+	}
+	if scratch, _ := memory.LoadScratch(ctx, "thread-1"); len(scratch) != 0 {
+		t.Errorf("Expected the scratchpad to be deleted, got %v", scratch)
+	}
+	if claimed, _ := memory.ClaimOnce(ctx, "thread-1", "email"); !claimed {
+		t.Error("Expected the side effects to be deleted")
+	}
+	if state, _ := memory.RestoreFn()(ctx, "thread-2"); state.Value != "other" {
+		t.Errorf("Expected the other thread to be kept, got %+v", state)
+	}
+}
+
+func TestMemory_WithoutHistory(t *testing.T) {
+	memory, err := sqlite.NewMemory[testState](openDB(t))
+	if err != nil {
+		t.Fatalf("NewMemory failed: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := memory.PersistFn()(ctx, "thread-1", testState{Value: "first"}); err != nil {
+		t.Fatalf("PersistFn failed: %v", err)
+	}
+	if history, err := memory.History(ctx, "thread-1"); err != nil || len(history) != 0 {
+		t.Errorf("Expected no history, got %+v %v", history, err)
+	}
+	if err := memory.Delete(ctx, "thread-1"); err != nil {
+		t.Errorf("Expected the deletion to skip the missing history table, got %v", err)
+	}
+}
+
+func TestLeaseStore(t *testing.T) {
+	leases, err := sqlite.NewLeaseStore(openDB(t))
+	if err != nil {
+		t.Fatalf("NewLeaseStore failed: %v", err)
+	}
+	ctx := context.Background()
+
+	if acquired, err := leases.Acquire(ctx, "thread-1", "instance-a", time.Hour); err != nil || !acquired {
+		t.Fatalf("Expected the lease to be acquired, got %t %v", acquired, err)
+	}
+	if acquired, _ := leases.Acquire(ctx, "thread-1", "instance-b", time.Hour); acquired {
+		t.Error("Expected a held lease not to be acquired by another owner")
+	}
+	if acquired, _ := leases.Acquire(ctx, "thread-1", "instance-a", time.Hour); !acquired {
+		t.Error("Expected the owner to renew its lease")
+	}
+
+	if err := leases.Release(ctx, "thread-1", "instance-b"); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if acquired, _ := leases.Acquire(ctx, "thread-1", "instance-b", time.Hour); acquired {
+		t.Error("Expected the release of another owner to be ignored")
+	}
+	if err := leases.Release(ctx, "thread-1", "instance-a"); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if acquired, _ := leases.Acquire(ctx, "thread-1", "instance-b", time.Millisecond); !acquired {
+		t.Error("Expected a released lease to be acquired")
+	}
+
+	time.Sleep(5 * time.Millisecond)
+	if acquired, _ := leases.Acquire(ctx, "thread-1", "instance-a", time.Hour); !acquired {
+		t.Error("Expected an expired lease to be taken over")
+	}
+}
+
+func TestEventStore(t *testing.T) {
+	store, err := sqlite.NewEventStore[testState](openDB(t))
+	if err != nil {
+		t.Fatalf("NewEventStore failed: %v", err)
+	}
+	ctx := context.Background()
+
+	startedAt := time.Now()
+	events := []g.Event[testState]{
+		{ThreadID: "thread-1", Node: "Start", Timestamp: startedAt.Add(-time.Minute), State: testState{Value: "old"}, Running: true},
+		{ThreadID: "thread-1", Node: "Node1", Timestamp: startedAt, State: testState{Value: "new"}, Running: true},
+		{ThreadID: "thread-2", Node: "Node1", Timestamp: startedAt, State: testState{Value: "other"}},
+		{ThreadID: "thread-1", Node: "End", Timestamp: startedAt.Add(time.Second), Error: "failure", ErrorCode: g.CodeNodeFailed},
+	}
+	for _, event := range events {
+		if err := store.Append(ctx, event); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	got, err := store.Events(ctx, "thread-1", startedAt)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("Expected the 2 events since the given time, got %+v %v", got, err)
+	}
+	if got[0].Node != "Node1" || got[0].State.Value != "new" || got[1].ErrorCode != g.CodeNodeFailed || got[0].Seq >= got[1].Seq {
+		t.Errorf("Expected the events in order with their sequence, got %+v", got)
+	}
+	if all, _ := store.Events(ctx, "thread-1", time.Time{}); len(all) != 3 {
+		t.Errorf("Expected all the events of the thread, got %+v", all)
+	}
+}
+
+func TestJournal(t *testing.T) {
+	journal, err := sqlite.NewJournal[testState](openDB(t))
+	if err != nil {
+		t.Fatalf("NewJournal failed: %v", err)
+	}
+	ctx := context.Background()
+
+	for _, entry := range []g.JournalEntry[testState]{
+		{ThreadID: "thread-1", Step: 2, Node: "Node1", Next: "End", State: testState{Value: "second"}},
+		{ThreadID: "thread-1", Step: 1, Node: "Start", Next: "Node1", State: testState{Value: "first"}},
+		{ThreadID: "thread-1", Step: 2, Node: "Node1", Next: "End", State: testState{Value: "replaced"}},
+	} {
+		if err := journal.Append(ctx, entry); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+
+	entries, err := journal.Entries(ctx, "thread-1")
+	if err != nil || len(entries) != 2 || entries[0].Step != 1 || entries[1].State.Value != "replaced" {
+		t.Errorf("Expected the steps in order with the replaced one, got %+v %v", entries, err)
+	}
+	if err := journal.Clear(ctx, "thread-1"); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	if entries, _ := journal.Entries(ctx, "thread-1"); len(entries) != 0 {
+		t.Errorf("Expected the journal to be cleared, got %+v", entries)
+	}
+}
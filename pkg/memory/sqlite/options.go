@@ -0,0 +1,243 @@
+package sqlite
+
+import (
+	"errors"
+
+	"github.com/morphy76/ggraph/internal/sqlident"
+)
+
+const (
+	// DriverName is the name of the CGO-free SQLite driver registered by the package.
+	DriverName = "sqlite"
+	// DefaultTableName is the default name of the table holding the thread states.
+	DefaultTableName = "ggraph_threads"
+	// DefaultHistoryTableName is the default name of the table holding the state history.
+	DefaultHistoryTableName = "ggraph_threads_history"
+	// DefaultOnceTableName is the default name of the table holding the side effects executed by the threads.
+	DefaultOnceTableName = "ggraph_threads_once"
+	// DefaultScratchTableName is the default name of the table holding the scratchpads of the threads.
+	DefaultScratchTableName = "ggraph_threads_scratch"
+	// DefaultEventTableName is the default name of the table holding the events of the EventStore.
+	DefaultEventTableName = "ggraph_events"
+	// DefaultLeaseTableName is the default name of the table holding the thread leases of the LeaseStore.
+	DefaultLeaseTableName = "ggraph_leases"
+	// DefaultJournalTableName is the default name of the table holding the invocation steps of the Journal.
+	DefaultJournalTableName = "ggraph_journal"
+)
+
+var (
+	// ErrDBNil indicates that the provided database handle is nil.
+	ErrDBNil = errors.New("database handle cannot be nil")
+	// ErrInvalidTableName indicates that the provided table name is not a valid SQL identifier.
+	ErrInvalidTableName = errors.New("invalid table name")
+)
+
+// Options holds the configuration for the SQLite Memory implementation.
+type Options struct {
+	// TableName is the name of the table holding one row per thread.
+	TableName string
+	// HistoryTableName is the name of the append-only table holding every persisted state.
+	HistoryTableName string
+	// History enables the write-ahead history table.
+	History bool
+	// OnceTableName is the name of the table recording the side effects executed with graph.Once.
+	OnceTableName string
+	// ScratchTableName is the name of the table holding the scratchpad entries set with graph.Scratchpad.Set.
+	ScratchTableName string
+	// EventTableName is the name of the append-only table of the EventStore.
+	EventTableName string
+	// LeaseTableName is the name of the table of the LeaseStore.
+	LeaseTableName string
+	// JournalTableName is the name of the table of the Journal.
+	JournalTableName string
+	// SkipMigration disables the automatic creation of the tables.
+	SkipMigration bool
+}
+
+// Option is a functional option for configuring the SQLite Memory implementation.
+type Option interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - r: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *Options) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc func(*Options) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - r: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc) Apply(r *Options) error { return s(r) }
+
+// WithTableName sets the name of the table holding the thread states.
+//
+// Parameters:
+//   - name: The table name, it must be a valid SQL identifier.
+//
+// Returns:
+//   - An Option that sets the table name.
+//
+// Example:
+//
+//	memory, err := sqlite.NewMemory[MyState](db, sqlite.WithTableName("my_threads"))
+func WithTableName(name string) Option {
+	return OptionFunc(func(r *Options) error {
+		if !sqlident.Valid(name) {
+			return ErrInvalidTableName
+		}
+		r.TableName = name
+		return nil
+	})
+}
+
+// WithHistory enables the write-ahead history table.
+//
+// Every persisted state is appended to the history table before the thread row
+// is updated, making it possible to inspect how a thread evolved over time.
+//
+// Parameters:
+//   - tableName: The history table name; when empty DefaultHistoryTableName is used.
+//
+// Returns:
+//   - An Option that enables the history table.
+//
+// Example:
+//
+//	memory, err := sqlite.NewMemory[MyState](db, sqlite.WithHistory(""))
+func WithHistory(tableName string) Option {
+	return OptionFunc(func(r *Options) error {
+		if tableName != "" {
+			if !sqlident.Valid(tableName) {
+				return ErrInvalidTableName
+			}
+			r.HistoryTableName = tableName
+		}
+		r.History = true
+		return nil
+	})
+}
+
+// WithEventTableName sets the name of the table holding the events of the EventStore.
+//
+// Parameters:
+//   - name: The table name, it must be a valid SQL identifier.
+//
+// Returns:
+//   - An Option that sets the event table name.
+//
+// Example:
+//
+//	store, err := sqlite.NewEventStore[MyState](db, sqlite.WithEventTableName("audit"))
+func WithEventTableName(name string) Option {
+	return OptionFunc(func(r *Options) error {
+		if !sqlident.Valid(name) {
+			return ErrInvalidTableName
+		}
+		r.EventTableName = name
+		return nil
+	})
+}
+
+// WithLeaseTableName sets the name of the table holding the thread leases of the LeaseStore.
+//
+// Parameters:
+//   - name: The table name, it must be a valid SQL identifier.
+//
+// Returns:
+//   - An Option that sets the lease table name.
+//
+// Example:
+//
+//	leases, err := sqlite.NewLeaseStore(db, sqlite.WithLeaseTableName("leases"))
+func WithLeaseTableName(name string) Option {
+	return OptionFunc(func(r *Options) error {
+		if !sqlident.Valid(name) {
+			return ErrInvalidTableName
+		}
+		r.LeaseTableName = name
+		return nil
+	})
+}
+
+// WithOnceTableName sets the name of the table recording the side effects executed by the threads.
+//
+// Parameters:
+//   - name: The table name, it must be a valid SQL identifier.
+//
+// Returns:
+//   - An Option that sets the side effect table name.
+//
+// Example:
+//
+//	memory, err := sqlite.NewMemory[MyState](db, sqlite.WithOnceTableName("side_effects"))
+func WithOnceTableName(name string) Option {
+	return OptionFunc(func(r *Options) error {
+		if !sqlident.Valid(name) {
+			return ErrInvalidTableName
+		}
+		r.OnceTableName = name
+		return nil
+	})
+}
+
+// WithScratchTableName sets the name of the table holding the scratchpads of the threads.
+//
+// Parameters:
+//   - name: The table name, it must be a valid SQL identifier.
+//
+// Returns:
+//   - An Option that sets the scratchpad table name.
+//
+// Example:
+//
+//	memory, err := sqlite.NewMemory[MyState](db, sqlite.WithScratchTableName("scratch"))
+func WithScratchTableName(name string) Option {
+	return OptionFunc(func(r *Options) error {
+		if !sqlident.Valid(name) {
+			return ErrInvalidTableName
+		}
+		r.ScratchTableName = name
+		return nil
+	})
+}
+
+// WithJournalTableName sets the name of the table holding the invocation steps of the Journal.
+//
+// Parameters:
+//   - name: The table name, it must be a valid SQL identifier.
+//
+// Returns:
+//   - An Option that sets the journal table name.
+//
+// Example:
+//
+//	journal, err := sqlite.NewJournal[MyState](db, sqlite.WithJournalTableName("steps"))
+func WithJournalTableName(name string) Option {
+	return OptionFunc(func(r *Options) error {
+		if !sqlident.Valid(name) {
+			return ErrInvalidTableName
+		}
+		r.JournalTableName = name
+		return nil
+	})
+}
+
+// WithoutMigration disables the automatic creation of the tables.
+//
+// Returns:
+//   - An Option that disables the schema migration.
+func WithoutMigration() Option {
+	return OptionFunc(func(r *Options) error {
+		r.SkipMigration = true
+		return nil
+	})
+}
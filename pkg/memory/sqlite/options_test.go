@@ -0,0 +1,211 @@
+package sqlite_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/morphy76/ggraph/pkg/memory/sqlite"
+)
+
+type testState struct {
+	Value string
+}
+
+func TestNewMemory_NilDB(t *testing.T) {
+	memory, err := sqlite.NewMemory[testState](nil)
+	if err == nil {
+		t.Fatal("Expected error when creating memory with nil database, got nil")
+	}
+	if memory != nil {
+		t.Error("Expected nil memory when database is nil")
+	}
+	if !errors.Is(err, sqlite.ErrDBNil) {
+		t.Errorf("Expected ErrDBNil, got %v", err)
+	}
+}
+
+func TestNewEventStore_NilDB(t *testing.T) {
+	store, err := sqlite.NewEventStore[testState](nil)
+	if !errors.Is(err, sqlite.ErrDBNil) {
+		t.Errorf("Expected ErrDBNil, got %v", err)
+	}
+	if store != nil {
+		t.Error("Expected nil store when database is nil")
+	}
+}
+
+func TestNewLeaseStore_NilDB(t *testing.T) {
+	store, err := sqlite.NewLeaseStore(nil)
+	if !errors.Is(err, sqlite.ErrDBNil) {
+		t.Errorf("Expected ErrDBNil, got %v", err)
+	}
+	if store != nil {
+		t.Error("Expected nil store when database is nil")
+	}
+}
+
+func TestNewJournal_NilDB(t *testing.T) {
+	journal, err := sqlite.NewJournal[testState](nil)
+	if !errors.Is(err, sqlite.ErrDBNil) {
+		t.Errorf("Expected ErrDBNil, got %v", err)
+	}
+	if journal != nil {
+		t.Error("Expected nil journal when database is nil")
+	}
+}
+
+func TestOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		option  sqlite.Option
+		wantErr bool
+		check   func(t *testing.T, opts sqlite.Options)
+	}{
+		{
+			name:   "valid table name",
+			option: sqlite.WithTableName("my_threads_2"),
+			check: func(t *testing.T, opts sqlite.Options) {
+				if opts.TableName != "my_threads_2" {
+					t.Errorf("Expected TableName 'my_threads_2', got '%s'", opts.TableName)
+				}
+			},
+		},
+		{
+			name:    "table name with injection",
+			option:  sqlite.WithTableName("threads; DROP TABLE x"),
+			wantErr: true,
+		},
+		{
+			name:    "table name starting with digit",
+			option:  sqlite.WithTableName("1threads"),
+			wantErr: true,
+		},
+		{
+			name:    "empty table name",
+			option:  sqlite.WithTableName(""),
+			wantErr: true,
+		},
+		{
+			name:   "history with default table",
+			option: sqlite.WithHistory(""),
+			check: func(t *testing.T, opts sqlite.Options) {
+				if !opts.History {
+					t.Error("Expected History to be enabled")
+				}
+				if opts.HistoryTableName != "" {
+					t.Errorf("Expected untouched HistoryTableName, got '%s'", opts.HistoryTableName)
+				}
+			},
+		},
+		{
+			name:   "history with custom table",
+			option: sqlite.WithHistory("audit"),
+			check: func(t *testing.T, opts sqlite.Options) {
+				if !opts.History || opts.HistoryTableName != "audit" {
+					t.Errorf("Expected history on table 'audit', got %+v", opts)
+				}
+			},
+		},
+		{
+			name:    "history with invalid table",
+			option:  sqlite.WithHistory("audit-log"),
+			wantErr: true,
+		},
+		{
+			name:   "event table",
+			option: sqlite.WithEventTableName("audit_events"),
+			check: func(t *testing.T, opts sqlite.Options) {
+				if opts.EventTableName != "audit_events" {
+					t.Errorf("Expected EventTableName 'audit_events', got '%s'", opts.EventTableName)
+				}
+			},
+		},
+		{
+			name:    "event table with invalid name",
+			option:  sqlite.WithEventTableName("audit events"),
+			wantErr: true,
+		},
+		{
+			name:   "lease table",
+			option: sqlite.WithLeaseTableName("leases"),
+			check: func(t *testing.T, opts sqlite.Options) {
+				if opts.LeaseTableName != "leases" {
+					t.Errorf("Expected LeaseTableName 'leases', got '%s'", opts.LeaseTableName)
+				}
+			},
+		},
+		{
+			name:    "lease table with invalid name",
+			option:  sqlite.WithLeaseTableName("leases;"),
+			wantErr: true,
+		},
+		{
+			name:   "once table",
+			option: sqlite.WithOnceTableName("side_effects"),
+			check: func(t *testing.T, opts sqlite.Options) {
+				if opts.OnceTableName != "side_effects" {
+					t.Errorf("Expected OnceTableName 'side_effects', got '%s'", opts.OnceTableName)
+				}
+			},
+		},
+		{
+			name:    "once table with invalid name",
+			option:  sqlite.WithOnceTableName("side effects"),
+			wantErr: true,
+		},
+		{
+			name:   "scratch table",
+			option: sqlite.WithScratchTableName("scratch"),
+			check: func(t *testing.T, opts sqlite.Options) {
+				if opts.ScratchTableName != "scratch" {
+					t.Errorf("Expected ScratchTableName 'scratch', got '%s'", opts.ScratchTableName)
+				}
+			},
+		},
+		{
+			name:    "scratch table with invalid name",
+			option:  sqlite.WithScratchTableName("scratch pad"),
+			wantErr: true,
+		},
+		{
+			name:   "journal table",
+			option: sqlite.WithJournalTableName("steps"),
+			check: func(t *testing.T, opts sqlite.Options) {
+				if opts.JournalTableName != "steps" {
+					t.Errorf("Expected JournalTableName 'steps', got '%s'", opts.JournalTableName)
+				}
+			},
+		},
+		{
+			name:    "journal table with invalid name",
+			option:  sqlite.WithJournalTableName("steps;"),
+			wantErr: true,
+		},
+		{
+			name:   "without migration",
+			option: sqlite.WithoutMigration(),
+			check: func(t *testing.T, opts sqlite.Options) {
+				if !opts.SkipMigration {
+					t.Error("Expected SkipMigration to be set")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := sqlite.Options{}
+			err := tt.option.Apply(&opts)
+			if tt.wantErr {
+				if !errors.Is(err, sqlite.ErrInvalidTableName) {
+					t.Errorf("Expected ErrInvalidTableName, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			tt.check(t, opts)
+		})
+	}
+}
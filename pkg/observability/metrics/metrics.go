@@ -0,0 +1,116 @@
+// Package metrics exposes the node metrics of a runtime in the Prometheus text exposition format.
+//
+// The handler renders a snapshot of the runtime stats on each scrape: the executions, the failures,
+// the latency percentiles, the latency objectives and the token throughput of each node, labelled
+// by node name, see graph.Profiled.
+//
+// Example:
+//
+//	http.Handle("/metrics", metrics.Handler(runtime))
+//	log.Fatal(http.ListenAndServe(":9090", nil))
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// ContentType is the content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// Handler returns an HTTP handler serving the node metrics of the runtime.
+//
+// Parameters:
+//   - runtime: The runtime whose node metrics are served.
+//
+// Returns:
+//   - The HTTP handler.
+func Handler(runtime g.Profiled) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", ContentType)
+		if err := Write(w, runtime.Stats()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+// Write writes the node metrics in the Prometheus text exposition format.
+//
+// Parameters:
+//   - w: The destination of the metrics.
+//   - stats: The node metrics, see graph.Profiled.
+//
+// Returns:
+//   - An error if the metrics cannot be written.
+func Write(w io.Writer, stats g.RuntimeStats) error {
+	out := bufio.NewWriter(w)
+
+	family(out, "ggraph_node_executions_total", "counter", "Executions of the node which returned, failed ones included.")
+	for _, node := range stats.Nodes {
+		sample(out, "ggraph_node_executions_total", node.Node, "", float64(node.Executions))
+	}
+	family(out, "ggraph_node_failures_total", "counter", "Executions of the node which returned an error.")
+	for _, node := range stats.Nodes {
+		sample(out, "ggraph_node_failures_total", node.Node, "", float64(node.Failures))
+	}
+
+	family(out, "ggraph_node_latency_seconds", "summary", "Latency of the node, the quantiles cover the most recent executions.")
+	for _, node := range stats.Nodes {
+		sample(out, "ggraph_node_latency_seconds", node.Node, `quantile="0.5"`, node.P50Latency.Seconds())
+		sample(out, "ggraph_node_latency_seconds", node.Node, `quantile="0.95"`, node.P95Latency.Seconds())
+		sample(out, "ggraph_node_latency_seconds", node.Node, `quantile="0.99"`, node.P99Latency.Seconds())
+		sample(out, "ggraph_node_latency_seconds_sum", node.Node, "", node.TotalLatency.Seconds())
+		sample(out, "ggraph_node_latency_seconds_count", node.Node, "", float64(node.Executions))
+	}
+	family(out, "ggraph_node_latency_max_seconds", "gauge", "Longest execution of the node.")
+	for _, node := range stats.Nodes {
+		sample(out, "ggraph_node_latency_max_seconds", node.Node, "", node.MaxLatency.Seconds())
+	}
+
+	family(out, "ggraph_node_latency_slo_seconds", "gauge", "Latency objective of the node.")
+	for _, node := range stats.Nodes {
+		if node.LatencySLO > 0 {
+			sample(out, "ggraph_node_latency_slo_seconds", node.Node, "", node.LatencySLO.Seconds())
+		}
+	}
+	family(out, "ggraph_node_slo_breaches_total", "counter", "Executions of the node exceeding its latency objective.")
+	for _, node := range stats.Nodes {
+		sample(out, "ggraph_node_slo_breaches_total", node.Node, "", float64(node.SLOBreaches))
+	}
+
+	family(out, "ggraph_node_partial_updates_total", "counter", "Partial updates streamed by the node.")
+	for _, node := range stats.Nodes {
+		sample(out, "ggraph_node_partial_updates_total", node.Node, "", float64(node.PartialUpdates))
+	}
+	family(out, "ggraph_node_tokens_total", "counter", "Tokens reported by the node, by kind.")
+	for _, node := range stats.Nodes {
+		sample(out, "ggraph_node_tokens_total", node.Node, `kind="prompt"`, float64(node.Usage.PromptTokens))
+		sample(out, "ggraph_node_tokens_total", node.Node, `kind="completion"`, float64(node.Usage.CompletionTokens))
+	}
+	family(out, "ggraph_node_tokens_per_second", "gauge", "Completion tokens reported by the node per second of execution.")
+	for _, node := range stats.Nodes {
+		sample(out, "ggraph_node_tokens_per_second", node.Node, "", node.TokensPerSecond())
+	}
+
+	return out.Flush()
+}
+
+func family(out *bufio.Writer, name, kind, help string) {
+	fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+func sample(out *bufio.Writer, name, node, labels string, value float64) {
+	if labels != "" {
+		labels = "," + labels
+	}
+	fmt.Fprintf(out, "%s{node=\"%s\"%s} %s\n", name, labelEscaper.Replace(node), labels, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// labelEscaper escapes the label values: backslashes, double quotes and newlines.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
@@ -0,0 +1,76 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/observability/metrics"
+)
+
+type profiled g.RuntimeStats
+
+func (p profiled) Stats() g.RuntimeStats {
+	return g.RuntimeStats(p)
+}
+
+func TestHandler(t *testing.T) {
+	stats := profiled{Nodes: []g.NodeStats{
+		{
+			Node:           "Ask",
+			Executions:     4,
+			Failures:       1,
+			TotalLatency:   2 * time.Second,
+			MaxLatency:     time.Second,
+			P50Latency:     250 * time.Millisecond,
+			P95Latency:     750 * time.Millisecond,
+			P99Latency:     time.Second,
+			LatencySLO:     500 * time.Millisecond,
+			SLOBreaches:    2,
+			PartialUpdates: 3,
+			Usage:          g.Usage{PromptTokens: 10, CompletionTokens: 40, TotalTokens: 50},
+		},
+		{Node: `say "hi"\now`, Executions: 1},
+	}}
+	server := httptest.NewServer(metrics.Handler(stats))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != metrics.ContentType {
+		t.Errorf("Expected the exposition content type, got %q", resp.Header.Get("Content-Type"))
+	}
+	body, _ := io.ReadAll(resp.Body)
+	exposition := string(body)
+
+	for _, expected := range []string{
+		"# TYPE ggraph_node_latency_seconds summary\n",
+		`ggraph_node_executions_total{node="Ask"} 4` + "\n",
+		`ggraph_node_failures_total{node="Ask"} 1` + "\n",
+		`ggraph_node_latency_seconds{node="Ask",quantile="0.5"} 0.25` + "\n",
+		`ggraph_node_latency_seconds{node="Ask",quantile="0.95"} 0.75` + "\n",
+		`ggraph_node_latency_seconds{node="Ask",quantile="0.99"} 1` + "\n",
+		`ggraph_node_latency_seconds_sum{node="Ask"} 2` + "\n",
+		`ggraph_node_latency_seconds_count{node="Ask"} 4` + "\n",
+		`ggraph_node_latency_slo_seconds{node="Ask"} 0.5` + "\n",
+		`ggraph_node_slo_breaches_total{node="Ask"} 2` + "\n",
+		`ggraph_node_partial_updates_total{node="Ask"} 3` + "\n",
+		`ggraph_node_tokens_total{node="Ask",kind="completion"} 40` + "\n",
+		`ggraph_node_tokens_per_second{node="Ask"} 20` + "\n",
+		`ggraph_node_executions_total{node="say \"hi\"\\now"} 1` + "\n",
+	} {
+		if !strings.Contains(exposition, expected) {
+			t.Errorf("Expected %q in the exposition:\n%s", expected, exposition)
+		}
+	}
+	if strings.Contains(exposition, `ggraph_node_latency_slo_seconds{node="say`) {
+		t.Errorf("Expected no latency objective for the node without one:\n%s", exposition)
+	}
+}
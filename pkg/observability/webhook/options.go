@@ -0,0 +1,175 @@
+package webhook
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultMaxRetries is the default number of retries of a failed delivery.
+	DefaultMaxRetries = 3
+	// DefaultInitialBackoff is the default wait before the first retry.
+	DefaultInitialBackoff = 500 * time.Millisecond
+	// DefaultMaxBackoff is the default upper bound of the wait between retries.
+	DefaultMaxBackoff = 30 * time.Second
+	// DefaultTimeout is the default timeout of a single delivery attempt.
+	DefaultTimeout = 10 * time.Second
+)
+
+var (
+	// ErrInvalidURL indicates that the webhook URL is not an absolute http or https URL.
+	ErrInvalidURL = errors.New("webhook URL must be an absolute http or https URL")
+	// ErrNoEventTypes indicates that the sink was configured without event types.
+	ErrNoEventTypes = errors.New("at least one event type is required")
+	// ErrUnknownEventType indicates that an event type is not supported.
+	ErrUnknownEventType = errors.New("unknown event type")
+	// ErrInvalidRetries indicates that the retry configuration is invalid.
+	ErrInvalidRetries = errors.New("retries must not be negative and backoffs must be positive")
+	// ErrHTTPClientNil indicates that a nil HTTP client was given.
+	ErrHTTPClientNil = errors.New("HTTP client cannot be nil")
+	// ErrEmptySecret indicates that an empty signing secret was given.
+	ErrEmptySecret = errors.New("signing secret cannot be empty")
+)
+
+// Options holds the configuration of the webhook Sink.
+type Options struct {
+	// Events are the event types delivered, all of them by default.
+	Events []EventType
+	// Secret signs the payloads when set, see SignatureHeader.
+	Secret []byte
+	// IncludeState adds the state of the thread to the payloads.
+	IncludeState bool
+	// MaxRetries is the number of retries of a failed delivery.
+	MaxRetries int
+	// InitialBackoff is the wait before the first retry, doubled at every retry.
+	InitialBackoff time.Duration
+	// MaxBackoff is the upper bound of the wait between retries.
+	MaxBackoff time.Duration
+	// Client is the HTTP client posting the payloads.
+	Client *http.Client
+}
+
+// Option is a functional option for configuring the webhook Sink.
+type Option interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - r: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *Options) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc func(*Options) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - r: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc) Apply(r *Options) error { return s(r) }
+
+// WithEvents sets the event types delivered to the webhook.
+//
+// Parameters:
+//   - events: The event types.
+//
+// Returns:
+//   - An Option that sets the event types.
+//
+// Example:
+//
+//	sink, err := webhook.NewSink[MyState](url, webhook.WithEvents(webhook.ThreadFailed, webhook.PersistenceFailed))
+func WithEvents(events ...EventType) Option {
+	return OptionFunc(func(r *Options) error {
+		if len(events) == 0 {
+			return ErrNoEventTypes
+		}
+		for _, event := range events {
+			if !event.valid() {
+				return fmt.Errorf("%w: %q", ErrUnknownEventType, event)
+			}
+		}
+		r.Events = events
+		return nil
+	})
+}
+
+// WithSecret signs the payloads with HMAC-SHA256, see Sign.
+//
+// Parameters:
+//   - secret: The secret shared with the receiver.
+//
+// Returns:
+//   - An Option that sets the signing secret.
+func WithSecret(secret []byte) Option {
+	return OptionFunc(func(r *Options) error {
+		if len(secret) == 0 {
+			return ErrEmptySecret
+		}
+		r.Secret = secret
+		return nil
+	})
+}
+
+// WithState adds the state of the thread to the payloads.
+//
+// Returns:
+//   - An Option that includes the state.
+func WithState() Option {
+	return OptionFunc(func(r *Options) error {
+		r.IncludeState = true
+		return nil
+	})
+}
+
+// WithRetries sets the retries of the failed deliveries.
+//
+// The deliveries failing with a network error, a 429 or a 5xx status are retried with an
+// exponential backoff; the other statuses are not retried.
+//
+// Parameters:
+//   - maxRetries: The number of retries, 0 disables them.
+//   - initialBackoff: The wait before the first retry, doubled at every retry.
+//   - maxBackoff: The upper bound of the wait between retries.
+//
+// Returns:
+//   - An Option that sets the retries.
+//
+// Example:
+//
+//	sink, err := webhook.NewSink[MyState](url, webhook.WithRetries(5, time.Second, time.Minute))
+func WithRetries(maxRetries int, initialBackoff, maxBackoff time.Duration) Option {
+	return OptionFunc(func(r *Options) error {
+		if maxRetries < 0 || initialBackoff <= 0 || maxBackoff < initialBackoff {
+			return ErrInvalidRetries
+		}
+		r.MaxRetries = maxRetries
+		r.InitialBackoff = initialBackoff
+		r.MaxBackoff = maxBackoff
+		return nil
+	})
+}
+
+// WithHTTPClient sets the HTTP client posting the payloads.
+//
+// Parameters:
+//   - client: The HTTP client, its timeout bounds every attempt.
+//
+// Returns:
+//   - An Option that sets the HTTP client.
+func WithHTTPClient(client *http.Client) Option {
+	return OptionFunc(func(r *Options) error {
+		if client == nil {
+			return ErrHTTPClientNil
+		}
+		r.Client = client
+		return nil
+	})
+}
@@ -0,0 +1,343 @@
+// Package webhook provides a sink posting the lifecycle events of a runtime to an HTTP endpoint.
+//
+// The sink subscribes to the event bus of the runtime and POSTs one JSON Payload per event,
+// optionally signed with HMAC-SHA256, retrying the failed deliveries with an exponential backoff.
+//
+// Example:
+//
+//	sink, err := webhook.NewSink[MyState]("https://ops.example.com/hooks/ggraph",
+//	    webhook.WithEvents(webhook.ThreadFailed, webhook.PersistenceFailed),
+//	    webhook.WithSecret([]byte(os.Getenv("WEBHOOK_SECRET"))))
+//	stop := sink.Attach(runtime)
+//	defer stop()
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// EventType is the type of a lifecycle event.
+type EventType string
+
+const (
+	// ThreadCompleted is the successful end of an invocation.
+	ThreadCompleted EventType = "thread.completed"
+	// ThreadFailed is the end of an invocation with a fatal error.
+	ThreadFailed EventType = "thread.failed"
+	// ThreadEvicted is the eviction of an inactive thread.
+	ThreadEvicted EventType = "thread.evicted"
+	// PersistenceFailed is the failure to persist the state of a thread.
+	PersistenceFailed EventType = "persistence.failed"
+)
+
+const (
+	// EventHeader carries the event type of the payload.
+	EventHeader = "X-GGraph-Event"
+	// DeliveryHeader carries the identifier of the payload, the same for all the attempts.
+	DeliveryHeader = "X-GGraph-Delivery"
+	// TimestampHeader carries the unix time of the attempt, part of the signed content.
+	TimestampHeader = "X-GGraph-Timestamp"
+	// SignatureHeader carries "sha256=" followed by the hex encoded signature, see Sign.
+	SignatureHeader = "X-GGraph-Signature"
+)
+
+// persistenceNode is the node name of the monitor entries reporting the persistence failures.
+const persistenceNode = "Persistence"
+
+var allEventTypes = []EventType{ThreadCompleted, ThreadFailed, ThreadEvicted, PersistenceFailed}
+
+// ErrDeliveryFailed indicates that a payload could not be delivered after the retries.
+var ErrDeliveryFailed = errors.New("webhook delivery failed")
+
+func (e EventType) valid() bool {
+	return slices.Contains(allEventTypes, e)
+}
+
+// Payload is the JSON body posted to the webhook.
+type Payload[T g.SharedState] struct {
+	// ID identifies the event, see DeliveryHeader.
+	ID string `json:"id"`
+	// Type is the event type.
+	Type EventType `json:"type"`
+	// ThreadID is the thread the event belongs to.
+	ThreadID string `json:"thread_id"`
+	// Tenant is the tenant of the thread, if any.
+	Tenant string `json:"tenant,omitempty"`
+	// Node is the node which produced the event.
+	Node string `json:"node"`
+	// Timestamp is the time the event was observed.
+	Timestamp time.Time `json:"timestamp"`
+	// Error is the error message of the failures.
+	Error string `json:"error,omitempty"`
+	// ErrorCode is the machine-readable kind of the error, see graph.ErrorCodeOf.
+	ErrorCode g.ErrorCode `json:"error_code,omitempty"`
+	// Usage is the cumulative usage of the invocation.
+	Usage g.Usage `json:"usage"`
+	// State is the state of the thread, only with WithState.
+	State *T `json:"state,omitempty"`
+}
+
+// Stats describes the deliveries of a Sink.
+type Stats struct {
+	// Delivered is the number of payloads accepted by the webhook.
+	Delivered uint64
+	// Failed is the number of payloads given up after the retries.
+	Failed uint64
+	// Retries is the number of retried attempts.
+	Retries uint64
+}
+
+// Sink posts the lifecycle events to a webhook.
+type Sink[T g.SharedState] struct {
+	url  string
+	opts Options
+
+	delivered atomic.Uint64
+	failed    atomic.Uint64
+	retries   atomic.Uint64
+}
+
+// NewSink creates a sink posting to the given URL.
+//
+// Parameters:
+//   - webhookURL: The absolute http or https URL of the webhook.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - The Sink.
+//   - An error if the URL or the options are invalid.
+//
+// Example:
+//
+//	sink, err := webhook.NewSink[MyState](url, webhook.WithSecret(secret), webhook.WithRetries(5, time.Second, time.Minute))
+func NewSink[T g.SharedState](webhookURL string, opts ...Option) (*Sink[T], error) {
+	parsed, err := url.Parse(webhookURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("webhook sink creation failed: %w", ErrInvalidURL)
+	}
+
+	useOpts := &Options{
+		Events:         allEventTypes,
+		MaxRetries:     DefaultMaxRetries,
+		InitialBackoff: DefaultInitialBackoff,
+		MaxBackoff:     DefaultMaxBackoff,
+		Client:         &http.Client{Timeout: DefaultTimeout},
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("webhook sink creation failed: %w", err)
+		}
+	}
+
+	return &Sink[T]{url: webhookURL, opts: *useOpts}, nil
+}
+
+// Attach subscribes the sink to the events of the runtime, delivering them in order until stopped.
+//
+// The events the sink cannot keep up with are dropped by the event bus, see g.EventStats.
+//
+// Parameters:
+//   - bus: The event bus of the runtime.
+//
+// Returns:
+//   - The function stopping the sink, aborting the pending retries.
+func (s *Sink[T]) Attach(bus g.EventBus[T]) func() {
+	entries, cancel := bus.Subscribe(g.EventFilter[T]{
+		SkipPartial: true,
+		Match: func(entry g.StateMonitorEntry[T]) bool {
+			eventType, ok := Classify(entry)
+			return ok && slices.Contains(s.opts.Events, eventType)
+		},
+	})
+
+	ctx, stop := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for entry := range entries {
+			// Failures are accounted in Stats.
+			_ = s.Deliver(ctx, entry)
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			stop()
+			cancel()
+			wg.Wait()
+		})
+	}
+}
+
+// Deliver posts the monitor entry, when it is one of the configured event types.
+//
+// Parameters:
+//   - ctx: The context of the delivery, bounding the retries.
+//   - entry: The monitor entry.
+//
+// Returns:
+//   - ErrDeliveryFailed wrapping the last error when the retries are exhausted, otherwise nil.
+func (s *Sink[T]) Deliver(ctx context.Context, entry g.StateMonitorEntry[T]) error {
+	eventType, ok := Classify(entry)
+	if !ok || !slices.Contains(s.opts.Events, eventType) {
+		return nil
+	}
+
+	payload := Payload[T]{
+		ID:        uuid.NewString(),
+		Type:      eventType,
+		ThreadID:  entry.ThreadID,
+		Tenant:    entry.Tenant,
+		Node:      entry.Node,
+		Timestamp: time.Now().UTC(),
+		Usage:     entry.Usage,
+	}
+	if entry.Error != nil {
+		payload.Error = entry.Error.Error()
+		payload.ErrorCode = entry.ErrorCode
+	}
+	if s.opts.IncludeState {
+		payload.State = &entry.NewState
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		s.failed.Add(1)
+		return fmt.Errorf("%w: cannot serialize payload: %w", ErrDeliveryFailed, err)
+	}
+
+	backoff := s.opts.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := s.post(ctx, payload, body)
+		if err == nil {
+			s.delivered.Add(1)
+			return nil
+		}
+		if !retry || attempt >= s.opts.MaxRetries {
+			s.failed.Add(1)
+			return fmt.Errorf("%w: event %s after %d attempts: %w", ErrDeliveryFailed, payload.ID, attempt+1, err)
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			s.failed.Add(1)
+			return fmt.Errorf("%w: event %s: %w", ErrDeliveryFailed, payload.ID, ctx.Err())
+		}
+		s.retries.Add(1)
+		backoff = min(backoff*2, s.opts.MaxBackoff)
+	}
+}
+
+// Stats returns a snapshot of the delivery metrics.
+//
+// Returns:
+//   - The delivery metrics.
+func (s *Sink[T]) Stats() Stats {
+	return Stats{
+		Delivered: s.delivered.Load(),
+		Failed:    s.failed.Load(),
+		Retries:   s.retries.Load(),
+	}
+}
+
+// post makes one attempt, reporting whether a failure can be retried.
+func (s *Sink[T]) post(ctx context.Context, payload Payload[T], body []byte) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(payload.Type))
+	req.Header.Set(DeliveryHeader, payload.ID)
+	req.Header.Set(TimestampHeader, timestamp)
+	if len(s.opts.Secret) > 0 {
+		req.Header.Set(SignatureHeader, "sha256="+Sign(s.opts.Secret, timestamp, body))
+	}
+
+	resp, err := s.opts.Client.Do(req)
+	if err != nil {
+		return ctx.Err() == nil, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retry, fmt.Errorf("unexpected status %s", resp.Status)
+}
+
+// Classify returns the event type of the monitor entry.
+//
+// Parameters:
+//   - entry: The monitor entry.
+//
+// Returns:
+//   - The event type.
+//   - false if the entry is not a lifecycle event.
+func Classify[T g.SharedState](entry g.StateMonitorEntry[T]) (EventType, bool) {
+	switch {
+	case entry.Partial:
+		return "", false
+	case !entry.Running && entry.Error == nil:
+		return ThreadCompleted, true
+	case !entry.Running:
+		return ThreadFailed, true
+	case errors.Is(entry.Error, g.ErrEvictionByInactivity):
+		return ThreadEvicted, true
+	case entry.Error != nil && (entry.Node == persistenceNode || errors.Is(entry.Error, g.ErrPersistenceQueueFull)):
+		return PersistenceFailed, true
+	default:
+		return "", false
+	}
+}
+
+// Sign returns the hex encoded HMAC-SHA256 of the timestamp and the body, joined by a dot.
+//
+// Receivers recompute it from the TimestampHeader and the raw body, compare it with the
+// SignatureHeader using hmac.Equal and reject the stale timestamps to prevent replays.
+//
+// Parameters:
+//   - secret: The shared secret.
+//   - timestamp: The value of the TimestampHeader.
+//   - body: The raw body.
+//
+// Returns:
+//   - The hex encoded signature.
+//
+// Example:
+//
+//	expected := "sha256=" + webhook.Sign(secret, r.Header.Get(webhook.TimestampHeader), body)
+//	valid := hmac.Equal([]byte(expected), []byte(r.Header.Get(webhook.SignatureHeader)))
+func Sign(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,217 @@
+package webhook_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/observability/webhook"
+)
+
+type testState struct {
+	Value string `json:"value"`
+}
+
+type received struct {
+	payload   webhook.Payload[testState]
+	event     string
+	timestamp string
+	signature string
+	body      []byte
+}
+
+type recorder struct {
+	mu       sync.Mutex
+	requests []received
+	statuses []int
+	got      chan struct{}
+}
+
+func newRecorder(statuses ...int) (*recorder, *httptest.Server) {
+	rec := &recorder{statuses: statuses, got: make(chan struct{}, 10)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload webhook.Payload[testState]
+		_ = json.Unmarshal(body, &payload)
+
+		rec.mu.Lock()
+		rec.requests = append(rec.requests, received{
+			payload:   payload,
+			event:     r.Header.Get(webhook.EventHeader),
+			timestamp: r.Header.Get(webhook.TimestampHeader),
+			signature: r.Header.Get(webhook.SignatureHeader),
+			body:      body,
+		})
+		status := http.StatusNoContent
+		if len(rec.statuses) > 0 {
+			status, rec.statuses = rec.statuses[0], rec.statuses[1:]
+		}
+		rec.mu.Unlock()
+
+		w.WriteHeader(status)
+		rec.got <- struct{}{}
+	}))
+	return rec, server
+}
+
+func (r *recorder) all() []received {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]received{}, r.requests...)
+}
+
+func TestSink_Deliver_Signed(t *testing.T) {
+	rec, server := newRecorder()
+	defer server.Close()
+
+	secret := []byte("s3cret")
+	sink, err := webhook.NewSink[testState](server.URL, webhook.WithSecret(secret), webhook.WithState())
+	if err != nil {
+		t.Fatalf("NewSink failed: %v", err)
+	}
+
+	err = sink.Deliver(context.Background(), g.StateMonitorEntry[testState]{ThreadID: "t1", Node: "EndNode", NewState: testState{Value: "done"}})
+	if err != nil {
+		t.Fatalf("Deliver failed: %v", err)
+	}
+
+	requests := rec.all()
+	if len(requests) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(requests))
+	}
+	req := requests[0]
+	if req.event != string(webhook.ThreadCompleted) || req.payload.Type != webhook.ThreadCompleted || req.payload.ThreadID != "t1" {
+		t.Errorf("Unexpected payload %+v", req.payload)
+	}
+	if req.payload.State == nil || req.payload.State.Value != "done" {
+		t.Errorf("Expected the state in the payload, got %+v", req.payload.State)
+	}
+	expected := "sha256=" + webhook.Sign(secret, req.timestamp, req.body)
+	if !hmac.Equal([]byte(expected), []byte(req.signature)) {
+		t.Errorf("Invalid signature %q", req.signature)
+	}
+}
+
+func TestSink_Deliver_Retries(t *testing.T) {
+	rec, server := newRecorder(http.StatusServiceUnavailable, http.StatusTooManyRequests, http.StatusOK)
+	defer server.Close()
+
+	sink, _ := webhook.NewSink[testState](server.URL, webhook.WithRetries(3, time.Millisecond, 2*time.Millisecond))
+	entry := g.StateMonitorEntry[testState]{ThreadID: "t1", Node: "Node1", Error: errors.New("boom")}
+	if err := sink.Deliver(context.Background(), entry); err != nil {
+		t.Fatalf("Deliver failed: %v", err)
+	}
+
+	requests := rec.all()
+	if len(requests) != 3 {
+		t.Fatalf("Expected 3 attempts, got %d", len(requests))
+	}
+	if requests[0].payload.ID != requests[2].payload.ID || requests[0].payload.Type != webhook.ThreadFailed {
+		t.Errorf("Expected the same failed event across attempts, got %+v and %+v", requests[0].payload, requests[2].payload)
+	}
+	if stats := sink.Stats(); stats.Delivered != 1 || stats.Retries != 2 || stats.Failed != 0 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestSink_Deliver_GivesUp(t *testing.T) {
+	rec, server := newRecorder(http.StatusBadRequest, http.StatusInternalServerError, http.StatusInternalServerError)
+	defer server.Close()
+
+	sink, _ := webhook.NewSink[testState](server.URL, webhook.WithRetries(1, time.Millisecond, time.Millisecond))
+
+	entry := g.StateMonitorEntry[testState]{ThreadID: "t1"}
+	if err := sink.Deliver(context.Background(), entry); !errors.Is(err, webhook.ErrDeliveryFailed) {
+		t.Errorf("Expected ErrDeliveryFailed for a client error, got %v", err)
+	}
+	if n := len(rec.all()); n != 1 {
+		t.Errorf("Expected a client error not to be retried, got %d attempts", n)
+	}
+
+	if err := sink.Deliver(context.Background(), entry); !errors.Is(err, webhook.ErrDeliveryFailed) {
+		t.Errorf("Expected ErrDeliveryFailed after the retries, got %v", err)
+	}
+	if n := len(rec.all()); n != 3 {
+		t.Errorf("Expected 1 retry, got %d attempts", n-1)
+	}
+	if stats := sink.Stats(); stats.Failed != 2 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name     string
+		entry    g.StateMonitorEntry[testState]
+		expected webhook.EventType
+		ok       bool
+	}{
+		{name: "completed", entry: g.StateMonitorEntry[testState]{}, expected: webhook.ThreadCompleted, ok: true},
+		{name: "failed", entry: g.StateMonitorEntry[testState]{Error: errors.New("boom")}, expected: webhook.ThreadFailed, ok: true},
+		{name: "evicted", entry: g.StateMonitorEntry[testState]{Running: true, Error: g.ErrEvictionByInactivity}, expected: webhook.ThreadEvicted, ok: true},
+		{name: "persistence", entry: g.StateMonitorEntry[testState]{Running: true, Node: "Persistence", Error: errors.New("db down")}, expected: webhook.PersistenceFailed, ok: true},
+		{name: "queue full", entry: g.StateMonitorEntry[testState]{Running: true, Node: "Node1", Error: g.ErrPersistenceQueueFull}, expected: webhook.PersistenceFailed, ok: true},
+		{name: "running", entry: g.StateMonitorEntry[testState]{Running: true}},
+		{name: "partial", entry: g.StateMonitorEntry[testState]{Partial: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			eventType, ok := webhook.Classify(tt.entry)
+			if eventType != tt.expected || ok != tt.ok {
+				t.Errorf("Expected %q/%v, got %q/%v", tt.expected, tt.ok, eventType, ok)
+			}
+		})
+	}
+}
+
+func TestNewSink_Invalid(t *testing.T) {
+	if _, err := webhook.NewSink[testState]("ftp://example.com"); !errors.Is(err, webhook.ErrInvalidURL) {
+		t.Errorf("Expected ErrInvalidURL, got %v", err)
+	}
+	if _, err := webhook.NewSink[testState]("http://example.com", webhook.WithEvents("thread.paused")); !errors.Is(err, webhook.ErrUnknownEventType) {
+		t.Errorf("Expected ErrUnknownEventType, got %v", err)
+	}
+	if _, err := webhook.NewSink[testState]("http://example.com", webhook.WithRetries(1, time.Second, time.Millisecond)); !errors.Is(err, webhook.ErrInvalidRetries) {
+		t.Errorf("Expected ErrInvalidRetries, got %v", err)
+	}
+}
+
+func TestSink_Attach(t *testing.T) {
+	rec, server := newRecorder()
+	defer server.Close()
+
+	sink, _ := webhook.NewSink[testState](server.URL, webhook.WithEvents(webhook.ThreadCompleted))
+
+	node, _ := builders.NewNode("Node1", func(userInput, currentState testState, notify g.NotifyPartialFn[testState]) (testState, error) {
+		notify(testState{Value: "partial"})
+		return testState{Value: userInput.Value}, nil
+	})
+	runtime, _ := builders.CreateRuntime(builders.CreateStartEdge(node), nil)
+	runtime.AddEdge(builders.CreateEndEdge(node))
+	defer runtime.Shutdown()
+
+	stop := sink.Attach(runtime)
+	defer stop()
+
+	threadID := runtime.Invoke(testState{Value: "hello"})
+	select {
+	case <-rec.got:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for the webhook")
+	}
+
+	stop()
+	requests := rec.all()
+	if len(requests) != 1 || requests[0].payload.Type != webhook.ThreadCompleted || requests[0].payload.ThreadID != threadID {
+		t.Errorf("Expected only the completion of %s, got %+v", threadID, requests)
+	}
+}
@@ -0,0 +1,180 @@
+// Package reducers provides composable state reducers.
+//
+// A reducer combines the state of a thread with the change returned by a node. The
+// default reducer replaces the state; the reducers of this package merge it field by
+// field with a strategy per field, e.g. appending the new messages of a conversation
+// or summing counters.
+//
+// With merging strategies the nodes return only their contribution to those fields:
+// a node appending to a slice returns the new items, not the whole slice.
+//
+// Example:
+//
+//	reducer := reducers.Merge(
+//	    reducers.Field(func(s *MyState) *[]string { return &s.Messages }, reducers.Append[string]()),
+//	    reducers.Field(func(s *MyState) *int { return &s.Calls }, reducers.Sum[int]()),
+//	)
+//	node, err := builders.NewNode("MyNode", myNodeFunction, g.WithReducer(reducer))
+package reducers
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// Strategy merges the current value of a field with the value carried by the change.
+type Strategy[F any] func(current, change F) F
+
+// FieldReducer merges one field of the state into the reduced state.
+//
+// Parameters:
+//   - reduced: The state being reduced, initialized with the change.
+//   - currentState: The state of the thread before the change.
+//   - change: The change returned by the node.
+type FieldReducer[T g.SharedState] func(reduced *T, currentState, change T)
+
+// Merge creates a reducer applying the field reducers on top of the change.
+//
+// The fields without a field reducer take the value of the change, as with the
+// default replacing reducer.
+//
+// Parameters:
+//   - fields: The field reducers, applied in order.
+//
+// Returns:
+//   - The reducer function.
+//
+// Example:
+//
+//	reducer := reducers.Merge(
+//	    reducers.Field(func(s *MyState) *map[string]string { return &s.Labels }, reducers.LastWriterWins[string, string]()),
+//	)
+func Merge[T g.SharedState](fields ...FieldReducer[T]) g.ReducerFn[T] {
+	useFields := slices.Clone(fields)
+	return func(currentState, change T) T {
+		reduced := change
+		for _, field := range useFields {
+			field(&reduced, currentState, change)
+		}
+		return reduced
+	}
+}
+
+// Chain creates a reducer applying the reducers in sequence, each one reducing the result of the previous one with the change.
+//
+// Parameters:
+//   - reducers: The reducers to chain.
+//
+// Returns:
+//   - The reducer function.
+func Chain[T g.SharedState](reducers ...g.ReducerFn[T]) g.ReducerFn[T] {
+	useReducers := slices.Clone(reducers)
+	return func(currentState, change T) T {
+		for _, reducer := range useReducers {
+			currentState = reducer(currentState, change)
+		}
+		return currentState
+	}
+}
+
+// Field creates a field reducer merging the field selected by the accessor with the strategy.
+//
+// Parameters:
+//   - field: The accessor returning a pointer to the field of the given state.
+//   - strategy: The strategy merging the field.
+//
+// Returns:
+//   - The field reducer.
+//
+// Example:
+//
+//	reducers.Field(func(s *MyState) *[]string { return &s.Messages }, reducers.Append[string]())
+func Field[T g.SharedState, F any](field func(state *T) *F, strategy Strategy[F]) FieldReducer[T] {
+	return func(reduced *T, currentState, change T) {
+		*field(reduced) = strategy(*field(&currentState), *field(&change))
+	}
+}
+
+// Replace keeps the value of the change.
+func Replace[F any]() Strategy[F] {
+	return func(_, change F) F { return change }
+}
+
+// Keep keeps the current value, ignoring the change.
+func Keep[F any]() Strategy[F] {
+	return func(current, _ F) F { return current }
+}
+
+// ReplaceIfNotZero keeps the value of the change unless it is the zero value.
+func ReplaceIfNotZero[F comparable]() Strategy[F] {
+	return func(current, change F) F {
+		var zero F
+		if change == zero {
+			return current
+		}
+		return change
+	}
+}
+
+// Append appends the items of the change to the current items.
+func Append[E any]() Strategy[[]E] {
+	return func(current, change []E) []E {
+		if len(change) == 0 {
+			return current
+		}
+		return append(slices.Clip(current), change...)
+	}
+}
+
+// AppendUnique appends the items of the change missing from the current items.
+func AppendUnique[E comparable]() Strategy[[]E] {
+	return func(current, change []E) []E {
+		rv := slices.Clip(current)
+		for _, item := range change {
+			if !slices.Contains(rv, item) {
+				rv = append(rv, item)
+			}
+		}
+		return rv
+	}
+}
+
+// LastWriterWins merges the entries of the change into the current entries, the change winning on conflicting keys.
+func LastWriterWins[K comparable, V any]() Strategy[map[K]V] {
+	return func(current, change map[K]V) map[K]V {
+		if len(change) == 0 {
+			return current
+		}
+		rv := maps.Clone(current)
+		if rv == nil {
+			rv = make(map[K]V, len(change))
+		}
+		maps.Copy(rv, change)
+		return rv
+	}
+}
+
+// Number is the constraint of the numeric strategies.
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// Sum adds the change to the current value, for counters.
+func Sum[N Number]() Strategy[N] {
+	return func(current, change N) N { return current + change }
+}
+
+// Max keeps the greatest value.
+func Max[N cmp.Ordered]() Strategy[N] {
+	return func(current, change N) N { return max(current, change) }
+}
+
+// Min keeps the smallest value.
+func Min[N cmp.Ordered]() Strategy[N] {
+	return func(current, change N) N { return min(current, change) }
+}
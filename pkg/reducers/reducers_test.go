@@ -0,0 +1,133 @@
+package reducers_test
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/morphy76/ggraph/pkg/reducers"
+)
+
+type testState struct {
+	Messages []string          `reduce:"append"`
+	Labels   map[string]string `reduce:"merge"`
+	Calls    int               `reduce:"sum"`
+	Best     float64           `reduce:"max"`
+	Lowest   uint              `reduce:"min"`
+	Owner    string            `reduce:"keep"`
+	Summary  string            `reduce:"nonzero"`
+	Answer   string
+}
+
+func TestMerge(t *testing.T) {
+	reducer := reducers.Merge(
+		reducers.Field(func(s *testState) *[]string { return &s.Messages }, reducers.Append[string]()),
+		reducers.Field(func(s *testState) *map[string]string { return &s.Labels }, reducers.LastWriterWins[string, string]()),
+		reducers.Field(func(s *testState) *int { return &s.Calls }, reducers.Sum[int]()),
+		reducers.Field(func(s *testState) *float64 { return &s.Best }, reducers.Max[float64]()),
+		reducers.Field(func(s *testState) *uint { return &s.Lowest }, reducers.Min[uint]()),
+		reducers.Field(func(s *testState) *string { return &s.Owner }, reducers.Keep[string]()),
+		reducers.Field(func(s *testState) *string { return &s.Summary }, reducers.ReplaceIfNotZero[string]()),
+	)
+	assertReduced(t, reducer)
+}
+
+func TestFromTags(t *testing.T) {
+	reducer, err := reducers.FromTags[testState]()
+	if err != nil {
+		t.Fatalf("FromTags failed: %v", err)
+	}
+	assertReduced(t, reducer)
+}
+
+func assertReduced(t *testing.T, reducer func(currentState, change testState) testState) {
+	t.Helper()
+
+	current := testState{
+		Messages: make([]string, 1, 10),
+		Labels:   map[string]string{"a": "1", "b": "2"},
+		Calls:    2,
+		Best:     0.5,
+		Lowest:   3,
+		Owner:    "alice",
+		Summary:  "old",
+		Answer:   "old",
+	}
+	current.Messages[0] = "hi"
+
+	reduced := reducer(current, testState{
+		Messages: []string{"there"},
+		Labels:   map[string]string{"b": "3"},
+		Calls:    1,
+		Best:     0.9,
+		Lowest:   5,
+		Owner:    "bob",
+		Answer:   "new",
+	})
+
+	if !slices.Equal(reduced.Messages, []string{"hi", "there"}) {
+		t.Errorf("Unexpected messages %v", reduced.Messages)
+	}
+	if len(reduced.Labels) != 2 || reduced.Labels["a"] != "1" || reduced.Labels["b"] != "3" {
+		t.Errorf("Unexpected labels %v", reduced.Labels)
+	}
+	if reduced.Calls != 3 || reduced.Best != 0.9 || reduced.Lowest != 3 {
+		t.Errorf("Unexpected numbers %+v", reduced)
+	}
+	if reduced.Owner != "alice" || reduced.Summary != "old" || reduced.Answer != "new" {
+		t.Errorf("Unexpected strings %+v", reduced)
+	}
+
+	if current.Labels["b"] != "2" {
+		t.Error("Expected the current map to be left untouched")
+	}
+	reduced.Messages[0] = "changed"
+	if current.Messages[0] != "hi" {
+		t.Error("Expected the reduced slice not to alias the current one")
+	}
+}
+
+func TestChain(t *testing.T) {
+	double := func(currentState, change testState) testState {
+		currentState.Calls *= 2
+		return currentState
+	}
+	add := reducers.Merge(reducers.Field(func(s *testState) *int { return &s.Calls }, reducers.Sum[int]()))
+
+	reduced := reducers.Chain(add, double)(testState{Calls: 1}, testState{Calls: 2})
+	if reduced.Calls != 6 {
+		t.Errorf("Expected (1+2)*2, got %d", reduced.Calls)
+	}
+}
+
+func TestAppendUnique(t *testing.T) {
+	strategy := reducers.AppendUnique[string]()
+	if merged := strategy([]string{"a", "b"}, []string{"b", "c", "c"}); !slices.Equal(merged, []string{"a", "b", "c"}) {
+		t.Errorf("Unexpected merge %v", merged)
+	}
+}
+
+func TestFromTags_Invalid(t *testing.T) {
+	type unknown struct {
+		Value string `reduce:"concat"`
+	}
+	type mismatch struct {
+		Value string `reduce:"append"`
+	}
+	type notNumber struct {
+		Value []int `reduce:"sum"`
+	}
+
+	if _, err := reducers.FromTags[unknown](); !errors.Is(err, reducers.ErrUnknownStrategy) {
+		t.Errorf("Expected ErrUnknownStrategy, got %v", err)
+	}
+	if _, err := reducers.FromTags[mismatch](); !errors.Is(err, reducers.ErrStrategyMismatch) {
+		t.Errorf("Expected ErrStrategyMismatch, got %v", err)
+	}
+	if _, err := reducers.FromTags[notNumber](); !errors.Is(err, reducers.ErrStrategyMismatch) {
+		t.Errorf("Expected ErrStrategyMismatch for sum, got %v", err)
+	}
+	if _, err := reducers.FromTags[string](); !errors.Is(err, reducers.ErrNotStruct) {
+		t.Errorf("Expected ErrNotStruct, got %v", err)
+	}
+}
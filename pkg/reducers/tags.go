@@ -0,0 +1,176 @@
+package reducers
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// TagName is the struct tag selecting the strategy of a field, see FromTags.
+const TagName = "reduce"
+
+var (
+	// ErrNotStruct indicates that FromTags was used with a state which is not a struct.
+	ErrNotStruct = errors.New("state must be a struct")
+	// ErrUnknownStrategy indicates that a reduce tag names an unknown strategy.
+	ErrUnknownStrategy = errors.New("unknown reduce strategy")
+	// ErrStrategyMismatch indicates that a reduce tag names a strategy not applicable to the type of the field.
+	ErrStrategyMismatch = errors.New("reduce strategy not applicable to the field type")
+)
+
+type tagStrategy func(current, change reflect.Value) reflect.Value
+
+// FromTags generates a reducer from the reduce struct tags of the state.
+//
+// The supported strategies are:
+//   - replace: the value of the change, the default for untagged fields.
+//   - keep: the current value.
+//   - nonzero: the value of the change unless it is the zero value.
+//   - append: the items of the change appended to the current slice.
+//   - merge: the entries of the change merged into the current map, the change winning.
+//   - sum, max, min: the sum, the greatest or the smallest of two numbers.
+//
+// Returns:
+//   - The reducer function.
+//   - An error if the state is not a struct or a tag is invalid.
+//
+// Example:
+//
+//	type MyState struct {
+//	    Messages []string          `reduce:"append"`
+//	    Labels   map[string]string `reduce:"merge"`
+//	    Calls    int               `reduce:"sum"`
+//	    Answer   string
+//	}
+//	reducer, err := reducers.FromTags[MyState]()
+func FromTags[T g.SharedState]() (g.ReducerFn[T], error) {
+	stateType := reflect.TypeFor[T]()
+	if stateType.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("reducer generation failed for %s: %w", stateType, ErrNotStruct)
+	}
+
+	strategies := make(map[int]tagStrategy)
+	for idx := range stateType.NumField() {
+		field := stateType.Field(idx)
+		tag, ok := field.Tag.Lookup(TagName)
+		if !ok || tag == "replace" {
+			continue
+		}
+		if !field.IsExported() {
+			return nil, fmt.Errorf("reducer generation failed for field %s: %w: unexported field", field.Name, ErrStrategyMismatch)
+		}
+		strategy, err := strategyFor(tag, field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("reducer generation failed for field %s: %w", field.Name, err)
+		}
+		strategies[idx] = strategy
+	}
+
+	return func(currentState, change T) T {
+		reduced := change
+		reducedValue := reflect.ValueOf(&reduced).Elem()
+		currentValue := reflect.ValueOf(currentState)
+		changeValue := reflect.ValueOf(change)
+		for idx, strategy := range strategies {
+			reducedValue.Field(idx).Set(strategy(currentValue.Field(idx), changeValue.Field(idx)))
+		}
+		return reduced
+	}, nil
+}
+
+func strategyFor(tag string, fieldType reflect.Type) (tagStrategy, error) {
+	switch tag {
+	case "keep":
+		return func(current, _ reflect.Value) reflect.Value { return current }, nil
+	case "nonzero":
+		return func(current, change reflect.Value) reflect.Value {
+			if change.IsZero() {
+				return current
+			}
+			return change
+		}, nil
+	case "append":
+		if fieldType.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("%w: %s on %s", ErrStrategyMismatch, tag, fieldType)
+		}
+		return func(current, change reflect.Value) reflect.Value {
+			if change.Len() == 0 {
+				return current
+			}
+			rv := reflect.MakeSlice(fieldType, 0, current.Len()+change.Len())
+			return reflect.AppendSlice(reflect.AppendSlice(rv, current), change)
+		}, nil
+	case "merge":
+		if fieldType.Kind() != reflect.Map {
+			return nil, fmt.Errorf("%w: %s on %s", ErrStrategyMismatch, tag, fieldType)
+		}
+		return func(current, change reflect.Value) reflect.Value {
+			if change.Len() == 0 {
+				return current
+			}
+			rv := reflect.MakeMapWithSize(fieldType, current.Len()+change.Len())
+			for _, source := range []reflect.Value{current, change} {
+				iter := source.MapRange()
+				for iter.Next() {
+					rv.SetMapIndex(iter.Key(), iter.Value())
+				}
+			}
+			return rv
+		}, nil
+	case "sum", "max", "min":
+		return numericStrategy(tag, fieldType)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownStrategy, tag)
+	}
+}
+
+func numericStrategy(tag string, fieldType reflect.Type) (tagStrategy, error) {
+	var less func(a, b reflect.Value) bool
+	var add func(a, b reflect.Value) reflect.Value
+	switch fieldType.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		less = func(a, b reflect.Value) bool { return a.Int() < b.Int() }
+		add = func(a, b reflect.Value) reflect.Value {
+			rv := reflect.New(fieldType).Elem()
+			rv.SetInt(a.Int() + b.Int())
+			return rv
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		less = func(a, b reflect.Value) bool { return a.Uint() < b.Uint() }
+		add = func(a, b reflect.Value) reflect.Value {
+			rv := reflect.New(fieldType).Elem()
+			rv.SetUint(a.Uint() + b.Uint())
+			return rv
+		}
+	case reflect.Float32, reflect.Float64:
+		less = func(a, b reflect.Value) bool { return a.Float() < b.Float() }
+		add = func(a, b reflect.Value) reflect.Value {
+			rv := reflect.New(fieldType).Elem()
+			rv.SetFloat(a.Float() + b.Float())
+			return rv
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s on %s", ErrStrategyMismatch, tag, fieldType)
+	}
+
+	switch tag {
+	case "sum":
+		return add, nil
+	case "max":
+		return func(current, change reflect.Value) reflect.Value {
+			if less(current, change) {
+				return change
+			}
+			return current
+		}, nil
+	default:
+		return func(current, change reflect.Value) reflect.Value {
+			if less(change, current) {
+				return change
+			}
+			return current
+		}, nil
+	}
+}
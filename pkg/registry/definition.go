@@ -0,0 +1,154 @@
+// Package registry stores versioned graph definitions and instantiates runtimes from them.
+//
+// A Definition describes the topology of a graph declaratively, its nodes refer to their
+// functions, routing policies and reducers by name; the Bindings given to Instantiate map those
+// names to the code compiled in the binary. Publishing a new version of a definition into a
+// shared Store rolls a graph change out without releasing a new binary, as long as the bindings
+// it refers to exist.
+package registry
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+)
+
+var (
+	// ErrDefinitionNameEmpty indicates that a definition has no name.
+	ErrDefinitionNameEmpty = errors.New("definition name cannot be empty")
+	// ErrInvalidDefinition indicates that a definition does not describe a valid graph.
+	ErrInvalidDefinition = errors.New("invalid graph definition")
+	// ErrUnknownDefinition indicates that no definition is stored with the given name and version.
+	ErrUnknownDefinition = errors.New("unknown graph definition")
+	// ErrDefinitionExists indicates that a definition is already stored with the given name and version.
+	ErrDefinitionExists = errors.New("graph definition already exists")
+	// ErrUnboundName indicates that a definition refers to a name missing from the bindings.
+	ErrUnboundName = errors.New("name is not bound")
+)
+
+// LatestVersion selects the most recent version of a definition.
+const LatestVersion = 0
+
+// Definition is the declarative description of a graph.
+type Definition struct {
+	// Name identifies the graph within the registry.
+	Name string `json:"name"`
+	// Version is assigned by Registry.Publish, starting from 1.
+	Version int `json:"version,omitempty"`
+	// Description documents the graph, or the change introduced by the version.
+	Description string `json:"description,omitempty"`
+	// Nodes are the nodes of the graph.
+	Nodes []NodeDefinition `json:"nodes"`
+	// Edges are the edges of the graph, exactly one of them must be a start edge.
+	Edges []EdgeDefinition `json:"edges"`
+	// PublishedAt is set by Registry.Publish.
+	PublishedAt time.Time `json:"publishedAt"`
+}
+
+// NodeDefinition describes a node by the names of its bindings.
+type NodeDefinition struct {
+	// Name is the unique name of the node.
+	Name string `json:"name"`
+	// Fn is the name of the node function, a node without function is a router.
+	Fn string `json:"fn,omitempty"`
+	// Policy is the name of the routing policy, the default one when empty.
+	Policy string `json:"policy,omitempty"`
+	// Reducer is the name of the reducer, the default one when empty.
+	Reducer string `json:"reducer,omitempty"`
+}
+
+// EdgeDefinition describes an edge between two nodes.
+type EdgeDefinition struct {
+	// From is the name of the source node, empty for the start edge.
+	From string `json:"from,omitempty"`
+	// To is the name of the target node, empty for an end edge.
+	To string `json:"to,omitempty"`
+	// Labels are the labels of the edge.
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+// ParseDefinition decodes and validates a JSON graph definition.
+//
+// Parameters:
+//   - data: The JSON document.
+//
+// Returns:
+//   - The definition.
+//   - An error if the document cannot be decoded or the definition is invalid.
+//
+// Example:
+//
+//	def, err := registry.ParseDefinition([]byte(`{
+//	    "name": "support",
+//	    "nodes": [{"name": "Triage", "fn": "triage"}],
+//	    "edges": [{"to": "Triage"}, {"from": "Triage"}]
+//	}`))
+func ParseDefinition(data []byte) (Definition, error) {
+	var def Definition
+	if err := json.Unmarshal(data, &def); err != nil {
+		return Definition{}, fmt.Errorf("cannot decode graph definition: %w", err)
+	}
+	if err := def.Validate(); err != nil {
+		return Definition{}, err
+	}
+	return def, nil
+}
+
+// Validate checks the consistency of the definition, regardless of the bindings.
+//
+// Returns:
+//   - An error wrapping ErrDefinitionNameEmpty or ErrInvalidDefinition if the definition is invalid.
+func (d Definition) Validate() error {
+	if d.Name == "" {
+		return fmt.Errorf("cannot validate graph definition: %w", ErrDefinitionNameEmpty)
+	}
+	if len(d.Nodes) == 0 {
+		return fmt.Errorf("%w %s: no nodes", ErrInvalidDefinition, d.Name)
+	}
+
+	nodes := make(map[string]bool, len(d.Nodes))
+	for _, node := range d.Nodes {
+		if node.Name == "" {
+			return fmt.Errorf("%w %s: node without name", ErrInvalidDefinition, d.Name)
+		}
+		if nodes[node.Name] {
+			return fmt.Errorf("%w %s: duplicate node %s", ErrInvalidDefinition, d.Name, node.Name)
+		}
+		if node.Fn == "" && node.Policy == "" {
+			return fmt.Errorf("%w %s: node %s has neither function nor policy", ErrInvalidDefinition, d.Name, node.Name)
+		}
+		nodes[node.Name] = true
+	}
+
+	starts := 0
+	for idx, edge := range d.Edges {
+		switch {
+		case edge.From == "" && edge.To == "":
+			return fmt.Errorf("%w %s: edge %d has neither source nor target", ErrInvalidDefinition, d.Name, idx)
+		case edge.From == "":
+			starts++
+		case !nodes[edge.From]:
+			return fmt.Errorf("%w %s: edge %d from unknown node %s", ErrInvalidDefinition, d.Name, idx, edge.From)
+		}
+		if edge.To != "" && !nodes[edge.To] {
+			return fmt.Errorf("%w %s: edge %d to unknown node %s", ErrInvalidDefinition, d.Name, idx, edge.To)
+		}
+	}
+	if starts != 1 {
+		return fmt.Errorf("%w %s: %d start edges, expected 1", ErrInvalidDefinition, d.Name, starts)
+	}
+	return nil
+}
+
+func (d Definition) clone() Definition {
+	rv := d
+	rv.Nodes = append([]NodeDefinition(nil), d.Nodes...)
+	rv.Edges = make([]EdgeDefinition, len(d.Edges))
+	for idx, edge := range d.Edges {
+		rv.Edges[idx] = edge
+		rv.Edges[idx].Labels = maps.Clone(edge.Labels)
+	}
+	return rv
+}
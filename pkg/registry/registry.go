@@ -0,0 +1,234 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	b "github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// ErrStoreNil indicates that a registry has no store.
+var ErrStoreNil = errors.New("registry store cannot be nil")
+
+// Bindings map the names used by the definitions to the code of the binary.
+type Bindings[T g.SharedState] struct {
+	// Fns are the node functions, by name.
+	Fns map[string]g.ContextNodeFn[T]
+	// Policies are the routing policies, by name.
+	Policies map[string]g.RoutePolicy[T]
+	// Reducers are the reducers, by name.
+	Reducers map[string]g.ReducerFn[T]
+}
+
+// Check verifies that the bindings provide all the names a definition refers to.
+//
+// Parameters:
+//   - def: The definition.
+//
+// Returns:
+//   - An error wrapping ErrUnboundName for the first missing name.
+func (bnd Bindings[T]) Check(def Definition) error {
+	for _, node := range def.Nodes {
+		if _, ok := bnd.Fns[node.Fn]; node.Fn != "" && !ok {
+			return fmt.Errorf("node %s of %s: function %q: %w", node.Name, def.Name, node.Fn, ErrUnboundName)
+		}
+		if _, ok := bnd.Policies[node.Policy]; node.Policy != "" && !ok {
+			return fmt.Errorf("node %s of %s: policy %q: %w", node.Name, def.Name, node.Policy, ErrUnboundName)
+		}
+		if _, ok := bnd.Reducers[node.Reducer]; node.Reducer != "" && !ok {
+			return fmt.Errorf("node %s of %s: reducer %q: %w", node.Name, def.Name, node.Reducer, ErrUnboundName)
+		}
+	}
+	return nil
+}
+
+// Registry publishes the versions of the graph definitions into a Store and instantiates runtimes from them.
+type Registry[T g.SharedState] struct {
+	store Store
+}
+
+// New creates a registry backed by the given store.
+//
+// Parameters:
+//   - store: The Store holding the definitions, see NewMemStore and NewMemoryStore.
+//
+// Returns:
+//   - The Registry.
+//   - An error if the store is nil.
+//
+// Example:
+//
+//	reg, err := registry.New[MyState](registry.NewMemStore())
+func New[T g.SharedState](store Store) (*Registry[T], error) {
+	if store == nil {
+		return nil, fmt.Errorf("registry creation failed: %w", ErrStoreNil)
+	}
+	return &Registry[T]{store: store}, nil
+}
+
+// Publish validates a definition and stores it as the next version of its name.
+//
+// Parameters:
+//   - ctx: The context of the operation.
+//   - def: The definition, its Version and PublishedAt are assigned by the registry.
+//
+// Returns:
+//   - The published definition.
+//   - An error if the definition is invalid or cannot be stored.
+//
+// Example:
+//
+//	published, err := reg.Publish(ctx, def)
+//	log.Printf("published %s version %d", published.Name, published.Version)
+func (r *Registry[T]) Publish(ctx context.Context, def Definition) (Definition, error) {
+	if err := def.Validate(); err != nil {
+		return Definition{}, fmt.Errorf("cannot publish graph definition: %w", err)
+	}
+	versions, err := r.store.Versions(ctx, def.Name)
+	if err != nil {
+		return Definition{}, fmt.Errorf("cannot publish %s: %w", def.Name, err)
+	}
+
+	def = def.clone()
+	def.Version = 1
+	if len(versions) > 0 {
+		def.Version = versions[len(versions)-1] + 1
+	}
+	def.PublishedAt = time.Now()
+	if err := r.store.Save(ctx, def); err != nil {
+		return Definition{}, fmt.Errorf("cannot publish %s: %w", def.Name, err)
+	}
+	return def, nil
+}
+
+// Get returns a version of a definition.
+//
+// Parameters:
+//   - ctx: The context of the operation.
+//   - name: The name of the definition.
+//   - version: The version, LatestVersion for the most recent one.
+//
+// Returns:
+//   - The definition.
+//   - An error wrapping ErrUnknownDefinition if the version does not exist.
+func (r *Registry[T]) Get(ctx context.Context, name string, version int) (Definition, error) {
+	if version == LatestVersion {
+		versions, err := r.store.Versions(ctx, name)
+		if err != nil {
+			return Definition{}, fmt.Errorf("cannot get %s: %w", name, err)
+		}
+		if len(versions) == 0 {
+			return Definition{}, fmt.Errorf("cannot get %s: %w", name, ErrUnknownDefinition)
+		}
+		version = versions[len(versions)-1]
+	}
+	return r.store.Load(ctx, name, version)
+}
+
+// Versions returns the published versions of a definition, in ascending order.
+//
+// Parameters:
+//   - ctx: The context of the operation.
+//   - name: The name of the definition.
+//
+// Returns:
+//   - The versions, empty if the definition is unknown.
+//   - An error if the store cannot be read.
+func (r *Registry[T]) Versions(ctx context.Context, name string) ([]int, error) {
+	return r.store.Versions(ctx, name)
+}
+
+// Instantiate builds and validates a runtime from a version of a definition.
+//
+// Parameters:
+//   - ctx: The context of the operation.
+//   - name: The name of the definition.
+//   - version: The version, LatestVersion for the most recent one.
+//   - bindings: The code the names of the definition refer to.
+//   - stateMonitorCh: The channel receiving the state monitoring entries of the runtime.
+//   - opts: Optional configuration options for the runtime.
+//
+// Returns:
+//   - The runtime, ready to be invoked.
+//   - An error if the definition does not exist, refers to unbound names or does not validate.
+//
+// Example:
+//
+//	bindings := registry.Bindings[MyState]{
+//	    Fns: map[string]graph.ContextNodeFn[MyState]{"triage": triage, "answer": answer},
+//	}
+//	runtime, err := reg.Instantiate(ctx, "support", registry.LatestVersion, bindings, stateMonitorCh)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer runtime.Shutdown()
+func (r *Registry[T]) Instantiate(
+	ctx context.Context,
+	name string,
+	version int,
+	bindings Bindings[T],
+	stateMonitorCh chan g.StateMonitorEntry[T],
+	opts ...g.RuntimeOption[T],
+) (g.Runtime[T], error) {
+	def, err := r.Get(ctx, name, version)
+	if err != nil {
+		return nil, fmt.Errorf("cannot instantiate %s: %w", name, err)
+	}
+	if err := bindings.Check(def); err != nil {
+		return nil, fmt.Errorf("cannot instantiate %s version %d: %w", def.Name, def.Version, err)
+	}
+
+	nodes := make(map[string]g.Node[T], len(def.Nodes))
+	for _, nodeDef := range def.Nodes {
+		node, err := buildNode(nodeDef, bindings)
+		if err != nil {
+			return nil, fmt.Errorf("cannot instantiate %s version %d: %w", def.Name, def.Version, err)
+		}
+		nodes[nodeDef.Name] = node
+	}
+
+	var startEdge g.Edge[T]
+	edges := make([]g.Edge[T], 0, len(def.Edges))
+	for _, edgeDef := range def.Edges {
+		var labels []map[string]string
+		if edgeDef.Labels != nil {
+			labels = append(labels, edgeDef.Labels)
+		}
+		switch {
+		case edgeDef.From == "":
+			startEdge = b.CreateStartEdge(nodes[edgeDef.To])
+		case edgeDef.To == "":
+			edges = append(edges, b.CreateEndEdge(nodes[edgeDef.From], labels...))
+		default:
+			edges = append(edges, b.CreateEdge(nodes[edgeDef.From], nodes[edgeDef.To], labels...))
+		}
+	}
+
+	runtime, err := b.CreateRuntime(startEdge, stateMonitorCh, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("cannot instantiate %s version %d: %w", def.Name, def.Version, err)
+	}
+	runtime.AddEdge(edges...)
+	if err := runtime.Validate(); err != nil {
+		runtime.Shutdown()
+		return nil, fmt.Errorf("cannot instantiate %s version %d: %w", def.Name, def.Version, err)
+	}
+	return runtime, nil
+}
+
+func buildNode[T g.SharedState](def NodeDefinition, bindings Bindings[T]) (g.Node[T], error) {
+	var opts []g.NodeOption[T]
+	if def.Policy != "" {
+		opts = append(opts, g.WithRoutingPolicy(bindings.Policies[def.Policy]))
+	}
+	if def.Reducer != "" {
+		opts = append(opts, g.WithReducer(bindings.Reducers[def.Reducer]))
+	}
+	if def.Fn == "" {
+		return b.NewNode[T](def.Name, nil, opts...)
+	}
+	return b.NewContextNode(def.Name, bindings.Fns[def.Fn], opts...)
+}
@@ -0,0 +1,203 @@
+package registry_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/registry"
+)
+
+type flowState struct {
+	Visited []string
+	Route   string
+}
+
+func visit(name string) g.ContextNodeFn[flowState] {
+	return func(_ context.Context, _, currentState flowState, _ g.NotifyPartialFn[flowState]) (flowState, error) {
+		currentState.Visited = append(slices.Clone(currentState.Visited), name)
+		return currentState, nil
+	}
+}
+
+func testBindings(t *testing.T) registry.Bindings[flowState] {
+	byRoute, err := builders.CreateConditionalRoutePolicy(func(userInput, _ flowState, edges []g.Edge[flowState]) g.Edge[flowState] {
+		for _, edge := range edges {
+			if route, _ := edge.LabelByKey("route"); route == userInput.Route {
+				return edge
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("CreateConditionalRoutePolicy failed: %v", err)
+	}
+	return registry.Bindings[flowState]{
+		Fns: map[string]g.ContextNodeFn[flowState]{
+			"greet":  visit("greet"),
+			"refund": visit("refund"),
+			"answer": visit("answer"),
+		},
+		Policies: map[string]g.RoutePolicy[flowState]{"byRoute": byRoute},
+	}
+}
+
+const supportV1 = `{
+	"name": "support",
+	"nodes": [{"name": "Greet", "fn": "greet"}, {"name": "Answer", "fn": "answer"}],
+	"edges": [{"to": "Greet"}, {"from": "Greet", "to": "Answer"}, {"from": "Answer"}]
+}`
+
+const supportV2 = `{
+	"name": "support",
+	"description": "route refunds",
+	"nodes": [
+		{"name": "Greet", "fn": "greet"},
+		{"name": "Intent", "policy": "byRoute"},
+		{"name": "Refund", "fn": "refund"},
+		{"name": "Answer", "fn": "answer"}
+	],
+	"edges": [
+		{"to": "Greet"},
+		{"from": "Greet", "to": "Intent"},
+		{"from": "Intent", "to": "Refund", "labels": {"route": "refund"}},
+		{"from": "Intent", "to": "Answer", "labels": {"route": ""}},
+		{"from": "Refund"},
+		{"from": "Answer"}
+	]
+}`
+
+func publish(t *testing.T, reg *registry.Registry[flowState], data string) registry.Definition {
+	def, err := registry.ParseDefinition([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseDefinition failed: %v", err)
+	}
+	published, err := reg.Publish(context.Background(), def)
+	if err != nil {
+		t.Fatalf("Publish failed: %v", err)
+	}
+	return published
+}
+
+func run(t *testing.T, reg *registry.Registry[flowState], version int, userInput flowState) flowState {
+	stateMonitorCh := make(chan g.StateMonitorEntry[flowState], 10)
+	runtime, err := reg.Instantiate(context.Background(), "support", version, testBindings(t), stateMonitorCh)
+	if err != nil {
+		t.Fatalf("Instantiate failed: %v", err)
+	}
+	defer runtime.Shutdown()
+
+	runtime.Invoke(userInput)
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case entry := <-stateMonitorCh:
+			if !entry.Running {
+				if entry.Error != nil {
+					t.Fatalf("Unexpected error: %v", entry.Error)
+				}
+				return entry.NewState
+			}
+		case <-timeout:
+			t.Fatal("Timeout waiting for the graph completion")
+		}
+	}
+}
+
+// TestRegistry_Instantiate tests that the runtimes are built from the published versions of a definition
+func TestRegistry_Instantiate(t *testing.T) {
+	catalogs := builders.NewMemMemory[registry.Catalog]()
+	memoryStore, err := registry.NewMemoryStore(catalogs)
+	if err != nil {
+		t.Fatalf("NewMemoryStore failed: %v", err)
+	}
+
+	for name, store := range map[string]registry.Store{"mem store": registry.NewMemStore(), "memory store": memoryStore} {
+		t.Run(name, func(t *testing.T) {
+			reg, err := registry.New[flowState](store)
+			if err != nil {
+				t.Fatalf("New failed: %v", err)
+			}
+
+			if v1 := publish(t, reg, supportV1); v1.Version != 1 || v1.PublishedAt.IsZero() {
+				t.Errorf("Expected the first version to be published, got %+v", v1)
+			}
+			if v2 := publish(t, reg, supportV2); v2.Version != 2 || v2.Description != "route refunds" {
+				t.Errorf("Expected the second version to be published, got %+v", v2)
+			}
+			if versions, _ := reg.Versions(context.Background(), "support"); !slices.Equal(versions, []int{1, 2}) {
+				t.Errorf("Expected versions [1 2], got %v", versions)
+			}
+
+			if final := run(t, reg, 1, flowState{Route: "refund"}); !slices.Equal(final.Visited, []string{"greet", "answer"}) {
+				t.Errorf("Expected the first version to run, got %v", final.Visited)
+			}
+			if final := run(t, reg, registry.LatestVersion, flowState{Route: "refund"}); !slices.Equal(final.Visited, []string{"greet", "refund"}) {
+				t.Errorf("Expected the latest version to route refunds, got %v", final.Visited)
+			}
+			if final := run(t, reg, 2, flowState{}); !slices.Equal(final.Visited, []string{"greet", "answer"}) {
+				t.Errorf("Expected the latest version to answer, got %v", final.Visited)
+			}
+		})
+	}
+}
+
+// TestRegistry_Errors tests that invalid definitions and bindings are rejected
+func TestRegistry_Errors(t *testing.T) {
+	ctx := context.Background()
+	reg, _ := registry.New[flowState](registry.NewMemStore())
+
+	if _, err := registry.New[flowState](nil); !errors.Is(err, registry.ErrStoreNil) {
+		t.Errorf("Expected ErrStoreNil, got %v", err)
+	}
+	if _, err := registry.NewMemoryStore(nil); !errors.Is(err, registry.ErrCatalogMemoryNil) {
+		t.Errorf("Expected ErrCatalogMemoryNil, got %v", err)
+	}
+
+	invalid := map[string]registry.Definition{
+		"no start edge": {Name: "x", Nodes: []registry.NodeDefinition{{Name: "A", Fn: "greet"}}},
+		"unknown node": {Name: "x", Nodes: []registry.NodeDefinition{{Name: "A", Fn: "greet"}},
+			Edges: []registry.EdgeDefinition{{To: "A"}, {From: "A", To: "B"}}},
+		"duplicate node": {Name: "x", Nodes: []registry.NodeDefinition{{Name: "A", Fn: "greet"}, {Name: "A", Fn: "answer"}},
+			Edges: []registry.EdgeDefinition{{To: "A"}}},
+		"unbound router": {Name: "x", Nodes: []registry.NodeDefinition{{Name: "A"}},
+			Edges: []registry.EdgeDefinition{{To: "A"}}},
+	}
+	for name, def := range invalid {
+		if _, err := reg.Publish(ctx, def); !errors.Is(err, registry.ErrInvalidDefinition) {
+			t.Errorf("%s: expected ErrInvalidDefinition, got %v", name, err)
+		}
+	}
+	if _, err := reg.Publish(ctx, registry.Definition{}); !errors.Is(err, registry.ErrDefinitionNameEmpty) {
+		t.Errorf("Expected ErrDefinitionNameEmpty, got %v", err)
+	}
+	if _, err := registry.ParseDefinition([]byte("{")); err == nil {
+		t.Error("Expected malformed JSON to be rejected")
+	}
+
+	if _, err := reg.Get(ctx, "support", registry.LatestVersion); !errors.Is(err, registry.ErrUnknownDefinition) {
+		t.Errorf("Expected ErrUnknownDefinition, got %v", err)
+	}
+	publish(t, reg, supportV2)
+	if _, err := reg.Get(ctx, "support", 3); !errors.Is(err, registry.ErrUnknownDefinition) {
+		t.Errorf("Expected ErrUnknownDefinition, got %v", err)
+	}
+
+	bindings := testBindings(t)
+	delete(bindings.Fns, "refund")
+	stateMonitorCh := make(chan g.StateMonitorEntry[flowState], 10)
+	if _, err := reg.Instantiate(ctx, "support", registry.LatestVersion, bindings, stateMonitorCh); !errors.Is(err, registry.ErrUnboundName) {
+		t.Errorf("Expected ErrUnboundName, got %v", err)
+	}
+
+	store := registry.NewMemStore()
+	def, _ := reg.Get(ctx, "support", 1)
+	_ = store.Save(ctx, def)
+	if err := store.Save(ctx, def); !errors.Is(err, registry.ErrDefinitionExists) {
+		t.Errorf("Expected ErrDefinitionExists, got %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// ErrCatalogMemoryNil indicates that a memory-backed store has no memory.
+var ErrCatalogMemoryNil = errors.New("catalog memory cannot be nil")
+
+// Store holds the versions of the graph definitions.
+//
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Save stores a new version of a definition.
+	//
+	// Parameters:
+	//   - ctx: The context of the operation.
+	//   - def: The definition, with its name and version set.
+	//
+	// Returns:
+	//   - An error wrapping ErrDefinitionExists if the version is already stored.
+	Save(ctx context.Context, def Definition) error
+	// Load returns a version of a definition.
+	//
+	// Parameters:
+	//   - ctx: The context of the operation.
+	//   - name: The name of the definition.
+	//   - version: The version of the definition.
+	//
+	// Returns:
+	//   - The definition.
+	//   - An error wrapping ErrUnknownDefinition if the version is not stored.
+	Load(ctx context.Context, name string, version int) (Definition, error)
+	// Versions returns the stored versions of a definition, in ascending order.
+	//
+	// Parameters:
+	//   - ctx: The context of the operation.
+	//   - name: The name of the definition.
+	//
+	// Returns:
+	//   - The versions, empty if the definition is unknown.
+	//   - An error if the store cannot be read.
+	Versions(ctx context.Context, name string) ([]int, error)
+}
+
+// Catalog is the record of all the versions of a definition, as persisted by a memory-backed store.
+type Catalog struct {
+	Definitions []Definition `json:"definitions"`
+}
+
+func (c Catalog) find(version int) (Definition, bool) {
+	for _, def := range c.Definitions {
+		if def.Version == version {
+			return def, true
+		}
+	}
+	return Definition{}, false
+}
+
+func (c Catalog) versions() []int {
+	rv := make([]int, 0, len(c.Definitions))
+	for _, def := range c.Definitions {
+		rv = append(rv, def.Version)
+	}
+	sort.Ints(rv)
+	return rv
+}
+
+var _ Store = (*MemStore)(nil)
+
+// MemStore is an in-memory Store, suitable for tests and single-process deployments.
+type MemStore struct {
+	mu       sync.RWMutex
+	catalogs map[string]Catalog
+}
+
+// NewMemStore creates an empty in-memory Store.
+//
+// Returns:
+//   - The MemStore.
+//
+// Example:
+//
+//	reg := registry.New[MyState](registry.NewMemStore())
+func NewMemStore() *MemStore {
+	return &MemStore{catalogs: make(map[string]Catalog)}
+}
+
+// Save stores a new version of a definition.
+func (s *MemStore) Save(ctx context.Context, def Definition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	catalog := s.catalogs[def.Name]
+	if _, ok := catalog.find(def.Version); ok {
+		return fmt.Errorf("cannot save %s version %d: %w", def.Name, def.Version, ErrDefinitionExists)
+	}
+	catalog.Definitions = append(catalog.Definitions, def.clone())
+	s.catalogs[def.Name] = catalog
+	return nil
+}
+
+// Load returns a version of a definition.
+func (s *MemStore) Load(ctx context.Context, name string, version int) (Definition, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	def, ok := s.catalogs[name].find(version)
+	if !ok {
+		return Definition{}, fmt.Errorf("cannot load %s version %d: %w", name, version, ErrUnknownDefinition)
+	}
+	return def.clone(), nil
+}
+
+// Versions returns the stored versions of a definition, in ascending order.
+func (s *MemStore) Versions(ctx context.Context, name string) ([]int, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.catalogs[name].versions(), nil
+}
+
+var _ Store = (*memoryStore)(nil)
+
+// NewMemoryStore creates a Store persisting the catalog of each definition into a Memory, keyed by its name.
+//
+// Any Memory implementation can back the registry, e.g. the SQLite or MongoDB ones, so that the
+// definitions are shared among the replicas of a deployment. Saves are serialized within the
+// process only: the replicas should not publish the same definition concurrently.
+//
+// Parameters:
+//   - memory: The Memory holding the catalogs.
+//
+// Returns:
+//   - The Store.
+//   - An error if the memory is nil.
+//
+// Example:
+//
+//	memory, _ := sqlite.NewMemory[registry.Catalog](db)
+//	store, err := registry.NewMemoryStore(memory)
+func NewMemoryStore(memory g.Memory[Catalog]) (Store, error) {
+	if memory == nil {
+		return nil, fmt.Errorf("memory store creation failed: %w", ErrCatalogMemoryNil)
+	}
+	return &memoryStore{
+		persistFn: memory.PersistFn(),
+		restoreFn: memory.RestoreFn(),
+	}, nil
+}
+
+type memoryStore struct {
+	mu        sync.Mutex
+	persistFn g.PersistFn[Catalog]
+	restoreFn g.RestoreFn[Catalog]
+}
+
+func (s *memoryStore) Save(ctx context.Context, def Definition) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	catalog, err := s.restoreFn(ctx, def.Name)
+	if err != nil {
+		return fmt.Errorf("cannot save %s version %d: %w", def.Name, def.Version, err)
+	}
+	if _, ok := catalog.find(def.Version); ok {
+		return fmt.Errorf("cannot save %s version %d: %w", def.Name, def.Version, ErrDefinitionExists)
+	}
+	catalog.Definitions = append(catalog.Definitions, def)
+	if err := s.persistFn(ctx, def.Name, catalog); err != nil {
+		return fmt.Errorf("cannot save %s version %d: %w", def.Name, def.Version, err)
+	}
+	return nil
+}
+
+func (s *memoryStore) Load(ctx context.Context, name string, version int) (Definition, error) {
+	catalog, err := s.restoreFn(ctx, name)
+	if err != nil {
+		return Definition{}, fmt.Errorf("cannot load %s version %d: %w", name, version, err)
+	}
+	def, ok := catalog.find(version)
+	if !ok {
+		return Definition{}, fmt.Errorf("cannot load %s version %d: %w", name, version, ErrUnknownDefinition)
+	}
+	return def.clone(), nil
+}
+
+func (s *memoryStore) Versions(ctx context.Context, name string) ([]int, error) {
+	catalog, err := s.restoreFn(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("cannot list the versions of %s: %w", name, err)
+	}
+	return catalog.versions(), nil
+}
@@ -0,0 +1,261 @@
+package a2a_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	"github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/server"
+	"github.com/morphy76/ggraph/pkg/server/a2a"
+)
+
+func startAgent(t *testing.T) (*a2a.Client, <-chan struct{}) {
+	started := make(chan struct{}, 1)
+	echo, err := builders.NewContextNode("Echo", func(ctx context.Context, userInput, currentState a.Conversation, notify g.NotifyPartialFn[a.Conversation]) (a.Conversation, error) {
+		question := userInput.Messages[len(userInput.Messages)-1].Content
+		switch question {
+		case "fail":
+			return currentState, errors.New("boom")
+		case "slow":
+			started <- struct{}{}
+			<-ctx.Done()
+			return currentState, context.Cause(ctx)
+		}
+		rv := currentState.Clone()
+		rv.Messages = append(rv.Messages, userInput.Messages...)
+		notify(a.Conversation{Messages: append(rv.Messages, a.CreateMessage(a.Assistant, "thinking"))})
+		rv.Messages = append(rv.Messages, a.CreateMessage(a.Assistant, "echo: "+question))
+		return rv, nil
+	})
+	if err != nil {
+		t.Fatalf("NewContextNode failed: %v", err)
+	}
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[a.Conversation], 100)
+	runtime, err := builders.CreateRuntime(builders.CreateStartEdge(echo), stateMonitorCh)
+	if err != nil {
+		t.Fatalf("CreateRuntime failed: %v", err)
+	}
+	runtime.AddEdge(builders.CreateEndEdge(echo))
+	broker := server.NewBroker(stateMonitorCh, nil)
+
+	srv, err := a2a.NewServer(runtime, broker, a2a.ConversationCodec(), a2a.AgentCard{Name: "Echo", Description: "Echoes the question"})
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+	httpServer := httptest.NewServer(srv)
+
+	t.Cleanup(func() {
+		httpServer.Close()
+		broker.Stop()
+		runtime.Shutdown()
+	})
+	return a2a.NewClient(httpServer.URL, nil), started
+}
+
+func testContext(t *testing.T) context.Context {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	t.Cleanup(cancel)
+	return ctx
+}
+
+func userMessage(text string) a2a.Message {
+	return a2a.Message{Role: a2a.RoleUser, Parts: []a2a.Part{a2a.TextPart(text)}}
+}
+
+func TestClient_Card(t *testing.T) {
+	client, _ := startAgent(t)
+
+	card, err := client.Card(testContext(t))
+	if err != nil {
+		t.Fatalf("Card failed: %v", err)
+	}
+	if card.Name != "Echo" || card.ProtocolVersion != a2a.ProtocolVersion || !card.Capabilities.Streaming {
+		t.Errorf("unexpected card: %+v", card)
+	}
+}
+
+func TestClient_Send(t *testing.T) {
+	client, _ := startAgent(t)
+	ctx := testContext(t)
+
+	task, err := client.Send(ctx, userMessage("hello"))
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if task.Status.State != a2a.TaskCompleted {
+		t.Fatalf("expected a completed task, got %s", task.Status.State)
+	}
+	if len(task.Artifacts) != 1 || task.Artifacts[0].Parts[0].Text != "echo: hello" {
+		t.Errorf("unexpected artifacts: %+v", task.Artifacts)
+	}
+
+	got, err := client.GetTask(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("GetTask failed: %v", err)
+	}
+	if got.Status.State != a2a.TaskCompleted || len(got.Artifacts) != 1 {
+		t.Errorf("unexpected task: %+v", got)
+	}
+
+	failed, err := client.Send(ctx, userMessage("fail"))
+	if err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	if failed.Status.State != a2a.TaskFailed || failed.Status.Message == nil ||
+		!strings.Contains(failed.Status.Message.Parts[0].Text, "boom") {
+		t.Errorf("expected a failed task, got %+v", failed.Status)
+	}
+}
+
+func TestClient_GetUnknownTask(t *testing.T) {
+	client, _ := startAgent(t)
+
+	_, err := client.GetTask(testContext(t), "missing")
+	var rpcErr *a2a.Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != a2a.CodeTaskNotFound {
+		t.Errorf("expected a task not found error, got %v", err)
+	}
+}
+
+func TestClient_Stream(t *testing.T) {
+	client, _ := startAgent(t)
+
+	var events []a2a.Event
+	err := client.Stream(testContext(t), userMessage("hello"), func(event a2a.Event) error {
+		events = append(events, event)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream failed: %v", err)
+	}
+	if len(events) < 3 {
+		t.Fatalf("expected the task, the artifact and the final status, got %d events", len(events))
+	}
+	if events[0].Task == nil || events[0].Task.Status.State != a2a.TaskWorking {
+		t.Errorf("expected the working task first, got %+v", events[0])
+	}
+
+	var partial, artifact bool
+	for _, event := range events[1 : len(events)-1] {
+		if event.Status != nil && event.Status.Status.Message != nil && event.Status.Status.Message.Parts[0].Text == "thinking" {
+			partial = true
+		}
+		if event.Artifact != nil && event.Artifact.Artifact.Parts[0].Text == "echo: hello" {
+			artifact = true
+		}
+	}
+	if !partial || !artifact {
+		t.Errorf("expected the partial update and the artifact, got %+v", events)
+	}
+
+	last := events[len(events)-1]
+	if last.Status == nil || !last.Status.Final || last.Status.Status.State != a2a.TaskCompleted {
+		t.Errorf("expected a final completed status, got %+v", last)
+	}
+}
+
+func TestClient_Cancel(t *testing.T) {
+	client, started := startAgent(t)
+	ctx := testContext(t)
+
+	taskIDs := make(chan string, 1)
+	final := make(chan a2a.TaskState, 1)
+	go func() {
+		_ = client.Stream(ctx, userMessage("slow"), func(event a2a.Event) error {
+			if event.Task != nil {
+				taskIDs <- event.Task.ID
+			}
+			if event.Status != nil && event.Status.Final {
+				final <- event.Status.Status.State
+			}
+			return nil
+		})
+	}()
+
+	taskID := <-taskIDs
+	<-started
+	task, err := client.CancelTask(ctx, taskID)
+	if err != nil {
+		t.Fatalf("CancelTask failed: %v", err)
+	}
+	if task.Status.State != a2a.TaskCanceled {
+		t.Errorf("expected a canceled task, got %s", task.Status.State)
+	}
+	if state := <-final; state != a2a.TaskCanceled {
+		t.Errorf("expected a final canceled status, got %s", state)
+	}
+
+	_, err = client.CancelTask(ctx, taskID)
+	var rpcErr *a2a.Error
+	if !errors.As(err, &rpcErr) || rpcErr.Code != a2a.CodeTaskNotCancelable {
+		t.Errorf("expected a not cancelable error, got %v", err)
+	}
+}
+
+func TestNewRemoteAgentNode(t *testing.T) {
+	client, _ := startAgent(t)
+
+	remote, err := a2a.NewRemoteAgentNode("Remote", client, a2a.ConversationCodec())
+	if err != nil {
+		t.Fatalf("NewRemoteAgentNode failed: %v", err)
+	}
+	stateMonitorCh := make(chan g.StateMonitorEntry[a.Conversation], 100)
+	runtime, err := builders.CreateRuntime(builders.CreateStartEdge(remote), stateMonitorCh)
+	if err != nil {
+		t.Fatalf("CreateRuntime failed: %v", err)
+	}
+	runtime.AddEdge(builders.CreateEndEdge(remote))
+	broker := server.NewBroker(stateMonitorCh, nil)
+	t.Cleanup(func() {
+		broker.Stop()
+		runtime.Shutdown()
+	})
+
+	invoke := func(threadID, question string) g.StateMonitorEntry[a.Conversation] {
+		sub, unsubscribe := broker.Subscribe(threadID, 10)
+		defer unsubscribe()
+		runtime.Invoke(a.CreateConversation(a.CreateMessage(a.User, question)), g.InvokeConfigThreadID(threadID))
+		select {
+		case <-sub.Done():
+			return sub.Final()
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for the remote agent")
+		}
+		return g.StateMonitorEntry[a.Conversation]{}
+	}
+
+	final := invoke("local", "hello")
+	if final.Error != nil {
+		t.Fatalf("remote agent failed: %v", final.Error)
+	}
+	messages := final.NewState.Messages
+	if len(messages) != 2 || messages[0].Content != "hello" || messages[1].Role != a.Assistant || messages[1].Content != "echo: hello" {
+		t.Errorf("unexpected conversation: %+v", messages)
+	}
+
+	remoteTask, err := client.GetTask(testContext(t), "local")
+	if err != nil || remoteTask.ContextID != "local" {
+		t.Errorf("expected the remote task to continue the local thread, got %+v, %v", remoteTask, err)
+	}
+
+	failed := invoke("failing", "fail")
+	if !errors.Is(failed.Error, a2a.ErrRemoteTaskFailed) {
+		t.Errorf("expected ErrRemoteTaskFailed, got %v", failed.Error)
+	}
+}
+
+func TestNewRemoteAgentNode_Validation(t *testing.T) {
+	if _, err := a2a.NewRemoteAgentNode[a.Conversation]("Remote", nil, a2a.ConversationCodec()); !errors.Is(err, a2a.ErrClientNil) {
+		t.Errorf("expected ErrClientNil, got %v", err)
+	}
+	if _, err := a2a.NewRemoteAgentNode[a.Conversation]("Remote", a2a.NewClient("http://localhost", nil), nil); !errors.Is(err, a2a.ErrCodecNil) {
+		t.Errorf("expected ErrCodecNil, got %v", err)
+	}
+}
@@ -0,0 +1,339 @@
+package a2a
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+
+	b "github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+var (
+	// ErrClientNil indicates that no client was given to the remote agent node.
+	ErrClientNil = errors.New("a2a client cannot be nil")
+	// ErrRemoteTaskFailed indicates that the remote task failed or was cancelled.
+	ErrRemoteTaskFailed = errors.New("remote task did not complete")
+	// ErrUnexpectedStatus indicates that the remote agent answered with an HTTP error.
+	ErrUnexpectedStatus = errors.New("unexpected HTTP status")
+)
+
+// Client calls a remote A2A agent.
+type Client struct {
+	url        string
+	httpClient *http.Client
+	ids        atomic.Int64
+}
+
+// NewClient creates the client of a remote agent.
+//
+// Parameters:
+//   - url: The JSON-RPC endpoint of the agent, the URL of its card.
+//   - httpClient: The HTTP client, http.DefaultClient if nil.
+//
+// Returns:
+//   - The Client.
+//
+// Example:
+//
+//	client := a2a.NewClient("https://agents.example.com/researcher", nil)
+//	card, err := client.Card(ctx)
+func NewClient(url string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{url: strings.TrimSuffix(url, "/"), httpClient: httpClient}
+}
+
+// Card fetches the agent card.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//
+// Returns:
+//   - The agent card.
+//   - An error if the card cannot be fetched.
+func (c *Client) Card(ctx context.Context) (*AgentCard, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.url+AgentCardPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("cannot fetch the agent card: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("cannot fetch the agent card: %w: %s", ErrUnexpectedStatus, resp.Status)
+	}
+
+	var rv AgentCard
+	if err := json.NewDecoder(resp.Body).Decode(&rv); err != nil {
+		return nil, fmt.Errorf("cannot decode the agent card: %w", err)
+	}
+	return &rv, nil
+}
+
+// Send sends a message and waits for the task to end.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - msg: The message, its kind and ID are filled in when missing.
+//
+// Returns:
+//   - The task.
+//   - An error if the request fails, a JSON-RPC error being returned as *Error.
+func (c *Client) Send(ctx context.Context, msg Message) (*Task, error) {
+	var rv Task
+	if err := c.call(ctx, MethodSendMessage, MessageSendParams{Message: fillMessage(msg)}, &rv); err != nil {
+		return nil, err
+	}
+	return &rv, nil
+}
+
+// Stream sends a message and receives the task updates until the final one.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - msg: The message, its kind and ID are filled in when missing.
+//   - onEvent: The function receiving the events, an error stops the stream.
+//
+// Returns:
+//   - An error if the request or onEvent fails, a JSON-RPC error being returned as *Error.
+//
+// Example:
+//
+//	err := client.Stream(ctx, a2a.Message{Role: a2a.RoleUser, Parts: []a2a.Part{a2a.TextPart("hi")}}, func(event a2a.Event) error {
+//	    if event.Status != nil {
+//	        log.Printf("task %s is %s", event.Status.TaskID, event.Status.Status.State)
+//	    }
+//	    return nil
+//	})
+func (c *Client) Stream(ctx context.Context, msg Message, onEvent func(Event) error) error {
+	resp, err := c.post(ctx, MethodStreamMessage, MessageSendParams{Message: fillMessage(msg)})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/event-stream") {
+		return decodeResult(resp, nil)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		var rpcResp rpcResponse
+		if err := json.Unmarshal([]byte(strings.TrimSpace(data)), &rpcResp); err != nil {
+			return fmt.Errorf("cannot decode the stream event: %w", err)
+		}
+		if rpcResp.Error != nil {
+			return rpcResp.Error
+		}
+		var event Event
+		if err := json.Unmarshal(rpcResp.Result, &event); err != nil {
+			return fmt.Errorf("cannot decode the stream event: %w", err)
+		}
+		if err := onEvent(event); err != nil {
+			return err
+		}
+		if event.Status != nil && event.Status.Final {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("stream interrupted: %w", err)
+	}
+	return nil
+}
+
+// GetTask returns a task.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - taskID: The task.
+//
+// Returns:
+//   - The task.
+//   - An error if the request fails, a JSON-RPC error being returned as *Error.
+func (c *Client) GetTask(ctx context.Context, taskID string) (*Task, error) {
+	var rv Task
+	if err := c.call(ctx, MethodGetTask, TaskIDParams{ID: taskID}, &rv); err != nil {
+		return nil, err
+	}
+	return &rv, nil
+}
+
+// CancelTask cancels a task.
+//
+// Parameters:
+//   - ctx: The context of the request.
+//   - taskID: The task.
+//
+// Returns:
+//   - The cancelled task.
+//   - An error if the request fails, a JSON-RPC error being returned as *Error.
+func (c *Client) CancelTask(ctx context.Context, taskID string) (*Task, error) {
+	var rv Task
+	if err := c.call(ctx, MethodCancelTask, TaskIDParams{ID: taskID}, &rv); err != nil {
+		return nil, err
+	}
+	return &rv, nil
+}
+
+func (c *Client) call(ctx context.Context, method string, params any, result any) error {
+	resp, err := c.post(ctx, method, params)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return decodeResult(resp, result)
+}
+
+func (c *Client) post(ctx context.Context, method string, params any) (*http.Response, error) {
+	rawParams, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode the %s request: %w", method, err)
+	}
+	body, _ := json.Marshal(rpcRequest{JSONRPC: "2.0", ID: c.ids.Add(1), Method: method, Params: rawParams})
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if method == MethodStreamMessage {
+		req.Header.Set("Accept", "text/event-stream")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s request failed: %w", method, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("%s request failed: %w: %s", method, ErrUnexpectedStatus, resp.Status)
+	}
+	return resp, nil
+}
+
+func decodeResult(resp *http.Response, result any) error {
+	var rpcResp rpcResponse
+	if err := json.NewDecoder(resp.Body).Decode(&rpcResp); err != nil {
+		return fmt.Errorf("cannot decode the response: %w", err)
+	}
+	if rpcResp.Error != nil {
+		return rpcResp.Error
+	}
+	if result == nil {
+		return nil
+	}
+	if err := json.Unmarshal(rpcResp.Result, result); err != nil {
+		return fmt.Errorf("cannot decode the response: %w", err)
+	}
+	return nil
+}
+
+func fillMessage(msg Message) Message {
+	msg.Kind = KindMessage
+	if msg.MessageID == "" {
+		msg.MessageID = uuid.NewString()
+	}
+	if msg.Role == "" {
+		msg.Role = RoleUser
+	}
+	return msg
+}
+
+// NewRemoteAgentNode creates a node delegating to a remote A2A agent.
+//
+// The node sends the parts of the user input, or of the current state when the user input
+// encodes to no part, as a message whose context is the thread ID, so that the successive
+// invocations of a thread continue the same remote conversation. The working updates of the
+// remote task are notified as partial states and its artifacts are applied to the state.
+//
+// Parameters:
+//   - name: The unique name for the node.
+//   - client: The client of the remote agent.
+//   - codec: The codec of the state.
+//   - opts: Optional node options.
+//
+// Returns:
+//   - The remote agent node, failing with ErrRemoteTaskFailed when the remote task does not complete.
+//   - An error if the client or the codec is nil.
+//
+// Example:
+//
+//	researcher, _ := a2a.NewRemoteAgentNode("Researcher", a2a.NewClient(url, nil), a2a.ConversationCodec())
+func NewRemoteAgentNode[T g.SharedState](name string, client *Client, codec Codec[T], opts ...g.NodeOption[T]) (g.Node[T], error) {
+	if client == nil {
+		return nil, fmt.Errorf("remote agent node creation error for name %s: %w", name, ErrClientNil)
+	}
+	if codec == nil {
+		return nil, fmt.Errorf("remote agent node creation error for name %s: %w", name, ErrCodecNil)
+	}
+
+	return b.NewContextNode(name, func(ctx context.Context, userInput, currentState T, notify g.NotifyPartialFn[T]) (T, error) {
+		state := currentState
+		parts, err := codec.Parts(userInput)
+		if err != nil {
+			return currentState, fmt.Errorf("cannot encode the remote agent request: %w", err)
+		}
+		if len(parts) > 0 {
+			if state, err = codec.Apply(RoleUser, parts, state); err != nil {
+				return currentState, fmt.Errorf("cannot apply the remote agent request: %w", err)
+			}
+		} else if parts, err = codec.Parts(currentState); err != nil {
+			return currentState, fmt.Errorf("cannot encode the remote agent request: %w", err)
+		}
+
+		msg := Message{Role: RoleUser, Parts: parts}
+		if threadID, ok := g.ThreadIDFromContext(ctx); ok {
+			msg.ContextID = threadID
+		}
+
+		var artifacts []Part
+		var final TaskStatus
+		err = client.Stream(ctx, msg, func(event Event) error {
+			switch {
+			case event.Artifact != nil:
+				artifacts = append(artifacts, event.Artifact.Artifact.Parts...)
+			case event.Status != nil && event.Status.Final:
+				final = event.Status.Status
+			case event.Status != nil && event.Status.Status.Message != nil:
+				if partial, err := codec.Apply(RoleAgent, event.Status.Status.Message.Parts, state); err == nil {
+					notify(partial)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return currentState, fmt.Errorf("remote agent request failed: %w", err)
+		}
+		if final.State != TaskCompleted {
+			reason := string(final.State)
+			if final.Message != nil && len(final.Message.Parts) > 0 {
+				reason = fmt.Sprintf("%s: %s", final.State, final.Message.Parts[0].Text)
+			}
+			return currentState, fmt.Errorf("%w: %s", ErrRemoteTaskFailed, reason)
+		}
+
+		rv, err := codec.Apply(RoleAgent, artifacts, state)
+		if err != nil {
+			return currentState, fmt.Errorf("cannot apply the remote agent answer: %w", err)
+		}
+		return rv, nil
+	}, opts...)
+}
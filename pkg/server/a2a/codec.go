@@ -0,0 +1,113 @@
+package a2a
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// ErrNoDataPart indicates that a message carries no data part to decode the state from.
+var ErrNoDataPart = errors.New("no data part in the message")
+
+// Codec maps the state of a graph to the parts of the A2A messages and artifacts.
+type Codec[T g.SharedState] interface {
+	// Parts encodes the state as the parts of an outgoing message or artifact.
+	//
+	// Parameters:
+	//   - state: The state.
+	//
+	// Returns:
+	//   - The parts.
+	//   - An error if the state cannot be encoded.
+	Parts(state T) ([]Part, error)
+	// Apply decodes the parts of an incoming message or artifact into the state.
+	//
+	// Parameters:
+	//   - role: The author of the parts.
+	//   - parts: The parts.
+	//   - state: The state to update, the zero value for a new task.
+	//
+	// Returns:
+	//   - The updated state.
+	//   - An error if the parts cannot be decoded.
+	Apply(role Role, parts []Part, state T) (T, error)
+}
+
+// ConversationCodec maps a conversation to text parts.
+//
+// A conversation is encoded as the text of its last message; the text parts of an incoming
+// message are joined and appended to the conversation as a user message, or as an assistant
+// message when authored by the agent.
+//
+// Returns:
+//   - The Codec.
+func ConversationCodec() Codec[a.Conversation] {
+	return conversationCodec{}
+}
+
+type conversationCodec struct{}
+
+func (conversationCodec) Parts(state a.Conversation) ([]Part, error) {
+	if len(state.Messages) == 0 {
+		return []Part{}, nil
+	}
+	return []Part{TextPart(state.Messages[len(state.Messages)-1].Content)}, nil
+}
+
+func (conversationCodec) Apply(role Role, parts []Part, state a.Conversation) (a.Conversation, error) {
+	var texts []string
+	for _, part := range parts {
+		if part.Kind == KindText {
+			texts = append(texts, part.Text)
+		}
+	}
+	if len(texts) == 0 {
+		return state, nil
+	}
+
+	useRole := a.User
+	if role == RoleAgent {
+		useRole = a.Assistant
+	}
+	rv := state.Clone()
+	rv.Messages = append(rv.Messages, a.CreateMessage(useRole, strings.Join(texts, "\n")))
+	return rv, nil
+}
+
+// DataCodec maps the state to a single JSON data part.
+//
+// The incoming data part replaces the state.
+//
+// Returns:
+//   - The Codec.
+func DataCodec[T g.SharedState]() Codec[T] {
+	return dataCodec[T]{}
+}
+
+type dataCodec[T g.SharedState] struct{}
+
+func (dataCodec[T]) Parts(state T) ([]Part, error) {
+	part, err := DataPart(state)
+	if err != nil {
+		return nil, err
+	}
+	return []Part{part}, nil
+}
+
+func (dataCodec[T]) Apply(role Role, parts []Part, state T) (T, error) {
+	for _, part := range parts {
+		if part.Kind != KindData {
+			continue
+		}
+		var rv T
+		if err := json.Unmarshal(part.Data, &rv); err != nil {
+			return state, fmt.Errorf("cannot decode the data part: %w", err)
+		}
+		return rv, nil
+	}
+	return state, ErrNoDataPart
+}
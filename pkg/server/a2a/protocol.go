@@ -0,0 +1,340 @@
+// Package a2a implements the Agent-to-Agent (A2A) protocol on top of a graph runtime.
+//
+// The Server exposes a runtime as a remote agent speaking JSON-RPC 2.0 over HTTP: a message
+// creates or continues a task, whose updates can be streamed with server-sent events and whose
+// final state is returned as artifacts. The Client calls remote A2A agents, and NewRemoteAgentNode
+// embeds one of them as a node of a graph, so that agents built with different frameworks
+// cooperate in the same multi-agent system.
+//
+// A task is a thread of the runtime: the task ID, and its context ID, are the thread ID.
+package a2a
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ProtocolVersion is the version of the A2A protocol implemented by the package.
+const ProtocolVersion = "0.3.0"
+
+// AgentCardPath is the well-known path of the agent card.
+const AgentCardPath = "/.well-known/agent-card.json"
+
+// JSON-RPC methods.
+const (
+	// MethodSendMessage sends a message, creating or continuing a task.
+	MethodSendMessage = "message/send"
+	// MethodStreamMessage sends a message and streams the task updates.
+	MethodStreamMessage = "message/stream"
+	// MethodGetTask returns a task.
+	MethodGetTask = "tasks/get"
+	// MethodCancelTask cancels a task.
+	MethodCancelTask = "tasks/cancel"
+)
+
+// JSON-RPC error codes.
+const (
+	// CodeParseError indicates an invalid JSON payload.
+	CodeParseError = -32700
+	// CodeInvalidRequest indicates an invalid JSON-RPC request.
+	CodeInvalidRequest = -32600
+	// CodeMethodNotFound indicates an unsupported method.
+	CodeMethodNotFound = -32601
+	// CodeInvalidParams indicates invalid method parameters.
+	CodeInvalidParams = -32602
+	// CodeInternalError indicates a server failure.
+	CodeInternalError = -32603
+	// CodeTaskNotFound indicates an unknown task.
+	CodeTaskNotFound = -32001
+	// CodeTaskNotCancelable indicates a task in a terminal state.
+	CodeTaskNotCancelable = -32002
+)
+
+// Role is the author of a message.
+type Role string
+
+const (
+	// RoleUser is a message of the client.
+	RoleUser Role = "user"
+	// RoleAgent is a message of the agent.
+	RoleAgent Role = "agent"
+)
+
+// TaskState is the lifecycle state of a task.
+type TaskState string
+
+const (
+	// TaskSubmitted is a task received and not yet started.
+	TaskSubmitted TaskState = "submitted"
+	// TaskWorking is a running task.
+	TaskWorking TaskState = "working"
+	// TaskInputRequired is a task waiting for a message of the client.
+	TaskInputRequired TaskState = "input-required"
+	// TaskCompleted is a task ended successfully.
+	TaskCompleted TaskState = "completed"
+	// TaskCanceled is a task cancelled by the client.
+	TaskCanceled TaskState = "canceled"
+	// TaskFailed is a task ended with an error.
+	TaskFailed TaskState = "failed"
+)
+
+// Terminal reports whether the state ends the task.
+//
+// Returns:
+//   - true for the completed, canceled and failed states.
+func (s TaskState) Terminal() bool {
+	return s == TaskCompleted || s == TaskCanceled || s == TaskFailed
+}
+
+// Kinds of the objects and of the parts.
+const (
+	// KindMessage is the kind of a Message.
+	KindMessage = "message"
+	// KindTask is the kind of a Task.
+	KindTask = "task"
+	// KindStatusUpdate is the kind of a TaskStatusUpdateEvent.
+	KindStatusUpdate = "status-update"
+	// KindArtifactUpdate is the kind of a TaskArtifactUpdateEvent.
+	KindArtifactUpdate = "artifact-update"
+	// KindText is the kind of a text Part.
+	KindText = "text"
+	// KindData is the kind of a structured data Part.
+	KindData = "data"
+)
+
+// AgentCard describes a remote agent.
+type AgentCard struct {
+	// Name is the name of the agent.
+	Name string `json:"name"`
+	// Description describes what the agent does.
+	Description string `json:"description"`
+	// URL is the JSON-RPC endpoint of the agent.
+	URL string `json:"url"`
+	// Version is the version of the agent.
+	Version string `json:"version"`
+	// ProtocolVersion is the A2A version spoken by the agent.
+	ProtocolVersion string `json:"protocolVersion"`
+	// Capabilities are the optional features of the agent.
+	Capabilities AgentCapabilities `json:"capabilities"`
+	// DefaultInputModes are the media types accepted by the agent.
+	DefaultInputModes []string `json:"defaultInputModes"`
+	// DefaultOutputModes are the media types produced by the agent.
+	DefaultOutputModes []string `json:"defaultOutputModes"`
+	// Skills are the abilities of the agent.
+	Skills []AgentSkill `json:"skills"`
+}
+
+// AgentCapabilities are the optional features of an agent.
+type AgentCapabilities struct {
+	// Streaming is true when message/stream is supported.
+	Streaming bool `json:"streaming"`
+}
+
+// AgentSkill is an ability of an agent.
+type AgentSkill struct {
+	// ID identifies the skill.
+	ID string `json:"id"`
+	// Name is the name of the skill.
+	Name string `json:"name"`
+	// Description describes the skill.
+	Description string `json:"description"`
+	// Tags classify the skill.
+	Tags []string `json:"tags"`
+	// Examples are sample prompts of the skill.
+	Examples []string `json:"examples,omitempty"`
+}
+
+// Part is a piece of content of a message or an artifact.
+type Part struct {
+	// Kind is KindText or KindData.
+	Kind string `json:"kind"`
+	// Text is the content of a text part.
+	Text string `json:"text,omitempty"`
+	// Data is the content of a data part.
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// TextPart creates a text part.
+//
+// Parameters:
+//   - text: The text.
+//
+// Returns:
+//   - The Part.
+func TextPart(text string) Part {
+	return Part{Kind: KindText, Text: text}
+}
+
+// DataPart creates a structured data part.
+//
+// Parameters:
+//   - value: The value, encoded as JSON.
+//
+// Returns:
+//   - The Part.
+//   - An error if the value cannot be encoded.
+func DataPart(value any) (Part, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return Part{}, fmt.Errorf("cannot encode the data part: %w", err)
+	}
+	return Part{Kind: KindData, Data: data}, nil
+}
+
+// Message is a turn of the conversation between the client and the agent.
+type Message struct {
+	// Kind is KindMessage.
+	Kind string `json:"kind"`
+	// MessageID identifies the message.
+	MessageID string `json:"messageId"`
+	// Role is the author of the message.
+	Role Role `json:"role"`
+	// Parts is the content of the message.
+	Parts []Part `json:"parts"`
+	// TaskID is the task the message continues, empty to create a task.
+	TaskID string `json:"taskId,omitempty"`
+	// ContextID groups the tasks of the same conversation.
+	ContextID string `json:"contextId,omitempty"`
+}
+
+// TaskStatus is the status of a task.
+type TaskStatus struct {
+	// State is the lifecycle state.
+	State TaskState `json:"state"`
+	// Message is an optional message of the agent, e.g. the error of a failed task.
+	Message *Message `json:"message,omitempty"`
+	// Timestamp is the RFC 3339 time of the status.
+	Timestamp string `json:"timestamp,omitempty"`
+}
+
+// Artifact is an output of a task.
+type Artifact struct {
+	// ArtifactID identifies the artifact.
+	ArtifactID string `json:"artifactId"`
+	// Name is the name of the artifact.
+	Name string `json:"name,omitempty"`
+	// Parts is the content of the artifact.
+	Parts []Part `json:"parts"`
+}
+
+// Task is a unit of work of an agent.
+type Task struct {
+	// Kind is KindTask.
+	Kind string `json:"kind"`
+	// ID identifies the task.
+	ID string `json:"id"`
+	// ContextID groups the tasks of the same conversation.
+	ContextID string `json:"contextId"`
+	// Status is the current status.
+	Status TaskStatus `json:"status"`
+	// Artifacts are the outputs of the task.
+	Artifacts []Artifact `json:"artifacts,omitempty"`
+}
+
+// TaskStatusUpdateEvent is streamed when the status of a task changes.
+type TaskStatusUpdateEvent struct {
+	// Kind is KindStatusUpdate.
+	Kind string `json:"kind"`
+	// TaskID is the task.
+	TaskID string `json:"taskId"`
+	// ContextID is the context of the task.
+	ContextID string `json:"contextId"`
+	// Status is the new status.
+	Status TaskStatus `json:"status"`
+	// Final is true for the last event of the stream.
+	Final bool `json:"final"`
+}
+
+// TaskArtifactUpdateEvent is streamed when a task produces an artifact.
+type TaskArtifactUpdateEvent struct {
+	// Kind is KindArtifactUpdate.
+	Kind string `json:"kind"`
+	// TaskID is the task.
+	TaskID string `json:"taskId"`
+	// ContextID is the context of the task.
+	ContextID string `json:"contextId"`
+	// Artifact is the artifact.
+	Artifact Artifact `json:"artifact"`
+	// LastChunk is true for the last chunk of the artifact.
+	LastChunk bool `json:"lastChunk"`
+}
+
+// MessageSendParams are the parameters of message/send and message/stream.
+type MessageSendParams struct {
+	// Message is the message of the client.
+	Message Message `json:"message"`
+	// Configuration tunes the request.
+	Configuration *MessageSendConfiguration `json:"configuration,omitempty"`
+}
+
+// MessageSendConfiguration tunes message/send.
+type MessageSendConfiguration struct {
+	// Blocking waits for the task to end before answering; when false the task is returned as soon as it is submitted.
+	Blocking *bool `json:"blocking,omitempty"`
+}
+
+// TaskIDParams are the parameters of tasks/get and tasks/cancel.
+type TaskIDParams struct {
+	// ID is the task.
+	ID string `json:"id"`
+}
+
+// Event is an update streamed by message/stream, exactly one of its fields is set.
+type Event struct {
+	// Task is the task, as first streamed.
+	Task *Task
+	// Status is a status update.
+	Status *TaskStatusUpdateEvent
+	// Artifact is an artifact update.
+	Artifact *TaskArtifactUpdateEvent
+}
+
+// UnmarshalJSON decodes the event by its kind.
+func (e *Event) UnmarshalJSON(data []byte) error {
+	var head struct {
+		Kind string `json:"kind"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return err
+	}
+	switch head.Kind {
+	case KindTask:
+		e.Task = &Task{}
+		return json.Unmarshal(data, e.Task)
+	case KindStatusUpdate:
+		e.Status = &TaskStatusUpdateEvent{}
+		return json.Unmarshal(data, e.Status)
+	case KindArtifactUpdate:
+		e.Artifact = &TaskArtifactUpdateEvent{}
+		return json.Unmarshal(data, e.Artifact)
+	default:
+		return fmt.Errorf("unknown event kind %q", head.Kind)
+	}
+}
+
+// Error is a JSON-RPC error.
+type Error struct {
+	// Code is the JSON-RPC error code.
+	Code int `json:"code"`
+	// Message describes the error.
+	Message string `json:"message"`
+}
+
+// Error returns the error message.
+func (e *Error) Error() string {
+	return fmt.Sprintf("a2a error %d: %s", e.Code, e.Message)
+}
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      any             `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      any             `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
@@ -0,0 +1,366 @@
+package a2a
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/server"
+)
+
+var (
+	// ErrRuntimeNil indicates that no runtime was given to the server.
+	ErrRuntimeNil = errors.New("runtime cannot be nil")
+	// ErrBrokerNil indicates that no broker was given to the server.
+	ErrBrokerNil = errors.New("broker cannot be nil")
+	// ErrCodecNil indicates that no codec was given.
+	ErrCodecNil = errors.New("codec cannot be nil")
+)
+
+// Server exposes a runtime as an A2A agent.
+type Server[T g.SharedState] struct {
+	runtime g.Runtime[T]
+	broker  *server.Broker[T]
+	codec   Codec[T]
+	card    AgentCard
+
+	mu    sync.Mutex
+	tasks map[string]*taskRecord
+}
+
+type taskRecord struct {
+	task Task
+	done chan struct{}
+}
+
+// NewServer creates the A2A endpoint of a runtime.
+//
+// The tasks run detached from the requests: a client going away does not cancel its task,
+// tasks/cancel does. The card is served at AgentCardPath, its protocol version and streaming
+// capability being filled in by the server.
+//
+// Parameters:
+//   - runtime: The runtime executing the graph.
+//   - broker: The broker dispatching the runtime state monitor entries.
+//   - codec: The codec of the state, see ConversationCodec and DataCodec.
+//   - card: The agent card describing the graph.
+//
+// Returns:
+//   - The Server, an http.Handler.
+//   - An error if any argument is nil.
+//
+// Example:
+//
+//	broker := server.NewBroker(stateMonitorCh, nil)
+//	srv, _ := a2a.NewServer(runtime, broker, a2a.ConversationCodec(), a2a.AgentCard{
+//	    Name: "Researcher", Description: "Answers research questions", URL: "https://agents.example.com/researcher",
+//	})
+//	http.Handle("/", srv)
+func NewServer[T g.SharedState](runtime g.Runtime[T], broker *server.Broker[T], codec Codec[T], card AgentCard) (*Server[T], error) {
+	if runtime == nil {
+		return nil, fmt.Errorf("a2a server creation failed: %w", ErrRuntimeNil)
+	}
+	if broker == nil {
+		return nil, fmt.Errorf("a2a server creation failed: %w", ErrBrokerNil)
+	}
+	if codec == nil {
+		return nil, fmt.Errorf("a2a server creation failed: %w", ErrCodecNil)
+	}
+
+	card.ProtocolVersion = ProtocolVersion
+	card.Capabilities.Streaming = true
+	if card.DefaultInputModes == nil {
+		card.DefaultInputModes = []string{"text/plain", "application/json"}
+	}
+	if card.DefaultOutputModes == nil {
+		card.DefaultOutputModes = []string{"text/plain", "application/json"}
+	}
+	if card.Skills == nil {
+		card.Skills = []AgentSkill{}
+	}
+
+	return &Server[T]{runtime: runtime, broker: broker, codec: codec, card: card, tasks: make(map[string]*taskRecord)}, nil
+}
+
+// ServeHTTP serves the agent card and the JSON-RPC methods.
+func (s *Server[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet && r.URL.Path == AgentCardPath {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(s.card)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req rpcRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(w, nil, nil, &Error{Code: CodeParseError, Message: err.Error()})
+		return
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		writeResponse(w, req.ID, nil, &Error{Code: CodeInvalidRequest, Message: "invalid JSON-RPC 2.0 request"})
+		return
+	}
+
+	switch req.Method {
+	case MethodSendMessage:
+		var params MessageSendParams
+		if rpcErr := decodeParams(req.Params, &params); rpcErr != nil {
+			writeResponse(w, req.ID, nil, rpcErr)
+			return
+		}
+		blocking := params.Configuration == nil || params.Configuration.Blocking == nil || *params.Configuration.Blocking
+		task, rpcErr := s.send(r.Context(), params.Message, blocking)
+		writeResponse(w, req.ID, task, rpcErr)
+	case MethodStreamMessage:
+		var params MessageSendParams
+		if rpcErr := decodeParams(req.Params, &params); rpcErr != nil {
+			writeResponse(w, req.ID, nil, rpcErr)
+			return
+		}
+		s.stream(r.Context(), w, req.ID, params.Message)
+	case MethodGetTask:
+		var params TaskIDParams
+		if rpcErr := decodeParams(req.Params, &params); rpcErr != nil {
+			writeResponse(w, req.ID, nil, rpcErr)
+			return
+		}
+		task, rpcErr := s.get(params.ID)
+		writeResponse(w, req.ID, task, rpcErr)
+	case MethodCancelTask:
+		var params TaskIDParams
+		if rpcErr := decodeParams(req.Params, &params); rpcErr != nil {
+			writeResponse(w, req.ID, nil, rpcErr)
+			return
+		}
+		task, rpcErr := s.cancel(r.Context(), params.ID)
+		writeResponse(w, req.ID, task, rpcErr)
+	default:
+		writeResponse(w, req.ID, nil, &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("method %q not found", req.Method)})
+	}
+}
+
+func (s *Server[T]) send(ctx context.Context, msg Message, blocking bool) (*Task, *Error) {
+	record, rpcErr := s.start(msg, nil)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if blocking {
+		select {
+		case <-record.done:
+		case <-ctx.Done():
+		}
+	}
+	return s.snapshot(record), nil
+}
+
+func (s *Server[T]) stream(ctx context.Context, w http.ResponseWriter, id any, msg Message) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeResponse(w, id, nil, &Error{Code: CodeInternalError, Message: "streaming is not supported by the connection"})
+		return
+	}
+
+	var sub *server.Subscription[T]
+	var unsubscribe func()
+	record, rpcErr := s.start(msg, func(threadID string) {
+		sub, unsubscribe = s.broker.Subscribe(threadID, 0)
+	})
+	if rpcErr != nil {
+		writeResponse(w, id, nil, rpcErr)
+		return
+	}
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	send := func(event any) {
+		writeEvent(w, id, event)
+		flusher.Flush()
+	}
+
+	task := s.snapshot(record)
+	send(task)
+	working := func(entry g.StateMonitorEntry[T]) {
+		status := TaskStatus{State: TaskWorking, Timestamp: timestamp()}
+		if parts, err := s.codec.Parts(entry.NewState); err == nil && len(parts) > 0 {
+			status.Message = &Message{Kind: KindMessage, MessageID: uuid.NewString(), Role: RoleAgent, Parts: parts, TaskID: task.ID, ContextID: task.ContextID}
+		}
+		send(TaskStatusUpdateEvent{Kind: KindStatusUpdate, TaskID: task.ID, ContextID: task.ContextID, Status: status})
+	}
+	for {
+		select {
+		case entry := <-sub.Entries():
+			working(entry)
+		case <-record.done:
+			for len(sub.Entries()) > 0 {
+				working(<-sub.Entries())
+			}
+			final := s.snapshot(record)
+			for _, artifact := range final.Artifacts {
+				send(TaskArtifactUpdateEvent{Kind: KindArtifactUpdate, TaskID: final.ID, ContextID: final.ContextID, Artifact: artifact, LastChunk: true})
+			}
+			send(TaskStatusUpdateEvent{Kind: KindStatusUpdate, TaskID: final.ID, ContextID: final.ContextID, Status: final.Status, Final: true})
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// start invokes the thread of the message, subscribing the caller with the optional subscribe function first.
+func (s *Server[T]) start(msg Message, subscribe func(threadID string)) (*taskRecord, *Error) {
+	threadID := msg.TaskID
+	if threadID == "" {
+		threadID = msg.ContextID
+	}
+	if threadID == "" {
+		threadID = s.runtime.NewThreadID()
+	}
+
+	var zero T
+	userInput, err := s.codec.Apply(RoleUser, msg.Parts, zero)
+	if err != nil {
+		return nil, &Error{Code: CodeInvalidParams, Message: err.Error()}
+	}
+
+	s.mu.Lock()
+	if existing, ok := s.tasks[threadID]; ok && !existing.task.Status.State.Terminal() {
+		s.mu.Unlock()
+		return nil, &Error{Code: CodeInvalidRequest, Message: fmt.Sprintf("task %s is still running", threadID)}
+	}
+	record := &taskRecord{
+		task: Task{
+			Kind:      KindTask,
+			ID:        threadID,
+			ContextID: threadID,
+			Status:    TaskStatus{State: TaskWorking, Timestamp: timestamp()},
+		},
+		done: make(chan struct{}),
+	}
+	s.tasks[threadID] = record
+	s.mu.Unlock()
+
+	if subscribe != nil {
+		subscribe(threadID)
+	}
+	sub, unsubscribe := s.broker.Subscribe(threadID, 1)
+	go s.track(record, sub, unsubscribe)
+
+	s.runtime.Invoke(userInput, g.InvokeConfigThreadID(threadID))
+	return record, nil
+}
+
+// track records the outcome of the task once its thread ends.
+func (s *Server[T]) track(record *taskRecord, sub *server.Subscription[T], unsubscribe func()) {
+	defer unsubscribe()
+	final := sub.Final()
+
+	status := TaskStatus{State: TaskCompleted, Timestamp: timestamp()}
+	var artifacts []Artifact
+	switch {
+	case errors.Is(final.Error, g.ErrThreadCancelled) || errors.Is(final.Error, context.Canceled):
+		status.State = TaskCanceled
+	case final.Error != nil:
+		status.State = TaskFailed
+		status.Message = &Message{Kind: KindMessage, MessageID: uuid.NewString(), Role: RoleAgent, Parts: []Part{TextPart(final.Error.Error())}}
+	default:
+		parts, err := s.codec.Parts(final.NewState)
+		if err != nil {
+			status.State = TaskFailed
+			status.Message = &Message{Kind: KindMessage, MessageID: uuid.NewString(), Role: RoleAgent, Parts: []Part{TextPart(err.Error())}}
+			break
+		}
+		artifacts = []Artifact{{ArtifactID: uuid.NewString(), Name: "result", Parts: parts}}
+	}
+
+	s.mu.Lock()
+	record.task.Status = status
+	record.task.Artifacts = artifacts
+	s.mu.Unlock()
+	close(record.done)
+}
+
+func (s *Server[T]) get(taskID string) (*Task, *Error) {
+	s.mu.Lock()
+	record, ok := s.tasks[taskID]
+	s.mu.Unlock()
+	if !ok {
+		return nil, &Error{Code: CodeTaskNotFound, Message: fmt.Sprintf("task %s not found", taskID)}
+	}
+	return s.snapshot(record), nil
+}
+
+func (s *Server[T]) cancel(ctx context.Context, taskID string) (*Task, *Error) {
+	task, rpcErr := s.get(taskID)
+	if rpcErr != nil {
+		return nil, rpcErr
+	}
+	if task.Status.State.Terminal() {
+		return nil, &Error{Code: CodeTaskNotCancelable, Message: fmt.Sprintf("task %s is %s", taskID, task.Status.State)}
+	}
+	if err := s.runtime.Cancel(taskID); err != nil {
+		return nil, &Error{Code: CodeTaskNotCancelable, Message: err.Error()}
+	}
+
+	s.mu.Lock()
+	record := s.tasks[taskID]
+	s.mu.Unlock()
+	select {
+	case <-record.done:
+	case <-ctx.Done():
+	}
+	return s.snapshot(record), nil
+}
+
+func (s *Server[T]) snapshot(record *taskRecord) *Task {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rv := record.task
+	rv.Artifacts = append([]Artifact(nil), record.task.Artifacts...)
+	return &rv
+}
+
+func decodeParams(raw json.RawMessage, params any) *Error {
+	if err := json.Unmarshal(raw, params); err != nil {
+		return &Error{Code: CodeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func writeResponse(w http.ResponseWriter, id any, result any, rpcErr *Error) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response(id, result, rpcErr))
+}
+
+func writeEvent(w http.ResponseWriter, id any, event any) {
+	data, _ := json.Marshal(response(id, event, nil))
+	fmt.Fprintf(w, "data: %s\n\n", data)
+}
+
+func response(id any, result any, rpcErr *Error) rpcResponse {
+	rv := rpcResponse{JSONRPC: "2.0", ID: id, Error: rpcErr}
+	if rpcErr == nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			rv.Error = &Error{Code: CodeInternalError, Message: err.Error()}
+			return rv
+		}
+		rv.Result = data
+	}
+	return rv
+}
+
+func timestamp() string {
+	return time.Now().UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"sync"
+
+	"github.com/morphy76/ggraph/pkg/agent/tool"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// ToolApprovalPrefix prefixes the tool name in the node of the interrupts waiting for a tool approval.
+const ToolApprovalPrefix = "approve:"
+
+// ToolApprover creates a tool approval function waiting for the decision of a remote client.
+//
+// The approval of a tool call is an interrupt of the thread: the call waits on the mailbox as
+// the node ToolApprovalPrefix followed by the tool name, until the client delivers the
+// tool.Approval, e.g. through the websocket handler. The approvals of the parallel calls of
+// a thread are asked one at a time.
+//
+// Parameters:
+//   - mailbox: The mailbox delivering the approvals.
+//
+// Returns:
+//   - The approval function, to be given to tool.RequireApproval.
+//
+// Example:
+//
+//	approvals := server.NewMailbox[tool.Approval]()
+//	sendMail, _ = sendMail.WithPolicy(tool.RequireApproval(server.ToolApprover(approvals)))
+//	...
+//	_ = approvals.Deliver(threadID, tool.Approval{Approved: true})
+func ToolApprover(mailbox *Mailbox[tool.Approval]) tool.ApproveFn {
+	type turn struct {
+		token   chan struct{}
+		waiters int
+	}
+	var (
+		mu    sync.Mutex
+		turns = make(map[string]*turn)
+	)
+	return func(ctx context.Context, call tool.FnCall) (tool.Approval, error) {
+		threadID, ok := g.ThreadIDFromContext(ctx)
+		if !ok {
+			return tool.Approval{}, ErrThreadIDMissing
+		}
+
+		mu.Lock()
+		useTurn, ok := turns[threadID]
+		if !ok {
+			useTurn = &turn{token: make(chan struct{}, 1)}
+			turns[threadID] = useTurn
+		}
+		useTurn.waiters++
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			defer mu.Unlock()
+			if useTurn.waiters--; useTurn.waiters == 0 {
+				delete(turns, threadID)
+			}
+		}()
+
+		select {
+		case useTurn.token <- struct{}{}:
+		case <-ctx.Done():
+			return tool.Approval{}, context.Cause(ctx)
+		}
+		defer func() { <-useTurn.token }()
+
+		return mailbox.Wait(ctx, threadID, ToolApprovalPrefix+call.ToolName)
+	}
+}
@@ -0,0 +1,62 @@
+package server_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/morphy76/ggraph/pkg/agent/tool"
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/server"
+)
+
+func TestToolApprover(t *testing.T) {
+	mailbox := server.NewMailbox[tool.Approval]()
+	approve := server.ToolApprover(mailbox)
+
+	ctx, cancel := context.WithTimeout(g.ContextWithThreadID(context.Background(), "thread-1"), 5*time.Second)
+	defer cancel()
+	interrupts, unwatch := mailbox.Watch("thread-1")
+	defer unwatch()
+
+	var wg sync.WaitGroup
+	decisions := make(chan tool.Approval, 2)
+	for _, name := range []string{"send_mail", "delete_file"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			approval, err := approve(ctx, tool.FnCall{ToolName: name})
+			if err != nil {
+				t.Errorf("approval of %s failed: %v", name, err)
+			}
+			decisions <- approval
+		}()
+	}
+
+	for range 2 {
+		select {
+		case interrupt := <-interrupts:
+			if interrupt.Node != server.ToolApprovalPrefix+"send_mail" && interrupt.Node != server.ToolApprovalPrefix+"delete_file" {
+				t.Errorf("unexpected interrupt node %s", interrupt.Node)
+			}
+			if err := mailbox.Deliver("thread-1", tool.Approval{Approved: true}); err != nil {
+				t.Errorf("Deliver failed: %v", err)
+			}
+		case <-ctx.Done():
+			t.Fatal("timeout waiting for the approvals")
+		}
+	}
+	wg.Wait()
+	close(decisions)
+	for decision := range decisions {
+		if !decision.Approved {
+			t.Errorf("expected the calls to be approved")
+		}
+	}
+
+	if _, err := approve(context.Background(), tool.FnCall{ToolName: "send_mail"}); !errors.Is(err, server.ErrThreadIDMissing) {
+		t.Errorf("expected ErrThreadIDMissing, got %v", err)
+	}
+}
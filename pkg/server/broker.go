@@ -0,0 +1,165 @@
+// Package server provides the building blocks shared by the transports exposing a graph runtime to remote clients.
+package server
+
+import (
+	"sync"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// DefaultSubscriptionBufferSize is the default number of non-terminal entries buffered for a subscriber.
+const DefaultSubscriptionBufferSize = 100
+
+// Subscription receives the state monitor entries of a thread.
+//
+// Non-terminal entries are delivered through Entries and dropped when the subscriber does not keep up;
+// the terminal entry, the one with Running set to false, is never dropped and is available from Done.
+type Subscription[T g.SharedState] struct {
+	entries chan g.StateMonitorEntry[T]
+	done    chan struct{}
+	final   g.StateMonitorEntry[T]
+	once    sync.Once
+}
+
+// Entries returns the channel of the non-terminal entries.
+func (s *Subscription[T]) Entries() <-chan g.StateMonitorEntry[T] {
+	return s.entries
+}
+
+// Done returns a channel closed when the terminal entry is received.
+func (s *Subscription[T]) Done() <-chan struct{} {
+	return s.done
+}
+
+// Final returns the terminal entry, valid once Done is closed.
+func (s *Subscription[T]) Final() g.StateMonitorEntry[T] {
+	<-s.done
+	return s.final
+}
+
+func (s *Subscription[T]) complete(entry g.StateMonitorEntry[T]) {
+	s.once.Do(func() {
+		s.final = entry
+		close(s.done)
+	})
+}
+
+// Broker dispatches the entries of a runtime state monitor channel to the subscribers of each thread.
+//
+// A runtime has a single state monitor channel, the broker lets several remote clients
+// observe the threads they invoked.
+type Broker[T g.SharedState] struct {
+	mu          sync.Mutex
+	subscribers map[string]map[*Subscription[T]]struct{}
+	forward     chan<- g.StateMonitorEntry[T]
+	stopped     chan struct{}
+}
+
+// NewBroker creates a broker consuming the given state monitor channel until it is closed or the broker is stopped.
+//
+// Parameters:
+//   - stateMonitorCh: The state monitor channel given to the runtime.
+//   - forward: An optional channel receiving every entry, for local observers; entries are dropped when it is full.
+//
+// Returns:
+//   - A running Broker.
+//
+// Example:
+//
+//	stateMonitorCh := make(chan g.StateMonitorEntry[MyState], 100)
+//	runtime, _ := builders.CreateRuntime(startEdge, stateMonitorCh)
+//	broker := server.NewBroker(stateMonitorCh, nil)
+//	defer broker.Stop()
+func NewBroker[T g.SharedState](stateMonitorCh <-chan g.StateMonitorEntry[T], forward chan<- g.StateMonitorEntry[T]) *Broker[T] {
+	rv := &Broker[T]{
+		subscribers: make(map[string]map[*Subscription[T]]struct{}),
+		forward:     forward,
+		stopped:     make(chan struct{}),
+	}
+	go rv.dispatch(stateMonitorCh)
+	return rv
+}
+
+// Subscribe registers a subscriber for the entries of a thread.
+//
+// Subscribe before invoking the thread so that no entry is missed.
+//
+// Parameters:
+//   - threadID: The thread to observe.
+//   - bufferSize: The number of non-terminal entries buffered, DefaultSubscriptionBufferSize if not positive.
+//
+// Returns:
+//   - The Subscription.
+//   - A function removing the subscription.
+func (b *Broker[T]) Subscribe(threadID string, bufferSize int) (*Subscription[T], func()) {
+	if bufferSize < 1 {
+		bufferSize = DefaultSubscriptionBufferSize
+	}
+	sub := &Subscription[T]{
+		entries: make(chan g.StateMonitorEntry[T], bufferSize),
+		done:    make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subscribers[threadID]; !ok {
+		b.subscribers[threadID] = make(map[*Subscription[T]]struct{})
+	}
+	b.subscribers[threadID][sub] = struct{}{}
+
+	return sub, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.subscribers[threadID], sub)
+		if len(b.subscribers[threadID]) == 0 {
+			delete(b.subscribers, threadID)
+		}
+	}
+}
+
+// Stop stops the dispatching of the entries.
+func (b *Broker[T]) Stop() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	select {
+	case <-b.stopped:
+	default:
+		close(b.stopped)
+	}
+}
+
+func (b *Broker[T]) dispatch(stateMonitorCh <-chan g.StateMonitorEntry[T]) {
+	for {
+		select {
+		case <-b.stopped:
+			return
+		case entry, ok := <-stateMonitorCh:
+			if !ok {
+				return
+			}
+			b.publish(entry)
+		}
+	}
+}
+
+func (b *Broker[T]) publish(entry g.StateMonitorEntry[T]) {
+	if b.forward != nil {
+		select {
+		case b.forward <- entry:
+		default:
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for sub := range b.subscribers[entry.ThreadID] {
+		if !entry.Running {
+			sub.complete(entry)
+			continue
+		}
+		select {
+		case sub.entries <- entry:
+		default:
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package grpc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// StateCodec converts the graph state from and to the protobuf value carried by the service messages.
+type StateCodec[T g.SharedState] interface {
+	// Encode converts the state into a protobuf value.
+	Encode(state T) (*structpb.Value, error)
+	// Decode converts a protobuf value into the state.
+	Decode(value *structpb.Value) (T, error)
+}
+
+// JSONCodec creates a StateCodec carrying the JSON representation of the state.
+//
+// JSON numbers are carried as doubles: integers beyond 2^53 lose precision.
+//
+// Returns:
+//   - A StateCodec based on encoding/json.
+//
+// Example:
+//
+//	srv, err := grpc.NewServer(runtime, broker, grpc.JSONCodec[MyState]())
+func JSONCodec[T g.SharedState]() StateCodec[T] {
+	return jsonCodec[T]{}
+}
+
+// AnyCodec creates a StateCodec for protobuf states, carried as google.protobuf.Any in its JSON form.
+//
+// The state message type must be registered in the global protobuf registry, as generated types are.
+//
+// Parameters:
+//   - newFn: A function creating an empty state message to decode into.
+//
+// Returns:
+//   - A StateCodec based on google.protobuf.Any.
+//
+// Example:
+//
+//	codec := grpc.AnyCodec(func() *pb.MyState { return &pb.MyState{} })
+func AnyCodec[T proto.Message](newFn func() T) StateCodec[T] {
+	return anyCodec[T]{newFn: newFn}
+}
+
+type jsonCodec[T g.SharedState] struct{}
+
+func (jsonCodec[T]) Encode(state T) (*structpb.Value, error) {
+	raw, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode the state: %w", err)
+	}
+	rv := &structpb.Value{}
+	if err := rv.UnmarshalJSON(raw); err != nil {
+		return nil, fmt.Errorf("cannot encode the state: %w", err)
+	}
+	return rv, nil
+}
+
+func (jsonCodec[T]) Decode(value *structpb.Value) (T, error) {
+	var rv T
+	if value == nil {
+		return rv, nil
+	}
+	raw, err := value.MarshalJSON()
+	if err != nil {
+		return rv, fmt.Errorf("cannot decode the state: %w", err)
+	}
+	if err := json.Unmarshal(raw, &rv); err != nil {
+		return rv, fmt.Errorf("cannot decode the state: %w", err)
+	}
+	return rv, nil
+}
+
+type anyCodec[T proto.Message] struct {
+	newFn func() T
+}
+
+func (c anyCodec[T]) Encode(state T) (*structpb.Value, error) {
+	packed, err := anypb.New(state)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode the state: %w", err)
+	}
+	raw, err := protojson.Marshal(packed)
+	if err != nil {
+		return nil, fmt.Errorf("cannot encode the state: %w", err)
+	}
+	rv := &structpb.Value{}
+	if err := rv.UnmarshalJSON(raw); err != nil {
+		return nil, fmt.Errorf("cannot encode the state: %w", err)
+	}
+	return rv, nil
+}
+
+func (c anyCodec[T]) Decode(value *structpb.Value) (T, error) {
+	rv := c.newFn()
+	if value == nil {
+		return rv, nil
+	}
+	raw, err := value.MarshalJSON()
+	if err != nil {
+		return rv, fmt.Errorf("cannot decode the state: %w", err)
+	}
+	packed := &anypb.Any{}
+	if err := protojson.Unmarshal(raw, packed); err != nil {
+		return rv, fmt.Errorf("cannot decode the state: %w", err)
+	}
+	if err := packed.UnmarshalTo(rv); err != nil {
+		return rv, fmt.Errorf("cannot decode the state: %w", err)
+	}
+	return rv, nil
+}
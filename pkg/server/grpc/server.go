@@ -0,0 +1,256 @@
+// Package grpc exposes a graph runtime as a gRPC workflow service callable from other languages.
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/server"
+)
+
+var (
+	// ErrRuntimeNil indicates that no runtime was given to the server.
+	ErrRuntimeNil = errors.New("runtime cannot be nil")
+	// ErrBrokerNil indicates that no broker was given to the server.
+	ErrBrokerNil = errors.New("broker cannot be nil")
+	// ErrCodecNil indicates that no state codec was given to the server.
+	ErrCodecNil = errors.New("state codec cannot be nil")
+)
+
+var _ GraphServiceServer = (*Server[g.SharedState])(nil)
+
+// Server implements the graph service on top of a runtime.
+type Server[T g.SharedState] struct {
+	runtime g.Runtime[T]
+	broker  *server.Broker[T]
+	codec   StateCodec[T]
+}
+
+// NewServer creates the graph service for a runtime.
+//
+// Invoke runs detached from the request; InvokeSync and Stream run within the request context,
+// so that a client going away cancels its invocation. The request metadata are given to the
+// invocations as InvokeConfig.Metadata, e.g. to route them with graph.MetadataSelector.
+//
+// Parameters:
+//   - runtime: The runtime executing the graph.
+//   - broker: The broker dispatching the runtime state monitor entries.
+//   - codec: The codec of the state, see JSONCodec and AnyCodec.
+//
+// Returns:
+//   - The Server.
+//   - An error if any argument is nil.
+//
+// Example:
+//
+//	broker := server.NewBroker(stateMonitorCh, nil)
+//	srv, _ := grpc.NewServer(runtime, broker, grpc.JSONCodec[MyState]())
+//	grpcServer := ggrpc.NewServer()
+//	grpc.RegisterGraphServiceServer(grpcServer, srv)
+//	grpcServer.Serve(listener)
+func NewServer[T g.SharedState](runtime g.Runtime[T], broker *server.Broker[T], codec StateCodec[T]) (*Server[T], error) {
+	if runtime == nil {
+		return nil, fmt.Errorf("grpc server creation failed: %w", ErrRuntimeNil)
+	}
+	if broker == nil {
+		return nil, fmt.Errorf("grpc server creation failed: %w", ErrBrokerNil)
+	}
+	if codec == nil {
+		return nil, fmt.Errorf("grpc server creation failed: %w", ErrCodecNil)
+	}
+	return &Server[T]{runtime: runtime, broker: broker, codec: codec}, nil
+}
+
+func (s *Server[T]) Invoke(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	threadID, userInput, err := s.decodeInvocation(req)
+	if err != nil {
+		return nil, err
+	}
+
+	threadID = s.runtime.Invoke(userInput, g.InvokeConfigThreadID(threadID), invocationMetadata(ctx))
+	return threadResponse(threadID), nil
+}
+
+func (s *Server[T]) InvokeSync(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	threadID, userInput, err := s.decodeInvocation(req)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, unsubscribe := s.broker.Subscribe(threadID, 1)
+	defer unsubscribe()
+
+	s.runtime.Invoke(userInput, g.InvokeConfigThreadID(threadID), g.InvokeConfigContext(ctx), invocationMetadata(ctx))
+
+	select {
+	case <-sub.Done():
+	case <-ctx.Done():
+		return nil, status.FromContextError(ctx.Err()).Err()
+	}
+
+	final := sub.Final()
+	if final.Error != nil {
+		return nil, statusFromError(final.Error)
+	}
+	return s.encodeEntry(final)
+}
+
+func (s *Server[T]) Stream(req *structpb.Struct, stream ggrpc.ServerStreamingServer[structpb.Struct]) error {
+	threadID, userInput, err := s.decodeInvocation(req)
+	if err != nil {
+		return err
+	}
+	ctx := stream.Context()
+
+	sub, unsubscribe := s.broker.Subscribe(threadID, 0)
+	defer unsubscribe()
+
+	s.runtime.Invoke(userInput, g.InvokeConfigThreadID(threadID), g.InvokeConfigContext(ctx), invocationMetadata(ctx))
+
+	for {
+		select {
+		case entry := <-sub.Entries():
+			if err := s.send(stream, entry); err != nil {
+				return err
+			}
+		case <-sub.Done():
+			return s.finish(stream, sub)
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+func (s *Server[T]) finish(stream ggrpc.ServerStreamingServer[structpb.Struct], sub *server.Subscription[T]) error {
+	// Deliver the entries buffered before the terminal one.
+	for len(sub.Entries()) > 0 {
+		if err := s.send(stream, <-sub.Entries()); err != nil {
+			return err
+		}
+	}
+
+	final := sub.Final()
+	if err := s.send(stream, final); err != nil {
+		return err
+	}
+	if final.Error != nil {
+		return statusFromError(final.Error)
+	}
+	return nil
+}
+
+func (s *Server[T]) Cancel(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	threadID := req.GetFields()[FieldThreadID].GetStringValue()
+	if threadID == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "missing %s", FieldThreadID)
+	}
+	if err := s.runtime.Cancel(threadID); err != nil {
+		return nil, statusFromError(err)
+	}
+	return threadResponse(threadID), nil
+}
+
+func (s *Server[T]) ListThreads(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error) {
+	threads := s.runtime.ListThreads()
+	values := make([]*structpb.Value, len(threads))
+	for i, threadID := range threads {
+		values[i] = structpb.NewStringValue(threadID)
+	}
+	return &structpb.Struct{Fields: map[string]*structpb.Value{
+		FieldThreadIDs: structpb.NewListValue(&structpb.ListValue{Values: values}),
+	}}, nil
+}
+
+func (s *Server[T]) decodeInvocation(req *structpb.Struct) (string, T, error) {
+	threadID := req.GetFields()[FieldThreadID].GetStringValue()
+	if threadID == "" {
+		threadID = s.runtime.NewThreadID()
+	}
+	userInput, err := s.codec.Decode(req.GetFields()[FieldState])
+	if err != nil {
+		return "", userInput, status.Error(codes.InvalidArgument, err.Error())
+	}
+	return threadID, userInput, nil
+}
+
+// invocationMetadata returns the configuration carrying the first value of each incoming metadata of the request.
+func invocationMetadata(ctx context.Context) g.InvokeConfig {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return g.InvokeConfig{}
+	}
+	values := make(map[string]string, len(md))
+	for key, vals := range md {
+		if len(vals) > 0 {
+			values[key] = vals[0]
+		}
+	}
+	return g.InvokeConfigMetadata(values)
+}
+
+func (s *Server[T]) encodeEntry(entry g.StateMonitorEntry[T]) (*structpb.Struct, error) {
+	state, err := s.codec.Encode(entry.NewState)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	rv := &structpb.Struct{Fields: map[string]*structpb.Value{
+		FieldThreadID: structpb.NewStringValue(entry.ThreadID),
+		FieldNode:     structpb.NewStringValue(entry.Node),
+		FieldState:    state,
+		FieldRunning:  structpb.NewBoolValue(entry.Running),
+		FieldPartial:  structpb.NewBoolValue(entry.Partial),
+	}}
+	if entry.Error != nil {
+		rv.Fields[FieldError] = structpb.NewStringValue(entry.Error.Error())
+	}
+	if entry.Outcome != "" {
+		rv.Fields[FieldOutcome] = structpb.NewStringValue(entry.Outcome)
+	}
+	if !entry.Usage.IsZero() {
+		rv.Fields[FieldUsage] = structpb.NewStructValue(&structpb.Struct{Fields: map[string]*structpb.Value{
+			"prompt_tokens":     structpb.NewNumberValue(float64(entry.Usage.PromptTokens)),
+			"completion_tokens": structpb.NewNumberValue(float64(entry.Usage.CompletionTokens)),
+			"total_tokens":      structpb.NewNumberValue(float64(entry.Usage.TotalTokens)),
+		}})
+	}
+	return rv, nil
+}
+
+func (s *Server[T]) send(stream ggrpc.ServerStreamingServer[structpb.Struct], entry g.StateMonitorEntry[T]) error {
+	msg, err := s.encodeEntry(entry)
+	if err != nil {
+		return err
+	}
+	return stream.Send(msg)
+}
+
+func threadResponse(threadID string) *structpb.Struct {
+	return &structpb.Struct{Fields: map[string]*structpb.Value{
+		FieldThreadID: structpb.NewStringValue(threadID),
+	}}
+}
+
+func statusFromError(err error) error {
+	switch {
+	case errors.Is(err, g.ErrThreadNotExecuting):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, g.ErrRuntimeExecuting):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, g.ErrThreadCancelled):
+		return status.Error(codes.Canceled, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, err.Error())
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, err.Error())
+	default:
+		return status.Error(codes.Aborted, err.Error())
+	}
+}
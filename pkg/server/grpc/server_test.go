@@ -0,0 +1,235 @@
+package grpc_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/server"
+	"github.com/morphy76/ggraph/pkg/server/grpc"
+)
+
+type counterState struct {
+	Value   string `json:"value"`
+	Counter int    `json:"counter"`
+}
+
+func startServer(t *testing.T) *ggrpc.ClientConn {
+	node, err := builders.NewNode("Counter", func(userInput, currentState counterState, notify g.NotifyPartialFn[counterState]) (counterState, error) {
+		if userInput.Value == "fail" {
+			return currentState, errors.New("boom")
+		}
+		notify(counterState{Value: "partial"})
+		return counterState{Value: userInput.Value, Counter: currentState.Counter + 1}, nil
+	})
+	if err != nil {
+		t.Fatalf("NewNode failed: %v", err)
+	}
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[counterState], 100)
+	runtime, err := builders.CreateRuntime(builders.CreateStartEdge(node), stateMonitorCh)
+	if err != nil {
+		t.Fatalf("CreateRuntime failed: %v", err)
+	}
+	runtime.AddEdge(builders.CreateOutcomeEdge(node, "counted"))
+	broker := server.NewBroker(stateMonitorCh, nil)
+
+	srv, err := grpc.NewServer(runtime, broker, grpc.JSONCodec[counterState]())
+	if err != nil {
+		t.Fatalf("NewServer failed: %v", err)
+	}
+
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := ggrpc.NewServer()
+	grpc.RegisterGraphServiceServer(grpcServer, srv)
+	go grpcServer.Serve(listener)
+
+	conn, err := ggrpc.NewClient("passthrough:///bufnet",
+		ggrpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		ggrpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("NewClient failed: %v", err)
+	}
+
+	t.Cleanup(func() {
+		conn.Close()
+		grpcServer.Stop()
+		broker.Stop()
+		runtime.Shutdown()
+	})
+	return conn
+}
+
+func invocationRequest(t *testing.T, threadID, value string) *structpb.Struct {
+	req, err := structpb.NewStruct(map[string]any{
+		grpc.FieldThreadID: threadID,
+		grpc.FieldState:    map[string]any{"value": value},
+	})
+	if err != nil {
+		t.Fatalf("NewStruct failed: %v", err)
+	}
+	return req
+}
+
+func TestServer_InvokeSync(t *testing.T) {
+	conn := startServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	resp := &structpb.Struct{}
+	if err := conn.Invoke(ctx, grpc.InvokeSyncMethod, invocationRequest(t, "sync", "hello"), resp); err != nil {
+		t.Fatalf("InvokeSync failed: %v", err)
+	}
+
+	if resp.Fields[grpc.FieldThreadID].GetStringValue() != "sync" {
+		t.Errorf("Expected thread sync, got %v", resp.Fields[grpc.FieldThreadID])
+	}
+	state := resp.Fields[grpc.FieldState].GetStructValue().AsMap()
+	if state["value"] != "hello" || state["counter"] != float64(1) {
+		t.Errorf("Unexpected final state: %v", state)
+	}
+	if resp.Fields[grpc.FieldRunning].GetBoolValue() {
+		t.Error("Expected a terminal entry")
+	}
+	if outcome := resp.Fields[grpc.FieldOutcome].GetStringValue(); outcome != "counted" {
+		t.Errorf("Expected the outcome counted, got %q", outcome)
+	}
+
+	err := conn.Invoke(ctx, grpc.InvokeSyncMethod, invocationRequest(t, "", "fail"), resp)
+	if status.Code(err) != codes.Aborted {
+		t.Errorf("Expected Aborted, got %v", err)
+	}
+}
+
+func TestServer_Stream(t *testing.T) {
+	conn := startServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	stream, err := conn.NewStream(ctx, &grpc.GraphServiceDesc.Streams[0], grpc.StreamMethod)
+	if err != nil {
+		t.Fatalf("NewStream failed: %v", err)
+	}
+	if err := stream.SendMsg(invocationRequest(t, "stream", "hello")); err != nil {
+		t.Fatalf("SendMsg failed: %v", err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("CloseSend failed: %v", err)
+	}
+
+	var entries []*structpb.Struct
+	for {
+		entry := &structpb.Struct{}
+		err := stream.RecvMsg(entry)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("RecvMsg failed: %v", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 {
+		t.Fatal("Expected streamed entries")
+	}
+	sawPartial := false
+	for _, entry := range entries {
+		if entry.Fields[grpc.FieldPartial].GetBoolValue() {
+			sawPartial = true
+		}
+	}
+	if !sawPartial {
+		t.Error("Expected a partial entry")
+	}
+	last := entries[len(entries)-1]
+	if last.Fields[grpc.FieldRunning].GetBoolValue() {
+		t.Error("Expected the stream to end with the terminal entry")
+	}
+}
+
+func TestServer_InvokeAndListThreads(t *testing.T) {
+	conn := startServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	resp := &structpb.Struct{}
+	if err := conn.Invoke(ctx, grpc.InvokeMethod, invocationRequest(t, "", "async"), resp); err != nil {
+		t.Fatalf("Invoke failed: %v", err)
+	}
+	threadID := resp.Fields[grpc.FieldThreadID].GetStringValue()
+	if threadID == "" {
+		t.Fatal("Expected a generated thread ID")
+	}
+
+	if err := conn.Invoke(ctx, grpc.ListThreadsMethod, &structpb.Struct{}, resp); err != nil {
+		t.Fatalf("ListThreads failed: %v", err)
+	}
+	found := false
+	for _, value := range resp.Fields[grpc.FieldThreadIDs].GetListValue().GetValues() {
+		if value.GetStringValue() == threadID {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected thread %s to be listed, got %v", threadID, resp.Fields[grpc.FieldThreadIDs])
+	}
+}
+
+func TestServer_Cancel(t *testing.T) {
+	conn := startServer(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	resp := &structpb.Struct{}
+	err := conn.Invoke(ctx, grpc.CancelMethod, &structpb.Struct{}, resp)
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", err)
+	}
+
+	req, _ := structpb.NewStruct(map[string]any{grpc.FieldThreadID: "missing"})
+	err = conn.Invoke(ctx, grpc.CancelMethod, req, resp)
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+}
+
+func TestNewServer_Validation(t *testing.T) {
+	if _, err := grpc.NewServer[counterState](nil, nil, nil); !errors.Is(err, grpc.ErrRuntimeNil) {
+		t.Errorf("Expected ErrRuntimeNil, got %v", err)
+	}
+}
+
+func TestAnyCodec(t *testing.T) {
+	codec := grpc.AnyCodec(func() *wrapperspb.StringValue { return &wrapperspb.StringValue{} })
+
+	value, err := codec.Encode(wrapperspb.String("hello"))
+	if err != nil {
+		t.Fatalf("Encode failed: %v", err)
+	}
+	if value.GetStructValue().AsMap()["@type"] != "type.googleapis.com/google.protobuf.StringValue" {
+		t.Errorf("Expected the Any type URL, got %v", value)
+	}
+
+	decoded, err := codec.Decode(value)
+	if err != nil {
+		t.Fatalf("Decode failed: %v", err)
+	}
+	if decoded.GetValue() != "hello" {
+		t.Errorf("Expected hello, got %q", decoded.GetValue())
+	}
+}
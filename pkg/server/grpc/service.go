@@ -0,0 +1,126 @@
+package grpc
+
+import (
+	"context"
+
+	ggrpc "google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// The service is described with the protobuf well-known types, so that clients in any language can call it
+// without generated stubs. The equivalent definition is:
+//
+//	service GraphService {
+//	  rpc Invoke(google.protobuf.Struct) returns (google.protobuf.Struct);
+//	  rpc InvokeSync(google.protobuf.Struct) returns (google.protobuf.Struct);
+//	  rpc Stream(google.protobuf.Struct) returns (stream google.protobuf.Struct);
+//	  rpc Cancel(google.protobuf.Struct) returns (google.protobuf.Struct);
+//	  rpc ListThreads(google.protobuf.Struct) returns (google.protobuf.Struct);
+//	}
+const (
+	// ServiceName is the fully qualified name of the graph service.
+	ServiceName = "ggraph.v1.GraphService"
+
+	// InvokeMethod is the full name of the Invoke method.
+	InvokeMethod = "/" + ServiceName + "/Invoke"
+	// InvokeSyncMethod is the full name of the InvokeSync method.
+	InvokeSyncMethod = "/" + ServiceName + "/InvokeSync"
+	// StreamMethod is the full name of the Stream method.
+	StreamMethod = "/" + ServiceName + "/Stream"
+	// CancelMethod is the full name of the Cancel method.
+	CancelMethod = "/" + ServiceName + "/Cancel"
+	// ListThreadsMethod is the full name of the ListThreads method.
+	ListThreadsMethod = "/" + ServiceName + "/ListThreads"
+)
+
+// Message fields.
+const (
+	// FieldThreadID carries the thread identifier.
+	FieldThreadID = "thread_id"
+	// FieldState carries the state, encoded by the StateCodec.
+	FieldState = "state"
+	// FieldNode carries the name of the node of a monitor entry.
+	FieldNode = "node"
+	// FieldRunning is true while the invocation is running.
+	FieldRunning = "running"
+	// FieldPartial is true for partial state updates.
+	FieldPartial = "partial"
+	// FieldError carries the error message of a failed invocation.
+	FieldError = "error"
+	// FieldThreadIDs carries the list of the thread identifiers.
+	FieldThreadIDs = "thread_ids"
+	// FieldUsage carries the token usage of the invocation so far, with the prompt_tokens, completion_tokens and total_tokens fields.
+	FieldUsage = "usage"
+	// FieldOutcome carries the outcome of a completed invocation, see graph.StateMonitorEntry.Outcome.
+	FieldOutcome = "outcome"
+)
+
+// GraphServiceServer is the server API of the graph service.
+type GraphServiceServer interface {
+	// Invoke starts an invocation and returns its thread identifier without waiting for its completion.
+	Invoke(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	// InvokeSync starts an invocation and returns its final state.
+	InvokeSync(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	// Stream starts an invocation and streams its state monitor entries.
+	Stream(req *structpb.Struct, stream ggrpc.ServerStreamingServer[structpb.Struct]) error
+	// Cancel cancels the running invocation of a thread.
+	Cancel(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+	// ListThreads returns the identifiers of the active threads.
+	ListThreads(ctx context.Context, req *structpb.Struct) (*structpb.Struct, error)
+}
+
+// GraphServiceDesc is the grpc.ServiceDesc of the graph service.
+var GraphServiceDesc = ggrpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*GraphServiceServer)(nil),
+	Methods: []ggrpc.MethodDesc{
+		{MethodName: "Invoke", Handler: unaryHandler(InvokeMethod, GraphServiceServer.Invoke)},
+		{MethodName: "InvokeSync", Handler: unaryHandler(InvokeSyncMethod, GraphServiceServer.InvokeSync)},
+		{MethodName: "Cancel", Handler: unaryHandler(CancelMethod, GraphServiceServer.Cancel)},
+		{MethodName: "ListThreads", Handler: unaryHandler(ListThreadsMethod, GraphServiceServer.ListThreads)},
+	},
+	Streams: []ggrpc.StreamDesc{
+		{StreamName: "Stream", Handler: streamHandler, ServerStreams: true},
+	},
+	Metadata: "ggraph/v1/graph_service.proto",
+}
+
+// RegisterGraphServiceServer registers the graph service implementation on a gRPC server.
+//
+// Parameters:
+//   - registrar: The gRPC server.
+//   - srv: The service implementation, usually a *Server[T].
+//
+// Example:
+//
+//	grpcServer := ggrpc.NewServer()
+//	grpc.RegisterGraphServiceServer(grpcServer, srv)
+func RegisterGraphServiceServer(registrar ggrpc.ServiceRegistrar, srv GraphServiceServer) {
+	registrar.RegisterService(&GraphServiceDesc, srv)
+}
+
+type unaryFn func(GraphServiceServer, context.Context, *structpb.Struct) (*structpb.Struct, error)
+
+func unaryHandler(fullMethod string, fn unaryFn) ggrpc.MethodHandler {
+	return func(srv any, ctx context.Context, dec func(any) error, interceptor ggrpc.UnaryServerInterceptor) (any, error) {
+		req := &structpb.Struct{}
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		if interceptor == nil {
+			return fn(srv.(GraphServiceServer), ctx, req)
+		}
+		info := &ggrpc.UnaryServerInfo{Server: srv, FullMethod: fullMethod}
+		return interceptor(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+			return fn(srv.(GraphServiceServer), ctx, req.(*structpb.Struct))
+		})
+	}
+}
+
+func streamHandler(srv any, stream ggrpc.ServerStream) error {
+	req := &structpb.Struct{}
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	return srv.(GraphServiceServer).Stream(req, &ggrpc.GenericServerStream[structpb.Struct, structpb.Struct]{ServerStream: stream})
+}
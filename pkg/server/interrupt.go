@@ -0,0 +1,188 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+var (
+	// ErrNoWaitingInterrupt indicates that no interrupt node is waiting for a message on the thread.
+	ErrNoWaitingInterrupt = errors.New("no interrupt is waiting for the thread")
+	// ErrInterruptAlreadyWaiting indicates that another interrupt node is already waiting on the thread.
+	ErrInterruptAlreadyWaiting = errors.New("an interrupt is already waiting for the thread")
+	// ErrThreadIDMissing indicates that the invocation context does not carry the thread identifier.
+	ErrThreadIDMissing = errors.New("thread identifier not found in the context")
+)
+
+// Interrupt describes an interrupt node waiting for a message.
+type Interrupt struct {
+	// ThreadID is the thread of the waiting invocation.
+	ThreadID string
+	// Node is the name of the waiting node.
+	Node string
+}
+
+type waiter[T g.SharedState] struct {
+	node  string
+	reply chan T
+}
+
+// Mailbox delivers the messages of remote clients to the interrupt nodes waiting on their threads.
+type Mailbox[T g.SharedState] struct {
+	mu       sync.Mutex
+	waiting  map[string]*waiter[T]
+	watchers map[string]map[chan Interrupt]struct{}
+}
+
+// NewMailbox creates an empty Mailbox.
+//
+// Returns:
+//   - The Mailbox.
+//
+// Example:
+//
+//	mailbox := server.NewMailbox[MyState]()
+//	approval, _ := server.NewInterruptNode("Approval", mailbox)
+func NewMailbox[T g.SharedState]() *Mailbox[T] {
+	return &Mailbox[T]{
+		waiting:  make(map[string]*waiter[T]),
+		watchers: make(map[string]map[chan Interrupt]struct{}),
+	}
+}
+
+// Wait blocks until a message is delivered to the thread or the context is done.
+//
+// Parameters:
+//   - ctx: The invocation context.
+//   - threadID: The thread waiting for the message.
+//   - node: The name of the waiting node.
+//
+// Returns:
+//   - The delivered message.
+//   - ErrInterruptAlreadyWaiting, or the context error.
+func (m *Mailbox[T]) Wait(ctx context.Context, threadID, node string) (T, error) {
+	var zero T
+	useWaiter := &waiter[T]{node: node, reply: make(chan T, 1)}
+
+	m.mu.Lock()
+	if _, ok := m.waiting[threadID]; ok {
+		m.mu.Unlock()
+		return zero, fmt.Errorf("cannot wait on thread %s: %w", threadID, ErrInterruptAlreadyWaiting)
+	}
+	m.waiting[threadID] = useWaiter
+	for watcher := range m.watchers[threadID] {
+		select {
+		case watcher <- Interrupt{ThreadID: threadID, Node: node}:
+		default:
+		}
+	}
+	m.mu.Unlock()
+
+	defer func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if m.waiting[threadID] == useWaiter {
+			delete(m.waiting, threadID)
+		}
+	}()
+
+	select {
+	case message := <-useWaiter.reply:
+		return message, nil
+	case <-ctx.Done():
+		return zero, context.Cause(ctx)
+	}
+}
+
+// Deliver hands a message to the interrupt node waiting on the thread.
+//
+// Parameters:
+//   - threadID: The thread of the waiting invocation.
+//   - message: The message, returned as state change by the interrupt node.
+//
+// Returns:
+//   - ErrNoWaitingInterrupt if no interrupt node is waiting on the thread.
+func (m *Mailbox[T]) Deliver(threadID string, message T) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	useWaiter, ok := m.waiting[threadID]
+	if !ok {
+		return fmt.Errorf("cannot deliver to thread %s: %w", threadID, ErrNoWaitingInterrupt)
+	}
+	delete(m.waiting, threadID)
+	useWaiter.reply <- message
+	return nil
+}
+
+// Waiting returns the interrupt waiting on the thread, if any.
+func (m *Mailbox[T]) Waiting(threadID string) (Interrupt, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	useWaiter, ok := m.waiting[threadID]
+	if !ok {
+		return Interrupt{}, false
+	}
+	return Interrupt{ThreadID: threadID, Node: useWaiter.node}, true
+}
+
+// Watch notifies the interrupts starting to wait on the thread.
+//
+// Parameters:
+//   - threadID: The thread to watch.
+//
+// Returns:
+//   - The channel of the interrupts.
+//   - A function removing the watcher.
+func (m *Mailbox[T]) Watch(threadID string) (<-chan Interrupt, func()) {
+	watcher := make(chan Interrupt, 1)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.watchers[threadID]; !ok {
+		m.watchers[threadID] = make(map[chan Interrupt]struct{})
+	}
+	m.watchers[threadID][watcher] = struct{}{}
+
+	return watcher, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.watchers[threadID], watcher)
+		if len(m.watchers[threadID]) == 0 {
+			delete(m.watchers, threadID)
+		}
+	}
+}
+
+// NewInterruptNode creates a node pausing the invocation until a message is delivered to its thread.
+//
+// The delivered message is the state change of the node, merged with the node reducer.
+// Cancelling the invocation aborts the wait.
+//
+// Parameters:
+//   - name: The unique name for the node.
+//   - mailbox: The mailbox delivering the messages.
+//   - opts: Optional configuration options for the node.
+//
+// Returns:
+//   - The interrupt node.
+//   - An error if the node could not be created.
+//
+// Example:
+//
+//	approval, err := server.NewInterruptNode("Approval", mailbox, g.WithReducer(mergeApproval))
+func NewInterruptNode[T g.SharedState](name string, mailbox *Mailbox[T], opts ...g.NodeOption[T]) (g.Node[T], error) {
+	return builders.NewContextNode(name, func(ctx context.Context, userInput, currentState T, notify g.NotifyPartialFn[T]) (T, error) {
+		threadID, ok := g.ThreadIDFromContext(ctx)
+		if !ok {
+			return currentState, ErrThreadIDMissing
+		}
+		return mailbox.Wait(ctx, threadID, name)
+	}, opts...)
+}
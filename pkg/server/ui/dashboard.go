@@ -0,0 +1,631 @@
+// Package ui provides an embeddable HTTP dashboard of a runtime.
+//
+// The dashboard subscribes to the event bus of the runtime and tracks the threads, their
+// states, the timeline of their node executions and their token usage. It serves a single
+// page backed by a JSON API:
+//
+//	GET /                  the dashboard page
+//	GET /api/stats         the overview of the threads, the delivery and the node metrics, see Stats
+//	GET /metrics           the node metrics in the Prometheus text exposition format, see metrics.Handler
+//	GET /api/threads       the tracked threads, most recently updated first, see Thread
+//	GET /api/threads/{id}  a thread with its state and timeline, see ThreadDetail
+//	GET /api/events        the live monitor entries as server-sent events, see Entry
+//
+// The thread list accepts the "status" query parameter. The event stream accepts the "thread"
+// query parameter, repeated to follow several threads, and "partial=false" to skip the partial updates.
+//
+// Example:
+//
+//	dashboard, err := ui.NewDashboard(runtime, ui.WithTitle("orders"))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer dashboard.Close()
+//	http.Handle("/dashboard/", http.StripPrefix("/dashboard", dashboard))
+package ui
+
+import (
+	"cmp"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/observability/metrics"
+)
+
+// trackerBufferSize is the capacity of the subscription tracking the threads, the terminal entries must not be dropped.
+const trackerBufferSize = 1000
+
+// ErrThreadNotFound indicates that the dashboard does not track the requested thread.
+var ErrThreadNotFound = errors.New("thread not found")
+
+//go:embed index.html
+var indexHTML string
+
+var indexTemplate = template.Must(template.New("index").Parse(indexHTML))
+
+// Status is the status of a thread.
+type Status string
+
+const (
+	// StatusRunning is a thread executing its invocation.
+	StatusRunning Status = "running"
+	// StatusPaused is a thread stopped at a breakpoint, see g.WithDebugger.
+	StatusPaused Status = "paused"
+	// StatusCompleted is a thread whose last invocation completed.
+	StatusCompleted Status = "completed"
+	// StatusFailed is a thread whose last invocation failed.
+	StatusFailed Status = "failed"
+)
+
+func (s Status) terminated() bool {
+	return s == StatusCompleted || s == StatusFailed
+}
+
+// Thread summarizes a thread tracked by the dashboard.
+type Thread struct {
+	// ID is the thread identifier.
+	ID string `json:"id"`
+	// Status is the status of the thread.
+	Status Status `json:"status"`
+	// Node is the node of the last monitor entry.
+	Node string `json:"node"`
+	// Invocations is the number of invocations observed on the thread.
+	Invocations int `json:"invocations"`
+	// Steps is the number of nodes executed by the last invocation.
+	Steps int `json:"steps"`
+	// StartedAt is the time the last invocation was first observed.
+	StartedAt time.Time `json:"started_at"`
+	// UpdatedAt is the time of the last monitor entry.
+	UpdatedAt time.Time `json:"updated_at"`
+	// Usage is the token usage of all the observed invocations.
+	Usage g.Usage `json:"usage"`
+	// Error is the error message of the failed invocation.
+	Error string `json:"error,omitempty"`
+}
+
+// NodeExecution is a node execution in the timeline of a thread.
+//
+// The executions are measured between consecutive monitor entries of the thread, the pauses at the breakpoints included.
+type NodeExecution struct {
+	// Node is the name of the node.
+	Node string `json:"node"`
+	// Invocation is the ordinal of the invocation of the thread, starting from 1.
+	Invocation int `json:"invocation"`
+	// StartedAt is the time the execution started.
+	StartedAt time.Time `json:"started_at"`
+	// EndedAt is the time the execution completed.
+	EndedAt time.Time `json:"ended_at"`
+	// DurationMs is the duration of the execution in milliseconds.
+	DurationMs float64 `json:"duration_ms"`
+	// Usage is the token usage reported by the node.
+	Usage g.Usage `json:"usage"`
+	// Partials is the number of partial updates notified by the node.
+	Partials int `json:"partials"`
+	// Changes are the fields changed by the node, only when the runtime has g.WithStateDiff.
+	Changes []g.StateChange `json:"changes,omitempty"`
+	// Error is the error message of the failed execution.
+	Error string `json:"error,omitempty"`
+}
+
+// Warning is a non-fatal error of a thread, such as a persistence failure or the eviction.
+type Warning struct {
+	// Node is the node reporting the error.
+	Node string `json:"node"`
+	// Time is the time the error was observed.
+	Time time.Time `json:"time"`
+	// Error is the error message.
+	Error string `json:"error"`
+}
+
+// ThreadDetail is a thread with its state and timeline.
+type ThreadDetail struct {
+	Thread
+	// State is the JSON encoded last state of the thread, omitted when it cannot be serialized.
+	State json.RawMessage `json:"state,omitempty"`
+	// Timeline is the sequence of the node executions, oldest first.
+	Timeline []NodeExecution `json:"timeline"`
+	// Warnings are the non-fatal errors of the thread, oldest first.
+	Warnings []Warning `json:"warnings"`
+}
+
+// MonitorStats describes the delivery of the state monitor channel, see g.MonitorStats.
+type MonitorStats struct {
+	// Policy is the delivery policy of the runtime.
+	Policy string `json:"policy"`
+	// Delivered is the number of entries sent to the channel.
+	Delivered uint64 `json:"delivered"`
+	// Dropped is the number of entries lost.
+	Dropped uint64 `json:"dropped"`
+	// Buffered is the number of entries waiting on disk.
+	Buffered int `json:"buffered"`
+	// Spilled is the number of entries written to disk.
+	Spilled uint64 `json:"spilled"`
+}
+
+// EventStats describes the delivery of the event bus, see g.EventStats.
+type EventStats struct {
+	// Subscribers is the number of active subscriptions, the dashboard and its event streams included.
+	Subscribers int `json:"subscribers"`
+	// Delivered is the number of entries delivered to the subscriptions.
+	Delivered uint64 `json:"delivered"`
+	// Dropped is the number of entries dropped by the slow subscriptions.
+	Dropped uint64 `json:"dropped"`
+}
+
+// NodeStats describes the executions of a node, see g.NodeStats.
+type NodeStats struct {
+	// Node is the name of the node.
+	Node string `json:"node"`
+	// Executions is the number of executions of the node.
+	Executions uint64 `json:"executions"`
+	// Failures is the number of executions which failed.
+	Failures uint64 `json:"failures"`
+	// P50Ms is the median latency of the recent executions in milliseconds.
+	P50Ms float64 `json:"p50_ms"`
+	// P95Ms is the 95th percentile latency of the recent executions in milliseconds.
+	P95Ms float64 `json:"p95_ms"`
+	// P99Ms is the 99th percentile latency of the recent executions in milliseconds.
+	P99Ms float64 `json:"p99_ms"`
+	// MaxMs is the longest execution in milliseconds.
+	MaxMs float64 `json:"max_ms"`
+	// SLOMs is the latency objective of the node in milliseconds, zero when none.
+	SLOMs float64 `json:"slo_ms,omitempty"`
+	// SLOBreaches is the number of executions exceeding the latency objective.
+	SLOBreaches uint64 `json:"slo_breaches"`
+	// PartialUpdates is the number of partial updates streamed by the node.
+	PartialUpdates uint64 `json:"partial_updates"`
+	// TokensPerSecond is the streaming throughput of the node.
+	TokensPerSecond float64 `json:"tokens_per_second"`
+	// Usage is the token usage of the node.
+	Usage g.Usage `json:"usage"`
+}
+
+// Stats is the overview of the runtime.
+type Stats struct {
+	// Threads is the number of tracked threads by status.
+	Threads map[Status]int `json:"threads"`
+	// Usage is the token usage of the tracked threads.
+	Usage g.Usage `json:"usage"`
+	// Monitor describes the delivery of the state monitor channel.
+	Monitor MonitorStats `json:"monitor"`
+	// Events describes the delivery of the event bus.
+	Events EventStats `json:"events"`
+	// Nodes describes the executions of the nodes, sorted by name, see g.Profiled.
+	Nodes []NodeStats `json:"nodes"`
+}
+
+// Entry is a monitor entry streamed by the dashboard.
+type Entry struct {
+	// ThreadID is the thread of the entry.
+	ThreadID string `json:"thread_id"`
+	// Node is the node of the entry.
+	Node string `json:"node"`
+	// Time is the time the entry was streamed.
+	Time time.Time `json:"time"`
+	// Running is true while the invocation is running.
+	Running bool `json:"running"`
+	// Partial is true for the partial state updates.
+	Partial bool `json:"partial,omitempty"`
+	// Paused is true when the thread stopped at a breakpoint before the node.
+	Paused bool `json:"paused,omitempty"`
+	// Error is the error message of the entry.
+	Error string `json:"error,omitempty"`
+	// Usage is the token usage of the invocation so far.
+	Usage g.Usage `json:"usage"`
+	// State is the JSON encoded state, omitted when it cannot be serialized.
+	State json.RawMessage `json:"state,omitempty"`
+	// Changes are the fields changed by the node, only when the runtime has g.WithStateDiff.
+	Changes []g.StateChange `json:"changes,omitempty"`
+}
+
+// Dashboard tracks the threads of a runtime and serves them over HTTP.
+type Dashboard[T g.SharedState] struct {
+	runtime g.Runtime[T]
+	opts    Options
+	mux     *http.ServeMux
+
+	mu      sync.Mutex
+	threads map[string]*thread[T]
+
+	cancel  func()
+	tracked chan struct{}
+	closed  chan struct{}
+	once    sync.Once
+}
+
+type thread[T g.SharedState] struct {
+	info     Thread
+	state    T
+	timeline []NodeExecution
+	warnings []Warning
+
+	nodeStart       time.Time
+	partials        int
+	invocationUsage g.Usage
+	previousUsage   g.Usage
+}
+
+// NewDashboard creates a dashboard tracking the threads of the runtime from now on.
+//
+// Parameters:
+//   - runtime: The runtime to observe.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - The Dashboard, an http.Handler to mount on a server.
+//   - An error if the runtime is nil or an option is invalid.
+//
+// Example:
+//
+//	dashboard, _ := ui.NewDashboard(runtime, ui.WithMaxThreads(100))
+//	defer dashboard.Close()
+//	go http.ListenAndServe(":8080", dashboard)
+func NewDashboard[T g.SharedState](runtime g.Runtime[T], opts ...Option) (*Dashboard[T], error) {
+	if runtime == nil {
+		return nil, fmt.Errorf("dashboard creation failed: %w", ErrRuntimeNil)
+	}
+
+	useOpts := &Options{
+		Title:       DefaultTitle,
+		MaxThreads:  DefaultMaxThreads,
+		MaxTimeline: DefaultMaxTimeline,
+		KeepAlive:   DefaultKeepAlive,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("dashboard creation failed: %w", err)
+		}
+	}
+
+	rv := &Dashboard[T]{
+		runtime: runtime,
+		opts:    *useOpts,
+		mux:     http.NewServeMux(),
+		threads: make(map[string]*thread[T]),
+		tracked: make(chan struct{}),
+		closed:  make(chan struct{}),
+	}
+	rv.mux.HandleFunc("GET /{$}", rv.serveIndex)
+	rv.mux.HandleFunc("GET /api/stats", rv.serveStats)
+	rv.mux.Handle("GET /metrics", metrics.Handler(runtime))
+	rv.mux.HandleFunc("GET /api/threads", rv.serveThreads)
+	rv.mux.HandleFunc("GET /api/threads/{id}", rv.serveThread)
+	rv.mux.HandleFunc("GET /api/events", rv.serveEvents)
+
+	entries, cancel := runtime.Subscribe(g.EventFilter[T]{BufferSize: trackerBufferSize})
+	rv.cancel = cancel
+	go func() {
+		defer close(rv.tracked)
+		for entry := range entries {
+			rv.apply(entry, time.Now())
+		}
+	}()
+
+	return rv, nil
+}
+
+// ServeHTTP serves the dashboard page and its API.
+//
+// Parameters:
+//   - w: The response writer.
+//   - r: The request.
+func (d *Dashboard[T]) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mux.ServeHTTP(w, r)
+}
+
+// Close stops the tracking of the threads and ends the event streams, it is safe to call more than once.
+func (d *Dashboard[T]) Close() {
+	d.once.Do(func() {
+		close(d.closed)
+		d.cancel()
+		<-d.tracked
+	})
+}
+
+// Threads returns the tracked threads, most recently updated first.
+//
+// Returns:
+//   - The thread summaries.
+func (d *Dashboard[T]) Threads() []Thread {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	rv := make([]Thread, 0, len(d.threads))
+	for _, th := range d.threads {
+		rv = append(rv, th.info)
+	}
+	slices.SortFunc(rv, func(a, b Thread) int {
+		if c := b.UpdatedAt.Compare(a.UpdatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return rv
+}
+
+// Thread returns a tracked thread with its state and timeline.
+//
+// Parameters:
+//   - threadID: The thread identifier.
+//
+// Returns:
+//   - The thread detail.
+//   - ErrThreadNotFound if the thread is not tracked.
+func (d *Dashboard[T]) Thread(threadID string) (ThreadDetail, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	th, ok := d.threads[threadID]
+	if !ok {
+		return ThreadDetail{}, fmt.Errorf("%w: %s", ErrThreadNotFound, threadID)
+	}
+	return ThreadDetail{
+		Thread:   th.info,
+		State:    encode(th.state),
+		Timeline: slices.Clone(th.timeline),
+		Warnings: slices.Clone(th.warnings),
+	}, nil
+}
+
+// Stats returns the overview of the runtime.
+//
+// Returns:
+//   - The overview.
+func (d *Dashboard[T]) Stats() Stats {
+	monitor := d.runtime.MonitorStats()
+	events := d.runtime.EventStats()
+	rv := Stats{
+		Threads: map[Status]int{StatusRunning: 0, StatusPaused: 0, StatusCompleted: 0, StatusFailed: 0},
+		Monitor: MonitorStats{
+			Policy:    monitor.Policy.String(),
+			Delivered: monitor.Delivered,
+			Dropped:   monitor.Dropped,
+			Buffered:  monitor.Buffered,
+			Spilled:   monitor.Spilled,
+		},
+		Events: EventStats{Subscribers: events.Subscribers, Delivered: events.Delivered, Dropped: events.Dropped},
+		Nodes:  []NodeStats{},
+	}
+	for _, node := range d.runtime.Stats().Nodes {
+		rv.Nodes = append(rv.Nodes, NodeStats{
+			Node:            node.Node,
+			Executions:      node.Executions,
+			Failures:        node.Failures,
+			P50Ms:           milliseconds(node.P50Latency),
+			P95Ms:           milliseconds(node.P95Latency),
+			P99Ms:           milliseconds(node.P99Latency),
+			MaxMs:           milliseconds(node.MaxLatency),
+			SLOMs:           milliseconds(node.LatencySLO),
+			SLOBreaches:     node.SLOBreaches,
+			PartialUpdates:  node.PartialUpdates,
+			TokensPerSecond: node.TokensPerSecond(),
+			Usage:           node.Usage,
+		})
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for _, th := range d.threads {
+		rv.Threads[th.info.Status]++
+		rv.Usage = rv.Usage.Add(th.info.Usage)
+	}
+	return rv
+}
+
+func (d *Dashboard[T]) apply(entry g.StateMonitorEntry[T], now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	th, ok := d.threads[entry.ThreadID]
+	if !ok {
+		th = &thread[T]{info: Thread{ID: entry.ThreadID}}
+		d.threads[entry.ThreadID] = th
+		d.evict()
+	}
+	th.info.UpdatedAt = now
+
+	// The errors of running invocations, e.g. the eviction or the persistence failures, do not change the thread.
+	if entry.Running && entry.Error != nil {
+		th.warnings = appendCapped(th.warnings, Warning{Node: entry.Node, Time: now, Error: entry.Error.Error()}, d.opts.MaxTimeline)
+		return
+	}
+
+	if !ok || th.info.Status.terminated() {
+		th.previousUsage = th.info.Usage
+		th.invocationUsage = g.Usage{}
+		th.info.Invocations++
+		th.info.Steps = 0
+		th.info.StartedAt = now
+		th.info.Error = ""
+		th.nodeStart = now
+		th.partials = 0
+	}
+
+	th.info.Node = entry.Node
+	th.info.Usage = th.previousUsage.Add(entry.Usage)
+	if entry.Error == nil {
+		th.state = entry.NewState
+	}
+
+	switch {
+	case entry.Partial:
+		th.partials++
+		th.info.Status = StatusRunning
+	case entry.Breakpoint != nil:
+		th.info.Status = StatusPaused
+	default:
+		execution := NodeExecution{
+			Node:       entry.Node,
+			Invocation: th.info.Invocations,
+			StartedAt:  th.nodeStart,
+			EndedAt:    now,
+			DurationMs: milliseconds(now.Sub(th.nodeStart)),
+			Usage:      subtract(entry.Usage, th.invocationUsage),
+			Partials:   th.partials,
+			Changes:    entry.Changes,
+		}
+		th.info.Status = StatusRunning
+		switch {
+		case entry.Error != nil:
+			execution.Error = entry.Error.Error()
+			th.info.Error = execution.Error
+			th.info.Status = StatusFailed
+		case !entry.Running:
+			th.info.Status = StatusCompleted
+		}
+		th.timeline = appendCapped(th.timeline, execution, d.opts.MaxTimeline)
+		th.info.Steps++
+		th.nodeStart = now
+		th.partials = 0
+		th.invocationUsage = entry.Usage
+	}
+}
+
+// evict forgets the least recently updated thread beyond MaxThreads, preferring the terminated ones.
+func (d *Dashboard[T]) evict() {
+	if len(d.threads) <= d.opts.MaxThreads {
+		return
+	}
+	var victim *thread[T]
+	for _, th := range d.threads {
+		if victim == nil ||
+			(th.info.Status.terminated() && !victim.info.Status.terminated()) ||
+			(th.info.Status.terminated() == victim.info.Status.terminated() && th.info.UpdatedAt.Before(victim.info.UpdatedAt)) {
+			victim = th
+		}
+	}
+	delete(d.threads, victim.info.ID)
+}
+
+func (d *Dashboard[T]) serveIndex(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = indexTemplate.Execute(w, d.opts)
+}
+
+func (d *Dashboard[T]) serveStats(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, d.Stats())
+}
+
+func (d *Dashboard[T]) serveThreads(w http.ResponseWriter, r *http.Request) {
+	threads := d.Threads()
+	if status := Status(r.URL.Query().Get("status")); status != "" {
+		threads = slices.DeleteFunc(threads, func(th Thread) bool { return th.Status != status })
+	}
+	writeJSON(w, http.StatusOK, threads)
+}
+
+func (d *Dashboard[T]) serveThread(w http.ResponseWriter, r *http.Request) {
+	detail, err := d.Thread(r.PathValue("id"))
+	if err != nil {
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": err.Error()})
+		return
+	}
+	writeJSON(w, http.StatusOK, detail)
+}
+
+func (d *Dashboard[T]) serveEvents(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": "streaming is not supported"})
+		return
+	}
+
+	query := r.URL.Query()
+	entries, cancel := d.runtime.Subscribe(g.EventFilter[T]{
+		ThreadIDs:   query["thread"],
+		SkipPartial: query.Get("partial") == "false",
+	})
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(d.opts.KeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-d.closed:
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case entry, ok := <-entries:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(encodeEntry(entry, time.Now()))
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "event: entry\ndata: %s\n\n", data)
+		}
+		flusher.Flush()
+	}
+}
+
+func encodeEntry[T g.SharedState](entry g.StateMonitorEntry[T], now time.Time) Entry {
+	rv := Entry{
+		ThreadID: entry.ThreadID,
+		Node:     entry.Node,
+		Time:     now,
+		Running:  entry.Running,
+		Partial:  entry.Partial,
+		Paused:   entry.Breakpoint != nil,
+		Usage:    entry.Usage,
+		State:    encode(entry.NewState),
+		Changes:  entry.Changes,
+	}
+	if entry.Error != nil {
+		rv.Error = entry.Error.Error()
+	}
+	return rv
+}
+
+func encode(v any) json.RawMessage {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func appendCapped[E any](s []E, e E, limit int) []E {
+	s = append(s, e)
+	if len(s) > limit {
+		s = slices.Delete(s, 0, len(s)-limit)
+	}
+	return s
+}
+
+func subtract(a, b g.Usage) g.Usage {
+	return g.Usage{
+		PromptTokens:     a.PromptTokens - b.PromptTokens,
+		CompletionTokens: a.CompletionTokens - b.CompletionTokens,
+		TotalTokens:      a.TotalTokens - b.TotalTokens,
+	}
+}
+
+// milliseconds converts a duration into fractional milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
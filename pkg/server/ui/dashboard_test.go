@@ -0,0 +1,234 @@
+package ui_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/server/ui"
+)
+
+type chatState struct {
+	Question string `json:"question,omitempty"`
+	Answer   string `json:"answer,omitempty"`
+}
+
+func startDashboard(t *testing.T, opts ...ui.Option) (g.Runtime[chatState], *ui.Dashboard[chatState], *httptest.Server) {
+	ask, _ := builders.NewContextNode("Ask", func(ctx context.Context, userInput, _ chatState, notify g.NotifyPartialFn[chatState]) (chatState, error) {
+		if userInput.Question == "fail" {
+			return chatState{}, errors.New("boom")
+		}
+		notify(chatState{Answer: "thinking"})
+		g.ReportUsage(ctx, g.Usage{PromptTokens: 3, CompletionTokens: 4, TotalTokens: 7})
+		return chatState{Question: userInput.Question, Answer: "42"}, nil
+	})
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[chatState], 100)
+	runtime, err := builders.CreateRuntime(builders.CreateStartEdge(ask), stateMonitorCh, g.WithStateDiff[chatState](nil))
+	if err != nil {
+		t.Fatalf("CreateRuntime failed: %v", err)
+	}
+	runtime.AddEdge(builders.CreateEndEdge(ask))
+
+	dashboard, err := ui.NewDashboard(runtime, opts...)
+	if err != nil {
+		t.Fatalf("NewDashboard failed: %v", err)
+	}
+	httpServer := httptest.NewServer(dashboard)
+
+	t.Cleanup(func() {
+		httpServer.Close()
+		dashboard.Close()
+		runtime.Shutdown()
+	})
+	return runtime, dashboard, httpServer
+}
+
+func getJSON(t *testing.T, url string, status int, v any) {
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("GET %s failed: %v", url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != status {
+		t.Fatalf("Expected status %d for %s, got %d", status, url, resp.StatusCode)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("Decoding %s failed: %v", url, err)
+	}
+}
+
+func waitStatus(t *testing.T, dashboard *ui.Dashboard[chatState], threadID string, status ui.Status) {
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if detail, err := dashboard.Thread(threadID); err == nil && detail.Status == status {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Test timed out waiting for thread %s to be %s", threadID, status)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestDashboard_API(t *testing.T) {
+	runtime, dashboard, httpServer := startDashboard(t)
+
+	runtime.Invoke(chatState{Question: "answer"}, g.InvokeConfigThreadID("ok"))
+	waitStatus(t, dashboard, "ok", ui.StatusCompleted)
+	runtime.Invoke(chatState{Question: "fail"}, g.InvokeConfigThreadID("ko"))
+	waitStatus(t, dashboard, "ko", ui.StatusFailed)
+
+	var threads []ui.Thread
+	getJSON(t, httpServer.URL+"/api/threads", http.StatusOK, &threads)
+	if len(threads) != 2 || threads[0].ID != "ko" || threads[1].ID != "ok" {
+		t.Fatalf("Expected the threads most recently updated first, got %+v", threads)
+	}
+	getJSON(t, httpServer.URL+"/api/threads?status=failed", http.StatusOK, &threads)
+	if len(threads) != 1 || threads[0].ID != "ko" || threads[0].Error == "" {
+		t.Errorf("Expected the failed thread only, got %+v", threads)
+	}
+
+	var detail ui.ThreadDetail
+	getJSON(t, httpServer.URL+"/api/threads/ok", http.StatusOK, &detail)
+	if detail.Usage.TotalTokens != 7 || detail.Invocations != 1 || string(detail.State) != `{"question":"answer","answer":"42"}` {
+		t.Errorf("Unexpected thread detail %+v", detail)
+	}
+	var ask *ui.NodeExecution
+	for i := range detail.Timeline {
+		if detail.Timeline[i].Node == "Ask" {
+			ask = &detail.Timeline[i]
+		}
+	}
+	if ask == nil || ask.Usage.TotalTokens != 7 || ask.Partials != 1 || ask.DurationMs < 0 ||
+		len(ask.Changes) != 2 || ask.Changes[0].Path != "Question" || ask.Changes[1].After != "42" {
+		t.Errorf("Expected the Ask execution with its usage, partial update and changes, got %+v", detail.Timeline)
+	}
+
+	var stats ui.Stats
+	getJSON(t, httpServer.URL+"/api/stats", http.StatusOK, &stats)
+	if stats.Threads[ui.StatusCompleted] != 1 || stats.Threads[ui.StatusFailed] != 1 || stats.Usage.TotalTokens != 7 ||
+		stats.Monitor.Policy != g.MonitorDropNewest.String() || stats.Events.Subscribers != 1 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	if idx := slices.IndexFunc(stats.Nodes, func(n ui.NodeStats) bool { return n.Node == "Ask" }); idx == -1 ||
+		stats.Nodes[idx].Usage.TotalTokens != 7 || stats.Nodes[idx].PartialUpdates != 1 || stats.Nodes[idx].Executions == 0 {
+		t.Errorf("Expected the metrics of the Ask node, got %+v", stats.Nodes)
+	}
+
+	metricsResp, err := http.Get(httpServer.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics failed: %v", err)
+	}
+	defer metricsResp.Body.Close()
+	exposition := new(strings.Builder)
+	_, _ = bufio.NewReader(metricsResp.Body).WriteTo(exposition)
+	if !strings.Contains(exposition.String(), `ggraph_node_tokens_total{node="Ask",kind="completion"}`) {
+		t.Errorf("Expected the node metrics of the Ask node, got:\n%s", exposition)
+	}
+
+	var notFound map[string]string
+	getJSON(t, httpServer.URL+"/api/threads/missing", http.StatusNotFound, &notFound)
+	if !strings.Contains(notFound["error"], ui.ErrThreadNotFound.Error()) {
+		t.Errorf("Expected ErrThreadNotFound, got %v", notFound)
+	}
+
+	resp, err := http.Get(httpServer.URL + "/")
+	if err != nil {
+		t.Fatalf("GET / failed: %v", err)
+	}
+	defer resp.Body.Close()
+	page := new(strings.Builder)
+	_, _ = bufio.NewReader(resp.Body).WriteTo(page)
+	if !strings.Contains(page.String(), "<title>"+ui.DefaultTitle+"</title>") || !strings.Contains(page.String(), `EventSource("api/events")`) {
+		t.Errorf("Unexpected dashboard page:\n%s", page)
+	}
+}
+
+func TestDashboard_Events(t *testing.T) {
+	runtime, _, httpServer := startDashboard(t)
+
+	resp, err := http.Get(httpServer.URL + "/api/events?thread=followed&partial=false")
+	if err != nil {
+		t.Fatalf("GET /api/events failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("Unexpected content type %q", resp.Header.Get("Content-Type"))
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	if line, err := reader.ReadString('\n'); err != nil || line != ": connected\n" {
+		t.Fatalf("Expected the stream to open, got %q: %v", line, err)
+	}
+
+	runtime.Invoke(chatState{Question: "ignored"}, g.InvokeConfigThreadID("other"))
+	runtime.Invoke(chatState{Question: "answer"}, g.InvokeConfigThreadID("followed"))
+
+	var entries []ui.Entry
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Reading the stream failed after %+v: %v", entries, err)
+		}
+		data, ok := strings.CutPrefix(strings.TrimSpace(line), "data: ")
+		if !ok {
+			continue
+		}
+		var entry ui.Entry
+		if err := json.Unmarshal([]byte(data), &entry); err != nil {
+			t.Fatalf("Decoding %q failed: %v", data, err)
+		}
+		entries = append(entries, entry)
+		if !entry.Running {
+			break
+		}
+	}
+
+	for _, entry := range entries {
+		if entry.ThreadID != "followed" || entry.Partial {
+			t.Errorf("Expected the complete entries of the followed thread only, got %+v", entry)
+		}
+	}
+	if last := entries[len(entries)-1]; last.Usage.TotalTokens != 7 || string(last.State) != `{"question":"answer","answer":"42"}` {
+		t.Errorf("Unexpected terminal entry %+v", last)
+	}
+}
+
+func TestDashboard_MaxThreads(t *testing.T) {
+	runtime, dashboard, _ := startDashboard(t, ui.WithMaxThreads(1))
+
+	runtime.Invoke(chatState{Question: "answer"}, g.InvokeConfigThreadID("first"))
+	waitStatus(t, dashboard, "first", ui.StatusCompleted)
+	runtime.Invoke(chatState{Question: "answer"}, g.InvokeConfigThreadID("second"))
+	waitStatus(t, dashboard, "second", ui.StatusCompleted)
+
+	if _, err := dashboard.Thread("first"); !errors.Is(err, ui.ErrThreadNotFound) {
+		t.Errorf("Expected the first thread to be forgotten, got %v", err)
+	}
+}
+
+func TestNewDashboard_Invalid(t *testing.T) {
+	if _, err := ui.NewDashboard[chatState](nil); !errors.Is(err, ui.ErrRuntimeNil) {
+		t.Errorf("Expected ErrRuntimeNil, got %v", err)
+	}
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[chatState], 10)
+	start, _ := builders.NewNode[chatState]("Start", nil)
+	runtime, _ := builders.CreateRuntime(builders.CreateStartEdge(start), stateMonitorCh)
+	defer runtime.Shutdown()
+	for _, opt := range []ui.Option{ui.WithMaxThreads(0), ui.WithMaxTimeline(0), ui.WithKeepAlive(0)} {
+		if _, err := ui.NewDashboard(runtime, opt); !errors.Is(err, ui.ErrInvalidOption) {
+			t.Errorf("Expected ErrInvalidOption, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// DefaultTitle is the default title of the dashboard page.
+	DefaultTitle = "ggraph dashboard"
+	// DefaultMaxThreads is the default number of threads tracked by the dashboard.
+	DefaultMaxThreads = 1000
+	// DefaultMaxTimeline is the default number of node executions kept per thread.
+	DefaultMaxTimeline = 200
+	// DefaultKeepAlive is the default interval of the keep-alive comments of the event streams.
+	DefaultKeepAlive = 15 * time.Second
+)
+
+var (
+	// ErrRuntimeNil indicates that no runtime was given to the dashboard.
+	ErrRuntimeNil = errors.New("runtime cannot be nil")
+	// ErrInvalidOption indicates that an option of the dashboard has an invalid value.
+	ErrInvalidOption = errors.New("invalid dashboard option")
+)
+
+// Options holds the configuration of the Dashboard.
+type Options struct {
+	// Title is shown at the top of the dashboard page.
+	Title string
+	// MaxThreads is the number of threads tracked, the least recently updated terminated threads are forgotten first.
+	MaxThreads int
+	// MaxTimeline is the number of node executions kept per thread, the oldest are forgotten first.
+	MaxTimeline int
+	// KeepAlive is the interval of the keep-alive comments sent on the idle event streams.
+	KeepAlive time.Duration
+}
+
+// Option is a functional option for configuring the Dashboard.
+type Option interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - o: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(o *Options) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc func(*Options) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - o: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc) Apply(o *Options) error { return s(o) }
+
+// WithTitle sets the title of the dashboard page.
+//
+// Parameters:
+//   - title: The title.
+//
+// Returns:
+//   - An Option that sets the title.
+func WithTitle(title string) Option {
+	return OptionFunc(func(o *Options) error {
+		o.Title = title
+		return nil
+	})
+}
+
+// WithMaxThreads sets the number of threads tracked by the dashboard.
+//
+// Parameters:
+//   - maxThreads: The number of threads, it must be positive.
+//
+// Returns:
+//   - An Option that sets the number of threads.
+func WithMaxThreads(maxThreads int) Option {
+	return OptionFunc(func(o *Options) error {
+		if maxThreads < 1 {
+			return fmt.Errorf("%w: the number of threads must be positive", ErrInvalidOption)
+		}
+		o.MaxThreads = maxThreads
+		return nil
+	})
+}
+
+// WithMaxTimeline sets the number of node executions kept per thread.
+//
+// Parameters:
+//   - maxTimeline: The number of node executions, it must be positive.
+//
+// Returns:
+//   - An Option that sets the number of node executions.
+func WithMaxTimeline(maxTimeline int) Option {
+	return OptionFunc(func(o *Options) error {
+		if maxTimeline < 1 {
+			return fmt.Errorf("%w: the number of node executions must be positive", ErrInvalidOption)
+		}
+		o.MaxTimeline = maxTimeline
+		return nil
+	})
+}
+
+// WithKeepAlive sets the interval of the keep-alive comments of the event streams.
+//
+// Proxies close the idle connections, the comments keep the streams open.
+//
+// Parameters:
+//   - interval: The interval, it must be positive.
+//
+// Returns:
+//   - An Option that sets the interval.
+func WithKeepAlive(interval time.Duration) Option {
+	return OptionFunc(func(o *Options) error {
+		if interval <= 0 {
+			return fmt.Errorf("%w: the keep-alive interval must be positive", ErrInvalidOption)
+		}
+		o.KeepAlive = interval
+		return nil
+	})
+}
@@ -0,0 +1,304 @@
+// Package websocket exposes a graph runtime over WebSocket for interactive agents.
+//
+// A connection invokes a thread, receives its state monitor entries as they happen, including
+// the partial updates, and answers the interrupt nodes waiting on the thread.
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"golang.org/x/net/websocket"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/server"
+)
+
+// Client message types.
+const (
+	// TypeInvoke starts an invocation with the given state; the connection follows its thread.
+	TypeInvoke = "invoke"
+	// TypeMessage delivers the given state to the interrupt node waiting on the thread.
+	TypeMessage = "message"
+	// TypeCancel cancels the running invocation of the thread.
+	TypeCancel = "cancel"
+)
+
+// Server message types.
+const (
+	// TypeEntry carries a state monitor entry.
+	TypeEntry = "entry"
+	// TypeInterrupt signals that an interrupt node is waiting for a message.
+	TypeInterrupt = "interrupt"
+	// TypeError reports a failed client request.
+	TypeError = "error"
+)
+
+var (
+	// ErrRuntimeNil indicates that no runtime was given to the handler.
+	ErrRuntimeNil = errors.New("runtime cannot be nil")
+	// ErrBrokerNil indicates that no broker was given to the handler.
+	ErrBrokerNil = errors.New("broker cannot be nil")
+	// ErrMailboxNotSet indicates that the handler has no mailbox to deliver messages.
+	ErrMailboxNotSet = errors.New("mailbox is not set")
+	// ErrUnknownMessageType indicates that the client sent an unsupported message type.
+	ErrUnknownMessageType = errors.New("unknown message type")
+	// ErrNoThread indicates that the request has no thread and the connection follows none.
+	ErrNoThread = errors.New("no thread to address")
+)
+
+// ClientMessage is a message sent by the client.
+type ClientMessage struct {
+	// Type is one of TypeInvoke, TypeMessage and TypeCancel.
+	Type string `json:"type"`
+	// ThreadID addresses the thread, it defaults to the thread followed by the connection.
+	ThreadID string `json:"thread_id,omitempty"`
+	// State is the JSON encoded state.
+	State json.RawMessage `json:"state,omitempty"`
+}
+
+// ServerMessage is a message sent to the client.
+type ServerMessage struct {
+	// Type is one of TypeEntry, TypeInterrupt and TypeError.
+	Type string `json:"type"`
+	// ThreadID is the thread of the entry or interrupt.
+	ThreadID string `json:"thread_id,omitempty"`
+	// Node is the node of the entry or interrupt.
+	Node string `json:"node,omitempty"`
+	// State is the JSON encoded state of the entry.
+	State json.RawMessage `json:"state,omitempty"`
+	// Running is true while the invocation is running.
+	Running bool `json:"running,omitempty"`
+	// Partial is true for partial state updates.
+	Partial bool `json:"partial,omitempty"`
+	// Error is the error message of an entry or of a failed request.
+	Error string `json:"error,omitempty"`
+	// Outcome is the outcome of a completed invocation.
+	Outcome string `json:"outcome,omitempty"`
+	// Usage is the token usage of the invocation so far.
+	Usage *g.Usage `json:"usage,omitempty"`
+}
+
+// NewHandler creates the WebSocket endpoint of a runtime.
+//
+// Invocations started by a connection run within its lifetime: closing the connection cancels them.
+//
+// Parameters:
+//   - runtime: The runtime executing the graph.
+//   - broker: The broker dispatching the runtime state monitor entries.
+//   - mailbox: The mailbox of the interrupt nodes of the graph, nil if the graph has none.
+//
+// Returns:
+//   - The http.Handler serving the WebSocket endpoint.
+//   - An error if the runtime or the broker is nil.
+//
+// Example:
+//
+//	mailbox := server.NewMailbox[MyState]()
+//	handler, _ := websocket.NewHandler(runtime, server.NewBroker(stateMonitorCh, nil), mailbox)
+//	http.Handle("/graph", handler)
+func NewHandler[T g.SharedState](runtime g.Runtime[T], broker *server.Broker[T], mailbox *server.Mailbox[T]) (http.Handler, error) {
+	if runtime == nil {
+		return nil, fmt.Errorf("websocket handler creation failed: %w", ErrRuntimeNil)
+	}
+	if broker == nil {
+		return nil, fmt.Errorf("websocket handler creation failed: %w", ErrBrokerNil)
+	}
+
+	h := &handler[T]{runtime: runtime, broker: broker, mailbox: mailbox}
+	return websocket.Handler(h.serve), nil
+}
+
+type handler[T g.SharedState] struct {
+	runtime g.Runtime[T]
+	broker  *server.Broker[T]
+	mailbox *server.Mailbox[T]
+}
+
+type connection[T g.SharedState] struct {
+	*handler[T]
+
+	ctx context.Context
+	out chan ServerMessage
+
+	mu       sync.Mutex
+	threadID string
+	stop     context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func (h *handler[T]) serve(ws *websocket.Conn) {
+	ctx, cancel := context.WithCancel(ws.Request().Context())
+	conn := &connection[T]{handler: h, ctx: ctx, out: make(chan ServerMessage, server.DefaultSubscriptionBufferSize)}
+
+	writerDone := make(chan struct{})
+	go func() {
+		defer close(writerDone)
+		conn.write(ws)
+	}()
+
+	for {
+		var msg ClientMessage
+		if err := websocket.JSON.Receive(ws, &msg); err != nil {
+			break
+		}
+		if err := conn.handle(msg); err != nil {
+			conn.send(ServerMessage{Type: TypeError, ThreadID: msg.ThreadID, Error: err.Error()})
+		}
+	}
+
+	cancel()
+	conn.wg.Wait()
+	close(conn.out)
+	<-writerDone
+}
+
+func (c *connection[T]) handle(msg ClientMessage) error {
+	switch msg.Type {
+	case TypeInvoke:
+		return c.invoke(msg)
+	case TypeMessage:
+		if c.mailbox == nil {
+			return ErrMailboxNotSet
+		}
+		threadID, err := c.thread(msg)
+		if err != nil {
+			return err
+		}
+		message, err := decodeState[T](msg.State)
+		if err != nil {
+			return err
+		}
+		return c.mailbox.Deliver(threadID, message)
+	case TypeCancel:
+		threadID, err := c.thread(msg)
+		if err != nil {
+			return err
+		}
+		return c.runtime.Cancel(threadID)
+	default:
+		return fmt.Errorf("%w: %q", ErrUnknownMessageType, msg.Type)
+	}
+}
+
+func (c *connection[T]) invoke(msg ClientMessage) error {
+	userInput, err := decodeState[T](msg.State)
+	if err != nil {
+		return err
+	}
+	threadID := msg.ThreadID
+	if threadID == "" {
+		threadID = c.runtime.NewThreadID()
+	}
+
+	c.mu.Lock()
+	if c.stop != nil {
+		c.stop()
+	}
+	followCtx, stop := context.WithCancel(c.ctx)
+	c.threadID = threadID
+	c.stop = stop
+	c.mu.Unlock()
+
+	sub, unsubscribe := c.broker.Subscribe(threadID, 0)
+	var interrupts <-chan server.Interrupt
+	unwatch := func() {}
+	if c.mailbox != nil {
+		interrupts, unwatch = c.mailbox.Watch(threadID)
+	}
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		defer unsubscribe()
+		defer unwatch()
+		c.follow(followCtx, sub, interrupts)
+	}()
+
+	c.runtime.Invoke(userInput, g.InvokeConfigThreadID(threadID), g.InvokeConfigContext(c.ctx))
+	return nil
+}
+
+func (c *connection[T]) follow(ctx context.Context, sub *server.Subscription[T], interrupts <-chan server.Interrupt) {
+	for {
+		select {
+		case entry := <-sub.Entries():
+			c.send(encodeEntry(entry))
+		case interrupt := <-interrupts:
+			c.send(ServerMessage{Type: TypeInterrupt, ThreadID: interrupt.ThreadID, Node: interrupt.Node})
+		case <-sub.Done():
+			for len(sub.Entries()) > 0 {
+				c.send(encodeEntry(<-sub.Entries()))
+			}
+			c.send(encodeEntry(sub.Final()))
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (c *connection[T]) thread(msg ClientMessage) (string, error) {
+	if msg.ThreadID != "" {
+		return msg.ThreadID, nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.threadID == "" {
+		return "", ErrNoThread
+	}
+	return c.threadID, nil
+}
+
+func (c *connection[T]) send(msg ServerMessage) {
+	select {
+	case c.out <- msg:
+	case <-c.ctx.Done():
+	}
+}
+
+func (c *connection[T]) write(ws *websocket.Conn) {
+	for msg := range c.out {
+		if err := websocket.JSON.Send(ws, msg); err != nil {
+			// Keep draining until the connection is torn down.
+			continue
+		}
+	}
+}
+
+func decodeState[T g.SharedState](raw json.RawMessage) (T, error) {
+	var rv T
+	if len(raw) == 0 {
+		return rv, nil
+	}
+	if err := json.Unmarshal(raw, &rv); err != nil {
+		return rv, fmt.Errorf("cannot decode the state: %w", err)
+	}
+	return rv, nil
+}
+
+func encodeEntry[T g.SharedState](entry g.StateMonitorEntry[T]) ServerMessage {
+	rv := ServerMessage{
+		Type:     TypeEntry,
+		ThreadID: entry.ThreadID,
+		Node:     entry.Node,
+		Running:  entry.Running,
+		Partial:  entry.Partial,
+		Outcome:  entry.Outcome,
+	}
+	if state, err := json.Marshal(entry.NewState); err == nil {
+		rv.State = state
+	}
+	if !entry.Usage.IsZero() {
+		usage := entry.Usage
+		rv.Usage = &usage
+	}
+	if entry.Error != nil {
+		rv.Error = entry.Error.Error()
+	}
+	return rv
+}
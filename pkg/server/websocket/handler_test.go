@@ -0,0 +1,144 @@
+package websocket_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	xws "golang.org/x/net/websocket"
+
+	"github.com/morphy76/ggraph/pkg/builders"
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/server"
+	"github.com/morphy76/ggraph/pkg/server/websocket"
+)
+
+type chatState struct {
+	Question string `json:"question,omitempty"`
+	Answer   string `json:"answer,omitempty"`
+}
+
+func startHandler(t *testing.T) *xws.Conn {
+	mailbox := server.NewMailbox[chatState]()
+
+	ask, _ := builders.NewNode("Ask", func(userInput, currentState chatState, notify g.NotifyPartialFn[chatState]) (chatState, error) {
+		notify(chatState{Question: "thinking"})
+		return chatState{Question: userInput.Question + "?"}, nil
+	})
+	wait, err := server.NewInterruptNode("Wait", mailbox, g.WithReducer(func(currentState, change chatState) chatState {
+		currentState.Answer = change.Answer
+		return currentState
+	}))
+	if err != nil {
+		t.Fatalf("NewInterruptNode failed: %v", err)
+	}
+
+	stateMonitorCh := make(chan g.StateMonitorEntry[chatState], 100)
+	runtime, _ := builders.CreateRuntime(builders.CreateStartEdge(ask), stateMonitorCh)
+	runtime.AddEdge(builders.CreateEdge(ask, wait), builders.CreateEndEdge(wait))
+	broker := server.NewBroker(stateMonitorCh, nil)
+
+	handler, err := websocket.NewHandler(runtime, broker, mailbox)
+	if err != nil {
+		t.Fatalf("NewHandler failed: %v", err)
+	}
+	httpServer := httptest.NewServer(handler)
+
+	url := "ws" + strings.TrimPrefix(httpServer.URL, "http")
+	conn, err := xws.Dial(url, "", httpServer.URL)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	t.Cleanup(func() {
+		conn.Close()
+		httpServer.Close()
+		broker.Stop()
+		runtime.Shutdown()
+	})
+	return conn
+}
+
+func receiveUntil(t *testing.T, conn *xws.Conn, done func(websocket.ServerMessage) bool) []websocket.ServerMessage {
+	var rv []websocket.ServerMessage
+	for {
+		var msg websocket.ServerMessage
+		if err := xws.JSON.Receive(conn, &msg); err != nil {
+			t.Fatalf("Receive failed after %v: %v", rv, err)
+		}
+		rv = append(rv, msg)
+		if done(msg) {
+			return rv
+		}
+	}
+}
+
+func TestHandler_InterruptRoundTrip(t *testing.T) {
+	conn := startHandler(t)
+
+	if err := xws.JSON.Send(conn, websocket.ClientMessage{Type: websocket.TypeMessage, ThreadID: "idle", State: json.RawMessage(`{}`)}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	errMsg := receiveUntil(t, conn, func(msg websocket.ServerMessage) bool { return msg.Type == websocket.TypeError })
+	if !strings.Contains(errMsg[0].Error, server.ErrNoWaitingInterrupt.Error()) {
+		t.Errorf("Expected ErrNoWaitingInterrupt, got %q", errMsg[0].Error)
+	}
+
+	if err := xws.JSON.Send(conn, websocket.ClientMessage{Type: websocket.TypeInvoke, ThreadID: "chat", State: json.RawMessage(`{"question":"ready"}`)}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	messages := receiveUntil(t, conn, func(msg websocket.ServerMessage) bool { return msg.Type == websocket.TypeInterrupt })
+	if last := messages[len(messages)-1]; last.Node != "Wait" || last.ThreadID != "chat" {
+		t.Errorf("Unexpected interrupt: %+v", last)
+	}
+	received := messages
+
+	if err := xws.JSON.Send(conn, websocket.ClientMessage{Type: websocket.TypeMessage, State: json.RawMessage(`{"answer":"yes"}`)}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	messages = receiveUntil(t, conn, func(msg websocket.ServerMessage) bool { return msg.Type == websocket.TypeEntry && !msg.Running })
+	received = append(received, messages...)
+
+	// Entries and interrupts travel on distinct paths, only their presence is checked.
+	sawPartial := false
+	for _, msg := range received {
+		sawPartial = sawPartial || msg.Partial
+	}
+	if !sawPartial {
+		t.Error("Expected a partial entry")
+	}
+
+	final := messages[len(messages)-1]
+	if final.Error != "" {
+		t.Fatalf("Expected a completed invocation, got %q", final.Error)
+	}
+	var state chatState
+	if err := json.Unmarshal(final.State, &state); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if state.Question != "ready?" || state.Answer != "yes" {
+		t.Errorf("Unexpected final state: %+v", state)
+	}
+}
+
+func TestHandler_UnknownMessage(t *testing.T) {
+	conn := startHandler(t)
+
+	if err := xws.JSON.Send(conn, websocket.ClientMessage{Type: "bogus"}); err != nil {
+		t.Fatalf("Send failed: %v", err)
+	}
+	messages := receiveUntil(t, conn, func(msg websocket.ServerMessage) bool { return msg.Type == websocket.TypeError })
+	if !strings.Contains(messages[0].Error, websocket.ErrUnknownMessageType.Error()) {
+		t.Errorf("Expected ErrUnknownMessageType, got %q", messages[0].Error)
+	}
+}
+
+func TestNewHandler_Validation(t *testing.T) {
+	if _, err := websocket.NewHandler[chatState](nil, nil, nil); !errors.Is(err, websocket.ErrRuntimeNil) {
+		t.Errorf("Expected ErrRuntimeNil, got %v", err)
+	}
+}
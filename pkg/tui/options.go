@@ -0,0 +1,189 @@
+package tui
+
+import (
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+const (
+	// DefaultTitle is the default title of the monitor.
+	DefaultTitle = "ggraph monitor"
+	// DefaultMaxErrors is the default number of errors listed by the monitor.
+	DefaultMaxErrors = 8
+	// DefaultPreviewLines is the default number of lines of the preview of the selected thread.
+	DefaultPreviewLines = 6
+)
+
+var (
+	// ErrInvalidOption indicates that an option of the monitor has an invalid value.
+	ErrInvalidOption = errors.New("invalid monitor option")
+)
+
+// Options holds the configuration of the monitor.
+type Options[T g.SharedState] struct {
+	// Title is shown at the top of the monitor.
+	Title string
+	// Preview renders the text shown for the selected thread, e.g. the streamed tokens; nil shows none.
+	Preview func(state T) string
+	// OnEntry is called with every monitor entry, on the UI goroutine: it must return quickly.
+	OnEntry func(entry g.StateMonitorEntry[T])
+	// ExitAfter quits the monitor once that many invocations terminated, 0 to wait for the user.
+	ExitAfter int
+	// MaxErrors is the number of most recent errors listed.
+	MaxErrors int
+	// PreviewLines is the number of lines of the preview of the selected thread.
+	PreviewLines int
+	// ProgramOptions are the options of the bubbletea program, the alternate screen when nil.
+	ProgramOptions []tea.ProgramOption
+}
+
+// Option is a functional option for configuring the monitor.
+type Option[T g.SharedState] interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - o: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(o *Options[T]) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc[T g.SharedState] func(*Options[T]) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - o: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc[T]) Apply(o *Options[T]) error { return s(o) }
+
+// WithTitle sets the title of the monitor.
+//
+// Parameters:
+//   - title: The title.
+//
+// Returns:
+//   - An Option that sets the title.
+func WithTitle[T g.SharedState](title string) Option[T] {
+	return OptionFunc[T](func(o *Options[T]) error {
+		o.Title = title
+		return nil
+	})
+}
+
+// WithPreview renders the text shown for the selected thread.
+//
+// Parameters:
+//   - preview: The function rendering the state, see ConversationPreview for the agent conversations.
+//
+// Returns:
+//   - An Option that sets the preview.
+//
+// Example:
+//
+//	tui.WithPreview(tui.ConversationPreview)
+func WithPreview[T g.SharedState](preview func(state T) string) Option[T] {
+	return OptionFunc[T](func(o *Options[T]) error {
+		if preview == nil {
+			return fmt.Errorf("%w: nil preview", ErrInvalidOption)
+		}
+		o.Preview = preview
+		return nil
+	})
+}
+
+// WithOnEntry calls a function with every monitor entry, so that the application keeps its own bookkeeping.
+//
+// The function is called on the UI goroutine, before the entry is rendered: it must return quickly.
+//
+// Parameters:
+//   - onEntry: The function.
+//
+// Returns:
+//   - An Option that sets the function.
+func WithOnEntry[T g.SharedState](onEntry func(entry g.StateMonitorEntry[T])) Option[T] {
+	return OptionFunc[T](func(o *Options[T]) error {
+		if onEntry == nil {
+			return fmt.Errorf("%w: nil entry function", ErrInvalidOption)
+		}
+		o.OnEntry = onEntry
+		return nil
+	})
+}
+
+// WithExitAfter quits the monitor once a number of invocations terminated, successfully or not.
+//
+// Parameters:
+//   - invocations: The number of invocations, it must be positive.
+//
+// Returns:
+//   - An Option that sets the number of invocations.
+func WithExitAfter[T g.SharedState](invocations int) Option[T] {
+	return OptionFunc[T](func(o *Options[T]) error {
+		if invocations < 1 {
+			return fmt.Errorf("%w: the number of invocations must be positive", ErrInvalidOption)
+		}
+		o.ExitAfter = invocations
+		return nil
+	})
+}
+
+// WithMaxErrors sets the number of most recent errors listed.
+//
+// Parameters:
+//   - maxErrors: The number of errors, it must be positive.
+//
+// Returns:
+//   - An Option that sets the number of errors.
+func WithMaxErrors[T g.SharedState](maxErrors int) Option[T] {
+	return OptionFunc[T](func(o *Options[T]) error {
+		if maxErrors < 1 {
+			return fmt.Errorf("%w: the number of errors must be positive", ErrInvalidOption)
+		}
+		o.MaxErrors = maxErrors
+		return nil
+	})
+}
+
+// WithPreviewLines sets the number of lines of the preview of the selected thread.
+//
+// Parameters:
+//   - lines: The number of lines, it must be positive.
+//
+// Returns:
+//   - An Option that sets the number of lines.
+func WithPreviewLines[T g.SharedState](lines int) Option[T] {
+	return OptionFunc[T](func(o *Options[T]) error {
+		if lines < 1 {
+			return fmt.Errorf("%w: the number of preview lines must be positive", ErrInvalidOption)
+		}
+		o.PreviewLines = lines
+		return nil
+	})
+}
+
+// WithProgramOptions sets the options of the bubbletea program, replacing the alternate screen.
+//
+// Parameters:
+//   - opts: The program options.
+//
+// Returns:
+//   - An Option that sets the program options.
+//
+// Example:
+//
+//	tui.WithProgramOptions[MyState](tea.WithInput(nil), tea.WithOutput(logFile))
+func WithProgramOptions[T g.SharedState](opts ...tea.ProgramOption) Option[T] {
+	return OptionFunc[T](func(o *Options[T]) error {
+		o.ProgramOptions = append(o.ProgramOptions, opts...)
+		return nil
+	})
+}
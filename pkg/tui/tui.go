@@ -0,0 +1,447 @@
+// Package tui provides an interactive terminal monitor for the running graphs.
+//
+// The monitor consumes the state monitor channel of a runtime and shows, in real time, the
+// threads with their status, current node, steps, elapsed time and token usage, the streamed
+// preview of the selected thread and the most recent errors. It is built on bubbletea: Run
+// starts the program and returns the final snapshot of the threads, Model can be embedded in a
+// larger bubbletea application.
+//
+// The monitor owns the channel while it runs: use WithOnEntry for any other bookkeeping.
+package tui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	g "github.com/morphy76/ggraph/pkg/graph"
+)
+
+// ThreadStatus is the status of a thread shown by the monitor.
+type ThreadStatus int
+
+const (
+	// ThreadRunning is a thread executing an invocation.
+	ThreadRunning ThreadStatus = iota
+	// ThreadPaused is a thread paused at a breakpoint, see graph.WithDebugger.
+	ThreadPaused
+	// ThreadCompleted is a thread whose last invocation completed.
+	ThreadCompleted
+	// ThreadFailed is a thread whose last invocation failed.
+	ThreadFailed
+)
+
+// String returns the name of the status.
+func (s ThreadStatus) String() string {
+	switch s {
+	case ThreadRunning:
+		return "running"
+	case ThreadPaused:
+		return "paused"
+	case ThreadCompleted:
+		return "completed"
+	case ThreadFailed:
+		return "failed"
+	default:
+		return "unknown"
+	}
+}
+
+// Thread is the snapshot of a thread seen by the monitor.
+type Thread[T g.SharedState] struct {
+	// ID is the thread ID.
+	ID string
+	// Status is the status of the thread.
+	Status ThreadStatus
+	// Node is the node of the last entry.
+	Node string
+	// Steps counts the nodes executed by the thread.
+	Steps int
+	// StartedAt is the time the first entry of the thread was received.
+	StartedAt time.Time
+	// UpdatedAt is the time the last entry of the thread was received.
+	UpdatedAt time.Time
+	// Usage is the token usage of the last invocation.
+	Usage g.Usage
+	// Preview is the text rendered by the preview function from the last state, streamed or not.
+	Preview string
+	// State is the last committed state, the final one once the invocation terminated.
+	State T
+	// Err is the error of the failed invocation.
+	Err error
+}
+
+// Elapsed returns the time since the thread started, until its last entry once it terminated.
+//
+// Returns:
+//   - The elapsed time.
+func (t Thread[T]) Elapsed() time.Duration {
+	if t.Status == ThreadCompleted || t.Status == ThreadFailed {
+		return t.UpdatedAt.Sub(t.StartedAt)
+	}
+	return time.Since(t.StartedAt)
+}
+
+// loggedError is an error listed by the monitor.
+type loggedError struct {
+	at       time.Time
+	threadID string
+	node     string
+	err      error
+}
+
+type entryMsg[T g.SharedState] struct {
+	entry g.StateMonitorEntry[T]
+}
+
+type closedMsg struct{}
+
+type tickMsg time.Time
+
+var (
+	titleStyle    = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("12"))
+	headerStyle   = lipgloss.NewStyle().Bold(true).Faint(true)
+	selectedStyle = lipgloss.NewStyle().Bold(true).Reverse(true)
+	faintStyle    = lipgloss.NewStyle().Faint(true)
+	statusStyles  = map[ThreadStatus]lipgloss.Style{
+		ThreadRunning:   lipgloss.NewStyle().Foreground(lipgloss.Color("11")),
+		ThreadPaused:    lipgloss.NewStyle().Foreground(lipgloss.Color("13")),
+		ThreadCompleted: lipgloss.NewStyle().Foreground(lipgloss.Color("10")),
+		ThreadFailed:    lipgloss.NewStyle().Foreground(lipgloss.Color("9")),
+	}
+	errorStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("9"))
+)
+
+// Model is the bubbletea model of the monitor.
+type Model[T g.SharedState] struct {
+	entries <-chan g.StateMonitorEntry[T]
+	opts    Options[T]
+
+	threads    []*Thread[T]
+	byID       map[string]*Thread[T]
+	errors     []loggedError
+	terminated int
+	closed     bool
+
+	selected int
+	width    int
+}
+
+// NewModel creates the model of the monitor.
+//
+// Parameters:
+//   - entries: The state monitor channel of the runtime.
+//   - opts: The configuration options.
+//
+// Returns:
+//   - *Model[T]: The model.
+//   - error: An error if the options are invalid.
+func NewModel[T g.SharedState](entries <-chan g.StateMonitorEntry[T], opts ...Option[T]) (*Model[T], error) {
+	useOpts := Options[T]{
+		Title:        DefaultTitle,
+		MaxErrors:    DefaultMaxErrors,
+		PreviewLines: DefaultPreviewLines,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(&useOpts); err != nil {
+			return nil, fmt.Errorf("cannot create the monitor: %w", err)
+		}
+	}
+	return &Model[T]{
+		entries: entries,
+		opts:    useOpts,
+		byID:    make(map[string]*Thread[T]),
+		width:   100,
+	}, nil
+}
+
+// Run shows the monitor until the user quits, ctx is done, the channel is closed or WithExitAfter is satisfied.
+//
+// Parameters:
+//   - ctx: The lifetime of the monitor.
+//   - entries: The state monitor channel of the runtime.
+//   - opts: The configuration options.
+//
+// Returns:
+//   - []Thread[T]: The final snapshot of the threads, in the order they were first seen.
+//   - error: An error if the options are invalid or the program fails, the cause of ctx if it is done.
+//
+// Example:
+//
+//	stateMonitorCh := make(chan graph.StateMonitorEntry[a.Conversation], 100)
+//	runtime, _ := builders.CreateRuntime(startEdge, stateMonitorCh)
+//	go invokeAll(runtime)
+//	threads, err := tui.Run(ctx, stateMonitorCh, tui.WithPreview(tui.ConversationPreview), tui.WithExitAfter[a.Conversation](10))
+func Run[T g.SharedState](ctx context.Context, entries <-chan g.StateMonitorEntry[T], opts ...Option[T]) ([]Thread[T], error) {
+	model, err := NewModel(entries, opts...)
+	if err != nil {
+		return nil, err
+	}
+	programOpts := model.opts.ProgramOptions
+	if programOpts == nil {
+		programOpts = []tea.ProgramOption{tea.WithAltScreen()}
+	}
+	programOpts = append(programOpts, tea.WithContext(ctx))
+
+	_, err = tea.NewProgram(model, programOpts...).Run()
+	if err != nil && errors.Is(err, tea.ErrProgramKilled) && ctx.Err() != nil {
+		return model.Threads(), context.Cause(ctx)
+	}
+	if err != nil {
+		return model.Threads(), fmt.Errorf("monitor failed: %w", err)
+	}
+	return model.Threads(), nil
+}
+
+// Threads returns the snapshot of the threads, in the order they were first seen.
+//
+// It must not be called while the model is run by a program.
+//
+// Returns:
+//   - The threads.
+func (m *Model[T]) Threads() []Thread[T] {
+	rv := make([]Thread[T], len(m.threads))
+	for i, thread := range m.threads {
+		rv[i] = *thread
+	}
+	return rv
+}
+
+// Init implements tea.Model, it starts consuming the channel.
+func (m *Model[T]) Init() tea.Cmd {
+	return tea.Batch(m.next(), tick())
+}
+
+func (m *Model[T]) next() tea.Cmd {
+	return func() tea.Msg {
+		entry, ok := <-m.entries
+		if !ok {
+			return closedMsg{}
+		}
+		return entryMsg[T]{entry: entry}
+	}
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(time.Second, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+// Update implements tea.Model.
+func (m *Model[T]) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case entryMsg[T]:
+		m.apply(msg.entry)
+		if m.opts.ExitAfter > 0 && m.terminated >= m.opts.ExitAfter {
+			return m, tea.Quit
+		}
+		return m, m.next()
+	case closedMsg:
+		m.closed = true
+		return m, tea.Quit
+	case tickMsg:
+		return m, tick()
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "q", "esc", "ctrl+c":
+			return m, tea.Quit
+		case "up", "k":
+			m.selected = max(m.selected-1, 0)
+		case "down", "j":
+			m.selected = min(m.selected+1, max(len(m.threads)-1, 0))
+		case "home", "g":
+			m.selected = 0
+		case "end", "G":
+			m.selected = max(len(m.threads)-1, 0)
+		}
+	}
+	return m, nil
+}
+
+// apply folds the entry into the snapshot of its thread.
+func (m *Model[T]) apply(entry g.StateMonitorEntry[T]) {
+	if m.opts.OnEntry != nil {
+		m.opts.OnEntry(entry)
+	}
+
+	now := time.Now()
+	thread, ok := m.byID[entry.ThreadID]
+	if !ok {
+		thread = &Thread[T]{ID: entry.ThreadID, StartedAt: now}
+		m.byID[entry.ThreadID] = thread
+		m.threads = append(m.threads, thread)
+	}
+	if thread.Status == ThreadCompleted || thread.Status == ThreadFailed {
+		if entry.Running && entry.Error == nil {
+			// a new invocation of the same thread
+			*thread = Thread[T]{ID: entry.ThreadID, StartedAt: now}
+		}
+	}
+	thread.UpdatedAt = now
+	if entry.Running && entry.Error != nil {
+		// a non-fatal error, its entry carries no state
+		m.logError(now, entry)
+		return
+	}
+	if !entry.Usage.IsZero() {
+		thread.Usage = entry.Usage
+	}
+	if m.opts.Preview != nil {
+		thread.Preview = m.opts.Preview(entry.NewState)
+	}
+
+	switch {
+	case entry.Partial:
+		thread.Node = entry.Node
+		return
+	case entry.Breakpoint != nil:
+		thread.Status = ThreadPaused
+	case entry.Running:
+		thread.Status = ThreadRunning
+		thread.Steps++
+	case entry.Error != nil:
+		thread.Status = ThreadFailed
+		thread.Err = entry.Error
+		m.terminated++
+		m.logError(now, entry)
+	default:
+		thread.Status = ThreadCompleted
+		thread.Steps++
+		m.terminated++
+	}
+	thread.Node = entry.Node
+	thread.State = entry.NewState
+}
+
+func (m *Model[T]) logError(at time.Time, entry g.StateMonitorEntry[T]) {
+	m.errors = append(m.errors, loggedError{at: at, threadID: entry.ThreadID, node: entry.Node, err: entry.Error})
+	if len(m.errors) > m.opts.MaxErrors {
+		m.errors = m.errors[len(m.errors)-m.opts.MaxErrors:]
+	}
+}
+
+// View implements tea.Model.
+func (m *Model[T]) View() string {
+	var sb strings.Builder
+
+	counts := make(map[ThreadStatus]int)
+	var tokens int64
+	for _, thread := range m.threads {
+		counts[thread.Status]++
+		tokens += thread.Usage.TotalTokens
+	}
+	sb.WriteString(titleStyle.Render(m.opts.Title))
+	sb.WriteString("\n")
+	summary := fmt.Sprintf("threads %d · running %d · paused %d · completed %d · failed %d · tokens %d",
+		len(m.threads), counts[ThreadRunning], counts[ThreadPaused], counts[ThreadCompleted], counts[ThreadFailed], tokens)
+	if m.closed {
+		summary += " · monitor closed"
+	}
+	sb.WriteString(faintStyle.Render(summary))
+	sb.WriteString("\n\n")
+
+	idWidth := len("THREAD")
+	nodeWidth := len("NODE")
+	for _, thread := range m.threads {
+		idWidth = max(idWidth, min(len(thread.ID), 36))
+		nodeWidth = max(nodeWidth, min(len(thread.Node), 24))
+	}
+	row := func(id, status, node, steps, elapsed, tokens string) string {
+		return fmt.Sprintf("  %-*s  %-9s  %-*s  %5s  %8s  %8s", idWidth, truncate(id, idWidth), status, nodeWidth, truncate(node, nodeWidth), steps, elapsed, tokens)
+	}
+	sb.WriteString(headerStyle.Render(row("THREAD", "STATUS", "NODE", "STEPS", "ELAPSED", "TOKENS")))
+	sb.WriteString("\n")
+	if len(m.threads) == 0 {
+		sb.WriteString(faintStyle.Render("  waiting for the first monitor entry..."))
+		sb.WriteString("\n")
+	}
+	for i, thread := range m.threads {
+		line := row(thread.ID, thread.Status.String(), thread.Node, fmt.Sprint(thread.Steps),
+			thread.Elapsed().Round(100*time.Millisecond).String(), fmt.Sprint(thread.Usage.TotalTokens))
+		if i == m.selected {
+			sb.WriteString(selectedStyle.Render(line))
+		} else {
+			sb.WriteString(statusStyles[thread.Status].Render(line))
+		}
+		sb.WriteString("\n")
+	}
+
+	if m.selected < len(m.threads) {
+		thread := m.threads[m.selected]
+		sb.WriteString("\n")
+		sb.WriteString(headerStyle.Render("── " + thread.ID + " "))
+		sb.WriteString("\n")
+		if thread.Err != nil {
+			sb.WriteString(errorStyle.Render(thread.Err.Error()))
+			sb.WriteString("\n")
+		}
+		if thread.Preview != "" {
+			sb.WriteString(lastLines(lipgloss.NewStyle().Width(max(m.width-2, 20)).Render(thread.Preview), m.opts.PreviewLines))
+			sb.WriteString("\n")
+		}
+	}
+
+	if len(m.errors) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(headerStyle.Render("ERRORS"))
+		sb.WriteString("\n")
+		for _, logged := range m.errors {
+			line := fmt.Sprintf("  %s %s %s: %v", logged.at.Format(time.TimeOnly), logged.threadID, logged.node, logged.err)
+			sb.WriteString(errorStyle.Render(truncate(line, max(m.width, 20))))
+			sb.WriteString("\n")
+		}
+	}
+
+	sb.WriteString("\n")
+	sb.WriteString(faintStyle.Render("↑/↓ select · q quit"))
+	sb.WriteString("\n")
+	return sb.String()
+}
+
+func truncate(value string, width int) string {
+	runes := []rune(value)
+	if len(runes) <= width {
+		return value
+	}
+	if width <= 1 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-1]) + "…"
+}
+
+func lastLines(value string, lines int) string {
+	split := strings.Split(strings.TrimRight(value, "\n"), "\n")
+	if len(split) > lines {
+		split = split[len(split)-lines:]
+	}
+	return strings.Join(split, "\n")
+}
+
+// ConversationPreview renders the last message of a conversation, the growing answer while it is streamed.
+//
+// Parameters:
+//   - conversation: The state of the thread.
+//
+// Returns:
+//   - The role and the content of the last message, empty if there is none.
+func ConversationPreview(conversation a.Conversation) string {
+	if len(conversation.Messages) == 0 {
+		return ""
+	}
+	last := conversation.Messages[len(conversation.Messages)-1]
+	return fmt.Sprintf("%s: %s", roleNames[last.Role], last.Content)
+}
+
+var roleNames = map[a.MessageRole]string{
+	a.System:    "system",
+	a.User:      "user",
+	a.Assistant: "assistant",
+	a.Tool:      "tool",
+}
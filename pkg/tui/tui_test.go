@@ -0,0 +1,101 @@
+package tui_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	a "github.com/morphy76/ggraph/pkg/agent"
+	g "github.com/morphy76/ggraph/pkg/graph"
+	"github.com/morphy76/ggraph/pkg/tui"
+)
+
+func headless[T g.SharedState]() tui.Option[T] {
+	return tui.WithProgramOptions[T](tea.WithInput(nil), tea.WithOutput(io.Discard))
+}
+
+func conversation(content string) a.Conversation {
+	return a.CreateConversation(a.CreateMessage(a.User, "question"), a.CreateMessage(a.Assistant, content))
+}
+
+func TestRun(t *testing.T) {
+	entries := make(chan g.StateMonitorEntry[a.Conversation], 10)
+	entries <- g.StateMonitorEntry[a.Conversation]{ThreadID: "t1", Node: "StartNode", Running: true, NewState: a.CreateConversation()}
+	entries <- g.StateMonitorEntry[a.Conversation]{ThreadID: "t2", Node: "StartNode", Running: true}
+	entries <- g.StateMonitorEntry[a.Conversation]{ThreadID: "t1", Node: "Chat", Running: true, Partial: true, NewState: conversation("Hel")}
+	entries <- g.StateMonitorEntry[a.Conversation]{ThreadID: "t1", Node: "Persistence", Running: true, Error: errors.New("disk full")}
+	entries <- g.StateMonitorEntry[a.Conversation]{ThreadID: "t1", Node: "EndNode", NewState: conversation("Hello"), Usage: g.Usage{TotalTokens: 12}}
+	entries <- g.StateMonitorEntry[a.Conversation]{ThreadID: "t2", Node: "Chat", Error: errors.New("boom")}
+
+	seen := 0
+	threads, err := tui.Run(context.Background(), entries,
+		tui.WithPreview(tui.ConversationPreview),
+		tui.WithOnEntry(func(g.StateMonitorEntry[a.Conversation]) { seen++ }),
+		tui.WithExitAfter[a.Conversation](2),
+		headless[a.Conversation]())
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if seen != 6 {
+		t.Errorf("Expected the 6 entries to be passed on, got %d", seen)
+	}
+	if len(threads) != 2 || threads[0].ID != "t1" || threads[1].ID != "t2" {
+		t.Fatalf("Unexpected threads %+v", threads)
+	}
+	if first := threads[0]; first.Status != tui.ThreadCompleted || first.Steps != 2 || first.Node != "EndNode" ||
+		first.Usage.TotalTokens != 12 || first.Preview != "assistant: Hello" || first.State.Messages[1].Content != "Hello" {
+		t.Errorf("Unexpected completed thread %+v", first)
+	}
+	if second := threads[1]; second.Status != tui.ThreadFailed || second.Err == nil || second.Err.Error() != "boom" {
+		t.Errorf("Unexpected failed thread %+v", second)
+	}
+}
+
+func TestModel_View(t *testing.T) {
+	entries := make(chan g.StateMonitorEntry[a.Conversation], 10)
+	entries <- g.StateMonitorEntry[a.Conversation]{ThreadID: "thread-1", Node: "Chat", Running: true, Partial: true, NewState: conversation("streaming tok")}
+	entries <- g.StateMonitorEntry[a.Conversation]{ThreadID: "thread-1", Node: "ThreadEvictor", Running: true, Error: g.ErrEvictionByInactivity}
+	close(entries)
+
+	model, err := tui.NewModel(entries, tui.WithTitle[a.Conversation]("velvet"), tui.WithPreview(tui.ConversationPreview))
+	if err != nil {
+		t.Fatalf("NewModel failed: %v", err)
+	}
+	if _, err := tea.NewProgram(model, tea.WithInput(nil), tea.WithOutput(io.Discard)).Run(); err != nil {
+		t.Fatalf("Expected the monitor to quit once the channel is closed, got %v", err)
+	}
+
+	view := model.View()
+	for _, expected := range []string{"velvet", "monitor closed", "thread-1", "running", "Chat", "assistant: streaming tok", "ERRORS", g.ErrEvictionByInactivity.Error()} {
+		if !strings.Contains(view, expected) {
+			t.Errorf("Expected the view to contain %q:\n%s", expected, view)
+		}
+	}
+}
+
+func TestRun_Context(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := tui.Run(ctx, make(chan g.StateMonitorEntry[a.Conversation]), headless[a.Conversation]()); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the monitor to stop with its context, got %v", err)
+	}
+}
+
+func TestNewModel_Invalid(t *testing.T) {
+	for _, opt := range []tui.Option[a.Conversation]{
+		tui.WithPreview[a.Conversation](nil),
+		tui.WithOnEntry[a.Conversation](nil),
+		tui.WithExitAfter[a.Conversation](0),
+		tui.WithMaxErrors[a.Conversation](0),
+		tui.WithPreviewLines[a.Conversation](0),
+	} {
+		if _, err := tui.NewModel(nil, opt); !errors.Is(err, tui.ErrInvalidOption) {
+			t.Errorf("Expected ErrInvalidOption, got %v", err)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package vectorstore
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"math"
+	"sort"
+	"sync"
+)
+
+var _ Store = (*MemStore)(nil)
+
+// MemStore is an in-memory Store performing exact cosine similarity searches.
+//
+// It suits tests and small corpora; the search is linear in the number of documents.
+type MemStore struct {
+	mu         sync.RWMutex
+	dimensions int
+	docs       map[string]Document
+}
+
+// NewMemStore creates an empty in-memory Store.
+//
+// Parameters:
+//   - dimensions: The dimensions of the embeddings, 0 to accept the dimensions of the first document.
+//
+// Returns:
+//   - The MemStore.
+//
+// Example:
+//
+//	store := vectorstore.NewMemStore(0)
+//	err := store.Upsert(ctx, vectorstore.Document{ID: "1", Content: "hello", Embedding: vector})
+func NewMemStore(dimensions int) *MemStore {
+	return &MemStore{
+		dimensions: dimensions,
+		docs:       make(map[string]Document),
+	}
+}
+
+// Upsert inserts the documents, replacing those with the same ID.
+func (s *MemStore) Upsert(ctx context.Context, docs ...Document) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dimensions := s.dimensions
+	for _, doc := range docs {
+		if dimensions == 0 {
+			dimensions = len(doc.Embedding)
+		}
+		if err := ValidateDocument(doc, dimensions); err != nil {
+			return fmt.Errorf("cannot upsert document %q: %w", doc.ID, err)
+		}
+	}
+
+	s.dimensions = dimensions
+	for _, doc := range docs {
+		doc.Embedding = append([]float32{}, doc.Embedding...)
+		doc.Metadata = maps.Clone(doc.Metadata)
+		s.docs[doc.ID] = doc
+	}
+	return nil
+}
+
+// Query returns the documents closest to the query embedding, the closest first.
+func (s *MemStore) Query(ctx context.Context, query Query) ([]Match, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := ValidateQuery(query, s.dimensions); err != nil {
+		return nil, fmt.Errorf("cannot query the store: %w", err)
+	}
+
+	matches := make([]Match, 0, len(s.docs))
+	for _, doc := range s.docs {
+		if !matchesFilter(doc.Metadata, query.Filter) {
+			continue
+		}
+		score := cosine(query.Embedding, doc.Embedding)
+		if score < query.MinScore {
+			continue
+		}
+		matches = append(matches, Match{Document: doc, Score: score})
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Score == matches[j].Score {
+			return matches[i].ID < matches[j].ID
+		}
+		return matches[i].Score > matches[j].Score
+	})
+	if len(matches) > query.TopK {
+		matches = matches[:query.TopK]
+	}
+	return matches, nil
+}
+
+// Delete removes the documents with the given IDs, unknown IDs are ignored.
+func (s *MemStore) Delete(ctx context.Context, ids ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		delete(s.docs, id)
+	}
+	return nil
+}
+
+func matchesFilter(metadata, filter map[string]string) bool {
+	for key, value := range filter {
+		if metadata[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+func cosine(a, b []float32) float32 {
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return float32(dot / (math.Sqrt(normA) * math.Sqrt(normB)))
+}
@@ -0,0 +1,119 @@
+package vectorstore_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/morphy76/ggraph/pkg/vectorstore"
+)
+
+func newTestStore(t *testing.T) *vectorstore.MemStore {
+	store := vectorstore.NewMemStore(2)
+	err := store.Upsert(context.Background(),
+		vectorstore.Document{ID: "north", Content: "north", Embedding: []float32{0, 1}, Metadata: map[string]string{"lang": "en"}},
+		vectorstore.Document{ID: "east", Content: "east", Embedding: []float32{1, 0}, Metadata: map[string]string{"lang": "it"}},
+		vectorstore.Document{ID: "north-east", Content: "north-east", Embedding: []float32{1, 1}, Metadata: map[string]string{"lang": "en"}},
+	)
+	if err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	return store
+}
+
+func TestMemStore_Query(t *testing.T) {
+	store := newTestStore(t)
+
+	matches, err := store.Query(context.Background(), vectorstore.Query{Embedding: []float32{0, 2}, TopK: 2})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(matches) != 2 || matches[0].ID != "north" || matches[1].ID != "north-east" {
+		t.Fatalf("Expected [north north-east], got %+v", matches)
+	}
+	if matches[0].Score < 0.99 {
+		t.Errorf("Expected a score close to 1, got %f", matches[0].Score)
+	}
+}
+
+func TestMemStore_QueryFilterAndMinScore(t *testing.T) {
+	store := newTestStore(t)
+
+	matches, err := store.Query(context.Background(), vectorstore.Query{
+		Embedding: []float32{1, 0},
+		TopK:      5,
+		Filter:    map[string]string{"lang": "en"},
+		MinScore:  0.5,
+	})
+	if err != nil {
+		t.Fatalf("Query failed: %v", err)
+	}
+	if len(matches) != 1 || matches[0].ID != "north-east" {
+		t.Fatalf("Expected [north-east], got %+v", matches)
+	}
+}
+
+func TestMemStore_UpsertReplacesAndDelete(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	if err := store.Upsert(ctx, vectorstore.Document{ID: "east", Content: "west", Embedding: []float32{-1, 0}}); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	matches, _ := store.Query(ctx, vectorstore.Query{Embedding: []float32{-1, 0}, TopK: 1})
+	if len(matches) != 1 || matches[0].Content != "west" {
+		t.Fatalf("Expected the replaced document, got %+v", matches)
+	}
+
+	if err := store.Delete(ctx, "east", "missing"); err != nil {
+		t.Fatalf("Delete failed: %v", err)
+	}
+	matches, _ = store.Query(ctx, vectorstore.Query{Embedding: []float32{-1, 0}, TopK: 5})
+	for _, match := range matches {
+		if match.ID == "east" {
+			t.Error("Expected the document to be deleted")
+		}
+	}
+}
+
+func TestMemStore_Validation(t *testing.T) {
+	store := newTestStore(t)
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		err     error
+		wantErr error
+	}{
+		{"empty id", store.Upsert(ctx, vectorstore.Document{Embedding: []float32{1, 0}}), vectorstore.ErrDocumentIDEmpty},
+		{"empty embedding", store.Upsert(ctx, vectorstore.Document{ID: "x"}), vectorstore.ErrEmbeddingEmpty},
+		{"wrong dimensions", store.Upsert(ctx, vectorstore.Document{ID: "x", Embedding: []float32{1}}), vectorstore.ErrDimensionMismatch},
+		{"invalid topK", queryErr(store, vectorstore.Query{Embedding: []float32{1, 0}}), vectorstore.ErrInvalidTopK},
+		{"query dimensions", queryErr(store, vectorstore.Query{Embedding: []float32{1, 0, 0}, TopK: 1}), vectorstore.ErrDimensionMismatch},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !errors.Is(tt.err, tt.wantErr) {
+				t.Errorf("Expected %v, got %v", tt.wantErr, tt.err)
+			}
+		})
+	}
+}
+
+func TestMemStore_AdoptsDimensions(t *testing.T) {
+	store := vectorstore.NewMemStore(0)
+	ctx := context.Background()
+
+	if err := store.Upsert(ctx, vectorstore.Document{ID: "a", Embedding: []float32{1, 0, 0}}); err != nil {
+		t.Fatalf("Upsert failed: %v", err)
+	}
+	if err := store.Upsert(ctx, vectorstore.Document{ID: "b", Embedding: []float32{1, 0}}); !errors.Is(err, vectorstore.ErrDimensionMismatch) {
+		t.Errorf("Expected ErrDimensionMismatch, got %v", err)
+	}
+}
+
+func queryErr(store vectorstore.Store, query vectorstore.Query) error {
+	_, err := store.Query(context.Background(), query)
+	return err
+}
@@ -0,0 +1,85 @@
+package pgvector
+
+import (
+	"errors"
+
+	"github.com/morphy76/ggraph/internal/sqlident"
+)
+
+const (
+	// DefaultTableName is the default name of the table holding the documents.
+	DefaultTableName = "ggraph_documents"
+)
+
+var (
+	// ErrDBNil indicates that the provided database handle is nil.
+	ErrDBNil = errors.New("database handle cannot be nil")
+	// ErrInvalidTableName indicates that the provided table name is not a valid SQL identifier.
+	ErrInvalidTableName = errors.New("invalid table name")
+	// ErrInvalidDimensions indicates that the dimensions of the store are not positive.
+	ErrInvalidDimensions = errors.New("dimensions must be at least 1")
+)
+
+// Options holds the configuration for the pgvector Store implementation.
+type Options struct {
+	// TableName is the name of the table holding one row per document.
+	TableName string
+	// SkipMigration disables the automatic creation of the extension, the table and the index.
+	SkipMigration bool
+}
+
+// Option is a functional option for configuring the pgvector Store implementation.
+type Option interface {
+	// Apply applies the option to the Options.
+	//
+	// Parameters:
+	//   - r: A pointer to Options to modify.
+	//
+	// Returns:
+	//   - An error if the application of the option fails, otherwise nil.
+	Apply(r *Options) error
+}
+
+// OptionFunc is a function type that implements the Option interface.
+type OptionFunc func(*Options) error
+
+// Apply applies the OptionFunc to the given Options.
+//
+// Parameters:
+//   - r: A pointer to Options to modify.
+//
+// Returns:
+//   - An error if the application of the option fails, otherwise nil.
+func (s OptionFunc) Apply(r *Options) error { return s(r) }
+
+// WithTableName sets the name of the table holding the documents.
+//
+// Parameters:
+//   - name: The table name, it must be a valid SQL identifier.
+//
+// Returns:
+//   - An Option that sets the table name.
+//
+// Example:
+//
+//	store, err := pgvector.NewStore(db, 1536, pgvector.WithTableName("kb_documents"))
+func WithTableName(name string) Option {
+	return OptionFunc(func(r *Options) error {
+		if !sqlident.Valid(name) {
+			return ErrInvalidTableName
+		}
+		r.TableName = name
+		return nil
+	})
+}
+
+// WithoutMigration disables the automatic creation of the extension, the table and the index.
+//
+// Returns:
+//   - An Option that disables the schema migration.
+func WithoutMigration() Option {
+	return OptionFunc(func(r *Options) error {
+		r.SkipMigration = true
+		return nil
+	})
+}
@@ -0,0 +1,97 @@
+package pgvector_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"testing"
+
+	"github.com/morphy76/ggraph/pkg/vectorstore/pgvector"
+)
+
+type unreachableConnector struct{}
+
+func (unreachableConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, errors.New("unreachable")
+}
+
+func (unreachableConnector) Driver() driver.Driver { return nil }
+
+func TestNewStore_NilDB(t *testing.T) {
+	store, err := pgvector.NewStore(nil, 3)
+	if store != nil {
+		t.Error("Expected nil store when database is nil")
+	}
+	if !errors.Is(err, pgvector.ErrDBNil) {
+		t.Errorf("Expected ErrDBNil, got %v", err)
+	}
+}
+
+func TestNewStore_InvalidDimensions(t *testing.T) {
+	db := sql.OpenDB(unreachableConnector{})
+	defer db.Close()
+
+	store, err := pgvector.NewStore(db, 0)
+	if store != nil {
+		t.Error("Expected nil store when dimensions are invalid")
+	}
+	if !errors.Is(err, pgvector.ErrInvalidDimensions) {
+		t.Errorf("Expected ErrInvalidDimensions, got %v", err)
+	}
+}
+
+func TestOptions(t *testing.T) {
+	tests := []struct {
+		name    string
+		option  pgvector.Option
+		wantErr bool
+		check   func(t *testing.T, opts pgvector.Options)
+	}{
+		{
+			name:   "valid table name",
+			option: pgvector.WithTableName("docs_2"),
+			check: func(t *testing.T, opts pgvector.Options) {
+				if opts.TableName != "docs_2" {
+					t.Errorf("Expected TableName 'docs_2', got '%s'", opts.TableName)
+				}
+			},
+		},
+		{
+			name:    "table name with injection",
+			option:  pgvector.WithTableName("docs; DROP TABLE x"),
+			wantErr: true,
+		},
+		{
+			name:    "empty table name",
+			option:  pgvector.WithTableName(""),
+			wantErr: true,
+		},
+		{
+			name:   "without migration",
+			option: pgvector.WithoutMigration(),
+			check: func(t *testing.T, opts pgvector.Options) {
+				if !opts.SkipMigration {
+					t.Error("Expected SkipMigration to be set")
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := pgvector.Options{}
+			err := tt.option.Apply(&opts)
+			if tt.wantErr {
+				if !errors.Is(err, pgvector.ErrInvalidTableName) {
+					t.Errorf("Expected ErrInvalidTableName, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			tt.check(t, opts)
+		})
+	}
+}
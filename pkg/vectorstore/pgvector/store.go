@@ -0,0 +1,214 @@
+// Package pgvector provides a vector Store backed by PostgreSQL with the pgvector extension.
+//
+// The package does not register any SQL driver: open the database with a PostgreSQL
+// driver such as github.com/jackc/pgx/v5/stdlib and hand the *sql.DB over to NewStore.
+//
+// Example:
+//
+//	import _ "github.com/jackc/pgx/v5/stdlib"
+//
+//	db, _ := sql.Open("pgx", "postgres://localhost/rag")
+//	store, err := pgvector.NewStore(db, 1536)
+//	node, err := builders.NewRetrieverNode("Retrieve", embedder, store)
+package pgvector
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/morphy76/ggraph/pkg/vectorstore"
+)
+
+var _ vectorstore.Store = (*Store)(nil)
+
+// Store is a vector Store backed by pgvector, using the cosine distance.
+type Store struct {
+	db         *sql.DB
+	dimensions int
+	opts       Options
+}
+
+// NewStore creates a Store keeping one row per document.
+//
+// Parameters:
+//   - db: An open PostgreSQL database handle.
+//   - dimensions: The dimensions of the embeddings.
+//   - opts: Optional configuration options.
+//
+// Returns:
+//   - The pgvector Store.
+//   - An error if the arguments are invalid or the schema cannot be created.
+//
+// Example:
+//
+//	store, err := pgvector.NewStore(db, 1536)
+func NewStore(db *sql.DB, dimensions int, opts ...Option) (*Store, error) {
+	if db == nil {
+		return nil, fmt.Errorf("pgvector store creation failed: %w", ErrDBNil)
+	}
+	if dimensions < 1 {
+		return nil, fmt.Errorf("pgvector store creation failed: %w", ErrInvalidDimensions)
+	}
+
+	useOpts := &Options{
+		TableName: DefaultTableName,
+	}
+	for _, opt := range opts {
+		if err := opt.Apply(useOpts); err != nil {
+			return nil, fmt.Errorf("pgvector store creation failed: %w", err)
+		}
+	}
+
+	rv := &Store{
+		db:         db,
+		dimensions: dimensions,
+		opts:       *useOpts,
+	}
+
+	if !useOpts.SkipMigration {
+		if err := rv.migrate(context.Background()); err != nil {
+			return nil, fmt.Errorf("pgvector store creation failed: %w", err)
+		}
+	}
+
+	return rv, nil
+}
+
+// Upsert inserts the documents, replacing those with the same ID.
+func (s *Store) Upsert(ctx context.Context, docs ...vectorstore.Document) error {
+	for _, doc := range docs {
+		if err := vectorstore.ValidateDocument(doc, s.dimensions); err != nil {
+			return fmt.Errorf("cannot upsert document %q: %w", doc.ID, err)
+		}
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("cannot upsert documents: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, doc := range docs {
+		metadata, err := encodeMetadata(doc.Metadata)
+		if err != nil {
+			return fmt.Errorf("cannot upsert document %q: %w", doc.ID, err)
+		}
+		_, err = tx.ExecContext(ctx,
+			"INSERT INTO "+s.opts.TableName+" (id, content, metadata, embedding) VALUES ($1, $2, $3::jsonb, $4::vector) "+
+				"ON CONFLICT (id) DO UPDATE SET content = EXCLUDED.content, metadata = EXCLUDED.metadata, embedding = EXCLUDED.embedding",
+			doc.ID, doc.Content, metadata, vectorLiteral(doc.Embedding))
+		if err != nil {
+			return fmt.Errorf("cannot upsert document %q: %w", doc.ID, err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Query returns the documents closest to the query embedding, the closest first.
+//
+// The embeddings of the matches are not loaded.
+func (s *Store) Query(ctx context.Context, query vectorstore.Query) ([]vectorstore.Match, error) {
+	if err := vectorstore.ValidateQuery(query, s.dimensions); err != nil {
+		return nil, fmt.Errorf("cannot query the store: %w", err)
+	}
+	filter, err := encodeMetadata(query.Filter)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query the store: %w", err)
+	}
+
+	rows, err := s.db.QueryContext(ctx,
+		"SELECT id, content, metadata, 1 - (embedding <=> $1::vector) AS score FROM "+s.opts.TableName+
+			" WHERE metadata @> $2::jsonb AND 1 - (embedding <=> $1::vector) >= $3"+
+			" ORDER BY embedding <=> $1::vector LIMIT $4",
+		vectorLiteral(query.Embedding), filter, query.MinScore, query.TopK)
+	if err != nil {
+		return nil, fmt.Errorf("cannot query the store: %w", err)
+	}
+	defer rows.Close()
+
+	rv := make([]vectorstore.Match, 0, query.TopK)
+	for rows.Next() {
+		var match vectorstore.Match
+		var metadata []byte
+		if err := rows.Scan(&match.ID, &match.Content, &metadata, &match.Score); err != nil {
+			return nil, fmt.Errorf("cannot read the matches: %w", err)
+		}
+		if err := json.Unmarshal(metadata, &match.Metadata); err != nil {
+			return nil, fmt.Errorf("cannot read the metadata of document %q: %w", match.ID, err)
+		}
+		rv = append(rv, match)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("cannot read the matches: %w", err)
+	}
+	return rv, nil
+}
+
+// Delete removes the documents with the given IDs, unknown IDs are ignored.
+func (s *Store) Delete(ctx context.Context, ids ...string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	placeholders := make([]string, len(ids))
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "$" + strconv.Itoa(i+1)
+		args[i] = id
+	}
+
+	_, err := s.db.ExecContext(ctx,
+		"DELETE FROM "+s.opts.TableName+" WHERE id IN ("+strings.Join(placeholders, ", ")+")", args...)
+	if err != nil {
+		return fmt.Errorf("cannot delete documents: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) migrate(ctx context.Context) error {
+	statements := []string{
+		"CREATE EXTENSION IF NOT EXISTS vector",
+		"CREATE TABLE IF NOT EXISTS " + s.opts.TableName + " (" +
+			"id TEXT PRIMARY KEY, " +
+			"content TEXT NOT NULL, " +
+			"metadata JSONB NOT NULL DEFAULT '{}'::jsonb, " +
+			"embedding vector(" + strconv.Itoa(s.dimensions) + ") NOT NULL)",
+		"CREATE INDEX IF NOT EXISTS " + s.opts.TableName + "_embedding_idx ON " + s.opts.TableName +
+			" USING hnsw (embedding vector_cosine_ops)",
+	}
+	for _, statement := range statements {
+		if _, err := s.db.ExecContext(ctx, statement); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeMetadata(metadata map[string]string) (string, error) {
+	if len(metadata) == 0 {
+		return "{}", nil
+	}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func vectorLiteral(vector []float32) string {
+	var sb strings.Builder
+	sb.WriteByte('[')
+	for i, v := range vector {
+		if i > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(strconv.FormatFloat(float64(v), 'f', -1, 32))
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
@@ -0,0 +1,125 @@
+// Package vectorstore defines the storage of embedded documents queried by similarity.
+package vectorstore
+
+import (
+	"context"
+	"errors"
+)
+
+var (
+	// ErrDocumentIDEmpty indicates that a document has no identifier.
+	ErrDocumentIDEmpty = errors.New("document id cannot be empty")
+	// ErrEmbeddingEmpty indicates that a document or a query has no embedding.
+	ErrEmbeddingEmpty = errors.New("embedding cannot be empty")
+	// ErrDimensionMismatch indicates that an embedding does not have the dimensions of the store.
+	ErrDimensionMismatch = errors.New("embedding dimensions do not match the store")
+	// ErrInvalidTopK indicates that the number of requested matches is not positive.
+	ErrInvalidTopK = errors.New("topK must be at least 1")
+)
+
+// Document is a piece of content stored with its embedding.
+type Document struct {
+	// ID uniquely identifies the document within the store.
+	ID string
+	// Content is the text of the document.
+	Content string
+	// Metadata holds arbitrary attributes, usable as query filters.
+	Metadata map[string]string
+	// Embedding is the vector representation of the content.
+	Embedding []float32
+}
+
+// Match is a document returned by a query.
+type Match struct {
+	Document
+	// Score is the cosine similarity between the document and the query, higher is closer.
+	Score float32
+}
+
+// Query describes a similarity search.
+type Query struct {
+	// Embedding is the vector to search for.
+	Embedding []float32
+	// TopK is the maximum number of matches.
+	TopK int
+	// Filter restricts the matches to the documents whose metadata contains all the given pairs.
+	Filter map[string]string
+	// MinScore discards the matches scoring below it.
+	MinScore float32
+}
+
+// Store holds embedded documents and queries them by similarity.
+//
+// Implementations must be safe for concurrent use.
+type Store interface {
+	// Upsert inserts the documents, replacing those with the same ID.
+	//
+	// Parameters:
+	//   - ctx: The context of the operation.
+	//   - docs: The documents to store.
+	//
+	// Returns:
+	//   - An error if any document is invalid or the storage fails.
+	Upsert(ctx context.Context, docs ...Document) error
+	// Query returns the documents closest to the query embedding, the closest first.
+	//
+	// Parameters:
+	//   - ctx: The context of the operation.
+	//   - query: The similarity search.
+	//
+	// Returns:
+	//   - The matches.
+	//   - An error if the query is invalid or the storage fails.
+	Query(ctx context.Context, query Query) ([]Match, error)
+	// Delete removes the documents with the given IDs, unknown IDs are ignored.
+	//
+	// Parameters:
+	//   - ctx: The context of the operation.
+	//   - ids: The identifiers of the documents to remove.
+	//
+	// Returns:
+	//   - An error if the storage fails.
+	Delete(ctx context.Context, ids ...string) error
+}
+
+// ValidateDocument checks that a document can be stored.
+//
+// Parameters:
+//   - doc: The document to check.
+//   - dimensions: The dimensions of the store, 0 to skip the check.
+//
+// Returns:
+//   - ErrDocumentIDEmpty, ErrEmbeddingEmpty, ErrDimensionMismatch or nil.
+func ValidateDocument(doc Document, dimensions int) error {
+	if doc.ID == "" {
+		return ErrDocumentIDEmpty
+	}
+	if len(doc.Embedding) == 0 {
+		return ErrEmbeddingEmpty
+	}
+	if dimensions > 0 && len(doc.Embedding) != dimensions {
+		return ErrDimensionMismatch
+	}
+	return nil
+}
+
+// ValidateQuery checks that a query can be run.
+//
+// Parameters:
+//   - query: The query to check.
+//   - dimensions: The dimensions of the store, 0 to skip the check.
+//
+// Returns:
+//   - ErrEmbeddingEmpty, ErrInvalidTopK, ErrDimensionMismatch or nil.
+func ValidateQuery(query Query, dimensions int) error {
+	if len(query.Embedding) == 0 {
+		return ErrEmbeddingEmpty
+	}
+	if query.TopK < 1 {
+		return ErrInvalidTopK
+	}
+	if dimensions > 0 && len(query.Embedding) != dimensions {
+		return ErrDimensionMismatch
+	}
+	return nil
+}